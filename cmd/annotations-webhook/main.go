@@ -0,0 +1,58 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The annotations-webhook binary serves the validating admission webhook of the external-dns
+// annotations of Services, Ingresses and other resources, see docs/annotations/annotations.md.
+package main
+
+import (
+	"os"
+
+	"github.com/alecthomas/kingpin/v2"
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/pkg/admission"
+	"sigs.k8s.io/external-dns/pkg/apis/externaldns"
+	"sigs.k8s.io/external-dns/source"
+)
+
+func main() {
+	app := kingpin.New("annotations-webhook", "Admission webhook validating the external-dns annotations of resources.")
+	app.Version(externaldns.Version)
+	listenAddress := app.Flag("listen-address", "The address to serve the webhook on").Default(":9443").String()
+	certFile := app.Flag("tls-cert-file", "The TLS certificate of the webhook (required)").Required().String()
+	keyFile := app.Flag("tls-key-file", "The TLS key of the webhook (required)").Required().String()
+	annotationPrefix := app.Flag("annotation-prefix", "The prefix of the annotations, as given to ExternalDNS").Default(source.DefaultAnnotationPrefix).String()
+	namespaceDomains := app.Flag("namespace-domains", "Only admit the hostnames of the resources of a namespace within domains, e.g. 'team-a=a.example.com,shared.example.com' (optional, can be repeated)").Strings()
+	warnOnly := app.Flag("warn-only", "When enabled, admits the resources with invalid annotations with warnings instead of rejecting them").Bool()
+	logLevel := app.Flag("log-level", "Set the level of logging. (default: info, options: panic, debug, info, warning, error, fatal)").Default(log.InfoLevel.String()).Enum("panic", "debug", "info", "warning", "error", "fatal")
+	kingpin.MustParse(app.Parse(os.Args[1:]))
+
+	level, err := log.ParseLevel(*logLevel)
+	if err != nil {
+		log.Fatalf("failed to parse log level: %v", err)
+	}
+	log.SetLevel(level)
+
+	source.SetAnnotationPrefix(*annotationPrefix)
+	domains := admission.NamespaceDomains{}
+	for _, value := range *namespaceDomains {
+		if err := domains.Parse(value); err != nil {
+			log.Fatal(err)
+		}
+	}
+	log.Fatal(admission.ListenAndServeAnnotationsTLS(*listenAddress, *certFile, *keyFile, domains, *warnOnly))
+}
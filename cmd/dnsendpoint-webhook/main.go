@@ -0,0 +1,53 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The dnsendpoint-webhook binary serves the validating and mutating admission webhooks of the
+// DNSEndpoint CRD, see docs/contributing/crd-source.md.
+package main
+
+import (
+	"os"
+
+	"github.com/alecthomas/kingpin/v2"
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/pkg/admission"
+	"sigs.k8s.io/external-dns/pkg/apis/externaldns"
+)
+
+func main() {
+	app := kingpin.New("dnsendpoint-webhook", "Admission webhooks validating DNSEndpoints and setting their defaults.")
+	app.Version(externaldns.Version)
+	listenAddress := app.Flag("listen-address", "The address to serve the webhooks on").Default(":9443").String()
+	certFile := app.Flag("tls-cert-file", "The TLS certificate of the webhooks (required)").Required().String()
+	keyFile := app.Flag("tls-key-file", "The TLS key of the webhooks (required)").Required().String()
+	defaultTTL := app.Flag("default-ttl", "The TTL set by the mutating webhook on the endpoints without one, in seconds; 0 leaves it to the provider").Default("0").Int64()
+	passthroughRecordTypes := app.Flag("passthrough-record-types", "Record types accepted with their raw RDATA as targets, as passed through by external-dns with --passthrough-record-types; specify multiple times to include many (optional)").Strings()
+	logLevel := app.Flag("log-level", "Set the level of logging. (default: info, options: panic, debug, info, warning, error, fatal)").Default(log.InfoLevel.String()).Enum("panic", "debug", "info", "warning", "error", "fatal")
+	kingpin.MustParse(app.Parse(os.Args[1:]))
+
+	level, err := log.ParseLevel(*logLevel)
+	if err != nil {
+		log.Fatalf("failed to parse log level: %v", err)
+	}
+	log.SetLevel(level)
+
+	if *defaultTTL < 0 || endpoint.TTL(*defaultTTL) > endpoint.MaxTTL {
+		log.Fatalf("--default-ttl must be between 0 and %d", endpoint.MaxTTL)
+	}
+	log.Fatal(admission.ListenAndServeTLS(*listenAddress, *certFile, *keyFile, endpoint.TTL(*defaultTTL), *passthroughRecordTypes))
+}
@@ -0,0 +1,403 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+const (
+	// defaultCanaryTimeout is how long the changes of a canary zone may take to resolve.
+	defaultCanaryTimeout = 2 * time.Minute
+	// defaultCanaryInterval is the wait between the lookups of the records of a canary zone.
+	defaultCanaryInterval = 5 * time.Second
+)
+
+var canaryVerificationFailuresTotal = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Namespace: "external_dns",
+		Subsystem: "controller",
+		Name:      "canary_verification_failures_total",
+		Help:      "Number of times the changes applied to the canary zones did not resolve in time.",
+	},
+)
+
+func init() {
+	prometheus.MustRegister(canaryVerificationFailuresTotal)
+}
+
+// CanaryZone mirrors the records of Zone into the test zone Mirror, e.g. app.example.com is written
+// as app.canary.example.net for the zone example.com and the mirror canary.example.net.
+type CanaryZone struct {
+	Zone   string
+	Mirror string
+}
+
+// ParseCanaryZone parses a zone and its mirror given as "example.com=canary.example.net".
+func ParseCanaryZone(value string) (CanaryZone, error) {
+	zone, mirror, ok := strings.Cut(value, "=")
+	zone = strings.ToLower(strings.Trim(strings.TrimSpace(zone), "."))
+	mirror = strings.ToLower(strings.Trim(strings.TrimSpace(mirror), "."))
+	if !ok || zone == "" || mirror == "" {
+		return CanaryZone{}, fmt.Errorf("invalid canary zone %q: expected <zone>=<canary zone>", value)
+	}
+	if mirror == zone || strings.HasSuffix(mirror, "."+zone) || strings.HasSuffix(zone, "."+mirror) {
+		return CanaryZone{}, fmt.Errorf("invalid canary zone %q: the canary zone must not overlap the zone", value)
+	}
+	return CanaryZone{Zone: zone, Mirror: mirror}, nil
+}
+
+// CanaryLookup returns the targets of the records of a type of a DNS name, none if it has no such
+// records.
+type CanaryLookup func(ctx context.Context, name, recordType string) ([]string, error)
+
+// NewCanaryLookup returns a lookup querying the nameserver, given as host:port, directly. The
+// authoritative nameserver of the canary zones answers with the applied records without caching
+// them. Without a nameserver, the first nameserver of /etc/resolv.conf is queried.
+func NewCanaryLookup(nameserver string) (CanaryLookup, error) {
+	if nameserver == "" {
+		config, err := dns.ClientConfigFromFile("/etc/resolv.conf")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the nameservers: %w", err)
+		}
+		if len(config.Servers) == 0 {
+			return nil, errors.New("no nameserver in /etc/resolv.conf")
+		}
+		nameserver = net.JoinHostPort(config.Servers[0], config.Port)
+	}
+
+	udp := &dns.Client{Timeout: 5 * time.Second}
+	tcp := &dns.Client{Net: "tcp", Timeout: 5 * time.Second}
+	return func(ctx context.Context, name, recordType string) ([]string, error) {
+		qtype, ok := dns.StringToType[recordType]
+		if !ok {
+			return nil, fmt.Errorf("unknown record type %q", recordType)
+		}
+		query := new(dns.Msg)
+		query.SetQuestion(dns.Fqdn(name), qtype)
+		answer, _, err := udp.ExchangeContext(ctx, query, nameserver)
+		if err == nil && answer.Truncated {
+			answer, _, err = tcp.ExchangeContext(ctx, query, nameserver)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if answer.Rcode != dns.RcodeSuccess && answer.Rcode != dns.RcodeNameError {
+			return nil, fmt.Errorf("query of %s %s failed: %s", recordType, name, dns.RcodeToString[answer.Rcode])
+		}
+
+		var targets []string
+		for _, rr := range answer.Answer {
+			if rr.Header().Rrtype != qtype || !strings.EqualFold(rr.Header().Name, dns.Fqdn(name)) {
+				continue
+			}
+			switch r := rr.(type) {
+			case *dns.A:
+				targets = append(targets, r.A.String())
+			case *dns.AAAA:
+				targets = append(targets, r.AAAA.String())
+			case *dns.CNAME:
+				targets = append(targets, r.Target)
+			case *dns.TXT:
+				targets = append(targets, strings.Join(r.Txt, ""))
+			}
+		}
+		return targets, nil
+	}, nil
+}
+
+// Canary applies the changes of the records of zones with a canary zone to their canary zone first,
+// and to the zones themselves only once the records of the canary zone resolve to the new targets.
+// Only A, AAAA, CNAME and TXT records without a set identifier are verified.
+type Canary struct {
+	Zones  []CanaryZone
+	Lookup CanaryLookup
+	// Timeout is how long the records of the canary zones may take to resolve, defaultCanaryTimeout
+	// if zero
+	Timeout time.Duration
+	// interval is the wait between lookups, defaultCanaryInterval if zero
+	interval time.Duration
+	// records are the records of the canary zones, updated by the full reconciliations and the
+	// changes applied to the canary zones, guarded by mu as the changes of zones are applied
+	// concurrently
+	records map[endpoint.EndpointKey]*endpoint.Endpoint
+	mu      sync.Mutex
+}
+
+// canaryCheck is the expected state of a record of a canary zone, no targets for a deleted record.
+type canaryCheck struct {
+	name       string
+	recordType string
+	targets    endpoint.Targets
+}
+
+// split returns the records outside of the canary zones, so they are never planned against. The
+// records of the canary zones are kept when the records were listed from the registry.
+func (c *Canary) split(records []*endpoint.Endpoint, full bool) []*endpoint.Endpoint {
+	if full {
+		c.records = map[endpoint.EndpointKey]*endpoint.Endpoint{}
+	}
+	result := make([]*endpoint.Endpoint, 0, len(records))
+	for _, e := range records {
+		if !c.inMirror(e.DNSName) {
+			result = append(result, e)
+		} else if full {
+			c.records[e.Key()] = e
+		}
+	}
+	return result
+}
+
+// apply applies the changes to the canary zones, waits for them to resolve and then applies them.
+func (c *Canary) apply(ctx context.Context, changes *plan.Changes, apply func(context.Context, *plan.Changes) error) error {
+	c.mu.Lock()
+	mirrored, checks := c.mirrorChanges(changes)
+	c.mu.Unlock()
+	if mirrored.HasChanges() {
+		log.Infof("Applying %d changes to the canary zones first", len(mirrored.Create)+len(mirrored.UpdateNew)+len(mirrored.Delete))
+		err := apply(ctx, mirrored)
+		c.mu.Lock()
+		if err != nil {
+			// the records of the canary zones are unknown until the next full reconciliation
+			c.records = nil
+			c.mu.Unlock()
+			return fmt.Errorf("failed to apply the changes to the canary zones: %w", err)
+		}
+		// the records are unknown when the changes of another zone failed meanwhile
+		if c.records != nil {
+			c.update(mirrored)
+		}
+		c.mu.Unlock()
+	}
+	// the checks run even without changes to the canary zones, e.g. when retrying after a failed
+	// verification
+	if err := c.verify(ctx, checks); err != nil {
+		canaryVerificationFailuresTotal.Inc()
+		return err
+	}
+	return apply(ctx, changes)
+}
+
+// mirrorChanges returns the changes of the records of the canary zones bringing them to the new
+// state of the changed records, and the checks of that state.
+func (c *Canary) mirrorChanges(changes *plan.Changes) (*plan.Changes, []canaryCheck) {
+	if c.records == nil {
+		c.records = map[endpoint.EndpointKey]*endpoint.Endpoint{}
+	}
+	mirrored := &plan.Changes{}
+	var checks []canaryCheck
+
+	desired := func(e *endpoint.Endpoint) {
+		m := c.mirrorEndpoint(e)
+		if m == nil {
+			return
+		}
+		if m.SetIdentifier == "" {
+			checks = append(checks, canaryCheck{name: m.DNSName, recordType: m.RecordType, targets: m.Targets})
+		}
+		current, ok := c.records[m.Key()]
+		switch {
+		case !ok:
+			mirrored.Create = append(mirrored.Create, m)
+		case !current.Targets.Same(m.Targets) || current.RecordTTL != m.RecordTTL:
+			mirrored.UpdateOld = append(mirrored.UpdateOld, current)
+			mirrored.UpdateNew = append(mirrored.UpdateNew, m)
+		}
+	}
+	for _, e := range changes.Create {
+		desired(e)
+	}
+	for _, e := range changes.UpdateNew {
+		desired(e)
+	}
+	for _, e := range changes.Delete {
+		m := c.mirrorEndpoint(e)
+		if m == nil {
+			continue
+		}
+		if m.SetIdentifier == "" {
+			checks = append(checks, canaryCheck{name: m.DNSName, recordType: m.RecordType})
+		}
+		if current, ok := c.records[m.Key()]; ok {
+			mirrored.Delete = append(mirrored.Delete, current)
+		}
+	}
+	return mirrored, checks
+}
+
+// update updates the known records of the canary zones with the applied changes.
+func (c *Canary) update(changes *plan.Changes) {
+	for _, e := range changes.Delete {
+		delete(c.records, e.Key())
+	}
+	for _, e := range changes.Create {
+		c.records[e.Key()] = e
+	}
+	for _, e := range changes.UpdateNew {
+		c.records[e.Key()] = e
+	}
+}
+
+// verify looks up the records of the checks until they all resolve to their expected targets, or
+// fails after the timeout.
+func (c *Canary) verify(ctx context.Context, checks []canaryCheck) error {
+	timeout, interval := c.Timeout, c.interval
+	if timeout <= 0 {
+		timeout = defaultCanaryTimeout
+	}
+	if interval <= 0 {
+		interval = defaultCanaryInterval
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	pending := make([]canaryCheck, 0, len(checks))
+	for _, check := range checks {
+		switch check.recordType {
+		case endpoint.RecordTypeA, endpoint.RecordTypeAAAA, endpoint.RecordTypeCNAME, endpoint.RecordTypeTXT:
+			pending = append(pending, check)
+		}
+	}
+
+	for {
+		var failed []string
+		remaining := pending[:0]
+		for _, check := range pending {
+			ok, err := c.resolves(ctx, check)
+			if !ok {
+				remaining = append(remaining, check)
+				if err != nil {
+					failed = append(failed, fmt.Sprintf("%s %s: %v", check.recordType, check.name, err))
+				} else {
+					failed = append(failed, fmt.Sprintf("%s %s", check.recordType, check.name))
+				}
+			}
+		}
+		pending = remaining
+		if len(pending) == 0 {
+			if len(checks) > 0 {
+				log.Infof("The changes to the canary zones resolve, applying them")
+			}
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("the changes to the canary zones did not resolve within %s: %s", timeout, strings.Join(failed, ", "))
+		case <-time.After(interval):
+			log.Debugf("Waiting for %d records of the canary zones to resolve", len(pending))
+		}
+	}
+}
+
+// resolves returns whether the record of the check resolves to the expected targets.
+func (c *Canary) resolves(ctx context.Context, check canaryCheck) (bool, error) {
+	targets, err := c.Lookup(ctx, check.name, check.recordType)
+	if err != nil {
+		return false, err
+	}
+	return sameTargets(check.recordType, targets, check.targets), nil
+}
+
+// sameTargets compares the resolved targets to the targets of a record ignoring their order, the
+// quotes of TXT records, the case and the trailing dot of names and the notation of IPv6 addresses.
+func sameTargets(recordType string, resolved []string, targets endpoint.Targets) bool {
+	normalize := func(values []string) []string {
+		result := make([]string, 0, len(values))
+		for _, v := range values {
+			switch recordType {
+			case endpoint.RecordTypeTXT:
+				v = strings.Trim(v, `"`)
+			case endpoint.RecordTypeCNAME:
+				v = strings.ToLower(strings.TrimSuffix(v, "."))
+			default:
+				if ip := net.ParseIP(v); ip != nil {
+					v = ip.String()
+				}
+			}
+			result = append(result, v)
+		}
+		sort.Strings(result)
+		return result
+	}
+	a, b := normalize(resolved), normalize(targets)
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// mirrorEndpoint returns a copy of the endpoint renamed into the canary zone of its zone, or nil if
+// its zone has no canary zone.
+func (c *Canary) mirrorEndpoint(e *endpoint.Endpoint) *endpoint.Endpoint {
+	name, ok := c.mirrorName(e.DNSName)
+	if !ok {
+		return nil
+	}
+	m := *e
+	m.DNSName = name
+	m.Labels = endpoint.NewLabels()
+	for k, v := range e.Labels {
+		m.Labels[k] = v
+	}
+	return &m
+}
+
+// mirrorName returns the name in the canary zone of the longest zone matching the DNS name.
+func (c *Canary) mirrorName(dnsName string) (string, bool) {
+	name := strings.ToLower(strings.TrimSuffix(dnsName, "."))
+	var match *CanaryZone
+	for i, z := range c.Zones {
+		if (name == z.Zone || strings.HasSuffix(name, "."+z.Zone)) && (match == nil || len(z.Zone) > len(match.Zone)) {
+			match = &c.Zones[i]
+		}
+	}
+	if match == nil {
+		return "", false
+	}
+	return strings.TrimSuffix(name, match.Zone) + match.Mirror, true
+}
+
+// inMirror returns whether the DNS name is in a canary zone.
+func (c *Canary) inMirror(dnsName string) bool {
+	name := strings.ToLower(strings.TrimSuffix(dnsName, "."))
+	for _, z := range c.Zones {
+		if name == z.Mirror || strings.HasSuffix(name, "."+z.Mirror) {
+			return true
+		}
+	}
+	return false
+}
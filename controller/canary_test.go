@@ -0,0 +1,203 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/registry"
+)
+
+func TestParseCanaryZone(t *testing.T) {
+	z, err := ParseCanaryZone("Example.com.=canary.example.net")
+	require.NoError(t, err)
+	assert.Equal(t, CanaryZone{Zone: "example.com", Mirror: "canary.example.net"}, z)
+
+	for _, value := range []string{
+		"example.com",
+		"example.com=",
+		"=canary.example.net",
+		"example.com=example.com",
+		"example.com=canary.example.com",
+		"canary.example.com=example.com",
+	} {
+		_, err := ParseCanaryZone(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestCanaryMirrorName(t *testing.T) {
+	c := &Canary{Zones: []CanaryZone{
+		{Zone: "example.com", Mirror: "canary.example.net"},
+		{Zone: "prod.example.com", Mirror: "prod.canary.example.net"},
+	}}
+	for name, mirror := range map[string]string{
+		"example.com":          "canary.example.net",
+		"app.example.com":      "app.canary.example.net",
+		"app.Prod.example.com": "app.prod.canary.example.net",
+		"app.example.org":      "",
+		"appexample.com":       "",
+	} {
+		m, ok := c.mirrorName(name)
+		assert.Equal(t, mirror != "", ok, name)
+		assert.Equal(t, mirror, m, name)
+	}
+	assert.True(t, c.inMirror("app.canary.example.net"))
+	assert.False(t, c.inMirror("app.example.com"))
+}
+
+func TestSameTargets(t *testing.T) {
+	assert.True(t, sameTargets(endpoint.RecordTypeA, []string{"2.2.2.2", "1.1.1.1"}, endpoint.Targets{"1.1.1.1", "2.2.2.2"}))
+	assert.False(t, sameTargets(endpoint.RecordTypeA, []string{"1.1.1.1"}, endpoint.Targets{"1.1.1.1", "2.2.2.2"}))
+	assert.True(t, sameTargets(endpoint.RecordTypeAAAA, []string{"2001:db8::1"}, endpoint.Targets{"2001:DB8:0::1"}))
+	assert.True(t, sameTargets(endpoint.RecordTypeCNAME, []string{"Target.example.com."}, endpoint.Targets{"target.example.com"}))
+	assert.True(t, sameTargets(endpoint.RecordTypeTXT, []string{"value"}, endpoint.Targets{`"value"`}))
+	assert.True(t, sameTargets(endpoint.RecordTypeA, nil, nil))
+}
+
+func TestRunOnceWithCanary(t *testing.T) {
+	a := endpoint.NewEndpoint("a.example.com", endpoint.RecordTypeA, "1.1.1.1")
+	aCanary := endpoint.NewEndpoint("a.canary.example.net", endpoint.RecordTypeA, "1.1.1.1")
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return([]*endpoint.Endpoint{
+		endpoint.NewEndpoint("a.example.com", endpoint.RecordTypeA, "2.2.2.2"),
+		endpoint.NewEndpoint("b.example.com", endpoint.RecordTypeA, "3.3.3.3"),
+	}, nil)
+	provider := &filteredMockProvider{RecordsStore: []*endpoint.Endpoint{a, aCanary}}
+	r, err := registry.NewNoopRegistry(provider)
+	require.NoError(t, err)
+
+	resolved := map[string][]string{}
+	ctrl := &Controller{
+		Source:             source,
+		Registry:           r,
+		Policy:             &plan.SyncPolicy{},
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+		Canary: &Canary{
+			Zones: []CanaryZone{{Zone: "example.com", Mirror: "canary.example.net"}},
+			Lookup: func(_ context.Context, name, recordType string) ([]string, error) {
+				return resolved[name], nil
+			},
+			Timeout:  50 * time.Millisecond,
+			interval: 10 * time.Millisecond,
+		},
+	}
+
+	// the changes are applied to the canary zone, whose records are never deleted, but not to the
+	// zone while they don't resolve
+	require.Error(t, ctrl.RunOnce(context.Background()))
+	require.Len(t, provider.ApplyChangesCalls, 1)
+	canaryChanges := provider.ApplyChangesCalls[0]
+	require.Len(t, canaryChanges.Create, 1)
+	assert.Equal(t, "b.canary.example.net", canaryChanges.Create[0].DNSName)
+	assert.Equal(t, []*endpoint.Endpoint{aCanary}, canaryChanges.UpdateOld)
+	require.Len(t, canaryChanges.UpdateNew, 1)
+	assert.Equal(t, "a.canary.example.net", canaryChanges.UpdateNew[0].DNSName)
+	assert.Equal(t, endpoint.Targets{"2.2.2.2"}, canaryChanges.UpdateNew[0].Targets)
+	assert.Empty(t, canaryChanges.Delete)
+
+	// and to the zone once they resolve
+	resolved["a.canary.example.net"] = []string{"2.2.2.2"}
+	resolved["b.canary.example.net"] = []string{"3.3.3.3"}
+	ctrl.zones = zoneQueue{}
+	require.NoError(t, ctrl.RunOnce(context.Background()))
+	require.Len(t, provider.ApplyChangesCalls, 3)
+	changes := provider.ApplyChangesCalls[2]
+	require.Len(t, changes.Create, 1)
+	assert.Equal(t, "b.example.com", changes.Create[0].DNSName)
+	assert.Equal(t, []*endpoint.Endpoint{a}, changes.UpdateOld)
+}
+
+func TestCanaryApplyVerifiesWithoutChanges(t *testing.T) {
+	c := &Canary{
+		Zones: []CanaryZone{{Zone: "example.com", Mirror: "canary.example.net"}},
+		Lookup: func(context.Context, string, string) ([]string, error) {
+			return []string{"1.1.1.1"}, nil
+		},
+		Timeout:  50 * time.Millisecond,
+		interval: 10 * time.Millisecond,
+	}
+	aCanary := endpoint.NewEndpoint("a.canary.example.net", endpoint.RecordTypeA, "1.1.1.1")
+	c.split([]*endpoint.Endpoint{aCanary}, true)
+
+	var applied []*plan.Changes
+	apply := func(_ context.Context, changes *plan.Changes) error {
+		applied = append(applied, changes)
+		return nil
+	}
+
+	// the deletion is applied to the canary zone, but not to the zone while the record resolves
+	del := &plan.Changes{Delete: []*endpoint.Endpoint{endpoint.NewEndpoint("a.example.com", endpoint.RecordTypeA, "1.1.1.1")}}
+	create := &plan.Changes{Create: []*endpoint.Endpoint{endpoint.NewEndpoint("a.example.com", endpoint.RecordTypeA, "1.1.1.1")}}
+	require.Error(t, c.apply(context.Background(), del, apply))
+	require.Len(t, applied, 1)
+	assert.Equal(t, []*endpoint.Endpoint{aCanary}, applied[0].Delete)
+
+	// the canary zone is up to date, only the zone is changed once the record resolves
+	c.split([]*endpoint.Endpoint{aCanary}, true)
+	applied = nil
+	require.NoError(t, c.apply(context.Background(), create, apply))
+	assert.Equal(t, []*plan.Changes{create}, applied)
+}
+
+func TestNewCanaryLookup(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := &dns.Server{PacketConn: conn, Handler: dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetReply(req)
+		q := req.Question[0]
+		switch {
+		case q.Name == "a.example.com." && q.Qtype == dns.TypeA:
+			rr, _ := dns.NewRR("a.example.com. 300 IN A 1.1.1.1")
+			m.Answer = append(m.Answer, rr)
+		case q.Name == "a.example.com." && q.Qtype == dns.TypeTXT:
+			rr, _ := dns.NewRR(`a.example.com. 300 IN TXT "first" "second"`)
+			m.Answer = append(m.Answer, rr)
+		default:
+			m.Rcode = dns.RcodeNameError
+		}
+		_ = w.WriteMsg(m)
+	})}
+	go func() { _ = server.ActivateAndServe() }()
+	defer func() { _ = server.Shutdown() }()
+
+	lookup, err := NewCanaryLookup(conn.LocalAddr().String())
+	require.NoError(t, err)
+
+	targets, err := lookup(context.Background(), "a.example.com", endpoint.RecordTypeA)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1.1.1.1"}, targets)
+
+	targets, err = lookup(context.Background(), "a.example.com", endpoint.RecordTypeTXT)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"firstsecond"}, targets)
+
+	targets, err = lookup(context.Background(), "b.example.com", endpoint.RecordTypeA)
+	require.NoError(t, err)
+	assert.Empty(t, targets)
+}
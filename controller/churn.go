@@ -0,0 +1,264 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+// ChurnPath is the path of the handler of the churn report on the metrics address.
+const ChurnPath = "/debug/churn"
+
+var (
+	zoneChanges = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "external_dns",
+			Subsystem: "controller",
+			Name:      "zone_changes",
+			Help:      "Number of changes of the records of a zone applied within the churn window.",
+		},
+		[]string{"zone"},
+	)
+	churningRecords = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "external_dns",
+			Subsystem: "controller",
+			Name:      "churning_records",
+			Help:      "Number of records of a zone changed more times than the churn threshold within the churn window.",
+		},
+		[]string{"zone"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(zoneChanges)
+	prometheus.MustRegister(churningRecords)
+}
+
+// ChurnReport is the number of changes of the records of every zone within the window, the zones
+// and the records changing most first.
+type ChurnReport struct {
+	Window    string      `json:"window"`
+	Threshold int         `json:"threshold"`
+	Zones     []ZoneChurn `json:"zones"`
+}
+
+// ZoneChurn is the number of changes of the records of a zone within the window.
+type ZoneChurn struct {
+	Zone    string        `json:"zone"`
+	Changes int           `json:"changes"`
+	Records []RecordChurn `json:"records"`
+}
+
+// RecordChurn is the number of changes of a record within the window.
+type RecordChurn struct {
+	Name          string `json:"name"`
+	RecordType    string `json:"recordType"`
+	SetIdentifier string `json:"setIdentifier,omitempty"`
+	Changes       int    `json:"changes"`
+	// Flagged is whether the record changed more times than the threshold
+	Flagged bool `json:"flagged"`
+}
+
+// churnKey identifies a changed record.
+type churnKey struct {
+	zone          string
+	name          string
+	recordType    string
+	setIdentifier string
+}
+
+// Churn counts the applied changes of every record over a sliding window, flagging the records
+// changing more times than the threshold, to find the workloads causing DNS churn.
+type Churn struct {
+	Window    time.Duration
+	Threshold int
+
+	mu sync.Mutex
+	// changes are the times of the changes of every record within the window, oldest first
+	changes map[churnKey][]time.Time
+	// flagged are the records flagged by the last observation
+	flagged map[churnKey]bool
+	// zones are the zones observed last, whose metrics are reset once they have no changes
+	zones map[string]bool
+}
+
+// NewChurn returns a churn counting the changes within the window.
+func NewChurn(window time.Duration, threshold int) *Churn {
+	return &Churn{
+		Window:    window,
+		Threshold: threshold,
+		changes:   map[churnKey][]time.Time{},
+		flagged:   map[churnKey]bool{},
+		zones:     map[string]bool{},
+	}
+}
+
+// record counts the created, updated and deleted records of the applied changes. The zone of a
+// record is the most specific domain of the zones containing it, or its parent domain.
+func (c *Churn) record(changes *plan.Changes, zones []string, at time.Time) {
+	zoneNames := provider.ZoneIDName{}
+	for _, zone := range zones {
+		zone = strings.Trim(zone, ".")
+		if zone != "" {
+			zoneNames.Add(zone, zone)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, e := range append(append(append([]*endpoint.Endpoint{}, changes.Create...), changes.UpdateNew...), changes.Delete...) {
+		name := desiredStateKey(e.DNSName)
+		_, zone := zoneNames.FindZone(name)
+		if zone == "" {
+			if _, parent, ok := strings.Cut(name, "."); ok {
+				zone = parent
+			} else {
+				zone = name
+			}
+		}
+		key := churnKey{zone: zone, name: name, recordType: e.RecordType, setIdentifier: e.SetIdentifier}
+		c.changes[key] = append(c.changes[key], at)
+	}
+}
+
+// observe forgets the changes older than the window, updates the metrics of the zones, and warns
+// about the records flagged since the last observation.
+func (c *Churn) observe(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.prune(now)
+
+	changes := map[string]int{}
+	churning := map[string]int{}
+	flagged := map[churnKey]bool{}
+	for key, times := range c.changes {
+		changes[key.zone] += len(times)
+		if len(times) <= c.Threshold {
+			continue
+		}
+		churning[key.zone]++
+		flagged[key] = true
+		if !c.flagged[key] {
+			log.Warnf("The %s record %s changed %d times within %s, more than the churn threshold of %d", key.recordType, key.name, len(times), c.Window, c.Threshold)
+		}
+	}
+	c.flagged = flagged
+
+	for zone := range c.zones {
+		if _, ok := changes[zone]; !ok {
+			zoneChanges.DeleteLabelValues(zone)
+			churningRecords.DeleteLabelValues(zone)
+		}
+	}
+	c.zones = map[string]bool{}
+	for zone, n := range changes {
+		c.zones[zone] = true
+		zoneChanges.WithLabelValues(zone).Set(float64(n))
+		churningRecords.WithLabelValues(zone).Set(float64(churning[zone]))
+	}
+}
+
+// prune forgets the changes older than the window.
+func (c *Churn) prune(now time.Time) {
+	since := now.Add(-c.Window)
+	for key, times := range c.changes {
+		i := sort.Search(len(times), func(i int) bool { return times[i].After(since) })
+		if i == len(times) {
+			delete(c.changes, key)
+		} else if i > 0 {
+			c.changes[key] = append([]time.Time{}, times[i:]...)
+		}
+	}
+}
+
+// Report returns the churn of the zone, or of every zone if empty, at now.
+func (c *Churn) Report(zone string, now time.Time) *ChurnReport {
+	zone = strings.Trim(strings.ToLower(zone), ".")
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.prune(now)
+
+	byZone := map[string]*ZoneChurn{}
+	for key, times := range c.changes {
+		if zone != "" && key.zone != zone {
+			continue
+		}
+		z, ok := byZone[key.zone]
+		if !ok {
+			z = &ZoneChurn{Zone: key.zone}
+			byZone[key.zone] = z
+		}
+		z.Changes += len(times)
+		z.Records = append(z.Records, RecordChurn{
+			Name:          key.name,
+			RecordType:    key.recordType,
+			SetIdentifier: key.setIdentifier,
+			Changes:       len(times),
+			Flagged:       len(times) > c.Threshold,
+		})
+	}
+
+	report := &ChurnReport{Window: c.Window.String(), Threshold: c.Threshold, Zones: []ZoneChurn{}}
+	for _, z := range byZone {
+		sort.Slice(z.Records, func(i, j int) bool {
+			a, b := z.Records[i], z.Records[j]
+			if a.Changes != b.Changes {
+				return a.Changes > b.Changes
+			}
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+			if a.RecordType != b.RecordType {
+				return a.RecordType < b.RecordType
+			}
+			return a.SetIdentifier < b.SetIdentifier
+		})
+		report.Zones = append(report.Zones, *z)
+	}
+	sort.Slice(report.Zones, func(i, j int) bool {
+		a, b := report.Zones[i], report.Zones[j]
+		if a.Changes != b.Changes {
+			return a.Changes > b.Changes
+		}
+		return a.Zone < b.Zone
+	})
+	return report
+}
+
+// Handler serves the churn report as JSON, of the zone in the "zone" query parameter if any.
+func (c *Churn) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(c.Report(r.URL.Query().Get("zone"), time.Now())); err != nil {
+			log.Debugf("Failed to write the churn report: %v", err)
+		}
+	})
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+// metricsCount returns the number of metrics of the collector.
+func metricsCount(collector prometheus.Collector) int {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		collector.Collect(ch)
+		close(ch)
+	}()
+	n := 0
+	for range ch {
+		n++
+	}
+	return n
+}
+
+func gaugeValue(t *testing.T, gauge prometheus.Gauge) float64 {
+	t.Helper()
+	metric := &dto.Metric{}
+	require.NoError(t, gauge.Write(metric))
+	return metric.GetGauge().GetValue()
+}
+
+func TestChurnReport(t *testing.T) {
+	c := NewChurn(time.Hour, 2)
+	zones := []string{"example.org", "sub.example.org"}
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	flapping := endpoint.NewEndpoint("App.example.org.", endpoint.RecordTypeA, "1.1.1.1")
+	for i := 0; i < 3; i++ {
+		c.record(&plan.Changes{UpdateNew: []*endpoint.Endpoint{flapping}}, zones, start.Add(time.Duration(i)*10*time.Minute))
+	}
+	c.record(&plan.Changes{
+		Create: []*endpoint.Endpoint{endpoint.NewEndpoint("db.sub.example.org", endpoint.RecordTypeA, "2.2.2.2")},
+		Delete: []*endpoint.Endpoint{endpoint.NewEndpoint("old.other.com", endpoint.RecordTypeCNAME, "app.example.org")},
+	}, zones, start.Add(30*time.Minute))
+
+	c.observe(start.Add(30 * time.Minute))
+	assert.InDelta(t, 3, gaugeValue(t, zoneChanges.WithLabelValues("example.org")), 0)
+	assert.InDelta(t, 1, gaugeValue(t, churningRecords.WithLabelValues("example.org")), 0)
+	assert.InDelta(t, 1, gaugeValue(t, zoneChanges.WithLabelValues("other.com")), 0)
+
+	assert.Equal(t, &ChurnReport{Window: "1h0m0s", Threshold: 2, Zones: []ZoneChurn{
+		{Zone: "example.org", Changes: 3, Records: []RecordChurn{{Name: "app.example.org", RecordType: endpoint.RecordTypeA, Changes: 3, Flagged: true}}},
+		{Zone: "other.com", Changes: 1, Records: []RecordChurn{{Name: "old.other.com", RecordType: endpoint.RecordTypeCNAME, Changes: 1}}},
+		{Zone: "sub.example.org", Changes: 1, Records: []RecordChurn{{Name: "db.sub.example.org", RecordType: endpoint.RecordTypeA, Changes: 1}}},
+	}}, c.Report("", start.Add(30*time.Minute)))
+
+	// the changes older than the window are forgotten
+	assert.Equal(t, []ZoneChurn{
+		{Zone: "example.org", Changes: 2, Records: []RecordChurn{{Name: "app.example.org", RecordType: endpoint.RecordTypeA, Changes: 2}}},
+	}, c.Report("Example.org.", start.Add(65*time.Minute)).Zones)
+
+	c.observe(start.Add(2 * time.Hour))
+	assert.Empty(t, c.Report("", start.Add(2*time.Hour)).Zones)
+	assert.Equal(t, 0, metricsCount(zoneChanges))
+	assert.Equal(t, 0, metricsCount(churningRecords))
+}
+
+func TestChurnHandler(t *testing.T) {
+	c := NewChurn(time.Hour, 10)
+	c.record(&plan.Changes{Create: []*endpoint.Endpoint{endpoint.NewEndpoint("app.example.org", endpoint.RecordTypeA, "1.1.1.1")}}, nil, time.Now())
+
+	rec := httptest.NewRecorder()
+	c.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, ChurnPath+"?zone=example.org", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	report := &ChurnReport{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), report))
+	require.Len(t, report.Zones, 1)
+	assert.Equal(t, "example.org", report.Zones[0].Zone)
+	assert.Equal(t, 1, report.Zones[0].Changes)
+}
@@ -82,6 +82,23 @@ var (
 			Help:      "Timestamp of last attempted sync with the DNS provider",
 		},
 	)
+	lastFullSyncTimestamp = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "external_dns",
+			Subsystem: "controller",
+			Name:      "last_full_sync_timestamp_seconds",
+			Help:      "Timestamp of last successful sync with the records listed from the DNS provider",
+		},
+	)
+	reconcilesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "external_dns",
+			Subsystem: "controller",
+			Name:      "reconciles_total",
+			Help:      "Number of reconcile loops, full ones listing the records of the DNS provider or incremental ones.",
+		},
+		[]string{"type"},
+	)
 	controllerNoChangesTotal = prometheus.NewCounter(
 		prometheus.CounterOpts{
 			Namespace: "external_dns",
@@ -152,6 +169,40 @@ var (
 			Help:      "Number of DNS AAAA-records that exists both in source and registry.",
 		},
 	)
+	planDesiredEndpoints = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "external_dns",
+			Subsystem: "controller",
+			Name:      "plan_desired_endpoints",
+			Help:      "Number of desired endpoints the last plan was calculated with.",
+		},
+	)
+	planCurrentRecords = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "external_dns",
+			Subsystem: "controller",
+			Name:      "plan_current_records",
+			Help:      "Number of current records the last plan was calculated with.",
+		},
+	)
+	planChanges = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "external_dns",
+			Subsystem: "controller",
+			Name:      "plan_changes",
+			Help:      "Number of changes of the last plan, by action: create, update or delete.",
+		},
+		[]string{"action"},
+	)
+	skippedEndpoints = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "external_dns",
+			Subsystem: "controller",
+			Name:      "skipped_endpoints",
+			Help:      "Number of desired endpoints left out of the last plan, by reason: domain_filter, unsupported_type or ownership_conflict.",
+		},
+		[]string{"reason"},
+	)
 )
 
 func init() {
@@ -161,6 +212,8 @@ func init() {
 	prometheus.MustRegister(registryEndpointsTotal)
 	prometheus.MustRegister(lastSyncTimestamp)
 	prometheus.MustRegister(lastReconcileTimestamp)
+	prometheus.MustRegister(lastFullSyncTimestamp)
+	prometheus.MustRegister(reconcilesTotal)
 	prometheus.MustRegister(deprecatedRegistryErrors)
 	prometheus.MustRegister(deprecatedSourceErrors)
 	prometheus.MustRegister(controllerNoChangesTotal)
@@ -170,6 +223,10 @@ func init() {
 	prometheus.MustRegister(sourceAAAARecords)
 	prometheus.MustRegister(verifiedARecords)
 	prometheus.MustRegister(verifiedAAAARecords)
+	prometheus.MustRegister(planDesiredEndpoints)
+	prometheus.MustRegister(planCurrentRecords)
+	prometheus.MustRegister(planChanges)
+	prometheus.MustRegister(skippedEndpoints)
 }
 
 // Controller is responsible for orchestrating the different components.
@@ -191,24 +248,112 @@ type Controller struct {
 	nextRunAt time.Time
 	// The nextRunAtMux is for atomic updating of nextRunAt
 	nextRunAtMux sync.Mutex
+	// runMux serializes the reconciliations and the rollbacks
+	runMux sync.Mutex
 	// MangedRecordTypes are DNS record types that will be considered for management.
 	ManagedRecordTypes []string
 	// ExcludeRecordTypes are DNS record types that will be excluded from management.
 	ExcludeRecordTypes []string
 	// MinEventSyncInterval is used as window for batching events
 	MinEventSyncInterval time.Duration
+	// DesiredState, when set, is updated with the desired endpoints on every reconciliation
+	DesiredState *DesiredState
+	// Warnings, when set, posts warnings about the resources with invalid, conflicting or filtered endpoints
+	Warnings *Warnings
+	// PublishedHostnames, when set, writes the hostnames published for every resource back to it
+	// once the changes are applied
+	PublishedHostnames *PublishedHostnames
+	// Provenance, when set, is updated with the desired endpoints, the records and the applied changes
+	// of every reconciliation, to explain the records of a DNS name
+	Provenance *Provenance
+	// Churn, when set, counts the applied changes of every record over a sliding window
+	Churn *Churn
+	// Snapshot, when set, stores the applied records to reconcile only the drift against them on startup
+	Snapshot SnapshotStore
+	// snapshotLoaded is whether the first reconciliation used the snapshot already
+	snapshotLoaded bool
+	// snapshotSaved is whether the snapshot was saved since startup
+	snapshotSaved bool
+	// GarbageCollector, when set, removes the orphaned ownership records of the registry every
+	// GarbageCollectionInterval, or only logs them with GarbageCollectionDryRun
+	GarbageCollector          registry.GarbageCollector
+	GarbageCollectionInterval time.Duration
+	GarbageCollectionDryRun   bool
+	// nextGarbageCollectionAt is when the garbage collection runs next
+	nextGarbageCollectionAt time.Time
+	// zones retries the changes of zones that failed to apply independently of the other zones
+	zones zoneQueue
+	// ZoneConcurrency is the maximum number of zones whose changes are applied at once, for the
+	// providers whose API permits it. One if not set.
+	ZoneConcurrency int
+	// FullSyncInterval, when set, is the interval between the reconciliations listing the records of
+	// the registry. The reconciliations in between, triggered by events or Interval, reconcile against
+	// the records applied by the previous one.
+	FullSyncInterval time.Duration
+	// appliedRecords are the records after the last reconciliation, reconciled against until the next
+	// full reconciliation
+	appliedRecords []*endpoint.Endpoint
+	// nextFullSyncAt is when the next full reconciliation is due
+	nextFullSyncAt time.Time
+	// DampeningWindow is how long the changed targets of a record must stay the same before they
+	// are applied, unless the resource sets its own window. Zero applies them right away.
+	DampeningWindow time.Duration
+	// dampener holds the changed targets of records until they are stable
+	dampener dampener
+	// MaintenanceWindows are the windows during which the changes are deferred, the records are
+	// still read and the changes applied by the first reconciliation after the windows
+	MaintenanceWindows []MaintenanceWindow
+	// ChangeWindows, when set, defers the changes of the records of domains until their change
+	// window opens
+	ChangeWindows *plan.ChangeWindowPolicy
+	// TTLStep, when set, lowers the TTL of the records before their targets change and restores
+	// it afterwards
+	TTLStep *plan.TTLStepPolicy
+	// Canary, when set, applies the changes of zones with a canary zone to it first, and to the zones
+	// themselves once they resolve in the canary zone
+	Canary *Canary
+	// History, when set, records the inverse of the changes applied by every sync, so they can be
+	// rolled back
+	History HistoryStore
+	// BatchSize, when set, is the maximum number of changes of a zone applied at once, the batches
+	// being applied BatchInterval apart, to throttle large syncs to the rate the provider accepts
+	BatchSize     int
+	BatchInterval time.Duration
+	// Pagination, when set and the provider of the registry lists its records in pages, reads the
+	// records a page at a time and applies the changes of every page once it is read, the pages
+	// failing to be read being retried as PageRetry configures. Snapshot, FullSyncInterval, DampeningWindow,
+	// Canary, History, Provenance and PublishedHostnames need all the records and are not used.
+	Pagination bool
+	PageRetry  provider.RetryConfig
 }
 
 // RunOnce runs a single iteration of a reconciliation loop.
 func (c *Controller) RunOnce(ctx context.Context) error {
+	c.runMux.Lock()
+	defer c.runMux.Unlock()
+	c.zones.concurrency = c.ZoneConcurrency
 	lastReconcileTimestamp.SetToCurrentTime()
+	// the trace ID ties the latencies of the requests to the provider to the reconciliation
+	traceID := provider.NewTraceID()
+	ctx = provider.WithTraceID(ctx, traceID)
+	log.Debugf("Reconciling with trace ID %s", traceID)
 
-	records, err := c.Registry.Records(ctx)
+	if pager, ok := c.recordsPager(); ok && c.Pagination {
+		return c.runPaged(ctx, pager)
+	}
+
+	records, full, err := c.currentRecords(ctx, time.Now())
 	if err != nil {
-		registryErrorsTotal.Inc()
-		deprecatedRegistryErrors.Inc()
 		return err
 	}
+	if c.Canary != nil {
+		records = c.Canary.split(records, full)
+	}
+	if full {
+		reconcilesTotal.WithLabelValues("full").Inc()
+	} else {
+		reconcilesTotal.WithLabelValues("incremental").Inc()
+	}
 
 	registryEndpointsTotal.Set(float64(len(records)))
 	regARecords, regAAAARecords := countAddressRecords(records)
@@ -216,12 +361,20 @@ func (c *Controller) RunOnce(ctx context.Context) error {
 	registryAAAARecords.Set(float64(regAAAARecords))
 	ctx = context.WithValue(ctx, provider.RecordsContextKey, records)
 
+	// the endpoints are activated and expired at the time before the sources read them, so that an
+	// endpoint a source publishes until a time is never dropped because that time passed since
+	sourcedAt := time.Now()
 	endpoints, err := c.Source.Endpoints(ctx)
 	if err != nil {
 		sourceErrorsTotal.Inc()
 		deprecatedSourceErrors.Inc()
 		return err
 	}
+	windows := dampeningWindows(endpoints)
+	endpoints, activeAt := activeEndpoints(endpoints, sourcedAt)
+	if !activeAt.IsZero() {
+		c.scheduleRetry(activeAt)
+	}
 	sourceEndpointsTotal.Set(float64(len(endpoints)))
 	srcARecords, srcAAAARecords := countAddressRecords(endpoints)
 	sourceARecords.Set(float64(srcARecords))
@@ -234,33 +387,225 @@ func (c *Controller) RunOnce(ctx context.Context) error {
 		return fmt.Errorf("adjusting endpoints: %w", err)
 	}
 	registryFilter := c.Registry.GetDomainFilter()
+	domainFilter := endpoint.MatchAllDomainFilters{&c.DomainFilter, &registryFilter}
+	zones := c.zoneNames(ctx, registryFilter)
+	if c.DesiredState != nil {
+		c.DesiredState.update(endpoints, domainFilter)
+	}
+	if c.Warnings != nil {
+		c.Warnings.check(endpoints, domainFilter, time.Now())
+	}
+	if c.Provenance != nil {
+		c.Provenance.update(endpoints, records, zones, domainFilter, c.ManagedRecordTypes, c.ExcludeRecordTypes, c.Registry.OwnerID())
+	}
+	endpoints, stableAt := c.dampener.dampen(endpoints, records, windows, c.DampeningWindow, time.Now())
+	if !stableAt.IsZero() {
+		c.scheduleRetry(stableAt)
+	}
 
 	plan := &plan.Plan{
-		Policies:       []plan.Policy{c.Policy},
-		Current:        records,
-		Desired:        endpoints,
-		DomainFilter:   endpoint.MatchAllDomainFilters{&c.DomainFilter, &registryFilter},
-		ManagedRecords: c.ManagedRecordTypes,
-		ExcludeRecords: c.ExcludeRecordTypes,
-		OwnerID:        c.Registry.OwnerID(),
+		Policies:           c.policies(),
+		Current:            records,
+		Desired:            endpoints,
+		DomainFilter:       domainFilter,
+		ManagedRecords:     c.ManagedRecordTypes,
+		ExcludeRecords:     c.ExcludeRecordTypes,
+		OwnerID:            c.Registry.OwnerID(),
+		PropertyComparator: c.Registry.PropertyValuesEqual,
 	}
 
 	plan = plan.Calculate()
+	observePlan(plan)
+	if c.ChangeWindows != nil {
+		if opensAt := c.ChangeWindows.NextOpening(); !opensAt.IsZero() {
+			c.scheduleRetry(opensAt)
+		}
+	}
+	if c.TTLStep != nil {
+		if stepAt := c.TTLStep.NextStep(); !stepAt.IsZero() {
+			c.scheduleRetry(stepAt)
+		}
+	}
 
-	if plan.Changes.HasChanges() {
-		err = c.Registry.ApplyChanges(ctx, plan.Changes)
+	pending := false
+	maintenanceEnd := c.maintenanceWindowEnd(time.Now())
+	if plan.Changes.HasChanges() && !maintenanceEnd.IsZero() {
+		log.Infof("Deferring %d changes until the end of the maintenance window at %s", len(plan.Changes.Create)+len(plan.Changes.UpdateNew)+len(plan.Changes.Delete), maintenanceEnd.Format(time.RFC3339))
+		c.scheduleRetry(maintenanceEnd)
+		pending = true
+	} else if plan.Changes.HasChanges() {
+		apply, applied := c.collectingApply()
+		var anyApplied bool
+		pending, anyApplied, err = c.zones.apply(ctx, time.Now(), splitChangesByZone(plan.Changes, zones), apply)
+		c.recordHistory(applied, time.Now())
+		if c.Provenance != nil {
+			c.Provenance.recordApplied(applied, time.Now())
+		}
+		if c.Churn != nil {
+			c.Churn.record(applied, zones, time.Now())
+		}
+		if next := c.zones.nextRetry(); !next.IsZero() {
+			c.scheduleRetry(next)
+		}
 		if err != nil {
-			registryErrorsTotal.Inc()
-			deprecatedRegistryErrors.Inc()
-			return err
+			// The records of the failed zones are unknown until the next full reconciliation
+			c.appliedRecords = nil
+			if !anyApplied {
+				// Nothing could be applied, the errors are as fatal as they were for a single zone.
+				return fmt.Errorf("failed to apply changes: %w", err)
+			}
+			// The failed zones are retried with backoff, the process must not exit.
+			return provider.NewSoftError(fmt.Errorf("failed to apply changes: %w", err))
 		}
 	} else {
+		c.zones.reset()
 		controllerNoChangesTotal.Inc()
 		log.Info("All records are already up to date")
 	}
+	if c.Churn != nil {
+		c.Churn.observe(time.Now())
+	}
+
+	if !pending {
+		lastSyncTimestamp.SetToCurrentTime()
+		if full {
+			lastFullSyncTimestamp.SetToCurrentTime()
+		}
+		if c.FullSyncInterval > 0 {
+			c.appliedRecords = appliedRecords(records, plan.Changes)
+		}
+		if c.PublishedHostnames != nil {
+			c.PublishedHostnames.update(ctx, appliedRecords(records, plan.Changes), plan.Changes, c.Registry.OwnerID())
+		}
+		if c.Snapshot != nil && (plan.Changes.HasChanges() || !c.snapshotSaved) {
+			if err := c.Snapshot.Save(appliedRecords(records, plan.Changes)); err != nil {
+				log.Warnf("Failed to save the snapshot of the records: %v", err)
+			} else {
+				c.snapshotSaved = true
+			}
+		}
+	}
 
-	lastSyncTimestamp.SetToCurrentTime()
+	return nil
+}
+
+// Exclusive returns the lock held by the reconciliations and the rollbacks, for the writers of
+// the registry running next to the controller, which isn't safe for concurrent use.
+func (c *Controller) Exclusive() sync.Locker {
+	return &c.runMux
+}
+
+// zoneNames returns the names of the zones the changes are split by: the zones of the provider when
+// it lists them, and the domain filters otherwise.
+func (c *Controller) zoneNames(ctx context.Context, registryFilter endpoint.DomainFilter) []string {
+	zones, err := provider.ZoneNames(ctx, c.Registry)
+	if err == nil {
+		return zones
+	}
+	if !errors.Is(err, provider.ErrZonesNotListed) {
+		log.Warnf("Failed to list the zones of the provider, splitting the changes by the domain filters: %v", err)
+	}
+	return append(append([]string{}, c.DomainFilter.Filters...), registryFilter.Filters...)
+}
+
+// policies returns the policies the changes of the plan are subject to.
+func (c *Controller) policies() []plan.Policy {
+	policies := []plan.Policy{c.Policy}
+	if c.TTLStep != nil {
+		policies = append(policies, c.TTLStep)
+	}
+	if c.ChangeWindows != nil {
+		policies = append(policies, c.ChangeWindows)
+	}
+	return policies
+}
+
+// observePlan updates the metrics of the sizes of the plan and of the endpoints it skipped.
+func observePlan(p *plan.Plan) {
+	planDesiredEndpoints.Set(float64(len(p.Desired)))
+	planCurrentRecords.Set(float64(len(p.Current)))
+	planChanges.WithLabelValues("create").Set(float64(len(p.Changes.Create)))
+	planChanges.WithLabelValues("update").Set(float64(len(p.Changes.UpdateNew)))
+	planChanges.WithLabelValues("delete").Set(float64(len(p.Changes.Delete)))
+	for _, reason := range []string{plan.SkipReasonDomainFilter, plan.SkipReasonUnsupportedType, plan.SkipReasonOwnershipConflict} {
+		skippedEndpoints.WithLabelValues(reason).Set(float64(p.Skipped[reason]))
+	}
+}
+
+// currentRecords returns the records of the registry, and whether they were listed from it. The
+// first reconciliation with a snapshot returns the records of the snapshot instead, so a restart
+// only reconciles the drift against the last applied records, and schedules a full reconciliation
+// right after it. With a FullSyncInterval, the records applied by the previous reconciliation are
+// returned until the next full reconciliation is due.
+func (c *Controller) currentRecords(ctx context.Context, now time.Time) ([]*endpoint.Endpoint, bool, error) {
+	if c.Snapshot != nil && !c.snapshotLoaded {
+		c.snapshotLoaded = true
+		records, err := c.Snapshot.Load()
+		if err != nil {
+			log.Warnf("Ignoring the snapshot of the records: %v", err)
+		} else if len(records) > 0 {
+			log.Infof("Reconciling the drift against a snapshot of %d records, a full reconciliation follows", len(records))
+			c.scheduleRetry(now)
+			return records, false, nil
+		}
+	}
+
+	if c.FullSyncInterval > 0 && c.appliedRecords != nil && now.Before(c.nextFullSyncAt) {
+		log.Debugf("Reconciling against the %d records applied last, a full reconciliation follows at %s", len(c.appliedRecords), c.nextFullSyncAt.Format(time.RFC3339))
+		c.scheduleRetry(c.nextFullSyncAt)
+		return c.appliedRecords, false, nil
+	}
+
+	records, err := c.Registry.Records(ctx)
+	if err != nil {
+		registryErrorsTotal.Inc()
+		deprecatedRegistryErrors.Inc()
+		return nil, false, err
+	}
+	if c.FullSyncInterval > 0 {
+		c.nextFullSyncAt = now.Add(c.FullSyncInterval)
+		c.scheduleRetry(c.nextFullSyncAt)
+	}
+	return records, true, nil
+}
+
+// applyBatches applies the changes in batches of BatchSize changes, waiting BatchInterval between
+// them. It stops at the first batch that fails to apply.
+func (c *Controller) applyBatches(ctx context.Context, changes *plan.Changes, apply func(context.Context, *plan.Changes) error) error {
+	batches := changes.Batches(c.BatchSize)
+	for i, batch := range batches {
+		if i > 0 && c.BatchInterval > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(c.BatchInterval):
+			}
+		}
+		if len(batches) > 1 {
+			log.Infof("Applying batch %d of %d with %d changes", i+1, len(batches), batch.Len())
+		}
+		if err := apply(ctx, batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
+// applyZoneChanges applies the changes of a single zone.
+func (c *Controller) applyZoneChanges(ctx context.Context, changes *plan.Changes) error {
+	if c.Canary != nil {
+		return c.Canary.apply(ctx, changes, c.applyRegistryChanges)
+	}
+	return c.applyRegistryChanges(ctx, changes)
+}
+
+// applyRegistryChanges applies the changes to the registry.
+func (c *Controller) applyRegistryChanges(ctx context.Context, changes *plan.Changes) error {
+	if err := c.Registry.ApplyChanges(ctx, changes); err != nil {
+		registryErrorsTotal.Inc()
+		deprecatedRegistryErrors.Inc()
+		return err
+	}
 	return nil
 }
 
@@ -315,6 +660,16 @@ func (c *Controller) ScheduleRunOnce(now time.Time) {
 	}
 }
 
+// scheduleRetry makes sure a reconciliation happens at the latest at the given time, to retry the
+// changes of failed zones before the next interval.
+func (c *Controller) scheduleRetry(at time.Time) {
+	c.nextRunAtMux.Lock()
+	defer c.nextRunAtMux.Unlock()
+	if at.Before(c.nextRunAt) {
+		c.nextRunAt = at
+	}
+}
+
 func (c *Controller) ShouldRunOnce(now time.Time) bool {
 	c.nextRunAtMux.Lock()
 	defer c.nextRunAtMux.Unlock()
@@ -325,6 +680,33 @@ func (c *Controller) ShouldRunOnce(now time.Time) bool {
 	return true
 }
 
+// collectGarbage removes the orphaned ownership records and creates the missing ones when the garbage
+// collection is due. It holds the lock of the reconciliations, so it never sees the records of a change
+// being applied, by them or by the other writers of the registry, and it's postponed until the end of the
+// maintenance windows.
+func (c *Controller) collectGarbage(ctx context.Context, now time.Time) {
+	if c.GarbageCollector == nil || c.GarbageCollectionInterval <= 0 || now.Before(c.nextGarbageCollectionAt) {
+		return
+	}
+
+	c.runMux.Lock()
+	defer c.runMux.Unlock()
+	if !c.maintenanceWindowEnd(now).IsZero() {
+		return
+	}
+	c.nextGarbageCollectionAt = now.Add(c.GarbageCollectionInterval)
+
+	repaired, err := c.GarbageCollector.CollectGarbage(ctx, c.GarbageCollectionDryRun)
+	if err != nil {
+		registryErrorsTotal.Inc()
+		log.Errorf("Failed to collect the orphaned and missing ownership records: %v", err)
+		return
+	}
+	if repaired > 0 {
+		log.Infof("Collected %d orphaned and missing ownership records", repaired)
+	}
+}
+
 // Run runs RunOnce in a loop with a delay until context is canceled
 func (c *Controller) Run(ctx context.Context) {
 	ticker := time.NewTicker(time.Second)
@@ -339,6 +721,7 @@ func (c *Controller) Run(ctx context.Context) {
 				}
 			}
 		}
+		c.collectGarbage(ctx, time.Now())
 		select {
 		case <-ticker.C:
 		case <-ctx.Done():
@@ -229,6 +229,28 @@ func valueFromMetric(metric prometheus.Gauge) uint64 {
 	return reflect.Indirect(ref).FieldByName("valBits").Uint()
 }
 
+func TestObservePlan(t *testing.T) {
+	observePlan(&plan.Plan{
+		Current: []*endpoint.Endpoint{endpoint.NewEndpoint("a.example.org", endpoint.RecordTypeA, "1.1.1.1")},
+		Desired: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("a.example.org", endpoint.RecordTypeA, "2.2.2.2"),
+			endpoint.NewEndpoint("b.example.org", endpoint.RecordTypeMX, "10 mail.example.org"),
+		},
+		Changes: &plan.Changes{
+			UpdateOld: []*endpoint.Endpoint{endpoint.NewEndpoint("a.example.org", endpoint.RecordTypeA, "1.1.1.1")},
+			UpdateNew: []*endpoint.Endpoint{endpoint.NewEndpoint("a.example.org", endpoint.RecordTypeA, "2.2.2.2")},
+		},
+		Skipped: map[string]int{plan.SkipReasonUnsupportedType: 1},
+	})
+
+	assert.Equal(t, math.Float64bits(2), valueFromMetric(planDesiredEndpoints))
+	assert.Equal(t, math.Float64bits(1), valueFromMetric(planCurrentRecords))
+	assert.Equal(t, math.Float64bits(0), valueFromMetric(planChanges.WithLabelValues("create")))
+	assert.Equal(t, math.Float64bits(1), valueFromMetric(planChanges.WithLabelValues("update")))
+	assert.Equal(t, math.Float64bits(1), valueFromMetric(skippedEndpoints.WithLabelValues(plan.SkipReasonUnsupportedType)))
+	assert.Equal(t, math.Float64bits(0), valueFromMetric(skippedEndpoints.WithLabelValues(plan.SkipReasonOwnershipConflict)))
+}
+
 func TestShouldRunOnce(t *testing.T) {
 	ctrl := &Controller{Interval: 10 * time.Minute, MinEventSyncInterval: 5 * time.Second}
 
@@ -279,6 +301,43 @@ func TestShouldRunOnce(t *testing.T) {
 	assert.True(t, ctrl.ShouldRunOnce(now))
 }
 
+func TestRunOnceWithFullSyncInterval(t *testing.T) {
+	a := endpoint.NewEndpoint("a.example.org", endpoint.RecordTypeA, "1.1.1.1")
+	b := endpoint.NewEndpoint("b.example.org", endpoint.RecordTypeA, "2.2.2.2")
+
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return([]*endpoint.Endpoint{a, b}, nil)
+	provider := &filteredMockProvider{RecordsStore: []*endpoint.Endpoint{a}}
+	r, err := registry.NewNoopRegistry(provider)
+	require.NoError(t, err)
+
+	ctrl := &Controller{
+		Source:             source,
+		Registry:           r,
+		Policy:             &plan.SyncPolicy{},
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+		Interval:           time.Minute,
+		FullSyncInterval:   time.Hour,
+	}
+
+	// the first reconciliation lists the records
+	require.NoError(t, ctrl.RunOnce(context.Background()))
+	assert.Equal(t, 1, provider.RecordsCallCount)
+	require.Len(t, provider.ApplyChangesCalls, 1)
+	assert.Equal(t, []*endpoint.Endpoint{b}, provider.ApplyChangesCalls[0].Create)
+
+	// the following reconciliations use the records applied last
+	require.NoError(t, ctrl.RunOnce(context.Background()))
+	assert.Equal(t, 1, provider.RecordsCallCount)
+	assert.Len(t, provider.ApplyChangesCalls, 1)
+
+	// until a full reconciliation is due
+	ctrl.nextFullSyncAt = time.Now()
+	require.NoError(t, ctrl.RunOnce(context.Background()))
+	assert.Equal(t, 2, provider.RecordsCallCount)
+	assert.Len(t, provider.ApplyChangesCalls, 2)
+}
+
 func testControllerFiltersDomains(t *testing.T, configuredEndpoints []*endpoint.Endpoint, domainFilter endpoint.DomainFilter, providerEndpoints []*endpoint.Endpoint, expectedChanges []*plan.Changes) {
 	t.Helper()
 	cfg := externaldns.NewConfig()
@@ -412,6 +471,8 @@ func TestWhenMultipleControllerConsidersAllFilteredComain(t *testing.T) {
 						Targets:    endpoint.Targets{"1.2.3.4"},
 					},
 				},
+			},
+			{
 				UpdateOld: []*endpoint.Endpoint{
 					{
 						DNSName:    "some-record.used.tld",
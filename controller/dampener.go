@@ -0,0 +1,132 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/source"
+)
+
+var dampenedRecords = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "external_dns",
+		Subsystem: "controller",
+		Name:      "dampened_records",
+		Help:      "Number of records whose changed targets are held until they are stable.",
+	},
+)
+
+func init() {
+	prometheus.MustRegister(dampenedRecords)
+}
+
+// pendingTargets are the changed targets of a record, not applied until they are stable.
+type pendingTargets struct {
+	targets endpoint.Targets
+	since   time.Time
+}
+
+// dampener holds the changes of the targets of records until the targets didn't change for a
+// window, so targets flapping faster than the window, e.g. reassigned load balancer addresses,
+// don't cause an update of the provider on every reconciliation. Records being created or deleted
+// are never held.
+type dampener struct {
+	pending map[endpoint.EndpointKey]*pendingTargets
+}
+
+// dampeningWindows removes the dampening window property of the endpoints, which is only read by
+// the controller, and returns the windows of the endpoints having one.
+func dampeningWindows(endpoints []*endpoint.Endpoint) map[endpoint.EndpointKey]time.Duration {
+	windows := map[endpoint.EndpointKey]time.Duration{}
+	for _, ep := range endpoints {
+		value, ok := ep.GetProviderSpecificProperty(source.DampeningWindowKey)
+		if !ok {
+			continue
+		}
+		// the properties are shared by the endpoints of a resource, so they are copied
+		properties := make(endpoint.ProviderSpecific, 0, len(ep.ProviderSpecific)-1)
+		for _, p := range ep.ProviderSpecific {
+			if p.Name != source.DampeningWindowKey {
+				properties = append(properties, p)
+			}
+		}
+		ep.ProviderSpecific = properties
+
+		window, err := time.ParseDuration(value)
+		if err != nil {
+			log.Warnf("Ignoring the invalid dampening window %q of %s: %v", value, ep.DNSName, err)
+			continue
+		}
+		windows[ep.Key()] = window
+	}
+	return windows
+}
+
+// dampen returns the desired endpoints with the targets of the current records when their desired
+// targets didn't stay the same for the window of the endpoint, or the default window. It also
+// returns when the first held targets become stable, zero if no targets are held.
+func (d *dampener) dampen(desired, current []*endpoint.Endpoint, windows map[endpoint.EndpointKey]time.Duration, defaultWindow time.Duration, now time.Time) ([]*endpoint.Endpoint, time.Time) {
+	records := make(map[endpoint.EndpointKey]*endpoint.Endpoint, len(current))
+	for _, r := range current {
+		records[r.Key()] = r
+	}
+
+	pending := make(map[endpoint.EndpointKey]*pendingTargets)
+	var next time.Time
+	dampened := make([]*endpoint.Endpoint, 0, len(desired))
+	for _, ep := range desired {
+		key := ep.Key()
+		window, ok := windows[key]
+		if !ok {
+			window = defaultWindow
+		}
+		record, exists := records[key]
+		if window <= 0 || !exists || record.Targets.Same(ep.Targets) {
+			dampened = append(dampened, ep)
+			continue
+		}
+
+		p, ok := d.pending[key]
+		if !ok || !p.targets.Same(ep.Targets) {
+			p = &pendingTargets{targets: ep.Targets, since: now}
+		}
+		stableAt := p.since.Add(window)
+		if !now.Before(stableAt) {
+			dampened = append(dampened, ep)
+			continue
+		}
+
+		log.Debugf("Holding the targets %v of %s %s until %s, keeping %v", ep.Targets, ep.DNSName, ep.RecordType, stableAt.Format(time.RFC3339), record.Targets)
+		pending[key] = p
+		if next.IsZero() || stableAt.Before(next) {
+			next = stableAt
+		}
+		held := ep.DeepCopy()
+		held.Targets = append(endpoint.Targets{}, record.Targets...)
+		dampened = append(dampened, held)
+	}
+
+	// the targets of records that are no longer changed or desired are forgotten
+	d.pending = pending
+	dampenedRecords.Set(float64(len(pending)))
+	return dampened, next
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/source"
+)
+
+func TestDampeningWindows(t *testing.T) {
+	properties := endpoint.ProviderSpecific{
+		{Name: source.DampeningWindowKey, Value: "1m"},
+		{Name: "alias", Value: "true"},
+	}
+	a := endpoint.NewEndpoint("a.example.org", endpoint.RecordTypeA, "1.1.1.1")
+	a.ProviderSpecific = properties
+	invalid := endpoint.NewEndpoint("b.example.org", endpoint.RecordTypeA, "1.1.1.1")
+	invalid.ProviderSpecific = endpoint.ProviderSpecific{{Name: source.DampeningWindowKey, Value: "soon"}}
+	none := endpoint.NewEndpoint("c.example.org", endpoint.RecordTypeA, "1.1.1.1")
+
+	windows := dampeningWindows([]*endpoint.Endpoint{a, invalid, none})
+
+	assert.Equal(t, map[endpoint.EndpointKey]time.Duration{a.Key(): time.Minute}, windows)
+	assert.Equal(t, endpoint.ProviderSpecific{{Name: "alias", Value: "true"}}, a.ProviderSpecific)
+	assert.Empty(t, invalid.ProviderSpecific)
+	// the properties shared with other endpoints are left unchanged
+	assert.Len(t, properties, 2)
+}
+
+func TestDampen(t *testing.T) {
+	d := &dampener{}
+	now := time.Now()
+	current := []*endpoint.Endpoint{endpoint.NewEndpoint("a.example.org", endpoint.RecordTypeA, "1.1.1.1")}
+	desired := func(target string) []*endpoint.Endpoint {
+		return []*endpoint.Endpoint{
+			endpoint.NewEndpoint("a.example.org", endpoint.RecordTypeA, target),
+			endpoint.NewEndpoint("new.example.org", endpoint.RecordTypeA, target),
+		}
+	}
+
+	// the changed targets are held, the new record is created right away
+	eps, next := d.dampen(desired("2.2.2.2"), current, nil, time.Minute, now)
+	assert.Equal(t, endpoint.Targets{"1.1.1.1"}, eps[0].Targets)
+	assert.Equal(t, endpoint.Targets{"2.2.2.2"}, eps[1].Targets)
+	assert.Equal(t, now.Add(time.Minute), next)
+
+	// flapping targets restart the window
+	eps, next = d.dampen(desired("3.3.3.3"), current, nil, time.Minute, now.Add(30*time.Second))
+	assert.Equal(t, endpoint.Targets{"1.1.1.1"}, eps[0].Targets)
+	assert.Equal(t, now.Add(90*time.Second), next)
+
+	// stable targets are applied
+	eps, next = d.dampen(desired("3.3.3.3"), current, nil, time.Minute, now.Add(90*time.Second))
+	assert.Equal(t, endpoint.Targets{"3.3.3.3"}, eps[0].Targets)
+	assert.True(t, next.IsZero())
+	assert.Empty(t, d.pending)
+
+	// the window of the endpoint overrides the default window
+	windows := map[endpoint.EndpointKey]time.Duration{current[0].Key(): 0}
+	eps, next = d.dampen(desired("4.4.4.4"), current, windows, time.Minute, now)
+	assert.Equal(t, endpoint.Targets{"4.4.4.4"}, eps[0].Targets)
+	assert.True(t, next.IsZero())
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// DesiredState holds the endpoints the last reconciliation wanted to exist, so they can be
+// inspected while debugging, independently of the records of the provider.
+type DesiredState struct {
+	mu sync.RWMutex
+	// endpoints are indexed by their lower case DNS name without the trailing dot
+	endpoints map[string][]*endpoint.Endpoint
+}
+
+// NewDesiredState returns a desired state without endpoints.
+func NewDesiredState() *DesiredState {
+	return &DesiredState{endpoints: map[string][]*endpoint.Endpoint{}}
+}
+
+// Lookup returns the desired endpoints of the DNS name.
+func (s *DesiredState) Lookup(name string) []*endpoint.Endpoint {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.endpoints[desiredStateKey(name)]
+}
+
+// update replaces the desired endpoints by the ones matching the domain filter.
+func (s *DesiredState) update(endpoints []*endpoint.Endpoint, filter endpoint.MatchAllDomainFilters) {
+	indexed := make(map[string][]*endpoint.Endpoint, len(endpoints))
+	for _, e := range endpoints {
+		if !filter.Match(e.DNSName) {
+			continue
+		}
+		key := desiredStateKey(e.DNSName)
+		indexed[key] = append(indexed[key], e.DeepCopy())
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.endpoints = indexed
+}
+
+func desiredStateKey(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, "."))
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func TestDesiredState(t *testing.T) {
+	s := NewDesiredState()
+	filter := endpoint.NewDomainFilter([]string{"example.org"})
+
+	a := endpoint.NewEndpoint("a.example.org", endpoint.RecordTypeA, "1.1.1.1")
+	txt := endpoint.NewEndpoint("a.example.org", endpoint.RecordTypeTXT, "text")
+	filtered := endpoint.NewEndpoint("a.example.com", endpoint.RecordTypeA, "1.1.1.1")
+	s.update([]*endpoint.Endpoint{a, txt, filtered}, endpoint.MatchAllDomainFilters{&filter})
+
+	assert.Equal(t, []*endpoint.Endpoint{a, txt}, s.Lookup("A.example.org."))
+	assert.Empty(t, s.Lookup("a.example.com"))
+
+	// the endpoints of the last reconciliation replace the previous ones
+	s.update([]*endpoint.Endpoint{txt}, endpoint.MatchAllDomainFilters{&filter})
+	assert.Equal(t, []*endpoint.Endpoint{txt}, s.Lookup("a.example.org"))
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+// resourceKind is a kind of resource whose records get Events and published hostnames.
+type resourceKind struct {
+	ref corev1.ObjectReference
+	gvr schema.GroupVersionResource
+}
+
+// resourceKinds are the kinds of resources whose records get Events and published hostnames, by
+// the kind in their resource label.
+var resourceKinds = map[string]resourceKind{
+	"service": {
+		ref: corev1.ObjectReference{APIVersion: "v1", Kind: "Service"},
+		gvr: schema.GroupVersionResource{Version: "v1", Resource: "services"},
+	},
+	"ingress": {
+		ref: corev1.ObjectReference{APIVersion: "networking.k8s.io/v1", Kind: "Ingress"},
+		gvr: schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"},
+	},
+	"crd": {
+		ref: corev1.ObjectReference{APIVersion: "externaldns.k8s.io/v1alpha1", Kind: "DNSEndpoint"},
+		gvr: schema.GroupVersionResource{Group: "externaldns.k8s.io", Version: "v1alpha1", Resource: "dnsendpoints"},
+	},
+}
+
+// parseResource returns the kind, namespace and name of the resource label <kind>/<namespace>/<name>
+// of records, false for the labels of other kinds of resources.
+func parseResource(resource string) (kind resourceKind, namespace string, name string, ok bool) {
+	parts := strings.SplitN(resource, "/", 3)
+	if len(parts) != 3 {
+		return resourceKind{}, "", "", false
+	}
+	kind, ok = resourceKinds[parts[0]]
+	return kind, parts[1], parts[2], ok
+}
+
+// ResourceEventRecorder records an Event on a resource given by the resource label of its records.
+type ResourceEventRecorder func(resource, eventType, reason, message string)
+
+// NewResourceEventRecorder returns a ResourceEventRecorder posting the Events to the API server.
+// Only services, ingresses and DNSEndpoints get Events.
+func NewResourceEventRecorder(kubeClient kubernetes.Interface) ResourceEventRecorder {
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeClient.CoreV1().Events("")})
+	return newResourceEventRecorder(broadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: "external-dns"}))
+}
+
+func newResourceEventRecorder(recorder record.EventRecorder) ResourceEventRecorder {
+	return func(resource, eventType, reason, message string) {
+		kind, namespace, name, ok := parseResource(resource)
+		if !ok {
+			return
+		}
+		ref := kind.ref
+		ref.Namespace, ref.Name = namespace, name
+		recorder.Event(&ref, eventType, reason, message)
+	}
+}
+
+// DeferredChangeRecorder returns a function recording an Event on the resource of a record whose
+// change is deferred by its change window.
+func DeferredChangeRecorder(recordEvent ResourceEventRecorder) func(plan.Change, time.Time) {
+	return func(change plan.Change, opensAt time.Time) {
+		ep := change.Record()
+		recordEvent(ep.Labels[endpoint.ResourceLabelKey], corev1.EventTypeNormal, "ChangeDeferred", fmt.Sprintf("The change %s of the %s record %s is deferred until its change window opens at %s", change.ID, ep.RecordType, ep.DNSName, opensAt.Format(time.RFC3339)))
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+// refRecorder records the objects of the Events besides the Events.
+type refRecorder struct {
+	*record.FakeRecorder
+	refs []corev1.ObjectReference
+}
+
+func (r *refRecorder) Event(object runtime.Object, eventType, reason, message string) {
+	r.refs = append(r.refs, *object.(*corev1.ObjectReference))
+	r.FakeRecorder.Event(object, eventType, reason, message)
+}
+
+func TestResourceEventRecorder(t *testing.T) {
+	recorder := &refRecorder{FakeRecorder: record.NewFakeRecorder(10)}
+	recordEvent := newResourceEventRecorder(recorder)
+
+	recordEvent("service/default/app", corev1.EventTypeWarning, "Reason", "service message")
+	recordEvent("ingress/web/app", corev1.EventTypeWarning, "Reason", "ingress message")
+	recordEvent("crd/default/records", corev1.EventTypeNormal, "Reason", "crd message")
+	// neither other kinds of resources nor malformed labels get Events
+	recordEvent("pod/default/app", corev1.EventTypeWarning, "Reason", "pod message")
+	recordEvent("service/app", corev1.EventTypeWarning, "Reason", "malformed message")
+	recordEvent("", corev1.EventTypeWarning, "Reason", "unlabeled message")
+
+	assert.Equal(t, []corev1.ObjectReference{
+		{APIVersion: "v1", Kind: "Service", Namespace: "default", Name: "app"},
+		{APIVersion: "networking.k8s.io/v1", Kind: "Ingress", Namespace: "web", Name: "app"},
+		{APIVersion: "externaldns.k8s.io/v1alpha1", Kind: "DNSEndpoint", Namespace: "default", Name: "records"},
+	}, recorder.refs)
+	assert.Equal(t, "Warning Reason service message", <-recorder.Events)
+	assert.Equal(t, "Warning Reason ingress message", <-recorder.Events)
+	assert.Equal(t, "Normal Reason crd message", <-recorder.Events)
+	assert.Empty(t, recorder.Events)
+}
+
+func TestDeferredChangeRecorder(t *testing.T) {
+	var resources, messages []string
+	onDeferred := DeferredChangeRecorder(func(resource, eventType, reason, message string) {
+		assert.Equal(t, corev1.EventTypeNormal, eventType)
+		assert.Equal(t, "ChangeDeferred", reason)
+		resources = append(resources, resource)
+		messages = append(messages, message)
+	})
+
+	ep := resourceEndpoint("ingress/default/app", "app.example.org", endpoint.RecordTypeA, "1.2.3.4")
+	change := plan.NewChange(plan.ActionCreate, nil, ep)
+	onDeferred(change, time.Date(2024, 3, 1, 22, 0, 0, 0, time.UTC))
+
+	assert.Equal(t, []string{"ingress/default/app"}, resources)
+	assert.Equal(t, []string{"The change " + change.ID + " of the A record app.example.org is deferred until its change window opens at 2024-03-01T22:00:00Z"}, messages)
+}
@@ -0,0 +1,224 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+// ExplainPath is the path of the handler of the explanations on the metrics address.
+const ExplainPath = "/debug/explain"
+
+// Explanation describes where the records of a DNS name come from and what the last
+// reconciliation did with them.
+type Explanation struct {
+	Name string `json:"name"`
+	// Zone is the most specific domain of the domain filters containing the name
+	Zone    string            `json:"zone,omitempty"`
+	Records []ExplainedRecord `json:"records"`
+}
+
+// ExplainedRecord is a record of an explained DNS name, desired by the sources, existing in the
+// provider, or both.
+type ExplainedRecord struct {
+	RecordType    string           `json:"recordType"`
+	SetIdentifier string           `json:"setIdentifier,omitempty"`
+	Targets       endpoint.Targets `json:"targets"`
+	RecordTTL     endpoint.TTL     `json:"recordTTL,omitempty"`
+	// Resource is the source object the desired record comes from, e.g. service/default/app
+	Resource string `json:"resource,omitempty"`
+	// Labels are the ownership labels of the record in the registry
+	Labels  endpoint.Labels `json:"labels,omitempty"`
+	Desired bool            `json:"desired"`
+	Exists  bool            `json:"exists"`
+	// LastApplied is the last time a change of the record was applied by this instance
+	LastApplied *time.Time `json:"lastApplied,omitempty"`
+	// SkipReason is why a desired record is not applied, one of the plan.SkipReason constants
+	SkipReason string `json:"skipReason,omitempty"`
+}
+
+// Provenance holds the desired endpoints and the records of the last reconciliation, and when
+// the changes of the records were last applied, to explain the records of a DNS name.
+type Provenance struct {
+	mu sync.RWMutex
+	// the maps are indexed by the lower case DNS name without the trailing dot
+	desired map[string][]*endpoint.Endpoint
+	current map[string][]*endpoint.Endpoint
+	applied map[string]map[endpoint.EndpointKey]time.Time
+	zones   provider.ZoneIDName
+
+	domainFilter   endpoint.MatchAllDomainFilters
+	managedRecords []string
+	excludeRecords []string
+	ownerID        string
+}
+
+// NewProvenance returns a provenance without endpoints.
+func NewProvenance() *Provenance {
+	return &Provenance{
+		desired: map[string][]*endpoint.Endpoint{},
+		current: map[string][]*endpoint.Endpoint{},
+		applied: map[string]map[endpoint.EndpointKey]time.Time{},
+		zones:   provider.ZoneIDName{},
+	}
+}
+
+// update replaces the desired endpoints and the records by the ones of a reconciliation, and
+// forgets when the changes of the names without any of them were applied.
+func (p *Provenance) update(desired, current []*endpoint.Endpoint, zones []string, domainFilter endpoint.MatchAllDomainFilters, managedRecords, excludeRecords []string, ownerID string) {
+	zoneNames := provider.ZoneIDName{}
+	for _, zone := range zones {
+		zone = strings.Trim(zone, ".")
+		if zone != "" {
+			zoneNames.Add(zone, zone)
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.desired = indexByName(desired)
+	p.current = indexByName(current)
+	p.zones = zoneNames
+	p.domainFilter = domainFilter
+	p.managedRecords = managedRecords
+	p.excludeRecords = excludeRecords
+	p.ownerID = ownerID
+	for name := range p.applied {
+		if _, ok := p.desired[name]; ok {
+			continue
+		}
+		if _, ok := p.current[name]; !ok {
+			delete(p.applied, name)
+		}
+	}
+}
+
+// recordApplied records when the created and updated records were applied.
+func (p *Provenance) recordApplied(changes *plan.Changes, at time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, e := range append(append([]*endpoint.Endpoint{}, changes.Create...), changes.UpdateNew...) {
+		name := desiredStateKey(e.DNSName)
+		if p.applied[name] == nil {
+			p.applied[name] = map[endpoint.EndpointKey]time.Time{}
+		}
+		p.applied[name][recordKey(e)] = at
+	}
+}
+
+// Explain returns the explanation of the records of the DNS name.
+func (p *Provenance) Explain(name string) *Explanation {
+	name = desiredStateKey(name)
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	explanation := &Explanation{Name: name, Records: []ExplainedRecord{}}
+	_, explanation.Zone = p.zones.FindZone(name)
+
+	records := map[endpoint.EndpointKey]*ExplainedRecord{}
+	get := func(e *endpoint.Endpoint) *ExplainedRecord {
+		key := recordKey(e)
+		if records[key] == nil {
+			records[key] = &ExplainedRecord{RecordType: e.RecordType, SetIdentifier: e.SetIdentifier}
+			if at, ok := p.applied[name][key]; ok {
+				records[key].LastApplied = &at
+			}
+		}
+		return records[key]
+	}
+
+	conflict := false
+	for _, e := range p.current[name] {
+		record := get(e)
+		record.Exists = true
+		record.Targets = e.Targets
+		record.RecordTTL = e.RecordTTL
+		record.Labels = e.Labels
+		record.Resource = e.Labels[endpoint.ResourceLabelKey]
+		if p.ownerID != "" && !e.IsOwnedBy(p.ownerID) {
+			conflict = true
+		}
+	}
+	for _, e := range p.desired[name] {
+		record := get(e)
+		record.Desired = true
+		record.Targets = e.Targets
+		record.RecordTTL = e.RecordTTL
+		if resource := e.Labels[endpoint.ResourceLabelKey]; resource != "" {
+			record.Resource = resource
+		}
+		switch {
+		case !p.domainFilter.Match(e.DNSName):
+			record.SkipReason = plan.SkipReasonDomainFilter
+		case !plan.IsManagedRecord(e.RecordType, p.managedRecords, p.excludeRecords):
+			record.SkipReason = plan.SkipReasonUnsupportedType
+		case conflict:
+			record.SkipReason = plan.SkipReasonOwnershipConflict
+		}
+	}
+
+	for _, record := range records {
+		explanation.Records = append(explanation.Records, *record)
+	}
+	sort.Slice(explanation.Records, func(i, j int) bool {
+		if explanation.Records[i].RecordType != explanation.Records[j].RecordType {
+			return explanation.Records[i].RecordType < explanation.Records[j].RecordType
+		}
+		return explanation.Records[i].SetIdentifier < explanation.Records[j].SetIdentifier
+	})
+	return explanation
+}
+
+// Handler serves the explanation of the DNS name in the "name" query parameter as JSON.
+func (p *Provenance) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("name")
+		if name == "" {
+			http.Error(w, "missing the DNS name to explain", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(p.Explain(name)); err != nil {
+			log.Debugf("Failed to write the explanation: %v", err)
+		}
+	})
+}
+
+// recordKey identifies the records of a DNS name regardless of their targets.
+func recordKey(e *endpoint.Endpoint) endpoint.EndpointKey {
+	return endpoint.EndpointKey{RecordType: e.RecordType, SetIdentifier: e.SetIdentifier}
+}
+
+func indexByName(endpoints []*endpoint.Endpoint) map[string][]*endpoint.Endpoint {
+	indexed := make(map[string][]*endpoint.Endpoint, len(endpoints))
+	for _, e := range endpoints {
+		key := desiredStateKey(e.DNSName)
+		indexed[key] = append(indexed[key], e.DeepCopy())
+	}
+	return indexed
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+func TestProvenanceExplain(t *testing.T) {
+	p := NewProvenance()
+	filter := endpoint.NewDomainFilter([]string{"example.org", "sub.example.org"})
+	domainFilter := endpoint.MatchAllDomainFilters{&filter}
+
+	desiredA := endpoint.NewEndpointWithTTL("app.sub.example.org", endpoint.RecordTypeA, 300, "1.1.1.1")
+	desiredA.Labels[endpoint.ResourceLabelKey] = "service/default/app"
+	desiredTXT := endpoint.NewEndpoint("app.sub.example.org", endpoint.RecordTypeTXT, "text")
+	currentA := endpoint.NewEndpoint("app.sub.example.org", endpoint.RecordTypeA, "2.2.2.2")
+	currentA.Labels[endpoint.OwnerLabelKey] = "default"
+	conflicting := endpoint.NewEndpoint("taken.example.org", endpoint.RecordTypeA, "1.1.1.1")
+	other := endpoint.NewEndpoint("taken.example.org", endpoint.RecordTypeA, "2.2.2.2")
+	other.Labels[endpoint.OwnerLabelKey] = "other"
+	filtered := endpoint.NewEndpoint("app.example.com", endpoint.RecordTypeA, "1.1.1.1")
+
+	p.update(
+		[]*endpoint.Endpoint{desiredA, desiredTXT, conflicting, filtered},
+		[]*endpoint.Endpoint{currentA, other},
+		filter.Filters, domainFilter, []string{endpoint.RecordTypeA}, nil, "default",
+	)
+	appliedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	p.recordApplied(&plan.Changes{UpdateNew: []*endpoint.Endpoint{desiredA}}, appliedAt)
+
+	explanation := p.Explain("App.sub.example.org.")
+	assert.Equal(t, "app.sub.example.org", explanation.Name)
+	assert.Equal(t, "sub.example.org", explanation.Zone)
+	assert.Equal(t, []ExplainedRecord{
+		{
+			RecordType:  endpoint.RecordTypeA,
+			Targets:     endpoint.Targets{"1.1.1.1"},
+			RecordTTL:   300,
+			Resource:    "service/default/app",
+			Labels:      endpoint.Labels{endpoint.OwnerLabelKey: "default"},
+			Desired:     true,
+			Exists:      true,
+			LastApplied: &appliedAt,
+		},
+		{
+			RecordType: endpoint.RecordTypeTXT,
+			Targets:    endpoint.Targets{"text"},
+			Desired:    true,
+			SkipReason: plan.SkipReasonUnsupportedType,
+		},
+	}, explanation.Records)
+
+	assert.Equal(t, plan.SkipReasonOwnershipConflict, p.Explain("taken.example.org").Records[0].SkipReason)
+	assert.Equal(t, plan.SkipReasonDomainFilter, p.Explain("app.example.com").Records[0].SkipReason)
+	assert.Empty(t, p.Explain("unknown.example.org").Records)
+
+	// the applied times of the names which are neither desired nor exist are forgotten
+	p.update(nil, nil, filter.Filters, domainFilter, []string{endpoint.RecordTypeA}, nil, "default")
+	assert.Empty(t, p.applied)
+}
+
+func TestProvenanceHandler(t *testing.T) {
+	p := NewProvenance()
+	p.update([]*endpoint.Endpoint{endpoint.NewEndpoint("app.example.org", endpoint.RecordTypeA, "1.1.1.1")}, nil, nil, endpoint.MatchAllDomainFilters{}, []string{endpoint.RecordTypeA}, nil, "")
+
+	rec := httptest.NewRecorder()
+	p.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, ExplainPath+"?name=app.example.org", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	explanation := &Explanation{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), explanation))
+	assert.Equal(t, "app.example.org", explanation.Name)
+	require.Len(t, explanation.Records, 1)
+	assert.True(t, explanation.Records[0].Desired)
+
+	rec = httptest.NewRecorder()
+	p.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, ExplainPath, nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
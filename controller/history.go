@@ -0,0 +1,285 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/plan"
+)
+
+// defaultHistoryLimit is the number of syncs kept by a FileHistory without a Limit.
+const defaultHistoryLimit = 20
+
+// ErrUnknownSync is returned when rolling back to a sync missing from the history.
+var ErrUnknownSync = errors.New("unknown sync")
+
+// HistoryEntry is the inverse of the changes applied by a sync, undoing them when applied.
+type HistoryEntry struct {
+	ID      string        `json:"id"`
+	Time    time.Time     `json:"time"`
+	Inverse *plan.Changes `json:"inverse"`
+}
+
+// HistoryStore persists the inverse of the changes applied by every sync, so syncs can be rolled back.
+type HistoryStore interface {
+	// Record stores the inverse of the changes applied by a sync at the given time and returns the
+	// ID of the sync.
+	Record(changes *plan.Changes, at time.Time) (string, error)
+	// Since returns the entries of the sync with the ID and of all later syncs, newest first.
+	Since(id string) ([]HistoryEntry, error)
+	// Remove forgets the sync with the ID, e.g. because it was rolled back.
+	Remove(id string) error
+}
+
+// FileHistory stores every entry as a JSON file named after the sync in a directory, keeping the
+// Limit latest syncs, defaultHistoryLimit if zero.
+type FileHistory struct {
+	Dir   string
+	Limit int
+}
+
+// Record writes the inverse of the changes to a new file, and removes the files of the oldest syncs
+// beyond the limit. The IDs of the syncs are their UTC time, so they sort in the order of the syncs.
+func (h FileHistory) Record(changes *plan.Changes, at time.Time) (string, error) {
+	entry := HistoryEntry{
+		ID:      at.UTC().Format("20060102T150405.000Z"),
+		Time:    at,
+		Inverse: inverseChanges(changes),
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode the history of sync %s: %w", entry.ID, err)
+	}
+	if err := os.MkdirAll(h.Dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to write the history: %w", err)
+	}
+	if err := writeFileAtomically(h.path(entry.ID), data); err != nil {
+		return "", fmt.Errorf("failed to write the history of sync %s: %w", entry.ID, err)
+	}
+
+	ids, err := h.ids()
+	if err != nil {
+		return entry.ID, err
+	}
+	limit := h.Limit
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+	for len(ids) > limit {
+		if err := h.Remove(ids[0]); err != nil {
+			return entry.ID, err
+		}
+		ids = ids[1:]
+	}
+	return entry.ID, nil
+}
+
+// Since reads the files of the sync with the ID and of all later syncs.
+func (h FileHistory) Since(id string) ([]HistoryEntry, error) {
+	ids, err := h.ids()
+	if err != nil {
+		return nil, err
+	}
+	first := sort.SearchStrings(ids, id)
+	if first == len(ids) || ids[first] != id {
+		return nil, fmt.Errorf("%w %q in %s", ErrUnknownSync, id, h.Dir)
+	}
+
+	entries := make([]HistoryEntry, 0, len(ids)-first)
+	for i := len(ids) - 1; i >= first; i-- {
+		data, err := os.ReadFile(h.path(ids[i]))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the history of sync %s: %w", ids[i], err)
+		}
+		var entry HistoryEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return nil, fmt.Errorf("failed to decode the history of sync %s: %w", ids[i], err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// Remove deletes the file of the sync.
+func (h FileHistory) Remove(id string) error {
+	if err := os.Remove(h.path(id)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove the history of sync %s: %w", id, err)
+	}
+	return nil
+}
+
+// ids returns the IDs of the syncs in the directory, oldest first.
+func (h FileHistory) ids() ([]string, error) {
+	files, err := os.ReadDir(h.Dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the history: %w", err)
+	}
+	var ids []string
+	for _, f := range files {
+		if id, ok := strings.CutSuffix(f.Name(), ".json"); ok && !f.IsDir() {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func (h FileHistory) path(id string) string {
+	return filepath.Join(h.Dir, id+".json")
+}
+
+// inverseChanges returns the changes undoing the changes: the created records are deleted, the
+// deleted records created and the updated records updated back to their old state.
+func inverseChanges(changes *plan.Changes) *plan.Changes {
+	return &plan.Changes{
+		Create:    changes.Delete,
+		UpdateOld: changes.UpdateNew,
+		UpdateNew: changes.UpdateOld,
+		Delete:    changes.Create,
+	}
+}
+
+// collectingApply returns a function applying the changes of a zone in batches, and the changes it
+// applied.
+func (c *Controller) collectingApply() (func(context.Context, *plan.Changes) error, *plan.Changes) {
+	applied := &plan.Changes{}
+	// the changes of zones are applied concurrently
+	var mu sync.Mutex
+	return func(ctx context.Context, changes *plan.Changes) error {
+		// the batches applied before a batch fails are collected
+		return c.applyBatches(ctx, changes, func(ctx context.Context, batch *plan.Changes) error {
+			if err := c.applyZoneChanges(ctx, batch); err != nil {
+				return err
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			applied.Create = append(applied.Create, batch.Create...)
+			applied.UpdateOld = append(applied.UpdateOld, batch.UpdateOld...)
+			applied.UpdateNew = append(applied.UpdateNew, batch.UpdateNew...)
+			applied.Delete = append(applied.Delete, batch.Delete...)
+			for _, change := range batch.List() {
+				log.Infof("Applied change %s: %s", change.ID, change)
+			}
+			return nil
+		})
+	}, applied
+}
+
+// recordHistory records the changes applied by a sync in the history, if any.
+func (c *Controller) recordHistory(changes *plan.Changes, at time.Time) {
+	if c.History == nil || !changes.HasChanges() {
+		return
+	}
+	id, err := c.History.Record(changes, at)
+	if err != nil {
+		log.Warnf("Failed to record the applied changes in the history: %v", err)
+		return
+	}
+	log.Infof("Recorded the applied changes as sync %s", id)
+}
+
+// Rollback undoes the sync with the ID and every later sync, newest first, by applying their
+// inverse to the registry. The syncs rolled back are removed from the history. It returns the
+// number of syncs rolled back.
+//
+// The next reconciliation plans against the records again, so it reapplies the desired records of
+// the sources unless they were fixed in the meantime.
+func (c *Controller) Rollback(ctx context.Context, id string) (int, error) {
+	if c.History == nil {
+		return 0, errors.New("no history of the syncs is kept")
+	}
+	c.runMux.Lock()
+	defer c.runMux.Unlock()
+
+	entries, err := c.History.Since(id)
+	if err != nil {
+		return 0, err
+	}
+	// The applied records are unknown after a rollback, partial or not, and the snapshot of the
+	// records is outdated
+	c.appliedRecords = nil
+	if c.Snapshot != nil {
+		if err := c.Snapshot.Save(nil); err != nil {
+			log.Warnf("Failed to clear the snapshot of the records: %v", err)
+		}
+	}
+	for i, entry := range entries {
+		if err := c.applyRegistryChanges(ctx, entry.Inverse); err != nil {
+			return i, fmt.Errorf("failed to roll back sync %s: %w", entry.ID, err)
+		}
+		log.Infof("Rolled back sync %s applied at %s", entry.ID, entry.Time.Format(time.RFC3339))
+		if err := c.History.Remove(entry.ID); err != nil {
+			return i + 1, err
+		}
+	}
+	return len(entries), nil
+}
+
+// RollbackHandler serves POST requests with the ID of a sync in the "to" query parameter, rolling
+// back that sync and every later sync. The requests must carry the token as a bearer token, an
+// empty token rejecting all of them.
+func (c *Controller) RollbackHandler(token []byte) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if len(token) == 0 || !ok || subtle.ConstantTimeCompare(token, []byte(bearer)) != 1 {
+			log.Warnf("Rejecting unauthenticated rollback request from %s", r.RemoteAddr)
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		id := r.URL.Query().Get("to")
+		if id == "" {
+			http.Error(w, "missing the ID of the sync to roll back to", http.StatusBadRequest)
+			return
+		}
+
+		rolledBack, err := c.Rollback(r.Context(), id)
+		if errors.Is(err, ErrUnknownSync) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			log.Errorf("Failed to roll back to sync %s: %v", id, err)
+			http.Error(w, fmt.Sprintf("rolled back %d syncs, then failed: %v", rolledBack, err), http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprintf(w, "rolled back %d syncs\n", rolledBack)
+	})
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/registry"
+)
+
+func TestFileHistory(t *testing.T) {
+	h := FileHistory{Dir: t.TempDir() + "/history", Limit: 2}
+	at := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	a := endpoint.NewEndpoint("a.example.org", endpoint.RecordTypeA, "1.1.1.1")
+	b := endpoint.NewEndpoint("b.example.org", endpoint.RecordTypeA, "2.2.2.2")
+	bOld := endpoint.NewEndpoint("b.example.org", endpoint.RecordTypeA, "3.3.3.3")
+
+	first, err := h.Record(&plan.Changes{Create: []*endpoint.Endpoint{a}}, at)
+	require.NoError(t, err)
+	assert.Equal(t, "20240301T120000.000Z", first)
+	second, err := h.Record(&plan.Changes{UpdateOld: []*endpoint.Endpoint{bOld}, UpdateNew: []*endpoint.Endpoint{b}}, at.Add(time.Minute))
+	require.NoError(t, err)
+	third, err := h.Record(&plan.Changes{Delete: []*endpoint.Endpoint{a}}, at.Add(2*time.Minute))
+	require.NoError(t, err)
+
+	// the oldest sync is removed beyond the limit
+	_, err = h.Since(first)
+	assert.ErrorIs(t, err, ErrUnknownSync)
+
+	entries, err := h.Since(second)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, third, entries[0].ID)
+	require.Len(t, entries[0].Inverse.Create, 1)
+	assert.Equal(t, a.DNSName, entries[0].Inverse.Create[0].DNSName)
+	assert.Equal(t, second, entries[1].ID)
+	require.Len(t, entries[1].Inverse.UpdateOld, 1)
+	assert.Equal(t, b.Targets, entries[1].Inverse.UpdateOld[0].Targets)
+	require.Len(t, entries[1].Inverse.UpdateNew, 1)
+	assert.Equal(t, bOld.Targets, entries[1].Inverse.UpdateNew[0].Targets)
+
+	require.NoError(t, h.Remove(third))
+	entries, err = h.Since(second)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestRollback(t *testing.T) {
+	a := endpoint.NewEndpoint("a.example.org", endpoint.RecordTypeA, "1.1.1.1")
+	aNew := endpoint.NewEndpoint("a.example.org", endpoint.RecordTypeA, "2.2.2.2")
+	b := endpoint.NewEndpoint("b.example.org", endpoint.RecordTypeA, "3.3.3.3")
+
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return([]*endpoint.Endpoint{aNew, b}, nil)
+	provider := &filteredMockProvider{RecordsStore: []*endpoint.Endpoint{a}}
+	r, err := registry.NewNoopRegistry(provider)
+	require.NoError(t, err)
+	history := FileHistory{Dir: t.TempDir()}
+	ctrl := &Controller{
+		Source:             source,
+		Registry:           r,
+		Policy:             &plan.SyncPolicy{},
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+		History:            history,
+	}
+
+	require.NoError(t, ctrl.RunOnce(context.Background()))
+	require.Len(t, provider.ApplyChangesCalls, 1)
+	ids, err := history.ids()
+	require.NoError(t, err)
+	require.Len(t, ids, 1)
+
+	rolledBack, err := ctrl.Rollback(context.Background(), ids[0])
+	require.NoError(t, err)
+	assert.Equal(t, 1, rolledBack)
+	require.Len(t, provider.ApplyChangesCalls, 2)
+	inverse := provider.ApplyChangesCalls[1]
+	require.Len(t, inverse.Delete, 1)
+	assert.Equal(t, "b.example.org", inverse.Delete[0].DNSName)
+	require.Len(t, inverse.UpdateNew, 1)
+	assert.Equal(t, endpoint.Targets{"1.1.1.1"}, inverse.UpdateNew[0].Targets)
+	require.Len(t, inverse.UpdateOld, 1)
+	assert.Equal(t, endpoint.Targets{"2.2.2.2"}, inverse.UpdateOld[0].Targets)
+	assert.Empty(t, inverse.Create)
+
+	// the rolled back sync is forgotten
+	_, err = ctrl.Rollback(context.Background(), ids[0])
+	assert.ErrorIs(t, err, ErrUnknownSync)
+}
+
+func TestRollbackHandler(t *testing.T) {
+	provider := &filteredMockProvider{}
+	r, err := registry.NewNoopRegistry(provider)
+	require.NoError(t, err)
+	history := FileHistory{Dir: t.TempDir()}
+	id, err := history.Record(&plan.Changes{Create: []*endpoint.Endpoint{endpoint.NewEndpoint("a.example.org", endpoint.RecordTypeA, "1.1.1.1")}}, time.Now())
+	require.NoError(t, err)
+	ctrl := &Controller{Registry: r, History: history}
+	handler := ctrl.RollbackHandler([]byte("secret"))
+
+	for _, tt := range []struct {
+		method string
+		target string
+		token  string
+		status int
+	}{
+		{http.MethodPost, "/rollback?to=" + id, "", http.StatusUnauthorized},
+		{http.MethodPost, "/rollback?to=" + id, "wrong", http.StatusUnauthorized},
+		{http.MethodGet, "/rollback?to=" + id, "secret", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/rollback", "secret", http.StatusBadRequest},
+		{http.MethodPost, "/rollback?to=20000101T000000.000Z", "secret", http.StatusNotFound},
+		{http.MethodPost, "/rollback?to=" + id, "secret", http.StatusOK},
+	} {
+		req := httptest.NewRequest(tt.method, tt.target, nil)
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, tt.status, rec.Code, "%s %s", tt.method, tt.target)
+	}
+
+	// without a token, every request is rejected
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/rollback?to="+id, nil)
+	req.Header.Set("Authorization", "Bearer ")
+	ctrl.RollbackHandler(nil).ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	require.Len(t, provider.ApplyChangesCalls, 1)
+	assert.Len(t, provider.ApplyChangesCalls[0].Delete, 1)
+}
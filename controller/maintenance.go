@@ -0,0 +1,186 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var maintenanceWindowActive = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "external_dns",
+		Subsystem: "controller",
+		Name:      "maintenance_window_active",
+		Help:      "Whether the changes to the DNS provider are deferred by a maintenance window.",
+	},
+)
+
+func init() {
+	prometheus.MustRegister(maintenanceWindowActive)
+}
+
+// MaintenanceWindow is a recurring window during which no changes are applied to the DNS provider,
+// e.g. a change freeze. It starts at the times matching a cron expression and lasts Duration.
+type MaintenanceWindow struct {
+	schedule cronSchedule
+	Duration time.Duration
+}
+
+// ParseMaintenanceWindow parses a window given as the five fields of a cron expression of its start
+// (minute, hour, day of month, month and day of week) followed by its duration, e.g. "0 18 * * 5 62h"
+// for the weekends starting on Friday at 18:00.
+func ParseMaintenanceWindow(value string) (MaintenanceWindow, error) {
+	fields := strings.Fields(value)
+	if len(fields) != 6 {
+		return MaintenanceWindow{}, fmt.Errorf("invalid maintenance window %q: expected a cron expression of 5 fields and a duration", value)
+	}
+	schedule, err := parseCronSchedule(fields[:5])
+	if err != nil {
+		return MaintenanceWindow{}, fmt.Errorf("invalid maintenance window %q: %w", value, err)
+	}
+	duration, err := time.ParseDuration(fields[5])
+	if err != nil || duration <= 0 {
+		return MaintenanceWindow{}, fmt.Errorf("invalid maintenance window %q: invalid duration %q", value, fields[5])
+	}
+	return MaintenanceWindow{schedule: schedule, Duration: duration}, nil
+}
+
+// end returns the end of the occurrence of the window including now, or the zero time outside of the
+// window.
+func (w MaintenanceWindow) end(now time.Time) time.Time {
+	for start := now.Truncate(time.Minute); start.Add(w.Duration).After(now); start = start.Add(-time.Minute) {
+		if w.schedule.matches(start) {
+			return start.Add(w.Duration)
+		}
+	}
+	return time.Time{}
+}
+
+// maintenanceWindowEnd returns the latest end of the maintenance windows including now, or the zero
+// time outside of them.
+func (c *Controller) maintenanceWindowEnd(now time.Time) time.Time {
+	var end time.Time
+	for _, w := range c.MaintenanceWindows {
+		if e := w.end(now); e.After(end) {
+			end = e
+		}
+	}
+	if end.IsZero() {
+		maintenanceWindowActive.Set(0)
+	} else {
+		maintenanceWindowActive.Set(1)
+	}
+	return end
+}
+
+// cronSchedule holds the values matched by each field of a cron expression as bit sets.
+type cronSchedule struct {
+	minute, hour, dayOfMonth, month, dayOfWeek uint64
+	// the days match either field when both are restricted, like cron does
+	anyDayOfMonth, anyDayOfWeek bool
+}
+
+func parseCronSchedule(fields []string) (cronSchedule, error) {
+	var s cronSchedule
+	var err error
+	if s.minute, err = parseCronField(fields[0], 0, 59); err != nil {
+		return s, fmt.Errorf("invalid minute: %w", err)
+	}
+	if s.hour, err = parseCronField(fields[1], 0, 23); err != nil {
+		return s, fmt.Errorf("invalid hour: %w", err)
+	}
+	if s.dayOfMonth, err = parseCronField(fields[2], 1, 31); err != nil {
+		return s, fmt.Errorf("invalid day of month: %w", err)
+	}
+	if s.month, err = parseCronField(fields[3], 1, 12); err != nil {
+		return s, fmt.Errorf("invalid month: %w", err)
+	}
+	if s.dayOfWeek, err = parseCronField(fields[4], 0, 7); err != nil {
+		return s, fmt.Errorf("invalid day of week: %w", err)
+	}
+	// 7 is Sunday too
+	if s.dayOfWeek&(1<<7) != 0 {
+		s.dayOfWeek |= 1
+	}
+	s.anyDayOfMonth = strings.HasPrefix(fields[2], "*")
+	s.anyDayOfWeek = strings.HasPrefix(fields[4], "*")
+	return s, nil
+}
+
+// parseCronField parses a comma separated list of values, ranges and steps, e.g. "1-5", "*/15" or
+// "0,30", between min and max.
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step in %q", part)
+			}
+			rangePart = part[:i]
+		}
+
+		first, last := min, max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if first, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, fmt.Errorf("invalid range %q", part)
+			}
+			if last, err = strconv.Atoi(bounds[1]); err != nil {
+				return 0, fmt.Errorf("invalid range %q", part)
+			}
+		default:
+			var err error
+			if first, err = strconv.Atoi(rangePart); err != nil {
+				return 0, fmt.Errorf("invalid value %q", part)
+			}
+			// a single value with a step runs until the maximum
+			if step == 1 {
+				last = first
+			}
+		}
+		if first < min || last > max || first > last {
+			return 0, fmt.Errorf("%q is not between %d and %d", part, min, max)
+		}
+
+		for v := first; v <= last; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func (s cronSchedule) matches(t time.Time) bool {
+	if s.minute&(1<<uint(t.Minute())) == 0 || s.hour&(1<<uint(t.Hour())) == 0 || s.month&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+	dayOfMonth := s.dayOfMonth&(1<<uint(t.Day())) != 0
+	dayOfWeek := s.dayOfWeek&(1<<uint(t.Weekday())) != 0
+	if s.anyDayOfMonth || s.anyDayOfWeek {
+		return dayOfMonth && dayOfWeek
+	}
+	return dayOfMonth || dayOfWeek
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/registry"
+)
+
+func TestParseMaintenanceWindow(t *testing.T) {
+	for _, value := range []string{
+		"0 18 * * 5",
+		"0 18 * * 5 soon",
+		"0 18 * * 5 -1h",
+		"60 18 * * 5 1h",
+		"0 18 0 * * 1h",
+		"0 18 * 1-13 * 1h",
+		"0 18 * * 5-1 1h",
+		"*/0 18 * * * 1h",
+		"0 18 * * mon 1h",
+	} {
+		_, err := ParseMaintenanceWindow(value)
+		assert.Error(t, err, value)
+	}
+
+	w, err := ParseMaintenanceWindow("0 18 * * 5 62h")
+	require.NoError(t, err)
+	assert.Equal(t, 62*time.Hour, w.Duration)
+}
+
+func TestCronScheduleMatches(t *testing.T) {
+	// Friday 2024-03-01
+	friday := time.Date(2024, time.March, 1, 18, 30, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		schedule string
+		matches  bool
+	}{
+		{"* * * * *", true},
+		{"30 18 * * *", true},
+		{"*/15 18 * * *", true},
+		{"*/20 18 * * *", false},
+		{"0,30 17-19 * * *", true},
+		{"30 18 * * 1-5", true},
+		{"30 18 * * 0,6", false},
+		{"30 18 1 * *", true},
+		{"30 18 2 3 *", false},
+		// either day matches when both are restricted
+		{"30 18 2 * 5", true},
+		{"30 18 2 * 0", false},
+		{"30 18 * 4 *", false},
+	} {
+		w, err := ParseMaintenanceWindow(tt.schedule + " 1h")
+		require.NoError(t, err, tt.schedule)
+		assert.Equal(t, tt.matches, w.schedule.matches(friday), tt.schedule)
+	}
+
+	// 7 is Sunday too
+	w, err := ParseMaintenanceWindow("0 0 * * 7 1h")
+	require.NoError(t, err)
+	assert.True(t, w.schedule.matches(time.Date(2024, time.March, 3, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestMaintenanceWindowEnd(t *testing.T) {
+	w, err := ParseMaintenanceWindow("0 18 * * 5 62h")
+	require.NoError(t, err)
+	end := time.Date(2024, time.March, 4, 8, 0, 0, 0, time.UTC)
+
+	// Friday 2024-03-01 before and at the start of the window
+	assert.True(t, w.end(time.Date(2024, time.March, 1, 17, 59, 59, 0, time.UTC)).IsZero())
+	assert.Equal(t, end, w.end(time.Date(2024, time.March, 1, 18, 0, 0, 0, time.UTC)))
+	// Sunday
+	assert.Equal(t, end, w.end(time.Date(2024, time.March, 3, 12, 0, 0, 0, time.UTC)))
+	// Monday at the end of the window
+	assert.Equal(t, end, w.end(end.Add(-time.Second)))
+	assert.True(t, w.end(end).IsZero())
+}
+
+func TestRunOnceInMaintenanceWindow(t *testing.T) {
+	a := endpoint.NewEndpoint("a.example.org", endpoint.RecordTypeA, "1.1.1.1")
+	b := endpoint.NewEndpoint("b.example.org", endpoint.RecordTypeA, "2.2.2.2")
+
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return([]*endpoint.Endpoint{a, b}, nil)
+	provider := &filteredMockProvider{RecordsStore: []*endpoint.Endpoint{a}}
+	r, err := registry.NewNoopRegistry(provider)
+	require.NoError(t, err)
+
+	always, err := ParseMaintenanceWindow("* * * * * 1h")
+	require.NoError(t, err)
+	ctrl := &Controller{
+		Source:             source,
+		Registry:           r,
+		Policy:             &plan.SyncPolicy{},
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+		Interval:           time.Hour,
+		MaintenanceWindows: []MaintenanceWindow{always},
+	}
+	ctrl.nextRunAt = time.Now().Add(time.Hour)
+
+	// the records are read, the changes are deferred until the end of the window
+	require.NoError(t, ctrl.RunOnce(context.Background()))
+	assert.Equal(t, 1, provider.RecordsCallCount)
+	assert.Empty(t, provider.ApplyChangesCalls)
+	assert.True(t, ctrl.nextRunAt.Before(time.Now().Add(time.Hour)))
+	assert.Equal(t, 1.0, math.Float64frombits(valueFromMetric(maintenanceWindowActive)))
+
+	// and applied after it
+	ctrl.MaintenanceWindows = nil
+	require.NoError(t, ctrl.RunOnce(context.Background()))
+	require.Len(t, provider.ApplyChangesCalls, 1)
+	assert.Equal(t, []*endpoint.Endpoint{b}, provider.ApplyChangesCalls[0].Create)
+	assert.Equal(t, 0.0, math.Float64frombits(valueFromMetric(maintenanceWindowActive)))
+}
+
+// countingGarbageCollector counts the garbage collections
+type countingGarbageCollector struct {
+	collections int
+}
+
+func (gc *countingGarbageCollector) CollectGarbage(ctx context.Context, dryRun bool) (int, error) {
+	gc.collections++
+	return 0, nil
+}
+
+func TestCollectGarbageInMaintenanceWindow(t *testing.T) {
+	always, err := ParseMaintenanceWindow("* * * * * 1h")
+	require.NoError(t, err)
+	gc := &countingGarbageCollector{}
+	ctrl := &Controller{
+		GarbageCollector:          gc,
+		GarbageCollectionInterval: time.Hour,
+		MaintenanceWindows:        []MaintenanceWindow{always},
+	}
+	now := time.Now()
+
+	// the collection is postponed until the end of the window
+	ctrl.collectGarbage(context.Background(), now)
+	assert.Equal(t, 0, gc.collections)
+
+	ctrl.MaintenanceWindows = nil
+	ctrl.collectGarbage(context.Background(), now.Add(time.Second))
+	assert.Equal(t, 1, gc.collections)
+	ctrl.collectGarbage(context.Background(), now.Add(2*time.Second))
+	assert.Equal(t, 1, gc.collections)
+}
+
+func TestCollectGarbageExclusive(t *testing.T) {
+	gc := &countingGarbageCollector{}
+	ctrl := &Controller{GarbageCollector: gc, GarbageCollectionInterval: time.Hour}
+
+	// the collection waits for the writers of the registry holding the lock
+	ctrl.Exclusive().Lock()
+	done := make(chan struct{})
+	go func() {
+		ctrl.collectGarbage(context.Background(), time.Now())
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("the garbage was collected while the lock was held")
+	case <-time.After(50 * time.Millisecond):
+	}
+	ctrl.Exclusive().Unlock()
+	<-done
+	assert.Equal(t, 1, gc.collections)
+}
@@ -0,0 +1,229 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+var recordPages = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "external_dns",
+		Subsystem: "controller",
+		Name:      "record_pages",
+		Help:      "Number of pages of records read by the last paged reconciliation.",
+	},
+)
+
+func init() {
+	prometheus.MustRegister(recordPages)
+}
+
+// recordsPager returns the registry as a provider.RecordsPager if its provider lists its records in
+// pages. The registries implement RecordsPager whatever their provider, returning all the records
+// as a single page otherwise, which nothing is gained from.
+func (c *Controller) recordsPager() (provider.RecordsPager, bool) {
+	pager, ok := c.Registry.(provider.RecordsPager)
+	if !ok {
+		return nil, false
+	}
+	if r, ok := c.Registry.(interface{ PagesRecords() bool }); ok && !r.PagesRecords() {
+		return nil, false
+	}
+	return pager, true
+}
+
+// runPaged reconciles the records of the registry a page at a time: the changes of the DNS names
+// of a page are planned and applied once the page is read, and the desired endpoints of the DNS
+// names on no page are created once all the pages are read, so that only the desired endpoints
+// and a page of records are held in memory. A page failing to be read is retried on its own
+// without reading the pages before it again. If it keeps failing, the reconciliation stops
+// without creating the endpoints of the DNS names of no page, since they may be on the pages left.
+func (c *Controller) runPaged(ctx context.Context, pager provider.RecordsPager) error {
+	sourcedAt := time.Now()
+	endpoints, err := c.Source.Endpoints(ctx)
+	if err != nil {
+		sourceErrorsTotal.Inc()
+		deprecatedSourceErrors.Inc()
+		return err
+	}
+	// the dampening windows of the resources are removed from the endpoints, so that they don't reach
+	// the provider, and ignored, as the dampener needs all the records
+	if windows := dampeningWindows(endpoints); len(windows) > 0 {
+		log.Warnf("Ignoring the dampening windows of %d endpoints, which are not supported with pagination", len(windows))
+	}
+	endpoints, activeAt := activeEndpoints(endpoints, sourcedAt)
+	if !activeAt.IsZero() {
+		c.scheduleRetry(activeAt)
+	}
+	sourceEndpointsTotal.Set(float64(len(endpoints)))
+	endpoints, err = c.Registry.AdjustEndpoints(endpoints)
+	if err != nil {
+		return fmt.Errorf("adjusting endpoints: %w", err)
+	}
+	registryFilter := c.Registry.GetDomainFilter()
+	domainFilter := endpoint.MatchAllDomainFilters{&c.DomainFilter, &registryFilter}
+	zones := c.zoneNames(ctx, registryFilter)
+	if c.DesiredState != nil {
+		c.DesiredState.update(endpoints, domainFilter)
+	}
+	if c.Warnings != nil {
+		c.Warnings.check(endpoints, domainFilter, time.Now())
+	}
+
+	if end := c.maintenanceWindowEnd(time.Now()); !end.IsZero() {
+		log.Infof("Deferring the reconciliation until the end of the maintenance window at %s", end.Format(time.RFC3339))
+		c.scheduleRetry(end)
+		return nil
+	}
+
+	// the desired endpoints by DNS name, until the page of the DNS name is read
+	desired := make(map[string][]*endpoint.Endpoint, len(endpoints))
+	for _, ep := range endpoints {
+		name := endpoint.CanonicalDNSName(ep.DNSName)
+		desired[name] = append(desired[name], ep)
+	}
+
+	records := 0
+	var errs []error
+	// the zones with changes on any page, the failures of the other zones being forgotten
+	changedZones := map[string]bool{}
+	pending := false
+	seen := map[string]bool{}
+	pageToken := ""
+	for page := 1; ; page++ {
+		var current []*endpoint.Endpoint
+		var next string
+		err := c.PageRetry.Do(ctx, func(ctx context.Context) error {
+			var err error
+			current, next, err = pager.RecordsPage(ctx, pageToken)
+			return err
+		})
+		if err == nil && next != "" && seen[next] {
+			err = fmt.Errorf("page token %q was returned before", next)
+		}
+		if err != nil {
+			registryErrorsTotal.Inc()
+			deprecatedRegistryErrors.Inc()
+			recordPages.Set(float64(page - 1))
+			errs = append(errs, fmt.Errorf("failed to read page %d of the records: %w", page, err))
+			return provider.NewSoftError(errors.Join(errs...))
+		}
+		records += len(current)
+
+		var pageDesired []*endpoint.Endpoint
+		for _, record := range current {
+			name := endpoint.CanonicalDNSName(record.DNSName)
+			pageDesired = append(pageDesired, desired[name]...)
+			delete(desired, name)
+		}
+		pagePending, err := c.applyPage(ctx, current, pageDesired, domainFilter, zones, changedZones)
+		pending = pending || pagePending
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to apply the changes of page %d: %w", page, err))
+		}
+
+		if next == "" {
+			recordPages.Set(float64(page))
+			break
+		}
+		seen[next] = true
+		pageToken = next
+	}
+	registryEndpointsTotal.Set(float64(records))
+
+	// the endpoints of the DNS names on no page are created, in the order of the sources
+	var missing []*endpoint.Endpoint
+	for _, ep := range endpoints {
+		if _, ok := desired[endpoint.CanonicalDNSName(ep.DNSName)]; ok {
+			missing = append(missing, ep)
+		}
+	}
+	missingPending, err := c.applyPage(ctx, nil, missing, domainFilter, zones, changedZones)
+	pending = pending || missingPending
+	if err != nil {
+		errs = append(errs, fmt.Errorf("failed to create the records: %w", err))
+	}
+	c.zones.recoverOthers(changedZones)
+	if next := c.zones.nextRetry(); !next.IsZero() {
+		c.scheduleRetry(next)
+	}
+
+	if c.Churn != nil {
+		c.Churn.observe(time.Now())
+	}
+	if len(errs) > 0 {
+		return provider.NewSoftError(errors.Join(errs...))
+	}
+	if pending {
+		return nil
+	}
+	lastSyncTimestamp.SetToCurrentTime()
+	lastFullSyncTimestamp.SetToCurrentTime()
+	return nil
+}
+
+// applyPage plans and applies the changes of the records of a page to the desired endpoints of
+// their DNS names. The changes are applied by zone in batches, like those of a full reconciliation,
+// the zones with changes being added to changedZones. It returns whether changes of failed zones
+// are pending.
+func (c *Controller) applyPage(ctx context.Context, records, desired []*endpoint.Endpoint, domainFilter endpoint.MatchAllDomainFilters, zones []string, changedZones map[string]bool) (bool, error) {
+	plan := (&plan.Plan{
+		Policies:           c.policies(),
+		Current:            records,
+		Desired:            desired,
+		DomainFilter:       domainFilter,
+		ManagedRecords:     c.ManagedRecordTypes,
+		ExcludeRecords:     c.ExcludeRecordTypes,
+		OwnerID:            c.Registry.OwnerID(),
+		PropertyComparator: c.Registry.PropertyValuesEqual,
+	}).Calculate()
+	if c.ChangeWindows != nil {
+		if opensAt := c.ChangeWindows.NextOpening(); !opensAt.IsZero() {
+			c.scheduleRetry(opensAt)
+		}
+	}
+	if c.TTLStep != nil {
+		if stepAt := c.TTLStep.NextStep(); !stepAt.IsZero() {
+			c.scheduleRetry(stepAt)
+		}
+	}
+	if !plan.Changes.HasChanges() {
+		return false, nil
+	}
+
+	changes := splitChangesByZone(plan.Changes, zones)
+	for zone := range changes {
+		changedZones[zone] = true
+	}
+	apply, applied := c.collectingApply()
+	pending, _, err := c.zones.applyZones(context.WithValue(ctx, provider.RecordsContextKey, records), time.Now(), changes, apply)
+	if c.Churn != nil {
+		c.Churn.record(applied, zones, time.Now())
+	}
+	return pending, err
+}
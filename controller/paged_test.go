@@ -0,0 +1,193 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+	"sigs.k8s.io/external-dns/registry"
+	"sigs.k8s.io/external-dns/source"
+)
+
+// pagedMockProvider returns a record per page, the page at failingPage failing failures times.
+type pagedMockProvider struct {
+	filteredMockProvider
+	failingPage int
+	failures    int
+	requests    []string
+}
+
+func (p *pagedMockProvider) RecordsPage(ctx context.Context, pageToken string) ([]*endpoint.Endpoint, string, error) {
+	p.requests = append(p.requests, pageToken)
+	page := 0
+	if pageToken != "" {
+		page, _ = strconv.Atoi(pageToken)
+	}
+	if page == p.failingPage && p.failures > 0 {
+		p.failures--
+		return nil, "", errors.New("rate limited")
+	}
+	if page+1 == len(p.RecordsStore) {
+		return p.RecordsStore[page:], "", nil
+	}
+	return p.RecordsStore[page : page+1], strconv.Itoa(page + 1), nil
+}
+
+func TestRunPaged(t *testing.T) {
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return([]*endpoint.Endpoint{
+		endpoint.NewEndpoint("a.example.org", endpoint.RecordTypeA, "1.1.1.1"),
+		endpoint.NewEndpoint("b.example.org", endpoint.RecordTypeA, "3.3.3.3"),
+		endpoint.NewEndpoint("d.example.org", endpoint.RecordTypeA, "4.4.4.4"),
+	}, nil)
+	p := &pagedMockProvider{
+		filteredMockProvider: filteredMockProvider{RecordsStore: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("a.example.org", endpoint.RecordTypeA, "1.1.1.1"),
+			endpoint.NewEndpoint("b.example.org", endpoint.RecordTypeA, "2.2.2.2"),
+			endpoint.NewEndpoint("c.example.org", endpoint.RecordTypeA, "5.5.5.5"),
+		}},
+		failingPage: 1,
+		failures:    2,
+	}
+	r, err := registry.NewNoopRegistry(p)
+	require.NoError(t, err)
+	ctrl := &Controller{
+		Source:             source,
+		Registry:           r,
+		Policy:             &plan.SyncPolicy{},
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+		Pagination:         true,
+		PageRetry:          provider.RetryConfig{MaxRetries: 3, InitialInterval: time.Millisecond, MaxInterval: time.Millisecond},
+	}
+
+	require.NoError(t, ctrl.RunOnce(context.Background()))
+	assert.Zero(t, p.RecordsCallCount)
+	// the failing page is retried on its own
+	assert.Equal(t, []string{"", "1", "1", "1", "2"}, p.requests)
+
+	// the unchanged first page has no changes, then every page and the records on no page are
+	// applied in turn
+	require.Len(t, p.ApplyChangesCalls, 3)
+	require.Len(t, p.ApplyChangesCalls[0].UpdateNew, 1)
+	assert.Equal(t, endpoint.Targets{"3.3.3.3"}, p.ApplyChangesCalls[0].UpdateNew[0].Targets)
+	require.Len(t, p.ApplyChangesCalls[1].Delete, 1)
+	assert.Equal(t, "c.example.org", p.ApplyChangesCalls[1].Delete[0].DNSName)
+	require.Len(t, p.ApplyChangesCalls[2].Create, 1)
+	assert.Equal(t, "d.example.org", p.ApplyChangesCalls[2].Create[0].DNSName)
+}
+
+func TestRunOncePaginationWithoutPages(t *testing.T) {
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return([]*endpoint.Endpoint{
+		endpoint.NewEndpoint("a.example.org", endpoint.RecordTypeA, "1.1.1.1"),
+	}, nil)
+	p := &filteredMockProvider{}
+	r, err := registry.NewTXTRegistry(p, "", "", "owner", 0, "", nil, nil, false, nil)
+	require.NoError(t, err)
+	ctrl := &Controller{
+		Source:             source,
+		Registry:           r,
+		Policy:             &plan.SyncPolicy{},
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+		Pagination:         true,
+	}
+
+	// the provider has no pages, its records are listed once by the usual reconciliation
+	require.NoError(t, ctrl.RunOnce(context.Background()))
+	assert.Equal(t, 1, p.RecordsCallCount)
+	require.Len(t, p.ApplyChangesCalls, 1)
+	assert.Equal(t, "a.example.org", p.ApplyChangesCalls[0].Create[0].DNSName)
+}
+
+func TestRunPagedReadFailure(t *testing.T) {
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return([]*endpoint.Endpoint{
+		endpoint.NewEndpoint("d.example.org", endpoint.RecordTypeA, "4.4.4.4"),
+	}, nil)
+	p := &pagedMockProvider{
+		filteredMockProvider: filteredMockProvider{RecordsStore: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("a.example.org", endpoint.RecordTypeA, "1.1.1.1"),
+			endpoint.NewEndpoint("d.example.org", endpoint.RecordTypeA, "4.4.4.4"),
+		}},
+		failingPage: 1,
+		failures:    5,
+	}
+	r, err := registry.NewNoopRegistry(p)
+	require.NoError(t, err)
+	ctrl := &Controller{
+		Source:             source,
+		Registry:           r,
+		Policy:             &plan.SyncPolicy{},
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+		Pagination:         true,
+		PageRetry:          provider.RetryConfig{MaxRetries: 1, InitialInterval: time.Millisecond, MaxInterval: time.Millisecond},
+	}
+
+	// the records of the pages not read may exist already, they are not created
+	err = ctrl.RunOnce(context.Background())
+	assert.ErrorIs(t, err, provider.SoftError)
+	assert.ErrorContains(t, err, "failed to read page 2 of the records")
+	require.Len(t, p.ApplyChangesCalls, 1)
+	assert.Empty(t, p.ApplyChangesCalls[0].Create)
+	require.Len(t, p.ApplyChangesCalls[0].Delete, 1)
+	assert.Equal(t, "a.example.org", p.ApplyChangesCalls[0].Delete[0].DNSName)
+}
+
+func TestRunPagedBatchesAndDampeningWindows(t *testing.T) {
+	desired := endpoint.NewEndpoint("a.example.org", endpoint.RecordTypeA, "3.3.3.3").WithProviderSpecific(source.DampeningWindowKey, "5m")
+	src := new(testutils.MockSource)
+	src.On("Endpoints").Return([]*endpoint.Endpoint{
+		desired,
+		endpoint.NewEndpoint("b.example.org", endpoint.RecordTypeA, "4.4.4.4"),
+		endpoint.NewEndpoint("c.example.org", endpoint.RecordTypeA, "5.5.5.5"),
+	}, nil)
+	p := &pagedMockProvider{
+		filteredMockProvider: filteredMockProvider{RecordsStore: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("a.example.org", endpoint.RecordTypeA, "1.1.1.1"),
+		}},
+	}
+	r, err := registry.NewNoopRegistry(p)
+	require.NoError(t, err)
+	ctrl := &Controller{
+		Source:             src,
+		Registry:           r,
+		Policy:             &plan.SyncPolicy{},
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+		BatchSize:          1,
+		Pagination:         true,
+	}
+
+	require.NoError(t, ctrl.RunOnce(context.Background()))
+	// the update is applied right away, without the dampening window reaching the provider
+	require.Len(t, p.ApplyChangesCalls, 3)
+	require.Len(t, p.ApplyChangesCalls[0].UpdateNew, 1)
+	assert.Empty(t, p.ApplyChangesCalls[0].UpdateNew[0].ProviderSpecific)
+	// the records on no page are created a batch at a time
+	assert.Len(t, p.ApplyChangesCalls[1].Create, 1)
+	assert.Len(t, p.ApplyChangesCalls[2].Create, 1)
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+// PublishedHostnamesWriter writes the hostnames published for a resource, given by the resource
+// label of its endpoints, e.g. "ingress/default/app", back to the resource. No hostnames are
+// written once all the records of the resource are deleted.
+type PublishedHostnamesWriter func(ctx context.Context, resource string, hostnames []string) error
+
+// NewPublishedHostnamesWriter returns a PublishedHostnamesWriter writing the hostnames to the
+// annotation, comma-separated, and removing the annotation once there are none. Only services,
+// ingresses and DNSEndpoints get the annotation.
+func NewPublishedHostnamesWriter(dynamicClient dynamic.Interface, annotationKey string) PublishedHostnamesWriter {
+	return func(ctx context.Context, resource string, hostnames []string) error {
+		kind, namespace, name, ok := parseResource(resource)
+		if !ok {
+			return nil
+		}
+		// a null value removes the annotation
+		var value interface{}
+		if len(hostnames) > 0 {
+			value = strings.Join(hostnames, ",")
+		}
+		patch, err := json.Marshal(map[string]interface{}{
+			"metadata": map[string]interface{}{"annotations": map[string]interface{}{annotationKey: value}},
+		})
+		if err != nil {
+			return err
+		}
+		_, err = dynamicClient.Resource(kind.gvr).Namespace(namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+		// the records of a deleted resource are deleted too
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+}
+
+// PublishedHostnames writes the hostnames of the records of every resource back to it once the
+// changes creating them are applied, so other controllers can consume the authoritative list of
+// the hostnames published for it.
+type PublishedHostnames struct {
+	Write PublishedHostnamesWriter
+	// written are the hostnames written last for every resource
+	written map[string][]string
+}
+
+// update writes the hostnames of the resources whose published hostnames changed since they were
+// written last. The records are the records after the changes were applied; only the records owned
+// by the owner ID and the records changed are published by this instance.
+func (p *PublishedHostnames) update(ctx context.Context, records []*endpoint.Endpoint, changes *plan.Changes, ownerID string) {
+	if p.written == nil {
+		p.written = map[string][]string{}
+	}
+	published := publishedHostnames(records, changes, ownerID)
+
+	resources := make([]string, 0, len(published)+len(p.written))
+	for resource := range published {
+		resources = append(resources, resource)
+	}
+	for resource := range p.written {
+		if _, ok := published[resource]; !ok {
+			resources = append(resources, resource)
+		}
+	}
+	sort.Strings(resources)
+
+	for _, resource := range resources {
+		hostnames := published[resource]
+		if written, ok := p.written[resource]; ok && slices.Equal(written, hostnames) {
+			continue
+		}
+		if err := p.Write(ctx, resource, hostnames); err != nil {
+			log.Warnf("Failed to write the published hostnames of %s: %v", resource, err)
+			continue
+		}
+		if len(hostnames) == 0 {
+			delete(p.written, resource)
+		} else {
+			p.written[resource] = hostnames
+		}
+	}
+}
+
+// publishedHostnames returns the sorted hostnames of the records of every resource.
+func publishedHostnames(records []*endpoint.Endpoint, changes *plan.Changes, ownerID string) map[string][]string {
+	changed := make(map[*endpoint.Endpoint]struct{}, len(changes.Create)+len(changes.UpdateNew))
+	for _, ep := range append(append([]*endpoint.Endpoint{}, changes.Create...), changes.UpdateNew...) {
+		changed[ep] = struct{}{}
+	}
+
+	published := map[string][]string{}
+	for _, ep := range records {
+		resource := ep.Labels[endpoint.ResourceLabelKey]
+		if resource == "" {
+			continue
+		}
+		if _, ok := changed[ep]; !ok && ep.Labels[endpoint.OwnerLabelKey] != ownerID {
+			continue
+		}
+		if !slices.Contains(published[resource], ep.DNSName) {
+			published[resource] = append(published[resource], ep.DNSName)
+		}
+	}
+	for _, hostnames := range published {
+		sort.Strings(hostnames)
+	}
+	return published
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakeDynamic "k8s.io/client-go/dynamic/fake"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+func ownedEndpoint(owner, resource, dnsName, recordType string, targets ...string) *endpoint.Endpoint {
+	ep := resourceEndpoint(resource, dnsName, recordType, targets...)
+	ep.Labels[endpoint.OwnerLabelKey] = owner
+	return ep
+}
+
+func TestPublishedHostnames(t *testing.T) {
+	written := map[string][]string{}
+	var failure error
+	p := &PublishedHostnames{Write: func(_ context.Context, resource string, hostnames []string) error {
+		if failure != nil {
+			return failure
+		}
+		written[resource] = hostnames
+		return nil
+	}}
+
+	created := resourceEndpoint("ingress/default/app", "www.example.org", endpoint.RecordTypeCNAME, "app.example.org")
+	records := []*endpoint.Endpoint{
+		ownedEndpoint("default", "ingress/default/app", "app.example.org", endpoint.RecordTypeA, "1.1.1.1"),
+		ownedEndpoint("default", "ingress/default/app", "app.example.org", endpoint.RecordTypeAAAA, "::1"),
+		ownedEndpoint("other", "ingress/default/foreign", "foreign.example.org", endpoint.RecordTypeA, "2.2.2.2"),
+		ownedEndpoint("default", "service/default/db", "db.example.org", endpoint.RecordTypeA, "3.3.3.3"),
+		endpoint.NewEndpoint("unlabeled.example.org", endpoint.RecordTypeA, "4.4.4.4"),
+		created,
+	}
+	p.update(context.Background(), records, &plan.Changes{Create: []*endpoint.Endpoint{created}}, "default")
+	assert.Equal(t, map[string][]string{
+		"ingress/default/app": {"app.example.org", "www.example.org"},
+		"service/default/db":  {"db.example.org"},
+	}, written)
+
+	// the hostnames are only written again when they change
+	written = map[string][]string{}
+	p.update(context.Background(), records[:5], &plan.Changes{}, "default")
+	assert.Equal(t, map[string][]string{"ingress/default/app": {"app.example.org"}}, written)
+
+	// the hostnames of the resources whose records were all deleted are removed
+	written = map[string][]string{}
+	p.update(context.Background(), records[:2], &plan.Changes{}, "default")
+	assert.Equal(t, map[string][]string{"service/default/db": nil}, written)
+
+	// the hostnames failing to be written are written again by the next update
+	created = resourceEndpoint("ingress/default/app", "api.example.org", endpoint.RecordTypeCNAME, "app.example.org")
+	failure = errors.New("forbidden")
+	p.update(context.Background(), []*endpoint.Endpoint{records[0], created}, &plan.Changes{Create: []*endpoint.Endpoint{created}}, "default")
+	failure = nil
+	written = map[string][]string{}
+	p.update(context.Background(), []*endpoint.Endpoint{records[0], created}, &plan.Changes{Create: []*endpoint.Endpoint{created}}, "default")
+	assert.Equal(t, map[string][]string{"ingress/default/app": {"api.example.org", "app.example.org"}}, written)
+}
+
+func TestNewPublishedHostnamesWriter(t *testing.T) {
+	const annotationKey = "external-dns.alpha.kubernetes.io/published-hostnames"
+	ingressGVR := schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"}
+	ingress := &unstructured.Unstructured{}
+	ingress.SetAPIVersion("networking.k8s.io/v1")
+	ingress.SetKind("Ingress")
+	ingress.SetNamespace("default")
+	ingress.SetName("app")
+	ingress.SetAnnotations(map[string]string{"other": "value"})
+	service := &unstructured.Unstructured{}
+	service.SetAPIVersion("v1")
+	service.SetKind("Service")
+	service.SetNamespace("default")
+	service.SetName("app")
+
+	dynamicClient := fakeDynamic.NewSimpleDynamicClient(runtime.NewScheme(), ingress, service)
+	write := NewPublishedHostnamesWriter(dynamicClient, annotationKey)
+	annotations := func(gvr schema.GroupVersionResource) map[string]string {
+		object, err := dynamicClient.Resource(gvr).Namespace("default").Get(context.Background(), "app", metav1.GetOptions{})
+		require.NoError(t, err)
+		return object.GetAnnotations()
+	}
+
+	// the annotation is set, comma-separated, without touching the other annotations
+	require.NoError(t, write(context.Background(), "ingress/default/app", []string{"a.example.org", "b.example.org"}))
+	assert.Equal(t, map[string]string{"other": "value", annotationKey: "a.example.org,b.example.org"}, annotations(ingressGVR))
+
+	require.NoError(t, write(context.Background(), "service/default/app", []string{"svc.example.org"}))
+	assert.Equal(t, map[string]string{annotationKey: "svc.example.org"}, annotations(schema.GroupVersionResource{Version: "v1", Resource: "services"}))
+
+	// the annotation is removed once there are no hostnames
+	require.NoError(t, write(context.Background(), "ingress/default/app", nil))
+	assert.Equal(t, map[string]string{"other": "value"}, annotations(ingressGVR))
+
+	// the resources are deleted with their records
+	assert.NoError(t, write(context.Background(), "ingress/default/deleted", []string{"a.example.org"}))
+
+	// neither other kinds of resources nor malformed labels get the annotation
+	dynamicClient.ClearActions()
+	assert.NoError(t, write(context.Background(), "pod/default/app", []string{"a.example.org"}))
+	assert.NoError(t, write(context.Background(), "ingress/app", []string{"a.example.org"}))
+	assert.Empty(t, dynamicClient.Actions())
+}
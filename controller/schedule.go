@@ -0,0 +1,97 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/source"
+)
+
+var inactiveRecords = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "external_dns",
+		Subsystem: "controller",
+		Name:      "inactive_records",
+		Help:      "Number of records not published because they are not active yet or expired.",
+	},
+)
+
+func init() {
+	prometheus.MustRegister(inactiveRecords)
+}
+
+// activeEndpoints removes the active-from and active-until properties of the endpoints, which are
+// only read by the controller, and returns the endpoints active at now, so that the records are
+// created once they are active and deleted once they expired. It also returns the next time an
+// endpoint becomes active or expires, zero if none does.
+func activeEndpoints(endpoints []*endpoint.Endpoint, now time.Time) ([]*endpoint.Endpoint, time.Time) {
+	var next time.Time
+	schedule := func(at time.Time) {
+		if next.IsZero() || at.Before(next) {
+			next = at
+		}
+	}
+
+	active := make([]*endpoint.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		from := activeTime(ep, source.ActiveFromKey)
+		until := activeTime(ep, source.ActiveUntilKey)
+		switch {
+		case !from.IsZero() && now.Before(from):
+			log.Debugf("Not publishing %s %s until it is active at %s", ep.DNSName, ep.RecordType, from.Format(time.RFC3339))
+			schedule(from)
+		case !until.IsZero() && !now.Before(until):
+			log.Debugf("Not publishing %s %s since it expired at %s", ep.DNSName, ep.RecordType, until.Format(time.RFC3339))
+		default:
+			if !until.IsZero() {
+				schedule(until)
+			}
+			active = append(active, ep)
+		}
+	}
+	inactiveRecords.Set(float64(len(endpoints) - len(active)))
+	return active, next
+}
+
+// activeTime removes the property of the endpoint and returns its time, zero if it has none or it
+// isn't valid.
+func activeTime(ep *endpoint.Endpoint, name string) time.Time {
+	value, ok := ep.GetProviderSpecificProperty(name)
+	if !ok {
+		return time.Time{}
+	}
+	// the properties are shared by the endpoints of a resource, so they are copied
+	properties := make(endpoint.ProviderSpecific, 0, len(ep.ProviderSpecific)-1)
+	for _, p := range ep.ProviderSpecific {
+		if p.Name != name {
+			properties = append(properties, p)
+		}
+	}
+	ep.ProviderSpecific = properties
+
+	at, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		log.Warnf("Ignoring the invalid time %q of %s: %v", value, ep.DNSName, err)
+		return time.Time{}
+	}
+	return at
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/source"
+)
+
+func TestActiveEndpoints(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	scheduled := func(name, from, until string) *endpoint.Endpoint {
+		ep := endpoint.NewEndpoint(name, endpoint.RecordTypeA, "1.1.1.1")
+		if from != "" {
+			ep.ProviderSpecific = append(ep.ProviderSpecific, endpoint.ProviderSpecificProperty{Name: source.ActiveFromKey, Value: from})
+		}
+		if until != "" {
+			ep.ProviderSpecific = append(ep.ProviderSpecific, endpoint.ProviderSpecificProperty{Name: source.ActiveUntilKey, Value: until})
+		}
+		return ep
+	}
+
+	properties := endpoint.ProviderSpecific{
+		{Name: source.ActiveFromKey, Value: "2024-03-01T10:00:00Z"},
+		{Name: "alias", Value: "true"},
+	}
+	shared := endpoint.NewEndpoint("shared.example.org", endpoint.RecordTypeA, "1.1.1.1")
+	shared.ProviderSpecific = properties
+
+	endpoints := []*endpoint.Endpoint{
+		endpoint.NewEndpoint("always.example.org", endpoint.RecordTypeA, "1.1.1.1"),
+		scheduled("active.example.org", "2024-03-01T11:00:00Z", "2024-03-01T18:00:00Z"),
+		scheduled("pending.example.org", "2024-03-01T14:00:00+01:00", ""),
+		scheduled("expired.example.org", "", "2024-03-01T12:00:00Z"),
+		scheduled("invalid.example.org", "tomorrow", ""),
+		shared,
+	}
+
+	active, next := activeEndpoints(endpoints, now)
+	var names []string
+	for _, ep := range active {
+		names = append(names, ep.DNSName)
+		_, ok := ep.GetProviderSpecificProperty(source.ActiveFromKey)
+		assert.False(t, ok)
+		_, ok = ep.GetProviderSpecificProperty(source.ActiveUntilKey)
+		assert.False(t, ok)
+	}
+	assert.Equal(t, []string{"always.example.org", "active.example.org", "invalid.example.org", "shared.example.org"}, names)
+	assert.Equal(t, time.Date(2024, time.March, 1, 13, 0, 0, 0, time.UTC), next.UTC())
+	assert.Equal(t, endpoint.ProviderSpecific{{Name: "alias", Value: "true"}}, shared.ProviderSpecific)
+	assert.Len(t, properties, 2)
+
+	_, next = activeEndpoints([]*endpoint.Endpoint{endpoint.NewEndpoint("always.example.org", endpoint.RecordTypeA, "1.1.1.1")}, now)
+	assert.True(t, next.IsZero())
+}
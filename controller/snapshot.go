@@ -0,0 +1,112 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+// SnapshotStore persists the records external-dns applied last, so a restart can reconcile the
+// drift against them before listing all records of the registry.
+type SnapshotStore interface {
+	// Load returns the records of the last snapshot, or no records if there is none.
+	Load() ([]*endpoint.Endpoint, error)
+	// Save replaces the snapshot by the records.
+	Save(records []*endpoint.Endpoint) error
+}
+
+// FileSnapshot stores the snapshot as JSON in a file, e.g. on a persistent volume.
+type FileSnapshot struct {
+	Path string
+}
+
+// Load reads the records of the snapshot file. A missing file is an empty snapshot.
+func (s FileSnapshot) Load() ([]*endpoint.Endpoint, error) {
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	var records []*endpoint.Endpoint
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot %s: %w", s.Path, err)
+	}
+	return records, nil
+}
+
+// Save writes the records to a temporary file renamed to the snapshot file, so a crash never
+// leaves a partial snapshot.
+func (s FileSnapshot) Save(records []*endpoint.Endpoint) error {
+	data, err := json.Marshal(records)
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+
+	if err := writeFileAtomically(s.Path, data); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomically writes the data to a temporary file renamed to the file.
+func writeFileAtomically(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// appliedRecords returns the records after the changes were applied to them.
+func appliedRecords(records []*endpoint.Endpoint, changes *plan.Changes) []*endpoint.Endpoint {
+	removed := make(map[endpoint.EndpointKey]struct{}, len(changes.Delete)+len(changes.UpdateOld))
+	for _, e := range changes.Delete {
+		removed[e.Key()] = struct{}{}
+	}
+	for _, e := range changes.UpdateOld {
+		removed[e.Key()] = struct{}{}
+	}
+
+	applied := make([]*endpoint.Endpoint, 0, len(records)+len(changes.Create))
+	for _, e := range records {
+		if _, ok := removed[e.Key()]; !ok {
+			applied = append(applied, e)
+		}
+	}
+	applied = append(applied, changes.Create...)
+	return append(applied, changes.UpdateNew...)
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/registry"
+)
+
+func TestFileSnapshot(t *testing.T) {
+	s := FileSnapshot{Path: filepath.Join(t.TempDir(), "snapshot.json")}
+
+	records, err := s.Load()
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	saved := []*endpoint.Endpoint{
+		endpoint.NewEndpointWithTTL("a.example.org", endpoint.RecordTypeA, 300, "1.1.1.1"),
+		endpoint.NewEndpoint("b.example.org", endpoint.RecordTypeCNAME, "lb.example.com").WithSetIdentifier("blue"),
+	}
+	for _, e := range saved {
+		e.Labels[endpoint.OwnerLabelKey] = "default"
+	}
+	require.NoError(t, s.Save(saved))
+
+	records, err = s.Load()
+	require.NoError(t, err)
+	assert.Equal(t, saved, records)
+
+	require.NoError(t, os.WriteFile(s.Path, []byte("{"), 0o600))
+	_, err = s.Load()
+	assert.Error(t, err)
+}
+
+func TestAppliedRecords(t *testing.T) {
+	a := endpoint.NewEndpoint("a.example.org", endpoint.RecordTypeA, "1.1.1.1")
+	b := endpoint.NewEndpoint("b.example.org", endpoint.RecordTypeA, "2.2.2.2")
+	newB := endpoint.NewEndpoint("b.example.org", endpoint.RecordTypeA, "2.2.2.3")
+	c := endpoint.NewEndpoint("c.example.org", endpoint.RecordTypeA, "3.3.3.3")
+
+	assert.Equal(t, []*endpoint.Endpoint{c, newB}, appliedRecords(
+		[]*endpoint.Endpoint{a, b},
+		&plan.Changes{Create: []*endpoint.Endpoint{c}, UpdateOld: []*endpoint.Endpoint{b}, UpdateNew: []*endpoint.Endpoint{newB}, Delete: []*endpoint.Endpoint{a}},
+	))
+}
+
+func TestRunOnceReconcilesDriftAgainstSnapshot(t *testing.T) {
+	a := endpoint.NewEndpoint("a.example.org", endpoint.RecordTypeA, "1.1.1.1")
+	b := endpoint.NewEndpoint("b.example.org", endpoint.RecordTypeA, "2.2.2.2")
+	c := endpoint.NewEndpoint("c.example.org", endpoint.RecordTypeA, "3.3.3.3")
+
+	snapshot := FileSnapshot{Path: filepath.Join(t.TempDir(), "snapshot.json")}
+	require.NoError(t, snapshot.Save([]*endpoint.Endpoint{a, b}))
+
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return([]*endpoint.Endpoint{a, b, c}, nil)
+	provider := &filteredMockProvider{RecordsStore: []*endpoint.Endpoint{a, b}}
+	r, err := registry.NewNoopRegistry(provider)
+	require.NoError(t, err)
+
+	ctrl := &Controller{
+		Source:             source,
+		Registry:           r,
+		Policy:             &plan.SyncPolicy{},
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+		Interval:           time.Hour,
+		Snapshot:           snapshot,
+	}
+
+	// the first reconciliation doesn't list the records and schedules a full one
+	require.True(t, ctrl.ShouldRunOnce(time.Now()))
+	require.NoError(t, ctrl.RunOnce(context.Background()))
+	assert.Equal(t, 0, provider.RecordsCallCount)
+	require.Len(t, provider.ApplyChangesCalls, 1)
+	assert.Equal(t, []*endpoint.Endpoint{c}, provider.ApplyChangesCalls[0].Create)
+	assert.True(t, ctrl.ShouldRunOnce(time.Now()))
+
+	records, err := snapshot.Load()
+	require.NoError(t, err)
+	assert.Len(t, records, 3)
+
+	// the following reconciliations list the records
+	require.NoError(t, ctrl.RunOnce(context.Background()))
+	assert.Equal(t, 1, provider.RecordsCallCount)
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+const (
+	// defaultWarningInterval is the interval after which a warning that persists is posted again.
+	defaultWarningInterval = time.Hour
+	// maxWarningsPerRun is the number of warnings posted by a reconciliation at most, the others
+	// are posted by the next reconciliations.
+	maxWarningsPerRun = 20
+)
+
+// The reasons of the warnings about misconfigured resources.
+const (
+	WarningInvalidHostname  = "InvalidHostname"
+	WarningHostnameConflict = "HostnameConflict"
+	WarningDomainFiltered   = "DomainFiltered"
+)
+
+// WarningRecorder posts a warning about a resource, given by the resource label of its endpoints,
+// e.g. "ingress/default/app".
+type WarningRecorder func(resource, reason, message string)
+
+type warning struct {
+	resource string
+	reason   string
+	message  string
+}
+
+// Warnings posts a warning about the resources whose endpoints are invalid, conflict with the
+// endpoints of another resource or are outside of the domain filter. Every warning is posted once
+// per Interval, defaultWarningInterval if zero, as long as the problem persists, instead of being
+// logged on every reconciliation.
+type Warnings struct {
+	Record   WarningRecorder
+	Interval time.Duration
+	// posted is when every warning was posted last
+	posted map[warning]time.Time
+}
+
+// check posts the warnings about the resources of the endpoints not posted within the interval.
+func (w *Warnings) check(endpoints []*endpoint.Endpoint, domainFilter endpoint.MatchAllDomainFilters, now time.Time) {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = defaultWarningInterval
+	}
+	if w.posted == nil {
+		w.posted = map[warning]time.Time{}
+	}
+	for warning, at := range w.posted {
+		if now.Sub(at) >= interval {
+			delete(w.posted, warning)
+		}
+	}
+
+	posted := 0
+	for _, warning := range findWarnings(endpoints, domainFilter) {
+		if _, ok := w.posted[warning]; ok {
+			continue
+		}
+		if posted == maxWarningsPerRun {
+			return
+		}
+		w.Record(warning.resource, warning.reason, warning.message)
+		w.posted[warning] = now
+		posted++
+	}
+}
+
+// findWarnings returns the warnings about the resources of the endpoints, sorted by resource.
+// Endpoints without a resource label are ignored.
+func findWarnings(endpoints []*endpoint.Endpoint, domainFilter endpoint.MatchAllDomainFilters) []warning {
+	found := map[warning]bool{}
+	byKey := map[endpoint.EndpointKey][]*endpoint.Endpoint{}
+	for _, ep := range endpoints {
+		resource := ep.Labels[endpoint.ResourceLabelKey]
+		if resource == "" {
+			continue
+		}
+		if err := endpoint.ValidateDNSName(ep.DNSName); err != nil {
+			found[warning{resource, WarningInvalidHostname, fmt.Sprintf("The hostname of the %s record is invalid: %v", ep.RecordType, err)}] = true
+			continue
+		}
+		if !domainFilter.Match(ep.DNSName) {
+			found[warning{resource, WarningDomainFiltered, fmt.Sprintf("The %s record %s is outside of the domains managed by external-dns", ep.RecordType, ep.DNSName)}] = true
+			continue
+		}
+		key := ep.Key()
+		byKey[key] = append(byKey[key], ep)
+	}
+
+	for key, eps := range byKey {
+		for _, ep := range eps {
+			for _, other := range eps {
+				resource, otherResource := ep.Labels[endpoint.ResourceLabelKey], other.Labels[endpoint.ResourceLabelKey]
+				if resource == otherResource || ep.Targets.Same(other.Targets) {
+					continue
+				}
+				found[warning{resource, WarningHostnameConflict, fmt.Sprintf("The %s record %s is also desired by %s with other targets", key.RecordType, key.DNSName, otherResource)}] = true
+			}
+		}
+	}
+
+	warnings := make([]warning, 0, len(found))
+	for warning := range found {
+		warnings = append(warnings, warning)
+	}
+	sort.Slice(warnings, func(i, j int) bool {
+		if warnings[i].resource != warnings[j].resource {
+			return warnings[i].resource < warnings[j].resource
+		}
+		return warnings[i].message < warnings[j].message
+	})
+	return warnings
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func resourceEndpoint(resource, dnsName, recordType string, targets ...string) *endpoint.Endpoint {
+	ep := endpoint.NewEndpoint(dnsName, recordType, targets...)
+	ep.Labels[endpoint.ResourceLabelKey] = resource
+	return ep
+}
+
+func TestFindWarnings(t *testing.T) {
+	domainFilter := endpoint.NewDomainFilter([]string{"example.org"})
+	warnings := findWarnings([]*endpoint.Endpoint{
+		resourceEndpoint("ingress/default/a", "a.example.org", endpoint.RecordTypeA, "1.1.1.1"),
+		resourceEndpoint("ingress/default/b", "a.example.org", endpoint.RecordTypeA, "2.2.2.2"),
+		resourceEndpoint("ingress/default/c", "a.example.org", endpoint.RecordTypeA, "1.1.1.1"),
+		resourceEndpoint("service/default/d", "in_valid!.example.org", endpoint.RecordTypeA, "1.1.1.1"),
+		resourceEndpoint("service/default/e", "e.example.com", endpoint.RecordTypeA, "1.1.1.1"),
+		endpoint.NewEndpoint("f.example.com", endpoint.RecordTypeA, "1.1.1.1"),
+	}, endpoint.MatchAllDomainFilters{&domainFilter})
+
+	var reasons []string
+	for _, w := range warnings {
+		reasons = append(reasons, w.resource+" "+w.reason)
+	}
+	assert.Equal(t, []string{
+		"ingress/default/a HostnameConflict",
+		"ingress/default/b HostnameConflict",
+		"ingress/default/b HostnameConflict",
+		"ingress/default/c HostnameConflict",
+		"service/default/d InvalidHostname",
+		"service/default/e DomainFiltered",
+	}, reasons)
+	assert.Equal(t, "The A record a.example.org is also desired by ingress/default/b with other targets", warnings[0].message)
+}
+
+func TestWarningsDeduplicated(t *testing.T) {
+	var recorded []string
+	w := &Warnings{
+		Record: func(resource, reason, _ string) {
+			recorded = append(recorded, resource+" "+reason)
+		},
+		Interval: time.Hour,
+	}
+	endpoints := []*endpoint.Endpoint{resourceEndpoint("ingress/default/a", "in_valid!.example.org", endpoint.RecordTypeA, "1.1.1.1")}
+	now := time.Now()
+
+	w.check(endpoints, nil, now)
+	w.check(endpoints, nil, now.Add(time.Minute))
+	assert.Equal(t, []string{"ingress/default/a InvalidHostname"}, recorded)
+
+	// a warning that persists is posted again after the interval
+	w.check(endpoints, nil, now.Add(time.Hour))
+	assert.Len(t, recorded, 2)
+}
+
+func TestWarningsRateLimited(t *testing.T) {
+	recorded := 0
+	w := &Warnings{Record: func(string, string, string) { recorded++ }}
+	var endpoints []*endpoint.Endpoint
+	for i := 0; i < maxWarningsPerRun+5; i++ {
+		endpoints = append(endpoints, resourceEndpoint(fmt.Sprintf("ingress/default/%02d", i), "in_valid!.example.org", endpoint.RecordTypeA, "1.1.1.1"))
+	}
+	now := time.Now()
+
+	w.check(endpoints, nil, now)
+	require.Equal(t, maxWarningsPerRun, recorded)
+	// the others are posted by the next reconciliation
+	w.check(endpoints, nil, now.Add(time.Minute))
+	assert.Equal(t, maxWarningsPerRun+5, recorded)
+}
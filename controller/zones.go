@@ -0,0 +1,263 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+const (
+	// defaultZoneRetryInitialInterval is the wait before the first retry of the changes of a failed zone.
+	defaultZoneRetryInitialInterval = 10 * time.Second
+	// defaultZoneRetryMaxInterval caps the wait between retries of the changes of a failed zone.
+	defaultZoneRetryMaxInterval = 10 * time.Minute
+)
+
+var (
+	zoneConsecutiveFailures = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "external_dns",
+			Subsystem: "controller",
+			Name:      "zone_consecutive_failures",
+			Help:      "Number of consecutive failures to apply the changes of a zone.",
+		},
+		[]string{"zone"},
+	)
+	zoneErrorsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "external_dns",
+			Subsystem: "controller",
+			Name:      "zone_errors_total",
+			Help:      "Number of failures to apply the changes of a zone.",
+		},
+		[]string{"zone"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(zoneConsecutiveFailures)
+	prometheus.MustRegister(zoneErrorsTotal)
+}
+
+// zoneState is the state of a zone whose changes failed to apply.
+type zoneState struct {
+	failures int
+	retryAt  time.Time
+}
+
+// zoneQueue applies the changes of every zone separately, so the changes of healthy zones are
+// applied while the changes of failed zones are retried with exponential backoff.
+type zoneQueue struct {
+	initialInterval time.Duration
+	maxInterval     time.Duration
+	// concurrency is the maximum number of zones whose changes are applied at once, one if not set
+	concurrency int
+	// mu guards failed while the changes of zones are applied concurrently
+	mu     sync.Mutex
+	failed map[string]*zoneState
+}
+
+// backoff returns the wait before the next retry of a zone that failed the given number of times.
+func (q *zoneQueue) backoff(failures int) time.Duration {
+	initial, maxWait := q.initialInterval, q.maxInterval
+	if initial <= 0 {
+		initial = defaultZoneRetryInitialInterval
+	}
+	if maxWait <= 0 {
+		maxWait = defaultZoneRetryMaxInterval
+	}
+	wait := initial
+	for i := 1; i < failures && wait < maxWait; i++ {
+		wait *= 2
+	}
+	if wait > maxWait {
+		wait = maxWait
+	}
+	return wait
+}
+
+// apply applies the changes of every zone whose backoff elapsed. It returns whether changes of
+// zones are pending, because they failed or are still backing off, whether the changes of any zone
+// were applied, and the errors of the failed zones, if any.
+func (q *zoneQueue) apply(ctx context.Context, now time.Time, changes map[string]*plan.Changes, apply func(context.Context, *plan.Changes) error) (pending, applied bool, _ error) {
+	zones := make(map[string]bool, len(changes))
+	for zone := range changes {
+		zones[zone] = true
+	}
+	q.recoverOthers(zones)
+	return q.applyZones(ctx, now, changes, apply)
+}
+
+// applyZones applies the changes of every zone whose backoff elapsed, like apply, without
+// recovering the zones without changes, as the paged reconciliation applies the changes of a
+// zone over several pages. The changes of up to concurrency zones are applied at once.
+func (q *zoneQueue) applyZones(ctx context.Context, now time.Time, changes map[string]*plan.Changes, apply func(context.Context, *plan.Changes) error) (pending, applied bool, _ error) {
+	if q.failed == nil {
+		q.failed = map[string]*zoneState{}
+	}
+
+	zones := make([]string, 0, len(changes))
+	for zone := range changes {
+		zones = append(zones, zone)
+	}
+	sort.Strings(zones)
+
+	ready := make([]string, 0, len(zones))
+	for _, zone := range zones {
+		if state, failed := q.failed[zone]; failed && now.Before(state.retryAt) {
+			log.Warnf("Deferring changes of zone %q after %d failures until %s", zone, state.failures, state.retryAt.Format(time.RFC3339))
+			pending = true
+			continue
+		}
+		ready = append(ready, zone)
+	}
+
+	// the errors are joined in the order of the zones, whichever zone finishes first
+	errs := make([]error, len(ready))
+	var g errgroup.Group
+	g.SetLimit(max(q.concurrency, 1))
+	for i, zone := range ready {
+		i, zone := i, zone
+		g.Go(func() error {
+			err := apply(ctx, changes[zone])
+
+			q.mu.Lock()
+			defer q.mu.Unlock()
+			if err != nil {
+				state := q.fail(zone, now)
+				log.Errorf("Failed to apply changes of zone %q, retrying at %s: %v", zone, state.retryAt.Format(time.RFC3339), err)
+				errs[i] = fmt.Errorf("zone %q: %w", zone, err)
+				return nil
+			}
+			q.recover(zone)
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			pending = true
+		} else {
+			applied = true
+		}
+	}
+	return pending, applied, errors.Join(errs...)
+}
+
+// fail records a failure to apply the changes of the zone, and returns its state.
+func (q *zoneQueue) fail(zone string, now time.Time) *zoneState {
+	state, ok := q.failed[zone]
+	if !ok {
+		state = &zoneState{}
+		q.failed[zone] = state
+	}
+	state.failures++
+	state.retryAt = now.Add(q.backoff(state.failures))
+	zoneErrorsTotal.WithLabelValues(zone).Inc()
+	zoneConsecutiveFailures.WithLabelValues(zone).Set(float64(state.failures))
+	return state
+}
+
+// recoverOthers forgets the failures of the zones without changes, which have recovered, e.g.
+// because the failing records were removed.
+func (q *zoneQueue) recoverOthers(zones map[string]bool) {
+	for zone := range q.failed {
+		if !zones[zone] {
+			q.recover(zone)
+		}
+	}
+}
+
+// recover forgets the failures of the zone.
+func (q *zoneQueue) recover(zone string) {
+	delete(q.failed, zone)
+	zoneConsecutiveFailures.WithLabelValues(zone).Set(0)
+}
+
+// reset forgets the failures of all zones, e.g. because no changes are left to apply.
+func (q *zoneQueue) reset() {
+	for zone := range q.failed {
+		q.recover(zone)
+	}
+}
+
+// nextRetry returns the earliest time a failed zone is retried, the zero time if no zone failed.
+func (q *zoneQueue) nextRetry() time.Time {
+	var next time.Time
+	for _, state := range q.failed {
+		if next.IsZero() || state.retryAt.Before(next) {
+			next = state.retryAt
+		}
+	}
+	return next
+}
+
+// splitChangesByZone splits the changes by the longest of the zones matching the names of the
+// records. Records matching no zone are grouped under the empty zone name, so all the changes are
+// applied together when the zones are unknown, i.e. without --domain-filter for the providers not
+// listing their zones, see provider.ZoneLister. The old and new records of updates are kept in the
+// same order.
+func splitChangesByZone(changes *plan.Changes, zones []string) map[string]*plan.Changes {
+	zoneNames := provider.ZoneIDName{}
+	for _, zone := range zones {
+		zone = strings.Trim(zone, ".")
+		if zone != "" {
+			zoneNames.Add(zone, zone)
+		}
+	}
+
+	result := map[string]*plan.Changes{}
+	forZone := func(e *endpoint.Endpoint) *plan.Changes {
+		zone, _ := zoneNames.FindZone(strings.TrimSuffix(e.DNSName, "."))
+		if _, ok := result[zone]; !ok {
+			result[zone] = &plan.Changes{}
+		}
+		return result[zone]
+	}
+
+	for _, e := range changes.Create {
+		c := forZone(e)
+		c.Create = append(c.Create, e)
+	}
+	for i, e := range changes.UpdateNew {
+		c := forZone(e)
+		c.UpdateNew = append(c.UpdateNew, e)
+		if i < len(changes.UpdateOld) {
+			c.UpdateOld = append(c.UpdateOld, changes.UpdateOld[i])
+		}
+	}
+	for _, e := range changes.Delete {
+		c := forZone(e)
+		c.Delete = append(c.Delete, e)
+	}
+	return result
+}
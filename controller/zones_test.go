@@ -0,0 +1,342 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+	"sigs.k8s.io/external-dns/registry"
+)
+
+func TestSplitChangesByZone(t *testing.T) {
+	changes := &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("a.example.com", endpoint.RecordTypeA, "1.2.3.4"),
+			endpoint.NewEndpoint("a.sub.example.com.", endpoint.RecordTypeA, "1.2.3.4"),
+			endpoint.NewEndpoint("a.example.org", endpoint.RecordTypeA, "1.2.3.4"),
+		},
+		UpdateOld: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("b.sub.example.com", endpoint.RecordTypeA, "1.1.1.1"),
+			endpoint.NewEndpoint("b.example.com", endpoint.RecordTypeA, "1.1.1.1"),
+		},
+		UpdateNew: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("b.sub.example.com", endpoint.RecordTypeA, "2.2.2.2"),
+			endpoint.NewEndpoint("b.example.com", endpoint.RecordTypeA, "2.2.2.2"),
+		},
+		Delete: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("example.com", endpoint.RecordTypeA, "1.2.3.4"),
+		},
+	}
+
+	split := splitChangesByZone(changes, []string{"example.com", ".sub.example.com", ""})
+
+	require.Len(t, split, 3)
+	assert.Equal(t, &plan.Changes{
+		Create:    []*endpoint.Endpoint{changes.Create[0]},
+		UpdateOld: []*endpoint.Endpoint{changes.UpdateOld[1]},
+		UpdateNew: []*endpoint.Endpoint{changes.UpdateNew[1]},
+		Delete:    []*endpoint.Endpoint{changes.Delete[0]},
+	}, split["example.com"])
+	assert.Equal(t, &plan.Changes{
+		Create:    []*endpoint.Endpoint{changes.Create[1]},
+		UpdateOld: []*endpoint.Endpoint{changes.UpdateOld[0]},
+		UpdateNew: []*endpoint.Endpoint{changes.UpdateNew[0]},
+	}, split["sub.example.com"])
+	assert.Equal(t, &plan.Changes{
+		Create: []*endpoint.Endpoint{changes.Create[2]},
+	}, split[""])
+}
+
+func TestZoneQueueBackoff(t *testing.T) {
+	q := &zoneQueue{initialInterval: time.Second, maxInterval: 10 * time.Second}
+	assert.Equal(t, time.Second, q.backoff(1))
+	assert.Equal(t, 2*time.Second, q.backoff(2))
+	assert.Equal(t, 8*time.Second, q.backoff(4))
+	assert.Equal(t, 10*time.Second, q.backoff(5))
+	assert.Equal(t, 10*time.Second, q.backoff(100))
+
+	q = &zoneQueue{}
+	assert.Equal(t, defaultZoneRetryInitialInterval, q.backoff(1))
+	assert.Equal(t, defaultZoneRetryMaxInterval, q.backoff(100))
+}
+
+func TestZoneQueueApply(t *testing.T) {
+	q := &zoneQueue{initialInterval: time.Minute, maxInterval: time.Hour}
+	now := time.Now()
+	changes := map[string]*plan.Changes{
+		"bad.tld":  {Create: []*endpoint.Endpoint{endpoint.NewEndpoint("a.bad.tld", endpoint.RecordTypeA, "1.2.3.4")}},
+		"good.tld": {Create: []*endpoint.Endpoint{endpoint.NewEndpoint("a.good.tld", endpoint.RecordTypeA, "1.2.3.4")}},
+	}
+
+	var applied []string
+	apply := func(ctx context.Context, c *plan.Changes) error {
+		name := c.Create[0].DNSName
+		applied = append(applied, name)
+		if strings.HasSuffix(name, "bad.tld") {
+			return errors.New("zone is broken")
+		}
+		return nil
+	}
+
+	// The healthy zone is applied although the other zone fails.
+	pending, anyApplied, err := q.apply(context.Background(), now, changes, apply)
+	assert.True(t, pending)
+	assert.True(t, anyApplied)
+	assert.ErrorContains(t, err, `zone "bad.tld": zone is broken`)
+	assert.Equal(t, []string{"a.bad.tld", "a.good.tld"}, applied)
+	assert.Equal(t, now.Add(time.Minute), q.nextRetry())
+
+	// The failed zone is deferred until its backoff elapsed.
+	applied = nil
+	pending, anyApplied, err = q.apply(context.Background(), now.Add(30*time.Second), changes, apply)
+	assert.True(t, pending)
+	assert.True(t, anyApplied)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a.good.tld"}, applied)
+
+	// The failed zone is retried and the backoff doubles.
+	applied = nil
+	pending, anyApplied, err = q.apply(context.Background(), now.Add(time.Minute), changes, apply)
+	assert.True(t, pending)
+	assert.True(t, anyApplied)
+	assert.Error(t, err)
+	assert.Equal(t, []string{"a.bad.tld", "a.good.tld"}, applied)
+	assert.Equal(t, 2, q.failed["bad.tld"].failures)
+	assert.Equal(t, now.Add(3*time.Minute), q.nextRetry())
+
+	// The zone recovers once it has no changes anymore.
+	delete(changes, "bad.tld")
+	pending, anyApplied, err = q.apply(context.Background(), now.Add(time.Minute), changes, apply)
+	assert.False(t, pending)
+	assert.True(t, anyApplied)
+	assert.NoError(t, err)
+	assert.Empty(t, q.failed)
+	assert.True(t, q.nextRetry().IsZero())
+
+	// No change is applied when the only zone fails.
+	q = &zoneQueue{initialInterval: time.Minute, maxInterval: time.Hour}
+	pending, anyApplied, err = q.apply(context.Background(), now, map[string]*plan.Changes{"bad.tld": changes["good.tld"]}, func(context.Context, *plan.Changes) error {
+		return errors.New("zone is broken")
+	})
+	assert.True(t, pending)
+	assert.False(t, anyApplied)
+	assert.Error(t, err)
+}
+
+// zoneFailingProvider fails to apply the changes of records in the failing zone.
+type zoneFailingProvider struct {
+	filteredMockProvider
+	failingZone string
+}
+
+func (p *zoneFailingProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	for _, e := range changes.Create {
+		if strings.HasSuffix(e.DNSName, p.failingZone) {
+			return errors.New("zone is broken")
+		}
+	}
+	return p.filteredMockProvider.ApplyChanges(ctx, changes)
+}
+
+func TestRunOnceRetriesFailedZones(t *testing.T) {
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return([]*endpoint.Endpoint{
+		endpoint.NewEndpoint("create-record.bad.tld", endpoint.RecordTypeA, "1.2.3.4"),
+		endpoint.NewEndpoint("create-record.good.tld", endpoint.RecordTypeA, "1.2.3.4"),
+	}, nil)
+
+	p := &zoneFailingProvider{failingZone: "bad.tld"}
+	r, err := registry.NewNoopRegistry(p)
+	require.NoError(t, err)
+
+	ctrl := &Controller{
+		Source:             source,
+		Registry:           r,
+		Policy:             &plan.SyncPolicy{},
+		DomainFilter:       endpoint.NewDomainFilter([]string{"bad.tld", "good.tld"}),
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+		Interval:           time.Hour,
+	}
+
+	require.True(t, ctrl.ShouldRunOnce(time.Now()))
+	err = ctrl.RunOnce(context.Background())
+	assert.ErrorIs(t, err, provider.SoftError)
+	assert.ErrorContains(t, err, `zone "bad.tld"`)
+
+	// The healthy zone was applied.
+	require.Len(t, p.ApplyChangesCalls, 1)
+	assert.Equal(t, "create-record.good.tld", p.ApplyChangesCalls[0].Create[0].DNSName)
+
+	// The failed zone is retried before the next interval.
+	assert.True(t, ctrl.ShouldRunOnce(time.Now().Add(defaultZoneRetryInitialInterval)))
+}
+
+// zoneListingProvider lists its zones, like the providers implementing provider.ZoneLister.
+type zoneListingProvider struct {
+	zoneFailingProvider
+	zones []string
+}
+
+func (p *zoneListingProvider) ZoneNames(ctx context.Context) ([]string, error) {
+	return p.zones, nil
+}
+
+func TestRunOnceRetriesFailedZonesOfTheProvider(t *testing.T) {
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return([]*endpoint.Endpoint{
+		endpoint.NewEndpoint("create-record.bad.tld", endpoint.RecordTypeA, "1.2.3.4"),
+		endpoint.NewEndpoint("create-record.good.tld", endpoint.RecordTypeA, "1.2.3.4"),
+	}, nil)
+
+	p := &zoneListingProvider{zoneFailingProvider: zoneFailingProvider{failingZone: "bad.tld"}, zones: []string{"bad.tld.", "good.tld."}}
+	r, err := registry.NewNoopRegistry(p)
+	require.NoError(t, err)
+
+	// Without --domain-filter, the changes are split by the zones of the provider.
+	ctrl := &Controller{
+		Source:             source,
+		Registry:           r,
+		Policy:             &plan.SyncPolicy{},
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+		Interval:           time.Hour,
+	}
+
+	err = ctrl.RunOnce(context.Background())
+	assert.ErrorIs(t, err, provider.SoftError)
+	assert.ErrorContains(t, err, `zone "bad.tld"`)
+	require.Len(t, p.ApplyChangesCalls, 1)
+	assert.Equal(t, "create-record.good.tld", p.ApplyChangesCalls[0].Create[0].DNSName)
+	assert.True(t, ctrl.ShouldRunOnce(time.Now().Add(defaultZoneRetryInitialInterval)))
+}
+
+// parallelProvider applies the changes of a zone only once the changes of the other zones are
+// being applied too.
+type parallelProvider struct {
+	zoneListingProvider
+	applying sync.WaitGroup
+	mu       sync.Mutex
+}
+
+func (p *parallelProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	p.applying.Done()
+	applying := make(chan struct{})
+	go func() {
+		p.applying.Wait()
+		close(applying)
+	}()
+	select {
+	case <-applying:
+	case <-time.After(5 * time.Second):
+		return errors.New("the changes of the other zones are not applied meanwhile")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.zoneListingProvider.ApplyChanges(ctx, changes)
+}
+
+func TestRunOnceAppliesZonesConcurrently(t *testing.T) {
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return([]*endpoint.Endpoint{
+		endpoint.NewEndpoint("create-record.one.tld", endpoint.RecordTypeA, "1.2.3.4"),
+		endpoint.NewEndpoint("create-record.two.tld", endpoint.RecordTypeA, "1.2.3.4"),
+	}, nil)
+
+	p := &parallelProvider{zoneListingProvider: zoneListingProvider{zoneFailingProvider: zoneFailingProvider{failingZone: "bad.tld"}, zones: []string{"one.tld.", "two.tld."}}}
+	p.applying.Add(2)
+	r, err := registry.NewNoopRegistry(p)
+	require.NoError(t, err)
+
+	ctrl := &Controller{
+		Source:             source,
+		Registry:           r,
+		Policy:             &plan.SyncPolicy{},
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+		Interval:           time.Hour,
+		ZoneConcurrency:    2,
+	}
+
+	require.NoError(t, ctrl.RunOnce(context.Background()))
+	require.Len(t, p.ApplyChangesCalls, 2)
+	assert.ElementsMatch(t, []string{"create-record.one.tld", "create-record.two.tld"}, []string{p.ApplyChangesCalls[0].Create[0].DNSName, p.ApplyChangesCalls[1].Create[0].DNSName})
+}
+
+func TestRunOnceFailsWhenEveryZoneFails(t *testing.T) {
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return([]*endpoint.Endpoint{
+		endpoint.NewEndpoint("create-record.bad.tld", endpoint.RecordTypeA, "1.2.3.4"),
+	}, nil)
+
+	p := &zoneFailingProvider{failingZone: "bad.tld"}
+	r, err := registry.NewNoopRegistry(p)
+	require.NoError(t, err)
+
+	// Without --domain-filter nor the zones of the provider, all changes are applied together as a single zone.
+	ctrl := &Controller{
+		Source:             source,
+		Registry:           r,
+		Policy:             &plan.SyncPolicy{},
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+		Interval:           time.Hour,
+	}
+
+	err = ctrl.RunOnce(context.Background())
+	assert.ErrorContains(t, err, "zone is broken")
+	assert.False(t, errors.Is(err, provider.SoftError))
+}
+
+func TestApplyBatches(t *testing.T) {
+	p := &zoneFailingProvider{failingZone: "c.good.tld"}
+	r, err := registry.NewNoopRegistry(p)
+	require.NoError(t, err)
+	ctrl := &Controller{Registry: r, BatchSize: 2, BatchInterval: time.Millisecond}
+
+	changes := &plan.Changes{Create: []*endpoint.Endpoint{
+		endpoint.NewEndpoint("a.good.tld", endpoint.RecordTypeA, "1.2.3.4"),
+		endpoint.NewEndpoint("b.good.tld", endpoint.RecordTypeA, "1.2.3.4"),
+		endpoint.NewEndpoint("c.good.tld", endpoint.RecordTypeA, "1.2.3.4"),
+	}}
+	apply, applied := ctrl.collectingApply()
+	assert.EqualError(t, apply(context.Background(), changes), "zone is broken")
+
+	// the batches applied before the failure are collected
+	require.Len(t, p.ApplyChangesCalls, 1)
+	assert.Len(t, p.ApplyChangesCalls[0].Create, 2)
+	assert.Equal(t, changes.Create[:2], applied.Create)
+
+	// without a batch size, the changes are applied at once
+	p = &zoneFailingProvider{failingZone: "bad.tld"}
+	r, err = registry.NewNoopRegistry(p)
+	require.NoError(t, err)
+	ctrl = &Controller{Registry: r}
+	apply, _ = ctrl.collectingApply()
+	require.NoError(t, apply(context.Background(), changes))
+	require.Len(t, p.ApplyChangesCalls, 1)
+	assert.Len(t, p.ApplyChangesCalls[0].Create, 3)
+}
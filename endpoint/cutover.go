@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoint
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CutoverStage is a stage of a Cutover, sending a share of the traffic to the new targets from
+// its time on.
+type CutoverStage struct {
+	// The percentage of the traffic sent to the new targets
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	Weight int64 `json:"weight"`
+	// The time the stage starts at
+	At metav1.Time `json:"at"`
+}
+
+// CutoverSpec defines the desired state of Cutover
+type CutoverSpec struct {
+	// The hostname of the DNS record
+	DNSName string `json:"dnsName"`
+	// RecordType type of record, e.g. CNAME, A, AAAA, A if not set
+	// +optional
+	RecordType string `json:"recordType,omitempty"`
+	// TTL for the record
+	// +optional
+	RecordTTL TTL `json:"recordTTL,omitempty"`
+	// The targets the traffic is migrated from
+	FromTargets Targets `json:"fromTargets"`
+	// The targets the traffic is migrated to
+	ToTargets Targets `json:"toTargets"`
+	// The stages of the migration, in the order of their times
+	Stages []CutoverStage `json:"stages"`
+	// The provider specific property of the weights of the records, e.g. aws/weight. Without it,
+	// the targets are switched at once at the first stage sending at least half of the traffic to
+	// the new targets.
+	// +optional
+	WeightProperty string `json:"weightProperty,omitempty"`
+}
+
+// CutoverStatus defines the observed state of Cutover
+type CutoverStatus struct {
+	// The generation observed by the external-dns controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// The number of the stages started
+	// +optional
+	Stage int `json:"stage,omitempty"`
+	// The weight of the current stage
+	// +optional
+	Weight int64 `json:"weight,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// Cutover is a migration of the traffic of a DNS name from targets to others in stages, executed
+// by external-dns at the times of the stages.
+// +k8s:openapi-gen=true
+// +groupName=externaldns.k8s.io
+// +kubebuilder:resource:path=cutovers
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +versionName=v1alpha1
+
+type Cutover struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CutoverSpec   `json:"spec,omitempty"`
+	Status CutoverStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// CutoverList is a list of Cutover objects
+type CutoverList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Cutover `json:"items"`
+}
+
+// Validate returns an error describing every problem of the spec of the Cutover.
+func (c *Cutover) Validate() error {
+	var errs []error
+	if err := ValidateDNSName(c.Spec.DNSName); err != nil {
+		errs = append(errs, fmt.Errorf("spec.dnsName: %w", err))
+	}
+	if len(c.Spec.FromTargets) == 0 {
+		errs = append(errs, errors.New("spec.fromTargets: no targets"))
+	}
+	if len(c.Spec.ToTargets) == 0 {
+		errs = append(errs, errors.New("spec.toTargets: no targets"))
+	}
+	if len(c.Spec.Stages) == 0 {
+		errs = append(errs, errors.New("spec.stages: no stages"))
+	}
+	for i, stage := range c.Spec.Stages {
+		if stage.Weight < 0 || stage.Weight > 100 {
+			errs = append(errs, fmt.Errorf("spec.stages[%d].weight: %d is not between 0 and 100", i, stage.Weight))
+		}
+		if i > 0 && !stage.At.After(c.Spec.Stages[i-1].At.Time) {
+			errs = append(errs, fmt.Errorf("spec.stages[%d].at: %s is not after the previous stage", i, stage.At.UTC().Format(time.RFC3339)))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoint
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCutoverValidate(t *testing.T) {
+	at := func(hour int) metav1.Time {
+		return metav1.NewTime(time.Date(2024, time.March, 1, hour, 0, 0, 0, time.UTC))
+	}
+
+	valid := &Cutover{Spec: CutoverSpec{
+		DNSName:     "app.example.org",
+		FromTargets: Targets{"1.1.1.1"},
+		ToTargets:   Targets{"2.2.2.2"},
+		Stages:      []CutoverStage{{Weight: 10, At: at(10)}, {Weight: 100, At: at(12)}},
+	}}
+	assert.NoError(t, valid.Validate())
+
+	invalid := &Cutover{Spec: CutoverSpec{
+		DNSName:     "app..example.org",
+		FromTargets: Targets{"1.1.1.1"},
+		Stages:      []CutoverStage{{Weight: 10, At: at(10)}, {Weight: 110, At: at(10)}},
+	}}
+	assert.EqualError(t, invalid.Validate(), `spec.dnsName: label "" of "app..example.org" is invalid
+spec.toTargets: no targets
+spec.stages[1].weight: 110 is not between 0 and 100
+spec.stages[1].at: 2024-03-01T10:00:00Z is not after the previous stage`)
+
+	assert.EqualError(t, (&Cutover{Spec: CutoverSpec{DNSName: "app.example.org", FromTargets: Targets{"1.1.1.1"}, ToTargets: Targets{"2.2.2.2"}}}).Validate(),
+		"spec.stages: no stages")
+}
@@ -44,6 +44,10 @@ const (
 	RecordTypePTR = "PTR"
 	// RecordTypeMX is a RecordType enum value
 	RecordTypeMX = "MX"
+	// RecordTypeSSHFP is a RecordType enum value
+	RecordTypeSSHFP = "SSHFP"
+	// RecordTypeNAPTR is a RecordType enum value
+	RecordTypeNAPTR = "NAPTR"
 )
 
 // TTL is a structure defining the TTL of a DNS record
@@ -198,6 +202,11 @@ func NewEndpoint(dnsName, recordType string, targets ...string) *Endpoint {
 func NewEndpointWithTTL(dnsName, recordType string, ttl TTL, targets ...string) *Endpoint {
 	cleanTargets := make([]string, len(targets))
 	for idx, target := range targets {
+		if recordType == RecordTypeNAPTR {
+			// the "." replacement of the NAPTR targets is kept
+			cleanTargets[idx] = NormalizeNAPTRTarget(target)
+			continue
+		}
 		cleanTargets[idx] = strings.TrimSuffix(target, ".")
 	}
 
@@ -277,6 +286,23 @@ func (e *Endpoint) Key() EndpointKey {
 	}
 }
 
+// CanonicalKey returns the EndpointKey of the Endpoint with the canonical form of its DNS name, so
+// that the endpoints of the sources match the records of providers returning names in another case
+// or with a trailing dot.
+func (e *Endpoint) CanonicalKey() EndpointKey {
+	return EndpointKey{
+		DNSName:       CanonicalDNSName(e.DNSName),
+		RecordType:    e.RecordType,
+		SetIdentifier: e.SetIdentifier,
+	}
+}
+
+// CanonicalDNSName returns the canonical form of a DNS name: in lower case, without surrounding
+// spaces nor trailing dot.
+func CanonicalDNSName(name string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+}
+
 // IsOwnedBy returns true if the endpoint owner label matches the given ownerID, false otherwise
 func (e *Endpoint) IsOwnedBy(ownerID string) bool {
 	endpointOwner, ok := e.Labels[OwnerLabelKey]
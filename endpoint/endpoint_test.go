@@ -173,6 +173,15 @@ func TestFilterEndpointsByOwnerID(t *testing.T) {
 	}
 }
 
+func TestCanonicalKey(t *testing.T) {
+	for _, name := range []string{"foo.example.org", "Foo.Example.org.", " foo.example.org. "} {
+		want := EndpointKey{DNSName: "foo.example.org", RecordType: RecordTypeA, SetIdentifier: "set-1"}
+		if got := NewEndpoint(name, RecordTypeA, "1.1.1.1").WithSetIdentifier("set-1").CanonicalKey(); got != want {
+			t.Errorf("CanonicalKey() of %q = %v, want %v", name, got, want)
+		}
+	}
+}
+
 func TestIsOwnedBy(t *testing.T) {
 	type fields struct {
 		Labels Labels
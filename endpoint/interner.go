@@ -0,0 +1,72 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoint
+
+import "strings"
+
+// Interner deduplicates the strings of endpoints, so equal strings share the same memory.
+// Large installations have many endpoints with the same record types, owners, targets and
+// provider specific properties, which otherwise are separate copies, often substrings keeping
+// whole API responses or TXT records alive.
+// An Interner is not safe for concurrent use, it is meant to live for a single listing of records.
+type Interner struct {
+	strings map[string]string
+}
+
+// NewInterner returns an empty Interner.
+func NewInterner() *Interner {
+	return &Interner{strings: map[string]string{}}
+}
+
+// Intern returns the interned copy of the string.
+func (in *Interner) Intern(s string) string {
+	if s == "" {
+		return ""
+	}
+	if interned, ok := in.strings[s]; ok {
+		return interned
+	}
+	// clone the string, it may be a substring of a much larger string
+	s = strings.Clone(s)
+	in.strings[s] = s
+	return s
+}
+
+// InternLabels replaces the keys and values of the labels by their interned copies.
+func (in *Interner) InternLabels(labels Labels) {
+	for k, v := range labels {
+		// assigning an existing key also replaces the stored key by the interned one
+		labels[in.Intern(k)] = in.Intern(v)
+	}
+}
+
+// InternEndpoint replaces the strings of the endpoint shared with other endpoints by their
+// interned copies. The DNS name is kept as is, it is unique to the endpoint in most cases.
+func (in *Interner) InternEndpoint(e *Endpoint) {
+	e.RecordType = in.Intern(e.RecordType)
+	e.SetIdentifier = in.Intern(e.SetIdentifier)
+	for i, target := range e.Targets {
+		e.Targets[i] = in.Intern(target)
+	}
+	in.InternLabels(e.Labels)
+	for i, property := range e.ProviderSpecific {
+		e.ProviderSpecific[i] = ProviderSpecificProperty{
+			Name:  in.Intern(property.Name),
+			Value: in.Intern(property.Value),
+		}
+	}
+}
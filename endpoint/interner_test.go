@@ -0,0 +1,72 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoint
+
+import (
+	"strings"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sameMemory(a, b string) bool {
+	return unsafe.StringData(a) == unsafe.StringData(b)
+}
+
+func TestInternerIntern(t *testing.T) {
+	in := NewInterner()
+
+	text := "heritage=external-dns,external-dns/owner=default"
+	owner := text[strings.LastIndex(text, "=")+1:]
+
+	interned := in.Intern(owner)
+	assert.Equal(t, "default", interned)
+	// the substring is copied, so it doesn't keep the whole text alive
+	assert.False(t, sameMemory(owner, interned))
+
+	again := in.Intern(strings.Clone("default"))
+	assert.True(t, sameMemory(interned, again))
+
+	assert.Equal(t, "", in.Intern(""))
+}
+
+func TestInternerInternEndpoint(t *testing.T) {
+	in := NewInterner()
+
+	newEndpoint := func(name string) *Endpoint {
+		e := NewEndpoint(name, strings.Clone(RecordTypeCNAME), strings.Clone("lb.example.com"))
+		e.Labels[strings.Clone(OwnerLabelKey)] = strings.Clone("default")
+		e.WithProviderSpecific(strings.Clone("alias"), strings.Clone("true"))
+		return e
+	}
+	a := newEndpoint("a.example.com")
+	b := newEndpoint("b.example.com")
+
+	in.InternEndpoint(a)
+	in.InternEndpoint(b)
+
+	assert.Equal(t, "a.example.com", a.DNSName)
+	assert.Equal(t, "b.example.com", b.DNSName)
+	assert.True(t, sameMemory(a.RecordType, b.RecordType))
+	assert.True(t, sameMemory(a.Targets[0], b.Targets[0]))
+	assert.True(t, sameMemory(a.Labels[OwnerLabelKey], b.Labels[OwnerLabelKey]))
+	assert.True(t, sameMemory(a.ProviderSpecific[0].Name, b.ProviderSpecific[0].Name))
+	assert.True(t, sameMemory(a.ProviderSpecific[0].Value, b.ProviderSpecific[0].Value))
+	assert.Equal(t, Labels{OwnerLabelKey: "default"}, a.Labels)
+	assert.Equal(t, ProviderSpecific{{Name: "alias", Value: "true"}}, b.ProviderSpecific)
+}
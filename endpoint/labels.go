@@ -44,6 +44,17 @@ const (
 	// DualstackLabelKey is the name of the label that identifies dualstack endpoints
 	DualstackLabelKey = "dualstack"
 
+	// TTLStepLabelKey is the name of the label that holds the step of a record whose targets are
+	// changed with a lowered TTL
+	TTLStepLabelKey = "ttl-step"
+	// TTLStepSinceLabelKey is the name of the label that holds when a record reached its TTL step,
+	// in seconds since the epoch
+	TTLStepSinceLabelKey = "ttl-step-since"
+
+	// DescriptionLabelKey is the name of the label that holds the description of the change of a
+	// record, for the providers to write it in their comments
+	DescriptionLabelKey = "description"
+
 	// txtEncryptionNonce label for keep same nonce for same txt records, for prevent different result of encryption for same txt record, it can cause issues for some providers
 	txtEncryptionNonce = "txt-encryption-nonce"
 )
@@ -66,11 +77,10 @@ func NewLabelsFromStringPlain(labelText string) (Labels, error) {
 	tokens := strings.Split(labelText, ",")
 	foundExternalDNSHeritage := false
 	for _, token := range tokens {
-		if len(strings.Split(token, "=")) != 2 {
+		key, val, found := strings.Cut(token, "=")
+		if !found || strings.Contains(val, "=") {
 			continue
 		}
-		key := strings.Split(token, "=")[0]
-		val := strings.Split(token, "=")[1]
 		if key == "heritage" && val != heritage {
 			return nil, ErrInvalidHeritage
 		}
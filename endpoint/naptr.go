@@ -0,0 +1,171 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoint
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// NAPTRTarget is the target of a NAPTR record, see RFC 3403 section 4.1.
+type NAPTRTarget struct {
+	Order      uint16
+	Preference uint16
+	Flags      string
+	Service    string
+	Regexp     string
+	// Replacement is the name queried next, "." if the regexp is used instead
+	Replacement string
+}
+
+// ParseNAPTRTarget parses the target of a NAPTR record in the presentation format, e.g.
+// `100 10 "S" "SIP+D2U" "" _sip._udp.example.org`. The flags, the service and the regexp may be
+// quoted, with `\"` and `\\` escaping the quotes and the backslashes.
+func ParseNAPTRTarget(target string) (NAPTRTarget, error) {
+	fields, err := splitCharacterStrings(target)
+	if err != nil {
+		return NAPTRTarget{}, fmt.Errorf("%q is invalid: %w", target, err)
+	}
+	if len(fields) != 6 {
+		return NAPTRTarget{}, fmt.Errorf("%q is not of the form <order> <preference> <flags> <service> <regexp> <replacement>", target)
+	}
+	order, err := strconv.ParseUint(fields[0], 10, 16)
+	if err != nil {
+		return NAPTRTarget{}, fmt.Errorf("order %q of %q is not a 16 bit number", fields[0], target)
+	}
+	preference, err := strconv.ParseUint(fields[1], 10, 16)
+	if err != nil {
+		return NAPTRTarget{}, fmt.Errorf("preference %q of %q is not a 16 bit number", fields[1], target)
+	}
+	if fields[5] != "." {
+		if err := validateDNSName(fields[5], false); err != nil {
+			return NAPTRTarget{}, fmt.Errorf("replacement of %q is invalid: %w", target, err)
+		}
+	}
+	return NAPTRTarget{
+		Order:       uint16(order),
+		Preference:  uint16(preference),
+		Flags:       fields[2],
+		Service:     fields[3],
+		Regexp:      fields[4],
+		Replacement: fields[5],
+	}, nil
+}
+
+// String returns the target in the presentation format with the quoted character-strings and the
+// replacement without trailing dot, the form of the targets of the NAPTR endpoints.
+func (t NAPTRTarget) String() string {
+	replacement := t.Replacement
+	if replacement != "." {
+		replacement = strings.TrimSuffix(replacement, ".")
+	}
+	return t.format(replacement)
+}
+
+// FQDN returns the target in the presentation format with the fully qualified replacement, as
+// expected by the DNS servers.
+func (t NAPTRTarget) FQDN() string {
+	replacement := t.Replacement
+	if !strings.HasSuffix(replacement, ".") {
+		replacement += "."
+	}
+	return t.format(replacement)
+}
+
+func (t NAPTRTarget) format(replacement string) string {
+	return fmt.Sprintf("%d %d %s %s %s %s", t.Order, t.Preference,
+		quoteCharacterString(t.Flags), quoteCharacterString(t.Service), quoteCharacterString(t.Regexp), replacement)
+}
+
+// NormalizeNAPTRTarget returns the target of a NAPTR record in the form of the targets of the
+// NAPTR endpoints, or the target itself if it can't be parsed.
+func NormalizeNAPTRTarget(target string) string {
+	t, err := ParseNAPTRTarget(target)
+	if err != nil {
+		return target
+	}
+	return t.String()
+}
+
+// splitCharacterStrings splits s into its fields separated by white space, unquoting the quoted
+// fields and unescaping the escaped characters, see RFC 1035 section 5.1.
+func splitCharacterStrings(s string) ([]string, error) {
+	var fields []string
+	for i := 0; i < len(s); {
+		if s[i] == ' ' || s[i] == '\t' {
+			i++
+			continue
+		}
+		quoted := s[i] == '"'
+		if quoted {
+			i++
+		}
+		var field strings.Builder
+		closed := false
+		for ; i < len(s); i++ {
+			c := s[i]
+			if c == '\\' {
+				if i+1 == len(s) {
+					return nil, errors.New("trailing backslash")
+				}
+				// \DDD is the character of the decimal value DDD
+				if i+3 < len(s) && isDigits(s[i+1:i+4]) {
+					value, _ := strconv.Atoi(s[i+1 : i+4])
+					if value > 255 {
+						return nil, fmt.Errorf("escaped character \\%s is out of range", s[i+1:i+4])
+					}
+					field.WriteByte(byte(value))
+					i += 3
+					continue
+				}
+				i++
+				field.WriteByte(s[i])
+				continue
+			}
+			if quoted && c == '"' {
+				closed = true
+				i++
+				break
+			}
+			if !quoted && (c == ' ' || c == '\t') {
+				break
+			}
+			field.WriteByte(c)
+		}
+		if quoted && !closed {
+			return nil, errors.New("unterminated quoted string")
+		}
+		fields = append(fields, field.String())
+	}
+	return fields, nil
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// quoteCharacterString quotes s, escaping its quotes and backslashes.
+func quoteCharacterString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNAPTRTarget(t *testing.T) {
+	for _, tc := range []struct {
+		title    string
+		target   string
+		expected NAPTRTarget
+		fqdn     string
+		err      string
+	}{
+		{
+			title:    "replacement",
+			target:   `100 10 "S" "SIP+D2U" "" _sip._udp.example.org.`,
+			expected: NAPTRTarget{Order: 100, Preference: 10, Flags: "S", Service: "SIP+D2U", Replacement: "_sip._udp.example.org."},
+			fqdn:     `100 10 "S" "SIP+D2U" "" _sip._udp.example.org.`,
+		},
+		{
+			title:    "unquoted character-strings",
+			target:   "100  10\tS SIP+D2U \"\" _sip._udp.example.org",
+			expected: NAPTRTarget{Order: 100, Preference: 10, Flags: "S", Service: "SIP+D2U", Replacement: "_sip._udp.example.org"},
+			fqdn:     `100 10 "S" "SIP+D2U" "" _sip._udp.example.org.`,
+		},
+		{
+			title:    "regexp with escapes",
+			target:   `100 20 "U" "E2U+sip" "!^\\+?(.*)$!sip:\"\\1\"@example.org!" .`,
+			expected: NAPTRTarget{Order: 100, Preference: 20, Flags: "U", Service: "E2U+sip", Regexp: `!^\+?(.*)$!sip:"\1"@example.org!`, Replacement: "."},
+			fqdn:     `100 20 "U" "E2U+sip" "!^\\+?(.*)$!sip:\"\\1\"@example.org!" .`,
+		},
+		{
+			title:    "decimal escape",
+			target:   `100 20 "U" "E2U\043sip" "" .`,
+			expected: NAPTRTarget{Order: 100, Preference: 20, Flags: "U", Service: "E2U+sip", Replacement: "."},
+			fqdn:     `100 20 "U" "E2U+sip" "" .`,
+		},
+		{
+			title:  "missing field",
+			target: `100 10 "S" "SIP+D2U" _sip._udp.example.org`,
+			err:    `"100 10 \"S\" \"SIP+D2U\" _sip._udp.example.org" is not of the form <order> <preference> <flags> <service> <regexp> <replacement>`,
+		},
+		{
+			title:  "unterminated quote",
+			target: `100 10 "S" "SIP+D2U _sip._udp.example.org`,
+			err:    `"100 10 \"S\" \"SIP+D2U _sip._udp.example.org" is invalid: unterminated quoted string`,
+		},
+		{
+			title:  "invalid order",
+			target: `70000 10 "S" "SIP+D2U" "" _sip._udp.example.org`,
+			err:    `order "70000" of "70000 10 \"S\" \"SIP+D2U\" \"\" _sip._udp.example.org" is not a 16 bit number`,
+		},
+		{
+			title:  "invalid replacement",
+			target: `100 10 "S" "SIP+D2U" "" sip-.example.org`,
+			err:    `replacement of "100 10 \"S\" \"SIP+D2U\" \"\" sip-.example.org" is invalid: label "sip-" of "sip-.example.org" is invalid`,
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			target, err := ParseNAPTRTarget(tc.target)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, target)
+			assert.Equal(t, tc.fqdn, target.FQDN())
+
+			// the formatted target is parsed again to the same target
+			parsed, err := ParseNAPTRTarget(target.FQDN())
+			require.NoError(t, err)
+			assert.Equal(t, target.FQDN(), parsed.FQDN())
+		})
+	}
+}
+
+func TestNormalizeNAPTRTarget(t *testing.T) {
+	assert.Equal(t, `100 10 "S" "SIP+D2U" "" _sip._udp.example.org`, NormalizeNAPTRTarget(`100 10 S SIP+D2U "" _sip._udp.example.org.`))
+	assert.Equal(t, `100 10 "S" "" "!^.*$!sip:info@example.org!" .`, NormalizeNAPTRTarget(`100 10 S "" "!^.*$!sip:info@example.org!" .`))
+	assert.Equal(t, "invalid", NormalizeNAPTRTarget("invalid"))
+}
+
+func TestNewEndpointNAPTR(t *testing.T) {
+	e := NewEndpoint("example.org", RecordTypeNAPTR, `100 10 S SIP+D2U "" _sip._udp.example.org.`, `100 20 "U" "E2U+sip" "!^.*$!sip:info@example.org!" .`)
+	assert.Equal(t, Targets{`100 10 "S" "SIP+D2U" "" _sip._udp.example.org`, `100 20 "U" "E2U+sip" "!^.*$!sip:info@example.org!" .`}, e.Targets)
+}
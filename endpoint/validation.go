@@ -0,0 +1,177 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoint
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// MaxTTL is the largest TTL of a record, see RFC 2181 section 8.
+const MaxTTL = TTL(2147483647)
+
+// dnsLabelRegex matches a label of a DNS name. Underscores are allowed, as in the names of SRV and
+// TXT records, e.g. _acme-challenge.
+var dnsLabelRegex = regexp.MustCompile(`^[a-zA-Z0-9_]([-a-zA-Z0-9_]*[a-zA-Z0-9_])?$`)
+
+// supportedRecordTypes are the record types whose targets are validated.
+var supportedRecordTypes = []string{
+	RecordTypeA, RecordTypeAAAA, RecordTypeCNAME, RecordTypeMX, RecordTypeNAPTR, RecordTypeNS, RecordTypePTR, RecordTypeSRV, RecordTypeTXT,
+}
+
+// IsSupportedRecordType returns whether the targets of the record type are validated, as opposed to
+// those of the passthrough record types, which are their raw RDATA.
+func IsSupportedRecordType(recordType string) bool {
+	return slices.Contains(supportedRecordTypes, recordType)
+}
+
+// Validate returns an error for each field of the endpoint which a provider would reject, so that
+// invalid endpoints can be rejected before they are applied. The endpoints of the passthrough
+// record types are accepted with any target.
+func (e *Endpoint) Validate(passthroughRecordTypes ...string) error {
+	var errs []error
+	if err := validateDNSName(e.DNSName, true); err != nil {
+		errs = append(errs, fmt.Errorf("dnsName: %w", err))
+	}
+	if e.RecordTTL < 0 || e.RecordTTL > MaxTTL {
+		errs = append(errs, fmt.Errorf("recordTTL: %d is not between 0 and %d", e.RecordTTL, MaxTTL))
+	}
+	if len(e.Targets) == 0 {
+		errs = append(errs, errors.New("targets: at least one target is required"))
+	}
+	if !IsSupportedRecordType(e.RecordType) && !slices.Contains(passthroughRecordTypes, e.RecordType) {
+		errs = append(errs, fmt.Errorf("recordType: unsupported record type %q", e.RecordType))
+	}
+	if e.RecordType == RecordTypeCNAME && len(e.Targets) > 1 {
+		errs = append(errs, errors.New("targets: a CNAME record has a single target"))
+	}
+	for i, target := range e.Targets {
+		if err := validateTarget(e.RecordType, target); err != nil {
+			errs = append(errs, fmt.Errorf("targets[%d]: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SetDefaults sets the TTL of the endpoint to the default TTL unless it is configured.
+func (e *Endpoint) SetDefaults(defaultTTL TTL) {
+	if !e.RecordTTL.IsConfigured() {
+		e.RecordTTL = defaultTTL
+	}
+}
+
+// Validate returns an error for each invalid endpoint of the DNSEndpoint.
+func (d *DNSEndpoint) Validate(passthroughRecordTypes ...string) error {
+	var errs []error
+	for i, e := range d.Spec.Endpoints {
+		if e == nil {
+			errs = append(errs, fmt.Errorf("spec.endpoints[%d]: endpoint is empty", i))
+			continue
+		}
+		if err := e.Validate(passthroughRecordTypes...); err != nil {
+			errs = append(errs, fmt.Errorf("spec.endpoints[%d]: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SetDefaults sets the defaults of the endpoints of the DNSEndpoint.
+func (d *DNSEndpoint) SetDefaults(defaultTTL TTL) {
+	for _, e := range d.Spec.Endpoints {
+		if e != nil {
+			e.SetDefaults(defaultTTL)
+		}
+	}
+}
+
+// ValidateDNSName validates the DNS name of a record, which may have a leading wildcard label.
+func ValidateDNSName(name string) error {
+	return validateDNSName(name, true)
+}
+
+// validateDNSName validates a DNS name following RFC 1123, with an optional trailing dot and,
+// if allowed, a leading wildcard label.
+func validateDNSName(name string, allowWildcard bool) error {
+	name = strings.TrimSuffix(name, ".")
+	if name == "" {
+		return errors.New("name is empty")
+	}
+	if len(name) > 253 {
+		return fmt.Errorf("%q is longer than 253 characters", name)
+	}
+	for i, label := range strings.Split(name, ".") {
+		if i == 0 && allowWildcard && label == "*" {
+			continue
+		}
+		if len(label) > 63 {
+			return fmt.Errorf("label %q of %q is longer than 63 characters", label, name)
+		}
+		if !dnsLabelRegex.MatchString(label) {
+			return fmt.Errorf("label %q of %q is invalid", label, name)
+		}
+	}
+	return nil
+}
+
+// validateTarget validates the syntax of a target of a record of the given type.
+func validateTarget(recordType, target string) error {
+	switch recordType {
+	case RecordTypeA:
+		if ip, err := netip.ParseAddr(target); err != nil || !ip.Is4() {
+			return fmt.Errorf("%q is not an IPv4 address", target)
+		}
+	case RecordTypeAAAA:
+		if ip, err := netip.ParseAddr(target); err != nil || !ip.Is6() {
+			return fmt.Errorf("%q is not an IPv6 address", target)
+		}
+	case RecordTypeCNAME, RecordTypeNS, RecordTypePTR:
+		return validateDNSName(target, false)
+	case RecordTypeMX:
+		fields := strings.Fields(target)
+		if len(fields) != 2 {
+			return fmt.Errorf("%q is not of the form <preference> <exchange>", target)
+		}
+		if _, err := strconv.ParseUint(fields[0], 10, 16); err != nil {
+			return fmt.Errorf("preference %q of %q is invalid", fields[0], target)
+		}
+		return validateDNSName(fields[1], false)
+	case RecordTypeSRV:
+		fields := strings.Fields(target)
+		if len(fields) != 4 {
+			return fmt.Errorf("%q is not of the form <priority> <weight> <port> <target>", target)
+		}
+		for _, field := range fields[:3] {
+			if _, err := strconv.ParseUint(field, 10, 16); err != nil {
+				return fmt.Errorf("%q of %q is not a 16 bit number", field, target)
+			}
+		}
+		// "." means that the service is not available at the domain.
+		if fields[3] == "." {
+			return nil
+		}
+		return validateDNSName(fields[3], false)
+	case RecordTypeNAPTR:
+		_, err := ParseNAPTRTarget(target)
+		return err
+	}
+	return nil
+}
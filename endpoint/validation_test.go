@@ -0,0 +1,184 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoint
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEndpointValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint *Endpoint
+		wantErr  string
+	}{
+		{
+			name:     "A record",
+			endpoint: NewEndpoint("app.example.org", RecordTypeA, "1.2.3.4", "1.2.3.5"),
+		},
+		{
+			name:     "AAAA record",
+			endpoint: NewEndpoint("app.example.org", RecordTypeAAAA, "2001:db8::1"),
+		},
+		{
+			name:     "wildcard CNAME record with trailing dots",
+			endpoint: NewEndpointWithTTL("*.example.org.", RecordTypeCNAME, 300, "lb.example.org."),
+		},
+		{
+			name:     "TXT record with underscore",
+			endpoint: NewEndpoint("_acme-challenge.example.org", RecordTypeTXT, "any text"),
+		},
+		{
+			name:     "MX record",
+			endpoint: NewEndpoint("example.org", RecordTypeMX, "10 mail.example.org"),
+		},
+		{
+			name: "SRV record",
+			endpoint: &Endpoint{
+				DNSName:    "_sip._tcp.example.org",
+				RecordType: RecordTypeSRV,
+				Targets:    Targets{"10 60 5060 sip.example.org", "0 0 0 ."},
+			},
+		},
+		{
+			name:     "NAPTR record",
+			endpoint: NewEndpoint("example.org", RecordTypeNAPTR, `100 10 "S" "SIP+D2U" "" _sip._udp.example.org`, `100 20 "U" "E2U+sip" "!^.*$!sip:info@example.org!" .`),
+		},
+		{
+			name:     "invalid name",
+			endpoint: NewEndpoint("app-.example.org", RecordTypeA, "1.2.3.4"),
+			wantErr:  "dnsName",
+		},
+		{
+			name:     "wildcard not first",
+			endpoint: NewEndpoint("app.*.example.org", RecordTypeA, "1.2.3.4"),
+			wantErr:  "dnsName",
+		},
+		{
+			name:     "unsupported record type",
+			endpoint: NewEndpoint("app.example.org", "A6", "1.2.3.4"),
+			wantErr:  "recordType",
+		},
+		{
+			name:     "no targets",
+			endpoint: NewEndpoint("app.example.org", RecordTypeA),
+			wantErr:  "at least one target",
+		},
+		{
+			name:     "negative TTL",
+			endpoint: NewEndpointWithTTL("app.example.org", RecordTypeA, -1, "1.2.3.4"),
+			wantErr:  "recordTTL",
+		},
+		{
+			name:     "IPv6 target of A record",
+			endpoint: NewEndpoint("app.example.org", RecordTypeA, "2001:db8::1"),
+			wantErr:  "targets[0]",
+		},
+		{
+			name:     "IPv4 target of AAAA record",
+			endpoint: NewEndpoint("app.example.org", RecordTypeAAAA, "1.2.3.4"),
+			wantErr:  "targets[0]",
+		},
+		{
+			name:     "multiple targets of CNAME record",
+			endpoint: NewEndpoint("app.example.org", RecordTypeCNAME, "a.example.org", "b.example.org"),
+			wantErr:  "single target",
+		},
+		{
+			name:     "invalid target of CNAME record",
+			endpoint: NewEndpoint("app.example.org", RecordTypeCNAME, "lb example org"),
+			wantErr:  "targets[0]",
+		},
+		{
+			name:     "MX record without preference",
+			endpoint: NewEndpoint("example.org", RecordTypeMX, "mail.example.org"),
+			wantErr:  "targets[0]",
+		},
+		{
+			name:     "SRV record with invalid port",
+			endpoint: NewEndpoint("_sip._tcp.example.org", RecordTypeSRV, "10 60 70000 sip.example.org"),
+			wantErr:  "targets[0]",
+		},
+		{
+			name:     "NAPTR record without replacement",
+			endpoint: NewEndpoint("example.org", RecordTypeNAPTR, `100 10 "S" "SIP+D2U" ""`),
+			wantErr:  "targets[0]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.endpoint.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want no error", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDNSEndpointValidate(t *testing.T) {
+	d := &DNSEndpoint{Spec: DNSEndpointSpec{Endpoints: []*Endpoint{
+		NewEndpoint("app.example.org", RecordTypeA, "1.2.3.4"),
+		nil,
+		NewEndpoint("app.example.org", RecordTypeAAAA, "1.2.3.4"),
+	}}}
+	err := d.Validate()
+	if err == nil {
+		t.Fatal("Validate() = nil, want error")
+	}
+	for _, want := range []string{"spec.endpoints[1]", "spec.endpoints[2]"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() = %v, want error containing %q", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "spec.endpoints[0]") {
+		t.Errorf("Validate() = %v, want no error for spec.endpoints[0]", err)
+	}
+}
+
+func TestDNSEndpointValidatePassthrough(t *testing.T) {
+	d := &DNSEndpoint{Spec: DNSEndpointSpec{Endpoints: []*Endpoint{
+		NewEndpoint("app.example.org", "LOC", "52 22 23.000 N 4 53 32.000 E -2.00m 0.00m 10000m 10m"),
+	}}}
+	if err := d.Validate(); err == nil || !strings.Contains(err.Error(), "unsupported record type") {
+		t.Errorf("Validate() = %v, want error containing %q", err, "unsupported record type")
+	}
+	if err := d.Validate("LOC", "CERT"); err != nil {
+		t.Errorf("Validate() = %v, want no error", err)
+	}
+}
+
+func TestDNSEndpointSetDefaults(t *testing.T) {
+	d := &DNSEndpoint{Spec: DNSEndpointSpec{Endpoints: []*Endpoint{
+		NewEndpoint("app.example.org", RecordTypeA, "1.2.3.4"),
+		NewEndpointWithTTL("www.example.org", RecordTypeA, 60, "1.2.3.4"),
+		nil,
+	}}}
+	d.SetDefaults(300)
+	if ttl := d.Spec.Endpoints[0].RecordTTL; ttl != 300 {
+		t.Errorf("RecordTTL = %d, want 300", ttl)
+	}
+	if ttl := d.Spec.Endpoints[1].RecordTTL; ttl != 60 {
+		t.Errorf("RecordTTL = %d, want 60", ttl)
+	}
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoint
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The templates of the TXT records of the Verifications.
+const (
+	// VerificationTemplateGoogle is the record of the Google site verification.
+	VerificationTemplateGoogle = "google-site-verification"
+	// VerificationTemplateMS365 is the record of the domain verification of Microsoft 365.
+	VerificationTemplateMS365 = "ms365"
+	// VerificationTemplateACMEPersist is the persistent record binding the domain to an ACME
+	// account, the token being its value, e.g. "letsencrypt.org; accounturi=<account URI>".
+	VerificationTemplateACMEPersist = "acme-persist"
+	// VerificationTemplateCustom is the record of the recordName and value of the Verification.
+	VerificationTemplateCustom = "custom"
+)
+
+// verificationTemplates are the templates of the names and the values of the records of the
+// built-in templates.
+var verificationTemplates = map[string][2]string{
+	VerificationTemplateGoogle:      {"{{ .Domain }}", "google-site-verification={{ .Token }}"},
+	VerificationTemplateMS365:       {"{{ .Domain }}", "MS={{ .Token }}"},
+	VerificationTemplateACMEPersist: {"_validation-persist.{{ .Domain }}", "{{ .Token }}"},
+}
+
+// VerificationSpec defines the desired state of Verification
+type VerificationSpec struct {
+	// The domain verified, e.g. example.org
+	Domain string `json:"domain"`
+	// The template of the record: google-site-verification, ms365, acme-persist or custom
+	// +kubebuilder:validation:Enum=google-site-verification;ms365;acme-persist;custom
+	Template string `json:"template"`
+	// The token given by the provider verifying the domain
+	// +optional
+	Token string `json:"token,omitempty"`
+	// The name of the record of the custom template, a Go template of the domain, e.g. _github-challenge.{{ .Domain }}
+	// +optional
+	RecordName string `json:"recordName,omitempty"`
+	// The value of the record of the custom template, a Go template of the domain and the token
+	// +optional
+	Value string `json:"value,omitempty"`
+	// TTL for the record
+	// +optional
+	RecordTTL TTL `json:"recordTTL,omitempty"`
+	// Whether the provider confirmed the ownership of the domain, the record being removed
+	// retainAfterConfirmation later
+	// +optional
+	Confirmed bool `json:"confirmed,omitempty"`
+	// How long the record is kept after the confirmation, removed right away if not set
+	// +optional
+	RetainAfterConfirmation *metav1.Duration `json:"retainAfterConfirmation,omitempty"`
+}
+
+// VerificationStatus defines the observed state of Verification
+type VerificationStatus struct {
+	// The generation observed by the external-dns controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// The time the confirmation was observed at
+	// +optional
+	ConfirmedAt *metav1.Time `json:"confirmedAt,omitempty"`
+	// Whether the record was removed after the confirmation
+	// +optional
+	Removed bool `json:"removed,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// Verification is a TXT record verifying the ownership of a domain for a provider, published by
+// external-dns until the provider confirmed it.
+// +k8s:openapi-gen=true
+// +groupName=externaldns.k8s.io
+// +kubebuilder:resource:path=verifications
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +versionName=v1alpha1
+
+type Verification struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VerificationSpec   `json:"spec,omitempty"`
+	Status VerificationStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// VerificationList is a list of Verification objects
+type VerificationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Verification `json:"items"`
+}
+
+// Record returns the name and the value of the TXT record of the Verification, rendered from its
+// template.
+func (v *Verification) Record() (string, string, error) {
+	templates, ok := verificationTemplates[v.Spec.Template]
+	if v.Spec.Template == VerificationTemplateCustom {
+		templates, ok = [2]string{v.Spec.RecordName, v.Spec.Value}, true
+	}
+	if !ok {
+		return "", "", fmt.Errorf("unknown template %q", v.Spec.Template)
+	}
+	data := struct{ Domain, Token string }{Domain: strings.TrimSuffix(v.Spec.Domain, "."), Token: v.Spec.Token}
+	var rendered [2]string
+	for i, text := range templates {
+		tmpl, err := template.New(v.Spec.Template).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return "", "", err
+		}
+		var b strings.Builder
+		if err := tmpl.Execute(&b, data); err != nil {
+			return "", "", err
+		}
+		rendered[i] = b.String()
+	}
+	return rendered[0], rendered[1], nil
+}
+
+// Validate returns an error describing every problem of the spec of the Verification.
+func (v *Verification) Validate() error {
+	var errs []error
+	if err := validateDNSName(v.Spec.Domain, false); err != nil {
+		errs = append(errs, fmt.Errorf("spec.domain: %w", err))
+	}
+	if v.Spec.Template == VerificationTemplateCustom {
+		if v.Spec.RecordName == "" {
+			errs = append(errs, errors.New("spec.recordName: required by the custom template"))
+		}
+		if v.Spec.Value == "" {
+			errs = append(errs, errors.New("spec.value: required by the custom template"))
+		}
+	} else if v.Spec.Token == "" {
+		errs = append(errs, fmt.Errorf("spec.token: required by the %s template", v.Spec.Template))
+	}
+	if v.Spec.RetainAfterConfirmation != nil && v.Spec.RetainAfterConfirmation.Duration < 0 {
+		errs = append(errs, errors.New("spec.retainAfterConfirmation: negative duration"))
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	name, value, err := v.Record()
+	if err != nil {
+		return fmt.Errorf("spec.template: %w", err)
+	}
+	if err := validateDNSName(name, false); err != nil {
+		return fmt.Errorf("spec.recordName: %w", err)
+	}
+	if value == "" {
+		return errors.New("spec.value: empty value")
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoint
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestVerificationRecord(t *testing.T) {
+	for _, tt := range []struct {
+		spec          VerificationSpec
+		expectedName  string
+		expectedValue string
+	}{
+		{
+			spec:          VerificationSpec{Domain: "example.org.", Template: VerificationTemplateGoogle, Token: "abc"},
+			expectedName:  "example.org",
+			expectedValue: "google-site-verification=abc",
+		},
+		{
+			spec:          VerificationSpec{Domain: "example.org", Template: VerificationTemplateMS365, Token: "ms12345678"},
+			expectedName:  "example.org",
+			expectedValue: "MS=ms12345678",
+		},
+		{
+			spec:          VerificationSpec{Domain: "example.org", Template: VerificationTemplateACMEPersist, Token: "letsencrypt.org; accounturi=https://acme-v02.api.letsencrypt.org/acme/acct/1"},
+			expectedName:  "_validation-persist.example.org",
+			expectedValue: "letsencrypt.org; accounturi=https://acme-v02.api.letsencrypt.org/acme/acct/1",
+		},
+		{
+			spec:          VerificationSpec{Domain: "example.org", Template: VerificationTemplateCustom, RecordName: "_github-challenge-org.{{ .Domain }}", Value: "{{ .Token }}", Token: "8a2c"},
+			expectedName:  "_github-challenge-org.example.org",
+			expectedValue: "8a2c",
+		},
+	} {
+		t.Run(tt.spec.Template, func(t *testing.T) {
+			v := &Verification{Spec: tt.spec}
+			require.NoError(t, v.Validate())
+			name, value, err := v.Record()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedName, name)
+			assert.Equal(t, tt.expectedValue, value)
+		})
+	}
+}
+
+func TestVerificationValidate(t *testing.T) {
+	assert.EqualError(t, (&Verification{Spec: VerificationSpec{
+		Domain:                  "*.example.org",
+		Template:                VerificationTemplateGoogle,
+		RetainAfterConfirmation: &metav1.Duration{Duration: -time.Hour},
+	}}).Validate(), `spec.domain: label "*" of "*.example.org" is invalid
+spec.token: required by the google-site-verification template
+spec.retainAfterConfirmation: negative duration`)
+
+	assert.EqualError(t, (&Verification{Spec: VerificationSpec{Domain: "example.org", Template: VerificationTemplateCustom}}).Validate(),
+		`spec.recordName: required by the custom template
+spec.value: required by the custom template`)
+
+	assert.EqualError(t, (&Verification{Spec: VerificationSpec{Domain: "example.org", Template: "txt", Token: "abc"}}).Validate(),
+		`spec.template: unknown template "txt"`)
+
+	assert.ErrorContains(t, (&Verification{Spec: VerificationSpec{
+		Domain: "example.org", Template: VerificationTemplateCustom, RecordName: "{{ .Zone }}", Value: "abc",
+	}}).Validate(), "spec.template: ")
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoint
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RecordTypeDS is the type of the delegation signer records of a child zone, see RFC 4034 section 5.
+// The DS records are passed through, see --passthrough-record-types.
+const RecordTypeDS = "DS"
+
+// ZoneDelegationSpec defines the desired state of ZoneDelegation
+type ZoneDelegationSpec struct {
+	// The child zone delegated, e.g. team-a.example.org
+	Zone string `json:"zone"`
+	// The name servers of the child zone, the targets of the NS records of the delegation
+	NameServers Targets `json:"nameServers"`
+	// The DS records of the child zone, in the zone file format, e.g. "12345 13 2 <digest>"
+	// +optional
+	DSRecords Targets `json:"dsRecords,omitempty"`
+	// TTL for the records
+	// +optional
+	RecordTTL TTL `json:"recordTTL,omitempty"`
+	// Whether the delegation is published without checking that the name servers serve the zone
+	// +optional
+	SkipValidation bool `json:"skipValidation,omitempty"`
+}
+
+// ZoneDelegationStatus defines the observed state of ZoneDelegation
+type ZoneDelegationStatus struct {
+	// The generation observed by the external-dns controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Whether the delegation is published
+	// +optional
+	Delegated bool `json:"delegated,omitempty"`
+	// Why the delegation is not published, or why the check of a published delegation fails
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ZoneDelegation is the delegation of a child zone served by other name servers, published by
+// external-dns as the NS and DS records of the child zone in its parent zone.
+// +k8s:openapi-gen=true
+// +groupName=externaldns.k8s.io
+// +kubebuilder:resource:path=zonedelegations
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +versionName=v1alpha1
+
+type ZoneDelegation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ZoneDelegationSpec   `json:"spec,omitempty"`
+	Status ZoneDelegationStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// ZoneDelegationList is a list of ZoneDelegation objects
+type ZoneDelegationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ZoneDelegation `json:"items"`
+}
+
+// Validate returns an error describing every problem of the spec of the ZoneDelegation.
+func (d *ZoneDelegation) Validate() error {
+	var errs []error
+	if err := validateDNSName(d.Spec.Zone, false); err != nil {
+		errs = append(errs, fmt.Errorf("spec.zone: %w", err))
+	}
+	if len(d.Spec.NameServers) == 0 {
+		errs = append(errs, errors.New("spec.nameServers: no name servers"))
+	}
+	for i, nameServer := range d.Spec.NameServers {
+		if err := validateDNSName(nameServer, false); err != nil {
+			errs = append(errs, fmt.Errorf("spec.nameServers[%d]: %w", i, err))
+		}
+	}
+	for i, ds := range d.Spec.DSRecords {
+		if err := validateDS(ds); err != nil {
+			errs = append(errs, fmt.Errorf("spec.dsRecords[%d]: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validateDS validates the syntax of the RDATA of a DS record.
+func validateDS(target string) error {
+	fields := strings.Fields(target)
+	if len(fields) < 4 {
+		return fmt.Errorf("%q is not of the form <key tag> <algorithm> <digest type> <digest>", target)
+	}
+	if _, err := strconv.ParseUint(fields[0], 10, 16); err != nil {
+		return fmt.Errorf("key tag %q of %q is not a 16 bit number", fields[0], target)
+	}
+	for _, field := range fields[1:3] {
+		if _, err := strconv.ParseUint(field, 10, 8); err != nil {
+			return fmt.Errorf("%q of %q is not an 8 bit number", field, target)
+		}
+	}
+	// the digest may be split in several fields
+	if _, err := hex.DecodeString(strings.Join(fields[3:], "")); err != nil {
+		return fmt.Errorf("digest of %q is not hexadecimal", target)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestZoneDelegationValidate(t *testing.T) {
+	valid := &ZoneDelegation{Spec: ZoneDelegationSpec{
+		Zone:        "team-a.example.org",
+		NameServers: Targets{"ns1.example.net", "ns2.example.net."},
+		DSRecords:   Targets{"12345 13 2 3B8E3E8A4D2F6C1E0D7F9A5B 2C4E6F8A0B1C3D5E7F9A1B3C5D7E9F0A2B4C6D8E"},
+	}}
+	assert.NoError(t, valid.Validate())
+
+	invalid := &ZoneDelegation{Spec: ZoneDelegationSpec{
+		Zone:        "*.example.org",
+		NameServers: Targets{"ns1..example.net"},
+		DSRecords:   Targets{"12345 13 2", "123456 13 2 AB", "12345 13 2 XY"},
+	}}
+	assert.EqualError(t, invalid.Validate(), `spec.zone: label "*" of "*.example.org" is invalid
+spec.nameServers[0]: label "" of "ns1..example.net" is invalid
+spec.dsRecords[0]: "12345 13 2" is not of the form <key tag> <algorithm> <digest type> <digest>
+spec.dsRecords[1]: key tag "123456" of "123456 13 2 AB" is not a 16 bit number
+spec.dsRecords[2]: digest of "12345 13 2 XY" is not hexadecimal`)
+
+	assert.EqualError(t, (&ZoneDelegation{Spec: ZoneDelegationSpec{Zone: "team-a.example.org"}}).Validate(),
+		"spec.nameServers: no name servers")
+}
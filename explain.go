@@ -0,0 +1,106 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+
+	"sigs.k8s.io/external-dns/controller"
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/pkg/apis/externaldns"
+)
+
+// explain prints where the records of a DNS name come from, as explained by the --explain-endpoint
+// of a running instance.
+func explain(args []string, out io.Writer) error {
+	app := kingpin.New("external-dns explain", "Explain where the records of a DNS name come from, using the --explain-endpoint of a running ExternalDNS.")
+	app.Version(externaldns.Version)
+	server := app.Flag("server", "The URL of the metrics address of ExternalDNS, e.g. forwarded with kubectl port-forward deploy/external-dns 7979").Default("http://localhost:7979").String()
+	timeout := app.Flag("timeout", "The timeout of the request").Default("10s").Duration()
+	name := app.Arg("dns-name", "The DNS name to explain").Required().String()
+	if _, err := app.Parse(args); err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: *timeout}
+	resp, err := client.Get(strings.TrimSuffix(*server, "/") + controller.ExplainPath + "?name=" + url.QueryEscape(*name))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("explaining %s failed with %s: %s", *name, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	explanation := &controller.Explanation{}
+	if err := json.NewDecoder(resp.Body).Decode(explanation); err != nil {
+		return fmt.Errorf("decoding the explanation of %s: %w", *name, err)
+	}
+	printExplanation(out, explanation)
+	return nil
+}
+
+// printExplanation prints the records of the explanation as a table.
+func printExplanation(out io.Writer, explanation *controller.Explanation) {
+	zone := explanation.Zone
+	if zone == "" {
+		zone = "<none>"
+	}
+	fmt.Fprintf(out, "Name: %s\nZone: %s\n", explanation.Name, zone)
+	if len(explanation.Records) == 0 {
+		fmt.Fprintln(out, "No record is desired by the sources or exists in the provider.")
+		return
+	}
+
+	fmt.Fprintln(out)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TYPE\tSET ID\tTARGETS\tTTL\tSOURCE\tOWNER\tDESIRED\tEXISTS\tLAST APPLIED\tSKIPPED")
+	for _, r := range explanation.Records {
+		lastApplied := "-"
+		if r.LastApplied != nil {
+			lastApplied = r.LastApplied.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%t\t%t\t%s\t%s\n",
+			r.RecordType, orDash(r.SetIdentifier), orDash(r.Targets.String()), ttlOrDash(r),
+			orDash(r.Resource), orDash(r.Labels[endpoint.OwnerLabelKey]), r.Desired, r.Exists, lastApplied, orDash(r.SkipReason))
+	}
+	w.Flush()
+}
+
+func ttlOrDash(r controller.ExplainedRecord) string {
+	if !r.RecordTTL.IsConfigured() {
+		return "-"
+	}
+	return fmt.Sprint(int64(r.RecordTTL))
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
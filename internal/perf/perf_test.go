@@ -0,0 +1,253 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package perf
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"strconv"
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/controller"
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/registry"
+)
+
+const (
+	ownerID = "perf"
+	// driftEvery is the interval of the desired endpoints whose targets differ from the records,
+	// so every plan has changes to compute.
+	driftEvery = 100
+)
+
+var (
+	sizesFlag = flag.String("perf.sizes", "1000,10000,100000", "Comma separated numbers of endpoints of the benchmarks, e.g. 1000000.")
+
+	managedRecordTypes = []string{endpoint.RecordTypeA, endpoint.RecordTypeAAAA, endpoint.RecordTypeCNAME}
+)
+
+func init() {
+	// logging every change would dominate the benchmarks
+	log.SetLevel(log.WarnLevel)
+}
+
+// sizes returns the numbers of endpoints the benchmarks run with.
+func sizes(tb testing.TB) []int {
+	var sizes []int
+	for _, s := range strings.Split(*sizesFlag, ",") {
+		size, err := strconv.Atoi(strings.TrimSpace(s))
+		require.NoError(tb, err, "invalid -perf.sizes")
+		sizes = append(sizes, size)
+	}
+	return sizes
+}
+
+// scenario is an installation whose records were synchronized once with the TXT registry, and
+// whose source has drifted since.
+type scenario struct {
+	provider *Provider
+	registry *registry.TXTRegistry
+	source   *testutils.SyntheticSource
+}
+
+func newScenario(tb testing.TB, size int) *scenario {
+	desired := testutils.SyntheticEndpoints(testutils.SyntheticConfig{
+		Count:   size,
+		Zones:   size/1000 + 1,
+		Targets: 100,
+		Seed:    1,
+	})
+
+	p := NewProvider()
+	r, err := registry.NewTXTRegistry(p, "", "", ownerID, 0, "", managedRecordTypes, nil, false, nil)
+	require.NoError(tb, err)
+
+	created := make([]*endpoint.Endpoint, len(desired))
+	for i, e := range desired {
+		created[i] = e.DeepCopy()
+	}
+	require.NoError(tb, r.ApplyChanges(context.Background(), &plan.Changes{Create: created}))
+
+	for i := 0; i < len(desired); i += driftEvery {
+		desired[i].Targets = endpoint.Targets{"drifted.elb.example.com"}
+		desired[i].RecordType = endpoint.RecordTypeCNAME
+	}
+
+	return &scenario{provider: p, registry: r, source: &testutils.SyntheticSource{Records: desired}}
+}
+
+func (s *scenario) plan(current, desired []*endpoint.Endpoint) *plan.Plan {
+	return &plan.Plan{
+		Policies:       []plan.Policy{&plan.SyncPolicy{}},
+		Current:        current,
+		Desired:        desired,
+		ManagedRecords: managedRecordTypes,
+		OwnerID:        ownerID,
+	}
+}
+
+// TestScenario makes sure the benchmarks measure what they claim to.
+func TestScenario(t *testing.T) {
+	s := newScenario(t, 1000)
+	ctx := context.Background()
+
+	// every endpoint has a TXT record in the new format, all but AAAA records one in the old format too
+	records, err := s.provider.Records(ctx)
+	require.NoError(t, err)
+	txtRecords := 0
+	for _, e := range records {
+		if e.RecordType == endpoint.RecordTypeTXT {
+			txtRecords++
+		}
+	}
+	assert.Equal(t, 1000, len(records)-txtRecords)
+	assert.Greater(t, txtRecords, 1000)
+
+	current, err := s.registry.Records(ctx)
+	require.NoError(t, err)
+	assert.Len(t, current, 1000)
+	for _, e := range current {
+		assert.Equal(t, ownerID, e.Labels[endpoint.OwnerLabelKey])
+	}
+
+	desired, err := s.source.Endpoints(ctx)
+	require.NoError(t, err)
+	changes := s.plan(current, desired).Calculate().Changes
+	assert.True(t, changes.HasChanges())
+	assert.Less(t, len(changes.Create)+len(changes.UpdateNew)+len(changes.Delete), 1000/driftEvery*3)
+}
+
+// BenchmarkPlanCalculate measures the computation of the plan from the records of the registry
+// and the endpoints of the sources.
+func BenchmarkPlanCalculate(b *testing.B) {
+	for _, size := range sizes(b) {
+		b.Run("endpoints="+strconv.Itoa(size), func(b *testing.B) {
+			s := newScenario(b, size)
+			ctx := context.Background()
+			current, err := s.registry.Records(ctx)
+			require.NoError(b, err)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				desired, _ := s.source.Endpoints(ctx)
+				b.StartTimer()
+
+				s.plan(current, desired).Calculate()
+			}
+		})
+	}
+}
+
+// BenchmarkTXTRegistryRecords measures the interleaving of the records with the labels of their
+// TXT records, including the copies of the records made by the provider.
+func BenchmarkTXTRegistryRecords(b *testing.B) {
+	for _, size := range sizes(b) {
+		b.Run("endpoints="+strconv.Itoa(size), func(b *testing.B) {
+			s := newScenario(b, size)
+			ctx := context.Background()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := s.registry.Records(ctx); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkLabelsSerialization measures the serialization of the labels into TXT records and back.
+func BenchmarkLabelsSerialization(b *testing.B) {
+	for _, size := range sizes(b) {
+		b.Run("endpoints="+strconv.Itoa(size), func(b *testing.B) {
+			endpoints := testutils.SyntheticEndpoints(testutils.SyntheticConfig{Count: size, Seed: 1})
+			for _, e := range endpoints {
+				e.Labels[endpoint.OwnerLabelKey] = ownerID
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for _, e := range endpoints {
+					if _, err := endpoint.NewLabelsFromString(e.Labels.Serialize(true, false, nil), nil); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkEndpointsJSON measures the JSON serialization of endpoints, e.g. by the webhook provider.
+func BenchmarkEndpointsJSON(b *testing.B) {
+	for _, size := range sizes(b) {
+		b.Run("endpoints="+strconv.Itoa(size), func(b *testing.B) {
+			endpoints := testutils.SyntheticEndpoints(testutils.SyntheticConfig{Count: size, Seed: 1})
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				data, err := json.Marshal(endpoints)
+				if err != nil {
+					b.Fatal(err)
+				}
+				var decoded []*endpoint.Endpoint
+				if err := json.Unmarshal(data, &decoded); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkRunOnce measures a whole reconciliation of an installation in sync, from listing the
+// records to computing the plan.
+func BenchmarkRunOnce(b *testing.B) {
+	for _, size := range sizes(b) {
+		b.Run("endpoints="+strconv.Itoa(size), func(b *testing.B) {
+			s := newScenario(b, size)
+			ctrl := &controller.Controller{
+				Source:             s.source,
+				Registry:           s.registry,
+				Policy:             &plan.SyncPolicy{},
+				ManagedRecordTypes: managedRecordTypes,
+			}
+			ctx := context.Background()
+			// the first run applies the drift, the following ones find the records in sync
+			require.NoError(b, ctrl.RunOnce(ctx))
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := ctrl.RunOnce(ctx); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package perf contains the benchmarks of the reconciliation of large installations, see
+// docs/contributing/benchmarks.md.
+package perf
+
+import (
+	"context"
+	"sort"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+// Provider is an in-memory provider holding any number of records without validating changes,
+// so the benchmarks measure external-dns rather than the provider.
+type Provider struct {
+	provider.BaseProvider
+	records map[endpoint.EndpointKey]*endpoint.Endpoint
+	// sorted caches the records in a stable order until the next change
+	sorted []*endpoint.Endpoint
+}
+
+// NewProvider returns a provider without records.
+func NewProvider() *Provider {
+	return &Provider{records: map[endpoint.EndpointKey]*endpoint.Endpoint{}}
+}
+
+// Records returns copies of the records, as providers build new endpoints on every call.
+func (p *Provider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	if p.sorted == nil {
+		p.sorted = make([]*endpoint.Endpoint, 0, len(p.records))
+		for _, e := range p.records {
+			p.sorted = append(p.sorted, e)
+		}
+		sort.Slice(p.sorted, func(i, j int) bool {
+			if p.sorted[i].DNSName != p.sorted[j].DNSName {
+				return p.sorted[i].DNSName < p.sorted[j].DNSName
+			}
+			if p.sorted[i].RecordType != p.sorted[j].RecordType {
+				return p.sorted[i].RecordType < p.sorted[j].RecordType
+			}
+			return p.sorted[i].SetIdentifier < p.sorted[j].SetIdentifier
+		})
+	}
+
+	records := make([]*endpoint.Endpoint, len(p.sorted))
+	for i, e := range p.sorted {
+		records[i] = e.DeepCopy()
+		// the labels are stored in TXT records, providers don't return them
+		records[i].Labels = nil
+	}
+	return records, nil
+}
+
+// ApplyChanges applies the changes to the records.
+func (p *Provider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	for _, e := range changes.Delete {
+		delete(p.records, e.Key())
+	}
+	for _, e := range changes.UpdateOld {
+		delete(p.records, e.Key())
+	}
+	for _, e := range changes.Create {
+		p.records[e.Key()] = e.DeepCopy()
+	}
+	for _, e := range changes.UpdateNew {
+		p.records[e.Key()] = e.DeepCopy()
+	}
+	p.sorted = nil
+	return nil
+}
+
+// Len returns the number of records.
+func (p *Provider) Len() int {
+	return len(p.records)
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testutils
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// SyntheticConfig configures the endpoints generated by SyntheticEndpoints.
+type SyntheticConfig struct {
+	// Count is the number of endpoints.
+	Count int
+	// Zones is the number of zones the endpoints are spread over, at least one.
+	Zones int
+	// Targets is the number of distinct load balancers the CNAME endpoints point to, at least one.
+	Targets int
+	// Seed makes the generated endpoints reproducible, the same seed generates the same endpoints.
+	Seed int64
+}
+
+// SyntheticZone returns the name of the i-th zone of the synthetic endpoints.
+func SyntheticZone(i int) string {
+	return fmt.Sprintf("zone-%d.example.org", i)
+}
+
+// SyntheticEndpoints generates endpoints resembling the ones of a large cluster: 60% A records,
+// 20% CNAME records to load balancers, 10% AAAA records and 10% weighted A records with a set
+// identifier and provider specific properties.
+func SyntheticEndpoints(cfg SyntheticConfig) []*endpoint.Endpoint {
+	zones := max(cfg.Zones, 1)
+	targets := max(cfg.Targets, 1)
+	random := rand.New(rand.NewSource(cfg.Seed))
+
+	endpoints := make([]*endpoint.Endpoint, 0, cfg.Count)
+	for i := 0; i < cfg.Count; i++ {
+		name := fmt.Sprintf("service-%d.%s", i, SyntheticZone(i%zones))
+		resource := fmt.Sprintf("service/namespace-%d/service-%d", i%100, i)
+
+		var e *endpoint.Endpoint
+		switch kind := random.Intn(10); {
+		case kind < 6:
+			e = endpoint.NewEndpoint(name, endpoint.RecordTypeA, syntheticIPv4(random))
+		case kind < 8:
+			e = endpoint.NewEndpoint(name, endpoint.RecordTypeCNAME, fmt.Sprintf("lb-%d.elb.example.com", random.Intn(targets)))
+		case kind < 9:
+			e = endpoint.NewEndpoint(name, endpoint.RecordTypeAAAA, fmt.Sprintf("2001:db8::%x", random.Intn(1<<16)))
+		default:
+			e = endpoint.NewEndpoint(name, endpoint.RecordTypeA, syntheticIPv4(random), syntheticIPv4(random))
+			e.WithSetIdentifier("weighted-" + strconv.Itoa(random.Intn(2)))
+			e.WithProviderSpecific("aws/weight", strconv.Itoa(random.Intn(100)))
+		}
+		e.RecordTTL = endpoint.TTL(300)
+		e.Labels[endpoint.ResourceLabelKey] = resource
+		endpoints = append(endpoints, e)
+	}
+	return endpoints
+}
+
+func syntheticIPv4(random *rand.Rand) string {
+	return fmt.Sprintf("10.%d.%d.%d", random.Intn(256), random.Intn(256), random.Intn(256))
+}
+
+// SyntheticSource is a source returning copies of a fixed list of endpoints, as sources build
+// new endpoints on every call.
+type SyntheticSource struct {
+	Records []*endpoint.Endpoint
+}
+
+// Endpoints returns copies of the endpoints of the source.
+func (s *SyntheticSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints := make([]*endpoint.Endpoint, len(s.Records))
+	for i, e := range s.Records {
+		endpoints[i] = e.DeepCopy()
+	}
+	return endpoints, nil
+}
+
+// AddEventHandler does nothing, the endpoints of the source never change.
+func (s *SyntheticSource) AddEventHandler(ctx context.Context, handler func()) {
+}
@@ -18,10 +18,13 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -29,18 +32,25 @@ import (
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/aws/aws-sdk-go/service/route53profiles"
 	sd "github.com/aws/aws-sdk-go/service/servicediscovery"
 	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	"k8s.io/klog/v2"
 
 	"sigs.k8s.io/external-dns/controller"
 	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/pkg/acme"
 	"sigs.k8s.io/external-dns/pkg/apis/externaldns"
 	"sigs.k8s.io/external-dns/pkg/apis/externaldns/validation"
+	"sigs.k8s.io/external-dns/pkg/credentials"
+	"sigs.k8s.io/external-dns/pkg/debugdns"
 	"sigs.k8s.io/external-dns/plan"
 	"sigs.k8s.io/external-dns/provider"
 	"sigs.k8s.io/external-dns/provider/akamai"
@@ -49,12 +59,14 @@ import (
 	"sigs.k8s.io/external-dns/provider/awssd"
 	"sigs.k8s.io/external-dns/provider/azure"
 	"sigs.k8s.io/external-dns/provider/bluecat"
+	"sigs.k8s.io/external-dns/provider/bluecat/bam"
 	"sigs.k8s.io/external-dns/provider/civo"
 	"sigs.k8s.io/external-dns/provider/cloudflare"
 	"sigs.k8s.io/external-dns/provider/coredns"
 	"sigs.k8s.io/external-dns/provider/designate"
 	"sigs.k8s.io/external-dns/provider/digitalocean"
 	"sigs.k8s.io/external-dns/provider/dnsimple"
+	"sigs.k8s.io/external-dns/provider/dnsmasq"
 	"sigs.k8s.io/external-dns/provider/dyn"
 	"sigs.k8s.io/external-dns/provider/exoscale"
 	"sigs.k8s.io/external-dns/provider/gandi"
@@ -75,9 +87,11 @@ import (
 	"sigs.k8s.io/external-dns/provider/rfc2136"
 	"sigs.k8s.io/external-dns/provider/safedns"
 	"sigs.k8s.io/external-dns/provider/scaleway"
+	"sigs.k8s.io/external-dns/provider/technitium"
 	"sigs.k8s.io/external-dns/provider/tencentcloud"
 	"sigs.k8s.io/external-dns/provider/transip"
 	"sigs.k8s.io/external-dns/provider/ultradns"
+	"sigs.k8s.io/external-dns/provider/unbound"
 	"sigs.k8s.io/external-dns/provider/vinyldns"
 	"sigs.k8s.io/external-dns/provider/vultr"
 	"sigs.k8s.io/external-dns/provider/webhook"
@@ -86,9 +100,39 @@ import (
 	"sigs.k8s.io/external-dns/source"
 )
 
+// providerHealth reports the health of the provider on /healthz once its circuit breaker is set up.
+var providerHealth atomic.Pointer[provider.CircuitBreakerProvider]
+
+// sourcesHealth reports the health of the sources on /healthz?verbose once they are built.
+var sourcesHealth atomic.Pointer[[]source.Source]
+
+// verboseHealth is the body of /healthz?verbose.
+type verboseHealth struct {
+	Provider    *provider.Health   `json:"provider,omitempty"`
+	Sources     []source.Health    `json:"sources"`
+	WatchErrors source.WatchErrors `json:"watchErrors"`
+}
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "explain" {
+		if err := explain(os.Args[2:], os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}
+
+	// the rollback command runs with the flags of the instance whose syncs it rolls back
+	args := os.Args[1:]
+	var rollbackTo string
+	if len(args) > 0 && args[0] == "rollback" {
+		var err error
+		if rollbackTo, args, err = rollbackArgs(args[1:]); err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	cfg := externaldns.NewConfig()
-	if err := cfg.ParseFlags(os.Args[1:]); err != nil {
+	if err := cfg.ParseFlags(args); err != nil {
 		log.Fatalf("flag parsing error: %v", err)
 	}
 	if cfg.LogFormat == "json" {
@@ -120,32 +164,93 @@ func main() {
 	go serveMetrics(cfg.MetricsAddress)
 	go handleSigterm(cancel)
 
+	if cfg.AnnotationPrefix != "" {
+		source.SetAnnotationPrefix(cfg.AnnotationPrefix)
+	}
+	if cfg.ControllerValue != "" {
+		source.SetControllerAnnotationValue(cfg.ControllerValue)
+	}
+
 	// error is explicitly ignored because the filter is already validated in validation.ValidateConfig
 	labelSelector, _ := labels.Parse(cfg.LabelFilter)
+	fieldSelector, _ := fields.ParseSelector(cfg.FieldFilter)
+
+	gatewayRouteTypes := make([]source.GatewayRouteType, 0, len(cfg.GatewayRouteTypes))
+	for _, s := range cfg.GatewayRouteTypes {
+		routeType, err := source.ParseGatewayRouteType(s)
+		if err != nil {
+			log.Fatal(err)
+		}
+		gatewayRouteTypes = append(gatewayRouteTypes, routeType)
+	}
+	genericCRDMappings := make([]source.GenericCRDMapping, 0, len(cfg.GenericCRDMappings))
+	for _, s := range cfg.GenericCRDMappings {
+		mapping, err := source.ParseGenericCRDMapping(s)
+		if err != nil {
+			log.Fatal(err)
+		}
+		genericCRDMappings = append(genericCRDMappings, mapping)
+	}
+	var targetReadiness *source.TargetReadiness
+	if len(cfg.PodReadinessConditions) > 0 || len(cfg.NodeReadinessConditions) > 0 {
+		podConditions := make([]source.ReadinessCondition, 0, len(cfg.PodReadinessConditions))
+		for _, s := range cfg.PodReadinessConditions {
+			condition, err := source.ParseReadinessCondition(s)
+			if err != nil {
+				log.Fatal(err)
+			}
+			podConditions = append(podConditions, condition)
+		}
+		nodeConditions := make([]source.ReadinessCondition, 0, len(cfg.NodeReadinessConditions))
+		for _, s := range cfg.NodeReadinessConditions {
+			condition, err := source.ParseReadinessCondition(s)
+			if err != nil {
+				log.Fatal(err)
+			}
+			nodeConditions = append(nodeConditions, condition)
+		}
+		targetReadiness = source.NewTargetReadiness(podConditions, nodeConditions, cfg.ReadinessDampening)
+	}
+	var klipperPolicy *source.KlipperPolicy
+	if cfg.KlipperPolicy != "" {
+		klipperPolicy = &source.KlipperPolicy{Policy: cfg.KlipperPolicy, SubsetSize: cfg.KlipperSubsetSize, Target: cfg.KlipperTarget}
+	}
 
 	// Create a source.Config from the flags passed by the user.
 	sourceCfg := &source.Config{
-		Namespace:                      cfg.Namespace,
+		Namespaces:                     cfg.Namespace,
+		ExcludeNamespaces:              cfg.ExcludeNamespaces,
 		AnnotationFilter:               cfg.AnnotationFilter,
 		LabelFilter:                    labelSelector,
+		FieldFilter:                    fieldSelector,
 		IngressClassNames:              cfg.IngressClassNames,
 		FQDNTemplate:                   cfg.FQDNTemplate,
 		CombineFQDNAndAnnotation:       cfg.CombineFQDNAndAnnotation,
 		IgnoreHostnameAnnotation:       cfg.IgnoreHostnameAnnotation,
 		IgnoreIngressTLSSpec:           cfg.IgnoreIngressTLSSpec,
 		IgnoreIngressRulesSpec:         cfg.IgnoreIngressRulesSpec,
+		IngressHostnameSource:          cfg.IngressHostnameSource,
 		GatewayNamespace:               cfg.GatewayNamespace,
 		GatewayLabelFilter:             cfg.GatewayLabelFilter,
+		GatewayRouteTypes:              gatewayRouteTypes,
 		Compatibility:                  cfg.Compatibility,
 		PublishInternal:                cfg.PublishInternal,
+		PublishExternalIPs:             cfg.PublishExternalIPs,
+		MetalLB:                        cfg.MetalLB,
+		MetalLBNamespace:               cfg.MetalLBNamespace,
+		KlipperPolicy:                  klipperPolicy,
 		PublishHostIP:                  cfg.PublishHostIP,
+		PublishNodeSSHFP:               cfg.PublishNodeSSHFP,
 		AlwaysPublishNotReadyAddresses: cfg.AlwaysPublishNotReadyAddresses,
 		ConnectorServer:                cfg.ConnectorSourceServer,
 		CRDSourceAPIVersion:            cfg.CRDSourceAPIVersion,
 		CRDSourceKind:                  cfg.CRDSourceKind,
+		PassthroughRecordTypes:         cfg.PassthroughRecordTypes,
+		GenericCRDMappings:             genericCRDMappings,
 		KubeConfig:                     cfg.KubeConfig,
 		APIServerURL:                   cfg.APIServerURL,
 		ServiceTypeFilter:              cfg.ServiceTypeFilter,
+		LoadBalancerClassFilter:        cfg.LoadBalancerClassFilter,
 		CFAPIEndpoint:                  cfg.CFAPIEndpoint,
 		CFUsername:                     cfg.CFUsername,
 		CFPassword:                     cfg.CFPassword,
@@ -156,12 +261,18 @@ func main() {
 		OCPRouterName:                  cfg.OCPRouterName,
 		UpdateEvents:                   cfg.UpdateEvents,
 		ResolveLoadBalancerHostname:    cfg.ResolveServiceLoadBalancerHostname,
+		ResolveGatewayHostname:         cfg.ResolveGatewayHostnameAddresses,
 		TraefikDisableLegacy:           cfg.TraefikDisableLegacy,
 		TraefikDisableNew:              cfg.TraefikDisableNew,
+		QuarantineSources:              cfg.QuarantineSources,
+		QuarantineMinEndpoints:         cfg.QuarantineMinEndpoints,
+		QuarantineMaxDuration:          cfg.QuarantineMaxDuration,
+		TargetReadiness:                targetReadiness,
+		ExcludeDeletingNodes:           cfg.NodeDeletionEvents,
 	}
 
 	// Lookup all the selected sources by names and pass them the desired configuration.
-	sources, err := source.ByNames(ctx, &source.SingletonClientGenerator{
+	clientGenerator := &source.SingletonClientGenerator{
 		KubeConfig:   cfg.KubeConfig,
 		APIServerURL: cfg.APIServerURL,
 		// If update events are enabled, disable timeout.
@@ -171,10 +282,15 @@ func main() {
 			}
 			return cfg.RequestTimeout
 		}(),
-	}, cfg.Sources, sourceCfg)
+	}
+	if cfg.SourcePermissionsCheck != "" {
+		verifySourcePermissions(ctx, clientGenerator, cfg.Sources, sourceCfg, cfg.SourcePermissionsCheck == "fail")
+	}
+	sources, err := source.ByNames(ctx, clientGenerator, cfg.Sources, sourceCfg)
 	if err != nil {
 		log.Fatal(err)
 	}
+	sourcesHealth.Store(&sources)
 
 	// Filter targets
 	targetFilter := endpoint.NewTargetNetFilterWithExclusions(cfg.TargetNetFilter, cfg.ExcludeTargetNets)
@@ -182,6 +298,16 @@ func main() {
 	// Combine multiple sources into a single, deduplicated source.
 	endpointsSource := source.NewDedupSource(source.NewMultiSource(sources, sourceCfg.DefaultTargets))
 	endpointsSource = source.NewTargetFilterSource(endpointsSource, targetFilter)
+	if cfg.TargetHealthURL != "" {
+		endpointsSource = source.NewTargetHealthSource(endpointsSource, cfg.TargetHealthURL, cfg.TargetHealthFormat, cfg.TargetHealthMetric, cfg.TargetHealthInterval)
+	}
+	if cfg.MaxTargets > 0 {
+		endpointsSource = source.NewTargetLimitSource(endpointsSource, cfg.MaxTargets, cfg.TargetSelection)
+	}
+	endpointsSource, err = source.NewPairingSource(endpointsSource, cfg.DomainFilter, cfg.ApexPairingTemplate)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	// RegexDomainFilter overrides DomainFilter
 	var domainFilter endpoint.DomainFilter
@@ -196,7 +322,7 @@ func main() {
 	zoneTagFilter := provider.NewZoneTagFilter(cfg.AWSZoneTagFilter)
 
 	var awsSession *session.Session
-	if cfg.Provider == "aws" || cfg.Provider == "aws-sd" || cfg.Registry == "dynamodb" {
+	if (cfg.RecordsSnapshot == "" && (cfg.Provider == "aws" || cfg.Provider == "aws-sd")) || cfg.Registry == "dynamodb" {
 		awsSession, err = aws.NewSession(
 			aws.AWSSessionConfig{
 				AssumeRole:           cfg.AWSAssumeRole,
@@ -210,7 +336,14 @@ func main() {
 	}
 
 	var p provider.Provider
-	switch cfg.Provider {
+	providerName := cfg.Provider
+	// the dry-runs against a snapshot of the records don't set up the provider nor need its credentials
+	if cfg.RecordsSnapshot != "" {
+		providerName = "records-snapshot"
+	}
+	switch providerName {
+	case "records-snapshot":
+		p, err = provider.NewSnapshotProvider(cfg.RecordsSnapshot, domainFilter)
 	case "akamai":
 		p, err = akamai.NewAkamaiProvider(
 			akamai.AkamaiConfig{
@@ -222,6 +355,7 @@ func main() {
 				AccessToken:           cfg.AkamaiAccessToken,
 				EdgercPath:            cfg.AkamaiEdgercPath,
 				EdgercSection:         cfg.AkamaiEdgercSection,
+				UseChangelists:        cfg.AkamaiUseChangelists,
 				DryRun:                cfg.DryRun,
 			}, nil)
 	case "alibabacloud":
@@ -229,18 +363,22 @@ func main() {
 	case "aws":
 		p, err = aws.NewAWSProvider(
 			aws.AWSConfig{
-				DomainFilter:         domainFilter,
-				ZoneIDFilter:         zoneIDFilter,
-				ZoneTypeFilter:       zoneTypeFilter,
-				ZoneTagFilter:        zoneTagFilter,
-				BatchChangeSize:      cfg.AWSBatchChangeSize,
-				BatchChangeInterval:  cfg.AWSBatchChangeInterval,
-				EvaluateTargetHealth: cfg.AWSEvaluateTargetHealth,
-				PreferCNAME:          cfg.AWSPreferCNAME,
-				DryRun:               cfg.DryRun,
-				ZoneCacheDuration:    cfg.AWSZoneCacheDuration,
+				DomainFilter:          domainFilter,
+				ZoneIDFilter:          zoneIDFilter,
+				ZoneTypeFilter:        zoneTypeFilter,
+				ZoneTagFilter:         zoneTagFilter,
+				BatchChangeSize:       cfg.AWSBatchChangeSize,
+				BatchChangeSizeBytes:  cfg.AWSBatchChangeSizeBytes,
+				BatchChangeSizeValues: cfg.AWSBatchChangeSizeValues,
+				BatchChangeInterval:   cfg.AWSBatchChangeInterval,
+				EvaluateTargetHealth:  cfg.AWSEvaluateTargetHealth,
+				PreferCNAME:           cfg.AWSPreferCNAME,
+				DryRun:                cfg.DryRun,
+				ZoneCacheDuration:     cfg.AWSZoneCacheDuration,
+				ProfileIDs:            cfg.AWSRoute53ProfileIDs,
 			},
 			route53.New(awsSession),
+			route53profiles.New(awsSession),
 		)
 	case "aws-sd":
 		// Check that only compatible Registry is used with AWS-SD
@@ -252,9 +390,24 @@ func main() {
 	case "azure-dns", "azure":
 		p, err = azure.NewAzureProvider(cfg.AzureConfigFile, domainFilter, zoneNameFilter, zoneIDFilter, cfg.AzureResourceGroup, cfg.AzureUserAssignedIdentityClientID, cfg.DryRun)
 	case "azure-private-dns":
-		p, err = azure.NewAzurePrivateDNSProvider(cfg.AzureConfigFile, domainFilter, zoneIDFilter, cfg.AzureResourceGroup, cfg.AzureUserAssignedIdentityClientID, cfg.DryRun)
+		p, err = azure.NewAzurePrivateDNSProvider(cfg.AzureConfigFile, domainFilter, zoneIDFilter, cfg.AzureResourceGroup, cfg.AzureUserAssignedIdentityClientID, cfg.AzurePrivateDNSVNetIDs, cfg.AzurePrivateDNSCreateVNetLinks, cfg.DryRun)
 	case "bluecat":
 		p, err = bluecat.NewBluecatProvider(cfg.BluecatConfigFile, cfg.BluecatDNSConfiguration, cfg.BluecatDNSServerName, cfg.BluecatDNSDeployType, cfg.BluecatDNSView, cfg.BluecatGatewayHost, cfg.BluecatRootZone, cfg.TXTPrefix, cfg.TXTSuffix, domainFilter, zoneIDFilter, cfg.DryRun, cfg.BluecatSkipTLSVerify)
+	case "bluecat-bam":
+		p, err = bam.NewBAMProvider(
+			bam.Config{
+				DomainFilter:   domainFilter,
+				ZoneIDFilter:   zoneIDFilter,
+				DryRun:         cfg.DryRun,
+				Host:           cfg.BluecatAddressManagerHost,
+				Configuration:  cfg.BluecatDNSConfiguration,
+				View:           cfg.BluecatDNSView,
+				SkipTLSVerify:  cfg.BluecatSkipTLSVerify,
+				DNSServerName:  cfg.BluecatDNSServerName,
+				DNSDeployType:  cfg.BluecatDNSDeployType,
+				DeployInterval: cfg.BluecatDeployInterval,
+			},
+		)
 	case "vinyldns":
 		p, err = vinyldns.NewVinylDNSProvider(domainFilter, zoneIDFilter, cfg.DryRun)
 	case "vultr":
@@ -264,11 +417,11 @@ func main() {
 	case "civo":
 		p, err = civo.NewCivoProvider(domainFilter, cfg.DryRun)
 	case "cloudflare":
-		p, err = cloudflare.NewCloudFlareProvider(domainFilter, zoneIDFilter, cfg.CloudflareProxied, cfg.DryRun, cfg.CloudflareDNSRecordsPerPage)
+		p, err = cloudflare.NewCloudFlareProvider(domainFilter, zoneIDFilter, cfg.CloudflareProxied, cfg.DryRun, cfg.CloudflareDNSRecordsPerPage, vaultRefresher(ctx, cfg, credentials.CloudflareAPIToken, cfg.CloudflareAPITokenVaultPath))
 	case "rcodezero":
 		p, err = rcode0.NewRcodeZeroProvider(domainFilter, cfg.DryRun, cfg.RcodezeroTXTEncrypt)
 	case "google":
-		p, err = google.NewGoogleProvider(ctx, cfg.GoogleProject, domainFilter, zoneIDFilter, cfg.GoogleBatchChangeSize, cfg.GoogleBatchChangeInterval, cfg.GoogleZoneVisibility, cfg.DryRun)
+		p, err = google.NewGoogleProvider(ctx, cfg.GoogleProject, cfg.GoogleCredentialsFile, domainFilter, zoneIDFilter, cfg.GoogleBatchChangeSize, cfg.GoogleBatchChangeInterval, cfg.GoogleZoneVisibility, cfg.DryRun)
 	case "digitalocean":
 		p, err = digitalocean.NewDigitalOceanProvider(ctx, domainFilter, cfg.DryRun, cfg.DigitalOceanAPIPageSize)
 	case "ovh":
@@ -276,27 +429,33 @@ func main() {
 	case "linode":
 		p, err = linode.NewLinodeProvider(domainFilter, cfg.DryRun, externaldns.Version)
 	case "dnsimple":
-		p, err = dnsimple.NewDnsimpleProvider(domainFilter, zoneIDFilter, cfg.DryRun)
+		p, err = dnsimple.NewDnsimpleProvider(domainFilter, zoneIDFilter, cfg.DNSimpleZoneCacheDuration, cfg.DryRun)
 	case "infoblox":
-		p, err = infoblox.NewInfobloxProvider(
-			infoblox.StartupConfig{
-				DomainFilter:  domainFilter,
-				ZoneIDFilter:  zoneIDFilter,
-				Host:          cfg.InfobloxGridHost,
-				Port:          cfg.InfobloxWapiPort,
-				Username:      cfg.InfobloxWapiUsername,
-				Password:      cfg.InfobloxWapiPassword,
-				Version:       cfg.InfobloxWapiVersion,
-				SSLVerify:     cfg.InfobloxSSLVerify,
-				View:          cfg.InfobloxView,
-				MaxResults:    cfg.InfobloxMaxResults,
-				DryRun:        cfg.DryRun,
-				FQDNRegEx:     cfg.InfobloxFQDNRegEx,
-				NameRegEx:     cfg.InfobloxNameRegEx,
-				CreatePTR:     cfg.InfobloxCreatePTR,
-				CacheDuration: cfg.InfobloxCacheDuration,
-			},
-		)
+		ibStartupCfg := infoblox.StartupConfig{
+			DomainFilter:       domainFilter,
+			ZoneIDFilter:       zoneIDFilter,
+			Host:               cfg.InfobloxGridHost,
+			Port:               cfg.InfobloxWapiPort,
+			Username:           cfg.InfobloxWapiUsername,
+			Password:           cfg.InfobloxWapiPassword,
+			Version:            cfg.InfobloxWapiVersion,
+			SSLVerify:          cfg.InfobloxSSLVerify,
+			View:               cfg.InfobloxView,
+			MaxResults:         cfg.InfobloxMaxResults,
+			DryRun:             cfg.DryRun,
+			FQDNRegEx:          cfg.InfobloxFQDNRegEx,
+			NameRegEx:          cfg.InfobloxNameRegEx,
+			CreatePTR:          cfg.InfobloxCreatePTR,
+			CacheDuration:      cfg.InfobloxCacheDuration,
+			DomainViews:        domainMap(cfg.InfobloxDomainViews),
+			DomainNetworkViews: domainMap(cfg.InfobloxDomainNetworkViews),
+		}
+		// the ownership is only stored in extensible attributes by the infoblox-ea registry
+		if cfg.Registry == "infoblox-ea" {
+			ibStartupCfg.OwnerEA = cfg.InfobloxOwnerEA
+			ibStartupCfg.ResourceEA = cfg.InfobloxResourceEA
+		}
+		p, err = infoblox.NewInfobloxProvider(ibStartupCfg)
 	case "dyn":
 		p, err = dyn.NewDynProvider(
 			dyn.DynConfig{
@@ -337,10 +496,11 @@ func main() {
 		p, err = pdns.NewPDNSProvider(
 			ctx,
 			pdns.PDNSConfig{
-				DomainFilter: domainFilter,
-				DryRun:       cfg.DryRun,
-				Server:       cfg.PDNSServer,
-				APIKey:       cfg.PDNSAPIKey,
+				DomainFilter:    domainFilter,
+				DryRun:          cfg.DryRun,
+				Server:          cfg.PDNSServer,
+				APIKey:          cfg.PDNSAPIKey,
+				APIKeyRefresher: vaultRefresher(ctx, cfg, credentials.PDNSAPIKey, cfg.PDNSAPIKeyVaultPath),
 				TLSConfig: pdns.TLSConfig{
 					SkipTLSVerify:         cfg.PDNSSkipTLSVerify,
 					CAFilePath:            cfg.TLSCA,
@@ -364,11 +524,54 @@ func main() {
 			config, err = oci.LoadOCIConfig(cfg.OCIConfigFile)
 		}
 		config.ZoneCacheDuration = cfg.OCIZoneCacheDuration
+		config.PrivateViewIDs = cfg.OCIPrivateViewIDs
 		if err == nil {
 			p, err = oci.NewOCIProvider(*config, domainFilter, zoneIDFilter, cfg.OCIZoneScope, cfg.DryRun)
 		}
 	case "rfc2136":
-		p, err = rfc2136.NewRfc2136Provider(cfg.RFC2136Host, cfg.RFC2136Port, cfg.RFC2136Zone, cfg.RFC2136Insecure, cfg.RFC2136TSIGKeyName, cfg.RFC2136TSIGSecret, cfg.RFC2136TSIGSecretAlg, cfg.RFC2136TAXFR, domainFilter, cfg.DryRun, cfg.RFC2136MinTTL, cfg.RFC2136GSSTSIG, cfg.RFC2136KerberosUsername, cfg.RFC2136KerberosPassword, cfg.RFC2136KerberosRealm, cfg.RFC2136BatchChangeSize, nil)
+		secretRefresher := vaultRefresher(ctx, cfg, credentials.TSIGSecret, cfg.RFC2136TSIGSecretVaultPath)
+		if cfg.RFC2136TSIGSecretFile != "" {
+			secretRefresher = credentials.NewRefresher(credentials.FileSource{
+				Files:          map[string]string{credentials.TSIGSecret: cfg.RFC2136TSIGSecretFile},
+				ReloadInterval: cfg.CredentialsReloadInterval,
+			}, 0)
+			if _, err := secretRefresher.Get(ctx); err != nil {
+				log.Fatalf("failed to read the TSIG secret: %v", err)
+			}
+		}
+		p, err = rfc2136.NewRfc2136Provider(
+			rfc2136.RFC2136Config{
+				Host:                   cfg.RFC2136Host,
+				Port:                   cfg.RFC2136Port,
+				Zones:                  cfg.RFC2136Zone,
+				Insecure:               cfg.RFC2136Insecure,
+				TSIGKeyName:            cfg.RFC2136TSIGKeyName,
+				TSIGSecret:             cfg.RFC2136TSIGSecret,
+				TSIGSecretAlg:          cfg.RFC2136TSIGSecretAlg,
+				AXFR:                   cfg.RFC2136TAXFR,
+				DomainFilter:           domainFilter,
+				DryRun:                 cfg.DryRun,
+				MinTTL:                 cfg.RFC2136MinTTL,
+				GSSTSIG:                cfg.RFC2136GSSTSIG,
+				KerberosUsername:       cfg.RFC2136KerberosUsername,
+				KerberosPassword:       cfg.RFC2136KerberosPassword,
+				KerberosRealm:          cfg.RFC2136KerberosRealm,
+				ClockSkew:              cfg.RFC2136ClockSkew,
+				BatchChangeSize:        cfg.RFC2136BatchChangeSize,
+				UpdateCheck:            cfg.RFC2136UpdateCheck,
+				LocalAddress:           cfg.RFC2136LocalAddress,
+				DiscoverPrimary:        cfg.RFC2136DiscoverPrimary,
+				FallbackHosts:          cfg.RFC2136FallbackHosts,
+				ADSite:                 cfg.RFC2136ADSite,
+				ADDomain:               cfg.RFC2136ADDomain,
+				Retries:                cfg.RFC2136Retries,
+				RetryBackoff:           cfg.RFC2136RetryBackoff,
+				RetryBudget:            cfg.RFC2136RetryBudget,
+				PassthroughRecordTypes: cfg.PassthroughRecordTypes,
+				TSIGSecretRefresher:    secretRefresher,
+			},
+			nil,
+		)
 	case "ns1":
 		p, err = ns1.NewNS1Provider(
 			ns1.NS1Config{
@@ -394,6 +597,40 @@ func main() {
 				Server:                cfg.PiholeServer,
 				Password:              cfg.PiholePassword,
 				TLSInsecureSkipVerify: cfg.PiholeTLSInsecureSkipVerify,
+				APIVersion:            cfg.PiholeAPIVersion,
+				DomainFilter:          domainFilter,
+				DryRun:                cfg.DryRun,
+			},
+		)
+	case "dnsmasq":
+		p, err = dnsmasq.NewDnsmasqProvider(
+			dnsmasq.DnsmasqConfig{
+				HostsFile:    cfg.DnsmasqHostsFile,
+				ConfFile:     cfg.DnsmasqConfFile,
+				PidFile:      cfg.DnsmasqPidFile,
+				DomainFilter: domainFilter,
+				DryRun:       cfg.DryRun,
+			},
+		)
+	case "unbound":
+		p, err = unbound.NewUnboundProvider(
+			unbound.UnboundConfig{
+				ControlAddress:  cfg.UnboundControlAddress,
+				ServerCertFile:  cfg.UnboundServerCertFile,
+				ControlCertFile: cfg.UnboundControlCertFile,
+				ControlKeyFile:  cfg.UnboundControlKeyFile,
+				LocalZones:      cfg.UnboundLocalZones,
+				LocalZoneType:   cfg.UnboundLocalZoneType,
+				DomainFilter:    domainFilter,
+				DryRun:          cfg.DryRun,
+			},
+		)
+	case "technitium":
+		p, err = technitium.NewTechnitiumProvider(
+			technitium.TechnitiumConfig{
+				Server:                cfg.TechnitiumServer,
+				Token:                 cfg.TechnitiumToken,
+				TLSInsecureSkipVerify: cfg.TechnitiumSkipTLSVerify,
 				DomainFilter:          domainFilter,
 				DryRun:                cfg.DryRun,
 			},
@@ -407,7 +644,13 @@ func main() {
 	case "tencentcloud":
 		p, err = tencentcloud.NewTencentCloudProvider(domainFilter, zoneIDFilter, cfg.TencentCloudConfigFile, cfg.TencentCloudZoneType, cfg.DryRun)
 	case "webhook":
-		p, err = webhook.NewWebhookProvider(cfg.WebhookProviderURL)
+		var token []byte
+		if cfg.WebhookProviderTokenFile != "" {
+			if token, err = os.ReadFile(cfg.WebhookProviderTokenFile); err != nil {
+				log.Fatalf("failed to read the webhook provider token: %v", err)
+			}
+		}
+		p, err = webhook.NewWebhookProviderWithToken(cfg.WebhookProviderURL, strings.TrimSpace(string(token)))
 	default:
 		log.Fatalf("unknown dns provider: %s", cfg.Provider)
 	}
@@ -415,11 +658,66 @@ func main() {
 		log.Fatal(err)
 	}
 
+	// the records above the depth are refused whatever sends their changes, even with a wrong domain filter
+	if cfg.MinRecordDepth > 0 {
+		p = provider.NewDepthGuardProvider(p, cfg.MinRecordDepth)
+	}
+
+	if cfg.ChangeDescriptionTemplate != "" {
+		p, err = provider.NewDescribingProvider(p, cfg.ChangeDescriptionTemplate)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// the changes are dropped before reaching the provider, whatever sends them
+	if cfg.ReadOnly {
+		log.Info("running in read-only mode. No changes to DNS records will be sent to the provider.")
+		p = provider.NewReadOnlyProvider(p)
+	}
+
+	if cfg.RecordsSnapshotSave != "" {
+		snapshot, err := provider.SaveRecordsSnapshot(ctx, p, cfg.Provider, cfg.RecordsSnapshotSave)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Infof("Saved %d records of the provider to %s", len(snapshot.Records), cfg.RecordsSnapshotSave)
+		os.Exit(0)
+	}
+
+	if cfg.WebhookServer && cfg.WebhookServerClientsFile != "" {
+		clients, err := webhookapi.ReadGatewayClients(cfg.WebhookServerClientsFile)
+		if err != nil {
+			log.Fatalf("failed to read the webhook server clients: %v", err)
+		}
+		// the registries of the clients don't cache the records, as each would miss the changes of the others
+		gateway, err := webhookapi.NewGatewayServer(clients, func(clusterID string) (provider.Provider, error) {
+			return registry.NewTXTRegistry(p, cfg.TXTPrefix, cfg.TXTSuffix, clusterID, 0, cfg.TXTWildcardReplacement, cfg.ManagedDNSRecordTypes, cfg.ExcludeDNSRecordTypes, cfg.TXTEncryptEnabled, []byte(cfg.TXTEncryptAESKey))
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		if cfg.WebhookServerTLSCertFile == "" {
+			log.Warn("The tokens of the clients of the gateway are sent in clear, serve it behind a TLS terminating proxy or with --webhook-server-tls-cert-file")
+		}
+		webhookapi.StartGatewayHTTPApi(gateway, nil, cfg.WebhookProviderReadTimeout, cfg.WebhookProviderWriteTimeout, cfg.WebhookServerAddress, cfg.WebhookServerTLSCertFile, cfg.WebhookServerTLSKeyFile)
+		os.Exit(0)
+	}
 	if cfg.WebhookServer {
-		webhookapi.StartHTTPApi(p, nil, cfg.WebhookProviderReadTimeout, cfg.WebhookProviderWriteTimeout, "127.0.0.1:8888")
+		webhookapi.StartHTTPApi(p, nil, cfg.WebhookProviderReadTimeout, cfg.WebhookProviderWriteTimeout, cfg.WebhookServerAddress)
 		os.Exit(0)
 	}
 
+	// the latency of the requests to the provider is measured per operation
+	p = provider.NewInstrumentedProvider(cfg.Provider, p)
+
+	// the requests rejected while the provider is unhealthy aren't measured
+	if cfg.ProviderFailureThreshold > 0 {
+		breaker := provider.NewCircuitBreakerProvider(cfg.Provider, p, cfg.ProviderFailureThreshold, cfg.ProviderProbeBackoff)
+		providerHealth.Store(breaker)
+		p = breaker
+	}
+
 	var r registry.Registry
 	switch cfg.Registry {
 	case "dynamodb":
@@ -427,13 +725,18 @@ func main() {
 		if cfg.AWSDynamoDBRegion != "" {
 			config = config.WithRegion(cfg.AWSDynamoDBRegion)
 		}
-		r, err = registry.NewDynamoDBRegistry(p, cfg.TXTOwnerID, dynamodb.New(awsSession, config), cfg.AWSDynamoDBTable, cfg.TXTPrefix, cfg.TXTSuffix, cfg.TXTWildcardReplacement, cfg.ManagedDNSRecordTypes, cfg.ExcludeDNSRecordTypes, []byte(cfg.TXTEncryptAESKey), cfg.TXTCacheInterval)
+		r, err = registry.NewDynamoDBRegistry(p, cfg.TXTOwnerID, dynamodb.New(awsSession, config), cfg.AWSDynamoDBTable, cfg.TXTPrefix, cfg.TXTSuffix, cfg.TXTWildcardReplacement, cfg.ManagedDNSRecordTypes, cfg.ExcludeDNSRecordTypes, []byte(cfg.TXTEncryptAESKey), cfg.TXTCacheInterval, cfg.AWSDynamoDBRecordHistory, cfg.AWSDynamoDBHistoryRetention)
 	case "noop":
 		r, err = registry.NewNoopRegistry(p)
 	case "txt":
 		r, err = registry.NewTXTRegistry(p, cfg.TXTPrefix, cfg.TXTSuffix, cfg.TXTOwnerID, cfg.TXTCacheInterval, cfg.TXTWildcardReplacement, cfg.ManagedDNSRecordTypes, cfg.ExcludeDNSRecordTypes, cfg.TXTEncryptEnabled, []byte(cfg.TXTEncryptAESKey))
 	case "aws-sd":
-		r, err = registry.NewAWSSDRegistry(p.(*awssd.AWSSDProvider), cfg.TXTOwnerID)
+		if _, ok := provider.Unwrap(p).(*awssd.AWSSDProvider); !ok {
+			log.Fatal("the aws-sd registry requires the aws-sd provider")
+		}
+		r, err = registry.NewAWSSDRegistry(p, cfg.TXTOwnerID)
+	case "infoblox-ea":
+		r, err = registry.NewInfobloxEARegistry(p, cfg.TXTOwnerID)
 	default:
 		log.Fatalf("unknown registry: %s", cfg.Registry)
 	}
@@ -442,6 +745,28 @@ func main() {
 		log.Fatal(err)
 	}
 
+	if h, ok := r.(registry.RecordHistory); ok && cfg.AWSDynamoDBRecordHistory > 0 {
+		http.Handle("/debug/record-history", registry.NewRecordHistoryHandler(h))
+	}
+
+	if cfg.RegistrySnapshotSave != "" {
+		snapshot, err := registry.SaveSnapshot(ctx, r, cfg.RegistrySnapshotSave)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Infof("Saved the ownership of %d records to %s", len(snapshot.Records), cfg.RegistrySnapshotSave)
+		os.Exit(0)
+	}
+
+	if cfg.RegistrySnapshotRestore != "" {
+		restored, err := registry.RestoreSnapshotFile(ctx, r, cfg.RegistrySnapshotRestore)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Infof("Restored the ownership of %d records from %s", restored, cfg.RegistrySnapshotRestore)
+		os.Exit(0)
+	}
+
 	policy, exists := plan.Policies[cfg.Policy]
 	if !exists {
 		log.Fatalf("unknown policy: %s", cfg.Policy)
@@ -456,6 +781,147 @@ func main() {
 		ManagedRecordTypes:   cfg.ManagedDNSRecordTypes,
 		ExcludeRecordTypes:   cfg.ExcludeDNSRecordTypes,
 		MinEventSyncInterval: cfg.MinEventSyncInterval,
+		FullSyncInterval:     cfg.FullSyncInterval,
+		DampeningWindow:      cfg.DampeningWindow,
+		BatchSize:            cfg.ProviderBatchSize,
+		BatchInterval:        cfg.ProviderBatchInterval,
+		Pagination:           cfg.RecordsPagination,
+		PageRetry:            provider.DefaultRetryConfig,
+	}
+	if cfg.RecordsPagination && !provider.PagesRecords(p) {
+		log.Warnf("Ignoring --records-pagination, the %s provider doesn't list its records in pages", cfg.Provider)
+	}
+	// the changes of zones are applied concurrently for the providers whose API permits it
+	switch cfg.Provider {
+	case "aws":
+		ctrl.ZoneConcurrency = cfg.AWSZoneConcurrency
+	case "rfc2136":
+		ctrl.ZoneConcurrency = cfg.RFC2136ZoneConcurrency
+	}
+
+	if cfg.ACMEServer {
+		token, err := os.ReadFile(cfg.ACMEServerTokenFile)
+		if err != nil {
+			log.Fatalf("failed to read the ACME server token: %v", err)
+		}
+		solver := &acme.Solver{
+			Registry:           r,
+			DomainFilter:       domainFilter,
+			TTL:                endpoint.TTL(cfg.ACMEChallengeTTL),
+			PropagationTimeout: cfg.ACMEPropagationTimeout,
+			PollInterval:       cfg.ACMEPropagationInterval,
+			Lock:               ctrl.Exclusive(),
+		}
+		// present requests block until the record propagated, so the write timeout has to outlast the check
+		go acme.StartHTTPApi(solver, []byte(strings.TrimSpace(string(token))), nil, cfg.WebhookProviderReadTimeout, cfg.ACMEPropagationTimeout+cfg.WebhookProviderWriteTimeout, cfg.ACMEServerAddress)
+	}
+
+	if gc, ok := r.(registry.GarbageCollector); ok {
+		ctrl.GarbageCollector = gc
+		ctrl.GarbageCollectionInterval = cfg.TXTGCInterval
+		ctrl.GarbageCollectionDryRun = cfg.TXTGCDryRun
+	}
+
+	for _, value := range cfg.MaintenanceWindows {
+		window, err := controller.ParseMaintenanceWindow(value)
+		if err != nil {
+			log.Fatal(err)
+		}
+		ctrl.MaintenanceWindows = append(ctrl.MaintenanceWindows, window)
+	}
+
+	if len(cfg.ChangeWindows) > 0 {
+		ctrl.ChangeWindows = &plan.ChangeWindowPolicy{}
+		for _, value := range cfg.ChangeWindows {
+			window, err := plan.ParseChangeWindow(value)
+			if err != nil {
+				log.Fatal(err)
+			}
+			ctrl.ChangeWindows.Windows = append(ctrl.ChangeWindows.Windows, window)
+		}
+		if kubeClient, err := clientGenerator.KubeClient(); err != nil {
+			log.Warnf("Not recording Events for the deferred changes: %v", err)
+		} else {
+			ctrl.ChangeWindows.OnDeferred = controller.DeferredChangeRecorder(controller.NewResourceEventRecorder(kubeClient))
+		}
+	}
+
+	if cfg.TargetChangeTTL > 0 {
+		ctrl.TTLStep = &plan.TTLStepPolicy{TTL: endpoint.TTL(cfg.TargetChangeTTL.Seconds()), Soak: cfg.TargetChangeSoak}
+	}
+
+	if cfg.WarningEvents {
+		kubeClient, err := clientGenerator.KubeClient()
+		if err != nil {
+			log.Fatal(err)
+		}
+		recordEvent := controller.NewResourceEventRecorder(kubeClient)
+		ctrl.Warnings = &controller.Warnings{Record: func(resource, reason, message string) {
+			recordEvent(resource, corev1.EventTypeWarning, reason, message)
+		}}
+	}
+
+	if cfg.PublishedHostnamesAnnotation {
+		dynamicClient, err := clientGenerator.DynamicKubernetesClient()
+		if err != nil {
+			log.Fatal(err)
+		}
+		ctrl.PublishedHostnames = &controller.PublishedHostnames{Write: controller.NewPublishedHostnamesWriter(dynamicClient, cfg.AnnotationPrefix+"published-hostnames")}
+	}
+
+	if len(cfg.CanaryZones) > 0 {
+		lookup, err := controller.NewCanaryLookup(cfg.CanaryNameserver)
+		if err != nil {
+			log.Fatal(err)
+		}
+		ctrl.Canary = &controller.Canary{Lookup: lookup, Timeout: cfg.CanaryTimeout}
+		for _, value := range cfg.CanaryZones {
+			zone, err := controller.ParseCanaryZone(value)
+			if err != nil {
+				log.Fatal(err)
+			}
+			ctrl.Canary.Zones = append(ctrl.Canary.Zones, zone)
+		}
+	}
+
+	if cfg.SnapshotPath != "" {
+		ctrl.Snapshot = controller.FileSnapshot{Path: cfg.SnapshotPath}
+	}
+
+	if cfg.HistoryDir != "" {
+		ctrl.History = controller.FileHistory{Dir: cfg.HistoryDir, Limit: cfg.HistoryLimit}
+	}
+
+	if rollbackTo != "" {
+		rolledBack, err := ctrl.Rollback(ctx, rollbackTo)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Infof("Rolled back %d syncs", rolledBack)
+		os.Exit(0)
+	}
+
+	if cfg.RollbackEndpoint {
+		token, err := os.ReadFile(cfg.RollbackEndpointTokenFile)
+		if err != nil {
+			log.Fatalf("failed to read the rollback endpoint token: %v", err)
+		}
+		http.Handle("/rollback", ctrl.RollbackHandler([]byte(strings.TrimSpace(string(token)))))
+	}
+
+	if cfg.ExplainEndpoint {
+		ctrl.Provenance = controller.NewProvenance()
+		http.Handle(controller.ExplainPath, ctrl.Provenance.Handler())
+	}
+
+	if cfg.ChurnWindow > 0 {
+		ctrl.Churn = controller.NewChurn(cfg.ChurnWindow, cfg.ChurnThreshold)
+		http.Handle(controller.ChurnPath, ctrl.Churn.Handler())
+	}
+
+	if cfg.DebugDNSAddress != "" {
+		ctrl.DesiredState = controller.NewDesiredState()
+		go serveDebugDNS(cfg.DebugDNSAddress, ctrl.DesiredState)
 	}
 
 	if cfg.Once {
@@ -473,6 +939,17 @@ func main() {
 		// function initially being called for every Service/Ingress that exists
 		ctrl.Source.AddEventHandler(ctx, func() { ctrl.ScheduleRunOnce(time.Now()) })
 	}
+	if cfg.NodeDeletionEvents {
+		// The records of the nodes scaled down are removed within --min-event-sync-interval rather than
+		// on the next interval.
+		kubeClient, err := clientGenerator.KubeClient()
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := source.WatchNodeDeletions(ctx, kubeClient, func() { ctrl.ScheduleRunOnce(time.Now()) }); err != nil {
+			log.Fatal(err)
+		}
+	}
 
 	ctrl.ScheduleRunOnce(time.Now())
 	ctrl.Run(ctx)
@@ -486,13 +963,105 @@ func handleSigterm(cancel func()) {
 	cancel()
 }
 
+// vaultRefresher returns the refresher of the credentials of a provider read from the secret of
+// Vault at path, nil if path is empty. The credentials are fetched once, so external-dns doesn't
+// start without them.
+func vaultRefresher(ctx context.Context, cfg *externaldns.Config, name, path string) *credentials.Refresher {
+	if path == "" {
+		return nil
+	}
+	source, err := credentials.NewVaultSource(credentials.VaultServer{
+		Address:    cfg.VaultAddress,
+		Namespace:  cfg.VaultNamespace,
+		CACertFile: cfg.VaultCACert,
+	}, credentials.VaultAuth{
+		Method:       cfg.VaultAuthMethod,
+		MountPath:    cfg.VaultAuthMountPath,
+		RoleID:       cfg.VaultAppRoleRoleID,
+		SecretIDFile: cfg.VaultAppRoleSecretIDFile,
+		Role:         cfg.VaultKubernetesRole,
+		TokenFile:    cfg.VaultKubernetesTokenFile,
+	}, map[string]string{name: path}, cfg.CredentialsReloadInterval)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// the credentials are fetched again a minute before their lease ends
+	refresher := credentials.NewRefresher(source, time.Minute)
+	if _, err := refresher.Get(ctx); err != nil {
+		log.Fatalf("failed to read the %s from Vault: %v", name, err)
+	}
+	return refresher
+}
+
+// domainMap returns the values of the domains of domain=value flags.
+func domainMap(values []string) map[string]string {
+	m := make(map[string]string, len(values))
+	for _, v := range values {
+		domain, value, _ := strings.Cut(v, "=")
+		m[domain] = value
+	}
+	return m
+}
+
+// verifySourcePermissions logs the permissions the sources are missing to list and watch their
+// resources, and exits when fail is set, before a source missing some lists partial endpoints and
+// gets the records of the missing ones deleted.
+func verifySourcePermissions(ctx context.Context, p source.ClientGenerator, names []string, cfg *source.Config, fail bool) {
+	kubeClient, err := p.KubeClient()
+	if err != nil {
+		log.Fatal(err)
+	}
+	missing, err := source.VerifyPermissions(ctx, kubeClient, names, cfg)
+	if err != nil {
+		log.Errorf("Failed to verify the permissions of the sources: %v", err)
+		return
+	}
+	for _, permission := range missing {
+		log.Errorf("The source %s is missing the permission to %s", permission.Source, permission)
+	}
+	if len(missing) > 0 && fail {
+		log.Fatalf("The sources are missing %d permissions, grant them to the service account of ExternalDNS", len(missing))
+	}
+}
+
+func serveDebugDNS(address string, lookup debugdns.Lookup) {
+	log.Infof("Serving the desired state over DNS on %s", address)
+	log.Fatal(debugdns.ListenAndServe(address, lookup))
+}
+
+// serveVerboseHealth writes the health of the provider and of every source as JSON. The status is
+// always OK, like the health of the provider, a source missing permissions isn't fixed by a restart.
+func serveVerboseHealth(w http.ResponseWriter) {
+	health := verboseHealth{Sources: []source.Health{}, WatchErrors: source.WatchErrorsHealth()}
+	if breaker := providerHealth.Load(); breaker != nil {
+		h := breaker.Health()
+		health.Provider = &h
+	}
+	if sources := sourcesHealth.Load(); sources != nil {
+		health.Sources = source.SourcesHealth(*sources)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(health); err != nil {
+		log.Errorf("Failed to write the health: %v", err)
+	}
+}
+
 func serveMetrics(address string) {
-	http.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+	http.HandleFunc("/healthz", func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Query().Has("verbose") {
+			serveVerboseHealth(w)
+			return
+		}
+		if breaker := providerHealth.Load(); breaker != nil {
+			breaker.ServeHTTP(w, req)
+			return
+		}
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("OK"))
 	})
 
-	http.Handle("/metrics", promhttp.Handler())
+	// OpenMetrics is required to expose the exemplars of the histograms
+	http.Handle("/metrics", promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer, promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{EnableOpenMetrics: true})))
 
 	log.Fatal(http.ListenAndServe(address, nil))
 }
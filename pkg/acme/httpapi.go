@@ -0,0 +1,135 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package acme
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Server exposes a Solver over HTTP to the clients authenticated by its token.
+type Server struct {
+	Solver *Solver
+	Token  []byte
+}
+
+// PresentHandler publishes the challenge given in the request body.
+func (s *Server) PresentHandler(w http.ResponseWriter, req *http.Request) {
+	s.handle(w, req, func(req *http.Request, ch Challenge) error {
+		return s.Solver.Present(req.Context(), ch)
+	})
+}
+
+// CleanUpHandler removes the challenge given in the request body.
+func (s *Server) CleanUpHandler(w http.ResponseWriter, req *http.Request) {
+	s.handle(w, req, func(req *http.Request, ch Challenge) error {
+		return s.Solver.CleanUp(req.Context(), ch)
+	})
+}
+
+func (s *Server) handle(w http.ResponseWriter, req *http.Request, fn func(req *http.Request, ch Challenge) error) {
+	if !s.authenticated(req) {
+		log.Warnf("Rejecting unauthenticated challenge request from %s", req.RemoteAddr)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if req.Method != http.MethodPost {
+		log.Errorf("Unsupported method %s", req.Method)
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	var ch Challenge
+	if err := json.NewDecoder(req.Body).Decode(&ch); err != nil {
+		log.Errorf("Failed to decode challenge: %v", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	err := fn(req, ch)
+	switch {
+	case err == nil:
+		w.WriteHeader(http.StatusNoContent)
+	case errors.Is(err, ErrInvalidChallenge), errors.Is(err, ErrNotChallengeRecord), errors.Is(err, ErrDomainNotManaged):
+		log.Errorf("Rejected challenge: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrPropagationTimeout):
+		log.Errorf("Challenge not propagated: %v", err)
+		http.Error(w, err.Error(), http.StatusGatewayTimeout)
+	default:
+		log.Errorf("Failed to process challenge: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// authenticated returns whether the request carries the token of the server, either as a bearer
+// token or as the password of the basic authentication of the lego "httpreq" DNS provider.
+func (s *Server) authenticated(req *http.Request) bool {
+	if len(s.Token) == 0 {
+		return false
+	}
+	token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		_, token, ok = req.BasicAuth()
+	}
+	return ok && subtle.ConstantTimeCompare(s.Token, []byte(token)) == 1
+}
+
+// StartHTTPApi starts a HTTP server fulfilling DNS-01 challenges with the given solver for
+// the clients authenticated by the token.
+// The request body of both endpoints is a JSON encoded Challenge, compatible with the
+// lego "httpreq" DNS provider used by many ACME clients.
+// The server will respond to the following endpoints:
+// - /present (POST): publishes the challenge record and waits for it to propagate
+// - /cleanup (POST): removes the challenge record
+func StartHTTPApi(solver *Solver, token []byte, startedChan chan struct{}, readTimeout, writeTimeout time.Duration, address string) {
+	s := Server{
+		Solver: solver,
+		Token:  token,
+	}
+
+	m := http.NewServeMux()
+	m.HandleFunc("/present", s.PresentHandler)
+	m.HandleFunc("/cleanup", s.CleanUpHandler)
+
+	srv := &http.Server{
+		Addr:         address,
+		Handler:      m,
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+	}
+
+	l, err := net.Listen("tcp", address)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if startedChan != nil {
+		startedChan <- struct{}{}
+	}
+
+	if err := srv.Serve(l); err != nil {
+		log.Fatal(err)
+	}
+}
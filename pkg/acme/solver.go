@@ -0,0 +1,251 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package acme
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/registry"
+)
+
+const defaultPollInterval = 2 * time.Second
+
+// ChallengePrefix is the label the names of the DNS-01 challenge records start with.
+const ChallengePrefix = "_acme-challenge."
+
+var (
+	// ErrInvalidChallenge is returned when a challenge misses its FQDN or value
+	ErrInvalidChallenge = errors.New("challenge requires both fqdn and value")
+	// ErrNotChallengeRecord is returned when the challenge FQDN doesn't start with the challenge prefix,
+	// so that no other TXT record of a managed zone can be changed through the server
+	ErrNotChallengeRecord = errors.New("challenge fqdn must start with " + ChallengePrefix)
+	// ErrDomainNotManaged is returned when the challenge FQDN is not matched by the domain filter
+	ErrDomainNotManaged = errors.New("challenge fqdn is not managed by this instance")
+	// ErrPropagationTimeout is returned when the challenge record did not show up in time
+	ErrPropagationTimeout = errors.New("timed out waiting for challenge record to propagate")
+)
+
+// Challenge is a single DNS-01 challenge as handed over by an ACME client.
+type Challenge struct {
+	// FQDN is the name of the challenge record, e.g. _acme-challenge.example.org.
+	FQDN string `json:"fqdn"`
+	// Value is the key authorization digest that must be served as TXT record.
+	Value string `json:"value"`
+}
+
+// Solver fulfils DNS-01 challenges by writing TXT records through the registry of an already
+// configured provider, which records the ownership of the challenge records.
+type Solver struct {
+	Registry           registry.Registry
+	DomainFilter       endpoint.DomainFilter
+	TTL                endpoint.TTL
+	PropagationTimeout time.Duration
+	PollInterval       time.Duration
+	// Lock, when set, is held while the registry is read or written, as the registry of the
+	// controller isn't safe for concurrent use and its reconciliations must not interleave with
+	// the changes of the solver.
+	Lock sync.Locker
+
+	// challenges for the apex and the wildcard of a domain share the same record,
+	// so all read-modify-write cycles are serialized.
+	mu sync.Mutex
+}
+
+// Present publishes the challenge value and waits until the provider reports it.
+func (s *Solver) Present(ctx context.Context, ch Challenge) error {
+	name, value, err := s.validate(ch)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.present(ctx, name, value); err != nil {
+		return err
+	}
+	return s.waitForPropagation(ctx, name, value)
+}
+
+// present adds the value to the challenge record unless it is there already.
+func (s *Solver) present(ctx context.Context, name, value string) error {
+	defer s.lock()()
+
+	current, err := s.currentRecord(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	changes := &plan.Changes{}
+	switch {
+	case current == nil:
+		changes.Create = []*endpoint.Endpoint{endpoint.NewEndpointWithTTL(name, endpoint.RecordTypeTXT, s.TTL, value)}
+	case hasTarget(current, value):
+		log.Debugf("Challenge record %s already contains the requested value", name)
+		return nil
+	default:
+		desired := current.DeepCopy()
+		desired.Targets = append(desired.Targets, value)
+		changes.UpdateOld = []*endpoint.Endpoint{current}
+		changes.UpdateNew = []*endpoint.Endpoint{desired}
+	}
+
+	log.Infof("Presenting ACME challenge record %s", name)
+	if err := s.Registry.ApplyChanges(ctx, changes); err != nil {
+		return fmt.Errorf("failed to present challenge record %s: %w", name, err)
+	}
+	return nil
+}
+
+// CleanUp removes the challenge value, deleting the record once no values are left.
+func (s *Solver) CleanUp(ctx context.Context, ch Challenge) error {
+	name, value, err := s.validate(ch)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.lock()()
+
+	current, err := s.currentRecord(ctx, name)
+	if err != nil {
+		return err
+	}
+	if current == nil || !hasTarget(current, value) {
+		log.Debugf("Challenge record %s has nothing to clean up", name)
+		return nil
+	}
+
+	changes := &plan.Changes{}
+	remaining := endpoint.Targets{}
+	for _, t := range current.Targets {
+		if unquote(t) != unquote(value) {
+			remaining = append(remaining, t)
+		}
+	}
+	if len(remaining) == 0 {
+		changes.Delete = []*endpoint.Endpoint{current}
+	} else {
+		desired := current.DeepCopy()
+		desired.Targets = remaining
+		changes.UpdateOld = []*endpoint.Endpoint{current}
+		changes.UpdateNew = []*endpoint.Endpoint{desired}
+	}
+
+	log.Infof("Cleaning up ACME challenge record %s", name)
+	if err := s.Registry.ApplyChanges(ctx, changes); err != nil {
+		return fmt.Errorf("failed to clean up challenge record %s: %w", name, err)
+	}
+	return nil
+}
+
+func (s *Solver) validate(ch Challenge) (string, string, error) {
+	name := strings.ToLower(strings.TrimSuffix(ch.FQDN, "."))
+	if name == "" || ch.Value == "" {
+		return "", "", ErrInvalidChallenge
+	}
+	if !strings.HasPrefix(name, ChallengePrefix) {
+		return "", "", fmt.Errorf("%w: %s", ErrNotChallengeRecord, name)
+	}
+	if !s.DomainFilter.Match(name) {
+		return "", "", fmt.Errorf("%w: %s", ErrDomainNotManaged, name)
+	}
+	// TXT values are quoted the same way the TXT registry stores its ownership records.
+	return name, fmt.Sprintf("%q", unquote(ch.Value)), nil
+}
+
+// currentRecord returns the TXT record with the given name or nil if there is none.
+func (s *Solver) currentRecord(ctx context.Context, name string) (*endpoint.Endpoint, error) {
+	records, err := s.Registry.Records(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list records: %w", err)
+	}
+	for _, r := range records {
+		if r.RecordType == endpoint.RecordTypeTXT && r.SetIdentifier == "" && strings.TrimSuffix(r.DNSName, ".") == name {
+			return r, nil
+		}
+	}
+	return nil, nil
+}
+
+// waitForPropagation polls the provider until the challenge value is visible.
+// Asking the provider rather than public resolvers keeps the check fast and
+// unaffected by negative caching of the challenge name.
+func (s *Solver) waitForPropagation(ctx context.Context, name, value string) error {
+	if s.PropagationTimeout <= 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.PropagationTimeout)
+	defer cancel()
+
+	interval := s.PollInterval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		unlock := s.lock()
+		current, err := s.currentRecord(ctx, name)
+		unlock()
+		if err != nil {
+			log.Debugf("Failed to check propagation of %s: %v", name, err)
+		} else if current != nil && hasTarget(current, value) {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %s", ErrPropagationTimeout, name)
+		case <-ticker.C:
+		}
+	}
+}
+
+// lock acquires the lock of the registry, if any, and returns the function releasing it.
+func (s *Solver) lock() func() {
+	if s.Lock == nil {
+		return func() {}
+	}
+	s.Lock.Lock()
+	return s.Lock.Unlock
+}
+
+func hasTarget(ep *endpoint.Endpoint, value string) bool {
+	for _, t := range ep.Targets {
+		if unquote(t) == unquote(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func unquote(s string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(s, `"`), `"`)
+}
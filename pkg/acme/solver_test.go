@@ -0,0 +1,249 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package acme
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/controller"
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider/inmemory"
+	"sigs.k8s.io/external-dns/registry"
+)
+
+func newTestSolver(t *testing.T) (*Solver, *inmemory.InMemoryProvider) {
+	p := inmemory.NewInMemoryProvider(inmemory.InMemoryInitZones([]string{"example.org"}))
+	r, err := registry.NewTXTRegistry(p, "owner.", "", "acme", 0, "", nil, nil, false, nil)
+	require.NoError(t, err)
+	return &Solver{
+		Registry:           r,
+		DomainFilter:       endpoint.NewDomainFilter([]string{"example.org"}),
+		TTL:                60,
+		PropagationTimeout: time.Second,
+		PollInterval:       10 * time.Millisecond,
+	}, p
+}
+
+func challengeTargets(t *testing.T, p *inmemory.InMemoryProvider, name string) endpoint.Targets {
+	records, err := p.Records(context.Background())
+	require.NoError(t, err)
+	for _, r := range records {
+		if r.DNSName == name && r.RecordType == endpoint.RecordTypeTXT {
+			return r.Targets
+		}
+	}
+	return nil
+}
+
+func TestSolverPresentAndCleanUp(t *testing.T) {
+	s, p := newTestSolver(t)
+	ctx := context.Background()
+	name := "_acme-challenge.example.org"
+
+	require.NoError(t, s.Present(ctx, Challenge{FQDN: name + ".", Value: "apex"}))
+	assert.Equal(t, endpoint.Targets{`"apex"`}, challengeTargets(t, p, name))
+
+	// the ownership of the challenge record is recorded by the registry
+	current, err := s.currentRecord(ctx, name)
+	require.NoError(t, err)
+	assert.Equal(t, "acme", current.Labels[endpoint.OwnerLabelKey])
+
+	// the wildcard challenge shares the record with the apex one
+	require.NoError(t, s.Present(ctx, Challenge{FQDN: name + ".", Value: "wildcard"}))
+	assert.Equal(t, endpoint.Targets{`"apex"`, `"wildcard"`}, challengeTargets(t, p, name))
+
+	// presenting the same value twice is a no-op
+	require.NoError(t, s.Present(ctx, Challenge{FQDN: name, Value: "wildcard"}))
+	assert.Equal(t, endpoint.Targets{`"apex"`, `"wildcard"`}, challengeTargets(t, p, name))
+
+	require.NoError(t, s.CleanUp(ctx, Challenge{FQDN: name, Value: "apex"}))
+	assert.Equal(t, endpoint.Targets{`"wildcard"`}, challengeTargets(t, p, name))
+
+	require.NoError(t, s.CleanUp(ctx, Challenge{FQDN: name, Value: "wildcard"}))
+	assert.Nil(t, challengeTargets(t, p, name))
+	records, err := p.Records(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, records, "the ownership records are deleted with the challenge record")
+
+	// cleaning up an absent record succeeds
+	require.NoError(t, s.CleanUp(ctx, Challenge{FQDN: name, Value: "wildcard"}))
+}
+
+func TestSolverRejectsInvalidChallenges(t *testing.T) {
+	s, _ := newTestSolver(t)
+	ctx := context.Background()
+
+	assert.ErrorIs(t, s.Present(ctx, Challenge{FQDN: "_acme-challenge.example.org"}), ErrInvalidChallenge)
+	assert.ErrorIs(t, s.Present(ctx, Challenge{Value: "foo"}), ErrInvalidChallenge)
+	assert.ErrorIs(t, s.Present(ctx, Challenge{FQDN: "_acme-challenge.example.com", Value: "foo"}), ErrDomainNotManaged)
+	// only the challenge records can be written, not e.g. the SPF record of the zone
+	assert.ErrorIs(t, s.Present(ctx, Challenge{FQDN: "example.org", Value: "v=spf1 -all"}), ErrNotChallengeRecord)
+	assert.ErrorIs(t, s.CleanUp(ctx, Challenge{FQDN: "_dmarc.example.org", Value: "v=DMARC1"}), ErrNotChallengeRecord)
+}
+
+type lazyProvider struct {
+	*inmemory.InMemoryProvider
+}
+
+// ApplyChanges accepts the changes without ever making them visible.
+func (p lazyProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	return nil
+}
+
+func TestSolverPropagationTimeout(t *testing.T) {
+	s, p := newTestSolver(t)
+	r, err := registry.NewNoopRegistry(lazyProvider{p})
+	require.NoError(t, err)
+	s.Registry = r
+	s.PropagationTimeout = 50 * time.Millisecond
+
+	err = s.Present(context.Background(), Challenge{FQDN: "_acme-challenge.example.org", Value: "foo"})
+	assert.ErrorIs(t, err, ErrPropagationTimeout)
+}
+
+type staticSource []*endpoint.Endpoint
+
+func (s staticSource) Endpoints(context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints := make([]*endpoint.Endpoint, 0, len(s))
+	for _, ep := range s {
+		endpoints = append(endpoints, ep.DeepCopy())
+	}
+	return endpoints, nil
+}
+
+func (s staticSource) AddEventHandler(context.Context, func()) {}
+
+// TestSolverWithController presents challenges while the controller reconciles the same registry,
+// which is only safe while the solver holds the lock of the controller. Run with -race.
+func TestSolverWithController(t *testing.T) {
+	s, p := newTestSolver(t)
+	r, err := registry.NewTXTRegistry(p, "owner.", "", "acme", time.Hour, "", nil, nil, false, nil)
+	require.NoError(t, err)
+	ctrl := &controller.Controller{
+		Source:             staticSource{endpoint.NewEndpoint("www.example.org", endpoint.RecordTypeA, "1.2.3.4")},
+		Registry:           r,
+		Policy:             &plan.SyncPolicy{},
+		DomainFilter:       endpoint.NewDomainFilter([]string{"example.org"}),
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+	}
+	s.Registry = r
+	s.Lock = ctrl.Exclusive()
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 10; i++ {
+			assert.NoError(t, ctrl.RunOnce(ctx))
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 10; i++ {
+			assert.NoError(t, s.Present(ctx, Challenge{FQDN: "_acme-challenge.example.org", Value: fmt.Sprintf("value-%d", i)}))
+		}
+	}()
+	wg.Wait()
+
+	assert.Len(t, challengeTargets(t, p, "_acme-challenge.example.org"), 10)
+	records, err := r.Records(ctx)
+	require.NoError(t, err)
+	var names []string
+	for _, r := range records {
+		if r.RecordType != endpoint.RecordTypeTXT || r.DNSName == "_acme-challenge.example.org" {
+			names = append(names, r.DNSName)
+		}
+	}
+	assert.ElementsMatch(t, []string{"www.example.org", "_acme-challenge.example.org"}, names)
+}
+
+func TestServerHandlers(t *testing.T) {
+	s, p := newTestSolver(t)
+	srv := Server{Solver: s, Token: []byte("secret")}
+
+	for _, tc := range []struct {
+		title    string
+		method   string
+		handler  http.HandlerFunc
+		body     string
+		auth     func(req *http.Request)
+		expected int
+	}{
+		{"present", http.MethodPost, srv.PresentHandler, `{"fqdn":"_acme-challenge.example.org.","value":"foo"}`, bearer("secret"), http.StatusNoContent},
+		{"cleanup with basic auth", http.MethodPost, srv.CleanUpHandler, `{"fqdn":"_acme-challenge.example.org.","value":"foo"}`, basic("lego", "secret"), http.StatusNoContent},
+		{"no token", http.MethodPost, srv.PresentHandler, `{"fqdn":"_acme-challenge.example.org.","value":"foo"}`, func(*http.Request) {}, http.StatusUnauthorized},
+		{"wrong token", http.MethodPost, srv.PresentHandler, `{"fqdn":"_acme-challenge.example.org.","value":"foo"}`, bearer("guess"), http.StatusUnauthorized},
+		{"wrong password", http.MethodPost, srv.PresentHandler, `{"fqdn":"_acme-challenge.example.org.","value":"foo"}`, basic("lego", "guess"), http.StatusUnauthorized},
+		{"unmanaged domain", http.MethodPost, srv.PresentHandler, `{"fqdn":"_acme-challenge.example.com.","value":"foo"}`, bearer("secret"), http.StatusBadRequest},
+		{"not a challenge record", http.MethodPost, srv.PresentHandler, `{"fqdn":"example.org.","value":"foo"}`, bearer("secret"), http.StatusBadRequest},
+		{"invalid body", http.MethodPost, srv.PresentHandler, `{`, bearer("secret"), http.StatusBadRequest},
+		{"wrong method", http.MethodGet, srv.PresentHandler, ``, bearer("secret"), http.StatusMethodNotAllowed},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, "/", bytes.NewBufferString(tc.body))
+			tc.auth(req)
+			w := httptest.NewRecorder()
+			tc.handler(w, req)
+			assert.Equal(t, tc.expected, w.Code)
+		})
+	}
+
+	assert.Nil(t, challengeTargets(t, p, "_acme-challenge.example.org"))
+}
+
+func TestServerWithoutToken(t *testing.T) {
+	s, p := newTestSolver(t)
+	srv := Server{Solver: s}
+
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{"fqdn":"_acme-challenge.example.org.","value":"foo"}`))
+	bearer("")(req)
+	w := httptest.NewRecorder()
+	srv.PresentHandler(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Nil(t, challengeTargets(t, p, "_acme-challenge.example.org"))
+}
+
+func bearer(token string) func(req *http.Request) {
+	return func(req *http.Request) {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+}
+
+func basic(username, password string) func(req *http.Request) {
+	return func(req *http.Request) {
+		req.SetBasicAuth(username, password)
+	}
+}
+
+func TestChallengeJSON(t *testing.T) {
+	var ch Challenge
+	require.NoError(t, json.Unmarshal([]byte(`{"fqdn":"_acme-challenge.example.org.","value":"foo"}`), &ch))
+	assert.Equal(t, Challenge{FQDN: "_acme-challenge.example.org.", Value: "foo"}, ch)
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/source"
+)
+
+// AnnotationsPath is the path of the validating webhook of the external-dns annotations of
+// resources like Services and Ingresses.
+const AnnotationsPath = "/validate-annotations"
+
+// NamespaceDomains restricts the hostnames of the annotations of the resources of a namespace to
+// domains and their subdomains. The resources of the namespaces without domains are unrestricted.
+type NamespaceDomains map[string][]string
+
+// Parse parses the domains of a namespace given as "<namespace>=<domain>,<domain>",
+// e.g. "team-a=a.example.com,shared.example.com", and adds them to the namespace domains.
+func (n NamespaceDomains) Parse(value string) error {
+	namespace, domains, ok := strings.Cut(value, "=")
+	namespace = strings.TrimSpace(namespace)
+	if !ok || namespace == "" || strings.TrimSpace(domains) == "" {
+		return fmt.Errorf("invalid namespace domains %q: expected <namespace>=<domain>,<domain>", value)
+	}
+	for _, domain := range strings.Split(domains, ",") {
+		if domain = strings.TrimSpace(domain); domain != "" {
+			n[namespace] = append(n[namespace], domain)
+		}
+	}
+	return nil
+}
+
+// NewAnnotationsHandler returns the handler of the validating webhook of the annotations, rejecting
+// the resources with invalid annotations or hostnames outside of the domains of their namespace.
+// With warnOnly, the resources are admitted with warnings instead.
+func NewAnnotationsHandler(namespaceDomains NamespaceDomains, warnOnly bool) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(AnnotationsPath, reviewHandler(func(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+		if req.Operation == admissionv1.Delete {
+			return &admissionv1.AdmissionResponse{Allowed: true}
+		}
+		obj := &metav1.PartialObjectMetadata{}
+		if err := json.Unmarshal(req.Object.Raw, obj); err != nil {
+			return deny(fmt.Sprintf("failed to decode %s: %v", req.Kind.Kind, err))
+		}
+		err := validateAnnotations(obj.Annotations, namespaceDomains[req.Namespace])
+		if err == nil {
+			return &admissionv1.AdmissionResponse{Allowed: true}
+		}
+		if warnOnly {
+			log.Infof("Admitting %s %s/%s with invalid annotations: %v", req.Kind.Kind, req.Namespace, req.Name, err)
+			return &admissionv1.AdmissionResponse{Allowed: true, Warnings: strings.Split(err.Error(), "\n")}
+		}
+		return deny(err.Error())
+	}))
+	return mux
+}
+
+// validateAnnotations validates the annotations as the sources parse them, and that their
+// hostnames are within the domains, if any.
+func validateAnnotations(annotations map[string]string, domains []string) error {
+	errs := []error{source.ValidateAnnotations(annotations)}
+	if len(domains) > 0 {
+		filter := endpoint.NewDomainFilter(domains)
+		for _, hostname := range source.AnnotatedHostnames(annotations) {
+			if hostname != "" && !filter.Match(hostname) {
+				errs = append(errs, fmt.Errorf("hostname %q is outside of the domains of the namespace: %s", hostname, strings.Join(domains, ", ")))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ListenAndServeAnnotationsTLS serves the validating webhook of the annotations on the address
+// with the certificate and key.
+func ListenAndServeAnnotationsTLS(address, certFile, keyFile string, namespaceDomains NamespaceDomains, warnOnly bool) error {
+	log.Infof("Serving the annotations webhook on %s", address)
+	return listenAndServeTLS(address, certFile, keyFile, NewAnnotationsHandler(namespaceDomains, warnOnly))
+}
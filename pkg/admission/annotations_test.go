@@ -0,0 +1,104 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func reviewAnnotations(t *testing.T, handler http.Handler, namespace string, annotations map[string]string) *admissionv1.AdmissionResponse {
+	t.Helper()
+
+	raw, err := json.Marshal(&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: namespace, Annotations: annotations}})
+	require.NoError(t, err)
+	body, err := json.Marshal(&admissionv1.AdmissionReview{
+		Request: &admissionv1.AdmissionRequest{
+			UID:       types.UID("test"),
+			Kind:      metav1.GroupVersionKind{Version: "v1", Kind: "Service"},
+			Namespace: namespace,
+			Name:      "app",
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: raw},
+		},
+	})
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, AnnotationsPath, bytes.NewReader(body)))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	ar := &admissionv1.AdmissionReview{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), ar))
+	require.NotNil(t, ar.Response)
+	assert.Equal(t, types.UID("test"), ar.Response.UID)
+	return ar.Response
+}
+
+func TestNamespaceDomainsParse(t *testing.T) {
+	domains := NamespaceDomains{}
+	require.NoError(t, domains.Parse("team-a=a.example.com, shared.example.com"))
+	require.NoError(t, domains.Parse("team-a=other.example.com"))
+	assert.Equal(t, NamespaceDomains{"team-a": {"a.example.com", "shared.example.com", "other.example.com"}}, domains)
+
+	for _, value := range []string{"team-a", "=a.example.com", "team-a="} {
+		assert.Error(t, domains.Parse(value), value)
+	}
+}
+
+func TestAnnotationsHandler(t *testing.T) {
+	handler := NewAnnotationsHandler(NamespaceDomains{"team-a": {"a.example.com"}}, false)
+
+	resp := reviewAnnotations(t, handler, "team-a", map[string]string{"external-dns.alpha.kubernetes.io/hostname": "app.a.example.com"})
+	assert.True(t, resp.Allowed)
+
+	// the namespaces without domains are unrestricted
+	resp = reviewAnnotations(t, handler, "team-b", map[string]string{"external-dns.alpha.kubernetes.io/hostname": "app.b.example.com"})
+	assert.True(t, resp.Allowed)
+
+	resp = reviewAnnotations(t, handler, "team-a", map[string]string{"external-dns.alpha.kubernetes.io/hostname": "app.b.example.com"})
+	assert.False(t, resp.Allowed)
+	require.NotNil(t, resp.Result)
+	assert.Equal(t, `hostname "app.b.example.com" is outside of the domains of the namespace: a.example.com`, resp.Result.Message)
+
+	resp = reviewAnnotations(t, handler, "team-b", map[string]string{"external-dns.alpha.kubernetes.io/ttl": "0"})
+	assert.False(t, resp.Allowed)
+	require.NotNil(t, resp.Result)
+	assert.Contains(t, resp.Result.Message, "external-dns.alpha.kubernetes.io/ttl")
+}
+
+func TestAnnotationsHandlerWarnOnly(t *testing.T) {
+	handler := NewAnnotationsHandler(NamespaceDomains{"team-a": {"a.example.com"}}, true)
+
+	resp := reviewAnnotations(t, handler, "team-a", map[string]string{
+		"external-dns.alpha.kubernetes.io/hostname": "app.b.example.com",
+		"external-dns.alpha.kubernetes.io/ttl":      "ten",
+	})
+	assert.True(t, resp.Allowed)
+	assert.Len(t, resp.Warnings, 2)
+}
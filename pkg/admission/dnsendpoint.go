@@ -0,0 +1,178 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package admission implements the admission webhooks of DNSEndpoints and of the annotations of
+// resources like Services and Ingresses, so that invalid endpoints are rejected when they are
+// created instead of failing when they are applied by a provider.
+package admission
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+const (
+	// ValidatePath is the path of the validating webhook.
+	ValidatePath = "/validate"
+	// MutatePath is the path of the mutating webhook, which sets the defaults of the endpoints.
+	MutatePath = "/mutate"
+
+	maxRequestSize = 3 * 1024 * 1024
+	timeout        = 10 * time.Second
+)
+
+// jsonPatchOperation is an operation of a JSON patch, see RFC 6902.
+type jsonPatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// NewHandler returns the handler of the validating and mutating webhooks. The validating webhook
+// accepts the endpoints of the passthrough record types with any target. The mutating webhook sets
+// the TTL of the endpoints without one to defaultTTL, unless it is 0.
+func NewHandler(defaultTTL endpoint.TTL, passthroughRecordTypes []string) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(ValidatePath, reviewHandler(dnsEndpointReview(func(d *endpoint.DNSEndpoint) *admissionv1.AdmissionResponse {
+		return validate(d, passthroughRecordTypes)
+	})))
+	mux.Handle(MutatePath, reviewHandler(dnsEndpointReview(func(d *endpoint.DNSEndpoint) *admissionv1.AdmissionResponse {
+		return mutate(d, defaultTTL)
+	})))
+	return mux
+}
+
+// dnsEndpointReview decodes the DNSEndpoint of an admission request for review.
+func dnsEndpointReview(review func(*endpoint.DNSEndpoint) *admissionv1.AdmissionResponse) func(*admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	return func(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+		d := &endpoint.DNSEndpoint{}
+		if err := json.Unmarshal(req.Object.Raw, d); err != nil {
+			return deny(fmt.Sprintf("failed to decode DNSEndpoint: %v", err))
+		}
+		return review(d)
+	}
+}
+
+// reviewHandler decodes an AdmissionReview and responds with the response of review.
+func reviewHandler(review func(*admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestSize))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		ar := &admissionv1.AdmissionReview{}
+		if err := json.Unmarshal(body, ar); err != nil || ar.Request == nil {
+			http.Error(w, "invalid admission review", http.StatusBadRequest)
+			return
+		}
+
+		resp := review(ar.Request)
+		resp.UID = ar.Request.UID
+		log.Debugf("Admission review of %s %s/%s: allowed: %t", ar.Request.Kind.Kind, ar.Request.Namespace, ar.Request.Name, resp.Allowed)
+
+		out, err := json.Marshal(&admissionv1.AdmissionReview{TypeMeta: ar.TypeMeta, Response: resp})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write(out); err != nil {
+			log.Debugf("Failed to write admission review response: %v", err)
+		}
+	})
+}
+
+func validate(d *endpoint.DNSEndpoint, passthroughRecordTypes []string) *admissionv1.AdmissionResponse {
+	if err := d.Validate(passthroughRecordTypes...); err != nil {
+		return deny(err.Error())
+	}
+	return &admissionv1.AdmissionResponse{Allowed: true}
+}
+
+func mutate(d *endpoint.DNSEndpoint, defaultTTL endpoint.TTL) *admissionv1.AdmissionResponse {
+	resp := &admissionv1.AdmissionResponse{Allowed: true}
+	if !defaultTTL.IsConfigured() {
+		return resp
+	}
+
+	var patch []jsonPatchOperation
+	for i, e := range d.Spec.Endpoints {
+		if e == nil || e.RecordTTL.IsConfigured() {
+			continue
+		}
+		patch = append(patch, jsonPatchOperation{
+			Op:    "add",
+			Path:  fmt.Sprintf("/spec/endpoints/%d/recordTTL", i),
+			Value: int64(defaultTTL),
+		})
+	}
+	if len(patch) == 0 {
+		return resp
+	}
+
+	raw, err := json.Marshal(patch)
+	if err != nil {
+		return deny(err.Error())
+	}
+	patchType := admissionv1.PatchTypeJSONPatch
+	resp.Patch = raw
+	resp.PatchType = &patchType
+	return resp
+}
+
+func deny(message string) *admissionv1.AdmissionResponse {
+	return &admissionv1.AdmissionResponse{
+		Allowed: false,
+		Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Message: message,
+			Reason:  metav1.StatusReasonInvalid,
+			Code:    http.StatusUnprocessableEntity,
+		},
+	}
+}
+
+// ListenAndServeTLS serves the webhooks on the address with the certificate and key, as the API
+// server only calls webhooks over HTTPS.
+func ListenAndServeTLS(address, certFile, keyFile string, defaultTTL endpoint.TTL, passthroughRecordTypes []string) error {
+	log.Infof("Serving the DNSEndpoint webhooks on %s", address)
+	return listenAndServeTLS(address, certFile, keyFile, NewHandler(defaultTTL, passthroughRecordTypes))
+}
+
+func listenAndServeTLS(address, certFile, keyFile string, handler http.Handler) error {
+	server := &http.Server{
+		Addr:         address,
+		Handler:      handler,
+		ReadTimeout:  timeout,
+		WriteTimeout: timeout,
+	}
+	return server.ListenAndServeTLS(certFile, keyFile)
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func review(t *testing.T, path string, d *endpoint.DNSEndpoint, passthroughRecordTypes ...string) *admissionv1.AdmissionResponse {
+	t.Helper()
+
+	raw, err := json.Marshal(d)
+	require.NoError(t, err)
+	body, err := json.Marshal(&admissionv1.AdmissionReview{
+		Request: &admissionv1.AdmissionRequest{
+			UID:    types.UID("test"),
+			Object: runtime.RawExtension{Raw: raw},
+		},
+	})
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	NewHandler(300, passthroughRecordTypes).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body)))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	ar := &admissionv1.AdmissionReview{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), ar))
+	require.NotNil(t, ar.Response)
+	assert.Equal(t, types.UID("test"), ar.Response.UID)
+	return ar.Response
+}
+
+func newDNSEndpoint(endpoints ...*endpoint.Endpoint) *endpoint.DNSEndpoint {
+	return &endpoint.DNSEndpoint{Spec: endpoint.DNSEndpointSpec{Endpoints: endpoints}}
+}
+
+func TestValidate(t *testing.T) {
+	resp := review(t, ValidatePath, newDNSEndpoint(endpoint.NewEndpoint("app.example.org", endpoint.RecordTypeA, "1.2.3.4")))
+	assert.True(t, resp.Allowed)
+
+	resp = review(t, ValidatePath, newDNSEndpoint(endpoint.NewEndpoint("app.example.org", endpoint.RecordTypeA, "lb.example.org")))
+	assert.False(t, resp.Allowed)
+	require.NotNil(t, resp.Result)
+	assert.Contains(t, resp.Result.Message, "spec.endpoints[0]: targets[0]")
+
+	// the endpoints of the passthrough record types are accepted with any target
+	loc := newDNSEndpoint(endpoint.NewEndpoint("app.example.org", "LOC", "52 22 23.000 N 4 53 32.000 E -2.00m 0.00m 10000m 10m"))
+	resp = review(t, ValidatePath, loc)
+	assert.False(t, resp.Allowed)
+	resp = review(t, ValidatePath, loc, "LOC")
+	assert.True(t, resp.Allowed)
+}
+
+func TestMutate(t *testing.T) {
+	resp := review(t, MutatePath, newDNSEndpoint(
+		endpoint.NewEndpointWithTTL("app.example.org", endpoint.RecordTypeA, 60, "1.2.3.4"),
+		endpoint.NewEndpoint("www.example.org", endpoint.RecordTypeA, "1.2.3.4"),
+	))
+	assert.True(t, resp.Allowed)
+	require.NotNil(t, resp.PatchType)
+	assert.Equal(t, admissionv1.PatchTypeJSONPatch, *resp.PatchType)
+	assert.JSONEq(t, `[{"op":"add","path":"/spec/endpoints/1/recordTTL","value":300}]`, string(resp.Patch))
+
+	resp = review(t, MutatePath, newDNSEndpoint(endpoint.NewEndpointWithTTL("app.example.org", endpoint.RecordTypeA, 60, "1.2.3.4")))
+	assert.True(t, resp.Allowed)
+	assert.Nil(t, resp.Patch)
+}
+
+func TestInvalidReview(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewHandler(0, nil).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, ValidatePath, bytes.NewReader([]byte("{}"))))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	NewHandler(0, nil).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, ValidatePath, nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
@@ -50,43 +50,75 @@ type Config struct {
 	GlooNamespaces                     []string
 	SkipperRouteGroupVersion           string
 	Sources                            []string
-	Namespace                          string
+	Namespace                          []string
+	ExcludeNamespaces                  []string
 	AnnotationFilter                   string
 	LabelFilter                        string
+	FieldFilter                        string
 	IngressClassNames                  []string
 	FQDNTemplate                       string
+	ApexPairingTemplate                string
+	AnnotationPrefix                   string
+	ControllerValue                    string
 	CombineFQDNAndAnnotation           bool
 	IgnoreHostnameAnnotation           bool
 	IgnoreIngressTLSSpec               bool
 	IgnoreIngressRulesSpec             bool
+	IngressHostnameSource              string
 	GatewayNamespace                   string
 	GatewayLabelFilter                 string
+	GatewayRouteTypes                  []string
 	Compatibility                      string
 	PublishInternal                    bool
+	PublishExternalIPs                 bool
+	MetalLB                            bool
+	MetalLBNamespace                   string
+	KlipperPolicy                      string
+	KlipperSubsetSize                  int
+	KlipperTarget                      string
 	PublishHostIP                      bool
+	PublishNodeSSHFP                   bool
 	AlwaysPublishNotReadyAddresses     bool
 	ConnectorSourceServer              string
 	Provider                           string
+	ProviderFailureThreshold           int
+	ProviderProbeBackoff               time.Duration
 	GoogleProject                      string
+	GoogleCredentialsFile              string
 	GoogleBatchChangeSize              int
 	GoogleBatchChangeInterval          time.Duration
 	GoogleZoneVisibility               string
 	DomainFilter                       []string
 	ExcludeDomains                     []string
+	MinRecordDepth                     int
+	ChangeDescriptionTemplate          string
 	RegexDomainFilter                  *regexp.Regexp
 	RegexDomainExclusion               *regexp.Regexp
 	ZoneNameFilter                     []string
 	ZoneIDFilter                       []string
 	TargetNetFilter                    []string
 	ExcludeTargetNets                  []string
+	MaxTargets                         int
+	TargetSelection                    string
+	PodReadinessConditions             []string
+	NodeReadinessConditions            []string
+	ReadinessDampening                 time.Duration
+	TargetHealthURL                    string
+	TargetHealthFormat                 string
+	TargetHealthMetric                 string
+	TargetHealthInterval               time.Duration
 	AlibabaCloudConfigFile             string
 	AlibabaCloudZoneType               string
 	AWSZoneType                        string
 	AWSZoneTagFilter                   []string
+	AWSRoute53ProfileIDs               []string
 	AWSAssumeRole                      string
 	AWSAssumeRoleExternalID            string
 	AWSBatchChangeSize                 int
+	AWSBatchChangeSizeBytes            int
+	AWSBatchChangeSizeValues           int
 	AWSBatchChangeInterval             time.Duration
+	AWSZoneConcurrency                 int
 	AWSEvaluateTargetHealth            bool
 	AWSAPIRetries                      int
 	AWSPreferCNAME                     bool
@@ -94,10 +126,14 @@ type Config struct {
 	AWSSDServiceCleanup                bool
 	AWSDynamoDBRegion                  string
 	AWSDynamoDBTable                   string
+	AWSDynamoDBRecordHistory           int
+	AWSDynamoDBHistoryRetention        time.Duration
 	AzureConfigFile                    string
 	AzureResourceGroup                 string
 	AzureSubscriptionID                string
 	AzureUserAssignedIdentityClientID  string
+	AzurePrivateDNSVNetIDs             []string
+	AzurePrivateDNSCreateVNetLinks     bool
 	BluecatDNSConfiguration            string
 	BluecatConfigFile                  string
 	BluecatDNSView                     string
@@ -106,8 +142,11 @@ type Config struct {
 	BluecatDNSServerName               string
 	BluecatDNSDeployType               string
 	BluecatSkipTLSVerify               bool
+	BluecatAddressManagerHost          string
+	BluecatDeployInterval              time.Duration
 	CloudflareProxied                  bool
 	CloudflareDNSRecordsPerPage        int
+	CloudflareAPITokenVaultPath        string
 	CoreDNSPrefix                      string
 	RcodezeroTXTEncrypt                bool
 	AkamaiServiceConsumerDomain        string
@@ -116,6 +155,7 @@ type Config struct {
 	AkamaiAccessToken                  string
 	AkamaiEdgercPath                   string
 	AkamaiEdgercSection                string
+	AkamaiUseChangelists               bool
 	InfobloxGridHost                   string
 	InfobloxWapiPort                   int
 	InfobloxWapiUsername               string
@@ -128,6 +168,10 @@ type Config struct {
 	InfobloxNameRegEx                  string
 	InfobloxCreatePTR                  bool
 	InfobloxCacheDuration              int
+	InfobloxDomainViews                []string
+	InfobloxDomainNetworkViews         []string
+	InfobloxOwnerEA                    string
+	InfobloxResourceEA                 string
 	DynCustomerName                    string
 	DynUsername                        string
 	DynPassword                        string `secure:"yes"`
@@ -137,11 +181,13 @@ type Config struct {
 	OCIAuthInstancePrincipal           bool
 	OCIZoneScope                       string
 	OCIZoneCacheDuration               time.Duration
+	OCIPrivateViewIDs                  []string
 	InMemoryZones                      []string
 	OVHEndpoint                        string
 	OVHApiRateLimit                    int
 	PDNSServer                         string
 	PDNSAPIKey                         string `secure:"yes"`
+	PDNSAPIKeyVaultPath                string
 	PDNSSkipTLSVerify                  bool
 	TLSCA                              string
 	TLSClientCert                      string
@@ -153,13 +199,54 @@ type Config struct {
 	TXTSuffix                          string
 	TXTEncryptEnabled                  bool
 	TXTEncryptAESKey                   string `secure:"yes"`
+	TXTGCInterval                      time.Duration
+	TXTGCDryRun                        bool
+	RegistrySnapshotSave               string
+	RegistrySnapshotRestore            string
+	RecordsSnapshot                    string
+	RecordsSnapshotSave                string
 	Interval                           time.Duration
 	MinEventSyncInterval               time.Duration
+	FullSyncInterval                   time.Duration
+	DampeningWindow                    time.Duration
+	TargetChangeTTL                    time.Duration
+	TargetChangeSoak                   time.Duration
+	ProviderBatchSize                  int
+	ProviderBatchInterval              time.Duration
+	RecordsPagination                  bool
+	MaintenanceWindows                 []string
+	ChangeWindows                      []string
+	CanaryZones                        []string
+	CanaryNameserver                   string
+	CanaryTimeout                      time.Duration
 	Once                               bool
 	DryRun                             bool
+	ReadOnly                           bool
 	UpdateEvents                       bool
+	WarningEvents                      bool
+	PublishedHostnamesAnnotation       bool
+	NodeDeletionEvents                 bool
 	LogFormat                          string
 	MetricsAddress                     string
+	DebugDNSAddress                    string
+	CredentialsReloadInterval          time.Duration
+	VaultAddress                       string
+	VaultNamespace                     string
+	VaultCACert                        string
+	VaultAuthMethod                    string
+	VaultAuthMountPath                 string
+	VaultAppRoleRoleID                 string
+	VaultAppRoleSecretIDFile           string
+	VaultKubernetesRole                string
+	VaultKubernetesTokenFile           string
+	SnapshotPath                       string
+	HistoryDir                         string
+	HistoryLimit                       int
+	RollbackEndpoint                   bool
+	RollbackEndpointTokenFile          string
+	ExplainEndpoint                    bool
+	ChurnWindow                        time.Duration
+	ChurnThreshold                     int
 	LogLevel                           string
 	TXTCacheInterval                   time.Duration
 	TXTWildcardReplacement             string
@@ -170,11 +257,14 @@ type Config struct {
 	ExoscaleAPIZone                    string
 	CRDSourceAPIVersion                string
 	CRDSourceKind                      string
+	GenericCRDMappings                 []string
 	ServiceTypeFilter                  []string
+	LoadBalancerClassFilter            []string
 	CFAPIEndpoint                      string
 	CFUsername                         string
 	CFPassword                         string
 	ResolveServiceLoadBalancerHostname bool
+	ResolveGatewayHostnameAddresses    bool
 	RFC2136Host                        string
 	RFC2136Port                        int
 	RFC2136Zone                        []string
@@ -185,18 +275,33 @@ type Config struct {
 	RFC2136KerberosPassword            string `secure:"yes"`
 	RFC2136TSIGKeyName                 string
 	RFC2136TSIGSecret                  string `secure:"yes"`
+	RFC2136TSIGSecretFile              string
+	RFC2136TSIGSecretVaultPath         string
 	RFC2136TSIGSecretAlg               string
 	RFC2136TAXFR                       bool
 	RFC2136MinTTL                      time.Duration
 	RFC2136BatchChangeSize             int
+	RFC2136ZoneConcurrency             int
+	RFC2136ClockSkew                   time.Duration
+	RFC2136UpdateCheck                 bool
+	RFC2136LocalAddress                string
+	RFC2136DiscoverPrimary             bool
+	RFC2136FallbackHosts               []string
+	RFC2136ADSite                      string
+	RFC2136ADDomain                    string
+	RFC2136Retries                     int
+	RFC2136RetryBackoff                time.Duration
+	RFC2136RetryBudget                 int
 	NS1Endpoint                        string
 	NS1IgnoreSSL                       bool
 	NS1MinTTLSeconds                   int
 	TransIPAccountName                 string
 	TransIPPrivateKeyFile              string
 	DigitalOceanAPIPageSize            int
+	DNSimpleZoneCacheDuration          time.Duration
 	ManagedDNSRecordTypes              []string
 	ExcludeDNSRecordTypes              []string
+	PassthroughRecordTypes             []string
 	GoDaddyAPIKey                      string `secure:"yes"`
 	GoDaddySecretKey                   string `secure:"yes"`
 	GoDaddyTTL                         int64
@@ -209,14 +314,42 @@ type Config struct {
 	PiholeServer                       string
 	PiholePassword                     string `secure:"yes"`
 	PiholeTLSInsecureSkipVerify        bool
+	PiholeAPIVersion                   string
+	DnsmasqHostsFile                   string
+	DnsmasqConfFile                    string
+	DnsmasqPidFile                     string
+	UnboundControlAddress              string
+	UnboundServerCertFile              string
+	UnboundControlCertFile             string
+	UnboundControlKeyFile              string
+	UnboundLocalZones                  []string
+	UnboundLocalZoneType               string
+	TechnitiumServer                   string
+	TechnitiumToken                    string `secure:"yes"`
+	TechnitiumSkipTLSVerify            bool
 	PluralCluster                      string
 	PluralProvider                     string
 	WebhookProviderURL                 string
 	WebhookProviderReadTimeout         time.Duration
 	WebhookProviderWriteTimeout        time.Duration
 	WebhookServer                      bool
+	WebhookServerAddress               string
+	WebhookServerClientsFile           string
+	WebhookServerTLSCertFile           string
+	WebhookServerTLSKeyFile            string
+	WebhookProviderTokenFile           string
 	TraefikDisableLegacy               bool
 	TraefikDisableNew                  bool
+	QuarantineSources                  bool
+	QuarantineMinEndpoints             int
+	QuarantineMaxDuration              time.Duration
+	SourcePermissionsCheck             string
+	ACMEServer                         bool
+	ACMEServerAddress                  string
+	ACMEServerTokenFile                string
+	ACMEChallengeTTL                   int64
+	ACMEPropagationTimeout             time.Duration
+	ACMEPropagationInterval            time.Duration
 }
 
 var defaultConfig = &Config{
@@ -227,23 +360,37 @@ var defaultConfig = &Config{
 	GlooNamespaces:              []string{"gloo-system"},
 	SkipperRouteGroupVersion:    "zalando.org/v1",
 	Sources:                     nil,
-	Namespace:                   "",
+	Namespace:                   []string{},
+	ExcludeNamespaces:           []string{},
 	AnnotationFilter:            "",
 	LabelFilter:                 labels.Everything().String(),
+	FieldFilter:                 "",
 	IngressClassNames:           nil,
 	FQDNTemplate:                "",
+	AnnotationPrefix:            "external-dns.alpha.kubernetes.io/",
+	ControllerValue:             "dns-controller",
 	CombineFQDNAndAnnotation:    false,
 	IgnoreHostnameAnnotation:    false,
 	IgnoreIngressTLSSpec:        false,
 	IgnoreIngressRulesSpec:      false,
+	IngressHostnameSource:       "",
 	GatewayNamespace:            "",
 	GatewayLabelFilter:          "",
+	GatewayRouteTypes:           []string{},
 	Compatibility:               "",
 	PublishInternal:             false,
+	PublishExternalIPs:          false,
+	MetalLB:                     false,
+	MetalLBNamespace:            "metallb-system",
+	KlipperPolicy:               "",
+	KlipperSubsetSize:           3,
+	KlipperTarget:               "",
 	PublishHostIP:               false,
 	ConnectorSourceServer:       "localhost:8080",
 	Provider:                    "",
+	ProviderProbeBackoff:        30 * time.Second,
 	GoogleProject:               "",
+	GoogleCredentialsFile:       "",
 	GoogleBatchChangeSize:       1000,
 	GoogleBatchChangeInterval:   time.Second,
 	GoogleZoneVisibility:        "",
@@ -254,13 +401,25 @@ var defaultConfig = &Config{
 	RegexDomainExclusion:        regexp.MustCompile(""),
 	TargetNetFilter:             []string{},
 	ExcludeTargetNets:           []string{},
+	MaxTargets:                  0,
+	TargetSelection:             "stable-hash",
+	PodReadinessConditions:      []string{},
+	NodeReadinessConditions:     []string{},
+	ReadinessDampening:          0,
+	TargetHealthURL:             "",
+	TargetHealthFormat:          "json",
+	TargetHealthMetric:          "target_healthy",
+	TargetHealthInterval:        30 * time.Second,
 	AlibabaCloudConfigFile:      "/etc/kubernetes/alibaba-cloud.json",
 	AWSZoneType:                 "",
 	AWSZoneTagFilter:            []string{},
 	AWSAssumeRole:               "",
 	AWSAssumeRoleExternalID:     "",
 	AWSBatchChangeSize:          1000,
+	AWSBatchChangeSizeBytes:     32000,
+	AWSBatchChangeSizeValues:    1000,
 	AWSBatchChangeInterval:      time.Second,
+	AWSZoneConcurrency:          1,
 	AWSEvaluateTargetHealth:     true,
 	AWSAPIRetries:               3,
 	AWSPreferCNAME:              false,
@@ -268,13 +427,16 @@ var defaultConfig = &Config{
 	AWSSDServiceCleanup:         false,
 	AWSDynamoDBRegion:           "",
 	AWSDynamoDBTable:            "external-dns",
+	AWSDynamoDBHistoryRetention: 7 * 24 * time.Hour,
 	AzureConfigFile:             "/etc/kubernetes/azure.json",
 	AzureResourceGroup:          "",
 	AzureSubscriptionID:         "",
+	AzurePrivateDNSVNetIDs:      []string{},
 	BluecatConfigFile:           "/etc/kubernetes/bluecat.json",
 	BluecatDNSDeployType:        "no-deploy",
 	CloudflareProxied:           false,
 	CloudflareDNSRecordsPerPage: 100,
+	CloudflareAPITokenVaultPath: "",
 	CoreDNSPrefix:               "/skydns/",
 	RcodezeroTXTEncrypt:         false,
 	AkamaiServiceConsumerDomain: "",
@@ -283,6 +445,7 @@ var defaultConfig = &Config{
 	AkamaiAccessToken:           "",
 	AkamaiEdgercSection:         "",
 	AkamaiEdgercPath:            "",
+	AkamaiUseChangelists:        false,
 	InfobloxGridHost:            "",
 	InfobloxWapiPort:            443,
 	InfobloxWapiUsername:        "admin",
@@ -294,14 +457,18 @@ var defaultConfig = &Config{
 	InfobloxFQDNRegEx:           "",
 	InfobloxCreatePTR:           false,
 	InfobloxCacheDuration:       0,
+	InfobloxOwnerEA:             "external-dns-owner",
+	InfobloxResourceEA:          "external-dns-resource",
 	OCIConfigFile:               "/etc/kubernetes/oci.yaml",
 	OCIZoneScope:                "GLOBAL",
 	OCIZoneCacheDuration:        0 * time.Second,
+	OCIPrivateViewIDs:           []string{},
 	InMemoryZones:               []string{},
 	OVHEndpoint:                 "ovh-eu",
 	OVHApiRateLimit:             20,
 	PDNSServer:                  "http://localhost:8081",
 	PDNSAPIKey:                  "",
+	PDNSAPIKeyVaultPath:         "",
 	PDNSSkipTLSVerify:           false,
 	TLSCA:                       "",
 	TLSClientCert:               "",
@@ -314,11 +481,25 @@ var defaultConfig = &Config{
 	TXTCacheInterval:            0,
 	TXTWildcardReplacement:      "",
 	MinEventSyncInterval:        5 * time.Second,
+	CanaryTimeout:               2 * time.Minute,
+	HistoryLimit:                20,
+	ChurnThreshold:              10,
+	CredentialsReloadInterval:   time.Minute,
+	VaultAddress:                "",
+	VaultNamespace:              "",
+	VaultCACert:                 "",
+	VaultAuthMethod:             "kubernetes",
+	VaultAuthMountPath:          "",
+	VaultAppRoleRoleID:          "",
+	VaultAppRoleSecretIDFile:    "",
+	VaultKubernetesRole:         "",
+	VaultKubernetesTokenFile:    "/var/run/secrets/kubernetes.io/serviceaccount/token",
 	TXTEncryptEnabled:           false,
 	TXTEncryptAESKey:            "",
 	Interval:                    time.Minute,
 	Once:                        false,
 	DryRun:                      false,
+	ReadOnly:                    false,
 	UpdateEvents:                false,
 	LogFormat:                   "text",
 	MetricsAddress:              ":7979",
@@ -329,7 +510,9 @@ var defaultConfig = &Config{
 	ExoscaleAPISecret:           "",
 	CRDSourceAPIVersion:         "externaldns.k8s.io/v1alpha1",
 	CRDSourceKind:               "DNSEndpoint",
+	GenericCRDMappings:          []string{},
 	ServiceTypeFilter:           []string{},
+	LoadBalancerClassFilter:     []string{},
 	CFAPIEndpoint:               "",
 	CFUsername:                  "",
 	CFPassword:                  "",
@@ -343,15 +526,23 @@ var defaultConfig = &Config{
 	RFC2136KerberosPassword:     "",
 	RFC2136TSIGKeyName:          "",
 	RFC2136TSIGSecret:           "",
+	RFC2136TSIGSecretFile:       "",
+	RFC2136TSIGSecretVaultPath:  "",
 	RFC2136TSIGSecretAlg:        "",
 	RFC2136TAXFR:                true,
 	RFC2136MinTTL:               0,
 	RFC2136BatchChangeSize:      50,
+	RFC2136ZoneConcurrency:      1,
+	RFC2136ClockSkew:            5 * time.Minute,
+	RFC2136Retries:              2,
+	RFC2136RetryBackoff:         time.Second,
+	RFC2136RetryBudget:          10,
 	NS1Endpoint:                 "",
 	NS1IgnoreSSL:                false,
 	TransIPAccountName:          "",
 	TransIPPrivateKeyFile:       "",
 	DigitalOceanAPIPageSize:     50,
+	DNSimpleZoneCacheDuration:   0 * time.Second,
 	ManagedDNSRecordTypes:       []string{endpoint.RecordTypeA, endpoint.RecordTypeAAAA, endpoint.RecordTypeCNAME},
 	ExcludeDNSRecordTypes:       []string{},
 	GoDaddyAPIKey:               "",
@@ -365,14 +556,41 @@ var defaultConfig = &Config{
 	PiholeServer:                "",
 	PiholePassword:              "",
 	PiholeTLSInsecureSkipVerify: false,
+	PiholeAPIVersion:            "5",
+	DnsmasqHostsFile:            "",
+	DnsmasqConfFile:             "",
+	DnsmasqPidFile:              "",
+	UnboundControlAddress:       "127.0.0.1:8953",
+	UnboundServerCertFile:       "",
+	UnboundControlCertFile:      "",
+	UnboundControlKeyFile:       "",
+	UnboundLocalZoneType:        "transparent",
+	TechnitiumServer:            "",
+	TechnitiumToken:             "",
+	TechnitiumSkipTLSVerify:     false,
 	PluralCluster:               "",
 	PluralProvider:              "",
 	WebhookProviderURL:          "http://localhost:8888",
 	WebhookProviderReadTimeout:  5 * time.Second,
 	WebhookProviderWriteTimeout: 10 * time.Second,
 	WebhookServer:               false,
+	WebhookServerAddress:        "127.0.0.1:8888",
+	WebhookServerClientsFile:    "",
+	WebhookServerTLSCertFile:    "",
+	WebhookServerTLSKeyFile:     "",
+	WebhookProviderTokenFile:    "",
 	TraefikDisableLegacy:        false,
 	TraefikDisableNew:           false,
+	QuarantineSources:           false,
+	QuarantineMinEndpoints:      10,
+	QuarantineMaxDuration:       0,
+	SourcePermissionsCheck:      "",
+	ACMEServer:                  false,
+	ACMEServerAddress:           "127.0.0.1:8889",
+	ACMEServerTokenFile:         "",
+	ACMEChallengeTTL:            60,
+	ACMEPropagationTimeout:      2 * time.Minute,
+	ACMEPropagationInterval:     2 * time.Second,
 }
 
 // NewConfig returns new Config object
@@ -421,6 +639,7 @@ func (cfg *Config) ParseFlags(args []string) error {
 	app.Flag("kubeconfig", "Retrieve target cluster configuration from a Kubernetes configuration file (default: auto-detect)").Default(defaultConfig.KubeConfig).StringVar(&cfg.KubeConfig)
 	app.Flag("request-timeout", "Request timeout when calling Kubernetes APIs. 0s means no timeout").Default(defaultConfig.RequestTimeout.String()).DurationVar(&cfg.RequestTimeout)
 	app.Flag("resolve-service-load-balancer-hostname", "Resolve the hostname of LoadBalancer-type Service object to IP addresses in order to create DNS A/AAAA records instead of CNAMEs").BoolVar(&cfg.ResolveServiceLoadBalancerHostname)
+	app.Flag("resolve-gateway-hostname-addresses", "Resolve the hostname addresses of Gateways to IP addresses in order to create DNS A/AAAA records instead of CNAMEs").BoolVar(&cfg.ResolveGatewayHostnameAddresses)
 
 	// Flags related to cloud foundry
 	app.Flag("cf-api-endpoint", "The fully-qualified domain name of the cloud foundry instance you are targeting").Default(defaultConfig.CFAPIEndpoint).StringVar(&cfg.CFAPIEndpoint)
@@ -434,56 +653,95 @@ func (cfg *Config) ParseFlags(args []string) error {
 	app.Flag("skipper-routegroup-groupversion", "The resource version for skipper routegroup").Default(source.DefaultRoutegroupVersion).StringVar(&cfg.SkipperRouteGroupVersion)
 
 	// Flags related to processing source
-	app.Flag("source", "The resource types that are queried for endpoints; specify multiple times for multiple sources (required, options: service, ingress, node, pod, fake, connector, gateway-httproute, gateway-grpcroute, gateway-tlsroute, gateway-tcproute, gateway-udproute, istio-gateway, istio-virtualservice, cloudfoundry, contour-httpproxy, gloo-proxy, crd, empty, skipper-routegroup, openshift-route, ambassador-host, kong-tcpingress, f5-virtualserver, traefik-proxy)").Required().PlaceHolder("source").EnumsVar(&cfg.Sources, "service", "ingress", "node", "pod", "gateway-httproute", "gateway-grpcroute", "gateway-tlsroute", "gateway-tcproute", "gateway-udproute", "istio-gateway", "istio-virtualservice", "cloudfoundry", "contour-httpproxy", "gloo-proxy", "fake", "connector", "crd", "empty", "skipper-routegroup", "openshift-route", "ambassador-host", "kong-tcpingress", "f5-virtualserver", "traefik-proxy")
+	app.Flag("source", "The resource types that are queried for endpoints; specify multiple times for multiple sources (required, options: service, ingress, node, pod, fake, connector, gateway-httproute, gateway-grpcroute, gateway-tlsroute, gateway-tcproute, gateway-udproute, gateway-route, istio-gateway, istio-virtualservice, cloudfoundry, contour-httpproxy, gloo-proxy, crd, generic-crd, cutover, zone-delegation, verification, empty, skipper-routegroup, openshift-route, ambassador-host, kong-tcpingress, f5-virtualserver, traefik-proxy)").Required().PlaceHolder("source").EnumsVar(&cfg.Sources, "service", "ingress", "node", "pod", "gateway-httproute", "gateway-grpcroute", "gateway-tlsroute", "gateway-tcproute", "gateway-udproute", "gateway-route", "istio-gateway", "istio-virtualservice", "cloudfoundry", "contour-httpproxy", "gloo-proxy", "fake", "connector", "crd", "generic-crd", "cutover", "zone-delegation", "verification", "empty", "skipper-routegroup", "openshift-route", "ambassador-host", "kong-tcpingress", "f5-virtualserver", "traefik-proxy")
 	app.Flag("openshift-router-name", "if source is openshift-route then you can pass the ingress controller name. Based on this name external-dns will select the respective router from the route status and map that routerCanonicalHostname to the route host while creating a CNAME record.").StringVar(&cfg.OCPRouterName)
-	app.Flag("namespace", "Limit resources queried for endpoints to a specific namespace (default: all namespaces)").Default(defaultConfig.Namespace).StringVar(&cfg.Namespace)
+	app.Flag("namespace", "Limit resources queried for endpoints to a specific namespace; specify multiple times for multiple namespaces (default: all namespaces)").Default("").StringsVar(&cfg.Namespace)
+	app.Flag("exclude-namespaces", "Exclude the resources of a namespace from the resources queried for endpoints; specify multiple times for multiple namespaces (optional)").Default("").StringsVar(&cfg.ExcludeNamespaces)
 	app.Flag("annotation-filter", "Filter resources queried for endpoints by annotation, using label selector semantics").Default(defaultConfig.AnnotationFilter).StringVar(&cfg.AnnotationFilter)
-	app.Flag("label-filter", "Filter resources queried for endpoints by label selector; currently supported by source types crd, gateway-httproute, gateway-grpcroute, gateway-tlsroute, gateway-tcproute, gateway-udproute, ingress, node, openshift-route, and service").Default(defaultConfig.LabelFilter).StringVar(&cfg.LabelFilter)
+	app.Flag("label-filter", "Filter resources queried for endpoints by label selector, applied by the API server; supported by source types ambassador-host, contour-httpproxy, crd, cutover, f5-virtualserver, generic-crd, gateway-httproute, gateway-grpcroute, gateway-tlsroute, gateway-tcproute, gateway-udproute, gateway-route, gloo-proxy, ingress, istio-gateway, istio-virtualservice, kong-tcpingress, node, openshift-route, pod, service, skipper-routegroup, traefik-proxy, verification and zone-delegation; note that the ambassador-host and pod sources used to ignore it").Default(defaultConfig.LabelFilter).StringVar(&cfg.LabelFilter)
+	app.Flag("field-filter", "Filter resources queried for endpoints by field selector, applied by the API server, e.g. metadata.namespace!=kube-system; supported by the same source types as --label-filter (default: all resources)").Default(defaultConfig.FieldFilter).StringVar(&cfg.FieldFilter)
 	app.Flag("ingress-class", "Require an Ingress to have this class name (defaults to any class; specify multiple times to allow more than one class)").StringsVar(&cfg.IngressClassNames)
 	app.Flag("fqdn-template", "A templated string that's used to generate DNS names from sources that don't define a hostname themselves, or to add a hostname suffix when paired with the fake source (optional). Accepts comma separated list for multiple global FQDN.").Default(defaultConfig.FQDNTemplate).StringVar(&cfg.FQDNTemplate)
+	app.Flag("annotation-prefix", "The prefix of the annotations read from the resources of the sources, e.g. internal-dns/ to read internal-dns/hostname, to target several instances of ExternalDNS independently (default: external-dns.alpha.kubernetes.io/)").Default(defaultConfig.AnnotationPrefix).StringVar(&cfg.AnnotationPrefix)
+	app.Flag("controller-value", "The value of the controller annotation of the resources this instance is responsible for, resources annotated with another value are left to other instances of ExternalDNS (default: dns-controller)").Default(defaultConfig.ControllerValue).StringVar(&cfg.ControllerValue)
 	app.Flag("combine-fqdn-annotation", "Combine FQDN template and Annotations instead of overwriting").BoolVar(&cfg.CombineFQDNAndAnnotation)
+	app.Flag("apex-pairing-template", "A templated string of the hostname paired with each apex, a domain of --domain-filter, e.g. `www.{{ .Apex }}`: a CNAME record of the paired hostname pointing at the apex is created when only the apex is requested, and the A and AAAA records of the apex when only the paired hostname is requested (optional)").StringVar(&cfg.ApexPairingTemplate)
 	app.Flag("ignore-hostname-annotation", "Ignore hostname annotation when generating DNS names, valid only when --fqdn-template is set (default: false)").BoolVar(&cfg.IgnoreHostnameAnnotation)
 	app.Flag("ignore-ingress-tls-spec", "Ignore the spec.tls section in Ingress resources (default: false)").BoolVar(&cfg.IgnoreIngressTLSSpec)
 	app.Flag("gateway-namespace", "Limit Gateways of Route endpoints to a specific namespace (default: all namespaces)").StringVar(&cfg.GatewayNamespace)
 	app.Flag("gateway-label-filter", "Filter Gateways of Route endpoints via label selector (default: all gateways)").StringVar(&cfg.GatewayLabelFilter)
+	app.Flag("gateway-route-type", "A Gateway API route type read by the gateway-route source, e.g. an experimental or implementation specific one, as resource.group/version followed by optional ;-separated kind=<Kind>, protocol=<HTTP|HTTPS|TLS|TCP|UDP>, hostnames=<JSONPath> (default: {.spec.hostnames[*]}) and parents=<JSONPath> (default: {.status.parents}); specify multiple times for multiple route types").StringsVar(&cfg.GatewayRouteTypes)
 	app.Flag("compatibility", "Process annotation semantics from legacy implementations (optional, options: mate, molecule, kops-dns-controller)").Default(defaultConfig.Compatibility).EnumVar(&cfg.Compatibility, "", "mate", "molecule", "kops-dns-controller")
 	app.Flag("ignore-ingress-rules-spec", "Ignore the spec.rules section in Ingress resources (default: false)").BoolVar(&cfg.IgnoreIngressRulesSpec)
+	app.Flag("ingress-hostname-source", "The hostnames of the Ingress resources without the external-dns.alpha.kubernetes.io/ingress-hostname-source annotation, taking the same values: those of the spec only, of the annotations only, or of the spec.tls hosts only, e.g. the names of the certificates next to wildcard rules (default: those of the spec and the annotations, options: defined-hosts-only, annotation-only, tls-only)").Default(defaultConfig.IngressHostnameSource).EnumVar(&cfg.IngressHostnameSource, "", "defined-hosts-only", "annotation-only", "tls-only")
 	app.Flag("publish-internal-services", "Allow external-dns to publish DNS records for ClusterIP services (optional)").BoolVar(&cfg.PublishInternal)
+	app.Flag("publish-external-ips", "Allow external-dns to publish the spec.externalIPs of ClusterIP and NodePort services, rather than nothing or the IPs of the nodes (optional)").BoolVar(&cfg.PublishExternalIPs)
+	app.Flag("metallb", "Publish the LoadBalancer services allocated an address by MetalLB only once their IPAddressPool is advertised and, by L2Advertisements, a node announces them, and publish the targets of the IPAddressPools annotated with them instead of those of their services (default: disabled)").BoolVar(&cfg.MetalLB)
+	app.Flag("metallb-namespace", "The namespace of the IPAddressPools, advertisements and ServiceL2Statuses of MetalLB").Default(defaultConfig.MetalLBNamespace).StringVar(&cfg.MetalLBNamespace)
+	app.Flag("klipper-policy", "The targets of the LoadBalancer services of Klipper, the ServiceLB of k3s, whose status has the IPs of the nodes running its pods: the IPs of all the nodes, a subset of the IPs of the status stable as the other nodes come and go, or --klipper-target (default: the IPs of the status, options: all-nodes, stable-subset, target)").Default(defaultConfig.KlipperPolicy).EnumVar(&cfg.KlipperPolicy, "", "all-nodes", "stable-subset", "target")
+	app.Flag("klipper-subset-size", "The number of IPs of the LoadBalancer services of Klipper published by --klipper-policy=stable-subset").Default(strconv.Itoa(defaultConfig.KlipperSubsetSize)).IntVar(&cfg.KlipperSubsetSize)
+	app.Flag("klipper-target", "The target of the LoadBalancer services of Klipper published by --klipper-policy=target, e.g. the hostname of the Traefik ingress controller").Default(defaultConfig.KlipperTarget).StringVar(&cfg.KlipperTarget)
 	app.Flag("publish-host-ip", "Allow external-dns to publish host-ip for headless services (optional)").BoolVar(&cfg.PublishHostIP)
+	app.Flag("publish-node-sshfp", "Publish the SSH host keys of the nodes annotated with external-dns.alpha.kubernetes.io/ssh-host-keys as SSHFP records, valid only when using the node source (optional)").BoolVar(&cfg.PublishNodeSSHFP)
 	app.Flag("always-publish-not-ready-addresses", "Always publish also not ready addresses for headless services (optional)").BoolVar(&cfg.AlwaysPublishNotReadyAddresses)
 	app.Flag("connector-source-server", "The server to connect for connector source, valid only when using connector source").Default(defaultConfig.ConnectorSourceServer).StringVar(&cfg.ConnectorSourceServer)
 	app.Flag("crd-source-apiversion", "API version of the CRD for crd source, e.g. `externaldns.k8s.io/v1alpha1`, valid only when using crd source").Default(defaultConfig.CRDSourceAPIVersion).StringVar(&cfg.CRDSourceAPIVersion)
 	app.Flag("crd-source-kind", "Kind of the CRD for the crd source in API group and version specified by crd-source-apiversion").Default(defaultConfig.CRDSourceKind).StringVar(&cfg.CRDSourceKind)
+	app.Flag("generic-crd", "A custom resource read by the generic-crd source, as resource.group/version followed by ;-separated hostnames=<JSONPath> and targets=<JSONPath>, and optional ttl=<JSONPath> and provider-specific=<JSONPath>; specify multiple times for multiple custom resources").StringsVar(&cfg.GenericCRDMappings)
 	app.Flag("service-type-filter", "The service types to take care about (default: all, expected: ClusterIP, NodePort, LoadBalancer or ExternalName)").StringsVar(&cfg.ServiceTypeFilter)
-	app.Flag("managed-record-types", "Record types to manage; specify multiple times to include many; (default: A, AAAA, CNAME) (supported records: A, AAAA, CNAME, NS, SRV, TXT)").Default("A", "AAAA", "CNAME").StringsVar(&cfg.ManagedDNSRecordTypes)
+	app.Flag("service-load-balancer-class", "The loadBalancerClass of the LoadBalancer services to take care about, the empty class for the services without one; specify multiple times for multiple classes (default: all)").StringsVar(&cfg.LoadBalancerClassFilter)
+	app.Flag("managed-record-types", "Record types to manage; specify multiple times to include many; (default: A, AAAA, CNAME) (supported records: A, AAAA, CNAME, NAPTR, NS, SRV, SSHFP, TXT)").Default("A", "AAAA", "CNAME").StringsVar(&cfg.ManagedDNSRecordTypes)
+	app.Flag("passthrough-record-types", "Record types of the DNSEndpoints passed through with their raw RDATA, e.g. LOC or CERT, to the providers supporting it (rfc2136); they must be managed too; specify multiple times to pass through many (optional)").StringsVar(&cfg.PassthroughRecordTypes)
 	app.Flag("exclude-record-types", "Record types to exclude from management; specify multiple times to exclude many; (optional)").Default().StringsVar(&cfg.ExcludeDNSRecordTypes)
 	app.Flag("default-targets", "Set globally default host/IP that will apply as a target instead of source addresses. Specify multiple times for multiple targets (optional)").StringsVar(&cfg.DefaultTargets)
 	app.Flag("target-net-filter", "Limit possible targets by a net filter; specify multiple times for multiple possible nets (optional)").StringsVar(&cfg.TargetNetFilter)
 	app.Flag("exclude-target-net", "Exclude target nets (optional)").StringsVar(&cfg.ExcludeTargetNets)
+	app.Flag("max-targets", "Keep at most this number of targets per record, for the providers limiting the targets of a record set, e.g. of the headless services with many pods (default: 0, unlimited)").Default(strconv.Itoa(defaultConfig.MaxTargets)).IntVar(&cfg.MaxTargets)
+	app.Flag("target-selection", "The targets kept above --max-targets: those of the highest hashes of the record name and target, stable while other targets come and go, the lowest ones, or the first ones listed by the source, the ready addresses of headless services before the not ready ones (default: stable-hash, options: stable-hash, lowest, ready)").Default(defaultConfig.TargetSelection).EnumVar(&cfg.TargetSelection, "stable-hash", "lowest", "ready")
+	app.Flag("pod-readiness-condition", "A condition the pods of headless services and NodePort services with the Local external traffic policy must have for their targets to be published, e.g. the condition of a readiness gate, as type (True) or type=<True|False|Unknown>; specify multiple times for multiple conditions (optional)").StringsVar(&cfg.PodReadinessConditions)
+	app.Flag("node-readiness-condition", "A condition the nodes of NodePort services and of headless services publishing NodeExternalIP must have for their targets to be published, as type (True) or type=<True|False|Unknown>, e.g. Ready; specify multiple times for multiple conditions (optional)").StringsVar(&cfg.NodeReadinessConditions)
+	app.Flag("readiness-dampening", "How long a change of the readiness conditions of a pod or node must last for its targets to be published or removed (default: 0s, immediately)").Default(defaultConfig.ReadinessDampening.String()).DurationVar(&cfg.ReadinessDampening)
+	app.Flag("target-health-url", "The URL of an external health checker queried for the health of the targets, whose unhealthy targets are dropped from the records of several targets (optional)").Default(defaultConfig.TargetHealthURL).StringVar(&cfg.TargetHealthURL)
+	app.Flag("target-health-format", "The format of the health of the targets: a JSON object of the hostnames, each an object of its targets and whether they are healthy, or the Prometheus text format with --target-health-metric labeled with hostname and target, 0 for the unhealthy targets (default: json, options: json, prometheus)").Default(defaultConfig.TargetHealthFormat).EnumVar(&cfg.TargetHealthFormat, "json", "prometheus")
+	app.Flag("target-health-metric", "The metric of the health of the targets in the Prometheus format").Default(defaultConfig.TargetHealthMetric).StringVar(&cfg.TargetHealthMetric)
+	app.Flag("target-health-interval", "The interval between the queries of the health of the targets, also their timeout").Default(defaultConfig.TargetHealthInterval.String()).DurationVar(&cfg.TargetHealthInterval)
 	app.Flag("traefik-disable-legacy", "Disable listeners on Resources under the traefik.containo.us API Group").Default(strconv.FormatBool(defaultConfig.TraefikDisableLegacy)).BoolVar(&cfg.TraefikDisableLegacy)
 	app.Flag("traefik-disable-new", "Disable listeners on Resources under the traefik.io API Group").Default(strconv.FormatBool(defaultConfig.TraefikDisableNew)).BoolVar(&cfg.TraefikDisableNew)
+	app.Flag("quarantine-sources", "Keep the endpoints of the last successful listing of a source that fails, or that lists no endpoints after listing at least --quarantine-min-endpoints, instead of failing the sync or deleting its records (default: disabled)").BoolVar(&cfg.QuarantineSources)
+	app.Flag("quarantine-min-endpoints", "The number of endpoints a source must have listed for an empty listing of it to be quarantined; 0 to never quarantine empty listings").Default(strconv.Itoa(defaultConfig.QuarantineMinEndpoints)).IntVar(&cfg.QuarantineMinEndpoints)
+	app.Flag("quarantine-max-duration", "The duration after which a quarantined source is released, its failed or empty listing being used, e.g. to delete the records of a source that lost its resources on purpose without restarting; 0 to keep it quarantined until it lists endpoints again").Default(defaultConfig.QuarantineMaxDuration.String()).DurationVar(&cfg.QuarantineMaxDuration)
+	app.Flag("source-permissions-check", "Verify at startup that the sources can list and watch the Kubernetes resources they read; warn logs the missing permissions, fail exits (optional, options: warn, fail)").Default(defaultConfig.SourcePermissionsCheck).EnumVar(&cfg.SourcePermissionsCheck, "", "warn", "fail")
 
 	// Flags related to providers
-	providers := []string{"akamai", "alibabacloud", "aws", "aws-sd", "azure", "azure-dns", "azure-private-dns", "bluecat", "civo", "cloudflare", "coredns", "designate", "digitalocean", "dnsimple", "dyn", "exoscale", "gandi", "godaddy", "google", "ibmcloud", "infoblox", "inmemory", "linode", "ns1", "oci", "ovh", "pdns", "pihole", "plural", "rcodezero", "rdns", "rfc2136", "safedns", "scaleway", "skydns", "tencentcloud", "transip", "ultradns", "vinyldns", "vultr", "webhook"}
+	providers := []string{"akamai", "alibabacloud", "aws", "aws-sd", "azure", "azure-dns", "azure-private-dns", "bluecat", "bluecat-bam", "civo", "cloudflare", "coredns", "designate", "digitalocean", "dnsimple", "dnsmasq", "dyn", "exoscale", "gandi", "godaddy", "google", "ibmcloud", "infoblox", "inmemory", "linode", "ns1", "oci", "ovh", "pdns", "pihole", "plural", "rcodezero", "rdns", "rfc2136", "safedns", "scaleway", "skydns", "technitium", "tencentcloud", "transip", "ultradns", "unbound", "vinyldns", "vultr", "webhook"}
 	app.Flag("provider", "The DNS provider where the DNS records will be created (required, options: "+strings.Join(providers, ", ")+")").Required().PlaceHolder("provider").EnumVar(&cfg.Provider, providers...)
-	app.Flag("domain-filter", "Limit possible target zones by a domain suffix; specify multiple times for multiple domains (optional)").Default("").StringsVar(&cfg.DomainFilter)
+	app.Flag("provider-failure-threshold", "Mark the DNS provider unhealthy after this number of consecutive failures, pausing the changes and serving the records of the last successful listing until a probe succeeds (default: 0, disabled)").Default(strconv.Itoa(defaultConfig.ProviderFailureThreshold)).IntVar(&cfg.ProviderFailureThreshold)
+	app.Flag("provider-probe-backoff", "The delay before an unhealthy DNS provider is probed, doubled after every failed probe up to 10m").Default(defaultConfig.ProviderProbeBackoff.String()).DurationVar(&cfg.ProviderProbeBackoff)
+	app.Flag("domain-filter", "Limit possible target zones by a domain suffix; specify multiple times for multiple domains; the changes of every domain are applied and retried separately (optional)").Default("").StringsVar(&cfg.DomainFilter)
 	app.Flag("exclude-domains", "Exclude subdomains (optional)").Default("").StringsVar(&cfg.ExcludeDomains)
+	app.Flag("min-record-depth", "Refuse every change of the records whose DNS names have fewer labels than this, e.g. 4 allows the records of k8s.example.com but never the apex example.com nor its other records, whatever the domain filters (default: 0, disabled)").Default(strconv.Itoa(defaultConfig.MinRecordDepth)).IntVar(&cfg.MinRecordDepth)
+	app.Flag("change-description-template", "The template of the description of every change, written in the comments of the records or changes by the providers supporting them: the comments of the change batches of aws, of the records of cloudflare and of the record sets of pdns, e.g. {{.Action}} {{.DNSName}} for {{.Resource}} by {{.Owner}} (optional)").Default(defaultConfig.ChangeDescriptionTemplate).StringVar(&cfg.ChangeDescriptionTemplate)
 	app.Flag("regex-domain-filter", "Limit possible domains and target zones by a Regex filter; Overrides domain-filter (optional)").Default(defaultConfig.RegexDomainFilter.String()).RegexpVar(&cfg.RegexDomainFilter)
 	app.Flag("regex-domain-exclusion", "Regex filter that excludes domains and target zones matched by regex-domain-filter (optional)").Default(defaultConfig.RegexDomainExclusion.String()).RegexpVar(&cfg.RegexDomainExclusion)
 	app.Flag("zone-name-filter", "Filter target zones by zone domain (For now, only AzureDNS provider is using this flag); specify multiple times for multiple zones (optional)").Default("").StringsVar(&cfg.ZoneNameFilter)
 	app.Flag("zone-id-filter", "Filter target zones by hosted zone id; specify multiple times for multiple zones (optional)").Default("").StringsVar(&cfg.ZoneIDFilter)
 	app.Flag("google-project", "When using the Google provider, current project is auto-detected, when running on GCP. Specify other project with this. Must be specified when running outside GCP.").Default(defaultConfig.GoogleProject).StringVar(&cfg.GoogleProject)
+	app.Flag("google-credentials-file", "When using the Google provider, load the credentials from this file instead of the application default credentials; supports service account keys and workload identity federation configurations (optional)").Default(defaultConfig.GoogleCredentialsFile).StringVar(&cfg.GoogleCredentialsFile)
 	app.Flag("google-batch-change-size", "When using the Google provider, set the maximum number of changes that will be applied in each batch.").Default(strconv.Itoa(defaultConfig.GoogleBatchChangeSize)).IntVar(&cfg.GoogleBatchChangeSize)
 	app.Flag("google-batch-change-interval", "When using the Google provider, set the interval between batch changes.").Default(defaultConfig.GoogleBatchChangeInterval.String()).DurationVar(&cfg.GoogleBatchChangeInterval)
 	app.Flag("google-zone-visibility", "When using the Google provider, filter for zones with this visibility (optional, options: public, private)").Default(defaultConfig.GoogleZoneVisibility).EnumVar(&cfg.GoogleZoneVisibility, "", "public", "private")
 	app.Flag("alibaba-cloud-config-file", "When using the Alibaba Cloud provider, specify the Alibaba Cloud configuration file (required when --provider=alibabacloud)").Default(defaultConfig.AlibabaCloudConfigFile).StringVar(&cfg.AlibabaCloudConfigFile)
 	app.Flag("alibaba-cloud-zone-type", "When using the Alibaba Cloud provider, filter for zones of this type (optional, options: public, private)").Default(defaultConfig.AlibabaCloudZoneType).EnumVar(&cfg.AlibabaCloudZoneType, "", "public", "private")
 	app.Flag("aws-zone-type", "When using the AWS provider, filter for zones of this type (optional, options: public, private)").Default(defaultConfig.AWSZoneType).EnumVar(&cfg.AWSZoneType, "", "public", "private")
-	app.Flag("aws-zone-tags", "When using the AWS provider, filter for zones with these tags").Default("").StringsVar(&cfg.AWSZoneTagFilter)
+	app.Flag("aws-zone-tags", "When using the AWS provider, filter for zones with these tags; every filter must match, a filter matching if one of its tag sets separated by '|' does, e.g. env=prod,team=web|env=staging; '*' and '?' in a value are wildcards and '!' excludes the zones with a tag, e.g. !deprecated").Default("").StringsVar(&cfg.AWSZoneTagFilter)
+	app.Flag("aws-route53-profile-id", "When using the AWS provider, only consider the private hosted zones associated with this Route53 Profile, e.g. the zones of the account owning the profile shared with other accounts, whose credentials are required, see --aws-assume-role; specify multiple times for multiple profiles (optional)").StringsVar(&cfg.AWSRoute53ProfileIDs)
 	app.Flag("aws-assume-role", "When using the AWS API, assume this IAM role. Useful for hosted zones in another AWS account. Specify the full ARN, e.g. `arn:aws:iam::123455567:role/external-dns` (optional)").Default(defaultConfig.AWSAssumeRole).StringVar(&cfg.AWSAssumeRole)
 	app.Flag("aws-assume-role-external-id", "When using the AWS API and assuming a role then specify this external ID` (optional)").Default(defaultConfig.AWSAssumeRoleExternalID).StringVar(&cfg.AWSAssumeRoleExternalID)
 	app.Flag("aws-batch-change-size", "When using the AWS provider, set the maximum number of changes that will be applied in each batch.").Default(strconv.Itoa(defaultConfig.AWSBatchChangeSize)).IntVar(&cfg.AWSBatchChangeSize)
+	app.Flag("aws-batch-change-size-bytes", "When using the AWS provider, set the maximum number of characters of the values of the records changed in each batch, an UPSERT counting twice.").Default(strconv.Itoa(defaultConfig.AWSBatchChangeSizeBytes)).IntVar(&cfg.AWSBatchChangeSizeBytes)
+	app.Flag("aws-batch-change-size-values", "When using the AWS provider, set the maximum number of values of the records changed in each batch, an UPSERT counting twice.").Default(strconv.Itoa(defaultConfig.AWSBatchChangeSizeValues)).IntVar(&cfg.AWSBatchChangeSizeValues)
 	app.Flag("aws-batch-change-interval", "When using the AWS provider, set the interval between batch changes.").Default(defaultConfig.AWSBatchChangeInterval.String()).DurationVar(&cfg.AWSBatchChangeInterval)
+	app.Flag("aws-zone-concurrency", "When using the AWS provider, set the maximum number of hosted zones whose changes are applied concurrently.").Default(strconv.Itoa(defaultConfig.AWSZoneConcurrency)).IntVar(&cfg.AWSZoneConcurrency)
 	app.Flag("aws-evaluate-target-health", "When using the AWS provider, set whether to evaluate the health of a DNS target (default: enabled, disable with --no-aws-evaluate-target-health)").Default(strconv.FormatBool(defaultConfig.AWSEvaluateTargetHealth)).BoolVar(&cfg.AWSEvaluateTargetHealth)
 	app.Flag("aws-api-retries", "When using the AWS API, set the maximum number of retries before giving up.").Default(strconv.Itoa(defaultConfig.AWSAPIRetries)).IntVar(&cfg.AWSAPIRetries)
 	app.Flag("aws-prefer-cname", "When using the AWS provider, prefer using CNAME instead of ALIAS (default: disabled)").BoolVar(&cfg.AWSPreferCNAME)
@@ -493,6 +751,8 @@ func (cfg *Config) ParseFlags(args []string) error {
 	app.Flag("azure-resource-group", "When using the Azure provider, override the Azure resource group to use (required when --provider=azure-private-dns)").Default(defaultConfig.AzureResourceGroup).StringVar(&cfg.AzureResourceGroup)
 	app.Flag("azure-subscription-id", "When using the Azure provider, specify the Azure configuration file (required when --provider=azure-private-dns)").Default(defaultConfig.AzureSubscriptionID).StringVar(&cfg.AzureSubscriptionID)
 	app.Flag("azure-user-assigned-identity-client-id", "When using the Azure provider, override the client id of user assigned identity in config file (optional)").Default("").StringVar(&cfg.AzureUserAssignedIdentityClientID)
+	app.Flag("azure-private-dns-vnet-id", "When using the Azure Private DNS provider, the resource ID of a virtual network every managed zone has to be linked to; specify multiple times for multiple virtual networks (optional)").StringsVar(&cfg.AzurePrivateDNSVNetIDs)
+	app.Flag("azure-private-dns-create-vnet-links", "When using the Azure Private DNS provider, create missing links between managed zones and the virtual networks given with --azure-private-dns-vnet-id instead of only reporting them (default: disabled)").BoolVar(&cfg.AzurePrivateDNSCreateVNetLinks)
 	app.Flag("tencent-cloud-config-file", "When using the Tencent Cloud provider, specify the Tencent Cloud configuration file (required when --provider=tencentcloud)").Default(defaultConfig.TencentCloudConfigFile).StringVar(&cfg.TencentCloudConfigFile)
 	app.Flag("tencent-cloud-zone-type", "When using the Tencent Cloud provider, filter for zones with visibility (optional, options: public, private)").Default(defaultConfig.TencentCloudZoneType).EnumVar(&cfg.TencentCloudZoneType, "", "public", "private")
 
@@ -504,10 +764,13 @@ func (cfg *Config) ParseFlags(args []string) error {
 	app.Flag("bluecat-root-zone", "When using the Bluecat provider, specify the Bluecat root zone (optional when --provider=bluecat)").Default("").StringVar(&cfg.BluecatRootZone)
 	app.Flag("bluecat-skip-tls-verify", "When using the Bluecat provider, specify to skip TLS verification (optional when --provider=bluecat) (default: false)").BoolVar(&cfg.BluecatSkipTLSVerify)
 	app.Flag("bluecat-dns-server-name", "When using the Bluecat provider, specify the Bluecat DNS Server to initiate deploys against. This is only used if --bluecat-dns-deploy-type is not 'no-deploy' (optional when --provider=bluecat)").Default("").StringVar(&cfg.BluecatDNSServerName)
-	app.Flag("bluecat-dns-deploy-type", "When using the Bluecat provider, specify the type of DNS deployment to initiate after records are updated. Valid options are 'full-deploy' and 'no-deploy'. Deploy will only execute if --bluecat-dns-server-name is set (optional when --provider=bluecat)").Default(defaultConfig.BluecatDNSDeployType).StringVar(&cfg.BluecatDNSDeployType)
+	app.Flag("bluecat-dns-deploy-type", "When using the Bluecat provider, specify the type of DNS deployment to initiate after records are updated. Valid options are 'full-deploy' and 'no-deploy', and 'differential-deploy' with --provider=bluecat-bam. Deploy will only execute if --bluecat-dns-server-name is set (optional when --provider=bluecat)").Default(defaultConfig.BluecatDNSDeployType).StringVar(&cfg.BluecatDNSDeployType)
+	app.Flag("bluecat-address-manager-host", "When using the bluecat-bam provider, specify the URL of BlueCat Address Manager, e.g. https://bam.example.com (required when --provider=bluecat-bam)").Default("").StringVar(&cfg.BluecatAddressManagerHost)
+	app.Flag("bluecat-deploy-interval", "When using the bluecat-bam provider, the minimum interval between two deployments of --bluecat-dns-server-name, the changes made in between being deployed together at the end of the interval (default: 0s, deploy after every change)").Default(defaultConfig.BluecatDeployInterval.String()).DurationVar(&cfg.BluecatDeployInterval)
 
 	app.Flag("cloudflare-proxied", "When using the Cloudflare provider, specify if the proxy mode must be enabled (default: disabled)").BoolVar(&cfg.CloudflareProxied)
 	app.Flag("cloudflare-dns-records-per-page", "When using the Cloudflare provider, specify how many DNS records listed per page, max possible 5,000 (default: 100)").Default(strconv.Itoa(defaultConfig.CloudflareDNSRecordsPerPage)).IntVar(&cfg.CloudflareDNSRecordsPerPage)
+	app.Flag("cloudflare-api-token-vault-path", "When using the Cloudflare provider, read the API token from this secret of Vault instead of CF_API_TOKEN, as <path>#<key> (optional)").Default(defaultConfig.CloudflareAPITokenVaultPath).StringVar(&cfg.CloudflareAPITokenVaultPath)
 	app.Flag("coredns-prefix", "When using the CoreDNS provider, specify the prefix name").Default(defaultConfig.CoreDNSPrefix).StringVar(&cfg.CoreDNSPrefix)
 	app.Flag("akamai-serviceconsumerdomain", "When using the Akamai provider, specify the base URL (required when --provider=akamai and edgerc-path not specified)").Default(defaultConfig.AkamaiServiceConsumerDomain).StringVar(&cfg.AkamaiServiceConsumerDomain)
 	app.Flag("akamai-client-token", "When using the Akamai provider, specify the client token (required when --provider=akamai and edgerc-path not specified)").Default(defaultConfig.AkamaiClientToken).StringVar(&cfg.AkamaiClientToken)
@@ -515,6 +778,7 @@ func (cfg *Config) ParseFlags(args []string) error {
 	app.Flag("akamai-access-token", "When using the Akamai provider, specify the access token (required when --provider=akamai and edgerc-path not specified)").Default(defaultConfig.AkamaiAccessToken).StringVar(&cfg.AkamaiAccessToken)
 	app.Flag("akamai-edgerc-path", "When using the Akamai provider, specify the .edgerc file path. Path must be reachable form invocation environment. (required when --provider=akamai and *-token, secret serviceconsumerdomain not specified)").Default(defaultConfig.AkamaiEdgercPath).StringVar(&cfg.AkamaiEdgercPath)
 	app.Flag("akamai-edgerc-section", "When using the Akamai provider, specify the .edgerc file path (Optional when edgerc-path is specified)").Default(defaultConfig.AkamaiEdgercSection).StringVar(&cfg.AkamaiEdgercSection)
+	app.Flag("akamai-use-changelists", "When using the Akamai provider, apply the changes of each zone with a changelist, so they are activated together (default: disabled)").BoolVar(&cfg.AkamaiUseChangelists)
 	app.Flag("infoblox-grid-host", "When using the Infoblox provider, specify the Grid Manager host (required when --provider=infoblox)").Default(defaultConfig.InfobloxGridHost).StringVar(&cfg.InfobloxGridHost)
 	app.Flag("infoblox-wapi-port", "When using the Infoblox provider, specify the WAPI port (default: 443)").Default(strconv.Itoa(defaultConfig.InfobloxWapiPort)).IntVar(&cfg.InfobloxWapiPort)
 	app.Flag("infoblox-wapi-username", "When using the Infoblox provider, specify the WAPI username (default: admin)").Default(defaultConfig.InfobloxWapiUsername).StringVar(&cfg.InfobloxWapiUsername)
@@ -527,6 +791,10 @@ func (cfg *Config) ParseFlags(args []string) error {
 	app.Flag("infoblox-name-regex", "Apply this regular expression as a filter on the name field for obtaining infoblox records. This is disabled by default.").Default(defaultConfig.InfobloxNameRegEx).StringVar(&cfg.InfobloxNameRegEx)
 	app.Flag("infoblox-create-ptr", "When using the Infoblox provider, create a ptr entry in addition to an entry").Default(strconv.FormatBool(defaultConfig.InfobloxCreatePTR)).BoolVar(&cfg.InfobloxCreatePTR)
 	app.Flag("infoblox-cache-duration", "When using the Infoblox provider, set the record TTL (0s to disable).").Default(strconv.Itoa(defaultConfig.InfobloxCacheDuration)).IntVar(&cfg.InfobloxCacheDuration)
+	app.Flag("infoblox-domain-view", "When using the Infoblox provider, manage the zones of a domain in a DNS view instead of --infoblox-view, as domain=view; specify multiple times for multiple domains (optional)").StringsVar(&cfg.InfobloxDomainViews)
+	app.Flag("infoblox-domain-network-view", "When using the Infoblox provider, create the host records of a domain in a network view, and manage its zones in the default DNS view of the network view unless set with --infoblox-domain-view, as domain=network-view; specify multiple times for multiple domains (optional)").StringsVar(&cfg.InfobloxDomainNetworkViews)
+	app.Flag("infoblox-owner-ea", "When using the infoblox-ea registry, the extensible attribute storing the owner of the records (default: external-dns-owner)").Default(defaultConfig.InfobloxOwnerEA).StringVar(&cfg.InfobloxOwnerEA)
+	app.Flag("infoblox-resource-ea", "When using the infoblox-ea registry, the extensible attribute storing the resource of the records (default: external-dns-resource)").Default(defaultConfig.InfobloxResourceEA).StringVar(&cfg.InfobloxResourceEA)
 	app.Flag("dyn-customer-name", "When using the Dyn provider, specify the Customer Name").Default("").StringVar(&cfg.DynCustomerName)
 	app.Flag("dyn-username", "When using the Dyn provider, specify the Username").Default("").StringVar(&cfg.DynUsername)
 	app.Flag("dyn-password", "When using the Dyn provider, specify the password").Default("").StringVar(&cfg.DynPassword)
@@ -536,17 +804,20 @@ func (cfg *Config) ParseFlags(args []string) error {
 	app.Flag("oci-zone-scope", "When using OCI provider, filter for zones with this scope (optional, options: GLOBAL, PRIVATE). Defaults to GLOBAL, setting to empty value will target both.").Default(defaultConfig.OCIZoneScope).EnumVar(&cfg.OCIZoneScope, "", "GLOBAL", "PRIVATE")
 	app.Flag("oci-auth-instance-principal", "When using the OCI provider, specify whether OCI IAM instance principal authentication should be used (instead of key-based auth via the OCI config file).").Default(strconv.FormatBool(defaultConfig.OCIAuthInstancePrincipal)).BoolVar(&cfg.OCIAuthInstancePrincipal)
 	app.Flag("oci-zones-cache-duration", "When using the OCI provider, set the zones list cache TTL (0s to disable).").Default(defaultConfig.OCIZoneCacheDuration.String()).DurationVar(&cfg.OCIZoneCacheDuration)
+	app.Flag("oci-private-view-id", "When using the OCI provider, restrict private zones to the ones in this view; specify multiple times for multiple views (optional)").StringsVar(&cfg.OCIPrivateViewIDs)
 	app.Flag("rcodezero-txt-encrypt", "When using the Rcodezero provider with txt registry option, set if TXT rrs are encrypted (default: false)").Default(strconv.FormatBool(defaultConfig.RcodezeroTXTEncrypt)).BoolVar(&cfg.RcodezeroTXTEncrypt)
 	app.Flag("inmemory-zone", "Provide a list of pre-configured zones for the inmemory provider; specify multiple times for multiple zones (optional)").Default("").StringsVar(&cfg.InMemoryZones)
 	app.Flag("ovh-endpoint", "When using the OVH provider, specify the endpoint (default: ovh-eu)").Default(defaultConfig.OVHEndpoint).StringVar(&cfg.OVHEndpoint)
 	app.Flag("ovh-api-rate-limit", "When using the OVH provider, specify the API request rate limit, X operations by seconds (default: 20)").Default(strconv.Itoa(defaultConfig.OVHApiRateLimit)).IntVar(&cfg.OVHApiRateLimit)
 	app.Flag("pdns-server", "When using the PowerDNS/PDNS provider, specify the URL to the pdns server (required when --provider=pdns)").Default(defaultConfig.PDNSServer).StringVar(&cfg.PDNSServer)
 	app.Flag("pdns-api-key", "When using the PowerDNS/PDNS provider, specify the API key to use to authorize requests (required when --provider=pdns)").Default(defaultConfig.PDNSAPIKey).StringVar(&cfg.PDNSAPIKey)
+	app.Flag("pdns-api-key-vault-path", "When using the PowerDNS/PDNS provider, read the API key from this secret of Vault instead of --pdns-api-key, as <path>#<key> (optional)").Default(defaultConfig.PDNSAPIKeyVaultPath).StringVar(&cfg.PDNSAPIKeyVaultPath)
 	app.Flag("pdns-skip-tls-verify", "When using the PowerDNS/PDNS provider, disable verification of any TLS certificates (optional when --provider=pdns) (default: false)").Default(strconv.FormatBool(defaultConfig.PDNSSkipTLSVerify)).BoolVar(&cfg.PDNSSkipTLSVerify)
 	app.Flag("ns1-endpoint", "When using the NS1 provider, specify the URL of the API endpoint to target (default: https://api.nsone.net/v1/)").Default(defaultConfig.NS1Endpoint).StringVar(&cfg.NS1Endpoint)
 	app.Flag("ns1-ignoressl", "When using the NS1 provider, specify whether to verify the SSL certificate (default: false)").Default(strconv.FormatBool(defaultConfig.NS1IgnoreSSL)).BoolVar(&cfg.NS1IgnoreSSL)
 	app.Flag("ns1-min-ttl", "Minimal TTL (in seconds) for records. This value will be used if the provided TTL for a service/ingress is lower than this.").IntVar(&cfg.NS1MinTTLSeconds)
 	app.Flag("digitalocean-api-page-size", "Configure the page size used when querying the DigitalOcean API.").Default(strconv.Itoa(defaultConfig.DigitalOceanAPIPageSize)).IntVar(&cfg.DigitalOceanAPIPageSize)
+	app.Flag("dnsimple-zones-cache-duration", "When using the DNSimple provider, set the zones list cache TTL (0s to disable).").Default(defaultConfig.DNSimpleZoneCacheDuration.String()).DurationVar(&cfg.DNSimpleZoneCacheDuration)
 	app.Flag("ibmcloud-config-file", "When using the IBM Cloud provider, specify the IBM Cloud configuration file (required when --provider=ibmcloud").Default(defaultConfig.IBMCloudConfigFile).StringVar(&cfg.IBMCloudConfigFile)
 	app.Flag("ibmcloud-proxied", "When using the IBM provider, specify if the proxy mode must be enabled (default: disabled)").BoolVar(&cfg.IBMCloudProxied)
 	// GoDaddy flags
@@ -573,6 +844,8 @@ func (cfg *Config) ParseFlags(args []string) error {
 	app.Flag("rfc2136-insecure", "When using the RFC2136 provider, specify whether to attach TSIG or not (default: false, requires --rfc2136-tsig-keyname and rfc2136-tsig-secret)").Default(strconv.FormatBool(defaultConfig.RFC2136Insecure)).BoolVar(&cfg.RFC2136Insecure)
 	app.Flag("rfc2136-tsig-keyname", "When using the RFC2136 provider, specify the TSIG key to attached to DNS messages (required when --rfc2136-insecure=false)").Default(defaultConfig.RFC2136TSIGKeyName).StringVar(&cfg.RFC2136TSIGKeyName)
 	app.Flag("rfc2136-tsig-secret", "When using the RFC2136 provider, specify the TSIG (base64) value to attached to DNS messages (required when --rfc2136-insecure=false)").Default(defaultConfig.RFC2136TSIGSecret).StringVar(&cfg.RFC2136TSIGSecret)
+	app.Flag("rfc2136-tsig-secret-file", "When using the RFC2136 provider, read the TSIG (base64) secret from this file instead of --rfc2136-tsig-secret, reloaded every --credentials-reload-interval (optional)").Default(defaultConfig.RFC2136TSIGSecretFile).StringVar(&cfg.RFC2136TSIGSecretFile)
+	app.Flag("rfc2136-tsig-secret-vault-path", "When using the RFC2136 provider, read the TSIG (base64) secret from this secret of Vault instead of --rfc2136-tsig-secret, as <path>#<key> (optional)").Default(defaultConfig.RFC2136TSIGSecretVaultPath).StringVar(&cfg.RFC2136TSIGSecretVaultPath)
 	app.Flag("rfc2136-tsig-secret-alg", "When using the RFC2136 provider, specify the TSIG (base64) value to attached to DNS messages (required when --rfc2136-insecure=false)").Default(defaultConfig.RFC2136TSIGSecretAlg).StringVar(&cfg.RFC2136TSIGSecretAlg)
 	app.Flag("rfc2136-tsig-axfr", "When using the RFC2136 provider, specify the TSIG (base64) value to attached to DNS messages (required when --rfc2136-insecure=false)").BoolVar(&cfg.RFC2136TAXFR)
 	app.Flag("rfc2136-min-ttl", "When using the RFC2136 provider, specify minimal TTL (in duration format) for records. This value will be used if the provided TTL for a service/ingress is lower than this").Default(defaultConfig.RFC2136MinTTL.String()).DurationVar(&cfg.RFC2136MinTTL)
@@ -581,6 +854,17 @@ func (cfg *Config) ParseFlags(args []string) error {
 	app.Flag("rfc2136-kerberos-password", "When using the RFC2136 provider with GSS-TSIG, specify the password of the user with permissions to update DNS records (required when --rfc2136-gss-tsig=true)").Default(defaultConfig.RFC2136KerberosPassword).StringVar(&cfg.RFC2136KerberosPassword)
 	app.Flag("rfc2136-kerberos-realm", "When using the RFC2136 provider with GSS-TSIG, specify the realm of the user with permissions to update DNS records (required when --rfc2136-gss-tsig=true)").Default(defaultConfig.RFC2136KerberosRealm).StringVar(&cfg.RFC2136KerberosRealm)
 	app.Flag("rfc2136-batch-change-size", "When using the RFC2136 provider, set the maximum number of changes that will be applied in each batch.").Default(strconv.Itoa(defaultConfig.RFC2136BatchChangeSize)).IntVar(&cfg.RFC2136BatchChangeSize)
+	app.Flag("rfc2136-zone-concurrency", "When using the RFC2136 provider, set the maximum number of zones whose changes are applied concurrently.").Default(strconv.Itoa(defaultConfig.RFC2136ZoneConcurrency)).IntVar(&cfg.RFC2136ZoneConcurrency)
+	app.Flag("rfc2136-clock-skew", "When using the RFC2136 provider with TSIG or GSS-TSIG, the maximum difference between the clocks of ExternalDNS and of the DNS server for the signatures of the messages to be accepted, at most 18h12m15s").Default(defaultConfig.RFC2136ClockSkew.String()).DurationVar(&cfg.RFC2136ClockSkew)
+	app.Flag("rfc2136-update-check", "When using the RFC2136 provider, check at startup that every zone accepts updates, by deleting the records of a name which doesn't exist, and skip the changes of the zones refusing them; fails when every zone refuses them (default: disabled)").BoolVar(&cfg.RFC2136UpdateCheck)
+	app.Flag("rfc2136-local-address", "When using the RFC2136 provider, the local IP address the connections to the DNS server are made from, e.g. the address allowed by the allow-update ACL of the server when the pod has several interfaces (optional)").Default(defaultConfig.RFC2136LocalAddress).StringVar(&cfg.RFC2136LocalAddress)
+	app.Flag("rfc2136-discover-primary", "When using the RFC2136 provider, send the updates of every zone to its primary master, named by the MNAME of the SOA record of the zone answered by --rfc2136-host, then to --rfc2136-host if the primary master is unreachable (default: disabled)").BoolVar(&cfg.RFC2136DiscoverPrimary)
+	app.Flag("rfc2136-fallback-host", "When using the RFC2136 provider, send the updates to this host, as host or host:port, when the primary master and --rfc2136-host are unreachable (optional, can be repeated)").StringsVar(&cfg.RFC2136FallbackHosts)
+	app.Flag("rfc2136-ad-site", "When using the RFC2136 provider, send the updates to the domain controllers of this Active Directory site, discovered from their SRV records, then to the other domain controllers of the domain, before --rfc2136-host (optional)").Default(defaultConfig.RFC2136ADSite).StringVar(&cfg.RFC2136ADSite)
+	app.Flag("rfc2136-ad-domain", "When using the RFC2136 provider with --rfc2136-ad-site, the Active Directory domain the domain controllers are discovered in (default: the lowercased --rfc2136-kerberos-realm)").Default(defaultConfig.RFC2136ADDomain).StringVar(&cfg.RFC2136ADDomain)
+	app.Flag("rfc2136-retries", "When using the RFC2136 provider, the maximum number of times an update is sent again after a timeout, a network error, or a REFUSED or SERVFAIL answer, 0 to disable the retries").Default(strconv.Itoa(defaultConfig.RFC2136Retries)).IntVar(&cfg.RFC2136Retries)
+	app.Flag("rfc2136-retry-backoff", "When using the RFC2136 provider, the delay before an update is sent again, doubled after every retry up to 30s").Default(defaultConfig.RFC2136RetryBackoff.String()).DurationVar(&cfg.RFC2136RetryBackoff)
+	app.Flag("rfc2136-retry-budget", "When using the RFC2136 provider, the maximum number of retries of the updates of a synchronization, so an unreachable server doesn't delay it by the retries of every update").Default(strconv.Itoa(defaultConfig.RFC2136RetryBudget)).IntVar(&cfg.RFC2136RetryBudget)
 
 	// Flags related to TransIP provider
 	app.Flag("transip-account", "When using the TransIP provider, specify the account name (required when --provider=transip)").Default(defaultConfig.TransIPAccountName).StringVar(&cfg.TransIPAccountName)
@@ -590,6 +874,25 @@ func (cfg *Config) ParseFlags(args []string) error {
 	app.Flag("pihole-server", "When using the Pihole provider, the base URL of the Pihole web server (required when --provider=pihole)").Default(defaultConfig.PiholeServer).StringVar(&cfg.PiholeServer)
 	app.Flag("pihole-password", "When using the Pihole provider, the password to the server if it is protected").Default(defaultConfig.PiholePassword).StringVar(&cfg.PiholePassword)
 	app.Flag("pihole-tls-skip-verify", "When using the Pihole provider, disable verification of any TLS certificates").BoolVar(&cfg.PiholeTLSInsecureSkipVerify)
+	app.Flag("pihole-api-version", "When using the Pihole provider, the version of the Pihole API; 5 uses the scripts of the web interface, 6 the REST API of Pihole v6 (default: 5, options: 5, 6)").Default(defaultConfig.PiholeAPIVersion).EnumVar(&cfg.PiholeAPIVersion, "5", "6")
+
+	// Flags related to dnsmasq provider
+	app.Flag("dnsmasq-hosts-file", "When using the dnsmasq provider, the file the records are written to in hosts format, to be read by dnsmasq with --addn-hosts or --hostsdir (required when --provider=dnsmasq)").Default(defaultConfig.DnsmasqHostsFile).StringVar(&cfg.DnsmasqHostsFile)
+	app.Flag("dnsmasq-conf-file", "When using the dnsmasq provider, the file the CNAME records are written to as cname options, to be read by dnsmasq with --conf-file or --conf-dir; dnsmasq only reads it at startup (optional, CNAME records are skipped without it)").Default(defaultConfig.DnsmasqConfFile).StringVar(&cfg.DnsmasqConfFile)
+	app.Flag("dnsmasq-pid-file", "When using the dnsmasq provider, the pid file of dnsmasq; the process is sent SIGHUP to reload the hosts file after changes (optional)").Default(defaultConfig.DnsmasqPidFile).StringVar(&cfg.DnsmasqPidFile)
+
+	// Flags related to unbound provider
+	app.Flag("unbound-control-address", "When using the unbound provider, the address of the remote control of unbound, a host:port or the path of a unix socket (default: 127.0.0.1:8953)").Default(defaultConfig.UnboundControlAddress).StringVar(&cfg.UnboundControlAddress)
+	app.Flag("unbound-server-cert-file", "When using the unbound provider, the certificate of the unbound server created by unbound-control-setup; the remote control is used with TLS when set (optional)").Default(defaultConfig.UnboundServerCertFile).StringVar(&cfg.UnboundServerCertFile)
+	app.Flag("unbound-control-cert-file", "When using the unbound provider, the certificate of the unbound control client created by unbound-control-setup (required with --unbound-server-cert-file)").Default(defaultConfig.UnboundControlCertFile).StringVar(&cfg.UnboundControlCertFile)
+	app.Flag("unbound-control-key-file", "When using the unbound provider, the key of the unbound control client created by unbound-control-setup (required with --unbound-server-cert-file)").Default(defaultConfig.UnboundControlKeyFile).StringVar(&cfg.UnboundControlKeyFile)
+	app.Flag("unbound-local-zone", "When using the unbound provider, a local zone the records are managed in, created when missing; specify multiple times for multiple zones (required when --provider=unbound)").StringsVar(&cfg.UnboundLocalZones)
+	app.Flag("unbound-local-zone-type", "When using the unbound provider, the type of the local zones created by ExternalDNS (default: transparent)").Default(defaultConfig.UnboundLocalZoneType).EnumVar(&cfg.UnboundLocalZoneType, "transparent", "typetransparent", "static", "redirect", "nodefault")
+
+	// Flags related to Technitium provider
+	app.Flag("technitium-server", "When using the Technitium provider, the base URL of the web console of Technitium DNS Server, e.g. http://dns.example.org:5380 (required when --provider=technitium)").Default(defaultConfig.TechnitiumServer).StringVar(&cfg.TechnitiumServer)
+	app.Flag("technitium-token", "When using the Technitium provider, the API token created in the web console (required when --provider=technitium)").Default(defaultConfig.TechnitiumToken).StringVar(&cfg.TechnitiumToken)
+	app.Flag("technitium-tls-skip-verify", "When using the Technitium provider, disable verification of any TLS certificates").BoolVar(&cfg.TechnitiumSkipTLSVerify)
 
 	// Flags related to the Plural provider
 	app.Flag("plural-cluster", "When using the plural provider, specify the cluster name you're running with").Default(defaultConfig.PluralCluster).StringVar(&cfg.PluralCluster)
@@ -599,35 +902,91 @@ func (cfg *Config) ParseFlags(args []string) error {
 	app.Flag("policy", "Modify how DNS records are synchronized between sources and providers (default: sync, options: sync, upsert-only, create-only)").Default(defaultConfig.Policy).EnumVar(&cfg.Policy, "sync", "upsert-only", "create-only")
 
 	// Flags related to the registry
-	app.Flag("registry", "The registry implementation to use to keep track of DNS record ownership (default: txt, options: txt, noop, dynamodb, aws-sd)").Default(defaultConfig.Registry).EnumVar(&cfg.Registry, "txt", "noop", "dynamodb", "aws-sd")
+	app.Flag("registry", "The registry implementation to use to keep track of DNS record ownership (default: txt, options: txt, noop, dynamodb, aws-sd, infoblox-ea)").Default(defaultConfig.Registry).EnumVar(&cfg.Registry, "txt", "noop", "dynamodb", "aws-sd", "infoblox-ea")
 	app.Flag("txt-owner-id", "When using the TXT or DynamoDB registry, a name that identifies this instance of ExternalDNS (default: default)").Default(defaultConfig.TXTOwnerID).StringVar(&cfg.TXTOwnerID)
 	app.Flag("txt-prefix", "When using the TXT registry, a custom string that's prefixed to each ownership DNS record (optional). Could contain record type template like '%{record_type}-prefix-'. Mutual exclusive with txt-suffix!").Default(defaultConfig.TXTPrefix).StringVar(&cfg.TXTPrefix)
 	app.Flag("txt-suffix", "When using the TXT registry, a custom string that's suffixed to the host portion of each ownership DNS record (optional). Could contain record type template like '-%{record_type}-suffix'. Mutual exclusive with txt-prefix!").Default(defaultConfig.TXTSuffix).StringVar(&cfg.TXTSuffix)
 	app.Flag("txt-wildcard-replacement", "When using the TXT registry, a custom string that's used instead of an asterisk for TXT records corresponding to wildcard DNS records (optional)").Default(defaultConfig.TXTWildcardReplacement).StringVar(&cfg.TXTWildcardReplacement)
 	app.Flag("txt-encrypt-enabled", "When using the TXT registry, set if TXT records should be encrypted before stored (default: disabled)").BoolVar(&cfg.TXTEncryptEnabled)
 	app.Flag("txt-encrypt-aes-key", "When using the TXT registry, set TXT record decryption and encryption 32 byte aes key (required when --txt-encrypt=true)").Default(defaultConfig.TXTEncryptAESKey).StringVar(&cfg.TXTEncryptAESKey)
+	app.Flag("txt-gc-interval", "When using the TXT registry, the interval between the removals of the TXT records of this owner whose records don't exist and the creations of the TXT records missing for its records (default: disabled)").Default(defaultConfig.TXTGCInterval.String()).DurationVar(&cfg.TXTGCInterval)
+	app.Flag("txt-gc-dry-run", "When enabled, the garbage collection only logs the orphaned TXT records instead of removing them (default: disabled)").BoolVar(&cfg.TXTGCDryRun)
+	app.Flag("registry-snapshot-save", "When set, saves the records owned by this instance with their labels to this file and exits, to restore their ownership later (optional)").Default(defaultConfig.RegistrySnapshotSave).StringVar(&cfg.RegistrySnapshotSave)
+	app.Flag("registry-snapshot-restore", "When using the TXT registry, restores the ownership of the records without an owner saved in this file and exits (optional)").Default(defaultConfig.RegistrySnapshotRestore).StringVar(&cfg.RegistrySnapshotRestore)
+	app.Flag("records-snapshot-save", "When set, saves the records of the provider, including the ownership records, to this file and exits, to dry-run against them later with --records-snapshot (optional)").Default(defaultConfig.RecordsSnapshotSave).StringVar(&cfg.RecordsSnapshotSave)
+	app.Flag("records-snapshot", "When set with --dry-run, plans the changes against the records saved in this file by --records-snapshot-save instead of calling the provider, which isn't set up (optional)").Default(defaultConfig.RecordsSnapshot).StringVar(&cfg.RecordsSnapshot)
 	app.Flag("dynamodb-region", "When using the DynamoDB registry, the AWS region of the DynamoDB table (optional)").Default(cfg.AWSDynamoDBRegion).StringVar(&cfg.AWSDynamoDBRegion)
 	app.Flag("dynamodb-table", "When using the DynamoDB registry, the name of the DynamoDB table (default: \"external-dns\")").Default(defaultConfig.AWSDynamoDBTable).StringVar(&cfg.AWSDynamoDBTable)
+	app.Flag("dynamodb-record-history", "When using the DynamoDB registry, keep this number of versions of the targets and TTL of every record in its item, served as JSON by /debug/record-history?name=<dns name> on the metrics address (default: disabled)").Default(strconv.Itoa(defaultConfig.AWSDynamoDBRecordHistory)).IntVar(&cfg.AWSDynamoDBRecordHistory)
+	app.Flag("dynamodb-history-retention", "When using the DynamoDB registry with --dynamodb-record-history, keep the history of a deleted record in a tombstone item for this long (default: 168h)").Default(defaultConfig.AWSDynamoDBHistoryRetention.String()).DurationVar(&cfg.AWSDynamoDBHistoryRetention)
 
 	// Flags related to the main control loop
 	app.Flag("txt-cache-interval", "The interval between cache synchronizations in duration format (default: disabled)").Default(defaultConfig.TXTCacheInterval.String()).DurationVar(&cfg.TXTCacheInterval)
 	app.Flag("interval", "The interval between two consecutive synchronizations in duration format (default: 1m)").Default(defaultConfig.Interval.String()).DurationVar(&cfg.Interval)
 	app.Flag("min-event-sync-interval", "The minimum interval between two consecutive synchronizations triggered from kubernetes events in duration format (default: 5s)").Default(defaultConfig.MinEventSyncInterval.String()).DurationVar(&cfg.MinEventSyncInterval)
+	app.Flag("full-sync-interval", "When set, the interval between two consecutive synchronizations listing all records of the DNS provider, the synchronizations in between use the records applied last (default: disabled)").Default(defaultConfig.FullSyncInterval.String()).DurationVar(&cfg.FullSyncInterval)
+	app.Flag("dampening-window", "The duration the changed targets of a record must stay the same before they are applied, overridden by the dampening-window annotation (default: disabled)").Default(defaultConfig.DampeningWindow.String()).DurationVar(&cfg.DampeningWindow)
+	app.Flag("target-change-ttl", "The TTL the records are lowered to before their targets change, restored once the records resolve to their new targets; requires the txt registry (default: disabled)").DurationVar(&cfg.TargetChangeTTL)
+	app.Flag("target-change-soak", "How long the lowered TTL of --target-change-ttl is kept before and after the targets of a record change, before them at least the TTL of the record (default: 0s)").DurationVar(&cfg.TargetChangeSoak)
+	app.Flag("provider-batch-size", "The maximum number of changes of a zone applied at once, the changes of a DNS name are kept in the same batch; the providers may split the batches further (default: 0, all the changes at once)").Default(strconv.Itoa(defaultConfig.ProviderBatchSize)).IntVar(&cfg.ProviderBatchSize)
+	app.Flag("provider-batch-interval", "The delay between the batches of changes of --provider-batch-size (default: 0s)").Default(defaultConfig.ProviderBatchInterval.String()).DurationVar(&cfg.ProviderBatchInterval)
+	app.Flag("records-pagination", "When enabled, reads the records of the DNS provider a page at a time and applies the changes of every page once it is read, for providers listing their records in pages; not supported with --snapshot-path, --full-sync-interval, --dampening-window, --canary-zone, --history-dir, --explain-endpoint and --published-hostnames-annotation (default: disabled)").BoolVar(&cfg.RecordsPagination)
+	app.Flag("maintenance-window", "A window during which the changes are deferred until its end, the records are still read; specify a cron expression of its start followed by its duration, e.g. '0 18 * * 5 62h' (optional, can be repeated)").StringsVar(&cfg.MaintenanceWindows)
+	app.Flag("change-window", "Only apply the changes of the records of a domain and its subdomains between two times of the day in UTC, e.g. 'prod.example.com=02:00-04:00'; the changes of the records of other domains are applied right away (optional, can be repeated)").StringsVar(&cfg.ChangeWindows)
+	app.Flag("canary-zone", "Apply the changes of the records of a zone to a canary zone first, renamed into it, and to the zone only once they resolve in the canary zone, e.g. 'example.com=canary.example.net' (optional, can be repeated)").StringsVar(&cfg.CanaryZones)
+	app.Flag("canary-nameserver", "The nameserver, as host:port, queried for the records of the canary zones, preferably their authoritative nameserver (default: the first nameserver of /etc/resolv.conf)").Default(defaultConfig.CanaryNameserver).StringVar(&cfg.CanaryNameserver)
+	app.Flag("canary-timeout", "How long the changes applied to the canary zones may take to resolve before the changes of their zones are retried (default: 2m)").Default(defaultConfig.CanaryTimeout.String()).DurationVar(&cfg.CanaryTimeout)
 	app.Flag("once", "When enabled, exits the synchronization loop after the first iteration (default: disabled)").BoolVar(&cfg.Once)
 	app.Flag("dry-run", "When enabled, prints DNS record changes rather than actually performing them (default: disabled)").BoolVar(&cfg.DryRun)
+	app.Flag("read-only", "When enabled, never sends any change to the DNS provider, the changes of the ownership records included, for observing the records with credentials allowing to change them; not supported with the dynamodb registry, --rfc2136-update-check or --azure-private-dns-create-vnet-links (default: disabled)").BoolVar(&cfg.ReadOnly)
 	app.Flag("events", "When enabled, in addition to running every interval, the reconciliation loop will get triggered when supported sources change (default: disabled)").BoolVar(&cfg.UpdateEvents)
+	app.Flag("warning-events", "When enabled, posts a warning Event, once per hour at most, on the resources with invalid hostnames, with hostnames conflicting with other resources or outside of the domain filter (default: disabled)").BoolVar(&cfg.WarningEvents)
+	app.Flag("published-hostnames-annotation", "When enabled, writes the hostnames of the records of the services, ingresses and DNSEndpoints to their published-hostnames annotation, e.g. external-dns.alpha.kubernetes.io/published-hostnames, once the records are applied (default: disabled)").BoolVar(&cfg.PublishedHostnamesAnnotation)
+	app.Flag("node-deletion-events", "When enabled, the reconciliation loop is also triggered when nodes are deleted, or tainted for deletion by the cluster-autoscaler or Karpenter, whose targets are no longer published by the node source and the NodePort services (default: disabled)").BoolVar(&cfg.NodeDeletionEvents)
 
 	// Miscellaneous flags
+	app.Flag("credentials-reload-interval", "The interval between the reads of the credentials of the providers from files or Vault, e.g. --rfc2136-tsig-secret-file (default: 1m)").Default(defaultConfig.CredentialsReloadInterval.String()).DurationVar(&cfg.CredentialsReloadInterval)
+	app.Flag("vault-address", "The address of the Vault server the credentials of the providers are read from, e.g. with --rfc2136-tsig-secret-vault-path (required when a Vault path is set)").Default(defaultConfig.VaultAddress).StringVar(&cfg.VaultAddress)
+	app.Flag("vault-namespace", "The Vault Enterprise namespace the credentials are read from (default: the namespace of VAULT_NAMESPACE, or the root namespace)").Default(defaultConfig.VaultNamespace).StringVar(&cfg.VaultNamespace)
+	app.Flag("vault-ca-cert", "The file containing the PEM bundle of the CAs verifying the certificate of the Vault server (default: the file of VAULT_CACERT, or the CAs of the system)").Default(defaultConfig.VaultCACert).StringVar(&cfg.VaultCACert)
+	app.Flag("vault-auth-method", "The auth method used to log in to Vault (default: kubernetes, options: kubernetes, approle)").Default(defaultConfig.VaultAuthMethod).EnumVar(&cfg.VaultAuthMethod, "kubernetes", "approle")
+	app.Flag("vault-auth-mount-path", "The path the auth method is mounted at in Vault (default: the name of the auth method)").Default(defaultConfig.VaultAuthMountPath).StringVar(&cfg.VaultAuthMountPath)
+	app.Flag("vault-approle-role-id", "When using the AppRole auth method of Vault, the role ID (required when --vault-auth-method=approle)").Default(defaultConfig.VaultAppRoleRoleID).StringVar(&cfg.VaultAppRoleRoleID)
+	app.Flag("vault-approle-secret-id-file", "When using the AppRole auth method of Vault, the file containing the secret ID (required when --vault-auth-method=approle)").Default(defaultConfig.VaultAppRoleSecretIDFile).StringVar(&cfg.VaultAppRoleSecretIDFile)
+	app.Flag("vault-kubernetes-role", "When using the Kubernetes auth method of Vault, the role to log in with (required when --vault-auth-method=kubernetes)").Default(defaultConfig.VaultKubernetesRole).StringVar(&cfg.VaultKubernetesRole)
+	app.Flag("vault-kubernetes-token-file", "When using the Kubernetes auth method of Vault, the file containing the service account token (default: /var/run/secrets/kubernetes.io/serviceaccount/token)").Default(defaultConfig.VaultKubernetesTokenFile).StringVar(&cfg.VaultKubernetesTokenFile)
 	app.Flag("log-format", "The format in which log messages are printed (default: text, options: text, json)").Default(defaultConfig.LogFormat).EnumVar(&cfg.LogFormat, "text", "json")
 	app.Flag("metrics-address", "Specify where to serve the metrics and health check endpoint (default: :7979)").Default(defaultConfig.MetricsAddress).StringVar(&cfg.MetricsAddress)
+	app.Flag("debug-dns-address", "When set, serves the desired state of the records over DNS on this address for debugging, e.g. :5353 (default: disabled)").Default(defaultConfig.DebugDNSAddress).StringVar(&cfg.DebugDNSAddress)
+	app.Flag("snapshot-path", "When set, saves the applied records to this file, and on startup reconciles only the drift against them before a full reconciliation (default: disabled)").Default(defaultConfig.SnapshotPath).StringVar(&cfg.SnapshotPath)
+	app.Flag("history-dir", "When set, records the inverse of the changes applied by every sync in this directory, so they can be rolled back (default: disabled)").Default(defaultConfig.HistoryDir).StringVar(&cfg.HistoryDir)
+	app.Flag("history-limit", "The number of syncs kept in the history (default: 20)").Default(strconv.Itoa(defaultConfig.HistoryLimit)).IntVar(&cfg.HistoryLimit)
+	app.Flag("rollback-endpoint", "When enabled, serves POST /rollback?to=<sync-id> on the metrics address, rolling back that sync and every later sync recorded in --history-dir (default: disabled)").BoolVar(&cfg.RollbackEndpoint)
+	app.Flag("rollback-endpoint-token-file", "A file with the bearer token the requests to the rollback endpoint authenticate with; required with --rollback-endpoint").Default(defaultConfig.RollbackEndpointTokenFile).StringVar(&cfg.RollbackEndpointTokenFile)
+	app.Flag("explain-endpoint", "When enabled, serves GET /debug/explain?name=<dns name> on the metrics address, explaining where the records of the DNS name come from, as printed by `external-dns explain <dns name>` (default: disabled)").BoolVar(&cfg.ExplainEndpoint)
+	app.Flag("churn-window", "When set, counts the changes applied to every record over this sliding window, exported per zone as metrics and served by GET /debug/churn?zone=<zone> on the metrics address (default: disabled)").Default(defaultConfig.ChurnWindow.String()).DurationVar(&cfg.ChurnWindow)
+	app.Flag("churn-threshold", "The number of changes of a record within --churn-window above which it is flagged as churning (default: 10)").Default(strconv.Itoa(defaultConfig.ChurnThreshold)).IntVar(&cfg.ChurnThreshold)
 	app.Flag("log-level", "Set the level of logging. (default: info, options: panic, debug, info, warning, error, fatal)").Default(defaultConfig.LogLevel).EnumVar(&cfg.LogLevel, allLogLevelsAsStrings()...)
 
 	// Webhook provider
 	app.Flag("webhook-provider-url", "[EXPERIMENTAL] The URL of the remote endpoint to call for the webhook provider (default: http://localhost:8888)").Default(defaultConfig.WebhookProviderURL).StringVar(&cfg.WebhookProviderURL)
 	app.Flag("webhook-provider-read-timeout", "[EXPERIMENTAL] The read timeout for the webhook provider in duration format (default: 5s)").Default(defaultConfig.WebhookProviderReadTimeout.String()).DurationVar(&cfg.WebhookProviderReadTimeout)
 	app.Flag("webhook-provider-write-timeout", "[EXPERIMENTAL] The write timeout for the webhook provider in duration format (default: 10s)").Default(defaultConfig.WebhookProviderWriteTimeout.String()).DurationVar(&cfg.WebhookProviderWriteTimeout)
-
-	app.Flag("webhook-server", "[EXPERIMENTAL] When enabled, runs as a webhook server instead of a controller. (default: false).").BoolVar(&cfg.WebhookServer)
+	app.Flag("webhook-provider-token-file", "[EXPERIMENTAL] A file with the bearer token authenticating the webhook provider to the webhook server, e.g. to a central DNS gateway; use with --registry=noop as the gateway manages the ownership of the records (optional)").Default(defaultConfig.WebhookProviderTokenFile).StringVar(&cfg.WebhookProviderTokenFile)
+
+	app.Flag("webhook-server", "[EXPERIMENTAL] When enabled, runs as a webhook server instead of a controller, serving the provider to other controllers. (default: false).").BoolVar(&cfg.WebhookServer)
+	app.Flag("webhook-server-address", "[EXPERIMENTAL] The address the webhook server listens on; anyone who can reach it can change the records of the provider (default: 127.0.0.1:8888)").Default(defaultConfig.WebhookServerAddress).StringVar(&cfg.WebhookServerAddress)
+	app.Flag("webhook-server-clients-file", "[EXPERIMENTAL] Runs the webhook server as a central DNS gateway for the clients in the file, one <token>,<cluster ID> line per client; the records of each client are owned by its cluster ID with the TXT registry (optional)").Default(defaultConfig.WebhookServerClientsFile).StringVar(&cfg.WebhookServerClientsFile)
+	app.Flag("webhook-server-tls-cert-file", "[EXPERIMENTAL] The certificate file the central DNS gateway serves HTTPS with, so that the tokens of its clients aren't sent in clear; requires --webhook-server-clients-file and --webhook-server-tls-key-file (optional)").Default(defaultConfig.WebhookServerTLSCertFile).StringVar(&cfg.WebhookServerTLSCertFile)
+	app.Flag("webhook-server-tls-key-file", "[EXPERIMENTAL] The key file of --webhook-server-tls-cert-file (optional)").Default(defaultConfig.WebhookServerTLSKeyFile).StringVar(&cfg.WebhookServerTLSKeyFile)
+
+	// ACME DNS-01 challenge server
+	app.Flag("acme-server", "[EXPERIMENTAL] When enabled, serves an HTTP API next to the controller that fulfils ACME DNS-01 challenges through the configured provider (default: false)").BoolVar(&cfg.ACMEServer)
+	app.Flag("acme-server-address", "[EXPERIMENTAL] The address the ACME DNS-01 challenge server listens on (default: 127.0.0.1:8889)").Default(defaultConfig.ACMEServerAddress).StringVar(&cfg.ACMEServerAddress)
+	app.Flag("acme-server-token-file", "[EXPERIMENTAL] A file with the token the clients of the ACME DNS-01 challenge server authenticate with, as a bearer token or as the basic authentication password; required with --acme-server").Default(defaultConfig.ACMEServerTokenFile).StringVar(&cfg.ACMEServerTokenFile)
+	app.Flag("acme-challenge-ttl", "[EXPERIMENTAL] The TTL (in seconds) of ACME DNS-01 challenge records (default: 60)").Default(strconv.FormatInt(defaultConfig.ACMEChallengeTTL, 10)).Int64Var(&cfg.ACMEChallengeTTL)
+	app.Flag("acme-propagation-timeout", "[EXPERIMENTAL] The maximum time to wait for a challenge record to be reported by the provider in duration format, 0s disables the check (default: 2m)").Default(defaultConfig.ACMEPropagationTimeout.String()).DurationVar(&cfg.ACMEPropagationTimeout)
+	app.Flag("acme-propagation-interval", "[EXPERIMENTAL] The interval between two propagation checks of a challenge record in duration format (default: 2s)").Default(defaultConfig.ACMEPropagationInterval.String()).DurationVar(&cfg.ACMEPropagationInterval)
 
 	_, err := app.Parse(args)
 	if err != nil {
@@ -38,10 +38,14 @@ var (
 		GlooNamespaces:              []string{"gloo-system"},
 		SkipperRouteGroupVersion:    "zalando.org/v1",
 		Sources:                     []string{"service"},
-		Namespace:                   "",
+		Namespace:                   []string{""},
+		ExcludeNamespaces:           []string{""},
 		FQDNTemplate:                "",
+		AnnotationPrefix:            "external-dns.alpha.kubernetes.io/",
+		ControllerValue:             "dns-controller",
 		Compatibility:               "",
 		Provider:                    "google",
+		ProviderProbeBackoff:        30 * time.Second,
 		GoogleProject:               "",
 		GoogleBatchChangeSize:       1000,
 		GoogleBatchChangeInterval:   time.Second,
@@ -58,13 +62,17 @@ var (
 		AWSAssumeRole:               "",
 		AWSAssumeRoleExternalID:     "",
 		AWSBatchChangeSize:          1000,
+		AWSBatchChangeSizeBytes:     32000,
+		AWSBatchChangeSizeValues:    1000,
 		AWSBatchChangeInterval:      time.Second,
+		AWSZoneConcurrency:          1,
 		AWSEvaluateTargetHealth:     true,
 		AWSAPIRetries:               3,
 		AWSPreferCNAME:              false,
 		AWSZoneCacheDuration:        0 * time.Second,
 		AWSSDServiceCleanup:         false,
 		AWSDynamoDBTable:            "external-dns",
+		AWSDynamoDBHistoryRetention: 7 * 24 * time.Hour,
 		AzureConfigFile:             "/etc/kubernetes/azure.json",
 		AzureResourceGroup:          "",
 		AzureSubscriptionID:         "",
@@ -76,6 +84,8 @@ var (
 		BluecatRootZone:             "",
 		BluecatDNSDeployType:        defaultConfig.BluecatDNSDeployType,
 		BluecatSkipTLSVerify:        false,
+		BluecatAddressManagerHost:   "",
+		BluecatDeployInterval:       0,
 		CloudflareProxied:           false,
 		CloudflareDNSRecordsPerPage: 100,
 		CoreDNSPrefix:               "/skydns/",
@@ -93,6 +103,8 @@ var (
 		InfobloxView:                "",
 		InfobloxSSLVerify:           true,
 		InfobloxMaxResults:          0,
+		InfobloxOwnerEA:             "external-dns-owner",
+		InfobloxResourceEA:          "external-dns-resource",
 		OCIConfigFile:               "/etc/kubernetes/oci.yaml",
 		OCIZoneScope:                "GLOBAL",
 		OCIZoneCacheDuration:        0 * time.Second,
@@ -108,8 +120,15 @@ var (
 		TXTCacheInterval:            0,
 		Interval:                    time.Minute,
 		MinEventSyncInterval:        5 * time.Second,
+		CanaryTimeout:               2 * time.Minute,
+		HistoryLimit:                20,
+		ChurnThreshold:              10,
+		CredentialsReloadInterval:   time.Minute,
+		VaultAuthMethod:             "kubernetes",
+		VaultKubernetesTokenFile:    "/var/run/secrets/kubernetes.io/serviceaccount/token",
 		Once:                        false,
 		DryRun:                      false,
+		ReadOnly:                    false,
 		UpdateEvents:                false,
 		LogFormat:                   "text",
 		MetricsAddress:              ":7979",
@@ -127,6 +146,11 @@ var (
 		DigitalOceanAPIPageSize:     50,
 		ManagedDNSRecordTypes:       []string{endpoint.RecordTypeA, endpoint.RecordTypeAAAA, endpoint.RecordTypeCNAME},
 		RFC2136BatchChangeSize:      50,
+		RFC2136ZoneConcurrency:      1,
+		RFC2136ClockSkew:            5 * time.Minute,
+		RFC2136Retries:              2,
+		RFC2136RetryBackoff:         time.Second,
+		RFC2136RetryBudget:          10,
 		OCPRouterName:               "default",
 		IBMCloudProxied:             false,
 		IBMCloudConfigFile:          "/etc/kubernetes/ibmcloud.json",
@@ -135,122 +159,257 @@ var (
 		WebhookProviderURL:          "http://localhost:8888",
 		WebhookProviderReadTimeout:  5 * time.Second,
 		WebhookProviderWriteTimeout: 10 * time.Second,
+		WebhookServerAddress:        "127.0.0.1:8888",
+		PiholeAPIVersion:            "5",
+		UnboundControlAddress:       "127.0.0.1:8953",
+		UnboundLocalZoneType:        "transparent",
+		ACMEServerAddress:           "127.0.0.1:8889",
+		ACMEServerTokenFile:         "",
+		ACMEChallengeTTL:            60,
+		ACMEPropagationTimeout:      2 * time.Minute,
+		ACMEPropagationInterval:     2 * time.Second,
+		QuarantineMinEndpoints:      10,
+		TargetSelection:             "stable-hash",
+		TargetHealthFormat:          "json",
+		TargetHealthMetric:          "target_healthy",
+		TargetHealthInterval:        30 * time.Second,
+		MetalLBNamespace:            "metallb-system",
+		KlipperSubsetSize:           3,
 	}
 
 	overriddenConfig = &Config{
-		APIServerURL:                "http://127.0.0.1:8080",
-		KubeConfig:                  "/some/path",
-		RequestTimeout:              time.Second * 77,
-		GlooNamespaces:              []string{"gloo-not-system", "gloo-second-system"},
-		SkipperRouteGroupVersion:    "zalando.org/v2",
-		Sources:                     []string{"service", "ingress", "connector"},
-		Namespace:                   "namespace",
-		IgnoreHostnameAnnotation:    true,
-		IgnoreIngressTLSSpec:        true,
-		IgnoreIngressRulesSpec:      true,
-		FQDNTemplate:                "{{.Name}}.service.example.com",
-		Compatibility:               "mate",
-		Provider:                    "google",
-		GoogleProject:               "project",
-		GoogleBatchChangeSize:       100,
-		GoogleBatchChangeInterval:   time.Second * 2,
-		GoogleZoneVisibility:        "private",
-		DomainFilter:                []string{"example.org", "company.com"},
-		ExcludeDomains:              []string{"xapi.example.org", "xapi.company.com"},
-		RegexDomainFilter:           regexp.MustCompile("(example\\.org|company\\.com)$"),
-		RegexDomainExclusion:        regexp.MustCompile("xapi\\.(example\\.org|company\\.com)$"),
-		ZoneNameFilter:              []string{"yapi.example.org", "yapi.company.com"},
-		ZoneIDFilter:                []string{"/hostedzone/ZTST1", "/hostedzone/ZTST2"},
-		TargetNetFilter:             []string{"10.0.0.0/9", "10.1.0.0/9"},
-		ExcludeTargetNets:           []string{"1.0.0.0/9", "1.1.0.0/9"},
-		AlibabaCloudConfigFile:      "/etc/kubernetes/alibaba-cloud.json",
-		AWSZoneType:                 "private",
-		AWSZoneTagFilter:            []string{"tag=foo"},
-		AWSAssumeRole:               "some-other-role",
-		AWSAssumeRoleExternalID:     "pg2000",
-		AWSBatchChangeSize:          100,
-		AWSBatchChangeInterval:      time.Second * 2,
-		AWSEvaluateTargetHealth:     false,
-		AWSAPIRetries:               13,
-		AWSPreferCNAME:              true,
-		AWSZoneCacheDuration:        10 * time.Second,
-		AWSSDServiceCleanup:         true,
-		AWSDynamoDBTable:            "custom-table",
-		AzureConfigFile:             "azure.json",
-		AzureResourceGroup:          "arg",
-		AzureSubscriptionID:         "arg",
-		BluecatDNSConfiguration:     "arg",
-		BluecatDNSServerName:        "arg",
-		BluecatConfigFile:           "bluecat.json",
-		BluecatDNSView:              "arg",
-		BluecatGatewayHost:          "arg",
-		BluecatRootZone:             "arg",
-		BluecatDNSDeployType:        "full-deploy",
-		BluecatSkipTLSVerify:        true,
-		CloudflareProxied:           true,
-		CloudflareDNSRecordsPerPage: 5000,
-		CoreDNSPrefix:               "/coredns/",
-		AkamaiServiceConsumerDomain: "oooo-xxxxxxxxxxxxxxxx-xxxxxxxxxxxxxxxx.luna.akamaiapis.net",
-		AkamaiClientToken:           "o184671d5307a388180fbf7f11dbdf46",
-		AkamaiClientSecret:          "o184671d5307a388180fbf7f11dbdf46",
-		AkamaiAccessToken:           "o184671d5307a388180fbf7f11dbdf46",
-		AkamaiEdgercPath:            "/home/test/.edgerc",
-		AkamaiEdgercSection:         "default",
-		InfobloxGridHost:            "127.0.0.1",
-		InfobloxWapiPort:            8443,
-		InfobloxWapiUsername:        "infoblox",
-		InfobloxWapiPassword:        "infoblox",
-		InfobloxWapiVersion:         "2.6.1",
-		InfobloxView:                "internal",
-		InfobloxSSLVerify:           false,
-		InfobloxMaxResults:          2000,
-		OCIConfigFile:               "oci.yaml",
-		OCIZoneScope:                "PRIVATE",
-		OCIZoneCacheDuration:        30 * time.Second,
-		InMemoryZones:               []string{"example.org", "company.com"},
-		OVHEndpoint:                 "ovh-ca",
-		OVHApiRateLimit:             42,
-		PDNSServer:                  "http://ns.example.com:8081",
-		PDNSAPIKey:                  "some-secret-key",
-		PDNSSkipTLSVerify:           true,
-		TLSCA:                       "/path/to/ca.crt",
-		TLSClientCert:               "/path/to/cert.pem",
-		TLSClientCertKey:            "/path/to/key.pem",
-		Policy:                      "upsert-only",
-		Registry:                    "noop",
-		TXTOwnerID:                  "owner-1",
-		TXTPrefix:                   "associated-txt-record",
-		TXTCacheInterval:            12 * time.Hour,
-		Interval:                    10 * time.Minute,
-		MinEventSyncInterval:        50 * time.Second,
-		Once:                        true,
-		DryRun:                      true,
-		UpdateEvents:                true,
-		LogFormat:                   "json",
-		MetricsAddress:              "127.0.0.1:9099",
-		LogLevel:                    logrus.DebugLevel.String(),
-		ConnectorSourceServer:       "localhost:8081",
-		ExoscaleAPIEnvironment:      "api1",
-		ExoscaleAPIZone:             "zone1",
-		ExoscaleAPIKey:              "1",
-		ExoscaleAPISecret:           "2",
-		CRDSourceAPIVersion:         "test.k8s.io/v1alpha1",
-		CRDSourceKind:               "Endpoint",
-		RcodezeroTXTEncrypt:         true,
-		NS1Endpoint:                 "https://api.example.com/v1",
-		NS1IgnoreSSL:                true,
-		TransIPAccountName:          "transip",
-		TransIPPrivateKeyFile:       "/path/to/transip.key",
-		DigitalOceanAPIPageSize:     100,
-		ManagedDNSRecordTypes:       []string{endpoint.RecordTypeA, endpoint.RecordTypeAAAA, endpoint.RecordTypeCNAME, endpoint.RecordTypeNS},
-		RFC2136BatchChangeSize:      100,
-		IBMCloudProxied:             true,
-		IBMCloudConfigFile:          "ibmcloud.json",
-		TencentCloudConfigFile:      "tencent-cloud.json",
-		TencentCloudZoneType:        "private",
-		WebhookProviderURL:          "http://localhost:8888",
-		WebhookProviderReadTimeout:  5 * time.Second,
-		WebhookProviderWriteTimeout: 10 * time.Second,
+		APIServerURL:                 "http://127.0.0.1:8080",
+		KubeConfig:                   "/some/path",
+		RequestTimeout:               time.Second * 77,
+		GlooNamespaces:               []string{"gloo-not-system", "gloo-second-system"},
+		SkipperRouteGroupVersion:     "zalando.org/v2",
+		Sources:                      []string{"service", "ingress", "connector"},
+		Namespace:                    []string{"namespace", "other"},
+		ExcludeNamespaces:            []string{"kube-system"},
+		QuarantineSources:            true,
+		QuarantineMinEndpoints:       5,
+		QuarantineMaxDuration:        time.Hour,
+		SourcePermissionsCheck:       "fail",
+		IgnoreHostnameAnnotation:     true,
+		IgnoreIngressTLSSpec:         true,
+		IgnoreIngressRulesSpec:       true,
+		IngressHostnameSource:        "tls-only",
+		PublishNodeSSHFP:             true,
+		FQDNTemplate:                 "{{.Name}}.service.example.com",
+		ApexPairingTemplate:          "www.{{ .Apex }}",
+		AnnotationPrefix:             "internal-dns/",
+		ControllerValue:              "internal-dns",
+		Compatibility:                "mate",
+		Provider:                     "google",
+		ProviderFailureThreshold:     5,
+		ProviderProbeBackoff:         time.Minute,
+		GoogleProject:                "project",
+		GoogleCredentialsFile:        "/etc/gcp/credentials.json",
+		GoogleBatchChangeSize:        100,
+		GoogleBatchChangeInterval:    time.Second * 2,
+		GoogleZoneVisibility:         "private",
+		DomainFilter:                 []string{"example.org", "company.com"},
+		ExcludeDomains:               []string{"xapi.example.org", "xapi.company.com"},
+		MinRecordDepth:               4,
+		ChangeDescriptionTemplate:    "{{.Action}} for {{.Resource}}",
+		RegexDomainFilter:            regexp.MustCompile("(example\\.org|company\\.com)$"),
+		RegexDomainExclusion:         regexp.MustCompile("xapi\\.(example\\.org|company\\.com)$"),
+		ZoneNameFilter:               []string{"yapi.example.org", "yapi.company.com"},
+		ZoneIDFilter:                 []string{"/hostedzone/ZTST1", "/hostedzone/ZTST2"},
+		TargetNetFilter:              []string{"10.0.0.0/9", "10.1.0.0/9"},
+		ExcludeTargetNets:            []string{"1.0.0.0/9", "1.1.0.0/9"},
+		MaxTargets:                   100,
+		TargetSelection:              "ready",
+		PodReadinessConditions:       []string{"example.com/gate", "Ready"},
+		NodeReadinessConditions:      []string{"Ready", "NetworkUnavailable=False"},
+		ReadinessDampening:           30 * time.Second,
+		TargetHealthURL:              "http://health-checker:8080/metrics",
+		TargetHealthFormat:           "prometheus",
+		TargetHealthMetric:           "probe_success",
+		TargetHealthInterval:         time.Minute,
+		AlibabaCloudConfigFile:       "/etc/kubernetes/alibaba-cloud.json",
+		AWSZoneType:                  "private",
+		AWSZoneTagFilter:             []string{"tag=foo"},
+		AWSRoute53ProfileIDs:         []string{"rp-1", "rp-2"},
+		AWSAssumeRole:                "some-other-role",
+		AWSAssumeRoleExternalID:      "pg2000",
+		AWSBatchChangeSize:           100,
+		AWSBatchChangeSizeBytes:      16000,
+		AWSBatchChangeSizeValues:     500,
+		AWSBatchChangeInterval:       time.Second * 2,
+		AWSZoneConcurrency:           4,
+		AWSEvaluateTargetHealth:      false,
+		AWSAPIRetries:                13,
+		AWSPreferCNAME:               true,
+		AWSZoneCacheDuration:         10 * time.Second,
+		AWSSDServiceCleanup:          true,
+		AWSDynamoDBTable:             "custom-table",
+		AWSDynamoDBRecordHistory:     10,
+		AWSDynamoDBHistoryRetention:  72 * time.Hour,
+		AzureConfigFile:              "azure.json",
+		AzureResourceGroup:           "arg",
+		AzureSubscriptionID:          "arg",
+		BluecatDNSConfiguration:      "arg",
+		BluecatDNSServerName:         "arg",
+		BluecatConfigFile:            "bluecat.json",
+		BluecatDNSView:               "arg",
+		BluecatGatewayHost:           "arg",
+		BluecatRootZone:              "arg",
+		BluecatDNSDeployType:         "full-deploy",
+		BluecatSkipTLSVerify:         true,
+		BluecatAddressManagerHost:    "https://bam.example.com",
+		BluecatDeployInterval:        5 * time.Minute,
+		CloudflareProxied:            true,
+		CloudflareDNSRecordsPerPage:  5000,
+		CoreDNSPrefix:                "/coredns/",
+		AkamaiServiceConsumerDomain:  "oooo-xxxxxxxxxxxxxxxx-xxxxxxxxxxxxxxxx.luna.akamaiapis.net",
+		AkamaiClientToken:            "o184671d5307a388180fbf7f11dbdf46",
+		AkamaiClientSecret:           "o184671d5307a388180fbf7f11dbdf46",
+		AkamaiAccessToken:            "o184671d5307a388180fbf7f11dbdf46",
+		AkamaiEdgercPath:             "/home/test/.edgerc",
+		AkamaiEdgercSection:          "default",
+		AkamaiUseChangelists:         true,
+		InfobloxGridHost:             "127.0.0.1",
+		InfobloxWapiPort:             8443,
+		InfobloxWapiUsername:         "infoblox",
+		InfobloxWapiPassword:         "infoblox",
+		InfobloxWapiVersion:          "2.6.1",
+		InfobloxView:                 "internal",
+		InfobloxSSLVerify:            false,
+		InfobloxMaxResults:           2000,
+		InfobloxDomainViews:          []string{"example.org=external", "corp.example.org=internal"},
+		InfobloxDomainNetworkViews:   []string{"lab.example.org=lab"},
+		InfobloxOwnerEA:              "owner",
+		InfobloxResourceEA:           "resource",
+		OCIConfigFile:                "oci.yaml",
+		OCIZoneScope:                 "PRIVATE",
+		OCIZoneCacheDuration:         30 * time.Second,
+		OCIPrivateViewIDs:            []string{"ocid1.dnsview.oc1..view1", "ocid1.dnsview.oc1..view2"},
+		InMemoryZones:                []string{"example.org", "company.com"},
+		OVHEndpoint:                  "ovh-ca",
+		OVHApiRateLimit:              42,
+		PDNSServer:                   "http://ns.example.com:8081",
+		PDNSAPIKey:                   "some-secret-key",
+		PDNSSkipTLSVerify:            true,
+		TLSCA:                        "/path/to/ca.crt",
+		TLSClientCert:                "/path/to/cert.pem",
+		TLSClientCertKey:             "/path/to/key.pem",
+		Policy:                       "upsert-only",
+		Registry:                     "noop",
+		TXTOwnerID:                   "owner-1",
+		TXTPrefix:                    "associated-txt-record",
+		TXTCacheInterval:             12 * time.Hour,
+		TXTGCInterval:                24 * time.Hour,
+		TXTGCDryRun:                  true,
+		RegistrySnapshotSave:         "/tmp/registry-snapshot.json",
+		Interval:                     10 * time.Minute,
+		MinEventSyncInterval:         50 * time.Second,
+		FullSyncInterval:             time.Hour,
+		DampeningWindow:              5 * time.Minute,
+		TargetChangeTTL:              time.Minute,
+		TargetChangeSoak:             10 * time.Minute,
+		ProviderBatchSize:            500,
+		ProviderBatchInterval:        10 * time.Second,
+		RecordsPagination:            true,
+		MaintenanceWindows:           []string{"0 18 * * 5 62h", "0 0 24 12 * 48h"},
+		ChangeWindows:                []string{"prod.example.com=02:00-04:00", "example.org=22:00-00:00"},
+		CanaryZones:                  []string{"example.com=canary.example.net"},
+		CanaryNameserver:             "ns1.example.net:53",
+		CanaryTimeout:                5 * time.Minute,
+		Once:                         true,
+		DryRun:                       true,
+		ReadOnly:                     true,
+		RecordsSnapshot:              "/snapshots/records.json",
+		UpdateEvents:                 true,
+		WarningEvents:                true,
+		PublishedHostnamesAnnotation: true,
+		NodeDeletionEvents:           true,
+		PublishExternalIPs:           true,
+		MetalLB:                      true,
+		MetalLBNamespace:             "metallb",
+		KlipperPolicy:                "target",
+		KlipperSubsetSize:            2,
+		KlipperTarget:                "traefik.example.org",
+		LoadBalancerClassFilter:      []string{"metallb.io/metallb", "service.k8s.aws/nlb"},
+		LogFormat:                    "json",
+		MetricsAddress:               "127.0.0.1:9099",
+		DebugDNSAddress:              "127.0.0.1:5353",
+		CredentialsReloadInterval:    5 * time.Minute,
+		VaultAddress:                 "https://vault:8200",
+		VaultNamespace:               "team-a",
+		VaultCACert:                  "/etc/vault/ca.crt",
+		VaultAuthMethod:              "approle",
+		VaultAuthMountPath:           "approle-dns",
+		VaultAppRoleRoleID:           "external-dns",
+		VaultAppRoleSecretIDFile:     "/etc/vault/secret-id",
+		VaultKubernetesTokenFile:     "/var/run/secrets/kubernetes.io/serviceaccount/token",
+		SnapshotPath:                 "/var/lib/external-dns/snapshot.json",
+		HistoryDir:                   "/var/lib/external-dns/history",
+		HistoryLimit:                 5,
+		RollbackEndpoint:             true,
+		RollbackEndpointTokenFile:    "/etc/external-dns/rollback-token",
+		ExplainEndpoint:              true,
+		ChurnWindow:                  time.Hour,
+		ChurnThreshold:               5,
+		LogLevel:                     logrus.DebugLevel.String(),
+		ConnectorSourceServer:        "localhost:8081",
+		ExoscaleAPIEnvironment:       "api1",
+		ExoscaleAPIZone:              "zone1",
+		ExoscaleAPIKey:               "1",
+		ExoscaleAPISecret:            "2",
+		CRDSourceAPIVersion:          "test.k8s.io/v1alpha1",
+		CRDSourceKind:                "Endpoint",
+		RcodezeroTXTEncrypt:          true,
+		NS1Endpoint:                  "https://api.example.com/v1",
+		NS1IgnoreSSL:                 true,
+		TransIPAccountName:           "transip",
+		TransIPPrivateKeyFile:        "/path/to/transip.key",
+		DigitalOceanAPIPageSize:      100,
+		DNSimpleZoneCacheDuration:    30 * time.Second,
+		ManagedDNSRecordTypes:        []string{endpoint.RecordTypeA, endpoint.RecordTypeAAAA, endpoint.RecordTypeCNAME, endpoint.RecordTypeNS},
+		PassthroughRecordTypes:       []string{"LOC", "CERT"},
+		RFC2136BatchChangeSize:       100,
+		RFC2136ZoneConcurrency:       4,
+		RFC2136ClockSkew:             10 * time.Minute,
+		RFC2136UpdateCheck:           true,
+		RFC2136LocalAddress:          "10.0.0.5",
+		RFC2136DiscoverPrimary:       true,
+		RFC2136FallbackHosts:         []string{"ns2.example.org", "ns3.example.org:5353"},
+		RFC2136ADSite:                "Paris",
+		RFC2136ADDomain:              "corp.example.org",
+		RFC2136Retries:               5,
+		RFC2136RetryBackoff:          2 * time.Second,
+		RFC2136RetryBudget:           20,
+		IBMCloudProxied:              true,
+		IBMCloudConfigFile:           "ibmcloud.json",
+		TencentCloudConfigFile:       "tencent-cloud.json",
+		TencentCloudZoneType:         "private",
+		WebhookProviderURL:           "http://localhost:8888",
+		WebhookProviderReadTimeout:   5 * time.Second,
+		WebhookProviderWriteTimeout:  10 * time.Second,
+		WebhookServerAddress:         ":8888",
+		WebhookServerClientsFile:     "/etc/external-dns/clients",
+		WebhookServerTLSCertFile:     "/etc/external-dns/tls.crt",
+		WebhookServerTLSKeyFile:      "/etc/external-dns/tls.key",
+		WebhookProviderTokenFile:     "/etc/external-dns/token",
+		PiholeAPIVersion:             "6",
+		DnsmasqHostsFile:             "/etc/dnsmasq.hosts.d/external-dns",
+		DnsmasqConfFile:              "/etc/dnsmasq.d/external-dns.conf",
+		DnsmasqPidFile:               "/run/dnsmasq.pid",
+		UnboundControlAddress:        "/run/unbound.ctl",
+		UnboundServerCertFile:        "/etc/unbound/unbound_server.pem",
+		UnboundControlCertFile:       "/etc/unbound/unbound_control.pem",
+		UnboundControlKeyFile:        "/etc/unbound/unbound_control.key",
+		UnboundLocalZones:            []string{"example.org", "example.com"},
+		UnboundLocalZoneType:         "static",
+		TechnitiumServer:             "http://dns.example.org:5380",
+		TechnitiumToken:              "technitium-token",
+		TechnitiumSkipTLSVerify:      true,
+		ACMEServerAddress:            "127.0.0.1:8889",
+		ACMEServerTokenFile:          "",
+		ACMEChallengeTTL:             60,
+		ACMEPropagationTimeout:       2 * time.Minute,
+		ACMEPropagationInterval:      2 * time.Second,
 	}
 )
 
@@ -284,13 +443,27 @@ func TestParseFlags(t *testing.T) {
 				"--source=ingress",
 				"--source=connector",
 				"--namespace=namespace",
+				"--namespace=other",
+				"--exclude-namespaces=kube-system",
+				"--quarantine-sources",
+				"--quarantine-min-endpoints=5",
+				"--quarantine-max-duration=1h",
+				"--source-permissions-check=fail",
 				"--fqdn-template={{.Name}}.service.example.com",
+				"--apex-pairing-template=www.{{ .Apex }}",
+				"--annotation-prefix=internal-dns/",
+				"--controller-value=internal-dns",
 				"--ignore-hostname-annotation",
 				"--ignore-ingress-tls-spec",
 				"--ignore-ingress-rules-spec",
+				"--ingress-hostname-source=tls-only",
+				"--publish-node-sshfp",
 				"--compatibility=mate",
 				"--provider=google",
+				"--provider-failure-threshold=5",
+				"--provider-probe-backoff=1m",
 				"--google-project=project",
+				"--google-credentials-file=/etc/gcp/credentials.json",
 				"--google-batch-change-size=100",
 				"--google-batch-change-interval=2s",
 				"--google-zone-visibility=private",
@@ -305,6 +478,8 @@ func TestParseFlags(t *testing.T) {
 				"--bluecat-root-zone=arg",
 				"--bluecat-dns-deploy-type=full-deploy",
 				"--bluecat-skip-tls-verify",
+				"--bluecat-address-manager-host=https://bam.example.com",
+				"--bluecat-deploy-interval=5m",
 				"--cloudflare-proxied",
 				"--cloudflare-dns-records-per-page=5000",
 				"--coredns-prefix=/coredns/",
@@ -314,6 +489,7 @@ func TestParseFlags(t *testing.T) {
 				"--akamai-access-token=o184671d5307a388180fbf7f11dbdf46",
 				"--akamai-edgerc-path=/home/test/.edgerc",
 				"--akamai-edgerc-section=default",
+				"--akamai-use-changelists",
 				"--infoblox-grid-host=127.0.0.1",
 				"--infoblox-wapi-port=8443",
 				"--infoblox-wapi-username=infoblox",
@@ -321,6 +497,11 @@ func TestParseFlags(t *testing.T) {
 				"--infoblox-wapi-version=2.6.1",
 				"--infoblox-view=internal",
 				"--infoblox-max-results=2000",
+				"--infoblox-domain-view=example.org=external",
+				"--infoblox-domain-view=corp.example.org=internal",
+				"--infoblox-domain-network-view=lab.example.org=lab",
+				"--infoblox-owner-ea=owner",
+				"--infoblox-resource-ea=resource",
 				"--inmemory-zone=example.org",
 				"--inmemory-zone=company.com",
 				"--ovh-endpoint=ovh-ca",
@@ -331,6 +512,27 @@ func TestParseFlags(t *testing.T) {
 				"--oci-config-file=oci.yaml",
 				"--oci-zone-scope=PRIVATE",
 				"--oci-zones-cache-duration=30s",
+				"--oci-private-view-id=ocid1.dnsview.oc1..view1",
+				"--oci-private-view-id=ocid1.dnsview.oc1..view2",
+				"--pihole-api-version=6",
+				"--webhook-server-address=:8888",
+				"--webhook-server-clients-file=/etc/external-dns/clients",
+				"--webhook-server-tls-cert-file=/etc/external-dns/tls.crt",
+				"--webhook-server-tls-key-file=/etc/external-dns/tls.key",
+				"--webhook-provider-token-file=/etc/external-dns/token",
+				"--dnsmasq-hosts-file=/etc/dnsmasq.hosts.d/external-dns",
+				"--dnsmasq-conf-file=/etc/dnsmasq.d/external-dns.conf",
+				"--dnsmasq-pid-file=/run/dnsmasq.pid",
+				"--unbound-control-address=/run/unbound.ctl",
+				"--unbound-server-cert-file=/etc/unbound/unbound_server.pem",
+				"--unbound-control-cert-file=/etc/unbound/unbound_control.pem",
+				"--unbound-control-key-file=/etc/unbound/unbound_control.key",
+				"--unbound-local-zone=example.org",
+				"--unbound-local-zone=example.com",
+				"--unbound-local-zone-type=static",
+				"--technitium-server=http://dns.example.org:5380",
+				"--technitium-token=technitium-token",
+				"--technitium-tls-skip-verify",
 				"--tls-ca=/path/to/ca.crt",
 				"--tls-client-cert=/path/to/cert.pem",
 				"--tls-client-cert-key=/path/to/key.pem",
@@ -339,6 +541,8 @@ func TestParseFlags(t *testing.T) {
 				"--domain-filter=company.com",
 				"--exclude-domains=xapi.example.org",
 				"--exclude-domains=xapi.company.com",
+				"--min-record-depth=4",
+				"--change-description-template={{.Action}} for {{.Resource}}",
 				"--regex-domain-filter=(example\\.org|company\\.com)$",
 				"--regex-domain-exclusion=xapi\\.(example\\.org|company\\.com)$",
 				"--zone-name-filter=yapi.example.org",
@@ -349,12 +553,28 @@ func TestParseFlags(t *testing.T) {
 				"--target-net-filter=10.1.0.0/9",
 				"--exclude-target-net=1.0.0.0/9",
 				"--exclude-target-net=1.1.0.0/9",
+				"--max-targets=100",
+				"--target-selection=ready",
+				"--pod-readiness-condition=example.com/gate",
+				"--pod-readiness-condition=Ready",
+				"--node-readiness-condition=Ready",
+				"--node-readiness-condition=NetworkUnavailable=False",
+				"--readiness-dampening=30s",
+				"--target-health-url=http://health-checker:8080/metrics",
+				"--target-health-format=prometheus",
+				"--target-health-metric=probe_success",
+				"--target-health-interval=1m",
 				"--aws-zone-type=private",
 				"--aws-zone-tags=tag=foo",
+				"--aws-route53-profile-id=rp-1",
+				"--aws-route53-profile-id=rp-2",
 				"--aws-assume-role=some-other-role",
 				"--aws-assume-role-external-id=pg2000",
 				"--aws-batch-change-size=100",
+				"--aws-batch-change-size-bytes=16000",
+				"--aws-batch-change-size-values=500",
 				"--aws-batch-change-interval=2s",
+				"--aws-zone-concurrency=4",
 				"--aws-api-retries=13",
 				"--aws-prefer-cname",
 				"--aws-zones-cache-duration=10s",
@@ -365,14 +585,63 @@ func TestParseFlags(t *testing.T) {
 				"--txt-owner-id=owner-1",
 				"--txt-prefix=associated-txt-record",
 				"--txt-cache-interval=12h",
+				"--txt-gc-interval=24h",
+				"--txt-gc-dry-run",
+				"--registry-snapshot-save=/tmp/registry-snapshot.json",
 				"--dynamodb-table=custom-table",
+				"--dynamodb-record-history=10",
+				"--dynamodb-history-retention=72h",
 				"--interval=10m",
 				"--min-event-sync-interval=50s",
+				"--full-sync-interval=1h",
+				"--dampening-window=5m",
+				"--target-change-ttl=1m",
+				"--target-change-soak=10m",
+				"--provider-batch-size=500",
+				"--provider-batch-interval=10s",
+				"--records-pagination",
+				"--maintenance-window=0 18 * * 5 62h",
+				"--maintenance-window=0 0 24 12 * 48h",
+				"--change-window=prod.example.com=02:00-04:00",
+				"--change-window=example.org=22:00-00:00",
+				"--canary-zone=example.com=canary.example.net",
+				"--canary-nameserver=ns1.example.net:53",
+				"--canary-timeout=5m",
 				"--once",
 				"--dry-run",
+				"--read-only",
+				"--records-snapshot=/snapshots/records.json",
 				"--events",
+				"--warning-events",
+				"--published-hostnames-annotation",
+				"--node-deletion-events",
+				"--publish-external-ips",
+				"--metallb",
+				"--metallb-namespace=metallb",
+				"--klipper-policy=target",
+				"--klipper-subset-size=2",
+				"--klipper-target=traefik.example.org",
+				"--service-load-balancer-class=metallb.io/metallb",
+				"--service-load-balancer-class=service.k8s.aws/nlb",
 				"--log-format=json",
 				"--metrics-address=127.0.0.1:9099",
+				"--debug-dns-address=127.0.0.1:5353",
+				"--credentials-reload-interval=5m",
+				"--vault-address=https://vault:8200",
+				"--vault-namespace=team-a",
+				"--vault-ca-cert=/etc/vault/ca.crt",
+				"--vault-auth-method=approle",
+				"--vault-auth-mount-path=approle-dns",
+				"--vault-approle-role-id=external-dns",
+				"--vault-approle-secret-id-file=/etc/vault/secret-id",
+				"--snapshot-path=/var/lib/external-dns/snapshot.json",
+				"--history-dir=/var/lib/external-dns/history",
+				"--history-limit=5",
+				"--rollback-endpoint",
+				"--rollback-endpoint-token-file=/etc/external-dns/rollback-token",
+				"--explain-endpoint",
+				"--churn-window=1h",
+				"--churn-threshold=5",
 				"--log-level=debug",
 				"--connector-source-server=localhost:8081",
 				"--exoscale-apienv=api1",
@@ -387,11 +656,26 @@ func TestParseFlags(t *testing.T) {
 				"--transip-account=transip",
 				"--transip-keyfile=/path/to/transip.key",
 				"--digitalocean-api-page-size=100",
+				"--dnsimple-zones-cache-duration=30s",
 				"--managed-record-types=A",
 				"--managed-record-types=AAAA",
 				"--managed-record-types=CNAME",
 				"--managed-record-types=NS",
+				"--passthrough-record-types=LOC",
+				"--passthrough-record-types=CERT",
 				"--rfc2136-batch-change-size=100",
+				"--rfc2136-zone-concurrency=4",
+				"--rfc2136-clock-skew=10m",
+				"--rfc2136-update-check",
+				"--rfc2136-local-address=10.0.0.5",
+				"--rfc2136-discover-primary",
+				"--rfc2136-fallback-host=ns2.example.org",
+				"--rfc2136-fallback-host=ns3.example.org:5353",
+				"--rfc2136-ad-site=Paris",
+				"--rfc2136-ad-domain=corp.example.org",
+				"--rfc2136-retries=5",
+				"--rfc2136-retry-backoff=2s",
+				"--rfc2136-retry-budget=20",
 				"--ibmcloud-proxied",
 				"--ibmcloud-config-file=ibmcloud.json",
 				"--tencent-cloud-config-file=tencent-cloud.json",
@@ -411,14 +695,27 @@ func TestParseFlags(t *testing.T) {
 				"EXTERNAL_DNS_GLOO_NAMESPACE":                  "gloo-not-system\ngloo-second-system",
 				"EXTERNAL_DNS_SKIPPER_ROUTEGROUP_GROUPVERSION": "zalando.org/v2",
 				"EXTERNAL_DNS_SOURCE":                          "service\ningress\nconnector",
-				"EXTERNAL_DNS_NAMESPACE":                       "namespace",
+				"EXTERNAL_DNS_NAMESPACE":                       "namespace\nother",
+				"EXTERNAL_DNS_EXCLUDE_NAMESPACES":              "kube-system",
+				"EXTERNAL_DNS_QUARANTINE_SOURCES":              "1",
+				"EXTERNAL_DNS_QUARANTINE_MIN_ENDPOINTS":        "5",
+				"EXTERNAL_DNS_QUARANTINE_MAX_DURATION":         "1h",
+				"EXTERNAL_DNS_SOURCE_PERMISSIONS_CHECK":        "fail",
 				"EXTERNAL_DNS_FQDN_TEMPLATE":                   "{{.Name}}.service.example.com",
+				"EXTERNAL_DNS_APEX_PAIRING_TEMPLATE":           "www.{{ .Apex }}",
+				"EXTERNAL_DNS_ANNOTATION_PREFIX":               "internal-dns/",
+				"EXTERNAL_DNS_CONTROLLER_VALUE":                "internal-dns",
 				"EXTERNAL_DNS_IGNORE_HOSTNAME_ANNOTATION":      "1",
 				"EXTERNAL_DNS_IGNORE_INGRESS_TLS_SPEC":         "1",
 				"EXTERNAL_DNS_IGNORE_INGRESS_RULES_SPEC":       "1",
+				"EXTERNAL_DNS_INGRESS_HOSTNAME_SOURCE":         "tls-only",
+				"EXTERNAL_DNS_PUBLISH_NODE_SSHFP":              "1",
 				"EXTERNAL_DNS_COMPATIBILITY":                   "mate",
 				"EXTERNAL_DNS_PROVIDER":                        "google",
+				"EXTERNAL_DNS_PROVIDER_FAILURE_THRESHOLD":      "5",
+				"EXTERNAL_DNS_PROVIDER_PROBE_BACKOFF":          "1m",
 				"EXTERNAL_DNS_GOOGLE_PROJECT":                  "project",
+				"EXTERNAL_DNS_GOOGLE_CREDENTIALS_FILE":         "/etc/gcp/credentials.json",
 				"EXTERNAL_DNS_GOOGLE_BATCH_CHANGE_SIZE":        "100",
 				"EXTERNAL_DNS_GOOGLE_BATCH_CHANGE_INTERVAL":    "2s",
 				"EXTERNAL_DNS_GOOGLE_ZONE_VISIBILITY":          "private",
@@ -433,6 +730,8 @@ func TestParseFlags(t *testing.T) {
 				"EXTERNAL_DNS_BLUECAT_GATEWAY_HOST":            "arg",
 				"EXTERNAL_DNS_BLUECAT_ROOT_ZONE":               "arg",
 				"EXTERNAL_DNS_BLUECAT_SKIP_TLS_VERIFY":         "1",
+				"EXTERNAL_DNS_BLUECAT_ADDRESS_MANAGER_HOST":    "https://bam.example.com",
+				"EXTERNAL_DNS_BLUECAT_DEPLOY_INTERVAL":         "5m",
 				"EXTERNAL_DNS_CLOUDFLARE_PROXIED":              "1",
 				"EXTERNAL_DNS_CLOUDFLARE_DNS_RECORDS_PER_PAGE": "5000",
 				"EXTERNAL_DNS_COREDNS_PREFIX":                  "/coredns/",
@@ -442,6 +741,7 @@ func TestParseFlags(t *testing.T) {
 				"EXTERNAL_DNS_AKAMAI_ACCESS_TOKEN":             "o184671d5307a388180fbf7f11dbdf46",
 				"EXTERNAL_DNS_AKAMAI_EDGERC_PATH":              "/home/test/.edgerc",
 				"EXTERNAL_DNS_AKAMAI_EDGERC_SECTION":           "default",
+				"EXTERNAL_DNS_AKAMAI_USE_CHANGELISTS":          "1",
 				"EXTERNAL_DNS_INFOBLOX_GRID_HOST":              "127.0.0.1",
 				"EXTERNAL_DNS_INFOBLOX_WAPI_PORT":              "8443",
 				"EXTERNAL_DNS_INFOBLOX_WAPI_USERNAME":          "infoblox",
@@ -450,18 +750,52 @@ func TestParseFlags(t *testing.T) {
 				"EXTERNAL_DNS_INFOBLOX_VIEW":                   "internal",
 				"EXTERNAL_DNS_INFOBLOX_SSL_VERIFY":             "0",
 				"EXTERNAL_DNS_INFOBLOX_MAX_RESULTS":            "2000",
+				"EXTERNAL_DNS_INFOBLOX_DOMAIN_VIEW":            "example.org=external\ncorp.example.org=internal",
+				"EXTERNAL_DNS_INFOBLOX_DOMAIN_NETWORK_VIEW":    "lab.example.org=lab",
+				"EXTERNAL_DNS_INFOBLOX_OWNER_EA":               "owner",
+				"EXTERNAL_DNS_INFOBLOX_RESOURCE_EA":            "resource",
 				"EXTERNAL_DNS_OCI_CONFIG_FILE":                 "oci.yaml",
 				"EXTERNAL_DNS_OCI_ZONE_SCOPE":                  "PRIVATE",
 				"EXTERNAL_DNS_OCI_ZONES_CACHE_DURATION":        "30s",
+				"EXTERNAL_DNS_OCI_PRIVATE_VIEW_ID":             "ocid1.dnsview.oc1..view1\nocid1.dnsview.oc1..view2",
+				"EXTERNAL_DNS_PIHOLE_API_VERSION":              "6",
+				"EXTERNAL_DNS_WEBHOOK_SERVER_ADDRESS":          ":8888",
+				"EXTERNAL_DNS_WEBHOOK_SERVER_CLIENTS_FILE":     "/etc/external-dns/clients",
+				"EXTERNAL_DNS_WEBHOOK_SERVER_TLS_CERT_FILE":    "/etc/external-dns/tls.crt",
+				"EXTERNAL_DNS_WEBHOOK_SERVER_TLS_KEY_FILE":     "/etc/external-dns/tls.key",
+				"EXTERNAL_DNS_WEBHOOK_PROVIDER_TOKEN_FILE":     "/etc/external-dns/token",
+				"EXTERNAL_DNS_DNSMASQ_HOSTS_FILE":              "/etc/dnsmasq.hosts.d/external-dns",
+				"EXTERNAL_DNS_DNSMASQ_CONF_FILE":               "/etc/dnsmasq.d/external-dns.conf",
+				"EXTERNAL_DNS_DNSMASQ_PID_FILE":                "/run/dnsmasq.pid",
+				"EXTERNAL_DNS_UNBOUND_CONTROL_ADDRESS":         "/run/unbound.ctl",
+				"EXTERNAL_DNS_UNBOUND_SERVER_CERT_FILE":        "/etc/unbound/unbound_server.pem",
+				"EXTERNAL_DNS_UNBOUND_CONTROL_CERT_FILE":       "/etc/unbound/unbound_control.pem",
+				"EXTERNAL_DNS_UNBOUND_CONTROL_KEY_FILE":        "/etc/unbound/unbound_control.key",
+				"EXTERNAL_DNS_UNBOUND_LOCAL_ZONE":              "example.org\nexample.com",
+				"EXTERNAL_DNS_UNBOUND_LOCAL_ZONE_TYPE":         "static",
+				"EXTERNAL_DNS_TECHNITIUM_SERVER":               "http://dns.example.org:5380",
+				"EXTERNAL_DNS_TECHNITIUM_TOKEN":                "technitium-token",
+				"EXTERNAL_DNS_TECHNITIUM_TLS_SKIP_VERIFY":      "1",
 				"EXTERNAL_DNS_INMEMORY_ZONE":                   "example.org\ncompany.com",
 				"EXTERNAL_DNS_OVH_ENDPOINT":                    "ovh-ca",
 				"EXTERNAL_DNS_OVH_API_RATE_LIMIT":              "42",
 				"EXTERNAL_DNS_DOMAIN_FILTER":                   "example.org\ncompany.com",
 				"EXTERNAL_DNS_EXCLUDE_DOMAINS":                 "xapi.example.org\nxapi.company.com",
+				"EXTERNAL_DNS_MIN_RECORD_DEPTH":                "4",
+				"EXTERNAL_DNS_CHANGE_DESCRIPTION_TEMPLATE":     "{{.Action}} for {{.Resource}}",
 				"EXTERNAL_DNS_REGEX_DOMAIN_FILTER":             "(example\\.org|company\\.com)$",
 				"EXTERNAL_DNS_REGEX_DOMAIN_EXCLUSION":          "xapi\\.(example\\.org|company\\.com)$",
 				"EXTERNAL_DNS_TARGET_NET_FILTER":               "10.0.0.0/9\n10.1.0.0/9",
 				"EXTERNAL_DNS_EXCLUDE_TARGET_NET":              "1.0.0.0/9\n1.1.0.0/9",
+				"EXTERNAL_DNS_MAX_TARGETS":                     "100",
+				"EXTERNAL_DNS_TARGET_SELECTION":                "ready",
+				"EXTERNAL_DNS_POD_READINESS_CONDITION":         "example.com/gate\nReady",
+				"EXTERNAL_DNS_NODE_READINESS_CONDITION":        "Ready\nNetworkUnavailable=False",
+				"EXTERNAL_DNS_READINESS_DAMPENING":             "30s",
+				"EXTERNAL_DNS_TARGET_HEALTH_URL":               "http://health-checker:8080/metrics",
+				"EXTERNAL_DNS_TARGET_HEALTH_FORMAT":            "prometheus",
+				"EXTERNAL_DNS_TARGET_HEALTH_METRIC":            "probe_success",
+				"EXTERNAL_DNS_TARGET_HEALTH_INTERVAL":          "1m",
 				"EXTERNAL_DNS_PDNS_SERVER":                     "http://ns.example.com:8081",
 				"EXTERNAL_DNS_PDNS_API_KEY":                    "some-secret-key",
 				"EXTERNAL_DNS_PDNS_SKIP_TLS_VERIFY":            "1",
@@ -473,28 +807,78 @@ func TestParseFlags(t *testing.T) {
 				"EXTERNAL_DNS_ZONE_ID_FILTER":                  "/hostedzone/ZTST1\n/hostedzone/ZTST2",
 				"EXTERNAL_DNS_AWS_ZONE_TYPE":                   "private",
 				"EXTERNAL_DNS_AWS_ZONE_TAGS":                   "tag=foo",
+				"EXTERNAL_DNS_AWS_ROUTE53_PROFILE_ID":          "rp-1\nrp-2",
 				"EXTERNAL_DNS_AWS_ASSUME_ROLE":                 "some-other-role",
 				"EXTERNAL_DNS_AWS_ASSUME_ROLE_EXTERNAL_ID":     "pg2000",
 				"EXTERNAL_DNS_AWS_BATCH_CHANGE_SIZE":           "100",
+				"EXTERNAL_DNS_AWS_BATCH_CHANGE_SIZE_BYTES":     "16000",
+				"EXTERNAL_DNS_AWS_BATCH_CHANGE_SIZE_VALUES":    "500",
 				"EXTERNAL_DNS_AWS_BATCH_CHANGE_INTERVAL":       "2s",
+				"EXTERNAL_DNS_AWS_ZONE_CONCURRENCY":            "4",
 				"EXTERNAL_DNS_AWS_EVALUATE_TARGET_HEALTH":      "0",
 				"EXTERNAL_DNS_AWS_API_RETRIES":                 "13",
 				"EXTERNAL_DNS_AWS_PREFER_CNAME":                "true",
 				"EXTERNAL_DNS_AWS_ZONES_CACHE_DURATION":        "10s",
 				"EXTERNAL_DNS_AWS_SD_SERVICE_CLEANUP":          "true",
 				"EXTERNAL_DNS_DYNAMODB_TABLE":                  "custom-table",
+				"EXTERNAL_DNS_DYNAMODB_RECORD_HISTORY":         "10",
+				"EXTERNAL_DNS_DYNAMODB_HISTORY_RETENTION":      "72h",
 				"EXTERNAL_DNS_POLICY":                          "upsert-only",
 				"EXTERNAL_DNS_REGISTRY":                        "noop",
 				"EXTERNAL_DNS_TXT_OWNER_ID":                    "owner-1",
 				"EXTERNAL_DNS_TXT_PREFIX":                      "associated-txt-record",
 				"EXTERNAL_DNS_TXT_CACHE_INTERVAL":              "12h",
+				"EXTERNAL_DNS_TXT_GC_INTERVAL":                 "24h",
+				"EXTERNAL_DNS_TXT_GC_DRY_RUN":                  "1",
+				"EXTERNAL_DNS_REGISTRY_SNAPSHOT_SAVE":          "/tmp/registry-snapshot.json",
 				"EXTERNAL_DNS_INTERVAL":                        "10m",
 				"EXTERNAL_DNS_MIN_EVENT_SYNC_INTERVAL":         "50s",
+				"EXTERNAL_DNS_FULL_SYNC_INTERVAL":              "1h",
+				"EXTERNAL_DNS_DAMPENING_WINDOW":                "5m",
+				"EXTERNAL_DNS_TARGET_CHANGE_TTL":               "1m",
+				"EXTERNAL_DNS_TARGET_CHANGE_SOAK":              "10m",
+				"EXTERNAL_DNS_PROVIDER_BATCH_SIZE":             "500",
+				"EXTERNAL_DNS_PROVIDER_BATCH_INTERVAL":         "10s",
+				"EXTERNAL_DNS_RECORDS_PAGINATION":              "1",
+				"EXTERNAL_DNS_MAINTENANCE_WINDOW":              "0 18 * * 5 62h\n0 0 24 12 * 48h",
+				"EXTERNAL_DNS_CHANGE_WINDOW":                   "prod.example.com=02:00-04:00\nexample.org=22:00-00:00",
+				"EXTERNAL_DNS_CANARY_ZONE":                     "example.com=canary.example.net",
+				"EXTERNAL_DNS_CANARY_NAMESERVER":               "ns1.example.net:53",
+				"EXTERNAL_DNS_CANARY_TIMEOUT":                  "5m",
 				"EXTERNAL_DNS_ONCE":                            "1",
 				"EXTERNAL_DNS_DRY_RUN":                         "1",
+				"EXTERNAL_DNS_READ_ONLY":                       "1",
+				"EXTERNAL_DNS_RECORDS_SNAPSHOT":                "/snapshots/records.json",
 				"EXTERNAL_DNS_EVENTS":                          "1",
+				"EXTERNAL_DNS_WARNING_EVENTS":                  "1",
+				"EXTERNAL_DNS_PUBLISHED_HOSTNAMES_ANNOTATION":  "1",
+				"EXTERNAL_DNS_NODE_DELETION_EVENTS":            "1",
+				"EXTERNAL_DNS_PUBLISH_EXTERNAL_IPS":            "1",
+				"EXTERNAL_DNS_METALLB":                         "1",
+				"EXTERNAL_DNS_METALLB_NAMESPACE":               "metallb",
+				"EXTERNAL_DNS_KLIPPER_POLICY":                  "target",
+				"EXTERNAL_DNS_KLIPPER_SUBSET_SIZE":             "2",
+				"EXTERNAL_DNS_KLIPPER_TARGET":                  "traefik.example.org",
+				"EXTERNAL_DNS_SERVICE_LOAD_BALANCER_CLASS":     "metallb.io/metallb\nservice.k8s.aws/nlb",
 				"EXTERNAL_DNS_LOG_FORMAT":                      "json",
 				"EXTERNAL_DNS_METRICS_ADDRESS":                 "127.0.0.1:9099",
+				"EXTERNAL_DNS_DEBUG_DNS_ADDRESS":               "127.0.0.1:5353",
+				"EXTERNAL_DNS_CREDENTIALS_RELOAD_INTERVAL":     "5m",
+				"EXTERNAL_DNS_VAULT_ADDRESS":                   "https://vault:8200",
+				"EXTERNAL_DNS_VAULT_NAMESPACE":                 "team-a",
+				"EXTERNAL_DNS_VAULT_CA_CERT":                   "/etc/vault/ca.crt",
+				"EXTERNAL_DNS_VAULT_AUTH_METHOD":               "approle",
+				"EXTERNAL_DNS_VAULT_AUTH_MOUNT_PATH":           "approle-dns",
+				"EXTERNAL_DNS_VAULT_APPROLE_ROLE_ID":           "external-dns",
+				"EXTERNAL_DNS_VAULT_APPROLE_SECRET_ID_FILE":    "/etc/vault/secret-id",
+				"EXTERNAL_DNS_SNAPSHOT_PATH":                   "/var/lib/external-dns/snapshot.json",
+				"EXTERNAL_DNS_HISTORY_DIR":                     "/var/lib/external-dns/history",
+				"EXTERNAL_DNS_HISTORY_LIMIT":                   "5",
+				"EXTERNAL_DNS_ROLLBACK_ENDPOINT":               "1",
+				"EXTERNAL_DNS_ROLLBACK_ENDPOINT_TOKEN_FILE":    "/etc/external-dns/rollback-token",
+				"EXTERNAL_DNS_EXPLAIN_ENDPOINT":                "1",
+				"EXTERNAL_DNS_CHURN_WINDOW":                    "1h",
+				"EXTERNAL_DNS_CHURN_THRESHOLD":                 "5",
 				"EXTERNAL_DNS_LOG_LEVEL":                       "debug",
 				"EXTERNAL_DNS_CONNECTOR_SOURCE_SERVER":         "localhost:8081",
 				"EXTERNAL_DNS_EXOSCALE_APIENV":                 "api1",
@@ -509,8 +893,21 @@ func TestParseFlags(t *testing.T) {
 				"EXTERNAL_DNS_TRANSIP_ACCOUNT":                 "transip",
 				"EXTERNAL_DNS_TRANSIP_KEYFILE":                 "/path/to/transip.key",
 				"EXTERNAL_DNS_DIGITALOCEAN_API_PAGE_SIZE":      "100",
+				"EXTERNAL_DNS_DNSIMPLE_ZONES_CACHE_DURATION":   "30s",
 				"EXTERNAL_DNS_MANAGED_RECORD_TYPES":            "A\nAAAA\nCNAME\nNS",
+				"EXTERNAL_DNS_PASSTHROUGH_RECORD_TYPES":        "LOC\nCERT",
 				"EXTERNAL_DNS_RFC2136_BATCH_CHANGE_SIZE":       "100",
+				"EXTERNAL_DNS_RFC2136_ZONE_CONCURRENCY":        "4",
+				"EXTERNAL_DNS_RFC2136_CLOCK_SKEW":              "10m",
+				"EXTERNAL_DNS_RFC2136_UPDATE_CHECK":            "1",
+				"EXTERNAL_DNS_RFC2136_LOCAL_ADDRESS":           "10.0.0.5",
+				"EXTERNAL_DNS_RFC2136_DISCOVER_PRIMARY":        "1",
+				"EXTERNAL_DNS_RFC2136_FALLBACK_HOST":           "ns2.example.org\nns3.example.org:5353",
+				"EXTERNAL_DNS_RFC2136_AD_SITE":                 "Paris",
+				"EXTERNAL_DNS_RFC2136_AD_DOMAIN":               "corp.example.org",
+				"EXTERNAL_DNS_RFC2136_RETRIES":                 "5",
+				"EXTERNAL_DNS_RFC2136_RETRY_BACKOFF":           "2s",
+				"EXTERNAL_DNS_RFC2136_RETRY_BUDGET":            "20",
 				"EXTERNAL_DNS_IBMCLOUD_PROXIED":                "1",
 				"EXTERNAL_DNS_IBMCLOUD_CONFIG_FILE":            "ibmcloud.json",
 				"EXTERNAL_DNS_TENCENT_CLOUD_CONFIG_FILE":       "tencent-cloud.json",
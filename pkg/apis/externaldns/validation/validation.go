@@ -19,9 +19,17 @@ package validation
 import (
 	"errors"
 	"fmt"
+	"math"
+	"net"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/miekg/dns"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 
+	"sigs.k8s.io/external-dns/endpoint"
 	"sigs.k8s.io/external-dns/pkg/apis/externaldns"
 )
 
@@ -45,6 +53,16 @@ func ValidateConfig(cfg *externaldns.Config) error {
 		}
 	}
 
+	// AWS provider specific validations
+	if len(cfg.AWSRoute53ProfileIDs) > 0 {
+		if cfg.Provider != "aws" {
+			return errors.New("--aws-route53-profile-id is only supported with the aws provider")
+		}
+		if cfg.AWSZoneType == "public" {
+			return errors.New("--aws-route53-profile-id selects private hosted zones, it can't be used with --aws-zone-type=public")
+		}
+	}
+
 	// Akamai provider specific validations
 	if cfg.Provider == "akamai" {
 		if cfg.AkamaiServiceConsumerDomain == "" && cfg.AkamaiEdgercPath != "" {
@@ -69,6 +87,39 @@ func ValidateConfig(cfg *externaldns.Config) error {
 		if cfg.InfobloxWapiPassword == "" {
 			return errors.New("no Infoblox WAPI password specified")
 		}
+		for _, v := range append(cfg.InfobloxDomainViews, cfg.InfobloxDomainNetworkViews...) {
+			if domain, view, _ := strings.Cut(v, "="); domain == "" || view == "" {
+				return fmt.Errorf("invalid Infoblox domain view %q, must be domain=view", v)
+			}
+		}
+		if cfg.Registry == "infoblox-ea" && cfg.InfobloxOwnerEA == "" {
+			return errors.New("--infoblox-owner-ea is required when specifying --registry=infoblox-ea")
+		}
+	}
+
+	if cfg.Registry == "infoblox-ea" && cfg.Provider != "infoblox" {
+		return errors.New("--registry=infoblox-ea requires --provider=infoblox")
+	}
+
+	if cfg.Provider == "bluecat-bam" {
+		if cfg.BluecatAddressManagerHost == "" {
+			return errors.New("no BlueCat Address Manager host specified")
+		}
+		if cfg.BluecatDNSConfiguration == "" || cfg.BluecatDNSView == "" {
+			return errors.New("--bluecat-dns-configuration and --bluecat-dns-view are required when specifying --provider=bluecat-bam")
+		}
+		if cfg.BluecatDeployInterval < 0 {
+			return errors.New("--bluecat-deploy-interval cannot be negative")
+		}
+	}
+
+	if cfg.Provider == "unbound" {
+		if len(cfg.UnboundLocalZones) == 0 {
+			return errors.New("no unbound local zone specified")
+		}
+		if cfg.UnboundServerCertFile != "" && (cfg.UnboundControlCertFile == "" || cfg.UnboundControlKeyFile == "") {
+			return errors.New("--unbound-control-cert-file and --unbound-control-key-file are required when specifying --unbound-server-cert-file")
+		}
 	}
 
 	if cfg.Provider == "dyn" {
@@ -84,6 +135,98 @@ func ValidateConfig(cfg *externaldns.Config) error {
 		}
 	}
 
+	// the dynamodb registry writes the ownership to its table, not through the provider
+	if cfg.ReadOnly && cfg.Registry == "dynamodb" {
+		return errors.New("--read-only is not supported with the dynamodb registry")
+	}
+	// the update check sends updates to the zones at startup, the links are created when applying changes
+	if cfg.ReadOnly && cfg.RFC2136UpdateCheck {
+		return errors.New("--read-only is not supported with --rfc2136-update-check")
+	}
+	if cfg.ReadOnly && cfg.AzurePrivateDNSCreateVNetLinks {
+		return errors.New("--read-only is not supported with --azure-private-dns-create-vnet-links")
+	}
+	// the hostnames are written back once the records are created, which they never are in these modes
+	if (cfg.ReadOnly || cfg.DryRun) && cfg.PublishedHostnamesAnnotation {
+		return errors.New("--published-hostnames-annotation is not supported with --read-only and --dry-run")
+	}
+
+	if cfg.RecordsSnapshot != "" && !cfg.DryRun {
+		return errors.New("--records-snapshot requires --dry-run")
+	}
+	if cfg.RecordsSnapshot != "" && cfg.RecordsSnapshotSave != "" {
+		return errors.New("--records-snapshot and --records-snapshot-save are mutually exclusive arguments")
+	}
+
+	if cfg.ProviderBatchSize < 0 || cfg.ProviderBatchInterval < 0 {
+		return errors.New("--provider-batch-size and --provider-batch-interval cannot be negative")
+	}
+
+	// the paged reconciliation never holds all the records, which these need
+	if cfg.RecordsPagination && (cfg.SnapshotPath != "" || cfg.FullSyncInterval > 0 || cfg.DampeningWindow > 0 ||
+		len(cfg.CanaryZones) > 0 || cfg.HistoryDir != "" || cfg.ExplainEndpoint || cfg.PublishedHostnamesAnnotation) {
+		return errors.New("--records-pagination is not supported with --snapshot-path, --full-sync-interval, --dampening-window, --canary-zone, --history-dir, --explain-endpoint and --published-hostnames-annotation")
+	}
+
+	if (cfg.WebhookServerTLSCertFile == "") != (cfg.WebhookServerTLSKeyFile == "") {
+		return errors.New("--webhook-server-tls-cert-file and --webhook-server-tls-key-file must be specified together")
+	}
+	if cfg.WebhookServerTLSCertFile != "" && cfg.WebhookServerClientsFile == "" {
+		return errors.New("--webhook-server-tls-cert-file requires --webhook-server-clients-file")
+	}
+
+	if cfg.RollbackEndpoint {
+		if cfg.HistoryDir == "" {
+			return errors.New("--rollback-endpoint requires --history-dir")
+		}
+		// the endpoint rewrites the records, it must not be open to whoever can reach the metrics
+		if cfg.RollbackEndpointTokenFile == "" {
+			return errors.New("--rollback-endpoint-token-file is required when specifying --rollback-endpoint")
+		}
+	}
+
+	if cfg.ChurnWindow < 0 {
+		return errors.New("--churn-window cannot be negative")
+	}
+	if cfg.ChurnThreshold < 0 {
+		return errors.New("--churn-threshold cannot be negative")
+	}
+
+	if cfg.MinRecordDepth < 0 {
+		return errors.New("--min-record-depth cannot be negative")
+	}
+
+	if cfg.MaxTargets < 0 {
+		return errors.New("--max-targets cannot be negative")
+	}
+	if cfg.ReadinessDampening < 0 {
+		return errors.New("--readiness-dampening cannot be negative")
+	}
+	if cfg.TargetHealthURL != "" && cfg.TargetHealthInterval <= 0 {
+		return errors.New("--target-health-interval must be positive")
+	}
+	if cfg.KlipperPolicy == "stable-subset" && cfg.KlipperSubsetSize <= 0 {
+		return errors.New("--klipper-subset-size must be positive with --klipper-policy=stable-subset")
+	}
+	if cfg.KlipperPolicy == "target" && cfg.KlipperTarget == "" {
+		return errors.New("--klipper-target is required with --klipper-policy=target")
+	}
+
+	if cfg.QuarantineMinEndpoints < 0 {
+		return errors.New("--quarantine-min-endpoints cannot be negative")
+	}
+
+	if cfg.QuarantineMaxDuration < 0 {
+		return errors.New("--quarantine-max-duration cannot be negative")
+	}
+
+	if cfg.ProviderFailureThreshold < 0 {
+		return errors.New("--provider-failure-threshold cannot be negative")
+	}
+	if cfg.ProviderFailureThreshold > 0 && cfg.ProviderProbeBackoff <= 0 {
+		return errors.New("--provider-probe-backoff must be positive when --provider-failure-threshold is set")
+	}
+
 	if cfg.Provider == "rfc2136" {
 		if cfg.RFC2136MinTTL < 0 {
 			return errors.New("TTL specified for rfc2136 is negative")
@@ -93,21 +236,109 @@ func ValidateConfig(cfg *externaldns.Config) error {
 			return errors.New("--rfc2136-insecure and --rfc2136-gss-tsig are mutually exclusive arguments")
 		}
 
+		if cfg.RFC2136ADSite != "" && cfg.RFC2136ADDomain == "" && cfg.RFC2136KerberosRealm == "" {
+			return errors.New("--rfc2136-ad-site requires --rfc2136-ad-domain or --rfc2136-kerberos-realm")
+		}
+
 		if cfg.RFC2136GSSTSIG {
 			if cfg.RFC2136KerberosPassword == "" || cfg.RFC2136KerberosUsername == "" || cfg.RFC2136KerberosRealm == "" {
 				return errors.New("--rfc2136-kerberos-realm, --rfc2136-kerberos-username, and --rfc2136-kerberos-password are required when specifying --rfc2136-gss-tsig option")
 			}
 		}
 
+		if countSet(cfg.RFC2136TSIGSecret, cfg.RFC2136TSIGSecretFile, cfg.RFC2136TSIGSecretVaultPath) > 1 {
+			return errors.New("--rfc2136-tsig-secret, --rfc2136-tsig-secret-file and --rfc2136-tsig-secret-vault-path are mutually exclusive arguments")
+		}
+
 		if cfg.RFC2136BatchChangeSize < 1 {
 			return errors.New("batch size specified for rfc2136 cannot be less than 1")
 		}
+
+		// the fudge of the TSIG records is a number of seconds on 16 bits
+		if cfg.RFC2136ClockSkew < 0 || cfg.RFC2136ClockSkew > math.MaxUint16*time.Second {
+			return errors.New("--rfc2136-clock-skew must be between 0s and 18h12m15s")
+		}
+
+		if cfg.RFC2136Retries < 0 || cfg.RFC2136RetryBudget < 0 || cfg.RFC2136RetryBackoff < 0 {
+			return errors.New("--rfc2136-retries, --rfc2136-retry-backoff and --rfc2136-retry-budget cannot be negative")
+		}
+
+		if cfg.RFC2136LocalAddress != "" && net.ParseIP(cfg.RFC2136LocalAddress) == nil {
+			return fmt.Errorf("--rfc2136-local-address %q is not an IP address", cfg.RFC2136LocalAddress)
+		}
+	}
+
+	if cfg.Provider == "pdns" && cfg.PDNSAPIKey != "" && cfg.PDNSAPIKeyVaultPath != "" {
+		return errors.New("--pdns-api-key and --pdns-api-key-vault-path are mutually exclusive arguments")
+	}
+
+	if countSet(cfg.RFC2136TSIGSecretVaultPath, cfg.CloudflareAPITokenVaultPath, cfg.PDNSAPIKeyVaultPath) > 0 {
+		if cfg.VaultAddress == "" {
+			return errors.New("--vault-address is required when reading credentials from Vault")
+		}
+		switch cfg.VaultAuthMethod {
+		case "approle":
+			if cfg.VaultAppRoleRoleID == "" || cfg.VaultAppRoleSecretIDFile == "" {
+				return errors.New("--vault-approle-role-id and --vault-approle-secret-id-file are required when specifying --vault-auth-method=approle")
+			}
+		case "kubernetes":
+			if cfg.VaultKubernetesRole == "" {
+				return errors.New("--vault-kubernetes-role is required when specifying --vault-auth-method=kubernetes")
+			}
+		}
+	}
+
+	if cfg.ACMEServer {
+		if cfg.ACMEChallengeTTL < 1 {
+			return errors.New("TTL specified for ACME challenge records must be positive")
+		}
+		if cfg.ACMEPropagationTimeout > 0 && cfg.ACMEPropagationInterval <= 0 {
+			return errors.New("--acme-propagation-interval must be positive when --acme-propagation-timeout is set")
+		}
+		if cfg.ACMEServerTokenFile == "" {
+			return errors.New("--acme-server-token-file is required when specifying --acme-server")
+		}
+		// the ownership record of a challenge record would otherwise have the name and type of the challenge record
+		if cfg.Registry == "txt" && cfg.TXTPrefix == "" && cfg.TXTSuffix == "" {
+			return errors.New("--txt-prefix or --txt-suffix is required when specifying --acme-server with --registry=txt")
+		}
+		// the controller would delete the challenge records owned by it, as no source desires them
+		if slices.Contains(cfg.ManagedDNSRecordTypes, endpoint.RecordTypeTXT) {
+			return errors.New("--acme-server can't be used with TXT records in --managed-record-types")
+		}
+	}
+
+	if cfg.TargetChangeTTL < 0 || cfg.TargetChangeSoak < 0 {
+		return errors.New("--target-change-ttl and --target-change-soak must not be negative")
+	}
+	if cfg.TargetChangeTTL > 0 && cfg.Registry != "txt" {
+		return errors.New("--target-change-ttl requires the txt registry to keep the steps of the records")
+	}
+
+	if cfg.ApexPairingTemplate != "" && !slices.ContainsFunc(cfg.DomainFilter, func(domain string) bool { return domain != "" }) {
+		return errors.New("--apex-pairing-template requires the apexes to be given with --domain-filter")
 	}
 
 	if cfg.IgnoreHostnameAnnotation && cfg.FQDNTemplate == "" {
 		return errors.New("FQDN Template must be set if ignoring annotations")
 	}
 
+	if cfg.AnnotationPrefix != "" && !strings.HasSuffix(cfg.AnnotationPrefix, "/") {
+		return errors.New("--annotation-prefix must end with a slash, e.g. internal-dns/")
+	}
+
+	for _, recordType := range cfg.PassthroughRecordTypes {
+		if _, ok := dns.StringToType[recordType]; !ok {
+			return fmt.Errorf("--passthrough-record-types %q is not a record type", recordType)
+		}
+		if endpoint.IsSupportedRecordType(recordType) {
+			return fmt.Errorf("--passthrough-record-types %q is supported already and can't be passed through", recordType)
+		}
+		if !slices.Contains(cfg.ManagedDNSRecordTypes, recordType) {
+			return fmt.Errorf("--passthrough-record-types %q must be managed too, see --managed-record-types", recordType)
+		}
+	}
+
 	if len(cfg.TXTPrefix) > 0 && len(cfg.TXTSuffix) > 0 {
 		return errors.New("txt-prefix and txt-suffix are mutual exclusive")
 	}
@@ -116,5 +347,21 @@ func ValidateConfig(cfg *externaldns.Config) error {
 	if err != nil {
 		return errors.New("--label-filter does not specify a valid label selector")
 	}
+
+	_, err = fields.ParseSelector(cfg.FieldFilter)
+	if err != nil {
+		return errors.New("--field-filter does not specify a valid field selector")
+	}
 	return nil
 }
+
+// countSet returns the number of values which aren't empty.
+func countSet(values ...string) int {
+	n := 0
+	for _, v := range values {
+		if v != "" {
+			n++
+		}
+	}
+	return n
+}
@@ -18,7 +18,9 @@ package validation
 
 import (
 	"testing"
+	"time"
 
+	"sigs.k8s.io/external-dns/endpoint"
 	"sigs.k8s.io/external-dns/pkg/apis/externaldns"
 
 	"github.com/stretchr/testify/assert"
@@ -50,6 +52,22 @@ func TestValidateFlags(t *testing.T) {
 	cfg = newValidConfig(t)
 	cfg.Provider = ""
 	assert.Error(t, ValidateConfig(cfg))
+
+	cfg = newValidConfig(t)
+	cfg.AnnotationPrefix = "internal-dns"
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg = newValidConfig(t)
+	cfg.AnnotationPrefix = "internal-dns/"
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg = newValidConfig(t)
+	cfg.FieldFilter = "metadata.name"
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg = newValidConfig(t)
+	cfg.FieldFilter = "metadata.namespace!=kube-system"
+	assert.NoError(t, ValidateConfig(cfg))
 }
 
 func newValidConfig(t *testing.T) *externaldns.Config {
@@ -125,6 +143,79 @@ func TestValidateBadIgnoreHostnameAnnotationsConfig(t *testing.T) {
 	assert.Error(t, ValidateConfig(cfg))
 }
 
+func TestValidateAWSRoute53Profiles(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.AWSRoute53ProfileIDs = []string{"rp-1"}
+	assert.ErrorContains(t, ValidateConfig(cfg), "only supported with the aws provider")
+
+	cfg.Provider = "aws"
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.AWSZoneType = "public"
+	assert.ErrorContains(t, ValidateConfig(cfg), "--aws-zone-type=public")
+}
+
+func TestValidateWebhookServerTLS(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.WebhookServer = true
+	cfg.WebhookServerClientsFile = "/etc/external-dns/clients"
+	cfg.WebhookServerTLSCertFile = "/etc/external-dns/tls.crt"
+	assert.ErrorContains(t, ValidateConfig(cfg), "must be specified together")
+
+	cfg.WebhookServerTLSKeyFile = "/etc/external-dns/tls.key"
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.WebhookServerClientsFile = ""
+	assert.ErrorContains(t, ValidateConfig(cfg), "--webhook-server-clients-file")
+}
+
+func TestValidateRollbackEndpointConfig(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.RollbackEndpoint = true
+	assert.ErrorContains(t, ValidateConfig(cfg), "--history-dir")
+
+	cfg.HistoryDir = "/var/lib/external-dns/history"
+	assert.ErrorContains(t, ValidateConfig(cfg), "--rollback-endpoint-token-file")
+
+	cfg.RollbackEndpointTokenFile = "/etc/external-dns/rollback-token"
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidateACMEServerConfig(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.ACMEServer = true
+	cfg.ACMEChallengeTTL = 60
+	cfg.ACMEPropagationTimeout = time.Minute
+	cfg.ACMEPropagationInterval = time.Second
+	cfg.ACMEServerTokenFile = "/etc/external-dns/acme-token"
+	cfg.Registry = "txt"
+	cfg.TXTPrefix = "owner."
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.ACMEChallengeTTL = 0
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.ACMEChallengeTTL = 60
+	cfg.ACMEPropagationInterval = 0
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.ACMEPropagationTimeout = 0
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.ACMEServerTokenFile = ""
+	assert.ErrorContains(t, ValidateConfig(cfg), "--acme-server-token-file")
+
+	cfg.ACMEServerTokenFile = "/etc/external-dns/acme-token"
+	cfg.TXTPrefix = ""
+	assert.ErrorContains(t, ValidateConfig(cfg), "--txt-prefix or --txt-suffix")
+
+	cfg.TXTSuffix = "-owner"
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.ManagedDNSRecordTypes = []string{endpoint.RecordTypeA, endpoint.RecordTypeTXT}
+	assert.ErrorContains(t, ValidateConfig(cfg), "--managed-record-types")
+}
+
 func TestValidateBadRfc2136Config(t *testing.T) {
 	cfg := externaldns.NewConfig()
 
@@ -153,6 +244,406 @@ func TestValidateBadRfc2136Batch(t *testing.T) {
 	assert.NotNil(t, err)
 }
 
+func TestValidateBadRfc2136ClockSkew(t *testing.T) {
+	cfg := externaldns.NewConfig()
+
+	cfg.LogFormat = "json"
+	cfg.Sources = []string{"test-source"}
+	cfg.Provider = "rfc2136"
+	cfg.RFC2136BatchChangeSize = 50
+	cfg.RFC2136ClockSkew = 24 * time.Hour
+
+	assert.ErrorContains(t, ValidateConfig(cfg), "--rfc2136-clock-skew")
+
+	cfg.RFC2136ClockSkew = 10 * time.Minute
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidateRecordsSnapshot(t *testing.T) {
+	cfg := newValidConfig(t)
+
+	cfg.RecordsSnapshot = "records.json"
+	assert.ErrorContains(t, ValidateConfig(cfg), "requires --dry-run")
+
+	cfg.DryRun = true
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.RecordsSnapshotSave = "records.json"
+	assert.ErrorContains(t, ValidateConfig(cfg), "mutually exclusive")
+}
+
+func TestValidateProviderBatches(t *testing.T) {
+	cfg := newValidConfig(t)
+
+	cfg.ProviderBatchSize = 500
+	cfg.ProviderBatchInterval = 10 * time.Second
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.ProviderBatchSize = -1
+	assert.ErrorContains(t, ValidateConfig(cfg), "cannot be negative")
+}
+
+func TestValidateMinRecordDepth(t *testing.T) {
+	cfg := newValidConfig(t)
+
+	cfg.MinRecordDepth = 4
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.MinRecordDepth = -1
+	assert.ErrorContains(t, ValidateConfig(cfg), "cannot be negative")
+}
+
+func TestValidateMaxTargets(t *testing.T) {
+	cfg := newValidConfig(t)
+
+	cfg.MaxTargets = 100
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.MaxTargets = -1
+	assert.ErrorContains(t, ValidateConfig(cfg), "cannot be negative")
+}
+
+func TestValidateReadinessDampening(t *testing.T) {
+	cfg := newValidConfig(t)
+
+	cfg.ReadinessDampening = time.Minute
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.ReadinessDampening = -time.Second
+	assert.ErrorContains(t, ValidateConfig(cfg), "cannot be negative")
+}
+
+func TestValidateTargetHealthInterval(t *testing.T) {
+	cfg := newValidConfig(t)
+
+	cfg.TargetHealthURL = "http://health-checker:8080/health"
+	cfg.TargetHealthInterval = 30 * time.Second
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.TargetHealthInterval = 0
+	assert.ErrorContains(t, ValidateConfig(cfg), "must be positive")
+}
+
+func TestValidateKlipperPolicy(t *testing.T) {
+	cfg := newValidConfig(t)
+
+	cfg.KlipperPolicy = "stable-subset"
+	cfg.KlipperSubsetSize = 3
+	assert.NoError(t, ValidateConfig(cfg))
+	cfg.KlipperSubsetSize = 0
+	assert.ErrorContains(t, ValidateConfig(cfg), "--klipper-subset-size must be positive")
+
+	cfg.KlipperPolicy = "target"
+	assert.ErrorContains(t, ValidateConfig(cfg), "--klipper-target is required")
+	cfg.KlipperTarget = "traefik.example.org"
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidateQuarantineMinEndpoints(t *testing.T) {
+	cfg := newValidConfig(t)
+
+	cfg.QuarantineSources = true
+	cfg.QuarantineMinEndpoints = 0
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.QuarantineMinEndpoints = -1
+	assert.ErrorContains(t, ValidateConfig(cfg), "cannot be negative")
+}
+
+func TestValidateQuarantineMaxDuration(t *testing.T) {
+	cfg := newValidConfig(t)
+
+	cfg.QuarantineSources = true
+	cfg.QuarantineMaxDuration = time.Hour
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.QuarantineMaxDuration = -time.Second
+	assert.ErrorContains(t, ValidateConfig(cfg), "--quarantine-max-duration cannot be negative")
+}
+
+func TestValidateReadOnly(t *testing.T) {
+	cfg := newValidConfig(t)
+
+	cfg.ReadOnly = true
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.Registry = "dynamodb"
+	assert.ErrorContains(t, ValidateConfig(cfg), "--read-only is not supported with the dynamodb registry")
+
+	cfg = newValidConfig(t)
+	cfg.ReadOnly = true
+	cfg.Provider = "rfc2136"
+	cfg.RFC2136UpdateCheck = true
+	assert.ErrorContains(t, ValidateConfig(cfg), "--read-only is not supported with --rfc2136-update-check")
+
+	cfg = newValidConfig(t)
+	cfg.ReadOnly = true
+	cfg.Provider = "azure-private-dns"
+	cfg.AzurePrivateDNSCreateVNetLinks = true
+	assert.ErrorContains(t, ValidateConfig(cfg), "--read-only is not supported with --azure-private-dns-create-vnet-links")
+
+	cfg = newValidConfig(t)
+	cfg.ReadOnly = true
+	cfg.PublishedHostnamesAnnotation = true
+	assert.ErrorContains(t, ValidateConfig(cfg), "--published-hostnames-annotation is not supported")
+
+	cfg.ReadOnly = false
+	cfg.DryRun = true
+	assert.ErrorContains(t, ValidateConfig(cfg), "--published-hostnames-annotation is not supported")
+}
+
+func TestValidateChurn(t *testing.T) {
+	cfg := newValidConfig(t)
+
+	cfg.ChurnWindow = time.Hour
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.ChurnThreshold = -1
+	assert.ErrorContains(t, ValidateConfig(cfg), "--churn-threshold cannot be negative")
+
+	cfg.ChurnWindow = -time.Hour
+	assert.ErrorContains(t, ValidateConfig(cfg), "--churn-window cannot be negative")
+}
+
+func TestValidateRecordsPagination(t *testing.T) {
+	cfg := newValidConfig(t)
+
+	cfg.RecordsPagination = true
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.FullSyncInterval = time.Hour
+	assert.ErrorContains(t, ValidateConfig(cfg), "--records-pagination is not supported")
+
+	cfg.FullSyncInterval = 0
+	cfg.PublishedHostnamesAnnotation = true
+	assert.ErrorContains(t, ValidateConfig(cfg), "--records-pagination is not supported")
+}
+
+func TestValidateProviderFailureThreshold(t *testing.T) {
+	cfg := newValidConfig(t)
+
+	cfg.ProviderFailureThreshold = 5
+	assert.ErrorContains(t, ValidateConfig(cfg), "--provider-probe-backoff")
+
+	cfg.ProviderProbeBackoff = time.Minute
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.ProviderFailureThreshold = -1
+	assert.ErrorContains(t, ValidateConfig(cfg), "cannot be negative")
+}
+
+func TestValidateBadRfc2136Retries(t *testing.T) {
+	cfg := externaldns.NewConfig()
+
+	cfg.LogFormat = "json"
+	cfg.Sources = []string{"test-source"}
+	cfg.Provider = "rfc2136"
+	cfg.RFC2136BatchChangeSize = 50
+	cfg.RFC2136RetryBudget = -1
+
+	assert.ErrorContains(t, ValidateConfig(cfg), "cannot be negative")
+
+	cfg.RFC2136RetryBudget = 0
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidateRfc2136DiscoverPrimaryWithGSSTSIG(t *testing.T) {
+	cfg := externaldns.NewConfig()
+
+	cfg.LogFormat = "json"
+	cfg.Sources = []string{"test-source"}
+	cfg.Provider = "rfc2136"
+	cfg.RFC2136BatchChangeSize = 50
+	cfg.RFC2136GSSTSIG = true
+	cfg.RFC2136KerberosUsername = "user"
+	cfg.RFC2136KerberosPassword = "password"
+	cfg.RFC2136KerberosRealm = "EXAMPLE.ORG"
+	cfg.RFC2136DiscoverPrimary = true
+	cfg.RFC2136FallbackHosts = []string{"ns2.example.org"}
+
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidateRfc2136ADSite(t *testing.T) {
+	cfg := externaldns.NewConfig()
+
+	cfg.LogFormat = "json"
+	cfg.Sources = []string{"test-source"}
+	cfg.Provider = "rfc2136"
+	cfg.RFC2136BatchChangeSize = 50
+	cfg.RFC2136ADSite = "Paris"
+
+	assert.ErrorContains(t, ValidateConfig(cfg), "--rfc2136-ad-site")
+
+	cfg.RFC2136KerberosRealm = "EXAMPLE.ORG"
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.RFC2136KerberosRealm = ""
+	cfg.RFC2136ADDomain = "corp.example.org"
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidateBadRfc2136LocalAddress(t *testing.T) {
+	cfg := externaldns.NewConfig()
+
+	cfg.LogFormat = "json"
+	cfg.Sources = []string{"test-source"}
+	cfg.Provider = "rfc2136"
+	cfg.RFC2136BatchChangeSize = 50
+	cfg.RFC2136LocalAddress = "eth1"
+
+	assert.ErrorContains(t, ValidateConfig(cfg), "--rfc2136-local-address")
+
+	cfg.RFC2136LocalAddress = "10.0.0.5"
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidateBadRfc2136TSIGSecretFile(t *testing.T) {
+	cfg := externaldns.NewConfig()
+
+	cfg.LogFormat = "json"
+	cfg.Sources = []string{"test-source"}
+	cfg.Provider = "rfc2136"
+	cfg.RFC2136BatchChangeSize = 50
+	cfg.RFC2136TSIGSecret = "c2VjcmV0"
+	cfg.RFC2136TSIGSecretFile = "/etc/external-dns/tsig-secret"
+
+	err := ValidateConfig(cfg)
+
+	assert.NotNil(t, err)
+}
+
+func TestValidateVaultConfig(t *testing.T) {
+	cfg := externaldns.NewConfig()
+
+	cfg.LogFormat = "json"
+	cfg.Sources = []string{"test-source"}
+	cfg.Provider = "rfc2136"
+	cfg.RFC2136BatchChangeSize = 50
+	cfg.RFC2136TSIGSecretVaultPath = "secret/data/external-dns#tsig-secret"
+
+	assert.ErrorContains(t, ValidateConfig(cfg), "--vault-address")
+
+	cfg.VaultAddress = "https://vault:8200"
+	cfg.VaultAuthMethod = "kubernetes"
+	assert.ErrorContains(t, ValidateConfig(cfg), "--vault-kubernetes-role")
+
+	cfg.VaultKubernetesRole = "external-dns"
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.VaultAuthMethod = "approle"
+	cfg.VaultAppRoleRoleID = "role"
+	assert.ErrorContains(t, ValidateConfig(cfg), "--vault-approle-secret-id-file")
+
+	cfg.VaultAppRoleSecretIDFile = "/etc/external-dns/secret-id"
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.RFC2136TSIGSecret = "c2VjcmV0"
+	assert.ErrorContains(t, ValidateConfig(cfg), "mutually exclusive")
+}
+
+func TestValidatePassthroughRecordTypes(t *testing.T) {
+	cfg := newValidConfig(t)
+
+	cfg.PassthroughRecordTypes = []string{"FOO"}
+	assert.EqualError(t, ValidateConfig(cfg), `--passthrough-record-types "FOO" is not a record type`)
+
+	cfg.PassthroughRecordTypes = []string{"MX"}
+	assert.EqualError(t, ValidateConfig(cfg), `--passthrough-record-types "MX" is supported already and can't be passed through`)
+
+	cfg.PassthroughRecordTypes = []string{"LOC"}
+	assert.EqualError(t, ValidateConfig(cfg), `--passthrough-record-types "LOC" must be managed too, see --managed-record-types`)
+
+	cfg.ManagedDNSRecordTypes = append(cfg.ManagedDNSRecordTypes, "LOC")
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidateTargetChangeTTL(t *testing.T) {
+	cfg := newValidConfig(t)
+
+	cfg.TargetChangeTTL = time.Minute
+	cfg.TargetChangeSoak = -time.Minute
+	assert.EqualError(t, ValidateConfig(cfg), "--target-change-ttl and --target-change-soak must not be negative")
+
+	cfg.TargetChangeSoak = 10 * time.Minute
+	cfg.Registry = "noop"
+	assert.EqualError(t, ValidateConfig(cfg), "--target-change-ttl requires the txt registry to keep the steps of the records")
+
+	cfg.Registry = "txt"
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidateApexPairingTemplate(t *testing.T) {
+	cfg := newValidConfig(t)
+
+	cfg.ApexPairingTemplate = "www.{{ .Apex }}"
+	cfg.DomainFilter = []string{""}
+	assert.EqualError(t, ValidateConfig(cfg), "--apex-pairing-template requires the apexes to be given with --domain-filter")
+
+	cfg.DomainFilter = []string{"example.org"}
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidateBluecatBAMConfig(t *testing.T) {
+	cfg := externaldns.NewConfig()
+
+	cfg.LogFormat = "json"
+	cfg.Sources = []string{"test-source"}
+	cfg.Provider = "bluecat-bam"
+
+	assert.ErrorContains(t, ValidateConfig(cfg), "Address Manager host")
+
+	cfg.BluecatAddressManagerHost = "https://bam.example.com"
+	cfg.BluecatDNSConfiguration = "Example"
+	assert.ErrorContains(t, ValidateConfig(cfg), "--bluecat-dns-view")
+
+	cfg.BluecatDNSView = "Internal"
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidateUnboundConfig(t *testing.T) {
+	cfg := externaldns.NewConfig()
+
+	cfg.LogFormat = "json"
+	cfg.Sources = []string{"test-source"}
+	cfg.Provider = "unbound"
+
+	assert.ErrorContains(t, ValidateConfig(cfg), "no unbound local zone")
+
+	cfg.UnboundLocalZones = []string{"example.com"}
+	cfg.UnboundServerCertFile = "/etc/unbound/unbound_server.pem"
+	assert.ErrorContains(t, ValidateConfig(cfg), "--unbound-control-cert-file")
+
+	cfg.UnboundControlCertFile = "/etc/unbound/unbound_control.pem"
+	cfg.UnboundControlKeyFile = "/etc/unbound/unbound_control.key"
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidateInfobloxConfig(t *testing.T) {
+	cfg := externaldns.NewConfig()
+
+	cfg.LogFormat = "json"
+	cfg.Sources = []string{"test-source"}
+	cfg.Provider = "infoblox"
+	cfg.InfobloxGridHost = "grid.example.org"
+	cfg.InfobloxWapiPassword = "infoblox"
+	cfg.InfobloxDomainViews = []string{"example.org=external"}
+	cfg.InfobloxDomainNetworkViews = []string{"lab.example.org"}
+
+	assert.ErrorContains(t, ValidateConfig(cfg), "must be domain=view")
+
+	cfg.InfobloxDomainNetworkViews = []string{"lab.example.org=lab"}
+	cfg.Registry = "infoblox-ea"
+	assert.ErrorContains(t, ValidateConfig(cfg), "--infoblox-owner-ea")
+
+	cfg.InfobloxOwnerEA = "external-dns-owner"
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.Provider = "rfc2136"
+	cfg.RFC2136BatchChangeSize = 50
+	assert.ErrorContains(t, ValidateConfig(cfg), "--provider=infoblox")
+}
+
 func TestValidateGoodRfc2136Config(t *testing.T) {
 	cfg := externaldns.NewConfig()
 
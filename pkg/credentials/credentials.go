@@ -0,0 +1,167 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package credentials refreshes the secrets of the providers, e.g. API tokens or TSIG secrets,
+// while external-dns runs, so rotated secrets are picked up without restarting it.
+package credentials
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/provider"
+)
+
+// The names of the credentials shared by the sources.
+const (
+	// TSIGSecret is the TSIG secret of the rfc2136 provider
+	TSIGSecret = "tsig-secret"
+	// CloudflareAPIToken is the API token of the Cloudflare provider
+	CloudflareAPIToken = "cloudflare-api-token"
+	// PDNSAPIKey is the API key of the PowerDNS provider
+	PDNSAPIKey = "pdns-api-key"
+)
+
+// ErrRejected is wrapped by the errors of providers whose credentials were rejected.
+var ErrRejected = errors.New("credentials rejected")
+
+// Credentials are the secret values of a provider by name, e.g. "token" or "secret".
+type Credentials map[string]string
+
+// Source fetches the current credentials.
+type Source interface {
+	// Fetch returns the credentials and when they expire, the zero time if they don't.
+	Fetch(ctx context.Context) (Credentials, time.Time, error)
+}
+
+// Refresher caches the credentials of a source, and fetches them again shortly before they expire
+// or when they were rejected by the provider.
+type Refresher struct {
+	source Source
+	// margin is how long before they expire the credentials are fetched again
+	margin time.Duration
+
+	mu        sync.Mutex
+	current   Credentials
+	expiresAt time.Time
+	hooks     []func(Credentials)
+}
+
+// NewRefresher returns a refresher of the credentials of the source, fetching them again margin
+// before they expire.
+func NewRefresher(source Source, margin time.Duration) *Refresher {
+	return &Refresher{source: source, margin: margin}
+}
+
+// OnRefresh adds a hook called with the credentials every time they changed, e.g. to recreate
+// the client of a provider.
+func (r *Refresher) OnRefresh(hook func(Credentials)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hooks = append(r.hooks, hook)
+}
+
+// Get returns the credentials, fetched again if they expire. The credentials that are still
+// valid are returned when fetching them fails, so a failure of the source doesn't fail a sync.
+func (r *Refresher) Get(ctx context.Context) (Credentials, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if r.current != nil && (r.expiresAt.IsZero() || now.Before(r.expiresAt.Add(-r.margin))) {
+		return r.current, nil
+	}
+
+	credentials, expiresAt, err := r.source.Fetch(ctx)
+	if err != nil {
+		if r.current != nil && (r.expiresAt.IsZero() || now.Before(r.expiresAt)) {
+			log.Warnf("Failed to refresh the credentials, using the current ones: %v", err)
+			return r.current, nil
+		}
+		// the sync is retried, the credentials may be available by then
+		return nil, provider.NewSoftError(fmt.Errorf("failed to fetch the credentials: %w", err))
+	}
+
+	changed := !equal(r.current, credentials)
+	r.current, r.expiresAt = credentials, expiresAt
+	if changed {
+		log.Debug("Refreshed the credentials")
+		for _, hook := range r.hooks {
+			hook(credentials)
+		}
+	}
+	return credentials, nil
+}
+
+// Invalidate makes the next Get fetch the credentials again.
+func (r *Refresher) Invalidate() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.expiresAt = time.Unix(0, 0)
+}
+
+// Rejected invalidates the credentials rejected by the provider with the error, and returns a soft
+// error, so the sync is retried with fetched credentials instead of failing.
+func (r *Refresher) Rejected(err error) error {
+	r.Invalidate()
+	return provider.NewSoftError(fmt.Errorf("%w: %w", ErrRejected, err))
+}
+
+func equal(a, b Credentials) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || v != w {
+			return false
+		}
+	}
+	return true
+}
+
+// FileSource reads the credentials from files, e.g. mounted from a Kubernetes secret, which are
+// read again every ReloadInterval, so updates of the secret are picked up.
+type FileSource struct {
+	// Files are the paths of the files of the credentials by name
+	Files map[string]string
+	// ReloadInterval is the interval between the reads of the files, zero reads them only once
+	ReloadInterval time.Duration
+}
+
+// Fetch reads the files, without leading and trailing whitespace.
+func (s FileSource) Fetch(context.Context) (Credentials, time.Time, error) {
+	credentials := make(Credentials, len(s.Files))
+	for name, path := range s.Files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, time.Time{}, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		credentials[name] = strings.TrimSpace(string(data))
+	}
+
+	var expiresAt time.Time
+	if s.ReloadInterval > 0 {
+		expiresAt = time.Now().Add(s.ReloadInterval)
+	}
+	return credentials, expiresAt, nil
+}
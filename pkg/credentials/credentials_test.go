@@ -0,0 +1,97 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentials
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/provider"
+)
+
+type fakeSource struct {
+	credentials Credentials
+	expiresAt   time.Time
+	err         error
+	fetches     int
+}
+
+func (s *fakeSource) Fetch(context.Context) (Credentials, time.Time, error) {
+	s.fetches++
+	return s.credentials, s.expiresAt, s.err
+}
+
+func TestRefresher(t *testing.T) {
+	source := &fakeSource{credentials: Credentials{"token": "a"}, expiresAt: time.Now().Add(time.Hour)}
+	r := NewRefresher(source, time.Minute)
+	var refreshed []Credentials
+	r.OnRefresh(func(c Credentials) { refreshed = append(refreshed, c) })
+
+	c, err := r.Get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Credentials{"token": "a"}, c)
+
+	// the credentials are cached until they expire
+	_, err = r.Get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, source.fetches)
+
+	// rejected credentials are fetched again
+	err = r.Rejected(errors.New("bad signature"))
+	assert.ErrorIs(t, err, ErrRejected)
+	assert.ErrorIs(t, err, provider.SoftError)
+	source.credentials = Credentials{"token": "b"}
+	c, err = r.Get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Credentials{"token": "b"}, c)
+	assert.Equal(t, []Credentials{{"token": "a"}, {"token": "b"}}, refreshed)
+
+	// the credentials about to expire are fetched again, and kept while still valid if that fails
+	r.expiresAt = time.Now().Add(30 * time.Second)
+	source.err = errors.New("unavailable")
+	c, err = r.Get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Credentials{"token": "b"}, c)
+	assert.Equal(t, 3, source.fetches)
+
+	// expired credentials are not used
+	r.expiresAt = time.Now().Add(-time.Second)
+	_, err = r.Get(context.Background())
+	assert.ErrorIs(t, err, provider.SoftError)
+}
+
+func TestFileSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secret")
+	require.NoError(t, os.WriteFile(path, []byte("c2VjcmV0\n"), 0o600))
+
+	s := FileSource{Files: map[string]string{"secret": path}, ReloadInterval: time.Minute}
+	c, expiresAt, err := s.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Credentials{"secret": "c2VjcmV0"}, c)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), expiresAt, time.Second)
+
+	s = FileSource{Files: map[string]string{"secret": filepath.Join(t.TempDir(), "missing")}}
+	_, _, err = s.Fetch(context.Background())
+	assert.Error(t, err)
+}
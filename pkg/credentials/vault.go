@@ -0,0 +1,304 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentials
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/pkg/tlsutils"
+)
+
+// The auth methods of Vault supported by VaultSource.
+const (
+	VaultAuthAppRole    = "approle"
+	VaultAuthKubernetes = "kubernetes"
+)
+
+// errVaultForbidden is returned when Vault denies a request, e.g. because the token was revoked.
+var errVaultForbidden = errors.New("permission denied")
+
+// VaultServer is the Vault server VaultSource reads the secrets from.
+type VaultServer struct {
+	Address string
+	// Namespace is the Vault Enterprise namespace of the requests, the one of VAULT_NAMESPACE if
+	// empty, the root namespace if both are empty
+	Namespace string
+	// CACertFile is the PEM bundle of the CAs verifying the certificate of the server, the one of
+	// VAULT_CACERT if empty, the CAs of the system if both are empty
+	CACertFile string
+}
+
+// VaultAuth is how VaultSource logs in to Vault.
+type VaultAuth struct {
+	// Method is VaultAuthAppRole or VaultAuthKubernetes
+	Method string
+	// MountPath is the path the auth method is mounted at, the name of the method if empty
+	MountPath string
+	// RoleID and SecretIDFile are the credentials of the AppRole auth method
+	RoleID       string
+	SecretIDFile string
+	// Role and TokenFile, the service account token, are the credentials of the Kubernetes auth method
+	Role      string
+	TokenFile string
+}
+
+// VaultSource reads the credentials from secrets of Vault. It logs in with AppRole or
+// Kubernetes auth, renews its token while it is renewable, and logs in again otherwise. The
+// credentials expire with the first lease of the token or the secrets, or after the reload
+// interval, so the secrets rotated in Vault are picked up.
+type VaultSource struct {
+	address   string
+	namespace string
+	auth      VaultAuth
+	// secrets are the references of the credentials by name, the path of a secret and its key,
+	// e.g. "secret/data/external-dns#token"
+	secrets        map[string]string
+	reloadInterval time.Duration
+	client         *http.Client
+
+	mu             sync.Mutex
+	token          string
+	tokenIssuedAt  time.Time
+	tokenLease     time.Duration
+	tokenRenewable bool
+}
+
+// NewVaultSource returns a source reading the secrets from the Vault server. The secrets are
+// referenced by the names of the credentials, as the path of a secret and its key separated by #,
+// the key being the name of the credentials if omitted.
+//
+// Returns an error if the CA bundle of the server can't be read.
+func NewVaultSource(server VaultServer, auth VaultAuth, secrets map[string]string, reloadInterval time.Duration) (*VaultSource, error) {
+	if auth.MountPath == "" {
+		auth.MountPath = auth.Method
+	}
+	if server.Namespace == "" {
+		server.Namespace = os.Getenv("VAULT_NAMESPACE")
+	}
+	if server.CACertFile == "" {
+		server.CACertFile = os.Getenv("VAULT_CACERT")
+	}
+	tlsConfig, err := tlsutils.NewTLSConfig("", "", server.CACertFile, "", false, tls.VersionTLS12)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the CA bundle of Vault: %w", err)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &VaultSource{
+		address:        strings.TrimSuffix(server.Address, "/"),
+		namespace:      strings.Trim(server.Namespace, "/"),
+		auth:           auth,
+		secrets:        secrets,
+		reloadInterval: reloadInterval,
+		client:         &http.Client{Timeout: 30 * time.Second, Transport: transport},
+	}, nil
+}
+
+type vaultResponse struct {
+	Auth *struct {
+		ClientToken   string `json:"client_token"`
+		LeaseDuration int    `json:"lease_duration"`
+		Renewable     bool   `json:"renewable"`
+	} `json:"auth"`
+	LeaseDuration int                    `json:"lease_duration"`
+	Data          map[string]interface{} `json:"data"`
+}
+
+// Fetch reads the secrets, logging in or renewing the token first if needed.
+func (s *VaultSource) Fetch(ctx context.Context) (Credentials, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if err := s.ensureToken(ctx, now); err != nil {
+		return nil, time.Time{}, err
+	}
+
+	expiresAt := now.Add(s.tokenLease)
+	if s.tokenLease == 0 {
+		expiresAt = time.Time{}
+	}
+	if s.reloadInterval > 0 {
+		expiresAt = earliest(expiresAt, now.Add(s.reloadInterval))
+	}
+
+	credentials := make(Credentials, len(s.secrets))
+	for name, ref := range s.secrets {
+		path, key, _ := strings.Cut(ref, "#")
+		if key == "" {
+			key = name
+		}
+		value, lease, err := s.read(ctx, path, key)
+		if err != nil {
+			if errors.Is(err, errVaultForbidden) {
+				// the token may have been revoked, the next fetch logs in again
+				s.token = ""
+			}
+			return nil, time.Time{}, fmt.Errorf("failed to read %s from Vault: %w", name, err)
+		}
+		credentials[name] = value
+		if lease > 0 {
+			expiresAt = earliest(expiresAt, now.Add(lease))
+		}
+	}
+	return credentials, expiresAt, nil
+}
+
+// ensureToken keeps the token valid for more than half its lease, renewing it if possible.
+func (s *VaultSource) ensureToken(ctx context.Context, now time.Time) error {
+	if s.token != "" && (s.tokenLease == 0 || now.Before(s.tokenIssuedAt.Add(s.tokenLease/2))) {
+		return nil
+	}
+	if s.token != "" && s.tokenRenewable && now.Before(s.tokenIssuedAt.Add(s.tokenLease)) {
+		err := s.authenticate(ctx, http.MethodPost, "auth/token/renew-self", nil, now)
+		if err == nil {
+			return nil
+		}
+		log.Warnf("Failed to renew the Vault token, logging in again: %v", err)
+	}
+	return s.login(ctx, now)
+}
+
+func (s *VaultSource) login(ctx context.Context, now time.Time) error {
+	s.token = ""
+	var body map[string]string
+	switch s.auth.Method {
+	case VaultAuthAppRole:
+		secretID, err := os.ReadFile(s.auth.SecretIDFile)
+		if err != nil {
+			return fmt.Errorf("failed to read the AppRole secret ID: %w", err)
+		}
+		body = map[string]string{"role_id": s.auth.RoleID, "secret_id": strings.TrimSpace(string(secretID))}
+	case VaultAuthKubernetes:
+		jwt, err := os.ReadFile(s.auth.TokenFile)
+		if err != nil {
+			return fmt.Errorf("failed to read the service account token: %w", err)
+		}
+		body = map[string]string{"role": s.auth.Role, "jwt": strings.TrimSpace(string(jwt))}
+	default:
+		return fmt.Errorf("unsupported Vault auth method %q", s.auth.Method)
+	}
+	if err := s.authenticate(ctx, http.MethodPost, "auth/"+s.auth.MountPath+"/login", body, now); err != nil {
+		return fmt.Errorf("failed to log in to Vault: %w", err)
+	}
+	log.Debugf("Logged in to Vault with %s auth", s.auth.Method)
+	return nil
+}
+
+// authenticate sends a request returning a token, and keeps the token.
+func (s *VaultSource) authenticate(ctx context.Context, method, path string, body interface{}, now time.Time) error {
+	resp, err := s.do(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	if resp.Auth == nil || resp.Auth.ClientToken == "" {
+		return errors.New("no token in the response")
+	}
+	s.token = resp.Auth.ClientToken
+	s.tokenIssuedAt = now
+	s.tokenLease = time.Duration(resp.Auth.LeaseDuration) * time.Second
+	s.tokenRenewable = resp.Auth.Renewable
+	return nil
+}
+
+// read returns the value of the key of a secret and the lease of the secret. The secrets of the
+// KV version 2 engine nest their values in the field data of the response.
+func (s *VaultSource) read(ctx context.Context, path, key string) (string, time.Duration, error) {
+	resp, err := s.do(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return "", 0, err
+	}
+	data := resp.Data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, versioned := data["metadata"]; versioned {
+			data = nested
+		}
+	}
+	value, ok := data[key].(string)
+	if !ok {
+		return "", 0, fmt.Errorf("no key %q in the secret %s", key, path)
+	}
+	return value, time.Duration(resp.LeaseDuration) * time.Second, nil
+}
+
+func (s *VaultSource) do(ctx context.Context, method, path string, body interface{}) (*vaultResponse, error) {
+	var reader io.Reader = http.NoBody
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.address+"/v1/"+strings.TrimPrefix(path, "/"), reader)
+	if err != nil {
+		return nil, err
+	}
+	if s.token != "" {
+		req.Header.Set("X-Vault-Token", s.token)
+	}
+	if s.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", s.namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode == http.StatusForbidden:
+		return nil, errVaultForbidden
+	case res.StatusCode >= 300:
+		var e struct {
+			Errors []string `json:"errors"`
+		}
+		_ = json.NewDecoder(res.Body).Decode(&e)
+		return nil, fmt.Errorf("%s %s: %s %s", method, path, res.Status, strings.Join(e.Errors, ", "))
+	}
+
+	var resp vaultResponse
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("failed to decode the response of %s: %w", path, err)
+	}
+	return &resp, nil
+}
+
+// earliest returns the earliest of the times, the zero time meaning never.
+func earliest(a, b time.Time) time.Time {
+	if a.IsZero() || (!b.IsZero() && b.Before(a)) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentials
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeVault serves the login, renewal and secrets endpoints of Vault used by VaultSource.
+type fakeVault struct {
+	logins     []map[string]string
+	renewals   int
+	revoked    bool
+	namespaces []string
+}
+
+func (v *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	v.namespaces = append(v.namespaces, r.Header.Get("X-Vault-Namespace"))
+	auth := func(token string) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"auth": map[string]interface{}{"client_token": token, "lease_duration": 3600, "renewable": true},
+		})
+	}
+	switch r.URL.Path {
+	case "/v1/auth/approle/login", "/v1/auth/k8s/login":
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		v.logins = append(v.logins, body)
+		v.revoked = false
+		auth("token")
+		return
+	case "/v1/auth/token/renew-self":
+		v.renewals++
+		auth("token")
+		return
+	}
+
+	if r.Header.Get("X-Vault-Token") != "token" || v.revoked {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+		return
+	}
+	switch r.URL.Path {
+	case "/v1/secret/data/dns":
+		_, _ = w.Write([]byte(`{"data":{"data":{"tsig-secret":"c2VjcmV0","token":"abc"},"metadata":{"version":2}}}`))
+	case "/v1/kv/pdns":
+		_, _ = w.Write([]byte(`{"lease_duration":600,"data":{"api-key":"key"}}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"errors":[]}`))
+	}
+}
+
+func TestVaultSource(t *testing.T) {
+	vault := &fakeVault{}
+	server := httptest.NewServer(vault)
+	defer server.Close()
+
+	secretID := filepath.Join(t.TempDir(), "secret-id")
+	require.NoError(t, os.WriteFile(secretID, []byte("s3cr3t\n"), 0o600))
+
+	s, err := NewVaultSource(VaultServer{Address: server.URL}, VaultAuth{Method: VaultAuthAppRole, RoleID: "role", SecretIDFile: secretID}, map[string]string{
+		TSIGSecret:         "secret/data/dns",
+		CloudflareAPIToken: "secret/data/dns#token",
+		PDNSAPIKey:         "kv/pdns#api-key",
+	}, time.Hour)
+	require.NoError(t, err)
+
+	c, expiresAt, err := s.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Credentials{TSIGSecret: "c2VjcmV0", CloudflareAPIToken: "abc", PDNSAPIKey: "key"}, c)
+	// the lease of the PowerDNS secret expires first
+	assert.WithinDuration(t, time.Now().Add(10*time.Minute), expiresAt, time.Second)
+	assert.Equal(t, []map[string]string{{"role_id": "role", "secret_id": "s3cr3t"}}, vault.logins)
+
+	// the token is kept for half its lease, renewed afterwards
+	_, _, err = s.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, vault.logins, 1)
+	s.tokenIssuedAt = time.Now().Add(-45 * time.Minute)
+	_, _, err = s.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, vault.renewals)
+	assert.Len(t, vault.logins, 1)
+
+	// a revoked token is replaced by logging in again on the next fetch
+	vault.revoked = true
+	_, _, err = s.Fetch(context.Background())
+	assert.ErrorIs(t, err, errVaultForbidden)
+	_, _, err = s.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, vault.logins, 2)
+}
+
+func TestVaultSourceKubernetesAuth(t *testing.T) {
+	vault := &fakeVault{}
+	server := httptest.NewServer(vault)
+	defer server.Close()
+
+	jwt := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(jwt, []byte("jwt"), 0o600))
+
+	s, err := NewVaultSource(VaultServer{Address: server.URL}, VaultAuth{Method: VaultAuthKubernetes, MountPath: "k8s", Role: "external-dns", TokenFile: jwt}, map[string]string{
+		TSIGSecret: "secret/data/dns",
+	}, 0)
+	require.NoError(t, err)
+
+	c, expiresAt, err := s.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Credentials{TSIGSecret: "c2VjcmV0"}, c)
+	// the token expires first
+	assert.WithinDuration(t, time.Now().Add(time.Hour), expiresAt, time.Second)
+	assert.Equal(t, []map[string]string{{"role": "external-dns", "jwt": "jwt"}}, vault.logins)
+
+	s, err = NewVaultSource(VaultServer{Address: server.URL}, VaultAuth{Method: VaultAuthKubernetes, MountPath: "k8s", Role: "external-dns", TokenFile: jwt}, map[string]string{
+		TSIGSecret: "secret/data/missing",
+	}, 0)
+	require.NoError(t, err)
+	_, _, err = s.Fetch(context.Background())
+	assert.ErrorContains(t, err, "404")
+}
+
+func TestVaultSourceTLSAndNamespace(t *testing.T) {
+	vault := &fakeVault{}
+	server := httptest.NewTLSServer(vault)
+	defer server.Close()
+
+	jwt := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(jwt, []byte("jwt"), 0o600))
+	auth := VaultAuth{Method: VaultAuthKubernetes, MountPath: "k8s", Role: "external-dns", TokenFile: jwt}
+	secrets := map[string]string{TSIGSecret: "secret/data/dns"}
+
+	// the certificate of the server isn't trusted without its CA
+	s, err := NewVaultSource(VaultServer{Address: server.URL}, auth, secrets, 0)
+	require.NoError(t, err)
+	_, _, err = s.Fetch(context.Background())
+	assert.ErrorContains(t, err, "certificate")
+
+	caCert := filepath.Join(t.TempDir(), "ca.crt")
+	require.NoError(t, os.WriteFile(caCert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600))
+	t.Setenv("VAULT_CACERT", caCert)
+	t.Setenv("VAULT_NAMESPACE", "ignored")
+	s, err = NewVaultSource(VaultServer{Address: server.URL, Namespace: "/team-a/dns/"}, auth, secrets, 0)
+	require.NoError(t, err)
+	c, _, err := s.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Credentials{TSIGSecret: "c2VjcmV0"}, c)
+	assert.Equal(t, []string{"team-a/dns", "team-a/dns"}, vault.namespaces)
+
+	_, err = NewVaultSource(VaultServer{Address: server.URL, CACertFile: filepath.Join(t.TempDir(), "missing.crt")}, auth, secrets, 0)
+	assert.ErrorContains(t, err, "CA bundle")
+}
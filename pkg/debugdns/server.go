@@ -0,0 +1,125 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package debugdns serves the desired state of the records over DNS, so operators can query
+// what external-dns wants to exist, e.g. with `dig @<pod ip> -p 5353 app.example.org`.
+package debugdns
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/miekg/dns"
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// Lookup returns the desired endpoints of a DNS name.
+type Lookup interface {
+	Lookup(name string) []*endpoint.Endpoint
+}
+
+// Handler answers DNS queries from the desired endpoints. Names without endpoints are answered
+// with NXDOMAIN, like an authoritative server. Updates and zone transfers are refused.
+type Handler struct {
+	lookup Lookup
+}
+
+// NewHandler returns a handler answering from the endpoints returned by lookup.
+func NewHandler(lookup Lookup) *Handler {
+	return &Handler{lookup: lookup}
+}
+
+// ServeDNS answers a single query.
+func (h *Handler) ServeDNS(w dns.ResponseWriter, req *dns.Msg) {
+	m := new(dns.Msg)
+	m.SetReply(req)
+	m.Authoritative = true
+
+	if req.Opcode != dns.OpcodeQuery || len(req.Question) != 1 {
+		m.Rcode = dns.RcodeRefused
+	} else if q := req.Question[0]; q.Qtype == dns.TypeAXFR || q.Qtype == dns.TypeIXFR {
+		m.Rcode = dns.RcodeRefused
+	} else {
+		h.answer(m, q)
+	}
+
+	if err := w.WriteMsg(m); err != nil {
+		log.Debugf("Failed to write debug DNS response: %v", err)
+	}
+}
+
+func (h *Handler) answer(m *dns.Msg, q dns.Question) {
+	endpoints := h.lookup.Lookup(q.Name)
+	if len(endpoints) == 0 {
+		m.Rcode = dns.RcodeNameError
+		return
+	}
+
+	for _, e := range endpoints {
+		recordType := dns.StringToType[e.RecordType]
+		// a CNAME answers queries of any type, like in the zone
+		if q.Qtype != dns.TypeANY && q.Qtype != recordType && recordType != dns.TypeCNAME {
+			continue
+		}
+		rrs, err := records(q.Name, e)
+		if err != nil {
+			log.Debugf("Skipping endpoint %s in debug DNS response: %v", e, err)
+			continue
+		}
+		m.Answer = append(m.Answer, rrs...)
+	}
+}
+
+// records returns the resource records of the targets of the endpoint. Records without a
+// configured TTL have a TTL of zero.
+func records(name string, e *endpoint.Endpoint) ([]dns.RR, error) {
+	var ttl int64
+	if e.RecordTTL.IsConfigured() {
+		ttl = int64(e.RecordTTL)
+	}
+
+	rrs := make([]dns.RR, 0, len(e.Targets))
+	for _, target := range e.Targets {
+		if e.RecordType == endpoint.RecordTypeTXT {
+			target = strconv.Quote(target)
+		}
+		rr, err := dns.NewRR(fmt.Sprintf("%s %d IN %s %s", name, ttl, e.RecordType, target))
+		if err != nil {
+			return nil, fmt.Errorf("failed to build RR: %w", err)
+		}
+		if rr == nil {
+			continue
+		}
+		rrs = append(rrs, rr)
+	}
+	return rrs, nil
+}
+
+// ListenAndServe serves the desired endpoints on the address over both UDP and TCP, until one of
+// the servers fails.
+func ListenAndServe(address string, lookup Lookup) error {
+	handler := NewHandler(lookup)
+	errs := make(chan error, 2)
+	for _, network := range []string{"udp", "tcp"} {
+		server := &dns.Server{Addr: address, Net: network, Handler: handler}
+		go func() {
+			errs <- server.ListenAndServe()
+		}()
+	}
+	return <-errs
+}
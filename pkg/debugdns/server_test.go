@@ -0,0 +1,127 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package debugdns
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+type lookupStub map[string][]*endpoint.Endpoint
+
+func (l lookupStub) Lookup(name string) []*endpoint.Endpoint {
+	return l[strings.TrimSuffix(name, ".")]
+}
+
+// responseRecorder records the response of the handler.
+type responseRecorder struct {
+	dns.ResponseWriter
+	msg *dns.Msg
+}
+
+func (r *responseRecorder) WriteMsg(m *dns.Msg) error {
+	r.msg = m
+	return nil
+}
+
+func (r *responseRecorder) RemoteAddr() net.Addr {
+	return &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}
+}
+
+func TestHandlerServeDNS(t *testing.T) {
+	lookup := lookupStub{
+		"a.example.org": {
+			endpoint.NewEndpointWithTTL("a.example.org", endpoint.RecordTypeA, 300, "1.1.1.1", "1.1.1.2"),
+			endpoint.NewEndpoint("a.example.org", endpoint.RecordTypeTXT, "heritage=external-dns,external-dns/owner=default"),
+		},
+		"cname.example.org": {
+			endpoint.NewEndpoint("cname.example.org", endpoint.RecordTypeCNAME, "lb.example.com"),
+		},
+	}
+
+	for _, tc := range []struct {
+		name          string
+		qname         string
+		qtype         uint16
+		expectedRcode int
+		expected      []string
+	}{
+		{
+			name:          "address records",
+			qname:         "a.example.org.",
+			qtype:         dns.TypeA,
+			expectedRcode: dns.RcodeSuccess,
+			expected:      []string{"a.example.org.\t300\tIN\tA\t1.1.1.1", "a.example.org.\t300\tIN\tA\t1.1.1.2"},
+		},
+		{
+			name:          "txt record",
+			qname:         "a.example.org.",
+			qtype:         dns.TypeTXT,
+			expectedRcode: dns.RcodeSuccess,
+			expected:      []string{"a.example.org.\t0\tIN\tTXT\t\"heritage=external-dns,external-dns/owner=default\""},
+		},
+		{
+			name:          "other type of an existing name",
+			qname:         "a.example.org.",
+			qtype:         dns.TypeAAAA,
+			expectedRcode: dns.RcodeSuccess,
+		},
+		{
+			name:          "cname",
+			qname:         "cname.example.org.",
+			qtype:         dns.TypeA,
+			expectedRcode: dns.RcodeSuccess,
+			expected:      []string{"cname.example.org.\t0\tIN\tCNAME\tlb.example.com."},
+		},
+		{
+			name:          "unknown name",
+			qname:         "unknown.example.org.",
+			qtype:         dns.TypeA,
+			expectedRcode: dns.RcodeNameError,
+		},
+		{
+			name:          "zone transfer",
+			qname:         "example.org.",
+			qtype:         dns.TypeAXFR,
+			expectedRcode: dns.RcodeRefused,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := new(dns.Msg)
+			req.SetQuestion(tc.qname, tc.qtype)
+			w := &responseRecorder{}
+
+			NewHandler(lookup).ServeDNS(w, req)
+
+			require.NotNil(t, w.msg)
+			assert.Equal(t, tc.expectedRcode, w.msg.Rcode)
+			assert.True(t, w.msg.Authoritative)
+			var answers []string
+			for _, rr := range w.msg.Answer {
+				answers = append(answers, rr.String())
+			}
+			assert.Equal(t, tc.expected, answers)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plan
+
+// Len returns the number of changes, an update counting once.
+func (c *Changes) Len() int {
+	return len(c.Create) + len(c.UpdateNew) + len(c.Delete)
+}
+
+// Batches splits the changes into batches of at most size changes, an update counting once. The
+// changes of a DNS name are kept in the same batch, so e.g. a record replacing a record of another
+// type is created along with the deletion of the replaced one, the batch exceeding the size if the
+// name has more changes. A size below 1 returns the changes in a single batch.
+func (c *Changes) Batches(size int) []*Changes {
+	if size < 1 || c.Len() <= size {
+		return []*Changes{c}
+	}
+
+	var names []string
+	byName := map[string]*Changes{}
+	changesOf := func(name string) *Changes {
+		if _, ok := byName[name]; !ok {
+			names = append(names, name)
+			byName[name] = &Changes{}
+		}
+		return byName[name]
+	}
+	for _, e := range c.Create {
+		changes := changesOf(e.DNSName)
+		changes.Create = append(changes.Create, e)
+	}
+	for _, e := range c.UpdateOld {
+		changes := changesOf(e.DNSName)
+		changes.UpdateOld = append(changes.UpdateOld, e)
+	}
+	for _, e := range c.UpdateNew {
+		changes := changesOf(e.DNSName)
+		changes.UpdateNew = append(changes.UpdateNew, e)
+	}
+	for _, e := range c.Delete {
+		changes := changesOf(e.DNSName)
+		changes.Delete = append(changes.Delete, e)
+	}
+
+	var batches []*Changes
+	batch := &Changes{}
+	for _, name := range names {
+		changes := byName[name]
+		if batch.Len() > 0 && batch.Len()+changes.Len() > size {
+			batches = append(batches, batch)
+			batch = &Changes{}
+		}
+		batch.Create = append(batch.Create, changes.Create...)
+		batch.UpdateOld = append(batch.UpdateOld, changes.UpdateOld...)
+		batch.UpdateNew = append(batch.UpdateNew, changes.UpdateNew...)
+		batch.Delete = append(batch.Delete, changes.Delete...)
+	}
+	return append(batches, batch)
+}
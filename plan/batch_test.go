@@ -0,0 +1,57 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func TestChangesBatches(t *testing.T) {
+	a := func(name string) *endpoint.Endpoint {
+		return endpoint.NewEndpoint(name, endpoint.RecordTypeA, "1.2.3.4")
+	}
+	changes := &Changes{
+		Create:    []*endpoint.Endpoint{a("one.example.org"), a("two.example.org"), endpoint.NewEndpoint("three.example.org", endpoint.RecordTypeCNAME, "example.com")},
+		UpdateOld: []*endpoint.Endpoint{a("four.example.org")},
+		UpdateNew: []*endpoint.Endpoint{a("four.example.org")},
+		Delete:    []*endpoint.Endpoint{a("three.example.org"), a("five.example.org")},
+	}
+	assert.Equal(t, 6, changes.Len())
+
+	// the changes fit in a batch
+	assert.Equal(t, []*Changes{changes}, changes.Batches(0))
+	assert.Equal(t, []*Changes{changes}, changes.Batches(6))
+
+	batches := changes.Batches(2)
+	require.Len(t, batches, 3)
+	assert.Equal(t, []*endpoint.Endpoint{a("one.example.org"), a("two.example.org")}, batches[0].Create)
+	// the replacement of the A record of three.example.org by a CNAME record is in a single batch
+	assert.Equal(t, "three.example.org", batches[1].Create[0].DNSName)
+	assert.Equal(t, "three.example.org", batches[1].Delete[0].DNSName)
+	assert.Empty(t, batches[1].UpdateNew)
+	assert.Equal(t, &Changes{UpdateOld: []*endpoint.Endpoint{a("four.example.org")}, UpdateNew: []*endpoint.Endpoint{a("four.example.org")}, Delete: []*endpoint.Endpoint{a("five.example.org")}}, batches[2])
+
+	// the changes of a name exceed the size of a batch rather than being split
+	batches = changes.Batches(1)
+	require.Len(t, batches, 5)
+	assert.Equal(t, 2, batches[2].Len())
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plan
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// The actions of the changes.
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// Change is a single operation of the changes.
+type Change struct {
+	// ID is the same for the same change planned by different runs, to correlate them.
+	ID     string
+	Action string
+	// Old is the current record of an update or a deletion.
+	Old *endpoint.Endpoint
+	// New is the desired record of a creation or an update.
+	New *endpoint.Endpoint
+}
+
+// NewChange returns the change of the action from the old to the new record, with its ID.
+func NewChange(action string, old, new *endpoint.Endpoint) Change {
+	return Change{ID: ChangeID(action, old, new), Action: action, Old: old, New: new}
+}
+
+// Record returns the record the change applies to: the desired record, or the current record of a
+// deletion.
+func (c Change) Record() *endpoint.Endpoint {
+	if c.New != nil {
+		return c.New
+	}
+	return c.Old
+}
+
+func (c Change) String() string {
+	e := c.Record()
+	if e.SetIdentifier != "" {
+		return fmt.Sprintf("%s %s %s (%s) %s", c.Action, e.RecordType, e.DNSName, e.SetIdentifier, e.Targets)
+	}
+	return fmt.Sprintf("%s %s %s %s", c.Action, e.RecordType, e.DNSName, e.Targets)
+}
+
+// ChangeID returns the ID of the change of the action from the old to the new record: a hash of
+// the action and of the names, types, set identifiers, TTLs, targets and provider specific
+// properties of the records. The labels, e.g. the resource of the records, aren't part of it.
+func ChangeID(action string, old, new *endpoint.Endpoint) string {
+	h := sha256.New()
+	h.Write([]byte(action))
+	for _, e := range []*endpoint.Endpoint{old, new} {
+		if e == nil {
+			h.Write([]byte{0})
+			continue
+		}
+		targets := slices.Clone(e.Targets)
+		slices.Sort(targets)
+		properties := make([]string, 0, len(e.ProviderSpecific))
+		for _, p := range e.ProviderSpecific {
+			properties = append(properties, p.Name+"="+p.Value)
+		}
+		slices.Sort(properties)
+		fmt.Fprintf(h, "\x00%s\x00%s\x00%s\x00%d\x00%s\x00%s", e.DNSName, e.RecordType, e.SetIdentifier, e.RecordTTL, strings.Join(targets, ","), strings.Join(properties, ","))
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// List returns the operations of the changes with their IDs: the creations, the updates and the
+// deletions.
+func (c *Changes) List() []Change {
+	changes := make([]Change, 0, c.Len())
+	for _, e := range c.Create {
+		changes = append(changes, NewChange(ActionCreate, nil, e))
+	}
+	for i, e := range c.UpdateNew {
+		var old *endpoint.Endpoint
+		if i < len(c.UpdateOld) {
+			old = c.UpdateOld[i]
+		}
+		changes = append(changes, NewChange(ActionUpdate, old, e))
+	}
+	for _, e := range c.Delete {
+		changes = append(changes, NewChange(ActionDelete, e, nil))
+	}
+	return changes
+}
+
+// compareRecords orders the records by DNS name, type and set identifier.
+func compareRecords(a, b *endpoint.Endpoint) int {
+	if c := cmp.Compare(a.DNSName, b.DNSName); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.RecordType, b.RecordType); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.SetIdentifier, b.SetIdentifier)
+}
+
+// sort orders every kind of changes by the DNS names, types and set identifiers of their records,
+// so the same changes are planned in the same order by every run. The current records of the
+// updates are kept at the index of their desired record.
+func (c *Changes) sort() {
+	slices.SortStableFunc(c.Create, compareRecords)
+	slices.SortStableFunc(c.Delete, compareRecords)
+	if len(c.UpdateOld) != len(c.UpdateNew) {
+		slices.SortStableFunc(c.UpdateOld, compareRecords)
+		slices.SortStableFunc(c.UpdateNew, compareRecords)
+		return
+	}
+	order := make([]int, len(c.UpdateNew))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(i, j int) int {
+		return compareRecords(c.UpdateNew[i], c.UpdateNew[j])
+	})
+	updateOld := make([]*endpoint.Endpoint, len(order))
+	updateNew := make([]*endpoint.Endpoint, len(order))
+	for i, j := range order {
+		updateOld[i], updateNew[i] = c.UpdateOld[j], c.UpdateNew[j]
+	}
+	c.UpdateOld, c.UpdateNew = updateOld, updateNew
+}
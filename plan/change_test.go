@@ -0,0 +1,89 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plan
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func TestChangeID(t *testing.T) {
+	record := endpoint.NewEndpointWithTTL("app.example.org", endpoint.RecordTypeA, 300, "1.1.1.1", "2.2.2.2")
+	id := ChangeID(ActionCreate, nil, record)
+	assert.Len(t, id, 16)
+
+	// the order of the targets and the labels don't change the ID
+	same := endpoint.NewEndpointWithTTL("app.example.org", endpoint.RecordTypeA, 300, "2.2.2.2", "1.1.1.1")
+	same.Labels[endpoint.ResourceLabelKey] = "service/default/app"
+	assert.Equal(t, id, ChangeID(ActionCreate, nil, same))
+
+	// the action and the records do
+	assert.NotEqual(t, id, ChangeID(ActionDelete, record, nil))
+	assert.NotEqual(t, id, ChangeID(ActionCreate, nil, endpoint.NewEndpointWithTTL("app.example.org", endpoint.RecordTypeA, 60, "1.1.1.1", "2.2.2.2")))
+	assert.NotEqual(t, id, ChangeID(ActionCreate, nil, record.WithSetIdentifier("eu")))
+	assert.NotEqual(t, id, ChangeID(ActionCreate, nil, endpoint.NewEndpointWithTTL("app.example.org", endpoint.RecordTypeA, 300, "1.1.1.1", "2.2.2.2").WithProviderSpecific("alias", "true")))
+}
+
+func TestChangesList(t *testing.T) {
+	create := endpoint.NewEndpoint("new.example.org", endpoint.RecordTypeA, "1.1.1.1")
+	old := endpoint.NewEndpoint("app.example.org", endpoint.RecordTypeA, "1.1.1.1")
+	updated := endpoint.NewEndpoint("app.example.org", endpoint.RecordTypeA, "2.2.2.2")
+	deleted := endpoint.NewEndpoint("old.example.org", endpoint.RecordTypeCNAME, "example.com").WithSetIdentifier("eu")
+
+	changes := (&Changes{Create: []*endpoint.Endpoint{create}, UpdateOld: []*endpoint.Endpoint{old}, UpdateNew: []*endpoint.Endpoint{updated}, Delete: []*endpoint.Endpoint{deleted}}).List()
+	require.Len(t, changes, 3)
+	assert.Equal(t, NewChange(ActionCreate, nil, create), changes[0])
+	assert.Equal(t, "create A new.example.org 1.1.1.1", changes[0].String())
+	assert.Same(t, old, changes[1].Old)
+	assert.Same(t, updated, changes[1].Record())
+	assert.Equal(t, ChangeID(ActionUpdate, old, updated), changes[1].ID)
+	assert.Same(t, deleted, changes[2].Record())
+	assert.Equal(t, "delete CNAME old.example.org (eu) example.com", changes[2].String())
+}
+
+func TestCalculateOrdersChanges(t *testing.T) {
+	var desired, current []*endpoint.Endpoint
+	for i := 0; i < 20; i++ {
+		name := fmt.Sprintf("app-%02d.example.org", i)
+		desired = append(desired, endpoint.NewEndpoint("new-"+name, endpoint.RecordTypeA, "1.1.1.1"))
+		desired = append(desired, endpoint.NewEndpoint(name, endpoint.RecordTypeA, "2.2.2.2"))
+		current = append(current, endpoint.NewEndpoint(name, endpoint.RecordTypeA, "1.1.1.1"))
+		current = append(current, endpoint.NewEndpoint("old-"+name, endpoint.RecordTypeA, "1.1.1.1"))
+	}
+
+	var first []Change
+	for run := 0; run < 5; run++ {
+		p := &Plan{Policies: []Policy{&SyncPolicy{}}, Current: current, Desired: desired, ManagedRecords: []string{endpoint.RecordTypeA}}
+		changes := p.Calculate().Changes.List()
+		require.Len(t, changes, 60)
+		if first == nil {
+			first = changes
+			continue
+		}
+		// every run plans the changes in the same order with the same IDs
+		assert.Equal(t, first, changes)
+	}
+	assert.Equal(t, "new-app-00.example.org", first[0].Record().DNSName)
+	assert.Equal(t, "app-00.example.org", first[20].Record().DNSName)
+	assert.Equal(t, "1.1.1.1", first[20].Old.Targets[0])
+	assert.Equal(t, "old-app-19.example.org", first[59].Record().DNSName)
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plan
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+)
+
+var deferredChanges = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: "external_dns",
+		Subsystem: "controller",
+		Name:      "change_window_deferred_changes",
+		Help:      "Number of changes deferred until the change window of their domain opens.",
+	},
+	[]string{"domain"},
+)
+
+func init() {
+	prometheus.MustRegister(deferredChanges)
+}
+
+// ChangeWindow allows the changes of the records of a domain and its subdomains only between Start and
+// End every day, both offsets from midnight UTC. A window with End before Start spans midnight.
+type ChangeWindow struct {
+	Domain string
+	Start  time.Duration
+	End    time.Duration
+}
+
+// ParseChangeWindow parses a window given as a domain and the times in UTC it opens and closes, e.g.
+// "prod.example.com=02:00-04:00". A leading "*." of the domain is ignored.
+func ParseChangeWindow(value string) (ChangeWindow, error) {
+	domain, times, ok := strings.Cut(value, "=")
+	start, end, ok2 := strings.Cut(times, "-")
+	domain = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(domain), "*."), ".")
+	if !ok || !ok2 || domain == "" {
+		return ChangeWindow{}, fmt.Errorf("invalid change window %q: expected <domain>=<HH:MM>-<HH:MM>", value)
+	}
+
+	w := ChangeWindow{Domain: strings.ToLower(domain)}
+	var err error
+	if w.Start, err = parseTimeOfDay(start); err != nil {
+		return ChangeWindow{}, fmt.Errorf("invalid change window %q: %w", value, err)
+	}
+	if w.End, err = parseTimeOfDay(end); err != nil {
+		return ChangeWindow{}, fmt.Errorf("invalid change window %q: %w", value, err)
+	}
+	if w.Start == w.End {
+		return ChangeWindow{}, fmt.Errorf("invalid change window %q: it opens and closes at the same time", value)
+	}
+	return w, nil
+}
+
+func parseTimeOfDay(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q", value)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// matches returns whether the DNS name is the domain of the window or one of its subdomains.
+func (w ChangeWindow) matches(dnsName string) bool {
+	dnsName = strings.ToLower(strings.TrimSuffix(dnsName, "."))
+	return dnsName == w.Domain || strings.HasSuffix(dnsName, "."+w.Domain)
+}
+
+func (w ChangeWindow) open(now time.Time) bool {
+	now = now.UTC()
+	offset := now.Sub(now.Truncate(24 * time.Hour))
+	if w.Start < w.End {
+		return w.Start <= offset && offset < w.End
+	}
+	return offset >= w.Start || offset < w.End
+}
+
+// opensAt returns the next time the window opens after now.
+func (w ChangeWindow) opensAt(now time.Time) time.Time {
+	start := now.UTC().Truncate(24 * time.Hour).Add(w.Start)
+	if !start.After(now) {
+		start = start.Add(24 * time.Hour)
+	}
+	return start
+}
+
+// ChangeWindowPolicy defers the changes of the records of the domains with a change window until it
+// opens. The changes of the records of other domains are applied right away. The records of a domain
+// with several windows follow the window of the longest domain.
+type ChangeWindowPolicy struct {
+	Windows []ChangeWindow
+	// Now returns the current time, time.Now if nil
+	Now func() time.Time
+	// OnDeferred, when set, is called for every deferred change with the time its window opens
+	OnDeferred func(change Change, opensAt time.Time)
+	// nextOpening is the earliest opening of the windows of the changes deferred by the last Apply
+	nextOpening time.Time
+}
+
+// Apply applies the change window policy which strips out the changes of the records whose window is
+// closed.
+func (p *ChangeWindowPolicy) Apply(changes *Changes) *Changes {
+	now := time.Now()
+	if p.Now != nil {
+		now = p.Now()
+	}
+	p.nextOpening = time.Time{}
+	deferred := map[string]int{}
+	for _, w := range p.Windows {
+		deferred[w.Domain] = 0
+	}
+
+	allowed := func(change Change) bool {
+		ep := change.Record()
+		w := p.window(ep.DNSName)
+		if w == nil || w.open(now) {
+			return true
+		}
+		opensAt := w.opensAt(now)
+		log.Debugf("Deferring the change %s of %s %s until its change window opens at %s", change.ID, ep.RecordType, ep.DNSName, opensAt.Format(time.RFC3339))
+		deferred[w.Domain]++
+		if p.nextOpening.IsZero() || opensAt.Before(p.nextOpening) {
+			p.nextOpening = opensAt
+		}
+		if p.OnDeferred != nil {
+			p.OnDeferred(change, opensAt)
+		}
+		return false
+	}
+
+	result := &Changes{}
+	for _, ep := range changes.Create {
+		if allowed(NewChange(ActionCreate, nil, ep)) {
+			result.Create = append(result.Create, ep)
+		}
+	}
+	for i, ep := range changes.UpdateNew {
+		if allowed(NewChange(ActionUpdate, changes.UpdateOld[i], ep)) {
+			result.UpdateOld = append(result.UpdateOld, changes.UpdateOld[i])
+			result.UpdateNew = append(result.UpdateNew, ep)
+		}
+	}
+	for _, ep := range changes.Delete {
+		if allowed(NewChange(ActionDelete, ep, nil)) {
+			result.Delete = append(result.Delete, ep)
+		}
+	}
+
+	total := 0
+	for domain, count := range deferred {
+		deferredChanges.WithLabelValues(domain).Set(float64(count))
+		total += count
+	}
+	if total > 0 {
+		log.Infof("Deferring %d changes until their change windows open, the first at %s", total, p.nextOpening.Format(time.RFC3339))
+	}
+	return result
+}
+
+// NextOpening returns the earliest time a window of the changes deferred by the last Apply opens, or
+// the zero time if no change was deferred.
+func (p *ChangeWindowPolicy) NextOpening() time.Time {
+	return p.nextOpening
+}
+
+// window returns the window of the longest domain matching the DNS name, or nil.
+func (p *ChangeWindowPolicy) window(dnsName string) *ChangeWindow {
+	var match *ChangeWindow
+	for i, w := range p.Windows {
+		if w.matches(dnsName) && (match == nil || len(w.Domain) > len(match.Domain)) {
+			match = &p.Windows[i]
+		}
+	}
+	return match
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plan
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func TestParseChangeWindow(t *testing.T) {
+	w, err := ParseChangeWindow("*.Prod.example.com=02:00-04:30")
+	require.NoError(t, err)
+	assert.Equal(t, ChangeWindow{Domain: "prod.example.com", Start: 2 * time.Hour, End: 4*time.Hour + 30*time.Minute}, w)
+
+	for _, value := range []string{
+		"prod.example.com",
+		"prod.example.com=02:00",
+		"=02:00-04:00",
+		"prod.example.com=2am-4am",
+		"prod.example.com=02:00-24:00",
+		"prod.example.com=02:00-02:00",
+	} {
+		_, err := ParseChangeWindow(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestChangeWindowOpen(t *testing.T) {
+	day := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	night, err := ParseChangeWindow("example.com=22:00-02:00")
+	require.NoError(t, err)
+	early, err := ParseChangeWindow("example.com=02:00-04:00")
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		window  ChangeWindow
+		at      time.Duration
+		open    bool
+		opensAt time.Time
+	}{
+		{early, time.Hour, false, day.Add(2 * time.Hour)},
+		{early, 2 * time.Hour, true, day.Add(26 * time.Hour)},
+		{early, 4 * time.Hour, false, day.Add(26 * time.Hour)},
+		{night, time.Hour, true, day.Add(22 * time.Hour)},
+		{night, 2 * time.Hour, false, day.Add(22 * time.Hour)},
+		{night, 23 * time.Hour, true, day.Add(46 * time.Hour)},
+	} {
+		now := day.Add(tt.at)
+		assert.Equal(t, tt.open, tt.window.open(now), "%s at %s", tt.window.Domain, tt.at)
+		assert.Equal(t, tt.opensAt, tt.window.opensAt(now), "%s at %s", tt.window.Domain, tt.at)
+	}
+
+	// the window is in UTC
+	assert.True(t, early.open(time.Date(2024, time.March, 1, 4, 0, 0, 0, time.FixedZone("CET", 3600))))
+}
+
+func TestChangeWindowPolicy(t *testing.T) {
+	prod, err := ParseChangeWindow("prod.example.com=02:00-04:00")
+	require.NoError(t, err)
+	canary, err := ParseChangeWindow("canary.prod.example.com=00:00-01:00")
+	require.NoError(t, err)
+	now := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	var deferred []string
+	policy := &ChangeWindowPolicy{
+		Windows: []ChangeWindow{prod, canary},
+		Now:     func() time.Time { return now },
+		OnDeferred: func(change Change, opensAt time.Time) {
+			deferred = append(deferred, change.Record().DNSName+" "+opensAt.Format(time.RFC3339))
+		},
+	}
+
+	dev := endpoint.NewEndpoint("app.dev.example.com", endpoint.RecordTypeA, "1.1.1.1")
+	app := endpoint.NewEndpoint("app.prod.example.com", endpoint.RecordTypeA, "1.1.1.1")
+	appOld := endpoint.NewEndpoint("app.prod.example.com", endpoint.RecordTypeA, "2.2.2.2")
+	devOld := endpoint.NewEndpoint("app.dev.example.com", endpoint.RecordTypeA, "2.2.2.2")
+	apex := endpoint.NewEndpoint("prod.example.com", endpoint.RecordTypeA, "1.1.1.1")
+	canaryApp := endpoint.NewEndpoint("app.canary.prod.example.com", endpoint.RecordTypeA, "1.1.1.1")
+
+	changes := policy.Apply(&Changes{
+		Create:    []*endpoint.Endpoint{dev, canaryApp},
+		UpdateOld: []*endpoint.Endpoint{appOld, devOld},
+		UpdateNew: []*endpoint.Endpoint{app, dev},
+		Delete:    []*endpoint.Endpoint{apex},
+	})
+
+	assert.Equal(t, &Changes{
+		Create:    []*endpoint.Endpoint{dev},
+		UpdateOld: []*endpoint.Endpoint{devOld},
+		UpdateNew: []*endpoint.Endpoint{dev},
+	}, changes)
+	assert.Equal(t, []string{
+		"app.canary.prod.example.com 2024-03-02T00:00:00Z",
+		"app.prod.example.com 2024-03-02T02:00:00Z",
+		"prod.example.com 2024-03-02T02:00:00Z",
+	}, deferred)
+	assert.Equal(t, time.Date(2024, time.March, 2, 0, 0, 0, 0, time.UTC), policy.NextOpening())
+
+	// all changes are applied when the windows are open
+	now = time.Date(2024, time.March, 2, 0, 30, 0, 0, time.UTC)
+	changes = policy.Apply(&Changes{Create: []*endpoint.Endpoint{canaryApp}})
+	assert.Equal(t, []*endpoint.Endpoint{canaryApp}, changes.Create)
+	assert.True(t, policy.NextOpening().IsZero())
+}
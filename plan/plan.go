@@ -18,7 +18,6 @@ package plan
 
 import (
 	"fmt"
-	"strings"
 
 	"github.com/google/go-cmp/cmp"
 	log "github.com/sirupsen/logrus"
@@ -26,6 +25,16 @@ import (
 	"sigs.k8s.io/external-dns/endpoint"
 )
 
+// The reasons for desired records to be left out of the changes.
+const (
+	// SkipReasonDomainFilter is for records outside of the domain filter.
+	SkipReasonDomainFilter = "domain_filter"
+	// SkipReasonUnsupportedType is for records of a type not managed or excluded.
+	SkipReasonUnsupportedType = "unsupported_type"
+	// SkipReasonOwnershipConflict is for records whose DNS name is owned by another owner.
+	SkipReasonOwnershipConflict = "ownership_conflict"
+)
+
 // PropertyComparator is used in Plan for comparing the previous and current custom annotations.
 type PropertyComparator func(name string, previous string, current string) bool
 
@@ -49,6 +58,12 @@ type Plan struct {
 	ExcludeRecords []string
 	// OwnerID of records to manage
 	OwnerID string
+	// PropertyComparator compares the values of provider specific properties of the current and
+	// desired records. The values are compared textually if it is nil.
+	PropertyComparator PropertyComparator
+	// Skipped counts the desired records left out of the changes by the reason
+	// Populated after calling Calculate()
+	Skipped map[string]int
 }
 
 // Changes holds lists of actions to be executed by dns providers
@@ -92,8 +107,9 @@ type planTable struct {
 	resolver ConflictResolver
 }
 
-func newPlanTable() planTable { // TODO: make resolver configurable
-	return planTable{map[planKey]*planTableRow{}, PerResource{}}
+// newPlanTable returns a plan table with room for the given number of rows.
+func newPlanTable(size int) planTable { // TODO: make resolver configurable
+	return planTable{make(map[planKey]*planTableRow, size), PerResource{}}
 }
 
 // planTableRow represents a set of current and desired domain resource records.
@@ -165,16 +181,20 @@ func (c *Changes) HasChanges() bool {
 // state. It then passes those changes to the current policy for further
 // processing. It returns a copy of Plan with the changes populated.
 func (p *Plan) Calculate() *Plan {
-	t := newPlanTable()
-
 	if p.DomainFilter == nil {
 		p.DomainFilter = endpoint.MatchAllDomainFilters(nil)
 	}
 
-	for _, current := range filterRecordsForPlan(p.Current, p.DomainFilter, p.ManagedRecords, p.ExcludeRecords) {
+	skipped := map[string]int{}
+	currentRecords := filterRecordsForPlan(p.Current, p.DomainFilter, p.ManagedRecords, p.ExcludeRecords, nil)
+	desiredRecords := filterRecordsForPlan(p.Desired, p.DomainFilter, p.ManagedRecords, p.ExcludeRecords, skipped)
+
+	// Most desired records exist already, the table has about as many rows as the larger list of records.
+	t := newPlanTable(max(len(currentRecords), len(desiredRecords)))
+	for _, current := range currentRecords {
 		t.addCurrent(current)
 	}
-	for _, desired := range filterRecordsForPlan(p.Desired, p.DomainFilter, p.ManagedRecords, p.ExcludeRecords) {
+	for _, desired := range desiredRecords {
 		t.addCandidate(desired)
 	}
 
@@ -240,6 +260,9 @@ func (p *Plan) Calculate() *Plan {
 
 				if ownersMatch {
 					changes.Create = append(changes.Create, creates...)
+				} else {
+					log.Debugf("skipping the creation of %d records of %s owned by another owner", len(creates), key.dnsName)
+					skipped[SkipReasonOwnershipConflict] += len(creates)
 				}
 			}
 		}
@@ -251,16 +274,23 @@ func (p *Plan) Calculate() *Plan {
 
 	// filter out updates this external dns does not have ownership claim over
 	if p.OwnerID != "" {
+		updates := len(changes.UpdateNew)
 		changes.Delete = endpoint.FilterEndpointsByOwnerID(p.OwnerID, changes.Delete)
 		changes.UpdateOld = endpoint.FilterEndpointsByOwnerID(p.OwnerID, changes.UpdateOld)
 		changes.UpdateNew = endpoint.FilterEndpointsByOwnerID(p.OwnerID, changes.UpdateNew)
+		skipped[SkipReasonOwnershipConflict] += updates - len(changes.UpdateNew)
 	}
 
+	// the rows of the table are iterated in random order
+	changes.sort()
+
 	plan := &Plan{
-		Current:        p.Current,
-		Desired:        p.Desired,
-		Changes:        changes,
-		ManagedRecords: []string{endpoint.RecordTypeA, endpoint.RecordTypeAAAA, endpoint.RecordTypeCNAME},
+		Current:            p.Current,
+		Desired:            p.Desired,
+		Changes:            changes,
+		ManagedRecords:     []string{endpoint.RecordTypeA, endpoint.RecordTypeAAAA, endpoint.RecordTypeCNAME},
+		PropertyComparator: p.PropertyComparator,
+		Skipped:            skipped,
 	}
 
 	return plan
@@ -295,7 +325,7 @@ func (p *Plan) shouldUpdateProviderSpecific(desired, current *endpoint.Endpoint)
 	}
 	for _, c := range current.ProviderSpecific {
 		if d, ok := desiredProperties[c.Name]; ok {
-			if c.Value != d.Value {
+			if !p.propertyValuesEqual(c.Name, c.Value, d.Value) {
 				return true
 			}
 			delete(desiredProperties, c.Name)
@@ -307,6 +337,13 @@ func (p *Plan) shouldUpdateProviderSpecific(desired, current *endpoint.Endpoint)
 	return len(desiredProperties) > 0
 }
 
+func (p *Plan) propertyValuesEqual(name, previous, current string) bool {
+	if p.PropertyComparator != nil {
+		return p.PropertyComparator(name, previous, current)
+	}
+	return previous == current
+}
+
 // filterRecordsForPlan removes records that are not relevant to the planner.
 // Currently this just removes TXT records to prevent them from being
 // deleted erroneously by the planner (only the TXT registry should do this.)
@@ -314,17 +351,24 @@ func (p *Plan) shouldUpdateProviderSpecific(desired, current *endpoint.Endpoint)
 // Per RFC 1034, CNAME records conflict with all other records - it is the
 // only record with this property. The behavior of the planner may need to be
 // made more sophisticated to codify this.
-func filterRecordsForPlan(records []*endpoint.Endpoint, domainFilter endpoint.MatchAllDomainFilters, managedRecords, excludeRecords []string) []*endpoint.Endpoint {
-	filtered := []*endpoint.Endpoint{}
+//
+// The records removed are counted by the reason in skipped, unless it is nil.
+func filterRecordsForPlan(records []*endpoint.Endpoint, domainFilter endpoint.MatchAllDomainFilters, managedRecords, excludeRecords []string, skipped map[string]int) []*endpoint.Endpoint {
+	filtered := make([]*endpoint.Endpoint, 0, len(records))
 
 	for _, record := range records {
 		// Ignore records that do not match the domain filter provided
 		if !domainFilter.Match(record.DNSName) {
 			log.Debugf("ignoring record %s that does not match domain filter", record.DNSName)
+			if skipped != nil {
+				skipped[SkipReasonDomainFilter]++
+			}
 			continue
 		}
 		if IsManagedRecord(record.RecordType, managedRecords, excludeRecords) {
 			filtered = append(filtered, record)
+		} else if skipped != nil {
+			skipped[SkipReasonUnsupportedType]++
 		}
 	}
 
@@ -334,11 +378,7 @@ func filterRecordsForPlan(records []*endpoint.Endpoint, domainFilter endpoint.Ma
 // normalizeDNSName converts a DNS name to a canonical form, so that we can use string equality
 // it: removes space, converts to lower case, ensures there is a trailing dot
 func normalizeDNSName(dnsName string) string {
-	s := strings.TrimSpace(strings.ToLower(dnsName))
-	if !strings.HasSuffix(s, ".") {
-		s += "."
-	}
-	return s
+	return endpoint.CanonicalDNSName(dnsName) + "."
 }
 
 func IsManagedRecord(record string, managedRecords, excludeRecords []string) bool {
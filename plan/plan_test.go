@@ -17,6 +17,7 @@ limitations under the License.
 package plan
 
 import (
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -1085,3 +1086,89 @@ func TestShouldUpdateProviderSpecific(tt *testing.T) {
 		})
 	}
 }
+
+func TestShouldUpdateProviderSpecificWithComparator(tt *testing.T) {
+	// treats booleans of the custom property as equal regardless of their spelling
+	comparator := func(name, previous, current string) bool {
+		if name != "custom/property" {
+			return previous == current
+		}
+		p, _ := strconv.ParseBool(previous)
+		c, _ := strconv.ParseBool(current)
+		return p == c
+	}
+
+	for _, test := range []struct {
+		name         string
+		current      string
+		desired      string
+		property     string
+		shouldUpdate bool
+	}{
+		{name: "equivalent values", property: "custom/property", current: "true", desired: "1", shouldUpdate: false},
+		{name: "different values", property: "custom/property", current: "true", desired: "0", shouldUpdate: true},
+		{name: "other property compared textually", property: "other/property", current: "true", desired: "1", shouldUpdate: true},
+	} {
+		tt.Run(test.name, func(t *testing.T) {
+			current := &endpoint.Endpoint{
+				DNSName:          "foo.com",
+				Targets:          endpoint.Targets{"1.2.3.4"},
+				RecordType:       endpoint.RecordTypeA,
+				ProviderSpecific: endpoint.ProviderSpecific{{Name: test.property, Value: test.current}},
+			}
+			desired := &endpoint.Endpoint{
+				DNSName:          "foo.com",
+				Targets:          endpoint.Targets{"1.2.3.4"},
+				RecordType:       endpoint.RecordTypeA,
+				ProviderSpecific: endpoint.ProviderSpecific{{Name: test.property, Value: test.desired}},
+			}
+			plan := &Plan{
+				Policies:           []Policy{&SyncPolicy{}},
+				Current:            []*endpoint.Endpoint{current},
+				Desired:            []*endpoint.Endpoint{desired},
+				ManagedRecords:     []string{endpoint.RecordTypeA, endpoint.RecordTypeCNAME},
+				PropertyComparator: comparator,
+			}
+			assert.Equal(t, test.shouldUpdate, plan.shouldUpdateProviderSpecific(desired, current))
+
+			changes := plan.Calculate().Changes
+			if test.shouldUpdate {
+				assert.Len(t, changes.UpdateNew, 1)
+			} else {
+				assert.Empty(t, changes.UpdateNew)
+			}
+		})
+	}
+}
+
+func TestCalculateSkipped(t *testing.T) {
+	owned := endpoint.NewEndpoint("owned.domain.tld", endpoint.RecordTypeA, "1.1.1.1")
+	owned.Labels[endpoint.OwnerLabelKey] = "owner"
+	foreign := endpoint.NewEndpoint("foreign.domain.tld", endpoint.RecordTypeA, "1.1.1.1")
+	foreign.Labels[endpoint.OwnerLabelKey] = "other"
+	domainFilter := endpoint.NewDomainFilter([]string{"domain.tld"})
+	p := &Plan{
+		Policies: []Policy{&SyncPolicy{}},
+		Current:  []*endpoint.Endpoint{owned, foreign},
+		Desired: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("owned.domain.tld", endpoint.RecordTypeA, "2.2.2.2"),
+			endpoint.NewEndpoint("foreign.domain.tld", endpoint.RecordTypeA, "2.2.2.2"),
+			endpoint.NewEndpoint("foreign.domain.tld", endpoint.RecordTypeAAAA, "2001:db8::1"),
+			endpoint.NewEndpoint("new.domain.tld", endpoint.RecordTypeMX, "10 mail.domain.tld"),
+			endpoint.NewEndpoint("new.other.tld", endpoint.RecordTypeA, "3.3.3.3"),
+			endpoint.NewEndpoint("new.other.tld", endpoint.RecordTypeCNAME, "domain.tld"),
+		},
+		DomainFilter:   endpoint.MatchAllDomainFilters{&domainFilter},
+		ManagedRecords: []string{endpoint.RecordTypeA, endpoint.RecordTypeAAAA, endpoint.RecordTypeCNAME},
+		OwnerID:        "owner",
+	}
+
+	planned := p.Calculate()
+	assert.Len(t, planned.Changes.UpdateNew, 1)
+	assert.Empty(t, planned.Changes.Create)
+	assert.Equal(t, map[string]int{
+		SkipReasonDomainFilter:      2,
+		SkipReasonUnsupportedType:   1,
+		SkipReasonOwnershipConflict: 2,
+	}, planned.Skipped)
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plan
+
+import (
+	"strings"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// TargetChange is the difference between the targets of the current and the desired record of
+// an update. Providers able to add and remove single targets of a record use it to apply minimal
+// updates, instead of replacing all targets and removing the record transiently.
+type TargetChange struct {
+	// Old is the current record.
+	Old *endpoint.Endpoint
+	// New is the desired record.
+	New *endpoint.Endpoint
+	// Added are the targets of the desired record missing from the current record.
+	Added endpoint.Targets
+	// Removed are the targets of the current record missing from the desired record.
+	Removed endpoint.Targets
+}
+
+// DiffTargets returns the targets of desired missing from current, and the targets of current
+// missing from desired. Targets are compared case-insensitively, like in Targets.Same, and keep
+// their order.
+func DiffTargets(current, desired endpoint.Targets) (added endpoint.Targets, removed endpoint.Targets) {
+	currentSet := make(map[string]struct{}, len(current))
+	for _, t := range current {
+		currentSet[strings.ToLower(t)] = struct{}{}
+	}
+	desiredSet := make(map[string]struct{}, len(desired))
+	for _, t := range desired {
+		desiredSet[strings.ToLower(t)] = struct{}{}
+	}
+
+	for _, t := range desired {
+		if _, ok := currentSet[strings.ToLower(t)]; !ok {
+			added = append(added, t)
+		}
+	}
+	for _, t := range current {
+		if _, ok := desiredSet[strings.ToLower(t)]; !ok {
+			removed = append(removed, t)
+		}
+	}
+	return added, removed
+}
+
+// TargetChanges returns the target level differences of the updates. The current and desired
+// records are matched by name, type and set identifier. Desired records without a matching
+// current record are returned with all targets added.
+func (c *Changes) TargetChanges() []TargetChange {
+	old := make(map[endpoint.EndpointKey]*endpoint.Endpoint, len(c.UpdateOld))
+	for _, e := range c.UpdateOld {
+		old[e.Key()] = e
+	}
+
+	changes := make([]TargetChange, 0, len(c.UpdateNew))
+	for _, e := range c.UpdateNew {
+		change := TargetChange{Old: old[e.Key()], New: e}
+		if change.Old == nil {
+			change.Added = e.Targets
+		} else {
+			change.Added, change.Removed = DiffTargets(change.Old.Targets, e.Targets)
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func TestDiffTargets(t *testing.T) {
+	for _, tc := range []struct {
+		name            string
+		current         endpoint.Targets
+		desired         endpoint.Targets
+		expectedAdded   endpoint.Targets
+		expectedRemoved endpoint.Targets
+	}{
+		{
+			name:    "unchanged",
+			current: endpoint.Targets{"1.1.1.1", "1.1.1.2"},
+			desired: endpoint.Targets{"1.1.1.2", "1.1.1.1"},
+		},
+		{
+			name:          "added",
+			current:       endpoint.Targets{"1.1.1.1", "1.1.1.2"},
+			desired:       endpoint.Targets{"1.1.1.1", "1.1.1.2", "1.1.1.3"},
+			expectedAdded: endpoint.Targets{"1.1.1.3"},
+		},
+		{
+			name:            "removed",
+			current:         endpoint.Targets{"1.1.1.1", "1.1.1.2"},
+			desired:         endpoint.Targets{"1.1.1.2"},
+			expectedRemoved: endpoint.Targets{"1.1.1.1"},
+		},
+		{
+			name:            "replaced",
+			current:         endpoint.Targets{"a.example.com", "b.example.com"},
+			desired:         endpoint.Targets{"B.example.com", "c.example.com"},
+			expectedAdded:   endpoint.Targets{"c.example.com"},
+			expectedRemoved: endpoint.Targets{"a.example.com"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			added, removed := DiffTargets(tc.current, tc.desired)
+			assert.Equal(t, tc.expectedAdded, added)
+			assert.Equal(t, tc.expectedRemoved, removed)
+		})
+	}
+}
+
+func TestChangesTargetChanges(t *testing.T) {
+	oldA := endpoint.NewEndpoint("a.example.com", endpoint.RecordTypeA, "1.1.1.1", "1.1.1.2")
+	newA := endpoint.NewEndpoint("a.example.com", endpoint.RecordTypeA, "1.1.1.1", "1.1.1.2", "1.1.1.3")
+	oldB := endpoint.NewEndpoint("b.example.com", endpoint.RecordTypeCNAME, "old.example.com")
+	newB := endpoint.NewEndpoint("b.example.com", endpoint.RecordTypeCNAME, "new.example.com")
+	newC := endpoint.NewEndpoint("c.example.com", endpoint.RecordTypeA, "1.1.1.1")
+
+	changes := &Changes{
+		// the current records aren't at the same indexes as the desired ones
+		UpdateOld: []*endpoint.Endpoint{oldB, oldA},
+		UpdateNew: []*endpoint.Endpoint{newA, newB, newC},
+	}
+
+	assert.Equal(t, []TargetChange{
+		{Old: oldA, New: newA, Added: endpoint.Targets{"1.1.1.3"}},
+		{Old: oldB, New: newB, Added: endpoint.Targets{"new.example.com"}, Removed: endpoint.Targets{"old.example.com"}},
+		{New: newC, Added: endpoint.Targets{"1.1.1.1"}},
+	}, changes.TargetChanges())
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plan
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+var steppedRecords = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "external_dns",
+		Subsystem: "controller",
+		Name:      "ttl_stepped_records",
+		Help:      "Number of records whose targets are being changed with a lowered TTL.",
+	},
+)
+
+func init() {
+	prometheus.MustRegister(steppedRecords)
+}
+
+// The steps of the records whose targets are changed by the TTLStepPolicy, stored in the
+// endpoint.TTLStepLabelKey label of the records
+const (
+	// ttlStepLowered is the step of the records whose TTL is lowered, with their previous targets
+	ttlStepLowered = "lowered"
+	// ttlStepSwitched is the step of the records with their new targets and the lowered TTL
+	ttlStepSwitched = "switched"
+)
+
+// TTLStepPolicy changes the targets of records in three steps, so that the resolvers don't keep
+// resolving the previous targets for the whole TTL of the records: the TTL of a record is lowered
+// first, its targets are changed once the resolvers cached it with the lowered TTL, and its TTL is
+// restored after the soak period. The step of every record and when it was reached are stored in
+// its labels, so the steps are carried across the reconciliations by the registry.
+type TTLStepPolicy struct {
+	// TTL is the TTL of the records while their targets are changed
+	TTL endpoint.TTL
+	// Soak is how long the lowered TTL is kept before and after the targets change, before them at
+	// least the TTL the record is restored to
+	Soak time.Duration
+	// Now returns the current time, time.Now if nil
+	Now func() time.Time
+	// nextStep is the earliest time a record held by the last Apply can take its next step
+	nextStep time.Time
+}
+
+// Apply applies the TTL step policy which replaces the updates of the records by the next step of
+// their changes, and strips out those of the records waiting for their next step.
+func (p *TTLStepPolicy) Apply(changes *Changes) *Changes {
+	now := time.Now()
+	if p.Now != nil {
+		now = p.Now()
+	}
+	p.nextStep = time.Time{}
+
+	result := &Changes{Create: changes.Create, Delete: changes.Delete}
+	stepped := 0
+	for i, desired := range changes.UpdateNew {
+		current := changes.UpdateOld[i]
+		update, stepAt := p.step(current, desired, now)
+		if update == nil {
+			log.Debugf("Holding the change of %s %s until its next TTL step at %s", desired.RecordType, desired.DNSName, stepAt.Format(time.RFC3339))
+			if p.nextStep.IsZero() || stepAt.Before(p.nextStep) {
+				p.nextStep = stepAt
+			}
+			stepped++
+			continue
+		}
+		if update != desired {
+			log.Infof("Changing %s %s with the lowered TTL %d, step %s", update.RecordType, update.DNSName, p.TTL, update.Labels[endpoint.TTLStepLabelKey])
+			stepped++
+		}
+		result.UpdateOld = append(result.UpdateOld, current)
+		result.UpdateNew = append(result.UpdateNew, update)
+	}
+	steppedRecords.Set(float64(stepped))
+	return result
+}
+
+// NextStep returns the earliest time a record held by the last Apply can take its next step, or
+// the zero time if no record was held.
+func (p *TTLStepPolicy) NextStep() time.Time {
+	return p.nextStep
+}
+
+// step returns the update of the record to its next step, the desired record when it is changed
+// in one go, or nil and the time of its next step when it has to wait for it.
+func (p *TTLStepPolicy) step(current, desired *endpoint.Endpoint, now time.Time) (*endpoint.Endpoint, time.Time) {
+	changed := targetChanged(desired, current)
+	since := time.Time{}
+	if value, err := strconv.ParseInt(current.Labels[endpoint.TTLStepSinceLabelKey], 10, 64); err == nil {
+		since = time.Unix(value, 0).UTC()
+	}
+
+	switch current.Labels[endpoint.TTLStepLabelKey] {
+	case ttlStepLowered:
+		if !changed {
+			// the change of the targets was reverted, the TTL is restored
+			return desired, time.Time{}
+		}
+		// the resolvers may have cached the record with its TTL before it was lowered
+		soak := max(p.Soak, time.Duration(desired.RecordTTL)*time.Second)
+		if now.Before(since.Add(soak)) {
+			return nil, since.Add(soak)
+		}
+		return p.withStep(desired, ttlStepSwitched, now), time.Time{}
+	case ttlStepSwitched:
+		if changed {
+			return p.withStep(desired, ttlStepSwitched, now), time.Time{}
+		}
+		if now.Before(since.Add(p.Soak)) {
+			return nil, since.Add(p.Soak)
+		}
+		return desired, time.Time{}
+	}
+
+	// the records whose TTL is low already are changed in one go, as are those without TTL, since
+	// it couldn't be restored
+	if !changed || !desired.RecordTTL.IsConfigured() || desired.RecordTTL <= p.TTL ||
+		(current.RecordTTL.IsConfigured() && current.RecordTTL <= p.TTL) {
+		return desired, time.Time{}
+	}
+	lowered := p.withStep(desired, ttlStepLowered, now)
+	lowered.Targets = append(endpoint.Targets{}, current.Targets...)
+	return lowered, time.Time{}
+}
+
+// withStep returns a copy of the record with the lowered TTL and the labels of the step.
+func (p *TTLStepPolicy) withStep(desired *endpoint.Endpoint, step string, now time.Time) *endpoint.Endpoint {
+	update := desired.DeepCopy()
+	update.RecordTTL = p.TTL
+	if update.Labels == nil {
+		update.Labels = endpoint.NewLabels()
+	}
+	update.Labels[endpoint.TTLStepLabelKey] = step
+	update.Labels[endpoint.TTLStepSinceLabelKey] = strconv.FormatInt(now.Unix(), 10)
+	return update
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plan
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func TestTTLStepPolicy(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	policy := &TTLStepPolicy{TTL: 60, Soak: 10 * time.Minute, Now: func() time.Time { return now }}
+
+	record := endpoint.NewEndpointWithTTL("app.example.org", endpoint.RecordTypeA, 3600, "1.1.1.1")
+	desired := []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("app.example.org", endpoint.RecordTypeA, 3600, "2.2.2.2")}
+
+	// sync calculates the plan against the record and applies its update, as the registry would
+	sync := func() *Changes {
+		changes := (&Plan{
+			Current:        []*endpoint.Endpoint{record},
+			Desired:        desired,
+			Policies:       []Policy{policy},
+			ManagedRecords: []string{endpoint.RecordTypeA},
+		}).Calculate().Changes
+		if len(changes.UpdateNew) > 0 {
+			require.Len(t, changes.UpdateNew, 1)
+			record = changes.UpdateNew[0].DeepCopy()
+		}
+		return changes
+	}
+
+	// the TTL is lowered first
+	assert.NotEmpty(t, sync().UpdateNew)
+	assert.Equal(t, endpoint.TTL(60), record.RecordTTL)
+	assert.Equal(t, endpoint.Targets{"1.1.1.1"}, record.Targets)
+	assert.Equal(t, "lowered", record.Labels[endpoint.TTLStepLabelKey])
+	assert.True(t, policy.NextStep().IsZero())
+
+	// the targets are changed once the record was cached with its previous TTL
+	now = now.Add(30 * time.Minute)
+	assert.Empty(t, sync().UpdateNew)
+	assert.Equal(t, time.Date(2024, time.March, 1, 13, 0, 0, 0, time.UTC), policy.NextStep())
+
+	now = now.Add(30 * time.Minute)
+	assert.NotEmpty(t, sync().UpdateNew)
+	assert.Equal(t, endpoint.TTL(60), record.RecordTTL)
+	assert.Equal(t, endpoint.Targets{"2.2.2.2"}, record.Targets)
+	assert.Equal(t, "switched", record.Labels[endpoint.TTLStepLabelKey])
+
+	// the TTL is restored after the soak period
+	now = now.Add(5 * time.Minute)
+	assert.Empty(t, sync().UpdateNew)
+	assert.Equal(t, now.Add(5*time.Minute), policy.NextStep())
+
+	now = now.Add(5 * time.Minute)
+	assert.NotEmpty(t, sync().UpdateNew)
+	assert.Equal(t, endpoint.TTL(3600), record.RecordTTL)
+	assert.Equal(t, endpoint.Targets{"2.2.2.2"}, record.Targets)
+	assert.NotContains(t, record.Labels, endpoint.TTLStepLabelKey)
+	assert.NotContains(t, record.Labels, endpoint.TTLStepSinceLabelKey)
+
+	assert.Empty(t, sync().UpdateNew)
+}
+
+func TestTTLStepPolicyInOneGo(t *testing.T) {
+	policy := &TTLStepPolicy{TTL: 60, Soak: 10 * time.Minute}
+
+	for _, tc := range []struct {
+		title   string
+		current *endpoint.Endpoint
+		desired *endpoint.Endpoint
+	}{
+		{
+			title:   "TTL changed only",
+			current: endpoint.NewEndpointWithTTL("app.example.org", endpoint.RecordTypeA, 3600, "1.1.1.1"),
+			desired: endpoint.NewEndpointWithTTL("app.example.org", endpoint.RecordTypeA, 300, "1.1.1.1"),
+		},
+		{
+			title:   "low TTL",
+			current: endpoint.NewEndpointWithTTL("app.example.org", endpoint.RecordTypeA, 30, "1.1.1.1"),
+			desired: endpoint.NewEndpointWithTTL("app.example.org", endpoint.RecordTypeA, 30, "2.2.2.2"),
+		},
+		{
+			title:   "no TTL",
+			current: endpoint.NewEndpoint("app.example.org", endpoint.RecordTypeA, "1.1.1.1"),
+			desired: endpoint.NewEndpoint("app.example.org", endpoint.RecordTypeA, "2.2.2.2"),
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			changes := policy.Apply(&Changes{UpdateOld: []*endpoint.Endpoint{tc.current}, UpdateNew: []*endpoint.Endpoint{tc.desired}})
+			assert.Equal(t, []*endpoint.Endpoint{tc.desired}, changes.UpdateNew)
+		})
+	}
+
+	// the TTL is restored when the change of the targets is reverted
+	current := endpoint.NewEndpointWithTTL("app.example.org", endpoint.RecordTypeA, 60, "1.1.1.1")
+	current.Labels[endpoint.TTLStepLabelKey] = "lowered"
+	current.Labels[endpoint.TTLStepSinceLabelKey] = "1709294400"
+	desired := endpoint.NewEndpointWithTTL("app.example.org", endpoint.RecordTypeA, 3600, "1.1.1.1")
+	changes := policy.Apply(&Changes{UpdateOld: []*endpoint.Endpoint{current}, UpdateNew: []*endpoint.Endpoint{desired}})
+	assert.Equal(t, []*endpoint.Endpoint{desired}, changes.UpdateNew)
+}
@@ -19,10 +19,14 @@ package akamai
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 
+	client "github.com/akamai/AkamaiOPEN-edgegrid-golang/client-v1"
 	dns "github.com/akamai/AkamaiOPEN-edgegrid-golang/configdns-v2"
 	"github.com/akamai/AkamaiOPEN-edgegrid-golang/edgegrid"
 	log "github.com/sirupsen/logrus"
@@ -37,8 +41,15 @@ const (
 	edgeDNSRecordTTL = 600
 	maxUint          = ^uint(0)
 	maxInt           = int(maxUint >> 1)
+	// The record type of the alias of the apex of a zone to an Akamai edge hostname
+	recordTypeAkamaiCDN = "AKAMAICDN"
+	// The maximum length of the character-strings of TXT records
+	txtStringLength = 255
 )
 
+// akamaiEdgeDomains are the domains of the Akamai edge hostnames, the targets of AKAMAICDN records.
+var akamaiEdgeDomains = []string{".edgekey.net", ".edgesuite.net", ".akamaized.net", ".akamaiedge.net"}
+
 // edgeDNSClient is a proxy interface of the Akamai edgegrid configdns-v2 package that can be stubbed for testing.
 type AkamaiDNSService interface {
 	ListZones(queryArgs dns.ZoneListQueryArgs) (*dns.ZoneListResponse, error)
@@ -47,8 +58,28 @@ type AkamaiDNSService interface {
 	DeleteRecord(record *dns.RecordBody, zone string, recLock bool) error
 	UpdateRecord(record *dns.RecordBody, zone string, recLock bool) error
 	CreateRecordsets(recordsets *dns.Recordsets, zone string, recLock bool) error
+	CreateChangelist(zone string) error
+	AddChangelistChange(zone string, change *ChangelistChange) error
+	SubmitChangelist(zone string) error
+	DeleteChangelist(zone string) error
+}
+
+// ChangelistChange is the change of a recordset added to the changelist of a zone.
+type ChangelistChange struct {
+	Name  string   `json:"name"`
+	Type  string   `json:"type"`
+	Op    string   `json:"op"`
+	TTL   int      `json:"ttl,omitempty"`
+	Rdata []string `json:"rdata,omitempty"`
 }
 
+// The operations of the changes of a changelist
+const (
+	changelistAdd    = "ADD"
+	changelistEdit   = "EDIT"
+	changelistDelete = "DELETE"
+)
+
 type AkamaiConfig struct {
 	DomainFilter          endpoint.DomainFilter
 	ZoneIDFilter          provider.ZoneIDFilter
@@ -60,6 +91,7 @@ type AkamaiConfig struct {
 	EdgercSection         string
 	MaxBody               int
 	AccountKey            string
+	UseChangelists        bool
 	DryRun                bool
 }
 
@@ -71,8 +103,9 @@ type AkamaiProvider struct {
 	// Contract Ids to filter on
 	zoneIDFilter provider.ZoneIDFilter
 	// Edgegrid library configuration
-	config *edgegrid.Config
-	dryRun bool
+	config         *edgegrid.Config
+	useChangelists bool
+	dryRun         bool
 	// Defines client. Allows for mocking.
 	client AkamaiDNSService
 }
@@ -142,10 +175,11 @@ func NewAkamaiProvider(akamaiConfig AkamaiConfig, akaService AkamaiDNSService) (
 	}
 
 	provider := &AkamaiProvider{
-		domainFilter: akamaiConfig.DomainFilter,
-		zoneIDFilter: akamaiConfig.ZoneIDFilter,
-		config:       &edgeGridConfig,
-		dryRun:       akamaiConfig.DryRun,
+		domainFilter:   akamaiConfig.DomainFilter,
+		zoneIDFilter:   akamaiConfig.ZoneIDFilter,
+		config:         &edgeGridConfig,
+		useChangelists: akamaiConfig.UseChangelists,
+		dryRun:         akamaiConfig.DryRun,
 	}
 	if akaService != nil {
 		log.Debugf("Using STUB")
@@ -184,6 +218,39 @@ func (p AkamaiProvider) UpdateRecord(record *dns.RecordBody, zone string, recLoc
 	return record.Update(zone, recLock)
 }
 
+func (p AkamaiProvider) CreateChangelist(zone string) error {
+	return changelistRequest(http.MethodPost, "/config-dns/v2/changelists?zone="+url.QueryEscape(zone), nil)
+}
+
+func (p AkamaiProvider) AddChangelistChange(zone string, change *ChangelistChange) error {
+	return changelistRequest(http.MethodPost, "/config-dns/v2/changelists/"+zone+"/recordsets/add-change", change)
+}
+
+func (p AkamaiProvider) SubmitChangelist(zone string) error {
+	return changelistRequest(http.MethodPost, "/config-dns/v2/changelists/"+zone+"/submit", nil)
+}
+
+func (p AkamaiProvider) DeleteChangelist(zone string) error {
+	return changelistRequest(http.MethodDelete, "/config-dns/v2/changelists/"+zone, nil)
+}
+
+// changelistRequest sends a request of the changelists API, not covered by the configdns-v2 package.
+func changelistRequest(method, path string, body interface{}) error {
+	req, err := client.NewJSONRequest(dns.Config, method, path, body)
+	if err != nil {
+		return err
+	}
+	res, err := client.Do(dns.Config, req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if client.IsError(res) {
+		return client.NewAPIError(res)
+	}
+	return nil
+}
+
 // Fetch zones using Edgegrid DNS v2 API
 func (p AkamaiProvider) fetchZones() (akamaiZones, error) {
 	filteredZones := akamaiZones{Zones: make([]akamaiZone, 0)}
@@ -232,6 +299,10 @@ func (p AkamaiProvider) Records(context.Context) (endpoints []*endpoint.Endpoint
 		}
 
 		for _, recordset := range recordsets.Recordsets {
+			if recordset.Type == recordTypeAkamaiCDN {
+				// the alias of the apex is managed as a CNAME, like the records of the other names
+				recordset.Type = endpoint.RecordTypeCNAME
+			}
 			if !provider.SupportedRecordType(recordset.Type) {
 				log.Debugf("Skipping endpoint DNSName: '%s' RecordType: '%s'. Record type not supported.", recordset.Name, recordset.Type)
 				continue
@@ -274,6 +345,10 @@ func (p AkamaiProvider) ApplyChanges(ctx context.Context, changes *plan.Changes)
 	}
 	log.Debugf("Processing zones: [%v]", zoneNameIDMapper)
 
+	if p.useChangelists {
+		return p.applyChangelists(zoneNameIDMapper, changes)
+	}
+
 	// Create recordsets
 	log.Debugf("Create Changes requested [%v]", changes.Create)
 	if err := p.createRecordsets(zoneNameIDMapper, changes.Create); err != nil {
@@ -308,6 +383,89 @@ func (p AkamaiProvider) ApplyChanges(ctx context.Context, changes *plan.Changes)
 	return nil
 }
 
+// applyChangelists applies the changes of each zone with a changelist, so they are activated together
+// when the changelist is submitted. A changelist that can't be submitted is discarded.
+func (p AkamaiProvider) applyChangelists(zoneNameIDMapper provider.ZoneIDName, changes *plan.Changes) error {
+	changesByZone := map[string][]*ChangelistChange{}
+	add := func(op string, endpoints []*endpoint.Endpoint) {
+		for _, ep := range endpoints {
+			zone, _ := zoneNameIDMapper.FindZone(ep.DNSName)
+			if zone == "" {
+				log.Debugf("Skipping Akamai Edge DNS change of endpoint: '%s' type: '%s', it does not match against Domain filters", ep.DNSName, ep.RecordType)
+				continue
+			}
+			rec := newAkamaiRecordset(ep.DNSName, edgeRecordType(zone, ep), ttlAsInt(ep.RecordTTL), cleanTargets(ep.RecordType, ep.Targets...))
+			change := &ChangelistChange{Name: rec.Name, Type: rec.Type, Op: op}
+			if op != changelistDelete {
+				change.TTL, change.Rdata = rec.TTL, rec.Rdata
+			}
+			changesByZone[zone] = append(changesByZone[zone], change)
+		}
+	}
+	add(changelistDelete, changes.Delete)
+	add(changelistEdit, changes.UpdateNew)
+	add(changelistAdd, changes.Create)
+
+	zones := make([]string, 0, len(changesByZone))
+	for zone := range changesByZone {
+		zones = append(zones, zone)
+	}
+	sort.Strings(zones)
+
+	for _, zone := range zones {
+		for _, change := range changesByZone[zone] {
+			log.WithFields(log.Fields{
+				"record": change.Name,
+				"type":   change.Type,
+				"ttl":    change.TTL,
+				"target": fmt.Sprintf("%v", change.Rdata),
+				"zone":   zone,
+			}).Infof("Changelist change %s", change.Op)
+		}
+		if p.dryRun {
+			continue
+		}
+
+		if err := p.client.CreateChangelist(zone); err != nil {
+			return fmt.Errorf("failed to create the changelist of zone %s: %w", zone, err)
+		}
+		if err := p.submitChangelist(zone, changesByZone[zone]); err != nil {
+			if err := p.client.DeleteChangelist(zone); err != nil {
+				log.Errorf("Failed to delete the changelist of zone %s: %v", zone, err)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (p AkamaiProvider) submitChangelist(zone string, changes []*ChangelistChange) error {
+	for _, change := range changes {
+		if err := p.client.AddChangelistChange(zone, change); err != nil {
+			return fmt.Errorf("failed to add the change of %s %s to the changelist of zone %s: %w", change.Name, change.Type, zone, err)
+		}
+	}
+	if err := p.client.SubmitChangelist(zone); err != nil {
+		return fmt.Errorf("failed to submit the changelist of zone %s: %w", zone, err)
+	}
+	return nil
+}
+
+// edgeRecordType returns the type of the recordset of the endpoint. CNAME records aren't allowed at the
+// apex of a zone, so the apex is aliased to Akamai edge hostnames with an AKAMAICDN record.
+func edgeRecordType(zone string, ep *endpoint.Endpoint) string {
+	if ep.RecordType != endpoint.RecordTypeCNAME || strings.TrimSuffix(ep.DNSName, ".") != zone || len(ep.Targets) != 1 {
+		return ep.RecordType
+	}
+	target := strings.TrimSuffix(ep.Targets[0], ".")
+	for _, domain := range akamaiEdgeDomains {
+		if strings.HasSuffix(target, domain) {
+			return recordTypeAkamaiCDN
+		}
+	}
+	return ep.RecordType
+}
+
 // Create DNS Recordset
 func newAkamaiRecordset(dnsName, recordType string, ttl int, targets []string) dns.Recordset {
 	return dns.Recordset{
@@ -319,8 +477,9 @@ func newAkamaiRecordset(dnsName, recordType string, ttl int, targets []string) d
 }
 
 // cleanTargets preps recordset rdata if necessary for EdgeDNS
-func cleanTargets(rtype string, targets ...string) []string {
-	log.Debugf("Targets to clean: [%v]", targets)
+func cleanTargets(rtype string, endpointTargets ...string) []string {
+	log.Debugf("Targets to clean: [%v]", endpointTargets)
+	targets := append([]string{}, endpointTargets...)
 	if rtype == "CNAME" || rtype == "SRV" {
 		for idx, target := range targets {
 			targets[idx] = strings.TrimSuffix(target, ".")
@@ -334,7 +493,7 @@ func cleanTargets(rtype string, targets ...string) []string {
 			if strings.Contains(target, "owner") && strings.Contains(target, "\"") {
 				target = strings.ReplaceAll(target, "\"", "`")
 			}
-			targets[idx] = "\"" + target + "\""
+			targets[idx] = splitTxtRdata(target)
 		}
 	}
 	log.Debugf("Clean targets: [%v]", targets)
@@ -342,13 +501,23 @@ func cleanTargets(rtype string, targets ...string) []string {
 	return targets
 }
 
-// trimTxtRdata removes surrounding quotes for received TXT rdata
+// splitTxtRdata quotes the TXT data, split into character-strings of at most 255 characters.
+func splitTxtRdata(target string) string {
+	var strs []string
+	for len(target) > txtStringLength {
+		strs = append(strs, target[:txtStringLength])
+		target = target[txtStringLength:]
+	}
+	strs = append(strs, target)
+	return "\"" + strings.Join(strs, "\" \"") + "\""
+}
+
+// trimTxtRdata joins the character-strings and restores the embedded quotes of received TXT rdata
 func trimTxtRdata(rdata []string, rtype string) []string {
 	if rtype == "TXT" {
 		for idx, d := range rdata {
-			if strings.Contains(d, "`") {
-				rdata[idx] = strings.ReplaceAll(d, "`", "\"")
-			}
+			d = strings.ReplaceAll(d, "\" \"", "")
+			rdata[idx] = strings.ReplaceAll(d, "`", "\"")
 		}
 	}
 	log.Debugf("Trimmed data: [%v]", rdata)
@@ -381,7 +550,7 @@ func (p AkamaiProvider) createRecordsets(zoneNameIDMapper provider.ZoneIDName, e
 		recordsets := &dns.Recordsets{Recordsets: make([]dns.Recordset, 0)}
 		for _, endpoint := range endpoints {
 			newrec := newAkamaiRecordset(endpoint.DNSName,
-				endpoint.RecordType,
+				edgeRecordType(zone, endpoint),
 				ttlAsInt(endpoint.RecordTTL),
 				cleanTargets(endpoint.RecordType, endpoint.Targets...))
 			logfields := log.Fields{
@@ -423,7 +592,7 @@ func (p AkamaiProvider) deleteRecordsets(zoneNameIDMapper provider.ZoneIDName, e
 		}
 
 		recName := strings.TrimSuffix(endpoint.DNSName, ".")
-		rec, err := p.client.GetRecord(zoneName, recName, endpoint.RecordType)
+		rec, err := p.client.GetRecord(zoneName, recName, edgeRecordType(zoneName, endpoint))
 		if err != nil {
 			if _, ok := err.(*dns.RecordError); !ok {
 				return fmt.Errorf("endpoint deletion. record validation failed. error: %w", err)
@@ -455,7 +624,7 @@ func (p AkamaiProvider) updateNewRecordsets(zoneNameIDMapper provider.ZoneIDName
 		}
 
 		recName := strings.TrimSuffix(endpoint.DNSName, ".")
-		rec, err := p.client.GetRecord(zoneName, recName, endpoint.RecordType)
+		rec, err := p.client.GetRecord(zoneName, recName, edgeRecordType(zoneName, endpoint))
 		if err != nil {
 			log.Errorf("Endpoint update. Record validation failed. Error: %s", err.Error())
 			return err
@@ -19,6 +19,9 @@ package akamai
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
 
 	log "github.com/sirupsen/logrus"
@@ -38,7 +41,8 @@ type edgednsStubData struct {
 }
 
 type edgednsStub struct {
-	stubData map[string]edgednsStubData
+	stubData   map[string]edgednsStubData
+	changelist []string
 }
 
 func newStub() *edgednsStub {
@@ -148,6 +152,29 @@ func (r *edgednsStub) UpdateRecord(record *dns.RecordBody, zone string, recLock
 	return nil
 }
 
+func (r *edgednsStub) CreateChangelist(zone string) error {
+	r.changelist = append(r.changelist, "create "+zone)
+	return nil
+}
+
+func (r *edgednsStub) AddChangelistChange(zone string, change *ChangelistChange) error {
+	if change.Name == "fail.example.com" {
+		return errors.New("invalid recordset")
+	}
+	r.changelist = append(r.changelist, fmt.Sprintf("%s %s %s %d %v", change.Op, change.Name, change.Type, change.TTL, change.Rdata))
+	return nil
+}
+
+func (r *edgednsStub) SubmitChangelist(zone string) error {
+	r.changelist = append(r.changelist, "submit "+zone)
+	return nil
+}
+
+func (r *edgednsStub) DeleteChangelist(zone string) error {
+	r.changelist = append(r.changelist, "delete "+zone)
+	return nil
+}
+
 // Test FetchZones
 func TestFetchZonesZoneIDFilter(t *testing.T) {
 	stub := newStub()
@@ -384,3 +411,76 @@ func TestAkamaiApplyChanges(t *testing.T) {
 	apply := c.ApplyChanges(context.Background(), changes)
 	assert.Nil(t, apply)
 }
+
+func TestAkamaiApplyChangelists(t *testing.T) {
+	stub := newStub()
+	c, err := createAkamaiStubProvider(stub, endpoint.DomainFilter{}, provider.ZoneIDFilter{})
+	assert.Nil(t, err)
+	c.useChangelists = true
+
+	stub.setOutput("zone", []interface{}{"example.com"})
+	changes := &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			{DNSName: "example.com", RecordType: "CNAME", Targets: endpoint.Targets{"example.com.edgekey.net"}, RecordTTL: 300},
+			{DNSName: "www.example.com", RecordType: "TXT", Targets: endpoint.Targets{"heritage=external-dns"}, RecordTTL: 300},
+		},
+		UpdateOld: []*endpoint.Endpoint{{DNSName: "update.example.com", RecordType: "A", Targets: endpoint.Targets{"10.0.0.1"}, RecordTTL: 300}},
+		UpdateNew: []*endpoint.Endpoint{{DNSName: "update.example.com", RecordType: "A", Targets: endpoint.Targets{"10.0.0.2"}, RecordTTL: 300}},
+		Delete:    []*endpoint.Endpoint{{DNSName: "delete.example.com", RecordType: "CNAME", Targets: endpoint.Targets{"target.example.org."}}},
+	}
+	assert.NoError(t, c.ApplyChanges(context.Background(), changes))
+	assert.Equal(t, []string{
+		"create example.com",
+		"DELETE delete.example.com CNAME 0 []",
+		"EDIT update.example.com A 300 [10.0.0.2]",
+		"ADD example.com AKAMAICDN 300 [example.com.edgekey.net]",
+		`ADD www.example.com TXT 300 ["heritage=external-dns"]`,
+		"submit example.com",
+	}, stub.changelist)
+	assert.Equal(t, endpoint.Targets{"target.example.org."}, changes.Delete[0].Targets)
+
+	stub.changelist = nil
+	changes = &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			{DNSName: "ok.example.com", RecordType: "A", Targets: endpoint.Targets{"10.0.0.1"}},
+			{DNSName: "fail.example.com", RecordType: "A", Targets: endpoint.Targets{"10.0.0.1"}},
+		},
+	}
+	assert.ErrorContains(t, c.ApplyChanges(context.Background(), changes), "invalid recordset")
+	assert.Equal(t, []string{"create example.com", "ADD ok.example.com A 600 [10.0.0.1]", "delete example.com"}, stub.changelist)
+}
+
+func TestAkamaiRecordsAkamaiCDN(t *testing.T) {
+	stub := newStub()
+	c, err := createAkamaiStubProvider(stub, endpoint.DomainFilter{}, provider.ZoneIDFilter{})
+	assert.Nil(t, err)
+
+	stub.setOutput("zone", []interface{}{"example.com"})
+	stub.setOutput("recordset", []interface{}{
+		dns.Recordset{Name: "example.com", Type: recordTypeAkamaiCDN, TTL: 300, Rdata: []string{"example.com.edgekey.net"}},
+	})
+	endpoints, err := c.Records(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("example.com", endpoint.RecordTypeCNAME, 300, "example.com.edgekey.net")}, endpoints)
+}
+
+func TestEdgeRecordType(t *testing.T) {
+	for _, tc := range []struct {
+		ep       *endpoint.Endpoint
+		expected string
+	}{
+		{endpoint.NewEndpoint("example.com", endpoint.RecordTypeCNAME, "example.com.edgesuite.net"), recordTypeAkamaiCDN},
+		{endpoint.NewEndpoint("www.example.com", endpoint.RecordTypeCNAME, "example.com.edgesuite.net"), endpoint.RecordTypeCNAME},
+		{endpoint.NewEndpoint("example.com", endpoint.RecordTypeCNAME, "lb.example.org"), endpoint.RecordTypeCNAME},
+		{endpoint.NewEndpoint("example.com", endpoint.RecordTypeA, "10.0.0.1"), endpoint.RecordTypeA},
+	} {
+		assert.Equal(t, tc.expected, edgeRecordType("example.com", tc.ep), tc.ep.String())
+	}
+}
+
+func TestTxtRdataSplitting(t *testing.T) {
+	long := strings.Repeat("a", 300)
+	targets := cleanTargets(endpoint.RecordTypeTXT, long, "short")
+	assert.Equal(t, []string{`"` + strings.Repeat("a", 255) + `" "` + strings.Repeat("a", 45) + `"`, `"short"`}, targets)
+	assert.Equal(t, []string{`"` + long + `"`, `"short"`}, trimTxtRdata(targets, endpoint.RecordTypeTXT))
+}
@@ -18,15 +18,19 @@ package aws
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/aws/aws-sdk-go/service/route53profiles"
 	log "github.com/sirupsen/logrus"
 
 	"sigs.k8s.io/external-dns/endpoint"
@@ -43,6 +47,8 @@ const (
 	// As we are using the standard AWS client, this should already be compliant.
 	// Hence, ifever AWS decides to raise this limit, we will automatically reduce the pressure on rate limits
 	route53PageSize = "300"
+	// maxChangeBatchCommentLength is the maximum length of the comment of a change batch.
+	maxChangeBatchCommentLength = 256
 	// providerSpecificAlias specifies whether a CNAME endpoint maps to an AWS ALIAS record.
 	providerSpecificAlias            = "alias"
 	providerSpecificTargetHostedZone = "aws/target-hosted-zone"
@@ -189,6 +195,38 @@ var canonicalHostedZones = map[string]string{
 	"execute-api.sa-east-1.amazonaws.com":      "ZCMLWB8V5SYIT",
 	"execute-api.us-gov-east-1.amazonaws.com":  "Z3SE9ATJYCRCZJ",
 	"execute-api.us-gov-west-1.amazonaws.com":  "Z1K6XKP9SAGWDV",
+	// Amazon S3 website endpoints
+	// See: https://docs.aws.amazon.com/general/latest/gr/s3.html#s3_website_region_endpoints
+	"s3-website.us-east-2.amazonaws.com":      "Z2O1EMRO9K5GLX",
+	"s3-website-us-east-1.amazonaws.com":      "Z3AQBSTGFYJSTF",
+	"s3-website-us-west-1.amazonaws.com":      "Z2F56UZL2M1ACD",
+	"s3-website-us-west-2.amazonaws.com":      "Z3BJ6K6RIION7M",
+	"s3-website.af-south-1.amazonaws.com":     "Z83WF9RJE8B12",
+	"s3-website.ap-east-1.amazonaws.com":      "ZNB98KWMFR0R6",
+	"s3-website.ap-south-1.amazonaws.com":     "Z11RGJOFQNVJUP",
+	"s3-website.ap-northeast-3.amazonaws.com": "Z2YQB5RD63NC85",
+	"s3-website.ap-northeast-2.amazonaws.com": "Z3W03O7B5YMIYP",
+	"s3-website-ap-southeast-1.amazonaws.com": "Z3O0J2DXBE1FTB",
+	"s3-website-ap-southeast-2.amazonaws.com": "Z1WCIGYICN2BYD",
+	"s3-website-ap-northeast-1.amazonaws.com": "Z2M4EHUR26P7ZW",
+	"s3-website.ca-central-1.amazonaws.com":   "Z1QDHH18159H29",
+	"s3-website.eu-central-1.amazonaws.com":   "Z21DNDUVLTQW6Q",
+	"s3-website-eu-west-1.amazonaws.com":      "Z1BKCTXD74EZPE",
+	"s3-website.eu-west-2.amazonaws.com":      "Z3GKZC51ZF0DB4",
+	"s3-website.eu-south-1.amazonaws.com":     "Z30OZKI7KPW7MI",
+	"s3-website.eu-west-3.amazonaws.com":      "Z3R1K369G5AVDG",
+	"s3-website.eu-north-1.amazonaws.com":     "Z3BAZG2TWCNX0D",
+	"s3-website.me-south-1.amazonaws.com":     "Z1MPMWCPA7YB62",
+	"s3-website-sa-east-1.amazonaws.com":      "Z7KQH4QJS55SO",
+	"s3-website-us-gov-west-1.amazonaws.com":  "Z31GFT0UA1I2HV",
+	"s3-website.us-gov-east-1.amazonaws.com":  "Z2NIFVYYW2VKV1",
+}
+
+// targetHealthUnsupportedZones lists canonical hosted zones whose alias targets
+// reject EvaluateTargetHealth=true, i.e. CloudFront and edge-optimized API Gateway endpoints.
+// See: https://docs.aws.amazon.com/Route53/latest/APIReference/API_AliasTarget.html
+var targetHealthUnsupportedZones = map[string]struct{}{
+	"Z2FDTNDATAQYW2": {},
 }
 
 // Route53API is the subset of the AWS Route53 API that we actually use.  Add methods as required. Signatures must match exactly.
@@ -201,10 +239,17 @@ type Route53API interface {
 	ListTagsForResourceWithContext(ctx context.Context, input *route53.ListTagsForResourceInput, opts ...request.Option) (*route53.ListTagsForResourceOutput, error)
 }
 
+// Route53ProfilesAPI is the subset of the AWS Route53 Profiles API that we actually use.
+type Route53ProfilesAPI interface {
+	ListProfileResourceAssociationsPagesWithContext(ctx context.Context, input *route53profiles.ListProfileResourceAssociationsInput, fn func(resp *route53profiles.ListProfileResourceAssociationsOutput, lastPage bool) (shouldContinue bool), opts ...request.Option) error
+}
+
 // wrapper to handle ownership relation throughout the provider implementation
 type Route53Change struct {
 	route53.Change
 	OwnedRecord string
+	// Description is the description of the change, written in the comment of its change batch
+	Description string
 }
 
 type Route53Changes []*Route53Change
@@ -217,7 +262,35 @@ func (cs Route53Changes) Route53Changes() []*route53.Change {
 	return ret
 }
 
+// Comment returns the comment of the change batch of the changes: their distinct descriptions,
+// truncated to the 256 bytes allowed by Route53, or nil when none of them is described.
+func (cs Route53Changes) Comment() *string {
+	var descriptions []string
+	seen := map[string]bool{}
+	for _, c := range cs {
+		if c.Description != "" && !seen[c.Description] {
+			seen[c.Description] = true
+			descriptions = append(descriptions, c.Description)
+		}
+	}
+	if len(descriptions) == 0 {
+		return nil
+	}
+	comment := strings.Join(descriptions, "; ")
+	if len(comment) > maxChangeBatchCommentLength {
+		// cut on a rune boundary, Route53 rejects the change batch with invalid UTF-8
+		n := maxChangeBatchCommentLength
+		for n > 0 && !utf8.RuneStart(comment[n]) {
+			n--
+		}
+		comment = comment[:n]
+	}
+	return aws.String(comment)
+}
+
 type zonesListCache struct {
+	// mu guards the cache, the changes of zones being applied concurrently
+	mu       sync.Mutex
 	age      time.Time
 	duration time.Duration
 	zones    map[string]*route53.HostedZone
@@ -226,11 +299,14 @@ type zonesListCache struct {
 // AWSProvider is an implementation of Provider for AWS Route53.
 type AWSProvider struct {
 	provider.BaseProvider
-	client               Route53API
-	dryRun               bool
-	batchChangeSize      int
-	batchChangeInterval  time.Duration
-	evaluateTargetHealth bool
+	client              Route53API
+	dryRun              bool
+	batchChangeSize     int
+	batchChangeInterval time.Duration
+	// maximum number of characters of the values and of values of a change batch, unlimited if 0
+	batchChangeSizeBytes  int
+	batchChangeSizeValues int
+	evaluateTargetHealth  bool
 	// only consider hosted zones managing domains ending in this suffix
 	domainFilter endpoint.DomainFilter
 	// filter hosted zones by id
@@ -239,59 +315,104 @@ type AWSProvider struct {
 	zoneTypeFilter provider.ZoneTypeFilter
 	// filter hosted zones by tags
 	zoneTagFilter provider.ZoneTagFilter
-	preferCNAME   bool
-	zonesCache    *zonesListCache
-	// queue for collecting changes to submit them in the next iteration, but after all other changes
+	// only consider hosted zones associated with these Route53 Profiles
+	profileIDs     []string
+	profilesClient Route53ProfilesAPI
+	preferCNAME    bool
+	zonesCache     *zonesListCache
+	// queue for collecting changes to submit them in the next iteration, but after all other changes,
+	// guarded by failedChangesMux as the changes of zones are applied concurrently
 	failedChangesQueue map[string]Route53Changes
+	failedChangesMux   sync.Mutex
 }
 
 // AWSConfig contains configuration to create a new AWS provider.
 type AWSConfig struct {
-	DomainFilter         endpoint.DomainFilter
-	ZoneIDFilter         provider.ZoneIDFilter
-	ZoneTypeFilter       provider.ZoneTypeFilter
-	ZoneTagFilter        provider.ZoneTagFilter
-	BatchChangeSize      int
-	BatchChangeInterval  time.Duration
-	EvaluateTargetHealth bool
-	PreferCNAME          bool
-	DryRun               bool
-	ZoneCacheDuration    time.Duration
+	DomainFilter          endpoint.DomainFilter
+	ZoneIDFilter          provider.ZoneIDFilter
+	ZoneTypeFilter        provider.ZoneTypeFilter
+	ZoneTagFilter         provider.ZoneTagFilter
+	BatchChangeSize       int
+	BatchChangeSizeBytes  int
+	BatchChangeSizeValues int
+	BatchChangeInterval   time.Duration
+	EvaluateTargetHealth  bool
+	PreferCNAME           bool
+	DryRun                bool
+	ZoneCacheDuration     time.Duration
+	ProfileIDs            []string
 }
 
 // NewAWSProvider initializes a new AWS Route53 based Provider.
-func NewAWSProvider(awsConfig AWSConfig, client Route53API) (*AWSProvider, error) {
+func NewAWSProvider(awsConfig AWSConfig, client Route53API, profilesClient Route53ProfilesAPI) (*AWSProvider, error) {
 	provider := &AWSProvider{
-		client:               client,
-		domainFilter:         awsConfig.DomainFilter,
-		zoneIDFilter:         awsConfig.ZoneIDFilter,
-		zoneTypeFilter:       awsConfig.ZoneTypeFilter,
-		zoneTagFilter:        awsConfig.ZoneTagFilter,
-		batchChangeSize:      awsConfig.BatchChangeSize,
-		batchChangeInterval:  awsConfig.BatchChangeInterval,
-		evaluateTargetHealth: awsConfig.EvaluateTargetHealth,
-		preferCNAME:          awsConfig.PreferCNAME,
-		dryRun:               awsConfig.DryRun,
-		zonesCache:           &zonesListCache{duration: awsConfig.ZoneCacheDuration},
-		failedChangesQueue:   make(map[string]Route53Changes),
+		client:                client,
+		domainFilter:          awsConfig.DomainFilter,
+		zoneIDFilter:          awsConfig.ZoneIDFilter,
+		zoneTypeFilter:        awsConfig.ZoneTypeFilter,
+		zoneTagFilter:         awsConfig.ZoneTagFilter,
+		profileIDs:            awsConfig.ProfileIDs,
+		profilesClient:        profilesClient,
+		batchChangeSize:       awsConfig.BatchChangeSize,
+		batchChangeSizeBytes:  awsConfig.BatchChangeSizeBytes,
+		batchChangeSizeValues: awsConfig.BatchChangeSizeValues,
+		batchChangeInterval:   awsConfig.BatchChangeInterval,
+		evaluateTargetHealth:  awsConfig.EvaluateTargetHealth,
+		preferCNAME:           awsConfig.PreferCNAME,
+		dryRun:                awsConfig.DryRun,
+		zonesCache:            &zonesListCache{duration: awsConfig.ZoneCacheDuration},
+		failedChangesQueue:    make(map[string]Route53Changes),
 	}
 
 	return provider, nil
 }
 
+// ZoneNames returns the names of the hosted zones.
+func (p *AWSProvider) ZoneNames(ctx context.Context) ([]string, error) {
+	zones, err := p.Zones(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(zones))
+	for _, z := range zones {
+		names = append(names, aws.StringValue(z.Name))
+	}
+	return names, nil
+}
+
 // Zones returns the list of hosted zones.
 func (p *AWSProvider) Zones(ctx context.Context) (map[string]*route53.HostedZone, error) {
+	p.zonesCache.mu.Lock()
 	if p.zonesCache.zones != nil && time.Since(p.zonesCache.age) < p.zonesCache.duration {
+		p.zonesCache.mu.Unlock()
 		log.Debug("Using cached zones list")
 		return p.zonesCache.zones, nil
 	}
+	p.zonesCache.mu.Unlock()
 	log.Debug("Refreshing zones list cache")
 
 	zones := make(map[string]*route53.HostedZone)
 
+	var profileZones map[string]bool
+	if len(p.profileIDs) > 0 {
+		var err error
+		profileZones, err = p.profileZones(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	var tagErr error
+	profileZonesListed := false
 	f := func(resp *route53.ListHostedZonesOutput, lastPage bool) (shouldContinue bool) {
 		for _, zone := range resp.HostedZones {
+			if profileZones != nil {
+				if !profileZones[cleanZoneID(aws.StringValue(zone.Id))] {
+					continue
+				}
+				profileZonesListed = true
+			}
+
 			if !p.zoneIDFilter.Match(aws.StringValue(zone.Id)) {
 				continue
 			}
@@ -322,26 +443,67 @@ func (p *AWSProvider) Zones(ctx context.Context) (map[string]*route53.HostedZone
 		return true
 	}
 
+	start := time.Now()
 	err := p.client.ListHostedZonesPagesWithContext(ctx, &route53.ListHostedZonesInput{}, f)
+	provider.ObserveAPIRequest(ctx, "aws", provider.OperationListZones, start, err)
 	if err != nil {
 		return nil, provider.NewSoftError(fmt.Errorf("failed to list hosted zones: %w", err))
 	}
 	if tagErr != nil {
 		return nil, provider.NewSoftError(fmt.Errorf("failed to list zones tags: %w", tagErr))
 	}
+	if len(profileZones) > 0 && !profileZonesListed {
+		// the zones shared through a profile are only listed by the account owning them
+		return nil, fmt.Errorf("none of the %d hosted zones associated with the Route53 Profiles %v are listed with the current credentials, they must be those of the account owning the zones, e.g. with --aws-assume-role", len(profileZones), p.profileIDs)
+	}
 
 	for _, zone := range zones {
 		log.Debugf("Considering zone: %s (domain: %s)", aws.StringValue(zone.Id), aws.StringValue(zone.Name))
 	}
 
 	if p.zonesCache.duration > time.Duration(0) {
+		p.zonesCache.mu.Lock()
 		p.zonesCache.zones = zones
 		p.zonesCache.age = time.Now()
+		p.zonesCache.mu.Unlock()
 	}
 
 	return zones, nil
 }
 
+// profileZones returns the IDs of the hosted zones associated with the Route53 Profiles,
+// without the "/hostedzone/" prefix. Only the completed associations are considered.
+func (p *AWSProvider) profileZones(ctx context.Context) (map[string]bool, error) {
+	zones := map[string]bool{}
+	for _, profileID := range p.profileIDs {
+		f := func(resp *route53profiles.ListProfileResourceAssociationsOutput, lastPage bool) (shouldContinue bool) {
+			for _, association := range resp.ProfileResourceAssociations {
+				if aws.StringValue(association.Status) != route53profiles.ProfileStatusComplete {
+					continue
+				}
+				// e.g. arn:aws:route53:::hostedzone/Z1D633PJN98FT9, other resources such as
+				// the DNS Firewall rule groups can be associated with the profile too
+				_, zoneID, found := strings.Cut(aws.StringValue(association.ResourceArn), ":hostedzone/")
+				if !found {
+					continue
+				}
+				zones[zoneID] = true
+			}
+			return true
+		}
+
+		start := time.Now()
+		err := p.profilesClient.ListProfileResourceAssociationsPagesWithContext(ctx, &route53profiles.ListProfileResourceAssociationsInput{
+			ProfileId: aws.String(profileID),
+		}, f)
+		provider.ObserveAPIRequest(ctx, "aws", provider.OperationListZones, start, err)
+		if err != nil {
+			return nil, provider.NewSoftError(fmt.Errorf("failed to list the resources of profile %s: %w", profileID, err))
+		}
+	}
+	return zones, nil
+}
+
 // wildcardUnescape converts \\052.abc back to *.abc
 // Route53 stores wildcards escaped: http://docs.aws.amazon.com/Route53/latest/DeveloperGuide/DomainNameFormat.html?shortFooter=true#domain-name-format-asterisk
 func wildcardUnescape(s string) string {
@@ -362,76 +524,7 @@ func (p *AWSProvider) records(ctx context.Context, zones map[string]*route53.Hos
 	endpoints := make([]*endpoint.Endpoint, 0)
 	f := func(resp *route53.ListResourceRecordSetsOutput, lastPage bool) (shouldContinue bool) {
 		for _, r := range resp.ResourceRecordSets {
-			newEndpoints := make([]*endpoint.Endpoint, 0)
-
-			if !p.SupportedRecordType(aws.StringValue(r.Type)) {
-				continue
-			}
-
-			var ttl endpoint.TTL
-			if r.TTL != nil {
-				ttl = endpoint.TTL(*r.TTL)
-			}
-
-			if len(r.ResourceRecords) > 0 {
-				targets := make([]string, len(r.ResourceRecords))
-				for idx, rr := range r.ResourceRecords {
-					targets[idx] = aws.StringValue(rr.Value)
-				}
-
-				ep := endpoint.NewEndpointWithTTL(wildcardUnescape(aws.StringValue(r.Name)), aws.StringValue(r.Type), ttl, targets...)
-				if aws.StringValue(r.Type) == endpoint.RecordTypeCNAME {
-					ep = ep.WithProviderSpecific(providerSpecificAlias, "false")
-				}
-				newEndpoints = append(newEndpoints, ep)
-			}
-
-			if r.AliasTarget != nil {
-				// Alias records don't have TTLs so provide the default to match the TXT generation
-				if ttl == 0 {
-					ttl = recordTTL
-				}
-				ep := endpoint.
-					NewEndpointWithTTL(wildcardUnescape(aws.StringValue(r.Name)), endpoint.RecordTypeA, ttl, aws.StringValue(r.AliasTarget.DNSName)).
-					WithProviderSpecific(providerSpecificEvaluateTargetHealth, fmt.Sprintf("%t", aws.BoolValue(r.AliasTarget.EvaluateTargetHealth))).
-					WithProviderSpecific(providerSpecificAlias, "true")
-				newEndpoints = append(newEndpoints, ep)
-			}
-
-			for _, ep := range newEndpoints {
-				if r.SetIdentifier != nil {
-					ep.SetIdentifier = aws.StringValue(r.SetIdentifier)
-					switch {
-					case r.Weight != nil:
-						ep.WithProviderSpecific(providerSpecificWeight, fmt.Sprintf("%d", aws.Int64Value(r.Weight)))
-					case r.Region != nil:
-						ep.WithProviderSpecific(providerSpecificRegion, aws.StringValue(r.Region))
-					case r.Failover != nil:
-						ep.WithProviderSpecific(providerSpecificFailover, aws.StringValue(r.Failover))
-					case r.MultiValueAnswer != nil && aws.BoolValue(r.MultiValueAnswer):
-						ep.WithProviderSpecific(providerSpecificMultiValueAnswer, "")
-					case r.GeoLocation != nil:
-						if r.GeoLocation.ContinentCode != nil {
-							ep.WithProviderSpecific(providerSpecificGeolocationContinentCode, aws.StringValue(r.GeoLocation.ContinentCode))
-						} else {
-							if r.GeoLocation.CountryCode != nil {
-								ep.WithProviderSpecific(providerSpecificGeolocationCountryCode, aws.StringValue(r.GeoLocation.CountryCode))
-							}
-							if r.GeoLocation.SubdivisionCode != nil {
-								ep.WithProviderSpecific(providerSpecificGeolocationSubdivisionCode, aws.StringValue(r.GeoLocation.SubdivisionCode))
-							}
-						}
-					default:
-						// one of the above needs to be set, otherwise SetIdentifier doesn't make sense
-					}
-				}
-
-				if r.HealthCheckId != nil {
-					ep.WithProviderSpecific(providerSpecificHealthCheckID, aws.StringValue(r.HealthCheckId))
-				}
-
-				endpoints = append(endpoints, ep)
-			}
+			endpoints = append(endpoints, p.recordSetEndpoints(r)...)
 		}
 
 		return true
@@ -451,6 +544,166 @@ func (p *AWSProvider) records(ctx context.Context, zones map[string]*route53.Hos
 	return endpoints, nil
 }
 
+// route53PageToken is where the next page of records starts: the hosted zone, and the record set
+// to start listing from, the first one of the zone if none.
+type route53PageToken struct {
+	Zone       string `json:"zone"`
+	Name       string `json:"name,omitempty"`
+	Type       string `json:"type,omitempty"`
+	Identifier string `json:"identifier,omitempty"`
+}
+
+// RecordsPage implements provider.RecordsPager, returning the record sets of a response of
+// ListResourceRecordSets, the hosted zones being listed one after the other. The responses are
+// read on while they end in the middle of the record sets of a DNS name, so that a page holds
+// every record set of its DNS names.
+func (p *AWSProvider) RecordsPage(ctx context.Context, pageToken string) ([]*endpoint.Endpoint, string, error) {
+	zones, err := p.Zones(ctx)
+	if err != nil {
+		return nil, "", provider.NewSoftError(fmt.Errorf("records retrieval failed: %w", err))
+	}
+	zoneIDs := make([]string, 0, len(zones))
+	for id := range zones {
+		zoneIDs = append(zoneIDs, id)
+	}
+	sort.Strings(zoneIDs)
+
+	var token route53PageToken
+	if pageToken != "" {
+		if err := json.Unmarshal([]byte(pageToken), &token); err != nil {
+			return nil, "", fmt.Errorf("invalid page token %q: %w", pageToken, err)
+		}
+	}
+	// the zone of the token may have been removed since, the records of the zone following it are
+	// listed then
+	i := sort.SearchStrings(zoneIDs, token.Zone)
+	if i == len(zoneIDs) {
+		return nil, "", nil
+	}
+	params := &route53.ListResourceRecordSetsInput{
+		HostedZoneId: aws.String(zoneIDs[i]),
+		MaxItems:     aws.String(route53PageSize),
+	}
+	if zoneIDs[i] == token.Zone && token.Name != "" {
+		params.StartRecordName = aws.String(token.Name)
+		params.StartRecordType = aws.String(token.Type)
+		if token.Identifier != "" {
+			params.StartRecordIdentifier = aws.String(token.Identifier)
+		}
+	}
+
+	var (
+		endpoints []*endpoint.Endpoint
+		last      *route53.ListResourceRecordSetsOutput
+		lastName  string
+	)
+	f := func(resp *route53.ListResourceRecordSetsOutput, lastPage bool) (shouldContinue bool) {
+		for _, r := range resp.ResourceRecordSets {
+			endpoints = append(endpoints, p.recordSetEndpoints(r)...)
+			lastName = aws.StringValue(r.Name)
+		}
+		last = resp
+		return aws.BoolValue(resp.IsTruncated) && aws.StringValue(resp.NextRecordName) == lastName
+	}
+	if err := p.client.ListResourceRecordSetsPagesWithContext(ctx, params, f); err != nil {
+		return nil, "", provider.NewSoftError(fmt.Errorf("failed to list resource records sets for zone %s: %w", zoneIDs[i], err))
+	}
+
+	next := route53PageToken{}
+	switch {
+	case last != nil && aws.BoolValue(last.IsTruncated):
+		next = route53PageToken{
+			Zone:       zoneIDs[i],
+			Name:       aws.StringValue(last.NextRecordName),
+			Type:       aws.StringValue(last.NextRecordType),
+			Identifier: aws.StringValue(last.NextRecordIdentifier),
+		}
+	case i+1 < len(zoneIDs):
+		next = route53PageToken{Zone: zoneIDs[i+1]}
+	default:
+		return endpoints, "", nil
+	}
+	nextToken, err := json.Marshal(next)
+	if err != nil {
+		return nil, "", err
+	}
+	return endpoints, string(nextToken), nil
+}
+
+// recordSetEndpoints returns the endpoints of a resource record set, none if its type isn't supported.
+func (p *AWSProvider) recordSetEndpoints(r *route53.ResourceRecordSet) []*endpoint.Endpoint {
+	newEndpoints := make([]*endpoint.Endpoint, 0)
+
+	if !p.SupportedRecordType(aws.StringValue(r.Type)) {
+		return nil
+	}
+
+	var ttl endpoint.TTL
+	if r.TTL != nil {
+		ttl = endpoint.TTL(*r.TTL)
+	}
+
+	if len(r.ResourceRecords) > 0 {
+		targets := make([]string, len(r.ResourceRecords))
+		for idx, rr := range r.ResourceRecords {
+			targets[idx] = aws.StringValue(rr.Value)
+		}
+
+		ep := endpoint.NewEndpointWithTTL(wildcardUnescape(aws.StringValue(r.Name)), aws.StringValue(r.Type), ttl, targets...)
+		if aws.StringValue(r.Type) == endpoint.RecordTypeCNAME {
+			ep = ep.WithProviderSpecific(providerSpecificAlias, "false")
+		}
+		newEndpoints = append(newEndpoints, ep)
+	}
+
+	if r.AliasTarget != nil {
+		// Alias records don't have TTLs so provide the default to match the TXT generation
+		if ttl == 0 {
+			ttl = recordTTL
+		}
+		ep := endpoint.
+			NewEndpointWithTTL(wildcardUnescape(aws.StringValue(r.Name)), endpoint.RecordTypeA, ttl, aws.StringValue(r.AliasTarget.DNSName)).
+			WithProviderSpecific(providerSpecificEvaluateTargetHealth, fmt.Sprintf("%t", aws.BoolValue(r.AliasTarget.EvaluateTargetHealth))).
+			WithProviderSpecific(providerSpecificAlias, "true")
+		newEndpoints = append(newEndpoints, ep)
+	}
+
+	for _, ep := range newEndpoints {
+		if r.SetIdentifier != nil {
+			ep.SetIdentifier = aws.StringValue(r.SetIdentifier)
+			switch {
+			case r.Weight != nil:
+				ep.WithProviderSpecific(providerSpecificWeight, fmt.Sprintf("%d", aws.Int64Value(r.Weight)))
+			case r.Region != nil:
+				ep.WithProviderSpecific(providerSpecificRegion, aws.StringValue(r.Region))
+			case r.Failover != nil:
+				ep.WithProviderSpecific(providerSpecificFailover, aws.StringValue(r.Failover))
+			case r.MultiValueAnswer != nil && aws.BoolValue(r.MultiValueAnswer):
+				ep.WithProviderSpecific(providerSpecificMultiValueAnswer, "")
+			case r.GeoLocation != nil:
+				if r.GeoLocation.ContinentCode != nil {
+					ep.WithProviderSpecific(providerSpecificGeolocationContinentCode, aws.StringValue(r.GeoLocation.ContinentCode))
+				} else {
+					if r.GeoLocation.CountryCode != nil {
+						ep.WithProviderSpecific(providerSpecificGeolocationCountryCode, aws.StringValue(r.GeoLocation.CountryCode))
+					}
+					if r.GeoLocation.SubdivisionCode != nil {
+						ep.WithProviderSpecific(providerSpecificGeolocationSubdivisionCode, aws.StringValue(r.GeoLocation.SubdivisionCode))
+					}
+				}
+			default:
+				// one of the above needs to be set, otherwise SetIdentifier doesn't make sense
+			}
+		}
+
+		if r.HealthCheckId != nil {
+			ep.WithProviderSpecific(providerSpecificHealthCheckID, aws.StringValue(r.HealthCheckId))
+		}
+
+	}
+	return newEndpoints
+}
+
 // Identify if old and new endpoints require DELETE/CREATE instead of UPDATE.
 func (p *AWSProvider) requiresDeleteCreate(old *endpoint.Endpoint, new *endpoint.Endpoint) bool {
 	// a change of record type
@@ -542,6 +795,14 @@ func (p *AWSProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) e
 	return p.submitChanges(ctx, combinedChanges, zones)
 }
 
+// changeResourceRecordSets submits a change batch, measuring its latency.
+func (p *AWSProvider) changeResourceRecordSets(ctx context.Context, params *route53.ChangeResourceRecordSetsInput) error {
+	start := time.Now()
+	_, err := p.client.ChangeResourceRecordSetsWithContext(ctx, params)
+	provider.ObserveAPIRequest(ctx, "aws", provider.OperationApplyBatch, start, err)
+	return err
+}
+
 // submitChanges takes a zone and a collection of Changes and sends them as a single transaction.
 func (p *AWSProvider) submitChanges(ctx context.Context, changes Route53Changes, zones map[string]*route53.HostedZone) error {
 	// return early if there is nothing to change
@@ -558,83 +819,96 @@ func (p *AWSProvider) submitChanges(ctx context.Context, changes Route53Changes,
 
 	var failedZones []string
 	for z, cs := range changesByZone {
-		var failedUpdate bool
-
 		// group changes into new changes and into changes that failed in a previous iteration and are retried
+		p.failedChangesMux.Lock()
 		retriedChanges, newChanges := findChangesInQueue(cs, p.failedChangesQueue[z])
-		p.failedChangesQueue[z] = nil
+		p.failedChangesMux.Unlock()
 
-		batchCs := append(batchChangeSet(newChanges, p.batchChangeSize), batchChangeSet(retriedChanges, p.batchChangeSize)...)
-		for i, b := range batchCs {
-			if len(b) == 0 {
-				continue
-			}
+		failedChanges, failedUpdate := p.submitZoneChanges(ctx, z, zones[z], newChanges, retriedChanges)
 
-			for _, c := range b {
-				log.Infof("Desired change: %s %s %s [Id: %s]", *c.Action, *c.ResourceRecordSet.Name, *c.ResourceRecordSet.Type, z)
+		p.failedChangesMux.Lock()
+		p.failedChangesQueue[z] = failedChanges
+		p.failedChangesMux.Unlock()
+		if failedUpdate {
+			failedZones = append(failedZones, z)
+		}
+	}
+
+	if len(failedZones) > 0 {
+		return provider.NewSoftError(fmt.Errorf("failed to submit all changes for the following zones: %v", failedZones))
+	}
+
+	return nil
+}
+
+// submitZoneChanges submits the changes of a single hosted zone in batches. It returns the changes
+// that failed and are retried in the next iteration, and whether any change of the zone failed.
+func (p *AWSProvider) submitZoneChanges(ctx context.Context, z string, zone *route53.HostedZone, newChanges, retriedChanges Route53Changes) (failedChanges Route53Changes, failedUpdate bool) {
+	limits := changeBatchSize{changes: p.batchChangeSize, bytes: p.batchChangeSizeBytes, values: p.batchChangeSizeValues}
+	batchCs := append(batchChangeSet(newChanges, limits), batchChangeSet(retriedChanges, limits)...)
+	for i, b := range batchCs {
+		if len(b) == 0 {
+			continue
+		}
+
+		for _, c := range b {
+			log.Infof("Desired change: %s %s %s [Id: %s]", *c.Action, *c.ResourceRecordSet.Name, *c.ResourceRecordSet.Type, z)
+		}
+
+		if !p.dryRun {
+			params := &route53.ChangeResourceRecordSetsInput{
+				HostedZoneId: aws.String(z),
+				ChangeBatch: &route53.ChangeBatch{
+					Changes: b.Route53Changes(),
+					Comment: b.Comment(),
+				},
 			}
 
-			if !p.dryRun {
-				params := &route53.ChangeResourceRecordSetsInput{
-					HostedZoneId: aws.String(z),
-					ChangeBatch: &route53.ChangeBatch{
-						Changes: b.Route53Changes(),
-					},
-				}
+			successfulChanges := 0
+
+			if err := p.changeResourceRecordSets(ctx, params); err != nil {
+				log.Errorf("Failure in zone %s [Id: %s] when submitting change batch: %v", aws.StringValue(zone.Name), z, err)
+
+				changesByOwnership := groupChangesByNameAndOwnershipRelation(b)
 
-				successfulChanges := 0
-
-				if _, err := p.client.ChangeResourceRecordSetsWithContext(ctx, params); err != nil {
-					log.Errorf("Failure in zone %s [Id: %s] when submitting change batch: %v", aws.StringValue(zones[z].Name), z, err)
-
-					changesByOwnership := groupChangesByNameAndOwnershipRelation(b)
-
-					if len(changesByOwnership) > 1 {
-						log.Debug("Trying to submit change sets one-by-one instead")
-
-						for _, changes := range changesByOwnership {
-							for _, c := range changes {
-								log.Debugf("Desired change: %s %s %s [Id: %s]", *c.Action, *c.ResourceRecordSet.Name, *c.ResourceRecordSet.Type, z)
-							}
-							params.ChangeBatch = &route53.ChangeBatch{
-								Changes: changes.Route53Changes(),
-							}
-							if _, err := p.client.ChangeResourceRecordSetsWithContext(ctx, params); err != nil {
-								failedUpdate = true
-								log.Errorf("Failed submitting change (error: %v), it will be retried in a separate change batch in the next iteration", err)
-								p.failedChangesQueue[z] = append(p.failedChangesQueue[z], changes...)
-							} else {
-								successfulChanges = successfulChanges + len(changes)
-							}
+				if len(changesByOwnership) > 1 {
+					log.Debug("Trying to submit change sets one-by-one instead")
+
+					for _, changes := range changesByOwnership {
+						for _, c := range changes {
+							log.Debugf("Desired change: %s %s %s [Id: %s]", *c.Action, *c.ResourceRecordSet.Name, *c.ResourceRecordSet.Type, z)
+						}
+						params.ChangeBatch = &route53.ChangeBatch{
+							Changes: changes.Route53Changes(),
+							Comment: changes.Comment(),
+						}
+						if err := p.changeResourceRecordSets(ctx, params); err != nil {
+							failedUpdate = true
+							log.Errorf("Failed submitting change (error: %v), it will be retried in a separate change batch in the next iteration", err)
+							failedChanges = append(failedChanges, changes...)
+						} else {
+							successfulChanges = successfulChanges + len(changes)
 						}
-					} else {
-						failedUpdate = true
 					}
 				} else {
-					successfulChanges = len(b)
-				}
-
-				if successfulChanges > 0 {
-					// z is the R53 Hosted Zone ID already as aws.StringValue
-					log.Infof("%d record(s) in zone %s [Id: %s] were successfully updated", successfulChanges, aws.StringValue(zones[z].Name), z)
+					failedUpdate = true
 				}
+			} else {
+				successfulChanges = len(b)
+			}
 
-				if i != len(batchCs)-1 {
-					time.Sleep(p.batchChangeInterval)
-				}
+			if successfulChanges > 0 {
+				// z is the R53 Hosted Zone ID already as aws.StringValue
+				log.Infof("%d record(s) in zone %s [Id: %s] were successfully updated", successfulChanges, aws.StringValue(zone.Name), z)
 			}
-		}
 
-		if failedUpdate {
-			failedZones = append(failedZones, z)
+			if i != len(batchCs)-1 {
+				time.Sleep(p.batchChangeInterval)
+			}
 		}
 	}
 
-	if len(failedZones) > 0 {
-		return provider.NewSoftError(fmt.Errorf("failed to submit all changes for the following zones: %v", failedZones))
-	}
-
-	return nil
+	return failedChanges, failedUpdate
 }
 
 // newChanges returns a collection of Changes based on the given records and action.
@@ -666,10 +940,12 @@ func (p *AWSProvider) AdjustEndpoints(endpoints []*endpoint.Endpoint) ([]*endpoi
 		alias := false
 
 		if aliasString, ok := ep.GetProviderSpecificProperty(providerSpecificAlias); ok {
-			alias = aliasString == "true"
+			alias = propertySchema.Normalize(providerSpecificAlias, aliasString) == "true"
 			if alias {
 				if ep.RecordType != endpoint.RecordTypeA {
 					ep.DeleteProviderSpecificProperty(providerSpecificAlias)
+				} else if aliasString != "true" {
+					ep.SetProviderSpecificProperty(providerSpecificAlias, "true")
 				}
 			} else {
 				if ep.RecordType == endpoint.RecordTypeCNAME {
@@ -692,9 +968,13 @@ func (p *AWSProvider) AdjustEndpoints(endpoints []*endpoint.Endpoint) ([]*endpoi
 				log.Debugf("Modifying endpoint: %v, setting ttl=%v", ep, recordTTL)
 				ep.RecordTTL = recordTTL
 			}
-			if prop, ok := ep.GetProviderSpecificProperty(providerSpecificEvaluateTargetHealth); ok {
-				if prop != "true" && prop != "false" {
+			if !supportsTargetHealth(ep) {
+				ep.SetProviderSpecificProperty(providerSpecificEvaluateTargetHealth, "false")
+			} else if prop, ok := ep.GetProviderSpecificProperty(providerSpecificEvaluateTargetHealth); ok {
+				if normalized, err := provider.BoolProperty(prop); err != nil {
 					ep.SetProviderSpecificProperty(providerSpecificEvaluateTargetHealth, "false")
+				} else if normalized != prop {
+					ep.SetProviderSpecificProperty(providerSpecificEvaluateTargetHealth, normalized)
 				}
 			} else {
 				ep.SetProviderSpecificProperty(providerSpecificEvaluateTargetHealth, strconv.FormatBool(p.evaluateTargetHealth))
@@ -706,6 +986,20 @@ func (p *AWSProvider) AdjustEndpoints(endpoints []*endpoint.Endpoint) ([]*endpoi
 	return endpoints, nil
 }
 
+// propertySchema normalizes the provider specific properties whose values from annotations may
+// differ textually from the ones read from Route53, e.g. the weight "010" is read as "10" and
+// the boolean "1" as "true".
+var propertySchema = provider.PropertySchema{
+	providerSpecificAlias:                provider.BoolProperty,
+	providerSpecificEvaluateTargetHealth: provider.BoolProperty,
+	providerSpecificWeight:               provider.IntProperty,
+}
+
+// PropertyValuesEqual compares the values of provider specific properties semantically.
+func (p *AWSProvider) PropertyValuesEqual(name string, previous string, current string) bool {
+	return propertySchema.PropertyValuesEqual(name, previous, current)
+}
+
 // newChange returns a route53 Change and a boolean indicating if there should also be a change to a AAAA record
 // returned Change is based on the given record by the given action, e.g.
 // action=ChangeActionCreate returns a change for creation of the record and
@@ -725,6 +1019,9 @@ func (p *AWSProvider) newChange(action string, ep *endpoint.Endpoint) (*Route53C
 		if prop, ok := ep.GetProviderSpecificProperty(providerSpecificEvaluateTargetHealth); ok {
 			evalTargetHealth = prop == "true"
 		}
+		if _, unsupported := targetHealthUnsupportedZones[cleanZoneID(targetHostedZone)]; unsupported {
+			evalTargetHealth = false
+		}
 		// If the endpoint has a Dualstack label, append a change for AAAA record as well.
 		if val, ok := ep.Labels[endpoint.DualstackLabelKey]; ok {
 			dualstack = val == "true"
@@ -798,6 +1095,7 @@ func (p *AWSProvider) newChange(action string, ep *endpoint.Endpoint) (*Route53C
 	if ownedRecord, ok := ep.Labels[endpoint.OwnedRecordLabelKey]; ok {
 		change.OwnedRecord = ownedRecord
 	}
+	change.Description = ep.Labels[endpoint.DescriptionLabelKey]
 
 	return change, dualstack
 }
@@ -855,8 +1153,56 @@ func (p *AWSProvider) tagsForZone(ctx context.Context, zoneID string) (map[strin
 	return tagMap, nil
 }
 
-func batchChangeSet(cs Route53Changes, batchSize int) []Route53Changes {
-	if len(cs) <= batchSize {
+// changeBatchSize is the size of changes in the units of the limits of a change batch of Route53:
+// the number of changes, the number of ResourceRecord elements, and the number of characters of
+// their values.
+type changeBatchSize struct {
+	changes int
+	values  int
+	bytes   int
+}
+
+// sizeOf returns the size of the changes, an UPSERT counting twice for the values and their
+// characters, as it does for Route53. An alias counts as a value of its target.
+func sizeOf(cs Route53Changes) changeBatchSize {
+	size := changeBatchSize{changes: len(cs)}
+	for _, c := range cs {
+		values, bytes := len(c.ResourceRecordSet.ResourceRecords), 0
+		for _, rr := range c.ResourceRecordSet.ResourceRecords {
+			bytes += len(aws.StringValue(rr.Value))
+		}
+		if c.ResourceRecordSet.AliasTarget != nil {
+			values, bytes = 1, len(aws.StringValue(c.ResourceRecordSet.AliasTarget.DNSName))
+		}
+		if aws.StringValue(c.Action) == route53.ChangeActionUpsert {
+			values, bytes = 2*values, 2*bytes
+		}
+		size.values += values
+		size.bytes += bytes
+	}
+	return size
+}
+
+func (s changeBatchSize) add(o changeBatchSize) changeBatchSize {
+	return changeBatchSize{changes: s.changes + o.changes, values: s.values + o.values, bytes: s.bytes + o.bytes}
+}
+
+// exceeds returns whether the size exceeds one of the limits, a limit below 1 being unlimited.
+func (s changeBatchSize) exceeds(limits changeBatchSize) bool {
+	return (limits.changes > 0 && s.changes > limits.changes) ||
+		(limits.values > 0 && s.values > limits.values) ||
+		(limits.bytes > 0 && s.bytes > limits.bytes)
+}
+
+// batchChangeSet splits the changes into batches within the limits. The changes of a name and of
+// its ownership record are kept in the same batch, e.g. the deletion of a record and the creation
+// of the record of another type replacing it, and so are the changes of the names depending on each
+// other, an alias and the record of the same zone it targets, as long as they fit in a batch.
+// Otherwise, the changes of the names depending on each other are split into batches submitted in
+// the order of their dependencies, the targets created before their aliases and the aliases deleted
+// before their targets. The changes of a name exceeding the limits are not submitted.
+func batchChangeSet(cs Route53Changes, limits changeBatchSize) []Route53Changes {
+	if !sizeOf(cs).exceeds(limits) {
 		res := sortChangesByActionNameType(cs)
 		return []Route53Changes{res}
 	}
@@ -865,21 +1211,32 @@ func batchChangeSet(cs Route53Changes, batchSize int) []Route53Changes {
 
 	changesByOwnership := groupChangesByNameAndOwnershipRelation(cs)
 
-	names := make([]string, 0)
-	for v := range changesByOwnership {
-		names = append(names, v)
+	var units []Route53Changes
+	for _, names := range dependentNames(changesByOwnership) {
+		var changes Route53Changes
+		for _, name := range names {
+			changes = append(changes, changesByOwnership[name]...)
+		}
+		if len(names) == 1 || !sizeOf(changes).exceeds(limits) {
+			units = append(units, changes)
+			continue
+		}
+		log.Debugf("Splitting the changes of the dependent names %v into batches in the order of their dependencies", names)
+		for _, name := range orderByDependency(names, changesByOwnership) {
+			units = append(units, changesByOwnership[name])
+		}
 	}
-	sort.Strings(names)
 
 	currentBatch := Route53Changes{}
-	for k, name := range names {
-		v := changesByOwnership[name]
-		if len(v) > batchSize {
-			log.Warnf("Total changes for %v exceeds max batch size of %d, total changes: %d; changes will not be performed", k, batchSize, len(v))
+	for _, v := range units {
+		size := sizeOf(v)
+		if size.exceeds(limits) {
+			log.Warnf("Total changes for %v exceeds the limits of a batch of %d changes, %d values and %d characters, total changes: %d, values: %d, characters: %d; changes will not be performed",
+				aws.StringValue(v[0].ResourceRecordSet.Name), limits.changes, limits.values, limits.bytes, size.changes, size.values, size.bytes)
 			continue
 		}
 
-		if len(currentBatch)+len(v) > batchSize {
+		if sizeOf(currentBatch).add(size).exceeds(limits) {
 			// currentBatch would be too large if we add this changeset;
 			// add currentBatch to batchChanges and start a new currentBatch
 			batchChanges = append(batchChanges, sortChangesByActionNameType(currentBatch))
@@ -896,6 +1253,125 @@ func batchChangeSet(cs Route53Changes, batchSize int) []Route53Changes {
 	return batchChanges
 }
 
+// aliasDependencies returns, for every name of the changes, the names of the changes its aliases
+// target, and whether the alias is deleted.
+func aliasDependencies(changesByOwnership map[string]Route53Changes) map[string]map[string]bool {
+	byName := make(map[string]string, len(changesByOwnership))
+	for name := range changesByOwnership {
+		byName[normalizeAliasName(name)] = name
+	}
+	dependencies := map[string]map[string]bool{}
+	for name, changes := range changesByOwnership {
+		for _, c := range changes {
+			if c.ResourceRecordSet.AliasTarget == nil {
+				continue
+			}
+			target, ok := byName[normalizeAliasName(aws.StringValue(c.ResourceRecordSet.AliasTarget.DNSName))]
+			if !ok || target == name {
+				continue
+			}
+			if dependencies[name] == nil {
+				dependencies[name] = map[string]bool{}
+			}
+			dependencies[name][target] = aws.StringValue(c.Action) == route53.ChangeActionDelete
+		}
+	}
+	return dependencies
+}
+
+func normalizeAliasName(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, "."))
+}
+
+// dependentNames returns the names of the changes grouped with the names they depend on or which
+// depend on them, the groups and their names sorted.
+func dependentNames(changesByOwnership map[string]Route53Changes) [][]string {
+	parent := make(map[string]string, len(changesByOwnership))
+	var find func(name string) string
+	find = func(name string) string {
+		if parent[name] != name {
+			parent[name] = find(parent[name])
+		}
+		return parent[name]
+	}
+	for name := range changesByOwnership {
+		parent[name] = name
+	}
+	for name, targets := range aliasDependencies(changesByOwnership) {
+		for target := range targets {
+			a, b := find(name), find(target)
+			if a > b {
+				a, b = b, a
+			}
+			// the smallest name is the root of the group
+			parent[b] = a
+		}
+	}
+
+	byRoot := map[string][]string{}
+	for name := range changesByOwnership {
+		root := find(name)
+		byRoot[root] = append(byRoot[root], name)
+	}
+	roots := make([]string, 0, len(byRoot))
+	for root := range byRoot {
+		roots = append(roots, root)
+	}
+	sort.Strings(roots)
+
+	groups := make([][]string, 0, len(roots))
+	for _, root := range roots {
+		sort.Strings(byRoot[root])
+		groups = append(groups, byRoot[root])
+	}
+	return groups
+}
+
+// orderByDependency returns the sorted names in the order their changes can be submitted in: the
+// targets of the aliases created or updated before them, and the aliases deleted before their
+// targets. The names depending on each other in a cycle are kept in their sorted order.
+func orderByDependency(names []string, changesByOwnership map[string]Route53Changes) []string {
+	before := map[string][]string{}
+	pending := make(map[string]int, len(names))
+	for name, targets := range aliasDependencies(changesByOwnership) {
+		for target, deleted := range targets {
+			first, then := target, name
+			if deleted {
+				first, then = name, target
+			}
+			before[first] = append(before[first], then)
+			pending[then]++
+		}
+	}
+
+	ordered := make([]string, 0, len(names))
+	done := make(map[string]bool, len(names))
+	for len(ordered) < len(names) {
+		next := ""
+		for _, name := range names {
+			if !done[name] && pending[name] == 0 {
+				next = name
+				break
+			}
+		}
+		if next == "" {
+			// a cycle, the first remaining name is submitted first
+			for _, name := range names {
+				if !done[name] {
+					next = name
+					break
+				}
+			}
+		}
+		done[next] = true
+		ordered = append(ordered, next)
+		for _, then := range before[next] {
+			pending[then]--
+		}
+	}
+	return ordered
+}
+
 func sortChangesByActionNameType(cs Route53Changes) Route53Changes {
 	sort.SliceStable(cs, func(i, j int) bool {
 		if *cs[i].Action > *cs[j].Action {
@@ -1026,6 +1502,19 @@ func isAWSAlias(ep *endpoint.Endpoint) string {
 	return ""
 }
 
+// supportsTargetHealth determines if the alias target of the given endpoint allows evaluating its health.
+func supportsTargetHealth(ep *endpoint.Endpoint) bool {
+	if len(ep.Targets) == 0 {
+		return true
+	}
+	hostedZoneID, ok := ep.GetProviderSpecificProperty(providerSpecificTargetHostedZone)
+	if !ok {
+		hostedZoneID = canonicalHostedZone(strings.TrimSuffix(ep.Targets[0], "."))
+	}
+	_, unsupported := targetHealthUnsupportedZones[cleanZoneID(hostedZoneID)]
+	return !unsupported
+}
+
 // canonicalHostedZone returns the matching canonical zone for a given hostname.
 func canonicalHostedZone(hostname string) string {
 	for suffix, zone := range canonicalHostedZones {
@@ -22,12 +22,15 @@ import (
 	"net"
 	"sort"
 	"strings"
+	"sync"
 	"testing"
 	"time"
+	"unicode/utf8"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/aws/aws-sdk-go/service/route53profiles"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -52,6 +55,8 @@ var _ Route53API = &Route53APIStub{}
 // of all of its methods.
 // mostly taken from: https://github.com/kubernetes/kubernetes/blob/853167624edb6bc0cfdcdfb88e746e178f5db36c/federation/pkg/dnsprovider/providers/aws/route53/stubs/route53api.go
 type Route53APIStub struct {
+	// mu serializes changes submitted concurrently for several zones
+	mu         sync.Mutex
 	zones      map[string]*route53.HostedZone
 	recordSets map[string]map[string][]*route53.ResourceRecordSet
 	zoneTags   map[string][]*route53.Tag
@@ -159,6 +164,9 @@ func (r *Route53APIStub) ChangeResourceRecordSetsWithContext(ctx context.Context
 		return r.m.ChangeResourceRecordSets(input)
 	}
 
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	_, ok := r.zones[aws.StringValue(input.HostedZoneId)]
 	if !ok {
 		return nil, fmt.Errorf("Hosted zone doesn't exist: %s", aws.StringValue(input.HostedZoneId))
@@ -313,6 +321,85 @@ func TestAWSZones(t *testing.T) {
 	}
 }
 
+// route53ProfilesStub returns the resources associated with the profiles, one page per resource.
+type route53ProfilesStub struct {
+	associations map[string][]*route53profiles.ProfileResourceAssociation
+	err          error
+}
+
+func (s *route53ProfilesStub) ListProfileResourceAssociationsPagesWithContext(ctx context.Context, input *route53profiles.ListProfileResourceAssociationsInput, fn func(resp *route53profiles.ListProfileResourceAssociationsOutput, lastPage bool) (shouldContinue bool), opts ...request.Option) error {
+	if s.err != nil {
+		return s.err
+	}
+	associations := s.associations[aws.StringValue(input.ProfileId)]
+	for i, association := range associations {
+		if !fn(&route53profiles.ListProfileResourceAssociationsOutput{ProfileResourceAssociations: []*route53profiles.ProfileResourceAssociation{association}}, i == len(associations)-1) {
+			break
+		}
+	}
+	return nil
+}
+
+func TestAWSZonesWithProfiles(t *testing.T) {
+	profiles := &route53ProfilesStub{associations: map[string][]*route53profiles.ProfileResourceAssociation{
+		"rp-1": {
+			{ResourceArn: aws.String("arn:aws:route53:::hostedzone/zone-3.ext-dns-test-2.teapot.zalan.do."), Status: aws.String(route53profiles.ProfileStatusComplete)},
+			{ResourceArn: aws.String("arn:aws:route53:::hostedzone/zone-1.ext-dns-test-2.teapot.zalan.do."), Status: aws.String(route53profiles.ProfileStatusCreating)},
+			{ResourceArn: aws.String("arn:aws:route53resolver:us-east-1:123456789012:firewall-rule-group/rslvr-frg-1"), Status: aws.String(route53profiles.ProfileStatusComplete)},
+		},
+		"rp-2": {
+			{ResourceArn: aws.String("arn:aws:route53:::hostedzone/zone-2.ext-dns-test-2.teapot.zalan.do."), Status: aws.String(route53profiles.ProfileStatusComplete)},
+		},
+	}}
+
+	for _, ti := range []struct {
+		msg           string
+		profileIDs    []string
+		expectedZones []string
+	}{
+		{"one profile", []string{"rp-1"}, []string{"/hostedzone/zone-3.ext-dns-test-2.teapot.zalan.do."}},
+		{"many profiles", []string{"rp-1", "rp-2"}, []string{"/hostedzone/zone-2.ext-dns-test-2.teapot.zalan.do.", "/hostedzone/zone-3.ext-dns-test-2.teapot.zalan.do."}},
+		{"unknown profile", []string{"rp-3"}, []string{}},
+	} {
+		t.Run(ti.msg, func(t *testing.T) {
+			provider, _ := newAWSProvider(t, endpoint.NewDomainFilter([]string{"ext-dns-test-2.teapot.zalan.do."}), provider.NewZoneIDFilter([]string{}), provider.NewZoneTypeFilter(""), defaultEvaluateTargetHealth, false, nil)
+			provider.profileIDs = ti.profileIDs
+			provider.profilesClient = profiles
+			provider.zonesCache = &zonesListCache{}
+
+			zones, err := provider.Zones(context.Background())
+			require.NoError(t, err)
+
+			ids := []string{}
+			for id := range zones {
+				ids = append(ids, id)
+			}
+			assert.ElementsMatch(t, ti.expectedZones, ids)
+		})
+	}
+}
+
+func TestAWSZonesWithProfilesError(t *testing.T) {
+	p, _ := newAWSProvider(t, endpoint.NewDomainFilter([]string{"ext-dns-test-2.teapot.zalan.do."}), provider.NewZoneIDFilter([]string{}), provider.NewZoneTypeFilter(""), defaultEvaluateTargetHealth, false, nil)
+	p.profileIDs = []string{"rp-1"}
+	p.profilesClient = &route53ProfilesStub{err: fmt.Errorf("access denied")}
+	p.zonesCache = &zonesListCache{}
+
+	_, err := p.Zones(context.Background())
+	require.Error(t, err)
+	assert.ErrorIs(t, err, provider.SoftError)
+
+	// the zones of a profile shared by another account aren't listed with the credentials of this one
+	p.profilesClient = &route53ProfilesStub{associations: map[string][]*route53profiles.ProfileResourceAssociation{
+		"rp-1": {
+			{ResourceArn: aws.String("arn:aws:route53:::hostedzone/Z1D633PJN98FT9"), Status: aws.String(route53profiles.ProfileStatusComplete)},
+		},
+	}}
+	_, err = p.Zones(context.Background())
+	require.ErrorContains(t, err, "must be those of the account owning the zones")
+	assert.NotErrorIs(t, err, provider.SoftError)
+}
+
 func TestAWSRecordsFilter(t *testing.T) {
 	provider, _ := newAWSProvider(t, endpoint.DomainFilter{}, provider.ZoneIDFilter{}, provider.ZoneTypeFilter{}, false, false, nil)
 	domainFilter := provider.GetDomainFilter()
@@ -527,6 +614,10 @@ func TestAWSAdjustEndpoints(t *testing.T) {
 		endpoint.NewEndpoint("cname-test-elb.zone-2.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeCNAME, "foo.eu-central-1.elb.amazonaws.com"),
 		endpoint.NewEndpoint("cname-test-elb-no-alias.zone-2.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeCNAME, "foo.eu-central-1.elb.amazonaws.com").WithProviderSpecific(providerSpecificAlias, "false"),
 		endpoint.NewEndpoint("cname-test-elb-no-eth.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeCNAME, "foo.eu-central-1.elb.amazonaws.com").WithProviderSpecific(providerSpecificEvaluateTargetHealth, "false"), // eth = evaluate target health
+		endpoint.NewEndpoint("cname-test-cloudfront.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeCNAME, "d111111abcdef8.cloudfront.net").WithProviderSpecific(providerSpecificEvaluateTargetHealth, "true"),
+		endpoint.NewEndpoint("cname-test-s3.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeCNAME, "s3-website-us-east-1.amazonaws.com"),
+		endpoint.NewEndpoint("a-test-alias-bool.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "alias-target.zone-2.ext-dns-test-2.teapot.zalan.do").WithProviderSpecific(providerSpecificAlias, "1"),
+		endpoint.NewEndpoint("cname-test-elb-eth-bool.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeCNAME, "foo.eu-central-1.elb.amazonaws.com").WithProviderSpecific(providerSpecificEvaluateTargetHealth, "0"),
 	}
 
 	records, err := provider.AdjustEndpoints(records)
@@ -539,6 +630,10 @@ func TestAWSAdjustEndpoints(t *testing.T) {
 		endpoint.NewEndpoint("cname-test-elb.zone-2.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "foo.eu-central-1.elb.amazonaws.com").WithProviderSpecific(providerSpecificAlias, "true").WithProviderSpecific(providerSpecificEvaluateTargetHealth, "true"),
 		endpoint.NewEndpoint("cname-test-elb-no-alias.zone-2.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeCNAME, "foo.eu-central-1.elb.amazonaws.com").WithProviderSpecific(providerSpecificAlias, "false"),
 		endpoint.NewEndpoint("cname-test-elb-no-eth.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "foo.eu-central-1.elb.amazonaws.com").WithProviderSpecific(providerSpecificAlias, "true").WithProviderSpecific(providerSpecificEvaluateTargetHealth, "false"), // eth = evaluate target health
+		endpoint.NewEndpoint("cname-test-cloudfront.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "d111111abcdef8.cloudfront.net").WithProviderSpecific(providerSpecificAlias, "true").WithProviderSpecific(providerSpecificEvaluateTargetHealth, "false"),
+		endpoint.NewEndpoint("cname-test-s3.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "s3-website-us-east-1.amazonaws.com").WithProviderSpecific(providerSpecificAlias, "true").WithProviderSpecific(providerSpecificEvaluateTargetHealth, "true"),
+		endpoint.NewEndpoint("a-test-alias-bool.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "alias-target.zone-2.ext-dns-test-2.teapot.zalan.do").WithProviderSpecific(providerSpecificAlias, "true").WithProviderSpecific(providerSpecificEvaluateTargetHealth, "true"),
+		endpoint.NewEndpoint("cname-test-elb-eth-bool.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "foo.eu-central-1.elb.amazonaws.com").WithProviderSpecific(providerSpecificAlias, "true").WithProviderSpecific(providerSpecificEvaluateTargetHealth, "false"),
 	})
 }
 
@@ -1242,6 +1337,139 @@ func TestAWSsubmitChangesRetryOnError(t *testing.T) {
 	require.True(t, containsRecordWithDNSName(records, "fail__edns_housekeeping.zone-1.ext-dns-test-2.teapot.zalan.do"))
 }
 
+func TestAWSsubmitChangesFailedZone(t *testing.T) {
+	provider, clientStub := newAWSProvider(t, endpoint.NewDomainFilter([]string{"ext-dns-test-2.teapot.zalan.do."}), provider.NewZoneIDFilter([]string{}), provider.NewZoneTypeFilter(""), defaultEvaluateTargetHealth, false, nil)
+
+	ctx := context.Background()
+	zones, err := provider.Zones(ctx)
+	require.NoError(t, err)
+
+	ep1 := endpoint.NewEndpointWithTTL("success.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, endpoint.TTL(recordTTL), "1.0.0.1")
+	ep2 := endpoint.NewEndpointWithTTL("fail.zone-2.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, endpoint.TTL(recordTTL), "1.0.0.2")
+	ep3 := endpoint.NewEndpointWithTTL("success.zone-3.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, endpoint.TTL(recordTTL), "1.0.0.3")
+
+	// the changes of zone-2 fail, the changes of the other zones must be applied nevertheless
+	failing := provider.newChanges(route53.ChangeActionCreate, []*endpoint.Endpoint{ep2})
+	clientStub.MockMethod("ChangeResourceRecordSets", &route53.ChangeResourceRecordSetsInput{
+		HostedZoneId: aws.String("/hostedzone/zone-2.ext-dns-test-2.teapot.zalan.do."),
+		ChangeBatch: &route53.ChangeBatch{
+			Changes: failing.Route53Changes(),
+		},
+	}).Return(nil, fmt.Errorf("Mock route53 failure"))
+
+	cs := provider.newChanges(route53.ChangeActionCreate, []*endpoint.Endpoint{ep1, ep2, ep3})
+	err = provider.submitChanges(ctx, cs, zones)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "/hostedzone/zone-2.ext-dns-test-2.teapot.zalan.do.")
+	assert.NotContains(t, err.Error(), "/hostedzone/zone-1.ext-dns-test-2.teapot.zalan.do.")
+
+	records, err := provider.Records(ctx)
+	require.NoError(t, err)
+	assert.True(t, containsRecordWithDNSName(records, "success.zone-1.ext-dns-test-2.teapot.zalan.do"))
+	assert.False(t, containsRecordWithDNSName(records, "fail.zone-2.ext-dns-test-2.teapot.zalan.do"))
+	assert.True(t, containsRecordWithDNSName(records, "success.zone-3.ext-dns-test-2.teapot.zalan.do"))
+}
+
+// pagingRoute53Stub lists the record sets of the hosted zones two at a time, like Route53 does with
+// MaxItems, starting from the record set of the input.
+type pagingRoute53Stub struct {
+	*Route53APIStub
+	recordSets map[string][]*route53.ResourceRecordSet
+	requests   int
+}
+
+func (r *pagingRoute53Stub) ListResourceRecordSetsPagesWithContext(ctx context.Context, input *route53.ListResourceRecordSetsInput, fn func(p *route53.ListResourceRecordSetsOutput, lastPage bool) (shouldContinue bool), opts ...request.Option) error {
+	sets := r.recordSets[aws.StringValue(input.HostedZoneId)]
+	start := 0
+	if input.StartRecordName != nil {
+		for start < len(sets) && (aws.StringValue(sets[start].Name) != aws.StringValue(input.StartRecordName) ||
+			aws.StringValue(sets[start].Type) != aws.StringValue(input.StartRecordType) ||
+			aws.StringValue(sets[start].SetIdentifier) != aws.StringValue(input.StartRecordIdentifier)) {
+			start++
+		}
+	}
+	for {
+		end := min(start+2, len(sets))
+		output := &route53.ListResourceRecordSetsOutput{ResourceRecordSets: sets[start:end]}
+		if end < len(sets) {
+			output.IsTruncated = aws.Bool(true)
+			output.NextRecordName = sets[end].Name
+			output.NextRecordType = sets[end].Type
+			output.NextRecordIdentifier = sets[end].SetIdentifier
+		}
+		r.requests++
+		if !fn(output, end == len(sets)) || end == len(sets) {
+			return nil
+		}
+		start = end
+	}
+}
+
+func TestAWSRecordsPage(t *testing.T) {
+	p, stub := newAWSProvider(t, endpoint.NewDomainFilter([]string{"zone-1.ext-dns-test-2.teapot.zalan.do."}), provider.NewZoneIDFilter([]string{}), provider.NewZoneTypeFilter(""), defaultEvaluateTargetHealth, false, nil)
+	recordSet := func(name, setIdentifier, target string) *route53.ResourceRecordSet {
+		rs := &route53.ResourceRecordSet{
+			Name:            aws.String(name + ".zone-1.ext-dns-test-2.teapot.zalan.do."),
+			Type:            aws.String(route53.RRTypeA),
+			TTL:             aws.Int64(recordTTL),
+			ResourceRecords: []*route53.ResourceRecord{{Value: aws.String(target)}},
+		}
+		if setIdentifier != "" {
+			rs.SetIdentifier = aws.String(setIdentifier)
+			rs.Weight = aws.Int64(10)
+		}
+		return rs
+	}
+	client := &pagingRoute53Stub{
+		Route53APIStub: stub,
+		recordSets: map[string][]*route53.ResourceRecordSet{
+			"/hostedzone/zone-1.ext-dns-test-2.teapot.zalan.do.": {
+				recordSet("a", "", "1.1.1.1"),
+				recordSet("b", "one", "2.2.2.1"),
+				recordSet("b", "two", "2.2.2.2"),
+				recordSet("c", "", "3.3.3.3"),
+				recordSet("d", "", "4.4.4.4"),
+			},
+		},
+	}
+	p.client = client
+	ctx := context.Background()
+
+	names := func(endpoints []*endpoint.Endpoint) []string {
+		var result []string
+		for _, ep := range endpoints {
+			result = append(result, ep.DNSName+"/"+ep.SetIdentifier)
+		}
+		return result
+	}
+
+	// the response ending between the record sets of b is read on, so that the page holds all of them
+	records, next, err := p.RecordsPage(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"a.zone-1.ext-dns-test-2.teapot.zalan.do/",
+		"b.zone-1.ext-dns-test-2.teapot.zalan.do/one",
+		"b.zone-1.ext-dns-test-2.teapot.zalan.do/two",
+		"c.zone-1.ext-dns-test-2.teapot.zalan.do/",
+	}, names(records))
+	assert.Equal(t, 2, client.requests)
+	require.NotEmpty(t, next)
+
+	records, next, err = p.RecordsPage(ctx, next)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"d.zone-1.ext-dns-test-2.teapot.zalan.do/"}, names(records))
+	assert.Empty(t, next)
+
+	all, err := provider.Paginate(ctx, provider.DefaultRetryConfig, p.RecordsPage)
+	require.NoError(t, err)
+	expected, err := p.Records(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, expected, all)
+
+	_, _, err = p.RecordsPage(ctx, "invalid")
+	assert.Error(t, err)
+}
+
 func TestAWSBatchChangeSet(t *testing.T) {
 	var cs Route53Changes
 
@@ -1266,7 +1494,7 @@ func TestAWSBatchChangeSet(t *testing.T) {
 		})
 	}
 
-	batchCs := batchChangeSet(cs, defaultBatchChangeSize)
+	batchCs := batchChangeSet(cs, changeBatchSize{changes: defaultBatchChangeSize})
 
 	require.Equal(t, 1, len(batchCs))
 
@@ -1274,6 +1502,30 @@ func TestAWSBatchChangeSet(t *testing.T) {
 	validateAWSChangeRecords(t, batchCs[0], cs)
 }
 
+func TestAWSChangeBatchComment(t *testing.T) {
+	assert.Nil(t, Route53Changes{{}, {}}.Comment())
+
+	cs := Route53Changes{
+		{Description: "create for ingress/default/web"},
+		{Description: "create for ingress/default/web"},
+		{},
+		{Description: "delete for service/default/api"},
+	}
+	assert.Equal(t, "create for ingress/default/web; delete for service/default/api", aws.StringValue(cs.Comment()))
+
+	cs = Route53Changes{{Description: strings.Repeat("a", 300)}}
+	assert.Len(t, aws.StringValue(cs.Comment()), maxChangeBatchCommentLength)
+
+	// the 3 bytes of the rune at the limit are dropped together
+	cs = Route53Changes{{Description: strings.Repeat("a", maxChangeBatchCommentLength-1) + "€ and more"}}
+	comment := aws.StringValue(cs.Comment())
+	assert.True(t, utf8.ValidString(comment))
+	assert.Equal(t, strings.Repeat("a", maxChangeBatchCommentLength-1), comment)
+
+	cs = Route53Changes{{Description: strings.Repeat("a", maxChangeBatchCommentLength-3) + "€ and more"}}
+	assert.Equal(t, strings.Repeat("a", maxChangeBatchCommentLength-3)+"€", aws.StringValue(cs.Comment()))
+}
+
 func TestAWSBatchChangeSetExceeding(t *testing.T) {
 	var cs Route53Changes
 	const testCount = 50
@@ -1304,7 +1556,7 @@ func TestAWSBatchChangeSetExceeding(t *testing.T) {
 		)
 	}
 
-	batchCs := batchChangeSet(cs, testLimit)
+	batchCs := batchChangeSet(cs, changeBatchSize{changes: testLimit})
 
 	require.Equal(t, expectedBatchCount, len(batchCs))
 
@@ -1342,11 +1594,109 @@ func TestAWSBatchChangeSetExceedingNameChange(t *testing.T) {
 		)
 	}
 
-	batchCs := batchChangeSet(cs, testLimit)
+	batchCs := batchChangeSet(cs, changeBatchSize{changes: testLimit})
 
 	require.Equal(t, 0, len(batchCs))
 }
 
+func newRecordChange(action, name string, values ...string) *Route53Change {
+	change := &Route53Change{Change: route53.Change{
+		Action:            aws.String(action),
+		ResourceRecordSet: &route53.ResourceRecordSet{Name: aws.String(name), Type: aws.String(route53.RRTypeA)},
+	}}
+	for _, value := range values {
+		change.ResourceRecordSet.ResourceRecords = append(change.ResourceRecordSet.ResourceRecords, &route53.ResourceRecord{Value: aws.String(value)})
+	}
+	return change
+}
+
+func newAliasChange(action, name, target string) *Route53Change {
+	return &Route53Change{Change: route53.Change{
+		Action: aws.String(action),
+		ResourceRecordSet: &route53.ResourceRecordSet{
+			Name:        aws.String(name),
+			Type:        aws.String(route53.RRTypeA),
+			AliasTarget: &route53.AliasTarget{DNSName: aws.String(target), HostedZoneId: aws.String("/hostedzone/zone-1")},
+		},
+	}}
+}
+
+func batchNames(batches []Route53Changes) [][]string {
+	names := make([][]string, 0, len(batches))
+	for _, batch := range batches {
+		var batchNames []string
+		for _, c := range batch {
+			batchNames = append(batchNames, aws.StringValue(c.Action)+" "+aws.StringValue(c.ResourceRecordSet.Name))
+		}
+		names = append(names, batchNames)
+	}
+	return names
+}
+
+func TestAWSBatchChangeSetSizeLimits(t *testing.T) {
+	cs := Route53Changes{
+		newRecordChange(route53.ChangeActionCreate, "a.example.org", "1.1.1.1", "1.1.1.2"),
+		newRecordChange(route53.ChangeActionUpsert, "b.example.org", "2.2.2.2"),
+		newRecordChange(route53.ChangeActionCreate, "c.example.org", "3.3.3.3"),
+		newAliasChange(route53.ChangeActionCreate, "d.example.org", "lb.elb.amazonaws.com"),
+	}
+	assert.Equal(t, changeBatchSize{changes: 4, values: 6, bytes: 55}, sizeOf(cs))
+
+	// the values of an UPSERT count twice
+	assert.Equal(t, [][]string{
+		{"CREATE a.example.org"},
+		{"UPSERT b.example.org"},
+		{"CREATE c.example.org", "CREATE d.example.org"},
+	}, batchNames(batchChangeSet(cs, changeBatchSize{changes: 10, values: 2})))
+
+	assert.Equal(t, [][]string{
+		{"CREATE a.example.org"},
+		{"UPSERT b.example.org"},
+		{"CREATE c.example.org"},
+		{"CREATE d.example.org"},
+	}, batchNames(batchChangeSet(cs, changeBatchSize{changes: 10, bytes: 20})))
+
+	// the changes exceeding the limits are not submitted
+	assert.Equal(t, [][]string{
+		{"CREATE a.example.org"},
+		{"UPSERT b.example.org"},
+		{"CREATE c.example.org"},
+	}, batchNames(batchChangeSet(cs, changeBatchSize{changes: 10, bytes: 15})))
+}
+
+func TestAWSBatchChangeSetDependencies(t *testing.T) {
+	cs := Route53Changes{
+		newAliasChange(route53.ChangeActionCreate, "api.example.org", "zeta.example.org."),
+		newRecordChange(route53.ChangeActionCreate, "zeta.example.org", "1.1.1.1"),
+		newRecordChange(route53.ChangeActionCreate, "beta.example.org", "2.2.2.2"),
+		newRecordChange(route53.ChangeActionCreate, "gamma.example.org", "3.3.3.3"),
+	}
+
+	// the alias is submitted along with its target
+	assert.Equal(t, [][]string{
+		{"CREATE api.example.org", "CREATE zeta.example.org"},
+		{"CREATE beta.example.org", "CREATE gamma.example.org"},
+	}, batchNames(batchChangeSet(cs, changeBatchSize{changes: 2})))
+
+	// the target is created before the alias when they don't fit in a batch
+	assert.Equal(t, [][]string{
+		{"CREATE zeta.example.org"},
+		{"CREATE api.example.org"},
+		{"CREATE beta.example.org"},
+		{"CREATE gamma.example.org"},
+	}, batchNames(batchChangeSet(cs, changeBatchSize{changes: 1})))
+
+	// the alias is deleted before its target
+	cs = Route53Changes{
+		newRecordChange(route53.ChangeActionDelete, "alpha.example.org", "1.1.1.1"),
+		newAliasChange(route53.ChangeActionDelete, "www.example.org", "alpha.example.org"),
+	}
+	assert.Equal(t, [][]string{
+		{"DELETE www.example.org"},
+		{"DELETE alpha.example.org"},
+	}, batchNames(batchChangeSet(cs, changeBatchSize{changes: 1})))
+}
+
 func validateEndpoints(t *testing.T, provider *AWSProvider, endpoints []*endpoint.Endpoint, expected []*endpoint.Endpoint) {
 	assert.True(t, testutils.SameEndpoints(endpoints, expected), "actual and expected endpoints don't match. %+v:%+v", endpoints, expected)
 
@@ -1547,6 +1897,28 @@ func TestAWSCanonicalHostedZone(t *testing.T) {
 	assert.Equal(t, "", zone, "no canonical zone should be returned for a non-aws hostname")
 }
 
+func TestAWSSupportsTargetHealth(t *testing.T) {
+	for _, tc := range []struct {
+		target   string
+		hz       string
+		expected bool
+	}{
+		{"foo.eu-central-1.elb.amazonaws.com", "", true},
+		{"d111111abcdef8.cloudfront.net", "", false},
+		{"d111111abcdef8.cloudfront.net.", "", false},
+		{"a1234567890abcdef.awsglobalaccelerator.com", "", true},
+		{"s3-website.eu-central-1.amazonaws.com", "", true},
+		{"cdn.example.org", "/hostedzone/Z2FDTNDATAQYW2", false},
+		{"foo.example.org", "", true},
+	} {
+		ep := endpoint.NewEndpoint("test.example.org", endpoint.RecordTypeA, tc.target).WithProviderSpecific(providerSpecificAlias, "true")
+		if tc.hz != "" {
+			ep = ep.WithProviderSpecific(providerSpecificTargetHostedZone, tc.hz)
+		}
+		assert.Equal(t, tc.expected, supportsTargetHealth(ep), "%v", tc)
+	}
+}
+
 func TestAWSSuitableZones(t *testing.T) {
 	zones := map[string]*route53.HostedZone{
 		// Public domain
@@ -1773,3 +2145,14 @@ func TestRequiresDeleteCreate(t *testing.T) {
 	assert.False(t, provider.requiresDeleteCreate(oldSetIdentifier, oldSetIdentifier), "actual and expected endpoints don't match. %+v:%+v", oldSetIdentifier, oldSetIdentifier)
 	assert.True(t, provider.requiresDeleteCreate(oldSetIdentifier, newSetIdentifier), "actual and expected endpoints don't match. %+v:%+v", oldSetIdentifier, newSetIdentifier)
 }
+
+func TestAWSPropertyValuesEqual(t *testing.T) {
+	provider := &AWSProvider{}
+
+	assert.True(t, provider.PropertyValuesEqual(providerSpecificWeight, "10", "010"))
+	assert.False(t, provider.PropertyValuesEqual(providerSpecificWeight, "10", "20"))
+	assert.True(t, provider.PropertyValuesEqual(providerSpecificEvaluateTargetHealth, "true", "1"))
+	assert.False(t, provider.PropertyValuesEqual(providerSpecificEvaluateTargetHealth, "true", "0"))
+	assert.True(t, provider.PropertyValuesEqual(providerSpecificAlias, "false", "False"))
+	assert.False(t, provider.PropertyValuesEqual(providerSpecificRegion, "us-east-1", "US-EAST-1"))
+}
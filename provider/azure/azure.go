@@ -163,6 +163,21 @@ func (p *AzureProvider) ApplyChanges(ctx context.Context, changes *plan.Changes)
 	return nil
 }
 
+// ZoneNames returns the names of the zones of the resource group.
+func (p *AzureProvider) ZoneNames(ctx context.Context) ([]string, error) {
+	zones, err := p.zones(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(zones))
+	for _, z := range zones {
+		if z.Name != nil {
+			names = append(names, *z.Name)
+		}
+	}
+	return names, nil
+}
+
 func (p *AzureProvider) zones(ctx context.Context) ([]dns.Zone, error) {
 	log.Debugf("Retrieving Azure DNS zones for resource group: %s.", p.resourceGroup)
 	var zones []dns.Zone
@@ -21,6 +21,8 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
+	"time"
 
 	azcoreruntime "github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
@@ -44,6 +46,19 @@ type PrivateRecordSetsClient interface {
 	CreateOrUpdate(ctx context.Context, resourceGroupName string, privateZoneName string, recordType privatedns.RecordType, relativeRecordSetName string, parameters privatedns.RecordSet, options *privatedns.RecordSetsClientCreateOrUpdateOptions) (privatedns.RecordSetsClientCreateOrUpdateResponse, error)
 }
 
+// PrivateVirtualNetworkLinksClient is an interface of privatedns.VirtualNetworkLinksClient that can be stubbed for testing.
+type PrivateVirtualNetworkLinksClient interface {
+	NewListPager(resourceGroupName string, privateZoneName string, options *privatedns.VirtualNetworkLinksClientListOptions) *azcoreruntime.Pager[privatedns.VirtualNetworkLinksClientListResponse]
+	BeginCreateOrUpdate(ctx context.Context, resourceGroupName string, privateZoneName string, virtualNetworkLinkName string, parameters privatedns.VirtualNetworkLink, options *privatedns.VirtualNetworkLinksClientBeginCreateOrUpdateOptions) (*azcoreruntime.Poller[privatedns.VirtualNetworkLinksClientCreateOrUpdateResponse], error)
+}
+
+// vnetLinksCheckInterval is how long the virtual network links of a zone are trusted before
+// they are checked again, so links deleted outside of ExternalDNS are eventually restored.
+const vnetLinksCheckInterval = time.Hour
+
+// vnetLinkCreateTimeout is how long the creation of a virtual network link is awaited.
+const vnetLinkCreateTimeout = 5 * time.Minute
+
 // AzurePrivateDNSProvider implements the DNS provider for Microsoft's Azure Private DNS service
 type AzurePrivateDNSProvider struct {
 	provider.BaseProvider
@@ -52,14 +67,25 @@ type AzurePrivateDNSProvider struct {
 	dryRun                       bool
 	resourceGroup                string
 	userAssignedIdentityClientID string
-	zonesClient                  PrivateZonesClient
-	recordSetsClient             PrivateRecordSetsClient
+	// virtual networks every managed zone has to be linked to
+	vnetIDs         []string
+	createVNetLinks bool
+	// the time the virtual network links of a zone were last checked successfully, guarded by linksMux
+	linkedZones      map[string]time.Time
+	linksMux         sync.Mutex
+	zonesClient      PrivateZonesClient
+	recordSetsClient PrivateRecordSetsClient
+	vnetLinksClient  PrivateVirtualNetworkLinksClient
 }
 
 // NewAzurePrivateDNSProvider creates a new Azure Private DNS provider.
 //
+// When vnetIDs are given, every managed zone is checked for links to these virtual networks at
+// startup and when changes are applied, at most once per vnetLinksCheckInterval. Missing links are
+// created if createVNetLinks is set, otherwise they are only reported.
+//
 // Returns the provider or an error if a provider could not be created.
-func NewAzurePrivateDNSProvider(configFile string, domainFilter endpoint.DomainFilter, zoneIDFilter provider.ZoneIDFilter, resourceGroup, userAssignedIdentityClientID string, dryRun bool) (*AzurePrivateDNSProvider, error) {
+func NewAzurePrivateDNSProvider(configFile string, domainFilter endpoint.DomainFilter, zoneIDFilter provider.ZoneIDFilter, resourceGroup, userAssignedIdentityClientID string, vnetIDs []string, createVNetLinks bool, dryRun bool) (*AzurePrivateDNSProvider, error) {
 	cfg, err := getConfig(configFile, resourceGroup, userAssignedIdentityClientID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read Azure config file '%s': %v", configFile, err)
@@ -77,15 +103,33 @@ func NewAzurePrivateDNSProvider(configFile string, domainFilter endpoint.DomainF
 	if err != nil {
 		return nil, err
 	}
-	return &AzurePrivateDNSProvider{
+	vnetLinksClient, err := privatedns.NewVirtualNetworkLinksClient(cfg.SubscriptionID, cred, clientOpts)
+	if err != nil {
+		return nil, err
+	}
+	p := &AzurePrivateDNSProvider{
 		domainFilter:                 domainFilter,
 		zoneIDFilter:                 zoneIDFilter,
 		dryRun:                       dryRun,
 		resourceGroup:                cfg.ResourceGroup,
 		userAssignedIdentityClientID: cfg.UserAssignedIdentityID,
+		vnetIDs:                      vnetIDs,
+		createVNetLinks:              createVNetLinks,
+		linkedZones:                  map[string]time.Time{},
 		zonesClient:                  zonesClient,
 		recordSetsClient:             recordSetsClient,
-	}, nil
+		vnetLinksClient:              vnetLinksClient,
+	}
+	if len(vnetIDs) > 0 {
+		// the zones failing the check at startup are checked again when changes are applied
+		ctx := context.Background()
+		if zones, err := p.zones(ctx); err != nil {
+			log.Errorf("Failed to check the virtual network links of the Azure Private DNS zones: %v", err)
+		} else {
+			p.ensureVirtualNetworkLinks(ctx, zones)
+		}
+	}
+	return p, nil
 }
 
 // Records gets the current records.
@@ -161,12 +205,29 @@ func (p *AzurePrivateDNSProvider) ApplyChanges(ctx context.Context, changes *pla
 		return err
 	}
 
+	p.ensureVirtualNetworkLinks(ctx, zones)
+
 	deleted, updated := p.mapChanges(zones, changes)
 	p.deleteRecords(ctx, deleted)
 	p.updateRecords(ctx, updated)
 	return nil
 }
 
+// ZoneNames returns the names of the private zones of the resource group.
+func (p *AzurePrivateDNSProvider) ZoneNames(ctx context.Context) ([]string, error) {
+	zones, err := p.zones(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(zones))
+	for _, z := range zones {
+		if z.Name != nil {
+			names = append(names, *z.Name)
+		}
+	}
+	return names, nil
+}
+
 func (p *AzurePrivateDNSProvider) zones(ctx context.Context) ([]privatedns.PrivateZone, error) {
 	log.Debugf("Retrieving Azure Private DNS zones for Resource Group '%s'", p.resourceGroup)
 
@@ -191,6 +252,100 @@ func (p *AzurePrivateDNSProvider) zones(ctx context.Context) ([]privatedns.Priva
 	return zones, nil
 }
 
+// ensureVirtualNetworkLinks checks that every zone not checked within vnetLinksCheckInterval is
+// linked to the configured virtual networks. Missing links are created when enabled, otherwise a
+// warning is logged; in dry-run mode the links that would be created are only logged. A zone whose
+// links could not be listed or created is checked again the next time changes are applied, failures
+// don't prevent the changes from being applied.
+func (p *AzurePrivateDNSProvider) ensureVirtualNetworkLinks(ctx context.Context, zones []privatedns.PrivateZone) {
+	if len(p.vnetIDs) == 0 {
+		return
+	}
+
+	p.linksMux.Lock()
+	defer p.linksMux.Unlock()
+
+	for _, zone := range zones {
+		if checkedAt, ok := p.linkedZones[*zone.Name]; ok && time.Since(checkedAt) < vnetLinksCheckInterval {
+			continue
+		}
+		if p.ensureZoneVirtualNetworkLinks(ctx, *zone.Name) {
+			p.linkedZones[*zone.Name] = time.Now()
+		} else {
+			delete(p.linkedZones, *zone.Name)
+		}
+	}
+}
+
+// ensureZoneVirtualNetworkLinks links a single zone to the configured virtual networks.
+//
+// Returns whether all the links are in place, the created ones included.
+func (p *AzurePrivateDNSProvider) ensureZoneVirtualNetworkLinks(ctx context.Context, zoneName string) bool {
+	linked := map[string]bool{}
+	pager := p.vnetLinksClient.NewListPager(p.resourceGroup, zoneName, nil)
+	for pager.More() {
+		nextResult, err := pager.NextPage(ctx)
+		if err != nil {
+			log.Errorf("Failed to list virtual network links of Azure Private DNS zone '%s': %v", zoneName, err)
+			return false
+		}
+		for _, link := range nextResult.Value {
+			if link.Properties != nil && link.Properties.VirtualNetwork != nil && link.Properties.VirtualNetwork.ID != nil {
+				linked[strings.ToLower(*link.Properties.VirtualNetwork.ID)] = true
+			}
+		}
+	}
+
+	ok := true
+	for _, vnetID := range p.vnetIDs {
+		if linked[strings.ToLower(vnetID)] {
+			continue
+		}
+		name := virtualNetworkLinkName(vnetID)
+		switch {
+		case !p.createVNetLinks:
+			log.Warnf("Azure Private DNS zone '%s' is not linked to virtual network '%s', records will not be resolvable from it.", zoneName, vnetID)
+		case p.dryRun:
+			log.Infof("Would create virtual network link '%s' to '%s' for Azure Private DNS zone '%s'.", name, vnetID, zoneName)
+		default:
+			log.Infof("Creating virtual network link '%s' to '%s' for Azure Private DNS zone '%s'.", name, vnetID, zoneName)
+			link := privatedns.VirtualNetworkLink{
+				Location: to.Ptr("global"),
+				Properties: &privatedns.VirtualNetworkLinkProperties{
+					RegistrationEnabled: to.Ptr(false),
+					VirtualNetwork:      &privatedns.SubResource{ID: to.Ptr(vnetID)},
+				},
+			}
+			if err := p.createVirtualNetworkLink(ctx, zoneName, name, link); err != nil {
+				log.Errorf("Failed to create virtual network link '%s' for Azure Private DNS zone '%s': %v", name, zoneName, err)
+				ok = false
+			}
+		}
+	}
+	return ok
+}
+
+// createVirtualNetworkLink creates a virtual network link and waits until it exists, for at most
+// vnetLinkCreateTimeout.
+func (p *AzurePrivateDNSProvider) createVirtualNetworkLink(ctx context.Context, zoneName, name string, link privatedns.VirtualNetworkLink) error {
+	ctx, cancel := context.WithTimeout(ctx, vnetLinkCreateTimeout)
+	defer cancel()
+
+	poller, err := p.vnetLinksClient.BeginCreateOrUpdate(ctx, p.resourceGroup, zoneName, name, link, nil)
+	if err != nil {
+		return err
+	}
+	_, err = poller.PollUntilDone(ctx, nil)
+	return err
+}
+
+// virtualNetworkLinkName derives the link name from the name of the virtual network,
+// e.g. /subscriptions/.../virtualNetworks/cluster-vnet becomes cluster-vnet-external-dns.
+func virtualNetworkLinkName(vnetID string) string {
+	parts := strings.Split(strings.TrimSuffix(vnetID, "/"), "/")
+	return parts[len(parts)-1] + "-external-dns"
+}
+
 type azurePrivateDNSChangeMap map[string][]*endpoint.Endpoint
 
 func (p *AzurePrivateDNSProvider) mapChanges(zones []privatedns.PrivateZone, changes *plan.Changes) (azurePrivateDNSChangeMap, azurePrivateDNSChangeMap) {
@@ -18,11 +18,17 @@ package azure
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	azcoreruntime "github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
 	privatedns "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/privatedns/armprivatedns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"sigs.k8s.io/external-dns/endpoint"
 	"sigs.k8s.io/external-dns/plan"
 	"sigs.k8s.io/external-dns/provider"
@@ -430,3 +436,147 @@ func testAzurePrivateDNSApplyChangesInternal(t *testing.T, dryRun bool, client P
 		t.Fatal(err)
 	}
 }
+
+// mockPrivateVirtualNetworkLinksClient implements the methods of the Azure Private DNS Virtual Network Links Client
+// which are used in the Azure Private DNS Provider and records the links that were requested to be created
+type mockPrivateVirtualNetworkLinksClient struct {
+	links        []*privatedns.VirtualNetworkLink
+	createdLinks map[string]privatedns.VirtualNetworkLink
+	listed       int
+	// the error the creation of the links ends with
+	createErr error
+}
+
+func (client *mockPrivateVirtualNetworkLinksClient) NewListPager(resourceGroupName string, privateZoneName string, options *privatedns.VirtualNetworkLinksClientListOptions) *azcoreruntime.Pager[privatedns.VirtualNetworkLinksClientListResponse] {
+	client.listed++
+	return azcoreruntime.NewPager(azcoreruntime.PagingHandler[privatedns.VirtualNetworkLinksClientListResponse]{
+		More: func(resp privatedns.VirtualNetworkLinksClientListResponse) bool {
+			return false
+		},
+		Fetcher: func(context.Context, *privatedns.VirtualNetworkLinksClientListResponse) (privatedns.VirtualNetworkLinksClientListResponse, error) {
+			return privatedns.VirtualNetworkLinksClientListResponse{
+				VirtualNetworkLinkListResult: privatedns.VirtualNetworkLinkListResult{
+					Value: client.links,
+				},
+			}, nil
+		},
+	})
+}
+
+func (client *mockPrivateVirtualNetworkLinksClient) BeginCreateOrUpdate(ctx context.Context, resourceGroupName string, privateZoneName string, virtualNetworkLinkName string, parameters privatedns.VirtualNetworkLink, options *privatedns.VirtualNetworkLinksClientBeginCreateOrUpdateOptions) (*azcoreruntime.Poller[privatedns.VirtualNetworkLinksClientCreateOrUpdateResponse], error) {
+	if client.createdLinks == nil {
+		client.createdLinks = map[string]privatedns.VirtualNetworkLink{}
+	}
+	client.createdLinks[privateZoneName+"/"+virtualNetworkLinkName] = parameters
+	return azcoreruntime.NewPoller(nil, azcoreruntime.Pipeline{}, &azcoreruntime.NewPollerOptions[privatedns.VirtualNetworkLinksClientCreateOrUpdateResponse]{
+		Handler: &mockVirtualNetworkLinkPoller{err: client.createErr},
+	})
+}
+
+// mockVirtualNetworkLinkPoller is a long-running creation of a link, done as soon as it's polled
+type mockVirtualNetworkLinkPoller struct {
+	err error
+}
+
+func (poller *mockVirtualNetworkLinkPoller) Done() bool {
+	return true
+}
+
+func (poller *mockVirtualNetworkLinkPoller) Poll(context.Context) (*http.Response, error) {
+	return nil, nil
+}
+
+func (poller *mockVirtualNetworkLinkPoller) Result(ctx context.Context, out *privatedns.VirtualNetworkLinksClientCreateOrUpdateResponse) error {
+	return poller.err
+}
+
+func createMockVirtualNetworkLink(vnetID string) *privatedns.VirtualNetworkLink {
+	return &privatedns.VirtualNetworkLink{
+		Properties: &privatedns.VirtualNetworkLinkProperties{
+			VirtualNetwork: &privatedns.SubResource{ID: to.Ptr(vnetID)},
+		},
+	}
+}
+
+func TestAzurePrivateDNSVirtualNetworkLinks(t *testing.T) {
+	linkedVNet := "/subscriptions/sub/resourceGroups/k8s/providers/Microsoft.Network/virtualNetworks/linked"
+	missingVNet := "/subscriptions/sub/resourceGroups/k8s/providers/Microsoft.Network/virtualNetworks/cluster-vnet"
+
+	for _, tc := range []struct {
+		title    string
+		create   bool
+		dryRun   bool
+		expected []string
+	}{
+		{"validate only", false, false, []string{}},
+		{"create", true, false, []string{"example.com/cluster-vnet-external-dns"}},
+		{"create in dry run", true, true, []string{}},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			zonesClient := newMockPrivateZonesClient([]*privatedns.PrivateZone{createMockPrivateZone("example.com", "/privateDnsZones/example.com")})
+			recordSetsClient := newMockPrivateRecordSectsClient([]*privatedns.RecordSet{})
+			linksClient := &mockPrivateVirtualNetworkLinksClient{
+				// links to the same network are matched case insensitively
+				links: []*privatedns.VirtualNetworkLink{createMockVirtualNetworkLink(strings.ToUpper(linkedVNet))},
+			}
+			p := newAzurePrivateDNSProvider(endpoint.NewDomainFilter([]string{"example.com"}), provider.NewZoneIDFilter([]string{""}), tc.dryRun, "k8s", &zonesClient, &recordSetsClient)
+			p.vnetIDs = []string{linkedVNet, missingVNet}
+			p.createVNetLinks = tc.create
+			p.vnetLinksClient = linksClient
+			p.linkedZones = map[string]time.Time{}
+
+			// the links are checked when changes are applied, not when the records are read
+			_, err := p.Records(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, 0, linksClient.listed)
+			require.NoError(t, p.ApplyChanges(context.Background(), &plan.Changes{}))
+			assert.Equal(t, 1, linksClient.listed)
+
+			// and not again until the check expires
+			require.NoError(t, p.ApplyChanges(context.Background(), &plan.Changes{}))
+			assert.Equal(t, 1, linksClient.listed)
+
+			p.linkedZones["example.com"] = time.Now().Add(-vnetLinksCheckInterval)
+			require.NoError(t, p.ApplyChanges(context.Background(), &plan.Changes{}))
+			assert.Equal(t, 2, linksClient.listed)
+
+			created := []string{}
+			for name, link := range linksClient.createdLinks {
+				created = append(created, name)
+				assert.Equal(t, missingVNet, *link.Properties.VirtualNetwork.ID)
+				assert.Equal(t, "global", *link.Location)
+				assert.False(t, *link.Properties.RegistrationEnabled)
+			}
+			assert.Equal(t, tc.expected, created)
+		})
+	}
+}
+
+func TestAzurePrivateDNSVirtualNetworkLinkCreationFails(t *testing.T) {
+	vnetID := "/subscriptions/sub/resourceGroups/k8s/providers/Microsoft.Network/virtualNetworks/cluster-vnet"
+	zonesClient := newMockPrivateZonesClient([]*privatedns.PrivateZone{createMockPrivateZone("example.com", "/privateDnsZones/example.com")})
+	recordSetsClient := newMockPrivateRecordSectsClient([]*privatedns.RecordSet{})
+	linksClient := &mockPrivateVirtualNetworkLinksClient{createErr: errors.New("link failed")}
+	p := newAzurePrivateDNSProvider(endpoint.NewDomainFilter([]string{"example.com"}), provider.NewZoneIDFilter([]string{""}), false, "k8s", &zonesClient, &recordSetsClient)
+	p.vnetIDs = []string{vnetID}
+	p.createVNetLinks = true
+	p.vnetLinksClient = linksClient
+	p.linkedZones = map[string]time.Time{}
+
+	// a zone whose link failed to be created is checked again the next time changes are applied
+	require.NoError(t, p.ApplyChanges(context.Background(), &plan.Changes{}))
+	assert.NotContains(t, p.linkedZones, "example.com")
+	require.NoError(t, p.ApplyChanges(context.Background(), &plan.Changes{}))
+	assert.Equal(t, 2, linksClient.listed)
+
+	linksClient.createErr = nil
+	require.NoError(t, p.ApplyChanges(context.Background(), &plan.Changes{}))
+	assert.Contains(t, p.linkedZones, "example.com")
+	require.NoError(t, p.ApplyChanges(context.Background(), &plan.Changes{}))
+	assert.Equal(t, 3, linksClient.listed)
+}
+
+func TestAzurePrivateDNSVirtualNetworkLinkName(t *testing.T) {
+	assert.Equal(t, "cluster-vnet-external-dns", virtualNetworkLinkName("/subscriptions/sub/resourceGroups/k8s/providers/Microsoft.Network/virtualNetworks/cluster-vnet"))
+	assert.Equal(t, "cluster-vnet-external-dns", virtualNetworkLinkName("/subscriptions/sub/resourceGroups/k8s/providers/Microsoft.Network/virtualNetworks/cluster-vnet/"))
+}
@@ -41,6 +41,9 @@ type config struct {
 	UseManagedIdentityExtension  bool   `json:"useManagedIdentityExtension" yaml:"useManagedIdentityExtension"`
 	UseWorkloadIdentityExtension bool   `json:"useWorkloadIdentityExtension" yaml:"useWorkloadIdentityExtension"`
 	UserAssignedIdentityID       string `json:"userAssignedIdentityID" yaml:"userAssignedIdentityID"`
+	// AuxiliaryTenantIDs are the tenants, besides TenantID, the identity needs tokens for when
+	// managing zones of other tenants through a multi-tenant application.
+	AuxiliaryTenantIDs []string `json:"auxiliaryTenantIds" yaml:"auxiliaryTenantIds"`
 }
 
 func getConfig(configFile, resourceGroup, userAssignedIdentityClientID string) (*config, error) {
@@ -76,6 +79,8 @@ func getCredentials(cfg config) (azcore.TokenCredential, *arm.ClientOptions, err
 	}
 	armClientOpts := &arm.ClientOptions{
 		ClientOptions: clientOpts,
+		// requests against resources linked to other tenants carry additional tokens for these tenants
+		AuxiliaryTenants: cfg.AuxiliaryTenantIDs,
 	}
 
 	// Try to retrieve token with service principal credentials.
@@ -90,7 +95,8 @@ func getCredentials(cfg config) (azcore.TokenCredential, *arm.ClientOptions, err
 		!strings.EqualFold(cfg.ClientSecret, "msi") {
 		log.Info("Using client_id+client_secret to retrieve access token for Azure API.")
 		opts := &azidentity.ClientSecretCredentialOptions{
-			ClientOptions: clientOpts,
+			ClientOptions:              clientOpts,
+			AdditionallyAllowedTenants: cfg.AuxiliaryTenantIDs,
 		}
 		cred, err := azidentity.NewClientSecretCredential(cfg.TenantID, cfg.ClientID, cfg.ClientSecret, opts)
 		if err != nil {
@@ -111,6 +117,8 @@ func getCredentials(cfg config) (azcore.TokenCredential, *arm.ClientOptions, err
 			// empty in our config, they will automatically be read from environment variables by azidentity
 			TenantID: cfg.TenantID,
 			ClientID: cfg.ClientID,
+			// A federated multi-tenant application can acquire tokens for the tenants it was consented in.
+			AdditionallyAllowedTenants: cfg.AuxiliaryTenantIDs,
 		}
 
 		cred, err := azidentity.NewWorkloadIdentityCredential(&wiOpt)
@@ -124,6 +132,9 @@ func getCredentials(cfg config) (azcore.TokenCredential, *arm.ClientOptions, err
 	// Try to retrieve token with MSI.
 	if cfg.UseManagedIdentityExtension {
 		log.Info("Using managed identity extension to retrieve access token for Azure API.")
+		if len(cfg.AuxiliaryTenantIDs) > 0 {
+			return nil, nil, fmt.Errorf("auxiliary tenants are not supported with managed identities, use a service principal or workload identity instead")
+		}
 		msiOpt := azidentity.ManagedIdentityCredentialOptions{
 			ClientOptions: clientOpts,
 		}
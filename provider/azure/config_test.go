@@ -17,9 +17,13 @@ limitations under the License.
 package azure
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetCloudConfiguration(t *testing.T) {
@@ -44,3 +48,31 @@ func TestGetCloudConfiguration(t *testing.T) {
 		})
 	}
 }
+
+func TestGetConfigAuxiliaryTenants(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "azure.json")
+	require.NoError(t, os.WriteFile(configFile, []byte(`{
+  "tenantId": "customer-tenant",
+  "subscriptionId": "subscription",
+  "resourceGroup": "dns",
+  "aadClientId": "client",
+  "aadClientSecret": "secret",
+  "auxiliaryTenantIds": ["msp-tenant", "other-customer-tenant"]
+}`), 0o600))
+
+	cfg, err := getConfig(configFile, "", "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"msp-tenant", "other-customer-tenant"}, cfg.AuxiliaryTenantIDs)
+
+	_, clientOpts, err := getCredentials(*cfg)
+	require.NoError(t, err)
+	assert.Equal(t, cfg.AuxiliaryTenantIDs, clientOpts.AuxiliaryTenants)
+}
+
+func TestGetCredentialsAuxiliaryTenantsWithManagedIdentity(t *testing.T) {
+	_, _, err := getCredentials(config{
+		UseManagedIdentityExtension: true,
+		AuxiliaryTenantIDs:          []string{"other-tenant"},
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// DefaultBatchSize is the number of change requests sent at once to the APIs without bulk endpoints.
+const DefaultBatchSize = 10
+
+// ApplyInBatches sends the change requests of the items to a provider API without bulk endpoints,
+// which takes a request per record. The requests are sent in batches of up to size concurrent
+// requests and each of them is retried as configured. A failing request doesn't stop the others,
+// the errors of all failed requests are returned once every item was applied.
+func ApplyInBatches[T any](ctx context.Context, retry RetryConfig, size int, items []T, apply func(ctx context.Context, item T) error) error {
+	if size < 1 {
+		size = 1
+	}
+	var errs []error
+	for start := 0; start < len(items); start += size {
+		batch := items[start:min(start+size, len(items))]
+		batchErrs := make([]error, len(batch))
+		var wg sync.WaitGroup
+		for i, item := range batch {
+			wg.Add(1)
+			go func(i int, item T) {
+				defer wg.Done()
+				batchErrs[i] = retry.Do(ctx, func(ctx context.Context) error {
+					return apply(ctx, item)
+				})
+			}(i, item)
+		}
+		wg.Wait()
+		errs = append(errs, batchErrs...)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyInBatches(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5, 6, 7}
+
+	var mu sync.Mutex
+	var inFlight, maxInFlight int32
+	attempts := map[int]int{}
+	err := ApplyInBatches(context.Background(), testRetryConfig, 3, items, func(ctx context.Context, item int) error {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+
+		mu.Lock()
+		defer mu.Unlock()
+		if current > maxInFlight {
+			maxInFlight = current
+		}
+		attempts[item]++
+		switch {
+		case item == 2 && attempts[item] == 1:
+			return errTransient
+		case item == 5:
+			return errInvalid
+		}
+		return nil
+	})
+
+	assert.ErrorIs(t, err, errInvalid)
+	assert.NotErrorIs(t, err, errTransient)
+	assert.LessOrEqual(t, maxInFlight, int32(3))
+	assert.Equal(t, map[int]int{1: 1, 2: 2, 3: 1, 4: 1, 5: 1, 6: 1, 7: 1}, attempts)
+}
+
+func TestApplyInBatchesEmpty(t *testing.T) {
+	err := ApplyInBatches(context.Background(), testRetryConfig, 0, []int(nil), func(ctx context.Context, item int) error {
+		t.Fatal("unexpected request")
+		return nil
+	})
+
+	assert.NoError(t, err)
+}
@@ -0,0 +1,402 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bam
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// pageSize is the number of entities requested per page of a collection.
+const pageSize = 1000
+
+// The types of the resource records managed in Address Manager.
+const (
+	TypeHostRecord  = "HostRecord"
+	TypeAliasRecord = "AliasRecord"
+	TypeTXTRecord   = "TXTRecord"
+
+	TypeIPv4Address = "IPv4Address"
+	TypeIPv6Address = "IPv6Address"
+)
+
+// The types of the deployments of the DNS service of a server.
+const (
+	FullDeployment         = "FullDeployment"
+	DifferentialDeployment = "DifferentialDeployment"
+)
+
+// errUnauthorized is returned when the session expired, the client logs in again.
+var errUnauthorized = errors.New("unauthorized")
+
+// Client navigates the configuration and the view of Address Manager, and manages the resource
+// records of their zones.
+type Client interface {
+	// GetZones returns the zones of the view, including their subzones
+	GetZones(ctx context.Context) ([]Zone, error)
+	GetResourceRecords(ctx context.Context, zoneID int) ([]ResourceRecord, error)
+	// CreateResourceRecord returns the created record, with its ID
+	CreateResourceRecord(ctx context.Context, zoneID int, record ResourceRecord) (ResourceRecord, error)
+	UpdateResourceRecord(ctx context.Context, record ResourceRecord) error
+	DeleteResourceRecord(ctx context.Context, id int) error
+	// Deploy starts a deployment of the DNS service of the server
+	Deploy(ctx context.Context, deploymentType string) error
+}
+
+// Zone is a DNS zone of a view.
+type Zone struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	AbsoluteName string `json:"absoluteName"`
+}
+
+// ResourceRecord is a host, alias or TXT record, the fields of the other types being ignored.
+type ResourceRecord struct {
+	ID           int           `json:"id,omitempty"`
+	Type         string        `json:"type"`
+	Name         string        `json:"name"`
+	AbsoluteName string        `json:"absoluteName,omitempty"`
+	TTL          *int          `json:"ttl,omitempty"`
+	Addresses    []Address     `json:"addresses,omitempty"`
+	LinkedRecord *LinkedRecord `json:"linkedRecord,omitempty"`
+	Text         string        `json:"text,omitempty"`
+}
+
+// Address is an address of a host record.
+type Address struct {
+	ID      int    `json:"id,omitempty"`
+	Type    string `json:"type"`
+	Address string `json:"address"`
+}
+
+// LinkedRecord is the record an alias record points to.
+type LinkedRecord struct {
+	ID           int    `json:"id,omitempty"`
+	Type         string `json:"type,omitempty"`
+	AbsoluteName string `json:"absoluteName"`
+}
+
+type entity struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type collection struct {
+	Count int             `json:"count"`
+	Data  json.RawMessage `json:"data"`
+}
+
+type apiError struct {
+	Status  int    `json:"status"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// HTTPClient is the client of the REST v2 API of Address Manager.
+type HTTPClient struct {
+	host          string
+	username      string
+	password      string
+	configuration string
+	view          string
+	serverName    string
+	client        *http.Client
+
+	mu sync.Mutex
+	// credentials are the basic authentication credentials of the session
+	credentials     string
+	configurationID int
+	viewID          int
+}
+
+// NewHTTPClient returns a client of the Address Manager at host, managing the zones of the view of
+// the configuration, and deploying the server serverName.
+func NewHTTPClient(host, username, password, configuration, view, serverName string, skipTLSVerify bool) *HTTPClient {
+	return &HTTPClient{
+		host:          strings.TrimSuffix(host, "/"),
+		username:      username,
+		password:      password,
+		configuration: configuration,
+		view:          view,
+		serverName:    serverName,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+			Transport: &http.Transport{
+				Proxy: http.ProxyFromEnvironment,
+				TLSClientConfig: &tls.Config{
+					InsecureSkipVerify: skipTLSVerify,
+				},
+			},
+		},
+	}
+}
+
+// GetZones returns the zones of the view, traversing the subzones of the zones.
+func (c *HTTPClient) GetZones(ctx context.Context) ([]Zone, error) {
+	viewID, err := c.viewIDOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var zones []Zone
+	if err := c.list(ctx, fmt.Sprintf("/api/v2/views/%d/zones", viewID), nil, &zones); err != nil {
+		return nil, fmt.Errorf("failed to get the zones of view %s: %w", c.view, err)
+	}
+	// the subzones are children of their parent zone instead of the view
+	for i := 0; i < len(zones); i++ {
+		var subZones []Zone
+		if err := c.list(ctx, fmt.Sprintf("/api/v2/zones/%d/zones", zones[i].ID), nil, &subZones); err != nil {
+			return nil, fmt.Errorf("failed to get the subzones of zone %s: %w", zones[i].AbsoluteName, err)
+		}
+		zones = append(zones, subZones...)
+	}
+	return zones, nil
+}
+
+// GetResourceRecords returns the resource records of a zone, without the records of its subzones.
+func (c *HTTPClient) GetResourceRecords(ctx context.Context, zoneID int) ([]ResourceRecord, error) {
+	var records []ResourceRecord
+	if err := c.list(ctx, fmt.Sprintf("/api/v2/zones/%d/resourceRecords", zoneID), nil, &records); err != nil {
+		return nil, fmt.Errorf("failed to get the resource records of zone %d: %w", zoneID, err)
+	}
+	return records, nil
+}
+
+func (c *HTTPClient) CreateResourceRecord(ctx context.Context, zoneID int, record ResourceRecord) (ResourceRecord, error) {
+	var created ResourceRecord
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/api/v2/zones/%d/resourceRecords", zoneID), record, &created); err != nil {
+		return ResourceRecord{}, fmt.Errorf("failed to create %s %s: %w", record.Type, record.Name, err)
+	}
+	return created, nil
+}
+
+func (c *HTTPClient) UpdateResourceRecord(ctx context.Context, record ResourceRecord) error {
+	if err := c.do(ctx, http.MethodPut, fmt.Sprintf("/api/v2/resourceRecords/%d", record.ID), record, nil); err != nil {
+		return fmt.Errorf("failed to update %s %s: %w", record.Type, record.Name, err)
+	}
+	return nil
+}
+
+func (c *HTTPClient) DeleteResourceRecord(ctx context.Context, id int) error {
+	if err := c.do(ctx, http.MethodDelete, fmt.Sprintf("/api/v2/resourceRecords/%d", id), nil, nil); err != nil {
+		return fmt.Errorf("failed to delete resource record %d: %w", id, err)
+	}
+	return nil
+}
+
+// Deploy starts a deployment of the DNS service of the server, without waiting for it to complete.
+func (c *HTTPClient) Deploy(ctx context.Context, deploymentType string) error {
+	configurationID, err := c.configurationIDOf(ctx)
+	if err != nil {
+		return err
+	}
+	server, err := c.find(ctx, fmt.Sprintf("/api/v2/configurations/%d/servers", configurationID), c.serverName)
+	if err != nil {
+		return fmt.Errorf("failed to get server %s: %w", c.serverName, err)
+	}
+
+	body := map[string]interface{}{"type": deploymentType, "services": []string{"DNS"}}
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/api/v2/servers/%d/deployments", server.ID), body, nil); err != nil {
+		return fmt.Errorf("failed to deploy server %s: %w", c.serverName, err)
+	}
+	log.Infof("Started a %s of server %s", deploymentType, c.serverName)
+	return nil
+}
+
+func (c *HTTPClient) configurationIDOf(ctx context.Context) (int, error) {
+	c.mu.Lock()
+	id := c.configurationID
+	c.mu.Unlock()
+	if id != 0 {
+		return id, nil
+	}
+
+	configuration, err := c.find(ctx, "/api/v2/configurations", c.configuration)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get configuration %s: %w", c.configuration, err)
+	}
+	c.mu.Lock()
+	c.configurationID = configuration.ID
+	c.mu.Unlock()
+	return configuration.ID, nil
+}
+
+func (c *HTTPClient) viewIDOf(ctx context.Context) (int, error) {
+	c.mu.Lock()
+	id := c.viewID
+	c.mu.Unlock()
+	if id != 0 {
+		return id, nil
+	}
+
+	configurationID, err := c.configurationIDOf(ctx)
+	if err != nil {
+		return 0, err
+	}
+	view, err := c.find(ctx, fmt.Sprintf("/api/v2/configurations/%d/views", configurationID), c.view)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get view %s: %w", c.view, err)
+	}
+	c.mu.Lock()
+	c.viewID = view.ID
+	c.mu.Unlock()
+	return view.ID, nil
+}
+
+// find returns the entity of a collection by name.
+func (c *HTTPClient) find(ctx context.Context, path, name string) (entity, error) {
+	var entities []entity
+	query := url.Values{"filter": {"name:eq('" + strings.ReplaceAll(name, "'", "\\'") + "')"}}
+	if err := c.list(ctx, path, query, &entities); err != nil {
+		return entity{}, err
+	}
+	if len(entities) == 0 {
+		return entity{}, errors.New("not found")
+	}
+	return entities[0], nil
+}
+
+// list reads all the pages of a collection into items, a pointer to a slice.
+func (c *HTTPClient) list(ctx context.Context, path string, query url.Values, items interface{}) error {
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("limit", strconv.Itoa(pageSize))
+
+	var all []json.RawMessage
+	for offset := 0; ; offset += pageSize {
+		query.Set("offset", strconv.Itoa(offset))
+		var page collection
+		if err := c.do(ctx, http.MethodGet, path+"?"+query.Encode(), nil, &page); err != nil {
+			return err
+		}
+		var data []json.RawMessage
+		if len(page.Data) > 0 {
+			if err := json.Unmarshal(page.Data, &data); err != nil {
+				return fmt.Errorf("failed to decode %s: %w", path, err)
+			}
+		}
+		all = append(all, data...)
+		if len(data) < pageSize {
+			break
+		}
+	}
+
+	b, err := json.Marshal(all)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, items)
+}
+
+// do sends a request, logging in first if there is no session or it expired.
+func (c *HTTPClient) do(ctx context.Context, method, path string, body, result interface{}) error {
+	c.mu.Lock()
+	credentials := c.credentials
+	c.mu.Unlock()
+
+	if credentials == "" {
+		var err error
+		if credentials, err = c.login(ctx); err != nil {
+			return err
+		}
+	}
+	err := c.send(ctx, method, path, credentials, body, result)
+	if errors.Is(err, errUnauthorized) {
+		log.Debug("The Address Manager session expired, logging in again")
+		if credentials, err = c.login(ctx); err != nil {
+			return err
+		}
+		err = c.send(ctx, method, path, credentials, body, result)
+	}
+	return err
+}
+
+func (c *HTTPClient) login(ctx context.Context) (string, error) {
+	var session struct {
+		BasicAuthenticationCredentials string `json:"basicAuthenticationCredentials"`
+	}
+	err := c.send(ctx, http.MethodPost, "/api/v2/sessions", "", map[string]string{"username": c.username, "password": c.password}, &session)
+	if err != nil {
+		return "", fmt.Errorf("failed to log in to Address Manager: %w", err)
+	}
+	if session.BasicAuthenticationCredentials == "" {
+		return "", errors.New("failed to log in to Address Manager: no credentials in the session")
+	}
+
+	c.mu.Lock()
+	c.credentials = session.BasicAuthenticationCredentials
+	c.mu.Unlock()
+	return session.BasicAuthenticationCredentials, nil
+}
+
+func (c *HTTPClient) send(ctx context.Context, method, path, credentials string, body, result interface{}) error {
+	var reader io.Reader = http.NoBody
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.host+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/hal+json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if credentials != "" {
+		req.Header.Set("Authorization", "Basic "+credentials)
+	}
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusUnauthorized && credentials != "" {
+		return errUnauthorized
+	}
+	if res.StatusCode >= 300 {
+		var e apiError
+		_ = json.NewDecoder(res.Body).Decode(&e)
+		return fmt.Errorf("%s %s: %s %s %s", method, path, res.Status, e.Code, e.Message)
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(res.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode the response of %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bam
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBAM serves the endpoints of the REST v2 API of Address Manager used by HTTPClient.
+type fakeBAM struct {
+	t           *testing.T
+	logins      int
+	expired     bool
+	created     []ResourceRecord
+	deleted     []string
+	deployments []map[string]interface{}
+}
+
+func (b *fakeBAM) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	page := func(items ...interface{}) {
+		if items == nil {
+			items = []interface{}{}
+		}
+		assert.Equal(b.t, strconv.Itoa(pageSize), r.URL.Query().Get("limit"))
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"count": len(items), "data": items})
+	}
+
+	if r.URL.Path == "/api/v2/sessions" {
+		b.logins++
+		b.expired = false
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"basicAuthenticationCredentials": "Y3JlZHM="})
+		return
+	}
+	if r.Header.Get("Authorization") != "Basic Y3JlZHM=" || b.expired {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method + " " + r.URL.Path {
+	case "GET /api/v2/configurations":
+		assert.Equal(b.t, "name:eq('Example')", r.URL.Query().Get("filter"))
+		page(entity{ID: 1, Name: "Example"})
+	case "GET /api/v2/configurations/1/views":
+		assert.Equal(b.t, "name:eq('Internal')", r.URL.Query().Get("filter"))
+		page(entity{ID: 2, Name: "Internal"})
+	case "GET /api/v2/configurations/1/servers":
+		page(entity{ID: 5, Name: "dns1"})
+	case "GET /api/v2/views/2/zones":
+		page(Zone{ID: 10, Name: "com", AbsoluteName: "com"})
+	case "GET /api/v2/zones/10/zones":
+		page(Zone{ID: 11, Name: "example", AbsoluteName: "example.com"})
+	case "GET /api/v2/zones/11/zones":
+		page()
+	case "GET /api/v2/zones/11/resourceRecords":
+		page(ResourceRecord{ID: 20, Type: TypeTXTRecord, Name: "www", AbsoluteName: "www.example.com", Text: "text"})
+	case "POST /api/v2/zones/11/resourceRecords":
+		var record ResourceRecord
+		require.NoError(b.t, json.NewDecoder(r.Body).Decode(&record))
+		b.created = append(b.created, record)
+		record.ID = 21
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(record)
+	case "DELETE /api/v2/resourceRecords/20":
+		b.deleted = append(b.deleted, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	case "POST /api/v2/servers/5/deployments":
+		var body map[string]interface{}
+		require.NoError(b.t, json.NewDecoder(r.Body).Decode(&body))
+		b.deployments = append(b.deployments, body)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":7,"state":"QUEUED"}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = fmt.Fprintf(w, `{"status":404,"code":"ObjectNotFound","message":"%s not found"}`, r.URL.Path)
+	}
+}
+
+func TestHTTPClient(t *testing.T) {
+	bam := &fakeBAM{t: t}
+	server := httptest.NewServer(bam)
+	defer server.Close()
+
+	c := NewHTTPClient(server.URL, "user", "password", "Example", "Internal", "dns1", false)
+	ctx := context.Background()
+
+	zones, err := c.GetZones(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []Zone{{ID: 10, Name: "com", AbsoluteName: "com"}, {ID: 11, Name: "example", AbsoluteName: "example.com"}}, zones)
+
+	records, err := c.GetResourceRecords(ctx, 11)
+	require.NoError(t, err)
+	assert.Equal(t, []ResourceRecord{{ID: 20, Type: TypeTXTRecord, Name: "www", AbsoluteName: "www.example.com", Text: "text"}}, records)
+
+	created, err := c.CreateResourceRecord(ctx, 11, ResourceRecord{Type: TypeHostRecord, Name: "app", Addresses: []Address{{Type: TypeIPv4Address, Address: "10.0.0.1"}}})
+	require.NoError(t, err)
+	assert.Equal(t, 21, created.ID)
+	assert.Equal(t, []ResourceRecord{{Type: TypeHostRecord, Name: "app", Addresses: []Address{{Type: TypeIPv4Address, Address: "10.0.0.1"}}}}, bam.created)
+
+	// an expired session is replaced by logging in again
+	bam.expired = true
+	require.NoError(t, c.DeleteResourceRecord(ctx, 20))
+	assert.Equal(t, []string{"/api/v2/resourceRecords/20"}, bam.deleted)
+	assert.Equal(t, 2, bam.logins)
+
+	require.NoError(t, c.Deploy(ctx, DifferentialDeployment))
+	assert.Equal(t, []map[string]interface{}{{"type": DifferentialDeployment, "services": []interface{}{"DNS"}}}, bam.deployments)
+
+	err = c.UpdateResourceRecord(ctx, ResourceRecord{ID: 99, Type: TypeTXTRecord, Name: "missing"})
+	assert.ErrorContains(t, err, "ObjectNotFound")
+}
@@ -0,0 +1,422 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package bam implements a provider managing the records of BlueCat Address Manager with its REST
+// v2 API, without BlueCat Gateway.
+package bam
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+// The types of deployment of the DNS server after the records changed.
+const (
+	NoDeploy           = "no-deploy"
+	FullDeploy         = "full-deploy"
+	DifferentialDeploy = "differential-deploy"
+)
+
+var deploymentTypes = map[string]string{
+	FullDeploy:         FullDeployment,
+	DifferentialDeploy: DifferentialDeployment,
+}
+
+// Config is the configuration of the BAM provider.
+type Config struct {
+	DomainFilter endpoint.DomainFilter
+	ZoneIDFilter provider.ZoneIDFilter
+	DryRun       bool
+	// Host is the URL of Address Manager, e.g. https://bam.example.com
+	Host          string
+	Configuration string
+	View          string
+	SkipTLSVerify bool
+	// DNSServerName is the server deployed after the records changed, no server is deployed if empty
+	DNSServerName string
+	DNSDeployType string
+	// DeployInterval is the minimum interval between two deployments, the changes made in between
+	// being deployed together at the end of the interval
+	DeployInterval time.Duration
+}
+
+// BAMProvider implements the DNS provider for BlueCat Address Manager.
+type BAMProvider struct {
+	provider.BaseProvider
+	client       Client
+	domainFilter endpoint.DomainFilter
+	zoneIDFilter provider.ZoneIDFilter
+	dryRun       bool
+	deployer     *deployer
+}
+
+// NewBAMProvider returns a provider managing the records of the view of the configuration, with
+// the credentials of the environment variables BLUECAT_USERNAME and BLUECAT_PASSWORD.
+func NewBAMProvider(cfg Config) (*BAMProvider, error) {
+	if cfg.Host == "" || cfg.Configuration == "" || cfg.View == "" {
+		return nil, fmt.Errorf("the host, the DNS configuration and the DNS view of Address Manager are required")
+	}
+	if cfg.DNSDeployType != NoDeploy && deploymentTypes[cfg.DNSDeployType] == "" {
+		return nil, fmt.Errorf("%v is not a valid deployment type", cfg.DNSDeployType)
+	}
+	username, password := os.Getenv("BLUECAT_USERNAME"), os.Getenv("BLUECAT_PASSWORD")
+	if username == "" || password == "" {
+		return nil, fmt.Errorf("BLUECAT_USERNAME and BLUECAT_PASSWORD are required")
+	}
+
+	client := NewHTTPClient(cfg.Host, username, password, cfg.Configuration, cfg.View, cfg.DNSServerName, cfg.SkipTLSVerify)
+	return newBAMProvider(cfg, client), nil
+}
+
+func newBAMProvider(cfg Config, client Client) *BAMProvider {
+	p := &BAMProvider{
+		client:       client,
+		domainFilter: cfg.DomainFilter,
+		zoneIDFilter: cfg.ZoneIDFilter,
+		dryRun:       cfg.DryRun,
+	}
+	if cfg.DNSServerName != "" && cfg.DNSDeployType != NoDeploy && cfg.DNSDeployType != "" {
+		p.deployer = &deployer{client: client, deploymentType: deploymentTypes[cfg.DNSDeployType], interval: cfg.DeployInterval}
+	}
+	return p
+}
+
+// Records returns the A and AAAA records of the host records, the CNAME records of the alias
+// records, and the TXT records of the zones.
+func (p *BAMProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	zones, err := p.zones(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var endpoints []*endpoint.Endpoint
+	for _, zone := range zones {
+		records, err := p.client.GetResourceRecords(ctx, zone.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			endpoints = append(endpoints, recordEndpoints(record)...)
+		}
+	}
+	log.Debugf("fetched %d records from Address Manager", len(endpoints))
+	return endpoints, nil
+}
+
+func recordEndpoints(record ResourceRecord) []*endpoint.Endpoint {
+	var ttl endpoint.TTL
+	if record.TTL != nil {
+		ttl = endpoint.TTL(*record.TTL)
+	}
+
+	var endpoints []*endpoint.Endpoint
+	switch record.Type {
+	case TypeHostRecord:
+		var v4, v6 []string
+		for _, address := range record.Addresses {
+			switch address.Type {
+			case TypeIPv4Address:
+				v4 = append(v4, address.Address)
+			case TypeIPv6Address:
+				v6 = append(v6, address.Address)
+			}
+		}
+		if len(v4) > 0 {
+			endpoints = append(endpoints, endpoint.NewEndpointWithTTL(record.AbsoluteName, endpoint.RecordTypeA, ttl, v4...))
+		}
+		if len(v6) > 0 {
+			endpoints = append(endpoints, endpoint.NewEndpointWithTTL(record.AbsoluteName, endpoint.RecordTypeAAAA, ttl, v6...))
+		}
+	case TypeAliasRecord:
+		if record.LinkedRecord != nil {
+			endpoints = append(endpoints, endpoint.NewEndpointWithTTL(record.AbsoluteName, endpoint.RecordTypeCNAME, ttl, record.LinkedRecord.AbsoluteName))
+		}
+	case TypeTXTRecord:
+		endpoints = append(endpoints, endpoint.NewEndpointWithTTL(record.AbsoluteName, endpoint.RecordTypeTXT, ttl, record.Text))
+	}
+	return endpoints
+}
+
+// ApplyChanges applies the changes to the records, and deploys the server if they changed.
+func (p *BAMProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	zones, err := p.zones(ctx)
+	if err != nil {
+		return err
+	}
+
+	z := &zoneRecords{client: p.client, records: map[int][]ResourceRecord{}}
+	apply := func(endpoints []*endpoint.Endpoint, deleted bool) error {
+		for _, ep := range endpoints {
+			zone, ok := findZone(zones, ep.DNSName)
+			if !ok {
+				log.Debugf("Skipping record %s because no zone was found", ep.DNSName)
+				continue
+			}
+			if p.dryRun {
+				if deleted {
+					log.Infof("Would delete %s record %s in zone %s", ep.RecordType, ep.DNSName, zone.AbsoluteName)
+				} else {
+					log.Infof("Would set %s record %s in zone %s to %v", ep.RecordType, ep.DNSName, zone.AbsoluteName, ep.Targets)
+				}
+				continue
+			}
+			if err := p.setRecords(ctx, z, zone, ep, deleted); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// the updated records are replaced by the new ones, the old ones are only needed by the plan
+	if err := apply(changes.Delete, true); err != nil {
+		return err
+	}
+	if err := apply(changes.UpdateNew, false); err != nil {
+		return err
+	}
+	if err := apply(changes.Create, false); err != nil {
+		return err
+	}
+
+	if p.deployer != nil && !p.dryRun && changes.HasChanges() {
+		return p.deployer.schedule(ctx)
+	}
+	return nil
+}
+
+// setRecords sets the records of the endpoint to its targets, or deletes them.
+func (p *BAMProvider) setRecords(ctx context.Context, z *zoneRecords, zone Zone, ep *endpoint.Endpoint, deleted bool) error {
+	existing, err := z.get(ctx, zone.ID)
+	if err != nil {
+		return err
+	}
+	name := relativeName(ep.DNSName, zone.AbsoluteName)
+	var ttl *int
+	if ep.RecordTTL.IsConfigured() {
+		v := int(ep.RecordTTL)
+		ttl = &v
+	}
+
+	switch ep.RecordType {
+	case endpoint.RecordTypeA, endpoint.RecordTypeAAAA:
+		// the A and AAAA records of a name share a host record
+		addressType := TypeIPv4Address
+		if ep.RecordType == endpoint.RecordTypeAAAA {
+			addressType = TypeIPv6Address
+		}
+		var host *ResourceRecord
+		for i := range existing {
+			if existing[i].Type == TypeHostRecord && strings.EqualFold(existing[i].AbsoluteName, ep.DNSName) {
+				host = &existing[i]
+				break
+			}
+		}
+
+		var addresses []Address
+		if host != nil {
+			for _, address := range host.Addresses {
+				if address.Type != addressType {
+					addresses = append(addresses, Address{Type: address.Type, Address: address.Address})
+				}
+			}
+		}
+		if !deleted {
+			for _, target := range ep.Targets {
+				addresses = append(addresses, Address{Type: addressType, Address: target})
+			}
+		}
+
+		switch {
+		case host == nil && len(addresses) > 0:
+			log.Infof("Creating host record %s with %v", ep.DNSName, ep.Targets)
+			return z.create(ctx, zone.ID, ResourceRecord{Type: TypeHostRecord, Name: name, TTL: ttl, Addresses: addresses})
+		case host != nil && len(addresses) == 0:
+			log.Infof("Deleting host record %s", ep.DNSName)
+			return z.delete(ctx, zone.ID, host.ID)
+		case host != nil:
+			log.Infof("Updating the %s addresses of host record %s to %v", addressType, ep.DNSName, ep.Targets)
+			if !deleted {
+				host.TTL = ttl
+			}
+			host.Addresses = addresses
+			return p.client.UpdateResourceRecord(ctx, ResourceRecord{ID: host.ID, Type: TypeHostRecord, Name: name, TTL: host.TTL, Addresses: addresses})
+		}
+		return nil
+	case endpoint.RecordTypeCNAME, endpoint.RecordTypeTXT:
+		recordType := TypeAliasRecord
+		if ep.RecordType == endpoint.RecordTypeTXT {
+			recordType = TypeTXTRecord
+		}
+		// the targets replace the records of the name
+		for _, record := range existing {
+			if record.Type == recordType && strings.EqualFold(record.AbsoluteName, ep.DNSName) {
+				log.Infof("Deleting %s %s", recordType, ep.DNSName)
+				if err := z.delete(ctx, zone.ID, record.ID); err != nil {
+					return err
+				}
+			}
+		}
+		if deleted {
+			return nil
+		}
+		for _, target := range ep.Targets {
+			record := ResourceRecord{Type: recordType, Name: name, TTL: ttl}
+			if recordType == TypeAliasRecord {
+				record.LinkedRecord = &LinkedRecord{AbsoluteName: target}
+			} else {
+				record.Text = target
+			}
+			log.Infof("Creating %s %s to %s", recordType, ep.DNSName, target)
+			if err := z.create(ctx, zone.ID, record); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	log.Debugf("Skipping record %s of unsupported type %s", ep.DNSName, ep.RecordType)
+	return nil
+}
+
+func (p *BAMProvider) zones(ctx context.Context) ([]Zone, error) {
+	all, err := p.client.GetZones(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var zones []Zone
+	for _, zone := range all {
+		if !p.domainFilter.Match(zone.AbsoluteName) || !p.zoneIDFilter.Match(strconv.Itoa(zone.ID)) {
+			continue
+		}
+		zones = append(zones, zone)
+	}
+	log.Debugf("found %d zones", len(zones))
+	return zones, nil
+}
+
+// zoneRecords caches the records of the zones while the changes are applied.
+type zoneRecords struct {
+	client  Client
+	records map[int][]ResourceRecord
+}
+
+func (z *zoneRecords) get(ctx context.Context, zoneID int) ([]ResourceRecord, error) {
+	if records, ok := z.records[zoneID]; ok {
+		return records, nil
+	}
+	records, err := z.client.GetResourceRecords(ctx, zoneID)
+	if err != nil {
+		return nil, err
+	}
+	z.records[zoneID] = records
+	return records, nil
+}
+
+func (z *zoneRecords) create(ctx context.Context, zoneID int, record ResourceRecord) error {
+	created, err := z.client.CreateResourceRecord(ctx, zoneID, record)
+	if err != nil {
+		return err
+	}
+	z.records[zoneID] = append(z.records[zoneID], created)
+	return nil
+}
+
+func (z *zoneRecords) delete(ctx context.Context, zoneID, id int) error {
+	if err := z.client.DeleteResourceRecord(ctx, id); err != nil {
+		return err
+	}
+	records := z.records[zoneID][:0]
+	for _, record := range z.records[zoneID] {
+		if record.ID != id {
+			records = append(records, record)
+		}
+	}
+	z.records[zoneID] = records
+	return nil
+}
+
+// findZone returns the most specific zone of a name.
+func findZone(zones []Zone, name string) (Zone, bool) {
+	var result Zone
+	found := false
+	for _, zone := range zones {
+		if (strings.EqualFold(name, zone.AbsoluteName) || strings.HasSuffix(strings.ToLower(name), "."+strings.ToLower(zone.AbsoluteName))) &&
+			(!found || len(zone.AbsoluteName) > len(result.AbsoluteName)) {
+			result, found = zone, true
+		}
+	}
+	return result, found
+}
+
+// relativeName returns the name of a record relative to its zone, empty at the apex.
+func relativeName(name, zone string) string {
+	if strings.EqualFold(name, zone) {
+		return ""
+	}
+	return name[:len(name)-len(zone)-1]
+}
+
+// deployer deploys the server after the records changed, at most once per interval.
+type deployer struct {
+	client         Client
+	deploymentType string
+	interval       time.Duration
+
+	mu       sync.Mutex
+	last     time.Time
+	deferred *time.Timer
+}
+
+// schedule deploys the server now if the last deployment is older than the interval, at the end
+// of the interval otherwise.
+func (d *deployer) schedule(ctx context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.deferred != nil {
+		log.Debug("A deployment is already scheduled, the changes are deployed with it")
+		return nil
+	}
+	now := time.Now()
+	if wait := d.last.Add(d.interval).Sub(now); wait > 0 {
+		log.Infof("Scheduling a deployment in %s", wait.Round(time.Second))
+		d.deferred = time.AfterFunc(wait, d.deployDeferred)
+		return nil
+	}
+	d.last = now
+	return d.client.Deploy(ctx, d.deploymentType)
+}
+
+func (d *deployer) deployDeferred() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.deferred = nil
+	d.last = time.Now()
+	if err := d.client.Deploy(context.Background(), d.deploymentType); err != nil {
+		log.Errorf("Failed to deploy the changes: %v", err)
+	}
+}
@@ -0,0 +1,236 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bam
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+type mockClient struct {
+	zones       []Zone
+	records     map[int][]ResourceRecord
+	nextID      int
+	deployments []string
+}
+
+func (c *mockClient) GetZones(context.Context) ([]Zone, error) {
+	return c.zones, nil
+}
+
+func (c *mockClient) GetResourceRecords(_ context.Context, zoneID int) ([]ResourceRecord, error) {
+	return append([]ResourceRecord(nil), c.records[zoneID]...), nil
+}
+
+func (c *mockClient) CreateResourceRecord(_ context.Context, zoneID int, record ResourceRecord) (ResourceRecord, error) {
+	c.nextID++
+	record.ID = c.nextID
+	for _, zone := range c.zones {
+		if zone.ID == zoneID {
+			record.AbsoluteName = strings.TrimPrefix(record.Name+"."+zone.AbsoluteName, ".")
+		}
+	}
+	c.records[zoneID] = append(c.records[zoneID], record)
+	return record, nil
+}
+
+func (c *mockClient) UpdateResourceRecord(_ context.Context, record ResourceRecord) error {
+	for zoneID, records := range c.records {
+		for i := range records {
+			if records[i].ID == record.ID {
+				record.AbsoluteName = records[i].AbsoluteName
+				c.records[zoneID][i] = record
+			}
+		}
+	}
+	return nil
+}
+
+func (c *mockClient) DeleteResourceRecord(_ context.Context, id int) error {
+	for zoneID, records := range c.records {
+		var kept []ResourceRecord
+		for _, record := range records {
+			if record.ID != id {
+				kept = append(kept, record)
+			}
+		}
+		c.records[zoneID] = kept
+	}
+	return nil
+}
+
+func (c *mockClient) Deploy(_ context.Context, deploymentType string) error {
+	c.deployments = append(c.deployments, deploymentType)
+	return nil
+}
+
+func ttl(v int) *int {
+	return &v
+}
+
+func newMockClient() *mockClient {
+	return &mockClient{
+		zones: []Zone{
+			{ID: 1, Name: "example", AbsoluteName: "example.com"},
+			{ID: 2, Name: "sub", AbsoluteName: "sub.example.com"},
+			{ID: 3, Name: "example", AbsoluteName: "example.org"},
+		},
+		records: map[int][]ResourceRecord{
+			1: {
+				{ID: 10, Type: TypeHostRecord, Name: "www", AbsoluteName: "www.example.com", TTL: ttl(300), Addresses: []Address{
+					{ID: 100, Type: TypeIPv4Address, Address: "10.0.0.1"},
+					{ID: 101, Type: TypeIPv6Address, Address: "2001:db8::1"},
+				}},
+				{ID: 11, Type: TypeAliasRecord, Name: "app", AbsoluteName: "app.example.com", LinkedRecord: &LinkedRecord{AbsoluteName: "www.example.com"}},
+				{ID: 12, Type: TypeTXTRecord, Name: "app", AbsoluteName: "app.example.com", Text: "heritage=external-dns"},
+				{ID: 13, Type: "MXRecord", Name: "", AbsoluteName: "example.com"},
+			},
+			2: {
+				{ID: 20, Type: TypeHostRecord, Name: "api", AbsoluteName: "api.sub.example.com", Addresses: []Address{
+					{ID: 200, Type: TypeIPv4Address, Address: "10.0.1.1"},
+					{ID: 201, Type: TypeIPv4Address, Address: "10.0.1.2"},
+				}},
+			},
+			3: {
+				{ID: 30, Type: TypeHostRecord, Name: "www", AbsoluteName: "www.example.org", Addresses: []Address{
+					{ID: 300, Type: TypeIPv4Address, Address: "10.0.2.1"},
+				}},
+			},
+		},
+		nextID: 1000,
+	}
+}
+
+func TestBAMRecords(t *testing.T) {
+	p := newBAMProvider(Config{DomainFilter: endpoint.NewDomainFilter([]string{"example.com"})}, newMockClient())
+
+	endpoints, err := p.Records(context.Background())
+	require.NoError(t, err)
+
+	expected := []*endpoint.Endpoint{
+		endpoint.NewEndpointWithTTL("www.example.com", endpoint.RecordTypeA, 300, "10.0.0.1"),
+		endpoint.NewEndpointWithTTL("www.example.com", endpoint.RecordTypeAAAA, 300, "2001:db8::1"),
+		endpoint.NewEndpoint("app.example.com", endpoint.RecordTypeCNAME, "www.example.com"),
+		endpoint.NewEndpoint("app.example.com", endpoint.RecordTypeTXT, "heritage=external-dns"),
+		endpoint.NewEndpoint("api.sub.example.com", endpoint.RecordTypeA, "10.0.1.1", "10.0.1.2"),
+	}
+	assert.True(t, testutils.SameEndpoints(expected, endpoints), "expected %v, got %v", expected, endpoints)
+}
+
+func TestBAMApplyChanges(t *testing.T) {
+	client := newMockClient()
+	p := newBAMProvider(Config{
+		DomainFilter:  endpoint.NewDomainFilter([]string{"example.com"}),
+		DNSServerName: "dns1",
+		DNSDeployType: DifferentialDeploy,
+	}, client)
+
+	changes := &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			endpoint.NewEndpointWithTTL("new.sub.example.com", endpoint.RecordTypeA, 60, "10.0.1.3"),
+			endpoint.NewEndpoint("new.sub.example.com", endpoint.RecordTypeTXT, "heritage=external-dns", "other"),
+			endpoint.NewEndpoint("new.example.org", endpoint.RecordTypeA, "10.0.2.2"),
+		},
+		UpdateOld: []*endpoint.Endpoint{
+			endpoint.NewEndpointWithTTL("www.example.com", endpoint.RecordTypeA, 300, "10.0.0.1"),
+			endpoint.NewEndpoint("app.example.com", endpoint.RecordTypeCNAME, "www.example.com"),
+		},
+		UpdateNew: []*endpoint.Endpoint{
+			endpoint.NewEndpointWithTTL("www.example.com", endpoint.RecordTypeA, 300, "10.0.0.2", "10.0.0.3"),
+			endpoint.NewEndpoint("app.example.com", endpoint.RecordTypeCNAME, "api.sub.example.com"),
+		},
+		Delete: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("api.sub.example.com", endpoint.RecordTypeA, "10.0.1.1", "10.0.1.2"),
+			endpoint.NewEndpointWithTTL("www.example.com", endpoint.RecordTypeAAAA, 300, "2001:db8::1"),
+		},
+	}
+	require.NoError(t, p.ApplyChanges(context.Background(), changes))
+
+	endpoints, err := p.Records(context.Background())
+	require.NoError(t, err)
+	expected := []*endpoint.Endpoint{
+		endpoint.NewEndpointWithTTL("www.example.com", endpoint.RecordTypeA, 300, "10.0.0.2", "10.0.0.3"),
+		endpoint.NewEndpoint("app.example.com", endpoint.RecordTypeCNAME, "api.sub.example.com"),
+		endpoint.NewEndpoint("app.example.com", endpoint.RecordTypeTXT, "heritage=external-dns"),
+		endpoint.NewEndpointWithTTL("new.sub.example.com", endpoint.RecordTypeA, 60, "10.0.1.3"),
+		endpoint.NewEndpoint("new.sub.example.com", endpoint.RecordTypeTXT, "heritage=external-dns"),
+		endpoint.NewEndpoint("new.sub.example.com", endpoint.RecordTypeTXT, "other"),
+	}
+	assert.True(t, testutils.SameEndpoints(expected, endpoints), "expected %v, got %v", expected, endpoints)
+	// the host record of the other domain is left alone
+	assert.Len(t, client.records[3], 1)
+	assert.Equal(t, []string{DifferentialDeployment}, client.deployments)
+}
+
+func TestBAMApplyChangesDryRun(t *testing.T) {
+	client := newMockClient()
+	p := newBAMProvider(Config{DryRun: true, DNSServerName: "dns1", DNSDeployType: FullDeploy}, client)
+
+	require.NoError(t, p.ApplyChanges(context.Background(), &plan.Changes{
+		Delete: []*endpoint.Endpoint{endpoint.NewEndpoint("www.example.com", endpoint.RecordTypeA, "10.0.0.1")},
+	}))
+	assert.Len(t, client.records[1], 4)
+	assert.Empty(t, client.deployments)
+}
+
+func TestBAMDeployInterval(t *testing.T) {
+	client := newMockClient()
+	d := &deployer{client: client, deploymentType: FullDeployment, interval: 50 * time.Millisecond}
+
+	require.NoError(t, d.schedule(context.Background()))
+	assert.Equal(t, []string{FullDeployment}, client.deployments)
+
+	// the changes within the interval are deployed together at its end
+	require.NoError(t, d.schedule(context.Background()))
+	require.NoError(t, d.schedule(context.Background()))
+	d.mu.Lock()
+	assert.Len(t, client.deployments, 1)
+	d.mu.Unlock()
+
+	assert.Eventually(t, func() bool {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		return len(client.deployments) == 2 && d.deferred == nil
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestFindZone(t *testing.T) {
+	zones := newMockClient().zones
+
+	zone, ok := findZone(zones, "a.sub.example.com")
+	require.True(t, ok)
+	assert.Equal(t, 2, zone.ID)
+
+	zone, ok = findZone(zones, "Example.COM")
+	require.True(t, ok)
+	assert.Equal(t, 1, zone.ID)
+
+	_, ok = findZone(zones, "example.net")
+	assert.False(t, ok)
+
+	assert.Equal(t, "a", relativeName("a.sub.example.com", "sub.example.com"))
+	assert.Equal(t, "", relativeName("sub.example.com", "sub.example.com"))
+}
@@ -0,0 +1,268 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+// the delay between the probes of an unhealthy provider doubles up to this value
+const maxCircuitBreakerBackoff = 10 * time.Minute
+
+// ErrProviderUnhealthy is returned instead of sending the requests to a provider that failed
+// too many times in a row.
+var ErrProviderUnhealthy = errors.New("the DNS provider is unhealthy")
+
+var (
+	providerHealthy = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "external_dns",
+			Subsystem: "provider",
+			Name:      "healthy",
+			Help:      "Whether the DNS provider is healthy, 0 while its changes are paused after consecutive failures.",
+		},
+		[]string{"provider"},
+	)
+	circuitBreakerOpenedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "external_dns",
+			Subsystem: "provider",
+			Name:      "circuit_breaker_opened_total",
+			Help:      "Number of times the DNS provider was marked unhealthy after consecutive failures.",
+		},
+		[]string{"provider"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(providerHealthy)
+	prometheus.MustRegister(circuitBreakerOpenedTotal)
+}
+
+// Health is the health of a provider, as reported by /healthz.
+type Health struct {
+	Provider            string     `json:"provider"`
+	Healthy             bool       `json:"healthy"`
+	ConsecutiveFailures int        `json:"consecutiveFailures"`
+	LastError           string     `json:"lastError,omitempty"`
+	UnhealthySince      *time.Time `json:"unhealthySince,omitempty"`
+	NextProbe           *time.Time `json:"nextProbe,omitempty"`
+}
+
+// CircuitBreakerProvider marks the provider it wraps unhealthy after consecutive failures. While
+// it is unhealthy, the changes are rejected and the records are served from the last successful
+// listing, and the records are listed again as probe with exponential backoff until it succeeds.
+type CircuitBreakerProvider struct {
+	Provider
+	name      string
+	threshold int
+	backoff   time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	failures int
+	lastErr  error
+	// openedAt is the time the provider was marked unhealthy, zero while it is healthy
+	openedAt  time.Time
+	nextProbe time.Time
+	wait      time.Duration
+	records   []*endpoint.Endpoint
+}
+
+// NewCircuitBreakerProvider wraps the provider to mark it unhealthy after threshold consecutive
+// failures, probing it first after the backoff.
+func NewCircuitBreakerProvider(name string, p Provider, threshold int, backoff time.Duration) *CircuitBreakerProvider {
+	providerHealthy.WithLabelValues(name).Set(1)
+	return &CircuitBreakerProvider{Provider: p, name: name, threshold: threshold, backoff: backoff, now: time.Now}
+}
+
+// Records returns the records of the wrapped provider, or the records of its last successful
+// listing while it is unhealthy.
+func (p *CircuitBreakerProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	if err := p.allow(true); err != nil {
+		return p.cachedRecords(err)
+	}
+
+	records, err := p.Provider.Records(ctx)
+	p.report(err)
+	if err != nil {
+		if !p.Health().Healthy {
+			return p.cachedRecords(err)
+		}
+		return nil, err
+	}
+	p.mu.Lock()
+	p.records = copyEndpoints(records)
+	p.mu.Unlock()
+	return records, nil
+}
+
+// cachedRecords returns the records of the last successful listing, or the error if the records
+// were never listed.
+func (p *CircuitBreakerProvider) cachedRecords(err error) ([]*endpoint.Endpoint, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.records == nil {
+		return nil, err
+	}
+	log.Debugf("Using the records of the last successful listing: %v", err)
+	return copyEndpoints(p.records), nil
+}
+
+// RecordsPage returns the page of the records of the wrapped provider. The pages aren't cached,
+// so they fail while it is unhealthy, but for the probes.
+func (p *CircuitBreakerProvider) RecordsPage(ctx context.Context, pageToken string) ([]*endpoint.Endpoint, string, error) {
+	if err := p.allow(true); err != nil {
+		return nil, "", err
+	}
+	records, next, err := RecordsPage(ctx, p.Provider, pageToken)
+	p.report(err)
+	return records, next, err
+}
+
+// ZoneNames returns the names of the zones of the wrapped provider, unless it is unhealthy. The
+// zones are never listed to probe the provider.
+func (p *CircuitBreakerProvider) ZoneNames(ctx context.Context) ([]string, error) {
+	if err := p.allow(false); err != nil {
+		return nil, err
+	}
+	zones, err := ZoneNames(ctx, p.Provider)
+	if !errors.Is(err, ErrZonesNotListed) {
+		p.report(err)
+	}
+	return zones, err
+}
+
+// ApplyChanges applies the changes with the wrapped provider, unless it is unhealthy.
+func (p *CircuitBreakerProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	if err := p.allow(false); err != nil {
+		return err
+	}
+	err := p.Provider.ApplyChanges(ctx, changes)
+	p.report(err)
+	return err
+}
+
+// allow returns an error if the request can't be sent to the provider: it is unhealthy and the
+// request is a change, or a listing before the next probe.
+func (p *CircuitBreakerProvider) allow(probe bool) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.openedAt.IsZero() {
+		return nil
+	}
+	now := p.now()
+	if probe && !now.Before(p.nextProbe) {
+		// the other requests wait for the result of the probe
+		p.nextProbe = now.Add(p.wait)
+		return nil
+	}
+	return fmt.Errorf("%w after %d consecutive failures, probing it at %s: %v", ErrProviderUnhealthy, p.failures, p.nextProbe.Format(time.RFC3339), p.lastErr)
+}
+
+// report records the result of a request to the provider.
+func (p *CircuitBreakerProvider) report(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err == nil {
+		if !p.openedAt.IsZero() {
+			log.Infof("The DNS provider %s recovered after %d consecutive failures", p.name, p.failures)
+			providerHealthy.WithLabelValues(p.name).Set(1)
+		}
+		p.failures, p.lastErr, p.openedAt = 0, nil, time.Time{}
+		return
+	}
+	// the requests cancelled on shutdown don't tell the health of the provider
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+
+	p.failures++
+	p.lastErr = err
+	now := p.now()
+	switch {
+	case !p.openedAt.IsZero():
+		p.wait = min(2*p.wait, maxCircuitBreakerBackoff)
+		p.nextProbe = now.Add(p.wait)
+		log.Warnf("The DNS provider %s is still unhealthy, probing it again at %s: %v", p.name, p.nextProbe.Format(time.RFC3339), err)
+	case p.failures >= p.threshold:
+		p.openedAt = now
+		p.wait = p.backoff
+		p.nextProbe = now.Add(p.wait)
+		providerHealthy.WithLabelValues(p.name).Set(0)
+		circuitBreakerOpenedTotal.WithLabelValues(p.name).Inc()
+		log.Errorf("The DNS provider %s is unhealthy after %d consecutive failures, pausing the changes until it recovers, probing it at %s: %v", p.name, p.failures, p.nextProbe.Format(time.RFC3339), err)
+	}
+}
+
+// Health returns the health of the wrapped provider.
+func (p *CircuitBreakerProvider) Health() Health {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	h := Health{Provider: p.name, Healthy: p.openedAt.IsZero(), ConsecutiveFailures: p.failures}
+	if p.lastErr != nil {
+		h.LastError = p.lastErr.Error()
+	}
+	if !h.Healthy {
+		openedAt, nextProbe := p.openedAt, p.nextProbe
+		h.UnhealthySince, h.NextProbe = &openedAt, &nextProbe
+	}
+	return h
+}
+
+// ServeHTTP writes the health of the wrapped provider as JSON. The status is always OK, restarting
+// ExternalDNS doesn't make the provider healthy again.
+func (p *CircuitBreakerProvider) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(p.Health()); err != nil {
+		log.Errorf("Failed to write the health of the provider: %v", err)
+	}
+}
+
+// PropertyValuesEqual compares the values of provider specific properties as the wrapped provider does.
+func (p *CircuitBreakerProvider) PropertyValuesEqual(name string, previous string, current string) bool {
+	return PropertyValuesEqual(p.Provider, name, previous, current)
+}
+
+// Unwrap returns the wrapped provider.
+func (p *CircuitBreakerProvider) Unwrap() Provider {
+	return p.Provider
+}
+
+// copyEndpoints returns deep copies of the endpoints, so the registry can't alter the cached ones.
+func copyEndpoints(endpoints []*endpoint.Endpoint) []*endpoint.Endpoint {
+	copies := make([]*endpoint.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		copies = append(copies, ep.DeepCopy())
+	}
+	return copies
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+// flakyProvider lists its records and applies the changes unless it fails.
+type flakyProvider struct {
+	BaseProvider
+	err     error
+	records []*endpoint.Endpoint
+	calls   int
+}
+
+func (p *flakyProvider) Records(context.Context) ([]*endpoint.Endpoint, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return p.records, nil
+}
+
+func (p *flakyProvider) ApplyChanges(context.Context, *plan.Changes) error {
+	p.calls++
+	return p.err
+}
+
+func gaugeValue(t *testing.T, gauge prometheus.Gauge) float64 {
+	t.Helper()
+	metric := &dto.Metric{}
+	require.NoError(t, gauge.Write(metric))
+	return metric.GetGauge().GetValue()
+}
+
+func TestCircuitBreakerProvider(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	inner := &flakyProvider{records: []*endpoint.Endpoint{endpoint.NewEndpoint("a.example.org", endpoint.RecordTypeA, "1.2.3.4")}}
+	p := NewCircuitBreakerProvider("breaker-test", inner, 2, time.Minute)
+	p.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	records, err := p.Records(ctx)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+
+	// the provider is unhealthy after the consecutive failures
+	inner.err = errors.New("503 Service Unavailable")
+	assert.Error(t, p.ApplyChanges(ctx, &plan.Changes{}))
+	assert.True(t, p.Health().Healthy)
+	assert.Error(t, p.ApplyChanges(ctx, &plan.Changes{}))
+	assert.False(t, p.Health().Healthy)
+	assert.Equal(t, 0.0, gaugeValue(t, providerHealthy.WithLabelValues("breaker-test")))
+
+	// the changes are paused and the records are served from the last listing
+	inner.calls = 0
+	assert.ErrorIs(t, p.ApplyChanges(ctx, &plan.Changes{}), ErrProviderUnhealthy)
+	records, err = p.Records(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "a.example.org", records[0].DNSName)
+	assert.Equal(t, 0, inner.calls)
+
+	// the provider is probed with exponential backoff
+	now = now.Add(time.Minute)
+	_, err = p.Records(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, inner.calls)
+	assert.Equal(t, now.Add(2*time.Minute), *p.Health().NextProbe)
+
+	// and is healthy again once a probe succeeds
+	inner.err = nil
+	now = now.Add(2 * time.Minute)
+	_, err = p.Records(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, inner.calls)
+	assert.True(t, p.Health().Healthy)
+	assert.Equal(t, 1.0, gaugeValue(t, providerHealthy.WithLabelValues("breaker-test")))
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{}))
+}
+
+func TestCircuitBreakerProviderWithoutRecords(t *testing.T) {
+	inner := &flakyProvider{err: errors.New("connection refused")}
+	p := NewCircuitBreakerProvider("breaker-test-empty", inner, 1, time.Minute)
+
+	_, err := p.Records(context.Background())
+	assert.EqualError(t, err, "connection refused")
+	_, err = p.Records(context.Background())
+	assert.ErrorIs(t, err, ErrProviderUnhealthy)
+	assert.ErrorContains(t, err, "connection refused")
+
+	// the cancelled requests aren't failures of the provider
+	inner.err = context.Canceled
+	p = NewCircuitBreakerProvider("breaker-test-cancel", inner, 1, time.Minute)
+	_, err = p.Records(context.Background())
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.True(t, p.Health().Healthy)
+}
+
+func TestCircuitBreakerProviderServeHTTP(t *testing.T) {
+	inner := &flakyProvider{err: errors.New("connection refused")}
+	p := NewCircuitBreakerProvider("breaker-test-http", inner, 1, time.Minute)
+	_, _ = p.Records(context.Background())
+
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/healthz", nil))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var health Health
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &health))
+	assert.Equal(t, "breaker-test-http", health.Provider)
+	assert.False(t, health.Healthy)
+	assert.Equal(t, 1, health.ConsecutiveFailures)
+	assert.Equal(t, "connection refused", health.LastError)
+	assert.NotNil(t, health.NextProbe)
+
+	// the wrapped provider is unwrapped through the wrappers
+	assert.Same(t, inner, Unwrap(NewInstrumentedProvider("test", p)))
+}
@@ -18,6 +18,7 @@ package cloudflare
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
@@ -27,6 +28,7 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/pkg/credentials"
 	"sigs.k8s.io/external-dns/plan"
 	"sigs.k8s.io/external-dns/provider"
 	"sigs.k8s.io/external-dns/source"
@@ -125,6 +127,9 @@ type CloudFlareProvider struct {
 	proxiedByDefault  bool
 	DryRun            bool
 	DNSRecordsPerPage int
+	// tokenRefresher, when set, provides the API token, the client is recreated when it changes
+	tokenRefresher *credentials.Refresher
+	token          string
 }
 
 // cloudFlareChange differentiates between ChangActions
@@ -140,13 +145,18 @@ type RecordParamsTypes interface {
 
 // getUpdateDNSRecordParam is a function that returns the appropriate Record Param based on the cloudFlareChange passed in
 func getUpdateDNSRecordParam(cfc cloudFlareChange) cloudflare.UpdateDNSRecordParams {
-	return cloudflare.UpdateDNSRecordParams{
+	params := cloudflare.UpdateDNSRecordParams{
 		Name:    cfc.ResourceRecord.Name,
 		TTL:     cfc.ResourceRecord.TTL,
 		Proxied: cfc.ResourceRecord.Proxied,
 		Type:    cfc.ResourceRecord.Type,
 		Content: cfc.ResourceRecord.Content,
 	}
+	// the current comment is kept by the changes without description
+	if cfc.ResourceRecord.Comment != "" {
+		params.Comment = &cfc.ResourceRecord.Comment
+	}
+	return params
 }
 
 // getCreateDNSRecordParam is a function that returns the appropriate Record Param based on the cloudFlareChange passed in
@@ -157,17 +167,26 @@ func getCreateDNSRecordParam(cfc cloudFlareChange) cloudflare.CreateDNSRecordPar
 		Proxied: cfc.ResourceRecord.Proxied,
 		Type:    cfc.ResourceRecord.Type,
 		Content: cfc.ResourceRecord.Content,
+		Comment: cfc.ResourceRecord.Comment,
 	}
 }
 
 // NewCloudFlareProvider initializes a new CloudFlare DNS based Provider.
-func NewCloudFlareProvider(domainFilter endpoint.DomainFilter, zoneIDFilter provider.ZoneIDFilter, proxiedByDefault bool, dryRun bool, dnsRecordsPerPage int) (*CloudFlareProvider, error) {
+func NewCloudFlareProvider(domainFilter endpoint.DomainFilter, zoneIDFilter provider.ZoneIDFilter, proxiedByDefault bool, dryRun bool, dnsRecordsPerPage int, tokenRefresher *credentials.Refresher) (*CloudFlareProvider, error) {
 	// initialize via chosen auth method and returns new API object
 	var (
 		config *cloudflare.API
+		token  string
 		err    error
 	)
-	if os.Getenv("CF_API_TOKEN") != "" {
+	if tokenRefresher != nil {
+		var creds credentials.Credentials
+		creds, err = tokenRefresher.Get(context.Background())
+		if err == nil {
+			token = creds[credentials.CloudflareAPIToken]
+			config, err = cloudflare.NewWithAPIToken(token)
+		}
+	} else if os.Getenv("CF_API_TOKEN") != "" {
 		token := os.Getenv("CF_API_TOKEN")
 		if strings.HasPrefix(token, "file:") {
 			tokenBytes, err := os.ReadFile(strings.TrimPrefix(token, "file:"))
@@ -191,10 +210,57 @@ func NewCloudFlareProvider(domainFilter endpoint.DomainFilter, zoneIDFilter prov
 		proxiedByDefault:  proxiedByDefault,
 		DryRun:            dryRun,
 		DNSRecordsPerPage: dnsRecordsPerPage,
+		tokenRefresher:    tokenRefresher,
+		token:             token,
 	}
 	return provider, nil
 }
 
+// refreshClient recreates the client when the API token provided by the refresher changed.
+func (p *CloudFlareProvider) refreshClient(ctx context.Context) error {
+	if p.tokenRefresher == nil {
+		return nil
+	}
+	creds, err := p.tokenRefresher.Get(ctx)
+	if err != nil {
+		return err
+	}
+	token := creds[credentials.CloudflareAPIToken]
+	if token == p.token {
+		return nil
+	}
+	config, err := cloudflare.NewWithAPIToken(token)
+	if err != nil {
+		return fmt.Errorf("failed to initialize cloudflare provider: %v", err)
+	}
+	p.Client = zoneService{config}
+	p.token = token
+	return nil
+}
+
+// rejected returns the error of a request whose API token was rejected, a soft error fetching the
+// token again if it has a refresher.
+func (p *CloudFlareProvider) rejected(err error) error {
+	var authErr *cloudflare.AuthenticationError
+	if p.tokenRefresher == nil || !errors.As(err, &authErr) {
+		return err
+	}
+	return p.tokenRefresher.Rejected(err)
+}
+
+// ZoneNames returns the names of the zones.
+func (p *CloudFlareProvider) ZoneNames(ctx context.Context) ([]string, error) {
+	zones, err := p.Zones(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(zones))
+	for _, z := range zones {
+		names = append(names, z.Name)
+	}
+	return names, nil
+}
+
 // Zones returns the list of hosted zones.
 func (p *CloudFlareProvider) Zones(ctx context.Context) ([]cloudflare.Zone, error) {
 	result := []cloudflare.Zone{}
@@ -239,9 +305,12 @@ func (p *CloudFlareProvider) Zones(ctx context.Context) ([]cloudflare.Zone, erro
 
 // Records returns the list of records.
 func (p *CloudFlareProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	if err := p.refreshClient(ctx); err != nil {
+		return nil, err
+	}
 	zones, err := p.Zones(ctx)
 	if err != nil {
-		return nil, err
+		return nil, p.rejected(err)
 	}
 
 	endpoints := []*endpoint.Endpoint{}
@@ -305,9 +374,12 @@ func (p *CloudFlareProvider) submitChanges(ctx context.Context, changes []*cloud
 		return nil
 	}
 
+	if err := p.refreshClient(ctx); err != nil {
+		return err
+	}
 	zones, err := p.Zones(ctx)
 	if err != nil {
-		return err
+		return p.rejected(err)
 	}
 	// separate into per-zone change sets to be passed to the API.
 	changesByZone := p.changesByZone(zones, changes)
@@ -397,6 +469,17 @@ func (p *CloudFlareProvider) AdjustEndpoints(endpoints []*endpoint.Endpoint) ([]
 	return adjustedEndpoints, nil
 }
 
+// propertySchema normalizes the provider specific properties whose values from annotations may
+// differ textually from the ones read from Cloudflare, e.g. proxied "1" is read as "true".
+var propertySchema = provider.PropertySchema{
+	source.CloudflareProxiedKey: provider.BoolProperty,
+}
+
+// PropertyValuesEqual compares the values of provider specific properties semantically.
+func (p *CloudFlareProvider) PropertyValuesEqual(name string, previous string, current string) bool {
+	return propertySchema.PropertyValuesEqual(name, previous, current)
+}
+
 // changesByZone separates a multi-zone change into a single change per zone.
 func (p *CloudFlareProvider) changesByZone(zones []cloudflare.Zone, changeSet []*cloudFlareChange) map[string][]*cloudFlareChange {
 	changes := make(map[string][]*cloudFlareChange)
@@ -428,22 +511,23 @@ func (p *CloudFlareProvider) getRecordID(records []cloudflare.DNSRecord, record
 	return ""
 }
 
-func (p *CloudFlareProvider) newCloudFlareChange(action string, endpoint *endpoint.Endpoint, target string) *cloudFlareChange {
+func (p *CloudFlareProvider) newCloudFlareChange(action string, ep *endpoint.Endpoint, target string) *cloudFlareChange {
 	ttl := defaultCloudFlareRecordTTL
-	proxied := shouldBeProxied(endpoint, p.proxiedByDefault)
+	proxied := shouldBeProxied(ep, p.proxiedByDefault)
 
-	if endpoint.RecordTTL.IsConfigured() {
-		ttl = int(endpoint.RecordTTL)
+	if ep.RecordTTL.IsConfigured() {
+		ttl = int(ep.RecordTTL)
 	}
 
 	return &cloudFlareChange{
 		Action: action,
 		ResourceRecord: cloudflare.DNSRecord{
-			Name:    endpoint.DNSName,
+			Name:    ep.DNSName,
 			TTL:     ttl,
 			Proxied: &proxied,
-			Type:    endpoint.RecordType,
+			Type:    ep.RecordType,
 			Content: target,
+			Comment: ep.Labels[endpoint.DescriptionLabelKey],
 		},
 	}
 }
@@ -486,9 +570,30 @@ func shouldBeProxied(endpoint *endpoint.Endpoint, proxiedByDefault bool) bool {
 	if recordTypeProxyNotSupported[endpoint.RecordType] {
 		proxied = false
 	}
+	if isTunnelEndpoint(endpoint) {
+		// cloudflared only receives the traffic going through Cloudflare
+		if !proxied {
+			log.Debugf("Proxying %s, the CNAME record of a Cloudflare tunnel", endpoint.DNSName)
+		}
+		proxied = true
+	}
 	return proxied
 }
 
+// isTunnelEndpoint returns whether the endpoint is the CNAME record of a Cloudflare tunnel, e.g. of the
+// resources with the cloudflare-tunnel-id annotation.
+func isTunnelEndpoint(endpoint *endpoint.Endpoint) bool {
+	if endpoint.RecordType != "CNAME" || len(endpoint.Targets) == 0 {
+		return false
+	}
+	for _, target := range endpoint.Targets {
+		if !strings.HasSuffix(target, "."+source.CloudflareTunnelDomain) {
+			return false
+		}
+	}
+	return true
+}
+
 func groupByNameAndType(records []cloudflare.DNSRecord) []*endpoint.Endpoint {
 	endpoints := []*endpoint.Endpoint{}
 
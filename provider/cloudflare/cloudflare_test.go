@@ -24,12 +24,15 @@ import (
 	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	cloudflare "github.com/cloudflare/cloudflare-go"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/maxatome/go-testdeep/td"
 	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/pkg/credentials"
 	"sigs.k8s.io/external-dns/plan"
 	"sigs.k8s.io/external-dns/provider"
 )
@@ -558,6 +561,38 @@ func TestCloudflareProxiedOverrideIllegal(t *testing.T) {
 	)
 }
 
+func TestCloudflareProxiedTunnel(t *testing.T) {
+	endpoints := []*endpoint.Endpoint{
+		{
+			RecordType: "CNAME",
+			DNSName:    "bar.com",
+			Targets:    endpoint.Targets{"c1744f8b-faa1-48a4-9e5c-02ac921467fa.cfargotunnel.com"},
+			ProviderSpecific: endpoint.ProviderSpecific{
+				endpoint.ProviderSpecificProperty{
+					Name:  "external-dns.alpha.kubernetes.io/cloudflare-proxied",
+					Value: "false",
+				},
+			},
+		},
+	}
+
+	AssertActions(t, &CloudFlareProvider{}, endpoints, []MockAction{
+		{
+			Name:   "Create",
+			ZoneId: "001",
+			RecordData: cloudflare.DNSRecord{
+				Type:    "CNAME",
+				Name:    "bar.com",
+				Content: "c1744f8b-faa1-48a4-9e5c-02ac921467fa.cfargotunnel.com",
+				TTL:     1,
+				Proxied: proxyEnabled,
+			},
+		},
+	},
+		[]string{endpoint.RecordTypeA, endpoint.RecordTypeCNAME},
+	)
+}
+
 func TestCloudflareSetProxied(t *testing.T) {
 	var proxied *bool = proxyEnabled
 	var notProxied *bool = proxyDisabled
@@ -681,7 +716,8 @@ func TestCloudflareProvider(t *testing.T) {
 		provider.NewZoneIDFilter([]string{""}),
 		false,
 		true,
-		5000)
+		5000,
+		nil)
 	if err != nil {
 		t.Errorf("should not fail, %s", err)
 	}
@@ -697,7 +733,8 @@ func TestCloudflareProvider(t *testing.T) {
 		provider.NewZoneIDFilter([]string{""}),
 		false,
 		true,
-		5000)
+		5000,
+		nil)
 	if err != nil {
 		t.Errorf("should not fail, %s", err)
 	}
@@ -710,7 +747,8 @@ func TestCloudflareProvider(t *testing.T) {
 		provider.NewZoneIDFilter([]string{""}),
 		false,
 		true,
-		5000)
+		5000,
+		nil)
 	if err != nil {
 		t.Errorf("should not fail, %s", err)
 	}
@@ -722,12 +760,49 @@ func TestCloudflareProvider(t *testing.T) {
 		provider.NewZoneIDFilter([]string{""}),
 		false,
 		true,
-		5000)
+		5000,
+		nil)
 	if err == nil {
 		t.Errorf("expected to fail")
 	}
 }
 
+type tokenSource struct {
+	token string
+}
+
+func (s *tokenSource) Fetch(context.Context) (credentials.Credentials, time.Time, error) {
+	return credentials.Credentials{credentials.CloudflareAPIToken: s.token}, time.Time{}, nil
+}
+
+func TestCloudflareProviderTokenRefresher(t *testing.T) {
+	source := &tokenSource{token: "abc123def"}
+	refresher := credentials.NewRefresher(source, 0)
+	p, err := NewCloudFlareProvider(
+		endpoint.NewDomainFilter([]string{"bar.com"}),
+		provider.NewZoneIDFilter([]string{""}),
+		false,
+		true,
+		5000,
+		refresher)
+	require.NoError(t, err)
+	assert.Equal(t, "abc123def", p.token)
+
+	// the client is recreated once the rejected token was fetched again
+	client := p.Client
+	authErr := cloudflare.NewAuthenticationError(&cloudflare.Error{StatusCode: 401})
+	err = p.rejected(&authErr)
+	assert.ErrorIs(t, err, credentials.ErrRejected)
+	source.token = "rotated"
+	require.NoError(t, p.refreshClient(context.Background()))
+	assert.Equal(t, "rotated", p.token)
+	assert.NotEqual(t, client, p.Client)
+
+	// other errors are returned unchanged
+	other := errors.New("failed to list zones")
+	assert.Equal(t, other, p.rejected(other))
+}
+
 func TestCloudflareApplyChanges(t *testing.T) {
 	changes := &plan.Changes{}
 	client := NewMockCloudFlareClient()
@@ -1333,3 +1408,30 @@ func TestCustomTTLWithEnabledProxyNotChanged(t *testing.T) {
 	assert.Equal(t, 0, len(planned.Changes.UpdateOld), "no new changes should be here")
 	assert.Equal(t, 0, len(planned.Changes.Delete), "no new changes should be here")
 }
+
+func TestCloudflarePropertyValuesEqual(t *testing.T) {
+	p := &CloudFlareProvider{}
+	current := endpoint.NewEndpoint("proxied.bar.com", endpoint.RecordTypeA, "1.2.3.4").WithProviderSpecific("external-dns.alpha.kubernetes.io/cloudflare-proxied", "true")
+
+	for _, tc := range []struct {
+		proxied      string
+		shouldUpdate bool
+	}{
+		{"true", false},
+		{"1", false},
+		{"True", false},
+		{"false", true},
+	} {
+		t.Run(tc.proxied, func(t *testing.T) {
+			desired := endpoint.NewEndpoint("proxied.bar.com", endpoint.RecordTypeA, "1.2.3.4").WithProviderSpecific("external-dns.alpha.kubernetes.io/cloudflare-proxied", tc.proxied)
+			changes := (&plan.Plan{
+				Policies:           []plan.Policy{&plan.SyncPolicy{}},
+				Current:            []*endpoint.Endpoint{current},
+				Desired:            []*endpoint.Endpoint{desired},
+				ManagedRecords:     []string{endpoint.RecordTypeA},
+				PropertyComparator: p.PropertyValuesEqual,
+			}).Calculate().Changes
+			assert.Equal(t, tc.shouldUpdate, len(changes.UpdateNew) == 1)
+		})
+	}
+}
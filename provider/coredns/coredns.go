@@ -26,6 +26,7 @@ import (
 	"math/rand"
 	"net"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -257,9 +258,9 @@ func NewCoreDNSProvider(domainFilter endpoint.DomainFilter, prefix string, dryRu
 
 // findEp takes an Endpoint slice and looks for an element in it. If found it will
 // return Endpoint, otherwise it will return nil and a bool of false.
-func findEp(slice []*endpoint.Endpoint, dnsName string) (*endpoint.Endpoint, bool) {
+func findEp(slice []*endpoint.Endpoint, dnsName, recordType string) (*endpoint.Endpoint, bool) {
 	for _, item := range slice {
-		if item.DNSName == dnsName {
+		if item.DNSName == dnsName && item.RecordType == recordType {
 			return item, true
 		}
 	}
@@ -278,7 +279,9 @@ func findLabelInTargets(targets []string, label string) (string, bool) {
 }
 
 // Records returns all DNS records found in CoreDNS etcd backend. Depending on the record fields
-// it may be mapped to one or two records of type A, CNAME, TXT, A+TXT, CNAME+TXT
+// it may be mapped to one or two records of type A, CNAME, SRV, TXT, A+TXT, CNAME+TXT, SRV+TXT.
+// Services with a port are SRV records if they are in the group of their record name, as written
+// by ApplyChanges, services of other tools keep being read as A or CNAME records.
 func (p coreDNSProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
 	var result []*endpoint.Endpoint
 	services, err := p.client.GetServices(p.coreDNSPrefix)
@@ -295,23 +298,27 @@ func (p coreDNSProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, err
 		log.Debugf("Getting service (%v) with service host (%s)", service, service.Host)
 		prefix := strings.Join(domains[:service.TargetStrip], ".")
 		if service.Host != "" {
-			ep, found := findEp(result, dnsName)
+			recordType, target := guessRecordType(service.Host), service.Host
+			if service.Port > 0 && service.Group == dnsName {
+				recordType, target = endpoint.RecordTypeSRV, srvTarget(service)
+			}
+			ep, found := findEp(result, dnsName, recordType)
 			if found {
-				ep.Targets = append(ep.Targets, service.Host)
+				ep.Targets = append(ep.Targets, target)
 				log.Debugf("Extending ep (%s) with new service host (%s)", ep, service.Host)
 			} else {
 				ep = endpoint.NewEndpointWithTTL(
 					dnsName,
-					guessRecordType(service.Host),
+					recordType,
 					endpoint.TTL(service.TTL),
-					service.Host,
+					target,
 				)
 				log.Debugf("Creating new ep (%s) with new service host (%s)", ep, service.Host)
+				result = append(result, ep)
 			}
 			ep.Labels["originalText"] = service.Text
 			ep.Labels[randomPrefixLabel] = prefix
-			ep.Labels[service.Host] = prefix
-			result = append(result, ep)
+			ep.Labels[target] = prefix
 		}
 		if service.Text != "" {
 			ep := endpoint.NewEndpoint(
@@ -362,6 +369,14 @@ func (p coreDNSProvider) ApplyChanges(ctx context.Context, changes *plan.Changes
 					Key:         p.etcdKeyFor(prefix + "." + dnsName),
 					TargetStrip: strings.Count(prefix, ".") + 1,
 					TTL:         uint32(ep.RecordTTL),
+					// the group keeps the targets of the record name in one answer
+					Group: dnsName,
+				}
+				if ep.RecordType == endpoint.RecordTypeSRV {
+					if err := parseSRVTarget(target, &service); err != nil {
+						log.Warnf("Skipping target %s of %s: %v", target, dnsName, err)
+						continue
+					}
 				}
 				services = append(services, service)
 				ep.Labels[target] = prefix
@@ -407,6 +422,7 @@ func (p coreDNSProvider) ApplyChanges(ctx context.Context, changes *plan.Changes
 					Key:         p.etcdKeyFor(prefix + "." + dnsName),
 					TargetStrip: strings.Count(prefix, ".") + 1,
 					TTL:         uint32(ep.RecordTTL),
+					Group:       dnsName,
 				})
 			}
 			services[index].Text = ep.Targets[0]
@@ -446,6 +462,55 @@ func (p coreDNSProvider) ApplyChanges(ctx context.Context, changes *plan.Changes
 	return nil
 }
 
+// AdjustEndpoints canonicalizes the targets of SRV records to the format reported by Records.
+// A priority of 0 cannot be stored and is served as the default priority instead.
+func (p coreDNSProvider) AdjustEndpoints(endpoints []*endpoint.Endpoint) ([]*endpoint.Endpoint, error) {
+	for _, ep := range endpoints {
+		if ep.RecordType != endpoint.RecordTypeSRV {
+			continue
+		}
+		for i, target := range ep.Targets {
+			var service Service
+			if err := parseSRVTarget(target, &service); err == nil {
+				if service.Priority == 0 {
+					service.Priority = priority
+				}
+				ep.Targets[i] = srvTarget(&service)
+			}
+		}
+	}
+	return endpoints, nil
+}
+
+// parseSRVTarget sets the priority, weight, port and host of the service from a SRV
+// record target of the form "<priority> <weight> <port> <target>".
+func parseSRVTarget(target string, service *Service) error {
+	fields := strings.Fields(target)
+	if len(fields) != 4 {
+		return fmt.Errorf("invalid SRV target %q", target)
+	}
+	var values [3]int
+	for i := range values {
+		v, err := strconv.Atoi(fields[i])
+		if err != nil || v < 0 || v > 65535 {
+			return fmt.Errorf("invalid SRV target %q", target)
+		}
+		values[i] = v
+	}
+	if values[2] == 0 {
+		return fmt.Errorf("invalid SRV target %q: port must not be 0", target)
+	}
+	service.Priority, service.Weight, service.Port = values[0], values[1], values[2]
+	service.Host = strings.TrimSuffix(fields[3], ".")
+	return nil
+}
+
+// srvTarget returns the SRV record target of the service, without the trailing dot of the host
+// like the other targets of the endpoints.
+func srvTarget(service *Service) string {
+	return fmt.Sprintf("%d %d %d %s", service.Priority, service.Weight, service.Port, service.Host)
+}
+
 func (p coreDNSProvider) etcdKeyFor(dnsName string) string {
 	domains := strings.Split(dnsName, ".")
 	reverse(domains)
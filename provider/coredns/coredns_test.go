@@ -359,3 +359,112 @@ func mergeLabels(e *endpoint.Endpoint, labels map[string]string) {
 		}
 	}
 }
+
+func TestSRVServiceTranslation(t *testing.T) {
+	client := fakeETCDClient{
+		map[string]*Service{
+			"/skydns/com/example/_tcp/_http/1": {Host: "web1.example.com", Port: 8080, Priority: 10, Weight: 50, TargetStrip: 1, Group: "_http._tcp.example.com"},
+			"/skydns/com/example/_tcp/_http/2": {Host: "web2.example.com", Port: 8080, Priority: 20, Weight: 50, TargetStrip: 1, Group: "_http._tcp.example.com"},
+			"/skydns/com/example/_tcp/_http/3": {Host: "1.2.3.4", TargetStrip: 1},
+			// a service with a port written by another tool isn't a SRV record
+			"/skydns/com/example/_tcp/_http/4": {Host: "5.6.7.8", Port: 80, TargetStrip: 1},
+		},
+	}
+	provider := coreDNSProvider{
+		client:        client,
+		coreDNSPrefix: defaultCoreDNSPrefix,
+	}
+	endpoints, err := provider.Records(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(endpoints) != 2 {
+		t.Fatalf("got unexpected number of endpoints: %d", len(endpoints))
+	}
+	for _, ep := range endpoints {
+		if ep.DNSName != "_http._tcp.example.com" {
+			t.Errorf("got unexpected DNS name: %s", ep.DNSName)
+		}
+		switch ep.RecordType {
+		case endpoint.RecordTypeSRV:
+			if len(ep.Targets) != 2 || !ep.Targets.Same(endpoint.Targets{"10 50 8080 web1.example.com", "20 50 8080 web2.example.com"}) {
+				t.Errorf("got unexpected SRV targets: %v", ep.Targets)
+			}
+		case endpoint.RecordTypeA:
+			if len(ep.Targets) != 2 || !ep.Targets.Same(endpoint.Targets{"1.2.3.4", "5.6.7.8"}) {
+				t.Errorf("got unexpected A targets: %v", ep.Targets)
+			}
+		default:
+			t.Errorf("got unexpected DNS record type: %s", ep.RecordType)
+		}
+	}
+}
+
+// valueETCDClient stores copies of the saved services like etcd does, the provider reusing the
+// service it saves.
+type valueETCDClient struct {
+	fakeETCDClient
+}
+
+func (c valueETCDClient) SaveService(service *Service) error {
+	saved := *service
+	return c.fakeETCDClient.SaveService(&saved)
+}
+
+func TestCoreDNSApplyChangesSRV(t *testing.T) {
+	client := valueETCDClient{fakeETCDClient{
+		map[string]*Service{},
+	}}
+	coredns := coreDNSProvider{
+		client:        client,
+		coreDNSPrefix: defaultCoreDNSPrefix,
+	}
+
+	desired, err := coredns.AdjustEndpoints([]*endpoint.Endpoint{
+		endpoint.NewEndpoint("_http._tcp.domain1.local", endpoint.RecordTypeSRV, "0 50 8080 web1.domain1.local", "20 50 8080 web2.domain1.local."),
+		endpoint.NewEndpoint("_http._tcp.domain1.local", endpoint.RecordTypeTXT, "string1"),
+		endpoint.NewEndpoint("sub._http._tcp.domain1.local", endpoint.RecordTypeA, "5.5.5.5"),
+		endpoint.NewEndpoint("_invalid._tcp.domain1.local", endpoint.RecordTypeSRV, "8080 web1.domain1.local"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := desired[0].Targets; !got.Same(endpoint.Targets{"10 50 8080 web1.domain1.local", "20 50 8080 web2.domain1.local"}) {
+		t.Errorf("got unexpected canonical SRV targets: %v", got)
+	}
+	if err := coredns.ApplyChanges(context.Background(), &plan.Changes{Create: desired}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(client.services) != 3 {
+		t.Fatalf("got unexpected number of services: %d", len(client.services))
+	}
+	for key, service := range client.services {
+		switch service.Host {
+		case "web1.domain1.local", "web2.domain1.local":
+			if service.Port != 8080 || service.Weight != 50 {
+				t.Errorf("got unexpected SRV service %s: %+v", key, service)
+			}
+			if service.Group != "_http._tcp.domain1.local" {
+				t.Errorf("got unexpected group for %s: %s", key, service.Group)
+			}
+		case "5.5.5.5":
+			if service.Port != 0 || service.Group != "sub._http._tcp.domain1.local" {
+				t.Errorf("got unexpected A service %s: %+v", key, service)
+			}
+		default:
+			t.Errorf("got unexpected service %s: %+v", key, service)
+		}
+	}
+
+	// the records read back match the desired ones, so nothing changes on the next sync
+	records, err := coredns.Records(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ep := range records {
+		if ep.RecordType == endpoint.RecordTypeSRV && !ep.Targets.Same(desired[0].Targets) {
+			t.Errorf("got unexpected SRV targets: %v", ep.Targets)
+		}
+	}
+}
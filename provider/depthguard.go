@@ -0,0 +1,109 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+// ErrRecordTooShallow is returned for the changes of records whose DNS names have fewer labels
+// than the minimum depth of a DepthGuardProvider.
+var ErrRecordTooShallow = errors.New("record above the minimum depth")
+
+var depthGuardRefusedChanges = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Namespace: "external_dns",
+		Subsystem: "provider",
+		Name:      "depth_guard_refused_changes_total",
+		Help:      "Number of changes refused because their records are above the minimum depth.",
+	},
+)
+
+func init() {
+	prometheus.MustRegister(depthGuardRefusedChanges)
+}
+
+// DepthGuardProvider refuses the changes sent to the provider it wraps when any of their records
+// has a DNS name of fewer labels than the minimum depth, e.g. with a minimum depth of 4 the records
+// of the zone k8s.example.com can be changed but not the apex example.com nor its other records.
+// It is a second layer of safety, independent of the domain filters.
+type DepthGuardProvider struct {
+	Provider
+	minDepth int
+}
+
+// NewDepthGuardProvider wraps the provider to refuse the changes of the records whose DNS names
+// have fewer labels than minDepth.
+func NewDepthGuardProvider(p Provider, minDepth int) *DepthGuardProvider {
+	return &DepthGuardProvider{Provider: p, minDepth: minDepth}
+}
+
+// ApplyChanges applies the changes with the wrapped provider, unless a record is above the
+// minimum depth. None of the changes are applied then, so that a record is never changed without
+// its ownership record.
+func (p *DepthGuardProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	var refused []string
+	for _, endpoints := range [][]*endpoint.Endpoint{changes.Create, changes.UpdateOld, changes.UpdateNew, changes.Delete} {
+		for _, ep := range endpoints {
+			if dnsNameDepth(ep.DNSName) < p.minDepth {
+				refused = append(refused, ep.RecordType+" "+ep.DNSName)
+			}
+		}
+	}
+	if len(refused) > 0 {
+		depthGuardRefusedChanges.Add(float64(len(changes.Create) + len(changes.UpdateNew) + len(changes.Delete)))
+		return fmt.Errorf("%w of %d labels: %s", ErrRecordTooShallow, p.minDepth, strings.Join(refused, ", "))
+	}
+	return p.Provider.ApplyChanges(ctx, changes)
+}
+
+// RecordsPage returns the page of the records of the wrapped provider.
+func (p *DepthGuardProvider) RecordsPage(ctx context.Context, pageToken string) ([]*endpoint.Endpoint, string, error) {
+	return RecordsPage(ctx, p.Provider, pageToken)
+}
+
+// ZoneNames returns the names of the zones of the wrapped provider.
+func (p *DepthGuardProvider) ZoneNames(ctx context.Context) ([]string, error) {
+	return ZoneNames(ctx, p.Provider)
+}
+
+// PropertyValuesEqual compares the values of provider specific properties as the wrapped provider does.
+func (p *DepthGuardProvider) PropertyValuesEqual(name string, previous string, current string) bool {
+	return PropertyValuesEqual(p.Provider, name, previous, current)
+}
+
+// Unwrap returns the wrapped provider.
+func (p *DepthGuardProvider) Unwrap() Provider {
+	return p.Provider
+}
+
+// dnsNameDepth returns the number of labels of the DNS name.
+func dnsNameDepth(name string) int {
+	name = endpoint.CanonicalDNSName(name)
+	if name == "" {
+		return 0
+	}
+	return strings.Count(name, ".") + 1
+}
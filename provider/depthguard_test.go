@@ -0,0 +1,59 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+func TestDepthGuardProvider(t *testing.T) {
+	inner := &flakyProvider{}
+	p := NewDepthGuardProvider(inner, 4)
+
+	require.NoError(t, p.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("app.k8s.example.com", endpoint.RecordTypeA, "1.1.1.1"),
+			endpoint.NewEndpoint("*.k8s.example.com.", endpoint.RecordTypeA, "1.1.1.1"),
+		},
+	}))
+	assert.Equal(t, 1, inner.calls)
+
+	// a single record above the depth refuses all the changes
+	before := counterValue(t, depthGuardRefusedChanges)
+	err := p.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{endpoint.NewEndpoint("app.k8s.example.com", endpoint.RecordTypeA, "1.1.1.1")},
+		Delete: []*endpoint.Endpoint{endpoint.NewEndpoint("Example.com.", endpoint.RecordTypeA, "1.1.1.1")},
+	})
+	assert.ErrorIs(t, err, ErrRecordTooShallow)
+	assert.ErrorContains(t, err, "A Example.com")
+	assert.Equal(t, 1, inner.calls)
+	assert.Equal(t, before+2, counterValue(t, depthGuardRefusedChanges))
+}
+
+func TestDNSNameDepth(t *testing.T) {
+	assert.Equal(t, 0, dnsNameDepth(""))
+	assert.Equal(t, 0, dnsNameDepth("."))
+	assert.Equal(t, 2, dnsNameDepth("example.com."))
+	assert.Equal(t, 4, dnsNameDepth("*.k8s.example.com"))
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+// ChangeDescription is the data the change description template is executed with.
+type ChangeDescription struct {
+	// ID is the ID of the change, the same for the same change planned by different runs.
+	ID            string
+	Action        string
+	DNSName       string
+	RecordType    string
+	SetIdentifier string
+	Targets       endpoint.Targets
+	// Resource is the Kubernetes resource of the record, e.g. ingress/default/web.
+	Resource string
+	// Owner is the owner ID of the record.
+	Owner string
+}
+
+// DescribingProvider sets the description rendered by a template for every change sent to the
+// provider it wraps, in the endpoint.DescriptionLabelKey label of the records, for the providers
+// to write it where they keep comments, e.g. the comment of the Route53 change batches, so that
+// their audit logs link the changes back to the Kubernetes resources.
+type DescribingProvider struct {
+	Provider
+	template *template.Template
+}
+
+// NewDescribingProvider wraps the provider to describe the changes with the template, executed
+// with a ChangeDescription, e.g. "{{.Action}} by {{.Owner}} for {{.Resource}}".
+func NewDescribingProvider(p Provider, text string) (*DescribingProvider, error) {
+	tmpl, err := template.New("description").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the change description template: %w", err)
+	}
+	return &DescribingProvider{Provider: p, template: tmpl}, nil
+}
+
+// ApplyChanges applies the changes, with their descriptions, with the wrapped provider. The
+// records of the changes are copied, the descriptions aren't set on the records of the caller.
+func (p *DescribingProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	described := &plan.Changes{
+		Create:    make([]*endpoint.Endpoint, 0, len(changes.Create)),
+		UpdateOld: changes.UpdateOld,
+		UpdateNew: make([]*endpoint.Endpoint, 0, len(changes.UpdateNew)),
+		Delete:    make([]*endpoint.Endpoint, 0, len(changes.Delete)),
+	}
+	for _, change := range changes.List() {
+		record := p.describe(change)
+		switch change.Action {
+		case plan.ActionCreate:
+			described.Create = append(described.Create, record)
+		case plan.ActionUpdate:
+			described.UpdateNew = append(described.UpdateNew, record)
+		case plan.ActionDelete:
+			described.Delete = append(described.Delete, record)
+		}
+	}
+	return p.Provider.ApplyChanges(ctx, described)
+}
+
+// describe returns a copy of the record of the change labeled with its description. The record
+// itself is returned when the template fails, the change isn't held back for its description.
+func (p *DescribingProvider) describe(change plan.Change) *endpoint.Endpoint {
+	record := change.Record()
+	var description strings.Builder
+	err := p.template.Execute(&description, ChangeDescription{
+		ID:            change.ID,
+		Action:        change.Action,
+		DNSName:       record.DNSName,
+		RecordType:    record.RecordType,
+		SetIdentifier: record.SetIdentifier,
+		Targets:       record.Targets,
+		Resource:      record.Labels[endpoint.ResourceLabelKey],
+		Owner:         record.Labels[endpoint.OwnerLabelKey],
+	})
+	if err != nil {
+		log.Warnf("Failed to describe the change %s: %v", change, err)
+		return record
+	}
+
+	described := record.DeepCopy()
+	if described.Labels == nil {
+		described.Labels = endpoint.NewLabels()
+	}
+	described.Labels[endpoint.DescriptionLabelKey] = description.String()
+	return described
+}
+
+// RecordsPage returns the page of the records of the wrapped provider.
+func (p *DescribingProvider) RecordsPage(ctx context.Context, pageToken string) ([]*endpoint.Endpoint, string, error) {
+	return RecordsPage(ctx, p.Provider, pageToken)
+}
+
+// ZoneNames returns the names of the zones of the wrapped provider.
+func (p *DescribingProvider) ZoneNames(ctx context.Context) ([]string, error) {
+	return ZoneNames(ctx, p.Provider)
+}
+
+// PropertyValuesEqual compares the values of provider specific properties as the wrapped provider does.
+func (p *DescribingProvider) PropertyValuesEqual(name string, previous string, current string) bool {
+	return PropertyValuesEqual(p.Provider, name, previous, current)
+}
+
+// Unwrap returns the wrapped provider.
+func (p *DescribingProvider) Unwrap() Provider {
+	return p.Provider
+}
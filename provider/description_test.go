@@ -0,0 +1,75 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+// changesProvider keeps the last changes applied to it.
+type changesProvider struct {
+	BaseProvider
+	changes *plan.Changes
+}
+
+func (p *changesProvider) Records(context.Context) ([]*endpoint.Endpoint, error) {
+	return nil, nil
+}
+
+func (p *changesProvider) ApplyChanges(_ context.Context, changes *plan.Changes) error {
+	p.changes = changes
+	return nil
+}
+
+func TestDescribingProvider(t *testing.T) {
+	inner := &changesProvider{}
+	p, err := NewDescribingProvider(inner, "{{.Action}} {{.RecordType}} {{.DNSName}} for {{.Resource}} by {{.Owner}}")
+	require.NoError(t, err)
+
+	created := endpoint.NewEndpoint("a.example.org", endpoint.RecordTypeA, "1.1.1.1")
+	created.Labels[endpoint.ResourceLabelKey] = "ingress/default/web"
+	created.Labels[endpoint.OwnerLabelKey] = "cluster-a"
+	old := endpoint.NewEndpoint("b.example.org", endpoint.RecordTypeA, "1.1.1.1")
+	updated := endpoint.NewEndpoint("b.example.org", endpoint.RecordTypeA, "2.2.2.2")
+	updated.Labels[endpoint.ResourceLabelKey] = "service/default/api"
+	deleted := endpoint.NewEndpoint("c.example.org", endpoint.RecordTypeCNAME, "a.example.org")
+	require.NoError(t, p.ApplyChanges(context.Background(), &plan.Changes{
+		Create:    []*endpoint.Endpoint{created},
+		UpdateOld: []*endpoint.Endpoint{old},
+		UpdateNew: []*endpoint.Endpoint{updated},
+		Delete:    []*endpoint.Endpoint{deleted},
+	}))
+
+	assert.Equal(t, "create A a.example.org for ingress/default/web by cluster-a", inner.changes.Create[0].Labels[endpoint.DescriptionLabelKey])
+	assert.Same(t, old, inner.changes.UpdateOld[0])
+	assert.Equal(t, "update A b.example.org for service/default/api by ", inner.changes.UpdateNew[0].Labels[endpoint.DescriptionLabelKey])
+	assert.Equal(t, "delete CNAME c.example.org for  by ", inner.changes.Delete[0].Labels[endpoint.DescriptionLabelKey])
+	// the records of the caller are left alone
+	assert.NotContains(t, created.Labels, endpoint.DescriptionLabelKey)
+	assert.NotContains(t, deleted.Labels, endpoint.DescriptionLabelKey)
+	assert.Same(t, inner, Unwrap(p))
+
+	_, err = NewDescribingProvider(inner, "{{.Action")
+	assert.ErrorContains(t, err, "failed to parse the change description template")
+}
@@ -22,6 +22,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/dnsimple/dnsimple-go/dnsimple"
 	log "github.com/sirupsen/logrus"
@@ -33,7 +34,10 @@ import (
 	"sigs.k8s.io/external-dns/provider"
 )
 
-const dnsimpleRecordTTL = 3600 // Default TTL of 1 hour if not set (DNSimple's default)
+const (
+	dnsimpleRecordTTL = 3600 // Default TTL of 1 hour if not set (DNSimple's default)
+	dnsimplePerPage   = 100  // Maximum number of items per page of the listings
+)
 
 type dnsimpleIdentityService struct {
 	service *dnsimple.IdentityService
@@ -76,6 +80,12 @@ func (z dnsimpleZoneService) UpdateRecord(ctx context.Context, accountID string,
 	return z.service.UpdateRecord(ctx, accountID, zoneID, recordID, recordAttributes)
 }
 
+type zonesListCache struct {
+	age      time.Time
+	duration time.Duration
+	zones    map[string]dnsimple.Zone
+}
+
 type dnsimpleProvider struct {
 	provider.BaseProvider
 	client       dnsimpleZoneServiceInterface
@@ -84,6 +94,9 @@ type dnsimpleProvider struct {
 	domainFilter endpoint.DomainFilter
 	zoneIDFilter provider.ZoneIDFilter
 	dryRun       bool
+	zonesCache   zonesListCache
+	// records listed by Records per zone name, used to look up the IDs of the records to change
+	records map[string][]dnsimple.ZoneRecord
 }
 
 type dnsimpleChange struct {
@@ -98,7 +111,7 @@ const (
 )
 
 // NewDnsimpleProvider initializes a new Dnsimple based provider
-func NewDnsimpleProvider(domainFilter endpoint.DomainFilter, zoneIDFilter provider.ZoneIDFilter, dryRun bool) (provider.Provider, error) {
+func NewDnsimpleProvider(domainFilter endpoint.DomainFilter, zoneIDFilter provider.ZoneIDFilter, zonesCacheDuration time.Duration, dryRun bool) (provider.Provider, error) {
 	oauthToken := os.Getenv("DNSIMPLE_OAUTH")
 	if len(oauthToken) == 0 {
 		return nil, fmt.Errorf("no dnsimple oauth token provided")
@@ -116,6 +129,7 @@ func NewDnsimpleProvider(domainFilter endpoint.DomainFilter, zoneIDFilter provid
 		domainFilter: domainFilter,
 		zoneIDFilter: zoneIDFilter,
 		dryRun:       dryRun,
+		zonesCache:   zonesListCache{duration: zonesCacheDuration},
 	}
 
 	whoamiResponse, err := provider.identity.Whoami(context.Background())
@@ -138,9 +152,14 @@ func (p *dnsimpleProvider) GetAccountID(ctx context.Context) (accountID string,
 
 // Returns a list of filtered Zones
 func (p *dnsimpleProvider) Zones(ctx context.Context) (map[string]dnsimple.Zone, error) {
+	if p.zonesCache.zones != nil && time.Since(p.zonesCache.age) < p.zonesCache.duration {
+		log.Debug("Using cached zones list")
+		return p.zonesCache.zones, nil
+	}
+
 	zones := make(map[string]dnsimple.Zone)
 	page := 1
-	listOptions := &dnsimple.ZoneListOptions{}
+	listOptions := &dnsimple.ZoneListOptions{ListOptions: dnsimple.ListOptions{PerPage: dnsimple.Int(dnsimplePerPage)}}
 	for {
 		listOptions.Page = &page
 		zonesResponse, err := p.client.ListZones(ctx, p.accountID, listOptions)
@@ -164,6 +183,11 @@ func (p *dnsimpleProvider) Zones(ctx context.Context) (map[string]dnsimple.Zone,
 			break
 		}
 	}
+
+	if p.zonesCache.duration > time.Duration(0) {
+		p.zonesCache.zones = zones
+		p.zonesCache.age = time.Now()
+	}
 	return zones, nil
 }
 
@@ -173,15 +197,17 @@ func (p *dnsimpleProvider) Records(ctx context.Context) (endpoints []*endpoint.E
 	if err != nil {
 		return nil, err
 	}
+	zoneRecords := make(map[string][]dnsimple.ZoneRecord, len(zones))
 	for _, zone := range zones {
 		page := 1
-		listOptions := &dnsimple.ZoneRecordListOptions{}
+		listOptions := &dnsimple.ZoneRecordListOptions{ListOptions: dnsimple.ListOptions{PerPage: dnsimple.Int(dnsimplePerPage)}}
 		for {
 			listOptions.Page = &page
 			records, err := p.client.ListRecords(ctx, p.accountID, zone.Name, listOptions)
 			if err != nil {
 				return nil, err
 			}
+			zoneRecords[zone.Name] = append(zoneRecords[zone.Name], records.Data...)
 			for _, record := range records.Data {
 				switch record.Type {
 				case "A", "CNAME", "TXT":
@@ -203,6 +229,7 @@ func (p *dnsimpleProvider) Records(ctx context.Context) (endpoints []*endpoint.E
 			}
 		}
 	}
+	p.records = zoneRecords
 	return endpoints, nil
 }
 
@@ -274,7 +301,7 @@ func (p *dnsimpleProvider) submitChanges(ctx context.Context, changes []*dnsimpl
 					return err
 				}
 			case dnsimpleDelete:
-				recordID, err := p.GetRecordID(ctx, zone.Name, *recordAttributes.Name)
+				recordID, err := p.recordID(ctx, zone.Name, change.ResourceRecordSet)
 				if err != nil {
 					return err
 				}
@@ -283,7 +310,7 @@ func (p *dnsimpleProvider) submitChanges(ctx context.Context, changes []*dnsimpl
 					return err
 				}
 			case dnsimpleUpdate:
-				recordID, err := p.GetRecordID(ctx, zone.Name, *recordAttributes.Name)
+				recordID, err := p.recordID(ctx, zone.Name, change.ResourceRecordSet)
 				if err != nil {
 					return err
 				}
@@ -297,10 +324,32 @@ func (p *dnsimpleProvider) submitChanges(ctx context.Context, changes []*dnsimpl
 	return nil
 }
 
+// recordID returns the ID of the record with the name and type of the changed record, preferring the one
+// with the same content. It looks the record up in the records listed by Records before listing the
+// records of the name.
+func (p *dnsimpleProvider) recordID(ctx context.Context, zone string, changed dnsimple.ZoneRecord) (int64, error) {
+	var recordID int64
+	for _, record := range p.records[zone] {
+		if record.Name != changed.Name || record.Type != changed.Type {
+			continue
+		}
+		if record.Content == changed.Content {
+			return record.ID, nil
+		}
+		if recordID == 0 {
+			recordID = record.ID
+		}
+	}
+	if recordID != 0 {
+		return recordID, nil
+	}
+	return p.GetRecordID(ctx, zone, changed.Name)
+}
+
 // GetRecordID returns the record ID for a given record name and zone.
 func (p *dnsimpleProvider) GetRecordID(ctx context.Context, zone string, recordName string) (recordID int64, err error) {
 	page := 1
-	listOptions := &dnsimple.ZoneRecordListOptions{Name: &recordName}
+	listOptions := &dnsimple.ZoneRecordListOptions{Name: &recordName, ListOptions: dnsimple.ListOptions{PerPage: dnsimple.Int(dnsimplePerPage)}}
 	for {
 		listOptions.Page = &page
 		records, err := p.client.ListRecords(ctx, p.accountID, zone, listOptions)
@@ -344,6 +393,9 @@ func (p *dnsimpleProvider) ApplyChanges(ctx context.Context, changes *plan.Chang
 	combinedChanges = append(combinedChanges, newDnsimpleChanges(dnsimpleUpdate, changes.UpdateNew)...)
 	combinedChanges = append(combinedChanges, newDnsimpleChanges(dnsimpleDelete, changes.Delete)...)
 
+	// the records change, they are listed again by the next call of Records
+	defer func() { p.records = nil }()
+
 	return p.submitChanges(ctx, combinedChanges)
 }
 
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/dnsimple/dnsimple-go/dnsimple"
 	"github.com/stretchr/testify/assert"
@@ -105,10 +106,10 @@ func TestDnsimpleServices(t *testing.T) {
 	// Setup mock services
 	// Note: AnythingOfType doesn't work with interfaces https://github.com/stretchr/testify/issues/519
 	mockDNS := &mockDnsimpleZoneServiceInterface{}
-	mockDNS.On("ListZones", context.Background(), "1", &dnsimple.ZoneListOptions{ListOptions: dnsimple.ListOptions{Page: dnsimple.Int(1)}}).Return(&dnsimpleListZonesResponse, nil)
-	mockDNS.On("ListZones", context.Background(), "2", &dnsimple.ZoneListOptions{ListOptions: dnsimple.ListOptions{Page: dnsimple.Int(1)}}).Return(nil, fmt.Errorf("Account ID not found"))
-	mockDNS.On("ListRecords", context.Background(), "1", "example.com", &dnsimple.ZoneRecordListOptions{ListOptions: dnsimple.ListOptions{Page: dnsimple.Int(1)}}).Return(&dnsimpleListRecordsResponse, nil)
-	mockDNS.On("ListRecords", context.Background(), "1", "example-beta.com", &dnsimple.ZoneRecordListOptions{ListOptions: dnsimple.ListOptions{Page: dnsimple.Int(1)}}).Return(&dnsimple.ZoneRecordsResponse{Response: dnsimple.Response{Pagination: &dnsimple.Pagination{}}}, nil)
+	mockDNS.On("ListZones", context.Background(), "1", &dnsimple.ZoneListOptions{ListOptions: dnsimple.ListOptions{Page: dnsimple.Int(1), PerPage: dnsimple.Int(dnsimplePerPage)}}).Return(&dnsimpleListZonesResponse, nil)
+	mockDNS.On("ListZones", context.Background(), "2", &dnsimple.ZoneListOptions{ListOptions: dnsimple.ListOptions{Page: dnsimple.Int(1), PerPage: dnsimple.Int(dnsimplePerPage)}}).Return(nil, fmt.Errorf("Account ID not found"))
+	mockDNS.On("ListRecords", context.Background(), "1", "example.com", &dnsimple.ZoneRecordListOptions{ListOptions: dnsimple.ListOptions{Page: dnsimple.Int(1), PerPage: dnsimple.Int(dnsimplePerPage)}}).Return(&dnsimpleListRecordsResponse, nil)
+	mockDNS.On("ListRecords", context.Background(), "1", "example-beta.com", &dnsimple.ZoneRecordListOptions{ListOptions: dnsimple.ListOptions{Page: dnsimple.Int(1), PerPage: dnsimple.Int(dnsimplePerPage)}}).Return(&dnsimple.ZoneRecordsResponse{Response: dnsimple.Response{Pagination: &dnsimple.Pagination{}}}, nil)
 
 	for _, record := range records {
 		recordName := record.Name
@@ -124,7 +125,7 @@ func TestDnsimpleServices(t *testing.T) {
 			Data:     []dnsimple.ZoneRecord{record},
 		}
 
-		mockDNS.On("ListRecords", context.Background(), "1", record.ZoneID, &dnsimple.ZoneRecordListOptions{Name: &recordName, ListOptions: dnsimple.ListOptions{Page: dnsimple.Int(1)}}).Return(&dnsimpleRecordResponse, nil)
+		mockDNS.On("ListRecords", context.Background(), "1", record.ZoneID, &dnsimple.ZoneRecordListOptions{Name: &recordName, ListOptions: dnsimple.ListOptions{Page: dnsimple.Int(1), PerPage: dnsimple.Int(dnsimplePerPage)}}).Return(&dnsimpleRecordResponse, nil)
 		mockDNS.On("CreateRecord", context.Background(), "1", record.ZoneID, simpleRecord).Return(&dnsimple.ZoneRecordResponse{}, nil)
 		mockDNS.On("DeleteRecord", context.Background(), "1", record.ZoneID, record.ID).Return(&dnsimple.ZoneRecordResponse{}, nil)
 		mockDNS.On("UpdateRecord", context.Background(), "1", record.ZoneID, record.ID, simpleRecord).Return(&dnsimple.ZoneRecordResponse{}, nil)
@@ -139,6 +140,8 @@ func TestDnsimpleServices(t *testing.T) {
 	t.Run("ApplyChanges/SkipUnknownZone", testDnsimpleProviderApplyChangesSkipsUnknown)
 	t.Run("SuitableZone", testDnsimpleSuitableZone)
 	t.Run("GetRecordID", testDnsimpleGetRecordID)
+	t.Run("RecordID", testDnsimpleRecordID)
+	t.Run("ZonesCache", testDnsimpleZonesCache)
 }
 
 func testDnsimpleProviderZones(t *testing.T) {
@@ -211,7 +214,7 @@ func testDnsimpleSuitableZone(t *testing.T) {
 
 func TestNewDnsimpleProvider(t *testing.T) {
 	os.Setenv("DNSIMPLE_OAUTH", "xxxxxxxxxxxxxxxxxxxxxxxxxx")
-	_, err := NewDnsimpleProvider(endpoint.NewDomainFilter([]string{"example.com"}), provider.NewZoneIDFilter([]string{""}), true)
+	_, err := NewDnsimpleProvider(endpoint.NewDomainFilter([]string{"example.com"}), provider.NewZoneIDFilter([]string{""}), 0, true)
 	if err == nil {
 		t.Errorf("Expected to fail new provider on bad token")
 	}
@@ -235,6 +238,46 @@ func testDnsimpleGetRecordID(t *testing.T) {
 	assert.Equal(t, int64(1), result)
 }
 
+func testDnsimpleRecordID(t *testing.T) {
+	ctx := context.Background()
+	p := dnsimpleProvider{client: mockProvider.client, accountID: "1"}
+
+	// The records listed by Records are looked up by name, type and content
+	p.records = map[string][]dnsimple.ZoneRecord{
+		"example.com": {
+			{ID: 10, Name: "multi", Type: "A", Content: "127.0.0.1"},
+			{ID: 11, Name: "multi", Type: "A", Content: "127.0.0.2"},
+			{ID: 12, Name: "multi", Type: "TXT", Content: "text"},
+		},
+	}
+	result, err := p.recordID(ctx, "example.com", dnsimple.ZoneRecord{Name: "multi", Type: "A", Content: "127.0.0.2"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(11), result)
+
+	result, err = p.recordID(ctx, "example.com", dnsimple.ZoneRecord{Name: "multi", Type: "TXT", Content: "new text"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), result)
+
+	// Records not listed are listed by name
+	result, err = p.recordID(ctx, "example.com", dnsimple.ZoneRecord{Name: "example", Type: "CNAME", Content: "target"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), result)
+}
+
+func testDnsimpleZonesCache(t *testing.T) {
+	ctx := context.Background()
+	mockDNS := &mockDnsimpleZoneServiceInterface{}
+	mockDNS.On("ListZones", ctx, "1", &dnsimple.ZoneListOptions{ListOptions: dnsimple.ListOptions{Page: dnsimple.Int(1), PerPage: dnsimple.Int(dnsimplePerPage)}}).Return(&dnsimpleListZonesResponse, nil).Once()
+	p := dnsimpleProvider{client: mockDNS, accountID: "1", zonesCache: zonesListCache{duration: time.Hour}}
+
+	for i := 0; i < 2; i++ {
+		result, err := p.Zones(ctx)
+		require.NoError(t, err)
+		validateDnsimpleZones(t, result, dnsimpleListZonesResponse.Data)
+	}
+	mockDNS.AssertExpectations(t)
+}
+
 func validateDnsimpleZones(t *testing.T, zones map[string]dnsimple.Zone, expected []dnsimple.Zone) {
 	require.Len(t, zones, len(expected))
 
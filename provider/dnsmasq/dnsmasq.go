@@ -0,0 +1,409 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dnsmasq
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+const (
+	// fileHeader is written at the top of the hosts file.
+	fileHeader = "# This file is managed by ExternalDNS, manual changes will be overwritten."
+	// txtPrefix marks the comment lines holding TXT records. dnsmasq can't serve TXT records from
+	// hosts files, they are only kept for the TXT registry.
+	txtPrefix = "#txt "
+	// cnamePrefix starts the lines of the CNAME records in the configuration file.
+	cnamePrefix = "cname="
+)
+
+// ErrNoHostsFile is returned when there is no hosts file configured.
+var ErrNoHostsFile = errors.New("no dnsmasq hosts file given, set --dnsmasq-hosts-file")
+
+// DnsmasqProvider is an implementation of Provider for dnsmasq. The records are rendered into a
+// file in hosts format that dnsmasq reads with --addn-hosts or --hostsdir, the CNAME records into
+// a configuration file that dnsmasq reads with --conf-file or --conf-dir.
+type DnsmasqProvider struct {
+	provider.BaseProvider
+	hostsFile    string
+	confFile     string
+	pidFile      string
+	domainFilter endpoint.DomainFilter
+	dryRun       bool
+}
+
+// DnsmasqConfig is used for configuring a DnsmasqProvider.
+type DnsmasqConfig struct {
+	// The hosts file the records are written to.
+	HostsFile string
+	// An optional configuration file the CNAME records are written to, they are skipped without it.
+	ConfFile string
+	// An optional pid file of dnsmasq, the process is sent SIGHUP to reload the hosts file after changes.
+	PidFile string
+	// A filter to apply when looking up and applying records.
+	DomainFilter endpoint.DomainFilter
+	// Do nothing and log what would have changed to stdout.
+	DryRun bool
+}
+
+// dnsmasqKey identifies the record set of a name and type in the hosts file.
+type dnsmasqKey struct {
+	DNSName    string
+	RecordType string
+}
+
+// NewDnsmasqProvider initializes a new dnsmasq based Provider.
+func NewDnsmasqProvider(cfg DnsmasqConfig) (*DnsmasqProvider, error) {
+	if cfg.HostsFile == "" {
+		return nil, ErrNoHostsFile
+	}
+	return &DnsmasqProvider{
+		hostsFile:    cfg.HostsFile,
+		confFile:     cfg.ConfFile,
+		pidFile:      cfg.PidFile,
+		domainFilter: cfg.DomainFilter,
+		dryRun:       cfg.DryRun,
+	}, nil
+}
+
+// GetDomainFilter returns the domain filter of the provider.
+func (p *DnsmasqProvider) GetDomainFilter() endpoint.DomainFilter {
+	return p.domainFilter
+}
+
+// Records implements Provider, populating a slice of endpoints from the hosts file.
+func (p *DnsmasqProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	records, err := p.readRecords()
+	if err != nil {
+		return nil, err
+	}
+
+	endpoints := make([]*endpoint.Endpoint, 0, len(records))
+	for _, key := range sortedKeys(records) {
+		if !p.domainFilter.Match(key.DNSName) {
+			continue
+		}
+		endpoints = append(endpoints, endpoint.NewEndpoint(key.DNSName, key.RecordType, records[key]...))
+	}
+	return endpoints, nil
+}
+
+// AdjustEndpoints drops the records dnsmasq can't serve from a hosts file, or from the
+// configuration file for CNAME records, and the TTLs, dnsmasq answers all of them with its --local-ttl.
+func (p *DnsmasqProvider) AdjustEndpoints(endpoints []*endpoint.Endpoint) ([]*endpoint.Endpoint, error) {
+	adjusted := make([]*endpoint.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		switch ep.RecordType {
+		case endpoint.RecordTypeA, endpoint.RecordTypeAAAA, endpoint.RecordTypeTXT:
+		case endpoint.RecordTypeCNAME:
+			if p.confFile == "" {
+				log.Warnf("Skipping record %s of type %s, set --dnsmasq-conf-file to manage CNAME records", ep.DNSName, ep.RecordType)
+				continue
+			}
+		default:
+			log.Warnf("Skipping record %s of type %s, the dnsmasq provider only supports A, AAAA and CNAME records", ep.DNSName, ep.RecordType)
+			continue
+		}
+		ep.RecordTTL = 0
+		adjusted = append(adjusted, ep)
+	}
+	return adjusted, nil
+}
+
+// ApplyChanges implements Provider, rendering the desired state into the hosts file and the
+// configuration file and signalling dnsmasq to reload the hosts file.
+func (p *DnsmasqProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	if !changes.HasChanges() {
+		return nil
+	}
+
+	records, err := p.readRecords()
+	if err != nil {
+		return err
+	}
+
+	cnamesChanged := false
+	for _, ep := range append(append([]*endpoint.Endpoint{}, changes.Delete...), changes.UpdateOld...) {
+		cnamesChanged = cnamesChanged || ep.RecordType == endpoint.RecordTypeCNAME
+		log.Infof("Deleting %s %s %v", ep.RecordType, ep.DNSName, ep.Targets)
+		delete(records, dnsmasqKey{ep.DNSName, ep.RecordType})
+	}
+	for _, ep := range append(append([]*endpoint.Endpoint{}, changes.Create...), changes.UpdateNew...) {
+		if !p.domainFilter.Match(ep.DNSName) {
+			log.Debugf("Skipping record %s that does not match the domain filter", ep.DNSName)
+			continue
+		}
+		if ep.RecordType == endpoint.RecordTypeCNAME && p.confFile == "" {
+			log.Warnf("Skipping record %s of type %s, set --dnsmasq-conf-file to manage CNAME records", ep.DNSName, ep.RecordType)
+			continue
+		}
+		targets, err := validTargets(ep)
+		if err != nil {
+			log.Warnf("Skipping record %s: %v", ep.DNSName, err)
+			continue
+		}
+		log.Infof("Creating %s %s %v", ep.RecordType, ep.DNSName, targets)
+		records[dnsmasqKey{ep.DNSName, ep.RecordType}] = targets
+		cnamesChanged = cnamesChanged || ep.RecordType == endpoint.RecordTypeCNAME
+	}
+
+	if p.dryRun {
+		return nil
+	}
+	if err := p.writeRecords(records); err != nil {
+		return err
+	}
+	if cnamesChanged && p.confFile != "" {
+		log.Warnf("dnsmasq only reads the CNAME records of %s at startup, restart it to serve the changed ones", p.confFile)
+	}
+	return p.reload()
+}
+
+// readRecords parses the hosts file and the configuration file, a missing file is an empty one.
+func (p *DnsmasqProvider) readRecords() (map[dnsmasqKey][]string, error) {
+	records := map[dnsmasqKey][]string{}
+	if err := p.readCNAMEs(records); err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(p.hostsFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return records, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open hosts file: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(text, txtPrefix) {
+			name, value, ok := strings.Cut(strings.TrimPrefix(text, txtPrefix), " ")
+			if !ok {
+				return nil, fmt.Errorf("invalid TXT record in line %d of %s", line, p.hostsFile)
+			}
+			value, err := strconv.Unquote(strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("invalid TXT record in line %d of %s: %w", line, p.hostsFile, err)
+			}
+			key := dnsmasqKey{name, endpoint.RecordTypeTXT}
+			records[key] = append(records[key], value)
+			continue
+		}
+		if i := strings.Index(text, "#"); i >= 0 {
+			text = text[:i]
+		}
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		addr, err := netip.ParseAddr(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid address in line %d of %s: %w", line, p.hostsFile, err)
+		}
+		recordType := endpoint.RecordTypeA
+		if addr.Is6() {
+			recordType = endpoint.RecordTypeAAAA
+		}
+		for _, name := range fields[1:] {
+			key := dnsmasqKey{name, recordType}
+			records[key] = append(records[key], addr.String())
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read hosts file: %w", err)
+	}
+	return records, nil
+}
+
+// readCNAMEs parses the cname options of the configuration file, each of the form
+// cname=<name>,[<name>,]<target>[,<TTL>].
+func (p *DnsmasqProvider) readCNAMEs(records map[dnsmasqKey][]string) error {
+	if p.confFile == "" {
+		return nil
+	}
+	f, err := os.Open(p.confFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open configuration file: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if !strings.HasPrefix(text, cnamePrefix) {
+			return fmt.Errorf("unexpected option in line %d of %s, only cname options are supported", line, p.confFile)
+		}
+		values := strings.Split(strings.TrimPrefix(text, cnamePrefix), ",")
+		if _, err := strconv.Atoi(values[len(values)-1]); err == nil {
+			values = values[:len(values)-1]
+		}
+		if len(values) < 2 {
+			return fmt.Errorf("invalid CNAME record in line %d of %s", line, p.confFile)
+		}
+		target := values[len(values)-1]
+		for _, name := range values[:len(values)-1] {
+			records[dnsmasqKey{name, endpoint.RecordTypeCNAME}] = []string{target}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read configuration file: %w", err)
+	}
+	return nil
+}
+
+// writeRecords renders the records into the hosts file and the CNAME records into the
+// configuration file, if any.
+func (p *DnsmasqProvider) writeRecords(records map[dnsmasqKey][]string) error {
+	var hosts, conf bytes.Buffer
+	fmt.Fprintln(&hosts, fileHeader)
+	fmt.Fprintln(&conf, fileHeader)
+	for _, key := range sortedKeys(records) {
+		for _, target := range records[key] {
+			switch key.RecordType {
+			case endpoint.RecordTypeTXT:
+				fmt.Fprintf(&hosts, "%s%s %s\n", txtPrefix, key.DNSName, strconv.Quote(target))
+			case endpoint.RecordTypeCNAME:
+				fmt.Fprintf(&conf, "%s%s,%s\n", cnamePrefix, key.DNSName, target)
+			default:
+				fmt.Fprintf(&hosts, "%s %s\n", target, key.DNSName)
+			}
+		}
+	}
+
+	if err := writeFile(p.hostsFile, hosts.Bytes()); err != nil {
+		return fmt.Errorf("failed to write hosts file: %w", err)
+	}
+	if p.confFile != "" {
+		if err := writeFile(p.confFile, conf.Bytes()); err != nil {
+			return fmt.Errorf("failed to write configuration file: %w", err)
+		}
+	}
+	return nil
+}
+
+// writeFile replaces the file atomically, so dnsmasq never reads a partially written one.
+func writeFile(name string, content []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
+
+// reload sends SIGHUP to the dnsmasq process of the pid file, making it re-read its hosts files.
+// Without a pid file dnsmasq is expected to pick up the changes itself, e.g. with --hostsdir.
+func (p *DnsmasqProvider) reload() error {
+	if p.pidFile == "" {
+		return nil
+	}
+	content, err := os.ReadFile(p.pidFile)
+	if err != nil {
+		return fmt.Errorf("failed to read dnsmasq pid file: %w", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil {
+		return fmt.Errorf("invalid dnsmasq pid file %s: %w", p.pidFile, err)
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return fmt.Errorf("failed to find dnsmasq process %d: %w", pid, err)
+	}
+	if err := process.Signal(syscall.SIGHUP); err != nil {
+		return fmt.Errorf("failed to signal dnsmasq process %d: %w", pid, err)
+	}
+	log.Debugf("Sent SIGHUP to dnsmasq process %d", pid)
+	return nil
+}
+
+// validTargets returns the targets of the endpoint, checking that the addresses match the record type
+// and that CNAME records have a single target.
+func validTargets(ep *endpoint.Endpoint) ([]string, error) {
+	if ep.RecordType == endpoint.RecordTypeCNAME {
+		if len(ep.Targets) != 1 {
+			return nil, fmt.Errorf("CNAME record must have a single target, got %d", len(ep.Targets))
+		}
+		return []string{strings.TrimSuffix(ep.Targets[0], ".")}, nil
+	}
+	targets := make([]string, 0, len(ep.Targets))
+	for _, target := range ep.Targets {
+		if ep.RecordType == endpoint.RecordTypeTXT {
+			targets = append(targets, target)
+			continue
+		}
+		addr, err := netip.ParseAddr(target)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address %q", target)
+		}
+		if addr.Is4() != (ep.RecordType == endpoint.RecordTypeA) {
+			return nil, fmt.Errorf("address %q does not match record type %s", target, ep.RecordType)
+		}
+		targets = append(targets, addr.String())
+	}
+	return targets, nil
+}
+
+// sortedKeys returns the keys of the records, sorted by name and type to render a stable file.
+func sortedKeys(records map[dnsmasqKey][]string) []dnsmasqKey {
+	keys := make([]dnsmasqKey, 0, len(records))
+	for key := range records {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].DNSName != keys[j].DNSName {
+			return keys[i].DNSName < keys[j].DNSName
+		}
+		return keys[i].RecordType < keys[j].RecordType
+	})
+	return keys
+}
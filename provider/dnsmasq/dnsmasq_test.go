@@ -0,0 +1,275 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dnsmasq
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+func TestNewDnsmasqProvider(t *testing.T) {
+	_, err := NewDnsmasqProvider(DnsmasqConfig{})
+	assert.ErrorIs(t, err, ErrNoHostsFile)
+
+	_, err = NewDnsmasqProvider(DnsmasqConfig{HostsFile: filepath.Join(t.TempDir(), "external-dns.hosts")})
+	assert.NoError(t, err)
+}
+
+func TestDnsmasqRecords(t *testing.T) {
+	hostsFile := filepath.Join(t.TempDir(), "external-dns.hosts")
+	content := `# comment
+192.0.2.1 foo.example.org bar.example.org
+192.0.2.2 foo.example.org # trailing comment
+2001:db8::1 foo.example.org
+
+192.0.2.3 other.example.com
+#txt foo.example.org "heritage=external-dns,external-dns/owner=default"
+`
+	require.NoError(t, os.WriteFile(hostsFile, []byte(content), 0o644))
+
+	p, err := NewDnsmasqProvider(DnsmasqConfig{
+		HostsFile:    hostsFile,
+		DomainFilter: endpoint.NewDomainFilter([]string{"example.org"}),
+	})
+	require.NoError(t, err)
+
+	records, err := p.Records(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []*endpoint.Endpoint{
+		endpoint.NewEndpoint("bar.example.org", endpoint.RecordTypeA, "192.0.2.1"),
+		endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeA, "192.0.2.1", "192.0.2.2"),
+		endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeAAAA, "2001:db8::1"),
+		endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeTXT, "heritage=external-dns,external-dns/owner=default"),
+	}, records)
+}
+
+func TestDnsmasqRecordsMissingFile(t *testing.T) {
+	p, err := NewDnsmasqProvider(DnsmasqConfig{HostsFile: filepath.Join(t.TempDir(), "external-dns.hosts")})
+	require.NoError(t, err)
+
+	records, err := p.Records(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, records)
+}
+
+func TestDnsmasqRecordsInvalidFile(t *testing.T) {
+	for _, content := range []string{
+		"not-an-address foo.example.org\n",
+		"#txt foo.example.org unquoted\n",
+		"#txt foo.example.org\n",
+	} {
+		hostsFile := filepath.Join(t.TempDir(), "external-dns.hosts")
+		require.NoError(t, os.WriteFile(hostsFile, []byte(content), 0o644))
+
+		p, err := NewDnsmasqProvider(DnsmasqConfig{HostsFile: hostsFile})
+		require.NoError(t, err)
+
+		_, err = p.Records(context.Background())
+		assert.Error(t, err, content)
+	}
+}
+
+func TestDnsmasqApplyChanges(t *testing.T) {
+	hostsFile := filepath.Join(t.TempDir(), "external-dns.hosts")
+	content := `192.0.2.1 old.example.org
+192.0.2.2 update.example.org
+192.0.2.9 unmanaged.example.com
+`
+	require.NoError(t, os.WriteFile(hostsFile, []byte(content), 0o644))
+
+	p, err := NewDnsmasqProvider(DnsmasqConfig{
+		HostsFile:    hostsFile,
+		DomainFilter: endpoint.NewDomainFilter([]string{"example.org"}),
+	})
+	require.NoError(t, err)
+
+	err = p.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("new.example.org", endpoint.RecordTypeA, "192.0.2.10", "192.0.2.11"),
+			endpoint.NewEndpoint("new.example.org", endpoint.RecordTypeAAAA, "2001:db8::10"),
+			endpoint.NewEndpoint("new.example.org", endpoint.RecordTypeTXT, "heritage=external-dns"),
+			endpoint.NewEndpoint("filtered.example.net", endpoint.RecordTypeA, "192.0.2.12"),
+			endpoint.NewEndpoint("invalid.example.org", endpoint.RecordTypeA, "2001:db8::12"),
+		},
+		UpdateOld: []*endpoint.Endpoint{endpoint.NewEndpoint("update.example.org", endpoint.RecordTypeA, "192.0.2.2")},
+		UpdateNew: []*endpoint.Endpoint{endpoint.NewEndpoint("update.example.org", endpoint.RecordTypeA, "192.0.2.3")},
+		Delete:    []*endpoint.Endpoint{endpoint.NewEndpoint("old.example.org", endpoint.RecordTypeA, "192.0.2.1")},
+	})
+	require.NoError(t, err)
+
+	rendered, err := os.ReadFile(hostsFile)
+	require.NoError(t, err)
+	assert.Equal(t, fileHeader+`
+192.0.2.10 new.example.org
+192.0.2.11 new.example.org
+2001:db8::10 new.example.org
+#txt new.example.org "heritage=external-dns"
+192.0.2.9 unmanaged.example.com
+192.0.2.3 update.example.org
+`, string(rendered))
+
+	info, err := os.Stat(hostsFile)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o644), info.Mode().Perm())
+}
+
+func TestDnsmasqCNAMEs(t *testing.T) {
+	dir := t.TempDir()
+	hostsFile, confFile := filepath.Join(dir, "external-dns.hosts"), filepath.Join(dir, "external-dns.conf")
+	content := `# comment
+cname=old.example.org,a.example.org
+cname=update.example.org,alias.example.org,a.example.org,300
+`
+	require.NoError(t, os.WriteFile(confFile, []byte(content), 0o644))
+
+	p, err := NewDnsmasqProvider(DnsmasqConfig{HostsFile: hostsFile, ConfFile: confFile})
+	require.NoError(t, err)
+
+	records, err := p.Records(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []*endpoint.Endpoint{
+		endpoint.NewEndpoint("alias.example.org", endpoint.RecordTypeCNAME, "a.example.org"),
+		endpoint.NewEndpoint("old.example.org", endpoint.RecordTypeCNAME, "a.example.org"),
+		endpoint.NewEndpoint("update.example.org", endpoint.RecordTypeCNAME, "a.example.org"),
+	}, records)
+
+	err = p.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("a.example.org", endpoint.RecordTypeA, "192.0.2.10"),
+			endpoint.NewEndpoint("new.example.org", endpoint.RecordTypeCNAME, "a.example.org."),
+			endpoint.NewEndpoint("invalid.example.org", endpoint.RecordTypeCNAME, "a.example.org", "b.example.org"),
+		},
+		UpdateOld: []*endpoint.Endpoint{endpoint.NewEndpoint("update.example.org", endpoint.RecordTypeCNAME, "a.example.org")},
+		UpdateNew: []*endpoint.Endpoint{endpoint.NewEndpoint("update.example.org", endpoint.RecordTypeCNAME, "new.example.org")},
+		Delete:    []*endpoint.Endpoint{endpoint.NewEndpoint("old.example.org", endpoint.RecordTypeCNAME, "a.example.org")},
+	})
+	require.NoError(t, err)
+
+	rendered, err := os.ReadFile(confFile)
+	require.NoError(t, err)
+	assert.Equal(t, fileHeader+`
+cname=alias.example.org,a.example.org
+cname=new.example.org,a.example.org
+cname=update.example.org,new.example.org
+`, string(rendered))
+
+	rendered, err = os.ReadFile(hostsFile)
+	require.NoError(t, err)
+	assert.Equal(t, fileHeader+`
+192.0.2.10 a.example.org
+`, string(rendered))
+}
+
+func TestDnsmasqCNAMEsInvalidFile(t *testing.T) {
+	for _, content := range []string{
+		"address=/foo.example.org/192.0.2.1\n",
+		"cname=foo.example.org\n",
+	} {
+		confFile := filepath.Join(t.TempDir(), "external-dns.conf")
+		require.NoError(t, os.WriteFile(confFile, []byte(content), 0o644))
+
+		p, err := NewDnsmasqProvider(DnsmasqConfig{HostsFile: filepath.Join(t.TempDir(), "external-dns.hosts"), ConfFile: confFile})
+		require.NoError(t, err)
+
+		_, err = p.Records(context.Background())
+		assert.Error(t, err, content)
+	}
+}
+
+func TestDnsmasqApplyChangesDryRun(t *testing.T) {
+	hostsFile := filepath.Join(t.TempDir(), "external-dns.hosts")
+	p, err := NewDnsmasqProvider(DnsmasqConfig{HostsFile: hostsFile, DryRun: true})
+	require.NoError(t, err)
+
+	err = p.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{endpoint.NewEndpoint("new.example.org", endpoint.RecordTypeA, "192.0.2.10")},
+	})
+	require.NoError(t, err)
+	assert.NoFileExists(t, hostsFile)
+}
+
+func TestDnsmasqApplyChangesReload(t *testing.T) {
+	dir := t.TempDir()
+	pidFile := filepath.Join(dir, "dnsmasq.pid")
+	require.NoError(t, os.WriteFile(pidFile, []byte(strconv.Itoa(os.Getpid())+"\n"), 0o644))
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	p, err := NewDnsmasqProvider(DnsmasqConfig{HostsFile: filepath.Join(dir, "external-dns.hosts"), PidFile: pidFile})
+	require.NoError(t, err)
+
+	err = p.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{endpoint.NewEndpoint("new.example.org", endpoint.RecordTypeA, "192.0.2.10")},
+	})
+	require.NoError(t, err)
+
+	select {
+	case sig := <-signals:
+		assert.Equal(t, syscall.SIGHUP, sig)
+	case <-time.After(5 * time.Second):
+		t.Fatal("dnsmasq wasn't signalled")
+	}
+
+	require.NoError(t, os.WriteFile(pidFile, []byte("invalid"), 0o644))
+	err = p.ApplyChanges(context.Background(), &plan.Changes{
+		Delete: []*endpoint.Endpoint{endpoint.NewEndpoint("new.example.org", endpoint.RecordTypeA, "192.0.2.10")},
+	})
+	assert.Error(t, err)
+}
+
+func TestDnsmasqAdjustEndpoints(t *testing.T) {
+	p, err := NewDnsmasqProvider(DnsmasqConfig{HostsFile: filepath.Join(t.TempDir(), "external-dns.hosts")})
+	require.NoError(t, err)
+
+	adjusted, err := p.AdjustEndpoints([]*endpoint.Endpoint{
+		endpoint.NewEndpointWithTTL("a.example.org", endpoint.RecordTypeA, 300, "192.0.2.1"),
+		endpoint.NewEndpoint("cname.example.org", endpoint.RecordTypeCNAME, "a.example.org"),
+		endpoint.NewEndpoint("a.example.org", endpoint.RecordTypeTXT, "heritage=external-dns"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []*endpoint.Endpoint{
+		endpoint.NewEndpoint("a.example.org", endpoint.RecordTypeA, "192.0.2.1"),
+		endpoint.NewEndpoint("a.example.org", endpoint.RecordTypeTXT, "heritage=external-dns"),
+	}, adjusted)
+
+	// CNAME records are kept with a configuration file
+	p, err = NewDnsmasqProvider(DnsmasqConfig{HostsFile: filepath.Join(t.TempDir(), "external-dns.hosts"), ConfFile: filepath.Join(t.TempDir(), "external-dns.conf")})
+	require.NoError(t, err)
+
+	adjusted, err = p.AdjustEndpoints([]*endpoint.Endpoint{
+		endpoint.NewEndpoint("cname.example.org", endpoint.RecordTypeCNAME, "a.example.org"),
+		endpoint.NewEndpoint("mx.example.org", endpoint.RecordTypeMX, "10 a.example.org"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []*endpoint.Endpoint{
+		endpoint.NewEndpoint("cname.example.org", endpoint.RecordTypeCNAME, "a.example.org"),
+	}, adjusted)
+}
@@ -18,6 +18,7 @@ package exoscale
 
 import (
 	"context"
+	"errors"
 	"strings"
 
 	egoscale "github.com/exoscale/egoscale/v2"
@@ -83,9 +84,48 @@ func NewExoscaleProviderWithClient(client EgoscaleClientI, env, zone string, dry
 	return ep
 }
 
+// retryConfig retries the failed requests that may succeed when repeated, i.e. those failing on the
+// side of the API or without a response, but not the invalid ones.
+var retryConfig = provider.RetryConfig{
+	MaxRetries:      provider.DefaultRetryConfig.MaxRetries,
+	InitialInterval: provider.DefaultRetryConfig.InitialInterval,
+	MaxInterval:     provider.DefaultRetryConfig.MaxInterval,
+	Retryable: func(err error) bool {
+		return errors.Is(err, exoapi.ErrAPIError) || provider.RetryableError(err)
+	},
+}
+
+// exoscaleChange is the change of a record of a zone, sent as a request of its own.
+type exoscaleChange struct {
+	zoneID string
+	record egoscale.DNSDomainRecord
+}
+
+func (ep *ExoscaleProvider) listDomains(ctx context.Context) ([]egoscale.DNSDomain, error) {
+	var domains []egoscale.DNSDomain
+	err := retryConfig.Do(ctx, func(ctx context.Context) error {
+		var err error
+		domains, err = ep.client.ListDNSDomains(ctx, ep.apiZone)
+		return err
+	})
+	return domains, err
+}
+
+// listRecords lists the records of the zone. The Exoscale API returns all the records of a zone in a single
+// response, so unlike the listings of other providers they aren't fetched in pages.
+func (ep *ExoscaleProvider) listRecords(ctx context.Context, zoneID string) ([]egoscale.DNSDomainRecord, error) {
+	var records []egoscale.DNSDomainRecord
+	err := retryConfig.Do(ctx, func(ctx context.Context) error {
+		var err error
+		records, err = ep.client.ListDNSDomainRecords(ctx, ep.apiZone, zoneID)
+		return err
+	})
+	return records, err
+}
+
 func (ep *ExoscaleProvider) getZones(ctx context.Context) (map[string]string, error) {
 	ctx = exoapi.WithEndpoint(ctx, exoapi.NewReqEndpoint(ep.apiEnv, ep.apiZone))
-	domains, err := ep.client.ListDNSDomains(ctx, ep.apiZone)
+	domains, err := ep.listDomains(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -116,6 +156,21 @@ func (ep *ExoscaleProvider) ApplyChanges(ctx context.Context, changes *plan.Chan
 		return err
 	}
 
+	// The records of a zone are listed once for all updates and deletes, instead of once per change.
+	zoneRecords := map[string][]egoscale.DNSDomainRecord{}
+	recordsOf := func(zoneID string) ([]egoscale.DNSDomainRecord, error) {
+		if records, ok := zoneRecords[zoneID]; ok {
+			return records, nil
+		}
+		records, err := ep.listRecords(ctx, zoneID)
+		if err != nil {
+			return nil, err
+		}
+		zoneRecords[zoneID] = records
+		return records, nil
+	}
+
+	var creates, updates, deletes []exoscaleChange
 	for _, epoint := range changes.Create {
 		if !ep.domain.Match(epoint.DNSName) {
 			continue
@@ -132,16 +187,12 @@ func (ep *ExoscaleProvider) ApplyChanges(ctx context.Context, changes *plan.Chan
 			t := int64(epoint.RecordTTL)
 			ttl = &t
 		}
-		record := egoscale.DNSDomainRecord{
+		creates = append(creates, exoscaleChange{zoneID: zoneID, record: egoscale.DNSDomainRecord{
 			Name:    &name,
 			Type:    &epoint.RecordType,
 			TTL:     ttl,
 			Content: &epoint.Targets[0],
-		}
-		_, err := ep.client.CreateDNSDomainRecord(ctx, ep.apiZone, zoneID, &record)
-		if err != nil {
-			return err
-		}
+		}})
 	}
 
 	for _, epoint := range changes.UpdateNew {
@@ -154,7 +205,7 @@ func (ep *ExoscaleProvider) ApplyChanges(ctx context.Context, changes *plan.Chan
 			continue
 		}
 
-		records, err := ep.client.ListDNSDomainRecords(ctx, ep.apiZone, zoneID)
+		records, err := recordsOf(zoneID)
 		if err != nil {
 			return err
 		}
@@ -170,11 +221,7 @@ func (ep *ExoscaleProvider) ApplyChanges(ctx context.Context, changes *plan.Chan
 				ttl := int64(epoint.RecordTTL)
 				record.TTL = &ttl
 			}
-
-			err = ep.client.UpdateDNSDomainRecord(ctx, ep.apiZone, zoneID, &record)
-			if err != nil {
-				return err
-			}
+			updates = append(updates, exoscaleChange{zoneID: zoneID, record: record})
 
 			break
 		}
@@ -196,7 +243,7 @@ func (ep *ExoscaleProvider) ApplyChanges(ctx context.Context, changes *plan.Chan
 			continue
 		}
 
-		records, err := ep.client.ListDNSDomainRecords(ctx, ep.apiZone, zoneID)
+		records, err := recordsOf(zoneID)
 		if err != nil {
 			return err
 		}
@@ -206,16 +253,31 @@ func (ep *ExoscaleProvider) ApplyChanges(ctx context.Context, changes *plan.Chan
 				continue
 			}
 
-			err = ep.client.DeleteDNSDomainRecord(ctx, ep.apiZone, zoneID, &egoscale.DNSDomainRecord{ID: record.ID})
-			if err != nil {
-				return err
-			}
+			deletes = append(deletes, exoscaleChange{zoneID: zoneID, record: egoscale.DNSDomainRecord{ID: record.ID}})
 
 			break
 		}
 	}
 
-	return nil
+	// The Exoscale API takes a request per record, the requests of the changes are sent in batches.
+	err = provider.ApplyInBatches(ctx, retryConfig, provider.DefaultBatchSize, creates, func(ctx context.Context, change exoscaleChange) error {
+		_, err := ep.client.CreateDNSDomainRecord(ctx, ep.apiZone, change.zoneID, &change.record)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	err = provider.ApplyInBatches(ctx, retryConfig, provider.DefaultBatchSize, updates, func(ctx context.Context, change exoscaleChange) error {
+		return ep.client.UpdateDNSDomainRecord(ctx, ep.apiZone, change.zoneID, &change.record)
+	})
+	if err != nil {
+		return err
+	}
+
+	return provider.ApplyInBatches(ctx, retryConfig, provider.DefaultBatchSize, deletes, func(ctx context.Context, change exoscaleChange) error {
+		return ep.client.DeleteDNSDomainRecord(ctx, ep.apiZone, change.zoneID, &change.record)
+	})
 }
 
 // Records returns the list of endpoints
@@ -223,13 +285,13 @@ func (ep *ExoscaleProvider) Records(ctx context.Context) ([]*endpoint.Endpoint,
 	ctx = exoapi.WithEndpoint(ctx, exoapi.NewReqEndpoint(ep.apiEnv, ep.apiZone))
 	endpoints := make([]*endpoint.Endpoint, 0)
 
-	domains, err := ep.client.ListDNSDomains(ctx, ep.apiZone)
+	domains, err := ep.listDomains(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	for _, domain := range domains {
-		records, err := ep.client.ListDNSDomainRecords(ctx, ep.apiZone, *domain.ID)
+		records, err := ep.listRecords(ctx, *domain.ID)
 		if err != nil {
 			return nil, err
 		}
@@ -18,9 +18,12 @@ package exoscale
 
 import (
 	"context"
+	"errors"
+	"sync"
 	"testing"
 
 	egoscale "github.com/exoscale/egoscale/v2"
+	exoapi "github.com/exoscale/egoscale/v2/api"
 	"github.com/stretchr/testify/assert"
 
 	"sigs.k8s.io/external-dns/endpoint"
@@ -45,6 +48,8 @@ type updateRecordExoscale struct {
 }
 
 var (
+	// changesMu guards the changes recorded by the stub, the requests of the changes are sent concurrently.
+	changesMu      sync.Mutex
 	createExoscale []createRecordExoscale
 	deleteExoscale []deleteRecordExoscale
 	updateExoscale []updateRecordExoscale
@@ -93,16 +98,22 @@ func (ep *ExoscaleClientStub) ListDNSDomainRecords(ctx context.Context, _, domai
 }
 
 func (ep *ExoscaleClientStub) CreateDNSDomainRecord(ctx context.Context, _, domainID string, record *egoscale.DNSDomainRecord) (*egoscale.DNSDomainRecord, error) {
+	changesMu.Lock()
+	defer changesMu.Unlock()
 	createExoscale = append(createExoscale, createRecordExoscale{domainID: domainID, record: record})
 	return record, nil
 }
 
 func (ep *ExoscaleClientStub) DeleteDNSDomainRecord(ctx context.Context, _, domainID string, record *egoscale.DNSDomainRecord) error {
+	changesMu.Lock()
+	defer changesMu.Unlock()
 	deleteExoscale = append(deleteExoscale, deleteRecordExoscale{domainID: domainID, recordID: *record.ID})
 	return nil
 }
 
 func (ep *ExoscaleClientStub) UpdateDNSDomainRecord(ctx context.Context, _, domainID string, record *egoscale.DNSDomainRecord) error {
+	changesMu.Lock()
+	defer changesMu.Unlock()
 	updateExoscale = append(updateExoscale, updateRecordExoscale{domainID: domainID, record: record})
 	return nil
 }
@@ -203,6 +214,60 @@ func TestExoscaleApplyChanges(t *testing.T) {
 	assert.Equal(t, *groups[domainIDs[0]][0].ID, *updateExoscale[0].record.ID)
 }
 
+// countingClientStub counts the listings of records and fails the first ones with the errors.
+type countingClientStub struct {
+	ExoscaleClientStub
+	listErrors   []error
+	listRecords  map[string]int
+	listRequests int
+}
+
+func (ep *countingClientStub) ListDNSDomainRecords(ctx context.Context, zone, domainID string) ([]egoscale.DNSDomainRecord, error) {
+	ep.listRequests++
+	if ep.listRequests <= len(ep.listErrors) {
+		return nil, ep.listErrors[ep.listRequests-1]
+	}
+	ep.listRecords[domainID]++
+	return ep.ExoscaleClientStub.ListDNSDomainRecords(ctx, zone, domainID)
+}
+
+func TestExoscaleApplyChangesListsZonesOnce(t *testing.T) {
+	client := &countingClientStub{listRecords: map[string]int{}}
+	provider := NewExoscaleProviderWithClient(client, "", "", false)
+
+	deleteExoscale = make([]deleteRecordExoscale, 0)
+	updateExoscale = make([]updateRecordExoscale, 0)
+	err := provider.ApplyChanges(context.Background(), &plan.Changes{
+		UpdateNew: []*endpoint.Endpoint{
+			{DNSName: "v1.foo.com", RecordType: "TXT", Targets: []string{"new"}},
+			{DNSName: "v2.foo.com", RecordType: "CNAME", Targets: []string{"new"}},
+		},
+		Delete: []*endpoint.Endpoint{
+			{DNSName: "v2.bar.com", RecordType: "A", Targets: []string{"test"}},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{domainIDs[0]: 1, domainIDs[1]: 1}, client.listRecords)
+	assert.Equal(t, 2, len(updateExoscale))
+	assert.Equal(t, 1, len(deleteExoscale))
+}
+
+func TestExoscaleGetRecordsRetries(t *testing.T) {
+	client := &countingClientStub{listRecords: map[string]int{}, listErrors: []error{exoapi.ErrAPIError}}
+	provider := NewExoscaleProviderWithClient(client, "", "", false)
+
+	recs, err := provider.Records(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(recs))
+
+	client = &countingClientStub{listRecords: map[string]int{}, listErrors: []error{exoapi.ErrInvalidRequest}}
+	provider = NewExoscaleProviderWithClient(client, "", "", false)
+
+	_, err = provider.Records(context.Background())
+	assert.True(t, errors.Is(err, exoapi.ErrInvalidRequest))
+	assert.Equal(t, 1, client.listRequests)
+}
+
 func TestExoscaleMerge_NoUpdateOnTTL0Changes(t *testing.T) {
 	updateOld := []*endpoint.Endpoint{
 		{
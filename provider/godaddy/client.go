@@ -36,6 +36,9 @@ import (
 // DefaultTimeout api requests after 180s
 const DefaultTimeout = 180 * time.Second
 
+// defaultRetryAfter is the delay in seconds before retrying a rate limited request without Retry-After header
+const defaultRetryAfter = 60
+
 // Errors
 var (
 	ErrAPIDown = errors.New("godaddy: the GoDaddy API is down")
@@ -122,8 +125,8 @@ func NewClient(useOTE bool, apiKey, apiSecret string) (*Client, error) {
 		APISecret:   apiSecret,
 		APIEndPoint: endpoint,
 		Client:      &http.Client{},
-		// Add one token every second
-		Ratelimiter: rate.NewLimiter(rate.Every(time.Second), 60),
+		// Add one token every 2 seconds to a bucket of 30, never sending more than 60 requests in a minute
+		Ratelimiter: rate.NewLimiter(rate.Every(2*time.Second), 30),
 		Timeout:     DefaultTimeout,
 	}
 
@@ -229,8 +232,13 @@ func (c *Client) Do(req *http.Request) (*http.Response, error) {
 	c.Ratelimiter.Wait(req.Context())
 	resp, err := c.Client.Do(req)
 	// In case of several clients behind NAT we still can hit rate limit
-	for i := 1; i < 3 && err == nil && resp.StatusCode == 429; i++ {
+	for i := 1; i < 3 && err == nil && resp.StatusCode == http.StatusTooManyRequests; i++ {
+		resp.Body.Close()
+
 		retryAfter, _ := strconv.ParseInt(resp.Header.Get("Retry-After"), 10, 0)
+		if retryAfter <= 0 {
+			retryAfter = defaultRetryAfter
+		}
 
 		jitter := rand.Int63n(retryAfter)
 		retryAfterSec := retryAfter + jitter/2
@@ -238,6 +246,13 @@ func (c *Client) Do(req *http.Request) (*http.Response, error) {
 		sleepTime := time.Duration(retryAfterSec) * time.Second
 		time.Sleep(sleepTime)
 
+		// the body of the previous attempt has been consumed
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				break
+			}
+		}
+
 		c.Ratelimiter.Wait(req.Context())
 		resp, err = c.Client.Do(req)
 	}
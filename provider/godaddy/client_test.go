@@ -0,0 +1,58 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package godaddy
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
+)
+
+func TestClientRetriesRateLimitedRequest(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if len(bodies) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := &Client{
+		APIEndPoint: server.URL,
+		Client:      &http.Client{},
+		Ratelimiter: rate.NewLimiter(rate.Every(time.Millisecond), 1),
+		Timeout:     DefaultTimeout,
+	}
+
+	records := []gdRecordField{{Name: "godaddy", Type: "A", TTL: gdMinimalTTL, Data: "203.0.113.42"}}
+	assert.NoError(t, client.Patch("/v1/domains/example.net/records", records, nil))
+
+	// the body is sent again with the retry
+	assert.Len(t, bodies, 2)
+	assert.Equal(t, bodies[0], bodies[1])
+	assert.JSONEq(t, `[{"name":"godaddy","type":"A","ttl":600,"data":"203.0.113.42"}]`, bodies[1])
+}
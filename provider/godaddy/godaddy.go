@@ -36,6 +36,8 @@ const (
 	gdCreate     = 0
 	gdReplace    = 1
 	gdDelete     = 2
+	// gdMaxBatchSize is the maximum number of records added by a single PATCH request
+	gdMaxBatchSize = 100
 )
 
 var actionNames = []string{
@@ -69,6 +71,8 @@ type GDProvider struct {
 
 type gdEndpoint struct {
 	endpoint *endpoint.Endpoint
+	// previous holds the replaced endpoint of a gdReplace change
+	previous *endpoint.Endpoint
 	action   int
 }
 
@@ -98,6 +102,8 @@ type gdRecords struct {
 	records []gdRecordField
 	changed bool
 	zone    string
+	// pending holds the records to add, sent in batches once the other changes of the zone are applied
+	pending []gdRecordField
 }
 
 type gdZone struct {
@@ -358,7 +364,7 @@ func (p *GDProvider) changeAllRecords(endpoints []gdEndpoint, zoneRecords []*gdR
 
 			e.endpoint.RecordTTL = endpoint.TTL(maxOf(gdMinimalTTL, int64(e.endpoint.RecordTTL)))
 
-			if err := zoneRecord.applyEndpoint(e.action, p.client, *e.endpoint, dnsName, p.DryRun); err != nil {
+			if err := zoneRecord.applyEndpoint(p.client, e, dnsName, p.DryRun); err != nil {
 				log.Errorf("Unable to apply change %s on record %s type %s, %v", actionNames[e.action], dnsName, e.endpoint.RecordType, err)
 
 				return err
@@ -366,6 +372,12 @@ func (p *GDProvider) changeAllRecords(endpoints []gdEndpoint, zoneRecords []*gdR
 		}
 	}
 
+	for _, zoneRecord := range zoneRecords {
+		if err := zoneRecord.addPendingRecords(p.client, p.DryRun); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -396,8 +408,11 @@ func (p *GDProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) er
 	for iOld, recOld := range changes.UpdateOld {
 		for iNew, recNew := range changes.UpdateNew {
 			if recOld.DNSName == recNew.DNSName && recOld.RecordType == recNew.RecordType {
-				ReplaceEndpoints := []*endpoint.Endpoint{recNew}
-				allChanges = p.appendChange(gdReplace, ReplaceEndpoints, allChanges)
+				allChanges = append(allChanges, gdEndpoint{
+					action:   gdReplace,
+					endpoint: recNew,
+					previous: recOld,
+				})
 				iOldSkip[iOld] = true
 				iNewSkip[iNew] = true
 				break
@@ -440,24 +455,68 @@ func (p *GDProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) er
 	return nil
 }
 
-func (p *gdRecords) addRecord(client gdClient, endpoint endpoint.Endpoint, dnsName string, dryRun bool) error {
-	var response GDErrorResponse
-	for _, target := range endpoint.Targets {
-		change := gdRecordField{
-			Type: endpoint.RecordType,
-			Name: dnsName,
-			TTL:  int64(endpoint.RecordTTL),
-			Data: target,
+func newRecordField(endpoint endpoint.Endpoint, dnsName, target string) gdRecordField {
+	return gdRecordField{
+		Type: endpoint.RecordType,
+		Name: dnsName,
+		TTL:  int64(endpoint.RecordTTL),
+		Data: target,
+	}
+}
+
+func (c gdRecordField) toReplace() gdReplaceRecordField {
+	return gdReplaceRecordField{
+		Data:     c.Data,
+		TTL:      c.TTL,
+		Port:     c.Port,
+		Priority: c.Priority,
+		Weight:   c.Weight,
+		Protocol: c.Protocol,
+		Service:  c.Service,
+	}
+}
+
+func targetSet(endpoint *endpoint.Endpoint) map[string]bool {
+	targets := map[string]bool{}
+	if endpoint != nil {
+		for _, target := range endpoint.Targets {
+			targets[target] = true
 		}
+	}
+
+	return targets
+}
+
+// Queue the records of the targets, added by addPendingRecords
+func (p *gdRecords) addRecord(endpoint endpoint.Endpoint, dnsName string) {
+	for _, target := range endpoint.Targets {
+		change := newRecordField(endpoint, dnsName, target)
 
 		p.records = append(p.records, change)
+		p.pending = append(p.pending, change)
 		p.changed = true
 
 		log.Debugf("GoDaddy: Add an entry %s to zone %s", change.String(), p.zone)
+	}
+}
+
+// Add the queued records with as few PATCH requests as possible, the API adding them to the existing
+// records of the zone
+func (p *gdRecords) addPendingRecords(client gdClient, dryRun bool) error {
+	for len(p.pending) > 0 {
+		batch := p.pending[:min(len(p.pending), gdMaxBatchSize)]
+		p.pending = p.pending[len(batch):]
+
 		if dryRun {
-			log.Infof("[DryRun] - Add record %s.%s of type %s %s", change.Name, p.zone, change.Type, toString(change))
-		} else if err := client.Patch(fmt.Sprintf("/v1/domains/%s/records", p.zone), []gdRecordField{change}, &response); err != nil {
-			log.Errorf("Add record %s.%s of type %s failed: %s", change.Name, p.zone, change.Type, response)
+			log.Infof("[DryRun] - Add %d records to zone %s %s", len(batch), p.zone, toString(batch))
+
+			continue
+		}
+
+		var response GDErrorResponse
+		log.Debugf("Add %d records to zone %s", len(batch), p.zone)
+		if err := client.Patch(fmt.Sprintf("/v1/domains/%s/records", p.zone), batch, &response); err != nil {
+			log.Errorf("Add %d records to zone %s failed: %s", len(batch), p.zone, response)
 
 			return err
 		}
@@ -466,88 +525,100 @@ func (p *gdRecords) addRecord(client gdClient, endpoint endpoint.Endpoint, dnsNa
 	return nil
 }
 
-func (p *gdRecords) replaceRecord(client gdClient, endpoint endpoint.Endpoint, dnsName string, dryRun bool) error {
-	changed := []gdReplaceRecordField{}
-	records := []string{}
-
-	for _, target := range endpoint.Targets {
-		change := gdRecordField{
-			Type: endpoint.RecordType,
-			Name: dnsName,
-			TTL:  int64(endpoint.RecordTTL),
-			Data: target,
+// Update the records of the previous endpoint to the ones of the new endpoint. The records of the same
+// name and type not in the previous endpoint, e.g. created manually, are kept: when the update only
+// adds targets they are added like new records, otherwise the whole set is replaced with them and the
+// new targets.
+func (p *gdRecords) replaceRecord(client gdClient, previous *endpoint.Endpoint, endpoint endpoint.Endpoint, dnsName string, dryRun bool) error {
+	oldTargets := targetSet(previous)
+	newTargets := targetSet(&endpoint)
+
+	removed := previous == nil || previous.RecordTTL != endpoint.RecordTTL
+	for target := range oldTargets {
+		if !newTargets[target] {
+			removed = true
 		}
+	}
 
-		for index, record := range p.records {
-			if record.Type == change.Type && record.Name == change.Name {
-				p.records[index] = change
-				p.changed = true
+	if !removed {
+		added := endpoint
+		added.Targets = nil
+		for _, target := range endpoint.Targets {
+			if !oldTargets[target] {
+				added.Targets = append(added.Targets, target)
 			}
 		}
-		records = append(records, target)
-		changed = append(changed, gdReplaceRecordField{
-			Data:     change.Data,
-			TTL:      change.TTL,
-			Port:     change.Port,
-			Priority: change.Priority,
-			Weight:   change.Weight,
-			Protocol: change.Protocol,
-			Service:  change.Service,
-		})
-	}
+		p.addRecord(added, dnsName)
 
-	var response GDErrorResponse
+		return nil
+	}
 
-	if dryRun {
-		log.Infof("[DryRun] - Replace record %s.%s of type %s %s", dnsName, p.zone, endpoint.RecordType, records)
+	kept := make([]gdRecordField, 0, len(p.records))
+	changed := []gdReplaceRecordField{}
+	records := []string{}
 
-		return nil
+	for _, record := range p.records {
+		if record.Type == endpoint.RecordType && record.Name == dnsName {
+			if previous == nil || oldTargets[record.Data] || newTargets[record.Data] {
+				continue
+			}
+			changed = append(changed, record.toReplace())
+			records = append(records, record.Data)
+		}
+		kept = append(kept, record)
 	}
 
-	log.Debugf("Replace record %s.%s of type %s %s", dnsName, p.zone, endpoint.RecordType, records)
-	if err := client.Put(fmt.Sprintf("/v1/domains/%s/records/%s/%s", p.zone, endpoint.RecordType, dnsName), changed, &response); err != nil {
-		log.Errorf("Replace record %s.%s of type %s failed: %v", dnsName, p.zone, endpoint.RecordType, response)
+	for _, target := range endpoint.Targets {
+		change := newRecordField(endpoint, dnsName, target)
 
-		return err
+		kept = append(kept, change)
+		records = append(records, target)
+		changed = append(changed, change.toReplace())
 	}
 
-	return nil
+	p.records = kept
+	p.changed = true
+
+	return p.putRecords(client, endpoint.RecordType, dnsName, changed, records, dryRun)
 }
 
-// Remove one record from the record list
+// Remove the records of the targets, keeping the other records of the same name and type
 func (p *gdRecords) deleteRecord(client gdClient, endpoint endpoint.Endpoint, dnsName string, dryRun bool) error {
+	targets := targetSet(&endpoint)
+	kept := make([]gdRecordField, 0, len(p.records))
+	remaining := []gdReplaceRecordField{}
 	records := []string{}
+	deleted := 0
 
-	for _, target := range endpoint.Targets {
-		change := gdRecordField{
-			Type: endpoint.RecordType,
-			Name: dnsName,
-			TTL:  int64(endpoint.RecordTTL),
-			Data: target,
-		}
-		records = append(records, target)
-
-		log.Debugf("GoDaddy: Delete an entry %s from zone %s", change.String(), p.zone)
+	for _, record := range p.records {
+		if record.Type == endpoint.RecordType && record.Name == dnsName {
+			if targets[record.Data] {
+				log.Debugf("GoDaddy: Delete an entry %s from zone %s", record.String(), p.zone)
+				deleted++
 
-		deleteIndex := -1
-
-		for index, record := range p.records {
-			if record.Type == change.Type && record.Name == change.Name && record.Data == change.Data {
-				deleteIndex = index
-				break
+				continue
 			}
+			remaining = append(remaining, record.toReplace())
+			records = append(records, record.Data)
 		}
+		kept = append(kept, record)
+	}
 
-		if deleteIndex >= 0 {
-			p.records[deleteIndex] = p.records[len(p.records)-1]
+	if deleted == 0 && len(remaining) > 0 {
+		log.Debugf("GoDaddy: No record %s.%s of type %s to delete", dnsName, p.zone, endpoint.RecordType)
 
-			p.records = p.records[:len(p.records)-1]
-			p.changed = true
-		}
+		return nil
+	}
+
+	p.records = kept
+	p.changed = true
+
+	if len(remaining) > 0 {
+		return p.putRecords(client, endpoint.RecordType, dnsName, remaining, records, dryRun)
 	}
 
 	if dryRun {
-		log.Infof("[DryRun] - Delete record %s.%s of type %s %s", dnsName, p.zone, endpoint.RecordType, records)
+		log.Infof("[DryRun] - Delete record %s.%s of type %s %s", dnsName, p.zone, endpoint.RecordType, endpoint.Targets)
 
 		return nil
 	}
@@ -562,14 +633,33 @@ func (p *gdRecords) deleteRecord(client gdClient, endpoint endpoint.Endpoint, dn
 	return nil
 }
 
-func (p *gdRecords) applyEndpoint(action int, client gdClient, endpoint endpoint.Endpoint, dnsName string, dryRun bool) error {
-	switch action {
+// Replace all the records of the name and type
+func (p *gdRecords) putRecords(client gdClient, recordType, dnsName string, changed []gdReplaceRecordField, records []string, dryRun bool) error {
+	if dryRun {
+		log.Infof("[DryRun] - Replace record %s.%s of type %s %s", dnsName, p.zone, recordType, records)
+
+		return nil
+	}
+
+	var response GDErrorResponse
+	log.Debugf("Replace record %s.%s of type %s %s", dnsName, p.zone, recordType, records)
+	if err := client.Put(fmt.Sprintf("/v1/domains/%s/records/%s/%s", p.zone, recordType, dnsName), changed, &response); err != nil {
+		log.Errorf("Replace record %s.%s of type %s failed: %v", dnsName, p.zone, recordType, response)
+
+		return err
+	}
+
+	return nil
+}
+
+func (p *gdRecords) applyEndpoint(client gdClient, e gdEndpoint, dnsName string, dryRun bool) error {
+	switch e.action {
 	case gdCreate:
-		return p.addRecord(client, endpoint, dnsName, dryRun)
+		p.addRecord(*e.endpoint, dnsName)
 	case gdReplace:
-		return p.replaceRecord(client, endpoint, dnsName, dryRun)
+		return p.replaceRecord(client, e.previous, *e.endpoint, dnsName, dryRun)
 	case gdDelete:
-		return p.deleteRecord(client, endpoint, dnsName, dryRun)
+		return p.deleteRecord(client, *e.endpoint, dnsName, dryRun)
 	}
 
 	return nil
@@ -20,6 +20,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"sort"
 	"testing"
 
@@ -446,3 +447,92 @@ func TestGoDaddyErrorResponse(t *testing.T) {
 
 	client.AssertExpectations(t)
 }
+
+func TestGoDaddyDeltaChanges(t *testing.T) {
+	assert := assert.New(t)
+	client := newMockGoDaddyClient(t)
+	provider := &GDProvider{
+		client: client,
+	}
+
+	changes := plan.Changes{
+		Create: []*endpoint.Endpoint{
+			endpoint.NewEndpointWithTTL("www.example.net", "CNAME", gdMinimalTTL, "example.net"),
+		},
+		UpdateOld: []*endpoint.Endpoint{
+			endpoint.NewEndpointWithTTL("godaddy.example.net", "A", gdMinimalTTL, "203.0.113.42"),
+			endpoint.NewEndpointWithTTL("api.example.net", "A", gdMinimalTTL, "203.0.113.50", "203.0.113.51"),
+		},
+		UpdateNew: []*endpoint.Endpoint{
+			endpoint.NewEndpointWithTTL("godaddy.example.net", "A", gdMinimalTTL, "203.0.113.42", "203.0.113.43"),
+			endpoint.NewEndpointWithTTL("api.example.net", "A", gdMinimalTTL, "203.0.113.50", "203.0.113.52"),
+		},
+		Delete: []*endpoint.Endpoint{
+			endpoint.NewEndpointWithTTL("mail.example.net", "A", gdMinimalTTL, "203.0.113.60"),
+		},
+	}
+
+	client.On("Get", domainsURI).Return([]gdZone{
+		{
+			Domain: zoneNameExampleNet,
+		},
+	}, nil).Once()
+
+	client.On("Get", "/v1/domains/example.net/records").Return([]gdRecordField{
+		{Name: "godaddy", Type: "A", TTL: gdMinimalTTL, Data: "203.0.113.42"},
+		{Name: "api", Type: "A", TTL: gdMinimalTTL, Data: "203.0.113.50"},
+		{Name: "api", Type: "A", TTL: gdMinimalTTL, Data: "203.0.113.51"},
+		{Name: "mail", Type: "A", TTL: gdMinimalTTL, Data: "203.0.113.60"},
+		{Name: "mail", Type: "A", TTL: 3600, Data: "203.0.113.61"},
+	}, nil).Once()
+
+	// The other record of the name is kept
+	client.On("Put", "/v1/domains/example.net/records/A/mail", []gdReplaceRecordField{
+		{Data: "203.0.113.61", TTL: 3600},
+	}).Return(nil, nil).Once()
+
+	// A removed target replaces the whole set
+	client.On("Put", "/v1/domains/example.net/records/A/api", []gdReplaceRecordField{
+		{Data: "203.0.113.50", TTL: gdMinimalTTL},
+		{Data: "203.0.113.52", TTL: gdMinimalTTL},
+	}).Return(nil, nil).Once()
+
+	// The added target and the created record are sent together
+	client.On("Patch", "/v1/domains/example.net/records", []gdRecordField{
+		{Name: "godaddy", Type: "A", TTL: gdMinimalTTL, Data: "203.0.113.43"},
+		{Name: "www", Type: "CNAME", TTL: gdMinimalTTL, Data: "example.net"},
+	}).Return(nil, nil).Once()
+
+	assert.NoError(provider.ApplyChanges(context.TODO(), &changes))
+
+	client.AssertExpectations(t)
+}
+
+func TestGoDaddyBatchedCreate(t *testing.T) {
+	assert := assert.New(t)
+	client := newMockGoDaddyClient(t)
+	provider := &GDProvider{
+		client: client,
+	}
+
+	changes := plan.Changes{}
+	expected := []gdRecordField{}
+	for i := 0; i < gdMaxBatchSize+1; i++ {
+		name := fmt.Sprintf("host-%d", i)
+		changes.Create = append(changes.Create, endpoint.NewEndpointWithTTL(name+".example.net", "A", gdMinimalTTL, "203.0.113.42"))
+		expected = append(expected, gdRecordField{Name: name, Type: "A", TTL: gdMinimalTTL, Data: "203.0.113.42"})
+	}
+
+	client.On("Get", domainsURI).Return([]gdZone{
+		{
+			Domain: zoneNameExampleNet,
+		},
+	}, nil).Once()
+	client.On("Get", "/v1/domains/example.net/records").Return([]gdRecordField{}, nil).Once()
+	client.On("Patch", "/v1/domains/example.net/records", expected[:gdMaxBatchSize]).Return(nil, nil).Once()
+	client.On("Patch", "/v1/domains/example.net/records", expected[gdMaxBatchSize:]).Return(nil, nil).Once()
+
+	assert.NoError(provider.ApplyChanges(context.TODO(), &changes))
+
+	client.AssertExpectations(t)
+}
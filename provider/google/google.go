@@ -19,6 +19,7 @@ package google
 import (
 	"context"
 	"fmt"
+	"os"
 	"sort"
 	"strings"
 	"time"
@@ -26,6 +27,7 @@ import (
 	"cloud.google.com/go/compute/metadata"
 	"github.com/linki/instrumented_http"
 	log "github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 	dns "google.golang.org/api/dns/v1"
 	googleapi "google.golang.org/api/googleapi"
@@ -125,11 +127,12 @@ type GoogleProvider struct {
 }
 
 // NewGoogleProvider initializes a new Google CloudDNS based Provider.
-func NewGoogleProvider(ctx context.Context, project string, domainFilter endpoint.DomainFilter, zoneIDFilter provider.ZoneIDFilter, batchChangeSize int, batchChangeInterval time.Duration, zoneVisibility string, dryRun bool) (*GoogleProvider, error) {
-	gcloud, err := google.DefaultClient(ctx, dns.NdevClouddnsReadwriteScope)
+func NewGoogleProvider(ctx context.Context, project string, credentialsFile string, domainFilter endpoint.DomainFilter, zoneIDFilter provider.ZoneIDFilter, batchChangeSize int, batchChangeInterval time.Duration, zoneVisibility string, dryRun bool) (*GoogleProvider, error) {
+	creds, err := findCredentials(ctx, credentialsFile)
 	if err != nil {
 		return nil, err
 	}
+	gcloud := oauth2.NewClient(ctx, creds.TokenSource)
 
 	gcloud = instrumented_http.NewClient(gcloud, &instrumented_http.Callbacks{
 		PathProcessor: func(path string) string {
@@ -143,6 +146,11 @@ func NewGoogleProvider(ctx context.Context, project string, domainFilter endpoin
 		return nil, err
 	}
 
+	if project == "" && creds.ProjectID != "" {
+		log.Infof("Google project taken from credentials: %s", creds.ProjectID)
+		project = creds.ProjectID
+	}
+
 	if project == "" {
 		mProject, mErr := metadata.ProjectID()
 		if mErr != nil {
@@ -171,6 +179,39 @@ func NewGoogleProvider(ctx context.Context, project string, domainFilter endpoin
 	return provider, nil
 }
 
+// findCredentials loads the credentials from the given file or, if no file is given, from the
+// application default credentials. Besides service account keys this accepts workload identity
+// federation configurations ("external_account"), which let clusters outside of GCP exchange
+// their own OIDC tokens for Google credentials.
+func findCredentials(ctx context.Context, credentialsFile string) (*google.Credentials, error) {
+	if credentialsFile == "" {
+		return google.FindDefaultCredentials(ctx, dns.NdevClouddnsReadwriteScope)
+	}
+
+	data, err := os.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Google credentials file %s: %w", credentialsFile, err)
+	}
+	creds, err := google.CredentialsFromJSON(ctx, data, dns.NdevClouddnsReadwriteScope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load Google credentials from %s: %w", credentialsFile, err)
+	}
+	return creds, nil
+}
+
+// ZoneNames returns the names of the managed zones.
+func (p *GoogleProvider) ZoneNames(ctx context.Context) ([]string, error) {
+	zones, err := p.Zones(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(zones))
+	for _, z := range zones {
+		names = append(names, z.DnsName)
+	}
+	return names, nil
+}
+
 // Zones returns the list of hosted zones.
 func (p *GoogleProvider) Zones(ctx context.Context) (map[string]*dns.ManagedZone, error) {
 	zones := make(map[string]*dns.ManagedZone)
@@ -19,6 +19,8 @@ package google
 import (
 	"fmt"
 	"net/http"
+	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 	"testing"
@@ -808,3 +810,33 @@ func clearGoogleRecords(t *testing.T, provider *GoogleProvider, zone string) {
 func validateEndpoints(t *testing.T, endpoints []*endpoint.Endpoint, expected []*endpoint.Endpoint) {
 	assert.True(t, testutils.SameEndpoints(endpoints, expected), "actual and expected endpoints don't match. %s:%s", endpoints, expected)
 }
+
+func TestFindCredentialsExternalAccount(t *testing.T) {
+	dir := t.TempDir()
+	credentialsFile := filepath.Join(dir, "credentials.json")
+	config := fmt.Sprintf(`{
+  "type": "external_account",
+  "audience": "//iam.googleapis.com/projects/123/locations/global/workloadIdentityPools/pool/providers/provider",
+  "subject_token_type": "urn:ietf:params:oauth:token-type:jwt",
+  "token_url": "https://sts.googleapis.com/v1/token",
+  "credential_source": {"file": %q}
+}`, filepath.Join(dir, "token"))
+	require.NoError(t, os.WriteFile(credentialsFile, []byte(config), 0o600))
+
+	creds, err := findCredentials(context.Background(), credentialsFile)
+	require.NoError(t, err)
+	assert.NotNil(t, creds.TokenSource)
+	assert.Empty(t, creds.ProjectID)
+}
+
+func TestFindCredentialsErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := findCredentials(context.Background(), filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+
+	invalid := filepath.Join(dir, "invalid.json")
+	require.NoError(t, os.WriteFile(invalid, []byte(`{"type": "unknown"}`), 0o600))
+	_, err = findCredentials(context.Background(), invalid)
+	assert.Error(t, err)
+}
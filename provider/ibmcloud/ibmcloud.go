@@ -401,6 +401,17 @@ func (p *IBMCloudProvider) AdjustEndpoints(endpoints []*endpoint.Endpoint) ([]*e
 	return adjustedEndpoints, nil
 }
 
+// propertySchema normalizes the provider specific properties whose values from annotations may
+// differ textually from the ones read from IBM Cloud, e.g. proxied "1" is read as "true".
+var propertySchema = provider.PropertySchema{
+	proxyFilter: provider.BoolProperty,
+}
+
+// PropertyValuesEqual compares the values of provider specific properties semantically.
+func (p *IBMCloudProvider) PropertyValuesEqual(name string, previous string, current string) bool {
+	return propertySchema.PropertyValuesEqual(name, previous, current)
+}
+
 // submitChanges takes a zone and a collection of Changes and sends them as a single transaction.
 func (p *IBMCloudProvider) submitChanges(ctx context.Context, changes []*ibmcloudChange) error {
 	// return early if there is nothing to change
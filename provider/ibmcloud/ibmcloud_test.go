@@ -949,3 +949,30 @@ func (_m *mockSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, erro
 func (_m *mockSource) AddEventHandler(_a0 context.Context, _a1 func()) {
 	_m.Called(_a0, _a1)
 }
+
+func TestPropertyValuesEqual(t *testing.T) {
+	p := &IBMCloudProvider{}
+	current := endpoint.NewEndpoint("test.example.com", endpoint.RecordTypeA, "1.2.3.4").WithProviderSpecific(proxyFilter, "false")
+
+	for _, tc := range []struct {
+		proxied      string
+		shouldUpdate bool
+	}{
+		{"false", false},
+		{"0", false},
+		{"F", false},
+		{"true", true},
+	} {
+		t.Run(tc.proxied, func(t *testing.T) {
+			desired := endpoint.NewEndpoint("test.example.com", endpoint.RecordTypeA, "1.2.3.4").WithProviderSpecific(proxyFilter, tc.proxied)
+			changes := (&plan.Plan{
+				Policies:           []plan.Policy{&plan.SyncPolicy{}},
+				Current:            []*endpoint.Endpoint{current},
+				Desired:            []*endpoint.Endpoint{desired},
+				ManagedRecords:     []string{endpoint.RecordTypeA},
+				PropertyComparator: p.PropertyValuesEqual,
+			}).Calculate().Changes
+			assert.Equal(t, tc.shouldUpdate, len(changes.UpdateNew) == 1)
+		})
+	}
+}
@@ -62,6 +62,15 @@ type StartupConfig struct {
 	NameRegEx     string
 	CreatePTR     bool
 	CacheDuration int
+	// DomainViews are the DNS views of the zones of domains, instead of View
+	DomainViews map[string]string
+	// DomainNetworkViews are the network views of the host records of domains, the zones of a
+	// domain without a DNS view are in the default DNS view of its network view
+	DomainNetworkViews map[string]string
+	// OwnerEA and ResourceEA are the extensible attributes storing the owner and the resource of
+	// the records, used when OwnerEA is set, e.g. by the infoblox-ea registry
+	OwnerEA    string
+	ResourceEA string
 }
 
 // ProviderConfig implements the DNS provider for Infoblox.
@@ -75,6 +84,11 @@ type ProviderConfig struct {
 	fqdnRegEx     string
 	createPTR     bool
 	cacheDuration int
+	// the DNS and network views by domain
+	domainViews        map[string]string
+	domainNetworkViews map[string]string
+	ownerEA            string
+	resourceEA         string
 }
 
 type infobloxRecordSet struct {
@@ -178,23 +192,125 @@ func NewInfobloxProvider(ibStartupCfg StartupConfig) (*ProviderConfig, error) {
 		fqdnRegEx:     ibStartupCfg.FQDNRegEx,
 		createPTR:     ibStartupCfg.CreatePTR,
 		cacheDuration: ibStartupCfg.CacheDuration,
+
+		domainViews:        ibStartupCfg.DomainViews,
+		domainNetworkViews: ibStartupCfg.DomainNetworkViews,
+		ownerEA:            ibStartupCfg.OwnerEA,
+		resourceEA:         ibStartupCfg.ResourceEA,
 	}
 
 	return providerCfg, nil
 }
 
 func recordQueryParams(zone string, view string) *ibclient.QueryParams {
+	return ibclient.NewQueryParams(false, searchFields(zone, view))
+}
+
+func searchFields(zone string, view string) map[string]string {
 	searchFields := map[string]string{}
 	if zone != "" {
 		searchFields["zone"] = zone
 	}
 
+	if view != "" {
+		searchFields["view"] = view
+	}
+	return searchFields
+}
+
+// nameQueryParams returns the parameters searching the records of a name in a view.
+func nameQueryParams(name string, view string) *ibclient.QueryParams {
+	searchFields := map[string]string{"name": name}
 	if view != "" {
 		searchFields["view"] = view
 	}
 	return ibclient.NewQueryParams(false, searchFields)
 }
 
+// mostSpecificDomain returns the value of the longest domain of the map matching the name.
+func mostSpecificDomain(domains map[string]string, name string) (string, bool) {
+	var match, value string
+	for domain, v := range domains {
+		domain = strings.TrimSuffix(strings.ToLower(domain), ".")
+		n := strings.TrimSuffix(strings.ToLower(name), ".")
+		if (n == domain || strings.HasSuffix(n, "."+domain)) && len(domain) >= len(match) {
+			match, value = domain, v
+		}
+	}
+	return value, match != ""
+}
+
+// defaultDNSView returns the DNS view Infoblox creates for a network view.
+func defaultDNSView(networkView string) string {
+	if networkView == "default" {
+		return "default"
+	}
+	return "default." + networkView
+}
+
+// viewFor returns the DNS view of the records of a name.
+func (p *ProviderConfig) viewFor(name string) string {
+	if view, ok := mostSpecificDomain(p.domainViews, name); ok {
+		return view
+	}
+	if networkView, ok := mostSpecificDomain(p.domainNetworkViews, name); ok {
+		return defaultDNSView(networkView)
+	}
+	return p.view
+}
+
+// views returns the DNS views the zones are read from, all views if no view is configured.
+func (p *ProviderConfig) views() []string {
+	if p.view == "" {
+		return []string{""}
+	}
+	set := map[string]struct{}{p.view: {}}
+	for _, view := range p.domainViews {
+		set[view] = struct{}{}
+	}
+	for _, networkView := range p.domainNetworkViews {
+		set[defaultDNSView(networkView)] = struct{}{}
+	}
+	views := make([]string, 0, len(set))
+	for view := range set {
+		views = append(views, view)
+	}
+	sort.Strings(views)
+	return views
+}
+
+// labels sets the owner and the resource of an endpoint from the extensible attributes of its record.
+func (p *ProviderConfig) labels(ep *endpoint.Endpoint, ea ibclient.EA) *endpoint.Endpoint {
+	if p.ownerEA == "" {
+		return ep
+	}
+	if owner, ok := ea[p.ownerEA].(string); ok {
+		ep.Labels[endpoint.OwnerLabelKey] = owner
+	}
+	if resource, ok := ea[p.resourceEA].(string); ok && p.resourceEA != "" {
+		ep.Labels[endpoint.ResourceLabelKey] = resource
+	}
+	return ep
+}
+
+// extAttrs returns the extensible attributes storing the owner and the resource of an endpoint.
+func (p *ProviderConfig) extAttrs(ep *endpoint.Endpoint) ibclient.EA {
+	if p.ownerEA == "" {
+		return nil
+	}
+	ea := ibclient.EA{}
+	if owner := ep.Labels[endpoint.OwnerLabelKey]; owner != "" {
+		ea[p.ownerEA] = owner
+	}
+	if resource := ep.Labels[endpoint.ResourceLabelKey]; resource != "" && p.resourceEA != "" {
+		ea[p.resourceEA] = resource
+	}
+	if len(ea) == 0 {
+		return nil
+	}
+	return ea
+}
+
 // Records gets the current records.
 func (p *ProviderConfig) Records(ctx context.Context) (endpoints []*endpoint.Endpoint, err error) {
 	zones, err := p.zones()
@@ -204,7 +320,11 @@ func (p *ProviderConfig) Records(ctx context.Context) (endpoints []*endpoint.End
 
 	for _, zone := range zones {
 		logrus.Debugf("fetch records from zone '%s'", zone.Fqdn)
-		searchParams := recordQueryParams(zone.Fqdn, p.view)
+		view := p.view
+		if zone.View != nil {
+			view = *zone.View
+		}
+		searchParams := recordQueryParams(zone.Fqdn, view)
 		var resA []ibclient.RecordA
 		objA := ibclient.NewEmptyRecordA()
 		err = p.client.GetObject(objA, "", searchParams, &resA)
@@ -236,7 +356,7 @@ func (p *ProviderConfig) Records(ctx context.Context) (endpoints []*endpoint.End
 				}
 			}
 			if !foundExisting {
-				newEndpoint := endpoint.NewEndpoint(*res.Name, endpoint.RecordTypeA, *res.Ipv4Addr)
+				newEndpoint := p.labels(endpoint.NewEndpoint(*res.Name, endpoint.RecordTypeA, *res.Ipv4Addr), res.Ea)
 				if p.createPTR {
 					newEndpoint.WithProviderSpecific(providerSpecificInfobloxPtrRecord, "true")
 				}
@@ -251,7 +371,13 @@ func (p *ProviderConfig) Records(ctx context.Context) (endpoints []*endpoint.End
 		// Include Host records since they should be treated synonymously with A records
 		var resH []ibclient.HostRecord
 		objH := ibclient.NewEmptyHostRecord()
-		err = p.client.GetObject(objH, "", searchParams, &resH)
+		hostSearchParams := searchParams
+		if networkView, ok := mostSpecificDomain(p.domainNetworkViews, zone.Fqdn); ok {
+			fields := searchFields(zone.Fqdn, view)
+			fields["network_view"] = networkView
+			hostSearchParams = ibclient.NewQueryParams(false, fields)
+		}
+		err = p.client.GetObject(objH, "", hostSearchParams, &resH)
 		if err != nil && !isNotFoundError(err) {
 			return nil, fmt.Errorf("could not fetch host records from zone '%s': %w", zone.Fqdn, err)
 		}
@@ -261,7 +387,7 @@ func (p *ProviderConfig) Records(ctx context.Context) (endpoints []*endpoint.End
 
 				// host record is an abstraction in infoblox that combines A and PTR records
 				// for any host record we already should have a PTR record in infoblox, so mark it as created
-				newEndpoint := endpoint.NewEndpoint(*res.Name, endpoint.RecordTypeA, *ip.Ipv4Addr)
+				newEndpoint := p.labels(endpoint.NewEndpoint(*res.Name, endpoint.RecordTypeA, *ip.Ipv4Addr), res.Ea)
 				if p.createPTR {
 					newEndpoint.WithProviderSpecific(providerSpecificInfobloxPtrRecord, "true")
 				}
@@ -277,7 +403,7 @@ func (p *ProviderConfig) Records(ctx context.Context) (endpoints []*endpoint.End
 		}
 		for _, res := range resC {
 			logrus.Debugf("Record='%s' CNAME:'%s'", *res.Name, *res.Canonical)
-			endpoints = append(endpoints, endpoint.NewEndpoint(*res.Name, endpoint.RecordTypeCNAME, *res.Canonical))
+			endpoints = append(endpoints, p.labels(endpoint.NewEndpoint(*res.Name, endpoint.RecordTypeCNAME, *res.Canonical), res.Ea))
 		}
 
 		if p.createPTR {
@@ -288,12 +414,12 @@ func (p *ProviderConfig) Records(ctx context.Context) (endpoints []*endpoint.End
 			if err == nil {
 				var resP []ibclient.RecordPTR
 				objP := ibclient.NewEmptyRecordPTR()
-				err = p.client.GetObject(objP, "", recordQueryParams(arpaZone, p.view), &resP)
+				err = p.client.GetObject(objP, "", recordQueryParams(arpaZone, view), &resP)
 				if err != nil && !isNotFoundError(err) {
 					return nil, fmt.Errorf("could not fetch PTR records from zone '%s': %w", zone.Fqdn, err)
 				}
 				for _, res := range resP {
-					endpoints = append(endpoints, endpoint.NewEndpoint(*res.PtrdName, endpoint.RecordTypePTR, *res.Ipv4Addr))
+					endpoints = append(endpoints, p.labels(endpoint.NewEndpoint(*res.PtrdName, endpoint.RecordTypePTR, *res.Ipv4Addr), res.Ea))
 				}
 			}
 		}
@@ -337,7 +463,7 @@ func (p *ProviderConfig) Records(ctx context.Context) (endpoints []*endpoint.End
 			}
 			if !foundExisting {
 				logrus.Debugf("Record='%s' TXT:'%s'", *res.Name, *res.Text)
-				newEndpoint := endpoint.NewEndpoint(*res.Name, endpoint.RecordTypeTXT, *res.Text)
+				newEndpoint := p.labels(endpoint.NewEndpoint(*res.Name, endpoint.RecordTypeTXT, *res.Text), res.Ea)
 				endpoints = append(endpoints, newEndpoint)
 			}
 		}
@@ -422,11 +548,15 @@ func (p *ProviderConfig) ApplyChanges(ctx context.Context, changes *plan.Changes
 
 func (p *ProviderConfig) zones() ([]ibclient.ZoneAuth, error) {
 	var res, result []ibclient.ZoneAuth
-	obj := ibclient.NewZoneAuth(ibclient.ZoneAuth{})
-	queryParams := recordQueryParams("", p.view)
-	err := p.client.GetObject(obj, "", queryParams, &res)
-	if err != nil && !isNotFoundError(err) {
-		return nil, err
+	for _, view := range p.views() {
+		var viewZones []ibclient.ZoneAuth
+		obj := ibclient.NewZoneAuth(ibclient.ZoneAuth{})
+		queryParams := recordQueryParams("", view)
+		err := p.client.GetObject(obj, "", queryParams, &viewZones)
+		if err != nil && !isNotFoundError(err) {
+			return nil, err
+		}
+		res = append(res, viewZones...)
 	}
 
 	for _, zone := range res {
@@ -434,6 +564,11 @@ func (p *ProviderConfig) zones() ([]ibclient.ZoneAuth, error) {
 			continue
 		}
 
+		// the zones of a domain are only managed in the view of the domain
+		if view := p.viewFor(zone.Fqdn); view != "" && zone.View != nil && *zone.View != view {
+			continue
+		}
+
 		if !p.zoneIDFilter.Match(zone.Ref) {
 			continue
 		}
@@ -529,15 +664,25 @@ func (p *ProviderConfig) findReverseZone(zones []ibclient.ZoneAuth, name string)
 }
 
 func (p *ProviderConfig) recordSet(ep *endpoint.Endpoint, getObject bool, targetIndex int) (recordSet infobloxRecordSet, err error) {
+	// the PTR records are in the reverse zones, which aren't in the views of the domains
+	view := p.view
+	if ep.RecordType != endpoint.RecordTypePTR {
+		view = p.viewFor(ep.DNSName)
+	}
+	var ea ibclient.EA
+	if !getObject {
+		ea = p.extAttrs(ep)
+	}
 	switch ep.RecordType {
 	case endpoint.RecordTypeA:
 		var res []ibclient.RecordA
 		obj := ibclient.NewEmptyRecordA()
 		obj.Name = &ep.DNSName
 		obj.Ipv4Addr = &ep.Targets[targetIndex]
-		obj.View = p.view
+		obj.View = view
+		obj.Ea = ea
 		if getObject {
-			queryParams := ibclient.NewQueryParams(false, map[string]string{"name": *obj.Name})
+			queryParams := nameQueryParams(*obj.Name, view)
 			err = p.client.GetObject(obj, "", queryParams, &res)
 			if err != nil && !isNotFoundError(err) {
 				return
@@ -552,9 +697,10 @@ func (p *ProviderConfig) recordSet(ep *endpoint.Endpoint, getObject bool, target
 		obj := ibclient.NewEmptyRecordPTR()
 		obj.PtrdName = &ep.DNSName
 		obj.Ipv4Addr = &ep.Targets[targetIndex]
-		obj.View = p.view
+		obj.View = view
+		obj.Ea = ea
 		if getObject {
-			queryParams := ibclient.NewQueryParams(false, map[string]string{"name": *obj.PtrdName})
+			queryParams := nameQueryParams(*obj.PtrdName, view)
 			err = p.client.GetObject(obj, "", queryParams, &res)
 			if err != nil && !isNotFoundError(err) {
 				return
@@ -569,9 +715,10 @@ func (p *ProviderConfig) recordSet(ep *endpoint.Endpoint, getObject bool, target
 		obj := ibclient.NewEmptyRecordCNAME()
 		obj.Name = &ep.DNSName
 		obj.Canonical = &ep.Targets[0]
-		obj.View = &p.view
+		obj.View = &view
+		obj.Ea = ea
 		if getObject {
-			queryParams := ibclient.NewQueryParams(false, map[string]string{"name": *obj.Name})
+			queryParams := nameQueryParams(*obj.Name, view)
 			err = p.client.GetObject(obj, "", queryParams, &res)
 			if err != nil && !isNotFoundError(err) {
 				return
@@ -591,9 +738,10 @@ func (p *ProviderConfig) recordSet(ep *endpoint.Endpoint, getObject bool, target
 		obj := ibclient.NewEmptyRecordTXT()
 		obj.Name = &ep.DNSName
 		obj.Text = &ep.Targets[0]
-		obj.View = &p.view
+		obj.View = &view
+		obj.Ea = ea
 		if getObject {
-			queryParams := ibclient.NewQueryParams(false, map[string]string{"name": *obj.Name})
+			queryParams := nameQueryParams(*obj.Name, view)
 			err = p.client.GetObject(obj, "", queryParams, &res)
 			if err != nil && !isNotFoundError(err) {
 				return
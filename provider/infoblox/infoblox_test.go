@@ -273,7 +273,14 @@ func (client *mockIBConnector) GetObject(obj ibclient.IBObject, ref string, quer
 		}
 		*res.(*[]ibclient.RecordPTR) = result
 	case "zone_auth":
-		*res.(*[]ibclient.ZoneAuth) = *client.mockInfobloxZones
+		var result []ibclient.ZoneAuth
+		for _, zone := range *client.mockInfobloxZones {
+			if view := req.url.Query().Get("view"); view != "" && zone.View != nil && *zone.View != view {
+				continue
+			}
+			result = append(result, zone)
+		}
+		*res.(*[]ibclient.ZoneAuth) = result
 	}
 	return
 }
@@ -575,6 +582,82 @@ func TestInfobloxRecordsWithView(t *testing.T) {
 	client.verifyNoMoreGetObjectRequests(t)
 }
 
+func TestInfobloxRecordsWithDomainViews(t *testing.T) {
+	inside, outside := "Inside", "default.nv1"
+	client := mockIBConnector{
+		mockInfobloxZones: &[]ibclient.ZoneAuth{
+			{Fqdn: "foo.example.com", View: &inside},
+			{Fqdn: "foo.example.com", View: &outside},
+			{Fqdn: "bar.example.com", View: &inside},
+			{Fqdn: "bar.example.com", View: &outside},
+		},
+		mockInfobloxObjects: &[]ibclient.IBObject{},
+	}
+
+	providerCfg := newInfobloxProvider(endpoint.NewDomainFilter([]string{"foo.example.com", "bar.example.com"}), provider.NewZoneIDFilter([]string{""}), "Inside", true, false, &client)
+	providerCfg.domainNetworkViews = map[string]string{"bar.example.com": "nv1"}
+	zones, err := providerCfg.zones()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []ibclient.ZoneAuth{{Fqdn: "foo.example.com", View: &inside}, {Fqdn: "bar.example.com", View: &outside}}, zones)
+
+	_, err = providerCfg.Records(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.verifyGetObjectRequest(t, "zone_auth", "", &map[string]string{"view": "Inside"})
+	client.verifyGetObjectRequest(t, "zone_auth", "", &map[string]string{"view": "default.nv1"})
+	client.verifyGetObjectRequest(t, "record:host", "", &map[string]string{"zone": "foo.example.com", "view": "Inside"})
+	client.verifyGetObjectRequest(t, "record:host", "", &map[string]string{"zone": "bar.example.com", "view": "default.nv1", "network_view": "nv1"})
+	client.verifyGetObjectRequest(t, "record:a", "", &map[string]string{"zone": "bar.example.com", "view": "default.nv1"})
+
+	// the DNS view of a domain overrides the default DNS view of its network view
+	providerCfg.domainViews = map[string]string{"example.com": "Outside", "bar.example.com": "Bar"}
+	assert.Equal(t, "Bar", providerCfg.viewFor("a.bar.example.com"))
+	assert.Equal(t, "Outside", providerCfg.viewFor("foo.example.com"))
+	assert.Equal(t, "Inside", providerCfg.viewFor("other.com"))
+	assert.Equal(t, []string{"Bar", "Inside", "Outside", "default.nv1"}, providerCfg.views())
+}
+
+func TestInfobloxExtensibleAttributes(t *testing.T) {
+	owned := createMockInfobloxObject("owned.example.com", endpoint.RecordTypeA, "1.2.3.4").(*ibclient.RecordA)
+	owned.Ea = ibclient.EA{"external-dns-owner": "default", "external-dns-resource": "service/default/web"}
+	client := mockIBConnector{
+		mockInfobloxZones: &[]ibclient.ZoneAuth{
+			createMockInfobloxZone("example.com"),
+		},
+		mockInfobloxObjects: &[]ibclient.IBObject{
+			owned,
+			createMockInfobloxObject("unowned.example.com", endpoint.RecordTypeA, "1.2.3.5"),
+		},
+	}
+
+	providerCfg := newInfobloxProvider(endpoint.NewDomainFilter([]string{"example.com"}), provider.NewZoneIDFilter([]string{""}), "", false, false, &client)
+	providerCfg.ownerEA = "external-dns-owner"
+	providerCfg.resourceEA = "external-dns-resource"
+	actual, err := providerCfg.Records(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []*endpoint.Endpoint{
+		endpoint.NewEndpoint("owned.example.com", endpoint.RecordTypeA, "1.2.3.4"),
+		endpoint.NewEndpoint("unowned.example.com", endpoint.RecordTypeA, "1.2.3.5"),
+	}
+	expected[0].Labels[endpoint.OwnerLabelKey] = "default"
+	expected[0].Labels[endpoint.ResourceLabelKey] = "service/default/web"
+	validateEndpoints(t, actual, expected)
+
+	created := endpoint.NewEndpoint("new.example.com", endpoint.RecordTypeCNAME, "other.com")
+	created.Labels[endpoint.OwnerLabelKey] = "default"
+	err = providerCfg.ApplyChanges(context.Background(), &plan.Changes{Create: []*endpoint.Endpoint{created}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	objects := *client.mockInfobloxObjects
+	assert.Equal(t, ibclient.EA{"external-dns-owner": "default"}, objects[len(objects)-1].(*ibclient.RecordCNAME).Ea)
+}
+
 func TestInfobloxAdjustEndpoints(t *testing.T) {
 	client := mockIBConnector{
 		mockInfobloxZones: &[]ibclient.ZoneAuth{
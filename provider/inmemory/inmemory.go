@@ -19,6 +19,7 @@ package inmemory
 import (
 	"context"
 	"errors"
+	"sort"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
@@ -51,6 +52,7 @@ type InMemoryProvider struct {
 	filter         *filter
 	OnApplyChanges func(ctx context.Context, changes *plan.Changes)
 	OnRecords      func()
+	pageSize       int
 }
 
 // InMemoryOption allows to extend in-memory provider
@@ -83,6 +85,13 @@ func InMemoryWithDomain(domainFilter endpoint.DomainFilter) InMemoryOption {
 	}
 }
 
+// InMemoryWithPageSize makes RecordsPage return pages of about the given number of records
+func InMemoryWithPageSize(pageSize int) InMemoryOption {
+	return func(p *InMemoryProvider) {
+		p.pageSize = pageSize
+	}
+}
+
 // InMemoryInitZones pre-seeds the InMemoryProvider with given zones
 func InMemoryInitZones(zones []string) InMemoryOption {
 	return func(p *InMemoryProvider) {
@@ -121,6 +130,16 @@ func (im *InMemoryProvider) Zones() map[string]string {
 	return im.filter.Zones(im.client.Zones())
 }
 
+// ZoneNames returns the names of the filtered zones
+func (im *InMemoryProvider) ZoneNames(ctx context.Context) ([]string, error) {
+	zones := im.Zones()
+	names := make([]string, 0, len(zones))
+	for _, name := range zones {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
 // Records returns the list of endpoints
 func (im *InMemoryProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
 	defer im.OnRecords()
@@ -139,6 +158,31 @@ func (im *InMemoryProvider) Records(ctx context.Context) ([]*endpoint.Endpoint,
 	return endpoints, nil
 }
 
+// RecordsPage returns the records in the order of their DNS names, a page holding the records of
+// the DNS names from the one of the token on until it has at least the page size of them. All the
+// records are a single page without a page size.
+func (im *InMemoryProvider) RecordsPage(ctx context.Context, pageToken string) ([]*endpoint.Endpoint, string, error) {
+	records, err := im.Records(ctx)
+	if err != nil || im.pageSize <= 0 {
+		return records, "", err
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return endpoint.CanonicalDNSName(records[i].DNSName) < endpoint.CanonicalDNSName(records[j].DNSName)
+	})
+	start := sort.Search(len(records), func(i int) bool {
+		return endpoint.CanonicalDNSName(records[i].DNSName) >= pageToken
+	})
+	end := start + im.pageSize
+	for end < len(records) && endpoint.CanonicalDNSName(records[end].DNSName) == endpoint.CanonicalDNSName(records[end-1].DNSName) {
+		end++
+	}
+	if end >= len(records) {
+		return records[start:], "", nil
+	}
+	return records[start:end], endpoint.CanonicalDNSName(records[end].DNSName), nil
+}
+
 // ApplyChanges simply modifies records in memory
 // error checking occurs before any modifications are made, i.e. batch processing
 // create record - record should not exist
@@ -36,6 +36,7 @@ func TestInMemoryProvider(t *testing.T) {
 	t.Run("ApplyChanges", testInMemoryApplyChanges)
 	t.Run("NewInMemoryProvider", testNewInMemoryProvider)
 	t.Run("CreateZone", testInMemoryCreateZone)
+	t.Run("RecordsPage", testInMemoryRecordsPage)
 }
 
 func testInMemoryRecords(t *testing.T) {
@@ -573,6 +574,34 @@ func testInMemoryCreateZone(t *testing.T) {
 	assert.EqualError(t, err, ErrZoneAlreadyExists.Error())
 }
 
+func testInMemoryRecordsPage(t *testing.T) {
+	im := NewInMemoryProvider(InMemoryInitZones([]string{"org"}), InMemoryWithPageSize(2))
+	require.NoError(t, im.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("c.org", endpoint.RecordTypeA, "3.3.3.3"),
+			endpoint.NewEndpoint("a.org", endpoint.RecordTypeA, "1.1.1.1"),
+			endpoint.NewEndpoint("b.org", endpoint.RecordTypeA, "2.2.2.2"),
+			endpoint.NewEndpoint("b.org", endpoint.RecordTypeTXT, "text"),
+		},
+	}))
+
+	// the records of a DNS name are kept on the same page
+	records, next, err := im.RecordsPage(context.Background(), "")
+	require.NoError(t, err)
+	assert.Len(t, records, 3)
+	assert.Equal(t, "c.org", next)
+
+	records, next, err = im.RecordsPage(context.Background(), next)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "c.org", records[0].DNSName)
+	assert.Empty(t, next)
+
+	all, err := provider.Paginate(context.Background(), provider.RetryConfig{}, im.RecordsPage)
+	require.NoError(t, err)
+	assert.Len(t, all, 4)
+}
+
 func makeZone(s ...string) map[endpoint.EndpointKey]*endpoint.Endpoint {
 	if len(s)%3 != 0 {
 		panic("makeZone arguments must be multiple of 3")
@@ -18,6 +18,7 @@ package linode
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -33,6 +34,24 @@ import (
 	"sigs.k8s.io/external-dns/provider"
 )
 
+// linodePageSize is the maximum page size of the Linode API.
+const linodePageSize = 500
+
+// retryConfig retries the requests that were rate limited, failed on the side of the API or didn't get a
+// response at all. linodego reports the latter with codes below 100.
+var retryConfig = provider.RetryConfig{
+	MaxRetries:      provider.DefaultRetryConfig.MaxRetries,
+	InitialInterval: provider.DefaultRetryConfig.InitialInterval,
+	MaxInterval:     provider.DefaultRetryConfig.MaxInterval,
+	Retryable: func(err error) bool {
+		var apiErr *linodego.Error
+		if errors.As(err, &apiErr) {
+			return apiErr.Code < 100 || provider.RetryableStatus(apiErr.Code)
+		}
+		return provider.RetryableError(err)
+	},
+}
+
 // LinodeDomainClient interface to ease testing
 type LinodeDomainClient interface {
 	ListDomainRecords(ctx context.Context, domainID int, opts *linodego.ListOptions) ([]linodego.DomainRecord, error)
@@ -146,18 +165,33 @@ func (p *LinodeProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, err
 }
 
 func (p *LinodeProvider) fetchRecords(ctx context.Context, domainID int) ([]linodego.DomainRecord, error) {
-	records, err := p.Client.ListDomainRecords(ctx, domainID, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	return records, nil
+	return provider.Paginate(ctx, retryConfig, func(ctx context.Context, cursor string) ([]linodego.DomainRecord, string, error) {
+		opts, err := listOptions(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		records, err := p.Client.ListDomainRecords(ctx, domainID, opts)
+		if err != nil {
+			return nil, "", err
+		}
+		return records, nextPage(opts), nil
+	})
 }
 
 func (p *LinodeProvider) fetchZones(ctx context.Context) ([]linodego.Domain, error) {
 	var zones []linodego.Domain
 
-	allZones, err := p.Client.ListDomains(ctx, linodego.NewListOptions(0, ""))
+	allZones, err := provider.Paginate(ctx, retryConfig, func(ctx context.Context, cursor string) ([]linodego.Domain, string, error) {
+		opts, err := listOptions(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		domains, err := p.Client.ListDomains(ctx, opts)
+		if err != nil {
+			return nil, "", err
+		}
+		return domains, nextPage(opts), nil
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -173,9 +207,33 @@ func (p *LinodeProvider) fetchZones(ctx context.Context) ([]linodego.Domain, err
 	return zones, nil
 }
 
-// submitChanges takes a zone and a collection of Changes and sends them as a single transaction.
+// listOptions requests the page of the cursor, the first page for the empty cursor. Requesting a
+// page explicitly makes linodego fetch only that page, so a failing page is retried on its own.
+func listOptions(cursor string) (*linodego.ListOptions, error) {
+	page := 1
+	if cursor != "" {
+		var err error
+		if page, err = strconv.Atoi(cursor); err != nil {
+			return nil, fmt.Errorf("invalid page %q: %w", cursor, err)
+		}
+	}
+	opts := linodego.NewListOptions(page, "")
+	opts.PageSize = linodePageSize
+	return opts, nil
+}
+
+// nextPage returns the cursor of the page after the one fetched with the options, empty after the last page.
+func nextPage(opts *linodego.ListOptions) string {
+	if opts.PageOptions == nil || opts.Page >= opts.Pages {
+		return ""
+	}
+	return strconv.Itoa(opts.Page + 1)
+}
+
+// submitChanges takes a zone and a collection of Changes and sends them in batches, since the Linode API
+// takes a request per record.
 func (p *LinodeProvider) submitChanges(ctx context.Context, changes LinodeChanges) error {
-	for _, change := range changes.Creates {
+	createErr := provider.ApplyInBatches(ctx, retryConfig, provider.DefaultBatchSize, changes.Creates, func(ctx context.Context, change LinodeChangeCreate) error {
 		logFields := log.Fields{
 			"record":   change.Options.Name,
 			"type":     change.Options.Type,
@@ -193,10 +251,12 @@ func (p *LinodeProvider) submitChanges(ctx context.Context, changes LinodeChange
 				"Failed to Create record: %v",
 				err,
 			)
+			return err
 		}
-	}
+		return nil
+	})
 
-	for _, change := range changes.Deletes {
+	deleteErr := provider.ApplyInBatches(ctx, retryConfig, provider.DefaultBatchSize, changes.Deletes, func(ctx context.Context, change LinodeChangeDelete) error {
 		logFields := log.Fields{
 			"record":   change.DomainRecord.Name,
 			"type":     change.DomainRecord.Type,
@@ -214,10 +274,12 @@ func (p *LinodeProvider) submitChanges(ctx context.Context, changes LinodeChange
 				"Failed to Delete record: %v",
 				err,
 			)
+			return err
 		}
-	}
+		return nil
+	})
 
-	for _, change := range changes.Updates {
+	updateErr := provider.ApplyInBatches(ctx, retryConfig, provider.DefaultBatchSize, changes.Updates, func(ctx context.Context, change LinodeChangeUpdate) error {
 		logFields := log.Fields{
 			"record":   change.Options.Name,
 			"type":     change.Options.Type,
@@ -235,10 +297,12 @@ func (p *LinodeProvider) submitChanges(ctx context.Context, changes LinodeChange
 				"Failed to Update record: %v",
 				err,
 			)
+			return err
 		}
-	}
+		return nil
+	})
 
-	return nil
+	return errors.Join(createErr, deleteErr, updateErr)
 }
 
 func getWeight(recordType linodego.DomainRecordType) *int {
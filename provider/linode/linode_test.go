@@ -216,6 +216,72 @@ func TestLinodeFetchZonesWithFilter(t *testing.T) {
 	assert.Equal(t, expected, actual)
 }
 
+func TestLinodeFetchRecordsPaginated(t *testing.T) {
+	mockDomainClient := MockDomainClient{}
+
+	provider := &LinodeProvider{
+		Client:       &mockDomainClient,
+		domainFilter: endpoint.NewDomainFilter([]string{}),
+		DryRun:       false,
+	}
+
+	page := func(n int) interface{} {
+		return mock.MatchedBy(func(opts *linodego.ListOptions) bool {
+			return opts.Page == n && opts.PageSize == linodePageSize
+		})
+	}
+	setPages := func(args mock.Arguments) {
+		args.Get(2).(*linodego.ListOptions).Pages = 2
+	}
+
+	mockDomainClient.On(
+		"ListDomainRecords",
+		mock.Anything,
+		1,
+		page(1),
+	).Return(createFooRecords(), nil).Run(setPages).Once()
+	mockDomainClient.On(
+		"ListDomainRecords",
+		mock.Anything,
+		1,
+		page(2),
+	).Return([]linodego.DomainRecord{}, &linodego.Error{Code: 429, Message: "Too Many Requests"}).Run(setPages).Once()
+	mockDomainClient.On(
+		"ListDomainRecords",
+		mock.Anything,
+		1,
+		page(2),
+	).Return(createBazRecords(), nil).Run(setPages).Once()
+
+	actual, err := provider.fetchRecords(context.Background(), 1)
+	require.NoError(t, err)
+
+	mockDomainClient.AssertExpectations(t)
+	assert.Equal(t, append(createFooRecords(), createBazRecords()...), actual)
+}
+
+func TestLinodeFetchRecordsPermanentError(t *testing.T) {
+	mockDomainClient := MockDomainClient{}
+
+	provider := &LinodeProvider{
+		Client:       &mockDomainClient,
+		domainFilter: endpoint.NewDomainFilter([]string{}),
+		DryRun:       false,
+	}
+
+	mockDomainClient.On(
+		"ListDomainRecords",
+		mock.Anything,
+		1,
+		mock.Anything,
+	).Return([]linodego.DomainRecord{}, &linodego.Error{Code: 404, Message: "Not found"}).Once()
+
+	_, err := provider.fetchRecords(context.Background(), 1)
+	require.Error(t, err)
+
+	mockDomainClient.AssertExpectations(t)
+}
+
 func TestLinodeGetStrippedRecordName(t *testing.T) {
 	assert.Equal(t, "", getStrippedRecordName(linodego.Domain{
 		Domain: "foo.com",
@@ -539,3 +605,57 @@ func TestLinodeApplyChangesNoChanges(t *testing.T) {
 
 	mockDomainClient.AssertExpectations(t)
 }
+
+func TestLinodeApplyChangesFailedRequest(t *testing.T) {
+	mockDomainClient := MockDomainClient{}
+
+	provider := &LinodeProvider{
+		Client:       &mockDomainClient,
+		domainFilter: endpoint.NewDomainFilter([]string{}),
+		DryRun:       false,
+	}
+
+	mockDomainClient.On(
+		"ListDomains",
+		mock.Anything,
+		mock.Anything,
+	).Return([]linodego.Domain{{Domain: "example.com", ID: 1}}, nil).Once()
+
+	mockDomainClient.On(
+		"ListDomainRecords",
+		mock.Anything,
+		1,
+		mock.Anything,
+	).Return([]linodego.DomainRecord{}, nil).Once()
+
+	// the invalid request isn't retried and doesn't stop the other changes
+	mockDomainClient.On(
+		"CreateDomainRecord",
+		mock.Anything,
+		1,
+		linodego.DomainRecordCreateOptions{
+			Type: "A", Name: "invalid", Target: "targetA",
+			Priority: getPriority(), Weight: getWeight(linodego.RecordTypeA), Port: getPort(),
+		},
+	).Return(&linodego.DomainRecord{}, &linodego.Error{Code: 400, Message: "invalid target"}).Once()
+
+	mockDomainClient.On(
+		"CreateDomainRecord",
+		mock.Anything,
+		1,
+		linodego.DomainRecordCreateOptions{
+			Type: "A", Name: "valid", Target: "targetA",
+			Priority: getPriority(), Weight: getWeight(linodego.RecordTypeA), Port: getPort(),
+		},
+	).Return(&linodego.DomainRecord{}, nil).Once()
+
+	err := provider.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			{DNSName: "invalid.example.com", RecordType: "A", Targets: []string{"targetA"}},
+			{DNSName: "valid.example.com", RecordType: "A", Targets: []string{"targetA"}},
+		},
+	})
+	require.ErrorContains(t, err, "invalid target")
+
+	mockDomainClient.AssertExpectations(t)
+}
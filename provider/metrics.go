@@ -0,0 +1,182 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+// The operations of the providers whose latency is measured.
+const (
+	OperationListZones    = "list_zones"
+	OperationListRecords  = "list_records"
+	OperationApplyChanges = "apply_changes"
+	OperationApplyBatch   = "apply_batch"
+)
+
+var apiRequestDuration = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Namespace: "external_dns",
+		Subsystem: "provider",
+		Name:      "api_request_duration_seconds",
+		Help:      "Duration of the requests to the API of the DNS provider per operation and result code, with the trace ID of the reconciliation as exemplar.",
+		Buckets:   []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 120},
+	},
+	[]string{"provider", "operation", "code"},
+)
+
+func init() {
+	prometheus.MustRegister(apiRequestDuration)
+}
+
+var traceIDContextKey = &contextKey{"trace ID"}
+
+// NewTraceID returns a random ID in the format of the W3C trace context, to correlate the requests
+// of a reconciliation.
+func NewTraceID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(id)
+}
+
+// WithTraceID returns a context carrying the trace ID, attached as exemplar to the latencies of
+// the requests made with it.
+func WithTraceID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, traceIDContextKey, id)
+}
+
+// TraceID returns the trace ID carried by the context, if any.
+func TraceID(ctx context.Context) string {
+	id, _ := ctx.Value(traceIDContextKey).(string)
+	return id
+}
+
+// ObserveAPIRequest records the latency of a request of the operation to the API of the provider
+// started at the start time and failed with the error, if any.
+func ObserveAPIRequest(ctx context.Context, providerName, operation string, start time.Time, err error) {
+	observer := apiRequestDuration.WithLabelValues(providerName, operation, errorCode(err))
+	duration := time.Since(start).Seconds()
+	if id := TraceID(ctx); id != "" {
+		if exemplar, ok := observer.(prometheus.ExemplarObserver); ok {
+			exemplar.ObserveWithExemplar(duration, prometheus.Labels{"trace_id": id})
+			return
+		}
+	}
+	observer.Observe(duration)
+}
+
+// errorCode returns the label of the result of a request: the code of the error of the API, e.g.
+// "Throttling", or its HTTP status code if the error provides one.
+func errorCode(err error) string {
+	if err == nil {
+		return "ok"
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "timeout"
+	}
+	var coder interface{ Code() string }
+	if errors.As(err, &coder) && coder.Code() != "" {
+		return coder.Code()
+	}
+	var statusCoder interface{ StatusCode() int }
+	if errors.As(err, &statusCoder) && statusCoder.StatusCode() != 0 {
+		return strconv.Itoa(statusCoder.StatusCode())
+	}
+	return "error"
+}
+
+// InstrumentedProvider measures the latency of listing the records and applying the changes of
+// the provider it wraps.
+type InstrumentedProvider struct {
+	Provider
+	name string
+}
+
+// NewInstrumentedProvider wraps the provider to measure the latency of its requests, labelled
+// with the name of the provider.
+func NewInstrumentedProvider(name string, p Provider) *InstrumentedProvider {
+	return &InstrumentedProvider{Provider: p, name: name}
+}
+
+// Records returns the records of the wrapped provider.
+func (p *InstrumentedProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	start := time.Now()
+	records, err := p.Provider.Records(ctx)
+	ObserveAPIRequest(ctx, p.name, OperationListRecords, start, err)
+	return records, err
+}
+
+// ApplyChanges applies the changes with the wrapped provider.
+func (p *InstrumentedProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	start := time.Now()
+	err := p.Provider.ApplyChanges(ctx, changes)
+	ObserveAPIRequest(ctx, p.name, OperationApplyChanges, start, err)
+	return err
+}
+
+// RecordsPage returns the page of the records of the wrapped provider.
+func (p *InstrumentedProvider) RecordsPage(ctx context.Context, pageToken string) ([]*endpoint.Endpoint, string, error) {
+	start := time.Now()
+	records, next, err := RecordsPage(ctx, p.Provider, pageToken)
+	ObserveAPIRequest(ctx, p.name, OperationListRecords, start, err)
+	return records, next, err
+}
+
+// ZoneNames returns the names of the zones of the wrapped provider.
+func (p *InstrumentedProvider) ZoneNames(ctx context.Context) ([]string, error) {
+	start := time.Now()
+	zones, err := ZoneNames(ctx, p.Provider)
+	if errors.Is(err, ErrZonesNotListed) {
+		return nil, err
+	}
+	ObserveAPIRequest(ctx, p.name, OperationListZones, start, err)
+	return zones, err
+}
+
+// PropertyValuesEqual compares the values of provider specific properties as the wrapped provider does.
+func (p *InstrumentedProvider) PropertyValuesEqual(name string, previous string, current string) bool {
+	return PropertyValuesEqual(p.Provider, name, previous, current)
+}
+
+// Unwrap returns the wrapped provider.
+func (p *InstrumentedProvider) Unwrap() Provider {
+	return p.Provider
+}
+
+// Unwrap returns the provider wrapped by an InstrumentedProvider or a CircuitBreakerProvider, or
+// the provider itself.
+func Unwrap(p Provider) Provider {
+	for {
+		wrapper, ok := p.(interface{ Unwrap() Provider })
+		if !ok {
+			return p
+		}
+		p = wrapper.Unwrap()
+	}
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+type failingProvider struct {
+	BaseProvider
+	err error
+}
+
+func (p *failingProvider) Records(context.Context) ([]*endpoint.Endpoint, error) {
+	return nil, p.err
+}
+
+func (p *failingProvider) ApplyChanges(context.Context, *plan.Changes) error {
+	return p.err
+}
+
+func (p *failingProvider) PropertyValuesEqual(string, string, string) bool {
+	return true
+}
+
+func histogram(t *testing.T, providerName, operation, code string) *dto.Histogram {
+	t.Helper()
+	metric := &dto.Metric{}
+	require.NoError(t, apiRequestDuration.WithLabelValues(providerName, operation, code).(prometheus.Metric).Write(metric))
+	return metric.GetHistogram()
+}
+
+func TestErrorCode(t *testing.T) {
+	for _, tt := range []struct {
+		err  error
+		code string
+	}{
+		{nil, "ok"},
+		{errors.New("failure"), "error"},
+		{fmt.Errorf("request: %w", context.DeadlineExceeded), "timeout"},
+		{NewSoftError(awserr.New("Throttling", "rate exceeded", nil)), "Throttling"},
+		{awserr.NewRequestFailure(awserr.New("", "not found", nil), 404, ""), "404"},
+	} {
+		assert.Equal(t, tt.code, errorCode(tt.err), "%v", tt.err)
+	}
+}
+
+func TestTraceID(t *testing.T) {
+	id := NewTraceID()
+	assert.Len(t, id, 32)
+	assert.NotEqual(t, id, NewTraceID())
+	assert.Empty(t, TraceID(context.Background()))
+	assert.Equal(t, id, TraceID(WithTraceID(context.Background(), id)))
+}
+
+func TestInstrumentedProvider(t *testing.T) {
+	inner := &failingProvider{}
+	p := NewInstrumentedProvider("test", inner)
+	ctx := WithTraceID(context.Background(), "0af7651916cd43dd8448eb211c80319c")
+
+	_, err := p.Records(ctx)
+	require.NoError(t, err)
+	inner.err = errors.New("failure")
+	assert.Error(t, p.ApplyChanges(ctx, &plan.Changes{}))
+	ObserveAPIRequest(context.Background(), "test", OperationListZones, time.Now().Add(-time.Second), nil)
+
+	records := histogram(t, "test", OperationListRecords, "ok")
+	assert.Equal(t, uint64(1), records.GetSampleCount())
+	var exemplars []*dto.Exemplar
+	for _, b := range records.GetBucket() {
+		if b.GetExemplar() != nil {
+			exemplars = append(exemplars, b.GetExemplar())
+		}
+	}
+	require.Len(t, exemplars, 1)
+	assert.Equal(t, "trace_id", exemplars[0].GetLabel()[0].GetName())
+	assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", exemplars[0].GetLabel()[0].GetValue())
+
+	assert.Equal(t, uint64(1), histogram(t, "test", OperationApplyChanges, "error").GetSampleCount())
+	zones := histogram(t, "test", OperationListZones, "ok")
+	assert.Equal(t, uint64(1), zones.GetSampleCount())
+	assert.GreaterOrEqual(t, zones.GetSampleSum(), 1.0)
+
+	// the capabilities of the wrapped provider are kept
+	assert.True(t, PropertyValuesEqual(p, "name", "1", "true"))
+	assert.Same(t, inner, Unwrap(p))
+	assert.Same(t, inner, Unwrap(inner))
+}
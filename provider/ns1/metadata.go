@@ -0,0 +1,158 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ns1
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/ns1/ns1-go.v2/rest/model/data"
+	"gopkg.in/ns1/ns1-go.v2/rest/model/dns"
+	"gopkg.in/ns1/ns1-go.v2/rest/model/filter"
+)
+
+const (
+	// providerSpecificFilters holds the filter chain of the record as JSON list, e.g.
+	// [{"filter":"up"},{"filter":"geotarget_country"},{"filter":"select_first_n","config":{"N":1}}]
+	providerSpecificFilters = "ns1-filters"
+	// providerSpecificAnswerMeta holds the metadata of the answers as JSON object keyed by target, e.g.
+	// {"192.0.2.1":{"up":true,"country":["US"]},"192.0.2.2":{"up":false}}
+	providerSpecificAnswerMeta = "ns1-answer-meta"
+)
+
+// ns1Filter is the JSON representation of a filter in the ns1-filters property.
+type ns1Filter struct {
+	Filter   string                 `json:"filter"`
+	Disabled bool                   `json:"disabled,omitempty"`
+	Config   map[string]interface{} `json:"config,omitempty"`
+}
+
+// parseFilters parses the value of the ns1-filters property.
+func parseFilters(value string) ([]*filter.Filter, error) {
+	var parsed []ns1Filter
+	if err := json.Unmarshal([]byte(value), &parsed); err != nil {
+		return nil, fmt.Errorf("invalid %s %q: %w", providerSpecificFilters, value, err)
+	}
+
+	filters := make([]*filter.Filter, 0, len(parsed))
+	for _, f := range parsed {
+		if f.Filter == "" {
+			return nil, fmt.Errorf("invalid %s %q: filter type is missing", providerSpecificFilters, value)
+		}
+		config := filter.Config{}
+		for k, v := range f.Config {
+			config[k] = v
+		}
+		filters = append(filters, &filter.Filter{Type: f.Filter, Disabled: f.Disabled, Config: config})
+	}
+	return filters, nil
+}
+
+// formatFilters returns the ns1-filters property value of the given filter chain.
+func formatFilters(filters []*filter.Filter) (string, error) {
+	formatted := make([]ns1Filter, 0, len(filters))
+	for _, f := range filters {
+		formatted = append(formatted, ns1Filter{Filter: f.Type, Disabled: f.Disabled, Config: f.Config})
+	}
+	value, err := json.Marshal(formatted)
+	if err != nil {
+		return "", err
+	}
+	return string(value), nil
+}
+
+// parseAnswerMeta parses the value of the ns1-answer-meta property.
+func parseAnswerMeta(value string) (map[string]*data.Meta, error) {
+	var parsed map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(value), &parsed); err != nil {
+		return nil, fmt.Errorf("invalid %s %q: %w", providerSpecificAnswerMeta, value, err)
+	}
+
+	metas := make(map[string]*data.Meta, len(parsed))
+	for target, raw := range parsed {
+		meta := &data.Meta{}
+		if err := json.Unmarshal(raw, meta); err != nil {
+			return nil, fmt.Errorf("invalid %s of %q: %w", providerSpecificAnswerMeta, target, err)
+		}
+		metas[target] = meta
+	}
+	return metas, nil
+}
+
+// formatAnswerMeta returns the ns1-answer-meta property value of the given metadata.
+// Answers without metadata are left out.
+func formatAnswerMeta(metas map[string]*data.Meta) (string, error) {
+	formatted := make(map[string]json.RawMessage, len(metas))
+	for target, meta := range metas {
+		if meta == nil {
+			continue
+		}
+		raw, err := json.Marshal(meta)
+		if err != nil {
+			return "", err
+		}
+		if string(raw) == "{}" {
+			continue
+		}
+		formatted[target] = raw
+	}
+	value, err := json.Marshal(formatted)
+	if err != nil {
+		return "", err
+	}
+	return string(value), nil
+}
+
+// answerMeta returns the metadata of the answers of the record keyed by target.
+func answerMeta(answers []*dns.Answer) map[string]*data.Meta {
+	metas := make(map[string]*data.Meta, len(answers))
+	for _, a := range answers {
+		metas[strings.Join(a.Rdata, " ")] = a.Meta
+	}
+	return metas
+}
+
+// canonicalFilters returns the ns1-filters property value in the format reported by Records.
+func canonicalFilters(value string) (string, error) {
+	filters, err := parseFilters(value)
+	if err != nil {
+		return "", err
+	}
+	return formatFilters(filters)
+}
+
+// canonicalAnswerMeta returns the ns1-answer-meta property value in the format reported by Records.
+func canonicalAnswerMeta(value string) (string, error) {
+	metas, err := parseAnswerMeta(value)
+	if err != nil {
+		return "", err
+	}
+	return formatAnswerMeta(metas)
+}
+
+// isEmptyProperty reports whether the canonical property value configures nothing.
+func isEmptyProperty(value string) bool {
+	return value == "[]" || value == "{}"
+}
+
+// hasAdvancedConfig reports whether the record has metadata or a filter chain, which are
+// only returned when getting the record itself rather than the zone.
+func hasAdvancedConfig(r *dns.ZoneRecord) bool {
+	tier, err := r.Tier.Float64()
+	return err == nil && tier > 1
+}
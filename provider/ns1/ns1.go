@@ -22,10 +22,12 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
 	api "gopkg.in/ns1/ns1-go.v2/rest"
+	"gopkg.in/ns1/ns1-go.v2/rest/model/data"
 	"gopkg.in/ns1/ns1-go.v2/rest/model/dns"
 
 	"sigs.k8s.io/external-dns/endpoint"
@@ -51,6 +53,7 @@ type NS1DomainClient interface {
 	UpdateRecord(r *dns.Record) (*http.Response, error)
 	GetZone(zone string) (*dns.Zone, *http.Response, error)
 	ListZones() ([]*dns.Zone, *http.Response, error)
+	GetRecord(zone string, domain string, t string) (*dns.Record, *http.Response, error)
 }
 
 // NS1DomainService wraps the API and fulfills the NS1DomainClient interface
@@ -83,6 +86,11 @@ func (n NS1DomainService) ListZones() ([]*dns.Zone, *http.Response, error) {
 	return n.service.Zones.List()
 }
 
+// GetRecord wraps the Get method of the API's Record service
+func (n NS1DomainService) GetRecord(zone string, domain string, t string) (*dns.Record, *http.Response, error) {
+	return n.service.Records.Get(zone, domain, t)
+}
+
 // NS1Config passes cli args to the NS1Provider
 type NS1Config struct {
 	DomainFilter  endpoint.DomainFilter
@@ -101,6 +109,9 @@ type NS1Provider struct {
 	zoneIDFilter  provider.ZoneIDFilter
 	dryRun        bool
 	minTTLSeconds int
+	// records seen by the last call to Records, to keep the metadata and filter
+	// chains of the records the desired endpoints don't configure.
+	current map[endpoint.EndpointKey]*endpoint.Endpoint
 }
 
 // NewNS1Provider creates a new NS1 Provider
@@ -153,6 +164,7 @@ func (p *NS1Provider) Records(ctx context.Context) ([]*endpoint.Endpoint, error)
 	}
 
 	var endpoints []*endpoint.Endpoint
+	current := make(map[endpoint.EndpointKey]*endpoint.Endpoint)
 
 	for _, zone := range zones {
 		// TODO handle Header Codes
@@ -162,27 +174,238 @@ func (p *NS1Provider) Records(ctx context.Context) ([]*endpoint.Endpoint, error)
 		}
 
 		for _, record := range zoneData.Records {
-			if provider.SupportedRecordType(record.Type) {
-				endpoints = append(endpoints, endpoint.NewEndpointWithTTL(
+			if provider.SupportedRecordType(record.Type) || record.Type == endpoint.RecordTypeNAPTR {
+				targets := record.ShortAns
+				if record.Type == endpoint.RecordTypeNAPTR {
+					targets = make([]string, len(record.ShortAns))
+					for i, answer := range record.ShortAns {
+						targets[i] = ns1NAPTRTarget(answer)
+					}
+				}
+				ep := endpoint.NewEndpointWithTTL(
 					record.Domain,
 					record.Type,
 					endpoint.TTL(record.TTL),
-					record.ShortAns...,
-				),
+					targets...,
 				)
+				eps := []*endpoint.Endpoint{ep}
+				if hasAdvancedConfig(record) {
+					if eps, err = p.advancedEndpoints(ep, zone.Zone); err != nil {
+						return nil, err
+					}
+				}
+				for _, ep := range eps {
+					endpoints = append(endpoints, ep)
+					current[ep.Key()] = ep
+				}
+			}
+		}
+	}
+
+	p.current = current
+	return endpoints, nil
+}
+
+// advancedEndpoints returns the endpoints of the record with the filter chain and answer metadata
+// of the record as provider specific properties. The answers assigned to regions are split into an
+// endpoint per region, whose set identifier is the name of the region.
+func (p *NS1Provider) advancedEndpoints(ep *endpoint.Endpoint, zoneName string) ([]*endpoint.Endpoint, error) {
+	record, _, err := p.client.GetRecord(zoneName, ep.DNSName, ep.RecordType)
+	if err != nil {
+		return nil, err
+	}
+
+	filters, err := formatFilters(record.Filters)
+	if err != nil {
+		return nil, err
+	}
+
+	var regions []string
+	answersByRegion := map[string][]*dns.Answer{}
+	for _, answer := range record.Answers {
+		if _, ok := answersByRegion[answer.RegionName]; !ok {
+			regions = append(regions, answer.RegionName)
+		}
+		answersByRegion[answer.RegionName] = append(answersByRegion[answer.RegionName], answer)
+	}
+	if len(regions) == 0 || (len(regions) == 1 && regions[0] == "") {
+		regions = []string{""}
+		answersByRegion[""] = record.Answers
+	}
+
+	endpoints := make([]*endpoint.Endpoint, 0, len(regions))
+	for _, region := range regions {
+		regionEndpoint := ep
+		if len(regions) > 1 {
+			targets := make([]string, 0, len(answersByRegion[region]))
+			for _, answer := range answersByRegion[region] {
+				target := strings.Join(answer.Rdata, " ")
+				if ep.RecordType == endpoint.RecordTypeNAPTR {
+					target = ns1NAPTRTarget(target)
+				}
+				targets = append(targets, target)
 			}
+			regionEndpoint = endpoint.NewEndpointWithTTL(ep.DNSName, ep.RecordType, ep.RecordTTL, targets...).WithSetIdentifier(region)
+		}
+		if !isEmptyProperty(filters) {
+			regionEndpoint.SetProviderSpecificProperty(providerSpecificFilters, filters)
+		}
+		meta, err := formatAnswerMeta(answerMeta(answersByRegion[region]))
+		if err != nil {
+			return nil, err
+		}
+		if !isEmptyProperty(meta) {
+			regionEndpoint.SetProviderSpecificProperty(providerSpecificAnswerMeta, meta)
 		}
+		endpoints = append(endpoints, regionEndpoint)
 	}
+	return endpoints, nil
+}
+
+// AdjustEndpoints canonicalizes the NS1 provider specific properties of the endpoints.
+// Filter chains and answer metadata the endpoints don't configure are kept as they are
+// in NS1, so the ones set outside of ExternalDNS survive updates of the record.
+// Setting a property to an empty list or object clears it.
+func (p *NS1Provider) AdjustEndpoints(endpoints []*endpoint.Endpoint) ([]*endpoint.Endpoint, error) {
+	for _, ep := range endpoints {
+		current := p.current[ep.Key()]
+		for _, property := range []struct {
+			name      string
+			canonical func(string) (string, error)
+		}{
+			{providerSpecificFilters, canonicalFilters},
+			{providerSpecificAnswerMeta, canonicalAnswerMeta},
+		} {
+			var currentValue string
+			if current != nil {
+				currentValue, _ = current.GetProviderSpecificProperty(property.name)
+			}
+
+			value, ok := ep.GetProviderSpecificProperty(property.name)
+			if ok {
+				canonical, err := property.canonical(value)
+				if err != nil {
+					log.Warnf("Ignoring provider specific property of %s: %v", ep.DNSName, err)
+					ep.DeleteProviderSpecificProperty(property.name)
+					ok = false
+				} else {
+					value = canonical
+				}
+			}
 
+			switch {
+			case !ok && currentValue != "":
+				ep.SetProviderSpecificProperty(property.name, currentValue)
+			case ok && isEmptyProperty(value) && currentValue == "":
+				ep.DeleteProviderSpecificProperty(property.name)
+			case ok:
+				ep.SetProviderSpecificProperty(property.name, value)
+			}
+		}
+	}
 	return endpoints, nil
 }
 
-// ns1BuildRecord returns a dns.Record for a change set
+// propertySchema normalizes the provider specific properties whose values from annotations may
+// differ textually from the ones read from NS1, e.g. by the whitespace or the order of the keys of
+// the filter chains and the answer metadata.
+var propertySchema = provider.PropertySchema{
+	providerSpecificFilters:    canonicalFilters,
+	providerSpecificAnswerMeta: canonicalAnswerMeta,
+}
+
+// PropertyValuesEqual compares the values of provider specific properties semantically.
+func (p *NS1Provider) PropertyValuesEqual(name string, previous string, current string) bool {
+	return propertySchema.PropertyValuesEqual(name, previous, current)
+}
+
+// ns1NAPTRTarget returns the target of a NAPTR answer, whose fields are separated by single
+// spaces, e.g. "100 10 S SIP+D2U  _sip._udp.example.org" with an empty regexp.
+func ns1NAPTRTarget(answer string) string {
+	fields := strings.Split(answer, " ")
+	if len(fields) != 6 {
+		return answer
+	}
+	order, err := strconv.ParseUint(fields[0], 10, 16)
+	if err != nil {
+		return answer
+	}
+	preference, err := strconv.ParseUint(fields[1], 10, 16)
+	if err != nil {
+		return answer
+	}
+	return endpoint.NAPTRTarget{
+		Order:       uint16(order),
+		Preference:  uint16(preference),
+		Flags:       fields[2],
+		Service:     fields[3],
+		Regexp:      fields[4],
+		Replacement: fields[5],
+	}.String()
+}
+
+// ns1NAPTRRdata returns the fields of the answer of a NAPTR target.
+func ns1NAPTRRdata(target string) []string {
+	naptr, err := endpoint.ParseNAPTRTarget(target)
+	if err != nil {
+		return strings.Split(target, " ")
+	}
+	return []string{
+		strconv.Itoa(int(naptr.Order)),
+		strconv.Itoa(int(naptr.Preference)),
+		naptr.Flags,
+		naptr.Service,
+		naptr.Regexp,
+		naptr.Replacement,
+	}
+}
+
+// ns1BuildRecord returns a dns.Record for a change set. The answers of the endpoints with a set
+// identifier are assigned to the region named after it, the filter chain is the one of the first
+// endpoint having one.
 func (p *NS1Provider) ns1BuildRecord(zoneName string, change *ns1Change) *dns.Record {
 	record := dns.NewRecord(zoneName, change.Endpoint.DNSName, change.Endpoint.RecordType, map[string]string{}, []string{})
-	for _, v := range change.Endpoint.Targets {
-		record.AddAnswer(dns.NewAnswer(strings.Split(v, " ")))
+
+	endpoints := change.Group
+	if endpoints == nil {
+		endpoints = []*endpoint.Endpoint{change.Endpoint}
+	}
+	for _, ep := range endpoints {
+		var metas map[string]*data.Meta
+		if value, ok := ep.GetProviderSpecificProperty(providerSpecificAnswerMeta); ok {
+			var err error
+			if metas, err = parseAnswerMeta(value); err != nil {
+				log.Warnf("Ignoring answer metadata of %s: %v", ep.DNSName, err)
+			}
+		}
+		if ep.SetIdentifier != "" {
+			record.Regions[ep.SetIdentifier] = data.Region{}
+		}
+		for _, v := range ep.Targets {
+			rdata := strings.Split(v, " ")
+			if ep.RecordType == endpoint.RecordTypeNAPTR {
+				rdata = ns1NAPTRRdata(v)
+			}
+			answer := dns.NewAnswer(rdata)
+			if meta, ok := metas[v]; ok {
+				answer.Meta = meta
+			}
+			if ep.SetIdentifier != "" {
+				answer.SetRegion(ep.SetIdentifier)
+			}
+			record.AddAnswer(answer)
+		}
+
+		if value, ok := ep.GetProviderSpecificProperty(providerSpecificFilters); ok && len(record.Filters) == 0 {
+			filters, err := parseFilters(value)
+			if err != nil {
+				log.Warnf("Ignoring filter chain of %s: %v", ep.DNSName, err)
+			} else {
+				record.Filters = filters
+			}
+		}
 	}
+
 	// set default ttl, but respect minTTLSeconds
 	ttl := ns1DefaultTTL
 	if p.minTTLSeconds > ttl {
@@ -275,27 +498,38 @@ func (p *NS1Provider) zonesFiltered() ([]*dns.Zone, error) {
 type ns1Change struct {
 	Action   string
 	Endpoint *endpoint.Endpoint
+	// Group is the endpoints of the record told apart by their set identifier, Endpoint alone if nil
+	Group []*endpoint.Endpoint
 }
 
-// ApplyChanges applies a given set of changes in a given zone.
+// ApplyChanges applies a given set of changes in a given zone. The endpoints of a name and type
+// told apart by their set identifier are written as a single record, with the endpoints of the
+// other set identifiers the changes leave alone.
 func (p *NS1Provider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
-	combinedChanges := make([]*ns1Change, 0, len(changes.Create)+len(changes.UpdateNew)+len(changes.Delete))
+	current := make([]*endpoint.Endpoint, 0, len(p.current))
+	for _, ep := range p.current {
+		current = append(current, ep)
+	}
+	groups := provider.GroupChangesBySetIdentifier(current, changes)
+
+	combinedChanges := make([]*ns1Change, 0, len(groups.Create)+len(groups.Update)+len(groups.Delete))
 
-	combinedChanges = append(combinedChanges, newNS1Changes(ns1Create, changes.Create)...)
-	combinedChanges = append(combinedChanges, newNS1Changes(ns1Update, changes.UpdateNew)...)
-	combinedChanges = append(combinedChanges, newNS1Changes(ns1Delete, changes.Delete)...)
+	combinedChanges = append(combinedChanges, newNS1Changes(ns1Create, groups.Create)...)
+	combinedChanges = append(combinedChanges, newNS1Changes(ns1Update, groups.Update)...)
+	combinedChanges = append(combinedChanges, newNS1Changes(ns1Delete, groups.Delete)...)
 
 	return p.ns1SubmitChanges(combinedChanges)
 }
 
-// newNS1Changes returns a collection of Changes based on the given records and action.
-func newNS1Changes(action string, endpoints []*endpoint.Endpoint) []*ns1Change {
-	changes := make([]*ns1Change, 0, len(endpoints))
+// newNS1Changes returns a collection of Changes based on the given groups of records and action.
+func newNS1Changes(action string, groups []*provider.SetIdentifierGroup) []*ns1Change {
+	changes := make([]*ns1Change, 0, len(groups))
 
-	for _, endpoint := range endpoints {
+	for _, group := range groups {
 		changes = append(changes, &ns1Change{
 			Action:   action,
-			Endpoint: endpoint,
+			Endpoint: group.Endpoints[0],
+			Group:    group.Endpoints,
 		},
 		)
 	}
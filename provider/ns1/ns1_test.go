@@ -18,6 +18,7 @@ package ns1
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
@@ -27,7 +28,9 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	api "gopkg.in/ns1/ns1-go.v2/rest"
+	"gopkg.in/ns1/ns1-go.v2/rest/model/data"
 	"gopkg.in/ns1/ns1-go.v2/rest/model/dns"
+	"gopkg.in/ns1/ns1-go.v2/rest/model/filter"
 
 	"sigs.k8s.io/external-dns/endpoint"
 	"sigs.k8s.io/external-dns/plan"
@@ -71,6 +74,10 @@ func (m *MockNS1DomainClient) GetZone(zone string) (*dns.Zone, *http.Response, e
 	return nil, nil, nil
 }
 
+func (m *MockNS1DomainClient) GetRecord(zone string, domain string, t string) (*dns.Record, *http.Response, error) {
+	return nil, nil, api.ErrRecordMissing
+}
+
 func (m *MockNS1DomainClient) ListZones() ([]*dns.Zone, *http.Response, error) {
 	zones := []*dns.Zone{
 		{Zone: "foo.com", ID: "12345678910111213141516a"},
@@ -97,6 +104,10 @@ func (m *MockNS1GetZoneFail) GetZone(zone string) (*dns.Zone, *http.Response, er
 	return nil, nil, api.ErrZoneMissing
 }
 
+func (m *MockNS1GetZoneFail) GetRecord(zone string, domain string, t string) (*dns.Record, *http.Response, error) {
+	return nil, nil, api.ErrRecordMissing
+}
+
 func (m *MockNS1GetZoneFail) ListZones() ([]*dns.Zone, *http.Response, error) {
 	zones := []*dns.Zone{
 		{Zone: "foo.com", ID: "12345678910111213141516a"},
@@ -123,10 +134,70 @@ func (m *MockNS1ListZonesFail) GetZone(zone string) (*dns.Zone, *http.Response,
 	return &dns.Zone{}, nil, nil
 }
 
+func (m *MockNS1ListZonesFail) GetRecord(zone string, domain string, t string) (*dns.Record, *http.Response, error) {
+	return nil, nil, api.ErrRecordMissing
+}
+
 func (m *MockNS1ListZonesFail) ListZones() ([]*dns.Zone, *http.Response, error) {
 	return nil, nil, fmt.Errorf("no zones available")
 }
 
+// MockNS1AdvancedRecords returns a zone with a record using metadata and a filter chain.
+type MockNS1AdvancedRecords struct {
+	MockNS1DomainClient
+}
+
+func (m *MockNS1AdvancedRecords) GetZone(zone string) (*dns.Zone, *http.Response, error) {
+	return &dns.Zone{
+		Zone: "foo.com",
+		Records: []*dns.ZoneRecord{{
+			Domain:   "geo.foo.com",
+			ShortAns: []string{"1.1.1.1", "2.2.2.2"},
+			TTL:      60,
+			Type:     "A",
+			Tier:     json.Number("3"),
+		}},
+	}, nil, nil
+}
+
+func (m *MockNS1AdvancedRecords) GetRecord(zone string, domain string, t string) (*dns.Record, *http.Response, error) {
+	r := dns.NewRecord(zone, domain, t, map[string]string{}, []string{})
+	r.AddAnswer(&dns.Answer{Rdata: []string{"1.1.1.1"}, Meta: &data.Meta{Up: true, Country: []interface{}{"US"}}})
+	r.AddAnswer(&dns.Answer{Rdata: []string{"2.2.2.2"}, Meta: &data.Meta{}})
+	r.Filters = []*filter.Filter{
+		{Type: "up", Config: filter.Config{}},
+		{Type: "select_first_n", Config: filter.Config{"N": float64(1)}},
+	}
+	return r, nil, nil
+}
+
+// MockNS1RegionRecords returns a zone with a record whose answers are assigned to regions.
+type MockNS1RegionRecords struct {
+	MockNS1DomainClient
+}
+
+func (m *MockNS1RegionRecords) GetZone(zone string) (*dns.Zone, *http.Response, error) {
+	return &dns.Zone{
+		Zone: "foo.com",
+		Records: []*dns.ZoneRecord{{
+			Domain:   "pool.foo.com",
+			ShortAns: []string{"1.1.1.1", "2.2.2.2", "3.3.3.3"},
+			TTL:      60,
+			Type:     "A",
+			Tier:     json.Number("3"),
+		}},
+	}, nil, nil
+}
+
+func (m *MockNS1RegionRecords) GetRecord(zone string, domain string, t string) (*dns.Record, *http.Response, error) {
+	r := dns.NewRecord(zone, domain, t, map[string]string{}, []string{})
+	r.AddAnswer(&dns.Answer{Rdata: []string{"1.1.1.1"}, Meta: &data.Meta{Up: true}, RegionName: "east"})
+	r.AddAnswer(&dns.Answer{Rdata: []string{"2.2.2.2"}, Meta: &data.Meta{}, RegionName: "west"})
+	r.AddAnswer(&dns.Answer{Rdata: []string{"3.3.3.3"}, Meta: &data.Meta{}, RegionName: "west"})
+	r.Filters = []*filter.Filter{{Type: "up", Config: filter.Config{}}}
+	return r, nil, nil
+}
+
 func TestNS1Records(t *testing.T) {
 	provider := &NS1Provider{
 		client:        &MockNS1DomainClient{},
@@ -149,6 +220,150 @@ func TestNS1Records(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestNS1RecordsAdvancedConfig(t *testing.T) {
+	provider := &NS1Provider{
+		client:       &MockNS1AdvancedRecords{},
+		domainFilter: endpoint.NewDomainFilter([]string{"foo.com."}),
+		zoneIDFilter: provider.NewZoneIDFilter([]string{""}),
+	}
+
+	records, err := provider.Records(context.Background())
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+
+	filters, ok := records[0].GetProviderSpecificProperty(providerSpecificFilters)
+	require.True(t, ok)
+	assert.Equal(t, `[{"filter":"up"},{"filter":"select_first_n","config":{"N":1}}]`, filters)
+
+	meta, ok := records[0].GetProviderSpecificProperty(providerSpecificAnswerMeta)
+	require.True(t, ok)
+	assert.Equal(t, `{"1.1.1.1":{"up":true,"country":["US"]}}`, meta)
+}
+
+func TestNS1RecordsRegions(t *testing.T) {
+	p := &NS1Provider{
+		client:       &MockNS1RegionRecords{},
+		domainFilter: endpoint.NewDomainFilter([]string{"foo.com."}),
+		zoneIDFilter: provider.NewZoneIDFilter([]string{""}),
+	}
+
+	records, err := p.Records(context.Background())
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+
+	assert.Equal(t, "east", records[0].SetIdentifier)
+	assert.Equal(t, endpoint.Targets{"1.1.1.1"}, records[0].Targets)
+	meta, ok := records[0].GetProviderSpecificProperty(providerSpecificAnswerMeta)
+	require.True(t, ok)
+	assert.Equal(t, `{"1.1.1.1":{"up":true}}`, meta)
+
+	assert.Equal(t, "west", records[1].SetIdentifier)
+	assert.Equal(t, endpoint.Targets{"2.2.2.2", "3.3.3.3"}, records[1].Targets)
+	_, ok = records[1].GetProviderSpecificProperty(providerSpecificAnswerMeta)
+	assert.False(t, ok)
+
+	for _, record := range records {
+		filters, ok := record.GetProviderSpecificProperty(providerSpecificFilters)
+		require.True(t, ok)
+		assert.Equal(t, `[{"filter":"up"}]`, filters)
+	}
+
+	// changing the targets of a region writes the record with the answers of the other regions
+	groups := provider.GroupChangesBySetIdentifier(records, &plan.Changes{
+		UpdateOld: records[1:],
+		UpdateNew: []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("pool.foo.com", "A", 60, "4.4.4.4").WithSetIdentifier("west")},
+	})
+	require.Len(t, groups.Update, 1)
+	record := p.ns1BuildRecord("foo.com", newNS1Changes(ns1Update, groups.Update)[0])
+	require.Len(t, record.Answers, 2)
+	assert.Equal(t, []string{"1.1.1.1"}, record.Answers[0].Rdata)
+	assert.Equal(t, "east", record.Answers[0].RegionName)
+	assert.Equal(t, []string{"4.4.4.4"}, record.Answers[1].Rdata)
+	assert.Equal(t, "west", record.Answers[1].RegionName)
+	assert.Contains(t, record.Regions, "east")
+	assert.Contains(t, record.Regions, "west")
+	require.Len(t, record.Filters, 1)
+}
+
+func TestNS1AdjustEndpoints(t *testing.T) {
+	provider := &NS1Provider{
+		client:       &MockNS1AdvancedRecords{},
+		domainFilter: endpoint.NewDomainFilter([]string{"foo.com."}),
+		zoneIDFilter: provider.NewZoneIDFilter([]string{""}),
+	}
+	current, err := provider.Records(context.Background())
+	require.NoError(t, err)
+	currentFilters, _ := current[0].GetProviderSpecificProperty(providerSpecificFilters)
+	currentMeta, _ := current[0].GetProviderSpecificProperty(providerSpecificAnswerMeta)
+
+	for _, tc := range []struct {
+		title           string
+		dnsName         string
+		properties      map[string]string
+		expectedFilters string
+		expectedMeta    string
+	}{
+		{
+			title:           "keeps the configuration of the current record",
+			dnsName:         "geo.foo.com",
+			expectedFilters: currentFilters,
+			expectedMeta:    currentMeta,
+		},
+		{
+			title:   "canonicalizes the configured properties",
+			dnsName: "geo.foo.com",
+			properties: map[string]string{
+				providerSpecificFilters:    `[ {"filter": "up", "config": {}} ]`,
+				providerSpecificAnswerMeta: `{"1.1.1.1": {"up": false}}`,
+			},
+			expectedFilters: `[{"filter":"up"}]`,
+			expectedMeta:    `{"1.1.1.1":{"up":false}}`,
+		},
+		{
+			title:   "clears the configuration of the current record",
+			dnsName: "geo.foo.com",
+			properties: map[string]string{
+				providerSpecificFilters:    `[]`,
+				providerSpecificAnswerMeta: `{}`,
+			},
+			expectedFilters: `[]`,
+			expectedMeta:    `{}`,
+		},
+		{
+			title:   "drops empty properties of new records",
+			dnsName: "new.foo.com",
+			properties: map[string]string{
+				providerSpecificFilters:    `[]`,
+				providerSpecificAnswerMeta: `{}`,
+			},
+		},
+		{
+			title:   "drops invalid properties",
+			dnsName: "new.foo.com",
+			properties: map[string]string{
+				providerSpecificFilters:    `{"filter": "up"}`,
+				providerSpecificAnswerMeta: `[]`,
+			},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			ep := endpoint.NewEndpointWithTTL(tc.dnsName, "A", 60, "1.1.1.1", "2.2.2.2")
+			for name, value := range tc.properties {
+				ep.SetProviderSpecificProperty(name, value)
+			}
+
+			adjusted, err := provider.AdjustEndpoints([]*endpoint.Endpoint{ep})
+			require.NoError(t, err)
+			require.Len(t, adjusted, 1)
+
+			filters, _ := adjusted[0].GetProviderSpecificProperty(providerSpecificFilters)
+			assert.Equal(t, tc.expectedFilters, filters)
+			meta, _ := adjusted[0].GetProviderSpecificProperty(providerSpecificAnswerMeta)
+			assert.Equal(t, tc.expectedMeta, meta)
+		})
+	}
+}
+
 func TestNewNS1Provider(t *testing.T) {
 	_ = os.Setenv("NS1_APIKEY", "xxxxxxxxxxxxxxxxx")
 	testNS1Config := NS1Config{
@@ -222,6 +437,42 @@ func TestNS1BuildRecord(t *testing.T) {
 	assert.Equal(t, "foo.com", record.Zone)
 	assert.Equal(t, "new-b.foo.com", record.Domain)
 	assert.Equal(t, 3600, record.TTL)
+
+	changeWithConfig := &ns1Change{
+		Action: ns1Update,
+		Endpoint: endpoint.NewEndpoint("geo", "A", "1.1.1.1", "2.2.2.2").
+			WithProviderSpecific(providerSpecificFilters, `[{"filter":"up"},{"filter":"select_first_n","config":{"N":1}}]`).
+			WithProviderSpecific(providerSpecificAnswerMeta, `{"1.1.1.1":{"up":true}}`),
+	}
+	record = provider.ns1BuildRecord("foo.com", changeWithConfig)
+	require.Len(t, record.Filters, 2)
+	assert.Equal(t, "up", record.Filters[0].Type)
+	assert.Equal(t, "select_first_n", record.Filters[1].Type)
+	assert.Equal(t, float64(1), record.Filters[1].Config["N"])
+	require.Len(t, record.Answers, 2)
+	assert.Equal(t, true, record.Answers[0].Meta.Up)
+	assert.Nil(t, record.Answers[1].Meta.Up)
+}
+
+func TestNS1NAPTR(t *testing.T) {
+	target := `100 10 "S" "SIP+D2U" "" _sip._udp.foo.com`
+	assert.Equal(t, []string{"100", "10", "S", "SIP+D2U", "", "_sip._udp.foo.com"}, ns1NAPTRRdata(target))
+	assert.Equal(t, target, ns1NAPTRTarget("100 10 S SIP+D2U  _sip._udp.foo.com"))
+	assert.Equal(t, `100 20 "U" "E2U+sip" "!^.*$!sip:info@foo.com!" .`, ns1NAPTRTarget("100 20 U E2U+sip !^.*$!sip:info@foo.com! ."))
+	assert.Equal(t, "invalid", ns1NAPTRTarget("invalid"))
+
+	provider := &NS1Provider{
+		client:        &MockNS1DomainClient{},
+		domainFilter:  endpoint.NewDomainFilter([]string{"foo.com."}),
+		zoneIDFilter:  provider.NewZoneIDFilter([]string{""}),
+		minTTLSeconds: 300,
+	}
+	record := provider.ns1BuildRecord("foo.com", &ns1Change{
+		Action:   ns1Create,
+		Endpoint: endpoint.NewEndpoint("foo.com", endpoint.RecordTypeNAPTR, target),
+	})
+	require.Len(t, record.Answers, 1)
+	assert.Equal(t, []string{"100", "10", "S", "SIP+D2U", "", "_sip._udp.foo.com"}, record.Answers[0].Rdata)
 }
 
 func TestNS1ApplyChanges(t *testing.T) {
@@ -263,13 +514,18 @@ func TestNewNS1Changes(t *testing.T) {
 		{
 			Action:   "ns1Create",
 			Endpoint: endpoints[0],
+			Group:    endpoints[:1],
 		},
 		{
 			Action:   "ns1Create",
 			Endpoint: endpoints[1],
+			Group:    endpoints[1:],
 		},
 	}
-	changes := newNS1Changes("ns1Create", endpoints)
+	changes := newNS1Changes("ns1Create", []*provider.SetIdentifierGroup{
+		{DNSName: "testa.foo.com", RecordType: "A", Endpoints: endpoints[:1]},
+		{DNSName: "testba.bar.com", RecordType: "A", Endpoints: endpoints[1:]},
+	})
 	require.Len(t, changes, len(expected))
 	assert.Equal(t, expected, changes)
 }
@@ -318,3 +574,36 @@ func TestNewNS1ChangesByZone(t *testing.T) {
 	assert.Len(t, changes["bar.com"], 1)
 	assert.Len(t, changes["foo.com"], 3)
 }
+
+func TestNS1PropertyValuesEqual(t *testing.T) {
+	p := &NS1Provider{}
+	current := endpoint.NewEndpoint("geo.foo.com", endpoint.RecordTypeA, "1.1.1.1").
+		WithProviderSpecific(providerSpecificFilters, `[{"filter":"up"}]`).
+		WithProviderSpecific(providerSpecificAnswerMeta, `{"1.1.1.1":{"up":false}}`)
+
+	for _, tc := range []struct {
+		title        string
+		filters      string
+		meta         string
+		shouldUpdate bool
+	}{
+		{"canonical values", `[{"filter":"up"}]`, `{"1.1.1.1":{"up":false}}`, false},
+		{"formatted values", `[ {"filter": "up", "config": {}} ]`, `{ "1.1.1.1": {"up": false} }`, false},
+		{"other filters", `[{"filter":"up"},{"filter":"shuffle"}]`, `{"1.1.1.1":{"up":false}}`, true},
+		{"other metadata", `[{"filter":"up"}]`, `{"1.1.1.1":{"up":true}}`, true},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			desired := endpoint.NewEndpoint("geo.foo.com", endpoint.RecordTypeA, "1.1.1.1").
+				WithProviderSpecific(providerSpecificFilters, tc.filters).
+				WithProviderSpecific(providerSpecificAnswerMeta, tc.meta)
+			changes := (&plan.Plan{
+				Policies:           []plan.Policy{&plan.SyncPolicy{}},
+				Current:            []*endpoint.Endpoint{current},
+				Desired:            []*endpoint.Endpoint{desired},
+				ManagedRecords:     []string{endpoint.RecordTypeA},
+				PropertyComparator: p.PropertyValuesEqual,
+			}).Calculate().Changes
+			assert.Equal(t, tc.shouldUpdate, len(changes.UpdateNew) == 1)
+		})
+	}
+}
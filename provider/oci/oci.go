@@ -53,6 +53,8 @@ type OCIConfig struct {
 	Auth              OCIAuthConfig `yaml:"auth"`
 	CompartmentID     string        `yaml:"compartment"`
 	ZoneCacheDuration time.Duration
+	// PrivateViewIDs restricts the private zones to the ones in these views.
+	PrivateViewIDs []string `yaml:"privateViewIds"`
 }
 
 // OCIProvider is an implementation of Provider for Oracle Cloud Infrastructure
@@ -74,6 +76,9 @@ type ociDNSClient interface {
 	ListZones(ctx context.Context, request dns.ListZonesRequest) (response dns.ListZonesResponse, err error)
 	GetZoneRecords(ctx context.Context, request dns.GetZoneRecordsRequest) (response dns.GetZoneRecordsResponse, err error)
 	PatchZoneRecords(ctx context.Context, request dns.PatchZoneRecordsRequest) (response dns.PatchZoneRecordsResponse, err error)
+	ListSteeringPolicyAttachments(ctx context.Context, request dns.ListSteeringPolicyAttachmentsRequest) (response dns.ListSteeringPolicyAttachmentsResponse, err error)
+	CreateSteeringPolicyAttachment(ctx context.Context, request dns.CreateSteeringPolicyAttachmentRequest) (response dns.CreateSteeringPolicyAttachmentResponse, err error)
+	DeleteSteeringPolicyAttachment(ctx context.Context, request dns.DeleteSteeringPolicyAttachmentRequest) (response dns.DeleteSteeringPolicyAttachmentResponse, err error)
 }
 
 // LoadOCIConfig reads and parses the OCI ExternalDNS config file at the given
@@ -158,7 +163,15 @@ func (p *OCIProvider) zones(ctx context.Context) (map[string]dns.ZoneSummary, er
 	}
 	log.Debugf("Matching zones against domain filters: %v", p.domainFilter.Filters)
 	for _, scope := range scopes {
-		if err := p.addPaginatedZones(ctx, zones, scope); err != nil {
+		if scope == dns.GetZoneScopePrivate && len(p.cfg.PrivateViewIDs) > 0 {
+			for i := range p.cfg.PrivateViewIDs {
+				if err := p.addPaginatedZones(ctx, zones, scope, &p.cfg.PrivateViewIDs[i]); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+		if err := p.addPaginatedZones(ctx, zones, scope, nil); err != nil {
 			return nil, err
 		}
 	}
@@ -169,7 +182,7 @@ func (p *OCIProvider) zones(ctx context.Context) (map[string]dns.ZoneSummary, er
 	return zones, nil
 }
 
-func (p *OCIProvider) addPaginatedZones(ctx context.Context, zones map[string]dns.ZoneSummary, scope dns.GetZoneScopeEnum) error {
+func (p *OCIProvider) addPaginatedZones(ctx context.Context, zones map[string]dns.ZoneSummary, scope dns.GetZoneScopeEnum, viewID *string) error {
 	var page *string
 	// Loop until we have listed all zones.
 	for {
@@ -177,6 +190,7 @@ func (p *OCIProvider) addPaginatedZones(ctx context.Context, zones map[string]dn
 			CompartmentId: &p.cfg.CompartmentID,
 			ZoneType:      dns.ListZonesZoneTypePrimary,
 			Scope:         dns.ListZonesScopeEnum(scope),
+			ViewId:        viewID,
 			Page:          page,
 		})
 		if err != nil {
@@ -216,12 +230,19 @@ func (p *OCIProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error)
 
 	endpoints := []*endpoint.Endpoint{}
 	for _, zone := range zones {
+		attachments, err := p.steeringAttachments(ctx, zone)
+		if err != nil {
+			return nil, err
+		}
+
 		var page *string
 		for {
 			resp, err := p.client.GetZoneRecords(ctx, dns.GetZoneRecordsRequest{
 				ZoneNameOrId:  zone.Id,
 				Page:          page,
 				CompartmentId: &p.cfg.CompartmentID,
+				Scope:         dns.GetZoneRecordsScopeEnum(zone.Scope),
+				ViewId:        zone.ViewId,
 			})
 			if err != nil {
 				return nil, errors.Wrapf(err, "getting records for zone %q", *zone.Id)
@@ -231,14 +252,14 @@ func (p *OCIProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error)
 				if !provider.SupportedRecordType(*record.Rtype) {
 					continue
 				}
-				endpoints = append(endpoints,
-					endpoint.NewEndpointWithTTL(
-						*record.Domain,
-						*record.Rtype,
-						endpoint.TTL(*record.Ttl),
-						*record.Rdata,
-					),
+				ep := endpoint.NewEndpointWithTTL(
+					*record.Domain,
+					*record.Rtype,
+					endpoint.TTL(*record.Ttl),
+					*record.Rdata,
 				)
+				withSteeringPolicy(ep, attachments)
+				endpoints = append(endpoints, ep)
 			}
 
 			if page = resp.OpcNextPage; resp.OpcNextPage == nil {
@@ -282,20 +303,22 @@ func (p *OCIProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) e
 	}
 
 	if p.dryRun {
-		return nil
+		return p.applySteeringPolicies(ctx, zones, changes)
 	}
 
 	for zoneID, ops := range opsByZone {
 		if _, err := p.client.PatchZoneRecords(ctx, dns.PatchZoneRecordsRequest{
 			CompartmentId:           &p.cfg.CompartmentID,
 			ZoneNameOrId:            &zoneID,
+			Scope:                   dns.PatchZoneRecordsScopeEnum(zones[zoneID].Scope),
+			ViewId:                  zones[zoneID].ViewId,
 			PatchZoneRecordsDetails: dns.PatchZoneRecordsDetails{Items: ops},
 		}); err != nil {
 			return err
 		}
 	}
 
-	return nil
+	return p.applySteeringPolicies(ctx, zones, changes)
 }
 
 // newRecordOperation returns a RecordOperation based on a given endpoint.
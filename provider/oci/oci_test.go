@@ -18,6 +18,7 @@ package oci
 
 import (
 	"context"
+	"fmt"
 	"sort"
 	"strings"
 	"testing"
@@ -128,6 +129,18 @@ func (c *mockOCIDNSClient) PatchZoneRecords(ctx context.Context, request dns.Pat
 	return // Provider does not use the response so nothing to do here.
 }
 
+func (c *mockOCIDNSClient) ListSteeringPolicyAttachments(ctx context.Context, request dns.ListSteeringPolicyAttachmentsRequest) (response dns.ListSteeringPolicyAttachmentsResponse, err error) {
+	return
+}
+
+func (c *mockOCIDNSClient) CreateSteeringPolicyAttachment(ctx context.Context, request dns.CreateSteeringPolicyAttachmentRequest) (response dns.CreateSteeringPolicyAttachmentResponse, err error) {
+	return
+}
+
+func (c *mockOCIDNSClient) DeleteSteeringPolicyAttachment(ctx context.Context, request dns.DeleteSteeringPolicyAttachmentRequest) (response dns.DeleteSteeringPolicyAttachmentResponse, err error) {
+	return
+}
+
 // newOCIProvider creates an OCI provider with API calls mocked out.
 func newOCIProvider(client ociDNSClient, domainFilter endpoint.DomainFilter, zoneIDFilter provider.ZoneIDFilter, zoneScope string, dryRun bool) *OCIProvider {
 	return &OCIProvider{
@@ -534,14 +547,16 @@ func TestOperationsByZone(t *testing.T) {
 }
 
 type mutableMockOCIDNSClient struct {
-	zones   map[string]dns.ZoneSummary
-	records map[string]map[string]dns.Record
+	zones       map[string]dns.ZoneSummary
+	records     map[string]map[string]dns.Record
+	attachments map[string]dns.SteeringPolicyAttachmentSummary
 }
 
 func newMutableMockOCIDNSClient(zones []dns.ZoneSummary, recordsByZone map[string][]dns.Record) *mutableMockOCIDNSClient {
 	c := &mutableMockOCIDNSClient{
-		zones:   make(map[string]dns.ZoneSummary),
-		records: make(map[string]map[string]dns.Record),
+		zones:       make(map[string]dns.ZoneSummary),
+		records:     make(map[string]map[string]dns.Record),
+		attachments: make(map[string]dns.SteeringPolicyAttachmentSummary),
 	}
 
 	for _, zone := range zones {
@@ -561,20 +576,35 @@ func newMutableMockOCIDNSClient(zones []dns.ZoneSummary, recordsByZone map[strin
 func (c *mutableMockOCIDNSClient) ListZones(ctx context.Context, request dns.ListZonesRequest) (response dns.ListZonesResponse, err error) {
 	var zones []dns.ZoneSummary
 	for _, v := range c.zones {
+		if request.ViewId != nil && (v.ViewId == nil || *v.ViewId != *request.ViewId) {
+			continue
+		}
 		zones = append(zones, v)
 	}
 	return dns.ListZonesResponse{Items: zones}, nil
 }
 
-func (c *mutableMockOCIDNSClient) GetZoneRecords(ctx context.Context, request dns.GetZoneRecordsRequest) (response dns.GetZoneRecordsResponse, err error) {
-	if request.ZoneNameOrId == nil {
-		err = errors.New("no name or id")
-		return
+// zoneRecords returns the records of the zone, which must be addressed within its view.
+func (c *mutableMockOCIDNSClient) zoneRecords(zoneID *string, viewID *string) (map[string]dns.Record, error) {
+	if zoneID == nil {
+		return nil, errors.New("no name or id")
 	}
 
-	records, ok := c.records[*request.ZoneNameOrId]
+	records, ok := c.records[*zoneID]
 	if !ok {
-		err = errors.New("zone not found")
+		return nil, errors.New("zone not found")
+	}
+
+	if zone := c.zones[*zoneID]; zone.ViewId != nil && (viewID == nil || *viewID != *zone.ViewId) {
+		return nil, errors.New("zone not found in view")
+	}
+
+	return records, nil
+}
+
+func (c *mutableMockOCIDNSClient) GetZoneRecords(ctx context.Context, request dns.GetZoneRecordsRequest) (response dns.GetZoneRecordsResponse, err error) {
+	records, err := c.zoneRecords(request.ZoneNameOrId, request.ViewId)
+	if err != nil {
 		return
 	}
 
@@ -592,14 +622,8 @@ func ociRecordKey(rType, domain string) string {
 }
 
 func (c *mutableMockOCIDNSClient) PatchZoneRecords(ctx context.Context, request dns.PatchZoneRecordsRequest) (response dns.PatchZoneRecordsResponse, err error) {
-	if request.ZoneNameOrId == nil {
-		err = errors.New("no name or id")
-		return
-	}
-
-	records, ok := c.records[*request.ZoneNameOrId]
-	if !ok {
-		err = errors.New("zone not found")
+	records, err := c.zoneRecords(request.ZoneNameOrId, request.ViewId)
+	if err != nil {
 		return
 	}
 
@@ -628,6 +652,37 @@ func (c *mutableMockOCIDNSClient) PatchZoneRecords(ctx context.Context, request
 	return
 }
 
+func (c *mutableMockOCIDNSClient) ListSteeringPolicyAttachments(ctx context.Context, request dns.ListSteeringPolicyAttachmentsRequest) (response dns.ListSteeringPolicyAttachmentsResponse, err error) {
+	for _, v := range c.attachments {
+		if request.ZoneId == nil || *v.ZoneId == *request.ZoneId {
+			response.Items = append(response.Items, v)
+		}
+	}
+	return
+}
+
+func (c *mutableMockOCIDNSClient) CreateSteeringPolicyAttachment(ctx context.Context, request dns.CreateSteeringPolicyAttachmentRequest) (response dns.CreateSteeringPolicyAttachmentResponse, err error) {
+	details := request.CreateSteeringPolicyAttachmentDetails
+	id := fmt.Sprintf("ocid1.dns-steer-policy-attachment.oc1..%d", len(c.attachments))
+	c.attachments[id] = dns.SteeringPolicyAttachmentSummary{
+		Id:               &id,
+		SteeringPolicyId: details.SteeringPolicyId,
+		ZoneId:           details.ZoneId,
+		DomainName:       details.DomainName,
+		Rtypes:           []string{endpoint.RecordTypeA, endpoint.RecordTypeAAAA, endpoint.RecordTypeCNAME},
+	}
+	return
+}
+
+func (c *mutableMockOCIDNSClient) DeleteSteeringPolicyAttachment(ctx context.Context, request dns.DeleteSteeringPolicyAttachmentRequest) (response dns.DeleteSteeringPolicyAttachmentResponse, err error) {
+	if _, ok := c.attachments[*request.SteeringPolicyAttachmentId]; !ok {
+		err = errors.New("steering policy attachment not found")
+		return
+	}
+	delete(c.attachments, *request.SteeringPolicyAttachmentId)
+	return
+}
+
 // TestMutableMockOCIDNSClient exists because one must always test one's tests
 // right...?
 func TestMutableMockOCIDNSClient(t *testing.T) {
@@ -918,3 +973,69 @@ func TestOCIApplyChanges(t *testing.T) {
 		})
 	}
 }
+
+func TestOCISteeringPolicies(t *testing.T) {
+	zoneID := "ocid1.dns-zone.oc1..e1e042ef0bfbb5c251b9713fd7bf8959"
+	policyID := "ocid1.dns-steering-policy.oc1..aaaaaaaa"
+	client := newMutableMockOCIDNSClient([]dns.ZoneSummary{{
+		Id:    common.String(zoneID),
+		Name:  common.String("foo.com"),
+		Scope: dns.ScopeGlobal,
+	}}, nil)
+	p := newOCIProvider(client, endpoint.NewDomainFilter([]string{""}), provider.NewZoneIDFilter([]string{""}), "", false)
+	ctx := context.Background()
+
+	steered := endpoint.NewEndpointWithTTL("foo.foo.com", endpoint.RecordTypeA, endpoint.TTL(ociRecordTTL), "127.0.0.1").
+		WithProviderSpecific(providerSpecificSteeringPolicyID, policyID)
+	txt := endpoint.NewEndpointWithTTL("foo.foo.com", endpoint.RecordTypeTXT, endpoint.TTL(ociRecordTTL), "heritage=external-dns").
+		WithProviderSpecific(providerSpecificSteeringPolicyID, policyID)
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{Create: []*endpoint.Endpoint{steered, txt}}))
+	require.Len(t, client.attachments, 1)
+
+	// The attachment is reported on the records it covers only.
+	endpoints, err := p.Records(ctx)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []*endpoint.Endpoint{
+		steered,
+		endpoint.NewEndpointWithTTL("foo.foo.com", endpoint.RecordTypeTXT, endpoint.TTL(ociRecordTTL), "heritage=external-dns"),
+	}, endpoints)
+
+	// Removing the property detaches the policy.
+	plain := endpoint.NewEndpointWithTTL("foo.foo.com", endpoint.RecordTypeA, endpoint.TTL(ociRecordTTL), "127.0.0.1")
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{
+		UpdateOld: []*endpoint.Endpoint{steered},
+		UpdateNew: []*endpoint.Endpoint{plain},
+	}))
+	require.Empty(t, client.attachments)
+}
+
+func TestOCIPrivateViews(t *testing.T) {
+	zoneID := "ocid1.dns-zone.oc1..789012ef0bfbb5c251b9713fd7bf8959"
+	viewID := "ocid1.dnsview.oc1..aaaaaaaa"
+	client := newMutableMockOCIDNSClient([]dns.ZoneSummary{{
+		Id:     common.String(zoneID),
+		Name:   common.String("baz.com"),
+		Scope:  dns.ScopePrivate,
+		ViewId: common.String(viewID),
+	}, {
+		Id:     common.String("ocid1.dns-zone.oc1..123456ef0bfbb5c251b9713fd7bf8959"),
+		Name:   common.String("qux.com"),
+		Scope:  dns.ScopePrivate,
+		ViewId: common.String("ocid1.dnsview.oc1..bbbbbbbb"),
+	}}, nil)
+	p := newOCIProvider(client, endpoint.NewDomainFilter([]string{""}), provider.NewZoneIDFilter([]string{""}), "PRIVATE", false)
+	p.cfg.PrivateViewIDs = []string{viewID}
+	ctx := context.Background()
+
+	zones, err := p.zones(ctx)
+	require.NoError(t, err)
+	require.Len(t, zones, 1)
+	require.Contains(t, zones, zoneID)
+
+	// Records are read and written within the view of their zone.
+	ep := endpoint.NewEndpointWithTTL("foo.baz.com", endpoint.RecordTypeA, endpoint.TTL(ociRecordTTL), "10.0.0.1")
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{Create: []*endpoint.Endpoint{ep}}))
+	endpoints, err := p.Records(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []*endpoint.Endpoint{ep}, endpoints)
+}
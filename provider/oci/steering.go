@@ -0,0 +1,189 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oci
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/oracle/oci-go-sdk/v65/dns"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+// providerSpecificSteeringPolicyID is the provider specific property holding the
+// OCID of the Traffic Management steering policy attached to the record's domain.
+// It is set by the external-dns.alpha.kubernetes.io/oci-steering-policy-id annotation.
+const providerSpecificSteeringPolicyID = "oci-steering-policy-id"
+
+// steeringAttachmentKey identifies the steering policy attachment of a domain.
+type steeringAttachmentKey struct {
+	zoneID string
+	domain string
+}
+
+// steeringAttachments returns the steering policy attachments of the given zone by domain.
+// Steering policies can only be attached to global zones.
+func (p *OCIProvider) steeringAttachments(ctx context.Context, zone dns.ZoneSummary) (map[string]dns.SteeringPolicyAttachmentSummary, error) {
+	attachments := make(map[string]dns.SteeringPolicyAttachmentSummary)
+	if zone.Scope == dns.ScopePrivate {
+		return attachments, nil
+	}
+
+	var page *string
+	for {
+		resp, err := p.client.ListSteeringPolicyAttachments(ctx, dns.ListSteeringPolicyAttachmentsRequest{
+			CompartmentId: &p.cfg.CompartmentID,
+			ZoneId:        zone.Id,
+			Page:          page,
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "listing steering policy attachments for zone %q", *zone.Id)
+		}
+		for _, attachment := range resp.Items {
+			attachments[*attachment.DomainName] = attachment
+		}
+		if page = resp.OpcNextPage; resp.OpcNextPage == nil {
+			break
+		}
+	}
+	return attachments, nil
+}
+
+// withSteeringPolicy marks the endpoint with the steering policy attached to its domain,
+// provided the policy covers the record type.
+func withSteeringPolicy(ep *endpoint.Endpoint, attachments map[string]dns.SteeringPolicyAttachmentSummary) {
+	attachment, ok := attachments[ep.DNSName]
+	if !ok || ep.RecordType == endpoint.RecordTypeTXT {
+		return
+	}
+	if len(attachment.Rtypes) > 0 && !containsString(attachment.Rtypes, ep.RecordType) {
+		return
+	}
+	ep.SetProviderSpecificProperty(providerSpecificSteeringPolicyID, *attachment.SteeringPolicyId)
+}
+
+// steeringPolicies returns the steering policy requested for each domain of the given endpoints.
+func steeringPolicies(zones map[string]dns.ZoneSummary, endpoints []*endpoint.Endpoint) map[steeringAttachmentKey]string {
+	zoneNameIDMapper := provider.ZoneIDName{}
+	for _, z := range zones {
+		zoneNameIDMapper.Add(*z.Id, *z.Name)
+	}
+
+	policies := make(map[steeringAttachmentKey]string)
+	for _, ep := range endpoints {
+		policyID, ok := ep.GetProviderSpecificProperty(providerSpecificSteeringPolicyID)
+		if !ok || policyID == "" || ep.RecordType == endpoint.RecordTypeTXT {
+			continue
+		}
+		zoneID, _ := zoneNameIDMapper.FindZone(ep.DNSName)
+		if zoneID == "" {
+			continue
+		}
+		if zones[zoneID].Scope == dns.ScopePrivate {
+			log.Warnf("Ignoring steering policy %q of %q: steering policies cannot be attached to private zones", policyID, ep.DNSName)
+			continue
+		}
+		policies[steeringAttachmentKey{zoneID: zoneID, domain: ep.DNSName}] = policyID
+	}
+	return policies
+}
+
+// applySteeringPolicies attaches and detaches the steering policies requested by the changes.
+func (p *OCIProvider) applySteeringPolicies(ctx context.Context, zones map[string]dns.ZoneSummary, changes *plan.Changes) error {
+	desired := steeringPolicies(zones, append(append([]*endpoint.Endpoint{}, changes.Create...), changes.UpdateNew...))
+	previous := steeringPolicies(zones, append(append([]*endpoint.Endpoint{}, changes.UpdateOld...), changes.Delete...))
+
+	current := make(map[string]map[string]dns.SteeringPolicyAttachmentSummary)
+	attachmentsOf := func(zoneID string) (map[string]dns.SteeringPolicyAttachmentSummary, error) {
+		if attachments, ok := current[zoneID]; ok {
+			return attachments, nil
+		}
+		attachments, err := p.steeringAttachments(ctx, zones[zoneID])
+		if err != nil {
+			return nil, err
+		}
+		current[zoneID] = attachments
+		return attachments, nil
+	}
+
+	for key, policyID := range previous {
+		if desired[key] == policyID {
+			continue
+		}
+		attachments, err := attachmentsOf(key.zoneID)
+		if err != nil {
+			return err
+		}
+		attachment, ok := attachments[key.domain]
+		if !ok || *attachment.SteeringPolicyId != policyID {
+			continue
+		}
+		log.Infof("Detaching steering policy %q from %q", policyID, key.domain)
+		if p.dryRun {
+			continue
+		}
+		if _, err := p.client.DeleteSteeringPolicyAttachment(ctx, dns.DeleteSteeringPolicyAttachmentRequest{
+			SteeringPolicyAttachmentId: attachment.Id,
+		}); err != nil {
+			return errors.Wrapf(err, "detaching steering policy %q from %q", policyID, key.domain)
+		}
+		delete(attachments, key.domain)
+	}
+
+	for key, policyID := range desired {
+		attachments, err := attachmentsOf(key.zoneID)
+		if err != nil {
+			return err
+		}
+		if attachment, ok := attachments[key.domain]; ok {
+			if *attachment.SteeringPolicyId == policyID {
+				continue
+			}
+			return fmt.Errorf("domain %q already has steering policy %q attached", key.domain, *attachment.SteeringPolicyId)
+		}
+		log.Infof("Attaching steering policy %q to %q", policyID, key.domain)
+		if p.dryRun {
+			continue
+		}
+		zoneID, domain := key.zoneID, key.domain
+		if _, err := p.client.CreateSteeringPolicyAttachment(ctx, dns.CreateSteeringPolicyAttachmentRequest{
+			CreateSteeringPolicyAttachmentDetails: dns.CreateSteeringPolicyAttachmentDetails{
+				SteeringPolicyId: &policyID,
+				ZoneId:           &zoneID,
+				DomainName:       &domain,
+			},
+		}); err != nil {
+			return errors.Wrapf(err, "attaching steering policy %q to %q", policyID, key.domain)
+		}
+	}
+
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
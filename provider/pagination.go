@@ -0,0 +1,151 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	backoff "github.com/cenkalti/backoff/v4"
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// RetryConfig configures how failed requests to a provider API are retried.
+type RetryConfig struct {
+	// MaxRetries is the number of times a failed request is retried.
+	MaxRetries uint64
+	// InitialInterval is the wait before the first retry, it doubles with every retry up to MaxInterval.
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	// Retryable reports whether a request failing with the error is worth retrying,
+	// e.g. because it was rate limited. All errors are retried if it is nil.
+	Retryable func(error) bool
+}
+
+// DefaultRetryConfig retries requests failing with transient errors 5 times within about 15 seconds.
+var DefaultRetryConfig = RetryConfig{
+	MaxRetries:      5,
+	InitialInterval: 500 * time.Millisecond,
+	MaxInterval:     8 * time.Second,
+	Retryable:       RetryableError,
+}
+
+// RetryableError reports whether a request failing with the error may succeed when repeated, i.e.
+// it didn't get a response, e.g. because of a timeout or a reset connection, or the provider
+// returned a soft error. The errors of API responses aren't retried, most of them are client
+// errors like invalid requests; providers classify those with RetryableStatus.
+func RetryableError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, SoftError)
+}
+
+// RetryableStatus reports whether a request failing with the HTTP status code may succeed when
+// repeated, i.e. it was rate limited or failed on the side of the API.
+func RetryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+// Do calls op until it succeeds, fails with an error that isn't retryable, the retries are
+// exhausted or the context is done. It returns the last error of op.
+func (c RetryConfig) Do(ctx context.Context, op func(ctx context.Context) error) error {
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = c.InitialInterval
+	b.MaxInterval = c.MaxInterval
+	b.MaxElapsedTime = 0
+
+	return backoff.RetryNotify(func() error {
+		err := op(ctx)
+		if err != nil && c.Retryable != nil && !c.Retryable(err) {
+			return backoff.Permanent(err)
+		}
+		return err
+	}, backoff.WithContext(backoff.WithMaxRetries(b, c.MaxRetries), ctx), func(err error, wait time.Duration) {
+		log.Debugf("Request failed, retrying in %s: %v", wait, err)
+	})
+}
+
+// PageFunc fetches the page of a listing at the cursor, the empty cursor being the first page.
+// It returns the items of the page and the cursor of the next page, which is empty after the last page.
+type PageFunc[T any] func(ctx context.Context, cursor string) (items []T, next string, err error)
+
+// Paginate fetches all pages of a listing, retrying the requests of single pages as configured.
+// It fails when the API returns a cursor twice instead of looping forever.
+func Paginate[T any](ctx context.Context, retry RetryConfig, fetch PageFunc[T]) ([]T, error) {
+	var all []T
+	seen := map[string]bool{}
+	cursor := ""
+	for page := 1; ; page++ {
+		var items []T
+		var next string
+		err := retry.Do(ctx, func(ctx context.Context) error {
+			var err error
+			items, next, err = fetch(ctx, cursor)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch page %d: %w", page, err)
+		}
+		all = append(all, items...)
+
+		if next == "" {
+			return all, nil
+		}
+		if seen[next] {
+			return nil, fmt.Errorf("failed to fetch page %d: cursor %q was returned before", page+1, next)
+		}
+		seen[next] = true
+		cursor = next
+	}
+}
+
+// RecordsPager is implemented by providers listing their records in pages, so that the records of
+// huge zones can be reconciled a page at a time instead of being held in memory all at once.
+// A page must hold every record of the DNS names in it, since the changes of a DNS name are
+// planned from the records of a single page. Paginate(ctx, retry, p.RecordsPage) returns all the
+// records, for implementing Records.
+type RecordsPager interface {
+	// RecordsPage returns the records of the page at the token, the empty token being the first
+	// page, and the token of the next page, which is empty after the last page.
+	RecordsPage(ctx context.Context, pageToken string) (records []*endpoint.Endpoint, nextPageToken string, err error)
+}
+
+// PagesRecords returns whether the provider, once unwrapped, lists its records in pages. The
+// wrappers implement RecordsPager whatever the provider they wrap.
+func PagesRecords(p Provider) bool {
+	_, ok := Unwrap(p).(RecordsPager)
+	return ok
+}
+
+// RecordsPage returns the page of the records of the provider at the token if it implements
+// RecordsPager, and all its records as a single page otherwise.
+func RecordsPage(ctx context.Context, p Provider, pageToken string) ([]*endpoint.Endpoint, string, error) {
+	if pager, ok := p.(RecordsPager); ok {
+		return pager.RecordsPage(ctx, pageToken)
+	}
+	records, err := p.Records(ctx)
+	return records, "", err
+}
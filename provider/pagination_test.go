@@ -0,0 +1,229 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+var (
+	errTransient = errors.New("rate limited")
+	errInvalid   = errors.New("invalid request")
+
+	testRetryConfig = RetryConfig{
+		MaxRetries:      3,
+		InitialInterval: time.Millisecond,
+		MaxInterval:     time.Millisecond,
+		Retryable:       func(err error) bool { return errors.Is(err, errTransient) },
+	}
+)
+
+func TestRetryConfigDo(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		errs        []error
+		expectedErr error
+		calls       int
+	}{
+		{name: "success", calls: 1},
+		{name: "transient errors", errs: []error{errTransient, errTransient}, calls: 3},
+		{name: "retries exhausted", errs: []error{errTransient, errTransient, errTransient, errTransient, errTransient}, expectedErr: errTransient, calls: 4},
+		{name: "permanent error", errs: []error{errTransient, errInvalid, errTransient}, expectedErr: errInvalid, calls: 2},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			err := testRetryConfig.Do(context.Background(), func(ctx context.Context) error {
+				calls++
+				if calls <= len(tc.errs) {
+					return tc.errs[calls-1]
+				}
+				return nil
+			})
+			if tc.expectedErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tc.expectedErr)
+			}
+			assert.Equal(t, tc.calls, calls)
+		})
+	}
+}
+
+func TestRetryConfigDoCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	calls := 0
+	err := RetryConfig{MaxRetries: 10, InitialInterval: time.Hour, MaxInterval: time.Hour}.Do(ctx, func(ctx context.Context) error {
+		calls++
+		return errTransient
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestRetryableError(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		err       error
+		retryable bool
+	}{
+		{name: "connection reset", err: &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}, retryable: true},
+		{name: "wrapped url error", err: fmt.Errorf("failed to list records: %w", &url.Error{Op: "Get", URL: "https://api.example.com", Err: io.EOF}), retryable: true},
+		{name: "unexpected eof", err: io.ErrUnexpectedEOF, retryable: true},
+		{name: "soft error", err: NewSoftError(errInvalid), retryable: true},
+		{name: "canceled", err: &url.Error{Op: "Get", URL: "https://api.example.com", Err: context.Canceled}},
+		{name: "deadline exceeded", err: context.DeadlineExceeded},
+		{name: "api error", err: errInvalid},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.retryable, RetryableError(tc.err))
+		})
+	}
+}
+
+func TestRetryableStatus(t *testing.T) {
+	assert.True(t, RetryableStatus(http.StatusTooManyRequests))
+	assert.True(t, RetryableStatus(http.StatusInternalServerError))
+	assert.True(t, RetryableStatus(http.StatusServiceUnavailable))
+	assert.False(t, RetryableStatus(http.StatusBadRequest))
+	assert.False(t, RetryableStatus(http.StatusNotFound))
+	assert.False(t, RetryableStatus(http.StatusUnprocessableEntity))
+}
+
+// pages returns a PageFunc serving the items in pages of the given size, with the index of the
+// first item of the next page as cursor. The requests listed in failures fail once with the error.
+func pages(items []int, size int, failures map[int]error) (PageFunc[int], *int) {
+	requests := 0
+	return func(ctx context.Context, cursor string) ([]int, string, error) {
+		requests++
+		if err := failures[requests]; err != nil {
+			return nil, "", err
+		}
+		start := 0
+		if cursor != "" {
+			start, _ = strconv.Atoi(cursor)
+		}
+		end := start + size
+		if end >= len(items) {
+			return items[start:], "", nil
+		}
+		return items[start:end], strconv.Itoa(end), nil
+	}, &requests
+}
+
+func TestPaginate(t *testing.T) {
+	items := make([]int, 2500)
+	for i := range items {
+		items[i] = i
+	}
+
+	fetch, requests := pages(items, 1000, nil)
+	all, err := Paginate(context.Background(), testRetryConfig, fetch)
+	require.NoError(t, err)
+	assert.Equal(t, items, all)
+	assert.Equal(t, 3, *requests)
+
+	fetch, requests = pages(items, 1000, map[int]error{2: errTransient, 3: errTransient})
+	all, err = Paginate(context.Background(), testRetryConfig, fetch)
+	require.NoError(t, err)
+	assert.Equal(t, items, all)
+	assert.Equal(t, 5, *requests)
+
+	fetch, _ = pages(items, 1000, map[int]error{2: errInvalid})
+	_, err = Paginate(context.Background(), testRetryConfig, fetch)
+	assert.ErrorIs(t, err, errInvalid)
+	assert.ErrorContains(t, err, "page 2")
+
+	fetch, requests = pages(nil, 1000, nil)
+	all, err = Paginate(context.Background(), testRetryConfig, fetch)
+	require.NoError(t, err)
+	assert.Empty(t, all)
+	assert.Equal(t, 1, *requests)
+}
+
+func TestPaginateCursorLoop(t *testing.T) {
+	requests := 0
+	_, err := Paginate(context.Background(), testRetryConfig, func(ctx context.Context, cursor string) ([]int, string, error) {
+		requests++
+		return []int{requests}, "same", nil
+	})
+	assert.ErrorContains(t, err, "cursor \"same\" was returned before")
+	assert.Equal(t, 2, requests)
+}
+
+type pagedProvider struct {
+	textualProvider
+}
+
+func (p pagedProvider) RecordsPage(ctx context.Context, pageToken string) ([]*endpoint.Endpoint, string, error) {
+	if pageToken == "" {
+		return []*endpoint.Endpoint{endpoint.NewEndpoint("a.example.org", endpoint.RecordTypeA, "1.1.1.1")}, "b", nil
+	}
+	return []*endpoint.Endpoint{endpoint.NewEndpoint("b.example.org", endpoint.RecordTypeA, "2.2.2.2")}, "", nil
+}
+
+func TestRecordsPage(t *testing.T) {
+	records, next, err := RecordsPage(context.Background(), pagedProvider{}, "")
+	require.NoError(t, err)
+	assert.Equal(t, "a.example.org", records[0].DNSName)
+	assert.Equal(t, "b", next)
+
+	records, err = Paginate(context.Background(), testRetryConfig, pagedProvider{}.RecordsPage)
+	require.NoError(t, err)
+	assert.Len(t, records, 2)
+
+	// the providers without pages return all their records as a single page
+	records, next, err = RecordsPage(context.Background(), textualProvider{}, "")
+	require.NoError(t, err)
+	assert.Empty(t, records)
+	assert.Empty(t, next)
+}
+
+func TestRecordsPageWrapped(t *testing.T) {
+	for _, p := range []Provider{
+		NewInstrumentedProvider("paged", pagedProvider{}),
+		NewCircuitBreakerProvider("paged", pagedProvider{}, 3, time.Minute),
+	} {
+		_, next, err := RecordsPage(context.Background(), p, "")
+		require.NoError(t, err)
+		assert.Equal(t, "b", next)
+	}
+}
+
+func TestPagesRecords(t *testing.T) {
+	assert.True(t, PagesRecords(pagedProvider{}))
+	assert.True(t, PagesRecords(NewInstrumentedProvider("paged", pagedProvider{})))
+	assert.False(t, PagesRecords(textualProvider{}))
+	// the wrappers implement RecordsPager whatever the provider they wrap
+	assert.False(t, PagesRecords(NewInstrumentedProvider("textual", textualProvider{})))
+}
@@ -33,6 +33,7 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/pkg/credentials"
 	"sigs.k8s.io/external-dns/pkg/tlsutils"
 	"sigs.k8s.io/external-dns/plan"
 	"sigs.k8s.io/external-dns/provider"
@@ -67,7 +68,9 @@ type PDNSConfig struct {
 	DryRun       bool
 	Server       string
 	APIKey       string
-	TLSConfig    TLSConfig
+	// APIKeyRefresher, when set, provides the API key instead of APIKey, e.g. read from Vault
+	APIKeyRefresher *credentials.Refresher
+	TLSConfig       TLSConfig
 }
 
 // TLSConfig is comprised of the TLS-related fields necessary to create a new PDNSProvider
@@ -136,17 +139,46 @@ type PDNSAPIProvider interface {
 
 // PDNSAPIClient : Struct that encapsulates all the PowerDNS specific implementation details
 type PDNSAPIClient struct {
-	dryRun       bool
-	authCtx      context.Context
-	client       *pgo.APIClient
-	domainFilter endpoint.DomainFilter
+	dryRun          bool
+	authCtx         context.Context
+	apiKeyRefresher *credentials.Refresher
+	client          *pgo.APIClient
+	domainFilter    endpoint.DomainFilter
+}
+
+// auth returns the context authorizing the requests with the current API key.
+func (c *PDNSAPIClient) auth() (context.Context, error) {
+	if c.apiKeyRefresher == nil {
+		return c.authCtx, nil
+	}
+	creds, err := c.apiKeyRefresher.Get(c.authCtx)
+	if err != nil {
+		return nil, err
+	}
+	return context.WithValue(c.authCtx, pgo.ContextAPIKey, pgo.APIKey{Key: creds[credentials.PDNSAPIKey]}), nil
+}
+
+// unauthorized returns whether the API key was rejected, which is fetched again if it has a
+// refresher instead of retrying the request.
+func (c *PDNSAPIClient) unauthorized(resp *http.Response, err error) (bool, error) {
+	if c.apiKeyRefresher == nil || resp == nil || resp.StatusCode != http.StatusUnauthorized {
+		return false, err
+	}
+	return true, c.apiKeyRefresher.Rejected(err)
 }
 
 // ListZones : Method returns all enabled zones from PowerDNS
 // ref: https://doc.powerdns.com/authoritative/http-api/zone.html#get--servers-server_id-zones
 func (c *PDNSAPIClient) ListZones() (zones []pgo.Zone, resp *http.Response, err error) {
+	authCtx, err := c.auth()
+	if err != nil {
+		return nil, nil, err
+	}
 	for i := 0; i < retryLimit; i++ {
-		zones, resp, err = c.client.ZonesApi.ListZones(c.authCtx, defaultServerID)
+		zones, resp, err = c.client.ZonesApi.ListZones(authCtx, defaultServerID)
+		if rejected, err := c.unauthorized(resp, err); rejected {
+			return zones, resp, err
+		}
 		if err != nil {
 			log.Debugf("Unable to fetch zones %v", err)
 			log.Debugf("Retrying ListZones() ... %d", i)
@@ -179,8 +211,15 @@ func (c *PDNSAPIClient) PartitionZones(zones []pgo.Zone) (filteredZones []pgo.Zo
 // ListZone : Method returns the details of a specific zone from PowerDNS
 // ref: https://doc.powerdns.com/authoritative/http-api/zone.html#get--servers-server_id-zones-zone_id
 func (c *PDNSAPIClient) ListZone(zoneID string) (zone pgo.Zone, resp *http.Response, err error) {
+	authCtx, err := c.auth()
+	if err != nil {
+		return zone, nil, err
+	}
 	for i := 0; i < retryLimit; i++ {
-		zone, resp, err = c.client.ZonesApi.ListZone(c.authCtx, defaultServerID, zoneID)
+		zone, resp, err = c.client.ZonesApi.ListZone(authCtx, defaultServerID, zoneID)
+		if rejected, err := c.unauthorized(resp, err); rejected {
+			return zone, resp, err
+		}
 		if err != nil {
 			log.Debugf("Unable to fetch zone %v", err)
 			log.Debugf("Retrying ListZone() ... %d", i)
@@ -197,8 +236,15 @@ func (c *PDNSAPIClient) ListZone(zoneID string) (zone pgo.Zone, resp *http.Respo
 // PatchZone : Method used to update the contents of a particular zone from PowerDNS
 // ref: https://doc.powerdns.com/authoritative/http-api/zone.html#patch--servers-server_id-zones-zone_id
 func (c *PDNSAPIClient) PatchZone(zoneID string, zoneStruct pgo.Zone) (resp *http.Response, err error) {
+	authCtx, err := c.auth()
+	if err != nil {
+		return nil, err
+	}
 	for i := 0; i < retryLimit; i++ {
-		resp, err = c.client.ZonesApi.PatchZone(c.authCtx, defaultServerID, zoneID, zoneStruct)
+		resp, err = c.client.ZonesApi.PatchZone(authCtx, defaultServerID, zoneID, zoneStruct)
+		if rejected, err := c.unauthorized(resp, err); rejected {
+			return resp, err
+		}
 		if err != nil {
 			log.Debugf("Unable to patch zone %v", err)
 			log.Debugf("Retrying PatchZone() ... %d", i)
@@ -222,7 +268,7 @@ type PDNSProvider struct {
 func NewPDNSProvider(ctx context.Context, config PDNSConfig) (*PDNSProvider, error) {
 	// Do some input validation
 
-	if config.APIKey == "" {
+	if config.APIKey == "" && config.APIKeyRefresher == nil {
 		return nil, errors.New("missing API Key for PDNS. Specify using --pdns-api-key=")
 	}
 
@@ -244,10 +290,11 @@ func NewPDNSProvider(ctx context.Context, config PDNSConfig) (*PDNSProvider, err
 
 	provider := &PDNSProvider{
 		client: &PDNSAPIClient{
-			dryRun:       config.DryRun,
-			authCtx:      context.WithValue(ctx, pgo.ContextAPIKey, pgo.APIKey{Key: config.APIKey}),
-			client:       pgo.NewAPIClient(pdnsClientConfig),
-			domainFilter: config.DomainFilter,
+			dryRun:          config.DryRun,
+			authCtx:         context.WithValue(ctx, pgo.ContextAPIKey, pgo.APIKey{Key: config.APIKey}),
+			apiKeyRefresher: config.APIKeyRefresher,
+			client:          pgo.NewAPIClient(pdnsClientConfig),
+			domainFilter:    config.DomainFilter,
 		},
 	}
 	return provider, nil
@@ -317,6 +364,11 @@ func (p *PDNSProvider) ConvertEndpointsToZones(eps []*endpoint.Endpoint, changet
 					if ep.RecordType == "CNAME" || ep.RecordType == "ALIAS" {
 						t = provider.EnsureTrailingDot(t)
 					}
+					if ep.RecordType == endpoint.RecordTypeNAPTR {
+						if naptr, err := endpoint.ParseNAPTRTarget(t); err == nil {
+							t = naptr.FQDN()
+						}
+					}
 					records = append(records, pgo.Record{Content: t})
 				}
 
@@ -343,6 +395,10 @@ func (p *PDNSProvider) ConvertEndpointsToZones(eps []*endpoint.Endpoint, changet
 					} else {
 						rrset.Ttl = int32(ep.RecordTTL)
 					}
+					// the current comments are kept by the changes without description
+					if description := ep.Labels[endpoint.DescriptionLabelKey]; description != "" {
+						rrset.Comments = []pgo.Comment{{Content: description, Account: "external-dns"}}
+					}
 				}
 
 				zone.Rrsets = append(zone.Rrsets, rrset)
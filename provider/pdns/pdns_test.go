@@ -20,15 +20,18 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"net/http/httptest"
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	pgo "github.com/ffledgling/pdns-go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 
 	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/pkg/credentials"
 )
 
 // FIXME: What do we do about labels?
@@ -854,6 +857,19 @@ func (suite *NewPDNSProviderTestSuite) TestPDNSRRSetToEndpoints() {
 	eps, err = p.convertRRSetToEndpoints(RRSetDisabledRecord)
 	assert.Nil(suite.T(), err)
 	assert.Equal(suite.T(), endpointsDisabledRecord, eps)
+
+	// Given an RRSet of NAPTR records, we test that the targets have the form of the NAPTR endpoints
+	eps, err = p.convertRRSetToEndpoints(pgo.RrSet{
+		Name:  "example.com.",
+		Type_: "NAPTR",
+		Ttl:   300,
+		Records: []pgo.Record{
+			{Content: `100 10 "S" "SIP+D2U" "" _sip._udp.example.com.`},
+			{Content: `100 20 "U" "E2U+sip" "!^.*$!sip:info@example.com!" .`},
+		},
+	})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), endpoint.Targets{`100 10 "S" "SIP+D2U" "" _sip._udp.example.com`, `100 20 "U" "E2U+sip" "!^.*$!sip:info@example.com!" .`}, eps[0].Targets)
 }
 
 func (suite *NewPDNSProviderTestSuite) TestPDNSRecords() {
@@ -944,6 +960,17 @@ func (suite *NewPDNSProviderTestSuite) TestPDNSConvertEndpointsToZones() {
 		}
 	}
 
+	// Check endpoints of type NAPTR have their replacement fully qualified
+	zlist, err = p.ConvertEndpointsToZones([]*endpoint.Endpoint{
+		endpoint.NewEndpointWithTTL("example.com", endpoint.RecordTypeNAPTR, endpoint.TTL(300), `100 10 "S" "SIP+D2U" "" _sip._udp.example.com`, `100 20 "U" "E2U+sip" "!^.*$!sip:info@example.com!" .`),
+	}, PdnsReplace)
+	assert.Nil(suite.T(), err)
+	assert.Len(suite.T(), zlist, 1)
+	assert.Equal(suite.T(), []pgo.Record{
+		{Content: `100 10 "S" "SIP+D2U" "" _sip._udp.example.com.`},
+		{Content: `100 20 "U" "E2U+sip" "!^.*$!sip:info@example.com!" .`},
+	}, zlist[0].Rrsets[0].Records)
+
 	// Check endpoints of type CNAME are converted to ALIAS on the domain apex
 	zlist, err = p.ConvertEndpointsToZones(endpointsApexRecords, PdnsReplace)
 	assert.Nil(suite.T(), err)
@@ -1075,6 +1102,41 @@ func (suite *NewPDNSProviderTestSuite) TestPDNSClientPartitionZones() {
 	assert.Equal(suite.T(), partitionResultResidualSingleFilter, residualZones)
 }
 
+type pdnsKeySource struct {
+	key string
+}
+
+func (s *pdnsKeySource) Fetch(context.Context) (credentials.Credentials, time.Time, error) {
+	return credentials.Credentials{credentials.PDNSAPIKey: s.key}, time.Time{}, nil
+}
+
+func (suite *NewPDNSProviderTestSuite) TestPDNSClientAPIKeyRefresher() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-API-Key") != "rotated" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"id":"example.com.","name":"example.com."}]`))
+	}))
+	defer server.Close()
+
+	source := &pdnsKeySource{key: "expired"}
+	p, err := NewPDNSProvider(context.Background(), PDNSConfig{
+		Server:          server.URL,
+		APIKeyRefresher: credentials.NewRefresher(source, 0),
+	})
+	assert.NoError(suite.T(), err)
+
+	// the rejected key is fetched again on the next request instead of being retried
+	_, _, err = p.client.ListZones()
+	assert.ErrorIs(suite.T(), err, credentials.ErrRejected)
+
+	source.key = "rotated"
+	zones, _, err := p.client.ListZones()
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), zones, 1)
+}
+
 func TestNewPDNSProviderTestSuite(t *testing.T) {
 	suite.Run(t, new(NewPDNSProviderTestSuite))
 }
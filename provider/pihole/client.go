@@ -45,6 +45,12 @@ type piholeAPI interface {
 	deleteRecord(ctx context.Context, ep *endpoint.Endpoint) error
 }
 
+// piholeBatchAPI is implemented by clients able to apply all changes at once.
+type piholeBatchAPI interface {
+	// applyChanges deletes and creates the given records in a single transaction.
+	applyChanges(ctx context.Context, deletes, creates []*endpoint.Endpoint) error
+}
+
 // piholeClient implements the piholeAPI.
 type piholeClient struct {
 	cfg        PiholeConfig
@@ -0,0 +1,456 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pihole
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/linki/instrumented_http"
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// piholeClientV6 implements the piholeAPI with the REST API of Pi-hole v6.
+// All changes are applied with a single update of the local DNS configuration.
+type piholeClientV6 struct {
+	cfg        PiholeConfig
+	httpClient *http.Client
+
+	// sid is the session of the client, shared by concurrent requests.
+	mu  sync.Mutex
+	sid string
+}
+
+// piholeDNSConfig holds the local DNS records of the Pi-hole configuration.
+type piholeDNSConfig struct {
+	// Hosts are entries of the form "<ip> <name> [<name>...]".
+	Hosts []string `json:"hosts"`
+	// CNAMERecords are entries of the form "<name>,<target>[,<ttl>]".
+	CNAMERecords []string `json:"cnameRecords"`
+}
+
+type piholeConfigV6 struct {
+	Config struct {
+		DNS piholeDNSConfig `json:"dns"`
+	} `json:"config"`
+}
+
+type piholeAuthResponseV6 struct {
+	Session struct {
+		Valid   bool   `json:"valid"`
+		SID     string `json:"sid"`
+		Message string `json:"message"`
+	} `json:"session"`
+}
+
+type piholeErrorResponseV6 struct {
+	Error struct {
+		Key     string `json:"key"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// errUnauthorized is returned when the session of the client is missing or expired.
+var errUnauthorized = errors.New("unauthorized")
+
+// newPiholeClientV6 creates a new Pi-hole v6 API client.
+func newPiholeClientV6(cfg PiholeConfig) (piholeAPI, error) {
+	if cfg.Server == "" {
+		return nil, ErrNoPiholeServer
+	}
+
+	httpClient := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: cfg.TLSInsecureSkipVerify,
+			},
+		},
+	}
+	cl := instrumented_http.NewClient(httpClient, &instrumented_http.Callbacks{})
+
+	p := &piholeClientV6{
+		cfg:        cfg,
+		httpClient: cl,
+	}
+
+	if cfg.Password != "" {
+		if err := p.login(context.Background()); err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+func (p *piholeClientV6) listRecords(ctx context.Context, rtype string) ([]*endpoint.Endpoint, error) {
+	dnsConfig, err := p.getDNSConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]*endpoint.Endpoint, 0)
+	byName := make(map[string]*endpoint.Endpoint)
+	add := func(name, target string, ttl endpoint.TTL) {
+		if !p.cfg.DomainFilter.Match(name) {
+			log.Debugf("Skipping %s that does not match domain filter", name)
+			return
+		}
+		if ep, ok := byName[name]; ok {
+			ep.Targets = append(ep.Targets, target)
+			return
+		}
+		ep := endpoint.NewEndpointWithTTL(name, rtype, ttl, target)
+		byName[name] = ep
+		out = append(out, ep)
+	}
+
+	switch rtype {
+	case endpoint.RecordTypeA, endpoint.RecordTypeAAAA:
+		for _, entry := range dnsConfig.Hosts {
+			ip, names, ok := parseHostsEntry(entry)
+			if !ok || hostsRecordType(ip) != rtype {
+				continue
+			}
+			for _, name := range names {
+				add(name, ip, 0)
+			}
+		}
+	case endpoint.RecordTypeCNAME:
+		for _, entry := range dnsConfig.CNAMERecords {
+			name, target, ttl, ok := parseCNAMEEntry(entry)
+			if !ok {
+				continue
+			}
+			add(name, target, ttl)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported record type: %s", rtype)
+	}
+
+	return out, nil
+}
+
+func (p *piholeClientV6) createRecord(ctx context.Context, ep *endpoint.Endpoint) error {
+	return p.applyChanges(ctx, nil, []*endpoint.Endpoint{ep})
+}
+
+func (p *piholeClientV6) deleteRecord(ctx context.Context, ep *endpoint.Endpoint) error {
+	return p.applyChanges(ctx, []*endpoint.Endpoint{ep}, nil)
+}
+
+// applyChanges removes the deleted and adds the created endpoints with a single
+// update of the configuration, so other clients never see a partially applied change.
+func (p *piholeClientV6) applyChanges(ctx context.Context, deletes, creates []*endpoint.Endpoint) error {
+	dnsConfig, err := p.getDNSConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	hosts := newHostsEntries(dnsConfig.Hosts)
+	cnames := dnsConfig.CNAMERecords
+	changed := false
+
+	for _, ep := range deletes {
+		if !p.supported("delete", ep) {
+			continue
+		}
+		for _, target := range ep.Targets {
+			log.Infof("%sdelete %s IN %s -> %s", p.dryRunPrefix(), ep.DNSName, ep.RecordType, target)
+			if ep.RecordType == endpoint.RecordTypeCNAME {
+				var removed bool
+				cnames, removed = removeCNAMEEntry(cnames, ep.DNSName, target)
+				changed = changed || removed
+			} else {
+				changed = hosts.remove(target, ep.DNSName) || changed
+			}
+		}
+	}
+
+	for _, ep := range creates {
+		if !p.supported("add", ep) {
+			continue
+		}
+		for _, target := range ep.Targets {
+			log.Infof("%sadd %s IN %s -> %s", p.dryRunPrefix(), ep.DNSName, ep.RecordType, target)
+			if ep.RecordType == endpoint.RecordTypeCNAME {
+				entry := formatCNAMEEntry(ep.DNSName, target, ep.RecordTTL)
+				if !containsEntry(cnames, entry) {
+					cnames, _ = removeCNAMEEntry(cnames, ep.DNSName, target)
+					cnames = append(cnames, entry)
+					changed = true
+				}
+			} else {
+				changed = hosts.add(target, ep.DNSName) || changed
+			}
+		}
+	}
+
+	if !changed || p.cfg.DryRun {
+		return nil
+	}
+
+	update := piholeConfigV6{}
+	update.Config.DNS = piholeDNSConfig{
+		Hosts:        hosts.entries(),
+		CNAMERecords: append([]string{}, cnames...),
+	}
+	return p.do(ctx, http.MethodPatch, "/api/config", update, nil)
+}
+
+func (p *piholeClientV6) supported(action string, ep *endpoint.Endpoint) bool {
+	if !p.cfg.DomainFilter.Match(ep.DNSName) {
+		log.Debugf("Skipping %s %s that does not match domain filter", action, ep.DNSName)
+		return false
+	}
+	switch ep.RecordType {
+	case endpoint.RecordTypeA, endpoint.RecordTypeAAAA, endpoint.RecordTypeCNAME:
+		return true
+	}
+	log.Warnf("Skipping unsupported endpoint %s %s %v", ep.DNSName, ep.RecordType, ep.Targets)
+	return false
+}
+
+func (p *piholeClientV6) dryRunPrefix() string {
+	if p.cfg.DryRun {
+		return "DRY RUN: "
+	}
+	return ""
+}
+
+func (p *piholeClientV6) getDNSConfig(ctx context.Context) (*piholeDNSConfig, error) {
+	var res piholeConfigV6
+	if err := p.do(ctx, http.MethodGet, "/api/config/dns", nil, &res); err != nil {
+		return nil, err
+	}
+	return &res.Config.DNS, nil
+}
+
+// login creates a new session with the configured password.
+func (p *piholeClientV6) login(ctx context.Context) error {
+	log.Debugf("Creating new session on %s", p.cfg.Server)
+
+	var res piholeAuthResponseV6
+	body := map[string]string{"password": p.cfg.Password}
+	if err := p.send(ctx, http.MethodPost, "/api/auth", body, &res); err != nil {
+		return fmt.Errorf("failed to log in to Pi-hole: %w", err)
+	}
+	if !res.Session.Valid {
+		return fmt.Errorf("failed to log in to Pi-hole: %s", res.Session.Message)
+	}
+
+	p.mu.Lock()
+	p.sid = res.Session.SID
+	p.mu.Unlock()
+	return nil
+}
+
+// do sends the request, logging in again once if the session has expired.
+func (p *piholeClientV6) do(ctx context.Context, method, path string, in, out interface{}) error {
+	err := p.send(ctx, method, path, in, out)
+	if errors.Is(err, errUnauthorized) && p.cfg.Password != "" {
+		log.Info("Pihole session has expired, creating a new one")
+		if err := p.login(ctx); err != nil {
+			return err
+		}
+		err = p.send(ctx, method, path, in, out)
+	}
+	return err
+}
+
+func (p *piholeClientV6) send(ctx context.Context, method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		raw, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(raw)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(p.cfg.Server, "/")+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	p.mu.Lock()
+	if p.sid != "" {
+		req.Header.Set("X-FTL-SID", p.sid)
+	}
+	p.mu.Unlock()
+
+	res, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode == http.StatusUnauthorized && path != "/api/auth" {
+		return errUnauthorized
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		var apiErr piholeErrorResponseV6
+		if json.Unmarshal(raw, &apiErr) == nil && apiErr.Error.Message != "" {
+			return fmt.Errorf("received %s from request: %s (%s)", res.Status, apiErr.Error.Message, apiErr.Error.Key)
+		}
+		return fmt.Errorf("received non-2xx status code from request: %s", res.Status)
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(raw, out)
+}
+
+// hostsRecordType returns the record type of the hosts entry address.
+func hostsRecordType(ip string) string {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return ""
+	}
+	if addr.To4() != nil {
+		return endpoint.RecordTypeA
+	}
+	return endpoint.RecordTypeAAAA
+}
+
+func parseHostsEntry(entry string) (string, []string, bool) {
+	fields := strings.Fields(entry)
+	if len(fields) < 2 {
+		return "", nil, false
+	}
+	return fields[0], fields[1:], true
+}
+
+func parseCNAMEEntry(entry string) (string, string, endpoint.TTL, bool) {
+	fields := strings.Split(entry, ",")
+	if len(fields) < 2 {
+		return "", "", 0, false
+	}
+	var ttl endpoint.TTL
+	if len(fields) > 2 {
+		if v, err := strconv.ParseInt(strings.TrimSpace(fields[2]), 10, 64); err == nil {
+			ttl = endpoint.TTL(v)
+		}
+	}
+	return strings.TrimSpace(fields[0]), strings.TrimSpace(fields[1]), ttl, true
+}
+
+func formatCNAMEEntry(name, target string, ttl endpoint.TTL) string {
+	if ttl.IsConfigured() {
+		return fmt.Sprintf("%s,%s,%d", name, target, ttl)
+	}
+	return fmt.Sprintf("%s,%s", name, target)
+}
+
+// removeCNAMEEntry removes the entries of the given name and target, whatever their TTL.
+func removeCNAMEEntry(entries []string, name, target string) ([]string, bool) {
+	out := make([]string, 0, len(entries))
+	removed := false
+	for _, entry := range entries {
+		n, t, _, ok := parseCNAMEEntry(entry)
+		if ok && n == name && t == target {
+			removed = true
+			continue
+		}
+		out = append(out, entry)
+	}
+	return out, removed
+}
+
+func containsEntry(entries []string, entry string) bool {
+	for _, e := range entries {
+		if e == entry {
+			return true
+		}
+	}
+	return false
+}
+
+// hostsEntries is an editable list of hosts entries, keeping the order of the addresses.
+type hostsEntries struct {
+	ips   []string
+	names map[string][]string
+}
+
+func newHostsEntries(entries []string) *hostsEntries {
+	h := &hostsEntries{names: make(map[string][]string)}
+	for _, entry := range entries {
+		ip, names, ok := parseHostsEntry(entry)
+		if !ok {
+			continue
+		}
+		for _, name := range names {
+			h.add(ip, name)
+		}
+	}
+	return h
+}
+
+func (h *hostsEntries) add(ip, name string) bool {
+	names, ok := h.names[ip]
+	if !ok {
+		h.ips = append(h.ips, ip)
+	}
+	for _, n := range names {
+		if n == name {
+			return false
+		}
+	}
+	h.names[ip] = append(names, name)
+	return true
+}
+
+func (h *hostsEntries) remove(ip, name string) bool {
+	names := h.names[ip]
+	for i, n := range names {
+		if n == name {
+			h.names[ip] = append(names[:i:i], names[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (h *hostsEntries) entries() []string {
+	out := make([]string, 0, len(h.ips))
+	for _, ip := range h.ips {
+		if names := h.names[ip]; len(names) > 0 {
+			out = append(out, ip+" "+strings.Join(names, " "))
+		}
+	}
+	return out
+}
@@ -0,0 +1,251 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pihole
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+// fakePiholeV6 is a minimal Pi-hole v6 server holding the local DNS configuration.
+type fakePiholeV6 struct {
+	password string
+	sid      string
+	logins   int
+	patches  int
+	dns      piholeDNSConfig
+}
+
+func (f *fakePiholeV6) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/api/auth" && r.Method == http.MethodPost {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		res := piholeAuthResponseV6{}
+		if body["password"] == f.password {
+			f.logins++
+			f.sid = fmt.Sprintf("sid-%d", f.logins)
+			res.Session.Valid = true
+			res.Session.SID = f.sid
+		} else {
+			res.Session.Message = "password incorrect"
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+
+	if f.password != "" && r.Header.Get("X-FTL-SID") != f.sid {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":{"key":"unauthorized","message":"Unauthorized"}}`))
+		return
+	}
+
+	switch {
+	case r.URL.Path == "/api/config/dns" && r.Method == http.MethodGet:
+		res := piholeConfigV6{}
+		res.Config.DNS = f.dns
+		_ = json.NewEncoder(w).Encode(res)
+	case r.URL.Path == "/api/config" && r.Method == http.MethodPatch:
+		var req piholeConfigV6
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.patches++
+		f.dns = req.Config.DNS
+		_ = json.NewEncoder(w).Encode(req)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newTestPiholeV6(t *testing.T, fake *fakePiholeV6, cfg PiholeConfig) *piholeClientV6 {
+	t.Helper()
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	cfg.Server = srv.URL
+	cl, err := newPiholeClientV6(cfg)
+	require.NoError(t, err)
+	return cl.(*piholeClientV6)
+}
+
+func TestNewPiholeClientV6(t *testing.T) {
+	_, err := newPiholeClientV6(PiholeConfig{})
+	assert.ErrorIs(t, err, ErrNoPiholeServer)
+
+	fake := &fakePiholeV6{password: "correct"}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	_, err = newPiholeClientV6(PiholeConfig{Server: srv.URL, Password: "wrong"})
+	assert.Error(t, err)
+
+	cl, err := newPiholeClientV6(PiholeConfig{Server: srv.URL, Password: "correct"})
+	require.NoError(t, err)
+	assert.Equal(t, "sid-1", cl.(*piholeClientV6).sid)
+}
+
+func TestListRecordsV6(t *testing.T) {
+	fake := &fakePiholeV6{dns: piholeDNSConfig{
+		Hosts: []string{
+			"192.168.1.1 test1.example.com test2.example.com",
+			"192.168.1.2 test1.example.com",
+			"fc00::1 test1.example.com",
+			"192.168.1.3 test.other.org",
+		},
+		CNAMERecords: []string{
+			"cname1.example.com,test1.example.com",
+			"cname2.example.com,test2.example.com,300",
+		},
+	}}
+	cl := newTestPiholeV6(t, fake, PiholeConfig{DomainFilter: endpoint.NewDomainFilter([]string{"example.com"})})
+	ctx := context.Background()
+
+	records, err := cl.listRecords(ctx, endpoint.RecordTypeA)
+	require.NoError(t, err)
+	assert.Equal(t, []*endpoint.Endpoint{
+		endpoint.NewEndpoint("test1.example.com", endpoint.RecordTypeA, "192.168.1.1", "192.168.1.2"),
+		endpoint.NewEndpoint("test2.example.com", endpoint.RecordTypeA, "192.168.1.1"),
+	}, records)
+
+	records, err = cl.listRecords(ctx, endpoint.RecordTypeAAAA)
+	require.NoError(t, err)
+	assert.Equal(t, []*endpoint.Endpoint{
+		endpoint.NewEndpoint("test1.example.com", endpoint.RecordTypeAAAA, "fc00::1"),
+	}, records)
+
+	records, err = cl.listRecords(ctx, endpoint.RecordTypeCNAME)
+	require.NoError(t, err)
+	assert.Equal(t, []*endpoint.Endpoint{
+		endpoint.NewEndpoint("cname1.example.com", endpoint.RecordTypeCNAME, "test1.example.com"),
+		endpoint.NewEndpointWithTTL("cname2.example.com", endpoint.RecordTypeCNAME, 300, "test2.example.com"),
+	}, records)
+
+	_, err = cl.listRecords(ctx, endpoint.RecordTypeTXT)
+	assert.Error(t, err)
+}
+
+func TestApplyChangesV6(t *testing.T) {
+	fake := &fakePiholeV6{dns: piholeDNSConfig{
+		Hosts: []string{
+			"192.168.1.1 test1.example.com test2.example.com",
+			"192.168.1.3 test.other.org",
+		},
+		CNAMERecords: []string{
+			"cname1.example.com,test1.example.com",
+			"cname.other.org,test.other.org",
+		},
+	}}
+	cl := newTestPiholeV6(t, fake, PiholeConfig{DomainFilter: endpoint.NewDomainFilter([]string{"example.com"})})
+
+	err := cl.applyChanges(context.Background(), []*endpoint.Endpoint{
+		endpoint.NewEndpoint("test1.example.com", endpoint.RecordTypeA, "192.168.1.1"),
+		endpoint.NewEndpoint("cname1.example.com", endpoint.RecordTypeCNAME, "test1.example.com"),
+		// outside of the domain filter
+		endpoint.NewEndpoint("test.other.org", endpoint.RecordTypeA, "192.168.1.3"),
+	}, []*endpoint.Endpoint{
+		endpoint.NewEndpoint("test1.example.com", endpoint.RecordTypeA, "192.168.1.10", "192.168.1.11"),
+		endpoint.NewEndpoint("test3.example.com", endpoint.RecordTypeAAAA, "fc00::3"),
+		endpoint.NewEndpointWithTTL("cname1.example.com", endpoint.RecordTypeCNAME, 60, "test2.example.com"),
+		// unsupported
+		endpoint.NewEndpoint("txt.example.com", endpoint.RecordTypeTXT, "text"),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, fake.patches)
+	assert.Equal(t, []string{
+		"192.168.1.1 test2.example.com",
+		"192.168.1.3 test.other.org",
+		"192.168.1.10 test1.example.com",
+		"192.168.1.11 test1.example.com",
+		"fc00::3 test3.example.com",
+	}, fake.dns.Hosts)
+	assert.Equal(t, []string{
+		"cname.other.org,test.other.org",
+		"cname1.example.com,test2.example.com,60",
+	}, fake.dns.CNAMERecords)
+
+	// applying the same state again is a no-op
+	err = cl.applyChanges(context.Background(), nil, []*endpoint.Endpoint{
+		endpoint.NewEndpoint("test3.example.com", endpoint.RecordTypeAAAA, "fc00::3"),
+		endpoint.NewEndpointWithTTL("cname1.example.com", endpoint.RecordTypeCNAME, 60, "test2.example.com"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, fake.patches)
+}
+
+func TestApplyChangesV6DryRun(t *testing.T) {
+	fake := &fakePiholeV6{dns: piholeDNSConfig{Hosts: []string{}, CNAMERecords: []string{}}}
+	cl := newTestPiholeV6(t, fake, PiholeConfig{DryRun: true})
+
+	err := cl.createRecord(context.Background(), endpoint.NewEndpoint("test.example.com", endpoint.RecordTypeA, "192.168.1.1"))
+	require.NoError(t, err)
+	assert.Equal(t, 0, fake.patches)
+	assert.Empty(t, fake.dns.Hosts)
+}
+
+func TestSessionExpiryV6(t *testing.T) {
+	fake := &fakePiholeV6{password: "correct", dns: piholeDNSConfig{Hosts: []string{"192.168.1.1 test.example.com"}}}
+	cl := newTestPiholeV6(t, fake, PiholeConfig{Password: "correct"})
+
+	// expire the session of the client
+	fake.sid = "expired"
+
+	records, err := cl.listRecords(context.Background(), endpoint.RecordTypeA)
+	require.NoError(t, err)
+	assert.Len(t, records, 1)
+	assert.Equal(t, 2, fake.logins)
+}
+
+func TestPiholeProviderV6(t *testing.T) {
+	fake := &fakePiholeV6{dns: piholeDNSConfig{
+		Hosts:        []string{"192.168.1.1 test1.example.com", "fc00::1 test1.example.com"},
+		CNAMERecords: []string{"cname1.example.com,test1.example.com"},
+	}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	p, err := NewPiholeProvider(PiholeConfig{Server: srv.URL, APIVersion: "6"})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	records, err := p.Records(ctx)
+	require.NoError(t, err)
+	assert.Len(t, records, 3)
+
+	// all changes are applied with a single update
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{
+		Create:    []*endpoint.Endpoint{endpoint.NewEndpoint("test2.example.com", endpoint.RecordTypeA, "192.168.1.2")},
+		UpdateOld: []*endpoint.Endpoint{endpoint.NewEndpoint("test1.example.com", endpoint.RecordTypeAAAA, "fc00::1")},
+		UpdateNew: []*endpoint.Endpoint{endpoint.NewEndpoint("test1.example.com", endpoint.RecordTypeAAAA, "fc00::2")},
+		Delete:    []*endpoint.Endpoint{endpoint.NewEndpoint("cname1.example.com", endpoint.RecordTypeCNAME, "test1.example.com")},
+	}))
+	assert.Equal(t, 1, fake.patches)
+	assert.Equal(t, []string{"192.168.1.1 test1.example.com", "192.168.1.2 test2.example.com", "fc00::2 test1.example.com"}, fake.dns.Hosts)
+	assert.Empty(t, fake.dns.CNAMERecords)
+}
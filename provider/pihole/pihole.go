@@ -32,7 +32,8 @@ var ErrNoPiholeServer = errors.New("no pihole server found in the environment or
 // PiholeProvider is an implementation of Provider for Pi-hole Local DNS.
 type PiholeProvider struct {
 	provider.BaseProvider
-	api piholeAPI
+	api         piholeAPI
+	recordTypes []string
 }
 
 // PiholeConfig is used for configuring a PiholeProvider.
@@ -47,6 +48,8 @@ type PiholeConfig struct {
 	DomainFilter endpoint.DomainFilter
 	// Do nothing and log what would have changed to stdout.
 	DryRun bool
+	// The version of the Pi-hole API, 5 for the PHP scripts of the web interface or 6 for the REST API.
+	APIVersion string
 }
 
 // Helper struct for de-duping DNS entry updates.
@@ -57,29 +60,54 @@ type piholeEntryKey struct {
 
 // NewPiholeProvider initializes a new Pi-hole Local DNS based Provider.
 func NewPiholeProvider(cfg PiholeConfig) (*PiholeProvider, error) {
+	if cfg.APIVersion == "6" {
+		api, err := newPiholeClientV6(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return &PiholeProvider{
+			api:         api,
+			recordTypes: []string{endpoint.RecordTypeA, endpoint.RecordTypeAAAA, endpoint.RecordTypeCNAME},
+		}, nil
+	}
+
 	api, err := newPiholeClient(cfg)
 	if err != nil {
 		return nil, err
 	}
-	return &PiholeProvider{api: api}, nil
+	return &PiholeProvider{
+		api:         api,
+		recordTypes: []string{endpoint.RecordTypeA, endpoint.RecordTypeCNAME},
+	}, nil
 }
 
 // Records implements Provider, populating a slice of endpoints from
 // Pi-Hole local DNS.
 func (p *PiholeProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
-	aRecords, err := p.api.listRecords(ctx, endpoint.RecordTypeA)
-	if err != nil {
-		return nil, err
+	recordTypes := p.recordTypes
+	if len(recordTypes) == 0 {
+		recordTypes = []string{endpoint.RecordTypeA, endpoint.RecordTypeCNAME}
 	}
-	cnameRecords, err := p.api.listRecords(ctx, endpoint.RecordTypeCNAME)
-	if err != nil {
-		return nil, err
+
+	endpoints := make([]*endpoint.Endpoint, 0)
+	for _, rtype := range recordTypes {
+		records, err := p.api.listRecords(ctx, rtype)
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, records...)
 	}
-	return append(aRecords, cnameRecords...), nil
+	return endpoints, nil
 }
 
 // ApplyChanges implements Provider, syncing desired state with the Pi-hole server Local DNS.
 func (p *PiholeProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	if batch, ok := p.api.(piholeBatchAPI); ok {
+		deletes := append(append([]*endpoint.Endpoint{}, changes.Delete...), changes.UpdateOld...)
+		creates := append(append([]*endpoint.Endpoint{}, changes.Create...), changes.UpdateNew...)
+		return batch.applyChanges(ctx, deletes, creates)
+	}
+
 	// Handle pure deletes first.
 	for _, ep := range changes.Delete {
 		if err := p.api.deleteRecord(ctx, ep); err != nil {
@@ -0,0 +1,156 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PropertyComparer is implemented by providers that compare the values of their provider specific
+// properties semantically, e.g. because the values returned by Records are normalized by the API
+// and differ textually from equivalent values of the annotations, like "1" and "true".
+// The plan uses it instead of string equality to decide whether a record needs to be updated.
+type PropertyComparer interface {
+	// PropertyValuesEqual reports whether the previous and current values of the property are equivalent.
+	PropertyValuesEqual(name string, previous string, current string) bool
+}
+
+// PropertyValuesEqual compares the values of the provider specific property with the provider,
+// if it implements PropertyComparer, and textually otherwise.
+func PropertyValuesEqual(p Provider, name string, previous string, current string) bool {
+	if comparer, ok := p.(PropertyComparer); ok {
+		return comparer.PropertyValuesEqual(name, previous, current)
+	}
+	return previous == current
+}
+
+// PropertyNormalizer returns the canonical form of the value of a provider specific property.
+// It fails for values that aren't valid for the property.
+type PropertyNormalizer func(value string) (string, error)
+
+// PropertySchema maps the names of provider specific properties to the normalizers of their
+// values. It implements PropertyComparer, providers can embed it or delegate to it.
+type PropertySchema map[string]PropertyNormalizer
+
+// PropertyValuesEqual compares the values normalized by the normalizer of the property. Values of
+// properties without a normalizer and invalid values are compared textually.
+func (s PropertySchema) PropertyValuesEqual(name string, previous string, current string) bool {
+	if previous == current {
+		return true
+	}
+	normalize, ok := s[name]
+	if !ok {
+		return false
+	}
+	p, err := normalize(previous)
+	if err != nil {
+		return false
+	}
+	c, err := normalize(current)
+	if err != nil {
+		return false
+	}
+	return p == c
+}
+
+// Normalize returns the canonical form of the value of the property, the value itself for
+// properties without a normalizer and invalid values.
+func (s PropertySchema) Normalize(name string, value string) string {
+	if normalize, ok := s[name]; ok {
+		if normalized, err := normalize(value); err == nil {
+			return normalized
+		}
+	}
+	return value
+}
+
+// BoolProperty normalizes booleans, e.g. "1", "t" and "True" to "true".
+func BoolProperty(value string) (string, error) {
+	b, err := strconv.ParseBool(strings.TrimSpace(value))
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatBool(b), nil
+}
+
+// IntProperty normalizes integers, e.g. "010" and "+10" to "10".
+func IntProperty(value string) (string, error) {
+	i, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(i, 10), nil
+}
+
+// FloatProperty normalizes floating point numbers, e.g. "1.50" and "1.5e0" to "1.5".
+func FloatProperty(value string) (string, error) {
+	f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64), nil
+}
+
+// CaseInsensitiveProperty normalizes values that are compared case insensitively, e.g. region codes.
+func CaseInsensitiveProperty(value string) (string, error) {
+	return strings.ToLower(strings.TrimSpace(value)), nil
+}
+
+// JSONProperty normalizes JSON documents, dropping insignificant whitespace and sorting the keys
+// of objects, so documents are equal when they are deeply equal.
+func JSONProperty(value string) (string, error) {
+	var v interface{}
+	decoder := json.NewDecoder(strings.NewReader(value))
+	decoder.UseNumber()
+	if err := decoder.Decode(&v); err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// SetProperty returns a normalizer of lists of values separated by the separator whose order
+// doesn't matter. The items are normalized with the normalizer of the items, if it's not nil.
+func SetProperty(separator string, item PropertyNormalizer) PropertyNormalizer {
+	return func(value string) (string, error) {
+		var items []string
+		for _, v := range strings.Split(value, separator) {
+			v = strings.TrimSpace(v)
+			if v == "" {
+				continue
+			}
+			if item != nil {
+				var err error
+				if v, err = item(v); err != nil {
+					return "", err
+				}
+			}
+			items = append(items, v)
+		}
+		sort.Strings(items)
+		return strings.Join(items, separator), nil
+	}
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+func TestPropertyNormalizers(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		normalizer PropertyNormalizer
+		value      string
+		expected   string
+		invalid    bool
+	}{
+		{name: "bool true", normalizer: BoolProperty, value: "1", expected: "true"},
+		{name: "bool false", normalizer: BoolProperty, value: " False ", expected: "false"},
+		{name: "bool invalid", normalizer: BoolProperty, value: "yes", invalid: true},
+		{name: "int", normalizer: IntProperty, value: "+010", expected: "10"},
+		{name: "int invalid", normalizer: IntProperty, value: "1.5", invalid: true},
+		{name: "float", normalizer: FloatProperty, value: "1.50", expected: "1.5"},
+		{name: "float exponent", normalizer: FloatProperty, value: "15e-1", expected: "1.5"},
+		{name: "case insensitive", normalizer: CaseInsensitiveProperty, value: "EU-West-1", expected: "eu-west-1"},
+		{name: "json", normalizer: JSONProperty, value: `{ "b": [1, 2.0], "a": {"y": "<>", "x": null} }`, expected: `{"a":{"x":null,"y":"<>"},"b":[1,2.0]}`},
+		{name: "json invalid", normalizer: JSONProperty, value: `{"a":`, invalid: true},
+		{name: "set", normalizer: SetProperty(",", nil), value: "b, a,,c", expected: "a,b,c"},
+		{name: "set of ints", normalizer: SetProperty(",", IntProperty), value: "10,02", expected: "10,2"},
+		{name: "set of invalid ints", normalizer: SetProperty(",", IntProperty), value: "10,a", invalid: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			normalized, err := tc.normalizer(tc.value)
+			if tc.invalid {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, normalized)
+		})
+	}
+}
+
+func TestPropertySchema(t *testing.T) {
+	schema := PropertySchema{
+		"bool":   BoolProperty,
+		"weight": IntProperty,
+	}
+
+	assert.True(t, schema.PropertyValuesEqual("bool", "1", "true"))
+	assert.False(t, schema.PropertyValuesEqual("bool", "1", "false"))
+	assert.True(t, schema.PropertyValuesEqual("weight", "010", "10"))
+	assert.True(t, schema.PropertyValuesEqual("weight", "invalid", "invalid"))
+	assert.False(t, schema.PropertyValuesEqual("weight", "invalid", "10"))
+	assert.True(t, schema.PropertyValuesEqual("unknown", "1", "1"))
+	assert.False(t, schema.PropertyValuesEqual("unknown", "1", "true"))
+
+	assert.Equal(t, "true", schema.Normalize("bool", "T"))
+	assert.Equal(t, "invalid", schema.Normalize("bool", "invalid"))
+	assert.Equal(t, "T", schema.Normalize("unknown", "T"))
+}
+
+type textualProvider struct {
+	BaseProvider
+}
+
+func (p textualProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	return nil, nil
+}
+
+func (p textualProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	return nil
+}
+
+type comparingProvider struct {
+	textualProvider
+	PropertySchema
+}
+
+func TestPropertyValuesEqual(t *testing.T) {
+	p := comparingProvider{PropertySchema: PropertySchema{"bool": BoolProperty}}
+	assert.True(t, PropertyValuesEqual(p, "bool", "1", "true"))
+	assert.False(t, PropertyValuesEqual(p, "other", "1", "true"))
+
+	assert.False(t, PropertyValuesEqual(textualProvider{}, "bool", "1", "true"))
+	assert.True(t, PropertyValuesEqual(textualProvider{}, "bool", "true", "true"))
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+var readOnlyDroppedChanges = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Namespace: "external_dns",
+		Subsystem: "provider",
+		Name:      "read_only_dropped_changes_total",
+		Help:      "Number of changes not sent to the DNS provider because it is read-only.",
+	},
+)
+
+func init() {
+	prometheus.MustRegister(readOnlyDroppedChanges)
+}
+
+// ReadOnlyProvider lists the records of the provider it wraps and never sends it any change, so
+// that nothing using it, the registry included, can modify the records.
+type ReadOnlyProvider struct {
+	Provider
+}
+
+// NewReadOnlyProvider wraps the provider to drop all the changes sent to it.
+func NewReadOnlyProvider(p Provider) *ReadOnlyProvider {
+	return &ReadOnlyProvider{Provider: p}
+}
+
+// ApplyChanges drops the changes without sending them to the wrapped provider.
+func (p *ReadOnlyProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	dropped := len(changes.Create) + len(changes.UpdateNew) + len(changes.Delete)
+	if dropped > 0 {
+		log.Debugf("Dropping %d changes of the read-only DNS provider", dropped)
+		readOnlyDroppedChanges.Add(float64(dropped))
+	}
+	return nil
+}
+
+// RecordsPage returns the page of the records of the wrapped provider.
+func (p *ReadOnlyProvider) RecordsPage(ctx context.Context, pageToken string) ([]*endpoint.Endpoint, string, error) {
+	return RecordsPage(ctx, p.Provider, pageToken)
+}
+
+// ZoneNames returns the names of the zones of the wrapped provider.
+func (p *ReadOnlyProvider) ZoneNames(ctx context.Context) ([]string, error) {
+	return ZoneNames(ctx, p.Provider)
+}
+
+// PropertyValuesEqual compares the values of provider specific properties as the wrapped provider does.
+func (p *ReadOnlyProvider) PropertyValuesEqual(name string, previous string, current string) bool {
+	return PropertyValuesEqual(p.Provider, name, previous, current)
+}
+
+// Unwrap returns the wrapped provider.
+func (p *ReadOnlyProvider) Unwrap() Provider {
+	return p.Provider
+}
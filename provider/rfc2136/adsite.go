@@ -0,0 +1,101 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rfc2136
+
+import (
+	"context"
+	"errors"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// the domain controllers of the Active Directory site are discovered again after this interval
+const siteDiscoveryInterval = 5 * time.Minute
+
+// siteDiscovery discovers the domain controllers of an Active Directory site from the SRV records
+// they register, _ldap._tcp.<site>._sites.dc._msdcs.<domain>, followed by the other domain
+// controllers of the domain, so that the updates are sent to the closest ones first.
+type siteDiscovery struct {
+	mu sync.Mutex
+	// port of the DNS servers of the domain controllers
+	port      string
+	site      string
+	domain    string
+	lookupSRV func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+	servers   []string
+	at        time.Time
+}
+
+func newSiteDiscovery(port, site, domain string, lookupSRV func(context.Context, string, string, string) (string, []*net.SRV, error)) *siteDiscovery {
+	return &siteDiscovery{
+		port:      port,
+		site:      site,
+		domain:    strings.TrimSuffix(domain, "."),
+		lookupSRV: lookupSRV,
+	}
+}
+
+// domainControllers returns the addresses of the DNS servers of the domain controllers of the site,
+// then of the domain, in the order of the priorities and weights of their SRV records, discovered
+// again every siteDiscoveryInterval. The last ones discovered are returned when the discovery fails.
+func (d *siteDiscovery) domainControllers() ([]string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.servers) > 0 && time.Since(d.at) < siteDiscoveryInterval {
+		return d.servers, nil
+	}
+
+	var servers []string
+	var errs []error
+	for _, name := range []string{"_ldap._tcp." + d.site + "._sites.dc._msdcs." + d.domain, "_ldap._tcp.dc._msdcs." + d.domain} {
+		ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+		_, srvs, err := d.lookupSRV(ctx, "", "", name)
+		cancel()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, srv := range srvs {
+			server := net.JoinHostPort(strings.TrimSuffix(srv.Target, "."), d.port)
+			if !slices.Contains(servers, server) {
+				servers = append(servers, server)
+			}
+		}
+	}
+	if len(servers) == 0 {
+		err := errors.Join(errs...)
+		if err == nil {
+			err = errors.New("no domain controller registered")
+		}
+		if len(d.servers) > 0 {
+			log.Warnf("Failed to discover the domain controllers of the site %s, sending the updates to the last ones discovered: %v", d.site, err)
+			return d.servers, nil
+		}
+		return nil, err
+	}
+
+	if !slices.Equal(d.servers, servers) {
+		log.Infof("Sending the updates to the domain controllers of the site %s: %s", d.site, strings.Join(servers, ", "))
+	}
+	d.servers, d.at = servers, time.Now()
+	return servers, nil
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rfc2136
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSiteDiscovery(t *testing.T) {
+	lookups := 0
+	records := map[string][]*net.SRV{
+		"_ldap._tcp.Paris._sites.dc._msdcs.corp.example.org": {{Target: "dc1.corp.example.org."}, {Target: "dc2.corp.example.org."}},
+		"_ldap._tcp.dc._msdcs.corp.example.org":              {{Target: "dc3.corp.example.org."}, {Target: "dc1.corp.example.org."}},
+	}
+	var failure error
+	d := newSiteDiscovery("53", "Paris", "corp.example.org.", func(_ context.Context, service, proto, name string) (string, []*net.SRV, error) {
+		lookups++
+		if failure != nil {
+			return "", nil, failure
+		}
+		return name, records[name], nil
+	})
+
+	servers, err := d.domainControllers()
+	require.NoError(t, err)
+	// the domain controllers of the site come first
+	assert.Equal(t, []string{"dc1.corp.example.org:53", "dc2.corp.example.org:53", "dc3.corp.example.org:53"}, servers)
+
+	// the domain controllers are discovered again after an interval
+	_, err = d.domainControllers()
+	require.NoError(t, err)
+	assert.Equal(t, 2, lookups)
+	d.at = time.Now().Add(-siteDiscoveryInterval)
+
+	// the last domain controllers discovered are kept when the discovery fails
+	failure = errors.New("no such host")
+	servers, err = d.domainControllers()
+	require.NoError(t, err)
+	assert.Equal(t, 4, lookups)
+	assert.Equal(t, []string{"dc1.corp.example.org:53", "dc2.corp.example.org:53", "dc3.corp.example.org:53"}, servers)
+
+	d = newSiteDiscovery("53", "Paris", "corp.example.org", d.lookupSRV)
+	_, err = d.domainControllers()
+	assert.ErrorContains(t, err, "no such host")
+}
+
+func TestRfc2136ServersOfSite(t *testing.T) {
+	p, err := NewRfc2136Provider(RFC2136Config{Host: "ns2.example.org", Port: 53, Zones: []string{"example.org"}, Insecure: true, KerberosRealm: "CORP.EXAMPLE.ORG", BatchChangeSize: 50, FallbackHosts: []string{"192.0.2.1"}, ADSite: "Paris"}, nil)
+	require.NoError(t, err)
+	r := p.(*rfc2136Provider)
+	// the domain defaults to the Kerberos realm
+	assert.Equal(t, "corp.example.org", r.domainControllers.domain)
+	r.domainControllers.lookupSRV = func(_ context.Context, service, proto, name string) (string, []*net.SRV, error) {
+		if name == "_ldap._tcp.Paris._sites.dc._msdcs.corp.example.org" {
+			return name, []*net.SRV{{Target: "dc1.corp.example.org."}, {Target: "ns2.example.org."}}, nil
+		}
+		return "", nil, errors.New("no such host")
+	}
+
+	assert.Equal(t, []string{"dc1.corp.example.org:53", "ns2.example.org:53", "192.0.2.1:53"}, r.servers("example.org."))
+
+	// the updates are sent to the configured server when the discovery fails
+	r.domainControllers.lookupSRV = func(context.Context, string, string, string) (string, []*net.SRV, error) {
+		return "", nil, errors.New("no such host")
+	}
+	r.domainControllers.servers = nil
+	assert.Equal(t, []string{"ns2.example.org:53", "192.0.2.1:53"}, r.servers("example.org."))
+}
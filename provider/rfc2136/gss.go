@@ -0,0 +1,199 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rfc2136
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/miekg/dns"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// number of attempts of the TKEY negotiation of a GSS-TSIG context
+	tkeyAttempts = 3
+	// a GSS-TSIG context is negotiated again this long before it expires
+	gssContextRenewal = time.Minute
+)
+
+// tkeyRetryDelay is the delay before retrying a failed TKEY negotiation, doubled on every retry.
+var tkeyRetryDelay = time.Second
+
+// errNotAuth is the error of the messages the server answered with NOTAUTH, e.g. because their
+// signature was rejected.
+var errNotAuth = errors.New(dns.RcodeToString[dns.RcodeNotAuth])
+
+// gssHandle signs and verifies the messages of the GSS-TSIG contexts it negotiated.
+type gssHandle interface {
+	dns.TsigProvider
+	DeleteContext(keyName string) error
+	Close() error
+}
+
+// gssContext is a GSS-TSIG context negotiated with the server with TKEY, shared by the messages
+// until it is about to expire or the server rejects it, then negotiated again, so the provider
+// outlives the lifetime of the Kerberos tickets.
+type gssContext struct {
+	mu        sync.Mutex
+	negotiate func() (keyName string, handle gssHandle, expires time.Time, err error)
+	keyName   string
+	handle    gssHandle
+	// expires is zero if the server did not tell when the context expires
+	expires time.Time
+}
+
+func newGSSContext(negotiate func() (string, gssHandle, time.Time, error)) *gssContext {
+	return &gssContext{negotiate: negotiate}
+}
+
+// gssContexts are the GSS-TSIG contexts negotiated with each of the servers the messages are sent
+// to, as a context is only known to the server it was negotiated with.
+type gssContexts struct {
+	mu        sync.Mutex
+	negotiate func(server string) (keyName string, handle gssHandle, expires time.Time, err error)
+	contexts  map[string]*gssContext
+}
+
+func newGSSContexts(negotiate func(string) (string, gssHandle, time.Time, error)) *gssContexts {
+	return &gssContexts{negotiate: negotiate, contexts: map[string]*gssContext{}}
+}
+
+// context returns the context of the server, negotiated on its first use.
+func (g *gssContexts) context(server string) *gssContext {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	c, ok := g.contexts[server]
+	if !ok {
+		c = newGSSContext(func() (string, gssHandle, time.Time, error) { return g.negotiate(server) })
+		g.contexts[server] = c
+	}
+	return c
+}
+
+// send calls exchange with the context, negotiating one first if needed. When the server rejects
+// the message, e.g. because the context expired on its side, a new context is negotiated and
+// exchange is called again, once. The messages are sent one at a time, as the sequence numbers of
+// the signatures of a context must increase.
+func (g *gssContext) send(exchange func(keyName string, handle gssHandle) error) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err := g.establish(time.Now()); err != nil {
+		return err
+	}
+	err := exchange(g.keyName, g.handle)
+	if err == nil || !isRejected(err) {
+		return err
+	}
+	log.Warnf("The GSS-TSIG context %s was rejected, negotiating a new one: %v", g.keyName, err)
+	g.reset()
+	if err := g.establish(time.Now()); err != nil {
+		return err
+	}
+	return exchange(g.keyName, g.handle)
+}
+
+// establish negotiates a context if there is none or the current one is about to expire, retrying
+// the failed negotiations with backoff.
+func (g *gssContext) establish(now time.Time) error {
+	if g.handle != nil && (g.expires.IsZero() || now.Add(gssContextRenewal).Before(g.expires)) {
+		return nil
+	}
+	if g.handle != nil {
+		log.Infof("The GSS-TSIG context %s expires at %s, negotiating a new one", g.keyName, g.expires.Format(time.RFC3339))
+		g.reset()
+	}
+
+	delay := tkeyRetryDelay
+	for attempt := 1; ; attempt++ {
+		keyName, handle, expires, err := g.negotiate()
+		if err == nil {
+			log.Debugf("Negotiated the GSS-TSIG context %s expiring at %s", keyName, expires.Format(time.RFC3339))
+			g.keyName, g.handle, g.expires = keyName, handle, expires
+			return nil
+		}
+		if attempt == tkeyAttempts {
+			return fmt.Errorf("failed to negotiate a GSS-TSIG context after %d attempts: %w", attempt, err)
+		}
+		log.Warnf("Failed to negotiate a GSS-TSIG context, retrying in %s: %v", delay, err)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// reset deletes the current context, if any.
+func (g *gssContext) reset() {
+	if g.handle == nil {
+		return
+	}
+	if err := g.handle.DeleteContext(g.keyName); err != nil {
+		log.Debugf("Failed to delete the GSS-TSIG context %s: %v", g.keyName, err)
+	}
+	if err := g.handle.Close(); err != nil {
+		log.Debugf("Failed to close the GSS-TSIG client: %v", err)
+	}
+	g.keyName, g.handle, g.expires = "", nil, time.Time{}
+}
+
+// isRejected returns whether the server rejected the signature of a message.
+func isRejected(err error) bool {
+	return errors.Is(err, errNotAuth) || errors.Is(err, dns.ErrSig) || errors.Is(err, dns.ErrTime) ||
+		errors.Is(err, dns.ErrAuth) || errors.Is(err, dns.ErrSecret)
+}
+
+// tsigClock is the time the messages are signed at, corrected by the offset of the clock of the
+// server learnt from the BADTIME errors, so the signatures are accepted despite a clock drift.
+type tsigClock struct {
+	offset atomic.Int64
+}
+
+// now returns the time of the server in seconds since the epoch.
+func (c *tsigClock) now() int64 {
+	return time.Now().Unix() + c.offset.Load()
+}
+
+// adjust learns the offset of the clock of the server from the TSIG of a BADTIME response.
+func (c *tsigClock) adjust(resp *dns.Msg) {
+	t := resp.IsTsig()
+	if t == nil || t.Error != dns.RcodeBadTime {
+		return
+	}
+	// the time of the server is in the other data, on 48 bits, see RFC 8945 section 5.2.3
+	serverTime := t.TimeSigned
+	if other, err := hex.DecodeString(t.OtherData); err == nil && len(other) == 6 {
+		serverTime = 0
+		for _, b := range other {
+			serverTime = serverTime<<8 | uint64(b)
+		}
+	}
+	offset := int64(serverTime) - time.Now().Unix()
+	log.Warnf("The clock of the DNS server is %s off, signing the next messages at its time", time.Duration(offset)*time.Second)
+	c.offset.Store(offset)
+}
+
+// setTsig replaces the TSIG of the message, e.g. of a message sent again with a new context.
+func setTsig(msg *dns.Msg, keyName, algorithm string, fudge uint16, timeSigned int64) {
+	if msg.IsTsig() != nil {
+		msg.Extra = msg.Extra[:len(msg.Extra)-1]
+	}
+	msg.SetTsig(keyName, algorithm, fudge, timeSigned)
+}
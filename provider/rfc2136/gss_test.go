@@ -0,0 +1,193 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rfc2136
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeGSSHandle struct {
+	dns.TsigProvider
+	deleted []string
+	closed  bool
+}
+
+func (h *fakeGSSHandle) DeleteContext(keyName string) error {
+	h.deleted = append(h.deleted, keyName)
+	return nil
+}
+
+func (h *fakeGSSHandle) Close() error {
+	h.closed = true
+	return nil
+}
+
+type fakeNegotiator struct {
+	failures int
+	expires  time.Time
+	handles  []*fakeGSSHandle
+}
+
+func (n *fakeNegotiator) negotiate() (string, gssHandle, time.Time, error) {
+	if n.failures > 0 {
+		n.failures--
+		return "", nil, time.Time{}, errors.New("KDC unreachable")
+	}
+	handle := &fakeGSSHandle{}
+	n.handles = append(n.handles, handle)
+	return fmt.Sprintf("key-%d.", len(n.handles)), handle, n.expires, nil
+}
+
+func TestGSSContextSend(t *testing.T) {
+	n := &fakeNegotiator{expires: time.Now().Add(time.Hour)}
+	g := newGSSContext(n.negotiate)
+
+	var keyNames []string
+	send := func(err error) func(string, gssHandle) error {
+		return func(keyName string, _ gssHandle) error {
+			keyNames = append(keyNames, keyName)
+			return err
+		}
+	}
+
+	// the context is shared by the messages
+	require.NoError(t, g.send(send(nil)))
+	require.NoError(t, g.send(send(nil)))
+	assert.Equal(t, []string{"key-1.", "key-1."}, keyNames)
+
+	// the errors other than rejections are returned
+	keyNames = nil
+	assert.EqualError(t, g.send(send(errors.New("connection refused"))), "connection refused")
+	assert.Equal(t, []string{"key-1."}, keyNames)
+
+	// a rejected context is deleted and the message is sent again once with a new context
+	keyNames = nil
+	rejected := fmt.Errorf("bad return code: %w", errNotAuth)
+	assert.ErrorIs(t, g.send(send(rejected)), errNotAuth)
+	assert.Equal(t, []string{"key-1.", "key-2."}, keyNames)
+	assert.Equal(t, []string{"key-1."}, n.handles[0].deleted)
+	assert.True(t, n.handles[0].closed)
+
+	keyNames = nil
+	calls := 0
+	require.NoError(t, g.send(func(keyName string, _ gssHandle) error {
+		keyNames = append(keyNames, keyName)
+		calls++
+		if calls == 1 {
+			return dns.ErrSig
+		}
+		return nil
+	}))
+	assert.Equal(t, []string{"key-2.", "key-3."}, keyNames)
+}
+
+func TestGSSContextRenewal(t *testing.T) {
+	n := &fakeNegotiator{expires: time.Now().Add(30 * time.Second)}
+	g := newGSSContext(n.negotiate)
+
+	require.NoError(t, g.establish(time.Now()))
+	assert.Len(t, n.handles, 1)
+
+	// the context is negotiated again before it expires
+	n.expires = time.Now().Add(time.Hour)
+	require.NoError(t, g.establish(time.Now()))
+	assert.Len(t, n.handles, 2)
+	assert.True(t, n.handles[0].closed)
+	require.NoError(t, g.establish(time.Now()))
+	assert.Len(t, n.handles, 2)
+
+	// the contexts without expiry are used until they are rejected
+	n.expires = time.Time{}
+	g.reset()
+	require.NoError(t, g.establish(time.Now()))
+	require.NoError(t, g.establish(time.Now().Add(24*time.Hour)))
+	assert.Len(t, n.handles, 3)
+}
+
+func TestGSSContextNegotiationRetries(t *testing.T) {
+	defer func(delay time.Duration) { tkeyRetryDelay = delay }(tkeyRetryDelay)
+	tkeyRetryDelay = 0
+
+	n := &fakeNegotiator{failures: tkeyAttempts - 1}
+	g := newGSSContext(n.negotiate)
+	require.NoError(t, g.establish(time.Now()))
+	assert.Equal(t, "key-1.", g.keyName)
+
+	n = &fakeNegotiator{failures: tkeyAttempts}
+	g = newGSSContext(n.negotiate)
+	assert.EqualError(t, g.establish(time.Now()), "failed to negotiate a GSS-TSIG context after 3 attempts: KDC unreachable")
+	assert.Nil(t, g.handle)
+}
+
+func TestGSSContextsPerServer(t *testing.T) {
+	var servers []string
+	n := &fakeNegotiator{expires: time.Now().Add(time.Hour)}
+	g := newGSSContexts(func(server string) (string, gssHandle, time.Time, error) {
+		servers = append(servers, server)
+		return n.negotiate()
+	})
+
+	// a context is negotiated with every server, then shared by its messages
+	for _, server := range []string{"dc1:53", "dc2:53", "dc1:53"} {
+		require.NoError(t, g.context(server).send(func(string, gssHandle) error { return nil }))
+	}
+	assert.Equal(t, []string{"dc1:53", "dc2:53"}, servers)
+	assert.Equal(t, "key-1.", g.context("dc1:53").keyName)
+	assert.Equal(t, "key-2.", g.context("dc2:53").keyName)
+}
+
+func TestTsigClockAdjust(t *testing.T) {
+	c := &tsigClock{}
+	now := time.Now().Unix()
+	assert.InDelta(t, now, c.now(), 1)
+
+	resp := new(dns.Msg)
+	resp.SetTsig("key.", dns.HmacSHA256, 300, now)
+	c.adjust(resp)
+	assert.InDelta(t, now, c.now(), 1)
+
+	serverTime := uint64(now + 600)
+	other := make([]byte, 6)
+	for i := range other {
+		other[5-i] = byte(serverTime >> (8 * i))
+	}
+	tsig := resp.IsTsig()
+	tsig.Error = dns.RcodeBadTime
+	tsig.OtherLen = 6
+	tsig.OtherData = hex.EncodeToString(other)
+	c.adjust(resp)
+	assert.InDelta(t, now+600, c.now(), 1)
+}
+
+func TestSetTsig(t *testing.T) {
+	msg := new(dns.Msg)
+	msg.SetUpdate("example.org.")
+	setTsig(msg, "key-1.", dns.HmacSHA256, 300, 1)
+	setTsig(msg, "key-2.", dns.HmacSHA256, 300, 2)
+
+	require.Len(t, msg.Extra, 1)
+	assert.Equal(t, "key-2.", msg.IsTsig().Hdr.Name)
+	assert.Equal(t, uint64(2), msg.IsTsig().TimeSigned)
+}
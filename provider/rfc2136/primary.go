@@ -0,0 +1,90 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rfc2136
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+	log "github.com/sirupsen/logrus"
+)
+
+// the primary master of a zone is discovered again after this interval
+const primaryDiscoveryInterval = 5 * time.Minute
+
+// primaryDiscovery discovers the primary masters of the zones from the MNAME of their SOA record,
+// the servers the updates should be sent to, see RFC 2136 section 4.
+type primaryDiscovery struct {
+	mu sync.Mutex
+	// port of the primary masters
+	port       string
+	querySOA   func(zone string) (*dns.SOA, error)
+	lookupHost func(ctx context.Context, host string) ([]string, error)
+	primaries  map[string]discoveredPrimary
+}
+
+type discoveredPrimary struct {
+	server string
+	at     time.Time
+}
+
+func newPrimaryDiscovery(port string, querySOA func(string) (*dns.SOA, error), lookupHost func(context.Context, string) ([]string, error)) *primaryDiscovery {
+	return &primaryDiscovery{
+		port:       port,
+		querySOA:   querySOA,
+		lookupHost: lookupHost,
+		primaries:  map[string]discoveredPrimary{},
+	}
+}
+
+// primary returns the address of the primary master of the zone, discovered again every
+// primaryDiscoveryInterval.
+func (d *primaryDiscovery) primary(zone string) (string, error) {
+	zone = dns.Fqdn(zone)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if p, ok := d.primaries[zone]; ok && time.Since(p.at) < primaryDiscoveryInterval {
+		return p.server, nil
+	}
+
+	soa, err := d.querySOA(zone)
+	if err != nil {
+		return "", fmt.Errorf("failed to query the SOA record: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+	defer cancel()
+	addrs, err := d.lookupHost(ctx, strings.TrimSuffix(soa.Ns, "."))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve the primary master %s: %w", soa.Ns, err)
+	}
+	if len(addrs) == 0 {
+		return "", fmt.Errorf("the primary master %s has no address", soa.Ns)
+	}
+
+	server := net.JoinHostPort(addrs[0], d.port)
+	if d.primaries[zone].server != server {
+		log.Infof("Sending the updates of the zone %s to its primary master %s (%s)", zone, soa.Ns, server)
+	}
+	d.primaries[zone] = discoveredPrimary{server: server, at: time.Now()}
+	return server, nil
+}
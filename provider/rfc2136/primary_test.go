@@ -0,0 +1,144 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rfc2136
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrimaryDiscovery(t *testing.T) {
+	queries := 0
+	d := newPrimaryDiscovery("53", func(zone string) (*dns.SOA, error) {
+		queries++
+		if zone == "broken.org." {
+			return nil, errors.New("connection refused")
+		}
+		return &dns.SOA{Ns: "ns1." + zone}, nil
+	}, func(_ context.Context, host string) ([]string, error) {
+		if host == "ns1.example.org" {
+			return []string{"192.0.2.1", "192.0.2.2"}, nil
+		}
+		return nil, nil
+	})
+
+	primary, err := d.primary("example.org")
+	require.NoError(t, err)
+	assert.Equal(t, "192.0.2.1:53", primary)
+
+	// the primary master is discovered again after an interval
+	_, err = d.primary("example.org.")
+	require.NoError(t, err)
+	assert.Equal(t, 1, queries)
+	d.primaries["example.org."] = discoveredPrimary{server: primary, at: time.Now().Add(-primaryDiscoveryInterval)}
+	_, err = d.primary("example.org.")
+	require.NoError(t, err)
+	assert.Equal(t, 2, queries)
+
+	_, err = d.primary("broken.org")
+	assert.EqualError(t, err, "failed to query the SOA record: connection refused")
+	_, err = d.primary("unresolved.org")
+	assert.EqualError(t, err, "the primary master ns1.unresolved.org. has no address")
+}
+
+// startUpdateServer starts a DNS server accepting the updates over TCP, counting them.
+func startUpdateServer(t *testing.T) (string, *atomic.Int32) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	updates := &atomic.Int32{}
+	server := &dns.Server{
+		Listener: listener,
+		// the default function doesn't accept the updates
+		MsgAcceptFunc: func(dns.Header) dns.MsgAcceptAction { return dns.MsgAccept },
+		Handler: dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+			updates.Add(1)
+			resp := new(dns.Msg)
+			resp.SetReply(req)
+			w.WriteMsg(resp)
+		}),
+	}
+	go server.ActivateAndServe()
+	t.Cleanup(func() { server.Shutdown() })
+	return listener.Addr().String(), updates
+}
+
+// closedAddress returns a local address nothing listens on.
+func closedAddress(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+	return addr
+}
+
+func TestRfc2136SendMessageFallback(t *testing.T) {
+	fallback, updates := startUpdateServer(t)
+	host, port, err := net.SplitHostPort(closedAddress(t))
+	require.NoError(t, err)
+	portNumber, err := strconv.Atoi(port)
+	require.NoError(t, err)
+
+	p, err := NewRfc2136Provider(RFC2136Config{Host: host, Port: portNumber, Zones: []string{"example.org"}, Insecure: true, BatchChangeSize: 50, FallbackHosts: []string{fallback}}, nil)
+	require.NoError(t, err)
+
+	msg := new(dns.Msg)
+	msg.SetUpdate("example.org.")
+	rr, err := dns.NewRR("app.example.org. 300 IN A 1.2.3.4")
+	require.NoError(t, err)
+	msg.Insert([]dns.RR{rr})
+
+	// the update is sent to the fallback when the server is unreachable
+	require.NoError(t, p.(*rfc2136Provider).SendMessage(msg))
+	assert.Equal(t, int32(1), updates.Load())
+
+	// the error of the last server is returned when every server is unreachable
+	p, err = NewRfc2136Provider(RFC2136Config{Host: host, Port: portNumber, Zones: []string{"example.org"}, Insecure: true, BatchChangeSize: 50, FallbackHosts: []string{closedAddress(t)}}, nil)
+	require.NoError(t, err)
+	var netErr net.Error
+	assert.ErrorAs(t, p.(*rfc2136Provider).SendMessage(msg), &netErr)
+}
+
+func TestRfc2136Servers(t *testing.T) {
+	p, err := NewRfc2136Provider(RFC2136Config{Host: "ns2.example.org", Port: 5353, Zones: []string{"example.org"}, Insecure: true, BatchChangeSize: 50, DiscoverPrimary: true, FallbackHosts: []string{"ns3.example.org", "192.0.2.1:53"}}, nil)
+	require.NoError(t, err)
+	r := p.(*rfc2136Provider)
+	r.primaries = newPrimaryDiscovery("5353", func(zone string) (*dns.SOA, error) {
+		return &dns.SOA{Ns: "ns1.example.org."}, nil
+	}, func(_ context.Context, host string) ([]string, error) {
+		return []string{"192.0.2.1"}, nil
+	})
+
+	assert.Equal(t, []string{"192.0.2.1:5353", "ns2.example.org:5353", "ns3.example.org:5353", "192.0.2.1:53"}, r.servers("example.org."))
+
+	// the updates are sent to the configured server when the discovery fails
+	r.primaries.querySOA = func(string) (*dns.SOA, error) { return nil, errors.New("timeout") }
+	r.primaries.primaries = map[string]discoveredPrimary{}
+	assert.Equal(t, []string{"ns2.example.org:5353", "ns3.example.org:5353", "192.0.2.1:53"}, r.servers("example.org."))
+}
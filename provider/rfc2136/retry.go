@@ -0,0 +1,121 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rfc2136
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+)
+
+// the delay between the retries of a message doubles up to this value
+const maxRetryBackoff = 30 * time.Second
+
+// errServFail is the error of the messages the server failed to apply, e.g. while it reloads.
+var errServFail = errors.New(dns.RcodeToString[dns.RcodeServerFailure])
+
+var (
+	sendRetriesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "external_dns",
+			Subsystem: "rfc2136",
+			Name:      "send_retries_total",
+			Help:      "Number of update messages sent again after a transient error.",
+		},
+		[]string{"zone"},
+	)
+	sendFailuresTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "external_dns",
+			Subsystem: "rfc2136",
+			Name:      "send_failures_total",
+			Help:      "Number of update messages that failed once the retries were exhausted.",
+		},
+		[]string{"zone"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(sendRetriesTotal)
+	prometheus.MustRegister(sendFailuresTotal)
+}
+
+// retryBudget is the number of retries left to the messages of a synchronization, so a server
+// that is down doesn't delay it by the retries of every message. It's shared by the changes of all
+// the zones and batches of the synchronization, which may be applied concurrently.
+type retryBudget struct {
+	remaining atomic.Int64
+}
+
+func newRetryBudget(retries int) *retryBudget {
+	b := &retryBudget{}
+	b.refill(retries)
+	return b
+}
+
+// refill resets the number of retries left at the start of a synchronization.
+func (b *retryBudget) refill(retries int) {
+	b.remaining.Store(int64(retries))
+}
+
+// take reserves a retry, it returns false when the budget is exhausted.
+func (b *retryBudget) take() bool {
+	return b.remaining.Add(-1) >= 0
+}
+
+// isTransient returns whether the message may succeed when it is sent again.
+func isTransient(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, errRefused) || errors.Is(err, errServFail)
+}
+
+// sendWithRetries sends the message, sending it again up to retries times after the transient
+// errors, with a delay doubling from retryBackoff, while the budget of the synchronization allows it.
+func (r rfc2136Provider) sendWithRetries(ctx context.Context, msg *dns.Msg) error {
+	zone := msg.Question[0].Name
+	backoff := r.retryBackoff
+	for attempt := 0; ; attempt++ {
+		err := r.actions.SendMessage(msg)
+		if err == nil {
+			return nil
+		}
+		if !isTransient(err) || attempt >= r.retries {
+			sendFailuresTotal.WithLabelValues(zone).Inc()
+			return err
+		}
+		if !r.retriesLeft.take() {
+			log.Warnf("Not sending the update of the zone %s again, the retries of the synchronization are exhausted", zone)
+			sendFailuresTotal.WithLabelValues(zone).Inc()
+			return err
+		}
+
+		log.Warnf("Failed to send the update of the zone %s, sending it again in %s: %v", zone, backoff, err)
+		sendRetriesTotal.WithLabelValues(zone).Inc()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, maxRetryBackoff)
+	}
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rfc2136
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+// flakyStub fails the first messages of the zones with their errors.
+type flakyStub struct {
+	*rfc2136Stub
+	mu       sync.Mutex
+	failures map[string]int
+	err      error
+	sent     map[string]int
+}
+
+func (s *flakyStub) SendMessage(msg *dns.Msg) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	zone := msg.Question[0].Name
+	s.sent[zone]++
+	if s.failures[zone] > 0 {
+		s.failures[zone]--
+		return s.err
+	}
+	return nil
+}
+
+func newFlakyProvider(t *testing.T, retries, budget int, err error, failures map[string]int) (*rfc2136Provider, *flakyStub) {
+	t.Helper()
+
+	stub := &flakyStub{rfc2136Stub: newStub(), failures: failures, err: err, sent: map[string]int{}}
+	p, perr := NewRfc2136Provider(RFC2136Config{Zones: []string{"foo.com", "foobar.com"}, TSIGKeyName: "key", TSIGSecret: "secret", TSIGSecretAlg: "hmac-sha512", AXFR: true, MinTTL: 300 * time.Second, BatchChangeSize: 50, Retries: retries, RetryBackoff: time.Millisecond, RetryBudget: budget}, stub)
+	require.NoError(t, perr)
+	return p.(*rfc2136Provider), stub
+}
+
+func createChanges() *plan.Changes {
+	return &plan.Changes{Create: []*endpoint.Endpoint{
+		endpoint.NewEndpoint("v1.foo.com", endpoint.RecordTypeA, "1.1.1.1"),
+		endpoint.NewEndpoint("v1.foobar.com", endpoint.RecordTypeA, "1.1.1.1"),
+	}}
+}
+
+func TestRfc2136Retries(t *testing.T) {
+	refused := fmt.Errorf("bad return code: %w", errRefused)
+
+	// the transient errors are retried
+	p, stub := newFlakyProvider(t, 2, 10, refused, map[string]int{"foo.com.": 2})
+	require.NoError(t, p.ApplyChanges(context.Background(), createChanges()))
+	assert.Equal(t, map[string]int{"foo.com.": 3, "foobar.com.": 1}, stub.sent)
+
+	// up to the maximum number of retries
+	p, stub = newFlakyProvider(t, 2, 10, refused, map[string]int{"foo.com.": 3})
+	assert.ErrorContains(t, p.ApplyChanges(context.Background(), createChanges()), "REFUSED")
+	assert.Equal(t, map[string]int{"foo.com.": 3, "foobar.com.": 1}, stub.sent)
+
+	// the other errors aren't retried
+	p, stub = newFlakyProvider(t, 2, 10, errors.New("bad return code: NXRRSET"), map[string]int{"foo.com.": 1})
+	assert.ErrorContains(t, p.ApplyChanges(context.Background(), createChanges()), "NXRRSET")
+	assert.Equal(t, map[string]int{"foo.com.": 1, "foobar.com.": 1}, stub.sent)
+}
+
+func TestRfc2136RetryBudget(t *testing.T) {
+	timeout := &net.OpError{Op: "read", Err: errors.New("i/o timeout")}
+
+	// the retries of the messages of a synchronization share the budget
+	p, stub := newFlakyProvider(t, 5, 3, timeout, map[string]int{"foo.com.": 5, "foobar.com.": 5})
+	assert.Error(t, p.ApplyChanges(context.Background(), createChanges()))
+	assert.Equal(t, 5, stub.sent["foo.com."]+stub.sent["foobar.com."])
+
+	// the budget is renewed every synchronization, when the records are read
+	stub.failures = map[string]int{"foo.com.": 3}
+	stub.sent = map[string]int{}
+	assert.Error(t, p.ApplyChanges(context.Background(), createChanges()))
+	assert.Equal(t, map[string]int{"foo.com.": 1, "foobar.com.": 1}, stub.sent, "the budget of the synchronization was spent")
+	stub.sent = map[string]int{}
+	_, err := p.Records(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, p.ApplyChanges(context.Background(), createChanges()))
+	assert.Equal(t, map[string]int{"foo.com.": 3, "foobar.com.": 1}, stub.sent)
+}
+
+func TestRfc2136RetryBudgetAcrossZones(t *testing.T) {
+	timeout := &net.OpError{Op: "read", Err: errors.New("i/o timeout")}
+
+	// the controller applies the changes of every zone separately, they share the budget of the synchronization
+	p, stub := newFlakyProvider(t, 5, 3, timeout, map[string]int{"foo.com.": 5, "foobar.com.": 5})
+	_, err := p.Records(context.Background())
+	require.NoError(t, err)
+	for _, ep := range createChanges().Create {
+		assert.Error(t, p.ApplyChanges(context.Background(), &plan.Changes{Create: []*endpoint.Endpoint{ep}}))
+	}
+	assert.Equal(t, map[string]int{"foo.com.": 4, "foobar.com.": 1}, stub.sent)
+}
+
+func TestIsTransient(t *testing.T) {
+	assert.True(t, isTransient(&net.OpError{Op: "dial", Err: errors.New("connection refused")}))
+	assert.True(t, isTransient(fmt.Errorf("bad return code: %w", errRefused)))
+	assert.True(t, isTransient(fmt.Errorf("bad return code: %w", errServFail)))
+	assert.False(t, isTransient(fmt.Errorf("bad return code: %w", errNotAuth)))
+	assert.False(t, isTransient(errors.New("bad return code: YXDOMAIN")))
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -33,33 +34,66 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/pkg/credentials"
 	"sigs.k8s.io/external-dns/plan"
 	"sigs.k8s.io/external-dns/provider"
 )
 
 const (
-	// maximum time DNS client can be off from server for an update to succeed
-	clockSkew = 300
+	// default maximum time DNS client can be off from server for an update to succeed, in seconds
+	defaultClockSkew = 300
+	// timeout of the connections to the server from a local address, the default of the DNS client
+	dialTimeout = 2 * time.Second
 )
 
 // rfc2136 provider type
 type rfc2136Provider struct {
 	provider.BaseProvider
-	nameserver      string
-	zoneNames       []string
-	tsigKeyName     string
-	tsigSecret      string
-	tsigSecretAlg   string
+	nameserver string
+	// localAddress, when set, is the address the connections to the server are made from
+	localAddress net.IP
+	// fallbackServers are sent the updates the primary master and the server fail to receive
+	fallbackServers []string
+	// primaries, when set, discovers the primary masters of the zones the updates are sent to
+	primaries *primaryDiscovery
+	// domainControllers, when set, discovers the domain controllers of the Active Directory site
+	// the updates are sent to
+	domainControllers *siteDiscovery
+	zoneNames         []string
+	tsigKeyName       string
+	tsigSecret        string
+	tsigSecretAlg     string
+	// secretRefresher, when set, provides the TSIG secret instead of tsigSecret, e.g. read from a file
+	secretRefresher *credentials.Refresher
 	insecure        bool
 	axfr            bool
 	minTTL          time.Duration
 	batchChangeSize int
+	// retries is the maximum number of times a message is sent again after a transient error,
+	// the first time after retryBackoff, and at most retryBudget times per synchronization
+	retries      int
+	retryBackoff time.Duration
+	retryBudget  int
+	// retriesLeft is the budget of the current synchronization, refilled when the records are read
+	retriesLeft *retryBudget
+	// passthroughRecordTypes are the record types without bespoke support whose targets are their
+	// raw RDATA
+	passthroughRecordTypes []string
+	// readOnlyZones refused an update when the update policies were checked, their changes are skipped
+	readOnlyZones map[string]struct{}
+
+	// maximum time in seconds the DNS client can be off from the server for the signatures to be accepted
+	clockSkew uint16
+	clock     *tsigClock
 
 	// options specific to rfc3645 gss-tsig support
 	gssTsig      bool
 	krb5Username string
 	krb5Password string
 	krb5Realm    string
+	// gss are the contexts shared by the messages sent to each server, negotiated again when they
+	// expire or are rejected
+	gss *gssContexts
 
 	// only consider hosted zones managing domains ending in this suffix
 	domainFilter endpoint.DomainFilter
@@ -81,14 +115,57 @@ type rfc2136Actions interface {
 	IncomeTransfer(m *dns.Msg, a string) (env chan *dns.Envelope, err error)
 }
 
+// RFC2136Config is the configuration of the rfc2136 provider.
+type RFC2136Config struct {
+	// Host and Port are those of the name server the updates are sent to
+	Host  string
+	Port  int
+	Zones []string
+	// Insecure sends the updates without TSIG
+	Insecure      bool
+	TSIGKeyName   string
+	TSIGSecret    string
+	TSIGSecretAlg string
+	// TSIGSecretRefresher, when set, provides the TSIG secret instead of TSIGSecret, e.g. read from a file
+	TSIGSecretRefresher *credentials.Refresher
+	AXFR                bool
+	DomainFilter        endpoint.DomainFilter
+	DryRun              bool
+	MinTTL              time.Duration
+	// GSSTSIG enables the RFC3645 GSS-TSIG authentication with the Kerberos credentials
+	GSSTSIG          bool
+	KerberosUsername string
+	KerberosPassword string
+	KerberosRealm    string
+	// ClockSkew is the maximum time the DNS client can be off from the server, the default when zero
+	ClockSkew       time.Duration
+	BatchChangeSize int
+	// UpdateCheck checks the update policies of the zones on startup
+	UpdateCheck  bool
+	LocalAddress string
+	// DiscoverPrimary sends the updates to the primary server of the SOA of the zones
+	DiscoverPrimary bool
+	FallbackHosts   []string
+	// ADSite discovers the domain controllers of the Active Directory site of ADDomain, the
+	// Kerberos realm by default
+	ADSite       string
+	ADDomain     string
+	Retries      int
+	RetryBackoff time.Duration
+	RetryBudget  int
+	// PassthroughRecordTypes are the record types without bespoke support whose targets are their raw RDATA
+	PassthroughRecordTypes []string
+}
+
 // NewRfc2136Provider is a factory function for OpenStack rfc2136 providers
-func NewRfc2136Provider(host string, port int, zoneNames []string, insecure bool, keyName string, secret string, secretAlg string, axfr bool, domainFilter endpoint.DomainFilter, dryRun bool, minTTL time.Duration, gssTsig bool, krb5Username string, krb5Password string, krb5Realm string, batchChangeSize int, actions rfc2136Actions) (provider.Provider, error) {
-	secretAlgChecked, ok := tsigAlgs[secretAlg]
-	if !ok && !insecure && !gssTsig {
-		return nil, errors.Errorf("%s is not supported TSIG algorithm", secretAlg)
+func NewRfc2136Provider(config RFC2136Config, actions rfc2136Actions) (provider.Provider, error) {
+	secretAlgChecked, ok := tsigAlgs[config.TSIGSecretAlg]
+	if !ok && !config.Insecure && !config.GSSTSIG {
+		return nil, errors.Errorf("%s is not supported TSIG algorithm", config.TSIGSecretAlg)
 	}
 
 	// Set zone to root if no set
+	zoneNames := config.Zones
 	if len(zoneNames) == 0 {
 		zoneNames = append(zoneNames, ".")
 	}
@@ -98,19 +175,56 @@ func NewRfc2136Provider(host string, port int, zoneNames []string, insecure bool
 		return len(strings.Split(zoneNames[i], ".")) > len(strings.Split(zoneNames[j], "."))
 	})
 
+	var localIP net.IP
+	if config.LocalAddress != "" {
+		if localIP = net.ParseIP(config.LocalAddress); localIP == nil {
+			return nil, errors.Errorf("%s is not a valid local address", config.LocalAddress)
+		}
+	}
+
 	r := &rfc2136Provider{
-		nameserver:      net.JoinHostPort(host, strconv.Itoa(port)),
+		nameserver:      net.JoinHostPort(config.Host, strconv.Itoa(config.Port)),
+		localAddress:    localIP,
 		zoneNames:       zoneNames,
-		insecure:        insecure,
-		gssTsig:         gssTsig,
-		krb5Username:    krb5Username,
-		krb5Password:    krb5Password,
-		krb5Realm:       strings.ToUpper(krb5Realm),
-		domainFilter:    domainFilter,
-		dryRun:          dryRun,
-		axfr:            axfr,
-		minTTL:          minTTL,
-		batchChangeSize: batchChangeSize,
+		insecure:        config.Insecure,
+		gssTsig:         config.GSSTSIG,
+		krb5Username:    config.KerberosUsername,
+		krb5Password:    config.KerberosPassword,
+		krb5Realm:       strings.ToUpper(config.KerberosRealm),
+		clockSkew:       defaultClockSkew,
+		clock:           &tsigClock{},
+		domainFilter:    config.DomainFilter,
+		dryRun:          config.DryRun,
+		axfr:            config.AXFR,
+		minTTL:          config.MinTTL,
+		batchChangeSize: config.BatchChangeSize,
+		retries:         config.Retries,
+		retryBackoff:    config.RetryBackoff,
+		retryBudget:     config.RetryBudget,
+		retriesLeft:     newRetryBudget(config.RetryBudget),
+	}
+	if config.ClockSkew > 0 {
+		r.clockSkew = uint16(config.ClockSkew / time.Second)
+	}
+	r.passthroughRecordTypes = config.PassthroughRecordTypes
+	if config.GSSTSIG {
+		r.gss = newGSSContexts(r.negotiateContext)
+	}
+	for _, host := range config.FallbackHosts {
+		if _, _, err := net.SplitHostPort(host); err != nil {
+			host = net.JoinHostPort(host, strconv.Itoa(config.Port))
+		}
+		r.fallbackServers = append(r.fallbackServers, host)
+	}
+	if config.DiscoverPrimary {
+		r.primaries = newPrimaryDiscovery(strconv.Itoa(config.Port), r.querySOA, net.DefaultResolver.LookupHost)
+	}
+	if config.ADSite != "" {
+		adDomain := config.ADDomain
+		if adDomain == "" {
+			adDomain = strings.ToLower(config.KerberosRealm)
+		}
+		r.domainControllers = newSiteDiscovery(strconv.Itoa(config.Port), config.ADSite, adDomain, net.DefaultResolver.LookupSRV)
 	}
 	if actions != nil {
 		r.actions = actions
@@ -118,30 +232,59 @@ func NewRfc2136Provider(host string, port int, zoneNames []string, insecure bool
 		r.actions = r
 	}
 
-	if !insecure {
-		r.tsigKeyName = dns.Fqdn(keyName)
-		r.tsigSecret = secret
+	if !config.Insecure {
+		r.tsigKeyName = dns.Fqdn(config.TSIGKeyName)
+		r.tsigSecret = config.TSIGSecret
 		r.tsigSecretAlg = secretAlgChecked
+		r.secretRefresher = config.TSIGSecretRefresher
 	}
 
 	log.Infof("Configured RFC2136 with zone '%s' and nameserver '%s'", r.zoneNames, r.nameserver)
+	if config.UpdateCheck && config.DryRun {
+		log.Info("Skipping the check of the update policies of the zones in dry-run mode")
+	} else if config.UpdateCheck {
+		if err := r.checkUpdatePolicies(); err != nil {
+			return nil, err
+		}
+	}
 	return r, nil
 }
 
-// KeyName will return TKEY name and TSIG handle to use for followon actions with a secure connection
-func (r rfc2136Provider) KeyData() (keyName string, handle *gss.Client, err error) {
-	handle, err = gss.NewClient(new(dns.Client))
+// KeyName will return TKEY name, TSIG handle and expiry of the context to use for followon actions with a secure connection
+func (r rfc2136Provider) KeyData() (keyName string, handle *gss.Client, expires time.Time, err error) {
+	return r.keyData(r.nameserver)
+}
+
+// keyData negotiates a GSS-TSIG context with the server.
+func (r rfc2136Provider) keyData(server string) (keyName string, handle *gss.Client, expires time.Time, err error) {
+	handle, err = gss.NewClient(r.newClient())
 	if err != nil {
-		return keyName, handle, err
+		return keyName, handle, expires, err
 	}
 
-	keyName, _, err = handle.NegotiateContextWithCredentials(r.nameserver, r.krb5Realm, r.krb5Username, r.krb5Password)
+	keyName, expires, err = handle.NegotiateContextWithCredentials(server, r.krb5Realm, r.krb5Username, r.krb5Password)
 
-	return keyName, handle, err
+	return keyName, handle, expires, err
 }
 
-// Records returns the list of records.
+// negotiateContext negotiates a GSS-TSIG context with the server, closing the handle if the
+// negotiation fails.
+func (r rfc2136Provider) negotiateContext(server string) (string, gssHandle, time.Time, error) {
+	keyName, handle, expires, err := r.keyData(server)
+	if err != nil {
+		if handle != nil {
+			handle.Close()
+		}
+		return "", nil, time.Time{}, err
+	}
+	return keyName, handle, expires, nil
+}
+
+// Records returns the list of records. They are read at the start of every synchronization, which
+// refills the retry budget shared by its updates.
 func (r rfc2136Provider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	r.retriesLeft.refill(r.retryBudget)
+
 	rrs, err := r.List()
 	if err != nil {
 		return nil, err
@@ -177,8 +320,23 @@ OuterLoop:
 		case dns.TypeNS:
 			rrValues = []string{rr.(*dns.NS).Ns}
 			rrType = "NS"
+		case dns.TypeNAPTR:
+			naptr := rr.(*dns.NAPTR)
+			rrValues = []string{endpoint.NAPTRTarget{
+				Order:       naptr.Order,
+				Preference:  naptr.Preference,
+				Flags:       naptr.Flags,
+				Service:     naptr.Service,
+				Regexp:      naptr.Regexp,
+				Replacement: naptr.Replacement,
+			}.String()}
+			rrType = "NAPTR"
 		default:
-			continue // Unhandled record type
+			rrType = dns.TypeToString[rr.Header().Rrtype]
+			if !slices.Contains(r.passthroughRecordTypes, rrType) {
+				continue // Unhandled record type
+			}
+			rrValues = []string{passthroughTarget(rr)}
 		}
 
 		for idx, existingEndpoint := range eps {
@@ -201,10 +359,41 @@ OuterLoop:
 	return eps, nil
 }
 
+// secret returns the TSIG secret, the current one of the refresher if there is one.
+func (r rfc2136Provider) secret() (string, error) {
+	if r.secretRefresher == nil {
+		return r.tsigSecret, nil
+	}
+	c, err := r.secretRefresher.Get(context.Background())
+	if err != nil {
+		return "", err
+	}
+	return c[credentials.TSIGSecret], nil
+}
+
+// rejected returns the error of a message whose TSIG signature was rejected, a soft error fetching
+// the secret again if it has a refresher.
+func (r rfc2136Provider) rejected(err error) error {
+	if r.secretRefresher == nil || r.insecure || r.gssTsig {
+		return err
+	}
+	return r.secretRefresher.Rejected(err)
+}
+
 func (r rfc2136Provider) IncomeTransfer(m *dns.Msg, a string) (env chan *dns.Envelope, err error) {
 	t := new(dns.Transfer)
 	if !r.insecure && !r.gssTsig {
-		t.TsigSecret = map[string]string{r.tsigKeyName: r.tsigSecret}
+		secret, err := r.secret()
+		if err != nil {
+			return nil, err
+		}
+		t.TsigSecret = map[string]string{r.tsigKeyName: secret}
+	}
+	if r.localAddress != nil {
+		t.Conn, err = r.newClient().Dial(r.nameserver)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return t.In(m, r.nameserver)
@@ -223,7 +412,7 @@ func (r rfc2136Provider) List() ([]dns.RR, error) {
 		m := new(dns.Msg)
 		m.SetAxfr(dns.Fqdn(zone))
 		if !r.insecure && !r.gssTsig {
-			m.SetTsig(r.tsigKeyName, r.tsigSecretAlg, clockSkew, time.Now().Unix())
+			m.SetTsig(r.tsigKeyName, r.tsigSecretAlg, r.clockSkew, r.clock.now())
 		}
 
 		env, err := r.actions.IncomeTransfer(m, r.nameserver)
@@ -233,6 +422,9 @@ func (r rfc2136Provider) List() ([]dns.RR, error) {
 
 		for e := range env {
 			if e.Error != nil {
+				if errors.Is(e.Error, dns.ErrSig) || errors.Is(e.Error, dns.ErrAuth) {
+					return nil, r.rejected(fmt.Errorf("failed to fetch records via AXFR: %w", e.Error))
+				}
 				if e.Error == dns.ErrSoa {
 					log.Error("AXFR error: unexpected response received from the server")
 				} else {
@@ -253,7 +445,9 @@ func (r rfc2136Provider) ApplyChanges(ctx context.Context, changes *plan.Changes
 
 	var errors []error
 
-	for c, chunk := range chunkBy(changes.Create, r.batchChangeSize) {
+	creates, deletes, replacing, replaced := findReplacements(changes.Create, changes.Delete)
+
+	for c, chunk := range chunkBy(creates, r.batchChangeSize) {
 		log.Debugf("Processing batch %d of create changes", c)
 
 		m := make(map[string]*dns.Msg)
@@ -269,25 +463,58 @@ func (r rfc2136Provider) ApplyChanges(ctx context.Context, changes *plan.Changes
 			}
 
 			zone := findMsgZone(ep, r.zoneNames)
+			if _, ok := r.readOnlyZones[zone]; ok {
+				log.Debugf("Skipping record %s because the zone %s refused the updates", ep.DNSName, zone)
+				continue
+			}
 			r.krb5Realm = strings.ToUpper(zone)
 			m[zone].SetUpdate(zone)
 
 			r.AddRecord(m[zone], ep)
 		}
 
-		// only send if there are records available
-		for _, z := range m {
-			if len(z.Ns) > 0 {
-				if err := r.actions.SendMessage(z); err != nil {
-					log.Errorf("RFC2136 create record failed: %v", err)
+		errors = append(errors, r.sendMessages(ctx, m, "create")...)
+	}
+
+	for c, chunk := range chunkBy(replacing, r.batchChangeSize) {
+		log.Debugf("Processing batch %d of replace changes", c)
+
+		m := make(map[string]*dns.Msg)
+		m["."] = new(dns.Msg) // Add the root zone
+		for _, z := range r.zoneNames {
+			z = dns.Fqdn(z)
+			m[z] = new(dns.Msg)
+		}
+		for _, ep := range chunk {
+			if !r.domainFilter.Match(ep.DNSName) {
+				log.Debugf("Skipping record %s because it was filtered out by the specified --domain-filter", ep.DNSName)
+				continue
+			}
+
+			zone := findMsgZone(ep, r.zoneNames)
+			if _, ok := r.readOnlyZones[zone]; ok {
+				log.Debugf("Skipping record %s because the zone %s refused the updates", ep.DNSName, zone)
+				continue
+			}
+			r.krb5Realm = strings.ToUpper(zone)
+			m[zone].SetUpdate(zone)
+
+			// the replaced records are removed in the message adding the first record of the name
+			name := replacementName(ep)
+			for _, old := range replaced[name] {
+				if err := r.RemoveReplaced(m[zone], old); err != nil {
 					errors = append(errors, err)
-					continue
 				}
 			}
+			delete(replaced, name)
+
+			r.AddRecord(m[zone], ep)
 		}
+
+		errors = append(errors, r.sendMessages(ctx, m, "replace")...)
 	}
 
-	for c, chunk := range chunkBy(changes.UpdateNew, r.batchChangeSize) {
+	for c, chunk := range chunkBy(changes.TargetChanges(), r.batchChangeSize) {
 		log.Debugf("Processing batch %d of update changes", c)
 
 		m := make(map[string]*dns.Msg)
@@ -297,32 +524,28 @@ func (r rfc2136Provider) ApplyChanges(ctx context.Context, changes *plan.Changes
 			m[z] = new(dns.Msg)
 		}
 
-		for i, ep := range chunk {
+		for _, change := range chunk {
+			ep := change.New
 			if !r.domainFilter.Match(ep.DNSName) {
 				log.Debugf("Skipping record %s because it was filtered out by the specified --domain-filter", ep.DNSName)
 				continue
 			}
 
 			zone := findMsgZone(ep, r.zoneNames)
+			if _, ok := r.readOnlyZones[zone]; ok {
+				log.Debugf("Skipping record %s because the zone %s refused the updates", ep.DNSName, zone)
+				continue
+			}
 			r.krb5Realm = strings.ToUpper(zone)
 			m[zone].SetUpdate(zone)
 
-			r.UpdateRecord(m[zone], changes.UpdateOld[i], ep)
+			r.UpdateRecord(m[zone], change)
 		}
 
-		// only send if there are records available
-		for _, z := range m {
-			if len(z.Ns) > 0 {
-				if err := r.actions.SendMessage(z); err != nil {
-					log.Errorf("RFC2136 update record failed: %v", err)
-					errors = append(errors, err)
-					continue
-				}
-			}
-		}
+		errors = append(errors, r.sendMessages(ctx, m, "update")...)
 	}
 
-	for c, chunk := range chunkBy(changes.Delete, r.batchChangeSize) {
+	for c, chunk := range chunkBy(deletes, r.batchChangeSize) {
 		log.Debugf("Processing batch %d of delete changes", c)
 
 		m := make(map[string]*dns.Msg)
@@ -338,22 +561,17 @@ func (r rfc2136Provider) ApplyChanges(ctx context.Context, changes *plan.Changes
 			}
 
 			zone := findMsgZone(ep, r.zoneNames)
+			if _, ok := r.readOnlyZones[zone]; ok {
+				log.Debugf("Skipping record %s because the zone %s refused the updates", ep.DNSName, zone)
+				continue
+			}
 			r.krb5Realm = strings.ToUpper(zone)
 			m[zone].SetUpdate(zone)
 
 			r.RemoveRecord(m[zone], ep)
 		}
 
-		// only send if there are records available
-		for _, z := range m {
-			if len(z.Ns) > 0 {
-				if err := r.actions.SendMessage(z); err != nil {
-					log.Errorf("RFC2136 delete record failed: %v", err)
-					errors = append(errors, err)
-					continue
-				}
-			}
-		}
+		errors = append(errors, r.sendMessages(ctx, m, "delete")...)
 	}
 
 	if len(errors) > 0 {
@@ -363,25 +581,102 @@ func (r rfc2136Provider) ApplyChanges(ctx context.Context, changes *plan.Changes
 	return nil
 }
 
-func (r rfc2136Provider) UpdateRecord(m *dns.Msg, oldEp *endpoint.Endpoint, newEp *endpoint.Endpoint) error {
-	err := r.RemoveRecord(m, oldEp)
+// sendMessages sends the update messages of the zones that contain records, their retries are
+// taken from the budget of the synchronization.
+func (r rfc2136Provider) sendMessages(ctx context.Context, m map[string]*dns.Msg, action string) []error {
+	var errs []error
+	for _, z := range m {
+		// only send if there are records available
+		if len(z.Ns) == 0 {
+			continue
+		}
+		if err := r.sendWithRetries(ctx, z); err != nil {
+			log.Errorf("RFC2136 %s record failed: %v", action, err)
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// findReplacements splits the creations and deletions of the same names, e.g. when the type of a
+// record changes, from the other ones. The replacing records are added in the same update messages
+// removing the replaced records, so the names never resolve to an empty answer.
+func findReplacements(creates, deletes []*endpoint.Endpoint) (remainingCreates, remainingDeletes, replacing []*endpoint.Endpoint, replaced map[string][]*endpoint.Endpoint) {
+	created := make(map[string]struct{}, len(creates))
+	for _, ep := range creates {
+		created[replacementName(ep)] = struct{}{}
+	}
+
+	replaced = make(map[string][]*endpoint.Endpoint)
+	for _, ep := range deletes {
+		name := replacementName(ep)
+		if _, ok := created[name]; ok {
+			replaced[name] = append(replaced[name], ep)
+		} else {
+			remainingDeletes = append(remainingDeletes, ep)
+		}
+	}
+
+	for _, ep := range creates {
+		if _, ok := replaced[replacementName(ep)]; ok {
+			replacing = append(replacing, ep)
+		} else {
+			remainingCreates = append(remainingCreates, ep)
+		}
+	}
+	return remainingCreates, remainingDeletes, replacing, replaced
+}
+
+func replacementName(ep *endpoint.Endpoint) string {
+	return strings.ToLower(dns.Fqdn(ep.DNSName))
+}
+
+// UpdateRecord adds the update of the record to the message. Only the targets that changed are
+// removed and added, so the unchanged targets keep resolving while the update is applied. When
+// the TTL changes all the targets of the record are removed and added again in the same message,
+// which the server applies atomically.
+func (r rfc2136Provider) UpdateRecord(m *dns.Msg, change plan.TargetChange) error {
+	oldEp, newEp := change.Old, change.New
+	if oldEp == nil {
+		return r.AddRecord(m, newEp)
+	}
+
+	if r.ttl(newEp) != r.ttl(oldEp) {
+		if err := r.RemoveReplaced(m, oldEp); err != nil {
+			return err
+		}
+		return r.AddRecord(m, newEp)
+	}
+
+	removeEp := oldEp.DeepCopy()
+	removeEp.Targets = change.Removed
+	addEp := newEp.DeepCopy()
+	addEp.Targets = change.Added
+
+	err := r.RemoveRecord(m, removeEp)
 	if err != nil {
 		return err
 	}
 
-	return r.AddRecord(m, newEp)
+	return r.AddRecord(m, addEp)
 }
 
-func (r rfc2136Provider) AddRecord(m *dns.Msg, ep *endpoint.Endpoint) error {
-	log.Debugf("AddRecord.ep=%s", ep)
-
+// ttl returns the TTL of the record, at least the minimum TTL.
+func (r rfc2136Provider) ttl(ep *endpoint.Endpoint) int64 {
 	ttl := int64(r.minTTL.Seconds())
 	if ep.RecordTTL.IsConfigured() && int64(ep.RecordTTL) > ttl {
 		ttl = int64(ep.RecordTTL)
 	}
+	return ttl
+}
+
+func (r rfc2136Provider) AddRecord(m *dns.Msg, ep *endpoint.Endpoint) error {
+	log.Debugf("AddRecord.ep=%s", ep)
+
+	ttl := r.ttl(ep)
 
 	for _, target := range ep.Targets {
-		newRR := fmt.Sprintf("%s %d %s %s", ep.DNSName, ttl, ep.RecordType, target)
+		newRR := fmt.Sprintf("%s %d %s %s", ep.DNSName, ttl, ep.RecordType, rdata(ep.RecordType, target))
 		log.Infof("Adding RR: %s", newRR)
 
 		rr, err := dns.NewRR(newRR)
@@ -398,7 +693,7 @@ func (r rfc2136Provider) AddRecord(m *dns.Msg, ep *endpoint.Endpoint) error {
 func (r rfc2136Provider) RemoveRecord(m *dns.Msg, ep *endpoint.Endpoint) error {
 	log.Debugf("RemoveRecord.ep=%s", ep)
 	for _, target := range ep.Targets {
-		newRR := fmt.Sprintf("%s %d %s %s", ep.DNSName, ep.RecordTTL, ep.RecordType, target)
+		newRR := fmt.Sprintf("%s %d %s %s", ep.DNSName, ep.RecordTTL, ep.RecordType, rdata(ep.RecordType, target))
 		log.Infof("Removing RR: %s", newRR)
 
 		rr, err := dns.NewRR(newRR)
@@ -412,6 +707,66 @@ func (r rfc2136Provider) RemoveRecord(m *dns.Msg, ep *endpoint.Endpoint) error {
 	return nil
 }
 
+// AdjustEndpoints rewrites the targets of the passthrough record types in the form of those of the
+// records read from the server, so that they compare equal.
+func (r rfc2136Provider) AdjustEndpoints(endpoints []*endpoint.Endpoint) ([]*endpoint.Endpoint, error) {
+	for _, ep := range endpoints {
+		if !slices.Contains(r.passthroughRecordTypes, ep.RecordType) {
+			continue
+		}
+		for i, target := range ep.Targets {
+			rr, err := dns.NewRR(fmt.Sprintf(". 0 %s %s", ep.RecordType, target))
+			if err != nil || rr == nil {
+				log.Warnf("Invalid %s target %q of %s: %v", ep.RecordType, target, ep.DNSName, err)
+				continue
+			}
+			ep.Targets[i] = passthroughTarget(rr)
+		}
+	}
+	return endpoints, nil
+}
+
+// passthroughTarget returns the RDATA of the record in the presentation format, without trailing
+// dot like the other targets.
+func passthroughTarget(rr dns.RR) string {
+	return strings.TrimSuffix(strings.TrimPrefix(rr.String(), rr.Header().String()), ".")
+}
+
+// rdata returns the target of a record of the given type in the presentation format of its data.
+func rdata(recordType, target string) string {
+	if recordType == endpoint.RecordTypeNAPTR {
+		// the replacement of the targets is not fully qualified
+		if naptr, err := endpoint.ParseNAPTRTarget(target); err == nil {
+			return naptr.FQDN()
+		}
+	}
+	return target
+}
+
+// RemoveReplaced adds the removal of the record replaced by another one to the message. Only the
+// RRset of a CNAME, which no other record can share the name of, is deleted as a whole; of the
+// other types only the targets of the record are removed, keeping the records of the same name
+// and type not managed by external-dns, e.g. the SPF records next to its ownership TXT records.
+func (r rfc2136Provider) RemoveReplaced(m *dns.Msg, ep *endpoint.Endpoint) error {
+	if ep.RecordType == endpoint.RecordTypeCNAME {
+		r.RemoveRRset(m, ep)
+		return nil
+	}
+	return r.RemoveRecord(m, ep)
+}
+
+// RemoveRRset adds the deletion of all records of the name and type of the record to the message.
+func (r rfc2136Provider) RemoveRRset(m *dns.Msg, ep *endpoint.Endpoint) {
+	log.Debugf("RemoveRRset.ep=%s", ep)
+	log.Infof("Removing RRset: %s %s", ep.DNSName, ep.RecordType)
+
+	m.RemoveRRset([]dns.RR{&dns.ANY{Hdr: dns.RR_Header{
+		Name:   dns.Fqdn(ep.DNSName),
+		Rrtype: dns.StringToType[ep.RecordType],
+		Class:  dns.ClassINET,
+	}}})
+}
+
 func (r rfc2136Provider) SendMessage(msg *dns.Msg) error {
 	if r.dryRun {
 		log.Debugf("SendMessage.skipped")
@@ -419,31 +774,111 @@ func (r rfc2136Provider) SendMessage(msg *dns.Msg) error {
 	}
 	log.Debugf("SendMessage")
 
-	c := new(dns.Client)
+	servers := r.servers(msg.Question[0].Name)
+	var err error
+	for i, server := range servers {
+		err = r.sendMessageTo(msg, server)
+		var netErr net.Error
+		if err == nil || !errors.As(err, &netErr) || i == len(servers)-1 {
+			break
+		}
+		log.Warnf("Failed to send the update to %s, sending it to %s: %v", server, servers[i+1], err)
+	}
+	return err
+}
+
+// servers returns the servers the updates of the zone are sent to, in order, until one receives
+// them: the discovered primary master of the zone, the domain controllers of the Active Directory
+// site then of the domain, the configured server, then the fallbacks.
+func (r rfc2136Provider) servers(zone string) []string {
+	servers := make([]string, 0, 2+len(r.fallbackServers))
+	if r.primaries != nil {
+		primary, err := r.primaries.primary(zone)
+		if err != nil {
+			log.Warnf("Failed to discover the primary master of the zone %s, sending the updates to %s: %v", zone, r.nameserver, err)
+		} else {
+			servers = append(servers, primary)
+		}
+	}
+	if r.domainControllers != nil {
+		domainControllers, err := r.domainControllers.domainControllers()
+		if err != nil {
+			log.Warnf("Failed to discover the domain controllers of the site %s, sending the updates to %s: %v", r.domainControllers.site, r.nameserver, err)
+		}
+		for _, server := range domainControllers {
+			if !slices.Contains(servers, server) {
+				servers = append(servers, server)
+			}
+		}
+	}
+	for _, server := range append([]string{r.nameserver}, r.fallbackServers...) {
+		if !slices.Contains(servers, server) {
+			servers = append(servers, server)
+		}
+	}
+	return servers
+}
+
+// sendMessageTo signs the message and sends it to the server.
+func (r rfc2136Provider) sendMessageTo(msg *dns.Msg, server string) error {
+	c := r.newClient()
 
 	if !r.insecure {
 		if r.gssTsig {
-			keyName, handle, err := r.KeyData()
-			if err != nil {
-				return err
-			}
-			defer handle.Close()
-			defer handle.DeleteContext(keyName)
+			return r.gss.context(server).send(func(keyName string, handle gssHandle) error {
+				c.TsigProvider = handle
+				setTsig(msg, keyName, tsig.GSS, r.clockSkew, r.clock.now())
+				return r.exchange(c, msg, server)
+			})
+		}
+		secret, err := r.secret()
+		if err != nil {
+			return err
+		}
+		c.TsigProvider = tsig.HMAC{r.tsigKeyName: secret}
+		setTsig(msg, r.tsigKeyName, r.tsigSecretAlg, r.clockSkew, r.clock.now())
+	}
 
-			c.TsigProvider = handle
+	return r.exchange(c, msg, server)
+}
 
-			msg.SetTsig(keyName, tsig.GSS, clockSkew, time.Now().Unix())
-		} else {
-			c.TsigProvider = tsig.HMAC{r.tsigKeyName: r.tsigSecret}
-			msg.SetTsig(r.tsigKeyName, r.tsigSecretAlg, clockSkew, time.Now().Unix())
+// querySOA returns the SOA record of the zone, as answered by the configured server.
+func (r rfc2136Provider) querySOA(zone string) (*dns.SOA, error) {
+	m := new(dns.Msg)
+	m.SetQuestion(dns.Fqdn(zone), dns.TypeSOA)
+	resp, _, err := r.newClient().Exchange(m, r.nameserver)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Rcode != dns.RcodeSuccess {
+		return nil, fmt.Errorf("bad return code: %s", dns.RcodeToString[resp.Rcode])
+	}
+	for _, rr := range resp.Answer {
+		if soa, ok := rr.(*dns.SOA); ok {
+			return soa, nil
 		}
 	}
+	return nil, fmt.Errorf("no SOA record in the answer")
+}
 
-	c.Net = "tcp"
+// newClient returns a client sending the messages over TCP, from the local address if it is set.
+func (r rfc2136Provider) newClient() *dns.Client {
+	c := &dns.Client{Net: "tcp"}
+	if r.localAddress != nil {
+		c.Dialer = &net.Dialer{Timeout: dialTimeout, LocalAddr: &net.TCPAddr{IP: r.localAddress}}
+	}
+	return c
+}
 
-	resp, _, err := c.Exchange(msg, r.nameserver)
+// exchange sends the message with the client, learning the offset of the clock of the server from
+// the BADTIME errors.
+func (r rfc2136Provider) exchange(c *dns.Client, msg *dns.Msg, server string) error {
+	resp, _, err := c.Exchange(msg, server)
+	if resp != nil {
+		r.clock.adjust(resp)
+	}
 	if err != nil {
-		if resp != nil && resp.Rcode != dns.RcodeSuccess {
+		if resp == nil || resp.Rcode != dns.RcodeSuccess {
 			log.Infof("error in dns.Client.Exchange: %s", err)
 			return err
 		}
@@ -451,6 +886,15 @@ func (r rfc2136Provider) SendMessage(msg *dns.Msg) error {
 	}
 	if resp != nil && resp.Rcode != dns.RcodeSuccess {
 		log.Infof("Bad dns.Client.Exchange response: %s", resp)
+		if resp.Rcode == dns.RcodeNotAuth {
+			return r.rejected(fmt.Errorf("bad return code: %w", errNotAuth))
+		}
+		if resp.Rcode == dns.RcodeRefused {
+			return fmt.Errorf("bad return code: %w", errRefused)
+		}
+		if resp.Rcode == dns.RcodeServerFailure {
+			return fmt.Errorf("bad return code: %w", errServFail)
+		}
 		return fmt.Errorf("bad return code: %s", dns.RcodeToString[resp.Rcode])
 	}
 
@@ -458,8 +902,8 @@ func (r rfc2136Provider) SendMessage(msg *dns.Msg) error {
 	return nil
 }
 
-func chunkBy(slice []*endpoint.Endpoint, chunkSize int) [][]*endpoint.Endpoint {
-	var chunks [][]*endpoint.Endpoint
+func chunkBy[T any](slice []T, chunkSize int) [][]T {
+	var chunks [][]T
 
 	for i := 0; i < len(slice); i += chunkSize {
 		end := i + chunkSize
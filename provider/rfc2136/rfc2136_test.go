@@ -19,15 +19,18 @@ package rfc2136
 import (
 	"context"
 	"fmt"
+	"net"
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/miekg/dns"
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"sigs.k8s.io/external-dns/endpoint"
 	"sigs.k8s.io/external-dns/plan"
@@ -35,9 +38,12 @@ import (
 )
 
 type rfc2136Stub struct {
+	mu         sync.Mutex
 	output     []*dns.Envelope
 	updateMsgs []*dns.Msg
 	createMsgs []*dns.Msg
+	// sent are all the messages in the order they were sent
+	sent []*dns.Msg
 }
 
 func newStub() *rfc2136Stub {
@@ -59,6 +65,10 @@ func getSortedChanges(msgs []*dns.Msg) []string {
 }
 
 func (r *rfc2136Stub) SendMessage(msg *dns.Msg) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent = append(r.sent, msg)
+
 	zone := extractZoneFromMessage(msg.String())
 	// Make sure the zone starts with . to make sure HasSuffix does not match forbar.com for zone bar.com
 	if !strings.HasPrefix(zone, ".") {
@@ -81,7 +91,8 @@ func (r *rfc2136Stub) SendMessage(msg *dns.Msg) error {
 			return err
 		}
 
-		if strings.Contains(line, " NONE ") {
+		// the class ANY is printed as CLASS255 by recent versions of miekg/dns
+		if strings.Contains(line, " NONE ") || strings.Contains(line, " ANY ") || strings.Contains(line, " CLASS255 ") {
 			r.updateMsgs = append(r.updateMsgs, msg)
 		} else if strings.Contains(line, " IN ") {
 			r.createMsgs = append(r.createMsgs, msg)
@@ -118,17 +129,17 @@ func (r *rfc2136Stub) IncomeTransfer(m *dns.Msg, a string) (env chan *dns.Envelo
 }
 
 func createRfc2136StubProvider(stub *rfc2136Stub) (provider.Provider, error) {
-	return NewRfc2136Provider("", 0, nil, false, "key", "secret", "hmac-sha512", true, endpoint.DomainFilter{}, false, 300*time.Second, false, "", "", "", 50, stub)
+	return NewRfc2136Provider(RFC2136Config{TSIGKeyName: "key", TSIGSecret: "secret", TSIGSecretAlg: "hmac-sha512", AXFR: true, MinTTL: 300 * time.Second, BatchChangeSize: 50}, stub)
 }
 
 func createRfc2136StubProviderWithZones(stub *rfc2136Stub) (provider.Provider, error) {
 	zones := []string{"foo.com", "foobar.com"}
-	return NewRfc2136Provider("", 0, zones, false, "key", "secret", "hmac-sha512", true, endpoint.DomainFilter{}, false, 300*time.Second, false, "", "", "", 50, stub)
+	return NewRfc2136Provider(RFC2136Config{Zones: zones, TSIGKeyName: "key", TSIGSecret: "secret", TSIGSecretAlg: "hmac-sha512", AXFR: true, MinTTL: 300 * time.Second, BatchChangeSize: 50}, stub)
 }
 
 func createRfc2136StubProviderWithZonesFilters(stub *rfc2136Stub) (provider.Provider, error) {
 	zones := []string{"foo.com", "foobar.com"}
-	return NewRfc2136Provider("", 0, zones, false, "key", "secret", "hmac-sha512", true, endpoint.DomainFilter{Filters: zones}, false, 300*time.Second, false, "", "", "", 50, stub)
+	return NewRfc2136Provider(RFC2136Config{Zones: zones, TSIGKeyName: "key", TSIGSecret: "secret", TSIGSecretAlg: "hmac-sha512", AXFR: true, DomainFilter: endpoint.DomainFilter{Filters: zones}, MinTTL: 300 * time.Second, BatchChangeSize: 50}, stub)
 }
 
 func extractUpdateSectionFromMessage(msg fmt.Stringer) []string {
@@ -193,6 +204,82 @@ func TestRfc2136GetRecords(t *testing.T) {
 	assert.True(t, contains(recs, "v2.foo.com"))
 }
 
+func TestRfc2136GetRecordsNAPTR(t *testing.T) {
+	stub := newStub()
+	err := stub.setOutput([]string{
+		`foo.com 3600 IN NAPTR 100 10 "S" "SIP+D2U" "" _sip._udp.foo.com.`,
+		`foo.com 3600 IN NAPTR 100 20 "U" "E2U+sip" "!^.*$!sip:info@foo.com!" .`,
+	})
+	assert.NoError(t, err)
+
+	provider, err := createRfc2136StubProvider(stub)
+	assert.NoError(t, err)
+
+	recs, err := provider.Records(context.Background())
+	assert.NoError(t, err)
+
+	require.Len(t, recs, 1)
+	assert.Equal(t, endpoint.RecordTypeNAPTR, recs[0].RecordType)
+	assert.Equal(t, endpoint.Targets{
+		`100 10 "S" "SIP+D2U" "" _sip._udp.foo.com`,
+		`100 20 "U" "E2U+sip" "!^.*$!sip:info@foo.com!" .`,
+	}, recs[0].Targets)
+}
+
+func TestRfc2136ApplyChangesNAPTR(t *testing.T) {
+	stub := newStub()
+	provider, err := createRfc2136StubProvider(stub)
+	assert.NoError(t, err)
+
+	p := &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("foo.com", endpoint.RecordTypeNAPTR, `100 10 "S" "SIP+D2U" "" _sip._udp.foo.com`, `100 20 "U" "E2U+sip" "!^.*$!sip:info@foo.com!" .`),
+		},
+	}
+	require.NoError(t, provider.ApplyChanges(context.Background(), p))
+
+	require.NotEmpty(t, stub.createMsgs)
+	assert.Contains(t, stub.createMsgs[0].String(), "foo.com.\t300\tIN\tNAPTR\t100 10 \"S\" \"SIP+D2U\" \"\" _sip._udp.foo.com.")
+	assert.Contains(t, stub.createMsgs[0].String(), "foo.com.\t300\tIN\tNAPTR\t100 20 \"U\" \"E2U+sip\" \"!^.*$!sip:info@foo.com!\" .")
+}
+
+func TestRfc2136Passthrough(t *testing.T) {
+	stub := newStub()
+	err := stub.setOutput([]string{
+		"office.foo.com 3600 IN LOC 52 22 23 N 4 53 32 E -2m",
+		"office.foo.com 3600 IN CERT PGP 0 0 dGVzdA==",
+		"office.foo.com 3600 IN HINFO \"x86\" \"linux\"",
+	})
+	require.NoError(t, err)
+
+	p, err := NewRfc2136Provider(RFC2136Config{TSIGKeyName: "key", TSIGSecret: "secret", TSIGSecretAlg: "hmac-sha512", AXFR: true, MinTTL: 300 * time.Second, BatchChangeSize: 50, PassthroughRecordTypes: []string{"LOC", "CERT"}}, stub)
+	require.NoError(t, err)
+
+	// the records of the passthrough record types are read with their RDATA as target
+	recs, err := p.Records(context.Background())
+	require.NoError(t, err)
+	require.Len(t, recs, 2)
+	assert.Equal(t, "LOC", recs[0].RecordType)
+	assert.Equal(t, endpoint.Targets{"52 22 23.000 N 04 53 32.000 E -2m 1m 10000m 10m"}, recs[0].Targets)
+	assert.Equal(t, "CERT", recs[1].RecordType)
+	assert.Equal(t, endpoint.Targets{"PGP 0 0 dGVzdA=="}, recs[1].Targets)
+
+	// the desired targets are rewritten in the same form
+	desired, err := p.AdjustEndpoints([]*endpoint.Endpoint{
+		endpoint.NewEndpoint("office.foo.com", "LOC", "52 22 23 N 4 53 32 E -2m"),
+		endpoint.NewEndpoint("office.foo.com", "CERT", "invalid"),
+		endpoint.NewEndpoint("app.foo.com", endpoint.RecordTypeA, "1.2.3.4"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, endpoint.Targets{"52 22 23.000 N 04 53 32.000 E -2m 1m 10000m 10m"}, desired[0].Targets)
+	assert.Equal(t, endpoint.Targets{"invalid"}, desired[1].Targets)
+	assert.Equal(t, endpoint.Targets{"1.2.3.4"}, desired[2].Targets)
+
+	require.NoError(t, p.ApplyChanges(context.Background(), &plan.Changes{Create: desired[:1]}))
+	require.NotEmpty(t, stub.createMsgs)
+	assert.Contains(t, stub.createMsgs[0].String(), "office.foo.com.\t300\tIN\tLOC\t52 22 23.000 N 04 53 32.000 E -2m 1m 10000m 10m")
+}
+
 // Make sure the test version of SendMessage raises an error
 // if a zone update ever contains records outside of it's zone
 // as the TestRfc2136ApplyChanges tests all assume this
@@ -535,6 +622,214 @@ func TestRfc2136ApplyChangesWithUpdate(t *testing.T) {
 	assert.True(t, strings.Contains(stub.updateMsgs[1].String(), "boom"))
 }
 
+func TestRfc2136ApplyChangesWithTargetUpdates(t *testing.T) {
+	for _, tc := range []struct {
+		name                  string
+		newTTL                endpoint.TTL
+		newTargets            []string
+		expectedAdded         []string
+		expectedRemoved       []string
+		expectedRemovedRRsets []string
+	}{
+		{
+			name:          "target added",
+			newTTL:        400,
+			newTargets:    []string{"1.1.1.1", "1.1.1.2", "1.1.1.3"},
+			expectedAdded: []string{"1.1.1.3"},
+		},
+		{
+			name:            "target replaced",
+			newTTL:          400,
+			newTargets:      []string{"1.1.1.1", "1.1.1.3"},
+			expectedAdded:   []string{"1.1.1.3"},
+			expectedRemoved: []string{"1.1.1.2"},
+		},
+		{
+			name:            "ttl changed",
+			newTTL:          500,
+			newTargets:      []string{"1.1.1.1", "1.1.1.2"},
+			expectedAdded:   []string{"1.1.1.1", "1.1.1.2"},
+			expectedRemoved: []string{"1.1.1.1", "1.1.1.2"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			stub := newStub()
+			provider, err := createRfc2136StubProvider(stub)
+			assert.NoError(t, err)
+
+			err = provider.ApplyChanges(context.Background(), &plan.Changes{
+				UpdateOld: []*endpoint.Endpoint{
+					{DNSName: "v1.foo.com", RecordType: "A", Targets: []string{"1.1.1.1", "1.1.1.2"}, RecordTTL: endpoint.TTL(400)},
+				},
+				UpdateNew: []*endpoint.Endpoint{
+					{DNSName: "v1.foo.com", RecordType: "A", Targets: tc.newTargets, RecordTTL: tc.newTTL},
+				},
+			})
+			assert.NoError(t, err)
+
+			// the class of the RRset deletions is printed as ANY or CLASS255 depending on the version of
+			// miekg/dns, so the update section is read from the records
+			var added, removed, removedRRsets []string
+			for _, rr := range stub.createMsgs[0].Ns {
+				switch rr.Header().Class {
+				case dns.ClassANY:
+					removedRRsets = append(removedRRsets, dns.TypeToString[rr.Header().Rrtype])
+				case dns.ClassNONE:
+					removed = append(removed, rr.(*dns.A).A.String())
+				default:
+					added = append(added, rr.(*dns.A).A.String())
+				}
+			}
+			assert.Equal(t, tc.expectedAdded, added)
+			assert.Equal(t, tc.expectedRemoved, removed)
+			assert.Equal(t, tc.expectedRemovedRRsets, removedRRsets)
+		})
+	}
+}
+
+func TestRfc2136ApplyChangesWithReplacements(t *testing.T) {
+	stub := newStub()
+	provider, err := createRfc2136StubProvider(stub)
+	assert.NoError(t, err)
+
+	err = provider.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			{DNSName: "v1.foo.com", RecordType: "A", Targets: []string{"1.2.3.4"}},
+			{DNSName: "v2.foo.com", RecordType: "A", Targets: []string{"1.2.3.5"}},
+		},
+		Delete: []*endpoint.Endpoint{
+			{DNSName: "v1.foo.com", RecordType: "CNAME", Targets: []string{"lb.example.com"}},
+			{DNSName: "v3.foo.com", RecordType: "A", Targets: []string{"1.2.3.6"}},
+		},
+	})
+	assert.NoError(t, err)
+
+	// the creation of v2 and the deletion of v3 are independent, the replacement of v1 is atomic
+	assert.Equal(t, 2, len(stub.createMsgs))
+	assert.True(t, strings.Contains(stub.createMsgs[0].String(), "v2.foo.com"))
+	assert.False(t, strings.Contains(stub.createMsgs[0].String(), "v1.foo.com"))
+
+	replace := stub.createMsgs[1].Ns
+	require.Len(t, replace, 2)
+	assert.Equal(t, dns.RR_Header{Name: "v1.foo.com.", Rrtype: dns.TypeCNAME, Class: dns.ClassANY}, *replace[0].Header())
+	assert.Equal(t, []string{"v1.foo.com.", "300", "IN", "A", "1.2.3.4"}, strings.Fields(replace[1].String()))
+
+	assert.Equal(t, 2, len(stub.updateMsgs))
+	assert.Equal(t, stub.createMsgs[1], stub.updateMsgs[0])
+	assert.True(t, strings.Contains(stub.updateMsgs[1].String(), "v3.foo.com"))
+}
+
+// applyUpdates applies the update sections of the messages to the records of a zone like a server.
+func applyUpdates(records []dns.RR, msgs []*dns.Msg) []dns.RR {
+	for _, msg := range msgs {
+		for _, update := range msg.Ns {
+			h := update.Header()
+			kept := records[:0:0]
+			for _, rr := range records {
+				removed := false
+				switch h.Class {
+				case dns.ClassANY:
+					removed = strings.EqualFold(rr.Header().Name, h.Name) && rr.Header().Rrtype == h.Rrtype
+				case dns.ClassNONE:
+					inet := dns.Copy(update)
+					inet.Header().Class = dns.ClassINET
+					removed = dns.IsDuplicate(rr, inet)
+				}
+				if !removed {
+					kept = append(kept, rr)
+				}
+			}
+			records = kept
+			if h.Class == dns.ClassINET {
+				records = append(records, update)
+			}
+		}
+	}
+	return records
+}
+
+func TestRfc2136ApplyChangesKeepsUnmanagedRecords(t *testing.T) {
+	zone := func(rrs ...string) []dns.RR {
+		var records []dns.RR
+		for _, s := range rrs {
+			rr, err := dns.NewRR(s)
+			require.NoError(t, err)
+			records = append(records, rr)
+		}
+		return records
+	}
+	ownership := `"heritage=external-dns,external-dns/owner=default"`
+
+	for _, tc := range []struct {
+		name     string
+		changes  *plan.Changes
+		expected []dns.RR
+	}{
+		{
+			name: "type changed",
+			changes: &plan.Changes{
+				Create: []*endpoint.Endpoint{
+					endpoint.NewEndpoint("app.foo.com", endpoint.RecordTypeAAAA, "::1"),
+					endpoint.NewEndpoint("app.foo.com", endpoint.RecordTypeTXT, ownership),
+				},
+				Delete: []*endpoint.Endpoint{
+					endpoint.NewEndpoint("app.foo.com", endpoint.RecordTypeA, "1.2.3.4"),
+					endpoint.NewEndpoint("app.foo.com", endpoint.RecordTypeTXT, ownership),
+				},
+			},
+			expected: zone(
+				`app.foo.com. 300 IN TXT "v=spf1 -all"`,
+				`app.foo.com. 300 IN AAAA ::1`,
+				`app.foo.com. 300 IN TXT `+ownership,
+			),
+		},
+		{
+			name: "ttl changed",
+			changes: &plan.Changes{
+				UpdateOld: []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("app.foo.com", endpoint.RecordTypeTXT, 300, ownership)},
+				UpdateNew: []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("app.foo.com", endpoint.RecordTypeTXT, 600, ownership)},
+			},
+			expected: zone(
+				`app.foo.com. 300 IN A 1.2.3.4`,
+				`app.foo.com. 300 IN TXT "v=spf1 -all"`,
+				`app.foo.com. 600 IN TXT `+ownership,
+			),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			stub := newStub()
+			provider, err := createRfc2136StubProvider(stub)
+			require.NoError(t, err)
+
+			require.NoError(t, provider.ApplyChanges(context.Background(), tc.changes))
+
+			records := applyUpdates(zone(
+				`app.foo.com. 300 IN A 1.2.3.4`,
+				`app.foo.com. 300 IN TXT `+ownership,
+				`app.foo.com. 300 IN TXT "v=spf1 -all"`,
+			), stub.sent)
+			assert.Equal(t, tc.expected, records)
+		})
+	}
+}
+
+func TestFindReplacements(t *testing.T) {
+	createA := &endpoint.Endpoint{DNSName: "v1.foo.com", RecordType: "A"}
+	createAAAA := &endpoint.Endpoint{DNSName: "V1.foo.com.", RecordType: "AAAA"}
+	createOther := &endpoint.Endpoint{DNSName: "v2.foo.com", RecordType: "A"}
+	deleteCNAME := &endpoint.Endpoint{DNSName: "v1.foo.com", RecordType: "CNAME"}
+	deleteOther := &endpoint.Endpoint{DNSName: "v3.foo.com", RecordType: "A"}
+
+	creates, deletes, replacing, replaced := findReplacements(
+		[]*endpoint.Endpoint{createA, createOther, createAAAA},
+		[]*endpoint.Endpoint{deleteOther, deleteCNAME},
+	)
+	assert.Equal(t, []*endpoint.Endpoint{createOther}, creates)
+	assert.Equal(t, []*endpoint.Endpoint{deleteOther}, deletes)
+	assert.Equal(t, []*endpoint.Endpoint{createA, createAAAA}, replacing)
+	assert.Equal(t, map[string][]*endpoint.Endpoint{"v1.foo.com.": {deleteCNAME}}, replaced)
+}
+
 func TestChunkBy(t *testing.T) {
 	var records []*endpoint.Endpoint
 
@@ -561,3 +856,19 @@ func contains(arr []*endpoint.Endpoint, name string) bool {
 	}
 	return false
 }
+
+func TestRfc2136LocalAddress(t *testing.T) {
+	_, err := NewRfc2136Provider(RFC2136Config{TSIGKeyName: "key", TSIGSecret: "secret", TSIGSecretAlg: "hmac-sha512", AXFR: true, MinTTL: 300 * time.Second, BatchChangeSize: 50, LocalAddress: "eth1"}, newStub())
+	assert.EqualError(t, err, "eth1 is not a valid local address")
+
+	p, err := NewRfc2136Provider(RFC2136Config{TSIGKeyName: "key", TSIGSecret: "secret", TSIGSecretAlg: "hmac-sha512", AXFR: true, MinTTL: 300 * time.Second, BatchChangeSize: 50, LocalAddress: "10.0.0.5"}, newStub())
+	require.NoError(t, err)
+	c := p.(*rfc2136Provider).newClient()
+	assert.Equal(t, "tcp", c.Net)
+	require.NotNil(t, c.Dialer)
+	assert.Equal(t, &net.TCPAddr{IP: net.ParseIP("10.0.0.5")}, c.Dialer.LocalAddr)
+
+	p, err = createRfc2136StubProvider(newStub())
+	require.NoError(t, err)
+	assert.Nil(t, p.(*rfc2136Provider).newClient().Dialer)
+}
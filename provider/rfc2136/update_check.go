@@ -0,0 +1,77 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rfc2136
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/miekg/dns"
+	log "github.com/sirupsen/logrus"
+)
+
+// updateCheckLabel is the label of the name whose records are deleted to check the update policy
+// of a zone, which must not exist.
+const updateCheckLabel = "_external-dns-update-check"
+
+// errRefused is the error of the messages the server answered with REFUSED, e.g. because its
+// update policy doesn't allow them.
+var errRefused = errors.New(dns.RcodeToString[dns.RcodeRefused])
+
+// checkUpdatePolicies sends to every zone an update deleting the TXT records of a name which
+// doesn't exist, a no-op if the update is allowed, and makes the zones refusing it read-only. It
+// fails if every zone refuses the update, or if a zone can't be checked.
+func (r *rfc2136Provider) checkUpdatePolicies() error {
+	r.readOnlyZones = map[string]struct{}{}
+	for _, zone := range r.zoneNames {
+		zone = dns.Fqdn(zone)
+		name := updateCheckLabel + "." + zone
+		if zone == "." {
+			name = updateCheckLabel + "."
+		}
+		probe := &dns.ANY{Hdr: dns.RR_Header{Name: name, Rrtype: dns.TypeTXT, Class: dns.ClassINET}}
+
+		m := new(dns.Msg)
+		m.SetUpdate(zone)
+		// the records of the name are only deleted if it doesn't exist
+		m.NameNotUsed([]dns.RR{probe})
+		m.RemoveRRset([]dns.RR{probe})
+
+		err := r.actions.SendMessage(m)
+		switch {
+		case err == nil:
+			log.Infof("The zone %s accepts the updates", zone)
+		case errors.Is(err, errRefused) || isRejected(err):
+			log.Errorf("The zone %s refused an update, its records are read-only, check the allow-update or update-policy of the zone: %v", zone, err)
+			r.readOnlyZones[zone] = struct{}{}
+		default:
+			return fmt.Errorf("failed to check the update policy of the zone %s: %w", zone, err)
+		}
+	}
+
+	if len(r.readOnlyZones) == len(r.zoneNames) {
+		zones := make([]string, 0, len(r.readOnlyZones))
+		for zone := range r.readOnlyZones {
+			zones = append(zones, zone)
+		}
+		sort.Strings(zones)
+		return fmt.Errorf("every zone refused the updates, check the allow-update or update-policy of the zones: %s", strings.Join(zones, ", "))
+	}
+	return nil
+}
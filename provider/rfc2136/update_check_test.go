@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rfc2136
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+// updateCheckStub answers the messages of the zones with their errors.
+type updateCheckStub struct {
+	*rfc2136Stub
+	errors map[string]error
+	sent   map[string][]*dns.Msg
+}
+
+func (s *updateCheckStub) SendMessage(msg *dns.Msg) error {
+	zone := msg.Question[0].Name
+	s.sent[zone] = append(s.sent[zone], msg)
+	return s.errors[zone]
+}
+
+func newUpdateCheckProvider(errs map[string]error, dryRun bool) (*rfc2136Provider, *updateCheckStub, error) {
+	stub := &updateCheckStub{rfc2136Stub: newStub(), errors: errs, sent: map[string][]*dns.Msg{}}
+	p, err := NewRfc2136Provider(RFC2136Config{Zones: []string{"foo.com", "foobar.com"}, TSIGKeyName: "key", TSIGSecret: "secret", TSIGSecretAlg: "hmac-sha512", AXFR: true, DryRun: dryRun, MinTTL: 300 * time.Second, BatchChangeSize: 50, UpdateCheck: true}, stub)
+	if err != nil {
+		return nil, stub, err
+	}
+	return p.(*rfc2136Provider), stub, nil
+}
+
+func TestRfc2136UpdateCheck(t *testing.T) {
+	p, stub, err := newUpdateCheckProvider(map[string]error{
+		"foo.com.": fmt.Errorf("bad return code: %w", errRefused),
+	}, false)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]struct{}{"foo.com.": {}}, p.readOnlyZones)
+
+	require.Len(t, stub.sent["foobar.com."], 1)
+	probe := stub.sent["foobar.com."][0]
+	require.Len(t, probe.Answer, 1)
+	assert.Equal(t, "_external-dns-update-check.foobar.com.", probe.Answer[0].Header().Name)
+	assert.Equal(t, uint16(dns.ClassNONE), probe.Answer[0].Header().Class)
+	require.Len(t, probe.Ns, 1)
+	assert.Equal(t, "_external-dns-update-check.foobar.com.", probe.Ns[0].Header().Name)
+	assert.Equal(t, dns.TypeTXT, probe.Ns[0].Header().Rrtype)
+	assert.Equal(t, uint16(dns.ClassANY), probe.Ns[0].Header().Class)
+
+	// the changes of the read-only zones are skipped
+	require.NoError(t, p.ApplyChanges(context.Background(), &plan.Changes{Create: []*endpoint.Endpoint{
+		endpoint.NewEndpoint("v1.foo.com", endpoint.RecordTypeA, "1.1.1.1"),
+		endpoint.NewEndpoint("v1.foobar.com", endpoint.RecordTypeA, "1.1.1.1"),
+	}}))
+	assert.Len(t, stub.sent["foo.com."], 1)
+	assert.Len(t, stub.sent["foobar.com."], 2)
+}
+
+func TestRfc2136UpdateCheckFailures(t *testing.T) {
+	_, _, err := newUpdateCheckProvider(map[string]error{
+		"foo.com.":    fmt.Errorf("bad return code: %w", errRefused),
+		"foobar.com.": fmt.Errorf("bad return code: %w", errNotAuth),
+	}, false)
+	assert.EqualError(t, err, "every zone refused the updates, check the allow-update or update-policy of the zones: foo.com., foobar.com.")
+
+	_, _, err = newUpdateCheckProvider(map[string]error{
+		"foo.com.": errors.New("connection refused"),
+	}, false)
+	assert.EqualError(t, err, "failed to check the update policy of the zone foo.com.: connection refused")
+}
+
+func TestRfc2136UpdateCheckDryRun(t *testing.T) {
+	p, stub, err := newUpdateCheckProvider(map[string]error{
+		"foo.com.":    fmt.Errorf("bad return code: %w", errRefused),
+		"foobar.com.": fmt.Errorf("bad return code: %w", errRefused),
+	}, true)
+	require.NoError(t, err)
+	assert.Empty(t, p.readOnlyZones)
+	assert.Empty(t, stub.sent)
+}
@@ -119,6 +119,17 @@ func (p *ScalewayProvider) AdjustEndpoints(endpoints []*endpoint.Endpoint) ([]*e
 	return eps, nil
 }
 
+// propertySchema normalizes the provider specific properties whose values from annotations may
+// differ textually from the ones read from Scaleway, e.g. the priority "010" is read as "10".
+var propertySchema = provider.PropertySchema{
+	scalewayPriorityKey: provider.IntProperty,
+}
+
+// PropertyValuesEqual compares the values of provider specific properties semantically.
+func (p *ScalewayProvider) PropertyValuesEqual(name string, previous string, current string) bool {
+	return propertySchema.PropertyValuesEqual(name, previous, current)
+}
+
 // Zones returns the list of hosted zones.
 func (p *ScalewayProvider) Zones(ctx context.Context) ([]*domain.DNSZone, error) {
 	res := []*domain.DNSZone{}
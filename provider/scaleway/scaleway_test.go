@@ -623,3 +623,30 @@ func checkScalewayRecords(rs1, rs2 []*domain.Record) bool {
 	}
 	return total == 0
 }
+
+func TestScalewayProvider_PropertyValuesEqual(t *testing.T) {
+	p := &ScalewayProvider{}
+	current := endpoint.NewEndpoint("mx.example.com", endpoint.RecordTypeMX, "10 mail.example.com").WithProviderSpecific(scalewayPriorityKey, "10")
+
+	for _, tc := range []struct {
+		priority     string
+		shouldUpdate bool
+	}{
+		{"10", false},
+		{"010", false},
+		{"+10", false},
+		{"20", true},
+	} {
+		t.Run(tc.priority, func(t *testing.T) {
+			desired := endpoint.NewEndpoint("mx.example.com", endpoint.RecordTypeMX, "10 mail.example.com").WithProviderSpecific(scalewayPriorityKey, tc.priority)
+			changes := (&plan.Plan{
+				Policies:           []plan.Policy{&plan.SyncPolicy{}},
+				Current:            []*endpoint.Endpoint{current},
+				Desired:            []*endpoint.Endpoint{desired},
+				ManagedRecords:     []string{endpoint.RecordTypeMX},
+				PropertyComparator: p.PropertyValuesEqual,
+			}).Calculate().Changes
+			assert.Equal(t, tc.shouldUpdate, len(changes.UpdateNew) == 1)
+		})
+	}
+}
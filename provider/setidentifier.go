@@ -0,0 +1,133 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"sort"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+// SetIdentifierGroup is the endpoints of a DNS name and record type told apart by their set
+// identifier, which some providers store as a single record, e.g. the answer groups of an NS1
+// record.
+type SetIdentifierGroup struct {
+	DNSName    string
+	RecordType string
+	// Endpoints are sorted by set identifier
+	Endpoints []*endpoint.Endpoint
+}
+
+// SetIdentifierChanges are the groups of endpoints to create, update and delete.
+type SetIdentifierChanges struct {
+	Create []*SetIdentifierGroup
+	Update []*SetIdentifierGroup
+	Delete []*SetIdentifierGroup
+}
+
+type setIdentifierGroupKey struct {
+	dnsName    string
+	recordType string
+}
+
+// GroupChangesBySetIdentifier returns the groups of the endpoints touched by the changes, with the
+// endpoints they have once the changes are applied to the current endpoints. A provider storing a
+// group as a single record writes the whole group, so that changing the endpoint of one set
+// identifier keeps the endpoints of the others. The groups of the deleted records have the
+// endpoints they had before the changes.
+func GroupChangesBySetIdentifier(current []*endpoint.Endpoint, changes *plan.Changes) *SetIdentifierChanges {
+	groupKey := func(ep *endpoint.Endpoint) setIdentifierGroupKey {
+		return setIdentifierGroupKey{endpoint.CanonicalDNSName(ep.DNSName), ep.RecordType}
+	}
+
+	touched := map[setIdentifierGroupKey]bool{}
+	var order []setIdentifierGroupKey
+	for _, endpoints := range [][]*endpoint.Endpoint{changes.Create, changes.UpdateNew, changes.UpdateOld, changes.Delete} {
+		for _, ep := range endpoints {
+			if key := groupKey(ep); !touched[key] {
+				touched[key] = true
+				order = append(order, key)
+			}
+		}
+	}
+
+	// the previous endpoints of the changes are current, even if the provider didn't list them
+	before := map[setIdentifierGroupKey]map[string]*endpoint.Endpoint{}
+	for _, endpoints := range [][]*endpoint.Endpoint{current, changes.UpdateOld, changes.Delete} {
+		for _, ep := range endpoints {
+			key := groupKey(ep)
+			if !touched[key] {
+				continue
+			}
+			if before[key] == nil {
+				before[key] = map[string]*endpoint.Endpoint{}
+			}
+			before[key][ep.SetIdentifier] = ep
+		}
+	}
+
+	after := make(map[setIdentifierGroupKey]map[string]*endpoint.Endpoint, len(before))
+	for key, endpoints := range before {
+		after[key] = make(map[string]*endpoint.Endpoint, len(endpoints))
+		for setIdentifier, ep := range endpoints {
+			after[key][setIdentifier] = ep
+		}
+	}
+	for _, endpoints := range [][]*endpoint.Endpoint{changes.UpdateOld, changes.Delete} {
+		for _, ep := range endpoints {
+			delete(after[groupKey(ep)], ep.SetIdentifier)
+		}
+	}
+	for _, endpoints := range [][]*endpoint.Endpoint{changes.Create, changes.UpdateNew} {
+		for _, ep := range endpoints {
+			key := groupKey(ep)
+			if after[key] == nil {
+				after[key] = map[string]*endpoint.Endpoint{}
+			}
+			after[key][ep.SetIdentifier] = ep
+		}
+	}
+
+	result := &SetIdentifierChanges{}
+	for _, key := range order {
+		switch {
+		case len(before[key]) == 0 && len(after[key]) > 0:
+			result.Create = append(result.Create, newSetIdentifierGroup(after[key]))
+		case len(before[key]) > 0 && len(after[key]) == 0:
+			result.Delete = append(result.Delete, newSetIdentifierGroup(before[key]))
+		case len(after[key]) > 0:
+			result.Update = append(result.Update, newSetIdentifierGroup(after[key]))
+		}
+	}
+	return result
+}
+
+// newSetIdentifierGroup returns the group of the endpoints keyed by set identifier, which must not
+// be empty.
+func newSetIdentifierGroup(endpoints map[string]*endpoint.Endpoint) *SetIdentifierGroup {
+	group := &SetIdentifierGroup{Endpoints: make([]*endpoint.Endpoint, 0, len(endpoints))}
+	for _, ep := range endpoints {
+		group.Endpoints = append(group.Endpoints, ep)
+	}
+	sort.Slice(group.Endpoints, func(i, j int) bool {
+		return group.Endpoints[i].SetIdentifier < group.Endpoints[j].SetIdentifier
+	})
+	group.DNSName = group.Endpoints[0].DNSName
+	group.RecordType = group.Endpoints[0].RecordType
+	return group
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+func TestGroupChangesBySetIdentifier(t *testing.T) {
+	answer := func(name, setIdentifier, target string) *endpoint.Endpoint {
+		return endpoint.NewEndpoint(name, endpoint.RecordTypeA, target).WithSetIdentifier(setIdentifier)
+	}
+	eu := answer("app.example.org", "eu", "1.1.1.1")
+	us := answer("app.example.org", "us", "2.2.2.2")
+	usNew := answer("app.example.org", "us", "3.3.3.3")
+	old := answer("old.example.org", "eu", "4.4.4.4")
+	other := answer("other.example.org", "", "5.5.5.5")
+
+	for _, tt := range []struct {
+		title    string
+		current  []*endpoint.Endpoint
+		changes  *plan.Changes
+		expected *SetIdentifierChanges
+	}{
+		{
+			title:   "created next to another set identifier",
+			current: []*endpoint.Endpoint{eu, other},
+			changes: &plan.Changes{Create: []*endpoint.Endpoint{us}},
+			expected: &SetIdentifierChanges{
+				Update: []*SetIdentifierGroup{{DNSName: "app.example.org", RecordType: endpoint.RecordTypeA, Endpoints: []*endpoint.Endpoint{eu, us}}},
+			},
+		},
+		{
+			title:   "updated next to another set identifier",
+			current: []*endpoint.Endpoint{eu, us},
+			changes: &plan.Changes{UpdateOld: []*endpoint.Endpoint{us}, UpdateNew: []*endpoint.Endpoint{usNew}},
+			expected: &SetIdentifierChanges{
+				Update: []*SetIdentifierGroup{{DNSName: "app.example.org", RecordType: endpoint.RecordTypeA, Endpoints: []*endpoint.Endpoint{eu, usNew}}},
+			},
+		},
+		{
+			title:   "deleted next to another set identifier",
+			current: []*endpoint.Endpoint{eu, us},
+			changes: &plan.Changes{Delete: []*endpoint.Endpoint{eu}},
+			expected: &SetIdentifierChanges{
+				Update: []*SetIdentifierGroup{{DNSName: "app.example.org", RecordType: endpoint.RecordTypeA, Endpoints: []*endpoint.Endpoint{us}}},
+			},
+		},
+		{
+			title:   "last set identifier deleted",
+			current: []*endpoint.Endpoint{eu, old},
+			changes: &plan.Changes{Delete: []*endpoint.Endpoint{old}},
+			expected: &SetIdentifierChanges{
+				Delete: []*SetIdentifierGroup{{DNSName: "old.example.org", RecordType: endpoint.RecordTypeA, Endpoints: []*endpoint.Endpoint{old}}},
+			},
+		},
+		{
+			title:   "created without current records",
+			changes: &plan.Changes{Create: []*endpoint.Endpoint{us, eu, other}},
+			expected: &SetIdentifierChanges{
+				Create: []*SetIdentifierGroup{
+					{DNSName: "app.example.org", RecordType: endpoint.RecordTypeA, Endpoints: []*endpoint.Endpoint{eu, us}},
+					{DNSName: "other.example.org", RecordType: endpoint.RecordTypeA, Endpoints: []*endpoint.Endpoint{other}},
+				},
+			},
+		},
+		{
+			title:   "updated without current records",
+			changes: &plan.Changes{UpdateOld: []*endpoint.Endpoint{us}, UpdateNew: []*endpoint.Endpoint{usNew}},
+			expected: &SetIdentifierChanges{
+				Update: []*SetIdentifierGroup{{DNSName: "app.example.org", RecordType: endpoint.RecordTypeA, Endpoints: []*endpoint.Endpoint{usNew}}},
+			},
+		},
+	} {
+		t.Run(tt.title, func(t *testing.T) {
+			assert.Equal(t, tt.expected, GroupChangesBySetIdentifier(tt.current, tt.changes))
+		})
+	}
+}
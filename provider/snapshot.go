@@ -0,0 +1,110 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+// RecordsSnapshot is the records of a provider at a point in time, including the ownership
+// records of the registry, so the changes can be planned against them offline.
+type RecordsSnapshot struct {
+	Provider string               `json:"provider"`
+	TakenAt  time.Time            `json:"takenAt"`
+	Records  []*endpoint.Endpoint `json:"records"`
+}
+
+// SaveRecordsSnapshot writes the records of the provider as JSON to the file.
+func SaveRecordsSnapshot(ctx context.Context, p Provider, providerName, path string) (*RecordsSnapshot, error) {
+	records, err := p.Records(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the records of the provider: %w", err)
+	}
+	snapshot := &RecordsSnapshot{Provider: providerName, TakenAt: time.Now().UTC(), Records: records}
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode records snapshot: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write records snapshot: %w", err)
+	}
+	return snapshot, nil
+}
+
+// LoadRecordsSnapshot reads the records snapshot of the file.
+func LoadRecordsSnapshot(path string) (*RecordsSnapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read records snapshot: %w", err)
+	}
+	var snapshot RecordsSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to decode records snapshot %s: %w", path, err)
+	}
+	return &snapshot, nil
+}
+
+// SnapshotProvider lists the records of a snapshot instead of calling a provider, and only logs
+// the changes, to dry-run against the records of production without its credentials.
+type SnapshotProvider struct {
+	BaseProvider
+	snapshot     *RecordsSnapshot
+	domainFilter endpoint.DomainFilter
+}
+
+// NewSnapshotProvider returns a provider serving the records of the snapshot file.
+func NewSnapshotProvider(path string, domainFilter endpoint.DomainFilter) (*SnapshotProvider, error) {
+	snapshot, err := LoadRecordsSnapshot(path)
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("Planning against the %d records of the %s provider taken at %s", len(snapshot.Records), snapshot.Provider, snapshot.TakenAt.Format(time.RFC3339))
+	return &SnapshotProvider{snapshot: snapshot, domainFilter: domainFilter}, nil
+}
+
+// Records returns copies of the records of the snapshot, so the registry can't alter them.
+func (p *SnapshotProvider) Records(context.Context) ([]*endpoint.Endpoint, error) {
+	return copyEndpoints(p.snapshot.Records), nil
+}
+
+// ApplyChanges logs the changes without applying them.
+func (p *SnapshotProvider) ApplyChanges(_ context.Context, changes *plan.Changes) error {
+	for _, e := range changes.Create {
+		log.Infof("Desired change: CREATE %s %s %s", e.DNSName, e.RecordType, e.Targets)
+	}
+	for _, e := range changes.UpdateNew {
+		log.Infof("Desired change: UPDATE %s %s %s", e.DNSName, e.RecordType, e.Targets)
+	}
+	for _, e := range changes.Delete {
+		log.Infof("Desired change: DELETE %s %s %s", e.DNSName, e.RecordType, e.Targets)
+	}
+	return nil
+}
+
+// GetDomainFilter returns the domain filter of the records of the snapshot.
+func (p *SnapshotProvider) GetDomainFilter() endpoint.DomainFilter {
+	return p.domainFilter
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+func TestRecordsSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "records.json")
+	inner := &flakyProvider{records: []*endpoint.Endpoint{
+		endpoint.NewEndpoint("a.example.org", endpoint.RecordTypeA, "1.2.3.4"),
+		endpoint.NewEndpoint("a-a.example.org", endpoint.RecordTypeTXT, "\"heritage=external-dns,external-dns/owner=default\""),
+	}}
+
+	saved, err := SaveRecordsSnapshot(context.Background(), inner, "aws", path)
+	require.NoError(t, err)
+	assert.Len(t, saved.Records, 2)
+
+	p, err := NewSnapshotProvider(path, endpoint.NewDomainFilter([]string{"example.org"}))
+	require.NoError(t, err)
+	assert.Equal(t, endpoint.NewDomainFilter([]string{"example.org"}), p.GetDomainFilter())
+
+	records, err := p.Records(context.Background())
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "a.example.org", records[0].DNSName)
+	assert.Equal(t, endpoint.Targets{"1.2.3.4"}, records[0].Targets)
+	assert.Equal(t, endpoint.RecordTypeTXT, records[1].RecordType)
+
+	// the records of the snapshot are unaltered by the registry and the changes
+	records[0].Labels = endpoint.Labels{endpoint.OwnerLabelKey: "default"}
+	require.NoError(t, p.ApplyChanges(context.Background(), &plan.Changes{Delete: records[:1]}))
+	records, err = p.Records(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, records, 2)
+	assert.Empty(t, records[0].Labels)
+}
+
+func TestRecordsSnapshotErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := SaveRecordsSnapshot(context.Background(), &flakyProvider{err: errors.New("access denied")}, "aws", filepath.Join(dir, "records.json"))
+	assert.EqualError(t, err, "failed to list the records of the provider: access denied")
+
+	_, err = NewSnapshotProvider(filepath.Join(dir, "missing.json"), endpoint.DomainFilter{})
+	assert.ErrorContains(t, err, "failed to read records snapshot")
+
+	invalid := filepath.Join(dir, "invalid.json")
+	require.NoError(t, os.WriteFile(invalid, []byte("[}"), 0o600))
+	_, err = NewSnapshotProvider(invalid, endpoint.DomainFilter{})
+	assert.ErrorContains(t, err, "failed to decode records snapshot")
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package technitium
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/linki/instrumented_http"
+)
+
+// technitiumAPI is the subset of the HTTP API of Technitium DNS Server used by the provider.
+type technitiumAPI interface {
+	listZones(ctx context.Context) ([]technitiumZone, error)
+	listRecords(ctx context.Context, zone string) ([]technitiumRecord, error)
+	// addRecord adds the record to the zone, replacing the records of the same name and type with overwrite.
+	// The default TTL of the server is used for records without TTL.
+	addRecord(ctx context.Context, zone string, record technitiumRecord, overwrite bool) error
+	deleteRecord(ctx context.Context, zone string, record technitiumRecord) error
+}
+
+type technitiumZone struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Internal bool   `json:"internal"`
+	Disabled bool   `json:"disabled"`
+}
+
+type technitiumRecord struct {
+	Name     string          `json:"name"`
+	Type     string          `json:"type"`
+	TTL      int64           `json:"ttl"`
+	Disabled bool            `json:"disabled"`
+	RData    technitiumRData `json:"rData"`
+}
+
+// technitiumRData holds the fields of the data of the record types managed by the provider, named like
+// the parameters of the API.
+type technitiumRData struct {
+	IPAddress  string `json:"ipAddress,omitempty"`
+	CNAME      string `json:"cname,omitempty"`
+	Text       string `json:"text,omitempty"`
+	Preference uint16 `json:"preference,omitempty"`
+	Exchange   string `json:"exchange,omitempty"`
+	Priority   uint16 `json:"priority,omitempty"`
+	Weight     uint16 `json:"weight,omitempty"`
+	Port       uint16 `json:"port,omitempty"`
+	Target     string `json:"target,omitempty"`
+	NameServer string `json:"nameServer,omitempty"`
+	PTRName    string `json:"ptrName,omitempty"`
+}
+
+// values returns the parameters identifying the data of the record in the API.
+func (r technitiumRecord) values() url.Values {
+	v := url.Values{}
+	v.Set("domain", r.Name)
+	v.Set("type", r.Type)
+	switch r.Type {
+	case "A", "AAAA":
+		v.Set("ipAddress", r.RData.IPAddress)
+	case "CNAME":
+		v.Set("cname", r.RData.CNAME)
+	case "TXT":
+		v.Set("text", r.RData.Text)
+	case "MX":
+		v.Set("preference", strconv.Itoa(int(r.RData.Preference)))
+		v.Set("exchange", r.RData.Exchange)
+	case "SRV":
+		v.Set("priority", strconv.Itoa(int(r.RData.Priority)))
+		v.Set("weight", strconv.Itoa(int(r.RData.Weight)))
+		v.Set("port", strconv.Itoa(int(r.RData.Port)))
+		v.Set("target", r.RData.Target)
+	case "NS":
+		v.Set("nameServer", r.RData.NameServer)
+	case "PTR":
+		v.Set("ptrName", r.RData.PTRName)
+	}
+	return v
+}
+
+type technitiumResponse struct {
+	Status       string          `json:"status"`
+	ErrorMessage string          `json:"errorMessage"`
+	Response     json.RawMessage `json:"response"`
+}
+
+// technitiumClient implements technitiumAPI with an API token, sent with every request.
+type technitiumClient struct {
+	server     string
+	token      string
+	httpClient *http.Client
+}
+
+func newTechnitiumClient(cfg TechnitiumConfig) *technitiumClient {
+	httpClient := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: cfg.TLSInsecureSkipVerify,
+			},
+		},
+	}
+	return &technitiumClient{
+		server:     strings.TrimSuffix(cfg.Server, "/"),
+		token:      cfg.Token,
+		httpClient: instrumented_http.NewClient(httpClient, &instrumented_http.Callbacks{}),
+	}
+}
+
+func (c *technitiumClient) listZones(ctx context.Context) ([]technitiumZone, error) {
+	var res struct {
+		Zones []technitiumZone `json:"zones"`
+	}
+	if err := c.do(ctx, "/api/zones/list", url.Values{}, &res); err != nil {
+		return nil, err
+	}
+	return res.Zones, nil
+}
+
+func (c *technitiumClient) listRecords(ctx context.Context, zone string) ([]technitiumRecord, error) {
+	var res struct {
+		Records []technitiumRecord `json:"records"`
+	}
+	params := url.Values{}
+	params.Set("domain", zone)
+	params.Set("zone", zone)
+	params.Set("listZone", "true")
+	if err := c.do(ctx, "/api/zones/records/get", params, &res); err != nil {
+		return nil, err
+	}
+	return res.Records, nil
+}
+
+func (c *technitiumClient) addRecord(ctx context.Context, zone string, record technitiumRecord, overwrite bool) error {
+	params := record.values()
+	params.Set("zone", zone)
+	if record.TTL > 0 {
+		params.Set("ttl", strconv.FormatInt(record.TTL, 10))
+	}
+	params.Set("overwrite", strconv.FormatBool(overwrite))
+	return c.do(ctx, "/api/zones/records/add", params, nil)
+}
+
+func (c *technitiumClient) deleteRecord(ctx context.Context, zone string, record technitiumRecord) error {
+	params := record.values()
+	params.Set("zone", zone)
+	return c.do(ctx, "/api/zones/records/delete", params, nil)
+}
+
+// do posts the parameters as a form, keeping the token out of the URLs logged by proxies, and decodes
+// the response of a successful call into out.
+func (c *technitiumClient) do(ctx context.Context, path string, params url.Values, out interface{}) error {
+	params.Set("token", c.token)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.server+path, strings.NewReader(params.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("received non-2xx status code from %s: %s", path, res.Status)
+	}
+
+	// the API answers errors with a 200 and their status in the body
+	var body technitiumResponse
+	if err := json.Unmarshal(raw, &body); err != nil {
+		return fmt.Errorf("failed to decode the response of %s: %w", path, err)
+	}
+	switch body.Status {
+	case "ok":
+	case "invalid-token":
+		return fmt.Errorf("the Technitium API token is invalid or expired: %s", body.ErrorMessage)
+	default:
+		return fmt.Errorf("%s failed with status %s: %s", path, body.Status, body.ErrorMessage)
+	}
+
+	if out == nil || len(body.Response) == 0 {
+		return nil
+	}
+	return json.Unmarshal(body.Response, out)
+}
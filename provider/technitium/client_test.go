@@ -0,0 +1,172 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package technitium
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTechnitium serves the zones and records of the API of Technitium DNS Server used by the provider.
+type fakeTechnitium struct {
+	t       *testing.T
+	token   string
+	zones   []technitiumZone
+	records map[string][]technitiumRecord
+	calls   []string
+}
+
+func (f *fakeTechnitium) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	require.NoError(f.t, r.ParseForm())
+	reply := func(response interface{}) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok", "response": response})
+	}
+	if r.Method != http.MethodPost || r.PostForm.Get("token") != f.token {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"status": "invalid-token", "errorMessage": "Invalid token or session expired."})
+		return
+	}
+
+	zone := r.PostForm.Get("zone")
+	switch r.URL.Path {
+	case "/api/zones/list":
+		reply(map[string]interface{}{"zones": f.zones})
+	case "/api/zones/records/get":
+		assert.Equal(f.t, "true", r.PostForm.Get("listZone"))
+		reply(map[string]interface{}{"records": f.records[zone]})
+	case "/api/zones/records/add":
+		record := formRecord(r)
+		record.TTL = 3600
+		if ttl := r.PostForm.Get("ttl"); ttl != "" {
+			record.TTL, _ = strconv.ParseInt(ttl, 10, 64)
+		}
+		if r.PostForm.Get("overwrite") == "true" {
+			f.remove(zone, func(rr technitiumRecord) bool { return rr.Name == record.Name && rr.Type == record.Type })
+		}
+		f.records[zone] = append(f.records[zone], record)
+		f.calls = append(f.calls, "add "+r.PostForm.Get("overwrite")+" "+record.Name+" "+record.Type+" "+record.target())
+		reply(map[string]interface{}{})
+	case "/api/zones/records/delete":
+		record := formRecord(r)
+		f.remove(zone, func(rr technitiumRecord) bool {
+			return rr.Name == record.Name && rr.Type == record.Type && rr.RData == record.RData
+		})
+		f.calls = append(f.calls, "delete "+record.Name+" "+record.Type+" "+record.target())
+		reply(nil)
+	default:
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"status": "error", "errorMessage": "Invalid API call."})
+	}
+}
+
+func (f *fakeTechnitium) remove(zone string, match func(technitiumRecord) bool) {
+	var kept []technitiumRecord
+	for _, record := range f.records[zone] {
+		if !match(record) {
+			kept = append(kept, record)
+		}
+	}
+	f.records[zone] = kept
+}
+
+// formRecord reads the record from the parameters of the request like the server.
+func formRecord(r *http.Request) technitiumRecord {
+	number := func(name string) uint16 {
+		v, _ := strconv.ParseUint(r.PostForm.Get(name), 10, 16)
+		return uint16(v)
+	}
+	return technitiumRecord{
+		Name: r.PostForm.Get("domain"),
+		Type: r.PostForm.Get("type"),
+		RData: technitiumRData{
+			IPAddress:  r.PostForm.Get("ipAddress"),
+			CNAME:      r.PostForm.Get("cname"),
+			Text:       r.PostForm.Get("text"),
+			Preference: number("preference"),
+			Exchange:   r.PostForm.Get("exchange"),
+			Priority:   number("priority"),
+			Weight:     number("weight"),
+			Port:       number("port"),
+			Target:     r.PostForm.Get("target"),
+			NameServer: r.PostForm.Get("nameServer"),
+			PTRName:    r.PostForm.Get("ptrName"),
+		},
+	}
+}
+
+func newFakeTechnitium(t *testing.T) (*fakeTechnitium, *httptest.Server) {
+	f := &fakeTechnitium{
+		t:     t,
+		token: "token",
+		zones: []technitiumZone{
+			{Name: "example.com", Type: "Primary"},
+			{Name: "example.org", Type: "Secondary"},
+			{Name: "localhost", Type: "Primary", Internal: true},
+		},
+		records: map[string][]technitiumRecord{
+			"example.com": {
+				{Name: "example.com", Type: "SOA", TTL: 900},
+				{Name: "www.example.com", Type: "A", TTL: 300, RData: technitiumRData{IPAddress: "10.0.0.1"}},
+				{Name: "www.example.com", Type: "A", TTL: 300, RData: technitiumRData{IPAddress: "10.0.0.2"}},
+				{Name: "www.example.com", Type: "TXT", TTL: 300, RData: technitiumRData{Text: "heritage=external-dns,external-dns/owner=default"}},
+				{Name: "old.example.com", Type: "A", TTL: 300, Disabled: true, RData: technitiumRData{IPAddress: "10.0.0.9"}},
+				{Name: "example.com", Type: "MX", TTL: 3600, RData: technitiumRData{Preference: 10, Exchange: "mail.example.com"}},
+				{Name: "_sip._tcp.example.com", Type: "SRV", TTL: 3600, RData: technitiumRData{Priority: 10, Weight: 5, Port: 5060, Target: "sip.example.com"}},
+				{Name: "split.example.com", Type: "APP", TTL: 15},
+			},
+		},
+	}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	return f, server
+}
+
+func TestTechnitiumClient(t *testing.T) {
+	f, server := newFakeTechnitium(t)
+	c := newTechnitiumClient(TechnitiumConfig{Server: server.URL + "/", Token: "token"})
+	ctx := context.Background()
+
+	zones, err := c.listZones(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, f.zones, zones)
+
+	records, err := c.listRecords(ctx, "example.com")
+	require.NoError(t, err)
+	assert.Equal(t, f.records["example.com"], records)
+
+	record := technitiumRecord{Name: "app.example.com", Type: "CNAME", RData: technitiumRData{CNAME: "www.example.com"}}
+	require.NoError(t, c.addRecord(ctx, "example.com", record, false))
+	require.NoError(t, c.deleteRecord(ctx, "example.com", record))
+	assert.Equal(t, []string{"add false app.example.com CNAME www.example.com", "delete app.example.com CNAME www.example.com"}, f.calls)
+}
+
+func TestTechnitiumClientErrors(t *testing.T) {
+	_, server := newFakeTechnitium(t)
+	ctx := context.Background()
+
+	_, err := newTechnitiumClient(TechnitiumConfig{Server: server.URL, Token: "expired"}).listZones(ctx)
+	assert.ErrorContains(t, err, "token is invalid or expired")
+
+	err = newTechnitiumClient(TechnitiumConfig{Server: server.URL, Token: "token"}).do(ctx, "/api/unknown", url.Values{}, nil)
+	assert.ErrorContains(t, err, "Invalid API call.")
+}
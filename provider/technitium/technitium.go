@@ -0,0 +1,317 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package technitium
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+var (
+	// ErrNoTechnitiumServer is returned when there is no Technitium DNS Server configured.
+	ErrNoTechnitiumServer = errors.New("no Technitium DNS Server specified")
+	// ErrNoTechnitiumToken is returned when there is no API token configured.
+	ErrNoTechnitiumToken = errors.New("no Technitium API token specified")
+)
+
+// recordTypeAPP is the type of the records of the DNS apps of Technitium, answered by the app.
+const recordTypeAPP = "APP"
+
+// TechnitiumConfig is used for configuring a TechnitiumProvider.
+type TechnitiumConfig struct {
+	// The URL of the web console of the server, e.g. http://dns.example.org:5380.
+	Server string
+	// An API token created in the web console.
+	Token string
+	// Disable verification of TLS certificates.
+	TLSInsecureSkipVerify bool
+	// A filter to apply when looking up and applying records.
+	DomainFilter endpoint.DomainFilter
+	// Do nothing and log what would have changed to stdout.
+	DryRun bool
+}
+
+// TechnitiumProvider is an implementation of Provider for the primary and forwarder zones of
+// Technitium DNS Server.
+type TechnitiumProvider struct {
+	provider.BaseProvider
+	api          technitiumAPI
+	domainFilter endpoint.DomainFilter
+	dryRun       bool
+}
+
+// NewTechnitiumProvider initializes a new Technitium DNS Server based Provider.
+func NewTechnitiumProvider(cfg TechnitiumConfig) (*TechnitiumProvider, error) {
+	if cfg.Server == "" {
+		return nil, ErrNoTechnitiumServer
+	}
+	if cfg.Token == "" {
+		return nil, ErrNoTechnitiumToken
+	}
+	return &TechnitiumProvider{
+		api:          newTechnitiumClient(cfg),
+		domainFilter: cfg.DomainFilter,
+		dryRun:       cfg.DryRun,
+	}, nil
+}
+
+// GetDomainFilter returns the domain filter of the provider.
+func (p *TechnitiumProvider) GetDomainFilter() endpoint.DomainFilter {
+	return p.domainFilter
+}
+
+// zones returns the names of the zones the provider manages records in. Secondary and stub zones are
+// read-only and the internal zones, e.g. localhost, are built into the server.
+func (p *TechnitiumProvider) zones(ctx context.Context) ([]string, error) {
+	zones, err := p.api.listZones(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the Technitium zones: %w", err)
+	}
+
+	var names []string
+	for _, zone := range zones {
+		if zone.Internal || (zone.Type != "Primary" && zone.Type != "Forwarder") {
+			continue
+		}
+		if !p.domainFilter.Match(zone.Name) {
+			log.Debugf("Skipping zone %s that does not match domain filter", zone.Name)
+			continue
+		}
+		names = append(names, zone.Name)
+	}
+	return names, nil
+}
+
+// Records implements Provider, populating a slice of endpoints from the enabled records of the zones.
+func (p *TechnitiumProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	zones, err := p.zones(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var endpoints []*endpoint.Endpoint
+	for _, zone := range zones {
+		records, err := p.api.listRecords(ctx, zone)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the records of zone %s: %w", zone, err)
+		}
+
+		byKey := map[string]*endpoint.Endpoint{}
+		for _, record := range records {
+			if record.Disabled {
+				continue
+			}
+			if record.Type == recordTypeAPP {
+				log.Debugf("Ignoring the APP record %s answered by a DNS app", record.Name)
+				continue
+			}
+			if !supported(record.Type) {
+				continue
+			}
+			target := record.target()
+			key := record.Name + "/" + record.Type
+			if ep, ok := byKey[key]; ok {
+				ep.Targets = append(ep.Targets, target)
+				continue
+			}
+			ep := endpoint.NewEndpointWithTTL(record.Name, record.Type, endpoint.TTL(record.TTL), target)
+			byKey[key] = ep
+			endpoints = append(endpoints, ep)
+		}
+	}
+	return endpoints, nil
+}
+
+// AdjustEndpoints drops the records of the types not managed by the provider.
+func (p *TechnitiumProvider) AdjustEndpoints(endpoints []*endpoint.Endpoint) ([]*endpoint.Endpoint, error) {
+	adjusted := make([]*endpoint.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		if !supported(ep.RecordType) {
+			log.Warnf("Skipping record %s of unsupported type %s", ep.DNSName, ep.RecordType)
+			continue
+		}
+		adjusted = append(adjusted, ep)
+	}
+	return adjusted, nil
+}
+
+// ApplyChanges implements Provider. The records of an update replace all the records of the same
+// name and type at once, the old records don't need to be deleted.
+func (p *TechnitiumProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	if !changes.HasChanges() {
+		return nil
+	}
+
+	zones, err := p.zones(ctx)
+	if err != nil {
+		return err
+	}
+	zoneNames := provider.ZoneIDName{}
+	for _, zone := range zones {
+		zoneNames.Add(zone, zone)
+	}
+
+	for _, ep := range changes.Delete {
+		if err := p.apply(ctx, zoneNames, "delete", ep, false); err != nil {
+			return err
+		}
+	}
+	for _, ep := range changes.UpdateNew {
+		if err := p.apply(ctx, zoneNames, "update", ep, true); err != nil {
+			return err
+		}
+	}
+	for _, ep := range changes.Create {
+		if err := p.apply(ctx, zoneNames, "create", ep, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// apply deletes or adds the records of the endpoint, the first added one replacing the existing
+// records with overwrite.
+func (p *TechnitiumProvider) apply(ctx context.Context, zoneNames provider.ZoneIDName, action string, ep *endpoint.Endpoint, overwrite bool) error {
+	_, zone := zoneNames.FindZone(ep.DNSName)
+	if zone == "" {
+		log.Debugf("Skipping %s of record %s that is not in a zone", action, ep.DNSName)
+		return nil
+	}
+
+	for i, target := range ep.Targets {
+		record, err := newRecord(ep, target)
+		if err != nil {
+			log.Warnf("Skipping %s of record %s: %v", action, ep.DNSName, err)
+			continue
+		}
+		log.Infof("%s%s %s IN %s -> %s in zone %s", p.dryRunPrefix(), action, ep.DNSName, ep.RecordType, target, zone)
+		if p.dryRun {
+			continue
+		}
+
+		if action == "delete" {
+			err = p.api.deleteRecord(ctx, zone, record)
+		} else {
+			err = p.api.addRecord(ctx, zone, record, overwrite && i == 0)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to %s record %s %s: %w", action, ep.DNSName, ep.RecordType, err)
+		}
+	}
+	return nil
+}
+
+func (p *TechnitiumProvider) dryRunPrefix() string {
+	if p.dryRun {
+		return "DRY RUN: "
+	}
+	return ""
+}
+
+func supported(recordType string) bool {
+	switch recordType {
+	case endpoint.RecordTypeA, endpoint.RecordTypeAAAA, endpoint.RecordTypeCNAME, endpoint.RecordTypeTXT,
+		endpoint.RecordTypeMX, endpoint.RecordTypeSRV, endpoint.RecordTypeNS, endpoint.RecordTypePTR:
+		return true
+	}
+	return false
+}
+
+// target returns the record data in the format of the targets of endpoints.
+func (r technitiumRecord) target() string {
+	d := r.RData
+	switch r.Type {
+	case endpoint.RecordTypeA, endpoint.RecordTypeAAAA:
+		return d.IPAddress
+	case endpoint.RecordTypeCNAME:
+		return strings.TrimSuffix(d.CNAME, ".")
+	case endpoint.RecordTypeTXT:
+		return d.Text
+	case endpoint.RecordTypeMX:
+		return fmt.Sprintf("%d %s", d.Preference, strings.TrimSuffix(d.Exchange, "."))
+	case endpoint.RecordTypeSRV:
+		return fmt.Sprintf("%d %d %d %s", d.Priority, d.Weight, d.Port, strings.TrimSuffix(d.Target, "."))
+	case endpoint.RecordTypeNS:
+		return strings.TrimSuffix(d.NameServer, ".")
+	case endpoint.RecordTypePTR:
+		return strings.TrimSuffix(d.PTRName, ".")
+	}
+	return ""
+}
+
+// newRecord returns the record of one of the targets of the endpoint.
+func newRecord(ep *endpoint.Endpoint, target string) (technitiumRecord, error) {
+	record := technitiumRecord{Name: ep.DNSName, Type: ep.RecordType}
+	if ep.RecordTTL.IsConfigured() {
+		record.TTL = int64(ep.RecordTTL)
+	}
+
+	d := &record.RData
+	switch ep.RecordType {
+	case endpoint.RecordTypeA, endpoint.RecordTypeAAAA:
+		d.IPAddress = target
+	case endpoint.RecordTypeCNAME:
+		d.CNAME = target
+	case endpoint.RecordTypeTXT:
+		d.Text = target
+	case endpoint.RecordTypeMX:
+		fields, err := uint16Fields(target, 1)
+		if err != nil {
+			return record, fmt.Errorf("invalid MX target %q: %w", target, err)
+		}
+		d.Preference, d.Exchange = fields[0], strings.Fields(target)[1]
+	case endpoint.RecordTypeSRV:
+		fields, err := uint16Fields(target, 3)
+		if err != nil {
+			return record, fmt.Errorf("invalid SRV target %q: %w", target, err)
+		}
+		d.Priority, d.Weight, d.Port, d.Target = fields[0], fields[1], fields[2], strings.Fields(target)[3]
+	case endpoint.RecordTypeNS:
+		d.NameServer = target
+	case endpoint.RecordTypePTR:
+		d.PTRName = target
+	default:
+		return record, fmt.Errorf("unsupported record type %s", ep.RecordType)
+	}
+	return record, nil
+}
+
+// uint16Fields parses the n leading numbers of a target followed by a domain name.
+func uint16Fields(target string, n int) ([]uint16, error) {
+	fields := strings.Fields(target)
+	if len(fields) != n+1 {
+		return nil, fmt.Errorf("expected %d fields", n+1)
+	}
+	numbers := make([]uint16, n)
+	for i := range numbers {
+		v, err := strconv.ParseUint(fields[i], 10, 16)
+		if err != nil {
+			return nil, err
+		}
+		numbers[i] = uint16(v)
+	}
+	return numbers, nil
+}
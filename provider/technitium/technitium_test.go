@@ -0,0 +1,135 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package technitium
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+func TestNewTechnitiumProvider(t *testing.T) {
+	_, err := NewTechnitiumProvider(TechnitiumConfig{Token: "token"})
+	assert.ErrorIs(t, err, ErrNoTechnitiumServer)
+
+	_, err = NewTechnitiumProvider(TechnitiumConfig{Server: "http://localhost:5380"})
+	assert.ErrorIs(t, err, ErrNoTechnitiumToken)
+}
+
+func TestTechnitiumRecords(t *testing.T) {
+	_, server := newFakeTechnitium(t)
+	p, err := NewTechnitiumProvider(TechnitiumConfig{Server: server.URL, Token: "token"})
+	require.NoError(t, err)
+
+	endpoints, err := p.Records(context.Background())
+	require.NoError(t, err)
+	expected := []*endpoint.Endpoint{
+		endpoint.NewEndpointWithTTL("www.example.com", endpoint.RecordTypeA, 300, "10.0.0.1", "10.0.0.2"),
+		endpoint.NewEndpointWithTTL("www.example.com", endpoint.RecordTypeTXT, 300, "heritage=external-dns,external-dns/owner=default"),
+		endpoint.NewEndpointWithTTL("example.com", endpoint.RecordTypeMX, 3600, "10 mail.example.com"),
+		endpoint.NewEndpointWithTTL("_sip._tcp.example.com", endpoint.RecordTypeSRV, 3600, "10 5 5060 sip.example.com"),
+	}
+	assert.True(t, testutils.SameEndpoints(expected, endpoints), "expected %v, got %v", expected, endpoints)
+
+	p.domainFilter = endpoint.NewDomainFilter([]string{"example.net"})
+	endpoints, err = p.Records(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, endpoints)
+}
+
+func TestTechnitiumApplyChanges(t *testing.T) {
+	f, server := newFakeTechnitium(t)
+	p, err := NewTechnitiumProvider(TechnitiumConfig{Server: server.URL, Token: "token"})
+	require.NoError(t, err)
+
+	changes := &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			endpoint.NewEndpointWithTTL("app.example.com", endpoint.RecordTypeCNAME, 60, "www.example.com"),
+			endpoint.NewEndpoint("app.example.org", endpoint.RecordTypeA, "10.0.1.1"),
+		},
+		UpdateOld: []*endpoint.Endpoint{
+			endpoint.NewEndpointWithTTL("www.example.com", endpoint.RecordTypeA, 300, "10.0.0.1", "10.0.0.2"),
+		},
+		UpdateNew: []*endpoint.Endpoint{
+			endpoint.NewEndpointWithTTL("www.example.com", endpoint.RecordTypeA, 300, "10.0.0.3", "10.0.0.4"),
+		},
+		Delete: []*endpoint.Endpoint{
+			endpoint.NewEndpointWithTTL("_sip._tcp.example.com", endpoint.RecordTypeSRV, 3600, "10 5 5060 sip.example.com"),
+		},
+	}
+	require.NoError(t, p.ApplyChanges(context.Background(), changes))
+	assert.Equal(t, []string{
+		"delete _sip._tcp.example.com SRV 10 5 5060 sip.example.com",
+		"add true www.example.com A 10.0.0.3",
+		"add false www.example.com A 10.0.0.4",
+		"add false app.example.com CNAME www.example.com",
+	}, f.calls)
+
+	endpoints, err := p.Records(context.Background())
+	require.NoError(t, err)
+	expected := []*endpoint.Endpoint{
+		endpoint.NewEndpointWithTTL("www.example.com", endpoint.RecordTypeA, 300, "10.0.0.3", "10.0.0.4"),
+		endpoint.NewEndpointWithTTL("www.example.com", endpoint.RecordTypeTXT, 300, "heritage=external-dns,external-dns/owner=default"),
+		endpoint.NewEndpointWithTTL("example.com", endpoint.RecordTypeMX, 3600, "10 mail.example.com"),
+		endpoint.NewEndpointWithTTL("app.example.com", endpoint.RecordTypeCNAME, 60, "www.example.com"),
+	}
+	assert.True(t, testutils.SameEndpoints(expected, endpoints), "expected %v, got %v", expected, endpoints)
+}
+
+func TestTechnitiumApplyChangesDryRun(t *testing.T) {
+	f, server := newFakeTechnitium(t)
+	p, err := NewTechnitiumProvider(TechnitiumConfig{Server: server.URL, Token: "token", DryRun: true})
+	require.NoError(t, err)
+
+	require.NoError(t, p.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{endpoint.NewEndpoint("app.example.com", endpoint.RecordTypeA, "10.0.1.1")},
+	}))
+	assert.Empty(t, f.calls)
+}
+
+func TestTechnitiumAdjustEndpoints(t *testing.T) {
+	p, err := NewTechnitiumProvider(TechnitiumConfig{Server: "http://localhost:5380", Token: "token"})
+	require.NoError(t, err)
+
+	adjusted, err := p.AdjustEndpoints([]*endpoint.Endpoint{
+		endpoint.NewEndpoint("www.example.com", endpoint.RecordTypeA, "10.0.0.1"),
+		endpoint.NewEndpoint("www.example.com", "NAPTR", "100 10 \"S\" \"SIP+D2U\" \"\" _sip._udp.example.com."),
+	})
+	require.NoError(t, err)
+	assert.Len(t, adjusted, 1)
+}
+
+func TestNewRecord(t *testing.T) {
+	record, err := newRecord(endpoint.NewEndpointWithTTL("_sip._tcp.example.com", endpoint.RecordTypeSRV, 60, "10 5 5060 sip.example.com"), "10 5 5060 sip.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, technitiumRecord{
+		Name:  "_sip._tcp.example.com",
+		Type:  endpoint.RecordTypeSRV,
+		TTL:   60,
+		RData: technitiumRData{Priority: 10, Weight: 5, Port: 5060, Target: "sip.example.com"},
+	}, record)
+	assert.Equal(t, "10 5 5060 sip.example.com", record.target())
+
+	_, err = newRecord(endpoint.NewEndpoint("example.com", endpoint.RecordTypeMX, "mail.example.com"), "mail.example.com")
+	assert.Error(t, err)
+}
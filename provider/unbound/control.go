@@ -0,0 +1,118 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unbound
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// controlVersion is the version of the remote control protocol sent before each command.
+const controlVersion = "UBCT1 "
+
+// controlServerName is the name in the certificate created by unbound-control-setup.
+const controlServerName = "unbound"
+
+// controller runs the commands of unbound-control.
+type controller interface {
+	// run returns the output of the command, failing if unbound answers with an error
+	run(ctx context.Context, command string) (string, error)
+}
+
+// remoteControl speaks the remote control protocol of unbound, over TCP with TLS when the
+// certificates are given, without otherwise, e.g. over a unix socket.
+type remoteControl struct {
+	network   string
+	address   string
+	tlsConfig *tls.Config
+	timeout   time.Duration
+}
+
+// newRemoteControl returns a remote control of the unbound at address, a host:port or the path of a
+// unix socket. With the certificate of the server, the connection uses TLS with the certificate and
+// key of the client.
+func newRemoteControl(address, serverCertFile, controlCertFile, controlKeyFile string) (*remoteControl, error) {
+	rc := &remoteControl{network: "tcp", address: address, timeout: 30 * time.Second}
+	if strings.HasPrefix(address, "/") {
+		rc.network = "unix"
+	}
+	if serverCertFile == "" {
+		return rc, nil
+	}
+
+	serverCert, err := os.ReadFile(serverCertFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the unbound server certificate: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(serverCert) {
+		return nil, fmt.Errorf("no certificate in %s", serverCertFile)
+	}
+	clientCert, err := tls.LoadX509KeyPair(controlCertFile, controlKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the unbound control certificate: %w", err)
+	}
+	rc.tlsConfig = &tls.Config{
+		RootCAs:      pool,
+		Certificates: []tls.Certificate{clientCert},
+		ServerName:   controlServerName,
+		MinVersion:   tls.VersionTLS12,
+	}
+	return rc, nil
+}
+
+// run sends the command on a new connection, unbound closing it after the output.
+func (rc *remoteControl) run(ctx context.Context, command string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, rc.timeout)
+	defer cancel()
+
+	var conn net.Conn
+	var err error
+	if rc.tlsConfig != nil {
+		dialer := &tls.Dialer{Config: rc.tlsConfig}
+		conn, err = dialer.DialContext(ctx, rc.network, rc.address)
+	} else {
+		var dialer net.Dialer
+		conn, err = dialer.DialContext(ctx, rc.network, rc.address)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to unbound: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if _, err := io.WriteString(conn, controlVersion+command+"\n"); err != nil {
+		return "", fmt.Errorf("failed to send %q to unbound: %w", command, err)
+	}
+	output, err := io.ReadAll(conn)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the output of %q: %w", command, err)
+	}
+	if out := string(output); strings.HasPrefix(out, "error") {
+		return "", fmt.Errorf("unbound failed to run %q: %s", command, strings.TrimSpace(out))
+	}
+	return string(output), nil
+}
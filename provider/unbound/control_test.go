@@ -0,0 +1,138 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unbound
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveControl answers the commands received on the listener like the remote control of unbound.
+func serveControl(t *testing.T, l net.Listener) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		line, err := bufio.NewReader(conn).ReadString('\n')
+		if !assert.NoError(t, err) {
+			conn.Close()
+			continue
+		}
+		switch strings.TrimSpace(strings.TrimPrefix(line, controlVersion)) {
+		case "list_local_zones":
+			_, _ = conn.Write([]byte("example.com. transparent\n"))
+		default:
+			_, _ = conn.Write([]byte("error unknown command\n"))
+		}
+		conn.Close()
+	}
+}
+
+// writeCertificate writes a self-signed certificate and its key, like unbound-control-setup.
+func writeCertificate(t *testing.T, dir, name string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile, keyFile := filepath.Join(dir, name+".pem"), filepath.Join(dir, name+".key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
+
+func TestRemoteControl(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	go serveControl(t, l)
+
+	rc, err := newRemoteControl(l.Addr().String(), "", "", "")
+	require.NoError(t, err)
+
+	output, err := rc.run(context.Background(), "list_local_zones")
+	require.NoError(t, err)
+	assert.Equal(t, "example.com. transparent\n", output)
+
+	_, err = rc.run(context.Background(), "unknown")
+	assert.ErrorContains(t, err, "unknown command")
+}
+
+func TestRemoteControlTLS(t *testing.T) {
+	dir := t.TempDir()
+	serverCert, serverKey := writeCertificate(t, dir, "unbound")
+	controlCert, controlKey := writeCertificate(t, dir, "unbound-control")
+
+	cert, err := tls.LoadX509KeyPair(serverCert, serverKey)
+	require.NoError(t, err)
+	clientCAs := x509.NewCertPool()
+	pemData, err := os.ReadFile(controlCert)
+	require.NoError(t, err)
+	clientCAs.AppendCertsFromPEM(pemData)
+
+	l, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    clientCAs,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	})
+	require.NoError(t, err)
+	defer l.Close()
+	go serveControl(t, l)
+
+	rc, err := newRemoteControl(l.Addr().String(), serverCert, controlCert, controlKey)
+	require.NoError(t, err)
+
+	output, err := rc.run(context.Background(), "list_local_zones")
+	require.NoError(t, err)
+	assert.Equal(t, "example.com. transparent\n", output)
+
+	_, err = newRemoteControl(l.Addr().String(), filepath.Join(dir, "missing.pem"), controlCert, controlKey)
+	assert.Error(t, err)
+}
@@ -0,0 +1,377 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unbound
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/miekg/dns"
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+// defaultTTL is the TTL of the records without one.
+const defaultTTL = 300
+
+// ErrNoLocalZones is returned when there are no local zones configured.
+var ErrNoLocalZones = errors.New("no unbound local zones given, set --unbound-local-zone")
+
+// UnboundProvider is an implementation of Provider for unbound. The records are managed as
+// local-data of local zones with unbound-control, the remote control of unbound. unbound doesn't
+// persist them, they are created again after a restart on the next synchronization.
+type UnboundProvider struct {
+	provider.BaseProvider
+	control      controller
+	zones        []string
+	zoneType     string
+	domainFilter endpoint.DomainFilter
+	dryRun       bool
+}
+
+// UnboundConfig is used for configuring an UnboundProvider.
+type UnboundConfig struct {
+	// The address of the remote control of unbound, host:port or the path of a unix socket.
+	ControlAddress string
+	// The certificate of the server, and the certificate and key of the client, created by
+	// unbound-control-setup. The connection doesn't use TLS without the certificate of the server.
+	ServerCertFile  string
+	ControlCertFile string
+	ControlKeyFile  string
+	// The local zones of the records, created with LocalZoneType if missing.
+	LocalZones    []string
+	LocalZoneType string
+	// A filter to apply when looking up and applying records.
+	DomainFilter endpoint.DomainFilter
+	// Do nothing and log what would have changed to stdout.
+	DryRun bool
+}
+
+// unboundKey identifies the records of a name and type.
+type unboundKey struct {
+	DNSName    string
+	RecordType string
+}
+
+// NewUnboundProvider initializes a new unbound based Provider.
+func NewUnboundProvider(cfg UnboundConfig) (*UnboundProvider, error) {
+	control, err := newRemoteControl(cfg.ControlAddress, cfg.ServerCertFile, cfg.ControlCertFile, cfg.ControlKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	return newUnboundProvider(cfg, control)
+}
+
+func newUnboundProvider(cfg UnboundConfig, control controller) (*UnboundProvider, error) {
+	if len(cfg.LocalZones) == 0 {
+		return nil, ErrNoLocalZones
+	}
+	zones := make([]string, 0, len(cfg.LocalZones))
+	for _, zone := range cfg.LocalZones {
+		zones = append(zones, normalize(zone))
+	}
+	return &UnboundProvider{
+		control:      control,
+		zones:        zones,
+		zoneType:     cfg.LocalZoneType,
+		domainFilter: cfg.DomainFilter,
+		dryRun:       cfg.DryRun,
+	}, nil
+}
+
+// GetDomainFilter returns the domain filter of the provider.
+func (p *UnboundProvider) GetDomainFilter() endpoint.DomainFilter {
+	return p.domainFilter
+}
+
+// Records implements Provider, populating a slice of endpoints from the local data of the local zones.
+func (p *UnboundProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	records, _, err := p.readRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoints := make([]*endpoint.Endpoint, 0, len(records))
+	for _, key := range sortedKeys(records) {
+		endpoints = append(endpoints, records[key])
+	}
+	return endpoints, nil
+}
+
+// AdjustEndpoints drops the records of the types not managed by the provider.
+func (p *UnboundProvider) AdjustEndpoints(endpoints []*endpoint.Endpoint) ([]*endpoint.Endpoint, error) {
+	adjusted := make([]*endpoint.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		if !supported(ep.RecordType) {
+			log.Warnf("Skipping record %s of unsupported type %s", ep.DNSName, ep.RecordType)
+			continue
+		}
+		adjusted = append(adjusted, ep)
+	}
+	return adjusted, nil
+}
+
+// ApplyChanges implements Provider. unbound only removes all the local data of a name at once, so
+// the local data of the other types of the names with removed records is added again as it was read.
+func (p *UnboundProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	if !changes.HasChanges() {
+		return nil
+	}
+
+	records, data, err := p.readRecords(ctx)
+	if err != nil {
+		return err
+	}
+	if err := p.ensureZones(ctx); err != nil {
+		return err
+	}
+
+	removed := map[string]bool{}
+	changed := map[unboundKey]bool{}
+	added := map[unboundKey]*endpoint.Endpoint{}
+	for _, ep := range append(append([]*endpoint.Endpoint{}, changes.Delete...), changes.UpdateOld...) {
+		key := unboundKey{normalize(ep.DNSName), ep.RecordType}
+		if _, ok := records[key]; ok {
+			removed[key.DNSName] = true
+			changed[key] = true
+		}
+	}
+	for _, ep := range append(append([]*endpoint.Endpoint{}, changes.Create...), changes.UpdateNew...) {
+		name := normalize(ep.DNSName)
+		if !p.managed(name) {
+			log.Debugf("Skipping record %s that is not in a local zone", ep.DNSName)
+			continue
+		}
+		key := unboundKey{name, ep.RecordType}
+		added[key] = ep
+		changed[key] = true
+	}
+
+	names := make([]string, 0, len(removed))
+	for name := range removed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		log.Infof("Removing the local data of %s", name)
+		if _, err := p.run(ctx, "local_data_remove "+dns.Fqdn(name)); err != nil {
+			return err
+		}
+	}
+	for _, name := range names {
+		for _, rr := range data[name] {
+			if changed[unboundKey{name, dns.TypeToString[rr.Header().Rrtype]}] {
+				continue
+			}
+			if _, err := p.run(ctx, "local_data "+rr.String()); err != nil {
+				return err
+			}
+		}
+	}
+	for _, key := range sortedKeys(added) {
+		ep := added[key]
+		rrs, err := resourceRecords(ep)
+		if err != nil {
+			log.Warnf("Skipping record %s: %v", ep.DNSName, err)
+			continue
+		}
+		log.Infof("Adding %s %s %v", ep.RecordType, ep.DNSName, ep.Targets)
+		for _, rr := range rrs {
+			if _, err := p.run(ctx, "local_data "+rr.String()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// readRecords returns the records of the local data in the local zones, and all the local data of
+// the names in the local zones, including the types not managed by the provider.
+func (p *UnboundProvider) readRecords(ctx context.Context) (map[unboundKey]*endpoint.Endpoint, map[string][]dns.RR, error) {
+	output, err := p.control.run(ctx, "list_local_data")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	records := map[unboundKey]*endpoint.Endpoint{}
+	data := map[string][]dns.RR{}
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		rr, err := dns.NewRR(line)
+		if err != nil || rr == nil {
+			log.Debugf("Skipping local data %q: %v", line, err)
+			continue
+		}
+		name := normalize(rr.Header().Name)
+		if !p.managed(name) {
+			continue
+		}
+		data[name] = append(data[name], rr)
+		if !p.domainFilter.Match(name) {
+			continue
+		}
+		recordType, target, ok := recordTarget(rr)
+		if !ok {
+			continue
+		}
+		key := unboundKey{name, recordType}
+		if ep, ok := records[key]; ok {
+			ep.Targets = append(ep.Targets, target)
+			continue
+		}
+		records[key] = endpoint.NewEndpointWithTTL(name, recordType, endpoint.TTL(rr.Header().Ttl), target)
+	}
+	return records, data, scanner.Err()
+}
+
+// ensureZones creates the missing local zones, e.g. after unbound restarted.
+func (p *UnboundProvider) ensureZones(ctx context.Context) error {
+	output, err := p.control.run(ctx, "list_local_zones")
+	if err != nil {
+		return err
+	}
+	existing := map[string]bool{}
+	for _, line := range strings.Split(output, "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			existing[normalize(fields[0])] = true
+		}
+	}
+	for _, zone := range p.zones {
+		if existing[zone] {
+			continue
+		}
+		log.Infof("Creating %s local zone %s", p.zoneType, zone)
+		if _, err := p.run(ctx, fmt.Sprintf("local_zone %s %s", dns.Fqdn(zone), p.zoneType)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// run runs a command changing the local data, or only logs it in dry run mode.
+func (p *UnboundProvider) run(ctx context.Context, command string) (string, error) {
+	if p.dryRun {
+		log.Infof("Would run %q", command)
+		return "", nil
+	}
+	return p.control.run(ctx, command)
+}
+
+// managed returns whether the name is in one of the local zones.
+func (p *UnboundProvider) managed(name string) bool {
+	for _, zone := range p.zones {
+		if name == zone || strings.HasSuffix(name, "."+zone) {
+			return true
+		}
+	}
+	return false
+}
+
+func supported(recordType string) bool {
+	switch recordType {
+	case endpoint.RecordTypeA, endpoint.RecordTypeAAAA, endpoint.RecordTypeCNAME, endpoint.RecordTypeTXT,
+		endpoint.RecordTypeMX, endpoint.RecordTypeSRV, endpoint.RecordTypeNS, endpoint.RecordTypePTR:
+		return true
+	}
+	return false
+}
+
+// recordTarget returns the type and the target of a resource record, if it is supported.
+func recordTarget(rr dns.RR) (string, string, bool) {
+	switch rr := rr.(type) {
+	case *dns.A:
+		return endpoint.RecordTypeA, rr.A.String(), true
+	case *dns.AAAA:
+		return endpoint.RecordTypeAAAA, rr.AAAA.String(), true
+	case *dns.CNAME:
+		return endpoint.RecordTypeCNAME, strings.TrimSuffix(rr.Target, "."), true
+	case *dns.TXT:
+		return endpoint.RecordTypeTXT, strings.Join(rr.Txt, ""), true
+	case *dns.MX:
+		return endpoint.RecordTypeMX, fmt.Sprintf("%d %s", rr.Preference, strings.TrimSuffix(rr.Mx, ".")), true
+	case *dns.SRV:
+		return endpoint.RecordTypeSRV, fmt.Sprintf("%d %d %d %s", rr.Priority, rr.Weight, rr.Port, strings.TrimSuffix(rr.Target, ".")), true
+	case *dns.NS:
+		return endpoint.RecordTypeNS, strings.TrimSuffix(rr.Ns, "."), true
+	case *dns.PTR:
+		return endpoint.RecordTypePTR, strings.TrimSuffix(rr.Ptr, "."), true
+	}
+	return "", "", false
+}
+
+// resourceRecords returns the resource records of the targets of an endpoint.
+func resourceRecords(ep *endpoint.Endpoint) ([]dns.RR, error) {
+	ttl := uint32(defaultTTL)
+	if ep.RecordTTL.IsConfigured() {
+		ttl = uint32(ep.RecordTTL)
+	}
+	header := dns.RR_Header{Name: dns.Fqdn(ep.DNSName), Rrtype: dns.StringToType[ep.RecordType], Class: dns.ClassINET, Ttl: ttl}
+
+	rrs := make([]dns.RR, 0, len(ep.Targets))
+	for _, target := range ep.Targets {
+		if ep.RecordType == endpoint.RecordTypeTXT {
+			// the character strings of TXT records are limited to 255 bytes
+			var txt []string
+			for len(target) > 255 {
+				txt, target = append(txt, target[:255]), target[255:]
+			}
+			rrs = append(rrs, &dns.TXT{Hdr: header, Txt: append(txt, target)})
+			continue
+		}
+		switch ep.RecordType {
+		case endpoint.RecordTypeCNAME, endpoint.RecordTypeNS, endpoint.RecordTypePTR:
+			target = dns.Fqdn(target)
+		}
+		rr, err := dns.NewRR(header.String() + target)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s target %q: %w", ep.RecordType, target, err)
+		}
+		rrs = append(rrs, rr)
+	}
+	return rrs, nil
+}
+
+// normalize returns the name in lower case without trailing dot.
+func normalize(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, "."))
+}
+
+// sortedKeys returns the keys of the records, sorted by name and type to apply stable changes.
+func sortedKeys(records map[unboundKey]*endpoint.Endpoint) []unboundKey {
+	keys := make([]unboundKey, 0, len(records))
+	for key := range records {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].DNSName != keys[j].DNSName {
+			return keys[i].DNSName < keys[j].DNSName
+		}
+		return keys[i].RecordType < keys[j].RecordType
+	})
+	return keys
+}
@@ -0,0 +1,188 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unbound
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+// fakeUnbound keeps the local zones and data like unbound does for the commands of the provider.
+type fakeUnbound struct {
+	zones    []string
+	data     []string
+	commands []string
+}
+
+func (u *fakeUnbound) run(_ context.Context, command string) (string, error) {
+	name, args, _ := strings.Cut(command, " ")
+	switch name {
+	case "list_local_data":
+		return strings.Join(u.data, "\n") + "\n", nil
+	case "list_local_zones":
+		return strings.Join(u.zones, "\n") + "\n", nil
+	}
+
+	u.commands = append(u.commands, command)
+	switch name {
+	case "local_zone":
+		u.zones = append(u.zones, args)
+	case "local_data":
+		rr, err := dns.NewRR(args)
+		if err != nil {
+			return "", err
+		}
+		u.data = append(u.data, rr.String())
+	case "local_data_remove":
+		var kept []string
+		for _, line := range u.data {
+			if !strings.HasPrefix(line, args+"\t") {
+				kept = append(kept, line)
+			}
+		}
+		u.data = kept
+	}
+	return "ok\n", nil
+}
+
+func newFakeUnbound() *fakeUnbound {
+	return &fakeUnbound{
+		zones: []string{"example.com. transparent", "localhost. redirect"},
+		data: []string{
+			"localhost.\t10800\tIN\tA\t127.0.0.1",
+			"www.example.com.\t300\tIN\tA\t10.0.0.1",
+			"www.example.com.\t300\tIN\tA\t10.0.0.2",
+			"www.example.com.\t300\tIN\tAAAA\t2001:db8::1",
+			"www.example.com.\t300\tIN\tTXT\t\"heritage=external-dns,external-dns/owner=default\"",
+			"www.example.com.\t300\tIN\tSSHFP\t1 1 0123456789ABCDEF0123456789ABCDEF01234567",
+			"app.example.com.\t60\tIN\tCNAME\twww.example.com.",
+			"_http._tcp.example.com.\t300\tIN\tSRV\t10 5 80 www.example.com.",
+		},
+	}
+}
+
+func TestNewUnboundProvider(t *testing.T) {
+	_, err := newUnboundProvider(UnboundConfig{}, newFakeUnbound())
+	assert.ErrorIs(t, err, ErrNoLocalZones)
+}
+
+func TestUnboundRecords(t *testing.T) {
+	p, err := newUnboundProvider(UnboundConfig{LocalZones: []string{"example.com"}}, newFakeUnbound())
+	require.NoError(t, err)
+
+	endpoints, err := p.Records(context.Background())
+	require.NoError(t, err)
+	expected := []*endpoint.Endpoint{
+		endpoint.NewEndpointWithTTL("www.example.com", endpoint.RecordTypeA, 300, "10.0.0.1", "10.0.0.2"),
+		endpoint.NewEndpointWithTTL("www.example.com", endpoint.RecordTypeAAAA, 300, "2001:db8::1"),
+		endpoint.NewEndpointWithTTL("www.example.com", endpoint.RecordTypeTXT, 300, "heritage=external-dns,external-dns/owner=default"),
+		endpoint.NewEndpointWithTTL("app.example.com", endpoint.RecordTypeCNAME, 60, "www.example.com"),
+		endpoint.NewEndpointWithTTL("_http._tcp.example.com", endpoint.RecordTypeSRV, 300, "10 5 80 www.example.com"),
+	}
+	assert.True(t, testutils.SameEndpoints(expected, endpoints), "expected %v, got %v", expected, endpoints)
+}
+
+func TestUnboundApplyChanges(t *testing.T) {
+	unbound := newFakeUnbound()
+	p, err := newUnboundProvider(UnboundConfig{LocalZones: []string{"example.com", "example.org"}, LocalZoneType: "static"}, unbound)
+	require.NoError(t, err)
+
+	changes := &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("new.example.org", endpoint.RecordTypeA, "10.0.1.1"),
+			endpoint.NewEndpoint("app.example.com", endpoint.RecordTypeTXT, "heritage=external-dns"),
+			endpoint.NewEndpoint("other.example.net", endpoint.RecordTypeA, "10.0.2.1"),
+		},
+		UpdateOld: []*endpoint.Endpoint{
+			endpoint.NewEndpointWithTTL("www.example.com", endpoint.RecordTypeA, 300, "10.0.0.1", "10.0.0.2"),
+		},
+		UpdateNew: []*endpoint.Endpoint{
+			endpoint.NewEndpointWithTTL("www.example.com", endpoint.RecordTypeA, 600, "10.0.0.3"),
+		},
+		Delete: []*endpoint.Endpoint{
+			endpoint.NewEndpointWithTTL("_http._tcp.example.com", endpoint.RecordTypeSRV, 300, "10 5 80 www.example.com"),
+		},
+	}
+	require.NoError(t, p.ApplyChanges(context.Background(), changes))
+
+	assert.Equal(t, []string{
+		"local_zone example.org. static",
+		"local_data_remove _http._tcp.example.com.",
+		"local_data_remove www.example.com.",
+		"local_data www.example.com.\t300\tIN\tAAAA\t2001:db8::1",
+		"local_data www.example.com.\t300\tIN\tTXT\t\"heritage=external-dns,external-dns/owner=default\"",
+		"local_data www.example.com.\t300\tIN\tSSHFP\t1 1 0123456789ABCDEF0123456789ABCDEF01234567",
+		"local_data app.example.com.\t300\tIN\tTXT\t\"heritage=external-dns\"",
+		"local_data new.example.org.\t300\tIN\tA\t10.0.1.1",
+		"local_data www.example.com.\t600\tIN\tA\t10.0.0.3",
+	}, unbound.commands)
+	assert.Contains(t, unbound.data, "www.example.com.\t300\tIN\tSSHFP\t1 1 0123456789ABCDEF0123456789ABCDEF01234567")
+
+	endpoints, err := p.Records(context.Background())
+	require.NoError(t, err)
+	expected := []*endpoint.Endpoint{
+		endpoint.NewEndpointWithTTL("www.example.com", endpoint.RecordTypeA, 600, "10.0.0.3"),
+		endpoint.NewEndpointWithTTL("www.example.com", endpoint.RecordTypeAAAA, 300, "2001:db8::1"),
+		endpoint.NewEndpointWithTTL("www.example.com", endpoint.RecordTypeTXT, 300, "heritage=external-dns,external-dns/owner=default"),
+		endpoint.NewEndpointWithTTL("app.example.com", endpoint.RecordTypeCNAME, 60, "www.example.com"),
+		endpoint.NewEndpointWithTTL("app.example.com", endpoint.RecordTypeTXT, 300, "heritage=external-dns"),
+		endpoint.NewEndpointWithTTL("new.example.org", endpoint.RecordTypeA, 300, "10.0.1.1"),
+	}
+	assert.True(t, testutils.SameEndpoints(expected, endpoints), "expected %v, got %v", expected, endpoints)
+}
+
+func TestUnboundApplyChangesDryRun(t *testing.T) {
+	unbound := newFakeUnbound()
+	p, err := newUnboundProvider(UnboundConfig{LocalZones: []string{"example.com"}, DryRun: true}, unbound)
+	require.NoError(t, err)
+
+	require.NoError(t, p.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{endpoint.NewEndpoint("new.example.com", endpoint.RecordTypeA, "10.0.1.1")},
+	}))
+	assert.Empty(t, unbound.commands)
+}
+
+func TestUnboundAdjustEndpoints(t *testing.T) {
+	p, err := newUnboundProvider(UnboundConfig{LocalZones: []string{"example.com"}}, newFakeUnbound())
+	require.NoError(t, err)
+
+	adjusted, err := p.AdjustEndpoints([]*endpoint.Endpoint{
+		endpoint.NewEndpoint("www.example.com", endpoint.RecordTypeA, "10.0.0.1"),
+		endpoint.NewEndpoint("www.example.com", "NAPTR", "100 10 \"S\" \"SIP+D2U\" \"\" _sip._udp.example.com."),
+	})
+	require.NoError(t, err)
+	assert.Len(t, adjusted, 1)
+}
+
+func TestResourceRecordsSplitsLongTXT(t *testing.T) {
+	rrs, err := resourceRecords(endpoint.NewEndpoint("www.example.com", endpoint.RecordTypeTXT, strings.Repeat("a", 300)))
+	require.NoError(t, err)
+	require.Len(t, rrs, 1)
+	assert.Equal(t, []string{strings.Repeat("a", 255), strings.Repeat("a", 45)}, rrs[0].(*dns.TXT).Txt)
+
+	_, err = resourceRecords(endpoint.NewEndpoint("www.example.com", endpoint.RecordTypeA, "invalid"))
+	assert.Error(t, err)
+}
@@ -36,6 +36,8 @@ const (
 	vultrDelete = "DELETE"
 	vultrUpdate = "UPDATE"
 	vultrTTL    = 3600
+	// vultrPerPage is the maximum page size of the Vultr API.
+	vultrPerPage = 500
 )
 
 // VultrProvider is an implementation of Provider for Vultr DNS.
@@ -120,55 +122,45 @@ func (p *VultrProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, erro
 }
 
 func (p *VultrProvider) fetchRecords(ctx context.Context, domain string) ([]govultr.DomainRecord, error) {
-	var allRecords []govultr.DomainRecord
-	listOptions := &govultr.ListOptions{}
-
-	for {
-		records, meta, err := p.client.DomainRecord.List(ctx, domain, listOptions)
+	return provider.Paginate(ctx, provider.DefaultRetryConfig, func(ctx context.Context, cursor string) ([]govultr.DomainRecord, string, error) {
+		records, meta, err := p.client.DomainRecord.List(ctx, domain, &govultr.ListOptions{PerPage: vultrPerPage, Cursor: cursor})
 		if err != nil {
-			return nil, err
-		}
-
-		allRecords = append(allRecords, records...)
-
-		if meta.Links.Next == "" {
-			break
-		} else {
-			listOptions.Cursor = meta.Links.Next
-			continue
+			return nil, "", err
 		}
-	}
-
-	return allRecords, nil
+		return records, nextCursor(meta), nil
+	})
 }
 
 func (p *VultrProvider) fetchZones(ctx context.Context) ([]govultr.Domain, error) {
-	var zones []govultr.Domain
-	listOptions := &govultr.ListOptions{}
-
-	for {
-		allZones, meta, err := p.client.Domain.List(ctx, listOptions)
+	allZones, err := provider.Paginate(ctx, provider.DefaultRetryConfig, func(ctx context.Context, cursor string) ([]govultr.Domain, string, error) {
+		zones, meta, err := p.client.Domain.List(ctx, &govultr.ListOptions{PerPage: vultrPerPage, Cursor: cursor})
 		if err != nil {
-			return nil, err
-		}
-
-		for _, zone := range allZones {
-			if p.domainFilter.Match(zone.Domain) {
-				zones = append(zones, zone)
-			}
+			return nil, "", err
 		}
+		return zones, nextCursor(meta), nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		if meta.Links.Next == "" {
-			break
-		} else {
-			listOptions.Cursor = meta.Links.Next
-			continue
+	var zones []govultr.Domain
+	for _, zone := range allZones {
+		if p.domainFilter.Match(zone.Domain) {
+			zones = append(zones, zone)
 		}
 	}
 
 	return zones, nil
 }
 
+// nextCursor returns the cursor of the next page, empty after the last page.
+func nextCursor(meta *govultr.Meta) string {
+	if meta == nil || meta.Links == nil {
+		return ""
+	}
+	return meta.Links.Next
+}
+
 func (p *VultrProvider) submitChanges(ctx context.Context, changes []*VultrChanges) error {
 	if len(changes) == 0 {
 		log.Infof("All records are already up to date")
@@ -183,7 +175,18 @@ func (p *VultrProvider) submitChanges(ctx context.Context, changes []*VultrChang
 	zoneChanges := separateChangesByZone(zones, changes)
 
 	for zoneName, changes := range zoneChanges {
-		for _, change := range changes {
+		if len(changes) == 0 {
+			continue
+		}
+
+		// The records of the zone are listed once for all changes, instead of once per update and delete.
+		records, err := p.fetchRecords(ctx, zoneName)
+		if err != nil {
+			return err
+		}
+
+		// The Vultr API takes a request per record, the requests of the changes are sent in batches.
+		err = provider.ApplyInBatches(ctx, provider.DefaultRetryConfig, provider.DefaultBatchSize, changes, func(ctx context.Context, change *VultrChanges) error {
 			log.WithFields(log.Fields{
 				"record": change.ResourceRecordSet.Name,
 				"type":   change.ResourceRecordSet.Type,
@@ -198,7 +201,7 @@ func (p *VultrProvider) submitChanges(ctx context.Context, changes []*VultrChang
 					return err
 				}
 			case vultrDelete:
-				id, err := p.getRecordID(ctx, zoneName, change.ResourceRecordSet)
+				id, err := findRecordID(records, zoneName, change.ResourceRecordSet)
 				if err != nil {
 					return err
 				}
@@ -207,7 +210,7 @@ func (p *VultrProvider) submitChanges(ctx context.Context, changes []*VultrChang
 					return err
 				}
 			case vultrUpdate:
-				id, err := p.getRecordID(ctx, zoneName, change.ResourceRecordSet)
+				id, err := findRecordID(records, zoneName, change.ResourceRecordSet)
 				if err != nil {
 					return err
 				}
@@ -215,6 +218,10 @@ func (p *VultrProvider) submitChanges(ctx context.Context, changes []*VultrChang
 					return err
 				}
 			}
+			return nil
+		})
+		if err != nil {
+			return err
 		}
 	}
 	return nil
@@ -275,28 +282,24 @@ func separateChangesByZone(zones []govultr.Domain, changes []*VultrChanges) map[
 }
 
 func (p *VultrProvider) getRecordID(ctx context.Context, zone string, record *govultr.DomainRecordReq) (recordID string, err error) {
-	listOptions := &govultr.ListOptions{}
-	for {
-		records, meta, err := p.client.DomainRecord.List(ctx, zone, listOptions)
-		if err != nil {
-			return "0", err
-		}
+	records, err := p.fetchRecords(ctx, zone)
+	if err != nil {
+		return "0", err
+	}
 
-		for _, r := range records {
-			strippedName := strings.TrimSuffix(record.Name, "."+zone)
-			if record.Name == zone {
-				strippedName = ""
-			}
+	return findRecordID(records, zone, record)
+}
 
-			if r.Name == strippedName && r.Type == record.Type {
-				return r.ID, nil
-			}
-		}
-		if meta.Links.Next == "" {
-			break
-		} else {
-			listOptions.Cursor = meta.Links.Next
-			continue
+// findRecordID returns the ID of the record of the zone with the name and type of the request.
+func findRecordID(records []govultr.DomainRecord, zone string, record *govultr.DomainRecordReq) (string, error) {
+	strippedName := strings.TrimSuffix(record.Name, "."+zone)
+	if record.Name == zone {
+		strippedName = ""
+	}
+
+	for _, r := range records {
+		if r.Name == strippedName && r.Type == record.Type {
+			return r.ID, nil
 		}
 	}
 
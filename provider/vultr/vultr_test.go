@@ -24,6 +24,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/vultr/govultr/v2"
 
 	"sigs.k8s.io/external-dns/endpoint"
@@ -189,6 +190,53 @@ func TestVultrProvider_ApplyChanges(t *testing.T) {
 	}
 }
 
+// pagedVultrRecord serves two pages of records, the second one for the cursor "next".
+type pagedVultrRecord struct {
+	mockVultrRecord
+	cursors *[]string
+}
+
+func (m pagedVultrRecord) List(ctx context.Context, domain string, options *govultr.ListOptions) ([]govultr.DomainRecord, *govultr.Meta, error) {
+	*m.cursors = append(*m.cursors, options.Cursor)
+	if options.Cursor == "" {
+		return []govultr.DomainRecord{{ID: "1", Type: "A", Name: "first", Data: "192.168.1.1", TTL: 300}}, &govultr.Meta{
+			Total: 2,
+			Links: &govultr.Links{Next: "next"},
+		}, nil
+	}
+	return []govultr.DomainRecord{{ID: "2", Type: "A", Name: "second", Data: "192.168.1.2", TTL: 300}}, &govultr.Meta{
+		Total: 2,
+		Links: &govultr.Links{Prev: "prev"},
+	}, nil
+}
+
+func TestVultrProvider_RecordsPaginated(t *testing.T) {
+	var cursors []string
+	mocked := pagedVultrRecord{cursors: &cursors}
+	mockedDomain := mockVultrDomain{nil}
+
+	provider := &VultrProvider{
+		client: govultr.Client{
+			DomainRecord: &mocked,
+			Domain:       &mockedDomain,
+		},
+	}
+
+	records, err := provider.Records(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"", "next"}, cursors)
+	assert.Equal(t, []*endpoint.Endpoint{
+		endpoint.NewEndpointWithTTL("first.test.com", "A", 300, "192.168.1.1"),
+		endpoint.NewEndpointWithTTL("second.test.com", "A", 300, "192.168.1.2"),
+	}, records)
+
+	cursors = nil
+	id, err := provider.getRecordID(context.Background(), "test.com", &govultr.DomainRecordReq{Type: "A", Name: "second.test.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "2", id)
+	assert.Equal(t, []string{"", "next"}, cursors)
+}
+
 func TestVultrProvider_getRecordID(t *testing.T) {
 	mocked := mockVultrRecord{nil}
 	mockedDomain := mockVultrDomain{nil}
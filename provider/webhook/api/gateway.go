@@ -0,0 +1,219 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+// AuthorizationHeader carries the bearer token of a client of the gateway.
+const AuthorizationHeader = "Authorization"
+
+// GatewayClient is an external-dns instance, e.g. of an edge cluster, allowed to submit its changes to
+// the gateway. Its records are owned by its cluster ID.
+type GatewayClient struct {
+	Token     string
+	ClusterID string
+}
+
+// ReadGatewayClients reads the clients of the gateway from a file with a "<token>,<cluster ID>" line
+// per client, like the static token file of the API server. Empty lines and lines starting with "#"
+// are ignored.
+func ReadGatewayClients(path string) ([]GatewayClient, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var clients []GatewayClient
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		token, clusterID, ok := strings.Cut(text, ",")
+		token, clusterID = strings.TrimSpace(token), strings.TrimSpace(clusterID)
+		if !ok || token == "" || clusterID == "" {
+			return nil, fmt.Errorf("%s:%d: expected <token>,<cluster ID>", path, line)
+		}
+		clients = append(clients, GatewayClient{Token: token, ClusterID: clusterID})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return clients, nil
+}
+
+// GatewayServer serves one provider to many clients over the webhook protocol, so that only the
+// gateway holds the credentials of the provider. The records of each client are owned by its cluster
+// ID: a client only gets its own records and its changes to the records of other clients are dropped.
+type GatewayServer struct {
+	clients []gatewayClient
+	// mu serializes the changes of all clients, so that two clients can't both take the same
+	// unowned name between checking and applying their changes
+	mu sync.Mutex
+}
+
+type gatewayClient struct {
+	token  []byte
+	server *WebhookServer
+}
+
+// NewGatewayServer returns a gateway serving the clients. newOwnedProvider returns the provider of a
+// cluster ID, which manages the ownership of the records, e.g. the TXT registry of the provider with
+// the cluster ID as owner ID.
+func NewGatewayServer(clients []GatewayClient, newOwnedProvider func(clusterID string) (provider.Provider, error)) (*GatewayServer, error) {
+	g := &GatewayServer{}
+	tokens := map[string]bool{}
+	for _, c := range clients {
+		if tokens[c.Token] {
+			return nil, fmt.Errorf("the token of cluster %s is not unique", c.ClusterID)
+		}
+		tokens[c.Token] = true
+
+		p, err := newOwnedProvider(c.ClusterID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the provider of cluster %s: %w", c.ClusterID, err)
+		}
+		g.clients = append(g.clients, gatewayClient{
+			token:  []byte(c.Token),
+			server: &WebhookServer{Provider: &ownedProvider{Provider: p, clusterID: c.ClusterID, mu: &g.mu}},
+		})
+	}
+	return g, nil
+}
+
+// ServeHTTP authenticates the client of the request and serves it the routes of the webhook server.
+func (g *GatewayServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	server := g.authenticate(req)
+	if server == nil {
+		log.Warnf("Rejecting unauthenticated request from %s", req.RemoteAddr)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	switch req.URL.Path {
+	case "/":
+		server.NegotiateHandler(w, req)
+	case "/records":
+		server.RecordsHandler(w, req)
+	case "/adjustendpoints":
+		server.AdjustEndpointsHandler(w, req)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// authenticate returns the webhook server of the client with the bearer token of the request.
+func (g *GatewayServer) authenticate(req *http.Request) *WebhookServer {
+	token, ok := strings.CutPrefix(req.Header.Get(AuthorizationHeader), "Bearer ")
+	if !ok || token == "" {
+		return nil
+	}
+	var server *WebhookServer
+	// all tokens are compared, so that the time taken doesn't tell which token matched
+	for _, c := range g.clients {
+		if subtle.ConstantTimeCompare(c.token, []byte(token)) == 1 {
+			server = c.server
+		}
+	}
+	return server
+}
+
+// ownedProvider restricts a client of the gateway to the records owned by its cluster ID.
+type ownedProvider struct {
+	provider.Provider
+	clusterID string
+	mu        *sync.Mutex
+}
+
+// Records returns the records owned by the cluster ID, so that the plans of the client only cover them.
+func (p *ownedProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	records, err := p.Provider.Records(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return endpoint.FilterEndpointsByOwnerID(p.clusterID, records), nil
+}
+
+// ApplyChanges drops the changes to records owned by others, or by nobody, before applying the
+// changes of the client. The owners are checked and the changes applied while holding the lock
+// shared by all clients.
+func (p *ownedProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	records, err := p.Provider.Records(ctx)
+	if err != nil {
+		return err
+	}
+	owners := make(map[endpoint.EndpointKey]string, len(records))
+	for _, r := range records {
+		owners[r.Key()] = r.Labels[endpoint.OwnerLabelKey]
+	}
+	owned := func(ep *endpoint.Endpoint) bool {
+		owner, ok := owners[ep.Key()]
+		if ok && owner == p.clusterID {
+			return true
+		}
+		log.Warnf("Dropping the change of cluster %s to %s %s, which is owned by %q", p.clusterID, ep.DNSName, ep.RecordType, owner)
+		return false
+	}
+
+	filtered := &plan.Changes{}
+	for _, ep := range changes.Create {
+		if owner, ok := owners[ep.Key()]; ok && owner != p.clusterID {
+			log.Warnf("Dropping the creation of %s %s by cluster %s, which is owned by %q", ep.DNSName, ep.RecordType, p.clusterID, owner)
+			continue
+		}
+		filtered.Create = append(filtered.Create, ep)
+	}
+	// the old and new records of an update are paired by their index
+	for i, ep := range changes.UpdateOld {
+		if i < len(changes.UpdateNew) && owned(ep) {
+			filtered.UpdateOld = append(filtered.UpdateOld, ep)
+			filtered.UpdateNew = append(filtered.UpdateNew, changes.UpdateNew[i])
+		}
+	}
+	for _, ep := range changes.Delete {
+		if owned(ep) {
+			filtered.Delete = append(filtered.Delete, ep)
+		}
+	}
+	return p.Provider.ApplyChanges(ctx, filtered)
+}
+
+// StartGatewayHTTPApi starts the HTTP server of the gateway, see StartHTTPApi. With a certificate
+// and key file, the server serves HTTPS, so that the tokens of the clients aren't sent in clear.
+func StartGatewayHTTPApi(gateway *GatewayServer, startedChan chan struct{}, readTimeout, writeTimeout time.Duration, address, certFile, keyFile string) {
+	serve(gateway, startedChan, readTimeout, writeTimeout, address, certFile, keyFile)
+}
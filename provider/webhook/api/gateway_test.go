@@ -0,0 +1,222 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+// ownerFakeProvider has the records of all clusters, with their owner labels like a registry.
+type ownerFakeProvider struct {
+	provider.BaseProvider
+	records []*endpoint.Endpoint
+	applied *plan.Changes
+}
+
+func (p *ownerFakeProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	return p.records, nil
+}
+
+func (p *ownerFakeProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	p.applied = changes
+	return nil
+}
+
+func ownedEndpoint(dnsName, owner string) *endpoint.Endpoint {
+	ep := endpoint.NewEndpoint(dnsName, endpoint.RecordTypeA, "1.2.3.4")
+	if owner != "" {
+		ep.Labels[endpoint.OwnerLabelKey] = owner
+	}
+	return ep
+}
+
+func newTestGateway(t *testing.T) (*GatewayServer, *ownerFakeProvider) {
+	t.Helper()
+	p := &ownerFakeProvider{records: []*endpoint.Endpoint{
+		ownedEndpoint("a.example.org", "cluster-a"),
+		ownedEndpoint("b.example.org", "cluster-b"),
+		ownedEndpoint("unowned.example.org", ""),
+	}}
+	g, err := NewGatewayServer([]GatewayClient{
+		{Token: "token-a", ClusterID: "cluster-a"},
+		{Token: "token-b", ClusterID: "cluster-b"},
+	}, func(clusterID string) (provider.Provider, error) {
+		return p, nil
+	})
+	require.NoError(t, err)
+	return g, p
+}
+
+func TestReadGatewayClients(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "clients")
+	require.NoError(t, os.WriteFile(path, []byte("# edge clusters\ntoken-a,cluster-a\n\n token-b , cluster-b\n"), 0o600))
+
+	clients, err := ReadGatewayClients(path)
+	require.NoError(t, err)
+	require.Equal(t, []GatewayClient{
+		{Token: "token-a", ClusterID: "cluster-a"},
+		{Token: "token-b", ClusterID: "cluster-b"},
+	}, clients)
+
+	require.NoError(t, os.WriteFile(path, []byte("token-a\n"), 0o600))
+	_, err = ReadGatewayClients(path)
+	require.Error(t, err)
+}
+
+func TestNewGatewayServerDuplicateToken(t *testing.T) {
+	_, err := NewGatewayServer([]GatewayClient{
+		{Token: "token", ClusterID: "cluster-a"},
+		{Token: "token", ClusterID: "cluster-b"},
+	}, func(clusterID string) (provider.Provider, error) {
+		return &ownerFakeProvider{}, nil
+	})
+	require.Error(t, err)
+}
+
+func TestGatewayServerUnauthenticated(t *testing.T) {
+	g, _ := newTestGateway(t)
+	for _, authorization := range []string{"", "Bearer", "Bearer token-c", "token-a"} {
+		req := httptest.NewRequest(http.MethodGet, "/records", nil)
+		if authorization != "" {
+			req.Header.Set(AuthorizationHeader, authorization)
+		}
+		w := httptest.NewRecorder()
+		g.ServeHTTP(w, req)
+		require.Equal(t, http.StatusUnauthorized, w.Result().StatusCode, authorization)
+	}
+}
+
+func TestGatewayServerRecords(t *testing.T) {
+	g, _ := newTestGateway(t)
+	req := httptest.NewRequest(http.MethodGet, "/records", nil)
+	req.Header.Set(AuthorizationHeader, "Bearer token-a")
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Result().StatusCode)
+
+	var records []*endpoint.Endpoint
+	require.NoError(t, json.NewDecoder(w.Result().Body).Decode(&records))
+	require.Len(t, records, 1)
+	require.Equal(t, "a.example.org", records[0].DNSName)
+}
+
+func TestGatewayServerApplyChanges(t *testing.T) {
+	g, p := newTestGateway(t)
+	changes := &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			ownedEndpoint("new.example.org", ""),
+			ownedEndpoint("b.example.org", ""),
+		},
+		UpdateOld: []*endpoint.Endpoint{
+			ownedEndpoint("a.example.org", "cluster-a"),
+			ownedEndpoint("b.example.org", "cluster-a"),
+		},
+		UpdateNew: []*endpoint.Endpoint{
+			ownedEndpoint("a.example.org", "cluster-a"),
+			ownedEndpoint("b.example.org", "cluster-a"),
+		},
+		Delete: []*endpoint.Endpoint{
+			ownedEndpoint("unowned.example.org", "cluster-a"),
+		},
+	}
+	j, err := json.Marshal(changes)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/records", bytes.NewReader(j))
+	req.Header.Set(AuthorizationHeader, "Bearer token-a")
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNoContent, w.Result().StatusCode)
+
+	require.NotNil(t, p.applied)
+	require.Len(t, p.applied.Create, 1)
+	require.Equal(t, "new.example.org", p.applied.Create[0].DNSName)
+	require.Len(t, p.applied.UpdateOld, 1)
+	require.Equal(t, "a.example.org", p.applied.UpdateOld[0].DNSName)
+	require.Len(t, p.applied.UpdateNew, 1)
+	require.Equal(t, "a.example.org", p.applied.UpdateNew[0].DNSName)
+	require.Empty(t, p.applied.Delete)
+}
+
+// clusterFakeProvider shares the records of all clusters, creating the records owned by its cluster
+// like the registry of the cluster.
+type clusterFakeProvider struct {
+	provider.BaseProvider
+	mu        *sync.Mutex
+	records   *[]*endpoint.Endpoint
+	clusterID string
+}
+
+func (p *clusterFakeProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*endpoint.Endpoint{}, *p.records...), nil
+}
+
+func (p *clusterFakeProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	// a slow provider leaves time for the changes of another client to be checked
+	time.Sleep(10 * time.Millisecond)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, ep := range changes.Create {
+		*p.records = append(*p.records, ownedEndpoint(ep.DNSName, p.clusterID))
+	}
+	return nil
+}
+
+func TestGatewayServerConcurrentCreates(t *testing.T) {
+	var mu sync.Mutex
+	var records []*endpoint.Endpoint
+	g, err := NewGatewayServer([]GatewayClient{
+		{Token: "token-a", ClusterID: "cluster-a"},
+		{Token: "token-b", ClusterID: "cluster-b"},
+	}, func(clusterID string) (provider.Provider, error) {
+		return &clusterFakeProvider{mu: &mu, records: &records, clusterID: clusterID}, nil
+	})
+	require.NoError(t, err)
+
+	j, err := json.Marshal(&plan.Changes{Create: []*endpoint.Endpoint{ownedEndpoint("new.example.org", "")}})
+	require.NoError(t, err)
+	var wg sync.WaitGroup
+	for _, token := range []string{"token-a", "token-b"} {
+		wg.Add(1)
+		go func(token string) {
+			defer wg.Done()
+			req := httptest.NewRequest(http.MethodPost, "/records", bytes.NewReader(j))
+			req.Header.Set(AuthorizationHeader, "Bearer "+token)
+			g.ServeHTTP(httptest.NewRecorder(), req)
+		}(token)
+	}
+	wg.Wait()
+
+	// only the client whose changes were applied first owns the record
+	require.Len(t, records, 1)
+}
@@ -19,6 +19,8 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net"
 	"net/http"
 	"time"
@@ -33,8 +35,33 @@ import (
 const (
 	MediaTypeFormatAndVersion = "application/external.dns.webhook+json;version=1"
 	ContentTypeHeader         = "Content-Type"
+
+	// CapabilitiesHeader lists the optional features supported by the plugin, separated by commas.
+	// It is set on the response to the negotiation request.
+	CapabilitiesHeader = "External-Dns-Webhook-Capabilities"
+	// CapabilityTransactions is the capability of applying the changes of a request all-or-nothing.
+	CapabilityTransactions = "transactions"
+
+	// TransactionHeader asks the plugin to apply either all changes of the request or none of them.
+	TransactionHeader = "External-Dns-Transaction"
+	// TransactionAllOrNothing is the value of TransactionHeader for all-or-nothing changes.
+	TransactionAllOrNothing = "all-or-nothing"
+	// IdempotencyKeyHeader identifies the changes of a transactional request. Retries of a request
+	// have the same key, a plugin that already applied the changes of the key must not apply them again.
+	IdempotencyKeyHeader = "Idempotency-Key"
 )
 
+// TransactionalProvider is implemented by providers that can apply all changes of a plan or none of them.
+type TransactionalProvider interface {
+	provider.Provider
+	// ApplyChangesTransactionally applies all changes or none of them. Changes with an idempotency key
+	// that were applied before must not be applied again.
+	ApplyChangesTransactionally(ctx context.Context, changes *plan.Changes, idempotencyKey string) error
+}
+
+// WebhookServer serves a provider over HTTP, so that external-dns, or any other controller, can use
+// it as a webhook provider. The domain filter of the provider is enforced on the requests too, as a
+// client doesn't necessarily filter its endpoints.
 type WebhookServer struct {
 	Provider provider.Provider
 }
@@ -42,7 +69,7 @@ type WebhookServer struct {
 func (p *WebhookServer) RecordsHandler(w http.ResponseWriter, req *http.Request) {
 	switch req.Method {
 	case http.MethodGet:
-		records, err := p.Provider.Records(context.Background())
+		records, err := p.Provider.Records(req.Context())
 		if err != nil {
 			log.Errorf("Failed to get Records: %v", err)
 			w.WriteHeader(http.StatusInternalServerError)
@@ -61,7 +88,17 @@ func (p *WebhookServer) RecordsHandler(w http.ResponseWriter, req *http.Request)
 			w.WriteHeader(http.StatusBadRequest)
 			return
 		}
-		err := p.Provider.ApplyChanges(context.Background(), &changes)
+		if err := checkDomainFilter(p.Provider.GetDomainFilter(), &changes); err != nil {
+			log.Errorf("Failed to apply changes: %v", err)
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		err := p.applyChanges(req, &changes)
+		if errors.Is(err, errTransactionsUnsupported) {
+			log.Errorf("Failed to apply changes: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
 		if err != nil {
 			log.Errorf("Failed to apply changes: %v", err)
 			w.WriteHeader(http.StatusInternalServerError)
@@ -75,6 +112,32 @@ func (p *WebhookServer) RecordsHandler(w http.ResponseWriter, req *http.Request)
 	}
 }
 
+var errTransactionsUnsupported = errors.New("the provider doesn't support transactions")
+
+// checkDomainFilter returns an error if any of the changes is for a DNS name outside the domain filter.
+func checkDomainFilter(domainFilter endpoint.DomainFilter, changes *plan.Changes) error {
+	for _, endpoints := range [][]*endpoint.Endpoint{changes.Create, changes.UpdateOld, changes.UpdateNew, changes.Delete} {
+		for _, ep := range endpoints {
+			if !domainFilter.Match(ep.DNSName) {
+				return fmt.Errorf("%s is outside the domain filter of the provider", ep.DNSName)
+			}
+		}
+	}
+	return nil
+}
+
+// applyChanges applies the changes of the request, all-or-nothing if the request asks for it.
+func (p *WebhookServer) applyChanges(req *http.Request, changes *plan.Changes) error {
+	if req.Header.Get(TransactionHeader) != TransactionAllOrNothing {
+		return p.Provider.ApplyChanges(req.Context(), changes)
+	}
+	transactional, ok := p.Provider.(TransactionalProvider)
+	if !ok {
+		return errTransactionsUnsupported
+	}
+	return transactional.ApplyChangesTransactionally(req.Context(), changes, req.Header.Get(IdempotencyKeyHeader))
+}
+
 func (p *WebhookServer) AdjustEndpointsHandler(w http.ResponseWriter, req *http.Request) {
 	if req.Method != http.MethodPost {
 		log.Errorf("Unsupported method %s", req.Method)
@@ -88,12 +151,23 @@ func (p *WebhookServer) AdjustEndpointsHandler(w http.ResponseWriter, req *http.
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
-	w.Header().Set(ContentTypeHeader, MediaTypeFormatAndVersion)
-	pve, err := p.Provider.AdjustEndpoints(pve)
+	// endpoints outside the domain filter of the provider are dropped, as the provider won't manage them.
+	domainFilter := p.Provider.GetDomainFilter()
+	filtered := make([]*endpoint.Endpoint, 0, len(pve))
+	for _, ep := range pve {
+		if domainFilter.Match(ep.DNSName) {
+			filtered = append(filtered, ep)
+		} else {
+			log.Debugf("Dropping endpoint %v outside the domain filter of the provider", ep)
+		}
+	}
+	pve, err := p.Provider.AdjustEndpoints(filtered)
 	if err != nil {
 		log.Errorf("Failed to call adjust endpoints: %v", err)
 		w.WriteHeader(http.StatusInternalServerError)
+		return
 	}
+	w.Header().Set(ContentTypeHeader, MediaTypeFormatAndVersion)
 	if err := json.NewEncoder(w).Encode(&pve); err != nil {
 		log.Errorf("Failed to encode in adjustEndpointsHandler: %v", err)
 		w.WriteHeader(http.StatusInternalServerError)
@@ -103,6 +177,9 @@ func (p *WebhookServer) AdjustEndpointsHandler(w http.ResponseWriter, req *http.
 
 func (p *WebhookServer) NegotiateHandler(w http.ResponseWriter, req *http.Request) {
 	w.Header().Set(ContentTypeHeader, MediaTypeFormatAndVersion)
+	if _, ok := p.Provider.(TransactionalProvider); ok {
+		w.Header().Set(CapabilitiesHeader, CapabilityTransactions)
+	}
 	json.NewEncoder(w).Encode(p.Provider.GetDomainFilter())
 }
 
@@ -110,10 +187,11 @@ func (p *WebhookServer) NegotiateHandler(w http.ResponseWriter, req *http.Reques
 // the function takes an optional channel as input which is used to signal that the server has started.
 // The server will listen on port `providerPort`.
 // The server will respond to the following endpoints:
-// - / (GET): initialization, negotiates headers and returns the domain filter
+// - / (GET): initialization, negotiates headers and capabilities and returns the domain filter
 // - /records (GET): returns the current records
-// - /records (POST): applies the changes
-// - /adjustendpoints (POST): executes the AdjustEndpoints method
+// - /records (POST): applies the changes, all-or-nothing if requested and supported by the provider,
+// rejecting them if any is outside the domain filter of the provider
+// - /adjustendpoints (POST): executes the AdjustEndpoints method on the endpoints within the domain filter
 func StartHTTPApi(provider provider.Provider, startedChan chan struct{}, readTimeout, writeTimeout time.Duration, providerPort string) {
 	p := WebhookServer{
 		Provider: provider,
@@ -124,14 +202,20 @@ func StartHTTPApi(provider provider.Provider, startedChan chan struct{}, readTim
 	m.HandleFunc("/records", p.RecordsHandler)
 	m.HandleFunc("/adjustendpoints", p.AdjustEndpointsHandler)
 
+	serve(m, startedChan, readTimeout, writeTimeout, providerPort, "", "")
+}
+
+// serve serves the handler on the address, signaling on the optional channel when it listens.
+// The handler is served over TLS when a certificate file is given.
+func serve(handler http.Handler, startedChan chan struct{}, readTimeout, writeTimeout time.Duration, address, certFile, keyFile string) {
 	s := &http.Server{
-		Addr:         providerPort,
-		Handler:      m,
+		Addr:         address,
+		Handler:      handler,
 		ReadTimeout:  readTimeout,
 		WriteTimeout: writeTimeout,
 	}
 
-	l, err := net.Listen("tcp", providerPort)
+	l, err := net.Listen("tcp", address)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -140,7 +224,12 @@ func StartHTTPApi(provider provider.Provider, startedChan chan struct{}, readTim
 		startedChan <- struct{}{}
 	}
 
-	if err := s.Serve(l); err != nil {
+	if certFile != "" {
+		err = s.ServeTLS(l, certFile, keyFile)
+	} else {
+		err = s.Serve(l)
+	}
+	if err != nil {
 		log.Fatal(err)
 	}
 }
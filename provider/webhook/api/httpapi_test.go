@@ -30,6 +30,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"sigs.k8s.io/external-dns/endpoint"
 	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
 )
 
 var records []*endpoint.Endpoint
@@ -190,6 +191,67 @@ func TestRecordsHandlerWithWrongHTTPMethod(t *testing.T) {
 	require.Equal(t, http.StatusBadRequest, res.StatusCode)
 }
 
+type FakeTransactionalWebhookProvider struct {
+	FakeWebhookProvider
+	idempotencyKeys []string
+}
+
+func (p *FakeTransactionalWebhookProvider) ApplyChangesTransactionally(ctx context.Context, changes *plan.Changes, idempotencyKey string) error {
+	p.idempotencyKeys = append(p.idempotencyKeys, idempotencyKey)
+	return p.ApplyChanges(ctx, changes)
+}
+
+func newTransactionalRequest(t *testing.T, key string) *http.Request {
+	j, err := json.Marshal(&plan.Changes{})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/records", bytes.NewReader(j))
+	req.Header.Set(TransactionHeader, TransactionAllOrNothing)
+	req.Header.Set(IdempotencyKeyHeader, key)
+	return req
+}
+
+func TestRecordsHandlerApplyChangesTransactionally(t *testing.T) {
+	fakeProvider := &FakeTransactionalWebhookProvider{}
+	providerAPIServer := &WebhookServer{
+		Provider: fakeProvider,
+	}
+
+	w := httptest.NewRecorder()
+	providerAPIServer.RecordsHandler(w, newTransactionalRequest(t, "key"))
+	require.Equal(t, http.StatusNoContent, w.Result().StatusCode)
+	require.Equal(t, []string{"key"}, fakeProvider.idempotencyKeys)
+}
+
+func TestRecordsHandlerApplyChangesTransactionallyUnsupported(t *testing.T) {
+	providerAPIServer := &WebhookServer{
+		Provider: &FakeWebhookProvider{},
+	}
+
+	w := httptest.NewRecorder()
+	providerAPIServer.RecordsHandler(w, newTransactionalRequest(t, "key"))
+	require.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+}
+
+func TestNegotiateHandlerCapabilities(t *testing.T) {
+	for _, tc := range []struct {
+		provider     provider.Provider
+		capabilities string
+	}{
+		{provider: &FakeWebhookProvider{}, capabilities: ""},
+		{provider: &FakeTransactionalWebhookProvider{}, capabilities: CapabilityTransactions},
+	} {
+		w := httptest.NewRecorder()
+		providerAPIServer := &WebhookServer{
+			Provider: tc.provider,
+		}
+		providerAPIServer.NegotiateHandler(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		res := w.Result()
+		require.Equal(t, MediaTypeFormatAndVersion, res.Header.Get(ContentTypeHeader))
+		require.Equal(t, tc.capabilities, res.Header.Get(CapabilitiesHeader))
+	}
+}
+
 func TestAdjustEndpointsHandlerWithInvalidRequest(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPost, "/adjustendpoints", nil)
 	w := httptest.NewRecorder()
@@ -262,6 +324,73 @@ func TestAdjustEndpointsHandlerWithError(t *testing.T) {
 	require.NotNil(t, res.Body)
 }
 
+func TestRecordsHandlerApplyChangesOutsideDomainFilter(t *testing.T) {
+	changes := &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			{
+				DNSName:    "foo.bar.com",
+				RecordType: "A",
+				Targets:    endpoint.Targets{},
+			},
+		},
+		Delete: []*endpoint.Endpoint{
+			{
+				DNSName:    "foo.other.com",
+				RecordType: "A",
+				Targets:    endpoint.Targets{},
+			},
+		},
+	}
+	j, err := json.Marshal(changes)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/records", bytes.NewReader(j))
+	w := httptest.NewRecorder()
+
+	providerAPIServer := &WebhookServer{
+		Provider: &FakeWebhookProvider{
+			domainFilter: endpoint.NewDomainFilter([]string{"bar.com"}),
+		},
+	}
+	providerAPIServer.RecordsHandler(w, req)
+	res := w.Result()
+	require.Equal(t, http.StatusForbidden, res.StatusCode)
+}
+
+func TestAdjustEndpointsHandlerOutsideDomainFilter(t *testing.T) {
+	pve := []*endpoint.Endpoint{
+		{
+			DNSName:    "foo.bar.com",
+			RecordType: "A",
+			Targets:    endpoint.Targets{},
+		},
+		{
+			DNSName:    "foo.other.com",
+			RecordType: "A",
+			Targets:    endpoint.Targets{},
+		},
+	}
+	j, err := json.Marshal(pve)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/adjustendpoints", bytes.NewReader(j))
+	w := httptest.NewRecorder()
+
+	providerAPIServer := &WebhookServer{
+		Provider: &FakeWebhookProvider{
+			domainFilter: endpoint.NewDomainFilter([]string{"bar.com"}),
+		},
+	}
+	providerAPIServer.AdjustEndpointsHandler(w, req)
+	res := w.Result()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	adjusted := []*endpoint.Endpoint{}
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&adjusted))
+	require.Len(t, adjusted, 1)
+	require.Equal(t, "foo.bar.com", adjusted[0].DNSName)
+}
+
 func TestStartHTTPApi(t *testing.T) {
 	startedChan := make(chan struct{})
 	go StartHTTPApi(FakeWebhookProvider{}, startedChan, 5*time.Second, 10*time.Second, "127.0.0.1:8887")
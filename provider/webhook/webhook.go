@@ -20,15 +20,18 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 
 	"sigs.k8s.io/external-dns/endpoint"
 	"sigs.k8s.io/external-dns/plan"
 	webhookapi "sigs.k8s.io/external-dns/provider/webhook/api"
 
 	backoff "github.com/cenkalti/backoff/v4"
+	"github.com/google/uuid"
 	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
 )
@@ -93,6 +96,8 @@ type WebhookProvider struct {
 	client          *http.Client
 	remoteServerURL *url.URL
 	DomainFilter    endpoint.DomainFilter
+	// transactional is set when the plugin applies changes all-or-nothing.
+	transactional bool
 }
 
 func init() {
@@ -105,6 +110,12 @@ func init() {
 }
 
 func NewWebhookProvider(u string) (*WebhookProvider, error) {
+	return NewWebhookProviderWithToken(u, "")
+}
+
+// NewWebhookProviderWithToken returns a webhook provider authenticating its requests with the bearer
+// token, e.g. to a central DNS gateway, unless it is empty.
+func NewWebhookProviderWithToken(u, token string) (*WebhookProvider, error) {
 	parsedURL, err := url.Parse(u)
 	if err != nil {
 		return nil, err
@@ -118,6 +129,9 @@ func NewWebhookProvider(u string) (*WebhookProvider, error) {
 	req.Header.Set(acceptHeader, webhookapi.MediaTypeFormatAndVersion)
 
 	client := &http.Client{}
+	if token != "" {
+		client.Transport = &bearerTokenTransport{token: token, next: http.DefaultTransport}
+	}
 	var resp *http.Response
 	err = backoff.Retry(func() error {
 		resp, err = client.Do(req)
@@ -150,13 +164,41 @@ func NewWebhookProvider(u string) (*WebhookProvider, error) {
 		return nil, fmt.Errorf("wrong content type returned from server: %s", contentType)
 	}
 
+	transactional := hasCapability(resp.Header.Get(webhookapi.CapabilitiesHeader), webhookapi.CapabilityTransactions)
+	if transactional {
+		log.Info("The webhook provider applies changes all-or-nothing")
+	}
+
 	return &WebhookProvider{
 		client:          client,
 		remoteServerURL: parsedURL,
 		DomainFilter:    df,
+		transactional:   transactional,
 	}, nil
 }
 
+// bearerTokenTransport sets the bearer token on the requests.
+type bearerTokenTransport struct {
+	token string
+	next  http.RoundTripper
+}
+
+func (t *bearerTokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set(webhookapi.AuthorizationHeader, "Bearer "+t.token)
+	return t.next.RoundTrip(req)
+}
+
+// hasCapability reports whether the capability is in the comma separated list of capabilities.
+func hasCapability(capabilities, capability string) bool {
+	for _, c := range strings.Split(capabilities, ",") {
+		if strings.EqualFold(strings.TrimSpace(c), capability) {
+			return true
+		}
+	}
+	return false
+}
+
 // Records will make a GET call to remoteServerURL/records and return the results
 func (p WebhookProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
 	recordsRequestsGauge.Inc()
@@ -192,7 +234,9 @@ func (p WebhookProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, err
 	return endpoints, nil
 }
 
-// ApplyChanges will make a POST to remoteServerURL/records with the changes
+// ApplyChanges will make a POST to remoteServerURL/records with the changes.
+// If the plugin supports transactions, the changes are applied all-or-nothing and retried on
+// server errors with the same idempotency key, so they are applied only once.
 func (p WebhookProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
 	applyChangesRequestsGauge.Inc()
 	u := p.remoteServerURL.JoinPath("records").String()
@@ -204,27 +248,65 @@ func (p WebhookProvider) ApplyChanges(ctx context.Context, changes *plan.Changes
 		return err
 	}
 
-	req, err := http.NewRequest("POST", u, b)
+	if !p.transactional {
+		if err := p.postChanges(u, b.Bytes(), nil); err != nil {
+			applyChangesErrorsGauge.Inc()
+			return err
+		}
+		return nil
+	}
+
+	headers := map[string]string{
+		webhookapi.TransactionHeader:    webhookapi.TransactionAllOrNothing,
+		webhookapi.IdempotencyKeyHeader: uuid.NewString(),
+	}
+	err := backoff.Retry(func() error {
+		err := p.postChanges(u, b.Bytes(), headers)
+		var statusErr *applyChangesStatusError
+		if errors.As(err, &statusErr) && statusErr.code < http.StatusInternalServerError {
+			return backoff.Permanent(err)
+		}
+		return err
+	}, backoff.WithContext(backoff.WithMaxRetries(backoff.NewExponentialBackOff(), maxRetries), ctx))
 	if err != nil {
 		applyChangesErrorsGauge.Inc()
+		return err
+	}
+	return nil
+}
+
+// applyChangesStatusError is returned when the plugin responds to changes with an unexpected status code.
+type applyChangesStatusError struct {
+	code int
+}
+
+func (e *applyChangesStatusError) Error() string {
+	return fmt.Sprintf("failed to apply changes with code %d", e.code)
+}
+
+// postChanges sends the encoded changes to the plugin with the additional headers.
+func (p WebhookProvider) postChanges(u string, changes []byte, headers map[string]string) error {
+	req, err := http.NewRequest("POST", u, bytes.NewReader(changes))
+	if err != nil {
 		log.Debugf("Failed to create request: %s", err.Error())
 		return err
 	}
 
 	req.Header.Set(webhookapi.ContentTypeHeader, webhookapi.MediaTypeFormatAndVersion)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
 
 	resp, err := p.client.Do(req)
 	if err != nil {
-		applyChangesErrorsGauge.Inc()
 		log.Debugf("Failed to perform request: %s", err.Error())
 		return err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusNoContent {
-		applyChangesErrorsGauge.Inc()
 		log.Debugf("Failed to apply changes with code %d", resp.StatusCode)
-		return fmt.Errorf("failed to apply changes with code %d", resp.StatusCode)
+		return &applyChangesStatusError{code: resp.StatusCode}
 	}
 	return nil
 }
@@ -26,6 +26,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
 	webhookapi "sigs.k8s.io/external-dns/provider/webhook/api"
 )
 
@@ -133,6 +134,67 @@ func TestApplyChanges(t *testing.T) {
 	require.NotNil(t, err)
 }
 
+func TestApplyChangesTransactional(t *testing.T) {
+	var keys []string
+	statusCodes := []int{http.StatusServiceUnavailable, http.StatusNoContent}
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			w.Header().Set(webhookapi.ContentTypeHeader, webhookapi.MediaTypeFormatAndVersion)
+			w.Header().Set(webhookapi.CapabilitiesHeader, "other, "+webhookapi.CapabilityTransactions)
+			w.Write([]byte(`{}`))
+			return
+		}
+		require.Equal(t, "/records", r.URL.Path)
+		require.Equal(t, webhookapi.TransactionAllOrNothing, r.Header.Get(webhookapi.TransactionHeader))
+		keys = append(keys, r.Header.Get(webhookapi.IdempotencyKeyHeader))
+		w.WriteHeader(statusCodes[0])
+		statusCodes = statusCodes[1:]
+	}))
+	defer svr.Close()
+
+	provider, err := NewWebhookProvider(svr.URL)
+	require.NoError(t, err)
+	require.True(t, provider.transactional)
+
+	// the server error is retried with the same idempotency key
+	err = provider.ApplyChanges(context.TODO(), &plan.Changes{})
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	require.NotEmpty(t, keys[0])
+	require.Equal(t, keys[0], keys[1])
+
+	// a rejected transaction isn't retried, the next one has a new key
+	statusCodes = []int{http.StatusConflict}
+	err = provider.ApplyChanges(context.TODO(), &plan.Changes{})
+	require.EqualError(t, err, "failed to apply changes with code 409")
+	require.Len(t, keys, 3)
+	require.NotEqual(t, keys[0], keys[2])
+}
+
+func TestApplyChangesWithoutTransactions(t *testing.T) {
+	requests := 0
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			w.Header().Set(webhookapi.ContentTypeHeader, webhookapi.MediaTypeFormatAndVersion)
+			w.Write([]byte(`{}`))
+			return
+		}
+		requests++
+		require.Empty(t, r.Header.Get(webhookapi.TransactionHeader))
+		require.Empty(t, r.Header.Get(webhookapi.IdempotencyKeyHeader))
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer svr.Close()
+
+	provider, err := NewWebhookProvider(svr.URL)
+	require.NoError(t, err)
+	require.False(t, provider.transactional)
+
+	err = provider.ApplyChanges(context.TODO(), &plan.Changes{})
+	require.Error(t, err)
+	require.Equal(t, 1, requests)
+}
+
 func TestAdjustEndpoints(t *testing.T) {
 	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path == "/" {
@@ -213,3 +275,21 @@ func TestAdjustendpointsWithError(t *testing.T) {
 	_, err = provider.AdjustEndpoints(endpoints)
 	require.Error(t, err)
 }
+
+func TestBearerToken(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(webhookapi.AuthorizationHeader) != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set(webhookapi.ContentTypeHeader, webhookapi.MediaTypeFormatAndVersion)
+		w.Write([]byte(`{}`))
+	}))
+	defer svr.Close()
+
+	_, err := NewWebhookProvider(svr.URL)
+	require.Error(t, err)
+
+	_, err = NewWebhookProviderWithToken(svr.URL, "secret")
+	require.NoError(t, err)
+}
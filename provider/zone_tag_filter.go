@@ -17,12 +17,27 @@ limitations under the License.
 package provider
 
 import (
+	"regexp"
 	"strings"
 )
 
-// ZoneTagFilter holds a list of zone tags to filter by
+// ZoneTagFilter holds a list of zone tags to filter by. Every filter must match: it is one or more
+// alternative tag sets separated by "|", matching if one of them does, a tag set being one or more
+// tags separated by ",", matching if all of them do. A tag is either "key", matching the zones
+// with the tag, or "key=value", matching the zones with the tag of this value, "*" and "?" in the
+// value matching any characters and any single character. A tag prefixed with "!" matches the zones
+// it would not match otherwise, e.g. "!env=prod".
 type ZoneTagFilter struct {
 	zoneTags []string
+	filters  [][][]zoneTagCondition
+}
+
+// zoneTagCondition is a tag of a zone tag filter.
+type zoneTagCondition struct {
+	key string
+	// value matches the value of the tag, any value if nil
+	value   *regexp.Regexp
+	negated bool
 }
 
 // NewZoneTagFilter returns a new ZoneTagFilter given a list of zone tags
@@ -30,27 +45,72 @@ func NewZoneTagFilter(tags []string) ZoneTagFilter {
 	if len(tags) == 1 && len(tags[0]) == 0 {
 		tags = []string{}
 	}
-	return ZoneTagFilter{zoneTags: tags}
+	filters := make([][][]zoneTagCondition, 0, len(tags))
+	for _, tagFilter := range tags {
+		var alternatives [][]zoneTagCondition
+		for _, tagSet := range strings.Split(tagFilter, "|") {
+			var conditions []zoneTagCondition
+			for _, tag := range strings.Split(tagSet, ",") {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					conditions = append(conditions, newZoneTagCondition(tag))
+				}
+			}
+			if len(conditions) > 0 {
+				alternatives = append(alternatives, conditions)
+			}
+		}
+		filters = append(filters, alternatives)
+	}
+	return ZoneTagFilter{zoneTags: tags, filters: filters}
+}
+
+func newZoneTagCondition(tag string) zoneTagCondition {
+	condition := zoneTagCondition{}
+	condition.negated = strings.HasPrefix(tag, "!")
+	tag = strings.TrimPrefix(tag, "!")
+	key, value, hasValue := strings.Cut(tag, "=")
+	condition.key = key
+	if hasValue {
+		pattern := regexp.QuoteMeta(value)
+		pattern = strings.ReplaceAll(pattern, `\*`, ".*")
+		pattern = strings.ReplaceAll(pattern, `\?`, ".")
+		condition.value = regexp.MustCompile("^" + pattern + "$")
+	}
+	return condition
+}
+
+func (c zoneTagCondition) match(tagsMap map[string]string) bool {
+	value, hasTag := tagsMap[c.key]
+	matches := hasTag && (c.value == nil || c.value.MatchString(value))
+	return matches != c.negated
 }
 
 // Match checks whether a zone's set of tags matches the provided tag values
 func (f ZoneTagFilter) Match(tagsMap map[string]string) bool {
-	for _, tagFilter := range f.zoneTags {
-		filterParts := strings.SplitN(tagFilter, "=", 2)
-		switch len(filterParts) {
-		case 1:
-			if _, hasTag := tagsMap[filterParts[0]]; !hasTag {
-				return false
-			}
-		case 2:
-			if value, hasTag := tagsMap[filterParts[0]]; !hasTag || value != filterParts[1] {
-				return false
-			}
+	for _, alternatives := range f.filters {
+		if !matchAnyTagSet(alternatives, tagsMap) {
+			return false
 		}
 	}
 	return true
 }
 
+func matchAnyTagSet(alternatives [][]zoneTagCondition, tagsMap map[string]string) bool {
+	for _, conditions := range alternatives {
+		matches := true
+		for _, condition := range conditions {
+			if !condition.match(tagsMap) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
+
 // IsEmpty returns true if there are no tags for the filter
 func (f ZoneTagFilter) IsEmpty() bool {
 	return len(f.zoneTags) == 0
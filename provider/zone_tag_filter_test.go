@@ -53,6 +53,36 @@ func TestZoneTagFilterMatch(t *testing.T) {
 		{
 			"multiple filter matches", []string{"tag1=value1", "tag2=value2"}, map[string]string{"tag2": "value2", "tag1": "value1", "tag3": "value3"}, true,
 		},
+		{
+			"value wildcard matches", []string{"team=web-*"}, map[string]string{"team": "web-frontend"}, true,
+		},
+		{
+			"value wildcard no match", []string{"team=web-?"}, map[string]string{"team": "web-frontend"}, false,
+		},
+		{
+			"value wildcard quotes the rest of the value", []string{"team=web.*"}, map[string]string{"team": "web-frontend"}, false,
+		},
+		{
+			"alternative tag sets match", []string{"env=prod,team=web|env=staging"}, map[string]string{"env": "staging"}, true,
+		},
+		{
+			"alternative tag sets no match", []string{"env=prod,team=web|env=staging"}, map[string]string{"env": "prod", "team": "api"}, false,
+		},
+		{
+			"alternative tag sets and filter match", []string{"env=prod|env=staging", "team"}, map[string]string{"env": "prod", "team": "api"}, true,
+		},
+		{
+			"excluded tag matches", []string{"team", "!deprecated"}, map[string]string{"team": "api"}, true,
+		},
+		{
+			"excluded tag no match", []string{"team", "!deprecated"}, map[string]string{"team": "api", "deprecated": "true"}, false,
+		},
+		{
+			"excluded tag value matches", []string{"!env=prod*"}, map[string]string{"env": "staging"}, true,
+		},
+		{
+			"excluded tag value no match", []string{"!env=prod*"}, map[string]string{"env": "production"}, false,
+		},
 	} {
 		zoneTagFilter := NewZoneTagFilter(tc.zoneTagFilter)
 		t.Run(tc.name, func(t *testing.T) {
@@ -16,7 +16,11 @@ limitations under the License.
 
 package provider
 
-import "strings"
+import (
+	"strings"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
 
 type ZoneIDName map[string]string
 
@@ -24,9 +28,13 @@ func (z ZoneIDName) Add(zoneID, zoneName string) {
 	z[zoneID] = zoneName
 }
 
+// FindZone returns the zone of the hostname with the longest name. The names are compared in their
+// canonical form, regardless of their case and trailing dot.
 func (z ZoneIDName) FindZone(hostname string) (suitableZoneID, suitableZoneName string) {
+	hostname = endpoint.CanonicalDNSName(hostname)
 	for zoneID, zoneName := range z {
-		if hostname == zoneName || strings.HasSuffix(hostname, "."+zoneName) {
+		name := endpoint.CanonicalDNSName(zoneName)
+		if hostname == name || strings.HasSuffix(hostname, "."+name) {
 			if suitableZoneName == "" || len(zoneName) > len(suitableZoneName) {
 				suitableZoneID = zoneID
 				suitableZoneName = zoneName
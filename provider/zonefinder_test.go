@@ -62,4 +62,9 @@ func TestZoneIDName(t *testing.T) {
 	zoneID, zoneName = z.FindZone("foo.qux.baz")
 	assert.Equal(t, "foo.qux.baz", zoneName)
 	assert.Equal(t, "654321", zoneID)
+
+	// entry in another case and with a trailing dot
+	zoneID, zoneName = z.FindZone("Name.Foo.qux.baz.")
+	assert.Equal(t, "foo.qux.baz", zoneName)
+	assert.Equal(t, "654321", zoneID)
 }
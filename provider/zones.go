@@ -0,0 +1,41 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrZonesNotListed is returned by ZoneNames for the providers which don't list their zones.
+var ErrZonesNotListed = errors.New("the provider does not list its zones")
+
+// ZoneLister is implemented by providers listing the names of the zones they manage, so that the
+// changes of every zone are applied, and retried when they fail, independently of the other zones.
+type ZoneLister interface {
+	// ZoneNames returns the names of the zones of the provider, e.g. example.org.
+	ZoneNames(ctx context.Context) ([]string, error)
+}
+
+// ZoneNames returns the names of the zones of the provider if it implements ZoneLister, and
+// ErrZonesNotListed otherwise.
+func ZoneNames(ctx context.Context, p Provider) ([]string, error) {
+	if lister, ok := p.(ZoneLister); ok {
+		return lister.ZoneNames(ctx)
+	}
+	return nil, ErrZonesNotListed
+}
@@ -103,3 +103,13 @@ func (sdr *AWSSDRegistry) updateLabels(endpoints []*endpoint.Endpoint) {
 func (sdr *AWSSDRegistry) AdjustEndpoints(endpoints []*endpoint.Endpoint) ([]*endpoint.Endpoint, error) {
 	return sdr.provider.AdjustEndpoints(endpoints)
 }
+
+// ZoneNames returns the names of the zones of the provider.
+func (sdr *AWSSDRegistry) ZoneNames(ctx context.Context) ([]string, error) {
+	return provider.ZoneNames(ctx, sdr.provider)
+}
+
+// PropertyValuesEqual compares the values of provider specific properties as the provider does.
+func (sdr *AWSSDRegistry) PropertyValuesEqual(name string, previous string, current string) bool {
+	return provider.PropertyValuesEqual(sdr.provider, name, previous, current)
+}
@@ -20,7 +20,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -64,15 +67,36 @@ type DynamoDBRegistry struct {
 	recordsCache            []*endpoint.Endpoint
 	recordsCacheRefreshTime time.Time
 	cacheInterval           time.Duration
+
+	// keep the last historySize versions of the targets and TTL of the records, none if zero
+	historySize int
+	// history holds the versions of the records owned by us, guarded by historyMux as it is read
+	// by the debug API
+	history    map[endpoint.EndpointKey][]RecordVersion
+	historyMux sync.Mutex
+	// keep the history of a deleted record in a tombstone item for historyRetention
+	historyRetention time.Duration
+	// tombstones holds the expiry of the tombstone items of the deleted records
+	tombstones map[endpoint.EndpointKey]time.Time
+	// expiredTombstones are deleted from the table with the next changes
+	expiredTombstones sets.Set[endpoint.EndpointKey]
+
+	// applyMux serializes the changes of zones applied concurrently, as they update the labels
+	applyMux sync.Mutex
 }
 
 const dynamodbAttributeMigrate = "dynamodb/needs-migration"
 
+// dynamodbTombstoneSuffix is appended, followed by the owner, to the key of a deleted record to
+// form the key of its tombstone item, so it never collides with the item of a record created
+// again, by us or another owner.
+const dynamodbTombstoneSuffix = "#deleted#"
+
 // DynamoDB allows a maximum batch size of 25 items.
 var dynamodbMaxBatchSize uint8 = 25
 
 // NewDynamoDBRegistry returns a new DynamoDBRegistry object.
-func NewDynamoDBRegistry(provider provider.Provider, ownerID string, dynamodbAPI DynamoDBAPI, table string, txtPrefix, txtSuffix, txtWildcardReplacement string, managedRecordTypes, excludeRecordTypes []string, txtEncryptAESKey []byte, cacheInterval time.Duration) (*DynamoDBRegistry, error) {
+func NewDynamoDBRegistry(provider provider.Provider, ownerID string, dynamodbAPI DynamoDBAPI, table string, txtPrefix, txtSuffix, txtWildcardReplacement string, managedRecordTypes, excludeRecordTypes []string, txtEncryptAESKey []byte, cacheInterval time.Duration, historySize int, historyRetention time.Duration) (*DynamoDBRegistry, error) {
 	if ownerID == "" {
 		return nil, errors.New("owner id cannot be empty")
 	}
@@ -102,6 +126,8 @@ func NewDynamoDBRegistry(provider provider.Provider, ownerID string, dynamodbAPI
 		excludeRecordTypes:  excludeRecordTypes,
 		txtEncryptAESKey:    txtEncryptAESKey,
 		cacheInterval:       cacheInterval,
+		historySize:         historySize,
+		historyRetention:    historyRetention,
 	}, nil
 }
 
@@ -218,6 +244,9 @@ func (im *DynamoDBRegistry) Records(ctx context.Context) ([]*endpoint.Endpoint,
 
 // ApplyChanges updates the DNS provider and DynamoDB table with the changes.
 func (im *DynamoDBRegistry) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	im.applyMux.Lock()
+	defer im.applyMux.Unlock()
+
 	filteredChanges := &plan.Changes{
 		Create:    changes.Create,
 		UpdateNew: endpoint.FilterEndpointsByOwnerID(im.ownerID, changes.UpdateNew),
@@ -234,11 +263,16 @@ func (im *DynamoDBRegistry) ApplyChanges(ctx context.Context, changes *plan.Chan
 
 		key := r.Key()
 		oldLabels := im.labels[key]
+		historyChanged := im.addVersion(r)
+		if _, ok := im.tombstones[key]; ok {
+			// the history moves back from the tombstone to the item of the record
+			im.expiredTombstones.Insert(key)
+		}
 		if oldLabels == nil {
 			statements = im.appendInsert(statements, key, r.Labels)
 		} else {
 			im.orphanedLabels.Delete(key)
-			statements = im.appendUpdate(statements, key, oldLabels, r.Labels)
+			statements = im.appendUpdate(statements, key, oldLabels, r.Labels, historyChanged)
 		}
 
 		im.labels[key] = r.Labels
@@ -249,6 +283,7 @@ func (im *DynamoDBRegistry) ApplyChanges(ctx context.Context, changes *plan.Chan
 
 	for _, r := range filteredChanges.Delete {
 		delete(im.labels, r.Key())
+		im.addDeletion(r.Key())
 		if im.cacheInterval > 0 {
 			im.removeFromCache(r)
 		}
@@ -271,12 +306,13 @@ func (im *DynamoDBRegistry) ApplyChanges(ctx context.Context, changes *plan.Chan
 
 	for _, r := range filteredChanges.UpdateNew {
 		key := r.Key()
+		historyChanged := im.addVersion(r)
 		if needMigration[key] {
 			statements = im.appendInsert(statements, key, r.Labels)
 			// Invalidate the records cache so the next sync deletes the TXT ownership record
 			im.recordsCache = nil
 		} else {
-			statements = im.appendUpdate(statements, key, oldLabels[key], r.Labels)
+			statements = im.appendUpdate(statements, key, oldLabels[key], r.Labels, historyChanged)
 		}
 
 		// add new version of record to caches
@@ -299,19 +335,27 @@ func (im *DynamoDBRegistry) ApplyChanges(ctx context.Context, changes *plan.Chan
 						// The dynamodb insertion failed; remove from our cache.
 						im.removeFromCache(endpoint)
 						delete(im.labels, key)
+						if _, ok := im.tombstones[key]; ok {
+							// keep the tombstone, re-read its history with the next records
+							im.labels = nil
+						} else {
+							im.removeHistory(key)
+						}
+						im.expiredTombstones.Delete(key)
 						return nil
 					}
 				}
 			}
 			context = fmt.Sprintf("inserting dynamodb record %q", aws.StringValue(request.Parameters[0].S))
 		} else {
-			context = fmt.Sprintf("updating dynamodb record %q", aws.StringValue(request.Parameters[1].S))
+			context = fmt.Sprintf("updating dynamodb record %q", aws.StringValue(request.Parameters[len(request.Parameters)-1].S))
 		}
 		return fmt.Errorf("%s: %s: %s", context, aws.StringValue(response.Error.Code), aws.StringValue(response.Error.Message))
 	})
 	if err != nil {
 		im.recordsCache = nil
 		im.labels = nil
+		im.setHistory(nil)
 		return err
 	}
 
@@ -323,22 +367,48 @@ func (im *DynamoDBRegistry) ApplyChanges(ctx context.Context, changes *plan.Chan
 	if err != nil {
 		im.recordsCache = nil
 		im.labels = nil
+		im.setHistory(nil)
 		return err
 	}
 
-	statements = make([]*dynamodb.BatchStatementRequest, 0, len(filteredChanges.Delete)+len(im.orphanedLabels))
+	statements = make([]*dynamodb.BatchStatementRequest, 0, len(filteredChanges.Delete)+len(im.orphanedLabels)+len(im.expiredTombstones))
+	deleted := make([]endpoint.EndpointKey, 0, len(filteredChanges.Delete)+len(im.orphanedLabels))
 	for _, r := range filteredChanges.Delete {
 		statements = im.appendDelete(statements, r.Key())
+		deleted = append(deleted, r.Key())
 	}
 	for r := range im.orphanedLabels {
 		statements = im.appendDelete(statements, r)
 		delete(im.labels, r)
+		im.addDeletion(r)
+		deleted = append(deleted, r)
+	}
+	for key := range im.expiredTombstones {
+		statements = im.appendDeleteTombstone(statements, key)
+		delete(im.tombstones, key)
 	}
 	im.orphanedLabels = nil
-	return im.executeStatements(ctx, statements, func(request *dynamodb.BatchStatementRequest, response *dynamodb.BatchStatementResponse) error {
+	im.expiredTombstones = sets.New[endpoint.EndpointKey]()
+	err = im.executeStatements(ctx, statements, func(request *dynamodb.BatchStatementRequest, response *dynamodb.BatchStatementResponse) error {
 		im.labels = nil
+		im.setHistory(nil)
 		return fmt.Errorf("deleting dynamodb record %q: %s: %s", aws.StringValue(request.Parameters[0].S), aws.StringValue(response.Error.Code), aws.StringValue(response.Error.Message))
 	})
+	if err != nil || im.historySize <= 0 {
+		return err
+	}
+
+	// The tombstones are only written once the records are deleted, so a record that failed to be
+	// deleted never has one.
+	statements = make([]*dynamodb.BatchStatementRequest, 0, len(deleted))
+	for _, key := range deleted {
+		statements = im.appendTombstone(statements, key)
+	}
+	return im.executeStatements(ctx, statements, func(request *dynamodb.BatchStatementRequest, response *dynamodb.BatchStatementResponse) error {
+		im.labels = nil
+		im.setHistory(nil)
+		return fmt.Errorf("writing dynamodb tombstone %q: %s: %s", aws.StringValue(request.Parameters[0].S), aws.StringValue(response.Error.Code), aws.StringValue(response.Error.Message))
+	})
 }
 
 // AdjustEndpoints modifies the endpoints as needed by the specific provider.
@@ -346,6 +416,16 @@ func (im *DynamoDBRegistry) AdjustEndpoints(endpoints []*endpoint.Endpoint) ([]*
 	return im.provider.AdjustEndpoints(endpoints)
 }
 
+// ZoneNames returns the names of the zones of the provider.
+func (im *DynamoDBRegistry) ZoneNames(ctx context.Context) ([]string, error) {
+	return provider.ZoneNames(ctx, im.provider)
+}
+
+// PropertyValuesEqual compares the values of provider specific properties as the provider does.
+func (im *DynamoDBRegistry) PropertyValuesEqual(name string, previous string, current string) bool {
+	return provider.PropertyValuesEqual(im.provider, name, previous, current)
+}
+
 func (im *DynamoDBRegistry) readLabels(ctx context.Context) error {
 	table, err := im.dynamodbAPI.DescribeTableWithContext(ctx, &dynamodb.DescribeTableInput{
 		TableName: aws.String(im.table),
@@ -375,17 +455,43 @@ func (im *DynamoDBRegistry) readLabels(ctx context.Context) error {
 	}
 
 	labels := map[endpoint.EndpointKey]endpoint.Labels{}
+	history := map[endpoint.EndpointKey][]RecordVersion{}
+	tombstones := map[endpoint.EndpointKey]time.Time{}
+	expiredTombstones := sets.New[endpoint.EndpointKey]()
+	projection := "k,l"
+	if im.historySize > 0 {
+		projection = "k,l,h,x"
+	}
 	err = im.dynamodbAPI.ScanPagesWithContext(ctx, &dynamodb.ScanInput{
 		TableName:        aws.String(im.table),
 		FilterExpression: aws.String("o = :ownerval"),
 		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
 			":ownerval": {S: aws.String(im.ownerID)},
 		},
-		ProjectionExpression: aws.String("k,l"),
+		ProjectionExpression: aws.String(projection),
 		ConsistentRead:       aws.Bool(true),
 	}, func(output *dynamodb.ScanOutput, last bool) bool {
 		for _, item := range output.Items {
-			labels[fromDynamoKey(item["k"])] = fromDynamoLabels(item["l"], im.ownerID)
+			if expiry, ok := item["x"]; ok {
+				key := im.fromDynamoTombstoneKey(item["k"])
+				n, _ := strconv.ParseInt(aws.StringValue(expiry.N), 10, 64)
+				if time.Unix(n, 0).Before(time.Now()) {
+					expiredTombstones.Insert(key)
+					continue
+				}
+				tombstones[key] = time.Unix(n, 0)
+				if _, ok := labels[key]; !ok {
+					history[key] = fromDynamoHistory(item["h"])
+				}
+				continue
+			}
+			key := fromDynamoKey(item["k"])
+			labels[key] = fromDynamoLabels(item["l"], im.ownerID)
+			if versions := fromDynamoHistory(item["h"]); len(versions) > 0 {
+				history[key] = versions
+			} else {
+				delete(history, key)
+			}
 		}
 		return true
 	})
@@ -393,7 +499,17 @@ func (im *DynamoDBRegistry) readLabels(ctx context.Context) error {
 		return fmt.Errorf("querying dynamodb: %w", err)
 	}
 
+	// a tombstone of a record that exists again is left over from a failed sync
+	for key := range tombstones {
+		if _, ok := labels[key]; ok {
+			expiredTombstones.Insert(key)
+		}
+	}
+
 	im.labels = labels
+	im.tombstones = tombstones
+	im.expiredTombstones = expiredTombstones
+	im.setHistory(history)
 	return nil
 }
 
@@ -412,6 +528,18 @@ func toDynamoKey(key endpoint.EndpointKey) *dynamodb.AttributeValue {
 	}
 }
 
+func (im *DynamoDBRegistry) fromDynamoTombstoneKey(key *dynamodb.AttributeValue) endpoint.EndpointKey {
+	return fromDynamoKey(&dynamodb.AttributeValue{
+		S: aws.String(strings.TrimSuffix(aws.StringValue(key.S), dynamodbTombstoneSuffix+im.ownerID)),
+	})
+}
+
+func (im *DynamoDBRegistry) toDynamoTombstoneKey(key endpoint.EndpointKey) *dynamodb.AttributeValue {
+	return &dynamodb.AttributeValue{
+		S: aws.String(aws.StringValue(toDynamoKey(key).S) + dynamodbTombstoneSuffix + im.ownerID),
+	}
+}
+
 func fromDynamoLabels(label *dynamodb.AttributeValue, owner string) endpoint.Labels {
 	labels := endpoint.NewLabels()
 	for k, v := range label.M {
@@ -433,6 +561,18 @@ func toDynamoLabels(labels endpoint.Labels) *dynamodb.AttributeValue {
 }
 
 func (im *DynamoDBRegistry) appendInsert(statements []*dynamodb.BatchStatementRequest, key endpoint.EndpointKey, new endpoint.Labels) []*dynamodb.BatchStatementRequest {
+	if im.historySize > 0 {
+		return append(statements, &dynamodb.BatchStatementRequest{
+			Statement: aws.String(fmt.Sprintf("INSERT INTO %q VALUE {'k':?, 'o':?, 'l':?, 'h':?}", im.table)),
+			Parameters: []*dynamodb.AttributeValue{
+				toDynamoKey(key),
+				{S: aws.String(im.ownerID)},
+				toDynamoLabels(new),
+				im.toDynamoHistory(key),
+			},
+			ConsistentRead: aws.Bool(true),
+		})
+	}
 	return append(statements, &dynamodb.BatchStatementRequest{
 		Statement: aws.String(fmt.Sprintf("INSERT INTO %q VALUE {'k':?, 'o':?, 'l':?}", im.table)),
 		Parameters: []*dynamodb.AttributeValue{
@@ -444,20 +584,21 @@ func (im *DynamoDBRegistry) appendInsert(statements []*dynamodb.BatchStatementRe
 	})
 }
 
-func (im *DynamoDBRegistry) appendUpdate(statements []*dynamodb.BatchStatementRequest, key endpoint.EndpointKey, old endpoint.Labels, new endpoint.Labels) []*dynamodb.BatchStatementRequest {
-	if len(old) == len(new) {
-		equal := true
-		for k, v := range old {
-			if newV, exists := new[k]; !exists || v != newV {
-				equal = false
-				break
-			}
-		}
-		if equal {
-			return statements
-		}
+func (im *DynamoDBRegistry) appendUpdate(statements []*dynamodb.BatchStatementRequest, key endpoint.EndpointKey, old endpoint.Labels, new endpoint.Labels, historyChanged bool) []*dynamodb.BatchStatementRequest {
+	if !historyChanged && labelsEqual(old, new) {
+		return statements
 	}
 
+	if im.historySize > 0 {
+		return append(statements, &dynamodb.BatchStatementRequest{
+			Statement: aws.String(fmt.Sprintf("UPDATE %q SET \"l\"=?, \"h\"=? WHERE \"k\"=?", im.table)),
+			Parameters: []*dynamodb.AttributeValue{
+				toDynamoLabels(new),
+				im.toDynamoHistory(key),
+				toDynamoKey(key),
+			},
+		})
+	}
 	return append(statements, &dynamodb.BatchStatementRequest{
 		Statement: aws.String(fmt.Sprintf("UPDATE %q SET \"l\"=? WHERE \"k\"=?", im.table)),
 		Parameters: []*dynamodb.AttributeValue{
@@ -467,6 +608,18 @@ func (im *DynamoDBRegistry) appendUpdate(statements []*dynamodb.BatchStatementRe
 	})
 }
 
+func labelsEqual(old, new endpoint.Labels) bool {
+	if len(old) != len(new) {
+		return false
+	}
+	for k, v := range old {
+		if newV, exists := new[k]; !exists || v != newV {
+			return false
+		}
+	}
+	return true
+}
+
 func (im *DynamoDBRegistry) appendDelete(statements []*dynamodb.BatchStatementRequest, key endpoint.EndpointKey) []*dynamodb.BatchStatementRequest {
 	return append(statements, &dynamodb.BatchStatementRequest{
 		Statement: aws.String(fmt.Sprintf("DELETE FROM %q WHERE \"k\"=? AND \"o\"=?", im.table)),
@@ -477,6 +630,49 @@ func (im *DynamoDBRegistry) appendDelete(statements []*dynamodb.BatchStatementRe
 	})
 }
 
+// appendTombstone keeps the history of a deleted record in its tombstone item until
+// historyRetention passes. The expiry is stored in the "x" attribute in seconds since the epoch, so
+// it can be configured as the time to live attribute of the table.
+func (im *DynamoDBRegistry) appendTombstone(statements []*dynamodb.BatchStatementRequest, key endpoint.EndpointKey) []*dynamodb.BatchStatementRequest {
+	expiry := time.Now().Add(im.historyRetention)
+	expiryValue := &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(expiry.Unix(), 10))}
+	_, exists := im.tombstones[key]
+	if im.tombstones == nil {
+		im.tombstones = map[endpoint.EndpointKey]time.Time{}
+	}
+	im.tombstones[key] = expiry
+
+	if exists {
+		return append(statements, &dynamodb.BatchStatementRequest{
+			Statement: aws.String(fmt.Sprintf("UPDATE %q SET \"h\"=?, \"x\"=? WHERE \"k\"=?", im.table)),
+			Parameters: []*dynamodb.AttributeValue{
+				im.toDynamoHistory(key),
+				expiryValue,
+				im.toDynamoTombstoneKey(key),
+			},
+		})
+	}
+	return append(statements, &dynamodb.BatchStatementRequest{
+		Statement: aws.String(fmt.Sprintf("INSERT INTO %q VALUE {'k':?, 'o':?, 'h':?, 'x':?}", im.table)),
+		Parameters: []*dynamodb.AttributeValue{
+			im.toDynamoTombstoneKey(key),
+			{S: aws.String(im.ownerID)},
+			im.toDynamoHistory(key),
+			expiryValue,
+		},
+	})
+}
+
+func (im *DynamoDBRegistry) appendDeleteTombstone(statements []*dynamodb.BatchStatementRequest, key endpoint.EndpointKey) []*dynamodb.BatchStatementRequest {
+	return append(statements, &dynamodb.BatchStatementRequest{
+		Statement: aws.String(fmt.Sprintf("DELETE FROM %q WHERE \"k\"=? AND \"o\"=?", im.table)),
+		Parameters: []*dynamodb.AttributeValue{
+			im.toDynamoTombstoneKey(key),
+			{S: aws.String(im.ownerID)},
+		},
+	})
+}
+
 func (im *DynamoDBRegistry) executeStatements(ctx context.Context, statements []*dynamodb.BatchStatementRequest, handleErr func(request *dynamodb.BatchStatementRequest, response *dynamodb.BatchStatementResponse) error) error {
 	for len(statements) > 0 {
 		var chunk []*dynamodb.BatchStatementRequest
@@ -501,7 +697,7 @@ func (im *DynamoDBRegistry) executeStatements(ctx context.Context, statements []
 				op, _, _ := strings.Cut(*request.Statement, " ")
 				var key string
 				if op == "UPDATE" {
-					key = *request.Parameters[1].S
+					key = *request.Parameters[len(request.Parameters)-1].S
 				} else {
 					key = *request.Parameters[0].S
 				}
@@ -516,6 +712,144 @@ func (im *DynamoDBRegistry) executeStatements(ctx context.Context, statements []
 	return nil
 }
 
+// addVersion appends the targets and the TTL of the record to its history unless they did not
+// change, keeping the last historySize versions. It returns whether the history changed.
+func (im *DynamoDBRegistry) addVersion(r *endpoint.Endpoint) bool {
+	if im.historySize <= 0 {
+		return false
+	}
+	im.historyMux.Lock()
+	defer im.historyMux.Unlock()
+	if im.history == nil {
+		im.history = map[endpoint.EndpointKey][]RecordVersion{}
+	}
+
+	key := r.Key()
+	versions := im.history[key]
+	if n := len(versions); n > 0 && !versions[n-1].Deleted && versions[n-1].Targets.Same(r.Targets) && versions[n-1].TTL == r.RecordTTL {
+		return false
+	}
+	versions = append(versions, RecordVersion{Time: time.Now().UTC(), Targets: r.Targets, TTL: r.RecordTTL})
+	if len(versions) > im.historySize {
+		versions = versions[len(versions)-im.historySize:]
+	}
+	im.history[key] = versions
+	return true
+}
+
+// addDeletion appends a tombstone version to the history of a deleted record, kept with the
+// tombstone item of the record. Without a history there is nothing to keep.
+func (im *DynamoDBRegistry) addDeletion(key endpoint.EndpointKey) {
+	if im.historySize <= 0 {
+		im.removeHistory(key)
+		return
+	}
+	im.historyMux.Lock()
+	defer im.historyMux.Unlock()
+	if im.history == nil {
+		im.history = map[endpoint.EndpointKey][]RecordVersion{}
+	}
+
+	versions := append(im.history[key], RecordVersion{Time: time.Now().UTC(), Deleted: true})
+	if len(versions) > im.historySize {
+		versions = versions[len(versions)-im.historySize:]
+	}
+	im.history[key] = versions
+}
+
+func (im *DynamoDBRegistry) removeHistory(key endpoint.EndpointKey) {
+	im.historyMux.Lock()
+	defer im.historyMux.Unlock()
+	delete(im.history, key)
+}
+
+func (im *DynamoDBRegistry) setHistory(history map[endpoint.EndpointKey][]RecordVersion) {
+	im.historyMux.Lock()
+	defer im.historyMux.Unlock()
+	im.history = history
+}
+
+// RecordHistory returns the versions of the records of the DNS name owned by this instance, as
+// read from the table or applied since.
+func (im *DynamoDBRegistry) RecordHistory(dnsName string) []RecordVersions {
+	im.historyMux.Lock()
+	defer im.historyMux.Unlock()
+
+	var result []RecordVersions
+	for key, versions := range im.history {
+		if !strings.EqualFold(key.DNSName, dnsName) {
+			continue
+		}
+		newestFirst := make([]RecordVersion, 0, len(versions))
+		for i := len(versions) - 1; i >= 0; i-- {
+			newestFirst = append(newestFirst, versions[i])
+		}
+		result = append(result, RecordVersions{
+			DNSName:       key.DNSName,
+			RecordType:    key.RecordType,
+			SetIdentifier: key.SetIdentifier,
+			Versions:      newestFirst,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].RecordType != result[j].RecordType {
+			return result[i].RecordType < result[j].RecordType
+		}
+		return result[i].SetIdentifier < result[j].SetIdentifier
+	})
+	return result
+}
+
+func (im *DynamoDBRegistry) toDynamoHistory(key endpoint.EndpointKey) *dynamodb.AttributeValue {
+	im.historyMux.Lock()
+	defer im.historyMux.Unlock()
+
+	versions := make([]*dynamodb.AttributeValue, 0, len(im.history[key]))
+	for _, v := range im.history[key] {
+		targets := make([]*dynamodb.AttributeValue, 0, len(v.Targets))
+		for _, t := range v.Targets {
+			targets = append(targets, &dynamodb.AttributeValue{S: aws.String(t)})
+		}
+		version := map[string]*dynamodb.AttributeValue{
+			"t":   {S: aws.String(v.Time.Format(time.RFC3339))},
+			"v":   {L: targets},
+			"ttl": {N: aws.String(strconv.FormatInt(int64(v.TTL), 10))},
+		}
+		if v.Deleted {
+			version["d"] = &dynamodb.AttributeValue{BOOL: aws.Bool(true)}
+		}
+		versions = append(versions, &dynamodb.AttributeValue{M: version})
+	}
+	return &dynamodb.AttributeValue{L: versions}
+}
+
+func fromDynamoHistory(history *dynamodb.AttributeValue) []RecordVersion {
+	if history == nil {
+		return nil
+	}
+	versions := make([]RecordVersion, 0, len(history.L))
+	for _, item := range history.L {
+		var v RecordVersion
+		if t, ok := item.M["t"]; ok {
+			v.Time, _ = time.Parse(time.RFC3339, aws.StringValue(t.S))
+		}
+		if targets, ok := item.M["v"]; ok {
+			for _, t := range targets.L {
+				v.Targets = append(v.Targets, aws.StringValue(t.S))
+			}
+		}
+		if ttl, ok := item.M["ttl"]; ok {
+			n, _ := strconv.ParseInt(aws.StringValue(ttl.N), 10, 64)
+			v.TTL = endpoint.TTL(n)
+		}
+		if deleted, ok := item.M["d"]; ok {
+			v.Deleted = aws.BoolValue(deleted.BOOL)
+		}
+		versions = append(versions, v)
+	}
+	return versions
+}
+
 func (im *DynamoDBRegistry) addToCache(ep *endpoint.Endpoint) {
 	if im.recordsCache != nil {
 		im.recordsCache = append(im.recordsCache, ep)
@@ -18,6 +18,10 @@ package registry
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -38,31 +42,31 @@ import (
 func TestDynamoDBRegistryNew(t *testing.T) {
 	api, p := newDynamoDBAPIStub(t, nil)
 
-	_, err := NewDynamoDBRegistry(p, "test-owner", api, "test-table", "", "", "", []string{}, []string{}, []byte(""), time.Hour)
+	_, err := NewDynamoDBRegistry(p, "test-owner", api, "test-table", "", "", "", []string{}, []string{}, []byte(""), time.Hour, 0, 0)
 	require.NoError(t, err)
 
-	_, err = NewDynamoDBRegistry(p, "test-owner", api, "test-table", "testPrefix", "", "", []string{}, []string{}, []byte(""), time.Hour)
+	_, err = NewDynamoDBRegistry(p, "test-owner", api, "test-table", "testPrefix", "", "", []string{}, []string{}, []byte(""), time.Hour, 0, 0)
 	require.NoError(t, err)
 
-	_, err = NewDynamoDBRegistry(p, "test-owner", api, "test-table", "", "testSuffix", "", []string{}, []string{}, []byte(""), time.Hour)
+	_, err = NewDynamoDBRegistry(p, "test-owner", api, "test-table", "", "testSuffix", "", []string{}, []string{}, []byte(""), time.Hour, 0, 0)
 	require.NoError(t, err)
 
-	_, err = NewDynamoDBRegistry(p, "test-owner", api, "test-table", "", "", "testWildcard", []string{}, []string{}, []byte(""), time.Hour)
+	_, err = NewDynamoDBRegistry(p, "test-owner", api, "test-table", "", "", "testWildcard", []string{}, []string{}, []byte(""), time.Hour, 0, 0)
 	require.NoError(t, err)
 
-	_, err = NewDynamoDBRegistry(p, "test-owner", api, "test-table", "", "", "testWildcard", []string{}, []string{}, []byte(";k&l)nUC/33:{?d{3)54+,AD?]SX%yh^"), time.Hour)
+	_, err = NewDynamoDBRegistry(p, "test-owner", api, "test-table", "", "", "testWildcard", []string{}, []string{}, []byte(";k&l)nUC/33:{?d{3)54+,AD?]SX%yh^"), time.Hour, 0, 0)
 	require.NoError(t, err)
 
-	_, err = NewDynamoDBRegistry(p, "", api, "test-table", "", "", "", []string{}, []string{}, []byte(""), time.Hour)
+	_, err = NewDynamoDBRegistry(p, "", api, "test-table", "", "", "", []string{}, []string{}, []byte(""), time.Hour, 0, 0)
 	require.EqualError(t, err, "owner id cannot be empty")
 
-	_, err = NewDynamoDBRegistry(p, "test-owner", api, "", "", "", "", []string{}, []string{}, []byte(""), time.Hour)
+	_, err = NewDynamoDBRegistry(p, "test-owner", api, "", "", "", "", []string{}, []string{}, []byte(""), time.Hour, 0, 0)
 	require.EqualError(t, err, "table cannot be empty")
 
-	_, err = NewDynamoDBRegistry(p, "test-owner", api, "test-table", "", "", "", []string{}, []string{}, []byte(";k&l)nUC/33:{?d{3)54+,AD?]SX%yh^x"), time.Hour)
+	_, err = NewDynamoDBRegistry(p, "test-owner", api, "test-table", "", "", "", []string{}, []string{}, []byte(";k&l)nUC/33:{?d{3)54+,AD?]SX%yh^x"), time.Hour, 0, 0)
 	require.EqualError(t, err, "the AES Encryption key must have a length of 32 bytes")
 
-	_, err = NewDynamoDBRegistry(p, "test-owner", api, "test-table", "testPrefix", "testSuffix", "", []string{}, []string{}, []byte(""), time.Hour)
+	_, err = NewDynamoDBRegistry(p, "test-owner", api, "test-table", "testPrefix", "testSuffix", "", []string{}, []string{}, []byte(""), time.Hour, 0, 0)
 	require.EqualError(t, err, "txt-prefix and txt-suffix are mutually exclusive")
 }
 
@@ -112,7 +116,7 @@ func TestDynamoDBRegistryRecordsBadTable(t *testing.T) {
 			api, p := newDynamoDBAPIStub(t, nil)
 			tc.setup(&api.tableDescription)
 
-			r, _ := NewDynamoDBRegistry(p, "test-owner", api, "test-table", "", "", "", []string{}, []string{}, nil, time.Hour)
+			r, _ := NewDynamoDBRegistry(p, "test-owner", api, "test-table", "", "", "", []string{}, []string{}, nil, time.Hour, 0, 0)
 
 			_, err := r.Records(context.Background())
 			assert.EqualError(t, err, tc.expected)
@@ -198,7 +202,7 @@ func TestDynamoDBRegistryRecords(t *testing.T) {
 		},
 	}
 
-	r, _ := NewDynamoDBRegistry(p, "test-owner", api, "test-table", "txt.", "", "", []string{}, []string{}, nil, time.Hour)
+	r, _ := NewDynamoDBRegistry(p, "test-owner", api, "test-table", "txt.", "", "", []string{}, []string{}, nil, time.Hour, 0, 0)
 	_ = p.(*wrappedProvider).Provider.ApplyChanges(context.Background(), &plan.Changes{
 		Create: []*endpoint.Endpoint{
 			endpoint.NewEndpoint("migrate.test-zone.example.org", endpoint.RecordTypeA, "3.3.3.3").WithSetIdentifier("set-3"),
@@ -1038,7 +1042,7 @@ func TestDynamoDBRegistryApplyChanges(t *testing.T) {
 
 			ctx := context.Background()
 
-			r, _ := NewDynamoDBRegistry(p, "test-owner", api, "test-table", "txt.", "", "", []string{}, []string{}, nil, time.Hour)
+			r, _ := NewDynamoDBRegistry(p, "test-owner", api, "test-table", "txt.", "", "", []string{}, []string{}, nil, time.Hour, 0, 0)
 			_, err := r.Records(ctx)
 			require.Nil(t, err)
 
@@ -1069,6 +1073,182 @@ func TestDynamoDBRegistryApplyChanges(t *testing.T) {
 	}
 }
 
+func TestDynamoDBRegistryRecordHistory(t *testing.T) {
+	api := &historyDynamoDBStub{}
+	p := inmemory.NewInMemoryProvider()
+	_ = p.CreateZone(testZone)
+	_ = p.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{endpoint.NewEndpoint("bar.test-zone.example.org", endpoint.RecordTypeCNAME, "my-domain.com")},
+	})
+	ctx := context.Background()
+
+	r, err := NewDynamoDBRegistry(p, "test-owner", api, "test-table", "", "", "", []string{}, []string{}, nil, 0, 2, time.Hour)
+	require.NoError(t, err)
+	records, err := r.Records(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "k,l,h,x", api.projection)
+
+	update := func(current *endpoint.Endpoint, target string) *endpoint.Endpoint {
+		desired := current.DeepCopy()
+		desired.Targets = endpoint.Targets{target}
+		api.statements = nil
+		require.NoError(t, r.ApplyChanges(ctx, &plan.Changes{
+			UpdateOld: []*endpoint.Endpoint{current},
+			UpdateNew: []*endpoint.Endpoint{desired},
+		}))
+		return desired
+	}
+
+	// the versions read from the table are kept, the new version is written with the labels
+	bar := update(records[0], "new-domain.com")
+	require.Len(t, api.statements, 1)
+	assert.Equal(t, `UPDATE "test-table" SET "l"=?, "h"=? WHERE "k"=?`, aws.StringValue(api.statements[0].Statement))
+	assert.Len(t, api.statements[0].Parameters[1].L, 2)
+
+	history := r.RecordHistory("bar.test-zone.example.org")
+	require.Len(t, history, 1)
+	assert.Equal(t, endpoint.RecordTypeCNAME, history[0].RecordType)
+	require.Len(t, history[0].Versions, 2)
+	assert.Equal(t, endpoint.Targets{"new-domain.com"}, history[0].Versions[0].Targets)
+	assert.Equal(t, endpoint.Targets{"my-domain.com"}, history[0].Versions[1].Targets)
+	assert.Equal(t, time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC), history[0].Versions[1].Time)
+
+	// only the last versions are kept
+	update(bar, "other-domain.com")
+	history = r.RecordHistory("bar.test-zone.example.org")
+	require.Len(t, history[0].Versions, 2)
+	assert.Equal(t, endpoint.Targets{"other-domain.com"}, history[0].Versions[0].Targets)
+	assert.Equal(t, endpoint.Targets{"new-domain.com"}, history[0].Versions[1].Targets)
+
+	rec := httptest.NewRecorder()
+	NewRecordHistoryHandler(r).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/record-history?name=bar.test-zone.example.org.", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var served []RecordVersions
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &served))
+	assert.Equal(t, history, served)
+
+	rec = httptest.NewRecorder()
+	NewRecordHistoryHandler(r).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/record-history", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestDynamoDBRegistryRecordHistoryTombstones(t *testing.T) {
+	expiry := func(d time.Duration) *dynamodb.AttributeValue {
+		return &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(time.Now().Add(d).Unix(), 10))}
+	}
+	api := &historyDynamoDBStub{tombstones: []map[string]*dynamodb.AttributeValue{
+		{
+			"k": {S: aws.String("foo.test-zone.example.org#A##deleted#test-owner")},
+			"h": {L: []*dynamodb.AttributeValue{{M: map[string]*dynamodb.AttributeValue{
+				"t": {S: aws.String("2024-03-01T12:00:00Z")},
+				"v": {L: []*dynamodb.AttributeValue{{S: aws.String("1.2.3.4")}}},
+			}}, {M: map[string]*dynamodb.AttributeValue{
+				"t": {S: aws.String("2024-03-02T12:00:00Z")},
+				"d": {BOOL: aws.Bool(true)},
+			}}}},
+			"x": expiry(time.Hour),
+		},
+		{
+			"k": {S: aws.String("expired.test-zone.example.org#A##deleted#test-owner")},
+			"h": {L: []*dynamodb.AttributeValue{}},
+			"x": expiry(-time.Hour),
+		},
+	}}
+	p := inmemory.NewInMemoryProvider()
+	_ = p.CreateZone(testZone)
+	_ = p.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{endpoint.NewEndpoint("bar.test-zone.example.org", endpoint.RecordTypeCNAME, "my-domain.com")},
+	})
+	ctx := context.Background()
+
+	r, err := NewDynamoDBRegistry(p, "test-owner", api, "test-table", "", "", "", []string{}, []string{}, nil, 0, 3, time.Hour)
+	require.NoError(t, err)
+	records, err := r.Records(ctx)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+
+	// the history of a deleted record is still served
+	history := r.RecordHistory("foo.test-zone.example.org")
+	require.Len(t, history, 1)
+	require.Len(t, history[0].Versions, 2)
+	assert.True(t, history[0].Versions[0].Deleted)
+	assert.Equal(t, endpoint.Targets{"1.2.3.4"}, history[0].Versions[1].Targets)
+	assert.Empty(t, r.RecordHistory("expired.test-zone.example.org"))
+
+	// deleting a record moves its history to a tombstone, the expired tombstone is removed
+	require.NoError(t, r.ApplyChanges(ctx, &plan.Changes{Delete: records}))
+	require.Len(t, api.statements, 3)
+	for i, k := range []string{"bar.test-zone.example.org#CNAME#", "expired.test-zone.example.org#A##deleted#test-owner"} {
+		assert.Equal(t, `DELETE FROM "test-table" WHERE "k"=? AND "o"=?`, aws.StringValue(api.statements[i].Statement))
+		assert.Equal(t, k, aws.StringValue(api.statements[i].Parameters[0].S))
+	}
+	tombstone := api.statements[2]
+	assert.Equal(t, `INSERT INTO "test-table" VALUE {'k':?, 'o':?, 'h':?, 'x':?}`, aws.StringValue(tombstone.Statement))
+	assert.Equal(t, "bar.test-zone.example.org#CNAME##deleted#test-owner", aws.StringValue(tombstone.Parameters[0].S))
+	require.Len(t, tombstone.Parameters[2].L, 2)
+	assert.True(t, aws.BoolValue(tombstone.Parameters[2].L[1].M["d"].BOOL))
+
+	history = r.RecordHistory("bar.test-zone.example.org")
+	require.Len(t, history, 1)
+	require.Len(t, history[0].Versions, 2)
+	assert.True(t, history[0].Versions[0].Deleted)
+	assert.Equal(t, endpoint.Targets{"my-domain.com"}, history[0].Versions[1].Targets)
+
+	// creating the record again moves the history back from the tombstone
+	api.statements = nil
+	foo := endpoint.NewEndpoint("foo.test-zone.example.org", endpoint.RecordTypeA, "1.2.3.4")
+	require.NoError(t, r.ApplyChanges(ctx, &plan.Changes{Create: []*endpoint.Endpoint{foo}}))
+	require.Len(t, api.statements, 2)
+	assert.Equal(t, `INSERT INTO "test-table" VALUE {'k':?, 'o':?, 'l':?, 'h':?}`, aws.StringValue(api.statements[0].Statement))
+	assert.Len(t, api.statements[0].Parameters[3].L, 3)
+	assert.Equal(t, `DELETE FROM "test-table" WHERE "k"=? AND "o"=?`, aws.StringValue(api.statements[1].Statement))
+	assert.Equal(t, "foo.test-zone.example.org#A##deleted#test-owner", aws.StringValue(api.statements[1].Parameters[0].S))
+
+	history = r.RecordHistory("foo.test-zone.example.org")
+	require.Len(t, history[0].Versions, 3)
+	assert.Equal(t, endpoint.Targets{"1.2.3.4"}, history[0].Versions[0].Targets)
+	assert.True(t, history[0].Versions[1].Deleted)
+}
+
+// historyDynamoDBStub returns a table with a record with a version and the tombstones, and accepts
+// every statement.
+type historyDynamoDBStub struct {
+	tombstones []map[string]*dynamodb.AttributeValue
+	projection string
+	statements []*dynamodb.BatchStatementRequest
+}
+
+func (r *historyDynamoDBStub) DescribeTableWithContext(aws.Context, *dynamodb.DescribeTableInput, ...request.Option) (*dynamodb.DescribeTableOutput, error) {
+	return &dynamodb.DescribeTableOutput{Table: &dynamodb.TableDescription{
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{{AttributeName: aws.String("k"), AttributeType: aws.String("S")}},
+		KeySchema:            []*dynamodb.KeySchemaElement{{AttributeName: aws.String("k"), KeyType: aws.String("HASH")}},
+	}}, nil
+}
+
+func (r *historyDynamoDBStub) ScanPagesWithContext(_ aws.Context, input *dynamodb.ScanInput, fn func(*dynamodb.ScanOutput, bool) bool, _ ...request.Option) error {
+	r.projection = aws.StringValue(input.ProjectionExpression)
+	fn(&dynamodb.ScanOutput{Items: []map[string]*dynamodb.AttributeValue{{
+		"k": {S: aws.String("bar.test-zone.example.org#CNAME#")},
+		"l": {M: map[string]*dynamodb.AttributeValue{}},
+		"h": {L: []*dynamodb.AttributeValue{{M: map[string]*dynamodb.AttributeValue{
+			"t":   {S: aws.String("2024-03-01T12:00:00Z")},
+			"v":   {L: []*dynamodb.AttributeValue{{S: aws.String("my-domain.com")}}},
+			"ttl": {N: aws.String("0")},
+		}}}},
+	}}}, false)
+	fn(&dynamodb.ScanOutput{Items: r.tombstones}, true)
+	return nil
+}
+
+func (r *historyDynamoDBStub) BatchExecuteStatementWithContext(_ aws.Context, input *dynamodb.BatchExecuteStatementInput, _ ...request.Option) (*dynamodb.BatchExecuteStatementOutput, error) {
+	r.statements = append(r.statements, input.Statements...)
+	responses := make([]*dynamodb.BatchStatementResponse, len(input.Statements))
+	for i := range responses {
+		responses[i] = &dynamodb.BatchStatementResponse{}
+	}
+	return &dynamodb.BatchExecuteStatementOutput{Responses: responses}, nil
+}
+
 // DynamoDBAPIStub is a minimal implementation of DynamoDBAPI, used primarily for unit testing.
 type DynamoDBStub struct {
 	t                *testing.T
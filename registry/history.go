@@ -0,0 +1,72 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// RecordVersion is a version of the targets and the TTL of a record applied by the registry, or
+// its deletion.
+type RecordVersion struct {
+	Time    time.Time        `json:"time"`
+	Targets endpoint.Targets `json:"targets"`
+	TTL     endpoint.TTL     `json:"ttl,omitempty"`
+	Deleted bool             `json:"deleted,omitempty"`
+}
+
+// RecordVersions are the versions of a record, newest first.
+type RecordVersions struct {
+	DNSName       string          `json:"dnsName"`
+	RecordType    string          `json:"recordType"`
+	SetIdentifier string          `json:"setIdentifier,omitempty"`
+	Versions      []RecordVersion `json:"versions"`
+}
+
+// RecordHistory is implemented by the registries keeping the last versions of the records they
+// applied, to tell what a record pointed at before.
+type RecordHistory interface {
+	// RecordHistory returns the versions of the records of the DNS name owned by this instance.
+	RecordHistory(dnsName string) []RecordVersions
+}
+
+// NewRecordHistoryHandler serves the versions of the records of the DNS name in the "name" query
+// parameter as JSON.
+func NewRecordHistoryHandler(h RecordHistory) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimSuffix(r.URL.Query().Get("name"), ".")
+		if name == "" {
+			http.Error(w, "missing the DNS name of the records", http.StatusBadRequest)
+			return
+		}
+		history := h.RecordHistory(name)
+		if history == nil {
+			history = []RecordVersions{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(history); err != nil {
+			log.Debugf("Failed to write the record history: %v", err)
+		}
+	})
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"errors"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+// InfobloxEARegistry implements registry interface with ownership information stored in extensible
+// attributes of the records of Infoblox, read into and written from the labels of the endpoints by
+// the Infoblox provider, instead of TXT records
+type InfobloxEARegistry struct {
+	provider provider.Provider
+	ownerID  string
+}
+
+// NewInfobloxEARegistry returns implementation of registry for Infoblox extensible attributes
+func NewInfobloxEARegistry(provider provider.Provider, ownerID string) (*InfobloxEARegistry, error) {
+	if ownerID == "" {
+		return nil, errors.New("owner id cannot be empty")
+	}
+	return &InfobloxEARegistry{
+		provider: provider,
+		ownerID:  ownerID,
+	}, nil
+}
+
+func (r *InfobloxEARegistry) GetDomainFilter() endpoint.DomainFilter {
+	return r.provider.GetDomainFilter()
+}
+
+func (r *InfobloxEARegistry) OwnerID() string {
+	return r.ownerID
+}
+
+// Records returns the records of the provider, labeled with the owners of their extensible attributes
+func (r *InfobloxEARegistry) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	return r.provider.Records(ctx)
+}
+
+// ApplyChanges filters out the records not owned by this instance, and labels the others with the
+// owner written to their extensible attributes
+func (r *InfobloxEARegistry) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	filteredChanges := &plan.Changes{
+		Create:    changes.Create,
+		UpdateNew: endpoint.FilterEndpointsByOwnerID(r.ownerID, changes.UpdateNew),
+		UpdateOld: endpoint.FilterEndpointsByOwnerID(r.ownerID, changes.UpdateOld),
+		Delete:    endpoint.FilterEndpointsByOwnerID(r.ownerID, changes.Delete),
+	}
+
+	for _, ep := range filteredChanges.Create {
+		r.updateLabels(ep)
+	}
+	for _, ep := range filteredChanges.UpdateNew {
+		r.updateLabels(ep)
+	}
+
+	return r.provider.ApplyChanges(ctx, filteredChanges)
+}
+
+func (r *InfobloxEARegistry) updateLabels(ep *endpoint.Endpoint) {
+	if ep.Labels == nil {
+		ep.Labels = endpoint.NewLabels()
+	}
+	ep.Labels[endpoint.OwnerLabelKey] = r.ownerID
+}
+
+// AdjustEndpoints modifies the endpoints as needed by the specific provider
+func (r *InfobloxEARegistry) AdjustEndpoints(endpoints []*endpoint.Endpoint) ([]*endpoint.Endpoint, error) {
+	return r.provider.AdjustEndpoints(endpoints)
+}
+
+// ZoneNames returns the names of the zones of the provider.
+func (r *InfobloxEARegistry) ZoneNames(ctx context.Context) ([]string, error) {
+	return provider.ZoneNames(ctx, r.provider)
+}
+
+// PropertyValuesEqual compares the values of provider specific properties as the provider does.
+func (r *InfobloxEARegistry) PropertyValuesEqual(name string, previous string, current string) bool {
+	return provider.PropertyValuesEqual(r.provider, name, previous, current)
+}
@@ -49,6 +49,16 @@ func (im *NoopRegistry) Records(ctx context.Context) ([]*endpoint.Endpoint, erro
 	return im.provider.Records(ctx)
 }
 
+// RecordsPage returns the records of the page of the dns provider at the token
+func (im *NoopRegistry) RecordsPage(ctx context.Context, pageToken string) ([]*endpoint.Endpoint, string, error) {
+	return provider.RecordsPage(ctx, im.provider, pageToken)
+}
+
+// PagesRecords returns whether the dns provider lists its records in pages.
+func (im *NoopRegistry) PagesRecords() bool {
+	return provider.PagesRecords(im.provider)
+}
+
 // ApplyChanges propagates changes to the dns provider
 func (im *NoopRegistry) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
 	return im.provider.ApplyChanges(ctx, changes)
@@ -58,3 +68,13 @@ func (im *NoopRegistry) ApplyChanges(ctx context.Context, changes *plan.Changes)
 func (im *NoopRegistry) AdjustEndpoints(endpoints []*endpoint.Endpoint) ([]*endpoint.Endpoint, error) {
 	return im.provider.AdjustEndpoints(endpoints)
 }
+
+// ZoneNames returns the names of the zones of the provider.
+func (im *NoopRegistry) ZoneNames(ctx context.Context) ([]string, error) {
+	return provider.ZoneNames(ctx, im.provider)
+}
+
+// PropertyValuesEqual compares the values of provider specific properties as the provider does.
+func (im *NoopRegistry) PropertyValuesEqual(name string, previous string, current string) bool {
+	return provider.PropertyValuesEqual(im.provider, name, previous, current)
+}
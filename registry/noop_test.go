@@ -35,6 +35,16 @@ func TestNoopRegistry(t *testing.T) {
 	t.Run("NewNoopRegistry", testNoopInit)
 	t.Run("Records", testNoopRecords)
 	t.Run("ApplyChanges", testNoopApplyChanges)
+	t.Run("PropertyValuesEqual", testNoopPropertyValuesEqual)
+}
+
+func testNoopPropertyValuesEqual(t *testing.T) {
+	r, err := NewNoopRegistry(inmemory.NewInMemoryProvider())
+	require.NoError(t, err)
+
+	// the in-memory provider compares properties textually
+	assert.True(t, r.PropertyValuesEqual("custom/property", "true", "true"))
+	assert.False(t, r.PropertyValuesEqual("custom/property", "1", "true"))
 }
 
 func testNoopInit(t *testing.T) {
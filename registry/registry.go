@@ -33,4 +33,6 @@ type Registry interface {
 	AdjustEndpoints(endpoints []*endpoint.Endpoint) ([]*endpoint.Endpoint, error)
 	GetDomainFilter() endpoint.DomainFilter
 	OwnerID() string
+	// PropertyValuesEqual compares the values of provider specific properties of current and desired records.
+	PropertyValuesEqual(name string, previous string, current string) bool
 }
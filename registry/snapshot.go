@@ -0,0 +1,87 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// Snapshot is the ownership of the records of a registry: the records of an owner with their
+// labels, e.g. the resource they were created for. Restoring it re-establishes the ownership of
+// the records after the registry lost it, e.g. when the cluster was rebuilt.
+type Snapshot struct {
+	OwnerID string               `json:"ownerID"`
+	Records []*endpoint.Endpoint `json:"records"`
+}
+
+// SnapshotRestorer is implemented by the registries able to restore the ownership of records.
+type SnapshotRestorer interface {
+	// RestoreSnapshot takes the ownership of the records of the snapshot without an owner, and
+	// returns the number of records it took the ownership of.
+	RestoreSnapshot(ctx context.Context, snapshot *Snapshot) (int, error)
+}
+
+// TakeSnapshot returns the records of the registry owned by its owner.
+func TakeSnapshot(ctx context.Context, r Registry) (*Snapshot, error) {
+	records, err := r.Records(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Snapshot{
+		OwnerID: r.OwnerID(),
+		Records: endpoint.FilterEndpointsByOwnerID(r.OwnerID(), records),
+	}, nil
+}
+
+// SaveSnapshot writes the snapshot of the registry as JSON to the file.
+func SaveSnapshot(ctx context.Context, r Registry, path string) (*Snapshot, error) {
+	snapshot, err := TakeSnapshot(ctx, r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to take registry snapshot: %w", err)
+	}
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode registry snapshot: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write registry snapshot: %w", err)
+	}
+	return snapshot, nil
+}
+
+// RestoreSnapshotFile restores the snapshot of the file in the registry.
+func RestoreSnapshotFile(ctx context.Context, r Registry, path string) (int, error) {
+	restorer, ok := r.(SnapshotRestorer)
+	if !ok {
+		return 0, fmt.Errorf("the registry %T doesn't support restoring snapshots", r)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read registry snapshot: %w", err)
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return 0, fmt.Errorf("failed to decode registry snapshot %s: %w", path, err)
+	}
+	return restorer.RestoreSnapshot(ctx, &snapshot)
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider/inmemory"
+)
+
+func newSnapshotTestRegistry(t *testing.T) *TXTRegistry {
+	t.Helper()
+	p := inmemory.NewInMemoryProvider()
+	p.CreateZone(testZone)
+	require.NoError(t, p.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			newEndpointWithOwner("foo.test-zone.example.org", "foo.loadbalancer.com", endpoint.RecordTypeCNAME, ""),
+			newEndpointWithOwner("cname-foo.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=owner,external-dns/resource=ingress/default/foo\"", endpoint.RecordTypeTXT, ""),
+			// the TXT record of bar was lost
+			newEndpointWithOwner("bar.test-zone.example.org", "bar.loadbalancer.com", endpoint.RecordTypeCNAME, ""),
+			newEndpointWithOwner("baz.test-zone.example.org", "baz.loadbalancer.com", endpoint.RecordTypeCNAME, ""),
+			newEndpointWithOwner("cname-baz.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=other\"", endpoint.RecordTypeTXT, ""),
+		},
+	}))
+	r, err := NewTXTRegistry(p, "", "", "owner", 0, "", []string{endpoint.RecordTypeCNAME}, nil, false, nil)
+	require.NoError(t, err)
+	return r
+}
+
+func TestSaveSnapshot(t *testing.T) {
+	r := newSnapshotTestRegistry(t)
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+
+	snapshot, err := SaveSnapshot(context.Background(), r, path)
+	require.NoError(t, err)
+	assert.Equal(t, "owner", snapshot.OwnerID)
+	require.Len(t, snapshot.Records, 1)
+	assert.Equal(t, "foo.test-zone.example.org", snapshot.Records[0].DNSName)
+	assert.Equal(t, "ingress/default/foo", snapshot.Records[0].Labels[endpoint.ResourceLabelKey])
+
+	// restoring a snapshot of records owned already changes nothing
+	restored, err := RestoreSnapshotFile(context.Background(), r, path)
+	require.NoError(t, err)
+	assert.Equal(t, 0, restored)
+}
+
+func TestTXTRegistryRestoreSnapshot(t *testing.T) {
+	ctx := context.Background()
+	r := newSnapshotTestRegistry(t)
+
+	snapshot := &Snapshot{
+		OwnerID: "owner",
+		Records: []*endpoint.Endpoint{
+			newEndpointWithOwnerResource("foo.test-zone.example.org", "foo.loadbalancer.com", endpoint.RecordTypeCNAME, "owner", "ingress/default/foo"),
+			newEndpointWithOwnerResource("bar.test-zone.example.org", "bar.loadbalancer.com", endpoint.RecordTypeCNAME, "owner", "ingress/default/bar"),
+			newEndpointWithOwnerResource("baz.test-zone.example.org", "baz.loadbalancer.com", endpoint.RecordTypeCNAME, "owner", "ingress/default/baz"),
+			newEndpointWithOwnerResource("missing.test-zone.example.org", "missing.loadbalancer.com", endpoint.RecordTypeCNAME, "owner", "ingress/default/missing"),
+		},
+	}
+
+	restored, err := r.RestoreSnapshot(ctx, snapshot)
+	require.NoError(t, err)
+	assert.Equal(t, 1, restored)
+
+	records, err := r.Records(ctx)
+	require.NoError(t, err)
+	owners := map[string]string{}
+	for _, e := range records {
+		owners[e.DNSName] = e.Labels[endpoint.OwnerLabelKey] + "/" + e.Labels[endpoint.ResourceLabelKey]
+	}
+	assert.Equal(t, map[string]string{
+		"foo.test-zone.example.org": "owner/ingress/default/foo",
+		"bar.test-zone.example.org": "owner/ingress/default/bar",
+		"baz.test-zone.example.org": "other/",
+	}, owners)
+
+	snapshot.OwnerID = "other"
+	_, err = r.RestoreSnapshot(ctx, snapshot)
+	assert.Error(t, err)
+}
+
+func TestRestoreSnapshotFileUnsupportedRegistry(t *testing.T) {
+	r, err := NewNoopRegistry(inmemory.NewInMemoryProvider())
+	require.NoError(t, err)
+
+	_, err = RestoreSnapshotFile(context.Background(), r, filepath.Join(t.TempDir(), "snapshot.json"))
+	assert.ErrorContains(t, err, "doesn't support restoring snapshots")
+}
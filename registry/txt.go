@@ -19,7 +19,9 @@ package registry
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -44,6 +46,11 @@ type TXTRegistry struct {
 	recordsCache            []*endpoint.Endpoint
 	recordsCacheRefreshTime time.Time
 	cacheInterval           time.Duration
+	// cacheMux guards the cache updated by the changes of zones applied concurrently
+	cacheMux sync.Mutex
+
+	// the ownership of the records read with the first page, for the pages that follow
+	pagedOwnership *txtOwnership
 
 	// optional string to use to replace the asterisk in wildcard entries - without using this,
 	// registry TXT records corresponding to wildcard records will be invalid (and rejected by most providers), due to
@@ -119,10 +126,88 @@ func (im *TXTRegistry) Records(ctx context.Context) ([]*endpoint.Endpoint, error
 		return nil, err
 	}
 
-	endpoints := []*endpoint.Endpoint{}
+	ownership := newTXTOwnership(len(records)/2 + 1)
+	endpoints, err := im.readOwnership(ownership, records)
+	if err != nil {
+		return nil, err
+	}
+	im.labelRecords(ownership, endpoints)
 
-	labelMap := map[endpoint.EndpointKey]endpoint.Labels{}
-	txtRecordsMap := map[string]struct{}{}
+	// Update the cache.
+	if im.cacheInterval > 0 {
+		im.recordsCache = endpoints
+		im.recordsCacheRefreshTime = time.Now()
+	}
+
+	return endpoints, nil
+}
+
+// RecordsPage returns the records of the page of the provider at the token, with their labels.
+// The ownership of the records is read from all the pages when the first page is requested, since
+// the TXT record of a record may be on another page, and kept for the pages that follow.
+func (im *TXTRegistry) RecordsPage(ctx context.Context, pageToken string) ([]*endpoint.Endpoint, string, error) {
+	if !im.PagesRecords() {
+		// the records are listed once, as a single page
+		records, err := im.Records(ctx)
+		return records, "", err
+	}
+	if pageToken == "" || im.pagedOwnership == nil {
+		ownership := newTXTOwnership(0)
+		_, err := provider.Paginate(ctx, provider.DefaultRetryConfig, func(ctx context.Context, cursor string) ([]*endpoint.Endpoint, string, error) {
+			records, next, err := provider.RecordsPage(ctx, im.provider, cursor)
+			if err != nil {
+				return nil, "", err
+			}
+			// only the ownership is kept, the other records are read again with their page
+			_, err = im.readOwnership(ownership, records)
+			return nil, next, err
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		im.pagedOwnership = ownership
+	}
+
+	records, next, err := provider.RecordsPage(ctx, im.provider, pageToken)
+	if err != nil {
+		return nil, "", err
+	}
+	endpoints, err := im.readOwnership(im.pagedOwnership, records)
+	if err != nil {
+		return nil, "", err
+	}
+	im.labelRecords(im.pagedOwnership, endpoints)
+	return endpoints, next, nil
+}
+
+// PagesRecords returns whether the provider lists its records in pages.
+func (im *TXTRegistry) PagesRecords() bool {
+	return provider.PagesRecords(im.provider)
+}
+
+// txtOwnership is the ownership of the records read from the TXT records of the registry.
+type txtOwnership struct {
+	labelMap      map[endpoint.EndpointKey]endpoint.Labels
+	txtRecordsMap map[string]struct{}
+	// The labels are substrings of the TXT records, interning them lets the TXT records be
+	// garbage collected and shares the owners between all endpoints.
+	interner *endpoint.Interner
+}
+
+func newTXTOwnership(size int) *txtOwnership {
+	return &txtOwnership{
+		labelMap:      make(map[endpoint.EndpointKey]endpoint.Labels, size),
+		txtRecordsMap: make(map[string]struct{}, size),
+		interner:      endpoint.NewInterner(),
+	}
+}
+
+// readOwnership adds the labels of the TXT records of the registry to the ownership and returns
+// every other record.
+func (im *TXTRegistry) readOwnership(ownership *txtOwnership, records []*endpoint.Endpoint) ([]*endpoint.Endpoint, error) {
+	// Every record but the TXT records of the registry is returned, most installations have
+	// about as many TXT records as other records.
+	endpoints := make([]*endpoint.Endpoint, 0, len(records)/2+1)
 
 	for _, record := range records {
 		if record.RecordType != endpoint.RecordTypeTXT {
@@ -141,45 +226,31 @@ func (im *TXTRegistry) Records(ctx context.Context) ([]*endpoint.Endpoint, error
 		if err != nil {
 			return nil, err
 		}
+		ownership.interner.InternLabels(labels)
 
+		// the names are matched in their canonical form, the providers may return them in another
+		// case or with a trailing dot
 		endpointName, recordType := im.mapper.toEndpointName(record.DNSName)
 		key := endpoint.EndpointKey{
-			DNSName:       endpointName,
+			DNSName:       endpoint.CanonicalDNSName(endpointName),
 			RecordType:    recordType,
 			SetIdentifier: record.SetIdentifier,
 		}
-		labelMap[key] = labels
-		txtRecordsMap[record.DNSName] = struct{}{}
+		ownership.labelMap[key] = labels
+		ownership.txtRecordsMap[endpoint.CanonicalDNSName(record.DNSName)] = struct{}{}
 	}
+	return endpoints, nil
+}
 
+// labelRecords sets the labels of the records from the ownership, and flags the records owned by
+// this instance with missing TXT records for an update creating them.
+func (im *TXTRegistry) labelRecords(ownership *txtOwnership, endpoints []*endpoint.Endpoint) {
 	for _, ep := range endpoints {
 		if ep.Labels == nil {
 			ep.Labels = endpoint.NewLabels()
 		}
-		dnsNameSplit := strings.Split(ep.DNSName, ".")
-		// If specified, replace a leading asterisk in the generated txt record name with some other string
-		if im.wildcardReplacement != "" && dnsNameSplit[0] == "*" {
-			dnsNameSplit[0] = im.wildcardReplacement
-		}
-		dnsName := strings.Join(dnsNameSplit, ".")
-		key := endpoint.EndpointKey{
-			DNSName:       dnsName,
-			RecordType:    ep.RecordType,
-			SetIdentifier: ep.SetIdentifier,
-		}
-
-		// AWS Alias records have "new" format encoded as type "cname"
-		if isAlias, found := ep.GetProviderSpecificProperty("alias"); found && isAlias == "true" && ep.RecordType == endpoint.RecordTypeA {
-			key.RecordType = endpoint.RecordTypeCNAME
-		}
-
-		// Handle both new and old registry format with the preference for the new one
-		labels, labelsExist := labelMap[key]
-		if !labelsExist && ep.RecordType != endpoint.RecordTypeAAAA {
-			key.RecordType = ""
-			labels, labelsExist = labelMap[key]
-		}
-		if labelsExist {
+		ownership.interner.InternEndpoint(ep)
+		if labels, labelsExist := im.ownershipLabels(ownership, ep); labelsExist {
 			for k, v := range labels {
 				ep.Labels[k] = v
 			}
@@ -187,26 +258,45 @@ func (im *TXTRegistry) Records(ctx context.Context) ([]*endpoint.Endpoint, error
 
 		// Handle the migration of TXT records created before the new format (introduced in v0.12.0).
 		// The migration is done for the TXT records owned by this instance only.
-		if len(txtRecordsMap) > 0 && ep.Labels[endpoint.OwnerLabelKey] == im.ownerID {
+		if len(ownership.txtRecordsMap) > 0 && ep.Labels[endpoint.OwnerLabelKey] == im.ownerID {
 			if plan.IsManagedRecord(ep.RecordType, im.managedRecordTypes, im.excludeRecordTypes) {
 				// Get desired TXT records and detect the missing ones
 				desiredTXTs := im.generateTXTRecord(ep)
 				for _, desiredTXT := range desiredTXTs {
-					if _, exists := txtRecordsMap[desiredTXT.DNSName]; !exists {
+					if _, exists := ownership.txtRecordsMap[endpoint.CanonicalDNSName(desiredTXT.DNSName)]; !exists {
 						ep.WithProviderSpecific(providerSpecificForceUpdate, "true")
 					}
 				}
 			}
 		}
 	}
+}
 
-	// Update the cache.
-	if im.cacheInterval > 0 {
-		im.recordsCache = endpoints
-		im.recordsCacheRefreshTime = time.Now()
+// ownershipLabels returns the labels of the TXT record of the record, in either format of the registry.
+func (im *TXTRegistry) ownershipLabels(ownership *txtOwnership, ep *endpoint.Endpoint) (endpoint.Labels, bool) {
+	dnsName := ep.DNSName
+	// If specified, replace a leading asterisk in the generated txt record name with some other string
+	if im.wildcardReplacement != "" && (dnsName == "*" || strings.HasPrefix(dnsName, "*.")) {
+		dnsName = im.wildcardReplacement + dnsName[1:]
+	}
+	key := endpoint.EndpointKey{
+		DNSName:       endpoint.CanonicalDNSName(dnsName),
+		RecordType:    ep.RecordType,
+		SetIdentifier: ep.SetIdentifier,
 	}
 
-	return endpoints, nil
+	// AWS Alias records have "new" format encoded as type "cname"
+	if isAlias, found := ep.GetProviderSpecificProperty("alias"); found && isAlias == "true" && ep.RecordType == endpoint.RecordTypeA {
+		key.RecordType = endpoint.RecordTypeCNAME
+	}
+
+	// Handle both new and old registry format with the preference for the new one
+	labels, labelsExist := ownership.labelMap[key]
+	if !labelsExist && ep.RecordType != endpoint.RecordTypeAAAA {
+		key.RecordType = ""
+		labels, labelsExist = ownership.labelMap[key]
+	}
+	return labels, labelsExist
 }
 
 // generateTXTRecord generates both "old" and "new" TXT records.
@@ -306,6 +396,72 @@ func (im *TXTRegistry) AdjustEndpoints(endpoints []*endpoint.Endpoint) ([]*endpo
 	return im.provider.AdjustEndpoints(endpoints)
 }
 
+// ZoneNames returns the names of the zones of the provider.
+func (im *TXTRegistry) ZoneNames(ctx context.Context) ([]string, error) {
+	return provider.ZoneNames(ctx, im.provider)
+}
+
+// PropertyValuesEqual compares the values of provider specific properties as the provider does.
+func (im *TXTRegistry) PropertyValuesEqual(name string, previous string, current string) bool {
+	return provider.PropertyValuesEqual(im.provider, name, previous, current)
+}
+
+// RestoreSnapshot creates the TXT records of the records of the snapshot without an owner. The
+// records owned by another owner, or missing from the provider, are left alone.
+func (im *TXTRegistry) RestoreSnapshot(ctx context.Context, snapshot *Snapshot) (int, error) {
+	if snapshot.OwnerID != im.ownerID {
+		return 0, fmt.Errorf("the snapshot of owner %q can't be restored by owner %q", snapshot.OwnerID, im.ownerID)
+	}
+
+	// the cache could hide the records whose ownership was lost since it was filled
+	im.recordsCache = nil
+	records, err := im.Records(ctx)
+	if err != nil {
+		return 0, err
+	}
+	current := make(map[endpoint.EndpointKey]*endpoint.Endpoint, len(records))
+	for _, r := range records {
+		current[r.CanonicalKey()] = r
+	}
+
+	var (
+		txtRecords []*endpoint.Endpoint
+		restored   int
+	)
+	for _, r := range snapshot.Records {
+		ep, ok := current[r.CanonicalKey()]
+		if !ok {
+			log.Debugf("Skipping the restore of %s, the record doesn't exist", r)
+			continue
+		}
+		if owner := ep.Labels[endpoint.OwnerLabelKey]; owner != "" {
+			if owner != im.ownerID {
+				log.Warnf("Skipping the restore of %s, the record is owned by %q", r, owner)
+			}
+			continue
+		}
+
+		ep = ep.DeepCopy()
+		ep.Labels = endpoint.NewLabels()
+		for k, v := range r.Labels {
+			ep.Labels[k] = v
+		}
+		ep.Labels[endpoint.OwnerLabelKey] = im.ownerID
+		log.Infof("Restoring the ownership of %s", ep)
+		txtRecords = append(txtRecords, im.generateTXTRecord(ep)...)
+		restored++
+	}
+
+	if len(txtRecords) == 0 {
+		return 0, nil
+	}
+	if err := im.provider.ApplyChanges(ctx, &plan.Changes{Create: txtRecords}); err != nil {
+		return 0, err
+	}
+	im.recordsCache = nil
+	return restored, nil
+}
+
 /**
   nameMapper is the interface for mapping between the endpoint for the source
   and the endpoint for the TXT record.
@@ -469,18 +625,22 @@ func (pr affixNameMapper) toNewTXTName(endpointDNSName, recordType string) strin
 }
 
 func (im *TXTRegistry) addToCache(ep *endpoint.Endpoint) {
+	im.cacheMux.Lock()
+	defer im.cacheMux.Unlock()
 	if im.recordsCache != nil {
 		im.recordsCache = append(im.recordsCache, ep)
 	}
 }
 
 func (im *TXTRegistry) removeFromCache(ep *endpoint.Endpoint) {
+	im.cacheMux.Lock()
+	defer im.cacheMux.Unlock()
 	if im.recordsCache == nil || ep == nil {
 		return
 	}
 
 	for i, e := range im.recordsCache {
-		if e.DNSName == ep.DNSName && e.RecordType == ep.RecordType && e.SetIdentifier == ep.SetIdentifier && e.Targets.Same(ep.Targets) {
+		if e.CanonicalKey() == ep.CanonicalKey() && e.Targets.Same(ep.Targets) {
 			// We found a match delete the endpoint from the cache.
 			im.recordsCache = append(im.recordsCache[:i], im.recordsCache[i+1:]...)
 			return
@@ -0,0 +1,229 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+var (
+	orphanedRecords = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "external_dns",
+			Subsystem: "registry",
+			Name:      "orphaned_records",
+			Help:      "Number of ownership records of this owner whose records don't exist, found by the last garbage collection.",
+		},
+	)
+	duplicateRecords = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "external_dns",
+			Subsystem: "registry",
+			Name:      "duplicate_records",
+			Help:      "Number of ownership records of this owner duplicating another one but for the case or the trailing dot of their name, found by the last garbage collection.",
+		},
+	)
+	missingRecords = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "external_dns",
+			Subsystem: "registry",
+			Name:      "missing_records",
+			Help:      "Number of ownership records missing for the records of this owner, found by the last garbage collection.",
+		},
+	)
+	orphanedRecordsDeletedTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "external_dns",
+			Subsystem: "registry",
+			Name:      "orphaned_records_deleted_total",
+			Help:      "Number of orphaned ownership records deleted by the garbage collection.",
+		},
+	)
+	missingRecordsCreatedTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "external_dns",
+			Subsystem: "registry",
+			Name:      "missing_records_created_total",
+			Help:      "Number of missing ownership records created again by the garbage collection.",
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(orphanedRecords)
+	prometheus.MustRegister(duplicateRecords)
+	prometheus.MustRegister(missingRecords)
+	prometheus.MustRegister(orphanedRecordsDeletedTotal)
+	prometheus.MustRegister(missingRecordsCreatedTotal)
+}
+
+// GarbageCollector is implemented by the registries storing the ownership in records of their own,
+// which are left behind when the records they own are removed by a failed or partial change.
+type GarbageCollector interface {
+	// CollectGarbage removes the ownership records whose records don't exist and the duplicated
+	// ownership records, creates the missing ownership records of the records it owns, or only
+	// logs them when dryRun is set, and returns their number.
+	CollectGarbage(ctx context.Context, dryRun bool) (int, error)
+}
+
+// CollectGarbage removes the TXT records of this owner whose records don't exist, and those whose
+// name differs from the name of another one only by its case or trailing dot, left behind by the
+// matching of the names before it was done in their canonical form. Only the TXT records owned by
+// this owner are considered, the orphans of other owners are theirs to remove. It also creates
+// the TXT records missing for the records of this owner, i.e. those owned by a TXT record in one
+// of the formats of the registry but missing the other one. The records without any TXT record
+// are left alone, their owner is unknown.
+func (im *TXTRegistry) CollectGarbage(ctx context.Context, dryRun bool) (int, error) {
+	records, err := im.provider.Records(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	orphans, duplicates := im.orphanedTXTRecords(records)
+	missing, err := im.missingTXTRecords(records)
+	if err != nil {
+		return 0, err
+	}
+	orphanedRecords.Set(float64(len(orphans)))
+	duplicateRecords.Set(float64(len(duplicates)))
+	missingRecords.Set(float64(len(missing)))
+	if len(orphans) == 0 && len(duplicates) == 0 && len(missing) == 0 {
+		return 0, nil
+	}
+
+	for _, r := range orphans {
+		if dryRun {
+			log.Infof("Found orphaned ownership record %s, not deleting it in dry run", r)
+		} else {
+			log.Infof("Deleting orphaned ownership record %s", r)
+		}
+	}
+	for _, r := range duplicates {
+		if dryRun {
+			log.Infof("Found duplicate ownership record %s, not deleting it in dry run", r)
+		} else {
+			log.Infof("Deleting duplicate ownership record %s", r)
+		}
+	}
+	for _, r := range missing {
+		if dryRun {
+			log.Infof("Found missing ownership record %s, not creating it in dry run", r)
+		} else {
+			log.Infof("Creating missing ownership record %s", r)
+		}
+	}
+	garbage := append(orphans, duplicates...)
+	if dryRun {
+		return len(garbage) + len(missing), nil
+	}
+
+	if err := im.provider.ApplyChanges(ctx, &plan.Changes{Create: missing, Delete: garbage}); err != nil {
+		return len(garbage) + len(missing), err
+	}
+	orphanedRecordsDeletedTotal.Add(float64(len(garbage)))
+	missingRecordsCreatedTotal.Add(float64(len(missing)))
+	im.recordsCache = nil
+	return len(garbage) + len(missing), nil
+}
+
+// missingTXTRecords returns the TXT records missing for the records owned by this owner, in the
+// formats of the registry. The records are owned by the TXT records in the other format.
+func (im *TXTRegistry) missingTXTRecords(records []*endpoint.Endpoint) ([]*endpoint.Endpoint, error) {
+	ownership := newTXTOwnership(len(records))
+	endpoints, err := im.readOwnership(ownership, records)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []*endpoint.Endpoint
+	for _, ep := range endpoints {
+		labels, ok := im.ownershipLabels(ownership, ep)
+		if !ok || labels[endpoint.OwnerLabelKey] != im.ownerID {
+			continue
+		}
+		if !plan.IsManagedRecord(ep.RecordType, im.managedRecordTypes, im.excludeRecordTypes) {
+			continue
+		}
+		owned := ep.DeepCopy()
+		owned.Labels = endpoint.NewLabels()
+		for k, v := range labels {
+			owned.Labels[k] = v
+		}
+		for _, txt := range im.generateTXTRecord(owned) {
+			if _, exists := ownership.txtRecordsMap[endpoint.CanonicalDNSName(txt.DNSName)]; !exists {
+				missing = append(missing, txt)
+			}
+		}
+	}
+	return missing, nil
+}
+
+// orphanedTXTRecords returns the TXT records of this owner that no record would generate, in any
+// of the formats of the registry, and those duplicating another one in their canonical form. The
+// TXT records whose names are in their canonical form already are the ones kept.
+func (im *TXTRegistry) orphanedTXTRecords(records []*endpoint.Endpoint) (orphans, duplicates []*endpoint.Endpoint) {
+	type txtKey struct {
+		name          string
+		setIdentifier string
+	}
+
+	var txtRecords []*endpoint.Endpoint
+	expected := make(map[txtKey]struct{}, len(records))
+	for _, r := range records {
+		if r.RecordType == endpoint.RecordTypeTXT {
+			labels, err := endpoint.NewLabelsFromString(r.Targets[0], im.txtEncryptAESKey)
+			if err == nil && labels[endpoint.OwnerLabelKey] == im.ownerID {
+				txtRecords = append(txtRecords, r)
+				continue
+			}
+			// TXT records which aren't ownership records can be owned like any other record
+		}
+
+		recordType := r.RecordType
+		if isAlias, found := r.GetProviderSpecificProperty("alias"); found && isAlias == "true" && recordType == endpoint.RecordTypeA {
+			recordType = endpoint.RecordTypeCNAME
+		}
+		expected[txtKey{endpoint.CanonicalDNSName(im.mapper.toTXTName(r.DNSName)), r.SetIdentifier}] = struct{}{}
+		expected[txtKey{endpoint.CanonicalDNSName(im.mapper.toNewTXTName(r.DNSName, recordType)), r.SetIdentifier}] = struct{}{}
+	}
+
+	sort.SliceStable(txtRecords, func(i, j int) bool {
+		return txtRecords[i].DNSName == endpoint.CanonicalDNSName(txtRecords[i].DNSName) &&
+			txtRecords[j].DNSName != endpoint.CanonicalDNSName(txtRecords[j].DNSName)
+	})
+	seen := make(map[txtKey]struct{}, len(txtRecords))
+	for _, r := range txtRecords {
+		key := txtKey{endpoint.CanonicalDNSName(r.DNSName), r.SetIdentifier}
+		if _, ok := expected[key]; !ok {
+			orphans = append(orphans, r)
+			continue
+		}
+		if _, ok := seen[key]; ok {
+			duplicates = append(duplicates, r)
+			continue
+		}
+		seen[key] = struct{}{}
+	}
+	return orphans, duplicates
+}
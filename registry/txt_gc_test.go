@@ -0,0 +1,113 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider/inmemory"
+)
+
+func TestTXTRegistryCollectGarbage(t *testing.T) {
+	ctx := context.Background()
+	p := inmemory.NewInMemoryProvider()
+	p.CreateZone(testZone)
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			// owned records with both formats of TXT records
+			newEndpointWithOwner("foo.test-zone.example.org", "1.1.1.1", endpoint.RecordTypeA, ""),
+			newEndpointWithOwner("foo.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=owner\"", endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("a-foo.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=owner\"", endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("multiple.test-zone.example.org", "lb1.loadbalancer.com", endpoint.RecordTypeCNAME, "").WithSetIdentifier("set-1"),
+			newEndpointWithOwner("cname-multiple.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=owner\"", endpoint.RecordTypeTXT, "").WithSetIdentifier("set-1"),
+			// owned records missing the TXT record of the new format
+			newEndpointWithOwner("old.test-zone.example.org", "2.2.2.2", endpoint.RecordTypeA, ""),
+			newEndpointWithOwner("old.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=owner,external-dns/resource=service/default/old\"", endpoint.RecordTypeTXT, ""),
+			// records without any TXT record are left alone
+			newEndpointWithOwner("unowned.test-zone.example.org", "3.3.3.3", endpoint.RecordTypeA, ""),
+			// orphaned TXT records
+			newEndpointWithOwner("bar.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=owner\"", endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("cname-bar.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=owner\"", endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("cname-multiple.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=owner\"", endpoint.RecordTypeTXT, "").WithSetIdentifier("set-2"),
+			// TXT records duplicating another one but for the case of their name
+			newEndpointWithOwner("A-Foo.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=owner\"", endpoint.RecordTypeTXT, ""),
+			// the orphaned TXT records of other owners are left alone
+			newEndpointWithOwner("a-baz.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=other\"", endpoint.RecordTypeTXT, ""),
+			// TXT records which aren't ownership records are left alone
+			newEndpointWithOwner("qux.test-zone.example.org", "random", endpoint.RecordTypeTXT, ""),
+		},
+	}))
+	r, err := NewTXTRegistry(p, "", "", "owner", 0, "", []string{endpoint.RecordTypeA, endpoint.RecordTypeCNAME}, nil, false, nil)
+	require.NoError(t, err)
+
+	// the dry run changes nothing
+	repaired, err := r.CollectGarbage(ctx, true)
+	require.NoError(t, err)
+	assert.Equal(t, 6, repaired)
+	records, err := p.Records(ctx)
+	require.NoError(t, err)
+	assert.Len(t, records, 14)
+
+	// 4 orphaned TXT records are deleted, and the missing TXT records of the records "multiple"
+	// and "old" are created
+	repaired, err = r.CollectGarbage(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, 6, repaired)
+
+	records, err = p.Records(ctx)
+	require.NoError(t, err)
+	var names []string
+	for _, e := range records {
+		names = append(names, e.DNSName+"/"+e.RecordType+"/"+e.SetIdentifier)
+	}
+	sort.Strings(names)
+	assert.Equal(t, []string{
+		"a-baz.test-zone.example.org/TXT/",
+		"a-foo.test-zone.example.org/TXT/",
+		"a-old.test-zone.example.org/TXT/",
+		"cname-multiple.test-zone.example.org/TXT/set-1",
+		"foo.test-zone.example.org/A/",
+		"foo.test-zone.example.org/TXT/",
+		"multiple.test-zone.example.org/CNAME/set-1",
+		"multiple.test-zone.example.org/TXT/set-1",
+		"old.test-zone.example.org/A/",
+		"old.test-zone.example.org/TXT/",
+		"qux.test-zone.example.org/TXT/",
+		"unowned.test-zone.example.org/A/",
+	}, names)
+
+	// the recreated TXT record keeps the labels of the other one
+	for _, e := range records {
+		if e.DNSName == "a-old.test-zone.example.org" {
+			labels, err := endpoint.NewLabelsFromString(e.Targets[0], nil)
+			require.NoError(t, err)
+			assert.Equal(t, "owner", labels[endpoint.OwnerLabelKey])
+			assert.Equal(t, "service/default/old", labels[endpoint.ResourceLabelKey])
+		}
+	}
+
+	repaired, err = r.CollectGarbage(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, 0, repaired)
+}
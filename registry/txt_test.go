@@ -18,6 +18,7 @@ package registry
 
 import (
 	"context"
+	"fmt"
 	"reflect"
 	"strings"
 	"testing"
@@ -1646,6 +1647,61 @@ func TestMultiClusterDifferentRecordTypeOwnership(t *testing.T) {
 	}
 }
 
+func TestTXTRegistryRecordsPage(t *testing.T) {
+	ctx := context.Background()
+	p := inmemory.NewInMemoryProvider(inmemory.InMemoryWithPageSize(2))
+	p.CreateZone(testZone)
+	p.ApplyChanges(ctx, &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			newEndpointWithOwner("bar.test-zone.example.org", "my-domain.com", endpoint.RecordTypeCNAME, ""),
+			newEndpointWithOwner("foo.test-zone.example.org", "foo.loadbalancer.com", endpoint.RecordTypeCNAME, ""),
+			newEndpointWithOwner("qux.test-zone.example.org", "random", endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("txt.cname-bar.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=owner\"", endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("txt.cname-foo.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=other\"", endpoint.RecordTypeTXT, ""),
+		},
+	})
+	r, _ := NewTXTRegistry(p, "txt.%{record_type}-", "", "owner", time.Hour, "", []string{}, []string{}, false, nil)
+
+	// the TXT records on the last page give the ownership of the records on the first one
+	records, next, err := r.RecordsPage(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "owner", records[0].Labels[endpoint.OwnerLabelKey])
+	assert.Equal(t, "other", records[1].Labels[endpoint.OwnerLabelKey])
+	require.NotEmpty(t, next)
+
+	records, next, err = r.RecordsPage(ctx, next)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "qux.test-zone.example.org", records[0].DNSName)
+
+	records, next, err = r.RecordsPage(ctx, next)
+	require.NoError(t, err)
+	assert.Empty(t, records)
+	assert.Empty(t, next)
+}
+
+func TestTXTRegistryRecordsCanonicalNames(t *testing.T) {
+	records := []*endpoint.Endpoint{
+		endpoint.NewEndpoint("Foo.Test-Zone.example.org.", endpoint.RecordTypeA, "1.1.1.1"),
+		endpoint.NewEndpoint("a-foo.test-zone.example.org", endpoint.RecordTypeTXT, "\"heritage=external-dns,external-dns/owner=owner\""),
+		endpoint.NewEndpoint("bar.test-zone.example.org", endpoint.RecordTypeCNAME, "lb.example.com"),
+		endpoint.NewEndpoint("CNAME-Bar.test-zone.example.org.", endpoint.RecordTypeTXT, "\"heritage=external-dns,external-dns/owner=owner\""),
+	}
+	r, err := NewTXTRegistry(&recordsProvider{records: records}, "", "", "owner", 0, "", []string{endpoint.RecordTypeA, endpoint.RecordTypeCNAME}, nil, false, nil)
+	require.NoError(t, err)
+
+	endpoints, err := r.Records(context.Background())
+	require.NoError(t, err)
+	require.Len(t, endpoints, 2)
+	for _, ep := range endpoints {
+		assert.Equal(t, "owner", ep.Labels[endpoint.OwnerLabelKey], ep.DNSName)
+		// the TXT records of the old format are missing
+		_, forceUpdate := ep.GetProviderSpecificProperty(providerSpecificForceUpdate)
+		assert.True(t, forceUpdate, ep.DNSName)
+	}
+}
+
 /**
 
 helper methods
@@ -1675,3 +1731,39 @@ func newEndpointWithOwnerResource(dnsName, target, recordType, ownerID, resource
 	e.Labels[endpoint.ResourceLabelKey] = resource
 	return e
 }
+
+// recordsProvider returns the same records on every call of Records.
+type recordsProvider struct {
+	provider.BaseProvider
+	records []*endpoint.Endpoint
+}
+
+func (p *recordsProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	return p.records, nil
+}
+
+func (p *recordsProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	return nil
+}
+
+func BenchmarkTXTRegistryRecords(b *testing.B) {
+	const n = 10000
+	records := make([]*endpoint.Endpoint, 0, 2*n)
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("record-%d.%s", i, testZone)
+		records = append(records,
+			endpoint.NewEndpoint(name, endpoint.RecordTypeCNAME, "lb.example.com"),
+			endpoint.NewEndpoint("cname-"+name, endpoint.RecordTypeTXT, fmt.Sprintf("\"heritage=external-dns,external-dns/owner=owner,external-dns/resource=ingress/default/ingress-%d\"", i)),
+		)
+	}
+	r, err := NewTXTRegistry(&recordsProvider{records: records}, "", "", "owner", 0, "", []string{}, []string{}, false, nil)
+	require.NoError(b, err)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := r.Records(context.Background()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"strings"
+)
+
+// errRollbackUsage is returned when the rollback command misses the ID of the sync to roll back to.
+var errRollbackUsage = errors.New("usage: external-dns rollback --to=<sync-id> [flags of the instance]")
+
+// rollbackArgs splits the arguments of the rollback command into the ID of the sync given with --to
+// and the flags of the instance, the same as those of the regular command, which configure the
+// provider, the registry and the --history-dir the sync is rolled back with.
+func rollbackArgs(args []string) (string, []string, error) {
+	var to string
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; {
+		case strings.HasPrefix(arg, "--to="):
+			to = strings.TrimPrefix(arg, "--to=")
+		case arg == "--to" && i+1 < len(args):
+			i++
+			to = args[i]
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	if to == "" {
+		return "", nil, errRollbackUsage
+	}
+	return to, rest, nil
+}
@@ -0,0 +1,55 @@
+#!/usr/bin/env bash
+
+# Copyright 2024 The Kubernetes Authors.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# Runs the e2e tests of test/e2e in a kind cluster, see docs/contributing/e2e.md.
+
+set -o errexit
+set -o nounset
+set -o pipefail
+
+KIND_CLUSTER_NAME="${KIND_CLUSTER_NAME:-external-dns-e2e}"
+E2E_KEEP_CLUSTER="${E2E_KEEP_CLUSTER:-false}"
+E2E_DNS_LISTEN="${E2E_DNS_LISTEN:-:5353}"
+
+cd "$(dirname "${BASH_SOURCE[0]}")/.."
+
+for cmd in kind docker go; do
+  if ! command -v "${cmd}" &> /dev/null; then
+    echo "${cmd} is required to run the e2e tests" >&2
+    exit 1
+  fi
+done
+scripts/install-ko.sh
+
+if ! kind get clusters | grep -qx "${KIND_CLUSTER_NAME}"; then
+  kind create cluster --name "${KIND_CLUSTER_NAME}" --wait 2m
+  if [ "${E2E_KEEP_CLUSTER}" != "true" ]; then
+    trap 'kind delete cluster --name "${KIND_CLUSTER_NAME}"' EXIT
+  fi
+fi
+KUBECONFIG="$(mktemp)"
+export KUBECONFIG
+kind get kubeconfig --name "${KIND_CLUSTER_NAME}" > "${KUBECONFIG}"
+
+# ko loads the image into the nodes of the kind cluster
+E2E_IMAGE="$(KO_DOCKER_REPO=kind.local KIND_CLUSTER_NAME="${KIND_CLUSTER_NAME}" ko build --bare --platform="linux/$(go env GOARCH)" .)"
+export E2E_IMAGE
+
+# the pods reach the test DNS server, running on the host, through the gateway of the network of kind
+E2E_DNS_HOST="${E2E_DNS_HOST:-$(docker network inspect kind -f '{{range .IPAM.Config}}{{if .Gateway}}{{.Gateway}} {{end}}{{end}}' | awk '{print $1}')}"
+export E2E_DNS_HOST E2E_DNS_LISTEN
+
+go test -tags e2e -count=1 -v -timeout 20m ./test/e2e/... "$@"
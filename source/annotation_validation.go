@@ -0,0 +1,101 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"time"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// AnnotatedHostnames returns the hostnames of the hostname, internal-hostname and aliases
+// annotations, as read by the sources.
+func AnnotatedHostnames(annotations map[string]string) []string {
+	hostnames := append(getHostnamesFromAnnotations(annotations), getInternalHostnamesFromAnnotations(annotations)...)
+	return append(hostnames, getAliasesFromAnnotations(annotations)...)
+}
+
+// ValidateAnnotations returns an error for each external-dns annotation whose value the sources
+// would ignore or turn into records a provider rejects, so that resources can be checked before
+// they are created.
+func ValidateAnnotations(annotations map[string]string) error {
+	var errs []error
+	for _, key := range []string{hostnameAnnotationKey, internalHostnameAnnotationKey, aliasesAnnotationKey} {
+		if _, ok := annotations[key]; !ok {
+			continue
+		}
+		for _, hostname := range splitHostnameAnnotation(annotations[key]) {
+			if err := endpoint.ValidateDNSName(hostname); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			}
+		}
+	}
+	if value, ok := annotations[ttlAnnotationKey]; ok {
+		ttl, err := parseTTL(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %q is not a valid TTL", ttlAnnotationKey, value))
+		} else if ttl < ttlMinimum || ttl > ttlMaximum {
+			errs = append(errs, fmt.Errorf("%s: %d is not between %d and %d", ttlAnnotationKey, ttl, ttlMinimum, ttlMaximum))
+		}
+	}
+	if _, ok := annotations[targetAnnotationKey]; ok {
+		for _, target := range getTargetsFromTargetAnnotation(annotations) {
+			if _, err := netip.ParseAddr(target); err == nil {
+				continue
+			}
+			if err := endpoint.ValidateDNSName(target); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", targetAnnotationKey, err))
+			}
+		}
+	}
+	if value, ok := annotations[aliasAnnotationKey]; ok && value != "true" && value != "false" {
+		errs = append(errs, fmt.Errorf("%s: %q is neither true nor false", aliasAnnotationKey, value))
+	}
+	if value, ok := annotations[dampeningWindowAnnotationKey]; ok {
+		if window, err := time.ParseDuration(value); err != nil || window < 0 {
+			errs = append(errs, fmt.Errorf("%s: %q is not a valid duration", dampeningWindowAnnotationKey, value))
+		}
+	}
+	activeFrom, err := validateActiveTime(annotations, activeFromAnnotationKey)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	activeUntil, err := validateActiveTime(annotations, activeUntilAnnotationKey)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if !activeFrom.IsZero() && !activeUntil.IsZero() && !activeUntil.After(activeFrom) {
+		errs = append(errs, fmt.Errorf("%s: %s is not after %s", activeUntilAnnotationKey, annotations[activeUntilAnnotationKey], annotations[activeFromAnnotationKey]))
+	}
+	return errors.Join(errs...)
+}
+
+// validateActiveTime returns the time of the annotation, the zero time if it isn't set.
+func validateActiveTime(annotations map[string]string, key string) (time.Time, error) {
+	value, ok := annotations[key]
+	if !ok {
+		return time.Time{}, nil
+	}
+	at, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s: %q is not an RFC 3339 time", key, value)
+	}
+	return at, nil
+}
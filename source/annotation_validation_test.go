@@ -0,0 +1,113 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateAnnotations(t *testing.T) {
+	for _, tt := range []struct {
+		title       string
+		annotations map[string]string
+		err         string
+	}{
+		{
+			title: "valid",
+			annotations: map[string]string{
+				hostnameAnnotationKey:         "app.example.org, *.app.example.org.",
+				internalHostnameAnnotationKey: "app.internal.example.org",
+				aliasesAnnotationKey:          "www.example.org",
+				ttlAnnotationKey:              "10m",
+				targetAnnotationKey:           "1.2.3.4,lb.example.org.",
+				aliasAnnotationKey:            "true",
+				dampeningWindowAnnotationKey:  "5m",
+				activeFromAnnotationKey:       "2024-03-01T18:00:00Z",
+				activeUntilAnnotationKey:      "2024-03-04T00:00:00+01:00",
+			},
+		},
+		{
+			title:       "no annotations",
+			annotations: map[string]string{"app": "web"},
+		},
+		{
+			title:       "invalid hostname",
+			annotations: map[string]string{hostnameAnnotationKey: "app.example.org,app_!.example.org"},
+			err:         `external-dns.alpha.kubernetes.io/hostname: label "app_!" of "app_!.example.org" is invalid`,
+		},
+		{
+			title:       "empty hostname",
+			annotations: map[string]string{internalHostnameAnnotationKey: "app.example.org,"},
+			err:         "external-dns.alpha.kubernetes.io/internal-hostname: name is empty",
+		},
+		{
+			title:       "invalid alias hostname",
+			annotations: map[string]string{aliasesAnnotationKey: "www.example.org,-www.example.org"},
+			err:         `external-dns.alpha.kubernetes.io/aliases: label "-www" of "-www.example.org" is invalid`,
+		},
+		{
+			title:       "invalid TTL",
+			annotations: map[string]string{ttlAnnotationKey: "ten"},
+			err:         `external-dns.alpha.kubernetes.io/ttl: "ten" is not a valid TTL`,
+		},
+		{
+			title:       "TTL out of range",
+			annotations: map[string]string{ttlAnnotationKey: "0"},
+			err:         "external-dns.alpha.kubernetes.io/ttl: 0 is not between 1 and 2147483647",
+		},
+		{
+			title:       "invalid target",
+			annotations: map[string]string{targetAnnotationKey: "lb..example.org"},
+			err:         `external-dns.alpha.kubernetes.io/target: label "" of "lb..example.org" is invalid`,
+		},
+		{
+			title:       "invalid alias and dampening window",
+			annotations: map[string]string{aliasAnnotationKey: "yes", dampeningWindowAnnotationKey: "-1m"},
+			err:         "external-dns.alpha.kubernetes.io/alias: \"yes\" is neither true nor false\nexternal-dns.alpha.kubernetes.io/dampening-window: \"-1m\" is not a valid duration",
+		},
+		{
+			title:       "invalid active time",
+			annotations: map[string]string{activeFromAnnotationKey: "2024-03-01 18:00"},
+			err:         `external-dns.alpha.kubernetes.io/active-from: "2024-03-01 18:00" is not an RFC 3339 time`,
+		},
+		{
+			title:       "active until before active from",
+			annotations: map[string]string{activeFromAnnotationKey: "2024-03-01T18:00:00Z", activeUntilAnnotationKey: "2024-03-01T18:00:00Z"},
+			err:         "external-dns.alpha.kubernetes.io/active-until: 2024-03-01T18:00:00Z is not after 2024-03-01T18:00:00Z",
+		},
+	} {
+		t.Run(tt.title, func(t *testing.T) {
+			err := ValidateAnnotations(tt.annotations)
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestAnnotatedHostnames(t *testing.T) {
+	assert.Equal(t, []string{"a.example.org", "b.example.org", "c.example.org", "d.example.org"}, AnnotatedHostnames(map[string]string{
+		hostnameAnnotationKey:         "a.example.org, b.example.org",
+		internalHostnameAnnotationKey: "c.example.org",
+		aliasesAnnotationKey:          "d.example.org",
+	}))
+	assert.Empty(t, AnnotatedHostnames(map[string]string{}))
+}
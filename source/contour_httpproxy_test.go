@@ -30,6 +30,8 @@ import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/external-dns/endpoint"
 )
@@ -95,6 +97,8 @@ func (suite *HTTPProxySuite) SetupTest() {
 		"{{.Name}}",
 		false,
 		false,
+		labels.Everything(),
+		fields.Everything(),
 	)
 	suite.NoError(err, "should initialize httpproxy source")
 
@@ -192,6 +196,8 @@ func TestNewContourHTTPProxySource(t *testing.T) {
 				ti.fqdnTemplate,
 				ti.combineFQDNAndAnnotation,
 				false,
+				labels.Everything(),
+				fields.Everything(),
 			)
 			if ti.expectError {
 				assert.Error(t, err)
@@ -1060,6 +1066,8 @@ func testHTTPProxyEndpoints(t *testing.T) {
 				ti.fqdnTemplate,
 				ti.combineFQDNAndAnnotation,
 				ti.ignoreHostnameAnnotation,
+				labels.Everything(),
+				fields.Everything(),
 			)
 			require.NoError(t, err)
 
@@ -1087,6 +1095,8 @@ func newTestHTTPProxySource() (*httpProxySource, error) {
 		"{{.Name}}",
 		false,
 		false,
+		labels.Everything(),
+		fields.Everything(),
 	)
 	if err != nil {
 		return nil, err
@@ -1145,7 +1155,7 @@ func (ir fakeHTTPProxy) HTTPProxy() *projectcontour.HTTPProxy {
 		},
 		Spec: spec,
 		Status: projectcontour.HTTPProxyStatus{
-			LoadBalancer:  lb,
+			LoadBalancer: lb,
 		},
 	}
 
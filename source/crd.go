@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -28,6 +29,7 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -48,7 +50,11 @@ type crdSource struct {
 	codec            runtime.ParameterCodec
 	annotationFilter string
 	labelSelector    labels.Selector
+	fieldSelector    fields.Selector
 	informer         *cache.SharedInformer
+	// passthroughRecordTypes are the record types without bespoke support whose targets are
+	// passed through as raw RDATA
+	passthroughRecordTypes []string
 }
 
 func addKnownTypes(scheme *runtime.Scheme, groupVersion schema.GroupVersion) error {
@@ -108,24 +114,29 @@ func NewCRDClientForAPIVersionKind(client kubernetes.Interface, kubeConfig, apiS
 }
 
 // NewCRDSource creates a new crdSource with the given config.
-func NewCRDSource(crdClient rest.Interface, namespace, kind string, annotationFilter string, labelSelector labels.Selector, scheme *runtime.Scheme, startInformer bool) (Source, error) {
+func NewCRDSource(crdClient rest.Interface, namespace, kind string, annotationFilter string, labelSelector labels.Selector, fieldSelector fields.Selector, scheme *runtime.Scheme, startInformer bool, passthroughRecordTypes []string) (Source, error) {
 	sourceCrd := crdSource{
-		crdResource:      strings.ToLower(kind) + "s",
-		namespace:        namespace,
-		annotationFilter: annotationFilter,
-		labelSelector:    labelSelector,
-		crdClient:        crdClient,
-		codec:            runtime.NewParameterCodec(scheme),
+		crdResource:            strings.ToLower(kind) + "s",
+		namespace:              namespace,
+		annotationFilter:       annotationFilter,
+		labelSelector:          labelSelector,
+		fieldSelector:          fieldSelector,
+		crdClient:              crdClient,
+		codec:                  runtime.NewParameterCodec(scheme),
+		passthroughRecordTypes: passthroughRecordTypes,
 	}
 	if startInformer {
 		// external-dns already runs its sync-handler periodically (controlled by `--interval` flag) to ensure any
 		// missed or dropped events are handled.  specify a resync period 0 to avoid unnecessary sync handler invocations.
+		tweakListOptions := newListOptionsTweak(labelSelector, fieldSelector)
 		informer := cache.NewSharedInformer(
 			&cache.ListWatch{
 				ListFunc: func(lo metav1.ListOptions) (result runtime.Object, err error) {
+					tweakListOptions(&lo)
 					return sourceCrd.List(context.TODO(), &lo)
 				},
 				WatchFunc: func(lo metav1.ListOptions) (watch.Interface, error) {
+					tweakListOptions(&lo)
 					return sourceCrd.watch(context.TODO(), &lo)
 				},
 			},
@@ -168,7 +179,11 @@ func (cs *crdSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error
 		err    error
 	)
 
-	result, err = cs.List(ctx, &metav1.ListOptions{LabelSelector: cs.labelSelector.String()})
+	listOptions := metav1.ListOptions{LabelSelector: cs.labelSelector.String()}
+	if cs.fieldSelector != nil && !cs.fieldSelector.Empty() {
+		listOptions.FieldSelector = cs.fieldSelector.String()
+	}
+	result, err = cs.List(ctx, &listOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -180,14 +195,54 @@ func (cs *crdSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error
 	}
 
 	for _, dnsEndpoint := range result.Items {
-		// Make sure that all endpoints have targets for A or CNAME type
-		crdEndpoints := []*endpoint.Endpoint{}
-		for _, ep := range dnsEndpoint.Spec.Endpoints {
-			if (ep.RecordType == "CNAME" || ep.RecordType == "A" || ep.RecordType == "AAAA") && len(ep.Targets) < 1 {
-				log.Warnf("Endpoint %s with DNSName %s has an empty list of targets", dnsEndpoint.ObjectMeta.Name, ep.DNSName)
+		// Check controller annotation to see if we are responsible.
+		controller, ok := dnsEndpoint.Annotations[controllerAnnotationKey]
+		if ok && controller != controllerAnnotationValue {
+			log.Debugf("Skipping DNSEndpoint %s/%s because controller value does not match, found: %s, required: %s",
+				dnsEndpoint.Namespace, dnsEndpoint.Name, controller, controllerAnnotationValue)
+			continue
+		}
+
+		endpoints = append(endpoints, endpointsFromDNSEndpoint(&dnsEndpoint, cs.passthroughRecordTypes)...)
+
+		if dnsEndpoint.Status.ObservedGeneration == dnsEndpoint.Generation {
+			continue
+		}
+
+		dnsEndpoint.Status.ObservedGeneration = dnsEndpoint.Generation
+		// Update the ObservedGeneration
+		_, err = cs.UpdateStatus(ctx, &dnsEndpoint)
+		if err != nil {
+			log.Warnf("Could not update ObservedGeneration of the CRD: %v", err)
+		}
+	}
+
+	return endpoints, nil
+}
+
+// endpointsFromDNSEndpoint returns the endpoints of the DNSEndpoint with legal targets, labelled
+// with the DNSEndpoint as their resource. The endpoints of the record types without bespoke support
+// are skipped unless they are passthrough record types, whose targets are raw RDATA.
+func endpointsFromDNSEndpoint(dnsEndpoint *endpoint.DNSEndpoint, passthroughRecordTypes []string) []*endpoint.Endpoint {
+	// Make sure that all endpoints have targets for A or CNAME type
+	crdEndpoints := []*endpoint.Endpoint{}
+	for _, ep := range dnsEndpoint.Spec.Endpoints {
+		if (ep.RecordType == "CNAME" || ep.RecordType == "A" || ep.RecordType == "AAAA") && len(ep.Targets) < 1 {
+			log.Warnf("Endpoint %s with DNSName %s has an empty list of targets", dnsEndpoint.ObjectMeta.Name, ep.DNSName)
+			continue
+		}
+
+		switch {
+		case slices.Contains(passthroughRecordTypes, ep.RecordType):
+		case !endpoint.IsSupportedRecordType(ep.RecordType):
+			log.Warnf("Endpoint %s with DNSName %s has the record type %s, which is not passed through, see --passthrough-record-types", dnsEndpoint.ObjectMeta.Name, ep.DNSName, ep.RecordType)
+			continue
+		case ep.RecordType == endpoint.RecordTypeNAPTR:
+			if err := normalizeNAPTRTargets(ep); err != nil {
+				log.Warnf("Endpoint %s with DNSName %s has an illegal NAPTR target: %v", dnsEndpoint.ObjectMeta.Name, ep.DNSName, err)
 				continue
 			}
-
+		default:
 			illegalTarget := false
 			for _, target := range ep.Targets {
 				if strings.HasSuffix(target, ".") {
@@ -199,36 +254,29 @@ func (cs *crdSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error
 				log.Warnf("Endpoint %s with DNSName %s has an illegal target. The subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com')", dnsEndpoint.ObjectMeta.Name, ep.DNSName)
 				continue
 			}
-
-			if ep.Labels == nil {
-				ep.Labels = endpoint.NewLabels()
-			}
-
-			crdEndpoints = append(crdEndpoints, ep)
 		}
 
-		cs.setResourceLabel(&dnsEndpoint, crdEndpoints)
-		endpoints = append(endpoints, crdEndpoints...)
-
-		if dnsEndpoint.Status.ObservedGeneration == dnsEndpoint.Generation {
-			continue
+		if ep.Labels == nil {
+			ep.Labels = endpoint.NewLabels()
 		}
+		ep.Labels[endpoint.ResourceLabelKey] = fmt.Sprintf("crd/%s/%s", dnsEndpoint.ObjectMeta.Namespace, dnsEndpoint.ObjectMeta.Name)
 
-		dnsEndpoint.Status.ObservedGeneration = dnsEndpoint.Generation
-		// Update the ObservedGeneration
-		_, err = cs.UpdateStatus(ctx, &dnsEndpoint)
-		if err != nil {
-			log.Warnf("Could not update ObservedGeneration of the CRD: %v", err)
-		}
+		crdEndpoints = append(crdEndpoints, ep)
 	}
-
-	return endpoints, nil
+	return crdEndpoints
 }
 
-func (cs *crdSource) setResourceLabel(crd *endpoint.DNSEndpoint, endpoints []*endpoint.Endpoint) {
-	for _, ep := range endpoints {
-		ep.Labels[endpoint.ResourceLabelKey] = fmt.Sprintf("crd/%s/%s", crd.ObjectMeta.Namespace, crd.ObjectMeta.Name)
+// normalizeNAPTRTargets rewrites the NAPTR targets of the endpoint in the form of the targets of
+// the providers, so that they compare equal.
+func normalizeNAPTRTargets(ep *endpoint.Endpoint) error {
+	for i, target := range ep.Targets {
+		naptr, err := endpoint.ParseNAPTRTarget(target)
+		if err != nil {
+			return err
+		}
+		ep.Targets[i] = naptr.String()
 	}
+	return nil
 }
 
 func (cs *crdSource) watch(ctx context.Context, opts *metav1.ListOptions) (watch.Interface, error) {
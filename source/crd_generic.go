@@ -0,0 +1,350 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/util/jsonpath"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// GenericCRDMapping describes where the records of the objects of a custom resource are, so that
+// any custom resource can be a source without mirroring its objects as DNSEndpoints.
+type GenericCRDMapping struct {
+	Resource schema.GroupVersionResource
+	// HostnamesPath is the JSONPath of the hostnames of an object.
+	HostnamesPath string
+	// TargetsPath is the JSONPath of the targets of an object.
+	TargetsPath string
+	// TTLPath is the optional JSONPath of the TTL of an object, in seconds or as a duration.
+	TTLPath string
+	// ProviderSpecificPath is the optional JSONPath of the provider specific properties of an
+	// object, either a map or a list of name and value pairs.
+	ProviderSpecificPath string
+}
+
+// ParseGenericCRDMapping parses a mapping of the form "resource.group/version" followed by
+// ";"-separated settings: hostnames=<JSONPath> and targets=<JSONPath>, which are required, and
+// ttl=<JSONPath> and provider-specific=<JSONPath>. For example:
+//
+//	applications.platform.example.com/v1;hostnames={.spec.dns.hostnames[*]};targets={.status.addresses[*].ip}
+func ParseGenericCRDMapping(s string) (GenericCRDMapping, error) {
+	var m GenericCRDMapping
+	resource, err := parseResourceMapping(s, "generic CRD mapping", func(key, value string) bool {
+		switch key {
+		case "hostnames":
+			m.HostnamesPath = value
+		case "targets":
+			m.TargetsPath = value
+		case "ttl":
+			m.TTLPath = value
+		case "provider-specific":
+			m.ProviderSpecificPath = value
+		default:
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return GenericCRDMapping{}, err
+	}
+	m.Resource = resource
+
+	if m.HostnamesPath == "" || m.TargetsPath == "" {
+		return GenericCRDMapping{}, fmt.Errorf("invalid generic CRD mapping %q: hostnames and targets are required", s)
+	}
+	if err := validateMappingJSONPaths(s, "generic CRD mapping", m.HostnamesPath, m.TargetsPath, m.TTLPath, m.ProviderSpecificPath); err != nil {
+		return GenericCRDMapping{}, err
+	}
+	return m, nil
+}
+
+// genericCRDSource is an implementation of Source that provides endpoints by reading the objects of
+// a custom resource with the JSONPaths of a mapping.
+type genericCRDSource struct {
+	informer             informers.GenericInformer
+	mapping              GenericCRDMapping
+	namespace            string
+	annotationFilter     string
+	hostnamesPath        *jsonpath.JSONPath
+	targetsPath          *jsonpath.JSONPath
+	ttlPath              *jsonpath.JSONPath
+	providerSpecificPath *jsonpath.JSONPath
+}
+
+// NewGenericCRDSource creates a new genericCRDSource for the custom resource of the mapping.
+func NewGenericCRDSource(
+	ctx context.Context,
+	dynamicKubeClient dynamic.Interface,
+	namespace string,
+	annotationFilter string,
+	mapping GenericCRDMapping,
+	labelSelector labels.Selector,
+	fieldSelector fields.Selector,
+) (Source, error) {
+	gs := &genericCRDSource{
+		mapping:          mapping,
+		namespace:        namespace,
+		annotationFilter: annotationFilter,
+	}
+	for _, p := range []struct {
+		path  string
+		field **jsonpath.JSONPath
+	}{
+		{mapping.HostnamesPath, &gs.hostnamesPath},
+		{mapping.TargetsPath, &gs.targetsPath},
+		{mapping.TTLPath, &gs.ttlPath},
+		{mapping.ProviderSpecificPath, &gs.providerSpecificPath},
+	} {
+		if p.path == "" {
+			continue
+		}
+		jp := jsonpath.New(mapping.Resource.Resource).AllowMissingKeys(true)
+		if err := jp.Parse(p.path); err != nil {
+			return nil, err
+		}
+		*p.field = jp
+	}
+
+	informerFactory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(dynamicKubeClient, 0, namespace, newListOptionsTweak(labelSelector, fieldSelector))
+	gs.informer = informerFactory.ForResource(mapping.Resource)
+	gs.informer.Informer() // Register with factory before starting.
+
+	informerFactory.Start(ctx.Done())
+
+	// wait for the local cache to be populated.
+	if err := waitForDynamicCacheSync(context.Background(), informerFactory); err != nil {
+		return nil, err
+	}
+
+	return gs, nil
+}
+
+// NewGenericCRDSources creates a genericCRDSource for each of the mappings.
+func NewGenericCRDSources(
+	ctx context.Context,
+	dynamicKubeClient dynamic.Interface,
+	namespace string,
+	annotationFilter string,
+	mappings []GenericCRDMapping,
+	labelSelector labels.Selector,
+	fieldSelector fields.Selector,
+) (Source, error) {
+	sources := make([]Source, 0, len(mappings))
+	for _, mapping := range mappings {
+		src, err := NewGenericCRDSource(ctx, dynamicKubeClient, namespace, annotationFilter, mapping, labelSelector, fieldSelector)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, src)
+	}
+	return NewMultiSource(sources, nil), nil
+}
+
+// Endpoints returns endpoint objects for each host-target combination of the objects of the
+// custom resource.
+func (gs *genericCRDSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	selector, err := getLabelSelector(gs.annotationFilter)
+	if err != nil {
+		return nil, err
+	}
+	objs, err := gs.informer.Lister().ByNamespace(gs.namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	var endpoints []*endpoint.Endpoint
+	for _, obj := range objs {
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			return nil, fmt.Errorf("unexpected %s object %T", gs.mapping.Resource.Resource, obj)
+		}
+		if !matchLabelSelector(selector, u.GetAnnotations()) {
+			continue
+		}
+		if controller, ok := u.GetAnnotations()[controllerAnnotationKey]; ok && controller != controllerAnnotationValue {
+			log.Debugf("Skipping %s %s/%s because controller value does not match, found: %s, required: %s",
+				gs.mapping.Resource.Resource, u.GetNamespace(), u.GetName(), controller, controllerAnnotationValue)
+			continue
+		}
+
+		eps, err := gs.endpointsFromObject(u)
+		if err != nil {
+			log.Warnf("Skipping %s %s/%s: %v", gs.mapping.Resource.Resource, u.GetNamespace(), u.GetName(), err)
+			continue
+		}
+		endpoints = append(endpoints, eps...)
+	}
+
+	for _, ep := range endpoints {
+		sort.Sort(ep.Targets)
+	}
+	return endpoints, nil
+}
+
+func (gs *genericCRDSource) AddEventHandler(ctx context.Context, handler func()) {
+	log.Debugf("Adding event handler for %s", gs.mapping.Resource.Resource)
+
+	gs.informer.Informer().AddEventHandler(eventHandlerFunc(handler))
+}
+
+// endpointsFromObject reads the endpoints of an object with the JSONPaths of the mapping. The
+// target and TTL annotations take precedence over the targets and TTL of the object.
+func (gs *genericCRDSource) endpointsFromObject(u *unstructured.Unstructured) ([]*endpoint.Endpoint, error) {
+	resource := fmt.Sprintf("%s/%s/%s", gs.mapping.Resource.Resource, u.GetNamespace(), u.GetName())
+	annotations := u.GetAnnotations()
+
+	hostnames, err := findStrings(gs.hostnamesPath, u.Object)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hostnames: %w", err)
+	}
+
+	targets := getTargetsFromTargetAnnotation(annotations)
+	if len(targets) == 0 {
+		values, err := findStrings(gs.targetsPath, u.Object)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read targets: %w", err)
+		}
+		targets = endpoint.NewTargets(values...)
+	}
+
+	ttl := getTTLFromAnnotations(annotations, resource)
+	if _, ok := annotations[ttlAnnotationKey]; !ok && gs.ttlPath != nil {
+		values, err := findValues(gs.ttlPath, u.Object)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read TTL: %w", err)
+		}
+		if len(values) > 0 {
+			ttlValue, err := parseTTL(fmt.Sprint(values[0]))
+			if err != nil {
+				return nil, fmt.Errorf("invalid TTL %v: %w", values[0], err)
+			}
+			ttl = endpoint.TTL(ttlValue)
+		}
+	}
+
+	var providerSpecific endpoint.ProviderSpecific
+	if gs.providerSpecificPath != nil {
+		values, err := findValues(gs.providerSpecificPath, u.Object)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read provider specific properties: %w", err)
+		}
+		providerSpecific, err = providerSpecificFromValues(values)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var endpoints []*endpoint.Endpoint
+	for _, hostname := range hostnames {
+		endpoints = append(endpoints, endpointsForHostname(hostname, targets, ttl, providerSpecific, "", resource)...)
+	}
+	return endpoints, nil
+}
+
+// findValues returns the values selected by the JSONPath in the object.
+func findValues(jp *jsonpath.JSONPath, obj map[string]interface{}) ([]interface{}, error) {
+	results, err := jp.FindResults(obj)
+	if err != nil {
+		return nil, err
+	}
+	var values []interface{}
+	for _, result := range results {
+		for _, value := range result {
+			values = append(values, value.Interface())
+		}
+	}
+	return values, nil
+}
+
+// findStrings returns the strings selected by the JSONPath in the object, which may select a list
+// of strings too.
+func findStrings(jp *jsonpath.JSONPath, obj map[string]interface{}) ([]string, error) {
+	values, err := findValues(jp, obj)
+	if err != nil {
+		return nil, err
+	}
+	var strs []string
+	for _, value := range values {
+		switch v := value.(type) {
+		case string:
+			strs = append(strs, v)
+		case []interface{}:
+			for _, item := range v {
+				s, ok := item.(string)
+				if !ok {
+					return nil, fmt.Errorf("%v is not a string", item)
+				}
+				strs = append(strs, s)
+			}
+		default:
+			return nil, fmt.Errorf("%v is not a string", value)
+		}
+	}
+	return strs, nil
+}
+
+// providerSpecificFromValues converts maps and lists of name and value pairs, as in the
+// providerSpecific of a DNSEndpoint, to provider specific properties.
+func providerSpecificFromValues(values []interface{}) (endpoint.ProviderSpecific, error) {
+	var providerSpecific endpoint.ProviderSpecific
+	add := func(item interface{}) error {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("provider specific property %v is not an object", item)
+		}
+		if name, ok := m["name"].(string); ok && len(m) == 2 {
+			if value, ok := m["value"]; ok {
+				providerSpecific = append(providerSpecific, endpoint.ProviderSpecificProperty{Name: name, Value: fmt.Sprint(value)})
+				return nil
+			}
+		}
+		names := make([]string, 0, len(m))
+		for name := range m {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			providerSpecific = append(providerSpecific, endpoint.ProviderSpecificProperty{Name: name, Value: fmt.Sprint(m[name])})
+		}
+		return nil
+	}
+	for _, value := range values {
+		items, ok := value.([]interface{})
+		if !ok {
+			items = []interface{}{value}
+		}
+		for _, item := range items {
+			if err := add(item); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return providerSpecific, nil
+}
@@ -0,0 +1,260 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakeDynamic "k8s.io/client-go/dynamic/fake"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+var testGenericCRDGVR = schema.GroupVersionResource{
+	Group:    "platform.example.com",
+	Version:  "v1",
+	Resource: "applications",
+}
+
+func TestParseGenericCRDMapping(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		title    string
+		input    string
+		expected GenericCRDMapping
+		wantErr  bool
+	}{
+		{
+			title: "Required",
+			input: "applications.platform.example.com/v1;hostnames={.spec.hostnames[*]};targets={.status.addresses[*]}",
+			expected: GenericCRDMapping{
+				Resource:      testGenericCRDGVR,
+				HostnamesPath: "{.spec.hostnames[*]}",
+				TargetsPath:   "{.status.addresses[*]}",
+			},
+		},
+		{
+			title: "Optional",
+			input: "applications.platform.example.com/v1;hostnames={.spec.hostname};targets={.status.address};ttl={.spec.ttl};provider-specific={.spec.providerSpecific}",
+			expected: GenericCRDMapping{
+				Resource:             testGenericCRDGVR,
+				HostnamesPath:        "{.spec.hostname}",
+				TargetsPath:          "{.status.address}",
+				TTLPath:              "{.spec.ttl}",
+				ProviderSpecificPath: "{.spec.providerSpecific}",
+			},
+		},
+		{
+			title:   "MissingTargets",
+			input:   "applications.platform.example.com/v1;hostnames={.spec.hostname}",
+			wantErr: true,
+		},
+		{
+			title:   "MissingGroup",
+			input:   "applications/v1;hostnames={.spec.hostname};targets={.status.address}",
+			wantErr: true,
+		},
+		{
+			title:   "UnknownSetting",
+			input:   "applications.platform.example.com/v1;hostnames={.spec.hostname};targets={.status.address};color=blue",
+			wantErr: true,
+		},
+		{
+			title:   "InvalidJSONPath",
+			input:   "applications.platform.example.com/v1;hostnames={.spec.hostname[;targets={.status.address}",
+			wantErr: true,
+		},
+	} {
+		tt := tt
+		t.Run(tt.title, func(t *testing.T) {
+			t.Parallel()
+			m, err := ParseGenericCRDMapping(tt.input)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, m)
+		})
+	}
+}
+
+func newTestApplication(name string, annotations map[string]interface{}, spec, status map[string]interface{}) *unstructured.Unstructured {
+	metadata := map[string]interface{}{
+		"name":      name,
+		"namespace": "default",
+	}
+	if annotations != nil {
+		metadata["annotations"] = annotations
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "platform.example.com/v1",
+		"kind":       "Application",
+		"metadata":   metadata,
+		"spec":       spec,
+		"status":     status,
+	}}
+}
+
+func TestGenericCRDSourceEndpoints(t *testing.T) {
+	t.Parallel()
+
+	mapping := GenericCRDMapping{
+		Resource:             testGenericCRDGVR,
+		HostnamesPath:        "{.spec.hostnames}",
+		TargetsPath:          "{.status.addresses[*].ip}",
+		TTLPath:              "{.spec.ttl}",
+		ProviderSpecificPath: "{.spec.providerSpecific}",
+	}
+	for _, tt := range []struct {
+		title            string
+		annotationFilter string
+		objects          []runtime.Object
+		expected         []*endpoint.Endpoint
+	}{
+		{
+			title: "Mapped",
+			objects: []runtime.Object{
+				newTestApplication("app", nil,
+					map[string]interface{}{
+						"hostnames": []interface{}{"app.example.org", "www.example.org"},
+						"ttl":       "5m",
+						"providerSpecific": []interface{}{
+							map[string]interface{}{"name": "aws/evaluate-target-health", "value": "true"},
+						},
+					},
+					map[string]interface{}{
+						"addresses": []interface{}{
+							map[string]interface{}{"ip": "1.2.3.4"},
+							map[string]interface{}{"ip": "2001:db8::1"},
+						},
+					}),
+			},
+			expected: []*endpoint.Endpoint{
+				newGenericCRDEndpoint("app.example.org", endpoint.RecordTypeA, 300, "app", "1.2.3.4"),
+				newGenericCRDEndpoint("app.example.org", endpoint.RecordTypeAAAA, 300, "app", "2001:db8::1"),
+				newGenericCRDEndpoint("www.example.org", endpoint.RecordTypeA, 300, "app", "1.2.3.4"),
+				newGenericCRDEndpoint("www.example.org", endpoint.RecordTypeAAAA, 300, "app", "2001:db8::1"),
+			},
+		},
+		{
+			title: "Annotations",
+			objects: []runtime.Object{
+				newTestApplication("app",
+					map[string]interface{}{
+						targetAnnotationKey: "lb.example.org",
+						ttlAnnotationKey:    "60",
+					},
+					map[string]interface{}{
+						"hostnames": []interface{}{"app.example.org"},
+						"ttl":       int64(300),
+					},
+					map[string]interface{}{
+						"addresses": []interface{}{map[string]interface{}{"ip": "1.2.3.4"}},
+					}),
+			},
+			expected: []*endpoint.Endpoint{
+				{
+					DNSName:    "app.example.org",
+					RecordType: endpoint.RecordTypeCNAME,
+					RecordTTL:  60,
+					Targets:    endpoint.Targets{"lb.example.org"},
+				},
+			},
+		},
+		{
+			title:            "AnnotationFilter",
+			annotationFilter: "example.org/dns=true",
+			objects: []runtime.Object{
+				newTestApplication("app",
+					map[string]interface{}{"example.org/dns": "true"},
+					map[string]interface{}{"hostnames": []interface{}{"app.example.org"}},
+					map[string]interface{}{"addresses": []interface{}{map[string]interface{}{"ip": "1.2.3.4"}}}),
+				newTestApplication("other", nil,
+					map[string]interface{}{"hostnames": []interface{}{"other.example.org"}},
+					map[string]interface{}{"addresses": []interface{}{map[string]interface{}{"ip": "1.2.3.5"}}}),
+			},
+			expected: []*endpoint.Endpoint{
+				newTestEndpoint("app.example.org", endpoint.RecordTypeA, "1.2.3.4"),
+			},
+		},
+		{
+			title: "InvalidTTL",
+			objects: []runtime.Object{
+				newTestApplication("app", nil,
+					map[string]interface{}{
+						"hostnames": []interface{}{"app.example.org"},
+						"ttl":       "soon",
+					},
+					map[string]interface{}{"addresses": []interface{}{map[string]interface{}{"ip": "1.2.3.4"}}}),
+			},
+		},
+		{
+			title: "MissingFields",
+			objects: []runtime.Object{
+				newTestApplication("app", nil, map[string]interface{}{}, map[string]interface{}{}),
+			},
+		},
+	} {
+		tt := tt
+		t.Run(tt.title, func(t *testing.T) {
+			t.Parallel()
+			client := fakeDynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+				map[schema.GroupVersionResource]string{testGenericCRDGVR: "ApplicationList"}, tt.objects...)
+			src, err := NewGenericCRDSource(context.TODO(), client, "", tt.annotationFilter, mapping, labels.Everything(), fields.Everything())
+			require.NoError(t, err)
+			endpoints, err := src.Endpoints(context.Background())
+			require.NoError(t, err)
+			validateEndpoints(t, endpoints, tt.expected)
+		})
+	}
+}
+
+func newGenericCRDEndpoint(dnsName, recordType string, ttl int64, name string, targets ...string) *endpoint.Endpoint {
+	ep := newTestEndpointWithTTL(dnsName, recordType, ttl, targets...)
+	ep.ProviderSpecific = endpoint.ProviderSpecific{{Name: "aws/evaluate-target-health", Value: "true"}}
+	ep.Labels = endpoint.Labels{endpoint.ResourceLabelKey: "applications/default/" + name}
+	return ep
+}
+
+func TestProviderSpecificFromValues(t *testing.T) {
+	t.Parallel()
+
+	ps, err := providerSpecificFromValues([]interface{}{
+		map[string]interface{}{"b": "2", "a": int64(1)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, endpoint.ProviderSpecific{{Name: "a", Value: "1"}, {Name: "b", Value: "2"}}, ps)
+
+	ps, err = providerSpecificFromValues([]interface{}{
+		[]interface{}{map[string]interface{}{"name": "a", "value": "1"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, endpoint.ProviderSpecific{{Name: "a", Value: "1"}}, ps)
+
+	_, err = providerSpecificFromValues([]interface{}{"a"})
+	require.Error(t, err)
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -320,6 +321,26 @@ func testCRDSourceEndpoints(t *testing.T) {
 			expectEndpoints: true,
 			expectError:     false,
 		},
+		{
+			title:                "valid crd gvk with non matching controller annotation",
+			registeredAPIVersion: "test.k8s.io/v1alpha1",
+			apiVersion:           "test.k8s.io/v1alpha1",
+			registeredKind:       "DNSEndpoint",
+			kind:                 "DNSEndpoint",
+			namespace:            "foo",
+			registeredNamespace:  "foo",
+			annotations:          map[string]string{controllerAnnotationKey: "something-else"},
+			endpoints: []*endpoint.Endpoint{
+				{
+					DNSName:    "abc.example.org",
+					Targets:    endpoint.Targets{"1.2.3.4"},
+					RecordType: endpoint.RecordTypeA,
+					RecordTTL:  180,
+				},
+			},
+			expectEndpoints: false,
+			expectError:     false,
+		},
 		{
 			title:                "valid crd gvk with label and non matching label filter",
 			registeredAPIVersion: "test.k8s.io/v1alpha1",
@@ -404,6 +425,27 @@ func testCRDSourceEndpoints(t *testing.T) {
 			expectEndpoints: true,
 			expectError:     false,
 		},
+		{
+			title:                "Create NAPTR record",
+			registeredAPIVersion: "test.k8s.io/v1alpha1",
+			apiVersion:           "test.k8s.io/v1alpha1",
+			registeredKind:       "DNSEndpoint",
+			kind:                 "DNSEndpoint",
+			namespace:            "foo",
+			registeredNamespace:  "foo",
+			labels:               map[string]string{"test": "that"},
+			labelFilter:          "test=that",
+			endpoints: []*endpoint.Endpoint{
+				{
+					DNSName:    "example.org",
+					Targets:    endpoint.Targets{`100 10 "S" "SIP+D2U" "" _sip._udp.example.org`, `100 20 "S" "SIP+D2T" "" _sip._tcp.example.org`},
+					RecordType: endpoint.RecordTypeNAPTR,
+					RecordTTL:  180,
+				},
+			},
+			expectEndpoints: true,
+			expectError:     false,
+		},
 	} {
 		ti := ti
 		t.Run(ti.title, func(t *testing.T) {
@@ -424,7 +466,7 @@ func testCRDSourceEndpoints(t *testing.T) {
 			// So don't start the informer during testing.
 			startInformer := false
 
-			cs, err := NewCRDSource(restClient, ti.namespace, ti.kind, ti.annotationFilter, labelSelector, scheme, startInformer)
+			cs, err := NewCRDSource(restClient, ti.namespace, ti.kind, ti.annotationFilter, labelSelector, fields.Everything(), scheme, startInformer, nil)
 			require.NoError(t, err)
 
 			receivedEndpoints, err := cs.Endpoints(context.Background())
@@ -463,3 +505,41 @@ func validateCRDResource(t *testing.T, src Source, expectError bool) {
 		}
 	}
 }
+
+func TestEndpointsFromDNSEndpointNAPTR(t *testing.T) {
+	dnsEndpoint := &endpoint.DNSEndpoint{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "sip"},
+		Spec: endpoint.DNSEndpointSpec{
+			Endpoints: []*endpoint.Endpoint{
+				endpoint.NewEndpoint("example.org", endpoint.RecordTypeNAPTR, `100 10 S SIP+D2U "" _sip._udp.example.org.`),
+				endpoint.NewEndpoint("e164.example.org", endpoint.RecordTypeNAPTR, `100 10 "U" "E2U+sip" "!^.*$!sip:info@example.org!" .`),
+				endpoint.NewEndpoint("invalid.example.org", endpoint.RecordTypeNAPTR, `100 10 "S" "SIP+D2U" _sip._udp.example.org`),
+			},
+		},
+	}
+
+	endpoints := endpointsFromDNSEndpoint(dnsEndpoint, nil)
+	require.Len(t, endpoints, 2)
+	require.Equal(t, endpoint.Targets{`100 10 "S" "SIP+D2U" "" _sip._udp.example.org`}, endpoints[0].Targets)
+	require.Equal(t, endpoint.Targets{`100 10 "U" "E2U+sip" "!^.*$!sip:info@example.org!" .`}, endpoints[1].Targets)
+}
+
+func TestEndpointsFromDNSEndpointPassthrough(t *testing.T) {
+	dnsEndpoint := &endpoint.DNSEndpoint{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "office"},
+		Spec: endpoint.DNSEndpointSpec{
+			Endpoints: []*endpoint.Endpoint{
+				{DNSName: "office.example.org", RecordType: "LOC", Targets: endpoint.Targets{"52 22 23.000 N 4 53 32.000 E -2.00m 0.00m 10000m 10m"}},
+				{DNSName: "office.example.org", RecordType: "CERT", Targets: endpoint.Targets{"PGP 0 0 mQENBF...="}},
+			},
+		},
+	}
+
+	// the record types without bespoke support are only passed through when allowed
+	require.Empty(t, endpointsFromDNSEndpoint(dnsEndpoint.DeepCopy(), nil))
+
+	endpoints := endpointsFromDNSEndpoint(dnsEndpoint.DeepCopy(), []string{"LOC"})
+	require.Len(t, endpoints, 1)
+	require.Equal(t, "LOC", endpoints[0].RecordType)
+	require.Equal(t, "crd/foo/office", endpoints[0].Labels[endpoint.ResourceLabelKey])
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+var cutoverGVR = schema.GroupVersionResource{
+	Group:    "externaldns.k8s.io",
+	Version:  "v1alpha1",
+	Resource: "cutovers",
+}
+
+// cutoverSource is an implementation of Source that provides the endpoints of the current stage of
+// the Cutover objects.
+type cutoverSource struct {
+	dynamicKubeClient dynamic.Interface
+	informer          informers.GenericInformer
+	namespace         string
+	annotationFilter  string
+}
+
+// NewCutoverSource creates a new cutoverSource with the given config.
+func NewCutoverSource(
+	ctx context.Context,
+	dynamicKubeClient dynamic.Interface,
+	namespace string,
+	annotationFilter string,
+	labelSelector labels.Selector,
+	fieldSelector fields.Selector,
+) (Source, error) {
+	informerFactory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(dynamicKubeClient, 0, namespace, newListOptionsTweak(labelSelector, fieldSelector))
+	informer := informerFactory.ForResource(cutoverGVR)
+	informer.Informer() // Register with factory before starting.
+
+	informerFactory.Start(ctx.Done())
+
+	// wait for the local cache to be populated.
+	if err := waitForDynamicCacheSync(context.Background(), informerFactory); err != nil {
+		return nil, err
+	}
+
+	return &cutoverSource{
+		dynamicKubeClient: dynamicKubeClient,
+		informer:          informer,
+		namespace:         namespace,
+		annotationFilter:  annotationFilter,
+	}, nil
+}
+
+// Endpoints returns the endpoints of the current stage of every Cutover, and records the stage in
+// the status of the Cutover.
+func (cs *cutoverSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	selector, err := getLabelSelector(cs.annotationFilter)
+	if err != nil {
+		return nil, err
+	}
+	objs, err := cs.informer.Lister().ByNamespace(cs.namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var endpoints []*endpoint.Endpoint
+	for _, obj := range objs {
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			return nil, fmt.Errorf("unexpected cutover object %T", obj)
+		}
+		if !matchLabelSelector(selector, u.GetAnnotations()) {
+			continue
+		}
+		if controller, ok := u.GetAnnotations()[controllerAnnotationKey]; ok && controller != controllerAnnotationValue {
+			log.Debugf("Skipping cutover %s/%s because controller value does not match, found: %s, required: %s",
+				u.GetNamespace(), u.GetName(), controller, controllerAnnotationValue)
+			continue
+		}
+
+		cutover := &endpoint.Cutover{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, cutover); err != nil {
+			log.Warnf("Skipping cutover %s/%s: %v", u.GetNamespace(), u.GetName(), err)
+			continue
+		}
+		if err := cutover.Validate(); err != nil {
+			log.Warnf("Skipping invalid cutover %s/%s: %v", u.GetNamespace(), u.GetName(), err)
+			continue
+		}
+
+		eps, status := cutoverEndpoints(cutover, now)
+		endpoints = append(endpoints, eps...)
+
+		if cutover.Status == status {
+			continue
+		}
+		if cutover.Status.Stage != status.Stage {
+			log.Infof("Cutover %s/%s of %s at stage %d of %d, sending %d%% of the traffic to %v", cutover.Namespace, cutover.Name,
+				cutover.Spec.DNSName, status.Stage, len(cutover.Spec.Stages), status.Weight, cutover.Spec.ToTargets)
+		}
+		if err := cs.updateStatus(ctx, u, status); err != nil {
+			log.Warnf("Could not update the status of cutover %s/%s: %v", cutover.Namespace, cutover.Name, err)
+		}
+	}
+
+	for _, ep := range endpoints {
+		sort.Sort(ep.Targets)
+	}
+	return endpoints, nil
+}
+
+func (cs *cutoverSource) AddEventHandler(ctx context.Context, handler func()) {
+	log.Debug("Adding event handler for cutover")
+
+	cs.informer.Informer().AddEventHandler(eventHandlerFunc(handler))
+}
+
+// updateStatus replaces the status of the Cutover object.
+func (cs *cutoverSource) updateStatus(ctx context.Context, u *unstructured.Unstructured, status endpoint.CutoverStatus) error {
+	value, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&status)
+	if err != nil {
+		return err
+	}
+	u = u.DeepCopy()
+	if err := unstructured.SetNestedField(u.Object, value, "status"); err != nil {
+		return err
+	}
+	_, err = cs.dynamicKubeClient.Resource(cutoverGVR).Namespace(u.GetNamespace()).UpdateStatus(ctx, u, metav1.UpdateOptions{})
+	return err
+}
+
+// cutoverEndpoints returns the endpoints of the stage of the Cutover at now and its status. With a
+// weight property, the previous and the new targets are weighted records of their own, otherwise
+// the record points at the new targets from the first stage sending at least half of the traffic
+// to them. The endpoints expire at the time of the next stage, so that the controller reconciles
+// right when it starts.
+func cutoverEndpoints(cutover *endpoint.Cutover, now time.Time) ([]*endpoint.Endpoint, endpoint.CutoverStatus) {
+	status := endpoint.CutoverStatus{ObservedGeneration: cutover.Generation}
+	for _, stage := range cutover.Spec.Stages {
+		if now.Before(stage.At.Time) {
+			break
+		}
+		status.Stage++
+		status.Weight = stage.Weight
+	}
+
+	recordType := cutover.Spec.RecordType
+	if recordType == "" {
+		recordType = endpoint.RecordTypeA
+	}
+	var endpoints []*endpoint.Endpoint
+	if cutover.Spec.WeightProperty != "" {
+		from := endpoint.NewEndpointWithTTL(cutover.Spec.DNSName, recordType, cutover.Spec.RecordTTL, cutover.Spec.FromTargets...).
+			WithSetIdentifier(cutover.Name+"-from").
+			WithProviderSpecific(cutover.Spec.WeightProperty, strconv.FormatInt(100-status.Weight, 10))
+		to := endpoint.NewEndpointWithTTL(cutover.Spec.DNSName, recordType, cutover.Spec.RecordTTL, cutover.Spec.ToTargets...).
+			WithSetIdentifier(cutover.Name+"-to").
+			WithProviderSpecific(cutover.Spec.WeightProperty, strconv.FormatInt(status.Weight, 10))
+		endpoints = append(endpoints, from, to)
+	} else {
+		targets := cutover.Spec.FromTargets
+		if status.Weight >= 50 {
+			targets = cutover.Spec.ToTargets
+		}
+		endpoints = append(endpoints, endpoint.NewEndpointWithTTL(cutover.Spec.DNSName, recordType, cutover.Spec.RecordTTL, targets...))
+	}
+
+	resource := fmt.Sprintf("cutover/%s/%s", cutover.Namespace, cutover.Name)
+	for _, ep := range endpoints {
+		ep.Labels[endpoint.ResourceLabelKey] = resource
+		if status.Stage < len(cutover.Spec.Stages) {
+			ep.WithProviderSpecific(ActiveUntilKey, cutover.Spec.Stages[status.Stage].At.UTC().Format(time.RFC3339))
+		}
+	}
+	return endpoints, status
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func TestCutoverEndpoints(t *testing.T) {
+	at := func(hour int) metav1.Time {
+		return metav1.NewTime(time.Date(2024, time.March, 1, hour, 0, 0, 0, time.UTC))
+	}
+	cutover := func(weightProperty string) *endpoint.Cutover {
+		return &endpoint.Cutover{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app", Generation: 2},
+			Spec: endpoint.CutoverSpec{
+				DNSName:        "app.example.org",
+				RecordTTL:      60,
+				FromTargets:    endpoint.Targets{"1.1.1.1"},
+				ToTargets:      endpoint.Targets{"2.2.2.2"},
+				Stages:         []endpoint.CutoverStage{{Weight: 10, At: at(10)}, {Weight: 50, At: at(11)}, {Weight: 100, At: at(12)}},
+				WeightProperty: weightProperty,
+			},
+		}
+	}
+	expected := func(setIdentifier string, weight string, until string, targets ...string) *endpoint.Endpoint {
+		ep := endpoint.NewEndpointWithTTL("app.example.org", endpoint.RecordTypeA, 60, targets...).WithSetIdentifier(setIdentifier)
+		ep.Labels[endpoint.ResourceLabelKey] = "cutover/default/app"
+		if weight != "" {
+			ep.WithProviderSpecific("aws/weight", weight)
+		}
+		if until != "" {
+			ep.WithProviderSpecific(ActiveUntilKey, until)
+		}
+		return ep
+	}
+
+	for _, tt := range []struct {
+		title          string
+		weightProperty string
+		hour           int
+		expected       []*endpoint.Endpoint
+		status         endpoint.CutoverStatus
+	}{
+		{
+			title:          "weighted before the first stage",
+			weightProperty: "aws/weight",
+			hour:           9,
+			expected: []*endpoint.Endpoint{
+				expected("app-from", "100", "2024-03-01T10:00:00Z", "1.1.1.1"),
+				expected("app-to", "0", "2024-03-01T10:00:00Z", "2.2.2.2"),
+			},
+			status: endpoint.CutoverStatus{ObservedGeneration: 2},
+		},
+		{
+			title:          "weighted stage",
+			weightProperty: "aws/weight",
+			hour:           10,
+			expected: []*endpoint.Endpoint{
+				expected("app-from", "90", "2024-03-01T11:00:00Z", "1.1.1.1"),
+				expected("app-to", "10", "2024-03-01T11:00:00Z", "2.2.2.2"),
+			},
+			status: endpoint.CutoverStatus{ObservedGeneration: 2, Stage: 1, Weight: 10},
+		},
+		{
+			title:          "weighted last stage",
+			weightProperty: "aws/weight",
+			hour:           13,
+			expected: []*endpoint.Endpoint{
+				expected("app-from", "0", "", "1.1.1.1"),
+				expected("app-to", "100", "", "2.2.2.2"),
+			},
+			status: endpoint.CutoverStatus{ObservedGeneration: 2, Stage: 3, Weight: 100},
+		},
+		{
+			title:    "switched below half of the traffic",
+			hour:     10,
+			expected: []*endpoint.Endpoint{expected("", "", "2024-03-01T11:00:00Z", "1.1.1.1")},
+			status:   endpoint.CutoverStatus{ObservedGeneration: 2, Stage: 1, Weight: 10},
+		},
+		{
+			title:    "switched from half of the traffic",
+			hour:     11,
+			expected: []*endpoint.Endpoint{expected("", "", "2024-03-01T12:00:00Z", "2.2.2.2")},
+			status:   endpoint.CutoverStatus{ObservedGeneration: 2, Stage: 2, Weight: 50},
+		},
+	} {
+		t.Run(tt.title, func(t *testing.T) {
+			endpoints, status := cutoverEndpoints(cutover(tt.weightProperty), time.Date(2024, time.March, 1, tt.hour, 0, 0, 0, time.UTC))
+			assert.Equal(t, tt.expected, endpoints)
+			assert.Equal(t, tt.status, status)
+		})
+	}
+}
@@ -25,6 +25,7 @@ import (
 	log "github.com/sirupsen/logrus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -62,8 +63,10 @@ func NewF5VirtualServerSource(
 	kubeClient kubernetes.Interface,
 	namespace string,
 	annotationFilter string,
+	labelSelector labels.Selector,
+	fieldSelector fields.Selector,
 ) (Source, error) {
-	informerFactory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(dynamicKubeClient, 0, namespace, nil)
+	informerFactory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(dynamicKubeClient, 0, namespace, newListOptionsTweak(labelSelector, fieldSelector))
 	virtualServerInformer := informerFactory.ForResource(f5VirtualServerGVR)
 
 	virtualServerInformer.Informer().AddEventHandler(
@@ -147,6 +150,13 @@ func (vs *f5VirtualServerSource) endpointsFromVirtualServers(virtualServers []*f
 	var endpoints []*endpoint.Endpoint
 
 	for _, virtualServer := range virtualServers {
+		// Check controller annotation to see if we are responsible.
+		if controller, ok := virtualServer.Annotations[controllerAnnotationKey]; ok && controller != controllerAnnotationValue {
+			log.Debugf("Skipping VirtualServer %s/%s because controller value does not match, found: %s, required: %s",
+				virtualServer.Namespace, virtualServer.Name, controller, controllerAnnotationValue)
+			continue
+		}
+
 		resource := fmt.Sprintf("f5-virtualserver/%s/%s", virtualServer.Namespace, virtualServer.Name)
 
 		ttl := getTTLFromAnnotations(virtualServer.Annotations, resource)
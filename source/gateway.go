@@ -19,6 +19,8 @@ package source
 import (
 	"context"
 	"fmt"
+	"math"
+	"net"
 	"net/netip"
 	"sort"
 	"strings"
@@ -27,6 +29,7 @@ import (
 	log "github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -46,6 +49,9 @@ const (
 	gatewayKind  = "Gateway"
 )
 
+// gatewayLookupIP resolves the hostname addresses of Gateways, it is replaced in tests.
+var gatewayLookupIP = net.LookupIP
+
 type gatewayRoute interface {
 	// Object returns the underlying route object to be used by templates.
 	Object() kubeObject
@@ -66,16 +72,13 @@ type gatewayRouteInformer interface {
 	Informer() cache.SharedIndexInformer
 }
 
-func newGatewayInformerFactory(client gateway.Interface, namespace string, labelSelector labels.Selector) informers.SharedInformerFactory {
+func newGatewayInformerFactory(client gateway.Interface, namespace string, labelSelector labels.Selector, fieldSelector fields.Selector) informers.SharedInformerFactory {
 	var opts []informers.SharedInformerOption
 	if namespace != "" {
 		opts = append(opts, informers.WithNamespace(namespace))
 	}
-	if labelSelector != nil && !labelSelector.Empty() {
-		lbls := labelSelector.String()
-		opts = append(opts, informers.WithTweakListOptions(func(o *metav1.ListOptions) {
-			o.LabelSelector = lbls
-		}))
+	if (labelSelector != nil && !labelSelector.Empty()) || (fieldSelector != nil && !fieldSelector.Empty()) {
+		opts = append(opts, informers.WithTweakListOptions(newListOptionsTweak(labelSelector, fieldSelector)))
 	}
 	return informers.NewSharedInformerFactoryWithOptions(client, 0, opts...)
 }
@@ -96,6 +99,7 @@ type gatewayRouteSource struct {
 	fqdnTemplate             *template.Template
 	combineFQDNAnnotation    bool
 	ignoreHostnameAnnotation bool
+	resolveHostnameAddresses bool
 }
 
 func newGatewayRouteSource(clients ClientGenerator, config *Config, kind string, newInformerFn newGatewayRouteInformerFunc) (Source, error) {
@@ -123,13 +127,14 @@ func newGatewayRouteSource(clients ClientGenerator, config *Config, kind string,
 		return nil, err
 	}
 
-	informerFactory := newGatewayInformerFactory(client, config.GatewayNamespace, gwLabels)
+	informerFactory := newGatewayInformerFactory(client, config.GatewayNamespace, gwLabels, nil)
 	gwInformer := informerFactory.Gateway().V1().Gateways() // TODO: Gateway informer should be shared across gateway sources.
 	gwInformer.Informer()                                   // Register with factory before starting.
 
 	rtInformerFactory := informerFactory
-	if config.Namespace != config.GatewayNamespace || !selectorsEqual(rtLabels, gwLabels) {
-		rtInformerFactory = newGatewayInformerFactory(client, config.Namespace, rtLabels)
+	rtFields := config.FieldFilter
+	if config.Namespace != config.GatewayNamespace || !selectorsEqual(rtLabels, gwLabels) || (rtFields != nil && !rtFields.Empty()) {
+		rtInformerFactory = newGatewayInformerFactory(client, config.Namespace, rtLabels, rtFields)
 	}
 	rtInformer := newInformerFn(rtInformerFactory)
 	rtInformer.Informer() // Register with factory before starting.
@@ -175,6 +180,7 @@ func newGatewayRouteSource(clients ClientGenerator, config *Config, kind string,
 		fqdnTemplate:             tmpl,
 		combineFQDNAnnotation:    config.CombineFQDNAndAnnotation,
 		ignoreHostnameAnnotation: config.IgnoreHostnameAnnotation,
+		resolveHostnameAddresses: config.ResolveGatewayHostname,
 	}
 	return src, nil
 }
@@ -259,6 +265,12 @@ func newGatewayRouteResolver(src *gatewayRouteSource, gateways []*v1.Gateway, na
 	// Create Gateway Listener lookup table.
 	gws := make(map[types.NamespacedName]gatewayListeners, len(gateways))
 	for _, gw := range gateways {
+		// Check controller annotation to see if we are responsible.
+		if v, ok := gw.Annotations[controllerAnnotationKey]; ok && v != controllerAnnotationValue {
+			log.Debugf("Skipping Gateway %s/%s because controller value does not match, found: %s, required: %s",
+				gw.Namespace, gw.Name, v, controllerAnnotationValue)
+			continue
+		}
 		lss := make(map[v1.SectionName][]v1.Listener, len(gw.Spec.Listeners)+1)
 		for i, lis := range gw.Spec.Listeners {
 			lss[lis.Name] = gw.Spec.Listeners[i : i+1]
@@ -329,6 +341,11 @@ func (c *gatewayRouteResolver) resolve(rt gatewayRoute) (map[string]endpoint.Tar
 			if !c.routeIsAllowed(gw.gateway, lis, rt) {
 				continue
 			}
+			// Confirm that the Listener is ready, records pointing at it would otherwise fail.
+			if !gwListenerIsReady(gw.gateway, lis) {
+				log.Debugf("Gateway %s/%s section %q is not ready for %s %s/%s", namespace, ref.Name, lis.Name, c.src.rtKind, meta.Namespace, meta.Name)
+				continue
+			}
 			// Find all overlapping hostnames between the Route and Listener.
 			// For {TCP,UDP}Routes, all annotation-generated hostnames should match since the Listener doesn't specify a hostname.
 			// For {HTTP,TLS}Routes, hostnames (including any annotation-generated) will be required to match any Listeners specified hostname.
@@ -346,12 +363,15 @@ func (c *gatewayRouteResolver) resolve(rt gatewayRoute) (map[string]endpoint.Tar
 				if !ok {
 					continue
 				}
+				// Confirm that the host isn't isolated to a more specific Listener, which the Route isn't attached to.
+				if other := gwIsolatingListener(gw.listeners[""], lis, host); other != nil {
+					log.Debugf("Gateway %s/%s section %q serves %s instead of section %q for %s %s/%s", namespace, ref.Name, other.Name, host, lis.Name, c.src.rtKind, meta.Namespace, meta.Name)
+					continue
+				}
 				override := getTargetsFromTargetAnnotation(gw.gateway.Annotations)
 				hostTargets[host] = append(hostTargets[host], override...)
 				if len(override) == 0 {
-					for _, addr := range gw.gateway.Status.Addresses {
-						hostTargets[host] = append(hostTargets[host], addr.Value)
-					}
+					hostTargets[host] = append(hostTargets[host], c.addressTargets(gw.gateway)...)
 				}
 				match = true
 			}
@@ -368,6 +388,45 @@ func (c *gatewayRouteResolver) resolve(rt gatewayRoute) (map[string]endpoint.Tar
 	return hostTargets, nil
 }
 
+// addressTargets returns the targets of the status addresses of the Gateway. Hostname addresses,
+// e.g. the DNS names of cloud load balancers, are CNAME targets, or are resolved to A and AAAA
+// targets with --resolve-gateway-hostname-addresses. A CNAME can't coexist with other records,
+// so the hostname addresses of a Gateway with IP addresses too are only used when resolved.
+func (c *gatewayRouteResolver) addressTargets(gw *v1.Gateway) endpoint.Targets {
+	var ips, hostnames endpoint.Targets
+	for _, addr := range gw.Status.Addresses {
+		addrType := v1.IPAddressType
+		if addr.Type != nil {
+			addrType = *addr.Type
+		}
+		switch {
+		case addrType == v1.HostnameAddressType && c.src.resolveHostnameAddresses:
+			resolved, err := gatewayLookupIP(addr.Value)
+			if err != nil {
+				log.Errorf("Unable to resolve address %q of Gateway %s/%s: %v", addr.Value, gw.Namespace, gw.Name, err)
+				continue
+			}
+			for _, ip := range resolved {
+				ips = append(ips, ip.String())
+			}
+		case addrType == v1.HostnameAddressType:
+			hostnames = append(hostnames, strings.TrimSuffix(addr.Value, "."))
+		case addrType == v1.IPAddressType:
+			ips = append(ips, addr.Value)
+		default:
+			log.Debugf("Skipping address %q of Gateway %s/%s with implementation specific type %q", addr.Value, gw.Namespace, gw.Name, addrType)
+		}
+	}
+
+	if len(ips) == 0 {
+		return hostnames
+	}
+	if len(hostnames) > 0 {
+		log.Debugf("Skipping hostname addresses %v of Gateway %s/%s with IP addresses", hostnames, gw.Namespace, gw.Name)
+	}
+	return ips
+}
+
 func (c *gatewayRouteResolver) hosts(rt gatewayRoute) ([]string, error) {
 	var hostnames []string
 	for _, name := range rt.Hostnames() {
@@ -447,6 +506,56 @@ func (c *gatewayRouteResolver) routeIsAllowed(gw *v1.Gateway, lis *v1.Listener,
 	return false
 }
 
+// gwListenerIsReady returns whether the Listener of the Gateway is accepted and programmed, or
+// whether its status isn't reported.
+func gwListenerIsReady(gw *v1.Gateway, lis *v1.Listener) bool {
+	for _, status := range gw.Status.Listeners {
+		if status.Name != lis.Name {
+			continue
+		}
+		for _, c := range status.Conditions {
+			switch v1.ListenerConditionType(c.Type) {
+			case v1.ListenerConditionAccepted, v1.ListenerConditionProgrammed:
+				if c.Status == metav1.ConditionFalse {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// gwIsolatingListener returns the Listener of the Gateway on the same port as the Listener with a
+// more specific hostname matching the host, if any. With listener isolation, requests for the host
+// are only routed by the Routes attached to that Listener.
+// https://gateway-api.sigs.k8s.io/geps/gep-1713/
+func gwIsolatingListener(listeners []v1.Listener, lis *v1.Listener, host string) *v1.Listener {
+	specificity := gwHostSpecificity(lis.Hostname)
+	for i := range listeners {
+		other := &listeners[i]
+		if other.Name == lis.Name || other.Port != lis.Port || gwHostSpecificity(other.Hostname) <= specificity {
+			continue
+		}
+		if match, ok := gwMatchingHost(string(*other.Hostname), host); ok && match == host {
+			return other
+		}
+	}
+	return nil
+}
+
+// gwHostSpecificity ranks the hostnames of Listeners: no hostname matches any host, a wildcard
+// matches less hosts the longer its suffix is, and an exact hostname matches a single host.
+func gwHostSpecificity(hostname *v1.Hostname) int {
+	switch {
+	case hostname == nil || *hostname == "":
+		return 0
+	case strings.HasPrefix(string(*hostname), "*."):
+		return len(*hostname)
+	default:
+		return math.MaxInt
+	}
+}
+
 func gwRouteIsAccepted(conds []metav1.Condition) bool {
 	for _, c := range conds {
 		if v1.RouteConditionType(c.Type) == v1.RouteConditionAccepted {
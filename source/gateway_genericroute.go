@@ -0,0 +1,239 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	kubeinformers "k8s.io/client-go/informers"
+	"k8s.io/client-go/util/jsonpath"
+	v1 "sigs.k8s.io/gateway-api/apis/v1"
+	informers "sigs.k8s.io/gateway-api/pkg/client/informers/externalversions"
+)
+
+const (
+	defaultGatewayRouteHostnamesPath = "{.spec.hostnames[*]}"
+	defaultGatewayRouteParentsPath   = "{.status.parents}"
+)
+
+// GatewayRouteType describes a Gateway API route type which has no source of its own, e.g. an
+// experimental or implementation specific one, so that its routes can be read as unstructured
+// objects: where their hostnames and the status of their parents are.
+type GatewayRouteType struct {
+	Resource schema.GroupVersionResource
+	Kind     string
+	Protocol v1.ProtocolType
+	// HostnamesPath is the JSONPath of the hostnames of a route.
+	HostnamesPath string
+	// ParentsPath is the JSONPath of the status of the parents of a route, the parentRef and
+	// conditions of each Gateway the route is attached to.
+	ParentsPath string
+}
+
+// ParseGatewayRouteType parses a route type of the form "resource.group/version", optionally
+// followed by ";"-separated settings: kind=<Kind>, protocol=<HTTP|HTTPS|TLS|TCP|UDP>,
+// hostnames=<JSONPath> and parents=<JSONPath>. For example:
+//
+//	inferenceroutes.inference.networking.x-k8s.io/v1alpha1;kind=InferenceRoute;protocol=HTTP
+func ParseGatewayRouteType(s string) (GatewayRouteType, error) {
+	rt := GatewayRouteType{
+		Protocol:      v1.HTTPProtocolType,
+		HostnamesPath: defaultGatewayRouteHostnamesPath,
+		ParentsPath:   defaultGatewayRouteParentsPath,
+	}
+	resource, err := parseResourceMapping(s, "gateway route type", func(key, value string) bool {
+		switch key {
+		case "kind":
+			rt.Kind = value
+		case "protocol":
+			rt.Protocol = v1.ProtocolType(value)
+		case "hostnames":
+			rt.HostnamesPath = value
+		case "parents":
+			rt.ParentsPath = value
+		default:
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return GatewayRouteType{}, err
+	}
+	rt.Resource = resource
+	if rt.Kind == "" {
+		rt.Kind = resource.Resource
+	}
+
+	if err := validateMappingJSONPaths(s, "gateway route type", rt.HostnamesPath, rt.ParentsPath); err != nil {
+		return GatewayRouteType{}, err
+	}
+	return rt, nil
+}
+
+// NewGatewayGenericRouteSource creates a new Gateway source for the routes of the given type.
+func NewGatewayGenericRouteSource(clients ClientGenerator, config *Config, routeType GatewayRouteType) (Source, error) {
+	client, err := clients.DynamicKubernetesClient()
+	if err != nil {
+		return nil, err
+	}
+	hostnamesPath := jsonpath.New(routeType.Kind).AllowMissingKeys(true)
+	if err := hostnamesPath.Parse(routeType.HostnamesPath); err != nil {
+		return nil, err
+	}
+	parentsPath := jsonpath.New(routeType.Kind).AllowMissingKeys(true)
+	if err := parentsPath.Parse(routeType.ParentsPath); err != nil {
+		return nil, err
+	}
+
+	// The routes are read by a dynamic informer, the typed informer factory of the Gateway source
+	// is only used for the Gateways.
+	informerFactory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(client, 0, config.Namespace, newListOptionsTweak(config.LabelFilter, config.FieldFilter))
+	informer := informerFactory.ForResource(routeType.Resource)
+	informer.Informer() // Register with factory before starting.
+	informerFactory.Start(wait.NeverStop)
+	if err := waitForDynamicCacheSync(context.TODO(), informerFactory); err != nil {
+		return nil, err
+	}
+
+	rtInformer := &gatewayGenericRouteInformer{
+		GenericInformer: informer,
+		routeType:       routeType,
+		hostnamesPath:   hostnamesPath,
+		parentsPath:     parentsPath,
+	}
+	return newGatewayRouteSource(clients, config, routeType.Kind, func(informers.SharedInformerFactory) gatewayRouteInformer {
+		return rtInformer
+	})
+}
+
+// NewGatewayGenericRouteSources creates a Gateway source for each of the route types.
+func NewGatewayGenericRouteSources(clients ClientGenerator, config *Config) (Source, error) {
+	sources := make([]Source, 0, len(config.GatewayRouteTypes))
+	for _, routeType := range config.GatewayRouteTypes {
+		src, err := NewGatewayGenericRouteSource(clients, config, routeType)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, src)
+	}
+	return NewMultiSource(sources, nil), nil
+}
+
+type gatewayGenericRoute struct {
+	route     *unstructured.Unstructured
+	meta      metav1.ObjectMeta
+	protocol  v1.ProtocolType
+	hostnames []v1.Hostname
+	status    v1.RouteStatus
+}
+
+func (rt *gatewayGenericRoute) Object() kubeObject           { return rt.route }
+func (rt *gatewayGenericRoute) Metadata() *metav1.ObjectMeta { return &rt.meta }
+func (rt *gatewayGenericRoute) Hostnames() []v1.Hostname     { return rt.hostnames }
+func (rt *gatewayGenericRoute) Protocol() v1.ProtocolType    { return rt.protocol }
+func (rt *gatewayGenericRoute) RouteStatus() v1.RouteStatus  { return rt.status }
+
+type gatewayGenericRouteInformer struct {
+	kubeinformers.GenericInformer
+	routeType     GatewayRouteType
+	hostnamesPath *jsonpath.JSONPath
+	parentsPath   *jsonpath.JSONPath
+}
+
+func (inf *gatewayGenericRouteInformer) List(namespace string, selector labels.Selector) ([]gatewayRoute, error) {
+	list, err := inf.Lister().ByNamespace(namespace).List(selector)
+	if err != nil {
+		return nil, err
+	}
+	routes := make([]gatewayRoute, 0, len(list))
+	for _, obj := range list {
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			return nil, fmt.Errorf("unexpected %s object %T", inf.routeType.Kind, obj)
+		}
+		rt, err := inf.route(u)
+		if err != nil {
+			log.Warnf("Skipping %s %s/%s: %v", inf.routeType.Kind, u.GetNamespace(), u.GetName(), err)
+			continue
+		}
+		routes = append(routes, rt)
+	}
+	return routes, nil
+}
+
+// route reads the hostnames and the status of the parents of the unstructured route.
+func (inf *gatewayGenericRouteInformer) route(u *unstructured.Unstructured) (*gatewayGenericRoute, error) {
+	rt := &gatewayGenericRoute{
+		route: u,
+		meta: metav1.ObjectMeta{
+			Name:        u.GetName(),
+			Namespace:   u.GetNamespace(),
+			Labels:      u.GetLabels(),
+			Annotations: u.GetAnnotations(),
+		},
+		protocol: inf.routeType.Protocol,
+	}
+
+	results, err := inf.hostnamesPath.FindResults(u.Object)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hostnames: %w", err)
+	}
+	for _, result := range results {
+		for _, value := range result {
+			hostname, ok := value.Interface().(string)
+			if !ok {
+				return nil, fmt.Errorf("hostname %v is not a string", value.Interface())
+			}
+			rt.hostnames = append(rt.hostnames, v1.Hostname(hostname))
+		}
+	}
+
+	results, err = inf.parentsPath.FindResults(u.Object)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read parents: %w", err)
+	}
+	for _, result := range results {
+		for _, value := range result {
+			// The path selects either the list of parents or each of them.
+			parents, ok := value.Interface().([]interface{})
+			if !ok {
+				parents = []interface{}{value.Interface()}
+			}
+			for _, parent := range parents {
+				obj, ok := parent.(map[string]interface{})
+				if !ok {
+					return nil, fmt.Errorf("parent %v is not an object", parent)
+				}
+				var status v1.RouteParentStatus
+				if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj, &status); err != nil {
+					return nil, fmt.Errorf("failed to read parent: %w", err)
+				}
+				rt.status.Parents = append(rt.status.Parents, status)
+			}
+		}
+	}
+	return rt, nil
+}
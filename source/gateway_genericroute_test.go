@@ -0,0 +1,202 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/jsonpath"
+	v1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func TestParseGatewayRouteType(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		title    string
+		input    string
+		expected GatewayRouteType
+		wantErr  bool
+	}{
+		{
+			title: "Defaults",
+			input: "inferenceroutes.inference.networking.x-k8s.io/v1alpha1",
+			expected: GatewayRouteType{
+				Resource:      schema.GroupVersionResource{Group: "inference.networking.x-k8s.io", Version: "v1alpha1", Resource: "inferenceroutes"},
+				Kind:          "inferenceroutes",
+				Protocol:      v1.HTTPProtocolType,
+				HostnamesPath: defaultGatewayRouteHostnamesPath,
+				ParentsPath:   defaultGatewayRouteParentsPath,
+			},
+		},
+		{
+			title: "Settings",
+			input: "fooroutes.example.com/v1;kind=FooRoute;protocol=TLS;hostnames={.spec.hosts[*]};parents={.status.gateways}",
+			expected: GatewayRouteType{
+				Resource:      schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "fooroutes"},
+				Kind:          "FooRoute",
+				Protocol:      v1.TLSProtocolType,
+				HostnamesPath: "{.spec.hosts[*]}",
+				ParentsPath:   "{.status.gateways}",
+			},
+		},
+		{
+			title:   "MissingVersion",
+			input:   "fooroutes.example.com",
+			wantErr: true,
+		},
+		{
+			title:   "MissingGroup",
+			input:   "fooroutes/v1",
+			wantErr: true,
+		},
+		{
+			title:   "UnknownSetting",
+			input:   "fooroutes.example.com/v1;color=blue",
+			wantErr: true,
+		},
+		{
+			title:   "InvalidSetting",
+			input:   "fooroutes.example.com/v1;kind",
+			wantErr: true,
+		},
+		{
+			title:   "InvalidJSONPath",
+			input:   "fooroutes.example.com/v1;hostnames={.spec.hosts[",
+			wantErr: true,
+		},
+	} {
+		tt := tt
+		t.Run(tt.title, func(t *testing.T) {
+			t.Parallel()
+			rt, err := ParseGatewayRouteType(tt.input)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, rt)
+		})
+	}
+}
+
+func TestGatewayGenericRouteInformerRoute(t *testing.T) {
+	t.Parallel()
+
+	newInformer := func(t *testing.T, hostnames, parents string) *gatewayGenericRouteInformer {
+		hostnamesPath := jsonpath.New("test").AllowMissingKeys(true)
+		require.NoError(t, hostnamesPath.Parse(hostnames))
+		parentsPath := jsonpath.New("test").AllowMissingKeys(true)
+		require.NoError(t, parentsPath.Parse(parents))
+		return &gatewayGenericRouteInformer{
+			routeType:     GatewayRouteType{Kind: "FooRoute", Protocol: v1.HTTPProtocolType},
+			hostnamesPath: hostnamesPath,
+			parentsPath:   parentsPath,
+		}
+	}
+	parent := map[string]interface{}{
+		"parentRef": map[string]interface{}{
+			"name":      "test",
+			"namespace": "gateway-namespace",
+		},
+		"controllerName": "example.com/controller",
+		"conditions": []interface{}{
+			map[string]interface{}{
+				"type":               "Accepted",
+				"status":             "True",
+				"reason":             "Accepted",
+				"lastTransitionTime": "2024-01-01T00:00:00Z",
+			},
+		},
+	}
+	route := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "FooRoute",
+		"metadata": map[string]interface{}{
+			"name":        "test",
+			"namespace":   "default",
+			"annotations": map[string]interface{}{targetAnnotationKey: "1.2.3.4"},
+		},
+		"spec": map[string]interface{}{
+			"hostnames": []interface{}{"foo.example.internal", "bar.example.internal"},
+		},
+		"status": map[string]interface{}{
+			"parents": []interface{}{parent},
+		},
+	}}
+
+	for _, tt := range []struct {
+		title     string
+		hostnames string
+		parents   string
+	}{
+		{
+			title:     "Defaults",
+			hostnames: defaultGatewayRouteHostnamesPath,
+			parents:   defaultGatewayRouteParentsPath,
+		},
+		{
+			title:     "EachParent",
+			hostnames: defaultGatewayRouteHostnamesPath,
+			parents:   "{.status.parents[*]}",
+		},
+	} {
+		tt := tt
+		t.Run(tt.title, func(t *testing.T) {
+			t.Parallel()
+			rt, err := newInformer(t, tt.hostnames, tt.parents).route(route)
+			require.NoError(t, err)
+			assert.Equal(t, "test", rt.Metadata().Name)
+			assert.Equal(t, "default", rt.Metadata().Namespace)
+			assert.Equal(t, "1.2.3.4", rt.Metadata().Annotations[targetAnnotationKey])
+			assert.Equal(t, v1.HTTPProtocolType, rt.Protocol())
+			assert.Equal(t, []v1.Hostname{"foo.example.internal", "bar.example.internal"}, rt.Hostnames())
+			parents := rt.RouteStatus().Parents
+			require.Len(t, parents, 1)
+			assert.Equal(t, v1.ObjectName("test"), parents[0].ParentRef.Name)
+			require.NotNil(t, parents[0].ParentRef.Namespace)
+			assert.Equal(t, v1.Namespace("gateway-namespace"), *parents[0].ParentRef.Namespace)
+			require.Len(t, parents[0].Conditions, 1)
+			assert.Equal(t, "Accepted", parents[0].Conditions[0].Type)
+		})
+	}
+
+	t.Run("MissingFields", func(t *testing.T) {
+		t.Parallel()
+		empty := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "example.com/v1",
+			"kind":       "FooRoute",
+			"metadata":   map[string]interface{}{"name": "empty", "namespace": "default"},
+		}}
+		rt, err := newInformer(t, defaultGatewayRouteHostnamesPath, defaultGatewayRouteParentsPath).route(empty)
+		require.NoError(t, err)
+		assert.Empty(t, rt.Hostnames())
+		assert.Empty(t, rt.RouteStatus().Parents)
+	})
+
+	t.Run("InvalidHostname", func(t *testing.T) {
+		t.Parallel()
+		invalid := route.DeepCopy()
+		invalid.Object["spec"] = map[string]interface{}{"hostnames": []interface{}{int64(1)}}
+		_, err := newInformer(t, defaultGatewayRouteHostnamesPath, defaultGatewayRouteParentsPath).route(invalid)
+		require.Error(t, err)
+	})
+}
@@ -47,6 +47,10 @@ func gatewayStatus(ips ...string) v1.GatewayStatus {
 	return v1.GatewayStatus{Addresses: addrs}
 }
 
+func gatewayAddress(typ v1.AddressType, value string) v1.GatewayStatusAddress {
+	return v1.GatewayStatusAddress{Type: &typ, Value: value}
+}
+
 func httpRouteStatus(refs ...v1.ParentReference) v1.HTTPRouteStatus {
 	return v1.HTTPRouteStatus{RouteStatus: gwRouteStatus(refs...)}
 }
@@ -366,6 +370,32 @@ func TestGatewayHTTPRouteSourceEndpoints(t *testing.T) {
 			}},
 			endpoints: nil,
 		},
+		{
+			title:      "SkipGatewayControllerAnnotation",
+			config:     Config{},
+			namespaces: namespaces("default"),
+			gateways: []*v1.Gateway{{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test",
+					Namespace: "default",
+					Annotations: map[string]string{
+						controllerAnnotationKey: "something-else",
+					},
+				},
+				Spec: v1.GatewaySpec{
+					Listeners: []v1.Listener{{Protocol: v1.HTTPProtocolType}},
+				},
+				Status: gatewayStatus("1.2.3.4"),
+			}},
+			routes: []*v1.HTTPRoute{{
+				ObjectMeta: objectMeta("default", "api"),
+				Spec: v1.HTTPRouteSpec{
+					Hostnames: hostnames("api.example.internal"),
+				},
+				Status: httpRouteStatus(gwParentRef("default", "test")),
+			}},
+			endpoints: nil,
+		},
 		{
 			title:      "MultipleGateways",
 			config:     Config{},
@@ -471,6 +501,103 @@ func TestGatewayHTTPRouteSourceEndpoints(t *testing.T) {
 				newTestEndpoint("foo.example.internal", "A", "1.2.3.4"),
 			},
 		},
+		{
+			// https://gateway-api.sigs.k8s.io/geps/gep-1713/
+			title:      "ListenerIsolation",
+			config:     Config{},
+			namespaces: namespaces("default"),
+			gateways: []*v1.Gateway{{
+				ObjectMeta: objectMeta("default", "test"),
+				Spec: v1.GatewaySpec{
+					Listeners: []v1.Listener{
+						{
+							Name:     "wildcard",
+							Protocol: v1.HTTPProtocolType,
+							Hostname: hostnamePtr("*.example.internal"),
+							Port:     80,
+						},
+						{
+							Name:     "foo",
+							Protocol: v1.HTTPProtocolType,
+							Hostname: hostnamePtr("foo.example.internal"),
+							Port:     80,
+						},
+						{
+							Name:     "bar",
+							Protocol: v1.HTTPProtocolType,
+							Hostname: hostnamePtr("bar.example.internal"),
+							Port:     8080,
+						},
+					},
+				},
+				Status: gatewayStatus("1.2.3.4"),
+			}},
+			routes: []*v1.HTTPRoute{{
+				ObjectMeta: objectMeta("default", "test"),
+				Spec: v1.HTTPRouteSpec{
+					Hostnames: hostnames("foo.example.internal", "bar.example.internal", "qux.example.internal"),
+				},
+				Status: httpRouteStatus(
+					gwParentRef("default", "test", withSectionName("wildcard")),
+				),
+			}},
+			endpoints: []*endpoint.Endpoint{
+				// foo.example.internal is isolated to the foo listener on the same port
+				newTestEndpoint("bar.example.internal", "A", "1.2.3.4"),
+				newTestEndpoint("qux.example.internal", "A", "1.2.3.4"),
+			},
+		},
+		{
+			title:      "ListenerNotReady",
+			config:     Config{},
+			namespaces: namespaces("default"),
+			gateways: []*v1.Gateway{{
+				ObjectMeta: objectMeta("default", "test"),
+				Spec: v1.GatewaySpec{
+					Listeners: []v1.Listener{
+						{
+							Name:     "foo",
+							Protocol: v1.HTTPProtocolType,
+							Hostname: hostnamePtr("foo.example.internal"),
+						},
+						{
+							Name:     "bar",
+							Protocol: v1.HTTPProtocolType,
+							Hostname: hostnamePtr("bar.example.internal"),
+						},
+					},
+				},
+				Status: v1.GatewayStatus{
+					Addresses: gatewayStatus("1.2.3.4").Addresses,
+					Listeners: []v1.ListenerStatus{
+						{
+							Name: "foo",
+							Conditions: []metav1.Condition{{
+								Type:   string(v1.ListenerConditionProgrammed),
+								Status: metav1.ConditionTrue,
+							}},
+						},
+						{
+							Name: "bar",
+							Conditions: []metav1.Condition{{
+								Type:   string(v1.ListenerConditionProgrammed),
+								Status: metav1.ConditionFalse,
+							}},
+						},
+					},
+				},
+			}},
+			routes: []*v1.HTTPRoute{{
+				ObjectMeta: objectMeta("default", "test"),
+				Spec: v1.HTTPRouteSpec{
+					Hostnames: hostnames("*.example.internal"),
+				},
+				Status: httpRouteStatus(gwParentRef("default", "test")),
+			}},
+			endpoints: []*endpoint.Endpoint{
+				newTestEndpoint("foo.example.internal", "A", "1.2.3.4"),
+			},
+		},
 		{
 			// EXPERIMENTAL: https://gateway-api.sigs.k8s.io/geps/gep-957/
 			title:      "PortNumberMatch",
@@ -1118,6 +1245,59 @@ func TestGatewayHTTPRouteSourceEndpoints(t *testing.T) {
 				newTestEndpoint("test.example.internal", "A", "4.3.2.1", "2.3.4.5"),
 			},
 		},
+		{
+			title:      "HostnameAddresses",
+			config:     Config{},
+			namespaces: namespaces("default"),
+			gateways: []*v1.Gateway{{
+				ObjectMeta: objectMeta("default", "test"),
+				Spec: v1.GatewaySpec{
+					Listeners: []v1.Listener{{Protocol: v1.HTTPProtocolType}},
+				},
+				Status: v1.GatewayStatus{Addresses: []v1.GatewayStatusAddress{
+					gatewayAddress(v1.HostnameAddressType, "lb.example.com."),
+					gatewayAddress(v1.HostnameAddressType, "lb-2.example.com"),
+				}},
+			}},
+			routes: []*v1.HTTPRoute{{
+				ObjectMeta: objectMeta("default", "test"),
+				Spec: v1.HTTPRouteSpec{
+					Hostnames: hostnames("test.example.internal"),
+				},
+				Status: httpRouteStatus(gwParentRef("default", "test")),
+			}},
+			endpoints: []*endpoint.Endpoint{
+				newTestEndpoint("test.example.internal", "CNAME", "lb.example.com", "lb-2.example.com"),
+			},
+		},
+		{
+			title:      "MixedIPAndHostnameAddresses",
+			config:     Config{},
+			namespaces: namespaces("default"),
+			gateways: []*v1.Gateway{{
+				ObjectMeta: objectMeta("default", "test"),
+				Spec: v1.GatewaySpec{
+					Listeners: []v1.Listener{{Protocol: v1.HTTPProtocolType}},
+				},
+				Status: v1.GatewayStatus{Addresses: []v1.GatewayStatusAddress{
+					gatewayAddress(v1.HostnameAddressType, "lb.example.com"),
+					gatewayAddress(v1.IPAddressType, "1.2.3.4"),
+					{Value: "2001:db8::1"}, // the type defaults to IPAddress
+					gatewayAddress(v1.NamedAddressType, "named"),
+				}},
+			}},
+			routes: []*v1.HTTPRoute{{
+				ObjectMeta: objectMeta("default", "test"),
+				Spec: v1.HTTPRouteSpec{
+					Hostnames: hostnames("test.example.internal"),
+				},
+				Status: httpRouteStatus(gwParentRef("default", "test")),
+			}},
+			endpoints: []*endpoint.Endpoint{
+				newTestEndpoint("test.example.internal", "A", "1.2.3.4"),
+				newTestEndpoint("test.example.internal", "AAAA", "2001:db8::1"),
+			},
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
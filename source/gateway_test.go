@@ -17,8 +17,15 @@ limitations under the License.
 package source
 
 import (
+	"fmt"
+	"net"
 	"strings"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"sigs.k8s.io/external-dns/endpoint"
 )
 
 func TestGatewayMatchingHost(t *testing.T) {
@@ -189,3 +196,27 @@ func TestIsDNS1123Domain(t *testing.T) {
 		})
 	}
 }
+
+func TestGatewayAddressTargetsResolvesHostnames(t *testing.T) {
+	lookupIP := gatewayLookupIP
+	defer func() { gatewayLookupIP = lookupIP }()
+	gatewayLookupIP = func(host string) ([]net.IP, error) {
+		if host != "lb.example.com" {
+			return nil, fmt.Errorf("unknown host %s", host)
+		}
+		return []net.IP{net.ParseIP("1.2.3.4"), net.ParseIP("2001:db8::1")}, nil
+	}
+
+	hostname := v1.HostnameAddressType
+	gw := &v1.Gateway{Status: v1.GatewayStatus{Addresses: []v1.GatewayStatusAddress{
+		{Type: &hostname, Value: "lb.example.com"},
+		{Type: &hostname, Value: "unknown.example.com"},
+		{Value: "5.6.7.8"},
+	}}}
+
+	c := &gatewayRouteResolver{src: &gatewayRouteSource{resolveHostnameAddresses: true}}
+	assert.Equal(t, endpoint.Targets{"1.2.3.4", "2001:db8::1", "5.6.7.8"}, c.addressTargets(gw))
+
+	c = &gatewayRouteResolver{src: &gatewayRouteSource{}}
+	assert.Equal(t, endpoint.Targets{"5.6.7.8"}, c.addressTargets(gw))
+}
@@ -25,6 +25,8 @@ import (
 	log "github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
@@ -98,15 +100,17 @@ type glooSource struct {
 	dynamicKubeClient dynamic.Interface
 	kubeClient        kubernetes.Interface
 	glooNamespaces    []string
+	listOptions       func(*metav1.ListOptions)
 }
 
 // NewGlooSource creates a new glooSource with the given config
 func NewGlooSource(dynamicKubeClient dynamic.Interface, kubeClient kubernetes.Interface,
-	glooNamespaces []string) (Source, error) {
+	glooNamespaces []string, labelSelector labels.Selector, fieldSelector fields.Selector) (Source, error) {
 	return &glooSource{
 		dynamicKubeClient,
 		kubeClient,
 		glooNamespaces,
+		newListOptionsTweak(labelSelector, fieldSelector),
 	}, nil
 }
 
@@ -117,8 +121,11 @@ func (gs *glooSource) AddEventHandler(ctx context.Context, handler func()) {
 func (gs *glooSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
 	endpoints := []*endpoint.Endpoint{}
 
+	// the proxies are filtered by the API server, the virtual services they refer to aren't
+	listOptions := metav1.ListOptions{}
+	gs.listOptions(&listOptions)
 	for _, ns := range gs.glooNamespaces {
-		proxies, err := gs.dynamicKubeClient.Resource(proxyGVR).Namespace(ns).List(ctx, metav1.ListOptions{})
+		proxies, err := gs.dynamicKubeClient.Resource(proxyGVR).Namespace(ns).List(ctx, listOptions)
 		if err != nil {
 			return nil, err
 		}
@@ -165,6 +172,12 @@ func (gs *glooSource) generateEndpointsFromProxy(ctx context.Context, proxy *pro
 			if err != nil {
 				return nil, err
 			}
+			// the controller annotation of the virtual service the virtual host comes from
+			if controller, ok := annotations[controllerAnnotationKey]; ok && controller != controllerAnnotationValue {
+				log.Debugf("Gloo[%s]: Skipping virtual host %v because controller value does not match, found: %s, required: %s",
+					proxy.Metadata.Name, virtualHost.Domains, controller, controllerAnnotationValue)
+				continue
+			}
 			ttl := getTTLFromAnnotations(annotations, resource)
 			providerSpecific, setIdentifier := getProviderSpecificAnnotations(annotations)
 			for _, domain := range virtualHost.Domains {
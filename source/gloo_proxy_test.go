@@ -22,9 +22,12 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	fakeDynamic "k8s.io/client-go/dynamic/fake"
@@ -401,7 +404,7 @@ func TestGlooSource(t *testing.T) {
 			proxyGVR: "ProxyList",
 		})
 
-	source, err := NewGlooSource(fakeDynamicClient, fakeKubernetesClient, []string{defaultGlooNamespace})
+	source, err := NewGlooSource(fakeDynamicClient, fakeKubernetesClient, []string{defaultGlooNamespace}, labels.Everything(), fields.Everything())
 	assert.NoError(t, err)
 	assert.NotNil(t, source)
 
@@ -587,4 +590,11 @@ func TestGlooSource(t *testing.T) {
 			},
 		},
 	})
+
+	// the proxies are filtered by the label selector on the API server
+	source, err = NewGlooSource(fakeDynamicClient, fakeKubernetesClient, []string{defaultGlooNamespace}, labels.SelectorFromSet(labels.Set{"team": "a"}), fields.Everything())
+	require.NoError(t, err)
+	endpoints, err = source.Endpoints(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, endpoints)
 }
@@ -0,0 +1,270 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+var (
+	sourceEndpoints = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "external_dns",
+			Subsystem: "source",
+			Name:      "endpoints",
+			Help:      "Number of endpoints of the last successful listing of a source.",
+		},
+		[]string{"source"},
+	)
+	sourceLastSuccessfulList = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "external_dns",
+			Subsystem: "source",
+			Name:      "last_successful_list_timestamp_seconds",
+			Help:      "Timestamp of the last successful listing of the endpoints of a source.",
+		},
+		[]string{"source"},
+	)
+	sourceListErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "external_dns",
+			Subsystem: "source",
+			Name:      "list_errors_total",
+			Help:      "Number of failures to list the endpoints of a source.",
+		},
+		[]string{"source"},
+	)
+	sourceQuarantined = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "external_dns",
+			Subsystem: "source",
+			Name:      "quarantined",
+			Help:      "Whether a source is quarantined, 1 while the endpoints of its last successful listing are used instead of failed or empty listings.",
+		},
+		[]string{"source"},
+	)
+	sourceWatchErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "external_dns",
+			Subsystem: "source",
+			Name:      "watch_errors_total",
+			Help:      "Number of failures to watch the Kubernetes resources of the sources.",
+		},
+	)
+)
+
+// watchErrors holds the last failure to watch the Kubernetes resources, reported by the informers.
+var watchErrors struct {
+	sync.Mutex
+	WatchErrors
+}
+
+func init() {
+	prometheus.MustRegister(sourceEndpoints)
+	prometheus.MustRegister(sourceLastSuccessfulList)
+	prometheus.MustRegister(sourceListErrors)
+	prometheus.MustRegister(sourceQuarantined)
+	prometheus.MustRegister(sourceWatchErrors)
+
+	// The informers keep serving their cache when a watch fails, e.g. after the RBAC permissions of
+	// a resource are revoked, and only report the failure as an unhandled error.
+	utilruntime.ErrorHandlers = append(utilruntime.ErrorHandlers, reportWatchError)
+}
+
+// Health is the health of a source, from its last listings of endpoints.
+type Health struct {
+	Source              string     `json:"source"`
+	Healthy             bool       `json:"healthy"`
+	Quarantined         bool       `json:"quarantined"`
+	Endpoints           int        `json:"endpoints"`
+	LastSuccessfulList  *time.Time `json:"lastSuccessfulList,omitempty"`
+	ConsecutiveFailures int        `json:"consecutiveFailures"`
+	LastError           string     `json:"lastError,omitempty"`
+}
+
+// WatchErrors are the failures to watch the Kubernetes resources of all the sources.
+type WatchErrors struct {
+	Total         int        `json:"total"`
+	LastError     string     `json:"lastError,omitempty"`
+	LastErrorTime *time.Time `json:"lastErrorTime,omitempty"`
+}
+
+// instrumentedSource records the endpoint counts and listing failures of the source it wraps.
+// When quarantine is enabled, a failed listing, or an empty one after at least quarantineMinEndpoints
+// endpoints, is replaced by the endpoints of the last successful listing, so that a single broken
+// source neither fails the sync of all the sources nor deletes all its records. After
+// quarantineMaxDuration, if set, the source is released and its listings are used again.
+type instrumentedSource struct {
+	Source
+	name                   string
+	quarantine             bool
+	quarantineMinEndpoints int
+	quarantineMaxDuration  time.Duration
+	now                    func() time.Time
+
+	mu     sync.Mutex
+	health Health
+	// last are deep copies of the endpoints of the last successful listing, kept while quarantine
+	// is enabled, since the providers and the registry alter the listed endpoints in place.
+	last []*endpoint.Endpoint
+	// quarantinedSince is when the source was quarantined, zero while it isn't
+	quarantinedSince time.Time
+}
+
+// newInstrumentedSource wraps the source to report its health under the name.
+func newInstrumentedSource(name string, s Source, cfg *Config) *instrumentedSource {
+	return &instrumentedSource{
+		Source:                 s,
+		name:                   name,
+		quarantine:             cfg.QuarantineSources,
+		quarantineMinEndpoints: cfg.QuarantineMinEndpoints,
+		quarantineMaxDuration:  cfg.QuarantineMaxDuration,
+		now:                    time.Now,
+		health:                 Health{Source: name, Healthy: true},
+	}
+}
+
+// Endpoints lists the endpoints of the wrapped source and records the outcome.
+func (s *instrumentedSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := s.Source.Endpoints(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.health.Healthy = false
+		s.health.ConsecutiveFailures++
+		s.health.LastError = err.Error()
+		sourceListErrors.WithLabelValues(s.name).Inc()
+		if s.quarantine && s.last != nil && s.setQuarantined(fmt.Sprintf("failed to list its endpoints: %v", err)) {
+			return copyEndpoints(s.last), nil
+		}
+		return endpoints, err
+	}
+	if len(endpoints) == 0 && s.quarantine && s.quarantineMinEndpoints > 0 && len(s.last) >= s.quarantineMinEndpoints {
+		reason := fmt.Sprintf("listed no endpoints after %d", len(s.last))
+		if s.setQuarantined(reason) {
+			s.health.Healthy = false
+			s.health.LastError = reason
+			return copyEndpoints(s.last), nil
+		}
+	}
+	// no endpoints at all is legitimate, but also what a source missing its resources looks like
+	if len(endpoints) == 0 && s.health.Endpoints > 0 {
+		log.Warnf("The source %s has no endpoints anymore, it had %d on its previous listing", s.name, s.health.Endpoints)
+	}
+	if s.health.Quarantined {
+		log.Infof("The source %s is released from quarantine with %d endpoints", s.name, len(endpoints))
+		s.release()
+	}
+	if s.quarantine {
+		s.last = copyEndpoints(endpoints)
+	}
+	now := s.now()
+	s.health.Healthy = true
+	s.health.Endpoints = len(endpoints)
+	s.health.LastSuccessfulList = &now
+	s.health.ConsecutiveFailures = 0
+	s.health.LastError = ""
+	sourceEndpoints.WithLabelValues(s.name).Set(float64(len(endpoints)))
+	sourceLastSuccessfulList.WithLabelValues(s.name).Set(float64(now.Unix()))
+	return endpoints, nil
+}
+
+// setQuarantined marks the source quarantined for the reason, and returns whether it is: a source
+// quarantined for longer than quarantineMaxDuration is released, and its last endpoints dropped, so
+// that its failed or empty listing is used.
+func (s *instrumentedSource) setQuarantined(reason string) bool {
+	now := s.now()
+	if !s.health.Quarantined {
+		log.Errorf("The source %s %s, it is quarantined with the %d endpoints of its last successful listing until it lists endpoints again", s.name, reason, len(s.last))
+		s.quarantinedSince = now
+	} else if s.quarantineMaxDuration > 0 && now.Sub(s.quarantinedSince) >= s.quarantineMaxDuration {
+		log.Errorf("The source %s %s, it is released from quarantine after %s and its %d endpoints are dropped", s.name, reason, s.quarantineMaxDuration, len(s.last))
+		s.release()
+		s.last = nil
+		return false
+	}
+	s.health.Quarantined = true
+	sourceQuarantined.WithLabelValues(s.name).Set(1)
+	return true
+}
+
+// release marks the source not quarantined.
+func (s *instrumentedSource) release() {
+	s.health.Quarantined = false
+	s.quarantinedSince = time.Time{}
+	sourceQuarantined.WithLabelValues(s.name).Set(0)
+}
+
+// Health returns the health of the wrapped source.
+func (s *instrumentedSource) Health() Health {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.health
+}
+
+// SourcesHealth returns the health of the sources built by ByNames.
+func SourcesHealth(sources []Source) []Health {
+	health := make([]Health, 0, len(sources))
+	for _, s := range sources {
+		if is, ok := s.(*instrumentedSource); ok {
+			health = append(health, is.Health())
+		}
+	}
+	return health
+}
+
+// WatchErrorsHealth returns the failures to watch the Kubernetes resources of the sources.
+func WatchErrorsHealth() WatchErrors {
+	watchErrors.Lock()
+	defer watchErrors.Unlock()
+	return watchErrors.WatchErrors
+}
+
+// copyEndpoints returns deep copies of the endpoints, so the quarantined ones are never altered.
+func copyEndpoints(endpoints []*endpoint.Endpoint) []*endpoint.Endpoint {
+	copies := make([]*endpoint.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		copies = append(copies, ep.DeepCopy())
+	}
+	return copies
+}
+
+// reportWatchError records the failures of the informers to watch their resources.
+func reportWatchError(err error) {
+	if err == nil || !strings.Contains(err.Error(), "Failed to watch") {
+		return
+	}
+	sourceWatchErrors.Inc()
+
+	watchErrors.Lock()
+	defer watchErrors.Unlock()
+	now := time.Now()
+	watchErrors.Total++
+	watchErrors.LastError = err.Error()
+	watchErrors.LastErrorTime = &now
+}
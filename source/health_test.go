@@ -0,0 +1,173 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+func TestInstrumentedSource(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	mockSource := new(testutils.MockSource)
+	mockSource.On("Endpoints").Return([]*endpoint.Endpoint{
+		endpoint.NewEndpoint("a.example.org", endpoint.RecordTypeA, "1.1.1.1"),
+		endpoint.NewEndpoint("b.example.org", endpoint.RecordTypeA, "2.2.2.2"),
+	}, nil).Once()
+	mockSource.On("Endpoints").Return(nil, errors.New("forbidden")).Twice()
+	mockSource.On("Endpoints").Return([]*endpoint.Endpoint{}, nil).Once()
+	s := newInstrumentedSource("crd/default", mockSource, &Config{})
+	s.now = func() time.Time { return now }
+
+	endpoints, err := s.Endpoints(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, endpoints, 2)
+	assert.Equal(t, Health{Source: "crd/default", Healthy: true, Endpoints: 2, LastSuccessfulList: &now}, s.Health())
+	assert.Equal(t, 2.0, gaugeValue(t, sourceEndpoints.WithLabelValues("crd/default")))
+	assert.Equal(t, float64(now.Unix()), gaugeValue(t, sourceLastSuccessfulList.WithLabelValues("crd/default")))
+
+	// the failures keep the endpoints of the last successful listing
+	for i := 0; i < 2; i++ {
+		_, err = s.Endpoints(context.Background())
+		assert.EqualError(t, err, "forbidden")
+	}
+	assert.Equal(t, Health{Source: "crd/default", Endpoints: 2, LastSuccessfulList: &now, ConsecutiveFailures: 2, LastError: "forbidden"}, s.Health())
+	metric := &dto.Metric{}
+	require.NoError(t, sourceListErrors.WithLabelValues("crd/default").Write(metric))
+	assert.Equal(t, 2.0, metric.GetCounter().GetValue())
+
+	_, err = s.Endpoints(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Health{Source: "crd/default", Healthy: true, LastSuccessfulList: &now}, s.Health())
+	assert.Zero(t, gaugeValue(t, sourceEndpoints.WithLabelValues("crd/default")))
+
+	assert.Equal(t, []Health{s.Health()}, SourcesHealth([]Source{s, mockSource}))
+}
+
+func TestInstrumentedSourceQuarantine(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	listed := []*endpoint.Endpoint{
+		endpoint.NewEndpoint("a.example.org", endpoint.RecordTypeA, "1.1.1.1"),
+		endpoint.NewEndpoint("b.example.org", endpoint.RecordTypeA, "2.2.2.2"),
+	}
+	mockSource := new(testutils.MockSource)
+	mockSource.On("Endpoints").Return(listed, nil).Once()
+	mockSource.On("Endpoints").Return(nil, errors.New("forbidden")).Once()
+	mockSource.On("Endpoints").Return([]*endpoint.Endpoint{}, nil).Once()
+	mockSource.On("Endpoints").Return(listed[:1], nil).Once()
+	s := newInstrumentedSource("ingress", mockSource, &Config{QuarantineSources: true, QuarantineMinEndpoints: 2})
+	s.now = func() time.Time { return now }
+
+	_, err := s.Endpoints(context.Background())
+	require.NoError(t, err)
+	listed[0].Targets = endpoint.Targets{"3.3.3.3"}
+	// the listed endpoints are altered in place down the pipeline
+	listed[1].Targets[0] = "4.4.4.4"
+	listed[1].Labels[endpoint.OwnerLabelKey] = "owner"
+
+	// the failed listing is replaced by the endpoints of the last successful one
+	endpoints, err := s.Endpoints(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []*endpoint.Endpoint{
+		endpoint.NewEndpoint("a.example.org", endpoint.RecordTypeA, "1.1.1.1"),
+		endpoint.NewEndpoint("b.example.org", endpoint.RecordTypeA, "2.2.2.2"),
+	}, endpoints)
+	assert.Equal(t, Health{Source: "ingress", Quarantined: true, Endpoints: 2, LastSuccessfulList: &now, ConsecutiveFailures: 1, LastError: "forbidden"}, s.Health())
+	assert.Equal(t, 1.0, gaugeValue(t, sourceQuarantined.WithLabelValues("ingress")))
+
+	// and so is the empty listing
+	endpoints, err = s.Endpoints(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, endpoints, 2)
+	assert.True(t, s.Health().Quarantined)
+
+	endpoints, err = s.Endpoints(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, endpoints, 1)
+	assert.Equal(t, Health{Source: "ingress", Healthy: true, Endpoints: 1, LastSuccessfulList: &now}, s.Health())
+	assert.Zero(t, gaugeValue(t, sourceQuarantined.WithLabelValues("ingress")))
+}
+
+func TestInstrumentedSourceQuarantineMaxDuration(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	listed := []*endpoint.Endpoint{
+		endpoint.NewEndpoint("a.example.org", endpoint.RecordTypeA, "1.1.1.1"),
+		endpoint.NewEndpoint("b.example.org", endpoint.RecordTypeA, "2.2.2.2"),
+	}
+	mockSource := new(testutils.MockSource)
+	mockSource.On("Endpoints").Return(listed, nil).Once()
+	mockSource.On("Endpoints").Return(nil, errors.New("forbidden")).Times(3)
+	mockSource.On("Endpoints").Return([]*endpoint.Endpoint{}, nil).Once()
+	s := newInstrumentedSource("service", mockSource, &Config{QuarantineSources: true, QuarantineMinEndpoints: 2, QuarantineMaxDuration: time.Hour})
+	s.now = func() time.Time { return now }
+
+	_, err := s.Endpoints(context.Background())
+	require.NoError(t, err)
+
+	endpoints, err := s.Endpoints(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, endpoints, 2)
+	assert.True(t, s.Health().Quarantined)
+
+	now = now.Add(59 * time.Minute)
+	endpoints, err = s.Endpoints(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, endpoints, 2)
+	assert.True(t, s.Health().Quarantined)
+
+	// the failed listing is used once the source has been quarantined for the maximum duration
+	now = now.Add(time.Minute)
+	_, err = s.Endpoints(context.Background())
+	require.EqualError(t, err, "forbidden")
+	assert.False(t, s.Health().Quarantined)
+
+	// and so is the empty listing, with no endpoints left to keep
+	endpoints, err = s.Endpoints(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, endpoints)
+	assert.Equal(t, Health{Source: "service", Healthy: true, LastSuccessfulList: &now}, s.Health())
+	assert.Zero(t, gaugeValue(t, sourceQuarantined.WithLabelValues("service")))
+}
+
+func TestReportWatchError(t *testing.T) {
+	before := WatchErrorsHealth().Total
+
+	reportWatchError(errors.New("pkg/mod/k8s.io/client-go/tools/cache/reflector.go:229: unable to understand watch event"))
+	assert.Equal(t, before, WatchErrorsHealth().Total)
+
+	reportWatchError(errors.New(`pkg/mod/k8s.io/client-go/tools/cache/reflector.go:229: Failed to watch *v1.Ingress: failed to list *v1.Ingress: ingresses.networking.k8s.io is forbidden`))
+	watchErrors := WatchErrorsHealth()
+	assert.Equal(t, before+1, watchErrors.Total)
+	assert.Contains(t, watchErrors.LastError, "ingresses.networking.k8s.io is forbidden")
+	assert.NotNil(t, watchErrors.LastErrorTime)
+}
+
+func gaugeValue(t *testing.T, gauge interface{ Write(*dto.Metric) error }) float64 {
+	t.Helper()
+	metric := &dto.Metric{}
+	require.NoError(t, gauge.Write(metric))
+	return metric.GetGauge().GetValue()
+}
@@ -26,6 +26,7 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	networkv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/selection"
 	kubeinformers "k8s.io/client-go/informers"
@@ -45,6 +46,7 @@ const (
 	// Possible values for the ingress-hostname-source annotation
 	IngressHostnameSourceAnnotationOnlyValue   = "annotation-only"
 	IngressHostnameSourceDefinedHostsOnlyValue = "defined-hosts-only"
+	IngressHostnameSourceTLSOnlyValue          = "tls-only"
 
 	IngressClassAnnotationKey = "kubernetes.io/ingress.class"
 )
@@ -64,11 +66,12 @@ type ingressSource struct {
 	ingressInformer          netinformers.IngressInformer
 	ignoreIngressTLSSpec     bool
 	ignoreIngressRulesSpec   bool
+	hostnameSource           string
 	labelSelector            labels.Selector
 }
 
 // NewIngressSource creates a new ingressSource with the given config.
-func NewIngressSource(ctx context.Context, kubeClient kubernetes.Interface, namespace, annotationFilter string, fqdnTemplate string, combineFqdnAnnotation bool, ignoreHostnameAnnotation bool, ignoreIngressTLSSpec bool, ignoreIngressRulesSpec bool, labelSelector labels.Selector, ingressClassNames []string) (Source, error) {
+func NewIngressSource(ctx context.Context, kubeClient kubernetes.Interface, namespace, annotationFilter string, fqdnTemplate string, combineFqdnAnnotation bool, ignoreHostnameAnnotation bool, ignoreIngressTLSSpec bool, ignoreIngressRulesSpec bool, hostnameSource string, labelSelector labels.Selector, fieldSelector fields.Selector, ingressClassNames []string) (Source, error) {
 	tmpl, err := parseTemplate(fqdnTemplate)
 	if err != nil {
 		return nil, err
@@ -91,7 +94,7 @@ func NewIngressSource(ctx context.Context, kubeClient kubernetes.Interface, name
 	}
 	// Use shared informer to listen for add/update/delete of ingresses in the specified namespace.
 	// Set resync period to 0, to prevent processing when nothing has changed.
-	informerFactory := kubeinformers.NewSharedInformerFactoryWithOptions(kubeClient, 0, kubeinformers.WithNamespace(namespace))
+	informerFactory := kubeinformers.NewSharedInformerFactoryWithOptions(kubeClient, 0, kubeinformers.WithNamespace(namespace), kubeinformers.WithTweakListOptions(newListOptionsTweak(labelSelector, fieldSelector)))
 	ingressInformer := informerFactory.Networking().V1().Ingresses()
 
 	// Add default resource event handlers to properly initialize informer.
@@ -120,6 +123,7 @@ func NewIngressSource(ctx context.Context, kubeClient kubernetes.Interface, name
 		ingressInformer:          ingressInformer,
 		ignoreIngressTLSSpec:     ignoreIngressTLSSpec,
 		ignoreIngressRulesSpec:   ignoreIngressRulesSpec,
+		hostnameSource:           hostnameSource,
 		labelSelector:            labelSelector,
 	}
 	return sc, nil
@@ -153,7 +157,7 @@ func (sc *ingressSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, e
 			continue
 		}
 
-		ingEndpoints := endpointsFromIngress(ing, sc.ignoreHostnameAnnotation, sc.ignoreIngressTLSSpec, sc.ignoreIngressRulesSpec)
+		ingEndpoints := endpointsFromIngress(ing, sc.ignoreHostnameAnnotation, sc.ignoreIngressTLSSpec, sc.ignoreIngressRulesSpec, sc.hostnameSource)
 
 		// apply template if host is missing on ingress
 		if (sc.combineFQDNAnnotation || len(ingEndpoints) == 0) && sc.fqdnTemplate != nil {
@@ -172,6 +176,9 @@ func (sc *ingressSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, e
 
 		log.Debugf("Endpoints generated from ingress: %s/%s: %v", ing.Namespace, ing.Name, ingEndpoints)
 		sc.setDualstackLabel(ing, ingEndpoints)
+		if !sc.ignoreHostnameAnnotation {
+			ingEndpoints = append(ingEndpoints, aliasEndpoints(ing.Annotations, ingEndpoints, fmt.Sprintf("ingress/%s/%s", ing.Namespace, ing.Name))...)
+		}
 		endpoints = append(endpoints, ingEndpoints...)
 	}
 
@@ -285,7 +292,7 @@ func (sc *ingressSource) setDualstackLabel(ingress *networkv1.Ingress, endpoints
 }
 
 // endpointsFromIngress extracts the endpoints from ingress object
-func endpointsFromIngress(ing *networkv1.Ingress, ignoreHostnameAnnotation bool, ignoreIngressTLSSpec bool, ignoreIngressRulesSpec bool) []*endpoint.Endpoint {
+func endpointsFromIngress(ing *networkv1.Ingress, ignoreHostnameAnnotation bool, ignoreIngressTLSSpec bool, ignoreIngressRulesSpec bool, defaultHostnameSource string) []*endpoint.Endpoint {
 	resource := fmt.Sprintf("ingress/%s/%s", ing.Namespace, ing.Name)
 
 	ttl := getTTLFromAnnotations(ing.Annotations, resource)
@@ -299,28 +306,30 @@ func endpointsFromIngress(ing *networkv1.Ingress, ignoreHostnameAnnotation bool,
 	providerSpecific, setIdentifier := getProviderSpecificAnnotations(ing.Annotations)
 
 	// Gather endpoints defined on hosts sections of the ingress
-	var definedHostsEndpoints []*endpoint.Endpoint
+	var rulesEndpoints []*endpoint.Endpoint
 	// Skip endpoints if we do not want entries from Rules section
 	if !ignoreIngressRulesSpec {
 		for _, rule := range ing.Spec.Rules {
 			if rule.Host == "" {
 				continue
 			}
-			definedHostsEndpoints = append(definedHostsEndpoints, endpointsForHostname(rule.Host, targets, ttl, providerSpecific, setIdentifier, resource)...)
+			rulesEndpoints = append(rulesEndpoints, endpointsForHostname(rule.Host, targets, ttl, providerSpecific, setIdentifier, resource)...)
 		}
 	}
 
 	// Skip endpoints if we do not want entries from tls spec section
+	var tlsEndpoints []*endpoint.Endpoint
 	if !ignoreIngressTLSSpec {
 		for _, tls := range ing.Spec.TLS {
 			for _, host := range tls.Hosts {
 				if host == "" {
 					continue
 				}
-				definedHostsEndpoints = append(definedHostsEndpoints, endpointsForHostname(host, targets, ttl, providerSpecific, setIdentifier, resource)...)
+				tlsEndpoints = append(tlsEndpoints, endpointsForHostname(host, targets, ttl, providerSpecific, setIdentifier, resource)...)
 			}
 		}
 	}
+	definedHostsEndpoints := append(rulesEndpoints, tlsEndpoints...)
 
 	// Gather endpoints defined on annotations in the ingress
 	var annotationEndpoints []*endpoint.Endpoint
@@ -330,10 +339,13 @@ func endpointsFromIngress(ing *networkv1.Ingress, ignoreHostnameAnnotation bool,
 		}
 	}
 
-	// Determine which hostnames to consider in our final list
+	// Determine which hostnames to consider in our final list, the annotation overriding the default
 	hostnameSourceAnnotation, hostnameSourceAnnotationExists := ing.Annotations[ingressHostnameSourceKey]
 	if !hostnameSourceAnnotationExists {
-		return append(definedHostsEndpoints, annotationEndpoints...)
+		if defaultHostnameSource == "" {
+			return append(definedHostsEndpoints, annotationEndpoints...)
+		}
+		hostnameSourceAnnotation = defaultHostnameSource
 	}
 
 	// Include endpoints according to the hostname source annotation in our final list
@@ -344,6 +356,10 @@ func endpointsFromIngress(ing *networkv1.Ingress, ignoreHostnameAnnotation bool,
 	if strings.ToLower(hostnameSourceAnnotation) == IngressHostnameSourceAnnotationOnlyValue {
 		endpoints = append(endpoints, annotationEndpoints...)
 	}
+	// The TLS hosts only, e.g. the names of the certificate next to a wildcard rule
+	if strings.ToLower(hostnameSourceAnnotation) == IngressHostnameSourceTLSOnlyValue {
+		endpoints = append(endpoints, tlsEndpoints...)
+	}
 	return endpoints
 }
 
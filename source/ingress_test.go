@@ -25,6 +25,7 @@ import (
 	"github.com/stretchr/testify/suite"
 	networkv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes/fake"
 
@@ -64,7 +65,9 @@ func (suite *IngressSuite) SetupTest() {
 		false,
 		false,
 		false,
+		"",
 		labels.Everything(),
+		fields.Everything(),
 		[]string{},
 	)
 	suite.NoError(err, "should initialize ingress source")
@@ -160,7 +163,9 @@ func TestNewIngressSource(t *testing.T) {
 				false,
 				false,
 				false,
+				"",
 				labels.Everything(),
+				fields.Everything(),
 				ti.ingressClassNames,
 			)
 			if ti.expectError {
@@ -267,7 +272,7 @@ func testEndpointsFromIngress(t *testing.T) {
 	} {
 		t.Run(ti.title, func(t *testing.T) {
 			realIngress := ti.ingress.Ingress()
-			validateEndpoints(t, endpointsFromIngress(realIngress, ti.ignoreHostnameAnnotation, ti.ignoreIngressTLSSpec, ti.ignoreIngressRulesSpec), ti.expected)
+			validateEndpoints(t, endpointsFromIngress(realIngress, ti.ignoreHostnameAnnotation, ti.ignoreIngressTLSSpec, ti.ignoreIngressRulesSpec, ""), ti.expected)
 		})
 	}
 }
@@ -275,9 +280,10 @@ func testEndpointsFromIngress(t *testing.T) {
 func testEndpointsFromIngressHostnameSourceAnnotation(t *testing.T) {
 	// Host names and host name annotation provided, with various values of the ingress-hostname-source annotation
 	for _, ti := range []struct {
-		title    string
-		ingress  fakeIngress
-		expected []*endpoint.Endpoint
+		title          string
+		ingress        fakeIngress
+		hostnameSource string
+		expected       []*endpoint.Endpoint
 	}{
 		{
 			title: "No ingress-hostname-source annotation, one rule.host, one annotation host",
@@ -363,10 +369,59 @@ func testEndpointsFromIngressHostnameSourceAnnotation(t *testing.T) {
 				},
 			},
 		},
+		{
+			title: "Ingress-hostname-source=tls-only, one wildcard rule.host, one tls host, one annotation host",
+			ingress: fakeIngress{
+				dnsnames:    []string{"*.bar"},
+				tlsdnsnames: [][]string{{"foo.bar"}},
+				annotations: map[string]string{hostnameAnnotationKey: "foo.baz", ingressHostnameSourceKey: "tls-only"},
+				hostnames:   []string{"lb.com"},
+			},
+			expected: []*endpoint.Endpoint{
+				{
+					DNSName:    "foo.bar",
+					RecordType: endpoint.RecordTypeCNAME,
+					Targets:    endpoint.Targets{"lb.com"},
+				},
+			},
+		},
+		{
+			title: "No ingress-hostname-source annotation, tls-only by default, one wildcard rule.host, one tls host, one annotation host",
+			ingress: fakeIngress{
+				dnsnames:    []string{"*.bar"},
+				tlsdnsnames: [][]string{{"foo.bar"}},
+				annotations: map[string]string{hostnameAnnotationKey: "foo.baz"},
+				hostnames:   []string{"lb.com"},
+			},
+			hostnameSource: "tls-only",
+			expected: []*endpoint.Endpoint{
+				{
+					DNSName:    "foo.bar",
+					RecordType: endpoint.RecordTypeCNAME,
+					Targets:    endpoint.Targets{"lb.com"},
+				},
+			},
+		},
+		{
+			title: "Ingress-hostname-source=annotation-only overriding tls-only by default, one tls host, one annotation host",
+			ingress: fakeIngress{
+				tlsdnsnames: [][]string{{"foo.bar"}},
+				annotations: map[string]string{hostnameAnnotationKey: "foo.baz", ingressHostnameSourceKey: "annotation-only"},
+				hostnames:   []string{"lb.com"},
+			},
+			hostnameSource: "tls-only",
+			expected: []*endpoint.Endpoint{
+				{
+					DNSName:    "foo.baz",
+					RecordType: endpoint.RecordTypeCNAME,
+					Targets:    endpoint.Targets{"lb.com"},
+				},
+			},
+		},
 	} {
 		t.Run(ti.title, func(t *testing.T) {
 			realIngress := ti.ingress.Ingress()
-			validateEndpoints(t, endpointsFromIngress(realIngress, false, false, false), ti.expected)
+			validateEndpoints(t, endpointsFromIngress(realIngress, false, false, false, ti.hostnameSource), ti.expected)
 		})
 	}
 }
@@ -1407,7 +1462,9 @@ func testIngressEndpoints(t *testing.T) {
 				ti.ignoreHostnameAnnotation,
 				ti.ignoreIngressTLSSpec,
 				ti.ignoreIngressRulesSpec,
+				"",
 				ti.ingressLabelSelector,
+				fields.Everything(),
 				ti.ingressClassNames,
 			)
 			// Informer cache has all of the ingresses. Retrieve and validate their endpoints.
@@ -33,6 +33,8 @@ import (
 	v1 "k8s.io/api/core/v1"
 	networkv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/fake"
 	k8sclienttesting "k8s.io/client-go/testing"
@@ -123,6 +125,8 @@ func (suite *VirtualServiceSuite) SetupTest() {
 		"{{.Name}}",
 		false,
 		false,
+		labels.Everything(),
+		fields.Everything(),
 	)
 	suite.NoError(err, "should initialize virtualservice source")
 }
@@ -200,6 +204,8 @@ func TestNewIstioVirtualServiceSource(t *testing.T) {
 				ti.fqdnTemplate,
 				ti.combineFQDNAndAnnotation,
 				false,
+				labels.Everything(),
+				fields.Everything(),
 			)
 			if ti.expectError {
 				assert.Error(t, err)
@@ -1983,6 +1989,8 @@ func testVirtualServiceEndpoints(t *testing.T) {
 				ti.fqdnTemplate,
 				ti.combineFQDNAndAnnotation,
 				ti.ignoreHostnameAnnotation,
+				labels.Everything(),
+				fields.Everything(),
 			)
 			require.NoError(t, err)
 
@@ -2069,6 +2077,8 @@ func newTestVirtualServiceSource(loadBalancerList []fakeIngressGatewayService, i
 		"{{.Name}}",
 		false,
 		false,
+		labels.Everything(),
+		fields.Everything(),
 	)
 	if err != nil {
 		return nil, err
@@ -2111,7 +2121,7 @@ func (c fakeVirtualServiceConfig) Config() *networkingv1alpha3.VirtualService {
 }
 
 func TestVirtualServiceSourceGetGateway(t *testing.T) {
-	type fields struct {
+	type sourceFields struct {
 		virtualServiceSource *virtualServiceSource
 	}
 	type args struct {
@@ -2121,26 +2131,26 @@ func TestVirtualServiceSourceGetGateway(t *testing.T) {
 	}
 	tests := []struct {
 		name           string
-		fields         fields
+		fields         sourceFields
 		args           args
 		want           *networkingv1alpha3.Gateway
 		expectedErrStr string
 	}{
-		{name: "EmptyGateway", fields: fields{
+		{name: "EmptyGateway", fields: sourceFields{
 			virtualServiceSource: func() *virtualServiceSource { vs, _ := newTestVirtualServiceSource(nil, nil, nil); return vs }(),
 		}, args: args{
 			ctx:            context.TODO(),
 			gatewayStr:     "",
 			virtualService: nil,
 		}, want: nil, expectedErrStr: ""},
-		{name: "MeshGateway", fields: fields{
+		{name: "MeshGateway", fields: sourceFields{
 			virtualServiceSource: func() *virtualServiceSource { vs, _ := newTestVirtualServiceSource(nil, nil, nil); return vs }(),
 		}, args: args{
 			ctx:            context.TODO(),
 			gatewayStr:     IstioMeshGateway,
 			virtualService: nil,
 		}, want: nil, expectedErrStr: ""},
-		{name: "MissingGateway", fields: fields{
+		{name: "MissingGateway", fields: sourceFields{
 			virtualServiceSource: func() *virtualServiceSource { vs, _ := newTestVirtualServiceSource(nil, nil, nil); return vs }(),
 		}, args: args{
 			ctx:        context.TODO(),
@@ -2152,14 +2162,14 @@ func TestVirtualServiceSourceGetGateway(t *testing.T) {
 				Status:     v1alpha1.IstioStatus{},
 			},
 		}, want: nil, expectedErrStr: ""},
-		{name: "InvalidGatewayStr", fields: fields{
+		{name: "InvalidGatewayStr", fields: sourceFields{
 			virtualServiceSource: func() *virtualServiceSource { vs, _ := newTestVirtualServiceSource(nil, nil, nil); return vs }(),
 		}, args: args{
 			ctx:            context.TODO(),
 			gatewayStr:     "1/2/3/",
 			virtualService: &networkingv1alpha3.VirtualService{},
 		}, want: nil, expectedErrStr: "invalid gateway name (name or namespace/name) found '1/2/3/'"},
-		{name: "ExistingGateway", fields: fields{
+		{name: "ExistingGateway", fields: sourceFields{
 			virtualServiceSource: func() *virtualServiceSource {
 				vs, _ := newTestVirtualServiceSource(nil, nil, []fakeGatewayConfig{{
 					namespace: "bar",
@@ -2182,7 +2192,7 @@ func TestVirtualServiceSourceGetGateway(t *testing.T) {
 			Spec:       istionetworking.Gateway{},
 			Status:     v1alpha1.IstioStatus{},
 		}, expectedErrStr: ""},
-		{name: "ErrorGettingGateway", fields: fields{
+		{name: "ErrorGettingGateway", fields: sourceFields{
 			virtualServiceSource: func() *virtualServiceSource {
 				istioFake := istiofake.NewSimpleClientset()
 				istioFake.NetworkingV1alpha3().(*fakenetworking3.FakeNetworkingV1alpha3).PrependReactor("get", "gateways", func(action k8sclienttesting.Action) (handled bool, ret runtime.Object, err error) {
@@ -2197,6 +2207,8 @@ func TestVirtualServiceSourceGetGateway(t *testing.T) {
 					"{{.Name}}",
 					false,
 					false,
+					labels.Everything(),
+					fields.Everything(),
 				)
 				return vs.(*virtualServiceSource)
 			}(),
@@ -0,0 +1,123 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// The policies of the targets of the LoadBalancer services of Klipper, the ServiceLB of k3s.
+const (
+	// KlipperPolicyAllNodes publishes the IPs of all the nodes, rather than of the nodes running
+	// the pods of Klipper at the time.
+	KlipperPolicyAllNodes = "all-nodes"
+	// KlipperPolicyStableSubset publishes a subset of the IPs of the status, selected by their hash
+	// with the service, so that it doesn't change as the other nodes come and go.
+	KlipperPolicyStableSubset = "stable-subset"
+	// KlipperPolicyTarget publishes a fixed target instead, e.g. the hostname of the ingress
+	// controller, such as Traefik on k3s.
+	KlipperPolicyTarget = "target"
+)
+
+// KlipperPolicy is how the targets of the LoadBalancer services of Klipper are published. Klipper
+// sets the IPs of the nodes running its pods in the status of the services, which churns the
+// records of small clusters as the nodes come and go.
+type KlipperPolicy struct {
+	Policy string
+	// SubsetSize is the number of IPs published by KlipperPolicyStableSubset.
+	SubsetSize int
+	// Target is the target published by KlipperPolicyTarget.
+	Target string
+}
+
+// klipperTargets returns the targets of the LoadBalancer service by the Klipper policy, when its
+// targets are all IPs of nodes, as set by Klipper. The targets of the other services are kept.
+func (sc *serviceSource) klipperTargets(svc *v1.Service, targets endpoint.Targets) endpoint.Targets {
+	if sc.klipperPolicy == nil || len(targets) == 0 || len(svc.Spec.ExternalIPs) > 0 {
+		return targets
+	}
+	nodes, err := sc.nodeInformer.Lister().List(labels.Everything())
+	if err != nil {
+		log.Errorf("Unable to list the nodes for the Klipper service %s/%s: %v", svc.Namespace, svc.Name, err)
+		return targets
+	}
+	nodeIPs := map[string]struct{}{}
+	for _, node := range nodes {
+		for _, address := range node.Status.Addresses {
+			nodeIPs[address.Address] = struct{}{}
+		}
+	}
+	for _, target := range targets {
+		if _, ok := nodeIPs[target]; !ok {
+			return targets
+		}
+	}
+
+	switch sc.klipperPolicy.Policy {
+	case KlipperPolicyAllNodes:
+		return sc.klipperNodeIPs(nodes)
+	case KlipperPolicyStableSubset:
+		if len(targets) <= sc.klipperPolicy.SubsetSize {
+			return targets
+		}
+		key := svc.Namespace + "/" + svc.Name
+		subset := append(endpoint.Targets{}, targets...)
+		sort.SliceStable(subset, func(i, j int) bool { return stableHash(key, subset[i]) > stableHash(key, subset[j]) })
+		subset = subset[:sc.klipperPolicy.SubsetSize]
+		sort.Sort(subset)
+		return subset
+	case KlipperPolicyTarget:
+		return endpoint.Targets{sc.klipperPolicy.Target}
+	}
+	return targets
+}
+
+// klipperNodeIPs returns the IPs of the nodes Klipper would set: their external IPs, or their
+// internal IPs without any, of the nodes which aren't excluded.
+func (sc *serviceSource) klipperNodeIPs(nodes []*v1.Node) endpoint.Targets {
+	var targets endpoint.Targets
+	for _, node := range nodes {
+		if sc.excludeDeletingNodes && nodeDeleting(node) {
+			continue
+		}
+		if !sc.targetReadiness.NodeReady(node) {
+			continue
+		}
+		var externalIPs, internalIPs endpoint.Targets
+		for _, address := range node.Status.Addresses {
+			switch address.Type {
+			case v1.NodeExternalIP:
+				externalIPs = append(externalIPs, address.Address)
+			case v1.NodeInternalIP:
+				internalIPs = append(internalIPs, address.Address)
+			}
+		}
+		if len(externalIPs) > 0 {
+			targets = append(targets, externalIPs...)
+		} else {
+			targets = append(targets, internalIPs...)
+		}
+	}
+	sort.Sort(targets)
+	return targets
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func TestServiceSourceKlipperPolicy(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	for name, addresses := range map[string][]v1.NodeAddress{
+		"node-1": {{Type: v1.NodeInternalIP, Address: "10.0.0.1"}, {Type: v1.NodeExternalIP, Address: "192.0.2.1"}},
+		"node-2": {{Type: v1.NodeInternalIP, Address: "10.0.0.2"}},
+		"node-3": {{Type: v1.NodeInternalIP, Address: "10.0.0.3"}},
+		"node-4": {{Type: v1.NodeInternalIP, Address: "10.0.0.4"}},
+	} {
+		node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}, Status: v1.NodeStatus{Addresses: addresses}}
+		_, err := client.CoreV1().Nodes().Create(context.Background(), node, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+	for name, ips := range map[string][]string{
+		"klipper": {"10.0.0.2", "10.0.0.3", "10.0.0.4"},
+		"cloud":   {"198.51.100.1"},
+	} {
+		svc := &v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Annotations: map[string]string{hostnameAnnotationKey: name + ".example.org"}},
+			Spec:       v1.ServiceSpec{Type: v1.ServiceTypeLoadBalancer},
+		}
+		for _, ip := range ips {
+			svc.Status.LoadBalancer.Ingress = append(svc.Status.LoadBalancer.Ingress, v1.LoadBalancerIngress{IP: ip})
+		}
+		_, err := client.CoreV1().Services(svc.Namespace).Create(context.Background(), svc, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+
+	for _, tc := range []struct {
+		title    string
+		policy   *KlipperPolicy
+		expected endpoint.Targets
+		cname    bool
+	}{
+		{title: "disabled", expected: endpoint.Targets{"10.0.0.2", "10.0.0.3", "10.0.0.4"}},
+		{title: "all nodes", policy: &KlipperPolicy{Policy: KlipperPolicyAllNodes}, expected: endpoint.Targets{"10.0.0.2", "10.0.0.3", "10.0.0.4", "192.0.2.1"}},
+		{title: "stable subset larger than the status", policy: &KlipperPolicy{Policy: KlipperPolicyStableSubset, SubsetSize: 3}, expected: endpoint.Targets{"10.0.0.2", "10.0.0.3", "10.0.0.4"}},
+		{title: "target", policy: &KlipperPolicy{Policy: KlipperPolicyTarget, Target: "traefik.example.org"}, expected: endpoint.Targets{"traefik.example.org"}, cname: true},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			src, err := NewServiceSource(context.TODO(), client, ServiceSourceConfig{KlipperPolicy: tc.policy})
+			require.NoError(t, err)
+			endpoints, err := src.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			targets := map[string]endpoint.Targets{}
+			for _, ep := range endpoints {
+				targets[ep.DNSName] = append(targets[ep.DNSName], ep.Targets...)
+				if ep.DNSName == "klipper.example.org" && tc.cname {
+					assert.Equal(t, endpoint.RecordTypeCNAME, ep.RecordType)
+				}
+			}
+			assert.ElementsMatch(t, tc.expected, targets["klipper.example.org"])
+			// the services of other load balancers are left alone
+			assert.Equal(t, endpoint.Targets{"198.51.100.1"}, targets["cloud.example.org"])
+		})
+	}
+}
+
+func TestServiceSourceKlipperStableSubset(t *testing.T) {
+	subset := func(ips ...string) endpoint.Targets {
+		client := fake.NewSimpleClientset()
+		svc := &v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "klipper"},
+			Spec:       v1.ServiceSpec{Type: v1.ServiceTypeLoadBalancer},
+		}
+		for _, ip := range ips {
+			node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-" + ip}, Status: v1.NodeStatus{Addresses: []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: ip}}}}
+			_, err := client.CoreV1().Nodes().Create(context.Background(), node, metav1.CreateOptions{})
+			require.NoError(t, err)
+			svc.Status.LoadBalancer.Ingress = append(svc.Status.LoadBalancer.Ingress, v1.LoadBalancerIngress{IP: ip})
+		}
+		src, err := NewServiceSource(context.TODO(), client, ServiceSourceConfig{KlipperPolicy: &KlipperPolicy{Policy: KlipperPolicyStableSubset, SubsetSize: 2}})
+		require.NoError(t, err)
+		return src.(*serviceSource).klipperTargets(svc, extractLoadBalancerTargets(svc, false))
+	}
+
+	kept := subset("10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4", "10.0.0.5")
+	require.Len(t, kept, 2)
+	// removing a node which isn't kept doesn't change the subset
+	var others []string
+	for _, ip := range []string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4", "10.0.0.5"} {
+		if ip != kept[0] && ip != kept[1] {
+			others = append(others, ip)
+		}
+	}
+	assert.Equal(t, kept, subset(append([]string{kept[0], kept[1]}, others[1:]...)...))
+}
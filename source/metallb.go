@@ -0,0 +1,193 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// metalLBPoolAnnotationKey is set by MetalLB on the services it allocated an IP address to, to the
+// name of the IPAddressPool of the address.
+const metalLBPoolAnnotationKey = "metallb.universe.tf/ip-allocated-from-pool"
+
+var (
+	metalLBIPAddressPoolsGVR    = schema.GroupVersionResource{Group: "metallb.io", Version: "v1beta1", Resource: "ipaddresspools"}
+	metalLBL2AdvertisementsGVR  = schema.GroupVersionResource{Group: "metallb.io", Version: "v1beta1", Resource: "l2advertisements"}
+	metalLBBGPAdvertisementsGVR = schema.GroupVersionResource{Group: "metallb.io", Version: "v1beta1", Resource: "bgpadvertisements"}
+	metalLBServiceL2StatusesGVR = schema.GroupVersionResource{Group: "metallb.io", Version: "v1beta1", Resource: "servicel2statuses"}
+)
+
+// MetalLB tells the targets of the LoadBalancer services allocated by MetalLB which are announced,
+// from the IPAddressPools, the L2Advertisements and BGPAdvertisements, and the ServiceL2Statuses of
+// the namespace of MetalLB.
+type MetalLB struct {
+	namespace         string
+	pools             informers.GenericInformer
+	l2Advertisements  informers.GenericInformer
+	bgpAdvertisements informers.GenericInformer
+	l2Statuses        informers.GenericInformer
+}
+
+// NewMetalLB creates a new MetalLB reading the resources of MetalLB in the namespace.
+func NewMetalLB(ctx context.Context, dynamicKubeClient dynamic.Interface, namespace string) (*MetalLB, error) {
+	informerFactory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(dynamicKubeClient, 0, namespace, nil)
+	m := &MetalLB{
+		namespace:         namespace,
+		pools:             informerFactory.ForResource(metalLBIPAddressPoolsGVR),
+		l2Advertisements:  informerFactory.ForResource(metalLBL2AdvertisementsGVR),
+		bgpAdvertisements: informerFactory.ForResource(metalLBBGPAdvertisementsGVR),
+		l2Statuses:        informerFactory.ForResource(metalLBServiceL2StatusesGVR),
+	}
+	// Register with factory before starting.
+	for _, informer := range []informers.GenericInformer{m.pools, m.l2Advertisements, m.bgpAdvertisements, m.l2Statuses} {
+		informer.Informer()
+	}
+
+	informerFactory.Start(ctx.Done())
+
+	// wait for the local cache to be populated.
+	if err := waitForDynamicCacheSync(context.Background(), informerFactory); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Targets returns the targets of the LoadBalancer service to publish. The targets of a service
+// allocated an address by MetalLB are only published once its IPAddressPool is advertised, and, by
+// L2Advertisements only, once a node announces the service. The targets of a pool annotated with
+// the target annotation, e.g. a stable virtual IP in front of the services, replace those of the
+// service. The other services keep their targets, and so do all of them without MetalLB.
+func (m *MetalLB) Targets(svc *v1.Service, targets endpoint.Targets) endpoint.Targets {
+	poolName, ok := svc.Annotations[metalLBPoolAnnotationKey]
+	if m == nil || !ok || len(svc.Spec.ExternalIPs) > 0 {
+		return targets
+	}
+
+	pool, err := m.get(m.pools, poolName)
+	if err != nil {
+		log.Debugf("Skipping service %s/%s because its IPAddressPool %s isn't found: %v", svc.Namespace, svc.Name, poolName, err)
+		return nil
+	}
+	switch {
+	case m.advertised(m.bgpAdvertisements, pool):
+	case m.advertised(m.l2Advertisements, pool):
+		if !m.announced(svc) {
+			log.Debugf("Skipping service %s/%s because no node announces it yet", svc.Namespace, svc.Name)
+			return nil
+		}
+	default:
+		log.Debugf("Skipping service %s/%s because its IPAddressPool %s isn't advertised", svc.Namespace, svc.Name, poolName)
+		return nil
+	}
+
+	if poolTargets := getTargetsFromTargetAnnotation(pool.GetAnnotations()); len(poolTargets) > 0 {
+		return poolTargets
+	}
+	return targets
+}
+
+// get returns the object of the informer of the name in the namespace of MetalLB.
+func (m *MetalLB) get(informer informers.GenericInformer, name string) (*unstructured.Unstructured, error) {
+	obj, err := informer.Lister().ByNamespace(m.namespace).Get(name)
+	if err != nil {
+		return nil, err
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object %T", obj)
+	}
+	return u, nil
+}
+
+// list returns the objects of the informer in the namespace of MetalLB.
+func (m *MetalLB) list(informer informers.GenericInformer) []*unstructured.Unstructured {
+	objs, err := informer.Lister().ByNamespace(m.namespace).List(labels.Everything())
+	if err != nil {
+		log.Warnf("Failed to list the resources of MetalLB: %v", err)
+		return nil
+	}
+	list := make([]*unstructured.Unstructured, 0, len(objs))
+	for _, obj := range objs {
+		if u, ok := obj.(*unstructured.Unstructured); ok {
+			list = append(list, u)
+		}
+	}
+	return list
+}
+
+// advertised reports whether an advertisement of the informer selects the pool, by name or by
+// labels, or selects all the pools.
+func (m *MetalLB) advertised(informer informers.GenericInformer, pool *unstructured.Unstructured) bool {
+	for _, ad := range m.list(informer) {
+		names, _, _ := unstructured.NestedStringSlice(ad.Object, "spec", "ipAddressPools")
+		selectors, _, _ := unstructured.NestedSlice(ad.Object, "spec", "ipAddressPoolSelectors")
+		if len(names) == 0 && len(selectors) == 0 {
+			return true
+		}
+		for _, name := range names {
+			if name == pool.GetName() {
+				return true
+			}
+		}
+		for _, s := range selectors {
+			obj, ok := s.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			var labelSelector metav1.LabelSelector
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj, &labelSelector); err != nil {
+				log.Warnf("Skipping an invalid IPAddressPool selector of %s: %v", ad.GetName(), err)
+				continue
+			}
+			selector, err := metav1.LabelSelectorAsSelector(&labelSelector)
+			if err != nil {
+				log.Warnf("Skipping an invalid IPAddressPool selector of %s: %v", ad.GetName(), err)
+				continue
+			}
+			if selector.Matches(labels.Set(pool.GetLabels())) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// announced reports whether a node announces the service, as told by a ServiceL2Status.
+func (m *MetalLB) announced(svc *v1.Service) bool {
+	for _, status := range m.list(m.l2Statuses) {
+		name, _, _ := unstructured.NestedString(status.Object, "status", "serviceName")
+		namespace, _, _ := unstructured.NestedString(status.Object, "status", "serviceNamespace")
+		if name == svc.Name && namespace == svc.Namespace {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakeDynamic "k8s.io/client-go/dynamic/fake"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func newMetalLBObject(kind, name string, labels, annotations map[string]string, fields map[string]interface{}) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{Object: fields}
+	u.SetAPIVersion("metallb.io/v1beta1")
+	u.SetKind(kind)
+	u.SetNamespace("metallb-system")
+	u.SetName(name)
+	u.SetLabels(labels)
+	u.SetAnnotations(annotations)
+	return u
+}
+
+func newTestMetalLB(t *testing.T, objects ...runtime.Object) *MetalLB {
+	t.Helper()
+	client := fakeDynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		metalLBIPAddressPoolsGVR:    "IPAddressPoolList",
+		metalLBL2AdvertisementsGVR:  "L2AdvertisementList",
+		metalLBBGPAdvertisementsGVR: "BGPAdvertisementList",
+		metalLBServiceL2StatusesGVR: "ServiceL2StatusList",
+	}, objects...)
+	m, err := NewMetalLB(context.TODO(), client, "metallb-system")
+	require.NoError(t, err)
+	return m
+}
+
+func TestMetalLBTargets(t *testing.T) {
+	pools := []runtime.Object{
+		newMetalLBObject("IPAddressPool", "l2", nil, nil, map[string]interface{}{}),
+		newMetalLBObject("IPAddressPool", "bgp", map[string]string{"routing": "bgp"}, nil, map[string]interface{}{}),
+		newMetalLBObject("IPAddressPool", "vip", nil, map[string]string{targetAnnotationKey: "192.0.2.100"}, map[string]interface{}{}),
+		newMetalLBObject("IPAddressPool", "unadvertised", nil, nil, map[string]interface{}{}),
+	}
+	advertisements := []runtime.Object{
+		newMetalLBObject("L2Advertisement", "l2", nil, nil, map[string]interface{}{
+			"spec": map[string]interface{}{"ipAddressPools": []interface{}{"l2", "vip"}},
+		}),
+		newMetalLBObject("BGPAdvertisement", "bgp", nil, nil, map[string]interface{}{
+			"spec": map[string]interface{}{"ipAddressPoolSelectors": []interface{}{
+				map[string]interface{}{"matchLabels": map[string]interface{}{"routing": "bgp"}},
+			}},
+		}),
+		newMetalLBObject("ServiceL2Status", "l2-announced", nil, nil, map[string]interface{}{
+			"status": map[string]interface{}{"node": "node-1", "serviceName": "announced", "serviceNamespace": "default"},
+		}),
+		newMetalLBObject("ServiceL2Status", "vip-announced", nil, nil, map[string]interface{}{
+			"status": map[string]interface{}{"node": "node-1", "serviceName": "vip", "serviceNamespace": "default"},
+		}),
+	}
+	m := newTestMetalLB(t, append(pools, advertisements...)...)
+
+	service := func(name, pool string) *v1.Service {
+		svc := &v1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name}}
+		if pool != "" {
+			svc.Annotations = map[string]string{metalLBPoolAnnotationKey: pool}
+		}
+		return svc
+	}
+	targets := endpoint.Targets{"192.0.2.1"}
+
+	for _, tc := range []struct {
+		title    string
+		svc      *v1.Service
+		expected endpoint.Targets
+	}{
+		{title: "announced by L2", svc: service("announced", "l2"), expected: targets},
+		{title: "not announced by L2 yet", svc: service("pending", "l2"), expected: nil},
+		{title: "advertised by BGP", svc: service("routed", "bgp"), expected: targets},
+		{title: "pool not advertised", svc: service("announced", "unadvertised"), expected: nil},
+		{title: "pool not found", svc: service("announced", "missing"), expected: nil},
+		{title: "virtual IP of the pool", svc: service("vip", "vip"), expected: endpoint.Targets{"192.0.2.100"}},
+		{title: "not allocated by MetalLB", svc: service("other", ""), expected: targets},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			assert.Equal(t, tc.expected, m.Targets(tc.svc, targets))
+		})
+	}
+
+	var nilMetalLB *MetalLB
+	assert.Equal(t, targets, nilMetalLB.Targets(service("pending", "l2"), targets))
+}
+
+func TestMetalLBAdvertisementOfAllPools(t *testing.T) {
+	m := newTestMetalLB(t,
+		newMetalLBObject("IPAddressPool", "default", nil, nil, map[string]interface{}{}),
+		newMetalLBObject("BGPAdvertisement", "all", nil, nil, map[string]interface{}{}),
+	)
+	svc := &v1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web", Annotations: map[string]string{metalLBPoolAnnotationKey: "default"}}}
+	assert.Equal(t, endpoint.Targets{"192.0.2.1"}, m.Targets(svc, endpoint.Targets{"192.0.2.1"}))
+}
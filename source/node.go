@@ -24,6 +24,7 @@ import (
 	log "github.com/sirupsen/logrus"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	kubeinformers "k8s.io/client-go/informers"
 	coreinformers "k8s.io/client-go/informers/core/v1"
@@ -39,10 +40,14 @@ type nodeSource struct {
 	fqdnTemplate     *template.Template
 	nodeInformer     coreinformers.NodeInformer
 	labelSelector    labels.Selector
+	publishSSHFP     bool
+	excludeDeleting  bool
 }
 
-// NewNodeSource creates a new nodeSource with the given config.
-func NewNodeSource(ctx context.Context, kubeClient kubernetes.Interface, annotationFilter, fqdnTemplate string, labelSelector labels.Selector) (Source, error) {
+// NewNodeSource creates a new nodeSource with the given config. With publishSSHFP, the SSH host
+// keys of the nodes annotated with them are published as SSHFP records. With excludeDeleting, the
+// nodes being deleted, or about to be by the autoscalers, aren't published.
+func NewNodeSource(ctx context.Context, kubeClient kubernetes.Interface, annotationFilter, fqdnTemplate string, labelSelector labels.Selector, fieldSelector fields.Selector, publishSSHFP bool, excludeDeleting bool) (Source, error) {
 	tmpl, err := parseTemplate(fqdnTemplate)
 	if err != nil {
 		return nil, err
@@ -50,7 +55,7 @@ func NewNodeSource(ctx context.Context, kubeClient kubernetes.Interface, annotat
 
 	// Use shared informers to listen for add/update/delete of nodes.
 	// Set resync period to 0, to prevent processing when nothing has changed
-	informerFactory := kubeinformers.NewSharedInformerFactoryWithOptions(kubeClient, 0)
+	informerFactory := kubeinformers.NewSharedInformerFactoryWithOptions(kubeClient, 0, kubeinformers.WithTweakListOptions(newListOptionsTweak(labelSelector, fieldSelector)))
 	nodeInformer := informerFactory.Core().V1().Nodes()
 
 	// Add default resource event handler to properly initialize informer.
@@ -75,6 +80,8 @@ func NewNodeSource(ctx context.Context, kubeClient kubernetes.Interface, annotat
 		fqdnTemplate:     tmpl,
 		nodeInformer:     nodeInformer,
 		labelSelector:    labelSelector,
+		publishSSHFP:     publishSSHFP,
+		excludeDeleting:  excludeDeleting,
 	}, nil
 }
 
@@ -102,6 +109,11 @@ func (ns *nodeSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, erro
 			continue
 		}
 
+		if ns.excludeDeleting && nodeDeleting(node) {
+			log.Debugf("Skipping node %s because it is being deleted", node.Name)
+			continue
+		}
+
 		log.Debugf("creating endpoint for node %s", node.Name)
 
 		ttl := getTTLFromAnnotations(node.Annotations, fmt.Sprintf("node/%s", node.Name))
@@ -149,6 +161,27 @@ func (ns *nodeSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, erro
 			}
 			endpoints[key].Targets = append(endpoints[key].Targets, addr)
 		}
+
+		if ns.publishSSHFP {
+			if keys, ok := node.Annotations[sshHostKeysAnnotationKey]; ok {
+				targets, errs := sshfpTargets(keys)
+				for _, err := range errs {
+					log.Warnf("Skipping an SSH host key of node %s: %v", node.Name, err)
+				}
+				if len(targets) > 0 {
+					key := endpoint.EndpointKey{
+						DNSName:    ep.DNSName,
+						RecordType: endpoint.RecordTypeSSHFP,
+					}
+					if _, ok := endpoints[key]; !ok {
+						epCopy := *ep
+						epCopy.RecordType = key.RecordType
+						endpoints[key] = &epCopy
+					}
+					endpoints[key].Targets = append(endpoints[key].Targets, targets...)
+				}
+			}
+		}
 	}
 
 	endpointsSlice := []*endpoint.Endpoint{}
@@ -24,6 +24,7 @@ import (
 	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes/fake"
 
@@ -77,6 +78,9 @@ func testNodeSourceNewNodeSource(t *testing.T) {
 				ti.annotationFilter,
 				ti.fqdnTemplate,
 				labels.Everything(),
+				fields.Everything(),
+				false,
+				false,
 			)
 
 			if ti.expectError {
@@ -101,6 +105,7 @@ func testNodeSourceEndpoints(t *testing.T) {
 		nodeAddresses    []v1.NodeAddress
 		labels           map[string]string
 		annotations      map[string]string
+		publishSSHFP     bool
 		expected         []*endpoint.Endpoint
 		expectError      bool
 	}{
@@ -321,6 +326,42 @@ func testNodeSourceEndpoints(t *testing.T) {
 				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.2.3.4"}, RecordTTL: endpoint.TTL(10)},
 			},
 		},
+		{
+			title:         "ssh host keys annotated should publish SSHFP records",
+			nodeName:      "node1",
+			nodeAddresses: []v1.NodeAddress{{Type: v1.NodeExternalIP, Address: "1.2.3.4"}},
+			annotations: map[string]string{
+				sshHostKeysAnnotationKey: testSSHEd25519Key + "\nssh-foo AAAA\n" + testSSHECDSAKey,
+			},
+			publishSSHFP: true,
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.2.3.4"}},
+				{RecordType: "SSHFP", DNSName: "node1", Targets: endpoint.Targets{testSSHEd25519SSHFP, testSSHECDSASSHFP}},
+			},
+		},
+		{
+			title:         "ssh host keys annotated without publishing SSHFP records",
+			nodeName:      "node1",
+			nodeAddresses: []v1.NodeAddress{{Type: v1.NodeExternalIP, Address: "1.2.3.4"}},
+			annotations: map[string]string{
+				sshHostKeysAnnotationKey: testSSHEd25519Key,
+			},
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+		},
+		{
+			title:         "invalid ssh host keys annotated should publish no SSHFP records",
+			nodeName:      "node1",
+			nodeAddresses: []v1.NodeAddress{{Type: v1.NodeExternalIP, Address: "1.2.3.4"}},
+			annotations: map[string]string{
+				sshHostKeysAnnotationKey: "ssh-foo AAAA",
+			},
+			publishSSHFP: true,
+			expected: []*endpoint.Endpoint{
+				{RecordType: "A", DNSName: "node1", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+		},
 	} {
 		tc := tc
 		t.Run(tc.title, func(t *testing.T) {
@@ -357,6 +398,9 @@ func testNodeSourceEndpoints(t *testing.T) {
 				tc.annotationFilter,
 				tc.fqdnTemplate,
 				labelSelector,
+				fields.Everything(),
+				tc.publishSSHFP,
+				false,
 			)
 			require.NoError(t, err)
 
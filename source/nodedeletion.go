@@ -0,0 +1,87 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	kubeinformers "k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// The taints set on the nodes about to be removed by the autoscalers.
+const (
+	// clusterAutoscalerDeletionTaint is set by the cluster-autoscaler on the nodes it scales down.
+	clusterAutoscalerDeletionTaint = "ToBeDeletedByClusterAutoscaler"
+	// karpenterDisruptedTaint is set by Karpenter v1 on the nodes it disrupts.
+	karpenterDisruptedTaint = "karpenter.sh/disrupted"
+	// karpenterDisruptionTaint is set by Karpenter before v1 on the nodes it disrupts.
+	karpenterDisruptionTaint = "karpenter.sh/disruption"
+)
+
+// nodeDeleting reports whether the node is being deleted, or is about to be by the cluster-autoscaler
+// or Karpenter, which drain and taint the nodes they remove first.
+func nodeDeleting(node *v1.Node) bool {
+	if node.DeletionTimestamp != nil {
+		return true
+	}
+	for _, taint := range node.Spec.Taints {
+		switch taint.Key {
+		case clusterAutoscalerDeletionTaint, karpenterDisruptedTaint, karpenterDisruptionTaint:
+			return true
+		}
+	}
+	return false
+}
+
+// nodeDeletionHandler calls the handler when a node is deleted or starts being deleted.
+func nodeDeletionHandler(handler func()) cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldNode, ok := oldObj.(*v1.Node)
+			if !ok {
+				return
+			}
+			newNode, ok := newObj.(*v1.Node)
+			if !ok {
+				return
+			}
+			if !nodeDeleting(oldNode) && nodeDeleting(newNode) {
+				log.Debugf("Node %s is being deleted", newNode.Name)
+				handler()
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			log.Debug("Node deleted")
+			handler()
+		},
+	}
+}
+
+// WatchNodeDeletions calls the handler whenever a node is deleted or starts being deleted, e.g. to
+// reconcile the records of the nodes scaled down right away rather than on the next interval.
+func WatchNodeDeletions(ctx context.Context, kubeClient kubernetes.Interface, handler func()) error {
+	informerFactory := kubeinformers.NewSharedInformerFactory(kubeClient, 0)
+	nodeInformer := informerFactory.Core().V1().Nodes()
+	nodeInformer.Informer().AddEventHandler(nodeDeletionHandler(handler))
+
+	informerFactory.Start(ctx.Done())
+	return waitForCacheSync(context.Background(), informerFactory)
+}
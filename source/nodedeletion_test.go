@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func deletionTestNode(name string, taints ...string) *v1.Node {
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: v1.NodeStatus{Addresses: []v1.NodeAddress{
+			{Type: v1.NodeExternalIP, Address: "1.2.3.4"},
+		}},
+	}
+	for _, taint := range taints {
+		node.Spec.Taints = append(node.Spec.Taints, v1.Taint{Key: taint, Effect: v1.TaintEffectNoSchedule})
+	}
+	return node
+}
+
+func TestNodeDeleting(t *testing.T) {
+	assert.False(t, nodeDeleting(deletionTestNode("node-1")))
+	assert.False(t, nodeDeleting(deletionTestNode("node-1", "node.kubernetes.io/unschedulable")))
+	assert.True(t, nodeDeleting(deletionTestNode("node-1", clusterAutoscalerDeletionTaint)))
+	assert.True(t, nodeDeleting(deletionTestNode("node-1", karpenterDisruptedTaint)))
+	assert.True(t, nodeDeleting(deletionTestNode("node-1", karpenterDisruptionTaint)))
+
+	deleted := deletionTestNode("node-1")
+	deleted.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	assert.True(t, nodeDeleting(deleted))
+}
+
+func TestNodeDeletionHandler(t *testing.T) {
+	calls := 0
+	handler := nodeDeletionHandler(func() { calls++ })
+
+	handler.OnAdd(deletionTestNode("node-1"), false)
+	handler.OnUpdate(deletionTestNode("node-1"), deletionTestNode("node-1"))
+	assert.Equal(t, 0, calls, "not called for other changes")
+
+	handler.OnUpdate(deletionTestNode("node-1"), deletionTestNode("node-1", karpenterDisruptedTaint))
+	assert.Equal(t, 1, calls)
+	handler.OnUpdate(deletionTestNode("node-1", karpenterDisruptedTaint), deletionTestNode("node-1", karpenterDisruptedTaint))
+	assert.Equal(t, 1, calls, "called once when the node starts being deleted")
+
+	handler.OnDelete(deletionTestNode("node-1", karpenterDisruptedTaint))
+	assert.Equal(t, 2, calls)
+}
+
+func TestNodeSourceExcludeDeleting(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	for _, node := range []*v1.Node{
+		deletionTestNode("node-1"),
+		deletionTestNode("node-2", clusterAutoscalerDeletionTaint),
+	} {
+		_, err := client.CoreV1().Nodes().Create(context.Background(), node, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+
+	for _, tc := range []struct {
+		excludeDeleting bool
+		expected        []string
+	}{
+		{excludeDeleting: false, expected: []string{"node-1", "node-2"}},
+		{excludeDeleting: true, expected: []string{"node-1"}},
+	} {
+		s, err := NewNodeSource(context.TODO(), client, "", "", labels.Everything(), fields.Everything(), false, tc.excludeDeleting)
+		require.NoError(t, err)
+		endpoints, err := s.Endpoints(context.Background())
+		require.NoError(t, err)
+
+		var names []string
+		for _, ep := range endpoints {
+			names = append(names, ep.DNSName)
+		}
+		assert.ElementsMatch(t, tc.expected, names)
+	}
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	cfclient "github.com/cloudfoundry-community/go-cfclient"
+	openshift "github.com/openshift/client-go/route/clientset/versioned"
+	istioclient "istio.io/client-go/pkg/clientset/versioned"
+	v1 "k8s.io/api/core/v1"
+	networkv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	gateway "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// EndpointsForObject returns the endpoints the sources configured by cfg would generate for a
+// single Service, Ingress or DNSEndpoint, without a cluster, e.g. to lint manifests or review them
+// before they are applied. The objects outside of the namespaces of cfg, or not matching its
+// annotation or label filter, have no endpoints. The field filter is ignored.
+//
+// The Service and Ingress sources are built as for a cluster holding only the object, so the
+// targets of NodePort and headless Services, coming from the nodes and pods of the cluster, and
+// those of Services using a MetalLB address pool are missing unless they have a target annotation.
+func EndpointsForObject(ctx context.Context, obj runtime.Object, cfg *Config) ([]*endpoint.Endpoint, error) {
+	selector := cfg.LabelFilter
+	if selector == nil {
+		selector = labels.Everything()
+	}
+
+	var name string
+	switch o := obj.(type) {
+	case *v1.Service:
+		if !cfg.watchesNamespace(o.Namespace) {
+			return nil, nil
+		}
+		name = "service"
+	case *networkv1.Ingress:
+		if !cfg.watchesNamespace(o.Namespace) {
+			return nil, nil
+		}
+		name = "ingress"
+	case *endpoint.DNSEndpoint:
+		if !cfg.watchesNamespace(o.Namespace) || !selector.Matches(labels.Set(o.Labels)) {
+			return nil, nil
+		}
+		annotationSelector, err := getLabelSelector(cfg.AnnotationFilter)
+		if err != nil {
+			return nil, err
+		}
+		if !matchLabelSelector(annotationSelector, o.Annotations) {
+			return nil, nil
+		}
+		if controller, ok := o.Annotations[controllerAnnotationKey]; ok && controller != controllerAnnotationValue {
+			return nil, nil
+		}
+		return endpointsFromDNSEndpoint(o.DeepCopy(), cfg.PassthroughRecordTypes), nil
+	default:
+		return nil, fmt.Errorf("unsupported object %T: only Services, Ingresses and DNSEndpoints are supported", obj)
+	}
+
+	// the informers of the source stop with the context
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	objCfg := *cfg
+	objCfg.Namespace = ""
+	objCfg.Namespaces = nil
+	objCfg.LabelFilter = selector
+	objCfg.FieldFilter = fields.Everything()
+	src, err := BuildWithConfig(ctx, name, &objectClientGenerator{obj: obj}, &objCfg)
+	if err != nil {
+		return nil, err
+	}
+	return src.Endpoints(ctx)
+}
+
+// objectClientGenerator provides the clients of a cluster holding a single object, and none of
+// the resources of the other APIs.
+type objectClientGenerator struct {
+	obj runtime.Object
+}
+
+func (g *objectClientGenerator) KubeClient() (kubernetes.Interface, error) {
+	return fake.NewSimpleClientset(g.obj), nil
+}
+
+func (g *objectClientGenerator) DynamicKubernetesClient() (dynamic.Interface, error) {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		metalLBIPAddressPoolsGVR:    "IPAddressPoolList",
+		metalLBL2AdvertisementsGVR:  "L2AdvertisementList",
+		metalLBBGPAdvertisementsGVR: "BGPAdvertisementList",
+		metalLBServiceL2StatusesGVR: "ServiceL2StatusList",
+	}), nil
+}
+
+func (g *objectClientGenerator) GatewayClient() (gateway.Interface, error) {
+	return nil, errObjectClient
+}
+
+func (g *objectClientGenerator) IstioClient() (istioclient.Interface, error) {
+	return nil, errObjectClient
+}
+
+func (g *objectClientGenerator) CloudFoundryClient(string, string, string) (*cfclient.Client, error) {
+	return nil, errObjectClient
+}
+
+func (g *objectClientGenerator) OpenShiftClient() (openshift.Interface, error) {
+	return nil, errObjectClient
+}
+
+var errObjectClient = errors.New("the client isn't available to generate the endpoints of a single object")
+
+// watchesNamespace returns whether the sources watch the resources of the namespace.
+func (cfg *Config) watchesNamespace(namespace string) bool {
+	for _, excluded := range cfg.ExcludeNamespaces {
+		if excluded == namespace {
+			return false
+		}
+	}
+	for _, watched := range cfg.namespaces() {
+		if watched == "" || watched == namespace {
+			return true
+		}
+	}
+	return false
+}
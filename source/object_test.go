@@ -0,0 +1,125 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	networkv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func TestEndpointsForObjectService(t *testing.T) {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        "app",
+			Annotations: map[string]string{hostnameAnnotationKey: "app.example.org", ttlAnnotationKey: "60"},
+		},
+		Spec: v1.ServiceSpec{Type: v1.ServiceTypeLoadBalancer},
+		Status: v1.ServiceStatus{
+			LoadBalancer: v1.LoadBalancerStatus{Ingress: []v1.LoadBalancerIngress{{IP: "1.2.3.4"}}},
+		},
+	}
+
+	endpoints, err := EndpointsForObject(context.Background(), svc, &Config{})
+	require.NoError(t, err)
+	expected := endpoint.NewEndpointWithTTL("app.example.org", endpoint.RecordTypeA, 60, "1.2.3.4")
+	expected.Labels[endpoint.ResourceLabelKey] = "service/default/app"
+	validateEndpoints(t, endpoints, []*endpoint.Endpoint{expected})
+
+	for _, cfg := range []*Config{
+		{Namespace: "other"},
+		{ExcludeNamespaces: []string{"default"}},
+		{AnnotationFilter: "team=a"},
+		{ServiceTypeFilter: []string{string(v1.ServiceTypeClusterIP)}},
+		{LoadBalancerClassFilter: []string{"internal"}},
+	} {
+		endpoints, err := EndpointsForObject(context.Background(), svc, cfg)
+		require.NoError(t, err)
+		assert.Empty(t, endpoints, "%+v", cfg)
+	}
+}
+
+func TestEndpointsForObjectServiceExternalIPs(t *testing.T) {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        "app",
+			Annotations: map[string]string{hostnameAnnotationKey: "app.example.org"},
+		},
+		Spec: v1.ServiceSpec{Type: v1.ServiceTypeLoadBalancer, ExternalIPs: []string{"5.6.7.8"}},
+		Status: v1.ServiceStatus{
+			LoadBalancer: v1.LoadBalancerStatus{Ingress: []v1.LoadBalancerIngress{{IP: "1.2.3.4"}}},
+		},
+	}
+
+	endpoints, err := EndpointsForObject(context.Background(), svc, &Config{PublishExternalIPs: true})
+	require.NoError(t, err)
+	expected := endpoint.NewEndpoint("app.example.org", endpoint.RecordTypeA, "5.6.7.8")
+	expected.Labels[endpoint.ResourceLabelKey] = "service/default/app"
+	validateEndpoints(t, endpoints, []*endpoint.Endpoint{expected})
+}
+
+func TestEndpointsForObjectIngress(t *testing.T) {
+	ing := &networkv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+		Spec:       networkv1.IngressSpec{Rules: []networkv1.IngressRule{{Host: "web.example.org"}}},
+		Status: networkv1.IngressStatus{
+			LoadBalancer: networkv1.IngressLoadBalancerStatus{Ingress: []networkv1.IngressLoadBalancerIngress{{Hostname: "lb.example.org"}}},
+		},
+	}
+
+	endpoints, err := EndpointsForObject(context.Background(), ing, &Config{Namespaces: []string{"default"}})
+	require.NoError(t, err)
+	expected := endpoint.NewEndpoint("web.example.org", endpoint.RecordTypeCNAME, "lb.example.org")
+	expected.Labels[endpoint.ResourceLabelKey] = "ingress/default/web"
+	validateEndpoints(t, endpoints, []*endpoint.Endpoint{expected})
+
+	endpoints, err = EndpointsForObject(context.Background(), ing, &Config{IngressHostnameSource: IngressHostnameSourceAnnotationOnlyValue})
+	require.NoError(t, err)
+	assert.Empty(t, endpoints)
+}
+
+func TestEndpointsForObjectDNSEndpoint(t *testing.T) {
+	d := &endpoint.DNSEndpoint{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "records"},
+		Spec: endpoint.DNSEndpointSpec{Endpoints: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("a.example.org", endpoint.RecordTypeA, "1.1.1.1"),
+			endpoint.NewEndpoint("b.example.org", endpoint.RecordTypeA),
+		}},
+	}
+
+	endpoints, err := EndpointsForObject(context.Background(), d, &Config{})
+	require.NoError(t, err)
+	expected := endpoint.NewEndpoint("a.example.org", endpoint.RecordTypeA, "1.1.1.1")
+	expected.Labels[endpoint.ResourceLabelKey] = "crd/default/records"
+	validateEndpoints(t, endpoints, []*endpoint.Endpoint{expected})
+	// the object is not modified
+	assert.Empty(t, d.Spec.Endpoints[0].Labels[endpoint.ResourceLabelKey])
+}
+
+func TestEndpointsForObjectUnsupported(t *testing.T) {
+	_, err := EndpointsForObject(context.Background(), &v1.Pod{}, &Config{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// pairingTemplateData is the data the apex pairing template is executed with.
+type pairingTemplateData struct {
+	Apex string
+}
+
+// pairingSource is a Source that pairs the apex hostnames of its wrapped source with another
+// hostname, e.g. www.example.org with example.org, so that both are published when either is.
+type pairingSource struct {
+	source Source
+	// paired maps the apex hostnames to their paired hostname
+	paired map[string]string
+}
+
+// NewPairingSource creates a new pairingSource wrapping the provided Source. The apexes are the
+// domains of the domain filter, and the hostname paired with each of them is the result of the
+// template, e.g. "www.{{ .Apex }}".
+func NewPairingSource(source Source, apexes []string, pairingTemplate string) (Source, error) {
+	tmpl, err := parseTemplate(pairingTemplate)
+	if err != nil {
+		return nil, err
+	}
+	if tmpl == nil {
+		return source, nil
+	}
+	paired := map[string]string{}
+	for _, apex := range apexes {
+		// the domains starting with a dot match their subdomains only, so they have no apex
+		apex = strings.ToLower(strings.TrimSuffix(apex, "."))
+		if apex == "" || strings.HasPrefix(apex, ".") {
+			continue
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, pairingTemplateData{Apex: apex}); err != nil {
+			return nil, fmt.Errorf("failed to apply the apex pairing template on %s: %w", apex, err)
+		}
+		hostname := strings.ToLower(strings.TrimSuffix(strings.TrimSpace(buf.String()), "."))
+		if hostname == "" || hostname == apex {
+			return nil, fmt.Errorf("the apex pairing template pairs %s with %q", apex, hostname)
+		}
+		paired[apex] = hostname
+	}
+	return &pairingSource{source: source, paired: paired}, nil
+}
+
+// Endpoints collects endpoints from its wrapped source and adds the endpoints of the hostnames
+// paired with them which are not requested: a CNAME record of the paired hostname pointing at
+// the apex, or the A and AAAA records of the apex with the targets of the paired hostname, since
+// there can be no CNAME record at the apex. The added endpoints are owned by the same resource.
+func (ps *pairingSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ps.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	requested := map[string]bool{}
+	for _, ep := range endpoints {
+		requested[strings.ToLower(ep.DNSName)] = true
+	}
+
+	added := map[endpoint.EndpointKey]bool{}
+	result := append([]*endpoint.Endpoint{}, endpoints...)
+	for _, ep := range endpoints {
+		dnsName := strings.ToLower(ep.DNSName)
+		for apex, hostname := range ps.paired {
+			var pairedEndpoint *endpoint.Endpoint
+			switch {
+			case dnsName == apex && !requested[hostname]:
+				pairedEndpoint = endpoint.NewEndpointWithTTL(hostname, endpoint.RecordTypeCNAME, ep.RecordTTL, ep.DNSName)
+			case dnsName == hostname && !requested[apex]:
+				if ep.RecordType != endpoint.RecordTypeA && ep.RecordType != endpoint.RecordTypeAAAA {
+					log.Debugf("Not pairing %s with the apex %s because its %s record can't be at the apex", ep.DNSName, apex, ep.RecordType)
+					continue
+				}
+				pairedEndpoint = endpoint.NewEndpointWithTTL(apex, ep.RecordType, ep.RecordTTL, ep.Targets...)
+			default:
+				continue
+			}
+			pairedEndpoint.SetIdentifier = ep.SetIdentifier
+			pairedEndpoint.ProviderSpecific = ep.ProviderSpecific
+			if resource, ok := ep.Labels[endpoint.ResourceLabelKey]; ok {
+				pairedEndpoint.Labels[endpoint.ResourceLabelKey] = resource
+			}
+
+			key := pairedEndpoint.Key()
+			if added[key] {
+				continue
+			}
+			added[key] = true
+			log.Debugf("Pairing %s with %s", ep.DNSName, pairedEndpoint.DNSName)
+			result = append(result, pairedEndpoint)
+		}
+	}
+
+	return result, nil
+}
+
+func (ps *pairingSource) AddEventHandler(ctx context.Context, handler func()) {
+	ps.source.AddEventHandler(ctx, handler)
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func TestPairingSourceEndpoints(t *testing.T) {
+	withResource := func(ep *endpoint.Endpoint, resource string) *endpoint.Endpoint {
+		ep.Labels[endpoint.ResourceLabelKey] = resource
+		return ep
+	}
+
+	for _, tt := range []struct {
+		title     string
+		endpoints []*endpoint.Endpoint
+		expected  []*endpoint.Endpoint
+	}{
+		{
+			title: "apex paired with www",
+			endpoints: []*endpoint.Endpoint{
+				withResource(endpoint.NewEndpointWithTTL("example.org", endpoint.RecordTypeA, 300, "1.2.3.4"), "ingress/default/web"),
+				withResource(endpoint.NewEndpointWithTTL("example.org", endpoint.RecordTypeAAAA, 300, "2001:db8::1"), "ingress/default/web"),
+			},
+			expected: []*endpoint.Endpoint{
+				withResource(endpoint.NewEndpointWithTTL("example.org", endpoint.RecordTypeA, 300, "1.2.3.4"), "ingress/default/web"),
+				withResource(endpoint.NewEndpointWithTTL("example.org", endpoint.RecordTypeAAAA, 300, "2001:db8::1"), "ingress/default/web"),
+				withResource(endpoint.NewEndpointWithTTL("www.example.org", endpoint.RecordTypeCNAME, 300, "example.org"), "ingress/default/web"),
+			},
+		},
+		{
+			title: "www paired with apex",
+			endpoints: []*endpoint.Endpoint{
+				endpoint.NewEndpoint("www.example.com", endpoint.RecordTypeA, "1.2.3.4"),
+			},
+			expected: []*endpoint.Endpoint{
+				endpoint.NewEndpoint("www.example.com", endpoint.RecordTypeA, "1.2.3.4"),
+				endpoint.NewEndpoint("example.com", endpoint.RecordTypeA, "1.2.3.4"),
+			},
+		},
+		{
+			title: "www CNAME not paired with apex",
+			endpoints: []*endpoint.Endpoint{
+				endpoint.NewEndpoint("www.example.com", endpoint.RecordTypeCNAME, "lb.example.net"),
+			},
+			expected: []*endpoint.Endpoint{
+				endpoint.NewEndpoint("www.example.com", endpoint.RecordTypeCNAME, "lb.example.net"),
+			},
+		},
+		{
+			title: "both requested",
+			endpoints: []*endpoint.Endpoint{
+				endpoint.NewEndpoint("example.org", endpoint.RecordTypeA, "1.2.3.4"),
+				endpoint.NewEndpoint("www.example.org", endpoint.RecordTypeA, "1.2.3.5"),
+			},
+			expected: []*endpoint.Endpoint{
+				endpoint.NewEndpoint("example.org", endpoint.RecordTypeA, "1.2.3.4"),
+				endpoint.NewEndpoint("www.example.org", endpoint.RecordTypeA, "1.2.3.5"),
+			},
+		},
+		{
+			title: "subdomains not paired",
+			endpoints: []*endpoint.Endpoint{
+				endpoint.NewEndpoint("app.example.org", endpoint.RecordTypeA, "1.2.3.4"),
+				endpoint.NewEndpoint("sub.example.net", endpoint.RecordTypeA, "1.2.3.4"),
+			},
+			expected: []*endpoint.Endpoint{
+				endpoint.NewEndpoint("app.example.org", endpoint.RecordTypeA, "1.2.3.4"),
+				endpoint.NewEndpoint("sub.example.net", endpoint.RecordTypeA, "1.2.3.4"),
+			},
+		},
+	} {
+		t.Run(tt.title, func(t *testing.T) {
+			src, err := NewPairingSource(NewEchoSource(tt.endpoints), []string{"example.org", "Example.com.", ".example.net"}, "www.{{ .Apex }}")
+			require.NoError(t, err)
+
+			endpoints, err := src.Endpoints(context.Background())
+			require.NoError(t, err)
+			validateEndpoints(t, endpoints, tt.expected)
+		})
+	}
+}
+
+func TestNewPairingSource(t *testing.T) {
+	echo := NewEchoSource(nil)
+
+	src, err := NewPairingSource(echo, []string{"example.org"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, echo, src)
+
+	_, err = NewPairingSource(echo, []string{"example.org"}, "{{ .Apex")
+	assert.Error(t, err)
+
+	_, err = NewPairingSource(echo, []string{"example.org"}, "{{ .Apex }}.")
+	assert.EqualError(t, err, `the apex pairing template pairs example.org with "example.org"`)
+}
@@ -0,0 +1,258 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+)
+
+var sourceMissingPermissions = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: "external_dns",
+		Subsystem: "source",
+		Name:      "missing_permissions",
+		Help:      "Number of permissions to list and watch the Kubernetes resources a source is missing, as verified at startup.",
+	},
+	[]string{"source"},
+)
+
+func init() {
+	prometheus.MustRegister(sourceMissingPermissions)
+}
+
+// watchVerbs are the verbs the informers of the sources need on their resources.
+var watchVerbs = []string{"list", "watch"}
+
+// Permission is a permission a source needs on a Kubernetes resource. The resource is cluster-wide
+// when the namespace is empty.
+type Permission struct {
+	Source    string
+	Verb      string
+	Group     string
+	Resource  string
+	Namespace string
+}
+
+func (p Permission) String() string {
+	resource := p.Resource
+	if p.Group != "" {
+		resource += "." + p.Group
+	}
+	if p.Namespace == "" {
+		return fmt.Sprintf("%s %s in all namespaces", p.Verb, resource)
+	}
+	return fmt.Sprintf("%s %s in namespace %s", p.Verb, resource, p.Namespace)
+}
+
+// sourceResource is a resource read by a source.
+type sourceResource struct {
+	schema.GroupResource
+	// clusterScoped resources are checked cluster-wide, whatever the namespace of the source.
+	clusterScoped bool
+	// namespace overrides the namespace of the source, e.g. for the gateways of --gateway-namespace.
+	namespace *string
+}
+
+// sourceResources returns the Kubernetes resources the source of the name reads, or nil for the
+// sources which read no Kubernetes resources. The resource of the crd source depends on its kind
+// and is resolved by VerifyPermissions.
+func sourceResources(name string, cfg *Config) []sourceResource {
+	var (
+		services   = sourceResource{GroupResource: schema.GroupResource{Resource: "services"}}
+		pods       = sourceResource{GroupResource: schema.GroupResource{Resource: "pods"}}
+		nodes      = sourceResource{GroupResource: schema.GroupResource{Resource: "nodes"}, clusterScoped: true}
+		gateways   = sourceResource{GroupResource: schema.GroupResource{Group: "gateway.networking.k8s.io", Resource: "gateways"}, namespace: &cfg.GatewayNamespace}
+		namespaces = sourceResource{GroupResource: schema.GroupResource{Resource: "namespaces"}, clusterScoped: true}
+	)
+	resource := func(group, resource string) sourceResource {
+		return sourceResource{GroupResource: schema.GroupResource{Group: group, Resource: resource}}
+	}
+
+	switch name {
+	case "node":
+		return []sourceResource{nodes}
+	case "service":
+		resources := []sourceResource{services, resource("", "endpoints"), pods, nodes}
+		if cfg.MetalLB {
+			for _, gvr := range []schema.GroupVersionResource{metalLBIPAddressPoolsGVR, metalLBL2AdvertisementsGVR, metalLBBGPAdvertisementsGVR, metalLBServiceL2StatusesGVR} {
+				resources = append(resources, sourceResource{GroupResource: gvr.GroupResource(), namespace: &cfg.MetalLBNamespace})
+			}
+		}
+		return resources
+	case "ingress":
+		return []sourceResource{resource("networking.k8s.io", "ingresses")}
+	case "pod":
+		return []sourceResource{pods, nodes}
+	case "gateway-httproute":
+		return []sourceResource{gateways, resource("gateway.networking.k8s.io", "httproutes"), namespaces}
+	case "gateway-grpcroute":
+		return []sourceResource{gateways, resource("gateway.networking.k8s.io", "grpcroutes"), namespaces}
+	case "gateway-tlsroute":
+		return []sourceResource{gateways, resource("gateway.networking.k8s.io", "tlsroutes"), namespaces}
+	case "gateway-tcproute":
+		return []sourceResource{gateways, resource("gateway.networking.k8s.io", "tcproutes"), namespaces}
+	case "gateway-udproute":
+		return []sourceResource{gateways, resource("gateway.networking.k8s.io", "udproutes"), namespaces}
+	case "gateway-route":
+		resources := []sourceResource{gateways, namespaces}
+		for _, rt := range cfg.GatewayRouteTypes {
+			resources = append(resources, resource(rt.Resource.Group, rt.Resource.Resource))
+		}
+		return resources
+	case "istio-gateway":
+		return []sourceResource{services, resource("networking.istio.io", "gateways")}
+	case "istio-virtualservice":
+		return []sourceResource{services, resource("networking.istio.io", "virtualservices"), resource("networking.istio.io", "gateways")}
+	case "ambassador-host":
+		return []sourceResource{resource(ambHostGVR.Group, ambHostGVR.Resource)}
+	case "contour-httpproxy":
+		return []sourceResource{resource("projectcontour.io", "httpproxies")}
+	case "gloo-proxy":
+		return []sourceResource{resource(proxyGVR.Group, proxyGVR.Resource), resource(virtualServiceGVR.Group, virtualServiceGVR.Resource)}
+	case "traefik-proxy":
+		var resources []sourceResource
+		if !cfg.TraefikDisableNew {
+			resources = append(resources, resource(ingressrouteGVR.Group, ingressrouteGVR.Resource), resource(ingressrouteTCPGVR.Group, ingressrouteTCPGVR.Resource), resource(ingressrouteUDPGVR.Group, ingressrouteUDPGVR.Resource))
+		}
+		if !cfg.TraefikDisableLegacy {
+			resources = append(resources, resource(oldIngressrouteGVR.Group, oldIngressrouteGVR.Resource), resource(oldIngressrouteTCPGVR.Group, oldIngressrouteTCPGVR.Resource), resource(oldIngressrouteUDPGVR.Group, oldIngressrouteUDPGVR.Resource))
+		}
+		return resources
+	case "openshift-route":
+		return []sourceResource{resource("route.openshift.io", "routes")}
+	case "skipper-routegroup":
+		group, _, _ := strings.Cut(cfg.SkipperRouteGroupVersion, "/")
+		return []sourceResource{resource(group, "routegroups")}
+	case "kong-tcpingress":
+		return []sourceResource{resource(kongGroupdVersionResource.Group, kongGroupdVersionResource.Resource)}
+	case "f5-virtualserver":
+		return []sourceResource{resource(f5VirtualServerGVR.Group, f5VirtualServerGVR.Resource)}
+	case "generic-crd":
+		resources := make([]sourceResource, 0, len(cfg.GenericCRDMappings))
+		for _, mapping := range cfg.GenericCRDMappings {
+			resources = append(resources, resource(mapping.Resource.Group, mapping.Resource.Resource))
+		}
+		return resources
+	case "cutover":
+		return []sourceResource{resource(cutoverGVR.Group, cutoverGVR.Resource)}
+	case "zone-delegation":
+		return []sourceResource{resource(zoneDelegationGVR.Group, zoneDelegationGVR.Resource)}
+	case "verification":
+		return []sourceResource{resource(verificationGVR.Group, verificationGVR.Resource)}
+	}
+	return nil
+}
+
+// VerifyPermissions checks with SelfSubjectAccessReviews that ExternalDNS can list and watch every
+// Kubernetes resource the sources of the names read, in each of their namespaces, and returns the
+// missing permissions. They are also reported by the external_dns_source_missing_permissions metric,
+// under the names of the sources of ByNames.
+func VerifyPermissions(ctx context.Context, client kubernetes.Interface, names []string, cfg *Config) ([]Permission, error) {
+	var missing []Permission
+	for _, name := range names {
+		resources := sourceResources(name, cfg)
+		if name == "crd" {
+			gr, err := crdResource(client, cfg.CRDSourceAPIVersion, cfg.CRDSourceKind)
+			if err != nil {
+				return nil, err
+			}
+			resources = append(resources, sourceResource{GroupResource: gr})
+		}
+		if len(resources) == 0 {
+			continue
+		}
+
+		namespaces := []string{""}
+		if namespacedSources[name] {
+			namespaces = cfg.namespaces()
+		}
+		for _, namespace := range namespaces {
+			source := name
+			if namespace != "" {
+				source = name + "/" + namespace
+			}
+			count := 0
+			for _, r := range resources {
+				ns := namespace
+				switch {
+				case r.clusterScoped:
+					ns = ""
+				case r.namespace != nil:
+					ns = *r.namespace
+				}
+				for _, verb := range watchVerbs {
+					p := Permission{Source: source, Verb: verb, Group: r.Group, Resource: r.Resource, Namespace: ns}
+					ok, err := allowed(ctx, client, p)
+					if err != nil {
+						return nil, err
+					}
+					if !ok {
+						missing = append(missing, p)
+						count++
+					}
+				}
+			}
+			sourceMissingPermissions.WithLabelValues(source).Set(float64(count))
+		}
+	}
+	return missing, nil
+}
+
+// allowed reviews whether ExternalDNS has the permission.
+func allowed(ctx context.Context, client kubernetes.Interface, p Permission) (bool, error) {
+	review := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: p.Namespace,
+				Verb:      p.Verb,
+				Group:     p.Group,
+				Resource:  p.Resource,
+			},
+		},
+	}
+	review, err := client.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return false, fmt.Errorf("failed to review the permission to %s for the source %s: %w", p, p.Source, err)
+	}
+	return review.Status.Allowed, nil
+}
+
+// crdResource discovers the resource of the kind of the crd source.
+func crdResource(client kubernetes.Interface, apiVersion, kind string) (schema.GroupResource, error) {
+	groupVersion, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return schema.GroupResource{}, err
+	}
+	resources, err := client.Discovery().ServerResourcesForGroupVersion(groupVersion.String())
+	if err != nil {
+		return schema.GroupResource{}, err
+	}
+	for _, r := range resources.APIResources {
+		if r.Kind == kind && !strings.Contains(r.Name, "/") {
+			return schema.GroupResource{Group: groupVersion.Group, Resource: r.Name}, nil
+		}
+	}
+	return schema.GroupResource{}, fmt.Errorf("unable to find Resource Kind %q in GroupVersion %q", kind, apiVersion)
+}
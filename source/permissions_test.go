@@ -0,0 +1,69 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestVerifyPermissions(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	var reviewed []authorizationv1.ResourceAttributes
+	client.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		attributes := review.Spec.ResourceAttributes
+		reviewed = append(reviewed, *attributes)
+		// the nodes can only be listed, and the ingresses only read in the namespace team-a
+		review.Status.Allowed = (attributes.Resource != "nodes" || attributes.Verb == "list") &&
+			(attributes.Resource != "ingresses" || attributes.Namespace == "team-a")
+		return true, review, nil
+	})
+
+	missing, err := VerifyPermissions(context.Background(), client, []string{"node", "ingress", "fake"}, &Config{Namespaces: []string{"team-a", "team-b"}})
+	require.NoError(t, err)
+	assert.Equal(t, []Permission{
+		{Source: "node", Verb: "watch", Resource: "nodes"},
+		{Source: "ingress/team-b", Verb: "list", Group: "networking.k8s.io", Resource: "ingresses", Namespace: "team-b"},
+		{Source: "ingress/team-b", Verb: "watch", Group: "networking.k8s.io", Resource: "ingresses", Namespace: "team-b"},
+	}, missing)
+	assert.Len(t, reviewed, 6)
+	assert.Equal(t, 1.0, gaugeValue(t, sourceMissingPermissions.WithLabelValues("node")))
+	assert.Zero(t, gaugeValue(t, sourceMissingPermissions.WithLabelValues("ingress/team-a")))
+	assert.Equal(t, 2.0, gaugeValue(t, sourceMissingPermissions.WithLabelValues("ingress/team-b")))
+	assert.Equal(t, "list ingresses.networking.k8s.io in namespace team-b", missing[1].String())
+	assert.Equal(t, "watch nodes in all namespaces", missing[0].String())
+}
+
+func TestSourceResources(t *testing.T) {
+	cfg := &Config{GatewayNamespace: "gateways", TraefikDisableLegacy: true}
+
+	resources := sourceResources("gateway-httproute", cfg)
+	require.Len(t, resources, 3)
+	assert.Equal(t, "gateways", *resources[0].namespace)
+	assert.True(t, resources[2].clusterScoped)
+
+	assert.Len(t, sourceResources("traefik-proxy", cfg), 3)
+	assert.Nil(t, sourceResources("connector", cfg))
+}
@@ -23,6 +23,7 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	kubeinformers "k8s.io/client-go/informers"
 	coreinformers "k8s.io/client-go/informers/core/v1"
@@ -39,9 +40,11 @@ type podSource struct {
 }
 
 // NewPodSource creates a new podSource with the given config.
-func NewPodSource(ctx context.Context, kubeClient kubernetes.Interface, namespace string, compatibility string) (Source, error) {
+func NewPodSource(ctx context.Context, kubeClient kubernetes.Interface, namespace string, compatibility string, labelSelector labels.Selector, fieldSelector fields.Selector) (Source, error) {
+	// The pods are filtered by the API server, the nodes they run on aren't.
+	podInformerFactory := kubeinformers.NewSharedInformerFactoryWithOptions(kubeClient, 0, kubeinformers.WithNamespace(namespace), kubeinformers.WithTweakListOptions(newListOptionsTweak(labelSelector, fieldSelector)))
 	informerFactory := kubeinformers.NewSharedInformerFactoryWithOptions(kubeClient, 0, kubeinformers.WithNamespace(namespace))
-	podInformer := informerFactory.Core().V1().Pods()
+	podInformer := podInformerFactory.Core().V1().Pods()
 	nodeInformer := informerFactory.Core().V1().Nodes()
 
 	podInformer.Informer().AddEventHandler(
@@ -57,9 +60,13 @@ func NewPodSource(ctx context.Context, kubeClient kubernetes.Interface, namespac
 		},
 	)
 
+	podInformerFactory.Start(ctx.Done())
 	informerFactory.Start(ctx.Done())
 
 	// wait for the local cache to be populated.
+	if err := waitForCacheSync(context.Background(), podInformerFactory); err != nil {
+		return nil, err
+	}
 	if err := waitForCacheSync(context.Background(), informerFactory); err != nil {
 		return nil, err
 	}
@@ -89,6 +96,13 @@ func (ps *podSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error
 			continue
 		}
 
+		// Check controller annotation to see if we are responsible.
+		if controller, ok := pod.Annotations[controllerAnnotationKey]; ok && controller != controllerAnnotationValue {
+			log.Debugf("Skipping pod %s/%s because controller value does not match, found: %s, required: %s",
+				pod.Namespace, pod.Name, controller, controllerAnnotationValue)
+			continue
+		}
+
 		targets := getTargetsFromTargetAnnotation(pod.Annotations)
 
 		if domainAnnotation, ok := pod.Annotations[internalHostnameAnnotationKey]; ok {
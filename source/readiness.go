@@ -0,0 +1,160 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// readinessStateTTL is how long the readiness of a pod or node no longer seen is remembered.
+const readinessStateTTL = time.Hour
+
+// ReadinessCondition is a condition a pod or node must have, with the status, for its targets to
+// be published.
+type ReadinessCondition struct {
+	Type   string
+	Status v1.ConditionStatus
+}
+
+// ParseReadinessCondition parses a condition of the form "type", which must be True, or
+// "type=status", e.g. "Ready", "example.com/gate" or "NetworkUnavailable=False".
+func ParseReadinessCondition(s string) (ReadinessCondition, error) {
+	conditionType, status, found := strings.Cut(s, "=")
+	if conditionType == "" {
+		return ReadinessCondition{}, fmt.Errorf("invalid readiness condition %q: expected type or type=status", s)
+	}
+	if !found {
+		return ReadinessCondition{Type: conditionType, Status: v1.ConditionTrue}, nil
+	}
+	switch v1.ConditionStatus(status) {
+	case v1.ConditionTrue, v1.ConditionFalse, v1.ConditionUnknown:
+		return ReadinessCondition{Type: conditionType, Status: v1.ConditionStatus(status)}, nil
+	}
+	return ReadinessCondition{}, fmt.Errorf("invalid status %q of readiness condition %q: expected True, False or Unknown", status, s)
+}
+
+// TargetReadiness excludes the targets of the pods of headless services and of the nodes of
+// NodePort services which don't have all the readiness conditions, e.g. the custom conditions of
+// readiness gates. A pod or node is published as soon as it is seen, but its readiness changes are
+// only published once they have lasted for the dampening, so that flapping conditions don't make
+// the records flap with them.
+type TargetReadiness struct {
+	PodConditions  []ReadinessCondition
+	NodeConditions []ReadinessCondition
+	Dampening      time.Duration
+
+	now    func() time.Time
+	mu     sync.Mutex
+	states map[types.UID]*readinessState
+	pruned time.Time
+}
+
+// readinessState is the published readiness of a pod or node.
+type readinessState struct {
+	ready bool
+	// changedSince is when the observed readiness started to differ from the published one.
+	changedSince time.Time
+	lastSeen     time.Time
+}
+
+// NewTargetReadiness returns the readiness of the pods and nodes with the conditions, whose
+// changes are published after the dampening.
+func NewTargetReadiness(podConditions, nodeConditions []ReadinessCondition, dampening time.Duration) *TargetReadiness {
+	return &TargetReadiness{
+		PodConditions:  podConditions,
+		NodeConditions: nodeConditions,
+		Dampening:      dampening,
+		now:            time.Now,
+		states:         map[types.UID]*readinessState{},
+	}
+}
+
+// PodReady reports whether the targets of the pod are published. They always are without
+// readiness, or without pod conditions.
+func (r *TargetReadiness) PodReady(pod *v1.Pod) bool {
+	if r == nil || len(r.PodConditions) == 0 {
+		return true
+	}
+	statuses := make(map[string]v1.ConditionStatus, len(pod.Status.Conditions))
+	for _, c := range pod.Status.Conditions {
+		statuses[string(c.Type)] = c.Status
+	}
+	return r.ready("pod "+pod.Namespace+"/"+pod.Name, pod.UID, r.PodConditions, statuses)
+}
+
+// NodeReady reports whether the targets of the node are published. They always are without
+// readiness, or without node conditions.
+func (r *TargetReadiness) NodeReady(node *v1.Node) bool {
+	if r == nil || len(r.NodeConditions) == 0 {
+		return true
+	}
+	statuses := make(map[string]v1.ConditionStatus, len(node.Status.Conditions))
+	for _, c := range node.Status.Conditions {
+		statuses[string(c.Type)] = c.Status
+	}
+	return r.ready("node "+node.Name, node.UID, r.NodeConditions, statuses)
+}
+
+// ready returns the published readiness of the object, given the statuses of its conditions. A
+// missing condition isn't met, like the readiness gates of the pods.
+func (r *TargetReadiness) ready(name string, uid types.UID, conditions []ReadinessCondition, statuses map[string]v1.ConditionStatus) bool {
+	observed := true
+	for _, c := range conditions {
+		if statuses[c.Type] != c.Status {
+			observed = false
+			break
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	if now.Sub(r.pruned) > readinessStateTTL {
+		for id, state := range r.states {
+			if now.Sub(state.lastSeen) > readinessStateTTL {
+				delete(r.states, id)
+			}
+		}
+		r.pruned = now
+	}
+
+	state, ok := r.states[uid]
+	if !ok {
+		r.states[uid] = &readinessState{ready: observed, lastSeen: now}
+		return observed
+	}
+	state.lastSeen = now
+	switch {
+	case observed == state.ready:
+		state.changedSince = time.Time{}
+	case state.changedSince.IsZero() && r.Dampening > 0:
+		state.changedSince = now
+		log.Debugf("The readiness of the %s changed to %t, it is published once it lasts for %s", name, observed, r.Dampening)
+	case now.Sub(state.changedSince) >= r.Dampening:
+		log.Infof("The readiness of the %s changed to %t", name, observed)
+		state.ready = observed
+		state.changedSince = time.Time{}
+	}
+	return state.ready
+}
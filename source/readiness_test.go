@@ -0,0 +1,114 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseReadinessCondition(t *testing.T) {
+	for _, tc := range []struct {
+		in       string
+		expected ReadinessCondition
+		err      string
+	}{
+		{in: "Ready", expected: ReadinessCondition{Type: "Ready", Status: v1.ConditionTrue}},
+		{in: "example.com/gate", expected: ReadinessCondition{Type: "example.com/gate", Status: v1.ConditionTrue}},
+		{in: "NetworkUnavailable=False", expected: ReadinessCondition{Type: "NetworkUnavailable", Status: v1.ConditionFalse}},
+		{in: "", err: "expected type or type=status"},
+		{in: "=True", err: "expected type or type=status"},
+		{in: "Ready=yes", err: "expected True, False or Unknown"},
+	} {
+		t.Run(tc.in, func(t *testing.T) {
+			condition, err := ParseReadinessCondition(tc.in)
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, condition)
+		})
+	}
+}
+
+func readinessTestPod(gate v1.ConditionStatus) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web-0", UID: "web-0"},
+		Status: v1.PodStatus{Conditions: []v1.PodCondition{
+			{Type: v1.PodReady, Status: v1.ConditionTrue},
+			{Type: "example.com/gate", Status: gate},
+		}},
+	}
+}
+
+func TestTargetReadinessPodReady(t *testing.T) {
+	var nilReadiness *TargetReadiness
+	assert.True(t, nilReadiness.PodReady(readinessTestPod(v1.ConditionFalse)))
+	assert.True(t, NewTargetReadiness(nil, nil, 0).PodReady(readinessTestPod(v1.ConditionFalse)))
+
+	r := NewTargetReadiness([]ReadinessCondition{{Type: "example.com/gate", Status: v1.ConditionTrue}}, nil, 0)
+	assert.True(t, r.PodReady(readinessTestPod(v1.ConditionTrue)))
+	assert.False(t, r.PodReady(readinessTestPod(v1.ConditionFalse)))
+
+	missing := readinessTestPod(v1.ConditionTrue)
+	missing.UID = "web-1"
+	missing.Status.Conditions = missing.Status.Conditions[:1]
+	assert.False(t, r.PodReady(missing), "a missing condition isn't met")
+}
+
+func TestTargetReadinessNodeReady(t *testing.T) {
+	r := NewTargetReadiness(nil, []ReadinessCondition{{Type: "NetworkUnavailable", Status: v1.ConditionFalse}}, 0)
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1", UID: "node-1"},
+		Status: v1.NodeStatus{Conditions: []v1.NodeCondition{
+			{Type: v1.NodeNetworkUnavailable, Status: v1.ConditionFalse},
+		}},
+	}
+	assert.True(t, r.NodeReady(node))
+	node.Status.Conditions[0].Status = v1.ConditionTrue
+	assert.False(t, r.NodeReady(node))
+	assert.True(t, r.PodReady(readinessTestPod(v1.ConditionFalse)), "pods are ready without pod conditions")
+}
+
+func TestTargetReadinessDampening(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := NewTargetReadiness([]ReadinessCondition{{Type: "example.com/gate", Status: v1.ConditionTrue}}, nil, time.Minute)
+	r.now = func() time.Time { return now }
+
+	assert.True(t, r.PodReady(readinessTestPod(v1.ConditionTrue)), "first seen as observed")
+
+	assert.True(t, r.PodReady(readinessTestPod(v1.ConditionFalse)), "the change hasn't lasted")
+	now = now.Add(30 * time.Second)
+	assert.True(t, r.PodReady(readinessTestPod(v1.ConditionFalse)), "the change hasn't lasted")
+
+	// a flap back resets the change
+	assert.True(t, r.PodReady(readinessTestPod(v1.ConditionTrue)))
+	now = now.Add(30 * time.Second)
+	assert.True(t, r.PodReady(readinessTestPod(v1.ConditionFalse)), "the change starts over")
+	now = now.Add(time.Minute)
+	assert.False(t, r.PodReady(readinessTestPod(v1.ConditionFalse)), "the change lasted")
+
+	// the state of the pods no longer seen is forgotten
+	now = now.Add(2 * readinessStateTTL)
+	assert.True(t, r.PodReady(readinessTestPod(v1.ConditionTrue)), "first seen again as observed")
+}
@@ -27,6 +27,7 @@ import (
 	log "github.com/sirupsen/logrus"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	kubeinformers "k8s.io/client-go/informers"
 	coreinformers "k8s.io/client-go/informers/core/v1"
@@ -65,19 +66,56 @@ type serviceSource struct {
 	nodeInformer                   coreinformers.NodeInformer
 	serviceTypeFilter              map[string]struct{}
 	labelSelector                  labels.Selector
+	targetReadiness                *TargetReadiness
+	excludeDeletingNodes           bool
+	publishExternalIPs             bool
+	loadBalancerClassFilter        map[string]struct{}
+	metalLB                        *MetalLB
+	klipperPolicy                  *KlipperPolicy
+}
+
+// ServiceSourceConfig is the configuration of the service source.
+type ServiceSourceConfig struct {
+	// Namespace of the services, all namespaces if empty.
+	Namespace        string
+	AnnotationFilter string
+	FQDNTemplate     string
+	// CombineFQDNAnnotation combines the hostnames of the template and of the annotations.
+	CombineFQDNAnnotation bool
+	// Compatibility processes the services with legacy annotations, e.g. mate or molecule.
+	Compatibility                  string
+	PublishInternal                bool
+	PublishHostIP                  bool
+	AlwaysPublishNotReadyAddresses bool
+	// ServiceTypeFilter lists the types of the services to publish, all types if empty.
+	ServiceTypeFilter        []string
+	IgnoreHostnameAnnotation bool
+	// LabelSelector and FieldSelector filter the services on the API server, all services if nil.
+	LabelSelector               labels.Selector
+	FieldSelector               fields.Selector
+	ResolveLoadBalancerHostname bool
+	TargetReadiness             *TargetReadiness
+	ExcludeDeletingNodes        bool
+	PublishExternalIPs          bool
+	// LoadBalancerClassFilter lists the load balancer classes of the services to publish, all classes if empty.
+	LoadBalancerClassFilter []string
+	MetalLB                 *MetalLB
+	KlipperPolicy           *KlipperPolicy
 }
 
 // NewServiceSource creates a new serviceSource with the given config.
-func NewServiceSource(ctx context.Context, kubeClient kubernetes.Interface, namespace, annotationFilter string, fqdnTemplate string, combineFqdnAnnotation bool, compatibility string, publishInternal bool, publishHostIP bool, alwaysPublishNotReadyAddresses bool, serviceTypeFilter []string, ignoreHostnameAnnotation bool, labelSelector labels.Selector, resolveLoadBalancerHostname bool) (Source, error) {
-	tmpl, err := parseTemplate(fqdnTemplate)
+func NewServiceSource(ctx context.Context, kubeClient kubernetes.Interface, cfg ServiceSourceConfig) (Source, error) {
+	tmpl, err := parseTemplate(cfg.FQDNTemplate)
 	if err != nil {
 		return nil, err
 	}
 
 	// Use shared informers to listen for add/update/delete of services/pods/nodes in the specified namespace.
 	// Set resync period to 0, to prevent processing when nothing has changed
-	informerFactory := kubeinformers.NewSharedInformerFactoryWithOptions(kubeClient, 0, kubeinformers.WithNamespace(namespace))
-	serviceInformer := informerFactory.Core().V1().Services()
+	// The services are filtered by the API server, the endpoints, pods and nodes behind them aren't.
+	serviceInformerFactory := kubeinformers.NewSharedInformerFactoryWithOptions(kubeClient, 0, kubeinformers.WithNamespace(cfg.Namespace), kubeinformers.WithTweakListOptions(newListOptionsTweak(cfg.LabelSelector, cfg.FieldSelector)))
+	informerFactory := kubeinformers.NewSharedInformerFactoryWithOptions(kubeClient, 0, kubeinformers.WithNamespace(cfg.Namespace))
+	serviceInformer := serviceInformerFactory.Core().V1().Services()
 	endpointsInformer := informerFactory.Core().V1().Endpoints()
 	podInformer := informerFactory.Core().V1().Pods()
 	nodeInformer := informerFactory.Core().V1().Nodes()
@@ -108,9 +146,13 @@ func NewServiceSource(ctx context.Context, kubeClient kubernetes.Interface, name
 		},
 	)
 
+	serviceInformerFactory.Start(ctx.Done())
 	informerFactory.Start(ctx.Done())
 
 	// wait for the local cache to be populated.
+	if err := waitForCacheSync(context.Background(), serviceInformerFactory); err != nil {
+		return nil, err
+	}
 	if err := waitForCacheSync(context.Background(), informerFactory); err != nil {
 		return nil, err
 	}
@@ -118,28 +160,43 @@ func NewServiceSource(ctx context.Context, kubeClient kubernetes.Interface, name
 	// Transform the slice into a map so it will
 	// be way much easier and fast to filter later
 	serviceTypes := make(map[string]struct{})
-	for _, serviceType := range serviceTypeFilter {
+	for _, serviceType := range cfg.ServiceTypeFilter {
 		serviceTypes[serviceType] = struct{}{}
 	}
+	loadBalancerClasses := make(map[string]struct{})
+	for _, class := range cfg.LoadBalancerClassFilter {
+		loadBalancerClasses[class] = struct{}{}
+	}
+
+	labelSelector := cfg.LabelSelector
+	if labelSelector == nil {
+		labelSelector = labels.Everything()
+	}
 
 	return &serviceSource{
 		client:                         kubeClient,
-		namespace:                      namespace,
-		annotationFilter:               annotationFilter,
-		compatibility:                  compatibility,
+		namespace:                      cfg.Namespace,
+		annotationFilter:               cfg.AnnotationFilter,
+		compatibility:                  cfg.Compatibility,
 		fqdnTemplate:                   tmpl,
-		combineFQDNAnnotation:          combineFqdnAnnotation,
-		ignoreHostnameAnnotation:       ignoreHostnameAnnotation,
-		publishInternal:                publishInternal,
-		publishHostIP:                  publishHostIP,
-		alwaysPublishNotReadyAddresses: alwaysPublishNotReadyAddresses,
+		combineFQDNAnnotation:          cfg.CombineFQDNAnnotation,
+		ignoreHostnameAnnotation:       cfg.IgnoreHostnameAnnotation,
+		publishInternal:                cfg.PublishInternal,
+		publishHostIP:                  cfg.PublishHostIP,
+		alwaysPublishNotReadyAddresses: cfg.AlwaysPublishNotReadyAddresses,
 		serviceInformer:                serviceInformer,
 		endpointsInformer:              endpointsInformer,
 		podInformer:                    podInformer,
 		nodeInformer:                   nodeInformer,
 		serviceTypeFilter:              serviceTypes,
 		labelSelector:                  labelSelector,
-		resolveLoadBalancerHostname:    resolveLoadBalancerHostname,
+		resolveLoadBalancerHostname:    cfg.ResolveLoadBalancerHostname,
+		targetReadiness:                cfg.TargetReadiness,
+		excludeDeletingNodes:           cfg.ExcludeDeletingNodes,
+		publishExternalIPs:             cfg.PublishExternalIPs,
+		loadBalancerClassFilter:        loadBalancerClasses,
+		metalLB:                        cfg.MetalLB,
+		klipperPolicy:                  cfg.KlipperPolicy,
 	}, nil
 }
 
@@ -158,6 +215,10 @@ func (sc *serviceSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, e
 	if len(sc.serviceTypeFilter) > 0 {
 		services = sc.filterByServiceType(services)
 	}
+	// filter the LoadBalancer services on their classes if at least one has been provided
+	if len(sc.loadBalancerClassFilter) > 0 {
+		services = sc.filterByLoadBalancerClass(services)
+	}
 
 	endpoints := []*endpoint.Endpoint{}
 
@@ -201,6 +262,9 @@ func (sc *serviceSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, e
 
 		log.Debugf("Endpoints generated from service: %s/%s: %v", svc.Namespace, svc.Name, svcEndpoints)
 		sc.setResourceLabel(svc, svcEndpoints)
+		if !sc.ignoreHostnameAnnotation {
+			svcEndpoints = append(svcEndpoints, aliasEndpoints(svc.Annotations, svcEndpoints, fmt.Sprintf("service/%s/%s", svc.Namespace, svc.Name))...)
+		}
 		endpoints = append(endpoints, svcEndpoints...)
 	}
 
@@ -272,65 +336,80 @@ func (sc *serviceSource) extractHeadlessEndpoints(svc *v1.Service, hostname stri
 	endpointsType := getEndpointsTypeFromAnnotations(svc.Annotations)
 
 	targetsByHeadlessDomainAndType := make(map[endpoint.EndpointKey]endpoint.Targets)
+	// the ready addresses of all the subsets come first, for the targets to be kept first when truncated
+	var addresses []v1.EndpointAddress
 	for _, subset := range endpointsObject.Subsets {
-		addresses := subset.Addresses
-		if svc.Spec.PublishNotReadyAddresses || sc.alwaysPublishNotReadyAddresses {
+		addresses = append(addresses, subset.Addresses...)
+	}
+	if svc.Spec.PublishNotReadyAddresses || sc.alwaysPublishNotReadyAddresses {
+		for _, subset := range endpointsObject.Subsets {
 			addresses = append(addresses, subset.NotReadyAddresses...)
 		}
-
-		for _, address := range addresses {
-			// find pod for this address
-			if address.TargetRef == nil || address.TargetRef.APIVersion != "" || address.TargetRef.Kind != "Pod" {
-				log.Debugf("Skipping address because its target is not a pod: %v", address)
-				continue
-			}
-			var pod *v1.Pod
-			for _, v := range pods {
-				if v.Name == address.TargetRef.Name {
-					pod = v
-					break
-				}
-			}
-			if pod == nil {
-				log.Errorf("Pod %s not found for address %v", address.TargetRef.Name, address)
-				continue
+	}
+	for _, address := range addresses {
+		// find pod for this address
+		if address.TargetRef == nil || address.TargetRef.APIVersion != "" || address.TargetRef.Kind != "Pod" {
+			log.Debugf("Skipping address because its target is not a pod: %v", address)
+			continue
+		}
+		var pod *v1.Pod
+		for _, v := range pods {
+			if v.Name == address.TargetRef.Name {
+				pod = v
+				break
 			}
+		}
+		if pod == nil {
+			log.Errorf("Pod %s not found for address %v", address.TargetRef.Name, address)
+			continue
+		}
+		if !sc.targetReadiness.PodReady(pod) {
+			log.Debugf("Skipping address of pod %s/%s because it doesn't have the readiness conditions", pod.Namespace, pod.Name)
+			continue
+		}
 
-			headlessDomains := []string{hostname}
-			if pod.Spec.Hostname != "" {
-				headlessDomains = append(headlessDomains, fmt.Sprintf("%s.%s", pod.Spec.Hostname, hostname))
-			}
+		headlessDomains := []string{hostname}
+		if pod.Spec.Hostname != "" {
+			headlessDomains = append(headlessDomains, fmt.Sprintf("%s.%s", pod.Spec.Hostname, hostname))
+		}
 
-			for _, headlessDomain := range headlessDomains {
-				targets := getTargetsFromTargetAnnotation(pod.Annotations)
-				if len(targets) == 0 {
-					if endpointsType == EndpointsTypeNodeExternalIP {
-						node, err := sc.nodeInformer.Lister().Get(pod.Spec.NodeName)
-						if err != nil {
-							log.Errorf("Get node[%s] of pod[%s] error: %v; not adding any NodeExternalIP endpoints", pod.Spec.NodeName, pod.GetName(), err)
-							return endpoints
-						}
-						for _, address := range node.Status.Addresses {
-							if address.Type == v1.NodeExternalIP || (address.Type == v1.NodeInternalIP && suitableType(address.Address) == endpoint.RecordTypeAAAA) {
-								targets = append(targets, address.Address)
-								log.Debugf("Generating matching endpoint %s with NodeExternalIP %s", headlessDomain, address.Address)
-							}
+		for _, headlessDomain := range headlessDomains {
+			targets := getTargetsFromTargetAnnotation(pod.Annotations)
+			if len(targets) == 0 {
+				if endpointsType == EndpointsTypeNodeExternalIP {
+					node, err := sc.nodeInformer.Lister().Get(pod.Spec.NodeName)
+					if err != nil {
+						log.Errorf("Get node[%s] of pod[%s] error: %v; not adding any NodeExternalIP endpoints", pod.Spec.NodeName, pod.GetName(), err)
+						return endpoints
+					}
+					if sc.excludeDeletingNodes && nodeDeleting(node) {
+						log.Debugf("Skipping node %s of pod %s because it is being deleted", node.Name, pod.GetName())
+						continue
+					}
+					if !sc.targetReadiness.NodeReady(node) {
+						log.Debugf("Skipping node %s of pod %s because it doesn't have the readiness conditions", node.Name, pod.GetName())
+						continue
+					}
+					for _, address := range node.Status.Addresses {
+						if address.Type == v1.NodeExternalIP || (address.Type == v1.NodeInternalIP && suitableType(address.Address) == endpoint.RecordTypeAAAA) {
+							targets = append(targets, address.Address)
+							log.Debugf("Generating matching endpoint %s with NodeExternalIP %s", headlessDomain, address.Address)
 						}
-					} else if endpointsType == EndpointsTypeHostIP || sc.publishHostIP {
-						targets = endpoint.Targets{pod.Status.HostIP}
-						log.Debugf("Generating matching endpoint %s with HostIP %s", headlessDomain, pod.Status.HostIP)
-					} else {
-						targets = endpoint.Targets{address.IP}
-						log.Debugf("Generating matching endpoint %s with EndpointAddress IP %s", headlessDomain, address.IP)
 					}
+				} else if endpointsType == EndpointsTypeHostIP || sc.publishHostIP {
+					targets = endpoint.Targets{pod.Status.HostIP}
+					log.Debugf("Generating matching endpoint %s with HostIP %s", headlessDomain, pod.Status.HostIP)
+				} else {
+					targets = endpoint.Targets{address.IP}
+					log.Debugf("Generating matching endpoint %s with EndpointAddress IP %s", headlessDomain, address.IP)
 				}
-				for _, target := range targets {
-					key := endpoint.EndpointKey{
-						DNSName:    headlessDomain,
-						RecordType: suitableType(target),
-					}
-					targetsByHeadlessDomainAndType[key] = append(targetsByHeadlessDomainAndType[key], target)
+			}
+			for _, target := range targets {
+				key := endpoint.EndpointKey{
+					DNSName:    headlessDomain,
+					RecordType: suitableType(target),
 				}
+				targetsByHeadlessDomainAndType[key] = append(targetsByHeadlessDomainAndType[key], target)
 			}
 		}
 	}
@@ -452,6 +531,27 @@ func (sc *serviceSource) filterByServiceType(services []*v1.Service) []*v1.Servi
 	return filteredList
 }
 
+// filterByLoadBalancerClass filters the LoadBalancer services according to their classes, the
+// services without a class having the empty class. The services of other types are kept.
+func (sc *serviceSource) filterByLoadBalancerClass(services []*v1.Service) []*v1.Service {
+	filteredList := []*v1.Service{}
+	for _, service := range services {
+		if service.Spec.Type == v1.ServiceTypeLoadBalancer {
+			class := ""
+			if service.Spec.LoadBalancerClass != nil {
+				class = *service.Spec.LoadBalancerClass
+			}
+			if _, ok := sc.loadBalancerClassFilter[class]; !ok {
+				log.Debugf("Skipping service %s/%s because its load balancer class %q isn't filtered", service.Namespace, service.Name, class)
+				continue
+			}
+		}
+		filteredList = append(filteredList, service)
+	}
+
+	return filteredList
+}
+
 func (sc *serviceSource) setResourceLabel(service *v1.Service, endpoints []*endpoint.Endpoint) {
 	for _, ep := range endpoints {
 		ep.Labels[endpoint.ResourceLabelKey] = fmt.Sprintf("service/%s/%s", service.Namespace, service.Name)
@@ -497,15 +597,22 @@ func (sc *serviceSource) generateEndpoints(svc *v1.Service, hostname string, pro
 			if useClusterIP {
 				targets = extractServiceIps(svc)
 			} else {
-				targets = extractLoadBalancerTargets(svc, sc.resolveLoadBalancerHostname)
+				targets = sc.metalLB.Targets(svc, extractLoadBalancerTargets(svc, sc.resolveLoadBalancerHostname))
+				targets = sc.klipperTargets(svc, targets)
 			}
 		case v1.ServiceTypeClusterIP:
 			if svc.Spec.ClusterIP == v1.ClusterIPNone {
 				endpoints = append(endpoints, sc.extractHeadlessEndpoints(svc, hostname, ttl)...)
+			} else if !useClusterIP && sc.publishExternalIPs && len(svc.Spec.ExternalIPs) > 0 {
+				targets = svc.Spec.ExternalIPs
 			} else if useClusterIP || sc.publishInternal {
 				targets = extractServiceIps(svc)
 			}
 		case v1.ServiceTypeNodePort:
+			if sc.publishExternalIPs && len(svc.Spec.ExternalIPs) > 0 {
+				targets = svc.Spec.ExternalIPs
+				break
+			}
 			// add the nodeTargets and extract an SRV endpoint
 			var err error
 			targets, err = sc.extractNodePortTargets(svc)
@@ -644,6 +751,10 @@ func (sc *serviceSource) extractNodePortTargets(svc *v1.Service) (endpoint.Targe
 		var nodesRunning []*v1.Node
 		for _, v := range pods {
 			if v.Status.Phase == v1.PodRunning {
+				if !sc.targetReadiness.PodReady(v) {
+					log.Debugf("Skipping pod %s/%s because it doesn't have the readiness conditions", v.Namespace, v.Name)
+					continue
+				}
 				node, err := sc.nodeInformer.Lister().Get(v.Spec.NodeName)
 				if err != nil {
 					log.Debugf("Unable to find node where Pod %s is running", v.Spec.Hostname)
@@ -684,6 +795,14 @@ func (sc *serviceSource) extractNodePortTargets(svc *v1.Service) (endpoint.Targe
 	}
 
 	for _, node := range nodes {
+		if sc.excludeDeletingNodes && nodeDeleting(node) {
+			log.Debugf("Skipping node %s because it is being deleted", node.Name)
+			continue
+		}
+		if !sc.targetReadiness.NodeReady(node) {
+			log.Debugf("Skipping node %s because it doesn't have the readiness conditions", node.Name)
+			continue
+		}
 		for _, address := range node.Status.Addresses {
 			switch address.Type {
 			case v1.NodeExternalIP:
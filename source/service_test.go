@@ -64,22 +64,9 @@ func (suite *ServiceSuite) SetupTest() {
 	_, err := fakeClient.CoreV1().Services(suite.fooWithTargets.Namespace).Create(context.Background(), suite.fooWithTargets, metav1.CreateOptions{})
 	suite.NoError(err, "should successfully create service")
 
-	suite.sc, err = NewServiceSource(
-		context.TODO(),
-		fakeClient,
-		"",
-		"",
-		"{{.Name}}",
-		false,
-		"",
-		false,
-		false,
-		false,
-		[]string{},
-		false,
-		labels.Everything(),
-		false,
-	)
+	suite.sc, err = NewServiceSource(context.TODO(), fakeClient, ServiceSourceConfig{
+		FQDNTemplate: "{{.Name}}",
+	})
 	suite.NoError(err, "should initialize service source")
 }
 
@@ -145,22 +132,11 @@ func testServiceSourceNewServiceSource(t *testing.T) {
 		t.Run(ti.title, func(t *testing.T) {
 			t.Parallel()
 
-			_, err := NewServiceSource(
-				context.TODO(),
-				fake.NewSimpleClientset(),
-				"",
-				ti.annotationFilter,
-				ti.fqdnTemplate,
-				false,
-				"",
-				false,
-				false,
-				false,
-				ti.serviceTypesFilter,
-				false,
-				labels.Everything(),
-				false,
-			)
+			_, err := NewServiceSource(context.TODO(), fake.NewSimpleClientset(), ServiceSourceConfig{
+				AnnotationFilter:  ti.annotationFilter,
+				FQDNTemplate:      ti.fqdnTemplate,
+				ServiceTypeFilter: ti.serviceTypesFilter,
+			})
 
 			if ti.expectError {
 				assert.Error(t, err)
@@ -196,6 +172,9 @@ func testServiceSourceEndpoints(t *testing.T) {
 		expectError                 bool
 		serviceLabelSelector        string
 		resolveLoadBalancerHostname bool
+		publishExternalIPs          bool
+		loadBalancerClass           *string
+		loadBalancerClassFilter     []string
 	}{
 		{
 			title:              "no annotated services return no endpoints",
@@ -1062,6 +1041,79 @@ func testServiceSourceEndpoints(t *testing.T) {
 				{DNSName: "foobar-v6.example.org", RecordType: endpoint.RecordTypeAAAA, Targets: endpoint.Targets{"2001:db8::2"}},
 			},
 		},
+		{
+			title:              "ClusterIP service with external IPs publishes them when enabled",
+			svcNamespace:       "testing",
+			svcName:            "foo",
+			svcType:            v1.ServiceTypeClusterIP,
+			clusterIP:          "10.0.0.1",
+			externalIPs:        []string{"192.0.2.1", "2001:db8::1"},
+			publishExternalIPs: true,
+			annotations:        map[string]string{hostnameAnnotationKey: "foo.example.org"},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"192.0.2.1"}},
+				{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeAAAA, Targets: endpoint.Targets{"2001:db8::1"}},
+			},
+		},
+		{
+			title:        "ClusterIP service with external IPs publishes nothing by default",
+			svcNamespace: "testing",
+			svcName:      "foo",
+			svcType:      v1.ServiceTypeClusterIP,
+			clusterIP:    "10.0.0.1",
+			externalIPs:  []string{"192.0.2.1"},
+			annotations:  map[string]string{hostnameAnnotationKey: "foo.example.org"},
+			expected:     []*endpoint.Endpoint{},
+		},
+		{
+			title:              "NodePort service with external IPs publishes them rather than the nodes when enabled",
+			svcNamespace:       "testing",
+			svcName:            "foo",
+			svcType:            v1.ServiceTypeNodePort,
+			clusterIP:          "10.0.0.1",
+			externalIPs:        []string{"192.0.2.1"},
+			publishExternalIPs: true,
+			annotations:        map[string]string{hostnameAnnotationKey: "foo.example.org"},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"192.0.2.1"}},
+			},
+		},
+		{
+			title:                   "LoadBalancer service of a filtered class is published",
+			svcNamespace:            "testing",
+			svcName:                 "foo",
+			svcType:                 v1.ServiceTypeLoadBalancer,
+			lbs:                     []string{"1.2.3.4"},
+			loadBalancerClass:       loadBalancerClassPtr("metallb.io/metallb"),
+			loadBalancerClassFilter: []string{"metallb.io/metallb"},
+			annotations:             map[string]string{hostnameAnnotationKey: "foo.example.org"},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+		},
+		{
+			title:                   "LoadBalancer service of another class is skipped",
+			svcNamespace:            "testing",
+			svcName:                 "foo",
+			svcType:                 v1.ServiceTypeLoadBalancer,
+			lbs:                     []string{"1.2.3.4"},
+			loadBalancerClass:       loadBalancerClassPtr("service.k8s.aws/nlb"),
+			loadBalancerClassFilter: []string{"metallb.io/metallb"},
+			annotations:             map[string]string{hostnameAnnotationKey: "foo.example.org"},
+			expected:                []*endpoint.Endpoint{},
+		},
+		{
+			title:                   "LoadBalancer service without a class is filtered by the empty class",
+			svcNamespace:            "testing",
+			svcName:                 "foo",
+			svcType:                 v1.ServiceTypeLoadBalancer,
+			lbs:                     []string{"1.2.3.4"},
+			loadBalancerClassFilter: []string{""},
+			annotations:             map[string]string{hostnameAnnotationKey: "foo.example.org"},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+		},
 	} {
 		tc := tc
 		t.Run(tc.title, func(t *testing.T) {
@@ -1082,9 +1134,10 @@ func testServiceSourceEndpoints(t *testing.T) {
 
 			service := &v1.Service{
 				Spec: v1.ServiceSpec{
-					Type:        tc.svcType,
-					ClusterIP:   tc.clusterIP,
-					ExternalIPs: tc.externalIPs,
+					Type:              tc.svcType,
+					ClusterIP:         tc.clusterIP,
+					ExternalIPs:       tc.externalIPs,
+					LoadBalancerClass: tc.loadBalancerClass,
 				},
 				ObjectMeta: metav1.ObjectMeta{
 					Namespace:   tc.svcNamespace,
@@ -1111,22 +1164,19 @@ func testServiceSourceEndpoints(t *testing.T) {
 			}
 
 			// Create our object under test and get the endpoints.
-			client, err := NewServiceSource(
-				context.TODO(),
-				kubernetes,
-				tc.targetNamespace,
-				tc.annotationFilter,
-				tc.fqdnTemplate,
-				tc.combineFQDNAndAnnotation,
-				tc.compatibility,
-				false,
-				false,
-				false,
-				tc.serviceTypesFilter,
-				tc.ignoreHostnameAnnotation,
-				sourceLabel,
-				tc.resolveLoadBalancerHostname,
-			)
+			client, err := NewServiceSource(context.TODO(), kubernetes, ServiceSourceConfig{
+				Namespace:                   tc.targetNamespace,
+				AnnotationFilter:            tc.annotationFilter,
+				FQDNTemplate:                tc.fqdnTemplate,
+				CombineFQDNAnnotation:       tc.combineFQDNAndAnnotation,
+				Compatibility:               tc.compatibility,
+				ServiceTypeFilter:           tc.serviceTypesFilter,
+				IgnoreHostnameAnnotation:    tc.ignoreHostnameAnnotation,
+				LabelSelector:               sourceLabel,
+				ResolveLoadBalancerHostname: tc.resolveLoadBalancerHostname,
+				PublishExternalIPs:          tc.publishExternalIPs,
+				LoadBalancerClassFilter:     tc.loadBalancerClassFilter,
+			})
 
 			require.NoError(t, err)
 
@@ -1301,22 +1351,15 @@ func testMultipleServicesEndpoints(t *testing.T) {
 			}
 
 			// Create our object under test and get the endpoints.
-			client, err := NewServiceSource(
-				context.TODO(),
-				kubernetes,
-				tc.targetNamespace,
-				tc.annotationFilter,
-				tc.fqdnTemplate,
-				tc.combineFQDNAndAnnotation,
-				tc.compatibility,
-				false,
-				false,
-				false,
-				tc.serviceTypesFilter,
-				tc.ignoreHostnameAnnotation,
-				labels.Everything(),
-				false,
-			)
+			client, err := NewServiceSource(context.TODO(), kubernetes, ServiceSourceConfig{
+				Namespace:                tc.targetNamespace,
+				AnnotationFilter:         tc.annotationFilter,
+				FQDNTemplate:             tc.fqdnTemplate,
+				CombineFQDNAnnotation:    tc.combineFQDNAndAnnotation,
+				Compatibility:            tc.compatibility,
+				ServiceTypeFilter:        tc.serviceTypesFilter,
+				IgnoreHostnameAnnotation: tc.ignoreHostnameAnnotation,
+			})
 			require.NoError(t, err)
 
 			res, err := client.Endpoints(context.Background())
@@ -1593,22 +1636,15 @@ func TestClusterIpServices(t *testing.T) {
 				labelSelector = labels.Everything()
 			}
 			// Create our object under test and get the endpoints.
-			client, _ := NewServiceSource(
-				context.TODO(),
-				kubernetes,
-				tc.targetNamespace,
-				tc.annotationFilter,
-				tc.fqdnTemplate,
-				false,
-				tc.compatibility,
-				true,
-				false,
-				false,
-				[]string{},
-				tc.ignoreHostnameAnnotation,
-				labelSelector,
-				false,
-			)
+			client, _ := NewServiceSource(context.TODO(), kubernetes, ServiceSourceConfig{
+				Namespace:                tc.targetNamespace,
+				AnnotationFilter:         tc.annotationFilter,
+				FQDNTemplate:             tc.fqdnTemplate,
+				Compatibility:            tc.compatibility,
+				PublishInternal:          true,
+				IgnoreHostnameAnnotation: tc.ignoreHostnameAnnotation,
+				LabelSelector:            labelSelector,
+			})
 			require.NoError(t, err)
 
 			endpoints, err := client.Endpoints(context.Background())
@@ -2311,22 +2347,14 @@ func TestServiceSourceNodePortServices(t *testing.T) {
 			require.NoError(t, err)
 
 			// Create our object under test and get the endpoints.
-			client, _ := NewServiceSource(
-				context.TODO(),
-				kubernetes,
-				tc.targetNamespace,
-				tc.annotationFilter,
-				tc.fqdnTemplate,
-				false,
-				tc.compatibility,
-				true,
-				false,
-				false,
-				[]string{},
-				tc.ignoreHostnameAnnotation,
-				labels.Everything(),
-				false,
-			)
+			client, _ := NewServiceSource(context.TODO(), kubernetes, ServiceSourceConfig{
+				Namespace:                tc.targetNamespace,
+				AnnotationFilter:         tc.annotationFilter,
+				FQDNTemplate:             tc.fqdnTemplate,
+				Compatibility:            tc.compatibility,
+				PublishInternal:          true,
+				IgnoreHostnameAnnotation: tc.ignoreHostnameAnnotation,
+			})
 			require.NoError(t, err)
 
 			endpoints, err := client.Endpoints(context.Background())
@@ -3039,22 +3067,13 @@ func TestHeadlessServices(t *testing.T) {
 			}
 
 			// Create our object under test and get the endpoints.
-			client, _ := NewServiceSource(
-				context.TODO(),
-				kubernetes,
-				tc.targetNamespace,
-				"",
-				tc.fqdnTemplate,
-				false,
-				tc.compatibility,
-				true,
-				false,
-				false,
-				[]string{},
-				tc.ignoreHostnameAnnotation,
-				labels.Everything(),
-				false,
-			)
+			client, _ := NewServiceSource(context.TODO(), kubernetes, ServiceSourceConfig{
+				Namespace:                tc.targetNamespace,
+				FQDNTemplate:             tc.fqdnTemplate,
+				Compatibility:            tc.compatibility,
+				PublishInternal:          true,
+				IgnoreHostnameAnnotation: tc.ignoreHostnameAnnotation,
+			})
 			require.NoError(t, err)
 
 			endpoints, err := client.Endpoints(context.Background())
@@ -3498,22 +3517,14 @@ func TestHeadlessServicesHostIP(t *testing.T) {
 			require.NoError(t, err)
 
 			// Create our object under test and get the endpoints.
-			client, _ := NewServiceSource(
-				context.TODO(),
-				kubernetes,
-				tc.targetNamespace,
-				"",
-				tc.fqdnTemplate,
-				false,
-				tc.compatibility,
-				true,
-				true,
-				false,
-				[]string{},
-				tc.ignoreHostnameAnnotation,
-				labels.Everything(),
-				false,
-			)
+			client, _ := NewServiceSource(context.TODO(), kubernetes, ServiceSourceConfig{
+				Namespace:                tc.targetNamespace,
+				FQDNTemplate:             tc.fqdnTemplate,
+				Compatibility:            tc.compatibility,
+				PublishInternal:          true,
+				PublishHostIP:            true,
+				IgnoreHostnameAnnotation: tc.ignoreHostnameAnnotation,
+			})
 			require.NoError(t, err)
 
 			endpoints, err := client.Endpoints(context.Background())
@@ -3676,22 +3687,13 @@ func TestExternalServices(t *testing.T) {
 			require.NoError(t, err)
 
 			// Create our object under test and get the endpoints.
-			client, _ := NewServiceSource(
-				context.TODO(),
-				kubernetes,
-				tc.targetNamespace,
-				"",
-				tc.fqdnTemplate,
-				false,
-				tc.compatibility,
-				true,
-				false,
-				false,
-				[]string{},
-				tc.ignoreHostnameAnnotation,
-				labels.Everything(),
-				false,
-			)
+			client, _ := NewServiceSource(context.TODO(), kubernetes, ServiceSourceConfig{
+				Namespace:                tc.targetNamespace,
+				FQDNTemplate:             tc.fqdnTemplate,
+				Compatibility:            tc.compatibility,
+				PublishInternal:          true,
+				IgnoreHostnameAnnotation: tc.ignoreHostnameAnnotation,
+			})
 			require.NoError(t, err)
 
 			endpoints, err := client.Endpoints(context.Background())
@@ -3731,22 +3733,9 @@ func BenchmarkServiceEndpoints(b *testing.B) {
 	_, err := kubernetes.CoreV1().Services(service.Namespace).Create(context.Background(), service, metav1.CreateOptions{})
 	require.NoError(b, err)
 
-	client, err := NewServiceSource(
-		context.TODO(),
-		kubernetes,
-		v1.NamespaceAll,
-		"",
-		"",
-		false,
-		"",
-		false,
-		false,
-		false,
-		[]string{},
-		false,
-		labels.Everything(),
-		false,
-	)
+	client, err := NewServiceSource(context.TODO(), kubernetes, ServiceSourceConfig{
+		Namespace: v1.NamespaceAll,
+	})
 	require.NoError(b, err)
 
 	for i := 0; i < b.N; i++ {
@@ -3754,3 +3743,5 @@ func BenchmarkServiceEndpoints(b *testing.B) {
 		require.NoError(b, err)
 	}
 }
+
+func loadBalancerClassPtr(class string) *string { return &class }
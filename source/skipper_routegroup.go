@@ -34,6 +34,9 @@ import (
 	"time"
 
 	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 
 	"sigs.k8s.io/external-dns/endpoint"
 )
@@ -191,7 +194,7 @@ func (cli *routeGroupClient) do(req *http.Request) (*http.Response, error) {
 }
 
 // NewRouteGroupSource creates a new routeGroupSource with the given config.
-func NewRouteGroupSource(timeout time.Duration, token, tokenPath, apiServerURL, namespace, annotationFilter, fqdnTemplate, routegroupVersion string, combineFqdnAnnotation, ignoreHostnameAnnotation bool) (Source, error) {
+func NewRouteGroupSource(timeout time.Duration, token, tokenPath, apiServerURL, namespace, annotationFilter, fqdnTemplate, routegroupVersion string, combineFqdnAnnotation, ignoreHostnameAnnotation bool, labelSelector labels.Selector, fieldSelector fields.Selector) (Source, error) {
 	tmpl, err := parseTemplate(fqdnTemplate)
 	if err != nil {
 		return nil, err
@@ -228,6 +231,20 @@ func NewRouteGroupSource(timeout time.Duration, token, tokenPath, apiServerURL,
 		sc.apiEndpoint = apiServer + fmt.Sprintf(routeGroupNamespacedResource, routegroupVersion, namespace)
 	}
 
+	// the route groups are filtered by the API server
+	listOptions := metav1.ListOptions{}
+	newListOptionsTweak(labelSelector, fieldSelector)(&listOptions)
+	query := url.Values{}
+	if listOptions.LabelSelector != "" {
+		query.Set("labelSelector", listOptions.LabelSelector)
+	}
+	if listOptions.FieldSelector != "" {
+		query.Set("fieldSelector", listOptions.FieldSelector)
+	}
+	if len(query) > 0 {
+		sc.apiEndpoint += "?" + query.Encode()
+	}
+
 	log.Infoln("Created route group source")
 	return sc, nil
 }
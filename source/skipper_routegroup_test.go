@@ -19,9 +19,13 @@ package source
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"sigs.k8s.io/external-dns/endpoint"
 )
 
@@ -867,3 +871,33 @@ func TestParseTemplate(t *testing.T) {
 		})
 	}
 }
+
+func TestRouteGroupSourceSelectors(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		namespace     string
+		labelSelector labels.Selector
+		fieldSelector fields.Selector
+		expected      string
+	}{
+		{
+			name:          "no selectors",
+			labelSelector: labels.Everything(),
+			fieldSelector: fields.Everything(),
+			expected:      "https://api.example.org/apis/zalando.org/v1/routegroups",
+		},
+		{
+			name:          "label and field selectors",
+			namespace:     "default",
+			labelSelector: labels.SelectorFromSet(labels.Set{"team": "a"}),
+			fieldSelector: fields.OneTermEqualSelector("metadata.name", "app"),
+			expected:      "https://api.example.org/apis/zalando.org/v1/namespaces/default/routegroups?fieldSelector=metadata.name%3Dapp&labelSelector=team%3Da",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			src, err := NewRouteGroupSource(time.Second, "", "", "https://api.example.org:443", tt.namespace, "", "", "", false, false, tt.labelSelector, tt.fieldSelector)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, src.(*routeGroupSource).apiEndpoint)
+		})
+	}
+}
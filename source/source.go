@@ -31,37 +31,105 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/jsonpath"
 
 	"sigs.k8s.io/external-dns/endpoint"
 )
 
-const (
+// DefaultAnnotationPrefix is the prefix of the annotations read by the sources, unless another
+// prefix is set with SetAnnotationPrefix.
+const DefaultAnnotationPrefix = "external-dns.alpha.kubernetes.io/"
+
+// The annotation keys are built from the annotation prefix by SetAnnotationPrefix.
+var (
+	// The prefix of all annotations
+	annotationPrefix string
 	// The annotation used for figuring out which controller is responsible
-	controllerAnnotationKey = "external-dns.alpha.kubernetes.io/controller"
+	controllerAnnotationKey string
 	// The annotation used for defining the desired hostname
-	hostnameAnnotationKey = "external-dns.alpha.kubernetes.io/hostname"
+	hostnameAnnotationKey string
+	// The annotation used for defining the hostnames published as CNAME records of the primary hostname
+	aliasesAnnotationKey string
 	// The annotation used for specifying whether the public or private interface address is used
-	accessAnnotationKey = "external-dns.alpha.kubernetes.io/access"
+	accessAnnotationKey string
 	// The annotation used for specifying the type of endpoints to use for headless services
-	endpointsTypeAnnotationKey = "external-dns.alpha.kubernetes.io/endpoints-type"
+	endpointsTypeAnnotationKey string
 	// The annotation used for defining the desired ingress/service target
-	targetAnnotationKey = "external-dns.alpha.kubernetes.io/target"
+	targetAnnotationKey string
 	// The annotation used for defining the desired DNS record TTL
-	ttlAnnotationKey = "external-dns.alpha.kubernetes.io/ttl"
+	ttlAnnotationKey string
 	// The annotation used for switching to the alias record types e. g. AWS Alias records instead of a normal CNAME
-	aliasAnnotationKey = "external-dns.alpha.kubernetes.io/alias"
+	aliasAnnotationKey string
 	// The annotation used to determine the source of hostnames for ingresses.  This is an optional field - all
 	// available hostname sources are used if not specified.
-	ingressHostnameSourceKey = "external-dns.alpha.kubernetes.io/ingress-hostname-source"
-	// The value of the controller annotation so that we feel responsible
-	controllerAnnotationValue = "dns-controller"
+	ingressHostnameSourceKey string
 	// The annotation used for defining the desired hostname
-	internalHostnameAnnotationKey = "external-dns.alpha.kubernetes.io/internal-hostname"
+	internalHostnameAnnotationKey string
+	// The annotation used for determining if traffic will go through Cloudflare
+	cloudflareProxiedAnnotationKey string
+	// The annotation used for pointing the records at a Cloudflare tunnel
+	cloudflareTunnelIDAnnotationKey string
+	// The annotation used for defining the set identifier of the records
+	setIdentifierAnnotationKey string
+	// The annotation used to determine if an Istio gateway is implemented by an Ingress object
+	istioGatewayIngressAnnotationKey string
+	// The annotation used for defining how long changed targets must be stable before they are applied
+	dampeningWindowAnnotationKey string
+	// The annotations used for defining when the records are created and when they are deleted
+	activeFromAnnotationKey  string
+	activeUntilAnnotationKey string
+	// The annotation used for defining the SSH host public keys of a node, published as SSHFP records
+	sshHostKeysAnnotationKey string
 )
 
+// DefaultControllerAnnotationValue is the value of the controller annotation of the resources this
+// instance is responsible for, unless another value is set with SetControllerAnnotationValue.
+const DefaultControllerAnnotationValue = "dns-controller"
+
+// The value of the controller annotation so that we feel responsible
+var controllerAnnotationValue = DefaultControllerAnnotationValue
+
+func init() {
+	SetAnnotationPrefix(DefaultAnnotationPrefix)
+}
+
+// SetAnnotationPrefix sets the prefix of the annotations read by all sources, e.g. "internal-dns/"
+// for "internal-dns/hostname", so several instances of external-dns can be pointed at different
+// annotations of the same resources. It must be called before the sources are created.
+func SetAnnotationPrefix(prefix string) {
+	annotationPrefix = prefix
+	controllerAnnotationKey = prefix + "controller"
+	hostnameAnnotationKey = prefix + "hostname"
+	aliasesAnnotationKey = prefix + "aliases"
+	accessAnnotationKey = prefix + "access"
+	endpointsTypeAnnotationKey = prefix + "endpoints-type"
+	targetAnnotationKey = prefix + "target"
+	ttlAnnotationKey = prefix + "ttl"
+	aliasAnnotationKey = prefix + "alias"
+	ingressHostnameSourceKey = prefix + "ingress-hostname-source"
+	internalHostnameAnnotationKey = prefix + "internal-hostname"
+	cloudflareProxiedAnnotationKey = prefix + "cloudflare-proxied"
+	cloudflareTunnelIDAnnotationKey = prefix + "cloudflare-tunnel-id"
+	setIdentifierAnnotationKey = prefix + "set-identifier"
+	istioGatewayIngressAnnotationKey = prefix + "ingress"
+	dampeningWindowAnnotationKey = prefix + "dampening-window"
+	activeFromAnnotationKey = prefix + "active-from"
+	activeUntilAnnotationKey = prefix + "active-until"
+	sshHostKeysAnnotationKey = prefix + "ssh-host-keys"
+}
+
+// SetControllerAnnotationValue sets the value of the controller annotation of the resources this
+// instance is responsible for, so several instances of external-dns can each handle the resources
+// annotated for them. The resources without the annotation are handled by every instance. It must
+// be called before the sources are created.
+func SetControllerAnnotationValue(value string) {
+	controllerAnnotationValue = value
+}
+
 const (
 	EndpointsTypeNodeExternalIP = "NodeExternalIP"
 	EndpointsTypeHostIP         = "HostIP"
@@ -69,10 +137,25 @@ const (
 
 // Provider-specific annotations
 const (
-	// The annotation used for determining if traffic will go through Cloudflare
+	// The provider specific property used for determining if traffic will go through Cloudflare,
+	// also the annotation with the default annotation prefix
 	CloudflareProxiedKey = "external-dns.alpha.kubernetes.io/cloudflare-proxied"
 
+	// The domain of the hostnames of the Cloudflare tunnels, <tunnel-id>.cfargotunnel.com, the
+	// targets of the records of the resources with the cloudflare-tunnel-id annotation
+	CloudflareTunnelDomain = "cfargotunnel.com"
+
+	// The set identifier annotation with the default annotation prefix
 	SetIdentifierKey = "external-dns.alpha.kubernetes.io/set-identifier"
+
+	// The property used for the dampening window of the records, read and removed by the controller
+	// before the endpoints reach the provider
+	DampeningWindowKey = "external-dns.alpha.kubernetes.io/dampening-window"
+
+	// The properties used for the time the records are created at and the time they are deleted at,
+	// read and removed by the controller before the endpoints reach the provider
+	ActiveFromKey  = "external-dns.alpha.kubernetes.io/active-from"
+	ActiveUntilKey = "external-dns.alpha.kubernetes.io/active-until"
 )
 
 const (
@@ -177,6 +260,36 @@ func getInternalHostnamesFromAnnotations(annotations map[string]string) []string
 	return splitHostnameAnnotation(internalHostnameAnnotation)
 }
 
+func getAliasesFromAnnotations(annotations map[string]string) []string {
+	aliasesAnnotation, exists := annotations[aliasesAnnotationKey]
+	if !exists {
+		return nil
+	}
+	return splitHostnameAnnotation(aliasesAnnotation)
+}
+
+// aliasEndpoints returns the CNAME endpoints of the hostnames of the aliases annotation pointing at
+// the primary hostname of the resource, the hostname of its first endpoint, so that the aliases
+// follow the targets of the primary hostname and are owned by the same resource.
+func aliasEndpoints(annotations map[string]string, endpoints []*endpoint.Endpoint, resource string) []*endpoint.Endpoint {
+	aliases := getAliasesFromAnnotations(annotations)
+	if len(aliases) == 0 || len(endpoints) == 0 {
+		return nil
+	}
+	primary := endpoints[0]
+	ttl := getTTLFromAnnotations(annotations, resource)
+
+	var cnames []*endpoint.Endpoint
+	for _, alias := range aliases {
+		alias = strings.TrimSuffix(alias, ".")
+		if alias == "" || alias == primary.DNSName {
+			continue
+		}
+		cnames = append(cnames, endpointsForHostname(alias, endpoint.Targets{primary.DNSName}, ttl, primary.ProviderSpecific, primary.SetIdentifier, resource)...)
+	}
+	return cnames
+}
+
 func splitHostnameAnnotation(annotation string) []string {
 	return strings.Split(strings.Replace(annotation, " ", "", -1), ",")
 }
@@ -189,13 +302,31 @@ func getAliasFromAnnotations(annotations map[string]string) bool {
 func getProviderSpecificAnnotations(annotations map[string]string) (endpoint.ProviderSpecific, string) {
 	providerSpecificAnnotations := endpoint.ProviderSpecific{}
 
-	v, exists := annotations[CloudflareProxiedKey]
+	v, exists := annotations[cloudflareProxiedAnnotationKey]
 	if exists {
 		providerSpecificAnnotations = append(providerSpecificAnnotations, endpoint.ProviderSpecificProperty{
 			Name:  CloudflareProxiedKey,
 			Value: v,
 		})
 	}
+	if v, exists := annotations[dampeningWindowAnnotationKey]; exists {
+		providerSpecificAnnotations = append(providerSpecificAnnotations, endpoint.ProviderSpecificProperty{
+			Name:  DampeningWindowKey,
+			Value: v,
+		})
+	}
+	if v, exists := annotations[activeFromAnnotationKey]; exists {
+		providerSpecificAnnotations = append(providerSpecificAnnotations, endpoint.ProviderSpecificProperty{
+			Name:  ActiveFromKey,
+			Value: v,
+		})
+	}
+	if v, exists := annotations[activeUntilAnnotationKey]; exists {
+		providerSpecificAnnotations = append(providerSpecificAnnotations, endpoint.ProviderSpecificProperty{
+			Name:  ActiveUntilKey,
+			Value: v,
+		})
+	}
 	if getAliasFromAnnotations(annotations) {
 		providerSpecificAnnotations = append(providerSpecificAnnotations, endpoint.ProviderSpecificProperty{
 			Name:  "alias",
@@ -204,22 +335,34 @@ func getProviderSpecificAnnotations(annotations map[string]string) (endpoint.Pro
 	}
 	setIdentifier := ""
 	for k, v := range annotations {
-		if k == SetIdentifierKey {
+		if k == setIdentifierAnnotationKey {
 			setIdentifier = v
-		} else if strings.HasPrefix(k, "external-dns.alpha.kubernetes.io/aws-") {
-			attr := strings.TrimPrefix(k, "external-dns.alpha.kubernetes.io/aws-")
+		} else if strings.HasPrefix(k, annotationPrefix+"aws-") {
+			attr := strings.TrimPrefix(k, annotationPrefix+"aws-")
 			providerSpecificAnnotations = append(providerSpecificAnnotations, endpoint.ProviderSpecificProperty{
 				Name:  fmt.Sprintf("aws/%s", attr),
 				Value: v,
 			})
-		} else if strings.HasPrefix(k, "external-dns.alpha.kubernetes.io/scw-") {
-			attr := strings.TrimPrefix(k, "external-dns.alpha.kubernetes.io/scw-")
+		} else if strings.HasPrefix(k, annotationPrefix+"scw-") {
+			attr := strings.TrimPrefix(k, annotationPrefix+"scw-")
 			providerSpecificAnnotations = append(providerSpecificAnnotations, endpoint.ProviderSpecificProperty{
 				Name:  fmt.Sprintf("scw/%s", attr),
 				Value: v,
 			})
-		} else if strings.HasPrefix(k, "external-dns.alpha.kubernetes.io/ibmcloud-") {
-			attr := strings.TrimPrefix(k, "external-dns.alpha.kubernetes.io/ibmcloud-")
+		} else if strings.HasPrefix(k, annotationPrefix+"ns1-") {
+			attr := strings.TrimPrefix(k, annotationPrefix+"ns1-")
+			providerSpecificAnnotations = append(providerSpecificAnnotations, endpoint.ProviderSpecificProperty{
+				Name:  fmt.Sprintf("ns1-%s", attr),
+				Value: v,
+			})
+		} else if strings.HasPrefix(k, annotationPrefix+"oci-") {
+			attr := strings.TrimPrefix(k, annotationPrefix+"oci-")
+			providerSpecificAnnotations = append(providerSpecificAnnotations, endpoint.ProviderSpecificProperty{
+				Name:  fmt.Sprintf("oci-%s", attr),
+				Value: v,
+			})
+		} else if strings.HasPrefix(k, annotationPrefix+"ibmcloud-") {
+			attr := strings.TrimPrefix(k, annotationPrefix+"ibmcloud-")
 			providerSpecificAnnotations = append(providerSpecificAnnotations, endpoint.ProviderSpecificProperty{
 				Name:  fmt.Sprintf("ibmcloud-%s", attr),
 				Value: v,
@@ -229,7 +372,8 @@ func getProviderSpecificAnnotations(annotations map[string]string) (endpoint.Pro
 	return providerSpecificAnnotations, setIdentifier
 }
 
-// getTargetsFromTargetAnnotation gets endpoints from optional "target" annotation.
+// getTargetsFromTargetAnnotation gets endpoints from optional "target" annotation, or the hostname of the
+// Cloudflare tunnel of the optional "cloudflare-tunnel-id" annotation.
 // Returns empty endpoints array if none are found.
 func getTargetsFromTargetAnnotation(annotations map[string]string) endpoint.Targets {
 	var targets endpoint.Targets
@@ -243,6 +387,13 @@ func getTargetsFromTargetAnnotation(annotations map[string]string) endpoint.Targ
 			targetHostname = strings.TrimSuffix(targetHostname, ".")
 			targets = append(targets, targetHostname)
 		}
+		return targets
+	}
+
+	tunnelID := strings.TrimSpace(annotations[cloudflareTunnelIDAnnotationKey])
+	if tunnelID != "" {
+		tunnelID = strings.TrimSuffix(strings.TrimSuffix(tunnelID, "."), "."+CloudflareTunnelDomain)
+		targets = append(targets, tunnelID+"."+CloudflareTunnelDomain)
 	}
 	return targets
 }
@@ -346,6 +497,63 @@ func matchLabelSelector(selector labels.Selector, srcAnnotations map[string]stri
 	return selector.Matches(annotations)
 }
 
+// newListOptionsTweak returns the function restricting the lists and watches of an informer to the
+// resources matching the label and field selectors, so the API server filters them instead of
+// sending all of them to be filtered here.
+func newListOptionsTweak(labelSelector labels.Selector, fieldSelector fields.Selector) func(*metav1.ListOptions) {
+	var lbls, flds string
+	if labelSelector != nil && !labelSelector.Empty() {
+		lbls = labelSelector.String()
+	}
+	if fieldSelector != nil && !fieldSelector.Empty() {
+		flds = fieldSelector.String()
+	}
+	return func(o *metav1.ListOptions) {
+		if lbls != "" {
+			o.LabelSelector = lbls
+		}
+		if flds != "" {
+			o.FieldSelector = flds
+		}
+	}
+}
+
+// parseResourceMapping parses the mapping of a resource of the form "resource.group/version"
+// followed by ";"-separated key=value settings, which are passed to set. It reports unknown
+// settings, for which set returns false, naming the mapping as what, e.g. "gateway route type".
+func parseResourceMapping(s, what string, set func(key, value string) bool) (schema.GroupVersionResource, error) {
+	parts := strings.Split(s, ";")
+	gv := strings.SplitN(parts[0], "/", 2)
+	resource, group, _ := strings.Cut(gv[0], ".")
+	if len(gv) != 2 || resource == "" || group == "" || gv[1] == "" {
+		return schema.GroupVersionResource{}, fmt.Errorf("invalid %s %q: expected resource.group/version", what, s)
+	}
+	for _, setting := range parts[1:] {
+		key, value, ok := strings.Cut(setting, "=")
+		if !ok || value == "" {
+			return schema.GroupVersionResource{}, fmt.Errorf("invalid setting %q of %s %q: expected key=value", setting, what, s)
+		}
+		if !set(key, value) {
+			return schema.GroupVersionResource{}, fmt.Errorf("unknown setting %q of %s %q", key, what, s)
+		}
+	}
+	return schema.GroupVersionResource{Group: group, Version: gv[1], Resource: resource}, nil
+}
+
+// validateMappingJSONPaths checks the JSONPaths of the settings of the mapping of a resource,
+// skipping the optional ones that aren't set.
+func validateMappingJSONPaths(s, what string, paths ...string) error {
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		if err := jsonpath.New(what).Parse(path); err != nil {
+			return fmt.Errorf("invalid JSONPath %q of %s %q: %w", path, what, s, err)
+		}
+	}
+	return nil
+}
+
 type eventHandlerFunc func()
 
 func (fn eventHandlerFunc) OnAdd(obj interface{}, isInInitialList bool) { fn() }
@@ -21,6 +21,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 
 	"sigs.k8s.io/external-dns/endpoint"
 )
@@ -96,3 +99,134 @@ func TestSuitableType(t *testing.T) {
 		}
 	}
 }
+
+func TestSetAnnotationPrefix(t *testing.T) {
+	defer SetAnnotationPrefix(DefaultAnnotationPrefix)
+	SetAnnotationPrefix("internal-dns/")
+
+	annotations := map[string]string{
+		"internal-dns/hostname":                       "internal.example.org",
+		"internal-dns/set-identifier":                 "blue",
+		"internal-dns/aws-weight":                     "10",
+		"internal-dns/cloudflare-proxied":             "true",
+		"external-dns.alpha.kubernetes.io/hostname":   "external.example.org",
+		"external-dns.alpha.kubernetes.io/aws-region": "eu-west-1",
+	}
+	assert.Equal(t, []string{"internal.example.org"}, getHostnamesFromAnnotations(annotations))
+
+	providerSpecific, setIdentifier := getProviderSpecificAnnotations(annotations)
+	assert.Equal(t, "blue", setIdentifier)
+	assert.ElementsMatch(t, endpoint.ProviderSpecific{
+		{Name: CloudflareProxiedKey, Value: "true"},
+		{Name: "aws/weight", Value: "10"},
+	}, providerSpecific)
+}
+
+func TestGetTargetsFromTargetAnnotation(t *testing.T) {
+	for _, tc := range []struct {
+		title       string
+		annotations map[string]string
+		expected    endpoint.Targets
+	}{
+		{
+			title:       "no annotation",
+			annotations: map[string]string{},
+			expected:    nil,
+		},
+		{
+			title:       "targets",
+			annotations: map[string]string{targetAnnotationKey: "10.0.0.1, lb.example.org."},
+			expected:    endpoint.Targets{"10.0.0.1", "lb.example.org"},
+		},
+		{
+			title:       "cloudflare tunnel",
+			annotations: map[string]string{cloudflareTunnelIDAnnotationKey: "c1744f8b-faa1-48a4-9e5c-02ac921467fa"},
+			expected:    endpoint.Targets{"c1744f8b-faa1-48a4-9e5c-02ac921467fa.cfargotunnel.com"},
+		},
+		{
+			title:       "cloudflare tunnel hostname",
+			annotations: map[string]string{cloudflareTunnelIDAnnotationKey: "c1744f8b-faa1-48a4-9e5c-02ac921467fa.cfargotunnel.com."},
+			expected:    endpoint.Targets{"c1744f8b-faa1-48a4-9e5c-02ac921467fa.cfargotunnel.com"},
+		},
+		{
+			title: "targets before cloudflare tunnel",
+			annotations: map[string]string{
+				targetAnnotationKey:             "lb.example.org",
+				cloudflareTunnelIDAnnotationKey: "c1744f8b-faa1-48a4-9e5c-02ac921467fa",
+			},
+			expected: endpoint.Targets{"lb.example.org"},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			assert.Equal(t, tc.expected, getTargetsFromTargetAnnotation(tc.annotations))
+		})
+	}
+}
+
+func TestAliasEndpoints(t *testing.T) {
+	endpoints := []*endpoint.Endpoint{
+		endpoint.NewEndpoint("app.example.org", endpoint.RecordTypeA, "1.2.3.4").WithSetIdentifier("eu"),
+		endpoint.NewEndpoint("app.example.org", endpoint.RecordTypeAAAA, "2001:db8::1"),
+	}
+
+	assert.Nil(t, aliasEndpoints(map[string]string{}, endpoints, "service/default/app"))
+	assert.Nil(t, aliasEndpoints(map[string]string{aliasesAnnotationKey: "www.example.org"}, nil, "service/default/app"))
+
+	aliases := aliasEndpoints(map[string]string{
+		aliasesAnnotationKey: "www.example.org, web.example.org., app.example.org",
+		ttlAnnotationKey:     "60",
+	}, endpoints, "service/default/app")
+	assert.Equal(t, []*endpoint.Endpoint{
+		{
+			DNSName:       "www.example.org",
+			Targets:       endpoint.Targets{"app.example.org"},
+			RecordType:    endpoint.RecordTypeCNAME,
+			RecordTTL:     60,
+			SetIdentifier: "eu",
+			Labels:        endpoint.Labels{endpoint.ResourceLabelKey: "service/default/app"},
+		},
+		{
+			DNSName:       "web.example.org",
+			Targets:       endpoint.Targets{"app.example.org"},
+			RecordType:    endpoint.RecordTypeCNAME,
+			RecordTTL:     60,
+			SetIdentifier: "eu",
+			Labels:        endpoint.Labels{endpoint.ResourceLabelKey: "service/default/app"},
+		},
+	}, aliases)
+}
+
+func TestNewListOptionsTweak(t *testing.T) {
+	for _, tc := range []struct {
+		title         string
+		labelSelector labels.Selector
+		fieldSelector fields.Selector
+		expected      metav1.ListOptions
+	}{
+		{
+			title:    "no selectors",
+			expected: metav1.ListOptions{},
+		},
+		{
+			title:         "selecting everything",
+			labelSelector: labels.Everything(),
+			fieldSelector: fields.Everything(),
+			expected:      metav1.ListOptions{},
+		},
+		{
+			title:         "label and field selectors",
+			labelSelector: labels.SelectorFromSet(labels.Set{"app": "web"}),
+			fieldSelector: fields.OneTermNotEqualSelector("metadata.namespace", "kube-system"),
+			expected: metav1.ListOptions{
+				LabelSelector: "app=web",
+				FieldSelector: "metadata.namespace!=kube-system",
+			},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			options := metav1.ListOptions{}
+			newListOptionsTweak(tc.labelSelector, tc.fieldSelector)(&options)
+			assert.Equal(t, tc.expected, options)
+		})
+	}
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// sshfpAlgorithms are the SSHFP algorithm numbers of the SSH host key types, see RFC 4255,
+// RFC 6594 and RFC 7479.
+var sshfpAlgorithms = map[string]int{
+	"ssh-rsa":             1,
+	"ssh-dss":             2,
+	"ecdsa-sha2-nistp256": 3,
+	"ecdsa-sha2-nistp384": 3,
+	"ecdsa-sha2-nistp521": 3,
+	"ssh-ed25519":         4,
+}
+
+// sshfpSHA256 is the SSHFP fingerprint type of the SHA-256 fingerprints.
+const sshfpSHA256 = 2
+
+// sshfpTarget returns the target of the SSHFP record of an SSH host public key in the
+// authorized_keys format, e.g. "ssh-ed25519 AAAAC3Nza... root@node1" as found in
+// /etc/ssh/ssh_host_ed25519_key.pub.
+func sshfpTarget(key string) (string, error) {
+	fields := strings.Fields(key)
+	if len(fields) < 2 {
+		return "", fmt.Errorf("expected the key type and the base64 encoded key")
+	}
+	algorithm, ok := sshfpAlgorithms[fields[0]]
+	if !ok {
+		return "", fmt.Errorf("unsupported key type %q", fields[0])
+	}
+	blob, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		return "", fmt.Errorf("invalid base64 encoded key: %w", err)
+	}
+	// the key starts with its type, which must match the declared one
+	if len(blob) < 4 || uint64(len(blob)-4) < uint64(binary.BigEndian.Uint32(blob)) ||
+		string(blob[4:4+int(binary.BigEndian.Uint32(blob))]) != fields[0] {
+		return "", fmt.Errorf("the key isn't a %s key", fields[0])
+	}
+
+	fingerprint := sha256.Sum256(blob)
+	return fmt.Sprintf("%d %d %s", algorithm, sshfpSHA256, hex.EncodeToString(fingerprint[:])), nil
+}
+
+// sshfpTargets returns the targets of the SSHFP records of the SSH host public keys, one per
+// line, skipping the empty lines and the comments. The invalid keys are returned as errors along
+// with the targets of the valid ones.
+func sshfpTargets(keys string) ([]string, []error) {
+	var targets []string
+	var errs []error
+	for i, line := range strings.Split(keys, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		target, err := sshfpTarget(line)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", i+1, err))
+			continue
+		}
+		targets = append(targets, target)
+	}
+	return targets, errs
+}
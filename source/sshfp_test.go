@@ -0,0 +1,94 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testSSHEd25519Key = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIDbUGL8kwtE1d0iLTy5osylJ5apqdR0uce+2APvjOegN root@node1"
+	testSSHECDSAKey   = "ecdsa-sha2-nistp256 AAAAE2VjZHNhLXNoYTItbmlzdHAyNTYAAAAIbmlzdHAyNTYAAABBBO6y2ZzLelWUd4YIJxze/Y6UvVhKXUfUEDdxRtGW25mks2NMqBIbB0ipWGnFpA+FvGhoZE9GQZN0CxK0+5ReSEg="
+	// the fingerprints are those of ssh-keygen -r
+	testSSHEd25519SSHFP = "4 2 4a684dd2817a9082acc600ca16fb674d1aa0452c6c30596d5b09b3ac09f7d98b"
+	testSSHECDSASSHFP   = "3 2 813b65e4703eac42a232770ab8067a902397f0402027f821c1c45072478c99e8"
+)
+
+func TestSSHFPTarget(t *testing.T) {
+	for _, tc := range []struct {
+		title    string
+		key      string
+		expected string
+		err      string
+	}{
+		{
+			title:    "ed25519 key",
+			key:      testSSHEd25519Key,
+			expected: testSSHEd25519SSHFP,
+		},
+		{
+			title:    "ecdsa key without comment",
+			key:      testSSHECDSAKey,
+			expected: testSSHECDSASSHFP,
+		},
+		{
+			title: "missing key",
+			key:   "ssh-ed25519",
+			err:   "expected the key type and the base64 encoded key",
+		},
+		{
+			title: "unsupported key type",
+			key:   "ssh-foo AAAA",
+			err:   `unsupported key type "ssh-foo"`,
+		},
+		{
+			title: "invalid base64",
+			key:   "ssh-ed25519 !!!",
+			err:   "invalid base64 encoded key: illegal base64 data at input byte 0",
+		},
+		{
+			title: "mismatching key type",
+			key:   "ssh-rsa AAAAC3NzaC1lZDI1NTE5AAAAIDbUGL8kwtE1d0iLTy5osylJ5apqdR0uce+2APvjOegN",
+			err:   "the key isn't a ssh-rsa key",
+		},
+		{
+			title: "truncated key",
+			key:   "ssh-ed25519 AAAAC3Nz",
+			err:   "the key isn't a ssh-ed25519 key",
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			target, err := sshfpTarget(tc.key)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, target)
+		})
+	}
+}
+
+func TestSSHFPTargets(t *testing.T) {
+	targets, errs := sshfpTargets("# host keys\n" + testSSHEd25519Key + "\n\nssh-foo AAAA\n  " + testSSHECDSAKey + "\n")
+	assert.Equal(t, []string{testSSHEd25519SSHFP, testSSHECDSASSHFP}, targets)
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], `line 4: unsupported key type "ssh-foo"`)
+}
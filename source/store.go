@@ -30,6 +30,7 @@ import (
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	istioclient "istio.io/client-go/pkg/clientset/versioned"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
@@ -44,26 +45,39 @@ var ErrSourceNotFound = errors.New("source not found")
 // Config holds shared configuration options for all Sources.
 type Config struct {
 	Namespace                      string
+	Namespaces                     []string
+	ExcludeNamespaces              []string
 	AnnotationFilter               string
 	LabelFilter                    labels.Selector
+	FieldFilter                    fields.Selector
 	IngressClassNames              []string
 	FQDNTemplate                   string
 	CombineFQDNAndAnnotation       bool
 	IgnoreHostnameAnnotation       bool
 	IgnoreIngressTLSSpec           bool
 	IgnoreIngressRulesSpec         bool
+	IngressHostnameSource          string
 	GatewayNamespace               string
 	GatewayLabelFilter             string
+	GatewayRouteTypes              []GatewayRouteType
 	Compatibility                  string
 	PublishInternal                bool
 	PublishHostIP                  bool
+	PublishNodeSSHFP               bool
 	AlwaysPublishNotReadyAddresses bool
 	ConnectorServer                string
 	CRDSourceAPIVersion            string
 	CRDSourceKind                  string
+	PassthroughRecordTypes         []string
+	GenericCRDMappings             []GenericCRDMapping
 	KubeConfig                     string
 	APIServerURL                   string
 	ServiceTypeFilter              []string
+	LoadBalancerClassFilter        []string
+	PublishExternalIPs             bool
+	MetalLB                        bool
+	MetalLBNamespace               string
+	KlipperPolicy                  *KlipperPolicy
 	CFAPIEndpoint                  string
 	CFUsername                     string
 	CFPassword                     string
@@ -74,8 +88,14 @@ type Config struct {
 	OCPRouterName                  string
 	UpdateEvents                   bool
 	ResolveLoadBalancerHostname    bool
+	ResolveGatewayHostname         bool
 	TraefikDisableLegacy           bool
 	TraefikDisableNew              bool
+	QuarantineSources              bool
+	QuarantineMinEndpoints         int
+	QuarantineMaxDuration          time.Duration
+	TargetReadiness                *TargetReadiness
+	ExcludeDeletingNodes           bool
 }
 
 // ClientGenerator provides clients
@@ -190,20 +210,105 @@ func (p *SingletonClientGenerator) OpenShiftClient() (openshift.Interface, error
 	return p.openshiftClient, err
 }
 
-// ByNames returns multiple Sources given multiple names.
+// namespacedSources are the sources of namespaced resources, which can be restricted to several
+// namespaces and exclude some.
+var namespacedSources = map[string]bool{
+	"service":              true,
+	"ingress":              true,
+	"pod":                  true,
+	"gateway-httproute":    true,
+	"gateway-grpcroute":    true,
+	"gateway-tlsroute":     true,
+	"gateway-tcproute":     true,
+	"gateway-udproute":     true,
+	"gateway-route":        true,
+	"istio-gateway":        true,
+	"istio-virtualservice": true,
+	"ambassador-host":      true,
+	"contour-httpproxy":    true,
+	"traefik-proxy":        true,
+	"openshift-route":      true,
+	"crd":                  true,
+	"kong-tcpingress":      true,
+	"f5-virtualserver":     true,
+	"skipper-routegroup":   true,
+	"generic-crd":          true,
+	"cutover":              true,
+	"zone-delegation":      true,
+	"verification":         true,
+}
+
+// ByNames returns multiple Sources given multiple names. Their health is reported, see SourcesHealth,
+// under their names, followed by the namespace for the sources built per namespace.
 func ByNames(ctx context.Context, p ClientGenerator, names []string, cfg *Config) ([]Source, error) {
 	sources := []Source{}
 	for _, name := range names {
-		source, err := BuildWithConfig(ctx, name, p, cfg)
-		if err != nil {
-			return nil, err
+		if !namespacedSources[name] {
+			source, err := BuildWithConfig(ctx, name, p, cfg)
+			if err != nil {
+				return nil, err
+			}
+			sources = append(sources, newInstrumentedSource(name, source, cfg))
+			continue
+		}
+
+		// Namespaced sources watch a single namespace, or all of them, so one is built per namespace.
+		nsCfg := *cfg
+		nsCfg.FieldFilter = excludeNamespaces(cfg.FieldFilter, cfg.ExcludeNamespaces)
+		for _, namespace := range cfg.namespaces() {
+			nsCfg.Namespace = namespace
+			source, err := BuildWithConfig(ctx, name, p, &nsCfg)
+			if err != nil {
+				return nil, err
+			}
+			if namespace != "" {
+				sources = append(sources, newInstrumentedSource(name+"/"+namespace, source, cfg))
+			} else {
+				sources = append(sources, newInstrumentedSource(name, source, cfg))
+			}
 		}
-		sources = append(sources, source)
 	}
 
 	return sources, nil
 }
 
+// namespaces returns the namespaces to watch: those of Namespaces, or Namespace when it's not set.
+// All namespaces are watched when any of them is empty.
+func (cfg *Config) namespaces() []string {
+	if len(cfg.Namespaces) == 0 {
+		return []string{cfg.Namespace}
+	}
+	var namespaces []string
+	seen := make(map[string]bool, len(cfg.Namespaces))
+	for _, namespace := range cfg.Namespaces {
+		if namespace == "" {
+			return []string{""}
+		}
+		if !seen[namespace] {
+			seen[namespace] = true
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	return namespaces
+}
+
+// excludeNamespaces returns the field selector additionally excluding the resources of the namespaces.
+func excludeNamespaces(selector fields.Selector, namespaces []string) fields.Selector {
+	var selectors []fields.Selector
+	if selector != nil && !selector.Empty() {
+		selectors = append(selectors, selector)
+	}
+	for _, namespace := range namespaces {
+		if namespace != "" {
+			selectors = append(selectors, fields.OneTermNotEqualSelector("metadata.namespace", namespace))
+		}
+	}
+	if len(selectors) == 0 {
+		return selector
+	}
+	return fields.AndSelectors(selectors...)
+}
+
 // BuildWithConfig allows to generate a Source implementation from the shared config
 func BuildWithConfig(ctx context.Context, source string, p ClientGenerator, cfg *Config) (Source, error) {
 	switch source {
@@ -212,25 +317,56 @@ func BuildWithConfig(ctx context.Context, source string, p ClientGenerator, cfg
 		if err != nil {
 			return nil, err
 		}
-		return NewNodeSource(ctx, client, cfg.AnnotationFilter, cfg.FQDNTemplate, cfg.LabelFilter)
+		return NewNodeSource(ctx, client, cfg.AnnotationFilter, cfg.FQDNTemplate, cfg.LabelFilter, cfg.FieldFilter, cfg.PublishNodeSSHFP, cfg.ExcludeDeletingNodes)
 	case "service":
 		client, err := p.KubeClient()
 		if err != nil {
 			return nil, err
 		}
-		return NewServiceSource(ctx, client, cfg.Namespace, cfg.AnnotationFilter, cfg.FQDNTemplate, cfg.CombineFQDNAndAnnotation, cfg.Compatibility, cfg.PublishInternal, cfg.PublishHostIP, cfg.AlwaysPublishNotReadyAddresses, cfg.ServiceTypeFilter, cfg.IgnoreHostnameAnnotation, cfg.LabelFilter, cfg.ResolveLoadBalancerHostname)
+		var metalLB *MetalLB
+		if cfg.MetalLB {
+			dynamicClient, err := p.DynamicKubernetesClient()
+			if err != nil {
+				return nil, err
+			}
+			metalLB, err = NewMetalLB(ctx, dynamicClient, cfg.MetalLBNamespace)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return NewServiceSource(ctx, client, ServiceSourceConfig{
+			Namespace:                      cfg.Namespace,
+			AnnotationFilter:               cfg.AnnotationFilter,
+			FQDNTemplate:                   cfg.FQDNTemplate,
+			CombineFQDNAnnotation:          cfg.CombineFQDNAndAnnotation,
+			Compatibility:                  cfg.Compatibility,
+			PublishInternal:                cfg.PublishInternal,
+			PublishHostIP:                  cfg.PublishHostIP,
+			AlwaysPublishNotReadyAddresses: cfg.AlwaysPublishNotReadyAddresses,
+			ServiceTypeFilter:              cfg.ServiceTypeFilter,
+			IgnoreHostnameAnnotation:       cfg.IgnoreHostnameAnnotation,
+			LabelSelector:                  cfg.LabelFilter,
+			FieldSelector:                  cfg.FieldFilter,
+			ResolveLoadBalancerHostname:    cfg.ResolveLoadBalancerHostname,
+			TargetReadiness:                cfg.TargetReadiness,
+			ExcludeDeletingNodes:           cfg.ExcludeDeletingNodes,
+			PublishExternalIPs:             cfg.PublishExternalIPs,
+			LoadBalancerClassFilter:        cfg.LoadBalancerClassFilter,
+			MetalLB:                        metalLB,
+			KlipperPolicy:                  cfg.KlipperPolicy,
+		})
 	case "ingress":
 		client, err := p.KubeClient()
 		if err != nil {
 			return nil, err
 		}
-		return NewIngressSource(ctx, client, cfg.Namespace, cfg.AnnotationFilter, cfg.FQDNTemplate, cfg.CombineFQDNAndAnnotation, cfg.IgnoreHostnameAnnotation, cfg.IgnoreIngressTLSSpec, cfg.IgnoreIngressRulesSpec, cfg.LabelFilter, cfg.IngressClassNames)
+		return NewIngressSource(ctx, client, cfg.Namespace, cfg.AnnotationFilter, cfg.FQDNTemplate, cfg.CombineFQDNAndAnnotation, cfg.IgnoreHostnameAnnotation, cfg.IgnoreIngressTLSSpec, cfg.IgnoreIngressRulesSpec, cfg.IngressHostnameSource, cfg.LabelFilter, cfg.FieldFilter, cfg.IngressClassNames)
 	case "pod":
 		client, err := p.KubeClient()
 		if err != nil {
 			return nil, err
 		}
-		return NewPodSource(ctx, client, cfg.Namespace, cfg.Compatibility)
+		return NewPodSource(ctx, client, cfg.Namespace, cfg.Compatibility, cfg.LabelFilter, cfg.FieldFilter)
 	case "gateway-httproute":
 		return NewGatewayHTTPRouteSource(p, cfg)
 	case "gateway-grpcroute":
@@ -241,6 +377,8 @@ func BuildWithConfig(ctx context.Context, source string, p ClientGenerator, cfg
 		return NewGatewayTCPRouteSource(p, cfg)
 	case "gateway-udproute":
 		return NewGatewayUDPRouteSource(p, cfg)
+	case "gateway-route":
+		return NewGatewayGenericRouteSources(p, cfg)
 	case "istio-gateway":
 		kubernetesClient, err := p.KubeClient()
 		if err != nil {
@@ -250,7 +388,7 @@ func BuildWithConfig(ctx context.Context, source string, p ClientGenerator, cfg
 		if err != nil {
 			return nil, err
 		}
-		return NewIstioGatewaySource(ctx, kubernetesClient, istioClient, cfg.Namespace, cfg.AnnotationFilter, cfg.FQDNTemplate, cfg.CombineFQDNAndAnnotation, cfg.IgnoreHostnameAnnotation)
+		return NewIstioGatewaySource(ctx, kubernetesClient, istioClient, cfg.Namespace, cfg.AnnotationFilter, cfg.FQDNTemplate, cfg.CombineFQDNAndAnnotation, cfg.IgnoreHostnameAnnotation, cfg.LabelFilter, cfg.FieldFilter)
 	case "istio-virtualservice":
 		kubernetesClient, err := p.KubeClient()
 		if err != nil {
@@ -260,12 +398,15 @@ func BuildWithConfig(ctx context.Context, source string, p ClientGenerator, cfg
 		if err != nil {
 			return nil, err
 		}
-		return NewIstioVirtualServiceSource(ctx, kubernetesClient, istioClient, cfg.Namespace, cfg.AnnotationFilter, cfg.FQDNTemplate, cfg.CombineFQDNAndAnnotation, cfg.IgnoreHostnameAnnotation)
+		return NewIstioVirtualServiceSource(ctx, kubernetesClient, istioClient, cfg.Namespace, cfg.AnnotationFilter, cfg.FQDNTemplate, cfg.CombineFQDNAndAnnotation, cfg.IgnoreHostnameAnnotation, cfg.LabelFilter, cfg.FieldFilter)
 	case "cloudfoundry":
 		cfClient, err := p.CloudFoundryClient(cfg.CFAPIEndpoint, cfg.CFUsername, cfg.CFPassword)
 		if err != nil {
 			return nil, err
 		}
+		if !cfg.LabelFilter.Empty() || !cfg.FieldFilter.Empty() {
+			log.Warn("The cloudfoundry source doesn't list Kubernetes resources, --label-filter and --field-filter are ignored")
+		}
 		return NewCloudFoundrySource(cfClient)
 	case "ambassador-host":
 		kubernetesClient, err := p.KubeClient()
@@ -276,13 +417,13 @@ func BuildWithConfig(ctx context.Context, source string, p ClientGenerator, cfg
 		if err != nil {
 			return nil, err
 		}
-		return NewAmbassadorHostSource(ctx, dynamicClient, kubernetesClient, cfg.Namespace)
+		return NewAmbassadorHostSource(ctx, dynamicClient, kubernetesClient, cfg.Namespace, cfg.LabelFilter, cfg.FieldFilter)
 	case "contour-httpproxy":
 		dynamicClient, err := p.DynamicKubernetesClient()
 		if err != nil {
 			return nil, err
 		}
-		return NewContourHTTPProxySource(ctx, dynamicClient, cfg.Namespace, cfg.AnnotationFilter, cfg.FQDNTemplate, cfg.CombineFQDNAndAnnotation, cfg.IgnoreHostnameAnnotation)
+		return NewContourHTTPProxySource(ctx, dynamicClient, cfg.Namespace, cfg.AnnotationFilter, cfg.FQDNTemplate, cfg.CombineFQDNAndAnnotation, cfg.IgnoreHostnameAnnotation, cfg.LabelFilter, cfg.FieldFilter)
 	case "gloo-proxy":
 		kubernetesClient, err := p.KubeClient()
 		if err != nil {
@@ -292,7 +433,8 @@ func BuildWithConfig(ctx context.Context, source string, p ClientGenerator, cfg
 		if err != nil {
 			return nil, err
 		}
-		return NewGlooSource(dynamicClient, kubernetesClient, cfg.GlooNamespaces)
+		// the proxies live in the Gloo namespaces rather than the watched ones, but the excluded namespaces still apply
+		return NewGlooSource(dynamicClient, kubernetesClient, cfg.GlooNamespaces, cfg.LabelFilter, excludeNamespaces(cfg.FieldFilter, cfg.ExcludeNamespaces))
 	case "traefik-proxy":
 		kubernetesClient, err := p.KubeClient()
 		if err != nil {
@@ -302,13 +444,13 @@ func BuildWithConfig(ctx context.Context, source string, p ClientGenerator, cfg
 		if err != nil {
 			return nil, err
 		}
-		return NewTraefikSource(ctx, dynamicClient, kubernetesClient, cfg.Namespace, cfg.AnnotationFilter, cfg.IgnoreHostnameAnnotation, cfg.TraefikDisableLegacy, cfg.TraefikDisableNew)
+		return NewTraefikSource(ctx, dynamicClient, kubernetesClient, cfg.Namespace, cfg.AnnotationFilter, cfg.IgnoreHostnameAnnotation, cfg.LabelFilter, cfg.FieldFilter, cfg.TraefikDisableLegacy, cfg.TraefikDisableNew)
 	case "openshift-route":
 		ocpClient, err := p.OpenShiftClient()
 		if err != nil {
 			return nil, err
 		}
-		return NewOcpRouteSource(ctx, ocpClient, cfg.Namespace, cfg.AnnotationFilter, cfg.FQDNTemplate, cfg.CombineFQDNAndAnnotation, cfg.IgnoreHostnameAnnotation, cfg.LabelFilter, cfg.OCPRouterName)
+		return NewOcpRouteSource(ctx, ocpClient, cfg.Namespace, cfg.AnnotationFilter, cfg.FQDNTemplate, cfg.CombineFQDNAndAnnotation, cfg.IgnoreHostnameAnnotation, cfg.LabelFilter, cfg.FieldFilter, cfg.OCPRouterName)
 	case "fake":
 		return NewFakeSource(cfg.FQDNTemplate)
 	case "connector":
@@ -322,7 +464,7 @@ func BuildWithConfig(ctx context.Context, source string, p ClientGenerator, cfg
 		if err != nil {
 			return nil, err
 		}
-		return NewCRDSource(crdClient, cfg.Namespace, cfg.CRDSourceKind, cfg.AnnotationFilter, cfg.LabelFilter, scheme, cfg.UpdateEvents)
+		return NewCRDSource(crdClient, cfg.Namespace, cfg.CRDSourceKind, cfg.AnnotationFilter, cfg.LabelFilter, cfg.FieldFilter, scheme, cfg.UpdateEvents, cfg.PassthroughRecordTypes)
 	case "skipper-routegroup":
 		apiServerURL := cfg.APIServerURL
 		tokenPath := ""
@@ -333,7 +475,7 @@ func BuildWithConfig(ctx context.Context, source string, p ClientGenerator, cfg
 			tokenPath = restConfig.BearerTokenFile
 			token = restConfig.BearerToken
 		}
-		return NewRouteGroupSource(cfg.RequestTimeout, token, tokenPath, apiServerURL, cfg.Namespace, cfg.AnnotationFilter, cfg.FQDNTemplate, cfg.SkipperRouteGroupVersion, cfg.CombineFQDNAndAnnotation, cfg.IgnoreHostnameAnnotation)
+		return NewRouteGroupSource(cfg.RequestTimeout, token, tokenPath, apiServerURL, cfg.Namespace, cfg.AnnotationFilter, cfg.FQDNTemplate, cfg.SkipperRouteGroupVersion, cfg.CombineFQDNAndAnnotation, cfg.IgnoreHostnameAnnotation, cfg.LabelFilter, cfg.FieldFilter)
 	case "kong-tcpingress":
 		kubernetesClient, err := p.KubeClient()
 		if err != nil {
@@ -343,7 +485,7 @@ func BuildWithConfig(ctx context.Context, source string, p ClientGenerator, cfg
 		if err != nil {
 			return nil, err
 		}
-		return NewKongTCPIngressSource(ctx, dynamicClient, kubernetesClient, cfg.Namespace, cfg.AnnotationFilter, cfg.IgnoreHostnameAnnotation)
+		return NewKongTCPIngressSource(ctx, dynamicClient, kubernetesClient, cfg.Namespace, cfg.AnnotationFilter, cfg.IgnoreHostnameAnnotation, cfg.LabelFilter, cfg.FieldFilter)
 	case "f5-virtualserver":
 		kubernetesClient, err := p.KubeClient()
 		if err != nil {
@@ -353,7 +495,31 @@ func BuildWithConfig(ctx context.Context, source string, p ClientGenerator, cfg
 		if err != nil {
 			return nil, err
 		}
-		return NewF5VirtualServerSource(ctx, dynamicClient, kubernetesClient, cfg.Namespace, cfg.AnnotationFilter)
+		return NewF5VirtualServerSource(ctx, dynamicClient, kubernetesClient, cfg.Namespace, cfg.AnnotationFilter, cfg.LabelFilter, cfg.FieldFilter)
+	case "generic-crd":
+		dynamicClient, err := p.DynamicKubernetesClient()
+		if err != nil {
+			return nil, err
+		}
+		return NewGenericCRDSources(ctx, dynamicClient, cfg.Namespace, cfg.AnnotationFilter, cfg.GenericCRDMappings, cfg.LabelFilter, cfg.FieldFilter)
+	case "cutover":
+		dynamicClient, err := p.DynamicKubernetesClient()
+		if err != nil {
+			return nil, err
+		}
+		return NewCutoverSource(ctx, dynamicClient, cfg.Namespace, cfg.AnnotationFilter, cfg.LabelFilter, cfg.FieldFilter)
+	case "zone-delegation":
+		dynamicClient, err := p.DynamicKubernetesClient()
+		if err != nil {
+			return nil, err
+		}
+		return NewZoneDelegationSource(ctx, dynamicClient, cfg.Namespace, cfg.AnnotationFilter, cfg.LabelFilter, cfg.FieldFilter, cfg.PassthroughRecordTypes)
+	case "verification":
+		dynamicClient, err := p.DynamicKubernetesClient()
+		if err != nil {
+			return nil, err
+		}
+		return NewVerificationSource(ctx, dynamicClient, cfg.Namespace, cfg.AnnotationFilter, cfg.LabelFilter, cfg.FieldFilter)
 	}
 
 	return nil, ErrSourceNotFound
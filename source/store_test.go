@@ -23,10 +23,14 @@ import (
 
 	cfclient "github.com/cloudfoundry-community/go-cfclient"
 	openshift "github.com/openshift/client-go/route/clientset/versioned"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	istioclient "istio.io/client-go/pkg/clientset/versioned"
 	istiofake "istio.io/client-go/pkg/clientset/versioned/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
@@ -100,15 +104,9 @@ func (m *MockClientGenerator) OpenShiftClient() (openshift.Interface, error) {
 	return nil, args.Error(1)
 }
 
-type ByNamesTestSuite struct {
-	suite.Suite
-}
-
-func (suite *ByNamesTestSuite) TestAllInitialized() {
-	mockClientGenerator := new(MockClientGenerator)
-	mockClientGenerator.On("KubeClient").Return(fakeKube.NewSimpleClientset(), nil)
-	mockClientGenerator.On("IstioClient").Return(istiofake.NewSimpleClientset(), nil)
-	mockClientGenerator.On("DynamicKubernetesClient").Return(fakeDynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+// newByNamesDynamicClient returns a fake dynamic client listing the custom resources of the sources.
+func newByNamesDynamicClient() dynamic.Interface {
+	return fakeDynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
 		map[schema.GroupVersionResource]string{
 			{
 				Group:    "projectcontour.io",
@@ -160,13 +158,76 @@ func (suite *ByNamesTestSuite) TestAllInitialized() {
 				Version:  "v1alpha1",
 				Resource: "ingressrouteudps",
 			}: "IngressRouteUDPList",
-		}), nil)
+		})
+}
+
+type ByNamesTestSuite struct {
+	suite.Suite
+}
+
+func (suite *ByNamesTestSuite) TestAllInitialized() {
+	mockClientGenerator := new(MockClientGenerator)
+	mockClientGenerator.On("KubeClient").Return(fakeKube.NewSimpleClientset(), nil)
+	mockClientGenerator.On("IstioClient").Return(istiofake.NewSimpleClientset(), nil)
+	mockClientGenerator.On("DynamicKubernetesClient").Return(newByNamesDynamicClient(), nil)
 
 	sources, err := ByNames(context.TODO(), mockClientGenerator, []string{"service", "ingress", "istio-gateway", "contour-httpproxy", "kong-tcpingress", "f5-virtualserver", "traefik-proxy", "fake"}, &Config{})
 	suite.NoError(err, "should not generate errors")
 	suite.Len(sources, 8, "should generate all eight sources")
 }
 
+func (suite *ByNamesTestSuite) TestMultipleNamespaces() {
+	mockClientGenerator := new(MockClientGenerator)
+	mockClientGenerator.On("KubeClient").Return(fakeKube.NewSimpleClientset(), nil)
+
+	sources, err := ByNames(context.TODO(), mockClientGenerator, []string{"service", "fake"}, &Config{
+		LabelFilter:       labels.Everything(),
+		Namespaces:        []string{"default", "other", "default"},
+		ExcludeNamespaces: []string{"kube-system"},
+	})
+	suite.NoError(err, "should not generate errors")
+	suite.Len(sources, 3, "should generate a service source per namespace and a single fake source")
+}
+
+func (suite *ByNamesTestSuite) TestNamespacedSourcesScoped() {
+	mockClientGenerator := new(MockClientGenerator)
+	mockClientGenerator.On("KubeClient").Return(fakeKube.NewSimpleClientset(), nil)
+	mockClientGenerator.On("IstioClient").Return(istiofake.NewSimpleClientset(), nil)
+	mockClientGenerator.On("DynamicKubernetesClient").Return(newByNamesDynamicClient(), nil)
+
+	names := []string{"service", "ingress", "pod", "istio-gateway", "istio-virtualservice", "contour-httpproxy", "kong-tcpingress", "f5-virtualserver", "traefik-proxy", "skipper-routegroup"}
+	sources, err := ByNames(context.TODO(), mockClientGenerator, names, &Config{
+		APIServerURL: "http://127.0.0.1:8080",
+		KubeConfig:   "/nonexistent",
+		Namespaces:   []string{"foo"},
+	})
+	suite.Require().NoError(err, "should not generate errors")
+	suite.Require().Len(sources, len(names), "should generate a source per name")
+	for i, name := range names {
+		suite.True(namespacedSources[name], "%s should be namespaced", name)
+		suite.Equal(name+"/foo", sources[i].(*instrumentedSource).name, "%s should be built for the namespace", name)
+	}
+	routeGroups := sources[len(names)-1].(*instrumentedSource).Source.(*routeGroupSource)
+	suite.Equal("foo", routeGroups.namespace)
+	suite.Contains(routeGroups.apiEndpoint, "/namespaces/foo/routegroups")
+}
+
+func (suite *ByNamesTestSuite) TestGlooExcludeNamespaces() {
+	mockClientGenerator := new(MockClientGenerator)
+	mockClientGenerator.On("KubeClient").Return(fakeKube.NewSimpleClientset(), nil)
+	mockClientGenerator.On("DynamicKubernetesClient").Return(newByNamesDynamicClient(), nil)
+
+	sources, err := ByNames(context.TODO(), mockClientGenerator, []string{"gloo-proxy"}, &Config{
+		GlooNamespaces:    []string{"gloo-system"},
+		ExcludeNamespaces: []string{"kube-system"},
+	})
+	suite.Require().NoError(err, "should not generate errors")
+	suite.Require().Len(sources, 1, "should generate a single gloo source")
+	listOptions := metav1.ListOptions{}
+	sources[0].(*instrumentedSource).Source.(*glooSource).listOptions(&listOptions)
+	suite.Equal("metadata.namespace!=kube-system", listOptions.FieldSelector)
+}
+
 func (suite *ByNamesTestSuite) TestOnlyFake() {
 	mockClientGenerator := new(MockClientGenerator)
 	mockClientGenerator.On("KubeClient").Return(fakeKube.NewSimpleClientset(), nil)
@@ -219,3 +280,44 @@ func (suite *ByNamesTestSuite) TestIstioClientFails() {
 func TestByNames(t *testing.T) {
 	suite.Run(t, new(ByNamesTestSuite))
 }
+
+func TestConfigNamespaces(t *testing.T) {
+	for _, tc := range []struct {
+		title    string
+		cfg      Config
+		expected []string
+	}{
+		{
+			title:    "all namespaces by default",
+			cfg:      Config{},
+			expected: []string{""},
+		},
+		{
+			title:    "single namespace",
+			cfg:      Config{Namespace: "default"},
+			expected: []string{"default"},
+		},
+		{
+			title:    "multiple namespaces override the namespace",
+			cfg:      Config{Namespace: "default", Namespaces: []string{"one", "two", "one"}},
+			expected: []string{"one", "two"},
+		},
+		{
+			title:    "empty namespace selects all namespaces",
+			cfg:      Config{Namespaces: []string{"one", ""}},
+			expected: []string{""},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.cfg.namespaces())
+		})
+	}
+}
+
+func TestExcludeNamespaces(t *testing.T) {
+	assert.Nil(t, excludeNamespaces(nil, nil))
+	assert.Nil(t, excludeNamespaces(nil, []string{""}))
+	assert.Equal(t, "metadata.namespace!=kube-system", excludeNamespaces(nil, []string{"kube-system"}).String())
+	assert.Equal(t, "metadata.name!=foo,metadata.namespace!=kube-system,metadata.namespace!=monitoring",
+		excludeNamespaces(fields.OneTermNotEqualSelector("metadata.name", "foo"), []string{"kube-system", "monitoring"}).String())
+}
@@ -0,0 +1,256 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// The formats of the target health read by NewTargetHealthSource.
+const (
+	// TargetHealthFormatJSON is a JSON object of the hostnames, each an object of its targets and
+	// whether they are healthy, e.g. {"web.example.org": {"10.0.0.1": true, "10.0.0.2": false}}.
+	TargetHealthFormatJSON = "json"
+	// TargetHealthFormatPrometheus is the Prometheus text format, with a metric labeled with the
+	// hostname and the target, whose value is 0 for the unhealthy targets, e.g.
+	// target_healthy{hostname="web.example.org",target="10.0.0.2"} 0.
+	TargetHealthFormatPrometheus = "prometheus"
+)
+
+const (
+	// targetHealthTimeout is the longest a query of the health of the targets may take, so a
+	// hanging health checker doesn't stall the sync.
+	targetHealthTimeout = 10 * time.Second
+	// targetHealthExpiryIntervals is the number of intervals after which the health queried last
+	// expires when the queries keep failing.
+	targetHealthExpiryIntervals = 3
+)
+
+var (
+	unhealthyTargets = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "external_dns",
+			Subsystem: "source",
+			Name:      "unhealthy_targets",
+			Help:      "Number of targets dropped from the endpoints as unhealthy on the last listing.",
+		},
+	)
+	targetHealthErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "external_dns",
+			Subsystem: "source",
+			Name:      "target_health_errors_total",
+			Help:      "Number of errors querying the health of the targets.",
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(unhealthyTargets)
+	prometheus.MustRegister(targetHealthErrors)
+}
+
+// targetHealth is whether the targets of the hostnames are healthy.
+type targetHealth map[string]map[string]bool
+
+// targetHealthSource is a Source that drops the unhealthy targets of the endpoints of its wrapped
+// source with several targets, as reported by an external health checker, for the providers without
+// health checked routing.
+type targetHealthSource struct {
+	source   Source
+	url      string
+	format   string
+	metric   string
+	interval time.Duration
+	client   *http.Client
+	now      func() time.Time
+
+	mu      sync.Mutex
+	health  targetHealth
+	queried time.Time
+	updated time.Time
+}
+
+// NewTargetHealthSource creates a new targetHealthSource wrapping the provided Source, querying the
+// health of the targets from the URL in the format, at most once per interval. The metric is the
+// name of the metric of the Prometheus format.
+func NewTargetHealthSource(source Source, url, format, metric string, interval time.Duration) Source {
+	return &targetHealthSource{
+		source:   source,
+		url:      url,
+		format:   format,
+		metric:   metric,
+		interval: interval,
+		client:   &http.Client{Timeout: min(targetHealthTimeout, interval)},
+		now:      time.Now,
+	}
+}
+
+// Endpoints collects endpoints from its wrapped source and returns them without their unhealthy
+// targets. The targets of unknown health are kept, and so are all the targets of an endpoint when
+// none of them is healthy, like the health checked routing of the providers failing open.
+func (ts *targetHealthSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ts.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	health := ts.targetHealth(ctx)
+	dropped := 0
+	for _, ep := range endpoints {
+		targets, ok := health[ep.DNSName]
+		if !ok || len(ep.Targets) < 2 {
+			continue
+		}
+		healthy := make(endpoint.Targets, 0, len(ep.Targets))
+		for _, target := range ep.Targets {
+			if h, ok := targets[target]; !ok || h {
+				healthy = append(healthy, target)
+			}
+		}
+		if len(healthy) == len(ep.Targets) {
+			continue
+		}
+		if len(healthy) == 0 {
+			log.Warnf("All the targets of %s are unhealthy, keeping them all", ep.DNSName)
+			continue
+		}
+		log.Debugf("Dropping %d unhealthy targets of the %d targets of %s", len(ep.Targets)-len(healthy), len(ep.Targets), ep.DNSName)
+		dropped += len(ep.Targets) - len(healthy)
+		ep.Targets = healthy
+	}
+	unhealthyTargets.Set(float64(dropped))
+
+	return endpoints, nil
+}
+
+// targetHealth returns the health of the targets, queried again once the interval has passed. The
+// last health queried is kept when the query fails, until it expires after a few intervals and all
+// the targets are kept, failing open.
+func (ts *targetHealthSource) targetHealth(ctx context.Context) targetHealth {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	now := ts.now()
+	if !ts.queried.IsZero() && now.Sub(ts.queried) < ts.interval {
+		return ts.health
+	}
+	ts.queried = now
+	health, err := ts.query(ctx)
+	if err != nil {
+		targetHealthErrors.Inc()
+		if ts.health != nil && now.Sub(ts.updated) >= targetHealthExpiryIntervals*ts.interval {
+			log.Warnf("Failed to query the health of the targets, the last health queried at %s expired, keeping all the targets: %v", ts.updated.Format(time.RFC3339), err)
+			ts.health = nil
+			return nil
+		}
+		log.Warnf("Failed to query the health of the targets, using the last health queried: %v", err)
+		return ts.health
+	}
+	ts.health = health
+	ts.updated = now
+	return health
+}
+
+// query queries the health of the targets from the URL.
+func (ts *targetHealthSource) query(ctx context.Context) (targetHealth, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := ts.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s from %s", resp.Status, ts.url)
+	}
+
+	if ts.format == TargetHealthFormatPrometheus {
+		return parsePrometheusTargetHealth(resp.Body, ts.metric)
+	}
+	var health targetHealth
+	if err := json.NewDecoder(resp.Body).Decode(&health); err != nil {
+		return nil, fmt.Errorf("failed to decode the health of the targets: %w", err)
+	}
+	return health, nil
+}
+
+// parsePrometheusTargetHealth returns the health of the targets given by the samples of the metric,
+// labeled with the hostname and the target, in the Prometheus text format.
+func parsePrometheusTargetHealth(r io.Reader, metric string) (targetHealth, error) {
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the health of the targets: %w", err)
+	}
+	family, ok := families[metric]
+	if !ok {
+		return nil, fmt.Errorf("metric %s not found", metric)
+	}
+
+	health := targetHealth{}
+	for _, m := range family.GetMetric() {
+		var hostname, target string
+		for _, label := range m.GetLabel() {
+			switch label.GetName() {
+			case "hostname":
+				hostname = label.GetValue()
+			case "target":
+				target = label.GetValue()
+			}
+		}
+		if hostname == "" || target == "" {
+			continue
+		}
+		if health[hostname] == nil {
+			health[hostname] = map[string]bool{}
+		}
+		health[hostname][target] = sampleValue(m) > 0
+	}
+	return health, nil
+}
+
+// sampleValue returns the value of the sample of a gauge, counter or untyped metric.
+func sampleValue(m *dto.Metric) float64 {
+	switch {
+	case m.Gauge != nil:
+		return m.GetGauge().GetValue()
+	case m.Counter != nil:
+		return m.GetCounter().GetValue()
+	default:
+		return m.GetUntyped().GetValue()
+	}
+}
+
+func (ts *targetHealthSource) AddEventHandler(ctx context.Context, handler func()) {
+	ts.source.AddEventHandler(ctx, handler)
+}
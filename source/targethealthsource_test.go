@@ -0,0 +1,151 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func targetHealthTestEndpoints() []*endpoint.Endpoint {
+	return []*endpoint.Endpoint{
+		endpoint.NewEndpoint("web.example.org", endpoint.RecordTypeA, "10.0.0.1", "10.0.0.2", "10.0.0.3"),
+		endpoint.NewEndpoint("db.example.org", endpoint.RecordTypeA, "10.0.1.1", "10.0.1.2"),
+		endpoint.NewEndpoint("cache.example.org", endpoint.RecordTypeA, "10.0.2.1"),
+	}
+}
+
+func TestTargetHealthSource(t *testing.T) {
+	for _, tc := range []struct {
+		format string
+		body   string
+	}{
+		{
+			format: TargetHealthFormatJSON,
+			body: `{"web.example.org": {"10.0.0.1": true, "10.0.0.2": false},
+				"db.example.org": {"10.0.1.1": false, "10.0.1.2": false},
+				"cache.example.org": {"10.0.2.1": false}}`,
+		},
+		{
+			format: TargetHealthFormatPrometheus,
+			body: `# TYPE target_healthy gauge
+target_healthy{hostname="web.example.org",target="10.0.0.1"} 1
+target_healthy{hostname="web.example.org",target="10.0.0.2"} 0
+target_healthy{hostname="db.example.org",target="10.0.1.1"} 0
+target_healthy{hostname="db.example.org",target="10.0.1.2"} 0
+target_healthy{hostname="cache.example.org",target="10.0.2.1"} 0
+`,
+		},
+	} {
+		t.Run(tc.format, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Write([]byte(tc.body))
+			}))
+			defer server.Close()
+
+			s := NewTargetHealthSource(NewEchoSource(targetHealthTestEndpoints()), server.URL, tc.format, "target_healthy", time.Minute)
+			endpoints, err := s.Endpoints(context.Background())
+			require.NoError(t, err)
+			// the targets of unknown health are kept
+			assert.Equal(t, endpoint.Targets{"10.0.0.1", "10.0.0.3"}, endpoints[0].Targets)
+			// all the targets are kept when none is healthy
+			assert.Equal(t, endpoint.Targets{"10.0.1.1", "10.0.1.2"}, endpoints[1].Targets)
+			// the records of a single target are left alone
+			assert.Equal(t, endpoint.Targets{"10.0.2.1"}, endpoints[2].Targets)
+			assert.Equal(t, 1.0, gaugeValue(t, unhealthyTargets))
+		})
+	}
+}
+
+func TestTargetHealthSourceInterval(t *testing.T) {
+	queries := 0
+	healthy := false
+	failing := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		queries++
+		if failing {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if healthy {
+			w.Write([]byte(`{"web.example.org": {"10.0.0.2": true}}`))
+			return
+		}
+		w.Write([]byte(`{"web.example.org": {"10.0.0.2": false}}`))
+	}))
+	defer server.Close()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := NewTargetHealthSource(NewEchoSource(nil), server.URL, TargetHealthFormatJSON, "", time.Minute).(*targetHealthSource)
+	s.now = func() time.Time { return now }
+	targets := func() endpoint.Targets {
+		s.source = NewEchoSource(targetHealthTestEndpoints())
+		endpoints, err := s.Endpoints(context.Background())
+		require.NoError(t, err)
+		return endpoints[0].Targets
+	}
+
+	assert.Equal(t, endpoint.Targets{"10.0.0.1", "10.0.0.3"}, targets())
+	healthy = true
+	assert.Equal(t, endpoint.Targets{"10.0.0.1", "10.0.0.3"}, targets(), "queried at most once per interval")
+	assert.Equal(t, 1, queries)
+
+	now = now.Add(time.Minute)
+	assert.Equal(t, endpoint.Targets{"10.0.0.1", "10.0.0.2", "10.0.0.3"}, targets())
+
+	healthy = false
+	now = now.Add(time.Minute)
+	assert.Equal(t, endpoint.Targets{"10.0.0.1", "10.0.0.3"}, targets())
+
+	// the last health queried is kept when the query fails, until it expires
+	failing = true
+	for i := 1; i < targetHealthExpiryIntervals; i++ {
+		now = now.Add(time.Minute)
+		assert.Equal(t, endpoint.Targets{"10.0.0.1", "10.0.0.3"}, targets())
+	}
+	now = now.Add(time.Minute)
+	assert.Equal(t, endpoint.Targets{"10.0.0.1", "10.0.0.2", "10.0.0.3"}, targets(), "all targets kept once the health expired")
+	assert.Equal(t, 3+targetHealthExpiryIntervals, queries)
+}
+
+func TestTargetHealthSourceTimeout(t *testing.T) {
+	s := NewTargetHealthSource(NewEchoSource(nil), "http://localhost", TargetHealthFormatJSON, "", time.Minute).(*targetHealthSource)
+	assert.Equal(t, targetHealthTimeout, s.client.Timeout)
+
+	s = NewTargetHealthSource(NewEchoSource(nil), "http://localhost", TargetHealthFormatJSON, "", time.Second).(*targetHealthSource)
+	assert.Equal(t, time.Second, s.client.Timeout)
+}
+
+func TestParsePrometheusTargetHealth(t *testing.T) {
+	_, err := parsePrometheusTargetHealth(strings.NewReader("other_metric 1\n"), "target_healthy")
+	assert.ErrorContains(t, err, "metric target_healthy not found")
+
+	health, err := parsePrometheusTargetHealth(strings.NewReader(`target_healthy{hostname="web.example.org",target="10.0.0.1"} 1
+target_healthy{hostname="web.example.org"} 0
+`), "target_healthy")
+	require.NoError(t, err)
+	assert.Equal(t, targetHealth{"web.example.org": {"10.0.0.1": true}}, health)
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"hash/fnv"
+	"net/netip"
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// The strategies selecting the targets kept by NewTargetLimitSource.
+const (
+	// TargetSelectionStableHash keeps the targets of the highest hashes of the DNS name and the
+	// target, so that a target keeps being selected, or not, whatever the other targets.
+	TargetSelectionStableHash = "stable-hash"
+	// TargetSelectionLowest keeps the lowest targets, the IP addresses by value and the others
+	// alphabetically.
+	TargetSelectionLowest = "lowest"
+	// TargetSelectionReady keeps the first targets in the order of the source, which lists the
+	// ready addresses of the headless services before the not ready ones.
+	TargetSelectionReady = "ready"
+)
+
+var truncatedTargets = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Namespace: "external_dns",
+		Subsystem: "source",
+		Name:      "truncated_targets",
+		Help:      "Number of targets dropped from the endpoints above the maximum number of targets on the last listing.",
+	},
+)
+
+func init() {
+	prometheus.MustRegister(truncatedTargets)
+}
+
+// targetLimitSource is a Source that truncates the targets of the endpoints of its wrapped source
+// to a maximum number, selected by a strategy rather than by the arbitrary order of the targets.
+type targetLimitSource struct {
+	source     Source
+	maxTargets int
+	selection  string
+}
+
+// NewTargetLimitSource creates a new targetLimitSource wrapping the provided Source, keeping at
+// most maxTargets targets per endpoint as selected by the selection strategy.
+func NewTargetLimitSource(source Source, maxTargets int, selection string) Source {
+	return &targetLimitSource{source: source, maxTargets: maxTargets, selection: selection}
+}
+
+// Endpoints collects endpoints from its wrapped source and returns them with at most maxTargets
+// targets each.
+func (ts *targetLimitSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ts.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	dropped := 0
+	for _, ep := range endpoints {
+		if len(ep.Targets) <= ts.maxTargets {
+			continue
+		}
+		log.Debugf("Keeping %d of the %d targets of %s by %s", ts.maxTargets, len(ep.Targets), ep.DNSName, ts.selection)
+		dropped += len(ep.Targets) - ts.maxTargets
+		ep.Targets = ts.selectTargets(ep)
+	}
+	truncatedTargets.Set(float64(dropped))
+
+	return endpoints, nil
+}
+
+// selectTargets returns the maxTargets targets of the endpoint kept by the selection strategy, in
+// their original order.
+func (ts *targetLimitSource) selectTargets(ep *endpoint.Endpoint) endpoint.Targets {
+	indexes := make([]int, len(ep.Targets))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	switch ts.selection {
+	case TargetSelectionStableHash:
+		hashes := make([]uint64, len(ep.Targets))
+		for i, target := range ep.Targets {
+			hashes[i] = stableHash(ep.DNSName, target)
+		}
+		sort.SliceStable(indexes, func(i, j int) bool { return hashes[indexes[i]] > hashes[indexes[j]] })
+	case TargetSelectionLowest:
+		sort.SliceStable(indexes, func(i, j int) bool { return lowerTarget(ep.Targets[indexes[i]], ep.Targets[indexes[j]]) })
+	}
+
+	kept := indexes[:ts.maxTargets]
+	sort.Ints(kept)
+	targets := make(endpoint.Targets, 0, len(kept))
+	for _, i := range kept {
+		targets = append(targets, ep.Targets[i])
+	}
+	return targets
+}
+
+// stableHash returns the hash of the target for the key, e.g. the DNS name of its endpoint, which
+// ranks the targets of the key the same whatever the other targets.
+func stableHash(key, target string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key + "/" + target))
+	return h.Sum64()
+}
+
+// lowerTarget reports whether the target a sorts before b: by value when both are IP addresses,
+// alphabetically otherwise.
+func lowerTarget(a, b string) bool {
+	ipA, errA := netip.ParseAddr(a)
+	ipB, errB := netip.ParseAddr(b)
+	if errA == nil && errB == nil {
+		return ipA.Less(ipB)
+	}
+	return a < b
+}
+
+func (ts *targetLimitSource) AddEventHandler(ctx context.Context, handler func()) {
+	ts.source.AddEventHandler(ctx, handler)
+}
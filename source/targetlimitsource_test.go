@@ -0,0 +1,79 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func TestTargetLimitSource(t *testing.T) {
+	for _, tc := range []struct {
+		selection string
+		expected  endpoint.Targets
+	}{
+		{selection: TargetSelectionLowest, expected: endpoint.Targets{"10.0.0.9", "10.0.0.2"}},
+		{selection: TargetSelectionReady, expected: endpoint.Targets{"10.0.0.10", "10.0.0.9"}},
+	} {
+		t.Run(tc.selection, func(t *testing.T) {
+			s := NewTargetLimitSource(NewEchoSource([]*endpoint.Endpoint{
+				endpoint.NewEndpoint("web.example.org", endpoint.RecordTypeA, "10.0.0.10", "10.0.0.9", "10.0.0.2"),
+				endpoint.NewEndpoint("db.example.org", endpoint.RecordTypeA, "10.0.1.1"),
+			}), 2, tc.selection)
+
+			endpoints, err := s.Endpoints(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, endpoints[0].Targets)
+			assert.Equal(t, endpoint.Targets{"10.0.1.1"}, endpoints[1].Targets)
+			assert.Equal(t, 1.0, gaugeValue(t, truncatedTargets))
+		})
+	}
+}
+
+func TestTargetLimitSourceStableHash(t *testing.T) {
+	targets := endpoint.Targets{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4", "10.0.0.5", "10.0.0.6"}
+	limit := func(targets ...string) endpoint.Targets {
+		s := NewTargetLimitSource(NewEchoSource([]*endpoint.Endpoint{
+			endpoint.NewEndpoint("web.example.org", endpoint.RecordTypeA, targets...),
+		}), 3, TargetSelectionStableHash)
+		endpoints, err := s.Endpoints(context.Background())
+		require.NoError(t, err)
+		return endpoints[0].Targets
+	}
+
+	kept := limit(targets...)
+	require.Len(t, kept, 3)
+	// the selection doesn't depend on the order of the targets
+	reversed := limit("10.0.0.6", "10.0.0.5", "10.0.0.4", "10.0.0.3", "10.0.0.2", "10.0.0.1")
+	sort.Sort(reversed)
+	assert.Equal(t, kept, reversed)
+
+	// nor does removing a target which isn't kept change the selection
+	var others []string
+	for _, target := range targets {
+		if target != kept[0] && target != kept[1] && target != kept[2] {
+			others = append(others, target)
+		}
+	}
+	assert.Equal(t, kept, limit(append(endpoint.Targets{kept[0], kept[1], kept[2]}, others[1:]...)...))
+}
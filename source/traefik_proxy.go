@@ -27,6 +27,7 @@ import (
 	log "github.com/sirupsen/logrus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -93,10 +94,10 @@ type traefikSource struct {
 	unstructuredConverter      *unstructuredConverter
 }
 
-func NewTraefikSource(ctx context.Context, dynamicKubeClient dynamic.Interface, kubeClient kubernetes.Interface, namespace string, annotationFilter string, ignoreHostnameAnnotation bool, disableLegacy bool, disableNew bool) (Source, error) {
+func NewTraefikSource(ctx context.Context, dynamicKubeClient dynamic.Interface, kubeClient kubernetes.Interface, namespace string, annotationFilter string, ignoreHostnameAnnotation bool, labelSelector labels.Selector, fieldSelector fields.Selector, disableLegacy bool, disableNew bool) (Source, error) {
 	// Use shared informer to listen for add/update/delete of Host in the specified namespace.
 	// Set resync period to 0, to prevent processing when nothing has changed.
-	informerFactory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(dynamicKubeClient, 0, namespace, nil)
+	informerFactory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(dynamicKubeClient, 0, namespace, newListOptionsTweak(labelSelector, fieldSelector))
 	var ingressRouteInformer, ingressRouteTcpInformer, ingressRouteUdpInformer informers.GenericInformer
 	var oldIngressRouteInformer, oldIngressRouteTcpInformer, oldIngressRouteUdpInformer informers.GenericInformer
 
@@ -259,6 +260,13 @@ func (ts *traefikSource) ingressRouteEndpoints() ([]*endpoint.Endpoint, error) {
 
 		fullname := fmt.Sprintf("%s/%s", ingressRoute.Namespace, ingressRoute.Name)
 
+		// Check controller annotation to see if we are responsible.
+		if controller, ok := ingressRoute.Annotations[controllerAnnotationKey]; ok && controller != controllerAnnotationValue {
+			log.Debugf("Skipping IngressRoute %s because controller value does not match, found: %s, required: %s",
+				fullname, controller, controllerAnnotationValue)
+			continue
+		}
+
 		ingressEndpoints, err := ts.endpointsFromIngressRoute(ingressRoute, targets)
 		if err != nil {
 			return nil, err
@@ -312,6 +320,13 @@ func (ts *traefikSource) ingressRouteTCPEndpoints() ([]*endpoint.Endpoint, error
 
 		fullname := fmt.Sprintf("%s/%s", ingressRouteTCP.Namespace, ingressRouteTCP.Name)
 
+		// Check controller annotation to see if we are responsible.
+		if controller, ok := ingressRouteTCP.Annotations[controllerAnnotationKey]; ok && controller != controllerAnnotationValue {
+			log.Debugf("Skipping IngressRouteTCP %s because controller value does not match, found: %s, required: %s",
+				fullname, controller, controllerAnnotationValue)
+			continue
+		}
+
 		ingressEndpoints, err := ts.endpointsFromIngressRouteTCP(ingressRouteTCP, targets)
 		if err != nil {
 			return nil, err
@@ -365,6 +380,13 @@ func (ts *traefikSource) ingressRouteUDPEndpoints() ([]*endpoint.Endpoint, error
 
 		fullname := fmt.Sprintf("%s/%s", ingressRouteUDP.Namespace, ingressRouteUDP.Name)
 
+		// Check controller annotation to see if we are responsible.
+		if controller, ok := ingressRouteUDP.Annotations[controllerAnnotationKey]; ok && controller != controllerAnnotationValue {
+			log.Debugf("Skipping IngressRouteUDP %s because controller value does not match, found: %s, required: %s",
+				fullname, controller, controllerAnnotationValue)
+			continue
+		}
+
 		ingressEndpoints, err := ts.endpointsFromIngressRouteUDP(ingressRouteUDP, targets)
 		if err != nil {
 			return nil, err
@@ -418,6 +440,13 @@ func (ts *traefikSource) oldIngressRouteEndpoints() ([]*endpoint.Endpoint, error
 
 		fullname := fmt.Sprintf("%s/%s", ingressRoute.Namespace, ingressRoute.Name)
 
+		// Check controller annotation to see if we are responsible.
+		if controller, ok := ingressRoute.Annotations[controllerAnnotationKey]; ok && controller != controllerAnnotationValue {
+			log.Debugf("Skipping IngressRoute %s because controller value does not match, found: %s, required: %s",
+				fullname, controller, controllerAnnotationValue)
+			continue
+		}
+
 		ingressEndpoints, err := ts.endpointsFromIngressRoute(ingressRoute, targets)
 		if err != nil {
 			return nil, err
@@ -471,6 +500,13 @@ func (ts *traefikSource) oldIngressRouteTCPEndpoints() ([]*endpoint.Endpoint, er
 
 		fullname := fmt.Sprintf("%s/%s", ingressRouteTCP.Namespace, ingressRouteTCP.Name)
 
+		// Check controller annotation to see if we are responsible.
+		if controller, ok := ingressRouteTCP.Annotations[controllerAnnotationKey]; ok && controller != controllerAnnotationValue {
+			log.Debugf("Skipping IngressRouteTCP %s because controller value does not match, found: %s, required: %s",
+				fullname, controller, controllerAnnotationValue)
+			continue
+		}
+
 		ingressEndpoints, err := ts.endpointsFromIngressRouteTCP(ingressRouteTCP, targets)
 		if err != nil {
 			return nil, err
@@ -524,6 +560,13 @@ func (ts *traefikSource) oldIngressRouteUDPEndpoints() ([]*endpoint.Endpoint, er
 
 		fullname := fmt.Sprintf("%s/%s", ingressRouteUDP.Namespace, ingressRouteUDP.Name)
 
+		// Check controller annotation to see if we are responsible.
+		if controller, ok := ingressRouteUDP.Annotations[controllerAnnotationKey]; ok && controller != controllerAnnotationValue {
+			log.Debugf("Skipping IngressRouteUDP %s because controller value does not match, found: %s, required: %s",
+				fullname, controller, controllerAnnotationValue)
+			continue
+		}
+
 		ingressEndpoints, err := ts.endpointsFromIngressRouteUDP(ingressRouteUDP, targets)
 		if err != nil {
 			return nil, err
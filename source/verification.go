@@ -0,0 +1,233 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+var verificationGVR = schema.GroupVersionResource{
+	Group:    "externaldns.k8s.io",
+	Version:  "v1alpha1",
+	Resource: "verifications",
+}
+
+// verificationSource is an implementation of Source that provides the TXT records of the
+// Verification objects until their confirmation.
+type verificationSource struct {
+	dynamicKubeClient dynamic.Interface
+	informer          informers.GenericInformer
+	namespace         string
+	annotationFilter  string
+}
+
+// NewVerificationSource creates a new verificationSource with the given config.
+func NewVerificationSource(
+	ctx context.Context,
+	dynamicKubeClient dynamic.Interface,
+	namespace string,
+	annotationFilter string,
+	labelSelector labels.Selector,
+	fieldSelector fields.Selector,
+) (Source, error) {
+	informerFactory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(dynamicKubeClient, 0, namespace, newListOptionsTweak(labelSelector, fieldSelector))
+	informer := informerFactory.ForResource(verificationGVR)
+	informer.Informer() // Register with factory before starting.
+
+	informerFactory.Start(ctx.Done())
+
+	// wait for the local cache to be populated.
+	if err := waitForDynamicCacheSync(context.Background(), informerFactory); err != nil {
+		return nil, err
+	}
+
+	return &verificationSource{
+		dynamicKubeClient: dynamicKubeClient,
+		informer:          informer,
+		namespace:         namespace,
+		annotationFilter:  annotationFilter,
+	}, nil
+}
+
+// verificationRecord is the TXT record of a Verification.
+type verificationRecord struct {
+	resource string
+	name     string
+	value    string
+	ttl      endpoint.TTL
+	// removeAt is when the record is removed after the confirmation, zero until it is confirmed
+	removeAt time.Time
+}
+
+// Endpoints returns the TXT records of the Verifications which are not removed yet, and records
+// their confirmation in the status of the Verifications.
+func (vs *verificationSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	selector, err := getLabelSelector(vs.annotationFilter)
+	if err != nil {
+		return nil, err
+	}
+	objs, err := vs.informer.Lister().ByNamespace(vs.namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var records []verificationRecord
+	for _, obj := range objs {
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			return nil, fmt.Errorf("unexpected verification object %T", obj)
+		}
+		if !matchLabelSelector(selector, u.GetAnnotations()) {
+			continue
+		}
+		if controller, ok := u.GetAnnotations()[controllerAnnotationKey]; ok && controller != controllerAnnotationValue {
+			log.Debugf("Skipping verification %s/%s because controller value does not match, found: %s, required: %s",
+				u.GetNamespace(), u.GetName(), controller, controllerAnnotationValue)
+			continue
+		}
+
+		verification := &endpoint.Verification{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, verification); err != nil {
+			log.Warnf("Skipping verification %s/%s: %v", u.GetNamespace(), u.GetName(), err)
+			continue
+		}
+		if err := verification.Validate(); err != nil {
+			log.Warnf("Skipping invalid verification %s/%s: %v", u.GetNamespace(), u.GetName(), err)
+			continue
+		}
+
+		record, status := verificationRecordOf(verification, now)
+		if !status.Removed {
+			records = append(records, record)
+		}
+
+		if equality.Semantic.DeepEqual(verification.Status, status) {
+			continue
+		}
+		if status.Removed {
+			log.Infof("Removing the %s verification record %s of verification %s/%s, confirmed at %s", verification.Spec.Template, record.name,
+				verification.Namespace, verification.Name, status.ConfirmedAt.UTC().Format(time.RFC3339))
+		}
+		if err := vs.updateStatus(ctx, u, status); err != nil {
+			log.Warnf("Could not update the status of verification %s/%s: %v", verification.Namespace, verification.Name, err)
+		}
+	}
+
+	return verificationEndpoints(records), nil
+}
+
+func (vs *verificationSource) AddEventHandler(ctx context.Context, handler func()) {
+	log.Debug("Adding event handler for verification")
+
+	vs.informer.Informer().AddEventHandler(eventHandlerFunc(handler))
+}
+
+// updateStatus replaces the status of the Verification object.
+func (vs *verificationSource) updateStatus(ctx context.Context, u *unstructured.Unstructured, status endpoint.VerificationStatus) error {
+	value, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&status)
+	if err != nil {
+		return err
+	}
+	u = u.DeepCopy()
+	if err := unstructured.SetNestedField(u.Object, value, "status"); err != nil {
+		return err
+	}
+	_, err = vs.dynamicKubeClient.Resource(verificationGVR).Namespace(u.GetNamespace()).UpdateStatus(ctx, u, metav1.UpdateOptions{})
+	return err
+}
+
+// verificationRecordOf returns the record of the valid Verification and its status at now. The
+// confirmation is observed at the first time the Verification is confirmed, and the record is
+// removed retainAfterConfirmation later.
+func verificationRecordOf(verification *endpoint.Verification, now time.Time) (verificationRecord, endpoint.VerificationStatus) {
+	name, value, _ := verification.Record()
+	record := verificationRecord{
+		resource: fmt.Sprintf("verification/%s/%s", verification.Namespace, verification.Name),
+		name:     name,
+		value:    value,
+		ttl:      verification.Spec.RecordTTL,
+	}
+	status := endpoint.VerificationStatus{ObservedGeneration: verification.Generation}
+	if !verification.Spec.Confirmed {
+		return record, status
+	}
+
+	status.ConfirmedAt = verification.Status.ConfirmedAt
+	if status.ConfirmedAt == nil {
+		confirmedAt := metav1.NewTime(now.Truncate(time.Second))
+		status.ConfirmedAt = &confirmedAt
+	}
+	record.removeAt = status.ConfirmedAt.Time
+	if verification.Spec.RetainAfterConfirmation != nil {
+		record.removeAt = record.removeAt.Add(verification.Spec.RetainAfterConfirmation.Duration)
+	}
+	status.Removed = !now.Before(record.removeAt)
+	return record, status
+}
+
+// verificationEndpoints returns the TXT endpoints of the records, the values of the records of the
+// same name, e.g. the verifications of several providers at the apex of a domain, being the targets
+// of a single endpoint. An endpoint is active until the first of its records is removed, the
+// others being published again by the next reconciliation.
+func verificationEndpoints(records []verificationRecord) []*endpoint.Endpoint {
+	byName := map[string]*endpoint.Endpoint{}
+	removeAt := map[string]time.Time{}
+	var endpoints []*endpoint.Endpoint
+	for _, record := range records {
+		ep, ok := byName[record.name]
+		if !ok {
+			ep = endpoint.NewEndpointWithTTL(record.name, endpoint.RecordTypeTXT, record.ttl)
+			ep.Labels[endpoint.ResourceLabelKey] = record.resource
+			byName[record.name] = ep
+			endpoints = append(endpoints, ep)
+		}
+		ep.Targets = append(ep.Targets, record.value)
+		if !ep.RecordTTL.IsConfigured() || (record.ttl.IsConfigured() && record.ttl < ep.RecordTTL) {
+			ep.RecordTTL = record.ttl
+		}
+		if !record.removeAt.IsZero() && (removeAt[record.name].IsZero() || record.removeAt.Before(removeAt[record.name])) {
+			removeAt[record.name] = record.removeAt
+		}
+	}
+
+	for _, ep := range endpoints {
+		sort.Sort(ep.Targets)
+		if at := removeAt[ep.DNSName]; !at.IsZero() {
+			ep.WithProviderSpecific(ActiveUntilKey, at.UTC().Format(time.RFC3339))
+		}
+	}
+	sort.Slice(endpoints, func(i, j int) bool { return endpoints[i].DNSName < endpoints[j].DNSName })
+	return endpoints
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func TestVerificationRecordOf(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 500, time.UTC)
+	confirmedAt := metav1.NewTime(now.Add(-time.Hour).Truncate(time.Second))
+	verification := func(confirmed bool, retain time.Duration, status endpoint.VerificationStatus) *endpoint.Verification {
+		v := &endpoint.Verification{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "google", Generation: 2},
+			Spec: endpoint.VerificationSpec{
+				Domain:    "example.org",
+				Template:  endpoint.VerificationTemplateGoogle,
+				Token:     "abc",
+				RecordTTL: 300,
+				Confirmed: confirmed,
+			},
+			Status: status,
+		}
+		if retain > 0 {
+			v.Spec.RetainAfterConfirmation = &metav1.Duration{Duration: retain}
+		}
+		return v
+	}
+
+	for _, tt := range []struct {
+		title            string
+		verification     *endpoint.Verification
+		expectedRemoveAt time.Time
+		expectedStatus   endpoint.VerificationStatus
+	}{
+		{
+			title:          "not confirmed",
+			verification:   verification(false, 0, endpoint.VerificationStatus{}),
+			expectedStatus: endpoint.VerificationStatus{ObservedGeneration: 2},
+		},
+		{
+			title:            "confirmed now, removed right away",
+			verification:     verification(true, 0, endpoint.VerificationStatus{}),
+			expectedRemoveAt: now.Truncate(time.Second),
+			expectedStatus:   endpoint.VerificationStatus{ObservedGeneration: 2, ConfirmedAt: &metav1.Time{Time: now.Truncate(time.Second)}, Removed: true},
+		},
+		{
+			title:            "confirmed now, retained",
+			verification:     verification(true, 24*time.Hour, endpoint.VerificationStatus{}),
+			expectedRemoveAt: now.Truncate(time.Second).Add(24 * time.Hour),
+			expectedStatus:   endpoint.VerificationStatus{ObservedGeneration: 2, ConfirmedAt: &metav1.Time{Time: now.Truncate(time.Second)}},
+		},
+		{
+			title:            "confirmed before, retention elapsed",
+			verification:     verification(true, time.Hour, endpoint.VerificationStatus{ConfirmedAt: &confirmedAt}),
+			expectedRemoveAt: confirmedAt.Add(time.Hour),
+			expectedStatus:   endpoint.VerificationStatus{ObservedGeneration: 2, ConfirmedAt: &confirmedAt, Removed: true},
+		},
+		{
+			title:          "unconfirmed again",
+			verification:   verification(false, 0, endpoint.VerificationStatus{ConfirmedAt: &confirmedAt, Removed: true}),
+			expectedStatus: endpoint.VerificationStatus{ObservedGeneration: 2},
+		},
+	} {
+		t.Run(tt.title, func(t *testing.T) {
+			record, status := verificationRecordOf(tt.verification, now)
+			assert.Equal(t, verificationRecord{
+				resource: "verification/default/google",
+				name:     "example.org",
+				value:    "google-site-verification=abc",
+				ttl:      300,
+				removeAt: tt.expectedRemoveAt,
+			}, record)
+			assert.Equal(t, tt.expectedStatus, status)
+		})
+	}
+}
+
+func TestVerificationEndpoints(t *testing.T) {
+	removeAt := time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC)
+	endpoints := verificationEndpoints([]verificationRecord{
+		{resource: "verification/default/ms365", name: "example.org", value: "MS=ms12345678", ttl: 3600},
+		{resource: "verification/default/google", name: "example.org", value: "google-site-verification=abc", ttl: 300, removeAt: removeAt},
+		{resource: "verification/default/acme", name: "_validation-persist.example.org", value: "letsencrypt.org"},
+		{resource: "verification/default/later", name: "example.org", value: "later", removeAt: removeAt.Add(time.Hour)},
+	})
+
+	acme := endpoint.NewEndpoint("_validation-persist.example.org", endpoint.RecordTypeTXT, "letsencrypt.org")
+	acme.Labels[endpoint.ResourceLabelKey] = "verification/default/acme"
+	apex := endpoint.NewEndpointWithTTL("example.org", endpoint.RecordTypeTXT, 300, "MS=ms12345678", "google-site-verification=abc", "later").
+		WithProviderSpecific(ActiveUntilKey, "2024-05-02T12:00:00Z")
+	apex.Labels[endpoint.ResourceLabelKey] = "verification/default/ms365"
+	assert.Equal(t, []*endpoint.Endpoint{acme, apex}, endpoints)
+}
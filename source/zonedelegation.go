@@ -0,0 +1,284 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// the timeout of the queries of the NS records of the child zones to their name servers
+const zoneDelegationQueryTimeout = 2 * time.Second
+
+var zoneDelegationGVR = schema.GroupVersionResource{
+	Group:    "externaldns.k8s.io",
+	Version:  "v1alpha1",
+	Resource: "zonedelegations",
+}
+
+// queryNSFunc queries the name server for the NS records of the zone, returning their targets and
+// whether the answer is authoritative.
+type queryNSFunc func(ctx context.Context, nameServer, zone string) ([]string, bool, error)
+
+// zoneDelegationSource is an implementation of Source that provides the NS and DS records of the
+// child zones of the ZoneDelegation objects, once their name servers serve them.
+type zoneDelegationSource struct {
+	dynamicKubeClient      dynamic.Interface
+	informer               informers.GenericInformer
+	namespace              string
+	annotationFilter       string
+	passthroughRecordTypes []string
+	queryNS                queryNSFunc
+}
+
+// NewZoneDelegationSource creates a new zoneDelegationSource with the given config.
+func NewZoneDelegationSource(
+	ctx context.Context,
+	dynamicKubeClient dynamic.Interface,
+	namespace string,
+	annotationFilter string,
+	labelSelector labels.Selector,
+	fieldSelector fields.Selector,
+	passthroughRecordTypes []string,
+) (Source, error) {
+	informerFactory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(dynamicKubeClient, 0, namespace, newListOptionsTweak(labelSelector, fieldSelector))
+	informer := informerFactory.ForResource(zoneDelegationGVR)
+	informer.Informer() // Register with factory before starting.
+
+	informerFactory.Start(ctx.Done())
+
+	// wait for the local cache to be populated.
+	if err := waitForDynamicCacheSync(context.Background(), informerFactory); err != nil {
+		return nil, err
+	}
+
+	return &zoneDelegationSource{
+		dynamicKubeClient:      dynamicKubeClient,
+		informer:               informer,
+		namespace:              namespace,
+		annotationFilter:       annotationFilter,
+		passthroughRecordTypes: passthroughRecordTypes,
+		queryNS:                queryNS,
+	}, nil
+}
+
+// Endpoints returns the NS and DS records of the child zone of every ZoneDelegation whose name
+// servers serve it, and records whether it is delegated in the status of the ZoneDelegation.
+func (zs *zoneDelegationSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	selector, err := getLabelSelector(zs.annotationFilter)
+	if err != nil {
+		return nil, err
+	}
+	objs, err := zs.informer.Lister().ByNamespace(zs.namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	var endpoints []*endpoint.Endpoint
+	for _, obj := range objs {
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			return nil, fmt.Errorf("unexpected zone delegation object %T", obj)
+		}
+		if !matchLabelSelector(selector, u.GetAnnotations()) {
+			continue
+		}
+		if controller, ok := u.GetAnnotations()[controllerAnnotationKey]; ok && controller != controllerAnnotationValue {
+			log.Debugf("Skipping zone delegation %s/%s because controller value does not match, found: %s, required: %s",
+				u.GetNamespace(), u.GetName(), controller, controllerAnnotationValue)
+			continue
+		}
+
+		delegation := &endpoint.ZoneDelegation{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, delegation); err != nil {
+			log.Warnf("Skipping zone delegation %s/%s: %v", u.GetNamespace(), u.GetName(), err)
+			continue
+		}
+		if err := delegation.Validate(); err != nil {
+			log.Warnf("Skipping invalid zone delegation %s/%s: %v", u.GetNamespace(), u.GetName(), err)
+			continue
+		}
+
+		status := delegationStatus(ctx, delegation, zs.queryNS)
+		if status.Delegated {
+			endpoints = append(endpoints, zs.zoneDelegationEndpoints(delegation)...)
+		}
+
+		if delegation.Status == status {
+			continue
+		}
+		switch {
+		case !status.Delegated:
+			log.Warnf("Not delegating the zone %s of zone delegation %s/%s: %s", delegation.Spec.Zone, delegation.Namespace, delegation.Name, status.Message)
+		case status.Message != "":
+			log.Warnf("Keeping the delegation of the zone %s of zone delegation %s/%s, which fails the check: %s", delegation.Spec.Zone, delegation.Namespace, delegation.Name, status.Message)
+		default:
+			log.Infof("Delegating the zone %s to %v", delegation.Spec.Zone, delegation.Spec.NameServers)
+		}
+		if err := zs.updateStatus(ctx, u, status); err != nil {
+			log.Warnf("Could not update the status of zone delegation %s/%s: %v", delegation.Namespace, delegation.Name, err)
+		}
+	}
+
+	for _, ep := range endpoints {
+		sort.Sort(ep.Targets)
+	}
+	return endpoints, nil
+}
+
+func (zs *zoneDelegationSource) AddEventHandler(ctx context.Context, handler func()) {
+	log.Debug("Adding event handler for zone delegation")
+
+	zs.informer.Informer().AddEventHandler(eventHandlerFunc(handler))
+}
+
+// updateStatus replaces the status of the ZoneDelegation object.
+func (zs *zoneDelegationSource) updateStatus(ctx context.Context, u *unstructured.Unstructured, status endpoint.ZoneDelegationStatus) error {
+	value, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&status)
+	if err != nil {
+		return err
+	}
+	u = u.DeepCopy()
+	if err := unstructured.SetNestedField(u.Object, value, "status"); err != nil {
+		return err
+	}
+	_, err = zs.dynamicKubeClient.Resource(zoneDelegationGVR).Namespace(u.GetNamespace()).UpdateStatus(ctx, u, metav1.UpdateOptions{})
+	return err
+}
+
+// zoneDelegationEndpoints returns the NS records of the child zone and, when the DS records are
+// passed through, its DS records.
+func (zs *zoneDelegationSource) zoneDelegationEndpoints(delegation *endpoint.ZoneDelegation) []*endpoint.Endpoint {
+	zone := strings.TrimSuffix(delegation.Spec.Zone, ".")
+	nameServers := make(endpoint.Targets, 0, len(delegation.Spec.NameServers))
+	for _, nameServer := range delegation.Spec.NameServers {
+		nameServers = append(nameServers, strings.TrimSuffix(nameServer, "."))
+	}
+	endpoints := []*endpoint.Endpoint{endpoint.NewEndpointWithTTL(zone, endpoint.RecordTypeNS, delegation.Spec.RecordTTL, nameServers...)}
+	if len(delegation.Spec.DSRecords) > 0 {
+		if slices.Contains(zs.passthroughRecordTypes, endpoint.RecordTypeDS) {
+			endpoints = append(endpoints, endpoint.NewEndpointWithTTL(zone, endpoint.RecordTypeDS, delegation.Spec.RecordTTL, delegation.Spec.DSRecords...))
+		} else {
+			log.Warnf("Skipping the DS records of zone delegation %s/%s, which are not passed through, see --passthrough-record-types", delegation.Namespace, delegation.Name)
+		}
+	}
+
+	resource := fmt.Sprintf("zonedelegation/%s/%s", delegation.Namespace, delegation.Name)
+	for _, ep := range endpoints {
+		ep.Labels[endpoint.ResourceLabelKey] = resource
+	}
+	return endpoints
+}
+
+// delegationStatus returns the status of the delegation, which is published once its name servers
+// serve the child zone. A delegation already published stays published when the check fails later
+// on, as withdrawing it would take the child zone down, e.g. for name servers unreachable for a
+// moment; the failure is only reported in the message of the status.
+func delegationStatus(ctx context.Context, delegation *endpoint.ZoneDelegation, query queryNSFunc) endpoint.ZoneDelegationStatus {
+	status := endpoint.ZoneDelegationStatus{ObservedGeneration: delegation.Generation, Delegated: true}
+	if delegation.Spec.SkipValidation {
+		return status
+	}
+	if err := validateDelegation(ctx, delegation.Spec.Zone, delegation.Spec.NameServers, query); err != nil {
+		status.Delegated = delegation.Status.Delegated
+		status.Message = err.Error()
+	}
+	return status
+}
+
+// validateDelegation checks that the name servers serve the zone with the NS records of the
+// delegation, so that a lame delegation is never published. The name servers which can't be
+// reached are skipped, as long as one of them answers.
+func validateDelegation(ctx context.Context, zone string, nameServers []string, query queryNSFunc) error {
+	expected := normalizeNameServers(nameServers)
+	answered := false
+	var errs []error
+	for _, nameServer := range nameServers {
+		targets, authoritative, err := query(ctx, nameServer, zone)
+		if err != nil {
+			log.Debugf("Failed to query the name server %s for the NS records of %s: %v", nameServer, zone, err)
+			errs = append(errs, fmt.Errorf("%s: %w", nameServer, err))
+			continue
+		}
+		if !authoritative {
+			return fmt.Errorf("the name server %s is not authoritative for the zone", nameServer)
+		}
+		if actual := normalizeNameServers(targets); !slices.Equal(actual, expected) {
+			return fmt.Errorf("the name server %s serves the NS records %v instead of %v", nameServer, actual, expected)
+		}
+		answered = true
+	}
+	if !answered {
+		return fmt.Errorf("no name server answered: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// normalizeNameServers returns the sorted, lowercased fully qualified names of the name servers.
+func normalizeNameServers(nameServers []string) []string {
+	normalized := make([]string, 0, len(nameServers))
+	for _, nameServer := range nameServers {
+		normalized = append(normalized, dns.Fqdn(strings.ToLower(nameServer)))
+	}
+	sort.Strings(normalized)
+	return slices.Compact(normalized)
+}
+
+// queryNS queries the name server on port 53 for the NS records of the zone, without recursion.
+func queryNS(ctx context.Context, nameServer, zone string) ([]string, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, zoneDelegationQueryTimeout)
+	defer cancel()
+
+	m := new(dns.Msg)
+	m.SetQuestion(dns.Fqdn(zone), dns.TypeNS)
+	m.RecursionDesired = false
+	c := &dns.Client{Timeout: zoneDelegationQueryTimeout}
+	resp, _, err := c.ExchangeContext(ctx, m, net.JoinHostPort(strings.TrimSuffix(nameServer, "."), "53"))
+	if err != nil {
+		return nil, false, err
+	}
+	if resp.Rcode != dns.RcodeSuccess {
+		return nil, false, fmt.Errorf("bad return code: %s", dns.RcodeToString[resp.Rcode])
+	}
+	var targets []string
+	for _, rr := range resp.Answer {
+		if ns, ok := rr.(*dns.NS); ok {
+			targets = append(targets, ns.Ns)
+		}
+	}
+	return targets, resp.Authoritative, nil
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func TestZoneDelegationEndpoints(t *testing.T) {
+	delegation := &endpoint.ZoneDelegation{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "team-a"},
+		Spec: endpoint.ZoneDelegationSpec{
+			Zone:        "team-a.example.org.",
+			NameServers: endpoint.Targets{"ns1.example.net.", "ns2.example.net"},
+			DSRecords:   endpoint.Targets{"12345 13 2 AB"},
+			RecordTTL:   3600,
+		},
+	}
+	expected := func(recordType string, targets ...string) *endpoint.Endpoint {
+		ep := endpoint.NewEndpointWithTTL("team-a.example.org", recordType, 3600, targets...)
+		ep.Labels[endpoint.ResourceLabelKey] = "zonedelegation/default/team-a"
+		return ep
+	}
+
+	zs := &zoneDelegationSource{}
+	assert.Equal(t, []*endpoint.Endpoint{expected(endpoint.RecordTypeNS, "ns1.example.net", "ns2.example.net")}, zs.zoneDelegationEndpoints(delegation))
+
+	// the DS records are only published when they are passed through
+	zs.passthroughRecordTypes = []string{endpoint.RecordTypeDS}
+	assert.Equal(t, []*endpoint.Endpoint{
+		expected(endpoint.RecordTypeNS, "ns1.example.net", "ns2.example.net"),
+		expected(endpoint.RecordTypeDS, "12345 13 2 AB"),
+	}, zs.zoneDelegationEndpoints(delegation))
+}
+
+// nsAnswer is the answer of a name server to the query of the NS records of a zone.
+type nsAnswer struct {
+	targets       []string
+	authoritative bool
+	err           error
+}
+
+func TestValidateDelegation(t *testing.T) {
+	served := []string{"ns2.example.net.", "ns1.example.net."}
+	authoritative := nsAnswer{targets: served, authoritative: true}
+
+	for _, tt := range []struct {
+		title    string
+		answers  map[string]nsAnswer
+		expected string
+	}{
+		{
+			title:   "served by every name server",
+			answers: map[string]nsAnswer{"ns1.example.net": authoritative, "NS2.example.net.": authoritative},
+		},
+		{
+			title:   "unreachable name server",
+			answers: map[string]nsAnswer{"ns1.example.net": authoritative, "NS2.example.net.": {err: errors.New("i/o timeout")}},
+		},
+		{
+			title:    "no name server reachable",
+			answers:  map[string]nsAnswer{"ns1.example.net": {err: errors.New("i/o timeout")}, "NS2.example.net.": {err: errors.New("connection refused")}},
+			expected: "no name server answered: ns1.example.net: i/o timeout\nNS2.example.net.: connection refused",
+		},
+		{
+			title:    "lame name server",
+			answers:  map[string]nsAnswer{"ns1.example.net": authoritative, "NS2.example.net.": {targets: served}},
+			expected: "the name server NS2.example.net. is not authoritative for the zone",
+		},
+		{
+			title:    "different name servers",
+			answers:  map[string]nsAnswer{"ns1.example.net": {targets: []string{"ns1.example.net."}, authoritative: true}, "NS2.example.net.": authoritative},
+			expected: "the name server ns1.example.net serves the NS records [ns1.example.net.] instead of [ns1.example.net. ns2.example.net.]",
+		},
+	} {
+		t.Run(tt.title, func(t *testing.T) {
+			query := func(_ context.Context, nameServer, zone string) ([]string, bool, error) {
+				assert.Equal(t, "team-a.example.org", zone)
+				answer := tt.answers[nameServer]
+				return answer.targets, answer.authoritative, answer.err
+			}
+			err := validateDelegation(context.Background(), "team-a.example.org", []string{"ns1.example.net", "NS2.example.net."}, query)
+			if tt.expected == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDelegationStatus(t *testing.T) {
+	failing := func(context.Context, string, string) ([]string, bool, error) {
+		return nil, false, errors.New("i/o timeout")
+	}
+	delegation := &endpoint.ZoneDelegation{
+		ObjectMeta: metav1.ObjectMeta{Generation: 2},
+		Spec: endpoint.ZoneDelegationSpec{
+			Zone:        "team-a.example.org",
+			NameServers: endpoint.Targets{"ns1.example.net"},
+		},
+	}
+
+	// a delegation failing the check is not published
+	assert.Equal(t, endpoint.ZoneDelegationStatus{ObservedGeneration: 2, Message: "no name server answered: ns1.example.net: i/o timeout"},
+		delegationStatus(context.Background(), delegation, failing))
+
+	// a published delegation stays published, the failure is only reported
+	delegation.Status = endpoint.ZoneDelegationStatus{ObservedGeneration: 1, Delegated: true}
+	assert.Equal(t, endpoint.ZoneDelegationStatus{ObservedGeneration: 2, Delegated: true, Message: "no name server answered: ns1.example.net: i/o timeout"},
+		delegationStatus(context.Background(), delegation, failing))
+
+	// the check is skipped on request
+	delegation.Status = endpoint.ZoneDelegationStatus{}
+	delegation.Spec.SkipValidation = true
+	assert.Equal(t, endpoint.ZoneDelegationStatus{ObservedGeneration: 2, Delegated: true}, delegationStatus(context.Background(), delegation, failing))
+}
@@ -0,0 +1,227 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package e2e runs external-dns in a kind cluster against DNSServer, see docs/contributing/e2e.md.
+package e2e
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/miekg/dns"
+)
+
+// DNSServer is an authoritative server of a single zone accepting RFC 2136 updates without TSIG,
+// which is enough for the rfc2136 provider: it answers queries and zone transfers of the zone.
+type DNSServer struct {
+	zone string
+
+	mu      sync.Mutex
+	records []dns.RR
+
+	udp *dns.Server
+	tcp *dns.Server
+}
+
+// NewDNSServer returns a server of the zone without records.
+func NewDNSServer(zone string) *DNSServer {
+	return &DNSServer{zone: dns.Fqdn(strings.ToLower(zone))}
+}
+
+// Start serves the zone over UDP and TCP on the address, e.g. ":5353".
+func (s *DNSServer) Start(address string) error {
+	pc, err := net.ListenPacket("udp", address)
+	if err != nil {
+		return err
+	}
+	l, err := net.Listen("tcp", address)
+	if err != nil {
+		pc.Close()
+		return err
+	}
+	s.udp = &dns.Server{PacketConn: pc, Handler: s, MsgAcceptFunc: acceptUpdates}
+	s.tcp = &dns.Server{Listener: l, Handler: s, MsgAcceptFunc: acceptUpdates}
+	go s.udp.ActivateAndServe()
+	go s.tcp.ActivateAndServe()
+	return nil
+}
+
+// Addr returns the TCP address the server listens on.
+func (s *DNSServer) Addr() string {
+	return s.tcp.Listener.Addr().String()
+}
+
+// Stop stops serving.
+func (s *DNSServer) Stop() {
+	if s.udp != nil {
+		s.udp.Shutdown()
+	}
+	if s.tcp != nil {
+		s.tcp.Shutdown()
+	}
+}
+
+// Add adds a record in zone file format, e.g. to create records not managed by external-dns.
+func (s *DNSServer) Add(record string) error {
+	rr, err := dns.NewRR(record)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.insert(rr)
+	return nil
+}
+
+// Records returns the records of the name and type.
+func (s *DNSServer) Records(name string, rrtype uint16) []dns.RR {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var rrs []dns.RR
+	for _, rr := range s.records {
+		if matches(rr, name, rrtype) {
+			rrs = append(rrs, dns.Copy(rr))
+		}
+	}
+	return rrs
+}
+
+// ServeDNS answers queries, zone transfers and updates of the zone.
+func (s *DNSServer) ServeDNS(w dns.ResponseWriter, req *dns.Msg) {
+	m := new(dns.Msg)
+	m.SetReply(req)
+	if len(req.Question) != 1 || !dns.IsSubDomain(s.zone, strings.ToLower(req.Question[0].Name)) {
+		m.Rcode = dns.RcodeNotAuth
+		w.WriteMsg(m)
+		return
+	}
+
+	q := req.Question[0]
+	switch {
+	case req.Opcode == dns.OpcodeUpdate:
+		s.update(req.Ns)
+	case q.Qtype == dns.TypeAXFR:
+		s.transfer(w, req)
+		return
+	default:
+		m.Authoritative = true
+		if q.Qtype == dns.TypeSOA && strings.EqualFold(q.Name, s.zone) {
+			m.Answer = []dns.RR{s.soa()}
+		} else {
+			m.Answer = s.Records(q.Name, q.Qtype)
+		}
+	}
+	w.WriteMsg(m)
+}
+
+// update applies the update section of an update, see RFC 2136 section 2.5.
+func (s *DNSServer) update(rrs []dns.RR) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, rr := range rrs {
+		h := rr.Header()
+		switch h.Class {
+		case dns.ClassANY:
+			// deletes the RRset of the type, or all RRsets of the name with type ANY
+			s.remove(func(r dns.RR) bool { return matches(r, h.Name, h.Rrtype) })
+		case dns.ClassNONE:
+			// deletes the RR
+			s.remove(func(r dns.RR) bool { return equal(r, rr) })
+		default:
+			s.insert(rr)
+		}
+	}
+}
+
+func (s *DNSServer) transfer(w dns.ResponseWriter, req *dns.Msg) {
+	s.mu.Lock()
+	rrs := []dns.RR{s.soa()}
+	for _, rr := range s.records {
+		rrs = append(rrs, dns.Copy(rr))
+	}
+	rrs = append(rrs, s.soa())
+	s.mu.Unlock()
+
+	ch := make(chan *dns.Envelope, 1)
+	ch <- &dns.Envelope{RR: rrs}
+	close(ch)
+	tr := new(dns.Transfer)
+	if err := tr.Out(w, req, ch); err != nil {
+		fmt.Printf("Failed to transfer %s: %v\n", s.zone, err)
+	}
+	w.Close()
+}
+
+func (s *DNSServer) insert(rr dns.RR) {
+	rr.Header().Name = strings.ToLower(dns.Fqdn(rr.Header().Name))
+	rr.Header().Class = dns.ClassINET
+	for _, r := range s.records {
+		if equal(r, rr) {
+			return
+		}
+	}
+	s.records = append(s.records, dns.Copy(rr))
+}
+
+func (s *DNSServer) remove(match func(dns.RR) bool) {
+	kept := s.records[:0]
+	for _, r := range s.records {
+		if !match(r) {
+			kept = append(kept, r)
+		}
+	}
+	s.records = kept
+}
+
+func (s *DNSServer) soa() dns.RR {
+	return &dns.SOA{
+		Hdr:     dns.RR_Header{Name: s.zone, Rrtype: dns.TypeSOA, Class: dns.ClassINET, Ttl: 300},
+		Ns:      "ns." + s.zone,
+		Mbox:    "hostmaster." + s.zone,
+		Serial:  1,
+		Refresh: 3600,
+		Retry:   600,
+		Expire:  86400,
+		Minttl:  300,
+	}
+}
+
+// acceptUpdates accepts updates besides the messages accepted by default, which rejects them as not implemented.
+func acceptUpdates(h dns.Header) dns.MsgAcceptAction {
+	if int(h.Bits>>11)&0xF == dns.OpcodeUpdate {
+		return dns.MsgAccept
+	}
+	return dns.DefaultMsgAcceptFunc(h)
+}
+
+// matches reports whether the record has the name and type, any type with dns.TypeANY.
+func matches(rr dns.RR, name string, rrtype uint16) bool {
+	h := rr.Header()
+	return strings.EqualFold(h.Name, dns.Fqdn(name)) && (rrtype == dns.TypeANY || h.Rrtype == rrtype)
+}
+
+// equal reports whether the records have the same name, type and data, regardless of their TTL and class.
+func equal(a, b dns.RR) bool {
+	a, b = dns.Copy(a), dns.Copy(b)
+	for _, rr := range []dns.RR{a, b} {
+		rr.Header().Name = strings.ToLower(dns.Fqdn(rr.Header().Name))
+		rr.Header().Ttl = 0
+		rr.Header().Class = dns.ClassINET
+	}
+	return dns.IsDuplicate(a, b)
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/require"
+)
+
+func mustRR(t *testing.T, s string) dns.RR {
+	t.Helper()
+	rr, err := dns.NewRR(s)
+	require.NoError(t, err)
+	return rr
+}
+
+func TestDNSServer(t *testing.T) {
+	s := NewDNSServer("e2e.example.org")
+	require.NoError(t, s.Start("127.0.0.1:0"))
+	defer s.Stop()
+	require.NoError(t, s.Add("foreign.e2e.example.org. 300 IN A 10.9.9.9"))
+
+	c := &dns.Client{Net: "tcp"}
+	update := new(dns.Msg)
+	update.SetUpdate("e2e.example.org.")
+	update.Insert([]dns.RR{
+		mustRR(t, "app.e2e.example.org. 300 IN A 10.1.2.3"),
+		mustRR(t, "app.e2e.example.org. 300 IN A 10.1.2.4"),
+		mustRR(t, `app.e2e.example.org. 300 IN TXT "heritage=external-dns"`),
+	})
+	_, _, err := c.Exchange(update, s.Addr())
+	require.NoError(t, err)
+	require.Len(t, s.Records("app.e2e.example.org", dns.TypeA), 2)
+
+	update = new(dns.Msg)
+	update.SetUpdate("e2e.example.org.")
+	update.Remove([]dns.RR{mustRR(t, "app.e2e.example.org. 300 IN A 10.1.2.3")})
+	_, _, err = c.Exchange(update, s.Addr())
+	require.NoError(t, err)
+	require.Len(t, s.Records("app.e2e.example.org", dns.TypeA), 1)
+
+	query := new(dns.Msg)
+	query.SetQuestion("app.e2e.example.org.", dns.TypeA)
+	resp, _, err := c.Exchange(query, s.Addr())
+	require.NoError(t, err)
+	require.Len(t, resp.Answer, 1)
+	require.Equal(t, "10.1.2.4", resp.Answer[0].(*dns.A).A.String())
+
+	axfr := new(dns.Msg)
+	axfr.SetAxfr("e2e.example.org.")
+	envelopes, err := new(dns.Transfer).In(axfr, s.Addr())
+	require.NoError(t, err)
+	var transferred []dns.RR
+	for e := range envelopes {
+		require.NoError(t, e.Error)
+		transferred = append(transferred, e.RR...)
+	}
+	// the records between the SOA records
+	require.Len(t, transferred, 5)
+
+	update = new(dns.Msg)
+	update.SetUpdate("e2e.example.org.")
+	update.RemoveName([]dns.RR{mustRR(t, "app.e2e.example.org. 0 IN A 0.0.0.0")})
+	_, _, err = c.Exchange(update, s.Addr())
+	require.NoError(t, err)
+	require.Empty(t, s.Records("app.e2e.example.org", dns.TypeANY))
+	require.Len(t, s.Records("foreign.e2e.example.org", dns.TypeA), 1)
+
+	query.SetQuestion("other.example.com.", dns.TypeA)
+	resp, _, err = c.Exchange(query, s.Addr())
+	require.NoError(t, err)
+	require.Equal(t, dns.RcodeNotAuth, resp.Rcode)
+}
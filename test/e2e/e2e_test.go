@@ -0,0 +1,271 @@
+//go:build e2e
+
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	zone          = "e2e.example.org"
+	namespace     = "external-dns-e2e"
+	name          = "external-dns"
+	ownerID       = "e2e"
+	interval      = 2 * time.Second
+	timeout       = 2 * time.Minute
+	hostnameKey   = "external-dns.alpha.kubernetes.io/hostname"
+	targetKey     = "external-dns.alpha.kubernetes.io/target"
+	defaultListen = ":5353"
+)
+
+var (
+	client    kubernetes.Interface
+	dnsServer *DNSServer
+)
+
+// TestMain deploys external-dns to the cluster of the kubeconfig, with the rfc2136 provider updating
+// the DNS server of the test. It is configured by environment variables, set by scripts/e2e.sh:
+//   - E2E_IMAGE: the image of external-dns, loaded into the cluster (required)
+//   - E2E_DNS_HOST: the address of the test DNS server reachable from the pods of the cluster (required)
+//   - E2E_DNS_LISTEN: the address the test DNS server listens on (default: :5353)
+//   - KUBECONFIG: the kubeconfig of the cluster (default: ~/.kube/config)
+func TestMain(m *testing.M) {
+	os.Exit(run(m))
+}
+
+func run(m *testing.M) int {
+	image, dnsHost := os.Getenv("E2E_IMAGE"), os.Getenv("E2E_DNS_HOST")
+	if image == "" || dnsHost == "" {
+		fmt.Println("E2E_IMAGE and E2E_DNS_HOST are required, run the e2e tests with make e2e")
+		return 1
+	}
+	listen := os.Getenv("E2E_DNS_LISTEN")
+	if listen == "" {
+		listen = defaultListen
+	}
+
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(clientcmd.NewDefaultClientConfigLoadingRules(), nil).ClientConfig()
+	if err != nil {
+		fmt.Printf("Failed to load the kubeconfig: %v\n", err)
+		return 1
+	}
+	client, err = kubernetes.NewForConfig(config)
+	if err != nil {
+		fmt.Printf("Failed to create the client: %v\n", err)
+		return 1
+	}
+
+	dnsServer = NewDNSServer(zone)
+	if err := dnsServer.Start(listen); err != nil {
+		fmt.Printf("Failed to start the DNS server: %v\n", err)
+		return 1
+	}
+	defer dnsServer.Stop()
+
+	_, port, err := net.SplitHostPort(listen)
+	if err != nil {
+		fmt.Printf("Invalid E2E_DNS_LISTEN %q: %v\n", listen, err)
+		return 1
+	}
+	ctx := context.Background()
+	if err := deploy(ctx, image, dnsHost, port); err != nil {
+		fmt.Printf("Failed to deploy external-dns: %v\n", err)
+		undeploy(ctx)
+		return 1
+	}
+	defer undeploy(ctx)
+
+	return m.Run()
+}
+
+// deploy deploys external-dns with the rfc2136 provider and the service source.
+func deploy(ctx context.Context, image, dnsHost, dnsPort string) error {
+	if _, err := client.CoreV1().Namespaces().Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}, metav1.CreateOptions{}); err != nil {
+		return err
+	}
+	if _, err := client.CoreV1().ServiceAccounts(namespace).Create(ctx, &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: name}}, metav1.CreateOptions{}); err != nil {
+		return err
+	}
+	if _, err := client.RbacV1().ClusterRoles().Create(ctx, &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{Name: namespace},
+		Rules: []rbacv1.PolicyRule{{
+			APIGroups: []string{""},
+			Resources: []string{"services", "endpoints", "pods", "nodes"},
+			Verbs:     []string{"get", "watch", "list"},
+		}},
+	}, metav1.CreateOptions{}); err != nil {
+		return err
+	}
+	if _, err := client.RbacV1().ClusterRoleBindings().Create(ctx, &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: namespace},
+		RoleRef:    rbacv1.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "ClusterRole", Name: namespace},
+		Subjects:   []rbacv1.Subject{{Kind: "ServiceAccount", Name: name, Namespace: namespace}},
+	}, metav1.CreateOptions{}); err != nil {
+		return err
+	}
+
+	labels := map[string]string{"app": name}
+	_, err := client.AppsV1().Deployments(namespace).Create(ctx, &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					ServiceAccountName: name,
+					Containers: []corev1.Container{{
+						Name:            name,
+						Image:           image,
+						ImagePullPolicy: corev1.PullIfNotPresent,
+						Args: []string{
+							"--source=service",
+							"--namespace=" + namespace,
+							"--provider=rfc2136",
+							"--rfc2136-host=" + dnsHost,
+							"--rfc2136-port=" + dnsPort,
+							"--rfc2136-zone=" + zone,
+							"--rfc2136-insecure",
+							"--domain-filter=" + zone,
+							"--registry=txt",
+							"--txt-owner-id=" + ownerID,
+							"--policy=sync",
+							"--interval=" + interval.String(),
+							"--events",
+							"--log-level=debug",
+						},
+					}},
+				},
+			},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return err
+	}
+
+	return wait.PollUntilContextTimeout(ctx, interval, timeout, true, func(ctx context.Context) (bool, error) {
+		d, err := client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return d.Status.ReadyReplicas > 0, nil
+	})
+}
+
+func undeploy(ctx context.Context) {
+	policy := metav1.DeletePropagationForeground
+	opts := metav1.DeleteOptions{PropagationPolicy: &policy}
+	client.RbacV1().ClusterRoleBindings().Delete(ctx, namespace, opts)
+	client.RbacV1().ClusterRoles().Delete(ctx, namespace, opts)
+	client.CoreV1().Namespaces().Delete(ctx, namespace, opts)
+}
+
+func createService(t *testing.T, name, hostname, target string) {
+	t.Helper()
+	_, err := client.CoreV1().Services(namespace).Create(context.Background(), &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Annotations: map[string]string{hostnameKey: hostname, targetKey: target},
+		},
+		Spec: corev1.ServiceSpec{
+			Type:  corev1.ServiceTypeClusterIP,
+			Ports: []corev1.ServicePort{{Port: 80}},
+		},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+}
+
+func setServiceTarget(t *testing.T, name, target string) {
+	t.Helper()
+	svc, err := client.CoreV1().Services(namespace).Get(context.Background(), name, metav1.GetOptions{})
+	require.NoError(t, err)
+	svc.Annotations[targetKey] = target
+	_, err = client.CoreV1().Services(namespace).Update(context.Background(), svc, metav1.UpdateOptions{})
+	require.NoError(t, err)
+}
+
+func deleteService(t *testing.T, name string) {
+	t.Helper()
+	require.NoError(t, client.CoreV1().Services(namespace).Delete(context.Background(), name, metav1.DeleteOptions{}))
+}
+
+// targets returns the targets of the A records of the name in the test DNS server.
+func targets(name string) []string {
+	var ips []string
+	for _, rr := range dnsServer.Records(name, dns.TypeA) {
+		ips = append(ips, rr.(*dns.A).A.String())
+	}
+	return ips
+}
+
+// eventually waits until the A records of the name have the targets.
+func eventually(t *testing.T, name string, want ...string) {
+	t.Helper()
+	err := wait.PollUntilContextTimeout(context.Background(), interval, timeout, true, func(context.Context) (bool, error) {
+		got := targets(name)
+		return len(got) == len(want) && (len(want) == 0 || fmt.Sprint(got) == fmt.Sprint(want)), nil
+	})
+	require.NoError(t, err, "records of %s: got %v, want %v", name, targets(name), want)
+}
+
+func TestCreateUpdateDelete(t *testing.T) {
+	hostname := "app." + zone
+	createService(t, "app", hostname, "10.1.2.3")
+	eventually(t, hostname, "10.1.2.3")
+	require.NotEmpty(t, dnsServer.Records("a-"+hostname, dns.TypeTXT), "ownership record of %s", hostname)
+
+	setServiceTarget(t, "app", "10.1.2.4")
+	eventually(t, hostname, "10.1.2.4")
+
+	deleteService(t, "app")
+	eventually(t, hostname)
+	require.Empty(t, dnsServer.Records("a-"+hostname, dns.TypeTXT), "ownership record of %s", hostname)
+}
+
+func TestOwnership(t *testing.T) {
+	hostname := "foreign." + zone
+	require.NoError(t, dnsServer.Add(hostname+". 300 IN A 10.9.9.9"))
+
+	// the record without ownership record is not taken over
+	createService(t, "foreign", hostname, "10.1.2.5")
+	defer deleteService(t, "foreign")
+	time.Sleep(5 * interval)
+	require.Equal(t, []string{"10.9.9.9"}, targets(hostname))
+
+	// the records of the service are still created next to it
+	other := "owned." + zone
+	createService(t, "owned", other, "10.1.2.6")
+	defer deleteService(t, "owned")
+	eventually(t, other, "10.1.2.6")
+	require.Equal(t, []string{"10.9.9.9"}, targets(hostname))
+}